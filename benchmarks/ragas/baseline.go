@@ -0,0 +1,184 @@
+// ABOUTME: Compares benchmark results against a stored baseline run
+// ABOUTME: Flags metric drops beyond a threshold, PASS→FAIL flips, and missing tests so benchmarks can gate CI
+
+package ragas
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Metrics a Regression reports that are not scores
+const (
+	MetricStatus  = "status"  // The test passed in the baseline and fails now
+	MetricMissing = "missing" // The test is in the baseline but not the current run
+)
+
+// Regression is a metric that dropped relative to the baseline. For MetricStatus and
+// MetricMissing, Baseline and Current are the tests' overall scores.
+type Regression struct {
+	TestID   string  `json:"test_id"`
+	Metric   string  `json:"metric"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+	Drop     float64 `json:"drop"`
+}
+
+// BaselineComparison is the outcome of comparing a run with a baseline
+type BaselineComparison struct {
+	Regressions    []Regression `json:"regressions"`
+	NewTests       []string     `json:"new_tests"`       // In the current run but not the baseline
+	ScoringChanged []string     `json:"scoring_changed"` // Scored by the judge in one run and heuristics in the other; judged metrics not compared
+	Compared       int          `json:"compared"`
+}
+
+// LoadBaseline reads the results from a file written by ExportResults
+func LoadBaseline(path string) ([]TestResult, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var summary struct {
+		Results []TestResult `json:"results"`
+	}
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if len(summary.Results) == 0 {
+		return nil, fmt.Errorf("baseline %s contains no results", path)
+	}
+	return summary.Results, nil
+}
+
+// CompareToBaseline reports every metric that dropped by more than threshold, every
+// test that passed in the baseline and fails now, and every baseline test missing from
+// the current run. Metrics whose scale depends on the scoring mode (the judged
+// metrics and the overall score) are compared only when both runs were scored the
+// same way; tests scored differently are listed in ScoringChanged instead.
+func CompareToBaseline(current, baseline []TestResult, threshold float64) BaselineComparison {
+	byID := make(map[string]TestResult, len(baseline))
+	for _, b := range baseline {
+		byID[b.TestID] = b
+	}
+
+	comparison := BaselineComparison{Regressions: []Regression{}, NewTests: []string{}, ScoringChanged: []string{}}
+	ran := make(map[string]bool, len(current))
+	for _, cur := range current {
+		ran[cur.TestID] = true
+		base, ok := byID[cur.TestID]
+		if !ok {
+			comparison.NewTests = append(comparison.NewTests, cur.TestID)
+			continue
+		}
+		comparison.Compared++
+
+		sameScoring := ScoringMode(cur) == ScoringMode(base)
+		if !sameScoring {
+			comparison.ScoringChanged = append(comparison.ScoringChanged, cur.TestID)
+		} else if base.Status == "PASS" && cur.Status != "PASS" {
+			comparison.Regressions = append(comparison.Regressions, Regression{
+				TestID:   cur.TestID,
+				Metric:   MetricStatus,
+				Baseline: base.OverallScore,
+				Current:  cur.OverallScore,
+				Drop:     base.OverallScore - cur.OverallScore,
+			})
+		}
+
+		for _, m := range comparedMetrics(cur, base, sameScoring) {
+			if drop := m.baseline - m.current; drop > threshold {
+				comparison.Regressions = append(comparison.Regressions, Regression{
+					TestID:   cur.TestID,
+					Metric:   m.name,
+					Baseline: m.baseline,
+					Current:  m.current,
+					Drop:     drop,
+				})
+			}
+		}
+	}
+
+	// A scenario that stopped running must not slip past the gate
+	for _, base := range baseline {
+		if !ran[base.TestID] {
+			comparison.Regressions = append(comparison.Regressions, Regression{
+				TestID:   base.TestID,
+				Metric:   MetricMissing,
+				Baseline: base.OverallScore,
+				Drop:     base.OverallScore,
+			})
+		}
+	}
+
+	sort.Strings(comparison.NewTests)
+	sort.Strings(comparison.ScoringChanged)
+	return comparison
+}
+
+// FilterBaseline keeps the baseline results a run limited to test id would produce
+// (every result when id is empty), matching IDs as FilterScenarios does
+func FilterBaseline(baseline []TestResult, id string) []TestResult {
+	if id == "" {
+		return baseline
+	}
+	var matched []TestResult
+	for _, b := range baseline {
+		if matchesTestID(b.TestID, id) {
+			matched = append(matched, b)
+		}
+	}
+	return matched
+}
+
+// ScoringMode is how result was scored: "llm_judge" or "heuristic". Results from
+// before the mode was recorded are judged when they carry a judgement.
+func ScoringMode(result TestResult) string {
+	if result.Scoring != "" {
+		return result.Scoring
+	}
+	if mode, ok := result.Details["scoring"].(string); ok && mode != "" {
+		return mode
+	}
+	if result.Judgement != nil {
+		return ScoringJudge
+	}
+	return ScoringHeuristic
+}
+
+// metricPair is one metric's value in the current and baseline runs
+type metricPair struct {
+	name              string
+	current, baseline float64
+}
+
+// comparedMetrics lists the metrics both results have scores for on the same scale:
+// custom metrics always, the rest only when sameScoring
+func comparedMetrics(cur, base TestResult, sameScoring bool) []metricPair {
+	var metrics []metricPair
+	if sameScoring {
+		metrics = append(metrics,
+			metricPair{"faithfulness", cur.FaithfulnessScore, base.FaithfulnessScore},
+			metricPair{"context_recall", cur.ContextRecallScore, base.ContextRecallScore},
+			metricPair{"overall", cur.OverallScore, base.OverallScore},
+		)
+		if ScoringMode(cur) == ScoringJudge {
+			metrics = append(metrics,
+				metricPair{"answer_relevancy", cur.AnswerRelevancyScore, base.AnswerRelevancyScore},
+				metricPair{"context_precision", cur.ContextPrecisionScore, base.ContextPrecisionScore},
+			)
+		}
+	}
+	custom := make(map[string]float64, len(base.CustomMetrics))
+	for _, c := range base.CustomMetrics {
+		custom[c.Name] = c.Score
+	}
+	for _, c := range cur.CustomMetrics {
+		if score, ok := custom[c.Name]; ok {
+			metrics = append(metrics, metricPair{c.Name, c.Score, score})
+		}
+	}
+	return metrics
+}
@@ -0,0 +1,189 @@
+// ABOUTME: Tests for comparing benchmark results with a baseline run
+// ABOUTME: Verifies metric drops, PASS→FAIL flips, missing tests, and scoring mode mismatches are caught
+
+package ragas
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// heuristicResult is a heuristically scored result for baseline tests
+func heuristicResult(id, status string, faithfulness, recall float64) TestResult {
+	return TestResult{
+		TestID:             id,
+		Status:             status,
+		Scoring:            ScoringHeuristic,
+		FaithfulnessScore:  faithfulness,
+		ContextRecallScore: recall,
+		OverallScore:       (faithfulness + recall) / 2,
+	}
+}
+
+func TestCompareToBaseline(t *testing.T) {
+	judged := func(id, status string, score float64) TestResult {
+		return TestResult{TestID: id, Status: status, Scoring: ScoringJudge, FaithfulnessScore: score,
+			ContextRecallScore: score, AnswerRelevancyScore: score, ContextPrecisionScore: score, OverallScore: score}
+	}
+
+	tests := []struct {
+		name     string
+		current  []TestResult
+		baseline []TestResult
+		want     []string // "test_id metric" of each regression
+		newTests []string
+		changed  []string
+		compared int
+	}{
+		{
+			name:     "unchanged",
+			current:  []TestResult{heuristicResult("test_1", "PASS", 1, 1)},
+			baseline: []TestResult{heuristicResult("test_1", "PASS", 1, 1)},
+			compared: 1,
+		},
+		{
+			name:     "drop past the threshold",
+			current:  []TestResult{heuristicResult("test_1", "PASS", 0.8, 1)},
+			baseline: []TestResult{heuristicResult("test_1", "PASS", 1, 1)},
+			want:     []string{"test_1 faithfulness", "test_1 overall"},
+			compared: 1,
+		},
+		{
+			name:     "drop within the threshold",
+			current:  []TestResult{heuristicResult("test_1", "PASS", 0.97, 1)},
+			baseline: []TestResult{heuristicResult("test_1", "PASS", 1, 1)},
+			compared: 1,
+		},
+		{
+			name:     "PASS to FAIL within the threshold",
+			current:  []TestResult{heuristicResult("test_1", "FAIL", 0.89, 0.9)},
+			baseline: []TestResult{heuristicResult("test_1", "PASS", 0.9, 0.9)},
+			want:     []string{"test_1 status"},
+			compared: 1,
+		},
+		{
+			name:     "missing from the current run",
+			current:  []TestResult{heuristicResult("test_1", "PASS", 1, 1)},
+			baseline: []TestResult{heuristicResult("test_1", "PASS", 1, 1), heuristicResult("test_2", "PASS", 1, 1)},
+			want:     []string{"test_2 missing"},
+			compared: 1,
+		},
+		{
+			name:     "new test",
+			current:  []TestResult{heuristicResult("test_1", "PASS", 1, 1), heuristicResult("test_0", "FAIL", 0, 0)},
+			baseline: []TestResult{heuristicResult("test_1", "PASS", 1, 1)},
+			newTests: []string{"test_0"},
+			compared: 1,
+		},
+		{
+			name:     "judged metrics compared between judged runs",
+			current:  []TestResult{judged("test_1", "PASS", 0.8)},
+			baseline: []TestResult{judged("test_1", "PASS", 1)},
+			want:     []string{"test_1 faithfulness", "test_1 context_recall", "test_1 overall", "test_1 answer_relevancy", "test_1 context_precision"},
+			compared: 1,
+		},
+		{
+			name:     "scoring mode changed",
+			current:  []TestResult{heuristicResult("test_1", "FAIL", 0.2, 0.2)},
+			baseline: []TestResult{judged("test_1", "PASS", 1)},
+			changed:  []string{"test_1"},
+			compared: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompareToBaseline(tt.current, tt.baseline, 0.05)
+			var regressions []string
+			for _, r := range got.Regressions {
+				regressions = append(regressions, r.TestID+" "+r.Metric)
+			}
+			if !reflect.DeepEqual(regressions, tt.want) {
+				t.Errorf("regressions = %v, want %v", regressions, tt.want)
+			}
+			if len(got.NewTests) != len(tt.newTests) || (len(tt.newTests) > 0 && !reflect.DeepEqual(got.NewTests, tt.newTests)) {
+				t.Errorf("NewTests = %v, want %v", got.NewTests, tt.newTests)
+			}
+			if len(got.ScoringChanged) != len(tt.changed) || (len(tt.changed) > 0 && !reflect.DeepEqual(got.ScoringChanged, tt.changed)) {
+				t.Errorf("ScoringChanged = %v, want %v", got.ScoringChanged, tt.changed)
+			}
+			if got.Compared != tt.compared {
+				t.Errorf("Compared = %d, want %d", got.Compared, tt.compared)
+			}
+		})
+	}
+}
+
+func TestCompareToBaseline_CustomMetricsAcrossScoringModes(t *testing.T) {
+	cur := heuristicResult("test_1", "PASS", 1, 1)
+	cur.CustomMetrics = []CustomMetricResult{{Name: "latency_budget", Score: 0.5}}
+	base := TestResult{TestID: "test_1", Status: "PASS", Scoring: ScoringJudge, OverallScore: 1,
+		CustomMetrics: []CustomMetricResult{{Name: "latency_budget", Score: 1}}}
+
+	got := CompareToBaseline([]TestResult{cur}, []TestResult{base}, 0.1)
+	if len(got.Regressions) != 1 || got.Regressions[0].Metric != "latency_budget" {
+		t.Errorf("Regressions = %+v, want only the custom metric, which does not depend on scoring", got.Regressions)
+	}
+}
+
+func TestScoringMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		result TestResult
+		want   string
+	}{
+		{"recorded", TestResult{Scoring: ScoringJudge}, ScoringJudge},
+		{"from details", TestResult{Details: map[string]interface{}{"scoring": ScoringJudge}}, ScoringJudge},
+		{"judgement without a recorded mode", TestResult{Judgement: &JudgeBreakdown{}}, ScoringJudge},
+		{"nothing recorded", TestResult{}, ScoringHeuristic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScoringMode(tt.result); got != tt.want {
+				t.Errorf("ScoringMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterBaseline(t *testing.T) {
+	baseline := []TestResult{{TestID: "test_7a"}, {TestID: "test_7b"}}
+	if got := FilterBaseline(baseline, ""); len(got) != 2 {
+		t.Errorf("FilterBaseline(\"\") = %v, want every result", got)
+	}
+	if got := FilterBaseline(baseline, "7A"); len(got) != 1 || got[0].TestID != "test_7a" {
+		t.Errorf("FilterBaseline(7A) = %v, want test_7a", got)
+	}
+}
+
+func TestLoadBaseline(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, v interface{}) string {
+		t.Helper()
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	results := []TestResult{heuristicResult("test_1", "PASS", 1, 1)}
+	got, err := LoadBaseline(write("ok.json", map[string]interface{}{"results": results}))
+	if err != nil || len(got) != 1 || ScoringMode(got[0]) != ScoringHeuristic {
+		t.Errorf("LoadBaseline() = %+v, %v; want the result with its scoring mode", got, err)
+	}
+
+	if _, err := LoadBaseline(write("empty.json", map[string]interface{}{"results": []TestResult{}})); err == nil {
+		t.Error("LoadBaseline() of a baseline with no results succeeded, want an error")
+	}
+	if _, err := LoadBaseline(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("LoadBaseline() of a missing file succeeded, want an error")
+	}
+}
@@ -0,0 +1,187 @@
+// ABOUTME: Plugin interface for product-specific benchmark metrics
+// ABOUTME: Registered metrics run after the RAGAS scores and fail tests that fall below their threshold
+
+package ragas
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Metric is a custom acceptance check scored from 0.0 to 1.0
+type Metric interface {
+	Name() string
+	Evaluate(scenario TestScenario, response string, context []string) (float64, error)
+}
+
+// MetricCheck pairs a metric with the minimum score a test needs to pass
+type MetricCheck struct {
+	Metric    Metric
+	Threshold float64
+}
+
+// CustomMetricResult is one custom metric's outcome for a test
+type CustomMetricResult struct {
+	Name      string  `json:"name"`
+	Score     float64 `json:"score"`
+	Threshold float64 `json:"threshold"`
+	Passed    bool    `json:"passed"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// runCustomMetrics evaluates every check; a metric that errors counts as failed
+func runCustomMetrics(checks []MetricCheck, scenario TestScenario, response string, context []string) ([]CustomMetricResult, bool) {
+	results := make([]CustomMetricResult, 0, len(checks))
+	allPassed := true
+	for _, check := range checks {
+		result := CustomMetricResult{Name: check.Metric.Name(), Threshold: check.Threshold}
+		score, err := check.Metric.Evaluate(scenario, response, context)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Score = score
+			result.Passed = score >= check.Threshold
+		}
+		allPassed = allPassed && result.Passed
+		results = append(results, result)
+	}
+	return results, allPassed
+}
+
+// MetricFactory builds a metric from an optional argument (e.g. a word limit)
+type MetricFactory func(arg string) (Metric, error)
+
+var (
+	metricFactoriesMu sync.RWMutex
+	metricFactories   = map[string]MetricFactory{
+		"no_forbidden_strings": func(string) (Metric, error) { return NoForbiddenStrings{}, nil },
+		"cites_block_ids":      func(string) (Metric, error) { return CitesBlockIDs{}, nil },
+		"max_words": func(arg string) (Metric, error) {
+			limit, err := strconv.Atoi(arg)
+			if err != nil || limit <= 0 {
+				return nil, fmt.Errorf("max_words needs a positive word limit, e.g. max_words(80)")
+			}
+			return MaxWords{Limit: limit}, nil
+		},
+	}
+)
+
+// RegisterMetricFactory makes a metric available to ParseMetricCheck under name
+func RegisterMetricFactory(name string, factory MetricFactory) {
+	metricFactoriesMu.Lock()
+	defer metricFactoriesMu.Unlock()
+	metricFactories[name] = factory
+}
+
+// MetricNames lists the registered metric names
+func MetricNames() []string {
+	metricFactoriesMu.RLock()
+	defer metricFactoriesMu.RUnlock()
+
+	names := make([]string, 0, len(metricFactories))
+	for name := range metricFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// metricSpec matches "name", "name(arg)", and either with ":threshold"
+var metricSpec = regexp.MustCompile(`^([a-z0-9_]+)(?:\(([^)]*)\))?(?::([0-9.]+))?$`)
+
+// ParseMetricCheck builds a check from a spec like "no_forbidden_strings", "max_words(80)",
+// or "cites_block_ids:0.5"; the threshold defaults to 1.0
+func ParseMetricCheck(spec string) (MetricCheck, error) {
+	m := metricSpec.FindStringSubmatch(strings.TrimSpace(spec))
+	if m == nil {
+		return MetricCheck{}, fmt.Errorf("invalid metric %q (want name, name(arg), optionally :threshold)", spec)
+	}
+
+	metricFactoriesMu.RLock()
+	factory, ok := metricFactories[m[1]]
+	metricFactoriesMu.RUnlock()
+	if !ok {
+		return MetricCheck{}, fmt.Errorf("unknown metric %q (available: %s)", m[1], strings.Join(MetricNames(), ", "))
+	}
+
+	metric, err := factory(m[2])
+	if err != nil {
+		return MetricCheck{}, err
+	}
+
+	threshold := 1.0
+	if m[3] != "" {
+		threshold, err = strconv.ParseFloat(m[3], 64)
+		if err != nil || threshold > 1 {
+			return MetricCheck{}, fmt.Errorf("invalid threshold %q for %s (want 0.0-1.0)", m[3], m[1])
+		}
+	}
+	return MetricCheck{Metric: metric, Threshold: threshold}, nil
+}
+
+// NoForbiddenStrings scores the fraction of the scenario's forbidden strings absent from the response
+type NoForbiddenStrings struct{}
+
+// Name implements Metric
+func (NoForbiddenStrings) Name() string { return "no_forbidden_strings" }
+
+// Evaluate implements Metric
+func (NoForbiddenStrings) Evaluate(scenario TestScenario, response string, _ []string) (float64, error) {
+	forbidden := scenario.GroundTruth.ForbiddenInResponse
+	if len(forbidden) == 0 {
+		return 1.0, nil
+	}
+	found := checkContains(response, forbidden, false)
+	absent := 0
+	for _, c := range found {
+		if c.Passed {
+			absent++
+		}
+	}
+	return float64(absent) / float64(len(forbidden)), nil
+}
+
+// blockIDPattern matches Bridge Block IDs as generated by storage
+var blockIDPattern = regexp.MustCompile(`\bblock_[0-9A-Za-z_]+`)
+
+// CitesBlockIDs scores 1.0 when the response cites a block ID that appears in the retrieved context
+type CitesBlockIDs struct{}
+
+// Name implements Metric
+func (CitesBlockIDs) Name() string { return "cites_block_ids" }
+
+// Evaluate implements Metric
+func (CitesBlockIDs) Evaluate(_ TestScenario, response string, context []string) (float64, error) {
+	cited := blockIDPattern.FindAllString(response, -1)
+	if len(cited) == 0 {
+		return 0, nil
+	}
+	joined := strings.Join(context, "\n")
+	for _, id := range cited {
+		if strings.Contains(joined, id) {
+			return 1.0, nil
+		}
+	}
+	return 0, nil
+}
+
+// MaxWords scores 1.0 for responses of at most Limit words, shrinking proportionally beyond it
+type MaxWords struct {
+	Limit int
+}
+
+// Name implements Metric
+func (m MaxWords) Name() string { return fmt.Sprintf("max_words(%d)", m.Limit) }
+
+// Evaluate implements Metric
+func (m MaxWords) Evaluate(_ TestScenario, response string, _ []string) (float64, error) {
+	words := len(strings.Fields(response))
+	if words <= m.Limit {
+		return 1.0, nil
+	}
+	return float64(m.Limit) / float64(words), nil
+}
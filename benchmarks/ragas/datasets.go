@@ -0,0 +1,423 @@
+// ABOUTME: Adapters for public long-term memory benchmarks (LongMemEval, LoCoMo)
+// ABOUTME: Converts dataset files into TestScenarios and writes predictions in each dataset's format
+
+package ragas
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dataset names accepted by LoadDataset
+const (
+	DatasetLongMemEval = "longmemeval"
+	DatasetLoCoMo      = "locomo"
+)
+
+// LoCoMoPredictionKey is the per-question field LoCoMo's evaluation script reads predictions from
+const LoCoMoPredictionKey = "hmlr_prediction"
+
+// shortAnswerWords is the longest answer also used for the heuristic substring check
+const shortAnswerWords = 4
+
+// LoadDataset converts a dataset file into scenarios; limit > 0 keeps only the first limit questions
+func LoadDataset(name, path string, limit int) ([]TestScenario, error) {
+	var (
+		scenarios []TestScenario
+		err       error
+	)
+	switch strings.ToLower(name) {
+	case DatasetLongMemEval:
+		scenarios, err = LoadLongMemEval(path)
+	case DatasetLoCoMo:
+		scenarios, err = LoadLoCoMo(path)
+	default:
+		return nil, fmt.Errorf("unknown dataset %q (want %s or %s)", name, DatasetLongMemEval, DatasetLoCoMo)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && len(scenarios) > limit {
+		scenarios = scenarios[:limit]
+	}
+	return scenarios, nil
+}
+
+// ExportDatasetPredictions writes results in the named dataset's evaluation format.
+// LoCoMo predictions are written into a copy of the original dataset file at datasetPath.
+func ExportDatasetPredictions(name, datasetPath string, results []TestResult, outputPath string) error {
+	switch strings.ToLower(name) {
+	case DatasetLongMemEval:
+		return ExportLongMemEval(results, outputPath)
+	case DatasetLoCoMo:
+		return ExportLoCoMo(datasetPath, results, outputPath)
+	default:
+		return fmt.Errorf("unknown dataset %q", name)
+	}
+}
+
+// longMemEvalItem is one question from longmemeval_{s,m,oracle}.json
+type longMemEvalItem struct {
+	QuestionID   string          `json:"question_id"`
+	QuestionType string          `json:"question_type"`
+	Question     string          `json:"question"`
+	Answer       json.RawMessage `json:"answer"` // String or number
+	QuestionDate string          `json:"question_date"`
+	SessionDates []string        `json:"haystack_dates"`
+	Sessions     [][]chatMessage `json:"haystack_sessions"`
+}
+
+// chatMessage is one chat message in a LongMemEval haystack session
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// longMemEvalTimeLayout matches dates like "2023/05/20 (Sat) 02:21"
+const longMemEvalTimeLayout = "2006/01/02 (Mon) 15:04"
+
+// LoadLongMemEval converts a LongMemEval file into one scenario per question.
+// Haystack sessions are replayed as recorded turns and the question is the final query.
+func LoadLongMemEval(path string) ([]TestScenario, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read LongMemEval dataset: %w", err)
+	}
+
+	var items []longMemEvalItem
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse LongMemEval dataset %s: %w", path, err)
+	}
+
+	scenarios := make([]TestScenario, 0, len(items))
+	for _, item := range items {
+		var turns []ConversationTurn
+		for i, session := range item.Sessions {
+			var start time.Time
+			if i < len(item.SessionDates) {
+				start = parseDatasetTime(longMemEvalTimeLayout, item.SessionDates[i])
+			}
+			turns = appendPairs(turns, pairMessages(session), start)
+		}
+
+		answer := rawAnswer(item.Answer)
+		scenarios = append(scenarios, datasetScenario(
+			item.QuestionID,
+			fmt.Sprintf("LongMemEval %s", item.QuestionID),
+			item.QuestionType,
+			turns,
+			item.Question,
+			parseDatasetTime(longMemEvalTimeLayout, item.QuestionDate),
+			answer,
+			!strings.HasSuffix(item.QuestionID, "_abs"), // Abstention answers explain why there is no answer
+		))
+	}
+
+	return scenarios, nil
+}
+
+// pairMessages groups a session into (user, assistant) pairs; consecutive messages from one role are joined
+func pairMessages(session []chatMessage) [][2]string {
+	var pairs [][2]string
+	for _, msg := range session {
+		if msg.Role == "user" {
+			if len(pairs) > 0 && pairs[len(pairs)-1][1] == "" {
+				pairs[len(pairs)-1][0] += "\n" + msg.Content
+				continue
+			}
+			pairs = append(pairs, [2]string{msg.Content, ""})
+			continue
+		}
+
+		if len(pairs) == 0 {
+			pairs = append(pairs, [2]string{"", ""})
+		}
+		last := &pairs[len(pairs)-1]
+		if last[1] != "" {
+			last[1] += "\n"
+		}
+		last[1] += msg.Content
+	}
+
+	// Every replayed turn needs a user message and a recorded reply
+	kept := pairs[:0]
+	for _, p := range pairs {
+		if p[0] != "" && p[1] != "" {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// appendPairs adds one recorded turn per pair, a minute apart from the session start
+func appendPairs(turns []ConversationTurn, pairs [][2]string, start time.Time) []ConversationTurn {
+	for i, pair := range pairs {
+		turns = append(turns, ConversationTurn{
+			TurnNumber:  len(turns) + 1,
+			UserMessage: pair[0],
+			AIResponse:  pair[1],
+			Timestamp:   offsetTime(start, i),
+		})
+	}
+	return turns
+}
+
+// ExportLongMemEval writes predictions as JSON lines of {"question_id", "hypothesis"}
+func ExportLongMemEval(results []TestResult, outputPath string) error {
+	var b strings.Builder
+	for _, result := range results {
+		line, err := json.Marshal(map[string]string{
+			"question_id": result.TestID,
+			"hypothesis":  result.Response,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal prediction: %w", err)
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+
+	if err := os.WriteFile(outputPath, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write predictions: %w", err)
+	}
+	return nil
+}
+
+// locomoSample is one conversation from locomo10.json
+type locomoSample struct {
+	SampleID     string                     `json:"sample_id"`
+	Conversation map[string]json.RawMessage `json:"conversation"`
+	QA           []locomoQA                 `json:"qa"`
+}
+
+// locomoQA is one question about a LoCoMo conversation
+type locomoQA struct {
+	Question string          `json:"question"`
+	Answer   json.RawMessage `json:"answer"` // Missing for adversarial questions
+	Category int             `json:"category"`
+}
+
+// locomoDialog is one utterance in a LoCoMo session
+type locomoDialog struct {
+	Speaker     string `json:"speaker"`
+	Text        string `json:"text"`
+	BlipCaption string `json:"blip_caption"`
+}
+
+// locomoTimeLayout matches dates like "1:56 pm on 8 May, 2023"
+const locomoTimeLayout = "3:04 pm on 2 January, 2006"
+
+var locomoSessionKey = regexp.MustCompile(`^session_(\d+)$`)
+
+// LoadLoCoMo converts a LoCoMo file into one scenario per answerable question.
+// Each scenario replays the whole conversation, pairing consecutive utterances into turns.
+// Adversarial questions (no answer) are skipped.
+func LoadLoCoMo(path string) ([]TestScenario, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read LoCoMo dataset: %w", err)
+	}
+
+	var samples []locomoSample
+	if err := json.Unmarshal(raw, &samples); err != nil {
+		return nil, fmt.Errorf("failed to parse LoCoMo dataset %s: %w", path, err)
+	}
+
+	var scenarios []TestScenario
+	for _, sample := range samples {
+		turns, lastTime, err := locomoTurns(sample)
+		if err != nil {
+			return nil, fmt.Errorf("sample %s: %w", sample.SampleID, err)
+		}
+
+		for i, qa := range sample.QA {
+			answer := rawAnswer(qa.Answer)
+			if answer == "" {
+				continue
+			}
+			scenarios = append(scenarios, datasetScenario(
+				locomoScenarioID(sample.SampleID, i),
+				fmt.Sprintf("LoCoMo %s question %d", sample.SampleID, i+1),
+				fmt.Sprintf("category %d", qa.Category),
+				turns,
+				qa.Question,
+				offsetTime(lastTime, 24*60), // Ask a day after the last session
+				answer,
+				true,
+			))
+		}
+	}
+
+	return scenarios, nil
+}
+
+// locomoTurns flattens a LoCoMo conversation into recorded turns, sessions in order
+func locomoTurns(sample locomoSample) ([]ConversationTurn, time.Time, error) {
+	var sessionNumbers []int
+	for key := range sample.Conversation {
+		if m := locomoSessionKey.FindStringSubmatch(key); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			sessionNumbers = append(sessionNumbers, n)
+		}
+	}
+	sort.Ints(sessionNumbers)
+
+	var (
+		turns []ConversationTurn
+		last  time.Time
+	)
+	for _, n := range sessionNumbers {
+		var dialogs []locomoDialog
+		if err := json.Unmarshal(sample.Conversation[fmt.Sprintf("session_%d", n)], &dialogs); err != nil {
+			return nil, time.Time{}, fmt.Errorf("session_%d: %w", n, err)
+		}
+
+		var dateTime string
+		_ = json.Unmarshal(sample.Conversation[fmt.Sprintf("session_%d_date_time", n)], &dateTime)
+		start := parseDatasetTime(locomoTimeLayout, dateTime)
+		if !start.IsZero() {
+			last = start
+		}
+
+		var utterances []string
+		for _, d := range dialogs {
+			text := fmt.Sprintf("%s: %s", d.Speaker, d.Text)
+			if d.BlipCaption != "" {
+				text += fmt.Sprintf(" [shares %s]", d.BlipCaption)
+			}
+			utterances = append(utterances, text)
+		}
+
+		turns = appendPairs(turns, pairUtterances(utterances), start)
+	}
+
+	return turns, last, nil
+}
+
+// pairUtterances pairs consecutive utterances as user message and reply regardless of speaker
+// (names stay in the text); an odd final utterance is appended to the last reply
+func pairUtterances(utterances []string) [][2]string {
+	var pairs [][2]string
+	for i := 0; i+1 < len(utterances); i += 2 {
+		pairs = append(pairs, [2]string{utterances[i], utterances[i+1]})
+	}
+	if len(utterances)%2 == 1 {
+		last := utterances[len(utterances)-1]
+		if len(pairs) == 0 {
+			return [][2]string{{last, "(no reply)"}}
+		}
+		pairs[len(pairs)-1][1] += "\n" + last
+	}
+	return pairs
+}
+
+// locomoScenarioID names the scenario for question index i of a sample
+func locomoScenarioID(sampleID string, i int) string {
+	return fmt.Sprintf("%s_q%d", sampleID, i+1)
+}
+
+// ExportLoCoMo copies the dataset at datasetPath, adding each result's response
+// under LoCoMoPredictionKey on the matching question
+func ExportLoCoMo(datasetPath string, results []TestResult, outputPath string) error {
+	raw, err := os.ReadFile(datasetPath)
+	if err != nil {
+		return fmt.Errorf("failed to read LoCoMo dataset: %w", err)
+	}
+
+	// Decode generically so every field of the original file survives the round trip
+	var samples []map[string]interface{}
+	if err := json.Unmarshal(raw, &samples); err != nil {
+		return fmt.Errorf("failed to parse LoCoMo dataset %s: %w", datasetPath, err)
+	}
+
+	responses := make(map[string]string, len(results))
+	for _, result := range results {
+		responses[result.TestID] = result.Response
+	}
+
+	for _, sample := range samples {
+		sampleID, _ := sample["sample_id"].(string)
+		qas, _ := sample["qa"].([]interface{})
+		for i, item := range qas {
+			qa, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if response, ok := responses[locomoScenarioID(sampleID, i)]; ok {
+				qa[LoCoMoPredictionKey] = response
+			}
+		}
+	}
+
+	jsonData, err := json.MarshalIndent(samples, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal predictions: %w", err)
+	}
+	if err := os.WriteFile(outputPath, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write predictions: %w", err)
+	}
+	return nil
+}
+
+// datasetScenario builds a scenario that replays turns and then asks question
+func datasetScenario(id, name, description string, history []ConversationTurn, question string, askedAt time.Time, answer string, checkAnswer bool) TestScenario {
+	turns := make([]ConversationTurn, len(history), len(history)+1)
+	copy(turns, history)
+	turns = append(turns, ConversationTurn{
+		TurnNumber:  len(turns) + 1,
+		UserMessage: question,
+		Timestamp:   askedAt,
+	})
+
+	gt := GroundTruth{
+		FinalQueryTurn:  len(turns),
+		ReferenceAnswer: answer,
+	}
+	if checkAnswer && len(strings.Fields(answer)) <= shortAnswerWords {
+		gt.ExpectedInResponse = []string{answer}
+	}
+
+	return TestScenario{
+		ID:          id,
+		Name:        name,
+		Description: description,
+		Turns:       turns,
+		GroundTruth: gt,
+	}
+}
+
+// rawAnswer renders a JSON string or number answer as text
+func rawAnswer(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// parseDatasetTime parses value with layout, returning the zero time when it doesn't match
+func parseDatasetTime(layout, value string) time.Time {
+	t, err := time.Parse(layout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// offsetTime spaces turns a minute apart from start; the zero time stays zero (use now)
+func offsetTime(start time.Time, minutes int) time.Time {
+	if start.IsZero() {
+		return start
+	}
+	return start.Add(time.Duration(minutes) * time.Minute)
+}
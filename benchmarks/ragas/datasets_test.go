@@ -0,0 +1,249 @@
+// ABOUTME: Tests for the LongMemEval and LoCoMo dataset adapters
+// ABOUTME: Converts small hand-written dataset files and checks scenarios and exported predictions
+
+package ragas
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeDataset writes content to a file in a temporary directory and returns its path
+func writeDataset(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const longMemEvalFixture = `[
+  {
+    "question_id": "q1",
+    "question_type": "single-session-user",
+    "question": "What is my dog called?",
+    "answer": "Rex",
+    "question_date": "2023/05/21 (Sun) 10:00",
+    "haystack_dates": ["2023/05/20 (Sat) 02:21"],
+    "haystack_sessions": [[
+      {"role": "user", "content": "I got a dog."},
+      {"role": "user", "content": "His name is Rex."},
+      {"role": "assistant", "content": "Congratulations!"},
+      {"role": "user", "content": "Unanswered"}
+    ]]
+  },
+  {
+    "question_id": "q2_abs",
+    "question_type": "abstention",
+    "question": "What is my cat called?",
+    "answer": 42,
+    "question_date": "not a date",
+    "haystack_dates": [],
+    "haystack_sessions": []
+  }
+]`
+
+func TestLoadLongMemEval(t *testing.T) {
+	scenarios, err := LoadLongMemEval(writeDataset(t, "longmemeval.json", longMemEvalFixture))
+	if err != nil {
+		t.Fatalf("LoadLongMemEval() error = %v", err)
+	}
+	if len(scenarios) != 2 {
+		t.Fatalf("got %d scenarios, want 2", len(scenarios))
+	}
+
+	first := scenarios[0]
+	if first.ID != "q1" || len(first.Turns) != 2 {
+		t.Fatalf("first scenario = %s with %d turns, want q1 with one replayed turn and the question", first.ID, len(first.Turns))
+	}
+	replayed := first.Turns[0]
+	if replayed.UserMessage != "I got a dog.\nHis name is Rex." || replayed.AIResponse != "Congratulations!" {
+		t.Errorf("replayed turn = %q / %q, want consecutive user messages joined", replayed.UserMessage, replayed.AIResponse)
+	}
+	if want := time.Date(2023, 5, 20, 2, 21, 0, 0, time.UTC); !replayed.Timestamp.Equal(want) {
+		t.Errorf("replayed turn at %v, want %v", replayed.Timestamp, want)
+	}
+	if err := ValidateScenario(first); err != nil {
+		t.Errorf("converted scenario is invalid: %v", err)
+	}
+	gt := first.GroundTruth
+	if gt.FinalQueryTurn != 2 || gt.ReferenceAnswer != "Rex" || !reflect.DeepEqual(gt.ExpectedInResponse, []string{"Rex"}) {
+		t.Errorf("ground truth = %+v, want the question as turn 2 expecting Rex", gt)
+	}
+
+	abstention := scenarios[1]
+	if abstention.GroundTruth.ReferenceAnswer != "42" {
+		t.Errorf("numeric answer = %q, want 42", abstention.GroundTruth.ReferenceAnswer)
+	}
+	if len(abstention.GroundTruth.ExpectedInResponse) != 0 {
+		t.Errorf("abstention question expects %v in the response, want no substring check", abstention.GroundTruth.ExpectedInResponse)
+	}
+	if !abstention.Turns[0].Timestamp.IsZero() {
+		t.Errorf("unparseable question date gave %v, want the zero time", abstention.Turns[0].Timestamp)
+	}
+}
+
+const locomoFixture = `[
+  {
+    "sample_id": "conv-1",
+    "conversation": {
+      "speaker_a": "Ann",
+      "speaker_b": "Bob",
+      "session_2": [{"speaker": "Ann", "text": "I moved to Lisbon."}],
+      "session_2_date_time": "9:00 am on 9 May, 2023",
+      "session_1": [
+        {"speaker": "Ann", "text": "I adopted a cat."},
+        {"speaker": "Bob", "text": "What is its name?", "blip_caption": "a photo of a cat"},
+        {"speaker": "Ann", "text": "Miso."}
+      ],
+      "session_1_date_time": "1:56 pm on 8 May, 2023"
+    },
+    "qa": [
+      {"question": "What is Ann's cat called?", "answer": "Miso", "category": 1},
+      {"question": "What is Bob's dog called?", "category": 5},
+      {"question": "Where does Ann live now?", "answer": "Lisbon", "category": 2}
+    ]
+  }
+]`
+
+func TestLoadLoCoMo(t *testing.T) {
+	scenarios, err := LoadLoCoMo(writeDataset(t, "locomo.json", locomoFixture))
+	if err != nil {
+		t.Fatalf("LoadLoCoMo() error = %v", err)
+	}
+
+	var ids []string
+	for _, s := range scenarios {
+		ids = append(ids, s.ID)
+	}
+	if want := []string{"conv-1_q1", "conv-1_q3"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("scenario ids = %v, want %v (adversarial question skipped)", ids, want)
+	}
+
+	turns := scenarios[0].Turns
+	if len(turns) != 3 {
+		t.Fatalf("got %d turns, want two sessions of replayed turns plus the question", len(turns))
+	}
+	if turns[0].UserMessage != "Ann: I adopted a cat." || turns[0].AIResponse != "Bob: What is its name? [shares a photo of a cat]\nAnn: Miso." {
+		t.Errorf("session 1 turn = %q / %q", turns[0].UserMessage, turns[0].AIResponse)
+	}
+	if turns[1].UserMessage != "Ann: I moved to Lisbon." || turns[1].AIResponse != "(no reply)" {
+		t.Errorf("session 2 turn = %q / %q", turns[1].UserMessage, turns[1].AIResponse)
+	}
+	if want := time.Date(2023, 5, 10, 9, 0, 0, 0, time.UTC); !turns[2].Timestamp.Equal(want) {
+		t.Errorf("question asked at %v, want a day after the last session (%v)", turns[2].Timestamp, want)
+	}
+}
+
+func TestLoadDataset(t *testing.T) {
+	path := writeDataset(t, "longmemeval.json", longMemEvalFixture)
+
+	scenarios, err := LoadDataset("LongMemEval", path, 1)
+	if err != nil || len(scenarios) != 1 {
+		t.Errorf("LoadDataset() with limit 1 = %d scenarios, %v; want 1", len(scenarios), err)
+	}
+	if _, err := LoadDataset("squad", path, 0); err == nil || !strings.Contains(err.Error(), "unknown dataset") {
+		t.Errorf("LoadDataset(squad) error = %v, want unknown dataset", err)
+	}
+	if _, err := LoadDataset(DatasetLoCoMo, writeDataset(t, "bad.json", "{"), 0); err == nil {
+		t.Error("LoadDataset() of malformed JSON succeeded, want an error")
+	}
+}
+
+func TestExportLongMemEval(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "predictions.jsonl")
+	results := []TestResult{{TestID: "q1", Response: "Rex"}, {TestID: "q2", Response: "I don't know"}}
+	if err := ExportLongMemEval(results, out); err != nil {
+		t.Fatalf("ExportLongMemEval() error = %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d prediction lines, want 2", len(lines))
+	}
+	var first map[string]string
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first["question_id"] != "q1" || first["hypothesis"] != "Rex" {
+		t.Errorf("first prediction = %v", first)
+	}
+}
+
+func TestExportLoCoMo(t *testing.T) {
+	dataset := writeDataset(t, "locomo.json", locomoFixture)
+	out := filepath.Join(t.TempDir(), "predictions.json")
+	results := []TestResult{{TestID: "conv-1_q1", Response: "Miso"}}
+	if err := ExportDatasetPredictions(DatasetLoCoMo, dataset, results, out); err != nil {
+		t.Fatalf("ExportDatasetPredictions() error = %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var samples []struct {
+		Conversation map[string]json.RawMessage `json:"conversation"`
+		QA           []map[string]interface{}   `json:"qa"`
+	}
+	if err := json.Unmarshal(data, &samples); err != nil {
+		t.Fatal(err)
+	}
+	qa := samples[0].QA
+	if qa[0][LoCoMoPredictionKey] != "Miso" {
+		t.Errorf("question 1 prediction = %v, want Miso", qa[0][LoCoMoPredictionKey])
+	}
+	if _, ok := qa[2][LoCoMoPredictionKey]; ok {
+		t.Error("question without a result got a prediction")
+	}
+	if _, ok := samples[0].Conversation["speaker_a"]; !ok {
+		t.Error("original conversation fields were not preserved")
+	}
+}
+
+func TestPairMessages(t *testing.T) {
+	tests := []struct {
+		name    string
+		session []chatMessage
+		want    [][2]string
+	}{
+		{
+			name:    "alternating",
+			session: []chatMessage{{"user", "hi"}, {"assistant", "hello"}, {"user", "bye"}, {"assistant", "ciao"}},
+			want:    [][2]string{{"hi", "hello"}, {"bye", "ciao"}},
+		},
+		{
+			name:    "consecutive replies joined",
+			session: []chatMessage{{"user", "hi"}, {"assistant", "hello"}, {"assistant", "again"}},
+			want:    [][2]string{{"hi", "hello\nagain"}},
+		},
+		{
+			name:    "leading assistant message dropped",
+			session: []chatMessage{{"assistant", "welcome"}, {"user", "hi"}, {"assistant", "hello"}},
+			want:    [][2]string{{"hi", "hello"}},
+		},
+		{
+			name:    "unanswered message dropped",
+			session: []chatMessage{{"user", "hi"}},
+			want:    [][2]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pairMessages(tt.session)
+			if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("pairMessages() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
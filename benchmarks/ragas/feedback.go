@@ -0,0 +1,140 @@
+// ABOUTME: Builds a retrieval corpus from rate_memory feedback stored in a memory database
+// ABOUTME: Rated queries become benchmark queries; thumbs up marks what they should retrieve
+
+package ragas
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// FeedbackScenario turns the ratings in store into a retrieval scenario. Every rated
+// block becomes a conversation seeded from its turns, and every rated fact is seeded
+// with its block's conversation. Each distinct query (compared case-insensitively) is
+// one benchmark query whose relevant conversations and facts are those with a net
+// thumbs up for it; memories rated down stay in the corpus as distractors. Ratings
+// without a query, and facts no longer tied to a block, are skipped.
+func FeedbackScenario(store *storage.Storage) (RetrievalScenario, error) {
+	feedback, err := store.ListFeedback()
+	if err != nil {
+		return RetrievalScenario{}, err
+	}
+
+	scenario := RetrievalScenario{ID: "feedback", Name: "Rated memories"}
+	conversations := make(map[string]int) // block ID -> index in scenario.Conversations
+	factBlocks := make(map[string]string) // fact ID -> block ID, "" when it cannot be seeded
+	addConversation := func(blockID string) (bool, error) {
+		if _, ok := conversations[blockID]; ok {
+			return true, nil
+		}
+		block, err := store.GetBridgeBlock(blockID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get block %s: %w", blockID, err)
+		}
+		if block == nil {
+			return false, nil
+		}
+		conv := SeedConversation{ID: blockID, Topic: block.TopicLabel}
+		for _, turn := range block.Turns {
+			if text := strings.TrimSpace(turn.Text()); text != "" {
+				conv.Messages = append(conv.Messages, text)
+			}
+		}
+		if len(conv.Messages) == 0 {
+			return false, nil
+		}
+		conversations[blockID] = len(scenario.Conversations)
+		scenario.Conversations = append(scenario.Conversations, conv)
+		return true, nil
+	}
+	addFact := func(factID string) (bool, error) {
+		if blockID, ok := factBlocks[factID]; ok {
+			return blockID != "", nil
+		}
+		factBlocks[factID] = ""
+		fact, err := store.GetFactByID(factID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get fact %s: %w", factID, err)
+		}
+		if fact == nil || fact.BlockID == "" {
+			return false, nil
+		}
+		if ok, err := addConversation(fact.BlockID); !ok || err != nil {
+			return false, err
+		}
+		conv := &scenario.Conversations[conversations[fact.BlockID]]
+		conv.Facts = append(conv.Facts, SeedFact{ID: factID, Key: fact.Key, Value: fact.Value})
+		factBlocks[factID] = fact.BlockID
+		return true, nil
+	}
+
+	type ratedQuery struct {
+		query  string
+		blocks map[string]int // net rating per block
+		facts  map[string]int // net rating per fact
+		order  []string       // targets in the order first rated, "block:" or "fact:" prefixed
+	}
+	var queries []*ratedQuery
+	byKey := make(map[string]*ratedQuery)
+	for _, fb := range feedback {
+		key := strings.ToLower(strings.TrimSpace(fb.Query))
+		if key == "" {
+			continue
+		}
+		var seeded bool
+		if fb.BlockID != "" {
+			seeded, err = addConversation(fb.BlockID)
+		} else {
+			seeded, err = addFact(fb.FactID)
+		}
+		if err != nil {
+			return RetrievalScenario{}, err
+		}
+		if !seeded {
+			continue
+		}
+
+		q := byKey[key]
+		if q == nil {
+			q = &ratedQuery{query: strings.TrimSpace(fb.Query), blocks: map[string]int{}, facts: map[string]int{}}
+			byKey[key] = q
+			queries = append(queries, q)
+		}
+		if fb.BlockID != "" {
+			if _, ok := q.blocks[fb.BlockID]; !ok {
+				q.order = append(q.order, "block:"+fb.BlockID)
+			}
+			q.blocks[fb.BlockID] += fb.Rating.Value()
+		} else {
+			if _, ok := q.facts[fb.FactID]; !ok {
+				q.order = append(q.order, "fact:"+fb.FactID)
+			}
+			q.facts[fb.FactID] += fb.Rating.Value()
+		}
+	}
+
+	for _, q := range queries {
+		query := RetrievalQuery{Query: q.query}
+		for _, target := range q.order {
+			if id, ok := strings.CutPrefix(target, "block:"); ok && q.blocks[id] > 0 {
+				query.RelevantConversations = append(query.RelevantConversations, id)
+			} else if id, ok := strings.CutPrefix(target, "fact:"); ok && q.facts[id] > 0 {
+				query.RelevantFacts = append(query.RelevantFacts, id)
+			}
+		}
+		// A query with nothing rated up has no answer to score against
+		if len(query.RelevantConversations)+len(query.RelevantFacts) > 0 {
+			scenario.Queries = append(scenario.Queries, query)
+		}
+	}
+
+	if len(scenario.Queries) == 0 {
+		return RetrievalScenario{}, fmt.Errorf("no memory has been rated up for a query yet (see the rate_memory tool)")
+	}
+	if err := ValidateRetrievalScenario(scenario); err != nil {
+		return RetrievalScenario{}, err
+	}
+	return scenario, nil
+}
@@ -0,0 +1,248 @@
+// ABOUTME: Benchmark run history stored as JSON lines
+// ABOUTME: Records each run's scores, latency, and cost by commit and renders trends as sparklines
+
+package ragas
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// DefaultHistoryPath is where run summaries are appended unless -history says otherwise
+const DefaultHistoryPath = "benchmark_history.jsonl"
+
+// HistoryEntry summarizes one benchmark run
+type HistoryEntry struct {
+	Timestamp        time.Time          `json:"timestamp"`
+	Commit           string             `json:"commit,omitempty"`
+	Suite            string             `json:"suite"` // Which scenarios ran, so trends compare like with like
+	Scoring          string             `json:"scoring"`
+	Tests            int                `json:"tests"`
+	Passed           int                `json:"passed"`
+	MeanOverall      float64            `json:"mean_overall"`
+	MeanFaithfulness float64            `json:"mean_faithfulness"`
+	MeanRecall       float64            `json:"mean_context_recall"`
+	StoreP95Ms       float64            `json:"store_p95_ms"`
+	RetrievalP95Ms   float64            `json:"retrieval_p95_ms"`
+	CostUSD          float64            `json:"cost_usd"`
+	Scores           map[string]float64 `json:"scores"` // Overall score per test ID
+}
+
+// NewHistoryEntry summarizes results for the history file
+func NewHistoryEntry(results []TestResult, suite, commit string) HistoryEntry {
+	perf := SummarizePerformance(results)
+	entry := HistoryEntry{
+		Timestamp:      time.Now().UTC(),
+		Commit:         commit,
+		Suite:          suite,
+		Scoring:        ScoringHeuristic,
+		Tests:          len(results),
+		StoreP95Ms:     perf.Store.P95Ms,
+		RetrievalP95Ms: perf.Retrieval.P95Ms,
+		CostUSD:        perf.System.CostUSD,
+		Scores:         make(map[string]float64, len(results)),
+	}
+
+	for _, result := range results {
+		if result.Status == "PASS" {
+			entry.Passed++
+		}
+		if ScoringMode(result) == ScoringJudge {
+			entry.Scoring = ScoringJudge
+		}
+		entry.MeanOverall += result.OverallScore
+		entry.MeanFaithfulness += result.FaithfulnessScore
+		entry.MeanRecall += result.ContextRecallScore
+		entry.Scores[result.TestID] = result.OverallScore
+	}
+	if n := float64(len(results)); n > 0 {
+		entry.MeanOverall /= n
+		entry.MeanFaithfulness /= n
+		entry.MeanRecall /= n
+	}
+	return entry
+}
+
+// CurrentCommit returns the short hash of HEAD (with "-dirty" for uncommitted changes), or "" outside git
+func CurrentCommit() string {
+	out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	commit := strings.TrimSpace(string(out))
+
+	status, err := exec.Command("git", "status", "--porcelain", "--untracked-files=no").Output()
+	if err == nil && len(strings.TrimSpace(string(status))) > 0 {
+		commit += "-dirty"
+	}
+	return commit
+}
+
+// AppendHistory adds entry as one line at the end of the history file
+func AppendHistory(path string, entry HistoryEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	return f.Close()
+}
+
+// LoadHistory reads every entry in the history file, oldest first
+func LoadHistory(path string) ([]HistoryEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.Before(entries[j].Timestamp) })
+	return entries, nil
+}
+
+// RenderHistory writes score trends for the last limit entries (0 = all) of suite ("" = all suites)
+func RenderHistory(w io.Writer, entries []HistoryEntry, suite string, limit int) {
+	if suite != "" {
+		var filtered []HistoryEntry
+		for _, e := range entries {
+			if e.Suite == suite {
+				filtered = append(filtered, e)
+			}
+		}
+		entries = filtered
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintln(w, "No benchmark history")
+		return
+	}
+
+	pick := func(f func(HistoryEntry) float64) []float64 {
+		values := make([]float64, len(entries))
+		for i, e := range entries {
+			values[i] = f(e)
+		}
+		return values
+	}
+
+	_, _ = fmt.Fprintf(w, "Trends over %d runs (oldest → newest)\n\n", len(entries))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	trends := []struct {
+		name   string
+		values []float64
+		format string
+	}{
+		{"Overall", pick(func(e HistoryEntry) float64 { return e.MeanOverall }), "%.2f"},
+		{"Faithfulness", pick(func(e HistoryEntry) float64 { return e.MeanFaithfulness }), "%.2f"},
+		{"Context recall", pick(func(e HistoryEntry) float64 { return e.MeanRecall }), "%.2f"},
+		{"Pass rate", pick(func(e HistoryEntry) float64 { return float64(e.Passed) / float64(max(e.Tests, 1)) }), "%.2f"},
+		{"Store p95 (ms)", pick(func(e HistoryEntry) float64 { return e.StoreP95Ms }), "%.1f"},
+		{"Cost (USD)", pick(func(e HistoryEntry) float64 { return e.CostUSD }), "%.4f"},
+	}
+	for _, t := range trends {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t"+t.format+" → "+t.format+"\n", t.name, sparkline(t.values), t.values[0], t.values[len(t.values)-1])
+	}
+	_ = tw.Flush()
+
+	// Per-test overall scores, for tests present in the latest run
+	latest := entries[len(entries)-1]
+	ids := make([]string, 0, len(latest.Scores))
+	for id := range latest.Scores {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	if len(ids) > 0 {
+		_, _ = fmt.Fprintln(w, "\nPer test (overall):")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, id := range ids {
+			var values []float64
+			for _, e := range entries {
+				if score, ok := e.Scores[id]; ok {
+					values = append(values, score)
+				}
+			}
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\t%.2f\n", id, sparkline(values), values[len(values)-1])
+		}
+		_ = tw.Flush()
+	}
+
+	_, _ = fmt.Fprintln(w, "\nRuns:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "DATE\tCOMMIT\tSUITE\tSCORING\tPASSED\tOVERALL\tCOST")
+	_, _ = fmt.Fprintln(tw, "----\t------\t-----\t-------\t------\t-------\t----")
+	for _, e := range entries {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d/%d\t%.2f\t$%.4f\n",
+			e.Timestamp.Local().Format("2006-01-02 15:04"), orDash(e.Commit), e.Suite, e.Scoring,
+			e.Passed, e.Tests, e.MeanOverall, e.CostUSD)
+	}
+	_ = tw.Flush()
+}
+
+// sparkBars are the block characters used by sparkline, lowest to highest
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values as a row of bars scaled between their min and max
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		i := len(sparkBars) / 2
+		if hi > lo {
+			i = int((v - lo) / (hi - lo) * float64(len(sparkBars)-1))
+		}
+		b.WriteRune(sparkBars[i])
+	}
+	return b.String()
+}
+
+// orDash returns s, or "-" when s is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
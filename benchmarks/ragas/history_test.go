@@ -0,0 +1,148 @@
+// ABOUTME: Tests for the benchmark run history file
+// ABOUTME: Covers summarizing runs, appending and loading JSON lines, and rendering trends
+
+package ragas
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewHistoryEntry(t *testing.T) {
+	tests := []struct {
+		name        string
+		results     []TestResult
+		wantScoring string
+		wantPassed  int
+		wantOverall float64
+	}{
+		{
+			name:        "heuristic",
+			results:     []TestResult{heuristicResult("test_1", "PASS", 1, 1), heuristicResult("test_2", "FAIL", 0, 0.5)},
+			wantScoring: ScoringHeuristic,
+			wantPassed:  1,
+			wantOverall: 0.625,
+		},
+		{
+			name:        "any judged result marks the run judged",
+			results:     []TestResult{heuristicResult("test_1", "PASS", 1, 1), {TestID: "test_2", Scoring: ScoringJudge}},
+			wantScoring: ScoringJudge,
+			wantPassed:  1,
+			wantOverall: 0.5,
+		},
+		{
+			name:        "no results",
+			wantScoring: ScoringHeuristic,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := NewHistoryEntry(tt.results, "suite", "abc123")
+			if entry.Scoring != tt.wantScoring || entry.Passed != tt.wantPassed || !closeTo(entry.MeanOverall, tt.wantOverall) {
+				t.Errorf("entry = scoring %s, passed %d, overall %v; want %s, %d, %v",
+					entry.Scoring, entry.Passed, entry.MeanOverall, tt.wantScoring, tt.wantPassed, tt.wantOverall)
+			}
+			if entry.Tests != len(tt.results) || len(entry.Scores) != len(tt.results) {
+				t.Errorf("entry counts %d tests with %d scores, want %d", entry.Tests, len(entry.Scores), len(tt.results))
+			}
+		})
+	}
+}
+
+func TestAppendAndLoadHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	now := time.Now().UTC()
+
+	// Appended out of order; LoadHistory returns oldest first
+	for _, entry := range []HistoryEntry{
+		{Timestamp: now, Commit: "second", Suite: "s"},
+		{Timestamp: now.Add(-time.Hour), Commit: "first", Suite: "s"},
+	} {
+		if err := AppendHistory(path, entry); err != nil {
+			t.Fatalf("AppendHistory() error = %v", err)
+		}
+	}
+
+	entries, err := LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Commit != "first" || entries[1].Commit != "second" {
+		t.Errorf("LoadHistory() = %+v, want first then second", entries)
+	}
+}
+
+func TestLoadHistory_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadHistory(filepath.Join(dir, "missing.jsonl")); err == nil {
+		t.Error("LoadHistory() of a missing file succeeded, want an error")
+	}
+
+	path := filepath.Join(dir, "bad.jsonl")
+	if err := os.WriteFile(path, []byte("{\"suite\": \"s\"}\n\nnot json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadHistory(path); err == nil || !strings.Contains(err.Error(), "bad.jsonl:3") {
+		t.Errorf("LoadHistory() error = %v, want the bad line number", err)
+	}
+}
+
+func TestRenderHistory(t *testing.T) {
+	now := time.Now().UTC()
+	entries := []HistoryEntry{
+		{Timestamp: now.Add(-2 * time.Hour), Suite: "other", Tests: 1, MeanOverall: 0.1},
+		{Timestamp: now.Add(-time.Hour), Suite: "s", Tests: 2, Passed: 1, MeanOverall: 0.5, Scores: map[string]float64{"test_1": 0.5}},
+		{Timestamp: now, Suite: "s", Commit: "abc123", Tests: 2, Passed: 2, MeanOverall: 0.9, Scores: map[string]float64{"test_1": 0.9}},
+	}
+
+	tests := []struct {
+		name    string
+		suite   string
+		limit   int
+		want    []string
+		wantNot []string
+	}{
+		{"one suite", "s", 0, []string{"Trends over 2 runs", "0.50 → 0.90", "test_1", "abc123"}, []string{"other"}},
+		{"limited", "", 1, []string{"Trends over 1 runs"}, []string{"other"}},
+		{"unknown suite", "missing", 0, []string{"No benchmark history"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			RenderHistory(&buf, entries, tt.suite, tt.limit)
+			out := buf.String()
+			for _, s := range tt.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output missing %q:\n%s", s, out)
+				}
+			}
+			for _, s := range tt.wantNot {
+				if strings.Contains(out, s) {
+					t.Errorf("output contains %q:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   string
+	}{
+		{nil, ""},
+		{[]float64{0.5, 0.5}, "▅▅"},
+		{[]float64{0, 0.5, 1}, "▁▄█"},
+	}
+	for _, tt := range tests {
+		if got := sparkline(tt.values); got != tt.want {
+			t.Errorf("sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
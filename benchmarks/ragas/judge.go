@@ -0,0 +1,296 @@
+// ABOUTME: LLM-as-judge implementation of the RAGAS metrics
+// ABOUTME: Scores faithfulness, answer relevancy, context precision, and context recall with per-claim verdicts
+
+package ragas
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/harper/remember-standalone/internal/storage/sqlite"
+)
+
+// JudgeModel is the LLM used to grade answers
+type JudgeModel interface {
+	GenerateResponse(prompt string) (string, error)
+	GenerateEmbedding(text string) ([]float64, error)
+}
+
+// ClaimVerdict is the judge's decision about a single statement
+type ClaimVerdict struct {
+	Statement string  `json:"statement"`
+	Verdict   bool    `json:"verdict"`
+	Reason    string  `json:"reason,omitempty"`
+	Score     float64 `json:"score,omitempty"` // Similarity, for answer relevancy
+}
+
+// MetricBreakdown is one metric's score with the verdicts behind it
+type MetricBreakdown struct {
+	Score    float64        `json:"score"`
+	Verdicts []ClaimVerdict `json:"verdicts"`
+}
+
+// JudgeBreakdown holds the per-claim detail for every LLM-judged metric
+type JudgeBreakdown struct {
+	Faithfulness     MetricBreakdown `json:"faithfulness"`      // Answer claims supported by context
+	AnswerRelevancy  MetricBreakdown `json:"answer_relevancy"`  // Questions regenerated from the answer
+	ContextPrecision MetricBreakdown `json:"context_precision"` // Context items useful for the reference
+	ContextRecall    MetricBreakdown `json:"context_recall"`    // Reference statements found in context
+}
+
+// LLMJudge grades responses using RAGAS-style prompts
+type LLMJudge struct {
+	model JudgeModel
+}
+
+// NewLLMJudge creates a judge backed by model
+func NewLLMJudge(model JudgeModel) *LLMJudge {
+	return &LLMJudge{model: model}
+}
+
+// Evaluate runs all four metrics for a question, answer, retrieved context, and reference answer
+func (j *LLMJudge) Evaluate(question, answer string, context []string, reference string) (*JudgeBreakdown, error) {
+	var (
+		breakdown JudgeBreakdown
+		err       error
+	)
+
+	if breakdown.Faithfulness, err = j.Faithfulness(question, answer, context); err != nil {
+		return nil, fmt.Errorf("faithfulness: %w", err)
+	}
+	if breakdown.AnswerRelevancy, err = j.AnswerRelevancy(question, answer); err != nil {
+		return nil, fmt.Errorf("answer relevancy: %w", err)
+	}
+	if breakdown.ContextPrecision, err = j.ContextPrecision(question, context, reference); err != nil {
+		return nil, fmt.Errorf("context precision: %w", err)
+	}
+	if breakdown.ContextRecall, err = j.ContextRecall(question, context, reference); err != nil {
+		return nil, fmt.Errorf("context recall: %w", err)
+	}
+
+	return &breakdown, nil
+}
+
+// judgeVerdict is the JSON shape the judge returns for each statement
+type judgeVerdict struct {
+	Statement string `json:"statement"`
+	Verdict   int    `json:"verdict"`
+	Reason    string `json:"reason"`
+}
+
+// Faithfulness splits the answer into claims and checks each against the context.
+// Score = supported claims / total claims.
+func (j *LLMJudge) Faithfulness(question, answer string, context []string) (MetricBreakdown, error) {
+	var claims []string
+	err := j.ask(fmt.Sprintf(`Given a question and an answer, break the answer into standalone factual statements.
+Rewrite pronouns so each statement is understandable on its own.
+Return ONLY a JSON array of strings.
+
+Question: %s
+Answer: %s`, question, answer), &claims)
+	if err != nil {
+		return MetricBreakdown{}, err
+	}
+	if len(claims) == 0 {
+		// Nothing asserted, nothing unfaithful
+		return MetricBreakdown{Score: 1.0, Verdicts: []ClaimVerdict{}}, nil
+	}
+
+	var verdicts []judgeVerdict
+	err = j.ask(fmt.Sprintf(`Your task is to judge the faithfulness of statements based on a context.
+For each statement, return verdict 1 if it can be directly inferred from the context, or 0 if it cannot.
+Return ONLY a JSON array of objects with keys "statement", "verdict", and "reason", in the same order as the statements.
+
+Context:
+%s
+
+Statements:
+%s`, numbered(context), numbered(claims)), &verdicts)
+	if err != nil {
+		return MetricBreakdown{}, err
+	}
+
+	return scoreVerdicts(claims, verdicts)
+}
+
+// AnswerRelevancy regenerates questions from the answer and compares them with the real question.
+// Score = mean cosine similarity, or 0 when the answer is noncommittal.
+func (j *LLMJudge) AnswerRelevancy(question, answer string) (MetricBreakdown, error) {
+	var generated struct {
+		Questions    []string `json:"questions"`
+		Noncommittal int      `json:"noncommittal"`
+	}
+	err := j.ask(fmt.Sprintf(`Generate 3 different questions that the following answer would be a direct response to.
+Also decide whether the answer is noncommittal (evasive, vague, or "I don't know"): 1 if noncommittal, 0 otherwise.
+Return ONLY a JSON object with keys "questions" (array of strings) and "noncommittal" (0 or 1).
+
+Answer: %s`, answer), &generated)
+	if err != nil {
+		return MetricBreakdown{}, err
+	}
+	if len(generated.Questions) == 0 {
+		return MetricBreakdown{}, fmt.Errorf("judge returned no questions")
+	}
+
+	questionVec, err := j.model.GenerateEmbedding(question)
+	if err != nil {
+		return MetricBreakdown{}, fmt.Errorf("failed to embed question: %w", err)
+	}
+
+	breakdown := MetricBreakdown{Verdicts: make([]ClaimVerdict, 0, len(generated.Questions))}
+	var total float64
+	for _, q := range generated.Questions {
+		vec, err := j.model.GenerateEmbedding(q)
+		if err != nil {
+			return MetricBreakdown{}, fmt.Errorf("failed to embed generated question: %w", err)
+		}
+		similarity := sqlite.CosineSimilarity(questionVec, vec)
+		total += similarity
+		breakdown.Verdicts = append(breakdown.Verdicts, ClaimVerdict{
+			Statement: q,
+			Verdict:   generated.Noncommittal == 0,
+			Score:     similarity,
+		})
+	}
+
+	if generated.Noncommittal == 0 {
+		breakdown.Score = total / float64(len(generated.Questions))
+	}
+	return breakdown, nil
+}
+
+// ContextPrecision checks whether each retrieved item helps reach the reference answer.
+// Score = mean of precision@k over the relevant positions (rewards relevant items ranked first).
+func (j *LLMJudge) ContextPrecision(question string, context []string, reference string) (MetricBreakdown, error) {
+	if len(context) == 0 {
+		return MetricBreakdown{Score: 0, Verdicts: []ClaimVerdict{}}, nil
+	}
+
+	var verdicts []judgeVerdict
+	err := j.ask(fmt.Sprintf(`Given a question, a reference answer, and numbered context items, decide for each item whether it was useful in arriving at the reference answer.
+Return ONLY a JSON array with one object per context item, in order, with keys "statement" (a short summary of the item), "verdict" (1 if useful, 0 if not), and "reason".
+
+Question: %s
+Reference answer: %s
+
+Context items:
+%s`, question, reference, numbered(context)), &verdicts)
+	if err != nil {
+		return MetricBreakdown{}, err
+	}
+	if len(verdicts) != len(context) {
+		return MetricBreakdown{}, fmt.Errorf("judge returned %d verdicts for %d context items", len(verdicts), len(context))
+	}
+
+	breakdown := MetricBreakdown{Verdicts: make([]ClaimVerdict, 0, len(verdicts))}
+	var relevant, precisionSum float64
+	for k, v := range verdicts {
+		useful := v.Verdict == 1
+		if useful {
+			relevant++
+			precisionSum += relevant / float64(k+1)
+		}
+		breakdown.Verdicts = append(breakdown.Verdicts, ClaimVerdict{
+			Statement: context[k],
+			Verdict:   useful,
+			Reason:    v.Reason,
+		})
+	}
+	if relevant > 0 {
+		breakdown.Score = precisionSum / relevant
+	}
+	return breakdown, nil
+}
+
+// ContextRecall splits the reference answer into statements and checks each is attributable to the context.
+// Score = attributed statements / total statements.
+func (j *LLMJudge) ContextRecall(question string, context []string, reference string) (MetricBreakdown, error) {
+	if strings.TrimSpace(reference) == "" {
+		return MetricBreakdown{Score: 1.0, Verdicts: []ClaimVerdict{}}, nil
+	}
+
+	var verdicts []judgeVerdict
+	err := j.ask(fmt.Sprintf(`Given a context and a reference answer, split the reference answer into individual statements.
+For each statement, return verdict 1 if it can be attributed to the context, or 0 if it cannot.
+Return ONLY a JSON array of objects with keys "statement", "verdict", and "reason".
+
+Question: %s
+
+Context:
+%s
+
+Reference answer: %s`, question, numbered(context), reference), &verdicts)
+	if err != nil {
+		return MetricBreakdown{}, err
+	}
+	if len(verdicts) == 0 {
+		return MetricBreakdown{}, fmt.Errorf("judge returned no statements")
+	}
+
+	statements := make([]string, len(verdicts))
+	for i, v := range verdicts {
+		statements[i] = v.Statement
+	}
+	return scoreVerdicts(statements, verdicts)
+}
+
+// scoreVerdicts pairs statements with verdicts and returns the fraction judged true
+func scoreVerdicts(statements []string, verdicts []judgeVerdict) (MetricBreakdown, error) {
+	if len(verdicts) != len(statements) {
+		return MetricBreakdown{}, fmt.Errorf("judge returned %d verdicts for %d statements", len(verdicts), len(statements))
+	}
+
+	breakdown := MetricBreakdown{Verdicts: make([]ClaimVerdict, 0, len(verdicts))}
+	supported := 0
+	for i, v := range verdicts {
+		if v.Verdict == 1 {
+			supported++
+		}
+		breakdown.Verdicts = append(breakdown.Verdicts, ClaimVerdict{
+			Statement: statements[i],
+			Verdict:   v.Verdict == 1,
+			Reason:    v.Reason,
+		})
+	}
+	breakdown.Score = float64(supported) / float64(len(verdicts))
+	return breakdown, nil
+}
+
+// ask sends prompt to the judge and decodes the JSON in its reply into v
+func (j *LLMJudge) ask(prompt string, v interface{}) error {
+	content, err := j.model.GenerateResponse(prompt)
+	if err != nil {
+		return err
+	}
+	if err := parseJudgeJSON(content, v); err != nil {
+		return fmt.Errorf("failed to parse judge response: %w", err)
+	}
+	return nil
+}
+
+// parseJudgeJSON extracts the JSON value from a model reply, tolerating code fences and surrounding prose
+func parseJudgeJSON(content string, v interface{}) error {
+	start := strings.IndexAny(content, "[{")
+	if start < 0 {
+		return fmt.Errorf("no JSON found in %q", content)
+	}
+	closer := byte('}')
+	if content[start] == '[' {
+		closer = ']'
+	}
+	end := strings.LastIndexByte(content, closer)
+	if end < start {
+		return fmt.Errorf("unterminated JSON in %q", content)
+	}
+	return json.Unmarshal([]byte(content[start:end+1]), v)
+}
+
+// numbered renders items as a 1-based numbered list
+func numbered(items []string) string {
+	var b strings.Builder
+	for i, item := range items {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, item)
+	}
+	return b.String()
+}
@@ -0,0 +1,200 @@
+// ABOUTME: Tests for the LLM judge's response parsing and metric scoring
+// ABOUTME: Uses a scripted judge model so no API calls are made
+
+package ragas
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// scriptedJudge replies to prompts in order and embeds text by its first word
+type scriptedJudge struct {
+	replies []string
+	prompts []string
+}
+
+func (s *scriptedJudge) GenerateResponse(prompt string) (string, error) {
+	s.prompts = append(s.prompts, prompt)
+	if len(s.replies) == 0 {
+		return "", errors.New("no scripted reply left")
+	}
+	reply := s.replies[0]
+	s.replies = s.replies[1:]
+	return reply, nil
+}
+
+func (s *scriptedJudge) GenerateEmbedding(text string) ([]float64, error) {
+	if strings.HasPrefix(strings.ToLower(text), "what") {
+		return []float64{1, 0}, nil
+	}
+	return []float64{0, 1}, nil
+}
+
+func TestParseJudgeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{"bare array", `["a", "b"]`, []string{"a", "b"}, false},
+		{"code fence", "```json\n[\"a\"]\n```", []string{"a"}, false},
+		{"surrounding prose", "Here are the claims: [\"a\", \"b\"] Hope that helps.", []string{"a", "b"}, false},
+		{"brackets inside strings", `["a [1]", "b"]`, []string{"a [1]", "b"}, false},
+		{"no JSON", "I cannot answer that.", nil, true},
+		{"unterminated", `["a", "b"`, nil, true},
+		{"wrong shape", `{"claims": ["a"]}`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := parseJudgeJSON(tt.content, &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseJudgeJSON() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseJudgeJSON() error = %v", err)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("parseJudgeJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJudgeJSON_Object(t *testing.T) {
+	var got struct {
+		Questions    []string `json:"questions"`
+		Noncommittal int      `json:"noncommittal"`
+	}
+	content := "Sure!\n{\"questions\": [\"What is it?\"], \"noncommittal\": 1}\n"
+	if err := parseJudgeJSON(content, &got); err != nil {
+		t.Fatalf("parseJudgeJSON() error = %v", err)
+	}
+	if len(got.Questions) != 1 || got.Noncommittal != 1 {
+		t.Errorf("parseJudgeJSON() = %+v", got)
+	}
+}
+
+func TestLLMJudge_Metrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		replies []string
+		run     func(*LLMJudge) (MetricBreakdown, error)
+		want    float64
+		wantErr string
+	}{
+		{
+			name: "faithfulness",
+			replies: []string{
+				`["The key is XYZ789", "It was rotated"]`,
+				`[{"statement": "The key is XYZ789", "verdict": 1}, {"statement": "It was rotated", "verdict": 0, "reason": "not in context"}]`,
+			},
+			run: func(j *LLMJudge) (MetricBreakdown, error) {
+				return j.Faithfulness("What is my key?", "XYZ789, rotated", []string{"key: XYZ789"})
+			},
+			want: 0.5,
+		},
+		{
+			name:    "faithfulness with no claims",
+			replies: []string{`[]`},
+			run: func(j *LLMJudge) (MetricBreakdown, error) {
+				return j.Faithfulness("What is my key?", "Hello", nil)
+			},
+			want: 1,
+		},
+		{
+			name: "faithfulness verdict count mismatch",
+			replies: []string{
+				`["a", "b"]`,
+				`[{"statement": "a", "verdict": 1}]`,
+			},
+			run: func(j *LLMJudge) (MetricBreakdown, error) {
+				return j.Faithfulness("q", "a b", nil)
+			},
+			wantErr: "1 verdicts for 2 statements",
+		},
+		{
+			name:    "answer relevancy",
+			replies: []string{`{"questions": ["What is my key?", "Which key?"], "noncommittal": 0}`},
+			run: func(j *LLMJudge) (MetricBreakdown, error) {
+				return j.AnswerRelevancy("What is my key?", "XYZ789")
+			},
+			want: 0.5,
+		},
+		{
+			name:    "noncommittal answer",
+			replies: []string{`{"questions": ["What is my key?"], "noncommittal": 1}`},
+			run: func(j *LLMJudge) (MetricBreakdown, error) {
+				return j.AnswerRelevancy("What is my key?", "I don't know")
+			},
+			want: 0,
+		},
+		{
+			name:    "context precision rewards relevant items first",
+			replies: []string{`[{"verdict": 0}, {"verdict": 1}]`},
+			run: func(j *LLMJudge) (MetricBreakdown, error) {
+				return j.ContextPrecision("q", []string{"noise", "key: XYZ789"}, "XYZ789")
+			},
+			want: 0.5,
+		},
+		{
+			name:    "context recall",
+			replies: []string{"```json\n[{\"statement\": \"key is XYZ789\", \"verdict\": 1}]\n```"},
+			run: func(j *LLMJudge) (MetricBreakdown, error) {
+				return j.ContextRecall("q", []string{"key: XYZ789"}, "The key is XYZ789")
+			},
+			want: 1,
+		},
+		{
+			name:    "unparseable reply",
+			replies: []string{"I refuse."},
+			run: func(j *LLMJudge) (MetricBreakdown, error) {
+				return j.ContextRecall("q", []string{"c"}, "reference")
+			},
+			wantErr: "failed to parse judge response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.run(NewLLMJudge(&scriptedJudge{replies: tt.replies}))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if !closeTo(got.Score, tt.want) {
+				t.Errorf("score = %v, want %v", got.Score, tt.want)
+			}
+		})
+	}
+}
+
+func TestLLMJudge_EvaluateSkipsCallsWithoutInput(t *testing.T) {
+	model := &scriptedJudge{replies: []string{
+		`[]`, // No claims in the answer
+		`{"questions": ["What?"], "noncommittal": 0}`,
+	}}
+	breakdown, err := NewLLMJudge(model).Evaluate("What?", "Hmm", nil, "")
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if len(model.prompts) != 2 {
+		t.Errorf("judge was asked %d times, want 2 (no context and no reference need no verdicts)", len(model.prompts))
+	}
+	if breakdown.ContextPrecision.Score != 0 || breakdown.ContextRecall.Score != 1 {
+		t.Errorf("precision = %v, recall = %v; want 0 without context and 1 without a reference",
+			breakdown.ContextPrecision.Score, breakdown.ContextRecall.Score)
+	}
+}
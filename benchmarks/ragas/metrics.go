@@ -1,5 +1,5 @@
 // ABOUTME: RAGAS metrics implementation for faithfulness and context recall
-// ABOUTME: Simplified deterministic evaluation based on ground truth comparison
+// ABOUTME: Uses the LLM judge when configured, else deterministic ground truth comparison
 
 package ragas
 
@@ -9,13 +9,32 @@ import (
 )
 
 // MetricsCalculator computes RAGAS scores for benchmark tests
-type MetricsCalculator struct{}
+type MetricsCalculator struct {
+	judge  *LLMJudge // nil = heuristic scoring
+	checks []MetricCheck
+}
+
+// How a TestResult was scored
+const (
+	ScoringJudge     = "llm_judge" // By the LLM judge
+	ScoringHeuristic = "heuristic" // By deterministic heuristics
+)
 
-// NewMetricsCalculator creates a new metrics calculator
+// NewMetricsCalculator creates a metrics calculator using deterministic heuristics
 func NewMetricsCalculator() *MetricsCalculator {
 	return &MetricsCalculator{}
 }
 
+// NewLLMMetricsCalculator creates a metrics calculator that grades with an LLM judge
+func NewLLMMetricsCalculator(model JudgeModel) *MetricsCalculator {
+	return &MetricsCalculator{judge: NewLLMJudge(model)}
+}
+
+// AddMetric registers a custom metric that every test must score at least threshold on
+func (m *MetricsCalculator) AddMetric(metric Metric, threshold float64) {
+	m.checks = append(m.checks, MetricCheck{Metric: metric, Threshold: threshold})
+}
+
 // CalculateFaithfulness computes faithfulness score (0.0-1.0)
 // Faithfulness = Does the response match retrieved context? No hallucinations?
 func (m *MetricsCalculator) CalculateFaithfulness(
@@ -110,49 +129,130 @@ func (m *MetricsCalculator) CalculateContextRecall(
 	)
 }
 
-// EvaluateTest runs full RAGAS evaluation for a test
+// EvaluateTest runs full RAGAS evaluation for a test, then any custom metrics
 func (m *MetricsCalculator) EvaluateTest(
 	scenario TestScenario,
 	finalResponse string,
 	retrievedContext []string,
 ) TestResult {
-	// Calculate faithfulness
+	result := m.evaluateRAGAS(scenario, finalResponse, retrievedContext)
+	if len(m.checks) == 0 {
+		return result
+	}
+
+	custom, passed := runCustomMetrics(m.checks, scenario, finalResponse, retrievedContext)
+	result.CustomMetrics = custom
+	if !passed {
+		result.Status = "FAIL"
+	}
+	return result
+}
+
+// evaluateRAGAS computes the RAGAS scores and pass/fail status
+func (m *MetricsCalculator) evaluateRAGAS(
+	scenario TestScenario,
+	finalResponse string,
+	retrievedContext []string,
+) TestResult {
+	// Ground truth string checks always run: they catch e.g. a superseded
+	// API key that an LLM judge would accept because it appears in context
 	faithfulness, faithfulnessDetail := m.CalculateFaithfulness(
 		finalResponse,
 		scenario.GroundTruth.ExpectedInResponse,
 		scenario.GroundTruth.ForbiddenInResponse,
 	)
-
-	// Calculate context recall
 	recall, recallDetail := m.CalculateContextRecall(
 		retrievedContext,
 		scenario.GroundTruth.ExpectedContextItems,
 	)
 
+	result := TestResult{
+		TestID:   scenario.ID,
+		TestName: scenario.Name,
+		Response: finalResponse,
+		Details: map[string]interface{}{
+			"faithfulness_detail": faithfulnessDetail,
+			"recall_detail":       recallDetail,
+			"final_response":      finalResponse[:min(200, len(finalResponse))],
+			"context_items":       len(retrievedContext),
+		},
+	}
+
+	if m.judge != nil {
+		judgement, err := m.judge.Evaluate(finalQuestion(scenario), finalResponse, retrievedContext, referenceAnswer(scenario))
+		if err == nil {
+			groundTruthOK := faithfulness >= 0.9 && recall >= 0.9
+			result.Judgement = judgement
+			result.FaithfulnessScore = judgement.Faithfulness.Score
+			result.ContextRecallScore = judgement.ContextRecall.Score
+			result.AnswerRelevancyScore = judgement.AnswerRelevancy.Score
+			result.ContextPrecisionScore = judgement.ContextPrecision.Score
+			result.OverallScore = (result.FaithfulnessScore + result.ContextRecallScore +
+				result.AnswerRelevancyScore + result.ContextPrecisionScore) / 4.0
+			result.Scoring = ScoringJudge
+			result.Details["scoring"] = ScoringJudge
+			result.Details["ground_truth_check"] = groundTruthOK
+
+			result.Status = "FAIL"
+			if groundTruthOK && result.FaithfulnessScore >= 0.9 && result.ContextRecallScore >= 0.9 {
+				result.Status = "PASS"
+			}
+			return result
+		}
+		// Judge unavailable: fall back to heuristic scores but record why
+		result.ErrorMessage = fmt.Sprintf("LLM judge failed, using heuristic scores: %v", err)
+	}
+
 	// Calculate overall score
-	overallScore := (faithfulness + recall) / 2.0
+	result.FaithfulnessScore = faithfulness
+	result.ContextRecallScore = recall
+	result.OverallScore = (faithfulness + recall) / 2.0
+	result.Scoring = ScoringHeuristic
+	result.Details["scoring"] = ScoringHeuristic
 
 	// Determine pass/fail status
 	// For production memory system, we require >= 0.9 on both metrics
-	status := "FAIL"
+	result.Status = "FAIL"
 	if faithfulness >= 0.9 && recall >= 0.9 {
-		status = "PASS"
+		result.Status = "PASS"
 	}
 
-	return TestResult{
-		TestID:             scenario.ID,
-		TestName:           scenario.Name,
-		FaithfulnessScore:  faithfulness,
-		ContextRecallScore: recall,
-		OverallScore:       overallScore,
-		Status:             status,
-		Details: map[string]interface{}{
-			"faithfulness_detail": faithfulnessDetail,
-			"recall_detail":       recallDetail,
-			"final_response":      finalResponse[:min(200, len(finalResponse))],
-			"context_items":       len(retrievedContext),
-		},
+	return result
+}
+
+// finalQuestion returns the user message of the scenario's final query turn
+func finalQuestion(scenario TestScenario) string {
+	for _, turn := range scenario.Turns {
+		if turn.TurnNumber == scenario.GroundTruth.FinalQueryTurn {
+			return turn.UserMessage
+		}
+	}
+	return ""
+}
+
+// referenceAnswer returns the scenario's reference answer, deriving one from
+// current (non-superseded) expected facts and context items when none is given
+func referenceAnswer(scenario TestScenario) string {
+	gt := scenario.GroundTruth
+	if gt.ReferenceAnswer != "" {
+		return gt.ReferenceAnswer
+	}
+
+	superseded := make(map[string]bool)
+	for _, fact := range gt.ExpectedFacts {
+		if fact.Supersedes != nil {
+			superseded[fact.Supersedes.Key+"="+fact.Supersedes.Value] = true
+		}
+	}
+
+	var parts []string
+	for _, fact := range gt.ExpectedFacts {
+		if !superseded[fact.Key+"="+fact.Value] {
+			parts = append(parts, fmt.Sprintf("%s is %s.", fact.Key, fact.Value))
+		}
 	}
+	parts = append(parts, gt.ExpectedInResponse...)
+	return strings.Join(parts, " ")
 }
 
 // CalculateVegetarianAwareness is a special metric for Test 7B
@@ -0,0 +1,113 @@
+// ABOUTME: Concurrent benchmark execution with a bounded worker pool
+// ABOUTME: Each worker owns its storage and LLM client; logs are buffered per test and flushed whole
+
+package ragas
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// requestLimiter spaces API requests evenly to stay under a requests-per-minute cap
+type requestLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newRequestLimiter returns a limiter for rpm requests per minute, or nil when rpm <= 0
+func newRequestLimiter(rpm int) *requestLimiter {
+	if rpm <= 0 {
+		return nil
+	}
+	return &requestLimiter{interval: time.Minute / time.Duration(rpm)}
+}
+
+// Wait blocks until the next request slot
+func (l *requestLimiter) Wait() {
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	time.Sleep(time.Until(slot))
+}
+
+// runParallel runs scenarios on r.opts.Parallel workers.
+// After the first failure no new scenarios start; the earliest failing scenario's error is returned.
+func (r *BenchmarkRunner) runParallel(scenarios []TestScenario) ([]TestResult, error) {
+	workers := min(r.opts.Parallel, len(scenarios))
+
+	var (
+		results = make([]TestResult, len(scenarios))
+		errs    = make([]error, len(scenarios))
+		jobs    = make(chan int)
+		outMu   sync.Mutex
+		stopMu  sync.Mutex
+		stopped bool
+		wg      sync.WaitGroup
+	)
+
+	// Each worker gets its own LLM client so usage metering stays per test
+	runners := make([]*BenchmarkRunner, workers)
+	logs := make([]*bytes.Buffer, workers)
+	for i := range runners {
+		logs[i] = &bytes.Buffer{}
+		opts := r.opts
+		opts.Output = logs[i]
+		worker, err := newRunner(r.apiKey, opts, r.limiter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create worker: %w", err)
+		}
+		runners[i] = worker
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker *BenchmarkRunner, log *bytes.Buffer) {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = worker.RunTest(scenarios[i])
+				if errs[i] != nil {
+					stopMu.Lock()
+					stopped = true
+					stopMu.Unlock()
+				}
+
+				// Flush this test's log as one block so output never interleaves
+				outMu.Lock()
+				_, _ = r.out.Write(log.Bytes())
+				if !r.verbose && errs[i] == nil {
+					_, _ = fmt.Fprintf(r.out, "✓ %s finished (%s)\n", scenarios[i].ID, results[i].Status)
+				}
+				outMu.Unlock()
+				log.Reset()
+			}
+		}(runners[w], logs[w])
+	}
+
+	for i := range scenarios {
+		stopMu.Lock()
+		halt := stopped
+		stopMu.Unlock()
+		if halt {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("test %s failed: %w", scenarios[i].ID, err)
+		}
+	}
+	return results, nil
+}
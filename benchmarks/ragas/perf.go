@@ -0,0 +1,253 @@
+// ABOUTME: Latency and cost accounting for benchmark runs
+// ABOUTME: Times each turn's phases and meters LLM calls, tokens, and estimated spend
+
+package ragas
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// modelPrice is the USD price per million prompt and completion tokens
+type modelPrice struct {
+	Prompt     float64
+	Completion float64
+}
+
+// modelPrices are list prices used to estimate spend; unknown models cost 0
+var modelPrices = map[string]modelPrice{
+	"gpt-4o-mini":            {Prompt: 0.15, Completion: 0.60},
+	"gpt-4o":                 {Prompt: 2.50, Completion: 10.00},
+	"gpt-4.1-mini":           {Prompt: 0.40, Completion: 1.60},
+	"gpt-4.1":                {Prompt: 2.00, Completion: 8.00},
+	"text-embedding-3-small": {Prompt: 0.02},
+	"text-embedding-3-large": {Prompt: 0.13},
+}
+
+// EstimateCost returns the estimated USD cost of a call, and whether the model has a known price
+func EstimateCost(model string, promptTokens, completionTokens int) (float64, bool) {
+	price, ok := modelPrices[model]
+	if !ok {
+		return 0, false
+	}
+	return (float64(promptTokens)*price.Prompt + float64(completionTokens)*price.Completion) / 1e6, true
+}
+
+// TurnTiming is how long each phase of one turn took, in milliseconds
+type TurnTiming struct {
+	Turn         int     `json:"turn"`
+	StoreMs      float64 `json:"store_ms"`      // Routing plus StoreTurn/AppendTurnToBlock (includes embedding)
+	RetrievalMs  float64 `json:"retrieval_ms"`  // Context retrieval or hydration; 0 for replayed turns
+	GenerationMs float64 `json:"generation_ms"` // Chat model or mock response; 0 for replayed turns
+	ExtractionMs float64 `json:"extraction_ms"` // Fact extraction
+}
+
+// LatencyStats summarizes a set of latencies in milliseconds
+type LatencyStats struct {
+	Count  int     `json:"count"`
+	MeanMs float64 `json:"mean_ms"`
+	P50Ms  float64 `json:"p50_ms"`
+	P95Ms  float64 `json:"p95_ms"`
+	MaxMs  float64 `json:"max_ms"`
+}
+
+// OperationUsage is the LLM usage for one operation and model
+type OperationUsage struct {
+	Operation        string  `json:"operation"`
+	Model            string  `json:"model"`
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// UsageTotals sums LLM usage across operations
+type UsageTotals struct {
+	Calls            int              `json:"calls"`
+	PromptTokens     int              `json:"prompt_tokens"`
+	CompletionTokens int              `json:"completion_tokens"`
+	CostUSD          float64          `json:"estimated_cost_usd"`
+	Unpriced         []string         `json:"unpriced_models,omitempty"` // Models with no known price (counted as 0)
+	Operations       []OperationUsage `json:"operations,omitempty"`
+}
+
+// PerformanceMetrics is the latency and cost of one scenario, stored in TestResult.Details["performance"].
+// System usage covers the memory pipeline; judge usage is the cost of scoring and kept separate.
+type PerformanceMetrics struct {
+	Turns      []TurnTiming `json:"turns"`
+	Store      LatencyStats `json:"store_latency"`
+	Retrieval  LatencyStats `json:"retrieval_latency"`
+	Generation LatencyStats `json:"generation_latency"`
+	Extraction LatencyStats `json:"extraction_latency"`
+	System     UsageTotals  `json:"llm_usage"`
+	Judge      UsageTotals  `json:"judge_usage"`
+}
+
+// newPerformanceMetrics summarizes turn timings and metered usage
+func newPerformanceMetrics(turns []TurnTiming, system, judge []OperationUsage) *PerformanceMetrics {
+	pick := func(f func(TurnTiming) float64, skipZero bool) LatencyStats {
+		var values []float64
+		for _, t := range turns {
+			if v := f(t); v > 0 || !skipZero {
+				values = append(values, v)
+			}
+		}
+		return latencyStats(values)
+	}
+
+	return &PerformanceMetrics{
+		Turns:      turns,
+		Store:      pick(func(t TurnTiming) float64 { return t.StoreMs }, false),
+		Retrieval:  pick(func(t TurnTiming) float64 { return t.RetrievalMs }, true),
+		Generation: pick(func(t TurnTiming) float64 { return t.GenerationMs }, true),
+		Extraction: pick(func(t TurnTiming) float64 { return t.ExtractionMs }, true),
+		System:     totalUsage(system),
+		Judge:      totalUsage(judge),
+	}
+}
+
+// latencyStats computes mean, median, p95, and max of values
+func latencyStats(values []float64) LatencyStats {
+	if len(values) == 0 {
+		return LatencyStats{}
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	percentile := func(p float64) float64 {
+		return sorted[int(p*float64(len(sorted)-1)+0.5)]
+	}
+
+	return LatencyStats{
+		Count:  len(sorted),
+		MeanMs: sum / float64(len(sorted)),
+		P50Ms:  percentile(0.50),
+		P95Ms:  percentile(0.95),
+		MaxMs:  sorted[len(sorted)-1],
+	}
+}
+
+// totalUsage sums usage across operations, pricing each call
+func totalUsage(ops []OperationUsage) UsageTotals {
+	totals := UsageTotals{Operations: ops}
+	unpriced := make(map[string]bool)
+	for _, op := range ops {
+		totals.Calls += op.Calls
+		totals.PromptTokens += op.PromptTokens
+		totals.CompletionTokens += op.CompletionTokens
+		totals.CostUSD += op.CostUSD
+		if _, ok := modelPrices[op.Model]; !ok && !unpriced[op.Model] {
+			unpriced[op.Model] = true
+			totals.Unpriced = append(totals.Unpriced, op.Model)
+		}
+	}
+	sort.Strings(totals.Unpriced)
+	return totals
+}
+
+// ms converts a duration to fractional milliseconds
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// usageMeter counts LLM calls and tokens per operation; it is the runner's llm.UsageRecorder
+type usageMeter struct {
+	mu    sync.Mutex
+	usage map[[2]string]*OperationUsage
+}
+
+// newUsageMeter creates an empty meter
+func newUsageMeter() *usageMeter {
+	return &usageMeter{usage: make(map[[2]string]*OperationUsage)}
+}
+
+// RecordLLMUsage adds one call's token counts
+func (m *usageMeter) RecordLLMUsage(operation, model string, promptTokens, completionTokens int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := [2]string{operation, model}
+	op, ok := m.usage[key]
+	if !ok {
+		op = &OperationUsage{Operation: operation, Model: model}
+		m.usage[key] = op
+	}
+	cost, _ := EstimateCost(model, promptTokens, completionTokens)
+	op.Calls++
+	op.PromptTokens += promptTokens
+	op.CompletionTokens += completionTokens
+	op.CostUSD += cost
+	return nil
+}
+
+// take returns the usage recorded since the last take, sorted by operation, and resets the meter
+func (m *usageMeter) take() []OperationUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ops := make([]OperationUsage, 0, len(m.usage))
+	for _, op := range m.usage {
+		ops = append(ops, *op)
+	}
+	m.usage = make(map[[2]string]*OperationUsage)
+
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Operation != ops[j].Operation {
+			return ops[i].Operation < ops[j].Operation
+		}
+		return ops[i].Model < ops[j].Model
+	})
+	return ops
+}
+
+// RunPerformance aggregates latency and usage across a run for the exported summary
+type RunPerformance struct {
+	Store      LatencyStats `json:"store_latency"`
+	Retrieval  LatencyStats `json:"retrieval_latency"`
+	Generation LatencyStats `json:"generation_latency"`
+	System     UsageTotals  `json:"llm_usage"`
+	Judge      UsageTotals  `json:"judge_usage"`
+}
+
+// SummarizePerformance combines the performance metrics of every result
+func SummarizePerformance(results []TestResult) RunPerformance {
+	var (
+		store, retrieval, generation []float64
+		system, judge                []OperationUsage
+	)
+	for _, result := range results {
+		perf, ok := result.Details["performance"].(*PerformanceMetrics)
+		if !ok {
+			continue
+		}
+		for _, t := range perf.Turns {
+			store = append(store, t.StoreMs)
+			if t.RetrievalMs > 0 {
+				retrieval = append(retrieval, t.RetrievalMs)
+			}
+			if t.GenerationMs > 0 {
+				generation = append(generation, t.GenerationMs)
+			}
+		}
+		system = append(system, perf.System.Operations...)
+		judge = append(judge, perf.Judge.Operations...)
+	}
+
+	summary := RunPerformance{
+		Store:      latencyStats(store),
+		Retrieval:  latencyStats(retrieval),
+		Generation: latencyStats(generation),
+		System:     totalUsage(system),
+		Judge:      totalUsage(judge),
+	}
+	// Per-operation detail lives on each result; the run summary keeps totals only
+	summary.System.Operations = nil
+	summary.Judge.Operations = nil
+	return summary
+}
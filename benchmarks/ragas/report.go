@@ -0,0 +1,341 @@
+// ABOUTME: Self-contained HTML report for benchmark runs
+// ABOUTME: Renders score tables, transcripts, retrieved context, and highlighted failures
+
+package ragas
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxDiffCells bounds the word diff's LCS table; longer texts are shown without a diff
+const maxDiffCells = 250000
+
+// DiffSegment is a run of words in a word-level diff
+type DiffSegment struct {
+	Text string
+	Op   string // "eq", "del" (only in expected), or "ins" (only in actual)
+}
+
+// reportCheck is one ground truth string check
+type reportCheck struct {
+	Item   string
+	Passed bool
+}
+
+// reportTest is everything the template shows for one test
+type reportTest struct {
+	Result       TestResult
+	Scenario     *TestScenario
+	Baseline     *TestResult
+	Delta        float64
+	Response     template.HTML
+	Reference    string
+	Diff         []DiffSegment
+	Expected     []reportCheck
+	Forbidden    []reportCheck
+	ContextItems []reportCheck
+	Perf         *PerformanceMetrics
+}
+
+// reportData is the template's root value
+type reportData struct {
+	Generated string
+	Passed    int
+	Failed    int
+	Mean      float64
+	Tests     []reportTest
+}
+
+// WriteHTMLReport writes a self-contained HTML report to path.
+// scenarios supply ground truth for highlighting; baseline (optional) adds score deltas.
+func WriteHTMLReport(path string, scenarios []TestScenario, results, baseline []TestResult) error {
+	byID := make(map[string]*TestScenario, len(scenarios))
+	for i := range scenarios {
+		byID[scenarios[i].ID] = &scenarios[i]
+	}
+	baseByID := make(map[string]*TestResult, len(baseline))
+	for i := range baseline {
+		baseByID[baseline[i].TestID] = &baseline[i]
+	}
+
+	data := reportData{Generated: time.Now().Format(time.RFC1123)}
+	for _, result := range results {
+		test := reportTest{
+			Result:   result,
+			Scenario: byID[result.TestID],
+			Baseline: baseByID[result.TestID],
+			Response: template.HTML(template.HTMLEscapeString(result.Response)),
+		}
+		if test.Baseline != nil {
+			test.Delta = result.OverallScore - test.Baseline.OverallScore
+		}
+		if perf, ok := result.Details["performance"].(*PerformanceMetrics); ok {
+			test.Perf = perf
+		}
+
+		if s := test.Scenario; s != nil {
+			gt := s.GroundTruth
+			context := strings.Join(turnContext(result, gt.FinalQueryTurn), " ")
+			test.Expected = checkContains(result.Response, gt.ExpectedInResponse, true)
+			test.Forbidden = checkContains(result.Response, gt.ForbiddenInResponse, false)
+			test.ContextItems = checkContains(context, gt.ExpectedContextItems, true)
+			test.Response = highlight(result.Response, gt.ExpectedInResponse, gt.ForbiddenInResponse)
+			test.Reference = referenceAnswer(*s)
+			if result.Status != "PASS" && test.Reference != "" {
+				test.Diff = wordDiff(test.Reference, result.Response)
+			}
+		}
+
+		if result.Status == "PASS" {
+			data.Passed++
+		} else {
+			data.Failed++
+		}
+		data.Mean += result.OverallScore
+		data.Tests = append(data.Tests, test)
+	}
+	if len(results) > 0 {
+		data.Mean /= float64(len(results))
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	if err := reportTemplate.Execute(f, data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return f.Close()
+}
+
+// turnContext returns the context the given turn was answered from
+func turnContext(result TestResult, turn int) []string {
+	for _, t := range result.Transcript {
+		if t.Turn == turn {
+			return t.Context
+		}
+	}
+	return nil
+}
+
+// checkContains reports, for each item, whether its presence in text matches want
+func checkContains(text string, items []string, want bool) []reportCheck {
+	upper := strings.ToUpper(text)
+	checks := make([]reportCheck, 0, len(items))
+	for _, item := range items {
+		found := strings.Contains(upper, strings.ToUpper(item))
+		checks = append(checks, reportCheck{Item: item, Passed: found == want})
+	}
+	return checks
+}
+
+// highlight escapes text and marks case-insensitive occurrences of good and bad strings
+func highlight(text string, good, bad []string) template.HTML {
+	type mark struct {
+		start, end int
+		class      string
+	}
+
+	lower := strings.ToLower(text)
+	var marks []mark
+	for _, group := range []struct {
+		items []string
+		class string
+	}{{bad, "bad"}, {good, "good"}} {
+		for _, item := range group.items {
+			needle := strings.ToLower(item)
+			if needle == "" {
+				continue
+			}
+			for from := 0; ; {
+				i := strings.Index(lower[from:], needle)
+				if i < 0 {
+					break
+				}
+				start := from + i
+				marks = append(marks, mark{start, start + len(needle), group.class})
+				from = start + len(needle)
+			}
+		}
+	}
+	sort.SliceStable(marks, func(i, j int) bool { return marks[i].start < marks[j].start })
+
+	var b strings.Builder
+	pos := 0
+	for _, m := range marks {
+		if m.start < pos {
+			continue // Overlaps an earlier mark; bad marks sort first on ties
+		}
+		b.WriteString(template.HTMLEscapeString(text[pos:m.start]))
+		fmt.Fprintf(&b, `<mark class="%s">%s</mark>`, m.class, template.HTMLEscapeString(text[m.start:m.end]))
+		pos = m.end
+	}
+	b.WriteString(template.HTMLEscapeString(text[pos:]))
+	return template.HTML(b.String())
+}
+
+// wordDiff computes a word-level diff from expected to actual (case-insensitive match, actual's spelling kept)
+func wordDiff(expected, actual string) []DiffSegment {
+	a, b := strings.Fields(expected), strings.Fields(actual)
+	if len(a)*len(b) > maxDiffCells {
+		return nil
+	}
+
+	// lcs[i][j] = longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if strings.EqualFold(a[i], b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var segments []DiffSegment
+	emit := func(op, word string) {
+		if n := len(segments); n > 0 && segments[n-1].Op == op {
+			segments[n-1].Text += " " + word
+			return
+		}
+		segments = append(segments, DiffSegment{Text: word, Op: op})
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case strings.EqualFold(a[i], b[j]):
+			emit("eq", b[j])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			emit("del", a[i])
+			i++
+		default:
+			emit("ins", b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		emit("del", a[i])
+	}
+	for ; j < len(b); j++ {
+		emit("ins", b[j])
+	}
+	return segments
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"score": func(f float64) string { return fmt.Sprintf("%.2f", f) },
+	"delta": func(f float64) string { return fmt.Sprintf("%+.2f", f) },
+	"ms":    func(f float64) string { return fmt.Sprintf("%.1fms", f) },
+	"usd":   func(f float64) string { return fmt.Sprintf("$%.4f", f) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>HMLR Benchmark Report</title>
+<style>
+body { font: 14px/1.5 -apple-system, "Segoe UI", sans-serif; margin: 2em auto; max-width: 1100px; padding: 0 1em; color: #222; }
+h1 { margin-bottom: 0; }
+table { border-collapse: collapse; width: 100%; margin: 1em 0; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f5f5f5; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+tr.FAIL td:first-child, .FAIL > summary { border-left: 4px solid #c62828; }
+tr.PASS td:first-child, .PASS > summary { border-left: 4px solid #2e7d32; }
+.status-PASS { color: #2e7d32; font-weight: bold; }
+.status-FAIL { color: #c62828; font-weight: bold; }
+section > details { border: 1px solid #ddd; margin: 1em 0; padding: 0 1em 1em; }
+section > details > summary { font-size: 1.1em; padding: .5em; margin: 0 -1em; cursor: pointer; }
+mark.good, .ok { background: #c8e6c9; }
+mark.bad, .no { background: #ffcdd2; }
+.diff .del { background: #ffcdd2; text-decoration: line-through; }
+.diff .ins { background: #c8e6c9; }
+.turn { border-top: 1px dashed #ddd; padding: .5em 0; }
+.user { color: #1565c0; }
+.ai { white-space: pre-wrap; }
+.replayed { color: #888; font-size: .9em; }
+.muted { color: #777; }
+pre { white-space: pre-wrap; background: #fafafa; padding: .5em; margin: .25em 0; }
+</style>
+</head>
+<body>
+<h1>HMLR Benchmark Report</h1>
+<p class="muted">Generated {{.Generated}} · {{.Passed}} passed · {{.Failed}} failed · mean overall {{score .Mean}}</p>
+
+<table>
+<tr><th>Test</th><th>Status</th><th>Faithfulness</th><th>Context Recall</th><th>Answer Relevancy</th><th>Context Precision</th><th>Overall</th><th>Δ Baseline</th><th>Store p95</th><th>LLM Cost</th></tr>
+{{range .Tests}}<tr class="{{.Result.Status}}">
+<td><a href="#{{.Result.TestID}}">{{.Result.TestID}}</a></td>
+<td class="status-{{.Result.Status}}">{{.Result.Status}}</td>
+<td class="num">{{score .Result.FaithfulnessScore}}</td>
+<td class="num">{{score .Result.ContextRecallScore}}</td>
+<td class="num">{{if .Result.Judgement}}{{score .Result.AnswerRelevancyScore}}{{else}}–{{end}}</td>
+<td class="num">{{if .Result.Judgement}}{{score .Result.ContextPrecisionScore}}{{else}}–{{end}}</td>
+<td class="num">{{score .Result.OverallScore}}</td>
+<td class="num">{{if .Baseline}}{{delta .Delta}}{{else}}–{{end}}</td>
+<td class="num">{{with .Perf}}{{ms .Store.P95Ms}}{{else}}–{{end}}</td>
+<td class="num">{{with .Perf}}{{usd .System.CostUSD}}{{else}}–{{end}}</td>
+</tr>
+{{end}}</table>
+
+{{range .Tests}}<section id="{{.Result.TestID}}">
+<details class="{{.Result.Status}}"{{if ne .Result.Status "PASS"}} open{{end}}>
+<summary><strong>{{.Result.TestID}}</strong> {{.Result.TestName}} — <span class="status-{{.Result.Status}}">{{.Result.Status}}</span></summary>
+{{with .Scenario}}{{if .Description}}<p class="muted">{{.Description}}</p>{{end}}{{end}}
+{{if .Result.ErrorMessage}}<p class="no">{{.Result.ErrorMessage}}</p>{{end}}
+
+<h3>Final response</h3>
+<pre>{{.Response}}</pre>
+{{if .Diff}}<h4>Reference answer → response</h4>
+<pre class="diff">{{range .Diff}}<span class="{{.Op}}">{{.Text}}</span> {{end}}</pre>
+{{else if .Reference}}<p class="muted">Reference answer: {{.Reference}}</p>{{end}}
+
+{{if or .Expected .Forbidden .ContextItems}}<h3>Ground truth checks</h3>
+<ul>
+{{range .Expected}}<li class="{{if .Passed}}ok{{else}}no{{end}}">{{if .Passed}}✓{{else}}✗{{end}} response contains “{{.Item}}”</li>{{end}}
+{{range .Forbidden}}<li class="{{if .Passed}}ok{{else}}no{{end}}">{{if .Passed}}✓{{else}}✗{{end}} response omits “{{.Item}}”</li>{{end}}
+{{range .ContextItems}}<li class="{{if .Passed}}ok{{else}}no{{end}}">{{if .Passed}}✓{{else}}✗{{end}} context contains “{{.Item}}”</li>{{end}}
+</ul>{{end}}
+
+{{with .Result.CustomMetrics}}<h3>Custom metrics</h3>
+<table>
+<tr><th>Metric</th><th>Score</th><th>Threshold</th><th>Result</th></tr>
+{{range .}}<tr class="{{if .Passed}}ok{{else}}no{{end}}"><td>{{.Name}}</td><td class="num">{{score .Score}}</td><td class="num">{{score .Threshold}}</td><td>{{if .Error}}error: {{.Error}}{{else if .Passed}}pass{{else}}fail{{end}}</td></tr>{{end}}
+</table>{{end}}
+
+{{with .Result.Judgement}}<h3>Judge verdicts</h3>
+<table>
+<tr><th>Metric</th><th>Statement</th><th>Verdict</th><th>Reason</th></tr>
+{{range .Faithfulness.Verdicts}}<tr class="{{if .Verdict}}ok{{else}}no{{end}}"><td>faithfulness</td><td>{{.Statement}}</td><td>{{if .Verdict}}supported{{else}}unsupported{{end}}</td><td>{{.Reason}}</td></tr>{{end}}
+{{range .ContextRecall.Verdicts}}<tr class="{{if .Verdict}}ok{{else}}no{{end}}"><td>context recall</td><td>{{.Statement}}</td><td>{{if .Verdict}}attributed{{else}}missing{{end}}</td><td>{{.Reason}}</td></tr>{{end}}
+{{range .ContextPrecision.Verdicts}}<tr class="{{if .Verdict}}ok{{else}}no{{end}}"><td>context precision</td><td>{{.Statement}}</td><td>{{if .Verdict}}useful{{else}}not useful{{end}}</td><td>{{.Reason}}</td></tr>{{end}}
+{{range .AnswerRelevancy.Verdicts}}<tr><td>answer relevancy</td><td>{{.Statement}}</td><td>{{score .Score}}</td><td></td></tr>{{end}}
+</table>{{end}}
+
+<h3>Transcript</h3>
+{{range .Result.Transcript}}<div class="turn">
+<div class="user"><strong>[{{.Turn}}] User:</strong> {{.User}}</div>
+<div class="ai"><strong>AI:</strong> {{.Response}}{{if .Replayed}} <span class="replayed">(replayed)</span>{{end}}</div>
+{{if .Context}}<details><summary class="muted">Retrieved context ({{len .Context}} items)</summary>
+{{range .Context}}<pre>{{.}}</pre>{{end}}
+</details>{{end}}
+</div>
+{{else}}<p class="muted">No transcript recorded.</p>{{end}}
+</details>
+</section>
+{{end}}
+</body>
+</html>
+`))
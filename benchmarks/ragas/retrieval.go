@@ -0,0 +1,388 @@
+// ABOUTME: Retrieval-only benchmark mode measuring SearchMemory and SearchFacts directly
+// ABOUTME: Seeds a corpus of conversations and scores precision@k, recall@k, and MRR per query
+
+package ragas
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// RetrievalScenario is a corpus of seeded conversations plus queries with known answers
+type RetrievalScenario struct {
+	ID            string             `yaml:"id" json:"id"`
+	Name          string             `yaml:"name" json:"name"`
+	Conversations []SeedConversation `yaml:"conversations" json:"conversations"`
+	Queries       []RetrievalQuery   `yaml:"queries" json:"queries"`
+}
+
+// SeedConversation becomes one Bridge Block; ID is the label queries refer to
+type SeedConversation struct {
+	ID       string     `yaml:"id" json:"id"`
+	Topic    string     `yaml:"topic" json:"topic"`
+	Messages []string   `yaml:"messages" json:"messages"`
+	Facts    []SeedFact `yaml:"facts,omitempty" json:"facts,omitempty"`
+}
+
+// SeedFact is a fact stored alongside a seeded conversation
+type SeedFact struct {
+	ID    string `yaml:"id" json:"id"`
+	Key   string `yaml:"key" json:"key"`
+	Value string `yaml:"value" json:"value"`
+}
+
+// RetrievalQuery is a search with the conversations and facts that should come back
+type RetrievalQuery struct {
+	Query                 string   `yaml:"query" json:"query"`
+	RelevantConversations []string `yaml:"relevant_conversations,omitempty" json:"relevant_conversations,omitempty"`
+	RelevantFacts         []string `yaml:"relevant_facts,omitempty" json:"relevant_facts,omitempty"`
+}
+
+// RankingScores are precision@k, recall@k, and reciprocal rank for one ranked list
+type RankingScores struct {
+	PrecisionAtK float64 `json:"precision_at_k"`
+	RecallAtK    float64 `json:"recall_at_k"`
+	MRR          float64 `json:"mrr"`
+}
+
+// QueryResult holds what one query retrieved and how it scored
+type QueryResult struct {
+	Query           string        `json:"query"`
+	Conversations   []string      `json:"retrieved_conversations"`
+	Facts           []string      `json:"retrieved_facts"`
+	BlockScores     RankingScores `json:"block_scores"`
+	FactScores      RankingScores `json:"fact_scores"`
+	HasBlockTargets bool          `json:"has_block_targets"`
+	HasFactTargets  bool          `json:"has_fact_targets"`
+}
+
+// RetrievalResult aggregates retrieval quality over all queries of a scenario.
+// Block and fact averages only include queries that name relevant blocks or facts.
+type RetrievalResult struct {
+	ScenarioID string        `json:"scenario_id"`
+	K          int           `json:"k"`
+	Blocks     RankingScores `json:"blocks"`
+	Facts      RankingScores `json:"facts"`
+	Queries    []QueryResult `json:"queries"`
+}
+
+// LoadRetrievalScenario reads a retrieval corpus from a YAML or JSON file
+func LoadRetrievalScenario(path string) (RetrievalScenario, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return RetrievalScenario{}, fmt.Errorf("failed to read retrieval scenario: %w", err)
+	}
+
+	var scenario RetrievalScenario
+	if err := yaml.Unmarshal(raw, &scenario); err != nil {
+		return RetrievalScenario{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := ValidateRetrievalScenario(scenario); err != nil {
+		return RetrievalScenario{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return scenario, nil
+}
+
+// ValidateRetrievalScenario checks that every query target refers to a seeded conversation or fact
+func ValidateRetrievalScenario(s RetrievalScenario) error {
+	if s.ID == "" {
+		return fmt.Errorf("retrieval scenario id is required")
+	}
+
+	conversations := make(map[string]bool)
+	facts := make(map[string]bool)
+	for _, c := range s.Conversations {
+		if c.ID == "" || len(c.Messages) == 0 {
+			return fmt.Errorf("conversation %q needs an id and at least one message", c.ID)
+		}
+		if conversations[c.ID] {
+			return fmt.Errorf("duplicate conversation id %q", c.ID)
+		}
+		conversations[c.ID] = true
+		for _, f := range c.Facts {
+			if f.ID == "" || facts[f.ID] {
+				return fmt.Errorf("fact ids must be unique and non-empty (conversation %q)", c.ID)
+			}
+			facts[f.ID] = true
+		}
+	}
+
+	if len(s.Queries) == 0 {
+		return fmt.Errorf("at least one query is required")
+	}
+	for i, q := range s.Queries {
+		if q.Query == "" {
+			return fmt.Errorf("queries[%d]: query is required", i)
+		}
+		for _, id := range q.RelevantConversations {
+			if !conversations[id] {
+				return fmt.Errorf("queries[%d]: unknown conversation %q", i, id)
+			}
+		}
+		for _, id := range q.RelevantFacts {
+			if !facts[id] {
+				return fmt.Errorf("queries[%d]: unknown fact %q", i, id)
+			}
+		}
+	}
+	return nil
+}
+
+// RunRetrieval seeds the scenario's corpus into fresh storage and scores each query's top k results
+func (r *BenchmarkRunner) RunRetrieval(scenario RetrievalScenario, k int) (RetrievalResult, error) {
+	if k <= 0 {
+		return RetrievalResult{}, fmt.Errorf("k must be positive, got %d", k)
+	}
+
+	store, err := r.newStorage(scenario.ID)
+	if err != nil {
+		return RetrievalResult{}, fmt.Errorf("failed to create test storage: %w", err)
+	}
+	r.attachStorage(store)
+	defer r.Close()
+	r.seedScenario(scenario.ID)
+
+	// Seed: each conversation becomes its own block
+	blockToConversation := make(map[string]string)
+	base := time.Now().Add(-time.Duration(len(scenario.Conversations)) * time.Hour)
+	if r.seed != 0 {
+		base = seededEpoch
+	}
+	for i, conv := range scenario.Conversations {
+		blockID, err := r.seedConversation(conv, base.Add(time.Duration(i)*time.Hour))
+		if err != nil {
+			return RetrievalResult{}, fmt.Errorf("failed to seed conversation %s: %w", conv.ID, err)
+		}
+		blockToConversation[blockID] = conv.ID
+	}
+
+	result := RetrievalResult{ScenarioID: scenario.ID, K: k}
+	var blockQueries, factQueries int
+
+	for _, q := range scenario.Queries {
+		qr := QueryResult{
+			Query:           q.Query,
+			Conversations:   []string{},
+			Facts:           []string{},
+			HasBlockTargets: len(q.RelevantConversations) > 0,
+			HasFactTargets:  len(q.RelevantFacts) > 0,
+		}
+
+		memories, err := r.storage.SearchMemory(q.Query, k)
+		if err != nil {
+			return RetrievalResult{}, fmt.Errorf("search failed for %q: %w", q.Query, err)
+		}
+		for _, mem := range memories {
+			qr.Conversations = append(qr.Conversations, blockToConversation[mem.BlockID])
+		}
+
+		facts, err := r.storage.SearchFacts(q.Query, k)
+		if err != nil {
+			return RetrievalResult{}, fmt.Errorf("fact search failed for %q: %w", q.Query, err)
+		}
+		for _, fact := range facts {
+			qr.Facts = append(qr.Facts, fact.FactID)
+		}
+
+		if qr.HasBlockTargets {
+			qr.BlockScores = scoreRanking(qr.Conversations, q.RelevantConversations, k)
+			addScores(&result.Blocks, qr.BlockScores)
+			blockQueries++
+		}
+		if qr.HasFactTargets {
+			qr.FactScores = scoreRanking(qr.Facts, q.RelevantFacts, k)
+			addScores(&result.Facts, qr.FactScores)
+			factQueries++
+		}
+
+		if r.verbose {
+			fmt.Fprintf(r.out, "[Query] %s\n  blocks: %v (P@%d %.2f, R@%d %.2f, RR %.2f)\n  facts: %v\n",
+				q.Query, qr.Conversations, k, qr.BlockScores.PrecisionAtK, k, qr.BlockScores.RecallAtK, qr.BlockScores.MRR, qr.Facts)
+		}
+
+		result.Queries = append(result.Queries, qr)
+	}
+
+	averageScores(&result.Blocks, blockQueries)
+	averageScores(&result.Facts, factQueries)
+
+	return result, nil
+}
+
+// ExportRetrievalResults writes a retrieval benchmark result to JSON
+func (r *BenchmarkRunner) ExportRetrievalResults(result RetrievalResult, outputPath string) error {
+	summary := map[string]interface{}{
+		"mode":   "retrieval",
+		"result": result,
+	}
+	r.stampSummary(summary)
+
+	jsonData, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal results: %w", err)
+	}
+
+	if err := os.WriteFile(outputPath, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write results file: %w", err)
+	}
+
+	fmt.Fprintf(r.out, "✓ Results exported to: %s\n", outputPath)
+	return nil
+}
+
+// seedConversation stores a conversation as one block with its facts
+func (r *BenchmarkRunner) seedConversation(conv SeedConversation, start time.Time) (string, error) {
+	var blockID string
+	for i, message := range conv.Messages {
+		turn := &models.Turn{
+			TurnID:      fmt.Sprintf("turn_%s_%d", conv.ID, i+1),
+			Timestamp:   start.Add(time.Duration(i) * time.Minute),
+			UserMessage: message,
+			Keywords:    extractKeywords(message),
+			Topics:      []string{conv.Topic},
+		}
+
+		var err error
+		if i == 0 {
+			blockID, err = r.storage.StoreTurn(turn)
+		} else {
+			err = r.storage.AppendTurnToBlock(blockID, turn)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	for _, f := range conv.Facts {
+		fact := &models.Fact{
+			FactID:     f.ID,
+			BlockID:    blockID,
+			Key:        f.Key,
+			Value:      f.Value,
+			Confidence: 1.0,
+			CreatedAt:  start,
+		}
+		if err := r.storage.SaveFact(fact); err != nil {
+			return "", err
+		}
+	}
+
+	return blockID, nil
+}
+
+// scoreRanking computes precision@k, recall@k, and reciprocal rank of retrieved against relevant
+func scoreRanking(retrieved, relevant []string, k int) RankingScores {
+	relevantSet := make(map[string]bool, len(relevant))
+	for _, id := range relevant {
+		relevantSet[id] = true
+	}
+
+	var scores RankingScores
+	hits := 0
+	seen := make(map[string]bool)
+	for rank, id := range retrieved {
+		if rank >= k {
+			break
+		}
+		if !relevantSet[id] || seen[id] {
+			continue
+		}
+		seen[id] = true
+		hits++
+		if scores.MRR == 0 {
+			scores.MRR = 1.0 / float64(rank+1)
+		}
+	}
+
+	scores.PrecisionAtK = float64(hits) / float64(k)
+	if len(relevantSet) > 0 {
+		scores.RecallAtK = float64(hits) / float64(len(relevantSet))
+	}
+	return scores
+}
+
+// addScores accumulates s into total
+func addScores(total *RankingScores, s RankingScores) {
+	total.PrecisionAtK += s.PrecisionAtK
+	total.RecallAtK += s.RecallAtK
+	total.MRR += s.MRR
+}
+
+// averageScores divides accumulated scores by n queries
+func averageScores(total *RankingScores, n int) {
+	if n == 0 {
+		return
+	}
+	total.PrecisionAtK /= float64(n)
+	total.RecallAtK /= float64(n)
+	total.MRR /= float64(n)
+}
+
+// GetRetrievalCorpus returns the built-in retrieval benchmark corpus
+func GetRetrievalCorpus() RetrievalScenario {
+	return RetrievalScenario{
+		ID:   "retrieval_default",
+		Name: "Mixed-topic personal assistant corpus",
+		Conversations: []SeedConversation{
+			{
+				ID:    "weather_dashboard",
+				Topic: "weather",
+				Messages: []string{
+					"My API key for the weather service is ABC123XYZ.",
+					"I want the dashboard to show temperature and humidity in Fahrenheit.",
+				},
+				Facts: []SeedFact{{ID: "fact_weather_key", Key: "weather_api_key", Value: "ABC123XYZ"}},
+			},
+			{
+				ID:    "vegetarian_diet",
+				Topic: "dining",
+				Messages: []string{
+					"I'm strictly vegetarian and don't eat meat or fish.",
+					"Can you suggest vegetarian restaurants near downtown?",
+				},
+				Facts: []SeedFact{{ID: "fact_diet", Key: "dietary_preference", Value: "vegetarian"}},
+			},
+			{
+				ID:    "go_project",
+				Topic: "golang",
+				Messages: []string{
+					"I'm building an MCP server in Go using SQLite for storage.",
+					"How should I structure the Go packages for the storage layer?",
+				},
+				Facts: []SeedFact{{ID: "fact_language", Key: "primary_language", Value: "Go"}},
+			},
+			{
+				ID:    "japan_trip",
+				Topic: "travel",
+				Messages: []string{
+					"I'm planning a trip to Japan in April to see the cherry blossoms.",
+					"I'll be flying into Tokyo and taking the train to Kyoto.",
+				},
+				Facts: []SeedFact{{ID: "fact_trip", Key: "upcoming_trip", Value: "Japan in April"}},
+			},
+			{
+				ID:    "marathon_training",
+				Topic: "fitness",
+				Messages: []string{
+					"I'm training for the Chicago marathon in October.",
+					"My long run this weekend is 18 miles.",
+				},
+			},
+		},
+		Queries: []RetrievalQuery{
+			{Query: "weather", RelevantConversations: []string{"weather_dashboard"}, RelevantFacts: []string{"fact_weather_key"}},
+			{Query: "What credential do I use for the weather service?", RelevantConversations: []string{"weather_dashboard"}},
+			{Query: "vegetarian", RelevantConversations: []string{"vegetarian_diet"}, RelevantFacts: []string{"fact_diet"}},
+			{Query: "Where should I eat tonight given my diet?", RelevantConversations: []string{"vegetarian_diet"}},
+			{Query: "golang storage package layout", RelevantConversations: []string{"go_project"}},
+			{Query: "Japan", RelevantConversations: []string{"japan_trip"}, RelevantFacts: []string{"fact_trip"}},
+			{Query: "When is my race?", RelevantConversations: []string{"marathon_training"}},
+		},
+	}
+}
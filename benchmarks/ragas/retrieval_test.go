@@ -0,0 +1,155 @@
+// ABOUTME: Tests for retrieval-only benchmark scoring and scenario validation
+// ABOUTME: Checks precision@k, recall@k, and MRR against hand-computed rankings
+
+package ragas
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestScoreRanking(t *testing.T) {
+	tests := []struct {
+		name      string
+		retrieved []string
+		relevant  []string
+		k         int
+		want      RankingScores
+	}{
+		{
+			name:      "first result relevant",
+			retrieved: []string{"a", "b", "c"},
+			relevant:  []string{"a"},
+			k:         3,
+			want:      RankingScores{PrecisionAtK: 1.0 / 3, RecallAtK: 1, MRR: 1},
+		},
+		{
+			name:      "relevant at rank two",
+			retrieved: []string{"x", "a", "b"},
+			relevant:  []string{"a", "b"},
+			k:         3,
+			want:      RankingScores{PrecisionAtK: 2.0 / 3, RecallAtK: 1, MRR: 0.5},
+		},
+		{
+			name:      "relevant past k ignored",
+			retrieved: []string{"x", "y", "a"},
+			relevant:  []string{"a"},
+			k:         2,
+			want:      RankingScores{},
+		},
+		{
+			name:      "duplicates counted once",
+			retrieved: []string{"a", "a", "a"},
+			relevant:  []string{"a", "b"},
+			k:         3,
+			want:      RankingScores{PrecisionAtK: 1.0 / 3, RecallAtK: 0.5, MRR: 1},
+		},
+		{
+			name:      "fewer results than k",
+			retrieved: []string{"a"},
+			relevant:  []string{"a"},
+			k:         5,
+			want:      RankingScores{PrecisionAtK: 0.2, RecallAtK: 1, MRR: 1},
+		},
+		{
+			name:      "nothing relevant",
+			retrieved: []string{"a", "b"},
+			relevant:  nil,
+			k:         2,
+			want:      RankingScores{},
+		},
+		{
+			name:      "nothing retrieved",
+			retrieved: nil,
+			relevant:  []string{"a"},
+			k:         3,
+			want:      RankingScores{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scoreRanking(tt.retrieved, tt.relevant, tt.k)
+			if !closeTo(got.PrecisionAtK, tt.want.PrecisionAtK) || !closeTo(got.RecallAtK, tt.want.RecallAtK) || !closeTo(got.MRR, tt.want.MRR) {
+				t.Errorf("scoreRanking() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAverageScores(t *testing.T) {
+	var total RankingScores
+	addScores(&total, RankingScores{PrecisionAtK: 1, RecallAtK: 1, MRR: 1})
+	addScores(&total, RankingScores{PrecisionAtK: 0, RecallAtK: 0.5, MRR: 0.5})
+	averageScores(&total, 2)
+	if want := (RankingScores{PrecisionAtK: 0.5, RecallAtK: 0.75, MRR: 0.75}); total != want {
+		t.Errorf("averageScores() = %+v, want %+v", total, want)
+	}
+
+	var empty RankingScores
+	averageScores(&empty, 0)
+	if empty != (RankingScores{}) {
+		t.Errorf("averageScores() with no queries = %+v, want zero", empty)
+	}
+}
+
+func TestValidateRetrievalScenario(t *testing.T) {
+	valid := func() RetrievalScenario {
+		return RetrievalScenario{
+			ID: "corpus",
+			Conversations: []SeedConversation{
+				{ID: "dogs", Messages: []string{"My dog is Rex"}, Facts: []SeedFact{{ID: "dog", Key: "dog_name", Value: "Rex"}}},
+			},
+			Queries: []RetrievalQuery{{Query: "dog", RelevantConversations: []string{"dogs"}, RelevantFacts: []string{"dog"}}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*RetrievalScenario)
+		wantErr string
+	}{
+		{"valid", func(*RetrievalScenario) {}, ""},
+		{"missing id", func(s *RetrievalScenario) { s.ID = "" }, "id is required"},
+		{"conversation without messages", func(s *RetrievalScenario) { s.Conversations[0].Messages = nil }, "at least one message"},
+		{"duplicate conversation", func(s *RetrievalScenario) {
+			s.Conversations = append(s.Conversations, SeedConversation{ID: "dogs", Messages: []string{"again"}})
+		}, "duplicate conversation id"},
+		{"duplicate fact", func(s *RetrievalScenario) {
+			s.Conversations = append(s.Conversations, SeedConversation{ID: "cats", Messages: []string{"cat"}, Facts: []SeedFact{{ID: "dog"}}})
+		}, "fact ids must be unique"},
+		{"no queries", func(s *RetrievalScenario) { s.Queries = nil }, "at least one query"},
+		{"empty query", func(s *RetrievalScenario) { s.Queries[0].Query = "" }, "queries[0]: query is required"},
+		{"unknown conversation", func(s *RetrievalScenario) { s.Queries[0].RelevantConversations = []string{"cats"} }, "unknown conversation"},
+		{"unknown fact", func(s *RetrievalScenario) { s.Queries[0].RelevantFacts = []string{"cat"} }, "unknown fact"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.modify(&s)
+			err := ValidateRetrievalScenario(s)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateRetrievalScenario() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateRetrievalScenario() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetRetrievalCorpus_IsValid(t *testing.T) {
+	if err := ValidateRetrievalScenario(GetRetrievalCorpus()); err != nil {
+		t.Errorf("built-in corpus is invalid: %v", err)
+	}
+}
+
+// closeTo reports whether two scores are equal up to float rounding
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
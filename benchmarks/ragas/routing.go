@@ -0,0 +1,391 @@
+// ABOUTME: Routing benchmark scoring the Governor's continuation/resumption/shift decisions
+// ABOUTME: Replays labeled turn sequences and reports accuracy, per-scenario precision/recall, and a confusion matrix
+
+package ragas
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// RoutingSuite is a set of labeled turn sequences
+type RoutingSuite struct {
+	ID        string            `yaml:"id" json:"id"`
+	Name      string            `yaml:"name" json:"name"`
+	Sequences []RoutingSequence `yaml:"sequences" json:"sequences"`
+}
+
+// RoutingSequence is one conversation replayed into fresh storage
+type RoutingSequence struct {
+	ID          string        `yaml:"id" json:"id"`
+	Description string        `yaml:"description,omitempty" json:"description,omitempty"`
+	Turns       []RoutingTurn `yaml:"turns" json:"turns"`
+}
+
+// RoutingTurn is a user message labeled with the routing it should get.
+// Thread names the conversation thread; continuation and resumption must land in that thread's block.
+// Keywords and Topics override the benchmark's own extraction, e.g. to replay LLM-extracted ones.
+type RoutingTurn struct {
+	Message  string   `yaml:"message" json:"message"`
+	Expected string   `yaml:"expected" json:"expected"` // first, continuation, resumption, or shift
+	Thread   string   `yaml:"thread,omitempty" json:"thread,omitempty"`
+	Keywords []string `yaml:"keywords,omitempty" json:"keywords,omitempty"`
+	Topics   []string `yaml:"topics,omitempty" json:"topics,omitempty"`
+}
+
+// routingScenarioNames maps the short labels used in suites (and the full scenario names) to scenarios
+var routingScenarioNames = map[string]models.RoutingScenario{
+	"first":                          models.NewTopicFirst,
+	"continuation":                   models.TopicContinuation,
+	"resumption":                     models.TopicResumption,
+	"shift":                          models.TopicShift,
+	string(models.NewTopicFirst):     models.NewTopicFirst,
+	string(models.TopicContinuation): models.TopicContinuation,
+	string(models.TopicResumption):   models.TopicResumption,
+	string(models.TopicShift):        models.TopicShift,
+}
+
+// routingScenarios is the reporting order for per-scenario scores and the confusion matrix
+var routingScenarios = []models.RoutingScenario{
+	models.NewTopicFirst, models.TopicContinuation, models.TopicResumption, models.TopicShift,
+}
+
+// RoutingTurnResult is the Governor's decision for one turn
+type RoutingTurnResult struct {
+	Turn           int                    `json:"turn"`
+	Message        string                 `json:"message"`
+	Expected       models.RoutingScenario `json:"expected"`
+	Actual         models.RoutingScenario `json:"actual"`
+	ExpectedThread string                 `json:"expected_thread,omitempty"`
+	ActualThread   string                 `json:"actual_thread,omitempty"` // Thread of the block matched on continuation/resumption
+	Correct        bool                   `json:"correct"`
+}
+
+// RoutingSequenceResult holds every decision made for one sequence
+type RoutingSequenceResult struct {
+	ID      string              `json:"id"`
+	Correct int                 `json:"correct"`
+	Turns   []RoutingTurnResult `json:"turns"`
+}
+
+// ScenarioScores are precision, recall, and F1 for one routing scenario
+type ScenarioScores struct {
+	Expected  int     `json:"expected"`
+	Predicted int     `json:"predicted"`
+	Correct   int     `json:"correct"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+}
+
+// RoutingResult scores a suite at one topic match threshold.
+// Accuracy requires the right scenario and, for continuation/resumption, the right thread;
+// ScenarioAccuracy only checks the scenario.
+type RoutingResult struct {
+	SuiteID          string                                                    `json:"suite_id"`
+	Threshold        float64                                                   `json:"threshold"`
+	Turns            int                                                       `json:"turns"`
+	Correct          int                                                       `json:"correct"`
+	Accuracy         float64                                                   `json:"accuracy"`
+	ScenarioAccuracy float64                                                   `json:"scenario_accuracy"`
+	Scenarios        map[models.RoutingScenario]*ScenarioScores                `json:"scenarios"`
+	Confusion        map[models.RoutingScenario]map[models.RoutingScenario]int `json:"confusion"` // expected → actual → count
+	Sequences        []RoutingSequenceResult                                   `json:"sequences"`
+}
+
+// LoadRoutingSuite reads a routing suite from a YAML or JSON file
+func LoadRoutingSuite(path string) (RoutingSuite, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return RoutingSuite{}, fmt.Errorf("failed to read routing suite: %w", err)
+	}
+
+	var suite RoutingSuite
+	if err := yaml.Unmarshal(raw, &suite); err != nil {
+		return RoutingSuite{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := ValidateRoutingSuite(suite); err != nil {
+		return RoutingSuite{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return suite, nil
+}
+
+// ValidateRoutingSuite checks that labels are known and consistent with each sequence's threads
+func ValidateRoutingSuite(s RoutingSuite) error {
+	if s.ID == "" {
+		return fmt.Errorf("routing suite id is required")
+	}
+	if len(s.Sequences) == 0 {
+		return fmt.Errorf("at least one sequence is required")
+	}
+
+	ids := make(map[string]bool)
+	for _, seq := range s.Sequences {
+		if seq.ID == "" || ids[seq.ID] {
+			return fmt.Errorf("sequence ids must be unique and non-empty (%q)", seq.ID)
+		}
+		ids[seq.ID] = true
+		if len(seq.Turns) == 0 {
+			return fmt.Errorf("sequence %s: at least one turn is required", seq.ID)
+		}
+
+		seen := make(map[string]bool)
+		for i, turn := range seq.Turns {
+			if turn.Message == "" {
+				return fmt.Errorf("sequence %s turn %d: message is required", seq.ID, i+1)
+			}
+			scenario, ok := routingScenarioNames[turn.Expected]
+			if !ok {
+				return fmt.Errorf("sequence %s turn %d: unknown expected scenario %q (want first, continuation, resumption, or shift)", seq.ID, i+1, turn.Expected)
+			}
+			if (i == 0) != (scenario == models.NewTopicFirst) {
+				return fmt.Errorf("sequence %s turn %d: only the first turn can be %q", seq.ID, i+1, "first")
+			}
+			if scenario == models.TopicResumption && turn.Thread != "" && !seen[turn.Thread] {
+				return fmt.Errorf("sequence %s turn %d: resumes thread %q before it starts", seq.ID, i+1, turn.Thread)
+			}
+			seen[turn.Thread] = true
+		}
+	}
+	return nil
+}
+
+// RunRouting replays every sequence through the Governor at the given topic match threshold
+// (0 keeps the Governor's default) and scores each decision against its label
+func (r *BenchmarkRunner) RunRouting(suite RoutingSuite, threshold float64) (RoutingResult, error) {
+	if threshold <= 0 {
+		threshold = core.DefaultTopicMatchThreshold
+	}
+
+	result := RoutingResult{
+		SuiteID:   suite.ID,
+		Threshold: threshold,
+		Scenarios: make(map[models.RoutingScenario]*ScenarioScores),
+		Confusion: make(map[models.RoutingScenario]map[models.RoutingScenario]int),
+	}
+	for _, scenario := range routingScenarios {
+		result.Scenarios[scenario] = &ScenarioScores{}
+		result.Confusion[scenario] = make(map[models.RoutingScenario]int)
+	}
+
+	scenarioCorrect := 0
+	for _, seq := range suite.Sequences {
+		seqResult, err := r.runRoutingSequence(seq, threshold)
+		if err != nil {
+			return RoutingResult{}, fmt.Errorf("sequence %s: %w", seq.ID, err)
+		}
+
+		for _, turn := range seqResult.Turns {
+			result.Turns++
+			result.Confusion[turn.Expected][turn.Actual]++
+			result.Scenarios[turn.Expected].Expected++
+			result.Scenarios[turn.Actual].Predicted++
+			if turn.Expected == turn.Actual {
+				result.Scenarios[turn.Expected].Correct++
+				scenarioCorrect++
+			}
+			if turn.Correct {
+				result.Correct++
+			}
+		}
+		result.Sequences = append(result.Sequences, seqResult)
+	}
+
+	for _, scores := range result.Scenarios {
+		if scores.Predicted > 0 {
+			scores.Precision = float64(scores.Correct) / float64(scores.Predicted)
+		}
+		if scores.Expected > 0 {
+			scores.Recall = float64(scores.Correct) / float64(scores.Expected)
+		}
+		if scores.Precision+scores.Recall > 0 {
+			scores.F1 = 2 * scores.Precision * scores.Recall / (scores.Precision + scores.Recall)
+		}
+	}
+	if result.Turns > 0 {
+		result.Accuracy = float64(result.Correct) / float64(result.Turns)
+		result.ScenarioAccuracy = float64(scenarioCorrect) / float64(result.Turns)
+	}
+	return result, nil
+}
+
+// runRoutingSequence replays one sequence into fresh storage, applying each decision as the runner does
+func (r *BenchmarkRunner) runRoutingSequence(seq RoutingSequence, threshold float64) (RoutingSequenceResult, error) {
+	store, err := r.newStorage(seq.ID)
+	if err != nil {
+		return RoutingSequenceResult{}, fmt.Errorf("failed to create test storage: %w", err)
+	}
+	r.attachStorage(store)
+	defer r.Close()
+	r.seedScenario(seq.ID)
+
+	r.governor.SetTopicMatchThreshold(threshold)
+
+	base := time.Now()
+	if r.seed != 0 {
+		base = seededEpoch
+	}
+
+	result := RoutingSequenceResult{ID: seq.ID}
+	blockThreads := make(map[string]string) // block ID → thread of the turn that created it
+	for i, labeled := range seq.Turns {
+		turn := &models.Turn{
+			TurnID:      fmt.Sprintf("turn_%s_%d", seq.ID, i+1),
+			Timestamp:   base.Add(time.Duration(i) * time.Minute),
+			UserMessage: labeled.Message,
+			Keywords:    labeled.Keywords,
+			Topics:      labeled.Topics,
+		}
+		if turn.Keywords == nil {
+			turn.Keywords = extractKeywords(labeled.Message)
+		}
+		if turn.Topics == nil {
+			turn.Topics = extractTopics(labeled.Message)
+		}
+
+		decision, err := r.governor.Route(turn)
+		if err != nil {
+			return RoutingSequenceResult{}, fmt.Errorf("routing failed: %w", err)
+		}
+		blockID, err := r.applyRouting(decision, turn)
+		if err != nil {
+			return RoutingSequenceResult{}, err
+		}
+
+		tr := RoutingTurnResult{
+			Turn:           i + 1,
+			Message:        labeled.Message,
+			Expected:       routingScenarioNames[labeled.Expected],
+			Actual:         decision.Scenario,
+			ExpectedThread: labeled.Thread,
+		}
+		if decision.MatchedBlockID != "" {
+			tr.ActualThread = blockThreads[decision.MatchedBlockID]
+		} else {
+			blockThreads[blockID] = labeled.Thread
+		}
+
+		tr.Correct = tr.Expected == tr.Actual
+		if tr.Correct && decision.MatchedBlockID != "" && labeled.Thread != "" {
+			tr.Correct = tr.ActualThread == labeled.Thread
+		}
+		if tr.Correct {
+			result.Correct++
+		}
+
+		if r.verbose {
+			mark := "✓"
+			if !tr.Correct {
+				mark = "✗"
+			}
+			fmt.Fprintf(r.out, "%s [%s %d] %s → %s (expected %s)\n", mark, seq.ID, tr.Turn, labeled.Message, tr.Actual, tr.Expected)
+		}
+		result.Turns = append(result.Turns, tr)
+	}
+	return result, nil
+}
+
+// ExportRoutingResults writes routing results, one per threshold, to JSON
+func (r *BenchmarkRunner) ExportRoutingResults(results []RoutingResult, outputPath string) error {
+	summary := map[string]interface{}{
+		"mode":    "routing",
+		"results": results,
+	}
+	r.stampSummary(summary)
+
+	jsonData, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal results: %w", err)
+	}
+
+	if err := os.WriteFile(outputPath, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write results file: %w", err)
+	}
+
+	fmt.Fprintf(r.out, "✓ Results exported to: %s\n", outputPath)
+	return nil
+}
+
+// RoutingScenarios lists scenarios in reporting order
+func RoutingScenarios() []models.RoutingScenario {
+	return append([]models.RoutingScenario(nil), routingScenarios...)
+}
+
+// GetRoutingSuite returns the built-in routing benchmark suite
+func GetRoutingSuite() RoutingSuite {
+	return RoutingSuite{
+		ID:   "routing_default",
+		Name: "Built-in routing suite",
+		Sequences: []RoutingSequence{
+			{
+				ID:          "single_topic",
+				Description: "A focused conversation that never changes subject",
+				Turns: []RoutingTurn{
+					{Message: "I'm planning a vegetable garden in my backyard", Expected: "first", Thread: "garden"},
+					{Message: "Which vegetables grow well in a small backyard garden?", Expected: "continuation", Thread: "garden"},
+					{Message: "How often should I water the vegetable garden?", Expected: "continuation", Thread: "garden"},
+					{Message: "Should the garden beds get full sun?", Expected: "continuation", Thread: "garden"},
+				},
+			},
+			{
+				ID:          "shift_and_resume",
+				Description: "Leaves a topic for another and comes back to it",
+				Turns: []RoutingTurn{
+					{Message: "My Postgres database queries are slow on large tables", Expected: "first", Thread: "postgres"},
+					{Message: "Would an index speed up those Postgres queries?", Expected: "continuation", Thread: "postgres"},
+					{Message: "Recommend a good restaurant for dinner tonight", Expected: "shift", Thread: "dinner"},
+					{Message: "Somewhere with vegetarian food would be great", Expected: "continuation", Thread: "dinner"},
+					{Message: "Back to Postgres: which index type suits large tables?", Expected: "resumption", Thread: "postgres"},
+				},
+			},
+			{
+				ID:          "several_threads",
+				Description: "Juggles three threads, resuming the older ones",
+				Turns: []RoutingTurn{
+					{Message: "Help me plan a trip to Japan in April", Expected: "first", Thread: "japan"},
+					{Message: "What's the weather usually like in April?", Expected: "shift", Thread: "weather"},
+					{Message: "I also need to renew my passport before the trip", Expected: "shift", Thread: "passport"},
+					{Message: "Which Japan cities should the April trip include?", Expected: "resumption", Thread: "japan"},
+					{Message: "How long does passport renewal take?", Expected: "resumption", Thread: "passport"},
+					{Message: "Is rain likely in that weather?", Expected: "resumption", Thread: "weather"},
+				},
+			},
+			{
+				ID:          "topic_labels",
+				Description: "Turns that share only a topic label, not keywords",
+				Turns: []RoutingTurn{
+					{Message: "Where do I store my API key safely?", Expected: "first", Thread: "api_keys"},
+					{Message: "Should the key be rotated every month?", Expected: "continuation", Thread: "api_keys"},
+					{Message: "Is it going to rain? Weather check please", Expected: "shift", Thread: "weather"},
+					{Message: "One more API question: can I scope a key to one project?", Expected: "resumption", Thread: "api_keys"},
+				},
+			},
+			{
+				ID:          "short_follow_ups",
+				Description: "Terse follow-ups that share no keywords with the thread they continue",
+				Turns: []RoutingTurn{
+					{Message: "Explain how Go channels work with goroutines", Expected: "first", Thread: "go"},
+					{Message: "Thanks, could you show an example?", Expected: "continuation", Thread: "go"},
+					{Message: "Tell me about the history of the Roman empire", Expected: "shift", Thread: "rome"},
+					{Message: "Interesting, why did it fall?", Expected: "continuation", Thread: "rome"},
+				},
+			},
+			{
+				ID:          "near_misses",
+				Description: "New subjects that reuse a word from an earlier thread",
+				Turns: []RoutingTurn{
+					{Message: "My laptop battery drains quickly overnight", Expected: "first", Thread: "battery"},
+					{Message: "Which car battery brand lasts longest in cold winters?", Expected: "shift", Thread: "car"},
+					{Message: "Does laptop battery calibration help overnight drain?", Expected: "resumption", Thread: "battery"},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,159 @@
+// ABOUTME: Loads RAGAS benchmark scenarios from YAML/JSON files
+// ABOUTME: Validates scenario structure so broken definitions fail before any LLM calls
+
+package ragas
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultScenarioDir is where scenario files live relative to the repository root
+const DefaultScenarioDir = "benchmarks/scenarios"
+
+// LoadScenarios reads every .yaml, .yml, and .json scenario file in dir, sorted by file name
+func LoadScenarios(dir string) ([]TestScenario, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no scenario files (.yaml, .yml, .json) found in %s", dir)
+	}
+
+	scenarios := make([]TestScenario, 0, len(paths))
+	seen := make(map[string]string)
+	for _, path := range paths {
+		scenario, err := LoadScenarioFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[scenario.ID]; ok {
+			return nil, fmt.Errorf("%s: duplicate scenario id %q (also in %s)", path, scenario.ID, other)
+		}
+		seen[scenario.ID] = path
+		scenarios = append(scenarios, scenario)
+	}
+
+	return scenarios, nil
+}
+
+// LoadScenarioFile reads and validates a single scenario file.
+// JSON is parsed as YAML (a superset), so both share one schema.
+func LoadScenarioFile(path string) (TestScenario, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return TestScenario{}, fmt.Errorf("failed to read scenario: %w", err)
+	}
+
+	var scenario TestScenario
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true) // Reject typos like "user_mesage"
+	if err := decoder.Decode(&scenario); err != nil {
+		if errors.Is(err, io.EOF) {
+			return TestScenario{}, fmt.Errorf("%s: empty scenario file", path)
+		}
+		return TestScenario{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := ValidateScenario(scenario); err != nil {
+		return TestScenario{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return scenario, nil
+}
+
+// ValidateScenario checks that a scenario is complete and internally consistent
+func ValidateScenario(s TestScenario) error {
+	var problems []string
+
+	if strings.TrimSpace(s.ID) == "" {
+		problems = append(problems, "id is required")
+	}
+	if strings.TrimSpace(s.Name) == "" {
+		problems = append(problems, "name is required")
+	}
+	if len(s.Turns) == 0 {
+		problems = append(problems, "at least one turn is required")
+	}
+
+	for i, turn := range s.Turns {
+		if turn.TurnNumber != i+1 {
+			problems = append(problems, fmt.Sprintf("turns[%d]: turn must be %d (turns are numbered from 1 in order), got %d", i, i+1, turn.TurnNumber))
+		}
+		if strings.TrimSpace(turn.UserMessage) == "" {
+			problems = append(problems, fmt.Sprintf("turns[%d]: user_message is required", i))
+		}
+		if turn.Delay < 0 {
+			problems = append(problems, fmt.Sprintf("turns[%d]: delay must not be negative", i))
+		}
+	}
+
+	gt := s.GroundTruth
+	if gt.FinalQueryTurn < 1 || gt.FinalQueryTurn > len(s.Turns) {
+		problems = append(problems, fmt.Sprintf("ground_truth.final_query_turn must be between 1 and %d, got %d", len(s.Turns), gt.FinalQueryTurn))
+	}
+	for i, fact := range gt.ExpectedFacts {
+		if fact.Key == "" {
+			problems = append(problems, fmt.Sprintf("ground_truth.expected_facts[%d]: key is required", i))
+		}
+		if fact.TurnStored < 0 || fact.TurnStored > len(s.Turns) {
+			problems = append(problems, fmt.Sprintf("ground_truth.expected_facts[%d]: turn_stored must be between 0 (setup) and %d", i, len(s.Turns)))
+		}
+	}
+
+	if s.Setup != nil && s.Setup.UserProfile != nil {
+		for i, c := range s.Setup.UserProfile.Constraints {
+			if c.Key == "" || c.Description == "" {
+				problems = append(problems, fmt.Sprintf("setup.user_profile.constraints[%d]: key and description are required", i))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid scenario %q: %s", s.ID, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// FilterScenarios returns the scenarios matching id ("7a" matches "test_7a"); empty id matches all
+func FilterScenarios(scenarios []TestScenario, id string) []TestScenario {
+	if id == "" {
+		return scenarios
+	}
+
+	var matched []TestScenario
+	for _, s := range scenarios {
+		if matchesTestID(s.ID, id) {
+			matched = append(matched, s)
+		}
+	}
+	return matched
+}
+
+// matchesTestID reports whether testID is id, ignoring case and the "test_" prefix
+func matchesTestID(testID, id string) bool {
+	sid, id := strings.ToLower(testID), strings.ToLower(id)
+	return sid == id || sid == "test_"+id
+}
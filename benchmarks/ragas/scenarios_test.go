@@ -0,0 +1,212 @@
+// ABOUTME: Tests for loading and validating benchmark scenario files
+// ABOUTME: Covers the shipped scenarios, YAML/JSON parsing, unknown fields, and validation problems
+
+package ragas
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// validScenario returns a minimal scenario that passes validation
+func validScenario() TestScenario {
+	return TestScenario{
+		ID:   "test_x",
+		Name: "Example",
+		Turns: []ConversationTurn{
+			{TurnNumber: 1, UserMessage: "My dog is called Rex."},
+			{TurnNumber: 2, UserMessage: "What is my dog called?"},
+		},
+		GroundTruth: GroundTruth{
+			FinalQueryTurn: 2,
+			ExpectedFacts:  []ExpectedFact{{Key: "dog_name", Value: "Rex", TurnStored: 1}},
+		},
+	}
+}
+
+func TestLoadScenarios_ShippedScenarios(t *testing.T) {
+	scenarios, err := LoadScenarios(filepath.Join("..", "scenarios"))
+	if err != nil {
+		t.Fatalf("LoadScenarios() error = %v", err)
+	}
+	if len(scenarios) == 0 {
+		t.Fatal("LoadScenarios() returned no scenarios")
+	}
+	for i := 1; i < len(scenarios); i++ {
+		if scenarios[i-1].ID >= scenarios[i].ID {
+			t.Errorf("scenarios are not in file name order: %s before %s", scenarios[i-1].ID, scenarios[i].ID)
+		}
+	}
+}
+
+func TestLoadScenarioFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantID  string
+		wantErr string
+	}{
+		{
+			name: "yaml",
+			file: "a.yaml",
+			content: `id: test_y
+name: YAML
+turns:
+  - turn: 1
+    user_message: hello
+ground_truth:
+  final_query_turn: 1
+`,
+			wantID: "test_y",
+		},
+		{
+			name:    "json",
+			file:    "a.json",
+			content: `{"id": "test_j", "name": "JSON", "turns": [{"turn": 1, "user_message": "hello"}], "ground_truth": {"final_query_turn": 1}}`,
+			wantID:  "test_j",
+		},
+		{
+			name: "unknown field",
+			file: "a.yaml",
+			content: `id: test_y
+name: YAML
+turns:
+  - turn: 1
+    user_mesage: hello
+ground_truth:
+  final_query_turn: 1
+`,
+			wantErr: "user_mesage",
+		},
+		{
+			name:    "empty file",
+			file:    "a.yaml",
+			content: "",
+			wantErr: "empty scenario file",
+		},
+		{
+			name:    "invalid scenario",
+			file:    "a.yaml",
+			content: "id: test_y\nname: YAML\n",
+			wantErr: "at least one turn is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			scenario, err := LoadScenarioFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadScenarioFile() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadScenarioFile() error = %v", err)
+			}
+			if scenario.ID != tt.wantID {
+				t.Errorf("ID = %q, want %q", scenario.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestLoadScenarios_Errors(t *testing.T) {
+	t.Run("no scenario files", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a scenario"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadScenarios(dir); err == nil || !strings.Contains(err.Error(), "no scenario files") {
+			t.Errorf("LoadScenarios() error = %v, want no scenario files", err)
+		}
+	})
+
+	t.Run("duplicate id", func(t *testing.T) {
+		dir := t.TempDir()
+		content := `{"id": "test_dup", "name": "Dup", "turns": [{"turn": 1, "user_message": "hi"}], "ground_truth": {"final_query_turn": 1}}`
+		for _, name := range []string{"a.json", "b.json"} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := LoadScenarios(dir); err == nil || !strings.Contains(err.Error(), "duplicate scenario id") {
+			t.Errorf("LoadScenarios() error = %v, want duplicate scenario id", err)
+		}
+	})
+}
+
+func TestValidateScenario(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*TestScenario)
+		wantErr string
+	}{
+		{"valid", func(*TestScenario) {}, ""},
+		{"missing id", func(s *TestScenario) { s.ID = " " }, "id is required"},
+		{"missing name", func(s *TestScenario) { s.Name = "" }, "name is required"},
+		{"turns out of order", func(s *TestScenario) { s.Turns[1].TurnNumber = 3 }, "turns[1]: turn must be 2"},
+		{"empty user message", func(s *TestScenario) { s.Turns[0].UserMessage = "" }, "turns[0]: user_message is required"},
+		{"negative delay", func(s *TestScenario) { s.Turns[0].Delay = -1 }, "delay must not be negative"},
+		{"final query turn past the end", func(s *TestScenario) { s.GroundTruth.FinalQueryTurn = 3 }, "final_query_turn must be between 1 and 2"},
+		{"fact without key", func(s *TestScenario) { s.GroundTruth.ExpectedFacts[0].Key = "" }, "expected_facts[0]: key is required"},
+		{"fact stored after the last turn", func(s *TestScenario) { s.GroundTruth.ExpectedFacts[0].TurnStored = 5 }, "turn_stored must be between 0"},
+		{
+			"incomplete constraint",
+			func(s *TestScenario) {
+				s.Setup = &TestSetup{UserProfile: &UserProfileSetup{Constraints: []ProfileConstraint{{Key: "diet"}}}}
+			},
+			"constraints[0]: key and description are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := validScenario()
+			tt.modify(&s)
+			err := ValidateScenario(s)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateScenario() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateScenario() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFilterScenarios(t *testing.T) {
+	scenarios := []TestScenario{{ID: "test_7a"}, {ID: "test_7b"}, {ID: "custom"}}
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		{"", []string{"test_7a", "test_7b", "custom"}},
+		{"7a", []string{"test_7a"}},
+		{"TEST_7B", []string{"test_7b"}},
+		{"custom", []string{"custom"}},
+		{"7", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			var got []string
+			for _, s := range FilterScenarios(scenarios, tt.id) {
+				got = append(got, s.ID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("FilterScenarios(%q) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
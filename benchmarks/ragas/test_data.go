@@ -7,73 +7,95 @@ import "time"
 
 // TestScenario represents a complete RAGAS benchmark test
 type TestScenario struct {
-	ID          string
-	Name        string
-	Description string
-	Turns       []ConversationTurn
-	GroundTruth GroundTruth
-	Setup       *TestSetup // Optional pre-test setup (e.g., user profile)
+	ID          string             `yaml:"id" json:"id"`
+	Name        string             `yaml:"name" json:"name"`
+	Description string             `yaml:"description,omitempty" json:"description,omitempty"`
+	Turns       []ConversationTurn `yaml:"turns" json:"turns"`
+	GroundTruth GroundTruth        `yaml:"ground_truth" json:"ground_truth"`
+	Setup       *TestSetup         `yaml:"setup,omitempty" json:"setup,omitempty"` // Optional pre-test setup (e.g., user profile)
 }
 
 // ConversationTurn represents a single turn in a test conversation
 type ConversationTurn struct {
-	TurnNumber  int
-	UserMessage string
-	Delay       time.Duration // Delay before this turn (for temporal tests)
+	TurnNumber  int           `yaml:"turn" json:"turn"`
+	UserMessage string        `yaml:"user_message" json:"user_message"`
+	Delay       time.Duration `yaml:"delay,omitempty" json:"delay,omitempty"`             // Delay before this turn (for temporal tests)
+	AIResponse  string        `yaml:"ai_response,omitempty" json:"ai_response,omitempty"` // Recorded reply; replayed instead of generated
+	Timestamp   time.Time     `yaml:"timestamp,omitempty" json:"timestamp,omitempty"`     // When the turn happened (default: now)
 }
 
 // GroundTruth defines expected outcomes for RAGAS evaluation
 type GroundTruth struct {
 	// Expected facts to be stored
-	ExpectedFacts []ExpectedFact
+	ExpectedFacts []ExpectedFact `yaml:"expected_facts,omitempty" json:"expected_facts,omitempty"`
 
 	// Expected response for final query turn
-	FinalQueryTurn      int
-	ExpectedInResponse  []string // Strings that MUST appear in response
-	ForbiddenInResponse []string // Strings that MUST NOT appear in response
+	FinalQueryTurn      int      `yaml:"final_query_turn" json:"final_query_turn"`
+	ExpectedInResponse  []string `yaml:"expected_in_response,omitempty" json:"expected_in_response,omitempty"`   // Strings that MUST appear in response
+	ForbiddenInResponse []string `yaml:"forbidden_in_response,omitempty" json:"forbidden_in_response,omitempty"` // Strings that MUST NOT appear in response
 
 	// Context retrieval expectations
-	ExpectedContextItems []string // Facts/context that should be retrieved
+	ExpectedContextItems []string `yaml:"expected_context_items,omitempty" json:"expected_context_items,omitempty"` // Facts/context that should be retrieved
+
+	// Ideal answer used by the LLM judge for context precision/recall
+	// (derived from expected facts and context items when empty)
+	ReferenceAnswer string `yaml:"reference_answer,omitempty" json:"reference_answer,omitempty"`
 }
 
 // ExpectedFact represents a fact that should be extracted
 type ExpectedFact struct {
-	Key       string
-	Value     string
-	TurnStored int
-	Supersedes *ExpectedFact // For temporal conflicts (Test 7A)
+	Key        string        `yaml:"key" json:"key"`
+	Value      string        `yaml:"value" json:"value"`
+	TurnStored int           `yaml:"turn_stored" json:"turn_stored"`
+	Supersedes *ExpectedFact `yaml:"supersedes,omitempty" json:"supersedes,omitempty"` // For temporal conflicts (Test 7A)
 }
 
 // TestSetup defines pre-test environment setup
 type TestSetup struct {
-	UserProfile *UserProfileSetup
+	UserProfile *UserProfileSetup `yaml:"user_profile,omitempty" json:"user_profile,omitempty"`
 }
 
 // UserProfileSetup defines pre-populated user profile data
 type UserProfileSetup struct {
-	Name        string
-	Preferences []string
-	Constraints []ProfileConstraint
+	Name        string              `yaml:"name,omitempty" json:"name,omitempty"`
+	Preferences []string            `yaml:"preferences,omitempty" json:"preferences,omitempty"`
+	Constraints []ProfileConstraint `yaml:"constraints,omitempty" json:"constraints,omitempty"`
 }
 
 // ProfileConstraint represents a user constraint in profile
 type ProfileConstraint struct {
-	Key         string
-	Type        string
-	Description string
-	Severity    string
+	Key         string `yaml:"key" json:"key"`
+	Type        string `yaml:"type" json:"type"`
+	Description string `yaml:"description" json:"description"`
+	Severity    string `yaml:"severity,omitempty" json:"severity,omitempty"`
 }
 
 // TestResult represents the outcome of a benchmark test
 type TestResult struct {
-	TestID          string
-	TestName        string
-	FaithfulnessScore float64
-	ContextRecallScore float64
-	OverallScore    float64
-	Status          string // "PASS" or "FAIL"
-	Details         map[string]interface{}
-	ErrorMessage    string
+	TestID                string
+	TestName              string
+	FaithfulnessScore     float64
+	ContextRecallScore    float64
+	AnswerRelevancyScore  float64 // LLM-judged runs only
+	ContextPrecisionScore float64 // LLM-judged runs only
+	OverallScore          float64
+	Status                string           // "PASS" or "FAIL"
+	Scoring               string           `json:",omitempty"` // ScoringJudge or ScoringHeuristic
+	Response              string           `json:",omitempty"` // Full final response (Details holds a preview)
+	Transcript            []TranscriptTurn `json:",omitempty"` // Every turn with the context it was answered from
+	Details               map[string]interface{}
+	Judgement             *JudgeBreakdown      `json:",omitempty"` // Per-claim verdicts from the LLM judge
+	CustomMetrics         []CustomMetricResult `json:",omitempty"` // Registered product-specific metrics
+	ErrorMessage          string
+}
+
+// TranscriptTurn records one turn of a benchmark conversation
+type TranscriptTurn struct {
+	Turn     int      `json:"turn"`
+	User     string   `json:"user"`
+	Response string   `json:"response"`
+	Context  []string `json:"context,omitempty"`
+	Replayed bool     `json:"replayed,omitempty"` // Recorded response, not generated
 }
 
 // GetTest7A returns Test 7A: API Key Rotation scenario
@@ -122,6 +144,7 @@ func GetTest7A() TestScenario {
 			ExpectedContextItems: []string{
 				"XYZ789", // Most recent key must be in context
 			},
+			ReferenceAnswer: "Your current weather service API key is XYZ789.",
 		},
 	}
 }
@@ -162,7 +185,7 @@ func GetTest7B() TestScenario {
 					TurnStored: 0, // Pre-populated
 				},
 			},
-			FinalQueryTurn: 1,
+			FinalQueryTurn:     1,
 			ExpectedInResponse: []string{
 				// Must acknowledge vegetarian preference
 				// (flexible - any of these keywords indicates awareness)
@@ -177,6 +200,7 @@ func GetTest7B() TestScenario {
 			ExpectedContextItems: []string{
 				"vegetarian", // User profile constraint must be in context
 			},
+			ReferenceAnswer: "You are strictly vegetarian, so order a vegetarian dish such as a salad, roasted vegetables, or a pasta dish rather than steak.",
 		},
 	}
 }
@@ -253,6 +277,7 @@ func GetTest2A() TestScenario {
 			ExpectedContextItems: []string{
 				"ABC123XYZ", // API key must be in context
 			},
+			ReferenceAnswer: "ABC123XYZ",
 		},
 	}
 }
@@ -8,18 +8,104 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/harper/remember-standalone/benchmarks/ragas"
+	"github.com/harper/remember-standalone/internal/redact"
+	"github.com/harper/remember-standalone/internal/storage"
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	log.SetOutput(redact.NewWriter(os.Stderr))
+
+	if len(os.Args) > 1 && os.Args[1] == "history" {
+		runHistory(os.Args[2:])
+		return
+	}
+
 	// Command-line flags
-	testID := flag.String("test", "", "Run specific test (7a, 7b, 2a). If empty, runs all tests.")
+	testID := flag.String("test", "", "Run specific test by ID (e.g. 7a or test_7a). If empty, runs all tests.")
 	outputPath := flag.String("output", "benchmark_results.json", "Output path for JSON results")
 	verbose := flag.Bool("verbose", false, "Enable verbose output")
+	scenarioDir := flag.String("scenario-dir", "", "Load scenarios from YAML/JSON files in this directory (e.g. "+ragas.DefaultScenarioDir+") instead of the built-in set")
+	validateOnly := flag.Bool("validate", false, "Validate scenario files and exit without running benchmarks")
+	offline := flag.Bool("offline", false, "Use the deterministic mock instead of the chat model for responses")
+	seed := flag.Int64("seed", 0, "Make IDs, timestamps, and delays deterministic so runs produce identical output (0 = off)")
+	parallel := flag.Int("parallel", 1, "Run up to N scenarios concurrently, each with its own database")
+	rpm := flag.Int("rpm", 0, "Cap OpenAI requests per minute across all parallel scenarios (0 = unlimited)")
+	dbDir := flag.String("db-dir", "", "Keep each scenario's SQLite database in this directory (default: in-memory)")
+	dataset := flag.String("dataset", "", "Run a public dataset instead of the built-in scenarios: "+ragas.DatasetLongMemEval+" or "+ragas.DatasetLoCoMo)
+	datasetPath := flag.String("dataset-path", "", "Path to the dataset file (e.g. longmemeval_s.json, locomo10.json)")
+	datasetLimit := flag.Int("dataset-limit", 0, "Run only the first N dataset questions (0 = all)")
+	predictionsPath := flag.String("predictions", "", "With -dataset, also write predictions in the dataset's evaluation format")
+	historyPath := flag.String("history", ragas.DefaultHistoryPath, "Append this run's summary to this history file (empty disables)")
+	reportPath := flag.String("report", "", "Also write a self-contained HTML report to this path")
+	baselinePath := flag.String("baseline", "", "Compare scores against results from a previous run (e.g. results/main.json)")
+	failOnRegression := flag.Float64("fail-on-regression", 0, "With -baseline, exit non-zero if any metric drops by more than this amount (0 reports only)")
+	retrieval := flag.Bool("retrieval", false, "Measure retrieval quality only (precision@k, recall@k, MRR) without generating responses")
+	retrievalFile := flag.String("retrieval-corpus", "", "Load the retrieval corpus from this YAML/JSON file instead of the built-in one")
+	feedbackDB := flag.String("retrieval-feedback", "", "Build the retrieval corpus from rate_memory feedback in this memory database (data_dir/memory.db)")
+	k := flag.Int("k", 5, "Number of results to score per query in retrieval mode")
+	routing := flag.Bool("routing", false, "Score the Governor's routing decisions on labeled turn sequences instead of running scenarios")
+	routingFile := flag.String("routing-suite", "", "Load the routing suite from this YAML/JSON file instead of the built-in one")
+	routingThresholds := flag.String("routing-threshold", "", "Comma-separated topic match thresholds to compare in routing mode (default: the Governor's)")
+	var metrics metricFlags
+	flag.Var(&metrics, "metric", "Custom metric every test must pass, as name[(arg)][:threshold]; repeatable. Available: "+strings.Join(ragas.MetricNames(), ", "))
 	flag.Parse()
 
+	if *retrieval {
+		runRetrieval(*retrievalFile, *feedbackDB, *k, *outputPath, *verbose, *offline, *dbDir, *seed)
+		return
+	}
+	if *routing {
+		runRouting(*routingFile, *routingThresholds, *outputPath, *verbose, *dbDir, *seed)
+		return
+	}
+
+	// Select scenarios
+	scenarios := ragas.GetAllTests()
+	if *scenarioDir != "" {
+		loaded, err := ragas.LoadScenarios(*scenarioDir)
+		if err != nil {
+			log.Fatalf("Failed to load scenarios: %v", err)
+		}
+		scenarios = loaded
+	}
+	if *dataset != "" {
+		if *datasetPath == "" {
+			log.Fatal("-dataset requires -dataset-path")
+		}
+		loaded, err := ragas.LoadDataset(*dataset, *datasetPath, *datasetLimit)
+		if err != nil {
+			log.Fatalf("Failed to load dataset: %v", err)
+		}
+		scenarios = loaded
+	}
+
+	if *validateOnly {
+		fmt.Printf("✓ %d scenarios valid\n", len(scenarios))
+		return
+	}
+
+	scenarios = ragas.FilterScenarios(scenarios, *testID)
+	if len(scenarios) == 0 {
+		log.Fatalf("Unknown test ID: %s", *testID)
+	}
+
+	// Load the baseline up front so a bad path fails before any API calls
+	var baseline []ragas.TestResult
+	if *baselinePath != "" {
+		loaded, err := ragas.LoadBaseline(*baselinePath)
+		if err != nil {
+			log.Fatalf("Failed to load baseline: %v", err)
+		}
+		// Tests left out with -test are not missing from the run
+		baseline = ragas.FilterBaseline(loaded, *testID)
+	}
+
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
 		log.Printf("No .env file found (continuing anyway): %v", err)
@@ -27,8 +113,8 @@ func main() {
 
 	// Verify OpenAI API key
 	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		log.Fatal("OPENAI_API_KEY environment variable is required for benchmarks")
+	if apiKey == "" && !*offline {
+		log.Fatal("OPENAI_API_KEY environment variable is required for benchmarks (or use -offline)")
 	}
 
 	// Print header
@@ -38,47 +124,34 @@ func main() {
 	fmt.Println()
 
 	// Create benchmark runner
-	runner, err := ragas.NewBenchmarkRunner(apiKey, *verbose)
+	opts := ragas.RunnerOptions{
+		Verbose:           *verbose,
+		Offline:           *offline,
+		Parallel:          *parallel,
+		RequestsPerMinute: *rpm,
+		Seed:              *seed,
+		Metrics:           metrics,
+	}
+	if *dbDir != "" {
+		opts.Storage = ragas.StorageInDir(*dbDir)
+	}
+
+	runner, err := ragas.NewBenchmarkRunnerWithOptions(apiKey, opts)
 	if err != nil {
 		log.Fatalf("Failed to create benchmark runner: %v", err)
 	}
 	defer runner.Close()
 
 	// Run tests
-	var results []ragas.TestResult
-
-	if *testID == "" {
-		// Run all tests
-		fmt.Println("Running all RAGAS benchmark tests...")
-		fmt.Println()
-
-		results, err = runner.RunAllTests()
-		if err != nil {
-			log.Fatalf("Benchmark failed: %v", err)
-		}
+	if len(scenarios) == 1 {
+		fmt.Printf("Running test: %s\n\n", scenarios[0].Name)
 	} else {
-		// Run specific test
-		var scenario ragas.TestScenario
-
-		switch *testID {
-		case "7a":
-			scenario = ragas.GetTest7A()
-		case "7b":
-			scenario = ragas.GetTest7B()
-		case "2a":
-			scenario = ragas.GetTest2A()
-		default:
-			log.Fatalf("Unknown test ID: %s (valid options: 7a, 7b, 2a)", *testID)
-		}
-
-		fmt.Printf("Running test: %s\n\n", scenario.Name)
-
-		result, err := runner.RunTest(scenario)
-		if err != nil {
-			log.Fatalf("Test failed: %v", err)
-		}
+		fmt.Printf("Running %d RAGAS benchmark tests (%d at a time)...\n\n", len(scenarios), max(1, min(*parallel, len(scenarios))))
+	}
 
-		results = []ragas.TestResult{result}
+	results, err := runner.RunTests(scenarios)
+	if err != nil {
+		log.Fatalf("Benchmark failed: %v", err)
 	}
 
 	// Print summary
@@ -93,7 +166,27 @@ func main() {
 		fmt.Printf("\n%s: %s\n", result.TestID, result.TestName)
 		fmt.Printf("  Faithfulness: %.2f\n", result.FaithfulnessScore)
 		fmt.Printf("  Context Recall: %.2f\n", result.ContextRecallScore)
+		if result.Judgement != nil {
+			fmt.Printf("  Answer Relevancy: %.2f\n", result.AnswerRelevancyScore)
+			fmt.Printf("  Context Precision: %.2f\n", result.ContextPrecisionScore)
+		}
+		for _, c := range result.CustomMetrics {
+			mark := "✓"
+			if !c.Passed {
+				mark = "✗"
+			}
+			fmt.Printf("  %s %s: %.2f (needs %.2f)\n", mark, c.Name, c.Score, c.Threshold)
+		}
+		if result.ErrorMessage != "" {
+			fmt.Printf("  Warning: %s\n", result.ErrorMessage)
+		}
 		fmt.Printf("  Overall: %.2f\n", result.OverallScore)
+		if perf, ok := result.Details["performance"].(*ragas.PerformanceMetrics); ok {
+			fmt.Printf("  Latency: store %.1fms, retrieval %.1fms, generation %.1fms (mean)\n",
+				perf.Store.MeanMs, perf.Retrieval.MeanMs, perf.Generation.MeanMs)
+			fmt.Printf("  LLM: %d calls, %d tokens, ~$%.4f\n", perf.System.Calls,
+				perf.System.PromptTokens+perf.System.CompletionTokens, perf.System.CostUSD)
+		}
 		fmt.Printf("  Status: %s\n", result.Status)
 
 		if result.Status == "PASS" {
@@ -107,6 +200,11 @@ func main() {
 	fmt.Printf("Total Tests: %d\n", len(results))
 	fmt.Printf("Passed: %d\n", passed)
 	fmt.Printf("Failed: %d\n", failed)
+	perf := ragas.SummarizePerformance(results)
+	fmt.Printf("Store latency: p50 %.1fms, p95 %.1fms\n", perf.Store.P50Ms, perf.Store.P95Ms)
+	fmt.Printf("Retrieval latency: p50 %.1fms, p95 %.1fms\n", perf.Retrieval.P50Ms, perf.Retrieval.P95Ms)
+	fmt.Printf("LLM cost: ~$%.4f system (%d calls), ~$%.4f judge (%d calls)\n",
+		perf.System.CostUSD, perf.System.Calls, perf.Judge.CostUSD, perf.Judge.Calls)
 	fmt.Println("========================================")
 
 	// Export results
@@ -114,8 +212,315 @@ func main() {
 		log.Fatalf("Failed to export results: %v", err)
 	}
 
-	// Exit with error code if any tests failed
-	if failed > 0 {
+	if *dataset != "" && *predictionsPath != "" {
+		if err := ragas.ExportDatasetPredictions(*dataset, *datasetPath, results, *predictionsPath); err != nil {
+			log.Fatalf("Failed to export predictions: %v", err)
+		}
+		fmt.Printf("✓ %s predictions exported to: %s\n", *dataset, *predictionsPath)
+	}
+
+	if *historyPath != "" {
+		entry := ragas.NewHistoryEntry(results, suiteName(*dataset, *scenarioDir, *testID), ragas.CurrentCommit())
+		if err := ragas.AppendHistory(*historyPath, entry); err != nil {
+			log.Printf("Failed to record history: %v", err)
+		}
+	}
+
+	if *reportPath != "" {
+		if err := ragas.WriteHTMLReport(*reportPath, scenarios, results, baseline); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+		fmt.Printf("✓ HTML report written to: %s\n", *reportPath)
+	}
+
+	regressed := false
+	if baseline != nil {
+		regressed = reportBaseline(results, baseline, *failOnRegression)
+	}
+
+	// Exit with error code if any tests failed or regressed
+	if failed > 0 || regressed {
 		os.Exit(1)
 	}
 }
+
+// metricFlags collects repeated -metric flags
+type metricFlags []ragas.MetricCheck
+
+// String implements flag.Value
+func (m *metricFlags) String() string {
+	names := make([]string, len(*m))
+	for i, check := range *m {
+		names[i] = check.Metric.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// Set implements flag.Value
+func (m *metricFlags) Set(spec string) error {
+	check, err := ragas.ParseMetricCheck(spec)
+	if err != nil {
+		return err
+	}
+	*m = append(*m, check)
+	return nil
+}
+
+// suiteName identifies which scenarios a run covered, so history trends compare like with like
+func suiteName(dataset, scenarioDir, testID string) string {
+	suite := "builtin"
+	switch {
+	case dataset != "":
+		suite = dataset
+	case scenarioDir != "":
+		suite = scenarioDir
+	}
+	if testID != "" {
+		suite += ":" + testID
+	}
+	return suite
+}
+
+// runHistory implements "hmlr-benchmark history": score trends from the history file
+func runHistory(args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	path := fs.String("file", ragas.DefaultHistoryPath, "History file to read")
+	suite := fs.String("suite", "", "Only show runs of this suite (e.g. builtin, locomo, builtin:7a)")
+	limit := fs.Int("limit", 20, "Show the most recent N runs (0 = all)")
+	_ = fs.Parse(args)
+
+	entries, err := ragas.LoadHistory(*path)
+	if err != nil {
+		log.Fatalf("Failed to load history: %v", err)
+	}
+	ragas.RenderHistory(os.Stdout, entries, *suite, *limit)
+}
+
+// reportBaseline prints the comparison with a baseline and reports whether gating should fail the run
+func reportBaseline(results, baseline []ragas.TestResult, threshold float64) bool {
+	comparison := ragas.CompareToBaseline(results, baseline, threshold)
+
+	fmt.Println("\n========================================")
+	fmt.Println("BASELINE COMPARISON")
+	fmt.Println("========================================")
+	fmt.Printf("Compared %d tests (threshold %.2f)\n", comparison.Compared, threshold)
+	for _, id := range comparison.NewTests {
+		fmt.Printf("  new: %s (not in baseline)\n", id)
+	}
+	for _, id := range comparison.ScoringChanged {
+		fmt.Printf("  scoring changed: %s (judge vs heuristics; judged metrics not compared)\n", id)
+	}
+	for _, reg := range comparison.Regressions {
+		switch reg.Metric {
+		case ragas.MetricMissing:
+			fmt.Printf("  ✗ %s: in the baseline but not this run\n", reg.TestID)
+		case ragas.MetricStatus:
+			fmt.Printf("  ✗ %s: PASS → FAIL (overall %.2f → %.2f)\n", reg.TestID, reg.Baseline, reg.Current)
+		default:
+			fmt.Printf("  ✗ %s %s: %.2f → %.2f (-%.2f)\n", reg.TestID, reg.Metric, reg.Baseline, reg.Current, reg.Drop)
+		}
+	}
+	if len(comparison.Regressions) == 0 {
+		fmt.Println("  ✓ No regressions")
+	}
+
+	return threshold > 0 && len(comparison.Regressions) > 0
+}
+
+// runRetrieval runs the retrieval-only benchmark and prints per-query and aggregate scores
+func runRetrieval(corpusPath, feedbackDB string, k int, outputPath string, verbose, offline bool, dbDir string, seed int64) {
+	corpus := ragas.GetRetrievalCorpus()
+	if corpusPath != "" {
+		loaded, err := ragas.LoadRetrievalScenario(corpusPath)
+		if err != nil {
+			log.Fatalf("Failed to load retrieval corpus: %v", err)
+		}
+		corpus = loaded
+	}
+	if feedbackDB != "" {
+		if corpusPath != "" {
+			log.Fatalf("Use either -retrieval-corpus or -retrieval-feedback, not both")
+		}
+		store, err := storage.NewStorageReadOnly(feedbackDB)
+		if err != nil {
+			log.Fatalf("Failed to open memory database: %v", err)
+		}
+		rated, err := ragas.FeedbackScenario(store)
+		_ = store.Close()
+		if err != nil {
+			log.Fatalf("Failed to build corpus from feedback: %v", err)
+		}
+		corpus = rated
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("No .env file found (continuing anyway): %v", err)
+	}
+
+	// Without a key only keyword search runs; with one, turns are embedded for semantic search
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if offline {
+		apiKey = ""
+	}
+
+	opts := ragas.RunnerOptions{Verbose: verbose, Offline: offline, Seed: seed}
+	if dbDir != "" {
+		opts.Storage = ragas.StorageInDir(dbDir)
+	}
+
+	runner, err := ragas.NewBenchmarkRunnerWithOptions(apiKey, opts)
+	if err != nil {
+		log.Fatalf("Failed to create benchmark runner: %v", err)
+	}
+	defer runner.Close()
+
+	fmt.Println("========================================")
+	fmt.Println("HMLR Retrieval Benchmark")
+	fmt.Println("========================================")
+	fmt.Printf("Corpus: %s (%d conversations, %d queries, k=%d)\n\n", corpus.Name, len(corpus.Conversations), len(corpus.Queries), k)
+
+	result, err := runner.RunRetrieval(corpus, k)
+	if err != nil {
+		log.Fatalf("Retrieval benchmark failed: %v", err)
+	}
+
+	for _, q := range result.Queries {
+		fmt.Printf("%s\n", q.Query)
+		if q.HasBlockTargets {
+			fmt.Printf("  Blocks: P@%d %.2f  R@%d %.2f  RR %.2f\n", k, q.BlockScores.PrecisionAtK, k, q.BlockScores.RecallAtK, q.BlockScores.MRR)
+		}
+		if q.HasFactTargets {
+			fmt.Printf("  Facts:  P@%d %.2f  R@%d %.2f  RR %.2f\n", k, q.FactScores.PrecisionAtK, k, q.FactScores.RecallAtK, q.FactScores.MRR)
+		}
+	}
+
+	fmt.Println("\n========================================")
+	fmt.Printf("Blocks: P@%d %.2f  R@%d %.2f  MRR %.2f\n", k, result.Blocks.PrecisionAtK, k, result.Blocks.RecallAtK, result.Blocks.MRR)
+	fmt.Printf("Facts:  P@%d %.2f  R@%d %.2f  MRR %.2f\n", k, result.Facts.PrecisionAtK, k, result.Facts.RecallAtK, result.Facts.MRR)
+	fmt.Println("========================================")
+
+	if err := runner.ExportRetrievalResults(result, outputPath); err != nil {
+		log.Fatalf("Failed to export results: %v", err)
+	}
+}
+
+// runRouting scores the Governor on the routing suite at each threshold and prints where it misroutes
+func runRouting(suitePath, thresholdList, outputPath string, verbose bool, dbDir string, seed int64) {
+	suite := ragas.GetRoutingSuite()
+	if suitePath != "" {
+		loaded, err := ragas.LoadRoutingSuite(suitePath)
+		if err != nil {
+			log.Fatalf("Failed to load routing suite: %v", err)
+		}
+		suite = loaded
+	}
+
+	thresholds := []float64{0}
+	if thresholdList != "" {
+		thresholds = nil
+		for _, field := range strings.Split(thresholdList, ",") {
+			threshold, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil || threshold <= 0 || threshold > 1 {
+				log.Fatalf("Invalid routing threshold %q (want 0.0-1.0)", field)
+			}
+			thresholds = append(thresholds, threshold)
+		}
+	}
+
+	// Routing is deterministic and makes no LLM calls
+	opts := ragas.RunnerOptions{Verbose: verbose, Offline: true, Seed: seed}
+	if dbDir != "" {
+		opts.Storage = ragas.StorageInDir(dbDir)
+	}
+	runner, err := ragas.NewBenchmarkRunnerWithOptions("", opts)
+	if err != nil {
+		log.Fatalf("Failed to create benchmark runner: %v", err)
+	}
+	defer runner.Close()
+
+	turns := 0
+	for _, seq := range suite.Sequences {
+		turns += len(seq.Turns)
+	}
+	fmt.Println("========================================")
+	fmt.Println("HMLR Routing Benchmark")
+	fmt.Println("========================================")
+	fmt.Printf("Suite: %s (%d sequences, %d turns)\n", suite.Name, len(suite.Sequences), turns)
+
+	var results []ragas.RoutingResult
+	for _, threshold := range thresholds {
+		result, err := runner.RunRouting(suite, threshold)
+		if err != nil {
+			log.Fatalf("Routing benchmark failed: %v", err)
+		}
+		results = append(results, result)
+		printRoutingResult(result)
+	}
+
+	if len(results) > 1 {
+		fmt.Println("\n========================================")
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "THRESHOLD\tACCURACY\tSCENARIO ACCURACY")
+		_, _ = fmt.Fprintln(tw, "---------\t--------\t-----------------")
+		for _, result := range results {
+			_, _ = fmt.Fprintf(tw, "%.2f\t%.2f\t%.2f\n", result.Threshold, result.Accuracy, result.ScenarioAccuracy)
+		}
+		_ = tw.Flush()
+	}
+	fmt.Println("========================================")
+
+	if err := runner.ExportRoutingResults(results, outputPath); err != nil {
+		log.Fatalf("Failed to export results: %v", err)
+	}
+}
+
+// printRoutingResult prints accuracy, per-scenario scores, the confusion matrix, and misrouted turns
+func printRoutingResult(result ragas.RoutingResult) {
+	scenarios := ragas.RoutingScenarios()
+
+	fmt.Printf("\nThreshold %.2f: accuracy %.2f (%d/%d), scenario accuracy %.2f\n\n",
+		result.Threshold, result.Accuracy, result.Correct, result.Turns, result.ScenarioAccuracy)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SCENARIO\tEXPECTED\tPRECISION\tRECALL\tF1")
+	_, _ = fmt.Fprintln(tw, "--------\t--------\t---------\t------\t--")
+	for _, scenario := range scenarios {
+		s := result.Scenarios[scenario]
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%.2f\t%.2f\t%.2f\n", scenario, s.Expected, s.Precision, s.Recall, s.F1)
+	}
+	_ = tw.Flush()
+
+	fmt.Println("\nConfusion (rows expected, columns actual):")
+	tw = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprint(tw, "\t")
+	for _, scenario := range scenarios {
+		_, _ = fmt.Fprintf(tw, "%s\t", scenario)
+	}
+	_, _ = fmt.Fprintln(tw)
+	for _, expected := range scenarios {
+		_, _ = fmt.Fprintf(tw, "%s\t", expected)
+		for _, actual := range scenarios {
+			_, _ = fmt.Fprintf(tw, "%d\t", result.Confusion[expected][actual])
+		}
+		_, _ = fmt.Fprintln(tw)
+	}
+	_ = tw.Flush()
+
+	var misrouted []string
+	for _, seq := range result.Sequences {
+		for _, turn := range seq.Turns {
+			if turn.Correct {
+				continue
+			}
+			got := string(turn.Actual)
+			if turn.Expected == turn.Actual {
+				got += fmt.Sprintf(" into thread %q", turn.ActualThread)
+			}
+			misrouted = append(misrouted, fmt.Sprintf("  [%s %d] %q → %s (expected %s)", seq.ID, turn.Turn, turn.Message, got, turn.Expected))
+		}
+	}
+	if len(misrouted) > 0 {
+		fmt.Println("\nMisrouted:")
+		fmt.Println(strings.Join(misrouted, "\n"))
+	}
+}
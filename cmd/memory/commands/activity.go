@@ -0,0 +1,263 @@
+// ABOUTME: CLI command to show interaction history as a GitHub-style heatmap of turns per day
+// ABOUTME: Prints the heatmap in the terminal, or writes it as SVG or JSON with each week's top topics
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// activityGlyphs and activityColors are the heatmap's shades from no turns to the
+// busiest days: terminal characters and the SVG fill colors GitHub uses
+var (
+	activityGlyphs = []string{"·", "░", "▒", "▓", "█"}
+	activityColors = []string{"#ebedf0", "#9be9a8", "#40c463", "#30a14e", "#216e39"}
+)
+
+// activityRecentWeeks is how many recent weeks with turns list their top topics
+const activityRecentWeeks = 4
+
+// NewActivityCmd creates the activity command
+func NewActivityCmd() *cobra.Command {
+	var (
+		weeks      int
+		outputPath string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "activity",
+		Short: "Show a heatmap of turns per day and each week's top topics",
+		Long: `Show your interaction history as a GitHub-style heatmap: one cell per day,
+shaded by how many turns were stored that day, with a column per week (Sunday
+to Saturday). The busiest topics of recent weeks are listed underneath.
+
+Days are counted in the configured timezone, the same days topics are filed
+under.
+
+Formats:
+  (default)  The heatmap drawn in the terminal
+  svg        An SVG image; each cell's tooltip gives the day's turns
+  json       Turns for every day and the top topics of every week
+
+Examples:
+  memory activity
+  memory activity --weeks 12
+  memory activity --format svg -o activity.svg
+  memory activity --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validatePositiveInt(weeks, "weeks"); err != nil {
+				return err
+			}
+			if outputPath != "" && outputFormat != "svg" && outputFormat != "json" {
+				return fmt.Errorf("--output needs --format svg or json")
+			}
+
+			store, err := openStorage()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			activity, err := store.Activity(store.Now(), weeks)
+			if err != nil {
+				return err
+			}
+
+			var rendered string
+			switch outputFormat {
+			case "json":
+				jsonData, err := json.MarshalIndent(activity, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				rendered = string(jsonData) + "\n"
+			case "svg":
+				rendered = renderActivitySVG(activity)
+			default:
+				if !quiet {
+					printActivity(cmd.OutOrStdout(), activity)
+				}
+				return nil
+			}
+
+			if outputPath == "" {
+				_, _ = fmt.Fprint(cmd.OutOrStdout(), rendered)
+				return nil
+			}
+			if err := os.WriteFile(outputPath, []byte(rendered), 0644); err != nil {
+				return fmt.Errorf("writing %s: %w", outputPath, err)
+			}
+			if !quiet {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "✓ Wrote %d weeks of activity to %s\n", len(activity.Weeks), outputPath)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&weeks, "weeks", 52, "Number of weeks to show, ending this week")
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "Write the SVG or JSON to this file instead of stdout")
+
+	return cmd
+}
+
+// activityLevel buckets a day's turns into one of the heatmap's shades: 0 for none,
+// then quarters of the busiest day
+func activityLevel(turns, busiest int) int {
+	if turns <= 0 || busiest <= 0 {
+		return 0
+	}
+	return min(len(activityGlyphs)-1, (4*turns+busiest-1)/busiest)
+}
+
+// activityCount is n with unit, made plural unless n is 1: "1 day", "3 days"
+func activityCount(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// activityDay parses a day as the activity report gives it
+func activityDay(day string) time.Time {
+	t, _ := time.Parse("2006-01-02", day)
+	return t
+}
+
+// activityMonthLabels maps the week columns a month starts in to the month's short
+// name, skipping a month that would crowd its neighbor's label (names take 3 columns)
+func activityMonthLabels(activity *storage.Activity) map[int]string {
+	labels := make(map[int]string)
+	lastMonth, lastWeek := time.Month(0), -4
+	for i, week := range activity.Weeks {
+		// A week belongs to the month its Saturday is in
+		month := activityDay(week.Week).AddDate(0, 0, 6).Month()
+		if month == lastMonth {
+			continue
+		}
+		lastMonth = month
+		if i-lastWeek >= 4 && i+3 <= len(activity.Weeks) {
+			labels[i] = month.String()[:3]
+			lastWeek = i
+		}
+	}
+	return labels
+}
+
+// recentTopicWeeks returns the most recent weeks with any turns, newest first
+func recentTopicWeeks(activity *storage.Activity, n int) []storage.WeekActivity {
+	var weeks []storage.WeekActivity
+	for i := len(activity.Weeks) - 1; i >= 0 && len(weeks) < n; i-- {
+		if activity.Weeks[i].Turns > 0 {
+			weeks = append(weeks, activity.Weeks[i])
+		}
+	}
+	return weeks
+}
+
+// describeWeekTopics lists a week's top topics with their turns, e.g. "go (5), rust (2)"
+func describeWeekTopics(week storage.WeekActivity) string {
+	if len(week.TopTopics) == 0 {
+		return "no topic"
+	}
+	parts := make([]string, len(week.TopTopics))
+	for i, topic := range week.TopTopics {
+		parts[i] = fmt.Sprintf("%s (%d)", topic.Topic, topic.Turns)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// printActivity draws the heatmap in the terminal: a row per weekday, a column per week
+func printActivity(out io.Writer, activity *storage.Activity) {
+	header := []rune(strings.Repeat(" ", len(activity.Weeks)))
+	for week, month := range activityMonthLabels(activity) {
+		copy(header[week:], []rune(month))
+	}
+	_, _ = fmt.Fprintf(out, "    %s\n", strings.TrimRight(string(header), " "))
+
+	for weekday := 0; weekday < 7; weekday++ {
+		label := "   "
+		if weekday%2 == 1 {
+			label = time.Weekday(weekday).String()[:3]
+		}
+		var row strings.Builder
+		for week := range activity.Weeks {
+			i := week*7 + weekday
+			if i >= len(activity.Days) {
+				break
+			}
+			row.WriteString(activityGlyphs[activityLevel(activity.Days[i].Turns, activity.MaxDayTurns)])
+		}
+		_, _ = fmt.Fprintf(out, "%s %s\n", label, row.String())
+	}
+
+	_, _ = fmt.Fprintf(out, "\n%s on %s from %s to %s; longest streak %s\n", activityCount(activity.TotalTurns, "turn"),
+		activityCount(activity.ActiveDays, "day"), activity.From, activity.To, activityCount(activity.LongestStreak, "day"))
+	weeks := recentTopicWeeks(activity, activityRecentWeeks)
+	if len(weeks) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(out, "\nTop topics:")
+	for _, week := range weeks {
+		_, _ = fmt.Fprintf(out, "  Week of %s  %9s  %s\n", week.Week, activityCount(week.Turns, "turn"), describeWeekTopics(week))
+	}
+}
+
+// renderActivitySVG draws the heatmap as a standalone SVG image, GitHub style, with
+// the top topics of recent weeks underneath
+func renderActivitySVG(activity *storage.Activity) string {
+	const (
+		cell   = 11 // Cell size
+		step   = 13 // Cell size plus the gap between cells
+		left   = 32 // Room for weekday labels
+		top    = 20 // Room for month labels
+		line   = 16 // Height of a text line under the grid
+		margin = 10
+	)
+	topicWeeks := recentTopicWeeks(activity, activityRecentWeeks)
+	gridBottom := top + 7*step
+	width := left + len(activity.Weeks)*step + margin
+	height := gridBottom + margin + line*(2+len(topicWeeks))
+	width = max(width, 420)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="-apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif" font-size="10">`+"\n", width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+
+	for week, month := range activityMonthLabels(activity) {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" fill="#57606a">%s</text>`+"\n", left+week*step, top-6, month)
+	}
+	for _, weekday := range []time.Weekday{time.Monday, time.Wednesday, time.Friday} {
+		fmt.Fprintf(&b, `<text x="0" y="%d" fill="#57606a">%s</text>`+"\n", top+int(weekday)*step+cell-2, weekday.String()[:3])
+	}
+
+	for i, day := range activity.Days {
+		x, y := left+(i/7)*step, top+(i%7)*step
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"><title>%s: %s</title></rect>`+"\n",
+			x, y, cell, cell, activityColors[activityLevel(day.Turns, activity.MaxDayTurns)], day.Day, activityCount(day.Turns, "turn"))
+	}
+
+	y := gridBottom + margin + line - 4
+	fmt.Fprintf(&b, `<text x="%d" y="%d" fill="#24292f">%s</text>`+"\n", left, y, html.EscapeString(fmt.Sprintf(
+		"%s on %s, longest streak %s", activityCount(activity.TotalTurns, "turn"),
+		activityCount(activity.ActiveDays, "day"), activityCount(activity.LongestStreak, "day"))))
+	for i, color := range activityColors {
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"/>`+"\n", width-margin-(len(activityColors)-i)*step, y-cell+2, cell, cell, color)
+	}
+	for _, week := range topicWeeks {
+		y += line
+		fmt.Fprintf(&b, `<text x="%d" y="%d" fill="#57606a">%s</text>`+"\n", left, y, html.EscapeString(fmt.Sprintf(
+			"Week of %s: %s", week.Week, describeWeekTopics(week))))
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
@@ -0,0 +1,100 @@
+// ABOUTME: Tests for the activity command
+// ABOUTME: Verifies the terminal heatmap, the SVG and JSON output, and writing to a file
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+func TestActivityCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		out := &bytes.Buffer{}
+		root := NewRootCmd()
+		root.SetOut(out)
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	for _, add := range [][]string{
+		{"add", "learning go generics", "--tags", "golang"},
+		{"add", "more about go interfaces", "--tags", "golang"},
+		{"add", "the borrow checker again", "--tags", "rust"},
+	} {
+		if _, err := run(add...); err != nil {
+			t.Fatalf("%v error = %v", add, err)
+		}
+	}
+
+	out, err := run("activity", "--weeks", "4")
+	if err != nil {
+		t.Fatalf("activity error = %v", err)
+	}
+	for _, want := range []string{"Mon ", "3 turns on 1 day", "golang (2), rust (1)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("activity output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, err = run("activity", "--weeks", "4", "--format", "json")
+	if err != nil {
+		t.Fatalf("activity --format json error = %v", err)
+	}
+	var activity storage.Activity
+	if err := json.Unmarshal([]byte(out), &activity); err != nil {
+		t.Fatalf("activity JSON: %v\n%s", err, out)
+	}
+	if activity.TotalTurns != 3 || len(activity.Weeks) != 4 || len(activity.Days) < 22 {
+		t.Errorf("activity JSON = %d turns over %d weeks and %d days, want 3 over 4 weeks", activity.TotalTurns, len(activity.Weeks), len(activity.Days))
+	}
+
+	svgPath := filepath.Join(dir, "activity.svg")
+	if _, err := run("activity", "--weeks", "4", "--format", "svg", "-o", svgPath); err != nil {
+		t.Fatalf("activity --format svg error = %v", err)
+	}
+	svg, err := os.ReadFile(svgPath)
+	if err != nil {
+		t.Fatalf("reading SVG: %v", err)
+	}
+	if !strings.HasPrefix(string(svg), "<svg") || strings.Count(string(svg), "<title>") != len(activity.Days) || !strings.Contains(string(svg), ": 3 turns</title>") {
+		t.Errorf("SVG should have a cell per day, one with 3 turns:\n%s", svg)
+	}
+
+	if _, err := run("activity", "-o", svgPath); err == nil {
+		t.Error("activity -o without --format svg or json succeeded, want an error")
+	}
+	if _, err := run("activity", "--weeks", "0"); err == nil {
+		t.Error("activity --weeks 0 succeeded, want an error")
+	}
+}
+
+func TestActivityLevel(t *testing.T) {
+	for _, tt := range []struct{ turns, busiest, want int }{
+		{0, 10, 0},
+		{1, 10, 1},
+		{3, 10, 2},
+		{7, 10, 3},
+		{10, 10, 4},
+		{0, 0, 0},
+	} {
+		if got := activityLevel(tt.turns, tt.busiest); got != tt.want {
+			t.Errorf("activityLevel(%d, %d) = %d, want %d", tt.turns, tt.busiest, got, tt.want)
+		}
+	}
+}
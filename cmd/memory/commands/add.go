@@ -3,13 +3,14 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 	"time"
+	"unicode/utf8"
 
-	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
 	"github.com/harper/remember-standalone/internal/core"
@@ -20,8 +21,12 @@ import (
 )
 
 var (
-	addFile string
-	addTags []string
+	addFile     string
+	addTags     []string
+	addAttach   []string
+	addMessages string
+	addNoOrigin bool
+	addNoWait   bool
 )
 
 // NewAddCmd creates add command
@@ -31,16 +36,27 @@ func NewAddCmd() *cobra.Command {
 		Short: "Add a new memory",
 		Long: `Add a new memory from text or file.
 
+With an OpenAI key, fact extraction and embeddings run as background jobs.
+The command waits for them unless --no-wait is given, which leaves them for
+a running MCP server or 'memory jobs run'.
+
 Examples:
   memory add "Met with Alice about project X"
   memory add --file notes.txt
-  memory add --tags=meeting,project-x "Discussed timeline"`,
+  memory add --tags=meeting,project-x "Discussed timeline"
+  memory add --attach docs/design.md --attach https://example.com/spec "Reviewed the design doc"
+  memory add --messages transcript.json
+  memory add --no-wait "Quick note"`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: runAdd,
 	}
 
 	cmd.Flags().StringVar(&addFile, "file", "", "Read memory from file")
 	cmd.Flags().StringSliceVar(&addTags, "tags", []string{}, "Tags for memory (comma-separated)")
+	cmd.Flags().StringVar(&addMessages, "messages", "", "Read a JSON transcript (array of {role, content, tool_calls, ...}) as one turn")
+	cmd.Flags().StringArrayVar(&addAttach, "attach", nil, "Attach a file path or URL to the memory (repeatable); small text files are stored and embedded")
+	cmd.Flags().BoolVar(&addNoOrigin, "no-origin", false, "Don't record the device, hostname, working directory, or git repo on the memory")
+	cmd.Flags().BoolVar(&addNoWait, "no-wait", false, "Queue fact extraction and embeddings without waiting for them")
 
 	return cmd
 }
@@ -49,9 +65,19 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	// Load .env for API keys
 	_ = godotenv.Load()
 
-	// Get memory text
-	var text string
-	if addFile != "" {
+	// Get memory text, or a whole transcript
+	var (
+		text       string
+		transcript *models.Turn
+	)
+	if addMessages != "" {
+		var err error
+		transcript, err = readTranscript(addMessages)
+		if err != nil {
+			return err
+		}
+		text = transcript.UserMessage
+	} else if addFile != "" {
 		data, err := os.ReadFile(addFile)
 		if err != nil {
 			return fmt.Errorf("reading file: %w", err)
@@ -73,8 +99,13 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no text provided")
 	}
 
+	attachments, err := buildAttachments(addAttach)
+	if err != nil {
+		return err
+	}
+
 	// Initialize storage
-	store, err := storage.NewStorage()
+	store, cfg, err := openStorageWithConfig()
 	if err != nil {
 		return fmt.Errorf("initializing storage: %w", err)
 	}
@@ -82,65 +113,177 @@ func runAdd(cmd *cobra.Command, args []string) error {
 
 	// Create turn
 	turn := &models.Turn{
-		TurnID:      fmt.Sprintf("turn_%s_%s", time.Now().Format("20060102_150405"), uuid.New().String()[:8]),
+		TurnID:      models.NewID("turn"),
 		Timestamp:   time.Now(),
 		UserMessage: text,
 		AIResponse:  "",
 		Keywords:    addTags,
 		Topics:      addTags,
 	}
+	if transcript != nil {
+		turn.AIResponse = transcript.AIResponse
+		turn.Messages = transcript.Messages
+	}
+	if !addNoOrigin {
+		turn.Origin = currentOrigin(cfg.Device)
+	}
+	turn.Origin.Agent = cfg.AgentID
 
-	// Extract metadata if OpenAI is available
-	if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
-		openaiClient, err := llm.NewOpenAIClient(apiKey)
+	// Extract metadata if OpenAI is available; otherwise it is queued with the other
+	// enrichment and the turn is marked pending until a process with a key runs it
+	var openaiClient *llm.OpenAIClient
+	metadataPending := true
+	if cfg.OpenAIKey != "" {
+		client, err := llm.NewOpenAIClientWithConfig(llm.ConfigFromSettings(cfg))
 		if err != nil {
 			if verbose {
 				fmt.Fprintf(os.Stderr, "Warning: Could not initialize OpenAI client: %v\n", err)
 			}
 		} else {
-			metadata, err := openaiClient.ExtractMetadata(text)
+			openaiClient = client
+			openaiClient.SetUsageRecorder(store)
+			metadataText := text
+			if transcript != nil {
+				metadataText = turn.Text()
+			}
+			metadata, err := openaiClient.ExtractMetadata(metadataText)
 			if err != nil {
 				if verbose {
 					fmt.Fprintf(os.Stderr, "Warning: Could not extract metadata: %v\n", err)
 				}
 			} else {
-				// Extract keywords and topics from metadata
+				metadataPending = false
+				// Extract keywords, topics, and affect from metadata
 				if keywords := extractStringArray(metadata, "keywords"); len(keywords) > 0 {
 					turn.Keywords = append(turn.Keywords, keywords...)
 				}
 				if topics := extractStringArray(metadata, "topics"); len(topics) > 0 {
 					turn.Topics = append(turn.Topics, topics...)
 				}
-			}
-
-			// Extract facts
-			factScrubber := core.NewFactScrubber(openaiClient)
-			blockID, err := store.StoreTurn(turn)
-			if err != nil {
-				return fmt.Errorf("storing turn: %w", err)
-			}
-
-			if err := factScrubber.ExtractAndSave(turn, blockID, store); err != nil {
-				if verbose {
-					fmt.Fprintf(os.Stderr, "Warning: Could not extract facts: %v\n", err)
+				if label, ok := metadata["affect"].(string); ok {
+					if affect, err := models.ParseAffect(label); err == nil {
+						turn.Affect = affect
+					} else if verbose {
+						fmt.Fprintf(os.Stderr, "Warning: Ignoring metadata affect: %v\n", err)
+					}
 				}
 			}
-
-			if !quiet {
-				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Added memory %s with facts and metadata\n", turn.TurnID)
-			}
-			return nil
 		}
 	}
 
-	// Store without OpenAI features
+	turn.PendingEnrichment = openaiClient == nil || metadataPending
 	blockID, err := store.StoreTurn(turn)
 	if err != nil {
 		return fmt.Errorf("storing turn: %w", err)
 	}
+	if err := attachAll(store, turn.TurnID, attachments); err != nil {
+		return err
+	}
+
+	// Extract facts and embed the turn as jobs, so a failed call is retried later
+	queue := core.NewJobQueue(store)
+	core.RegisterJobHandlers(queue, openaiClient, nil)
+	kinds := []models.JobKind{models.JobExtractFacts, models.JobEmbedTurn}
+	if metadataPending {
+		kinds = append(kinds, models.JobExtractMetadata)
+	}
+	var jobs []*models.Job
+	for _, kind := range kinds {
+		job, err := queue.Enqueue(kind, models.TurnJob{TurnID: turn.TurnID, BlockID: blockID})
+		if err != nil {
+			return fmt.Errorf("queueing %s: %w", kind, err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if openaiClient == nil {
+		if !quiet {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Added memory %s (block: %s); facts, metadata, and embeddings queued until an OpenAI key is available\n", turn.TurnID, blockID)
+		}
+		return nil
+	}
+
+	if addNoWait {
+		if !quiet {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Added memory %s (block: %s); facts and embeddings queued\n", turn.TurnID, blockID)
+		}
+		return nil
+	}
+
+	for _, job := range jobs {
+		if err := queue.Run(cmd.Context(), job.ID); err != nil && verbose {
+			fmt.Fprintf(os.Stderr, "Warning: %s job %d failed and will be retried: %v\n", job.Kind, job.ID, err)
+		}
+	}
 
 	if !quiet {
-		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Added memory %s (block: %s)\n", turn.TurnID, blockID)
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Added memory %s with facts and metadata\n", turn.TurnID)
+	}
+	return nil
+}
+
+// readTranscript reads a JSON array of role-tagged messages from path ("-" for stdin)
+func readTranscript(path string) (*models.Turn, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading transcript: %w", err)
+	}
+
+	var messages []models.Message
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("parsing transcript: %w", err)
+	}
+	turn, err := models.NewTurnFromMessages(messages)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transcript: %w", err)
+	}
+	return turn, nil
+}
+
+// buildAttachments turns --attach references into attachments. Local text files up to
+// the attachment size limit carry their content; anything else is stored by reference.
+func buildAttachments(refs []string) ([]*models.Attachment, error) {
+	attachments := make([]*models.Attachment, 0, len(refs))
+	for _, ref := range refs {
+		var content string
+		if models.InferAttachmentKind(ref) == models.AttachmentFile {
+			info, err := os.Stat(ref)
+			if err != nil {
+				return nil, fmt.Errorf("reading attachment: %w", err)
+			}
+			if info.Size() <= models.MaxAttachmentContent {
+				data, err := os.ReadFile(ref)
+				if err != nil {
+					return nil, fmt.Errorf("reading attachment: %w", err)
+				}
+				if utf8.Valid(data) {
+					content = string(data)
+				}
+			}
+		}
+		a, err := models.NewAttachment(ref, "", content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attachment %q: %w", ref, err)
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, nil
+}
+
+// attachAll stores attachments on a saved turn
+func attachAll(store *storage.Storage, turnID string, attachments []*models.Attachment) error {
+	for _, a := range attachments {
+		if err := store.AttachToTurn(turnID, a); err != nil {
+			return fmt.Errorf("attaching %s: %w", a.Name, err)
+		}
 	}
 	return nil
 }
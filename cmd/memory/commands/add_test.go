@@ -4,7 +4,13 @@
 package commands
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
 )
 
 func TestNewAddCmd(t *testing.T) {
@@ -153,3 +159,142 @@ func TestAddCmd_Examples(t *testing.T) {
 		t.Error("Long description should mention --tags flag")
 	}
 }
+
+func TestAddCmd_Attach(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	doc := filepath.Join(dir, "design.md")
+	if err := os.WriteFile(doc, []byte("# Design\nUse a cache"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	root := NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetArgs([]string{"add", "--attach", doc, "--attach", "https://example.com/rfc", "Reviewed the design"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("add --attach: %v", err)
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	blocks, err := store.GetActiveBridgeBlocks()
+	if err != nil || len(blocks) != 1 {
+		t.Fatalf("GetActiveBridgeBlocks() = %d blocks, %v; want 1", len(blocks), err)
+	}
+	attachments, err := store.GetBlockAttachments(blocks[0].BlockID)
+	if err != nil || len(attachments) != 2 {
+		t.Fatalf("GetBlockAttachments() = %+v, %v; want 2", attachments, err)
+	}
+	if attachments[0].Name != "design.md" || attachments[0].Content != "# Design\nUse a cache" {
+		t.Errorf("file attachment = %+v, want design.md with its content", attachments[0])
+	}
+	if attachments[1].Ref != "https://example.com/rfc" || attachments[1].Content != "" {
+		t.Errorf("URL attachment = %+v, want the URL by reference", attachments[1])
+	}
+
+	root = NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"add", "--attach", filepath.Join(dir, "missing.md"), "text"})
+	if err := root.Execute(); err == nil {
+		t.Error("add --attach with a missing file should fail")
+	}
+}
+
+func TestAddCmd_Messages(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	transcript := filepath.Join(dir, "transcript.json")
+	data := `[
+		{"role": "system", "content": "You are a helpful agent"},
+		{"role": "user", "content": "Find the release notes"},
+		{"role": "assistant", "tool_calls": [{"id": "c1", "type": "function", "function": {"name": "search", "arguments": "{\"q\":\"release notes\"}"}}]},
+		{"role": "tool", "tool_call_id": "c1", "content": "notes.md"},
+		{"role": "assistant", "content": "They are in notes.md"}
+	]`
+	if err := os.WriteFile(transcript, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	root := NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetArgs([]string{"add", "--messages", transcript})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("add --messages: %v", err)
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	blocks, err := store.GetActiveBridgeBlocks()
+	if err != nil || len(blocks) != 1 {
+		t.Fatalf("GetActiveBridgeBlocks() = %d blocks, %v; want 1", len(blocks), err)
+	}
+	block, err := store.GetBridgeBlock(blocks[0].BlockID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	turn := block.Turns[0]
+	if len(turn.Messages) != 5 || turn.Messages[2].ToolCalls[0].Name != "search" {
+		t.Fatalf("Messages = %+v, want the transcript with its tool call", turn.Messages)
+	}
+	if turn.UserMessage != "Find the release notes" || turn.AIResponse != "They are in notes.md" {
+		t.Errorf("turn = %q / %q", turn.UserMessage, turn.AIResponse)
+	}
+}
+
+func TestAddCmd_NoKeyQueuesEnrichment(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	root := NewRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"add", "Switched the build to Bazel"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if !strings.Contains(out.String(), "queued until an OpenAI key is available") {
+		t.Errorf("add output = %q, want enrichment queued", out.String())
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts, err := store.JobCounts()
+	if err != nil || counts[models.JobPending] != 3 {
+		t.Errorf("JobCounts() = %v, %v; want facts, embedding, and metadata jobs pending", counts, err)
+	}
+	if n, err := store.PendingEnrichmentCount(); err != nil || n != 1 {
+		t.Errorf("PendingEnrichmentCount() = %d, %v; want 1", n, err)
+	}
+	_ = store.Close()
+
+	root = NewRootCmd()
+	out.Reset()
+	root.SetOut(&out)
+	root.SetArgs([]string{"doctor"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("doctor: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "1 turns waiting on metadata, embeddings, or facts") {
+		t.Errorf("doctor output = %q, want the pending turn reported", out.String())
+	}
+}
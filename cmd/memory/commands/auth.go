@@ -0,0 +1,213 @@
+// ABOUTME: Auth command for storing provider API keys in the OS keychain
+// ABOUTME: Keeps keys out of .env files; environment variables still take precedence
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/harper/remember-standalone/internal/config"
+	"github.com/harper/remember-standalone/internal/keychain"
+	"github.com/spf13/cobra"
+)
+
+// NewAuthCmd creates the auth command group
+func NewAuthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage provider API keys in the OS keychain",
+		Long: `Manage provider API keys stored in the OS keychain.
+
+Keys are saved in the macOS Keychain or the Linux Secret Service (via
+secret-tool) under the service "memory", so they don't have to live in .env
+files next to the database. An environment variable such as OPENAI_API_KEY
+still overrides the keychain.
+
+Examples:
+  memory auth set openai
+  echo "$KEY" | memory auth set openai
+  memory auth status`,
+	}
+
+	cmd.AddCommand(newAuthSetCmd())
+	cmd.AddCommand(newAuthDeleteCmd())
+	cmd.AddCommand(newAuthStatusCmd())
+
+	return cmd
+}
+
+func newAuthSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <provider>",
+		Short: "Save a provider's API key in the keychain (read from stdin)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			provider := args[0]
+			if err := validateProvider(provider); err != nil {
+				return err
+			}
+
+			key, err := readSecret(cmd, fmt.Sprintf("%s API key: ", provider))
+			if err != nil {
+				return err
+			}
+			if key == "" {
+				return fmt.Errorf("no API key given")
+			}
+
+			if err := keychain.Set(provider, key); err != nil {
+				return err
+			}
+			if !quiet {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Saved %s API key to the keychain\n", provider)
+				if os.Getenv(config.ProviderKeyEnv[provider]) != "" {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Note: %s is set and overrides the keychain\n", config.ProviderKeyEnv[provider])
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func newAuthDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <provider>",
+		Short: "Remove a provider's API key from the keychain",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			provider := args[0]
+			if err := validateProvider(provider); err != nil {
+				return err
+			}
+			if err := keychain.Delete(provider); err != nil {
+				return err
+			}
+			if !quiet {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Removed %s API key from the keychain\n", provider)
+			}
+			return nil
+		},
+	}
+}
+
+// authStatus is one row of `auth status`
+type authStatus struct {
+	Provider string        `json:"provider"`
+	Env      string        `json:"env"`
+	Key      string        `json:"key"`
+	Source   config.Source `json:"source"`
+}
+
+func newAuthStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show where each provider's API key comes from",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var rows []authStatus
+			for _, provider := range providerNames() {
+				key, source := config.ProviderKey(provider)
+				rows = append(rows, authStatus{
+					Provider: provider,
+					Env:      config.ProviderKeyEnv[provider],
+					Key:      maskKey(key),
+					Source:   source,
+				})
+			}
+
+			if outputFormat == "json" {
+				jsonData, err := json.MarshalIndent(rows, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintf(w, "PROVIDER\tKEY\tSOURCE\tENV\n")
+			_, _ = fmt.Fprintf(w, "--------\t---\t------\t---\n")
+			for _, row := range rows {
+				key, source := row.Key, string(row.Source)
+				if key == "" {
+					key, source = "(not set)", "-"
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", row.Provider, key, source, row.Env)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// providerNames lists the providers that take an API key, sorted
+func providerNames() []string {
+	names := make([]string, 0, len(config.ProviderKeyEnv))
+	for name := range config.ProviderKeyEnv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateProvider rejects providers memory has no API key for
+func validateProvider(provider string) error {
+	if _, ok := config.ProviderKeyEnv[provider]; !ok {
+		return fmt.Errorf("unknown provider %q (supported: %s)", provider, strings.Join(providerNames(), ", "))
+	}
+	return nil
+}
+
+// maskKey shows only the last four characters of an API key
+func maskKey(key string) string {
+	if key == "" {
+		return ""
+	}
+	if len(key) <= 8 {
+		return "****"
+	}
+	return "****" + key[len(key)-4:]
+}
+
+// readSecret reads one line from stdin, prompting with echo turned off when stdin is a terminal
+func readSecret(cmd *cobra.Command, prompt string) (string, error) {
+	in := cmd.InOrStdin()
+	if f, ok := in.(*os.File); ok && isTerminal(f) {
+		_, _ = fmt.Fprint(cmd.ErrOrStderr(), prompt)
+		if setEcho(f, false) == nil {
+			defer func() {
+				_ = setEcho(f, true)
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr())
+			}()
+		}
+	}
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("reading API key: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// setEcho turns terminal echo on or off with stty, which avoids a terminal library dependency
+func setEcho(f *os.File, on bool) error {
+	mode := "-echo"
+	if on {
+		mode = "echo"
+	}
+	stty := exec.Command("stty", mode)
+	stty.Stdin = f
+	return stty.Run()
+}
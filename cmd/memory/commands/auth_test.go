@@ -0,0 +1,70 @@
+// ABOUTME: Tests for auth command
+// ABOUTME: Verifies subcommands, provider validation, key masking, and status output
+
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewAuthCmd(t *testing.T) {
+	cmd := NewAuthCmd()
+
+	if cmd.Use != "auth" {
+		t.Errorf("Use = %q, want %q", cmd.Use, "auth")
+	}
+	for _, name := range []string{"set", "delete", "status"} {
+		if sub, _, err := cmd.Find([]string{name}); err != nil || sub == cmd {
+			t.Errorf("subcommand %q not found", name)
+		}
+	}
+}
+
+func TestAuthSet_UnknownProvider(t *testing.T) {
+	root := NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetIn(strings.NewReader("sk-test\n"))
+	root.SetArgs([]string{"auth", "set", "nope"})
+
+	err := root.Execute()
+	if err == nil || !strings.Contains(err.Error(), "unknown provider") {
+		t.Errorf("Execute() error = %v, want unknown provider", err)
+	}
+}
+
+func TestMaskKey(t *testing.T) {
+	tests := map[string]string{
+		"":                        "",
+		"short":                   "****",
+		"sk-abcdefghijklmnop1234": "****1234",
+	}
+	for key, want := range tests {
+		if got := maskKey(key); got != want {
+			t.Errorf("maskKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestAuthStatus_FromEnv(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-test-key-9876")
+	defer func() { outputFormat = "auto" }()
+
+	root := NewRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"auth", "status"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	got := out.String()
+	if !strings.Contains(got, "****9876") || !strings.Contains(got, "env") {
+		t.Errorf("status should show the masked env key:\n%s", got)
+	}
+	if strings.Contains(got, "sk-test-key-9876") {
+		t.Errorf("status leaked the full key:\n%s", got)
+	}
+}
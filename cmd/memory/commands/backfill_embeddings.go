@@ -0,0 +1,153 @@
+// ABOUTME: CLI command to embed turns stored without embeddings, e.g. before an API key was configured
+// ABOUTME: Chunks and embeds only turns with no embeddings at all, in rate-limited batches that resume after interruption
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/llm"
+)
+
+// NewBackfillEmbeddingsCmd creates the backfill-embeddings command
+func NewBackfillEmbeddingsCmd() *cobra.Command {
+	var (
+		batchSize int
+		rate      int
+		dryRun    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "backfill-embeddings",
+		Short: "Embed turns stored without embeddings",
+		Long: `Embed turns that have no embeddings at all, so semantic search can find them.
+
+Turns stored before an OpenAI key was configured (or while the API was
+unreachable) are kept without embeddings, and only keyword search sees them.
+This chunks each such turn and embeds it with embedding_model. Chunks are sent
+to the API in batches and requests are rate limited. Each turn is saved as soon
+as its batch completes, so an interrupted run resumes where it stopped when you
+run the command again.
+
+Turns already embedded, with any model, are left alone; to move every turn to
+a new model, use 'memory reembed' instead.
+
+Requires OPENAI_API_KEY (or a key saved with 'memory auth set openai').
+
+Examples:
+  memory backfill-embeddings --dry-run
+  memory backfill-embeddings
+  memory backfill-embeddings --batch-size 50 --rate 30`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validatePositiveInt(batchSize, "batch-size"); err != nil {
+				return err
+			}
+			if rate < 0 {
+				return fmt.Errorf("rate must not be negative, got %d", rate)
+			}
+
+			// Load .env for API keys
+			_ = godotenv.Load()
+
+			store, cfg, err := openStorageWithConfig()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			report := func(progress core.ReembedProgress) error {
+				return printBackfillResult(cmd, cfg.EmbeddingModel, dryRun, progress)
+			}
+
+			if dryRun {
+				pending, err := store.TurnsNeedingEmbedding("")
+				if err != nil {
+					return fmt.Errorf("finding turns without embeddings: %w", err)
+				}
+				return report(core.ReembedProgress{TotalTurns: len(pending)})
+			}
+
+			if cfg.OpenAIKey == "" {
+				return fmt.Errorf("OPENAI_API_KEY is required to generate embeddings (or run 'memory auth set openai')")
+			}
+			client, err := llm.NewOpenAIClientWithConfig(llm.ConfigFromSettings(cfg))
+			if err != nil {
+				return fmt.Errorf("initializing OpenAI client: %w", err)
+			}
+			client.SetUsageRecorder(store)
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			var onProgress func(core.ReembedProgress)
+			if !quiet && outputFormat != "json" {
+				errOut := cmd.ErrOrStderr()
+				onProgress = func(p core.ReembedProgress) {
+					renderProgressBar(errOut, p.DoneTurns, p.TotalTurns, "turns")
+				}
+			}
+
+			progress, err := core.NewReembedder(store, client, batchSize, rate).MissingOnly().Run(ctx, onProgress)
+			if onProgress != nil && progress.TotalTurns > 0 {
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr())
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return fmt.Errorf("interrupted after %d/%d turns; run again to resume", progress.DoneTurns, progress.TotalTurns)
+				}
+				return fmt.Errorf("backfill stopped after %d/%d turns (run again to resume): %w", progress.DoneTurns, progress.TotalTurns, err)
+			}
+
+			return report(progress)
+		},
+	}
+
+	cmd.Flags().IntVar(&batchSize, "batch-size", 100, "Maximum chunks per embedding request")
+	cmd.Flags().IntVar(&rate, "rate", 60, "Maximum requests per minute (0 = unlimited)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show how many turns have no embeddings without calling the API")
+
+	return cmd
+}
+
+// printBackfillResult reports the outcome of a backfill-embeddings run
+func printBackfillResult(cmd *cobra.Command, model string, dryRun bool, progress core.ReembedProgress) error {
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(map[string]interface{}{
+			"model":       model,
+			"dry_run":     dryRun,
+			"total_turns": progress.TotalTurns,
+			"done_turns":  progress.DoneTurns,
+			"chunks":      progress.Chunks,
+			"requests":    progress.Requests,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	if quiet {
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	switch {
+	case progress.TotalTurns == 0:
+		_, _ = fmt.Fprintln(out, "Every turn has embeddings")
+	case dryRun:
+		_, _ = fmt.Fprintf(out, "%d turns have no embeddings; they would be embedded with %s\n", progress.TotalTurns, model)
+	default:
+		_, _ = fmt.Fprintf(out, "✓ Embedded %d turns (%d chunks, %d requests) with %s\n",
+			progress.DoneTurns, progress.Chunks, progress.Requests, model)
+	}
+	return nil
+}
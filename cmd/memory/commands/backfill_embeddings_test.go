@@ -0,0 +1,86 @@
+// ABOUTME: Tests for the backfill-embeddings command
+// ABOUTME: Verifies flags, the dry-run count of turns without embeddings, and the API key requirement
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestBackfillEmbeddingsCmd_Flags(t *testing.T) {
+	cmd := NewBackfillEmbeddingsCmd()
+	for name, defVal := range map[string]string{"batch-size": "100", "rate": "60", "dry-run": "false"} {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			t.Errorf("--%s flag not found", name)
+			continue
+		}
+		if flag.DefValue != defVal {
+			t.Errorf("--%s default = %q, want %q", name, flag.DefValue, defVal)
+		}
+	}
+}
+
+func TestBackfillEmbeddingsCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		out := &bytes.Buffer{}
+		root := NewRootCmd()
+		root.SetOut(out)
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("backfill-embeddings", "--dry-run")
+	if err != nil {
+		t.Fatalf("backfill-embeddings --dry-run error = %v", err)
+	}
+	if !strings.Contains(out, "Every turn has embeddings") {
+		t.Errorf("output on an empty database = %q", out)
+	}
+
+	// Turns stored without a key have no embeddings
+	store, err := openStorage()
+	if err != nil {
+		t.Fatalf("openStorage() error = %v", err)
+	}
+	for _, id := range []string{"turn_1", "turn_2"} {
+		if _, err := store.StoreTurn(&models.Turn{TurnID: id, UserMessage: "notes from before the key"}); err != nil {
+			t.Fatalf("StoreTurn() error = %v", err)
+		}
+	}
+	_ = store.Close()
+
+	out, err = run("backfill-embeddings", "--dry-run", "--format", "json")
+	if err != nil {
+		t.Fatalf("backfill-embeddings --dry-run --format json error = %v", err)
+	}
+	var result struct {
+		Model      string `json:"model"`
+		DryRun     bool   `json:"dry_run"`
+		TotalTurns int    `json:"total_turns"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	if !result.DryRun || result.TotalTurns != 2 || result.Model != "text-embedding-3-small" {
+		t.Errorf("dry run = %+v, want 2 turns for text-embedding-3-small", result)
+	}
+
+	if _, err := run("backfill-embeddings"); err == nil || !strings.Contains(err.Error(), "OPENAI_API_KEY") {
+		t.Errorf("backfill-embeddings without a key error = %v, want one asking for OPENAI_API_KEY", err)
+	}
+}
@@ -0,0 +1,172 @@
+// ABOUTME: CLI command to compact old archived topics, replacing turn bodies with summary and facts
+// ABOUTME: Lists what it would compact with --dry-run, and restores turns kept compressed
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// NewCompactCmd creates the compact command
+func NewCompactCmd() *cobra.Command {
+	var (
+		olderThan time.Duration
+		policy    string
+		dryRun    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Replace the turns of old archived topics with their summary and facts",
+		Long: `Compact ARCHIVED topics last updated longer ago than --older-than
+(default: compact_after), shrinking the database while keeping what recall
+needs.
+
+Each topic's first turn is rewritten to hold its summary and the facts
+learned from it, and its other turns are emptied. Turns keep their times,
+origins, annotations, and attachments, and topics keep their keywords and
+tags, so searches still find them; the turns' embeddings are deleted.
+Secret facts are left out of the digest. Topics kept by a retention policy
+(see memory retention) are never compacted.
+
+--policy decides what happens to the original turns:
+
+  compress  Keep them gzipped in the database; 'memory compact restore'
+            puts them back (the default, or compact_policy)
+  drop      Discard them for good
+
+MCP servers compact on their own while pruning when compact_after is set.
+
+Examples:
+  memory compact --older-than 2160h --dry-run
+  memory compact --older-than 2160h --policy drop
+  memory compact restore block_20250101_093000`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, cfg, err := openStorageWithConfig()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			if !cmd.Flags().Changed("older-than") {
+				olderThan = cfg.CompactAfter
+			}
+			if olderThan <= 0 {
+				return fmt.Errorf("give --older-than or set compact_after")
+			}
+			compactPolicy := cfg.CompactPolicy
+			if cmd.Flags().Changed("policy") {
+				if compactPolicy, err = models.ParseCompactPolicy(policy); err != nil {
+					return err
+				}
+			}
+
+			var blocks []storage.CompactedBlock
+			if dryRun {
+				blocks, err = store.CompactionPlan(olderThan)
+			} else {
+				blocks, err = store.CompactArchived(olderThan, compactPolicy)
+			}
+			if err != nil {
+				return fmt.Errorf("compacting: %w", err)
+			}
+
+			if outputFormat == "json" {
+				jsonData, err := json.MarshalIndent(blocks, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+				return nil
+			}
+
+			out := cmd.OutOrStdout()
+			if len(blocks) > 0 && (dryRun || !quiet) {
+				printCompactedBlocks(out, blocks)
+			}
+			if !quiet {
+				var freed int64
+				for _, block := range blocks {
+					freed += block.Bytes
+				}
+				switch {
+				case dryRun:
+					_, _ = fmt.Fprintf(out, "Would compact %d archived topics (about %s)\n", len(blocks), formatBytes(freed))
+				case compactPolicy == models.CompactDrop:
+					_, _ = fmt.Fprintf(out, "✓ Compacted %d archived topics (about %s), dropping their turns\n", len(blocks), formatBytes(freed))
+				default:
+					_, _ = fmt.Fprintf(out, "✓ Compacted %d archived topics (about %s), keeping their turns compressed\n", len(blocks), formatBytes(freed))
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&olderThan, "older-than", 0, "Compact archived topics last updated longer ago than this (default: compact_after)")
+	cmd.Flags().StringVar(&policy, "policy", "", "What to do with the original turns: compress or drop (default: compact_policy)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the topics that would be compacted without compacting them")
+
+	cmd.AddCommand(newCompactRestoreCmd())
+
+	return cmd
+}
+
+// newCompactRestoreCmd creates the compact restore subcommand
+func newCompactRestoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <block-id>",
+		Short: "Put back the turns of a topic compacted with the compress policy",
+		Long: `Put back the original turns of a topic compacted with the compress
+policy. Their embeddings are not restored; run 'memory reembed' to
+regenerate them.
+
+Examples:
+  memory compact restore block_20250101_093000`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openStorage()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			restored, err := store.RestoreCompacted(args[0])
+			if err != nil {
+				return err
+			}
+
+			if outputFormat == "json" {
+				jsonData, err := json.MarshalIndent(map[string]interface{}{"block_id": args[0], "restored_turns": restored}, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+				return nil
+			}
+			if !quiet {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Restored %d turns of %s; run 'memory reembed' to embed them again\n", restored, args[0])
+			}
+			return nil
+		},
+	}
+}
+
+// printCompactedBlocks lists compacted blocks as a table
+func printCompactedBlocks(out io.Writer, blocks []storage.CompactedBlock) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "BLOCK\tTOPIC\tTURNS\tSIZE\tUPDATED\n")
+	for _, block := range blocks {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", block.BlockID, truncate(block.TopicLabel, 30),
+			block.Turns, formatBytes(block.Bytes), formatTime(block.UpdatedAt))
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,89 @@
+// ABOUTME: Tests for the compact command
+// ABOUTME: Verifies dry runs, compaction of archived topics, restoring them, and pruning with compact_after
+
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestCompactCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("MEMORY_MAX_DB_SIZE", "")
+	t.Setenv("MEMORY_COMPACT_AFTER", "")
+	t.Setenv("MEMORY_COMPACT_POLICY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_compact", Timestamp: time.Now(), UserMessage: strings.Repeat("old ", 2000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateBridgeBlockStatus(blockID, models.StatusArchived); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+	time.Sleep(time.Millisecond)
+
+	if _, err := run("compact"); err == nil || !strings.Contains(err.Error(), "compact_after") {
+		t.Errorf("compact without an age error = %v, want it asked for", err)
+	}
+	if _, err := run("compact", "--older-than", "1ns", "--policy", "shred"); err == nil {
+		t.Error("compact --policy shred succeeded, want an error")
+	}
+	out, err := run("compact", "--older-than", "1ns", "--dry-run")
+	if err != nil || !strings.Contains(out, blockID) || !strings.Contains(out, "Would compact 1 archived topics") {
+		t.Errorf("compact --dry-run = %q, %v; want the archived topic listed", out, err)
+	}
+	out, err = run("compact", "--older-than", "1ns")
+	if err != nil || !strings.Contains(out, "✓ Compacted 1 archived topics") || !strings.Contains(out, "keeping their turns compressed") {
+		t.Errorf("compact = %q, %v; want the topic compacted", out, err)
+	}
+	if out, err := run("stats"); err != nil || !strings.Contains(out, "1 restorable") {
+		t.Errorf("stats = %q, %v; want the compaction counted", out, err)
+	}
+
+	out, err = run("compact", "restore", blockID)
+	if err != nil || !strings.Contains(out, "✓ Restored 1 turns of "+blockID) {
+		t.Errorf("compact restore = %q, %v; want the turn restored", out, err)
+	}
+	if _, err := run("compact", "restore", blockID); err == nil {
+		t.Error("compact restore twice succeeded, want an error")
+	}
+
+	// Pruning compacts on its own once compact_after is set
+	t.Setenv("MEMORY_COMPACT_AFTER", "1ns")
+	t.Setenv("MEMORY_COMPACT_POLICY", "drop")
+	if out, err := run("prune", "--dry-run"); err != nil || !strings.Contains(out, "Would compact 1 archived topics") {
+		t.Errorf("prune --dry-run = %q, %v; want the topic due for compaction", out, err)
+	}
+	if out, err := run("prune"); err != nil || !strings.Contains(out, "✓ Compacted 1 archived topics") {
+		t.Errorf("prune = %q, %v; want the topic compacted", out, err)
+	}
+	if _, err := run("compact", "restore", blockID); err == nil || !strings.Contains(err.Error(), "drop policy") {
+		t.Errorf("compact restore after dropping error = %v, want it refused", err)
+	}
+}
@@ -0,0 +1,225 @@
+// ABOUTME: Config command showing the config file and the merged effective settings
+// ABOUTME: Also provides the config loading and storage opening shared by all commands
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/harper/remember-standalone/internal/config"
+	"github.com/harper/remember-standalone/internal/events"
+	"github.com/harper/remember-standalone/internal/signing"
+	"github.com/harper/remember-standalone/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var configShowEffective bool
+
+// loadConfig reads the --config file (or the default one) with environment overrides,
+// then applies --data-dir and --context
+func loadConfig() (*config.Config, error) {
+	path := configPath
+	if path == "" {
+		path = config.DefaultPath()
+	}
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if dataDir != "" {
+		if err := cfg.Override("data_dir", dataDir); err != nil {
+			return nil, err
+		}
+	}
+	if contextName != "" {
+		cfg.Context = contextName
+	}
+	displayLocation = cfg.Location
+	return cfg, nil
+}
+
+// openStorage opens the database of the current context
+func openStorage() (*storage.Storage, error) {
+	store, _, err := openStorageWithConfig()
+	return store, err
+}
+
+// openStorageWithConfig opens the current context's database and returns the config it came from
+func openStorageWithConfig() (*storage.Storage, *config.Config, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading config: %w", err)
+	}
+	store, err := openContextStorage(cfg, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, cfg, nil
+}
+
+// signingKeyPath is the local signing key, kept beside the config file so it outlasts a wipe
+func signingKeyPath(cfg *config.Config) string {
+	return filepath.Join(filepath.Dir(cfg.Path), signing.FileName)
+}
+
+// openContextStorage opens the database of cfg's context, read-only when readOnly is set.
+// It refuses a data directory it cannot write to and warns about network filesystems.
+func openContextStorage(cfg *config.Config, readOnly bool) (*storage.Storage, error) {
+	if err := cfg.SetContext(cfg.Context); err != nil {
+		return nil, err
+	}
+	status := config.CheckDataDir(cfg.ContextDir(cfg.Context))
+	if warning := status.Warning(); warning != "" && !quiet {
+		log.Printf("Warning: %s", warning)
+	}
+	var store *storage.Storage
+	var err error
+	if readOnly {
+		store, err = storage.NewStorageReadOnly(cfg.DBPath())
+	} else {
+		if err := status.Problem(); err != nil {
+			return nil, err
+		}
+		store, err = storage.NewStorageWithPath(cfg.DBPath())
+	}
+	if err != nil {
+		return nil, err
+	}
+	store.SetLocation(cfg.Location)
+	store.SetSlowQueryThreshold(cfg.SlowQueryThreshold)
+	store.SetFactReview(cfg.FactReview)
+	store.SetUndoLogSize(cfg.UndoLogSize)
+	store.SetFactLimits(cfg.FactLimits())
+	store.SetTopicTaxonomy(cfg.TopicTaxonomy)
+	// Queue a webhook delivery for each memory event; servers and 'memory jobs run' send them
+	if hooks := webhooks(cfg); hooks != nil && !readOnly {
+		hooks.Attach(store)
+	}
+	return store, nil
+}
+
+// webhooks returns the configured webhooks, or nil when there are none
+func webhooks(cfg *config.Config) *events.Webhooks {
+	if len(cfg.WebhookURLs) == 0 {
+		return nil
+	}
+	return events.NewWebhooks(cfg.WebhookURLs, cfg.WebhookEvents, cfg.WebhookSecret)
+}
+
+// NewConfigCmd creates the config command group
+func NewConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show configuration",
+		Long: `Show memory configuration.
+
+Settings are read from ~/.config/memory/config.yaml (or $XDG_CONFIG_HOME,
+$MEMORY_CONFIG, or --config). Environment variables override the file.`,
+	}
+
+	cmd.AddCommand(newConfigShowCmd())
+
+	return cmd
+}
+
+func newConfigShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the config file, or every effective setting",
+		Example: `  # Settings in the config file
+  memory config show
+
+  # Merged result of defaults, config file, and environment
+  memory config show --effective`,
+		Args: cobra.NoArgs,
+		RunE: runConfigShow,
+	}
+
+	cmd.Flags().BoolVar(&configShowEffective, "effective", false, "Show every setting after merging defaults, file, and environment")
+
+	return cmd
+}
+
+// effectiveSetting is one row of `config show --effective`
+type effectiveSetting struct {
+	Key    string        `json:"key"`
+	Value  string        `json:"value"`
+	Source config.Source `json:"source"`
+	Env    string        `json:"env"`
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	if !configShowEffective {
+		path := configPath
+		if path == "" {
+			path = config.DefaultPath()
+		}
+		values, err := config.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if outputFormat == "json" {
+			jsonData, err := json.MarshalIndent(values, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			_, _ = fmt.Fprintf(out, "%s\n", jsonData)
+			return nil
+		}
+
+		_, _ = fmt.Fprintf(out, "Config file: %s\n", path)
+		if len(values) == 0 {
+			_, _ = fmt.Fprintf(out, "No settings (defaults apply; see --effective)\n")
+			return nil
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "\nKEY\tVALUE\n")
+		_, _ = fmt.Fprintf(w, "---\t-----\n")
+		for _, s := range config.Settings() {
+			if v, ok := values[s.Key]; ok {
+				_, _ = fmt.Fprintf(w, "%s\t%s\n", s.Key, v)
+			}
+		}
+		return w.Flush()
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	var rows []effectiveSetting
+	for _, s := range config.Settings() {
+		rows = append(rows, effectiveSetting{Key: s.Key, Value: cfg.Value(s), Source: cfg.Source(s.Key), Env: s.Env})
+	}
+	contextSource := cfg.Source("context")
+	if contextName != "" {
+		contextSource = config.SourceFlag
+	}
+	rows = append(rows, effectiveSetting{Key: "context", Value: cfg.Context, Source: contextSource, Env: "MEMORY_CONTEXT"})
+	rows = append(rows, effectiveSetting{Key: "openai_api_key", Value: maskKey(cfg.OpenAIKey), Source: cfg.Source("openai_api_key"), Env: config.ProviderKeyEnv["openai"]})
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(out, "%s\n", jsonData)
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(out, "Config file: %s\n\n", cfg.Path)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "KEY\tVALUE\tSOURCE\tENV\n")
+	_, _ = fmt.Fprintf(w, "---\t-----\t------\t---\n")
+	for _, row := range rows {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", row.Key, row.Value, row.Source, row.Env)
+	}
+	return w.Flush()
+}
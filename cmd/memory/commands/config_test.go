@@ -0,0 +1,81 @@
+// ABOUTME: Tests for config command
+// ABOUTME: Verifies command structure and file vs effective output
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewConfigCmd(t *testing.T) {
+	cmd := NewConfigCmd()
+
+	if cmd.Use != "config" {
+		t.Errorf("Use = %q, want %q", cmd.Use, "config")
+	}
+
+	show, _, err := cmd.Find([]string{"show"})
+	if err != nil || show.Use != "show" {
+		t.Fatalf("show subcommand not found: %v", err)
+	}
+	if show.Flags().Lookup("effective") == nil {
+		t.Error("--effective flag not found")
+	}
+}
+
+func TestConfigShow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("chat_model: gpt-4o\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TOPIC_MATCH_THRESHOLD", "0.5")
+	t.Setenv("MEMORY_OPENAI_MODEL", "")
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "file only",
+			args:    []string{"config", "show", "--config", path},
+			want:    []string{"chat_model", "gpt-4o"},
+			notWant: []string{"topic_match_threshold"},
+		},
+		{
+			name: "effective",
+			args: []string{"config", "show", "--effective", "--config", path},
+			want: []string{"gpt-4o", "file", "topic_match_threshold", "0.5", "env", "text-embedding-3-small", "default"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configShowEffective = false
+			root := NewRootCmd()
+			var out bytes.Buffer
+			root.SetOut(&out)
+			root.SetArgs(tt.args)
+			if err := root.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, out.String())
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out.String(), notWant) {
+					t.Errorf("output should not contain %q:\n%s", notWant, out.String())
+				}
+			}
+		})
+	}
+	configPath = ""
+}
@@ -0,0 +1,264 @@
+// ABOUTME: Context command for named memory workspaces and the prompt context hydrated from them
+// ABOUTME: Creates, lists, and switches between contexts, and dumps context packs for prompt debugging
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/chatproxy"
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/llm"
+	"github.com/harper/remember-standalone/internal/rest"
+)
+
+// NewContextCmd creates the context command group
+func NewContextCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "Manage memory contexts (separate databases)",
+		Long: `Manage named memory contexts.
+
+Each context has its own database, so memories stored in one never show up in
+another. The "default" context is the database in data_dir; others live in
+data_dir/contexts/<name>.
+
+The current context is chosen by --context, then $MEMORY_CONTEXT, then
+'memory context use'.
+
+Examples:
+  memory context create work
+  memory context use work
+  memory --context personal search "birthday"
+  memory context dump --query "what editor do I use?" --out pack.json`,
+	}
+
+	cmd.AddCommand(newContextListCmd())
+	cmd.AddCommand(newContextCreateCmd())
+	cmd.AddCommand(newContextUseCmd())
+	cmd.AddCommand(newContextDumpCmd())
+
+	return cmd
+}
+
+func newContextListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List contexts, marking the current one",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			names, err := cfg.Contexts()
+			if err != nil {
+				return err
+			}
+
+			if outputFormat == "json" {
+				jsonData, err := json.MarshalIndent(map[string]interface{}{
+					"current":  cfg.Context,
+					"contexts": names,
+				}, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintf(w, "CURRENT\tNAME\tDIRECTORY\n")
+			_, _ = fmt.Fprintf(w, "-------\t----\t---------\n")
+			for _, name := range names {
+				marker := ""
+				if name == cfg.Context {
+					marker = "*"
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", marker, name, cfg.ContextDir(name))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newContextCreateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a new, empty context",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			if err := cfg.CreateContext(args[0]); err != nil {
+				return err
+			}
+			if !quiet {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Created context %q (switch with 'memory context use %s')\n", args[0], args[0])
+			}
+			return nil
+		},
+	}
+}
+
+func newContextUseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <name>",
+		Short: "Make a context current for future commands and MCP servers",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			if err := cfg.UseContext(args[0]); err != nil {
+				return err
+			}
+			if !quiet {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Switched to context %q\n", args[0])
+			}
+			return nil
+		},
+	}
+}
+
+func newContextDumpCmd() *cobra.Command {
+	var (
+		query         string
+		blockID       string
+		persona       string
+		maxTokens     int
+		outPath       string
+		revealSecrets bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "dump",
+		Short: "Dump the context hydrated for a query as a context pack",
+		Long: `Hydrate context for --query the way the MCP server, REST API, and chat
+proxy do, and write a context pack: the assembled prompt along with every
+section considered, its token count, and the memories and facts it kept or
+left out, with their scores and the reason for each exclusion.
+
+Without --block only memory is hydrated (retrieved memories, facts, and the
+profile), as the chat proxy adds to requests. With --block the full prompt
+for that topic is assembled, as the REST API's hydrate endpoint does.
+
+The pack is written to --out, or printed when --out is not given, so a
+prompt that went wrong can be shared and reproduced. Secret facts are
+masked as ******** in the pack, as they are in hydrated context, unless
+--reveal-secrets is passed.
+
+Examples:
+  memory context dump --query "what editor do I use?" --out pack.json
+  memory context dump --query "and the theme?" --block block_20260115_a1b2c3d4 --max-tokens 2000`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if query == "" {
+				return fmt.Errorf("--query is required")
+			}
+			if cmd.Flags().Changed("max-tokens") {
+				if err := validatePositiveInt(maxTokens, "max-tokens"); err != nil {
+					return err
+				}
+			} else if blockID != "" {
+				maxTokens = rest.DefaultContextTokens
+			} else {
+				maxTokens = chatproxy.DefaultContextTokens
+			}
+
+			// Load .env for API keys
+			_ = godotenv.Load()
+
+			store, cfg, err := openStorageWithConfig()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			// Memories are only retrieved with an embedding client, as when serving
+			hydrator := core.NewContextHydrator(store, nil)
+			if cfg.OpenAIKey != "" {
+				client, err := llm.NewOpenAIClientWithConfig(llm.ConfigFromSettings(cfg))
+				if err != nil {
+					if verbose {
+						fmt.Fprintf(os.Stderr, "Warning: Could not initialize OpenAI client: %v\n", err)
+					}
+				} else {
+					hydrator = core.NewContextHydrator(store, client)
+				}
+			}
+			if !cmd.Flags().Changed("persona") {
+				persona = cfg.Persona
+			}
+			hydrator.SetPersona(persona)
+			hydrator.SetRevealSecrets(revealSecrets)
+			hydrator.SetFactWeighting(core.FactWeighting{MinConfidence: cfg.FactMinConfidence, HalfLife: cfg.FactHalfLife})
+
+			var pack *core.ContextPack
+			if blockID != "" {
+				if pack, err = hydrator.PackBridgeBlock(blockID, query, maxTokens); err != nil {
+					return fmt.Errorf("hydrating context: %w", err)
+				}
+			} else {
+				pack = hydrator.PackMemory(query, maxTokens)
+			}
+
+			jsonData, err := json.MarshalIndent(pack, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			out := cmd.OutOrStdout()
+			if outPath == "" {
+				_, _ = fmt.Fprintf(out, "%s\n", jsonData)
+				return nil
+			}
+			if err := os.WriteFile(outPath, append(jsonData, '\n'), 0600); err != nil {
+				return fmt.Errorf("writing context pack: %w", err)
+			}
+			if quiet {
+				return nil
+			}
+
+			_, _ = fmt.Fprintf(out, "✓ Wrote context pack to %s (%d of %d tokens)\n\n", outPath, pack.Tokens, pack.MaxTokens)
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintf(w, "SECTION\tINCLUDED\tTOKENS\tITEMS\tREASON\n")
+			_, _ = fmt.Fprintf(w, "-------\t--------\t------\t-----\t------\n")
+			for _, section := range pack.Sections {
+				kept := 0
+				for _, item := range section.Items {
+					if item.Included {
+						kept++
+					}
+				}
+				items := ""
+				if len(section.Items) > 0 {
+					items = fmt.Sprintf("%d/%d", kept, len(section.Items))
+				}
+				included := "no"
+				if section.Included {
+					included = "yes"
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", section.Name, included, section.Tokens, items, section.Reason)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&query, "query", "", "Message to hydrate context for (required)")
+	cmd.Flags().StringVar(&blockID, "block", "", "Topic to assemble the full prompt for; without it only memory is hydrated")
+	cmd.Flags().StringVar(&persona, "persona", "", "Profile persona to hydrate with (default: the configured persona)")
+	cmd.Flags().IntVar(&maxTokens, "max-tokens", 0, fmt.Sprintf("Token budget (default %d, or %d with --block)", chatproxy.DefaultContextTokens, rest.DefaultContextTokens))
+	cmd.Flags().StringVar(&outPath, "out", "", "File to write the pack to (default: print it)")
+	cmd.Flags().BoolVar(&revealSecrets, "reveal-secrets", false, "Include secret fact values in the clear instead of masked")
+
+	return cmd
+}
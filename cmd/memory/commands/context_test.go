@@ -0,0 +1,145 @@
+// ABOUTME: Tests for context command
+// ABOUTME: Verifies subcommands, that contexts keep separate databases, and context pack dumps
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestNewContextCmd(t *testing.T) {
+	cmd := NewContextCmd()
+
+	if cmd.Use != "context" {
+		t.Errorf("Use = %q, want %q", cmd.Use, "context")
+	}
+	for _, name := range []string{"list", "create", "use", "dump"} {
+		if sub, _, err := cmd.Find([]string{name}); err != nil || sub == cmd {
+			t.Errorf("subcommand %q not found", name)
+		}
+	}
+}
+
+func TestContextCmd_SeparateDatabases(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	defer func() { contextName = "" }()
+
+	run := func(args ...string) string {
+		t.Helper()
+		contextName = ""
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs(args)
+		if err := root.Execute(); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	run("context", "create", "work")
+	run("context", "use", "work")
+
+	if out := run("context", "list"); !strings.Contains(out, "*        work") {
+		t.Errorf("list should mark work as current:\n%s", out)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(cfg.DBPath(), filepath.Join("contexts", "work", "memory.db")) {
+		t.Errorf("DBPath() = %s, want the work context's database", cfg.DBPath())
+	}
+
+	contextName = "default"
+	cfg, err = loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DBPath() != filepath.Join(dir, "data", "memory.db") {
+		t.Errorf("--context default DBPath() = %s", cfg.DBPath())
+	}
+}
+
+func TestContextDumpCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []models.Fact{
+		{FactID: "fact_dump_editor", Key: "editor", Value: "helix", Confidence: 0.9},
+		{FactID: "fact_dump_theme", Key: "editor_theme", Value: "gruvbox", Confidence: 0.1},
+	} {
+		if err := store.SaveFact(&f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = store.Close()
+
+	if _, err := run("context", "dump"); err == nil {
+		t.Error("context dump without --query succeeded, want an error")
+	}
+
+	packPath := filepath.Join(dir, "pack.json")
+	out, err := run("context", "dump", "--query", "editor", "--out", packPath)
+	if err != nil {
+		t.Fatalf("context dump error = %v", err)
+	}
+	if !strings.Contains(out, "✓ Wrote context pack to "+packPath) || !strings.Contains(out, "facts") || !strings.Contains(out, "1/2") {
+		t.Errorf("context dump output = %q, want the file and a section table", out)
+	}
+
+	data, err := os.ReadFile(packPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pack core.ContextPack
+	if err := json.Unmarshal(data, &pack); err != nil {
+		t.Fatalf("pack is not JSON: %v", err)
+	}
+	if pack.Query != "editor" || pack.MaxTokens != 1500 || !strings.Contains(pack.Prompt, "editor: helix") {
+		t.Errorf("pack = %+v, want the hydrated facts for the query", pack)
+	}
+	for _, section := range pack.Sections {
+		if section.Name == core.PackFacts && (len(section.Items) != 2 || section.Items[1].Reason == "") {
+			t.Errorf("facts = %+v, want the weak fact left out with a reason", section)
+		}
+	}
+
+	// Without --out the pack is printed; a missing topic is an error
+	if out, err := run("context", "dump", "--query", "editor"); err != nil || !strings.Contains(out, `"sections"`) {
+		t.Errorf("context dump to stdout = %q, %v; want the pack", out, err)
+	}
+	if _, err := run("context", "dump", "--query", "editor", "--block", "block_missing"); err == nil {
+		t.Error("context dump for a missing block succeeded, want an error")
+	}
+}
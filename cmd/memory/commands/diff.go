@@ -0,0 +1,121 @@
+// ABOUTME: CLI command to compare two exports or database snapshots
+// ABOUTME: Lists added, removed, and changed blocks, turns, facts, and profile fields
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// NewDiffCmd creates diff command
+func NewDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <export-a> <export-b>",
+		Short: "Compare two exports or database snapshots",
+		Long: `Compare two memory exports and show what changed from A to B.
+
+Each argument may be a YAML/JSON file written by 'memory export' or a
+SQLite database snapshot (.db). Blocks, turns, and facts are matched by
+ID and reported as added (+), removed (-), or changed (~) with the
+fields that differ.
+
+Useful for auditing what an agent changed during a session or verifying
+a migration.
+
+Examples:
+  memory diff before.yaml after.yaml
+  memory diff backup.db ~/.local/share/memory/memory.db
+  memory diff before.yaml after.yaml --format json`,
+		Args: cobra.ExactArgs(2),
+		RunE: runDiff,
+	}
+
+	return cmd
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	before, err := loadDiffSource(args[0])
+	if err != nil {
+		return err
+	}
+	after, err := loadDiffSource(args[1])
+	if err != nil {
+		return err
+	}
+
+	diff := storage.DiffExports(before, after)
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	if diff.Empty() {
+		_, _ = fmt.Fprintln(out, "No differences")
+		return nil
+	}
+
+	printDiffSet(out, "Blocks", diff.Blocks)
+	printDiffSet(out, "Turns", diff.Turns)
+	printDiffSet(out, "Facts", diff.Facts)
+	if len(diff.ProfileFields) > 0 {
+		_, _ = fmt.Fprintf(out, "Profile: ~ %s\n", strings.Join(diff.ProfileFields, ", "))
+	}
+
+	return nil
+}
+
+// loadDiffSource reads an export file, or exports a SQLite snapshot in memory
+func loadDiffSource(path string) (*storage.ExportData, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".db", ".sqlite", ".sqlite3":
+		store, err := storage.NewStorageWithPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("opening snapshot %s: %w", path, err)
+		}
+		defer func() { _ = store.Close() }()
+
+		data, err := store.Export()
+		if err != nil {
+			return nil, fmt.Errorf("reading snapshot %s: %w", path, err)
+		}
+		return data, nil
+	default:
+		return storage.LoadExport(path)
+	}
+}
+
+// printDiffSet writes one section of a diff, skipping sections with no changes
+func printDiffSet(w io.Writer, title string, set storage.DiffSet) {
+	if set.Empty() {
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "%s: +%d -%d ~%d\n", title, len(set.Added), len(set.Removed), len(set.Changed))
+	for _, id := range set.Added {
+		_, _ = fmt.Fprintf(w, "  + %s\n", id)
+	}
+	for _, id := range set.Removed {
+		_, _ = fmt.Fprintf(w, "  - %s\n", id)
+	}
+	for _, change := range set.Changed {
+		_, _ = fmt.Fprintf(w, "  ~ %s (%s)\n", change.ID, strings.Join(change.Fields, ", "))
+	}
+}
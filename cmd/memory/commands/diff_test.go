@@ -0,0 +1,88 @@
+// ABOUTME: Tests for diff command
+// ABOUTME: Verifies command structure and text output of export comparisons
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+func TestNewDiffCmd(t *testing.T) {
+	cmd := NewDiffCmd()
+
+	if !strings.HasPrefix(cmd.Use, "diff ") {
+		t.Errorf("Use = %q, want prefix %q", cmd.Use, "diff ")
+	}
+
+	if cmd.Short == "" {
+		t.Error("Short description should not be empty")
+	}
+
+	if err := cmd.Args(cmd, []string{"a.yaml"}); err == nil {
+		t.Error("diff should require two arguments")
+	}
+	if err := cmd.Args(cmd, []string{"a.yaml", "b.yaml"}); err != nil {
+		t.Errorf("diff with two args error = %v", err)
+	}
+
+	if !findSubstring(cmd.Long, "memory diff before.yaml after.yaml") {
+		t.Error("Long description should contain usage example")
+	}
+}
+
+func TestPrintDiffSet(t *testing.T) {
+	var buf bytes.Buffer
+	printDiffSet(&buf, "Facts", storage.DiffSet{
+		Added:   []string{"fact_new"},
+		Removed: []string{"fact_old"},
+		Changed: []storage.DiffChange{{ID: "fact_mod", Fields: []string{"value", "confidence"}}},
+	})
+
+	want := "Facts: +1 -1 ~1\n  + fact_new\n  - fact_old\n  ~ fact_mod (value, confidence)\n"
+	if buf.String() != want {
+		t.Errorf("printDiffSet() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	printDiffSet(&buf, "Blocks", storage.DiffSet{})
+	if buf.Len() != 0 {
+		t.Errorf("printDiffSet() for empty set wrote %q", buf.String())
+	}
+}
+
+func TestRunDiff(t *testing.T) {
+	dir := t.TempDir()
+	before := filepath.Join(dir, "before.yaml")
+	after := filepath.Join(dir, "after.yaml")
+	_ = os.WriteFile(before, []byte("version: \"1.0\"\nfacts:\n  - fact_id: fact_1\n    key: pet\n    value: cat\n"), 0644)
+	_ = os.WriteFile(after, []byte("version: \"1.0\"\nfacts:\n  - fact_id: fact_1\n    key: pet\n    value: dog\n"), 0644)
+
+	cmd := NewDiffCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	if err := runDiff(cmd, []string{before, after}); err != nil {
+		t.Fatalf("runDiff() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "~ fact_1 (value)") {
+		t.Errorf("output = %q, want changed fact_1", buf.String())
+	}
+
+	buf.Reset()
+	if err := runDiff(cmd, []string{before, before}); err != nil {
+		t.Fatalf("runDiff() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "No differences") {
+		t.Errorf("output = %q, want no differences", buf.String())
+	}
+
+	if err := runDiff(cmd, []string{before, filepath.Join(dir, "missing.yaml")}); err == nil {
+		t.Error("runDiff() expected error for missing file")
+	}
+}
@@ -0,0 +1,178 @@
+// ABOUTME: Doctor command that checks the memory installation for problems
+// ABOUTME: Reports on the config file, data directory, filesystem, context, database, and API key
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/harper/remember-standalone/internal/config"
+	"github.com/harper/remember-standalone/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// Doctor check statuses
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// doctorCheck is one row of `memory doctor`
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// NewDoctorCmd creates the doctor command
+func NewDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the configuration, data directory, and database for problems",
+		Long: `Check the memory installation for problems.
+
+Verifies that the config file loads, the data directory is writable and on a
+local filesystem (SQLite databases can be corrupted on NFS or SMB), the
+current context exists, the database opens, and an API key is available.
+Exits with an error if any check fails.`,
+		Example: `  memory doctor
+  memory doctor --data-dir /mnt/fast/memory`,
+		Args: cobra.NoArgs,
+		RunE: runDoctor,
+	}
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	checks := doctorChecks()
+
+	failed := 0
+	for _, c := range checks {
+		if c.Status == checkFail {
+			failed++
+		}
+	}
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(checks, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+	} else {
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "CHECK\tSTATUS\tDETAIL\n")
+		_, _ = fmt.Fprintf(w, "-----\t------\t------\n")
+		for _, c := range checks {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", c.Name, c.Status, c.Detail)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// doctorChecks runs every check in order, stopping early when the config cannot be loaded
+func doctorChecks() []doctorCheck {
+	cfg, err := loadConfig()
+	if err != nil {
+		return []doctorCheck{{Name: "config", Status: checkFail, Detail: err.Error()}}
+	}
+
+	var checks []doctorCheck
+	if _, err := os.Stat(cfg.Path); err == nil {
+		checks = append(checks, doctorCheck{Name: "config", Status: checkOK, Detail: cfg.Path})
+	} else {
+		checks = append(checks, doctorCheck{Name: "config", Status: checkOK, Detail: cfg.Path + " not found, using defaults"})
+	}
+
+	dir := cfg.ContextDir(cfg.Context)
+	status := config.CheckDataDir(dir)
+	if err := status.Problem(); err != nil {
+		checks = append(checks, doctorCheck{Name: "data_dir", Status: checkFail, Detail: err.Error()})
+	} else if !status.Exists {
+		checks = append(checks, doctorCheck{Name: "data_dir", Status: checkOK, Detail: dir + " (will be created)"})
+	} else {
+		checks = append(checks, doctorCheck{Name: "data_dir", Status: checkOK, Detail: dir + " is writable"})
+	}
+
+	switch {
+	case status.Network:
+		checks = append(checks, doctorCheck{Name: "filesystem", Status: checkWarn, Detail: status.Warning()})
+	case status.Filesystem != "":
+		checks = append(checks, doctorCheck{Name: "filesystem", Status: checkOK, Detail: status.Filesystem})
+	}
+
+	if err := cfg.CheckContext(); err != nil {
+		checks = append(checks, doctorCheck{Name: "context", Status: checkFail, Detail: err.Error()})
+		return checks
+	}
+	checks = append(checks, doctorCheck{Name: "context", Status: checkOK, Detail: cfg.Context})
+
+	database := checkDatabase(cfg.DBPath())
+	checks = append(checks, database)
+	if database.Status == checkOK {
+		checks = append(checks, checkEnrichment(cfg.DBPath(), cfg.OpenAIKey != ""))
+	}
+
+	if cfg.OpenAIKey == "" {
+		checks = append(checks, doctorCheck{Name: "api_key", Status: checkWarn, Detail: "OPENAI_API_KEY not set; embeddings and LLM features are disabled (see 'memory auth set openai')"})
+	} else {
+		checks = append(checks, doctorCheck{Name: "api_key", Status: checkOK, Detail: fmt.Sprintf("openai key from %s", cfg.Source("openai_api_key"))})
+	}
+
+	return checks
+}
+
+// checkDatabase opens the database read-only so the check never creates or migrates it
+func checkDatabase(path string) doctorCheck {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return doctorCheck{Name: "database", Status: checkOK, Detail: path + " not created yet"}
+	}
+
+	store, err := storage.NewStorageReadOnly(path)
+	if err != nil {
+		return doctorCheck{Name: "database", Status: checkFail, Detail: err.Error()}
+	}
+	defer func() { _ = store.Close() }()
+
+	stats, err := store.Stats(0)
+	if err != nil {
+		return doctorCheck{Name: "database", Status: checkFail, Detail: err.Error()}
+	}
+	return doctorCheck{Name: "database", Status: checkOK, Detail: fmt.Sprintf("%s (%d turns, %d facts)", path, stats.TurnCount, stats.FactCount)}
+}
+
+// checkEnrichment warns about turns stored while the LLM was missing or failing whose
+// metadata, embeddings, or facts have not been backfilled yet
+func checkEnrichment(path string, hasKey bool) doctorCheck {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return doctorCheck{Name: "enrichment", Status: checkOK, Detail: "no turns pending"}
+	}
+
+	store, err := storage.NewStorageReadOnly(path)
+	if err != nil {
+		return doctorCheck{Name: "enrichment", Status: checkFail, Detail: err.Error()}
+	}
+	defer func() { _ = store.Close() }()
+
+	pending, err := store.PendingEnrichmentCount()
+	switch {
+	case err != nil:
+		return doctorCheck{Name: "enrichment", Status: checkFail, Detail: err.Error()}
+	case pending == 0:
+		return doctorCheck{Name: "enrichment", Status: checkOK, Detail: "no turns pending"}
+	case !hasKey:
+		return doctorCheck{Name: "enrichment", Status: checkWarn, Detail: fmt.Sprintf("%d turns waiting on metadata, embeddings, or facts; set an OpenAI key and run 'memory jobs run'", pending)}
+	default:
+		return doctorCheck{Name: "enrichment", Status: checkWarn, Detail: fmt.Sprintf("%d turns waiting on metadata, embeddings, or facts; run 'memory jobs run' or start the MCP server to backfill them", pending)}
+	}
+}
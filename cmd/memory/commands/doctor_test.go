@@ -0,0 +1,58 @@
+// ABOUTME: Tests for doctor command
+// ABOUTME: Verifies checks pass on a fresh data directory and --data-dir is honored
+
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctorCmd_FreshInstall(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "ignored"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	defer func() { dataDir, outputFormat = "", "auto" }()
+
+	override := filepath.Join(dir, "data")
+	root := NewRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"doctor", "--data-dir", override})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("doctor failed: %v\n%s", err, out.String())
+	}
+
+	got := out.String()
+	for _, want := range []string{"config", "data_dir", "context", "database", "api_key"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q check:\n%s", want, got)
+		}
+	}
+	if !strings.Contains(got, override) {
+		t.Errorf("doctor should check the --data-dir directory %s:\n%s", override, got)
+	}
+	if strings.Contains(got, "fail") {
+		t.Errorf("no check should fail on a fresh install:\n%s", got)
+	}
+}
+
+func TestDoctorCmd_MissingContextFails(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "nope")
+
+	root := NewRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"doctor"})
+	if err := root.Execute(); err == nil {
+		t.Errorf("doctor should fail for a missing context:\n%s", out.String())
+	}
+}
@@ -0,0 +1,122 @@
+// ABOUTME: CLI command to keep topics and facts out of recall without deleting them
+// ABOUTME: With no arguments lists everything on the do-not-recall list
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// NewExcludeCmd creates the exclude command
+func NewExcludeCmd() *cobra.Command {
+	var (
+		factKey string
+		undo    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "exclude [<block_id>]",
+		Short: "Keep a topic or fact out of recall without deleting it",
+		Long: `Keep a topic (Bridge Block) or fact stored, but never surface it
+automatically: searches, retrieve_memory, and hydrated context skip it.
+Looking it up by block ID or key still works, and exports include it.
+
+--fact excludes every fact saved so far with the key. --undo lets a topic
+or fact back into recall. With no arguments, lists what is excluded.
+
+Examples:
+  memory exclude block_20260115_143022_a1b2c3d4
+  memory exclude --fact therapist_name
+  memory exclude --undo block_20260115_143022_a1b2c3d4
+  memory exclude`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if factKey != "" && len(args) > 0 {
+				return fmt.Errorf("give a block ID or --fact, not both")
+			}
+
+			store, err := openStorage()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			out := cmd.OutOrStdout()
+			if factKey == "" && len(args) == 0 {
+				if undo {
+					return fmt.Errorf("--undo needs a block ID or --fact")
+				}
+				exclusions, err := store.ListRecallExclusions()
+				if err != nil {
+					return fmt.Errorf("listing exclusions: %w", err)
+				}
+				if outputFormat == "json" {
+					jsonData, err := json.MarshalIndent(exclusions, "", "  ")
+					if err != nil {
+						return fmt.Errorf("marshaling JSON: %w", err)
+					}
+					_, _ = fmt.Fprintf(out, "%s\n", jsonData)
+					return nil
+				}
+				if len(exclusions.Blocks) == 0 && len(exclusions.FactKeys) == 0 {
+					if !quiet {
+						_, _ = fmt.Fprintf(out, "Nothing is excluded from recall\n")
+					}
+					return nil
+				}
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				_, _ = fmt.Fprintf(w, "KIND\tID\tTOPIC\n")
+				_, _ = fmt.Fprintf(w, "----\t--\t-----\n")
+				for _, block := range exclusions.Blocks {
+					_, _ = fmt.Fprintf(w, "topic\t%s\t%s\n", block.BlockID, truncate(block.TopicLabel, 50))
+				}
+				for _, key := range exclusions.FactKeys {
+					_, _ = fmt.Fprintf(w, "fact\t%s\t\n", key)
+				}
+				return w.Flush()
+			}
+
+			result := map[string]interface{}{"exclude_from_recall": !undo}
+			if factKey != "" {
+				if err := store.ExcludeFactFromRecall(factKey, !undo); err != nil {
+					return fmt.Errorf("updating fact: %w", err)
+				}
+				result["key"] = factKey
+			} else {
+				if err := store.ExcludeBlockFromRecall(args[0], !undo); err != nil {
+					return fmt.Errorf("updating topic: %w", err)
+				}
+				result["block_id"] = args[0]
+			}
+
+			if outputFormat == "json" {
+				jsonData, err := json.MarshalIndent(result, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(out, "%s\n", jsonData)
+				return nil
+			}
+			if !quiet {
+				target := "topic " + fmt.Sprint(result["block_id"])
+				if factKey != "" {
+					target = "facts with key " + factKey
+				}
+				if undo {
+					_, _ = fmt.Fprintf(out, "✓ Recalling %s again\n", target)
+				} else {
+					_, _ = fmt.Fprintf(out, "✓ Excluded %s from recall\n", target)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&factKey, "fact", "", "Exclude the facts with this key instead of a topic")
+	cmd.Flags().BoolVar(&undo, "undo", false, "Let the topic or facts back into recall")
+
+	return cmd
+}
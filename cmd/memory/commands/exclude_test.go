@@ -0,0 +1,77 @@
+// ABOUTME: Tests for the exclude command
+// ABOUTME: Verifies excluding and restoring a topic and facts, and listing the do-not-recall list
+
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestExcludeCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run("add", "Notes from the therapy session"); err != nil {
+		t.Fatal(err)
+	}
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := store.GetActiveBridgeBlocks()
+	if err != nil || len(blocks) != 1 {
+		t.Fatalf("GetActiveBridgeBlocks() = %d blocks, %v; want 1", len(blocks), err)
+	}
+	blockID := blocks[0].BlockID
+	if err := store.SaveFact(&models.Fact{FactID: "fact_exclude_cmd", BlockID: blockID, Key: "therapist_name", Value: "Dr. Lee", Confidence: 1}); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	if out, err := run("exclude"); err != nil || !strings.Contains(out, "Nothing is excluded") {
+		t.Fatalf("exclude with nothing excluded = %q, %v", out, err)
+	}
+	if out, err := run("exclude", blockID); err != nil || !strings.Contains(out, "✓ Excluded topic "+blockID) {
+		t.Fatalf("exclude block = %q, %v", out, err)
+	}
+	if out, err := run("exclude", "--fact", "therapist_name"); err != nil || !strings.Contains(out, "✓ Excluded facts with key therapist_name") {
+		t.Fatalf("exclude --fact = %q, %v", out, err)
+	}
+	if _, err := run("exclude", "--fact", "no_such_key"); err == nil {
+		t.Error("exclude --fact of a missing key succeeded, want an error")
+	}
+
+	out, err := run("exclude")
+	if err != nil || !strings.Contains(out, blockID) || !strings.Contains(out, "therapist_name") {
+		t.Errorf("exclude list = %q, %v; want the topic and the fact key", out, err)
+	}
+	if out, _ := run("search", "therapy"); strings.Contains(out, blockID) {
+		t.Errorf("search = %q, want the excluded topic left out", out)
+	}
+
+	if out, err := run("exclude", "--undo", blockID); err != nil || !strings.Contains(out, "Recalling topic "+blockID) {
+		t.Fatalf("exclude --undo = %q, %v", out, err)
+	}
+	if out, _ := run("exclude"); strings.Contains(out, blockID) {
+		t.Errorf("exclude list after --undo = %q, want only the fact key", out)
+	}
+}
@@ -0,0 +1,152 @@
+// ABOUTME: CLI commands for stored facts
+// ABOUTME: Reviews facts waiting for approval, approving, correcting, or rejecting each one
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// NewFactsCmd creates the facts command group
+func NewFactsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "facts",
+		Short: "Review facts extracted from conversations",
+	}
+
+	cmd.AddCommand(newFactsReviewCmd())
+
+	return cmd
+}
+
+func newFactsReviewCmd() *cobra.Command {
+	var list bool
+
+	cmd := &cobra.Command{
+		Use:   "review",
+		Short: "Approve or reject facts waiting for review",
+		Long: `Review facts waiting for approval.
+
+With fact_review set to true, facts the LLM extracts from conversations are
+saved pending: they are not looked up, searched, exported, or hydrated into
+context until approved. For each one, approve it, correct its value and
+approve it, or reject it (which deletes it). Skipped facts stay pending.
+Agents can do the same with the review_facts MCP tool.
+
+Examples:
+  memory config set fact_review true
+  memory facts review
+  memory facts review --list`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openStorage()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			facts, err := store.PendingFacts()
+			if err != nil {
+				return fmt.Errorf("listing pending facts: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			if outputFormat == "json" {
+				if facts == nil {
+					facts = []models.Fact{}
+				}
+				jsonData, err := json.MarshalIndent(facts, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(out, "%s\n", jsonData)
+				return nil
+			}
+
+			if len(facts) == 0 {
+				if !quiet {
+					_, _ = fmt.Fprintln(out, "Nothing to review: no facts are waiting for approval")
+				}
+				return nil
+			}
+
+			if list {
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				_, _ = fmt.Fprintf(w, "FACT ID\tKEY\tVALUE\tCONFIDENCE\tEXTRACTED\n")
+				_, _ = fmt.Fprintf(w, "-------\t---\t-----\t----------\t---------\n")
+				for i := range facts {
+					fact := &facts[i]
+					_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%.2f\t%s\n", fact.FactID, truncate(fact.Key, 25),
+						truncate(oneLine(logFactValue(fact)), 40), fact.Confidence, formatTime(fact.CreatedAt))
+				}
+				return w.Flush()
+			}
+
+			in := bufio.NewReader(cmd.InOrStdin())
+			var approved, edited, rejected int
+		review:
+			for i := range facts {
+				fact := &facts[i]
+				_, _ = fmt.Fprintf(out, "\n[%d/%d] %s = %s (confidence %.2f, extracted %s)\n", i+1, len(facts),
+					fact.Key, logFactValue(fact), fact.Confidence, formatTime(fact.CreatedAt))
+				if fact.SourceQuote != "" {
+					_, _ = fmt.Fprintf(out, "  from: %s\n", truncate(oneLine(fact.SourceQuote), 100))
+				}
+				for {
+					_, _ = fmt.Fprintf(out, "[a]pprove, [e]dit, [r]eject, [s]kip, [q]uit? ")
+					answer, err := readReviewLine(in)
+					if err != nil {
+						break review
+					}
+					switch strings.ToLower(answer) {
+					case "a", "approve":
+						if _, err := store.ApproveFact(fact.FactID, ""); err != nil {
+							return fmt.Errorf("approving fact: %w", err)
+						}
+						approved++
+					case "e", "edit":
+						_, _ = fmt.Fprintf(out, "New value: ")
+						value, err := readReviewLine(in)
+						if err != nil {
+							break review
+						}
+						if value == "" {
+							continue
+						}
+						if _, err := store.ApproveFact(fact.FactID, value); err != nil {
+							return fmt.Errorf("approving fact: %w", err)
+						}
+						edited++
+					case "r", "reject":
+						if err := store.RejectFact(fact.FactID); err != nil {
+							return fmt.Errorf("rejecting fact: %w", err)
+						}
+						rejected++
+					case "s", "skip":
+					case "q", "quit":
+						break review
+					default:
+						continue
+					}
+					break
+				}
+			}
+
+			if !quiet {
+				_, _ = fmt.Fprintf(out, "\nApproved %d, edited %d, rejected %d of %d facts\n", approved, edited, rejected, len(facts))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&list, "list", false, "List pending facts without prompting")
+
+	return cmd
+}
@@ -0,0 +1,81 @@
+// ABOUTME: Tests for the facts command
+// ABOUTME: Verifies listing pending facts and approving, correcting, and rejecting them in a review
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestFactsReviewCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(input string, args ...string) string {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetIn(strings.NewReader(input))
+		root.SetArgs(args)
+		if err := root.Execute(); err != nil {
+			t.Fatalf("%v error = %v", args, err)
+		}
+		return out.String()
+	}
+
+	if out := run("", "facts", "review"); !strings.Contains(out, "Nothing to review") {
+		t.Fatalf("review of an empty queue = %q, want nothing to review", out)
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_facts", UserMessage: "I live in Porto, drive a Saab, and have a cat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i, kv := range [][2]string{{"home_city", "Porto"}, {"car", "Saab"}, {"pet", "cat"}, {"drink", "tea"}} {
+		if err := store.SaveFact(&models.Fact{FactID: "fact_" + kv[0], BlockID: blockID, TurnID: "turn_facts", Key: kv[0], Value: kv[1],
+			Confidence: 0.8, CreatedAt: now.Add(time.Duration(i) * time.Second), Pending: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = store.Close()
+
+	if out := run("", "facts", "review", "--list"); !strings.Contains(out, "fact_home_city") || !strings.Contains(out, "Saab") {
+		t.Errorf("review --list = %q, want the pending facts", out)
+	}
+
+	// Facts come up oldest first; an unknown answer asks again
+	out := run("a\nx\ne\nVolvo\nr\ns\n", "facts", "review")
+	if !strings.Contains(out, "Approved 1, edited 1, rejected 1 of 4 facts") {
+		t.Fatalf("review = %q, want one fact each approved, edited, and rejected", out)
+	}
+
+	store, err = openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	if fact, err := store.GetFactByKey("car"); err != nil || fact == nil || fact.Value != "Volvo" {
+		t.Errorf("GetFactByKey(car) = %+v, %v; want the corrected value", fact, err)
+	}
+	if fact, err := store.GetFactByKey("pet"); err != nil || fact != nil {
+		t.Errorf("GetFactByKey(pet) = %+v, %v; want the rejected fact gone", fact, err)
+	}
+	if pending, err := store.PendingFacts(); err != nil || len(pending) != 1 || pending[0].Key != "drink" {
+		t.Errorf("PendingFacts() = %+v, %v; want the skipped fact still pending", pending, err)
+	}
+}
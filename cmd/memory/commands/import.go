@@ -0,0 +1,232 @@
+// ABOUTME: CLI command to import the topics and facts of a memory export
+// ABOUTME: Resolves collisions with stored ones by flag or by asking, and reports each decision
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// importAsk is the --on-conflict value that asks about each conflict
+const importAsk = "ask"
+
+// NewImportCmd creates the import command
+func NewImportCmd() *cobra.Command {
+	var (
+		onConflict       string
+		verify           bool
+		requireSignature bool
+		publicKey        string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import <export.yaml>",
+		Short: "Import the topics and facts of a memory export",
+		Long: `Import the topics (with their turns) and facts of a file written by
+'memory export', such as one from another context or machine.
+
+Topics and facts not stored yet are added, and ones already stored exactly as
+exported are left alone. One whose ID is stored with different contents is a
+conflict, resolved by --on-conflict:
+
+  skip       Keep the stored one and drop the incoming one
+  overwrite  Replace the stored one with the incoming one
+  merge      Keep the stored one and add the turns, keywords, and tags only the
+             incoming one has; for a fact, the newer value wins
+  rename     Import the incoming one under a new ID, keeping both
+  ask        Ask about each conflict (the default when run in a terminal;
+             otherwise conflicts are skipped)
+
+Every conflict is decided before anything is saved, and the decisions are
+listed afterwards. Imported topics marked active are paused, so the
+conversation in progress stays active. Secret facts the export masks are left
+out: export with --reveal-secrets to carry them over. Import the profile with
+'memory profile import' and embeddings with 'memory sync import-embeddings'.
+
+An export whose checksums no longer match its contents is refused. Exports
+without a checksum manifest load unchecked unless --verify is given, which
+refuses them; --require-signature also refuses any export not signed with this
+machine's signing key ('memory export --sign'). To accept an export signed on
+another machine, pass that machine's public key with --public-key.
+
+Examples:
+  memory import backup.yaml
+  memory import laptop.yaml --on-conflict merge
+  memory import backup.yaml --on-conflict rename --format json
+  memory import backup.yaml --verify --require-signature
+  memory import laptop.yaml --public-key <base64 key>`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if onConflict == "" {
+				onConflict = string(storage.ConflictSkip)
+				if f, ok := cmd.InOrStdin().(*os.File); ok && isTerminal(f) {
+					onConflict = importAsk
+				}
+			}
+			opts := storage.ImportOptions{}
+			if onConflict == importAsk {
+				opts.Resolve = newConflictPrompt(cmd.InOrStdin(), cmd.ErrOrStderr())
+			} else {
+				strategy, err := storage.ParseConflictStrategy(onConflict)
+				if err != nil {
+					return fmt.Errorf("--on-conflict must be ask, skip, overwrite, merge, or rename, got %q", onConflict)
+				}
+				opts.OnConflict = strategy
+			}
+
+			data, err := storage.LoadExport(args[0])
+			if err != nil {
+				return err
+			}
+			if publicKey != "" {
+				requireSignature = true
+			}
+			if verify || requireSignature {
+				if err := verifyImport(args[0], data, requireSignature, publicKey); err != nil {
+					return err
+				}
+			}
+
+			store, err := openStorage()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			result, err := store.ImportExport(data, opts)
+			if err != nil {
+				if result != nil {
+					return fmt.Errorf("import stopped after %d topics and %d facts: %w", result.Blocks, result.Facts, err)
+				}
+				return fmt.Errorf("import failed: %w", err)
+			}
+
+			if outputFormat == "json" {
+				jsonData, err := json.MarshalIndent(result, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+				return nil
+			}
+			if !quiet {
+				printImportResult(cmd.OutOrStdout(), result)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&onConflict, "on-conflict", "", "What to do with topics and facts stored with different contents: ask, skip, overwrite, merge, or rename")
+	cmd.Flags().BoolVar(&verify, "verify", false, "Refuse an export without a checksum manifest")
+	cmd.Flags().BoolVar(&requireSignature, "require-signature", false, "Refuse an export not signed with this machine's signing key (implies --verify)")
+	cmd.Flags().StringVar(&publicKey, "public-key", "", "Base64 public key the export must be signed with, instead of this machine's signing key (implies --require-signature)")
+
+	return cmd
+}
+
+// verifyImport refuses an export without a manifest, or whose manifest does not
+// match, and with requireSignature one not signed by publicKey when one is given,
+// else by this machine's signing key
+func verifyImport(path string, data *storage.ExportData, requireSignature bool, publicKey string) error {
+	if err := storage.VerifyExport(data); err != nil {
+		return fmt.Errorf("refusing export %s: %w", path, err)
+	}
+	if !requireSignature {
+		return nil
+	}
+	if !data.Manifest.Signed() {
+		return fmt.Errorf("refusing export %s: it is not signed", path)
+	}
+	if publicKey != "" {
+		if data.Manifest.PublicKey != publicKey {
+			return fmt.Errorf("refusing export %s: signed by key %s, not the expected %s", path, data.Manifest.PublicKey, publicKey)
+		}
+		return nil
+	}
+	if !isLocalSigningKey(data.Manifest.PublicKey) {
+		return fmt.Errorf("refusing export %s: signed by key %s, not this machine's signing key (pass --public-key to accept another machine's export)", path, data.Manifest.PublicKey)
+	}
+	return nil
+}
+
+// newConflictPrompt returns a resolver asking on out about each conflict and reading
+// the answer from in. A capital letter applies the answer to every later conflict too;
+// an empty answer, or the end of input, skips.
+func newConflictPrompt(in io.Reader, out io.Writer) func(storage.ImportConflict) (storage.ConflictStrategy, error) {
+	reader := bufio.NewReader(in)
+	var always storage.ConflictStrategy
+	return func(c storage.ImportConflict) (storage.ConflictStrategy, error) {
+		if always != "" {
+			return always, nil
+		}
+		_, _ = fmt.Fprintf(out, "\n%s %s differs (%s)\n", c.Kind, c.ID, strings.Join(c.Fields, ", "))
+		_, _ = fmt.Fprintf(out, "  stored:   %s\n", c.Stored)
+		_, _ = fmt.Fprintf(out, "  incoming: %s\n", c.Incoming)
+		for {
+			_, _ = fmt.Fprint(out, "[s]kip, [o]verwrite, [m]erge, [r]ename (capital for all remaining)? ")
+			line, err := reader.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return "", fmt.Errorf("failed to read response: %w", err)
+			}
+			answer := strings.TrimSpace(line)
+			if answer == "" {
+				if err == io.EOF {
+					_, _ = fmt.Fprintln(out)
+				}
+				return storage.ConflictSkip, nil
+			}
+			for _, strategy := range storage.ConflictStrategies {
+				if strings.EqualFold(answer, string(strategy)) || strings.EqualFold(answer, string(strategy)[:1]) {
+					if answer == strings.ToUpper(answer) {
+						always = strategy
+					}
+					return strategy, nil
+				}
+			}
+			if err == io.EOF {
+				return "", fmt.Errorf("invalid answer %q", answer)
+			}
+		}
+	}
+}
+
+// printImportResult reports what an import added and how each conflict was resolved
+func printImportResult(out io.Writer, result *storage.ExportImport) {
+	_, _ = fmt.Fprintf(out, "✓ Imported %d topics, %d turns, and %d facts", result.Blocks, result.Turns, result.Facts)
+	if result.Unchanged > 0 {
+		_, _ = fmt.Fprintf(out, " (%d already stored)", result.Unchanged)
+	}
+	_, _ = fmt.Fprintln(out)
+	if result.MaskedSecrets > 0 {
+		_, _ = fmt.Fprintf(out, "%d secret facts left out: the export masks their values\n", result.MaskedSecrets)
+	}
+	if len(result.Decisions) == 0 {
+		return
+	}
+
+	counts := map[storage.ConflictStrategy]int{}
+	_, _ = fmt.Fprintf(out, "\n%d conflicts:\n", len(result.Decisions))
+	for _, d := range result.Decisions {
+		counts[d.Strategy]++
+		line := fmt.Sprintf("  %-9s %s %s (%s)", d.Strategy, d.Kind, d.ID, strings.Join(d.Fields, ", "))
+		if d.NewID != "" {
+			line += " → " + d.NewID
+		}
+		_, _ = fmt.Fprintln(out, line)
+	}
+	var summary []string
+	for _, strategy := range storage.ConflictStrategies {
+		if counts[strategy] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[strategy], strategy))
+		}
+	}
+	_, _ = fmt.Fprintf(out, "Decisions: %s\n", strings.Join(summary, ", "))
+}
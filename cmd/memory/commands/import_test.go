@@ -0,0 +1,152 @@
+// ABOUTME: Tests for the import command
+// ABOUTME: Verifies importing an export into another database, conflict flags, the interactive prompt, and --verify
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+func TestImportCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(dataDir, stdin string, args ...string) (string, string, error) {
+		t.Helper()
+		t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, dataDir))
+		out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+		root := NewRootCmd()
+		root.SetOut(out)
+		root.SetErr(errOut)
+		root.SetIn(strings.NewReader(stdin))
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), errOut.String(), err
+	}
+
+	// The laptop's memory, exported before and after tagging its topic
+	if _, _, err := run("laptop", "", "add", "planning the garden beds"); err != nil {
+		t.Fatalf("add error = %v", err)
+	}
+	first := filepath.Join(dir, "first.yaml")
+	if _, _, err := run("laptop", "", "export", "-o", first); err != nil {
+		t.Fatalf("export error = %v", err)
+	}
+	store, err := storage.NewStorageWithPath(filepath.Join(dir, "laptop", "memory.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := store.GetActiveBridgeBlocks()
+	_ = store.Close()
+	if err != nil || len(blocks) != 1 {
+		t.Fatalf("GetActiveBridgeBlocks() = %d blocks, %v; want 1", len(blocks), err)
+	}
+	blockID := blocks[0].BlockID
+	if _, _, err := run("laptop", "", "tag", blockID, "+garden"); err != nil {
+		t.Fatalf("tag error = %v", err)
+	}
+	second := filepath.Join(dir, "second.yaml")
+	if _, _, err := run("laptop", "", "export", "-o", second); err != nil {
+		t.Fatalf("export error = %v", err)
+	}
+
+	out, _, err := run("desktop", "", "import", first)
+	if err != nil || !strings.Contains(out, "✓ Imported 1 topics, 1 turns, and 0 facts") {
+		t.Fatalf("import = %q, %v, want 1 topic imported", out, err)
+	}
+	out, _, err = run("desktop", "", "import", first)
+	if err != nil || !strings.Contains(out, "(1 already stored)") || strings.Contains(out, "conflicts") {
+		t.Errorf("import again = %q, %v, want it already stored", out, err)
+	}
+
+	// Not a terminal, so the conflict is skipped
+	out, _, err = run("desktop", "", "import", second)
+	if err != nil || !strings.Contains(out, "1 conflicts:") || !strings.Contains(out, "skip      block "+blockID+" (tags)") {
+		t.Errorf("import of the tagged export = %q, %v, want the conflict skipped", out, err)
+	}
+
+	// Asked, and told to rename
+	out, prompt, err := run("desktop", "x\nr\n", "import", second, "--on-conflict", "ask", "--format", "json")
+	if err != nil {
+		t.Fatalf("import --on-conflict ask error = %v", err)
+	}
+	if !strings.Contains(prompt, "block "+blockID+" differs (tags)") || strings.Count(prompt, "[s]kip") != 2 {
+		t.Errorf("prompt = %q, want the conflict described and asked twice after a bad answer", prompt)
+	}
+	var result storage.ExportImport
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("import JSON did not parse: %v\n%s", err, out)
+	}
+	if len(result.Decisions) != 1 || result.Decisions[0].Strategy != storage.ConflictRename || result.Decisions[0].NewID == "" || result.Blocks != 1 {
+		t.Errorf("import result = %+v, want the block renamed", result)
+	}
+
+	if _, _, err := run("desktop", "", "import", second, "--on-conflict", "replace"); err == nil {
+		t.Error("import --on-conflict replace succeeded, want an error")
+	}
+
+	// --verify refuses an export stripped of its manifest
+	raw, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := strings.Index(string(raw), "\nmanifest:")
+	if i < 0 {
+		t.Fatal("export has no manifest")
+	}
+	stripped := filepath.Join(dir, "stripped.yaml")
+	if err := os.WriteFile(stripped, raw[:i+1], 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := run("desktop", "", "import", stripped, "--verify"); err == nil || !errors.Is(err, storage.ErrNoManifest) {
+		t.Errorf("import --verify without a manifest = %v, want it refused", err)
+	}
+	if _, _, err := run("desktop", "", "import", first, "--verify"); err != nil {
+		t.Errorf("import --verify = %v, want the manifest accepted", err)
+	}
+
+	// --require-signature accepts only this machine's signature
+	if _, _, err := run("desktop", "", "import", first, "--require-signature"); err == nil || !strings.Contains(err.Error(), "not signed") {
+		t.Errorf("import --require-signature of an unsigned export = %v, want it refused", err)
+	}
+	signed := filepath.Join(dir, "signed.yaml")
+	if _, _, err := run("laptop", "", "export", "--sign", "-o", signed); err != nil {
+		t.Fatalf("export --sign error = %v", err)
+	}
+	if _, _, err := run("desktop", "", "import", signed, "--require-signature"); err != nil {
+		t.Errorf("import --require-signature of a signed export = %v, want it accepted", err)
+	}
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "other", "config.yaml"))
+	if _, _, err := run("laptop", "", "export", "--sign", "-o", signed); err != nil {
+		t.Fatalf("export --sign error = %v", err)
+	}
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	if _, _, err := run("desktop", "", "import", signed, "--require-signature"); err == nil || !strings.Contains(err.Error(), "not this machine's signing key") {
+		t.Errorf("import --require-signature of an export signed elsewhere = %v, want it refused", err)
+	}
+
+	// --public-key accepts that key's signature instead of this machine's
+	exported, err := storage.LoadExport(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := run("desktop", "", "import", signed, "--public-key", exported.Manifest.PublicKey); err != nil {
+		t.Errorf("import --public-key of an export signed with that key = %v, want it accepted", err)
+	}
+	if _, _, err := run("desktop", "", "import", signed, "--public-key", "AAAA"); err == nil || !strings.Contains(err.Error(), "not the expected") {
+		t.Errorf("import with the wrong --public-key = %v, want it refused", err)
+	}
+	if _, _, err := run("desktop", "", "import", first, "--public-key", exported.Manifest.PublicKey); err == nil || !strings.Contains(err.Error(), "not signed") {
+		t.Errorf("import --public-key of an unsigned export = %v, want it refused", err)
+	}
+}
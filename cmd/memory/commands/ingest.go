@@ -0,0 +1,197 @@
+// ABOUTME: CLI command to store a directory of notes (meeting notes, journals) as memories
+// ABOUTME: Ingests new and changed files once, or keeps watching the directory with --watch
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/llm"
+)
+
+// NewIngestCmd creates the ingest command
+func NewIngestCmd() *cobra.Command {
+	var (
+		watch      bool
+		interval   time.Duration
+		extensions []string
+		tags       []string
+		noOrigin   bool
+		noWait     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "ingest <dir>",
+		Short: "Store a directory of notes as memories",
+		Long: `Store the text files in a directory, such as meeting notes or a journal,
+as memories. Each file becomes its own topic, labeled with the file name and
+dated by its modification time; long files are split into sections between
+paragraphs. The source path is attached to the note, and the device, host,
+directory, and git repository are recorded as its origin.
+
+Files are read again only when their size or modification time changes, and
+stored again only when their content did: the new version replaces the note
+the old one became (facts learned from it are kept). Hidden files and
+directories are ignored. Deleting a file leaves its note in memory.
+
+With an OpenAI key, metadata, fact extraction, and embeddings run before the
+command returns unless --no-wait is given, which leaves them for a running
+MCP server or 'memory jobs run'.
+
+Examples:
+  memory ingest ~/notes/meetings
+  memory ingest ~/journal --watch
+  memory ingest ./docs --ext .md --ext .rst --tags project-x`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive, got %s", interval)
+			}
+			dir, err := filepath.Abs(args[0])
+			if err != nil {
+				return fmt.Errorf("resolving directory: %w", err)
+			}
+			if info, err := os.Stat(dir); err != nil {
+				return fmt.Errorf("reading directory: %w", err)
+			} else if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", dir)
+			}
+
+			// Load .env for API keys
+			_ = godotenv.Load()
+
+			store, cfg, err := openStorageWithConfig()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			var openaiClient *llm.OpenAIClient
+			if cfg.OpenAIKey != "" {
+				client, err := llm.NewOpenAIClientWithConfig(llm.ConfigFromSettings(cfg))
+				if err != nil {
+					if verbose {
+						fmt.Fprintf(os.Stderr, "Warning: Could not initialize OpenAI client: %v\n", err)
+					}
+				} else {
+					openaiClient = client
+					openaiClient.SetUsageRecorder(store)
+				}
+			}
+			queue := core.NewJobQueue(store)
+			core.RegisterJobHandlers(queue, openaiClient, nil)
+
+			opts := core.IngestOptions{Extensions: extensions, Tags: tags}
+			if !noOrigin {
+				opts.Origin = currentOrigin(cfg.Device)
+				opts.Origin.WorkingDir, opts.Origin.GitRepo = dir, gitRepoOf(dir)
+			}
+			opts.Origin.Agent = cfg.AgentID
+			ingester := core.NewIngester(store, queue, opts)
+
+			scan := func(single bool) error {
+				result, err := ingester.Scan(dir)
+				if err != nil {
+					return fmt.Errorf("ingest failed: %w", err)
+				}
+				if openaiClient != nil && !noWait {
+					for _, job := range result.Jobs {
+						if err := queue.Run(cmd.Context(), job.ID); err != nil && verbose {
+							fmt.Fprintf(os.Stderr, "Warning: %s job %d failed and will be retried: %v\n", job.Kind, job.ID, err)
+						}
+					}
+				}
+				if !single && len(result.Ingested) == 0 && len(result.Skipped) == 0 {
+					return nil
+				}
+				return printIngestResult(cmd, result, single, openaiClient != nil)
+			}
+
+			if err := scan(!watch); err != nil || !watch {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			if !quiet && outputFormat != "json" {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Watching %s every %s (Ctrl-C to stop)\n", dir, interval)
+			}
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+				if err := scan(false); err != nil {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&watch, "watch", false, "Keep ingesting new and changed files until interrupted")
+	cmd.Flags().DurationVar(&interval, "interval", core.DefaultIngestInterval, "How often --watch rescans the directory")
+	cmd.Flags().StringSliceVar(&extensions, "ext", nil, "File extensions to ingest (repeatable; default .md, .markdown, .txt)")
+	cmd.Flags().StringSliceVar(&tags, "tags", nil, "Tags for every note (comma-separated)")
+	cmd.Flags().BoolVar(&noOrigin, "no-origin", false, "Don't record the device, hostname, directory, or git repo on the notes")
+	cmd.Flags().BoolVar(&noWait, "no-wait", false, "Queue metadata, fact extraction, and embeddings without waiting for them")
+
+	return cmd
+}
+
+// printIngestResult reports one scan: indented JSON when it is the only output, one
+// line per scan while watching
+func printIngestResult(cmd *cobra.Command, result *core.IngestResult, single, enriched bool) error {
+	if outputFormat == "json" {
+		var jsonData []byte
+		var err error
+		if single {
+			jsonData, err = json.MarshalIndent(result, "", "  ")
+		} else {
+			jsonData, err = json.Marshal(result)
+		}
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+	for _, skipped := range result.Skipped {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Skipped %s: %s\n", relativeTo(result.Dir, skipped.Path), skipped.Reason)
+	}
+	if quiet {
+		return nil
+	}
+	for _, note := range result.Ingested {
+		verb := "Stored"
+		if note.Replaced {
+			verb = "Updated"
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  %s %s (block: %s, %d sections)\n", verb, relativeTo(result.Dir, note.Path), note.BlockID, note.Sections)
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Ingested %d files from %s: %d unchanged, %d skipped\n",
+		len(result.Ingested), result.Dir, result.Unchanged, len(result.Skipped))
+	if !enriched && len(result.Ingested) > 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "  Metadata, facts, and embeddings queued until an OpenAI key is available")
+	}
+	return nil
+}
+
+// relativeTo shortens path to its place under dir for display
+func relativeTo(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return rel
+	}
+	return path
+}
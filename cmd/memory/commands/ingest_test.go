@@ -0,0 +1,66 @@
+// ABOUTME: Tests for the ingest command
+// ABOUTME: Verifies notes are stored once, unchanged files are skipped, and a directory is required
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIngestCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	notes := filepath.Join(dir, "notes")
+	if err := os.MkdirAll(notes, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(notes, "retro.md"), []byte("The deploy went well."), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := run("ingest", notes)
+	if err != nil {
+		t.Fatalf("ingest: %v\n%s", err, out)
+	}
+	for _, want := range []string{"Stored retro.md", "✓ Ingested 1 files from " + notes, "queued until an OpenAI key"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("ingest = %q, want it to contain %q", out, want)
+		}
+	}
+	if out, err := run("ingest", notes); err != nil || !strings.Contains(out, "✓ Ingested 0 files from "+notes+": 1 unchanged") {
+		t.Errorf("second ingest = %q, %v; want the file unchanged", out, err)
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	results, err := store.SearchMemory("retro", 5)
+	if err != nil || len(results) != 1 {
+		t.Errorf("SearchMemory() after ingest = %+v, %v; want the note", results, err)
+	}
+
+	if _, err := run("ingest", filepath.Join(notes, "retro.md")); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("ingest of a file = %v, want an error", err)
+	}
+}
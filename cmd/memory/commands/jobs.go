@@ -0,0 +1,451 @@
+// ABOUTME: CLI command to inspect and manage the background job queue
+// ABOUTME: Lists jobs, runs due ones now, retries failures, and purges finished jobs
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/llm"
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+var (
+	jobsStatus    string
+	jobsLimit     int
+	jobsOlderThan time.Duration
+	jobsTask      string
+)
+
+// NewJobsCmd creates the jobs command group
+func NewJobsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Show and manage background jobs",
+		Long: `Show and manage background jobs.
+
+Embedding turns, extracting facts, and learning the user profile run as
+jobs in a queue stored in the database. MCP servers work through the
+queue in the background (job_workers at a time); a job that fails is
+retried with backoff and marked failed after 5 attempts. With no
+subcommand, lists the most recent jobs.
+
+Servers also run built-in tasks (consolidate, prune, digest, reembed,
+sync) at the times set by the schedule setting; 'memory jobs history'
+shows how each run went.
+
+Examples:
+  memory jobs
+  memory jobs list --status failed
+  memory jobs run
+  memory jobs retry
+  memory jobs purge --older-than 168h
+  memory jobs history`,
+		RunE: runJobsList,
+	}
+	addJobsListFlags(cmd)
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List recent jobs",
+		Long: `List recent jobs, newest first, with a count of jobs in each status.
+
+Examples:
+  memory jobs list
+  memory jobs list --status pending --limit 50
+  memory jobs list --format json`,
+		Args: cobra.NoArgs,
+		RunE: runJobsList,
+	}
+	addJobsListFlags(list)
+
+	cmd.AddCommand(list)
+	cmd.AddCommand(newJobsRunCmd())
+	cmd.AddCommand(newJobsRetryCmd())
+	cmd.AddCommand(newJobsPurgeCmd())
+	cmd.AddCommand(newJobsHistoryCmd())
+
+	return cmd
+}
+
+// addJobsListFlags adds the list filters to cmd
+func addJobsListFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&jobsStatus, "status", "", "Only show jobs with this status (pending, running, done, failed)")
+	cmd.Flags().IntVar(&jobsLimit, "limit", 20, "Maximum number of jobs to show")
+}
+
+func runJobsList(cmd *cobra.Command, args []string) error {
+	if err := validatePositiveInt(jobsLimit, "limit"); err != nil {
+		return err
+	}
+	var status models.JobStatus
+	if jobsStatus != "" {
+		var err error
+		if status, err = models.ParseJobStatus(jobsStatus); err != nil {
+			return err
+		}
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	counts, err := store.JobCounts()
+	if err != nil {
+		return fmt.Errorf("counting jobs: %w", err)
+	}
+	queue, err := store.JobQueueStats()
+	if err != nil {
+		return fmt.Errorf("counting jobs: %w", err)
+	}
+	jobs, err := store.ListJobs(status, jobsLimit)
+	if err != nil {
+		return fmt.Errorf("listing jobs: %w", err)
+	}
+
+	if outputFormat == "json" {
+		if jobs == nil {
+			jobs = []models.Job{}
+		}
+		jsonData, err := json.MarshalIndent(map[string]interface{}{
+			"counts":          counts,
+			"pending_by_kind": queue.Pending,
+			"jobs":            jobs,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "Pending: %d  Running: %d  Done: %d  Failed: %d\n",
+		counts[models.JobPending], counts[models.JobRunning], counts[models.JobDone], counts[models.JobFailed])
+	if len(queue.Pending) > 0 {
+		kinds := make([]string, 0, len(queue.Pending))
+		for kind, n := range queue.Pending {
+			kinds = append(kinds, fmt.Sprintf("%s %d", kind, n))
+		}
+		sort.Strings(kinds)
+		_, _ = fmt.Fprintf(out, "Pending by kind: %s\n", strings.Join(kinds, ", "))
+	}
+	if len(jobs) == 0 {
+		if !quiet {
+			_, _ = fmt.Fprintf(out, "No jobs\n")
+		}
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(out, "\n")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "ID\tKIND\tSTATUS\tATTEMPTS\tUPDATED\tERROR\n")
+	_, _ = fmt.Fprintf(w, "--\t----\t------\t--------\t-------\t-----\n")
+	for _, job := range jobs {
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%d/%d\t%s\t%s\n", job.ID, job.Kind, job.Status,
+			job.Attempts, job.MaxAttempts, formatTime(job.UpdatedAt), truncate(job.LastError, 50))
+	}
+	return w.Flush()
+}
+
+func newJobsRunCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "run [job_id]...",
+		Short: "Run due jobs now",
+		Long: `Run jobs in this process instead of waiting for an MCP server.
+
+With job IDs, runs those jobs whether or not they are due. Otherwise runs
+every due job until the queue is empty; jobs that fail are rescheduled
+and left for a later run. Press Ctrl-C to stop after the current job.
+
+Requires OPENAI_API_KEY (or a key saved with 'memory auth set openai'),
+except to deliver webhooks; without a key only webhook deliveries run.
+
+Examples:
+  memory jobs run
+  memory jobs run 42 43`,
+		RunE: runJobsRun,
+	}
+}
+
+func runJobsRun(cmd *cobra.Command, args []string) error {
+	ids, err := parseJobIDs(args)
+	if err != nil {
+		return err
+	}
+
+	// Load .env for API keys
+	_ = godotenv.Load()
+
+	store, cfg, err := openStorageWithConfig()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// Webhook deliveries need no API key; the other jobs do
+	hooks := webhooks(cfg)
+	if cfg.OpenAIKey == "" && hooks == nil {
+		return fmt.Errorf("OPENAI_API_KEY is required to run jobs (or run 'memory auth set openai')")
+	}
+	queue := core.NewJobQueue(store)
+	if cfg.OpenAIKey != "" {
+		client, err := llm.NewOpenAIClientWithConfig(llm.ConfigFromSettings(cfg))
+		if err != nil {
+			return fmt.Errorf("initializing OpenAI client: %w", err)
+		}
+		client.SetUsageRecorder(store)
+		core.RegisterJobHandlers(queue, client, core.NewScribe(client))
+	}
+	if hooks != nil {
+		queue.Handle(models.JobDeliverWebhook, hooks.Deliver)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var result core.JobRunResult
+	if len(ids) > 0 {
+		for _, id := range ids {
+			if err := queue.Run(ctx, id); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Job %d: %v\n", id, err)
+				result.Failed++
+				continue
+			}
+			result.Done++
+		}
+	} else if result, err = queue.RunDue(ctx); err != nil {
+		return fmt.Errorf("running jobs: %w", err)
+	}
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	if !quiet {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Ran %d jobs: %d done, %d to retry, %d failed\n",
+			result.Done+result.Retried+result.Failed, result.Done, result.Retried, result.Failed)
+	}
+	return nil
+}
+
+func newJobsRetryCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "retry [job_id]...",
+		Short: "Requeue failed jobs",
+		Long: `Put failed jobs back in the queue with fresh attempts.
+
+With job IDs, requeues those jobs; otherwise every failed job.
+
+Examples:
+  memory jobs retry
+  memory jobs retry 42`,
+		RunE: runJobsRetry,
+	}
+}
+
+func runJobsRetry(cmd *cobra.Command, args []string) error {
+	ids, err := parseJobIDs(args)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	n, err := store.RetryJobs(ids...)
+	if err != nil {
+		return fmt.Errorf("retrying jobs: %w", err)
+	}
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(map[string]int{"requeued": n}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	if !quiet {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Requeued %d failed jobs\n", n)
+	}
+	return nil
+}
+
+func newJobsPurgeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Delete finished jobs",
+		Long: `Delete jobs that finished successfully, keeping pending and failed ones.
+
+Examples:
+  memory jobs purge
+  memory jobs purge --older-than 168h`,
+		Args: cobra.NoArgs,
+		RunE: runJobsPurge,
+	}
+
+	cmd.Flags().DurationVar(&jobsOlderThan, "older-than", 24*time.Hour, "Only delete jobs that finished at least this long ago")
+
+	return cmd
+}
+
+func runJobsPurge(cmd *cobra.Command, args []string) error {
+	if jobsOlderThan < 0 {
+		return fmt.Errorf("older-than must not be negative, got %s", jobsOlderThan)
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	n, err := store.PurgeJobs(time.Now().Add(-jobsOlderThan))
+	if err != nil {
+		return fmt.Errorf("purging jobs: %w", err)
+	}
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(map[string]int{"deleted": n}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	if !quiet {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Deleted %d finished jobs\n", n)
+	}
+	return nil
+}
+
+func newJobsHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show runs of scheduled tasks",
+		Long: `Show runs of the built-in tasks servers run on a schedule, newest first.
+
+Tasks are scheduled with the schedule setting (or MEMORY_SCHEDULE): entries
+of task=cron separated by semicolons, e.g.
+  schedule: "consolidate=@weekly; prune=0 3 * * *; digest=0 8 * * 1-5"
+
+Tasks:
+  consolidate  archive paused and closed topics idle for 30 days
+  prune        evict archived topics to stay under max_db_size
+  digest       write a Markdown digest of new topics and facts to the data directory
+  reembed      embed turns still missing embeddings from embedding_model
+  sync         sync the Markdown vault in vault_dir
+
+Each slot runs once however many servers share the database. Read-only
+servers run no tasks.
+
+Examples:
+  memory jobs history
+  memory jobs history --task digest --limit 5
+  memory jobs history --format json`,
+		Args: cobra.NoArgs,
+		RunE: runJobsHistory,
+	}
+
+	cmd.Flags().StringVar(&jobsTask, "task", "", "Only show runs of this task (consolidate, prune, digest, reembed, sync)")
+	cmd.Flags().IntVar(&jobsLimit, "limit", 20, "Maximum number of runs to show")
+
+	return cmd
+}
+
+func runJobsHistory(cmd *cobra.Command, args []string) error {
+	if err := validatePositiveInt(jobsLimit, "limit"); err != nil {
+		return err
+	}
+	var task models.ScheduledTask
+	if jobsTask != "" {
+		var err error
+		if task, err = models.ParseScheduledTask(jobsTask); err != nil {
+			return err
+		}
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	runs, err := store.ListScheduledRuns(task, jobsLimit)
+	if err != nil {
+		return fmt.Errorf("listing scheduled runs: %w", err)
+	}
+
+	if outputFormat == "json" {
+		if runs == nil {
+			runs = []models.ScheduledRun{}
+		}
+		jsonData, err := json.MarshalIndent(runs, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	if len(runs) == 0 {
+		if !quiet {
+			_, _ = fmt.Fprintf(out, "No scheduled runs\n")
+		}
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "ID\tTASK\tSCHEDULED\tSTATUS\tDURATION\tRESULT\n")
+	_, _ = fmt.Fprintf(w, "--\t----\t---------\t------\t--------\t------\n")
+	for _, run := range runs {
+		duration, result := "-", run.Summary
+		if run.FinishedAt != nil {
+			duration = run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond).String()
+		}
+		if run.Error != "" {
+			result = run.Error
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", run.ID, run.Task, formatTime(run.ScheduledFor),
+			run.Status, duration, truncate(result, 60))
+	}
+	return w.Flush()
+}
+
+// parseJobIDs parses job ID arguments
+func parseJobIDs(args []string) ([]int64, error) {
+	ids := make([]int64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid job ID %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
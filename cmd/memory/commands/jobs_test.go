@@ -0,0 +1,143 @@
+// ABOUTME: Tests for the jobs command
+// ABOUTME: Verifies listing, retrying, and purging queued jobs end to end
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestJobsCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	if out, err := run("jobs"); err != nil || !strings.Contains(out, "No jobs") {
+		t.Fatalf("jobs on an empty queue = %q, %v; want no jobs", out, err)
+	}
+
+	// One finished job and one that ran out of attempts
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, turnID := range []string{"turn_done", "turn_failed"} {
+		if _, err := store.EnqueueJob(models.JobExtractFacts, models.TurnJob{TurnID: turnID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	done, _ := store.ClaimNextJob()
+	failed, _ := store.ClaimNextJob()
+	if done == nil || failed == nil {
+		t.Fatal("could not claim the queued jobs")
+	}
+	_ = store.CompleteJob(done.ID)
+	_ = store.FailJob(failed.ID, errors.New("invalid api key"), time.Time{})
+	_ = store.Close()
+
+	out, err := run("jobs", "list", "--status", "failed")
+	if err != nil {
+		t.Fatalf("jobs list: %v", err)
+	}
+	if !strings.Contains(out, "Done: 1  Failed: 1") || !strings.Contains(out, "invalid api key") || strings.Contains(out, "\n1 ") {
+		t.Errorf("jobs list --status failed = %q, want counts and only the failed job", out)
+	}
+	if _, err := run("jobs", "list", "--status", "stuck"); err == nil {
+		t.Error("jobs list --status stuck succeeded, want an invalid status error")
+	}
+
+	if out, err := run("jobs", "retry"); err != nil || !strings.Contains(out, "Requeued 1 failed jobs") {
+		t.Errorf("jobs retry = %q, %v; want one job requeued", out, err)
+	}
+	if out, err := run("jobs"); err != nil || !strings.Contains(out, "Pending by kind: extract_facts 1") {
+		t.Errorf("jobs after retry = %q, %v; want the pending job counted by kind", out, err)
+	}
+	if out, err := run("jobs", "purge", "--older-than", "0s"); err != nil || !strings.Contains(out, "Deleted 1 finished jobs") {
+		t.Errorf("jobs purge = %q, %v; want the finished job deleted", out, err)
+	}
+	if out, err := run("jobs", "--format", "json"); err != nil || !strings.Contains(out, `"pending": 1`) {
+		t.Errorf("jobs --format json = %q, %v; want one pending job", out, err)
+	}
+
+	if _, err := run("jobs", "run"); err == nil || !strings.Contains(err.Error(), "OPENAI_API_KEY") {
+		t.Errorf("jobs run without a key error = %v, want the missing key", err)
+	}
+	if _, err := run("jobs", "retry", "abc"); err == nil {
+		t.Error("jobs retry abc succeeded, want an invalid ID error")
+	}
+}
+
+func TestJobsHistoryCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	if out, err := run("jobs", "history"); err != nil || !strings.Contains(out, "No scheduled runs") {
+		t.Fatalf("jobs history with no runs = %q, %v; want none", out, err)
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slot := time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC)
+	prune, _ := store.StartScheduledRun(models.TaskPrune, slot)
+	digest, _ := store.StartScheduledRun(models.TaskDigest, slot)
+	if prune == nil || digest == nil {
+		t.Fatal("could not start the scheduled runs")
+	}
+	_ = store.FinishScheduledRun(prune, "evicted 0 archived blocks", nil)
+	_ = store.FinishScheduledRun(digest, "", errors.New("disk full"))
+	_ = store.Close()
+
+	out, err := run("jobs", "history")
+	if err != nil {
+		t.Fatalf("jobs history: %v", err)
+	}
+	for _, want := range []string{"TASK", "prune", "evicted 0 archived blocks", "digest", "failed", "disk full"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("jobs history = %q, want it to contain %q", out, want)
+		}
+	}
+
+	out, err = run("jobs", "history", "--task", "prune", "--format", "json")
+	if err != nil || !strings.Contains(out, `"task": "prune"`) || strings.Contains(out, "digest") {
+		t.Errorf("jobs history --task prune --format json = %q, %v; want only the prune run", out, err)
+	}
+	if _, err := run("jobs", "history", "--task", "backup"); err == nil || !strings.Contains(err.Error(), "invalid scheduled task") {
+		t.Errorf("jobs history --task backup error = %v, want an invalid task error", err)
+	}
+}
@@ -44,7 +44,7 @@ func runList(cmd *cobra.Command, args []string) error {
 	_ = godotenv.Load()
 
 	// Initialize storage
-	store, err := storage.NewStorage()
+	store, err := openStorage()
 	if err != nil {
 		return fmt.Errorf("initializing storage: %w", err)
 	}
@@ -0,0 +1,248 @@
+// ABOUTME: CLI command to show memory's history of fact values and profile versions
+// ABOUTME: With --as-of, shows the facts and profile as they were at a past time instead
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/redact"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// logTimeLayout is how the log shows times: to the minute, in the configured zone
+const logTimeLayout = "2006-01-02 15:04"
+
+// NewLogCmd creates the log command
+func NewLogCmd() *cobra.Command {
+	var (
+		asOf    string
+		key     string
+		persona string
+		limit   int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show the history of facts and the profile, or memory as of a past time",
+		Long: `Show what memory learned and when, newest first: every value recorded for a
+fact (marked superseded once a later value replaced it) and every saved
+version of the profile and its personas.
+
+With --as-of, show memory as it was at that time instead: the value each fact
+had then and the profile (with --persona, or the persona setting, layered on)
+as last saved before it. Use it to audit what an agent knew when it made a
+decision. The time is RFC 3339, "YYYY-MM-DD HH:MM" in the configured timezone,
+or a date, meaning the end of that day.
+
+Deleted facts are gone from history too. Profile history starts when the
+database was upgraded to keep it.
+
+Secret facts are shown as ********.
+
+Examples:
+  memory log
+  memory log --key home_city
+  memory log --as-of 2026-03-01
+  memory log --as-of "2026-03-01 14:30" --persona work --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validatePositiveInt(limit, "limit"); err != nil {
+				return err
+			}
+
+			store, cfg, err := openStorageWithConfig()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			if asOf == "" {
+				entries, err := store.History(key, limit)
+				if err != nil {
+					return fmt.Errorf("reading history: %w", err)
+				}
+				return printHistory(cmd, entries)
+			}
+
+			at, err := models.ParseAsOf(asOf, displayLocation)
+			if err != nil {
+				return err
+			}
+			if persona == "" {
+				persona = cfg.Persona
+			}
+			snapshot, err := store.GetMemoryAsOf(at, persona)
+			if err != nil {
+				return fmt.Errorf("reading memory as of %s: %w", asOf, err)
+			}
+			if key != "" {
+				kept := snapshot.Facts[:0]
+				for _, fact := range snapshot.Facts {
+					if fact.Key == key {
+						kept = append(kept, fact)
+					}
+				}
+				snapshot.Facts = kept
+			}
+			return printSnapshot(cmd, snapshot)
+		},
+	}
+
+	cmd.Flags().StringVar(&asOf, "as-of", "", "Show facts and the profile as they were at this time")
+	cmd.Flags().StringVar(&key, "key", "", "Only show this fact")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Most changes to show")
+	cmd.Flags().StringVar(&persona, "persona", "", "Persona layered on the profile with --as-of (default: persona config setting)")
+
+	return cmd
+}
+
+// printHistory prints history entries as a table or JSON
+func printHistory(cmd *cobra.Command, entries []storage.HistoryEntry) error {
+	out := cmd.OutOrStdout()
+	if outputFormat == "json" {
+		for _, entry := range entries {
+			if entry.Fact != nil {
+				entry.Fact.Value = logFactValue(entry.Fact)
+			}
+		}
+		if entries == nil {
+			entries = []storage.HistoryEntry{}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(out, "%s\n", data)
+		return nil
+	}
+	if len(entries) == 0 {
+		if !quiet {
+			_, _ = fmt.Fprintln(out, "No history")
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "TIME\tCHANGE\tKEY\tVALUE\tBY\n")
+	_, _ = fmt.Fprintf(w, "----\t------\t---\t-----\t--\n")
+	for _, entry := range entries {
+		at := entry.At.In(displayLocation).Format(logTimeLayout)
+		switch {
+		case entry.Fact != nil:
+			change := "fact"
+			if entry.Superseded {
+				change = "fact (superseded)"
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", at, change, truncate(entry.Fact.Key, 25),
+				truncate(oneLine(logFactValue(entry.Fact)), 40), logFactAgent(entry.Fact))
+		case entry.Profile != nil:
+			change, name := "profile", "(shared)"
+			if entry.Profile.Persona != "" {
+				change, name = "persona", entry.Profile.Persona
+			}
+			summary := profileVersionSummary(entry.Profile)
+			if entry.Profile.Deleted {
+				change, summary = "persona", "(deleted)"
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", at, change, name, truncate(summary, 40))
+		}
+	}
+	return w.Flush()
+}
+
+// printSnapshot prints memory as of a past time as text or JSON
+func printSnapshot(cmd *cobra.Command, snapshot *storage.MemorySnapshot) error {
+	out := cmd.OutOrStdout()
+	for i := range snapshot.Facts {
+		snapshot.Facts[i].Value = logFactValue(&snapshot.Facts[i])
+	}
+	if outputFormat == "json" {
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(out, "%s\n", data)
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(out, "Memory as of %s\n\n", snapshot.AsOf.In(displayLocation).Format("2006-01-02 15:04:05 MST"))
+
+	if profile := snapshot.Profile; profile == nil {
+		_, _ = fmt.Fprintf(out, "Profile: (none saved yet)\n\n")
+	} else {
+		_, _ = fmt.Fprintf(out, "Profile (saved %s)\n", profile.LastUpdated.In(displayLocation).Format(logTimeLayout))
+		name := profile.Name
+		if name == "" {
+			name = "(not set)"
+		}
+		_, _ = fmt.Fprintf(out, "  Name: %s\n", name)
+		if profile.Persona != "" {
+			_, _ = fmt.Fprintf(out, "  Persona: %s\n", profile.Persona)
+		}
+		for _, p := range profile.Preferences {
+			_, _ = fmt.Fprintf(out, "  Preference: %s\n", p)
+		}
+		for _, t := range profile.TopicsOfInterest {
+			_, _ = fmt.Fprintf(out, "  Topic: %s\n", t)
+		}
+		for _, c := range profile.Constraints {
+			_, _ = fmt.Fprintf(out, "  Constraint: %s\n", c)
+		}
+		_, _ = fmt.Fprintln(out)
+	}
+
+	_, _ = fmt.Fprintf(out, "Facts (%d)\n", len(snapshot.Facts))
+	if len(snapshot.Facts) == 0 {
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "KEY\tVALUE\tRECORDED\tBY\n")
+	_, _ = fmt.Fprintf(w, "---\t-----\t--------\t--\n")
+	for i := range snapshot.Facts {
+		fact := &snapshot.Facts[i]
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", truncate(fact.Key, 25), truncate(oneLine(fact.Value), 40),
+			fact.CreatedAt.In(displayLocation).Format(logTimeLayout), logFactAgent(fact))
+	}
+	return w.Flush()
+}
+
+// logFactValue is a fact's value, masked when secret
+func logFactValue(fact *models.Fact) string {
+	if fact.ValueType == models.ValueTypeSecret {
+		return redact.Mask
+	}
+	return fact.Value
+}
+
+// oneLine collapses runs of whitespace, including newlines, to single spaces
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// logFactAgent is who recorded a fact: its agent, or else whatever extracted it
+func logFactAgent(fact *models.Fact) string {
+	if fact.Agent != "" {
+		return fact.Agent
+	}
+	return fact.ExtractedBy
+}
+
+// profileVersionSummary describes a profile version in a few words
+func profileVersionSummary(v *storage.ProfileVersion) string {
+	var parts []string
+	if v.Name != "" {
+		parts = append(parts, "name "+v.Name)
+	}
+	parts = append(parts, fmt.Sprintf("%d preferences", len(v.Preferences)))
+	if v.Persona == "" {
+		parts = append(parts, fmt.Sprintf("%d topics", len(v.TopicsOfInterest)))
+	}
+	parts = append(parts, fmt.Sprintf("%d constraints", len(v.Constraints)))
+	return strings.Join(parts, ", ")
+}
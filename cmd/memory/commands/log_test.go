@@ -0,0 +1,90 @@
+// ABOUTME: Tests for the log command
+// ABOUTME: Covers the change history, --key, and memory as of a past time with secrets masked
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestLogCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("MEMORY_TIMEZONE", "UTC")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) string {
+		t.Helper()
+		out := &bytes.Buffer{}
+		root := NewRootCmd()
+		root.SetOut(out)
+		root.SetArgs(args)
+		if err := root.Execute(); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	if out := run("log"); !strings.Contains(out, "No history") {
+		t.Errorf("log on an empty database = %q, want No history", out)
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	march := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	may := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, f := range []*models.Fact{
+		{FactID: "fact_city_1", Key: "home_city", Value: "Chicago", Confidence: 1, CreatedAt: march, Agent: "claude-code"},
+		{FactID: "fact_city_2", Key: "home_city", Value: "Oakland", Confidence: 1, CreatedAt: may},
+		{FactID: "fact_token", Key: "api_token", Value: "sk-live-1234", ValueType: models.ValueTypeSecret, Confidence: 1, CreatedAt: march},
+	} {
+		if err := store.SaveFact(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = store.Close()
+
+	out := run("log")
+	if !strings.Contains(out, "fact (superseded)") || !strings.Contains(out, "Oakland") || !strings.Contains(out, "claude-code") {
+		t.Errorf("log should list both home_city values, got:\n%s", out)
+	}
+	if strings.Contains(out, "sk-live-1234") {
+		t.Errorf("log should mask secrets, got:\n%s", out)
+	}
+	if out := run("log", "--key", "api_token"); strings.Contains(out, "home_city") || !strings.Contains(out, "********") {
+		t.Errorf("log --key api_token should show only the masked token, got:\n%s", out)
+	}
+
+	out = run("log", "--as-of", "2026-04-01")
+	if !strings.Contains(out, "Memory as of 2026-04-01 23:59:59 UTC") || !strings.Contains(out, "Chicago") || strings.Contains(out, "Oakland") {
+		t.Errorf("log --as-of 2026-04-01 should show the March home_city, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Facts (2)") || strings.Contains(out, "sk-live-1234") {
+		t.Errorf("log --as-of should list two facts with the secret masked, got:\n%s", out)
+	}
+
+	var snapshot struct {
+		Facts []models.Fact `json:"facts"`
+	}
+	out = run("log", "--as-of", "2026-06-01 00:00", "--key", "home_city", "--format", "json")
+	if err := json.Unmarshal([]byte(out), &snapshot); err != nil {
+		t.Fatalf("log --format json output is not JSON: %v\n%s", err, out)
+	}
+	if len(snapshot.Facts) != 1 || snapshot.Facts[0].Value != "Oakland" {
+		t.Errorf("log --as-of June --key home_city = %+v, want Oakland", snapshot.Facts)
+	}
+
+	if out := run("log", "--as-of", "2026-01-01"); !strings.Contains(out, "Facts (0)") || !strings.Contains(out, "(none saved yet)") {
+		t.Errorf("log --as-of before anything should be empty, got:\n%s", out)
+	}
+}
@@ -9,17 +9,30 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/harper/remember-standalone/internal/chatproxy"
 	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/diagnostics"
+	"github.com/harper/remember-standalone/internal/health"
 	"github.com/harper/remember-standalone/internal/llm"
 	"github.com/harper/remember-standalone/internal/mcp"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/rest"
 	"github.com/harper/remember-standalone/internal/storage"
+	"github.com/harper/remember-standalone/internal/tracing"
 	"github.com/joho/godotenv"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 )
 
+var (
+	mcpReadOnly   bool
+	mcpHealthAddr string
+	mcpDebugAddr  string
+)
+
 // NewMCPCmd creates the MCP command
 func NewMCPCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -30,7 +43,22 @@ func NewMCPCmd() *cobra.Command {
 Runs Memory as an MCP (Model Context Protocol) server, enabling
 LLM agents like Claude to use hierarchical memory via stdio.
 
-Configure in Claude Desktop's config file to enable memory tools.`,
+Configure in Claude Desktop's config file to enable memory tools.
+
+With --read-only (or read_only: true in config.yaml) only the retrieval and
+listing tools are offered and the database is opened read-only, so memory can
+be shared with untrusted or experimental agents safely.
+
+With --health-addr (or health_addr in config.yaml) the server also answers
+GET /healthz over HTTP with the same report as the health tool, for
+launchd, systemd, or other supervisors: 503 when storage is unreachable,
+200 otherwise.
+
+With --debug-addr (or debug_addr in config.yaml) it serves Go's pprof
+profiles under /debug/pprof/ and runtime variables (goroutines, memory
+stats, job queue, caches) at /debug/vars, for diagnosing leaks on
+long-running servers. Keep it on a loopback address: profiles can expose
+memory contents.`,
 		RunE: runMCP,
 		Example: `  # Start MCP server (typically called by Claude Desktop)
   memory mcp
@@ -43,32 +71,129 @@ Configure in Claude Desktop's config file to enable memory tools.`,
   #       "args": ["mcp"]
   #     }
   #   }
-  # }`,
+  # }
+
+  # Share memory without letting the agent change it
+  memory mcp --read-only
+
+  # Let a supervisor poll http://127.0.0.1:8765/healthz
+  memory mcp --health-addr 127.0.0.1:8765
+
+  # Profile a running server: go tool pprof http://127.0.0.1:6060/debug/pprof/heap
+  memory mcp --debug-addr 127.0.0.1:6060`,
 	}
 
+	cmd.Flags().BoolVar(&mcpReadOnly, "read-only", false, "Expose only retrieval tools and reject writes (overrides read_only in config)")
+	cmd.Flags().StringVar(&mcpHealthAddr, "health-addr", "", "Serve /healthz on this host:port (overrides health_addr in config)")
+	cmd.Flags().StringVar(&mcpDebugAddr, "debug-addr", "", "Serve pprof and expvar on this host:port (overrides debug_addr in config)")
+
 	return cmd
 }
 
 // runMCP starts the MCP server
 func runMCP(cmd *cobra.Command, args []string) error {
+	return runServer(cmd, false)
+}
+
+// runServer starts the storage, job queue, and tools shared by 'memory mcp' and
+// 'memory serve', then serves the tools over stdio, or over the REST API when
+// serveREST is set, until a signal or the client hangs up
+func runServer(cmd *cobra.Command, serveREST bool) error {
 	// Load .env file if it exists (for API keys)
 	if err := godotenv.Load(); err != nil && !quiet {
 		log.Printf("No .env file found (this is okay for production): %v", err)
 	}
 
-	// Verify we have required API keys
-	if os.Getenv("OPENAI_API_KEY") == "" {
-		log.Println("Warning: OPENAI_API_KEY not set - embeddings and LLM features will not work")
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	readOnly := cfg.ReadOnly
+	if cmd.Flags().Changed("read-only") {
+		readOnly = mcpReadOnly
+	}
+	if cmd.Flags().Changed("health-addr") {
+		if err := cfg.Override("health_addr", mcpHealthAddr); err != nil {
+			return err
+		}
+	}
+	if cmd.Flags().Changed("debug-addr") {
+		if err := cfg.Override("debug_addr", mcpDebugAddr); err != nil {
+			return err
+		}
+	}
+	if serveREST {
+		if cmd.Flags().Changed("http") {
+			if err := cfg.Override("http_addr", serveHTTPAddr); err != nil {
+				return err
+			}
+		}
+		if cfg.HTTPToken == "" && cfg.HTTPReadToken == "" && !rest.IsLoopback(cfg.HTTPAddr) {
+			return fmt.Errorf("http_token (or MEMORY_HTTP_TOKEN) is required to serve the REST API on %s; without one only loopback addresses are allowed", cfg.HTTPAddr)
+		}
 	}
 
 	// Initialize storage with XDG-compliant paths
-	store, err := storage.NewStorage()
+	store, err := openContextStorage(cfg, readOnly)
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
 
+	// Check the database before serving it, repairing what can be repaired safely
+	selfCheck := core.RunSelfCheck(store, !readOnly)
+	selfCheck.Log()
+
+	// Run the built-in tasks listed in schedule at their times
+	scheduler := core.NewScheduler(store, cfg.Location)
+	for _, entry := range cfg.Schedule {
+		scheduler.Schedule(entry.Task, entry.Cron)
+	}
+
+	// Keep the database under max_db_size, enforce retention policies, and compact old
+	// archived topics, unless pruning runs on a schedule
+	pruner := core.NewPruner(store, cfg.MaxDBSize, cfg.ArchiveRetention)
+	pruner.SetCompaction(cfg.CompactAfter, cfg.CompactPolicy)
+	if !readOnly && !scheduler.Scheduled(models.TaskPrune) {
+		pruner.Start(core.DefaultPruneInterval)
+	}
+
+	// Keep the Markdown vault in vault_dir in step with memory, unless it syncs on a schedule
+	vault := core.NewVaultSyncer(store, cfg.VaultDir, storage.ExportOptions{})
+	if cfg.VaultDir != "" && (readOnly || !scheduler.Scheduled(models.TaskSync)) {
+		vault.Start(core.DefaultVaultInterval)
+	}
+
+	// Opt-in telemetry counts tool calls; read-only servers never write, so they skip it
+	var onToolCall func(string)
+	skipTelemetry = readOnly
+	if tel := newTelemetry(cfg, store); tel.Enabled() && !readOnly {
+		onToolCall = func(tool string) { _ = tel.Record("mcp." + tool) }
+		go func() {
+			if _, err := tel.Report(); err != nil && verbose {
+				log.Printf("telemetry: %v", err)
+			}
+		}()
+	}
+
+	// Trace tool calls to an OpenTelemetry collector when one is configured
+	shutdownTracing := tracing.Setup(cfg.OTLPEndpoint, "memory", versionInfo.Version)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Warning: flushing traces: %v", err)
+		}
+	}()
+
+	// Verify we have required API keys (environment or keychain)
+	if cfg.OpenAIKey == "" {
+		log.Println("Warning: OPENAI_API_KEY not set - embeddings and LLM features will not work")
+	}
+
 	// Initialize Governor for smart routing
 	governor := core.NewGovernor(store)
+	governor.SetTopicMatchThreshold(cfg.TopicMatchThreshold)
+	governor.SetResumptionWindow(cfg.ResumptionWindow())
 
 	// Initialize ChunkEngine for hierarchical chunking
 	chunkEngine := core.NewChunkEngine()
@@ -76,42 +201,165 @@ func runMCP(cmd *cobra.Command, args []string) error {
 	// Initialize OpenAI client and Scribe for user profile learning (optional - only if API key is set)
 	var scribe *core.Scribe
 	var openaiClient *llm.OpenAIClient
-	if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
-		client, err := llm.NewOpenAIClient(apiKey)
+	if cfg.OpenAIKey != "" {
+		client, err := llm.NewOpenAIClientWithConfig(llm.ConfigFromSettings(cfg))
 		if err != nil {
 			log.Printf("Warning: Failed to initialize OpenAI client: %v", err)
 		} else {
 			openaiClient = client
-			scribe = core.NewScribe(openaiClient)
+			// Read-only servers never write, not even usage records or learned profile updates
+			if !readOnly {
+				openaiClient.SetUsageRecorder(store)
+				scribe = core.NewScribe(openaiClient)
+			}
 			if verbose {
 				log.Println("OpenAI client and Scribe agent initialized")
 			}
 		}
 	}
+	if cfg.RoutingMode == "embedding" {
+		if openaiClient != nil {
+			governor.SetEmbedder(openaiClient, cfg.SemanticThreshold)
+		} else {
+			log.Println("Warning: routing_mode embedding needs an OpenAI API key - routing on keywords")
+		}
+	}
+
+	// Read-only servers never write, so they run no scheduled tasks
+	if !readOnly {
+		builtins := core.BuiltinTasks{Pruner: pruner, DigestDir: cfg.DigestDir(), ReviewAfter: cfg.ProfileReviewAge()}
+		if cfg.VaultDir != "" {
+			builtins.Vault = vault
+		}
+		if openaiClient != nil {
+			builtins.Embedder = openaiClient
+		}
+		scheduler.HandleBuiltins(builtins)
+		scheduler.Start(core.DefaultScheduleInterval)
+	}
+
+	// Serve /healthz for supervisors when an address is configured
+	stopHealth := func() {}
+	if cfg.HealthAddr != "" {
+		stop, err := health.Serve(cfg.HealthAddr, health.NewChecker(store, openaiClient))
+		if err != nil {
+			pruner.Stop()
+			vault.Stop()
+			scheduler.Stop()
+			_ = store.Close()
+			return err
+		}
+		stopHealth = stop
+		if !quiet {
+			log.Printf("Health checks on http://%s/healthz", cfg.HealthAddr)
+		}
+	}
+
+	// Serve pprof and expvar for diagnosing leaks when a debug address is configured
+	stopDebug := func() {}
+	if cfg.DebugAddr != "" {
+		stop, err := diagnostics.Serve(cfg.DebugAddr, store)
+		if err != nil {
+			stopHealth()
+			pruner.Stop()
+			vault.Stop()
+			scheduler.Stop()
+			_ = store.Close()
+			return err
+		}
+		stopDebug = stop
+		if !quiet {
+			log.Printf("Debug endpoints on http://%s/debug/pprof/", cfg.DebugAddr)
+		}
+	}
 
 	// Create MCP server
+	// Tell each client at initialize which context preset retrieve_memory applies to it
+	hooks := &mcpserver.Hooks{}
+	mcp.AdvertiseContextPresets(hooks, cfg.ContextPresets)
 	server := mcpserver.NewMCPServer(
 		"HMLR Memory System",
 		"0.1.0",
+		mcpserver.WithHooks(hooks),
 	)
 
 	// Register MCP tools and get handlers for shutdown
-	handlers := mcp.RegisterTools(server, store, governor, chunkEngine, scribe, openaiClient)
+	handlers := mcp.RegisterTools(server, store, governor, chunkEngine, scribe, openaiClient, mcp.Options{
+		DefaultMaxResults: cfg.RetrievalK,
+		DefaultPersona:    cfg.Persona,
+		JobWorkers:        cfg.JobWorkers,
+		ProfileQueueLimit: cfg.ProfileQueueLimit,
+		AgentID:           cfg.AgentID,
+		ReadOnly:          readOnly,
+		OnToolCall:        onToolCall,
+		Webhooks:          webhooks(cfg),
+		ContextPresets:    cfg.ContextPresets,
+		DisabledTools:     cfg.DisabledTools,
+	})
 
 	// Setup graceful shutdown
 	ctx, stop := signal.NotifyContext(context.Background(),
 		os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	if !quiet {
-		log.Println("HMLR MCP server starting on stdio...")
+	mode := ""
+	if readOnly {
+		mode = " (read-only)"
 	}
 
-	// Start server in goroutine
+	// Serve the tools over stdio, or as the REST API until a signal arrives
 	serverErr := make(chan error, 1)
-	go func() {
-		serverErr <- mcpserver.ServeStdio(server)
-	}()
+	stopREST := func() {}
+	if serveREST {
+		facts := core.FactWeighting{MinConfidence: cfg.FactMinConfidence, HalfLife: cfg.FactHalfLife}
+		opts := rest.Options{Token: cfg.HTTPToken, ReadToken: cfg.HTTPReadToken, DefaultPersona: cfg.Persona, Facts: &facts}
+		if openaiClient != nil {
+			opts.Embedder = openaiClient
+			httpClient, err := llm.NewHTTPClient(llm.ConfigFromSettings(cfg))
+			if err != nil {
+				stopHealth()
+				stopDebug()
+				pruner.Stop()
+				vault.Stop()
+				scheduler.Stop()
+				handlers.Shutdown()
+				_ = store.Close()
+				return err
+			}
+			opts.Chat = chatproxy.New(server, store, chatproxy.Options{
+				APIKey:         cfg.OpenAIKey,
+				HTTPClient:     httpClient,
+				Embedder:       openaiClient,
+				DefaultPersona: cfg.Persona,
+				Facts:          &facts,
+			})
+		}
+		stopServing, err := rest.Serve(cfg.HTTPAddr, rest.NewHandler(server, store, opts))
+		if err != nil {
+			stopHealth()
+			stopDebug()
+			pruner.Stop()
+			vault.Stop()
+			scheduler.Stop()
+			handlers.Shutdown()
+			_ = store.Close()
+			return err
+		}
+		stopREST = stopServing
+		if !quiet {
+			log.Printf("HMLR REST API on http://%s/v1/%s, documented at /openapi.json", cfg.HTTPAddr, mode)
+			if opts.Chat != nil {
+				log.Printf("OpenAI-compatible chat with memory at http://%s/v1/chat/completions", cfg.HTTPAddr)
+			}
+		}
+	} else {
+		if !quiet {
+			log.Printf("HMLR MCP server starting on stdio%s...", mode)
+		}
+		go func() {
+			serverErr <- mcpserver.ServeStdio(server)
+		}()
+	}
 
 	// Wait for shutdown signal or server error
 	select {
@@ -120,7 +368,13 @@ func runMCP(cmd *cobra.Command, args []string) error {
 			log.Println("Shutdown signal received, gracefully shutting down...")
 		}
 
-		// Wait for all async Scribe operations to complete
+		// Let running background jobs finish; queued ones wait for the next start
+		stopREST()
+		stopHealth()
+		stopDebug()
+		pruner.Stop()
+		vault.Stop()
+		scheduler.Stop()
 		handlers.Shutdown()
 
 		// Close storage (flushes pending writes, closes DB)
@@ -133,6 +387,17 @@ func runMCP(cmd *cobra.Command, args []string) error {
 		}
 
 	case err := <-serverErr:
+		// The client hung up: still stop the job workers and close storage, which
+		// saves cache stats
+		stopHealth()
+		stopDebug()
+		pruner.Stop()
+		vault.Stop()
+		scheduler.Stop()
+		handlers.Shutdown()
+		if closeErr := store.Close(); closeErr != nil {
+			log.Printf("Warning: Error closing storage: %v", closeErr)
+		}
 		if err != nil {
 			return fmt.Errorf("server error: %w", err)
 		}
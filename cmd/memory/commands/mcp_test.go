@@ -68,3 +68,15 @@ func TestMCPCmd_Example(t *testing.T) {
 		t.Error("Example should mention Claude Desktop config")
 	}
 }
+
+func TestMCPCmd_ReadOnlyFlag(t *testing.T) {
+	cmd := NewMCPCmd()
+
+	flag := cmd.Flags().Lookup("read-only")
+	if flag == nil {
+		t.Fatal("--read-only flag not found")
+	}
+	if flag.DefValue != "false" {
+		t.Errorf("--read-only default = %q, want false", flag.DefValue)
+	}
+}
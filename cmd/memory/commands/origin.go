@@ -0,0 +1,121 @@
+// ABOUTME: Gathers the device and workspace origin recorded on CLI-added turns
+// ABOUTME: Finds the enclosing git repository by reading .git directly, without running git
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// currentOrigin describes where the CLI is running: the configured device name,
+// the hostname, the working directory, and its git repository when there is one
+func currentOrigin(device string) models.Origin {
+	o := models.Origin{Device: device}
+	o.Hostname, _ = os.Hostname()
+	if wd, err := os.Getwd(); err == nil {
+		o.WorkingDir = wd
+		o.GitRepo = gitRepoOf(wd)
+	}
+	return o.Normalize()
+}
+
+// gitRepoOf returns the "origin" remote URL of the repository containing dir,
+// the repository root when it has no such remote, or "" outside a repository
+func gitRepoOf(dir string) string {
+	root, gitDir := findGitDir(dir)
+	if root == "" {
+		return ""
+	}
+	if url := originRemoteURL(filepath.Join(gitDir, "config")); url != "" {
+		return url
+	}
+	return root
+}
+
+// findGitDir walks up from dir to the first directory holding .git and returns that
+// directory and the git directory. A .git file (worktrees, submodules) points at the
+// real git directory with "gitdir: <path>"; worktrees keep their remotes in the
+// common directory named by its commondir file.
+func findGitDir(dir string) (root, gitDir string) {
+	for dir = filepath.Clean(dir); ; dir = filepath.Dir(dir) {
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			if info.IsDir() {
+				return dir, dotGit
+			}
+			if data, err := os.ReadFile(dotGit); err == nil {
+				if target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:"); ok {
+					gitDir = resolvePath(dir, strings.TrimSpace(target))
+					if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+						gitDir = resolvePath(gitDir, strings.TrimSpace(string(common)))
+					}
+					return dir, gitDir
+				}
+			}
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return "", ""
+		}
+	}
+}
+
+// resolvePath joins a relative path onto base
+func resolvePath(base, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(base, path)
+}
+
+// originRemoteURL reads the url of [remote "origin"] from a git config file
+func originRemoteURL(configPath string) string {
+	f, err := os.Open(configPath)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+
+	inOrigin := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inOrigin = line == `[remote "origin"]`
+			continue
+		}
+		if !inOrigin {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok && strings.TrimSpace(key) == "url" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// resolveSearchOrigin expands search filters given relative to where the CLI runs:
+// --repo . means the current repository and --dir is made absolute
+func resolveSearchOrigin(filter models.Origin) (models.Origin, error) {
+	if filter.GitRepo == "." {
+		wd, err := os.Getwd()
+		if err != nil {
+			return models.Origin{}, fmt.Errorf("getting working directory: %w", err)
+		}
+		if filter.GitRepo = gitRepoOf(wd); filter.GitRepo == "" {
+			return models.Origin{}, fmt.Errorf("--repo .: %s is not inside a git repository", wd)
+		}
+	}
+	if filter.WorkingDir != "" {
+		dir, err := filepath.Abs(filter.WorkingDir)
+		if err != nil {
+			return models.Origin{}, fmt.Errorf("resolving --dir: %w", err)
+		}
+		filter.WorkingDir = dir
+	}
+	return filter.Normalize(), nil
+}
@@ -0,0 +1,121 @@
+// ABOUTME: Tests for the origin recorded on CLI-added turns
+// ABOUTME: Covers git repository detection and the add and search origin flags
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitRepoOf(t *testing.T) {
+	dir := t.TempDir()
+
+	remote := filepath.Join(dir, "api")
+	writeFile(t, filepath.Join(remote, ".git", "config"), `[core]
+	bare = false
+[remote "upstream"]
+	url = git@github.com:fork/api.git
+[remote "origin"]
+	url = git@github.com:acme/api.git
+	fetch = +refs/heads/*:refs/remotes/origin/*
+`)
+	if err := os.MkdirAll(filepath.Join(remote, "cmd", "server"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	local := filepath.Join(dir, "scratch")
+	writeFile(t, filepath.Join(local, ".git", "config"), "[core]\n\tbare = false\n")
+
+	// A worktree's .git file points into the main repository's worktrees directory
+	worktree := filepath.Join(dir, "api-feature")
+	writeFile(t, filepath.Join(remote, ".git", "worktrees", "feature", "commondir"), "../..\n")
+	writeFile(t, filepath.Join(worktree, ".git"), "gitdir: "+filepath.Join(remote, ".git", "worktrees", "feature")+"\n")
+
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{"repo root", remote, "git@github.com:acme/api.git"},
+		{"subdirectory", filepath.Join(remote, "cmd", "server"), "git@github.com:acme/api.git"},
+		{"no origin remote", local, local},
+		{"worktree", worktree, "git@github.com:acme/api.git"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gitRepoOf(tt.dir); got != tt.want {
+				t.Errorf("gitRepoOf(%s) = %q, want %q", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddAndSearch_Origin(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("MEMORY_DEVICE", "work-laptop")
+	t.Setenv("MEMORY_AGENT_ID", "research-agent")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	repo := filepath.Join(dir, "api")
+	writeFile(t, filepath.Join(repo, ".git", "config"), "[remote \"origin\"]\n\turl = https://github.com/acme/api\n")
+	t.Chdir(repo)
+
+	run := func(args ...string) string {
+		t.Helper()
+		out := &bytes.Buffer{}
+		root := NewRootCmd()
+		root.SetOut(out)
+		root.SetArgs(args)
+		if err := root.Execute(); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return out.String()
+	}
+	run("add", "--tags", "flaky", "flaky integration tests in the api")
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := store.GetActiveBridgeBlocks()
+	if err != nil || len(blocks) != 1 {
+		t.Fatalf("GetActiveBridgeBlocks() = %d blocks, %v; want 1", len(blocks), err)
+	}
+	block, err := store.GetBridgeBlock(blocks[0].BlockID)
+	_ = store.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	origin := block.Turns[0].Origin
+	if origin.Device != "work-laptop" || origin.GitRepo != "https://github.com/acme/api" || origin.WorkingDir == "" || origin.Hostname == "" || origin.Agent != "research-agent" {
+		t.Errorf("Origin = %+v, want device, hostname, working dir, the repo remote, and agent_id", origin)
+	}
+
+	if out := run("search", "--format", "json", "--repo", ".", "flaky"); !findSubstring(out, blocks[0].BlockID) {
+		t.Errorf("search --repo . should find the block, got:\n%s", out)
+	}
+	if out := run("search", "--device", "home-desktop", "flaky"); !findSubstring(out, "No memories found") {
+		t.Errorf("search --device home-desktop should find nothing, got:\n%s", out)
+	}
+	if out := run("search", "--format", "json", "--agent", "research-agent", "flaky"); !findSubstring(out, blocks[0].BlockID) {
+		t.Errorf("search --agent research-agent should find the block, got:\n%s", out)
+	}
+	if out := run("search", "--agent", "claude-code", "flaky"); !findSubstring(out, "No memories found") {
+		t.Errorf("search --agent claude-code should find nothing, got:\n%s", out)
+	}
+}
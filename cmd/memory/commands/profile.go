@@ -1,12 +1,15 @@
 // ABOUTME: CLI command to view and update user profile
-// ABOUTME: Shows name, preferences, and topics of interest
+// ABOUTME: Shows name, preferences, topics of interest, and constraints; exports and imports them on their own
 package commands
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -19,6 +22,12 @@ var (
 	profileName        string
 	profilePreferences []string
 	profileTopics      []string
+	profileConstraints []string
+	profilePersona     string
+	personaDelete      bool
+	profileReviewDays  int
+	profileReviewList  bool
+	profileReplace     bool
 )
 
 // NewProfileCmd creates profile command
@@ -28,36 +37,126 @@ func NewProfileCmd() *cobra.Command {
 		Short: "View and manage user profile",
 		Long: `View and manage your user profile.
 
-The profile stores your name, preferences, and topics of interest
-that help personalize memory retrieval and context.
+The profile stores your name, preferences, topics of interest, and
+constraints (rules such as dietary restrictions that answers must
+respect) that help personalize memory retrieval and context.
+
+Personas such as "work" or "home" keep their own preferences and
+constraints, layered over the shared name and topics. Select one with
+--persona or the persona config setting.
 
 Examples:
   memory profile
   memory profile --format json
   memory profile set --name "Doctor Biz"
   memory profile set --preference "prefers TDD"
-  memory profile set --topic "Go programming"`,
+  memory profile set --topic "Go programming"
+  memory profile set --constraint "Dietary Restriction:vegetarian:strict"
+  memory profile --persona work
+  memory profile set --persona work --preference "formal tone"
+  memory profile personas
+  memory profile review
+  memory profile export profile.yaml
+  memory profile import profile.yaml`,
 		RunE: runProfileShow,
 	}
 
+	cmd.PersistentFlags().StringVar(&profilePersona, "persona", "", "Persona to show or update (default: persona config setting)")
+
 	// Add set subcommand
 	setCmd := &cobra.Command{
 		Use:   "set",
 		Short: "Update profile fields",
 		Long: `Update profile fields.
 
+Constraints are given as TYPE:DESCRIPTION[:SEVERITY], where severity is
+strict, moderate, or mild.
+
 Examples:
   memory profile set --name "Doctor Biz"
   memory profile set --preference "prefers simple solutions"
-  memory profile set --topic "MCP servers" --topic "Go programming"`,
+  memory profile set --topic "MCP servers" --topic "Go programming"
+  memory profile set --constraint "Allergy:peanuts:strict"`,
 		RunE: runProfileSet,
 	}
 
 	setCmd.Flags().StringVar(&profileName, "name", "", "Set user name")
 	setCmd.Flags().StringArrayVar(&profilePreferences, "preference", nil, "Add a preference (can be repeated)")
 	setCmd.Flags().StringArrayVar(&profileTopics, "topic", nil, "Add a topic of interest (can be repeated)")
+	setCmd.Flags().StringArrayVar(&profileConstraints, "constraint", nil, "Add a constraint as TYPE:DESCRIPTION[:SEVERITY] (can be repeated)")
+
+	personasCmd := &cobra.Command{
+		Use:   "personas [name]",
+		Short: "List personas, or delete one with --delete",
+		Long: `List profile personas with their preference and constraint counts.
+
+Examples:
+  memory profile personas
+  memory profile personas work --delete`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runProfilePersonas,
+	}
+	personasCmd.Flags().BoolVar(&personaDelete, "delete", false, "Delete the named persona")
+
+	reviewCmd := &cobra.Command{
+		Use:   "review",
+		Short: "Review preferences and topics that have not come up in a while",
+		Long: `Review profile preferences and topics not reinforced in profile_review_days.
+
+An item is reinforced whenever it is learned again, restated through
+update_user_profile or 'memory profile set', or kept in a review. For each
+stale item, keep it, edit its text, drop it from the profile, or skip it for
+now. --list (or --format json) only lists the stale items.
+
+Examples:
+  memory profile review
+  memory profile review --days 30
+  memory profile review --list`,
+		Args: cobra.NoArgs,
+		RunE: runProfileReview,
+	}
+	reviewCmd.Flags().IntVar(&profileReviewDays, "days", 0, "Review items not reinforced in this many days (default: profile_review_days config setting)")
+	reviewCmd.Flags().BoolVar(&profileReviewList, "list", false, "List stale items without prompting")
+
+	exportCmd := &cobra.Command{
+		Use:   "export <file>",
+		Short: "Export the profile and personas to a YAML file",
+		Long: `Export the shared profile and every persona, without any conversation
+history, to a YAML file that 'memory profile import' reads in another
+context or on another machine. The file carries a checksum manifest like
+'memory export' files do.
+
+Examples:
+  memory profile export profile.yaml
+  memory --context work profile export work-profile.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: runProfileExport,
+	}
+
+	importCmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import a profile exported with 'memory profile export'",
+		Long: `Import the profile and personas from a file written by 'memory profile
+export' (or the profile in a 'memory export' file).
+
+By default the file is merged in: preferences, topics, constraints, and
+personas missing here are added, and the name is taken only when none is
+set. --replace makes the profile and personas exactly the file's,
+deleting personas it does not list.
+
+Examples:
+  memory profile import profile.yaml
+  memory --context home profile import profile.yaml --replace`,
+		Args: cobra.ExactArgs(1),
+		RunE: runProfileImport,
+	}
+	importCmd.Flags().BoolVar(&profileReplace, "replace", false, "Replace the profile and personas instead of merging")
 
 	cmd.AddCommand(setCmd)
+	cmd.AddCommand(personasCmd)
+	cmd.AddCommand(reviewCmd)
+	cmd.AddCommand(exportCmd)
+	cmd.AddCommand(importCmd)
 
 	return cmd
 }
@@ -67,13 +166,13 @@ func runProfileShow(cmd *cobra.Command, args []string) error {
 	_ = godotenv.Load()
 
 	// Initialize storage
-	store, err := storage.NewStorage()
+	store, cfg, err := openStorageWithConfig()
 	if err != nil {
 		return fmt.Errorf("initializing storage: %w", err)
 	}
 	defer func() { _ = store.Close() }()
 
-	profile, err := store.GetUserProfile()
+	profile, err := store.GetUserProfileAs(selectedPersona(cfg.Persona))
 	if err != nil {
 		return fmt.Errorf("getting profile: %w", err)
 	}
@@ -104,6 +203,9 @@ func runProfileShow(cmd *cobra.Command, args []string) error {
 			name = "(not set)"
 		}
 		_, _ = fmt.Fprintf(w, "Name\t%s\n", name)
+		if profile.Persona != "" {
+			_, _ = fmt.Fprintf(w, "Persona\t%s\n", profile.Persona)
+		}
 
 		prefs := "(none)"
 		if len(profile.Preferences) > 0 {
@@ -117,6 +219,8 @@ func runProfileShow(cmd *cobra.Command, args []string) error {
 		}
 		_, _ = fmt.Fprintf(w, "Topics\t%s\n", truncate(topics, 60))
 
+		_, _ = fmt.Fprintf(w, "Constraints\t%d\n", len(profile.Constraints))
+
 		_, _ = fmt.Fprintf(w, "Last Updated\t%s\n", formatTime(profile.LastUpdated))
 
 		_ = w.Flush()
@@ -135,6 +239,17 @@ func runProfileShow(cmd *cobra.Command, args []string) error {
 				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  • %s\n", t)
 			}
 		}
+
+		if len(profile.Constraints) > 0 {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\nConstraints:\n")
+			for _, c := range profile.Constraints {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  • %s\n", c)
+			}
+		}
+
+		if stale, err := store.StaleProfileItems(time.Now().AddDate(0, 0, -cfg.ProfileReviewDays)); err == nil && len(stale) > 0 && !quiet {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\n%d items not reinforced in %d days. Review them with: memory profile review\n", len(stale), cfg.ProfileReviewDays)
+		}
 	}
 
 	return nil
@@ -145,12 +260,21 @@ func runProfileSet(cmd *cobra.Command, args []string) error {
 	_ = godotenv.Load()
 
 	// Check if any flags were provided
-	if profileName == "" && len(profilePreferences) == 0 && len(profileTopics) == 0 {
-		return fmt.Errorf("no updates specified. Use --name, --preference, or --topic")
+	if profileName == "" && len(profilePreferences) == 0 && len(profileTopics) == 0 && len(profileConstraints) == 0 {
+		return fmt.Errorf("no updates specified. Use --name, --preference, --topic, or --constraint")
+	}
+
+	constraints := make([]models.ProfileConstraint, 0, len(profileConstraints))
+	for _, raw := range profileConstraints {
+		c, err := parseConstraint(raw)
+		if err != nil {
+			return err
+		}
+		constraints = append(constraints, c)
 	}
 
 	// Initialize storage
-	store, err := storage.NewStorage()
+	store, cfg, err := openStorageWithConfig()
 	if err != nil {
 		return fmt.Errorf("initializing storage: %w", err)
 	}
@@ -166,14 +290,30 @@ func runProfileSet(cmd *cobra.Command, args []string) error {
 		profile = &models.UserProfile{}
 	}
 
+	// With a persona, preferences and constraints go to it instead of the shared profile
+	var persona *models.Persona
+	if name := selectedPersona(cfg.Persona); name != "" {
+		persona, err = store.GetPersona(name)
+		if err != nil {
+			return fmt.Errorf("getting persona: %w", err)
+		}
+		if persona == nil {
+			persona = &models.Persona{Name: name}
+		}
+	}
+
 	// Apply updates
 	if profileName != "" {
 		profile.Name = profileName
 	}
 
+	preferences := &profile.Preferences
+	if persona != nil {
+		preferences = &persona.Preferences
+	}
 	for _, pref := range profilePreferences {
-		if !containsString(profile.Preferences, pref) {
-			profile.Preferences = append(profile.Preferences, pref)
+		if !containsString(*preferences, pref) {
+			*preferences = append(*preferences, pref)
 		}
 	}
 
@@ -183,10 +323,32 @@ func runProfileSet(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	for _, c := range constraints {
+		add := profile.AddConstraint
+		if persona != nil {
+			add = persona.AddConstraint
+		}
+		if err := add(c); err != nil {
+			return fmt.Errorf("adding constraint: %w", err)
+		}
+	}
+
 	// Save profile
 	if err := store.SaveUserProfile(profile); err != nil {
 		return fmt.Errorf("saving profile: %w", err)
 	}
+	if persona != nil {
+		if err := store.SavePersona(persona); err != nil {
+			return fmt.Errorf("saving persona: %w", err)
+		}
+	}
+	personaName := ""
+	if persona != nil {
+		personaName = persona.Name
+	}
+	if err := store.ReinforceProfileItems(personaName, profilePreferences, profileTopics); err != nil {
+		return fmt.Errorf("saving profile: %w", err)
+	}
 
 	if !quiet {
 		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Profile updated successfully\n")
@@ -194,3 +356,286 @@ func runProfileSet(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func runProfilePersonas(cmd *cobra.Command, args []string) error {
+	if personaDelete && len(args) == 0 {
+		return fmt.Errorf("--delete needs a persona name")
+	}
+
+	// Initialize storage
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if personaDelete {
+		deleted, err := store.DeletePersona(args[0])
+		if err != nil {
+			return fmt.Errorf("deleting persona: %w", err)
+		}
+		if !deleted {
+			return fmt.Errorf("persona %q not found", args[0])
+		}
+		if !quiet {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Deleted persona %s\n", args[0])
+		}
+		return nil
+	}
+
+	personas, err := store.ListPersonas()
+	if err != nil {
+		return fmt.Errorf("listing personas: %w", err)
+	}
+	if len(args) == 1 {
+		name, err := models.NormalizePersonaName(args[0])
+		if err != nil {
+			return err
+		}
+		var matched []*models.Persona
+		for _, p := range personas {
+			if p.Name == name {
+				matched = append(matched, p)
+			}
+		}
+		personas = matched
+	}
+
+	if outputFormat == "json" {
+		if personas == nil {
+			personas = []*models.Persona{}
+		}
+		jsonData, err := json.MarshalIndent(personas, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	if len(personas) == 0 {
+		if !quiet {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "No personas found. Create one with: memory profile set --persona work --preference \"...\"\n")
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "PERSONA\tPREFERENCES\tCONSTRAINTS\tLAST UPDATED\n")
+	_, _ = fmt.Fprintf(w, "-------\t-----------\t-----------\t------------\n")
+	for _, p := range personas {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", p.Name, truncate(strings.Join(p.Preferences, ", "), 50), len(p.Constraints), formatTime(p.LastUpdated))
+	}
+	return w.Flush()
+}
+
+func runProfileExport(cmd *cobra.Command, args []string) error {
+	// Initialize storage
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	profile, err := store.ExportProfileToYAML(args[0])
+	if err != nil {
+		return fmt.Errorf("exporting profile: %w", err)
+	}
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(map[string]interface{}{"file": args[0], "profile": profile}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+	if !quiet {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Exported the profile (%d preferences, %d topics, %d constraints) and %d personas to %s\n",
+			len(profile.Preferences), len(profile.TopicsOfInterest), len(profile.Constraints), len(profile.Personas), args[0])
+	}
+	return nil
+}
+
+func runProfileImport(cmd *cobra.Command, args []string) error {
+	data, err := storage.LoadExport(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Initialize storage
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	result, err := store.ImportProfile(data.Profile, profileReplace)
+	if err != nil {
+		return fmt.Errorf("importing profile from %s: %w", args[0], err)
+	}
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+	if !quiet {
+		action := "Merged " + args[0] + " into the profile"
+		if result.Replaced {
+			action = "Replaced the profile with " + args[0]
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ %s: %d preferences, %d topics, and %d constraints added; %d personas updated\n",
+			action, result.Preferences, result.Topics, result.Constraints, result.Personas)
+	}
+	return nil
+}
+
+func runProfileReview(cmd *cobra.Command, args []string) error {
+	if profileReviewDays < 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+
+	// Initialize storage
+	store, cfg, err := openStorageWithConfig()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	days := profileReviewDays
+	if days == 0 {
+		days = cfg.ProfileReviewDays
+	}
+	items, err := store.StaleProfileItems(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return fmt.Errorf("listing stale profile items: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if outputFormat == "json" {
+		if items == nil {
+			items = []models.ProfileItem{}
+		}
+		jsonData, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(out, "%s\n", jsonData)
+		return nil
+	}
+
+	if len(items) == 0 {
+		if !quiet {
+			_, _ = fmt.Fprintf(out, "Nothing to review: every preference and topic was reinforced in the last %d days\n", days)
+		}
+		return nil
+	}
+
+	if profileReviewList {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "KIND\tPERSONA\tTEXT\tLAST REINFORCED\n")
+		_, _ = fmt.Fprintf(w, "----\t-------\t----\t---------------\n")
+		for _, item := range items {
+			persona := item.Persona
+			if persona == "" {
+				persona = "(shared)"
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.Kind, persona, truncate(item.Text, 60), formatTime(item.ReinforcedAt))
+		}
+		return w.Flush()
+	}
+
+	in := bufio.NewReader(cmd.InOrStdin())
+	var kept, edited, dropped int
+review:
+	for i, item := range items {
+		owner := "shared"
+		if item.Persona != "" {
+			owner = "persona " + item.Persona
+		}
+		_, _ = fmt.Fprintf(out, "\n[%d/%d] %s (%s), last reinforced %s:\n  %s\n", i+1, len(items), item.Kind, owner, formatTime(item.ReinforcedAt), item.Text)
+		for {
+			_, _ = fmt.Fprintf(out, "[k]eep, [e]dit, [d]rop, [s]kip, [q]uit? ")
+			answer, err := readReviewLine(in)
+			if err != nil {
+				break review
+			}
+			switch strings.ToLower(answer) {
+			case "k", "keep":
+				if err := store.ConfirmProfileItem(item); err != nil {
+					return fmt.Errorf("keeping %s: %w", item.Kind, err)
+				}
+				kept++
+			case "e", "edit":
+				_, _ = fmt.Fprintf(out, "New text: ")
+				text, err := readReviewLine(in)
+				if err != nil {
+					break review
+				}
+				if text == "" || text == item.Text {
+					continue
+				}
+				if err := store.EditProfileItem(item, text); err != nil {
+					return fmt.Errorf("editing %s: %w", item.Kind, err)
+				}
+				edited++
+			case "d", "drop":
+				if err := store.DropProfileItem(item); err != nil {
+					return fmt.Errorf("dropping %s: %w", item.Kind, err)
+				}
+				dropped++
+			case "s", "skip":
+			case "q", "quit":
+				break review
+			default:
+				continue
+			}
+			break
+		}
+	}
+
+	if !quiet {
+		_, _ = fmt.Fprintf(out, "\nKept %d, edited %d, dropped %d of %d items\n", kept, edited, dropped, len(items))
+	}
+	return nil
+}
+
+// readReviewLine reads one trimmed answer; a final line without a newline still counts
+func readReviewLine(in *bufio.Reader) (string, error) {
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// selectedPersona returns the --persona flag if given, else the configured default
+func selectedPersona(configured string) string {
+	if profilePersona != "" {
+		return profilePersona
+	}
+	return configured
+}
+
+// parseConstraint parses TYPE:DESCRIPTION[:SEVERITY] from the --constraint flag
+func parseConstraint(raw string) (models.ProfileConstraint, error) {
+	parts := strings.SplitN(raw, ":", 3)
+	if len(parts) < 2 {
+		return models.ProfileConstraint{}, fmt.Errorf("invalid constraint %q: want TYPE:DESCRIPTION[:SEVERITY]", raw)
+	}
+	c := models.ProfileConstraint{
+		Type:        strings.TrimSpace(parts[0]),
+		Description: strings.TrimSpace(parts[1]),
+	}
+	if len(parts) == 3 {
+		c.Severity = strings.ToLower(strings.TrimSpace(parts[2]))
+	}
+	if err := c.Validate(); err != nil {
+		return models.ProfileConstraint{}, fmt.Errorf("invalid constraint %q: %w", raw, err)
+	}
+	return c, nil
+}
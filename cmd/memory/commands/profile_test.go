@@ -1,13 +1,20 @@
 // ABOUTME: Tests for profile command
-// ABOUTME: Verifies profile display and set subcommand
+// ABOUTME: Verifies profile display, the set subcommand, personas, reviewing stale items, and export/import
 
 package commands
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage/sqlite"
 )
 
 func TestNewProfileCmd(t *testing.T) {
@@ -69,6 +76,7 @@ func TestProfileCmd_SetFlags(t *testing.T) {
 		{"name"},
 		{"preference"},
 		{"topic"},
+		{"constraint"},
 	}
 
 	for _, tt := range tests {
@@ -91,6 +99,7 @@ func TestProfileCmd_Examples(t *testing.T) {
 		"--name",
 		"--preference",
 		"--topic",
+		"--constraint",
 	}
 
 	for _, part := range expectedParts {
@@ -112,3 +121,242 @@ func TestProfileCmd_Description(t *testing.T) {
 		t.Error("Long description should mention topics of interest")
 	}
 }
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    models.ProfileConstraint
+		wantErr bool
+	}{
+		{raw: "Allergy:peanuts:strict", want: models.ProfileConstraint{Type: "Allergy", Description: "peanuts", Severity: "strict"}},
+		{raw: "Diet: vegetarian", want: models.ProfileConstraint{Type: "Diet", Description: "vegetarian"}},
+		{raw: "Diet:vegetarian:Moderate", want: models.ProfileConstraint{Type: "Diet", Description: "vegetarian", Severity: "moderate"}},
+		{raw: "vegetarian", wantErr: true},
+		{raw: "Diet:", wantErr: true},
+		{raw: "Diet:vegetarian:always", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := parseConstraint(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseConstraint(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseConstraint(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileCmd_Personas(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("MEMORY_PERSONA", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) string {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs(args)
+		if err := root.Execute(); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	run("profile", "set", "--name", "Harper", "--preference", "concise answers")
+	run("profile", "set", "--persona", "work", "--preference", "formal tone", "--topic", "Go")
+
+	shared := run("profile")
+	if strings.Contains(shared, "formal tone") || !strings.Contains(shared, "Go") {
+		t.Errorf("shared profile = %q, want topics but no work preferences", shared)
+	}
+	if work := run("profile", "--persona", "work"); !strings.Contains(work, "formal tone") || !strings.Contains(work, "concise answers") {
+		t.Errorf("work profile = %q, want shared and work preferences", work)
+	}
+
+	// The persona config setting selects the default
+	t.Setenv("MEMORY_PERSONA", "work")
+	if work := run("profile"); !strings.Contains(work, "formal tone") {
+		t.Errorf("profile with MEMORY_PERSONA=work = %q, want work preferences", work)
+	}
+	t.Setenv("MEMORY_PERSONA", "")
+
+	if list := run("profile", "personas"); !strings.Contains(list, "work") {
+		t.Errorf("personas = %q, want work listed", list)
+	}
+	run("profile", "personas", "work", "--delete")
+	if list := run("profile", "personas"); !strings.Contains(list, "No personas found") {
+		t.Errorf("personas after delete = %q", list)
+	}
+}
+
+func TestProfileCmd_Review(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("MEMORY_PERSONA", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(stdin string, args ...string) string {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetIn(strings.NewReader(stdin))
+		root.SetArgs(args)
+		if err := root.Execute(); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	run("", "profile", "set", "--preference", "Prefers tea", "--preference", "Uses vim", "--topic", "sailing", "--topic", "chess")
+	if out := run("", "profile", "review"); !strings.Contains(out, "Nothing to review") {
+		t.Fatalf("review of a fresh profile = %q, want nothing to review", out)
+	}
+
+	// Backdate everything past profile_review_days
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := store.Path()
+	_ = store.Close()
+	db, err := sqlite.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`UPDATE profile_items SET reinforced_at = ?`, time.Now().AddDate(0, -6, 0)); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+
+	if out := run("", "profile"); !strings.Contains(out, "4 items not reinforced in 90 days") {
+		t.Errorf("profile = %q, want a review hint", out)
+	}
+	if out := run("", "profile", "review", "--list"); !strings.Contains(out, "Uses vim") || !strings.Contains(out, "(shared)") {
+		t.Errorf("review --list = %q, want the stale items", out)
+	}
+	if out := run("", "profile", "review", "--days", "365"); !strings.Contains(out, "Nothing to review") {
+		t.Errorf("review --days 365 = %q, want nothing to review", out)
+	}
+
+	// Items are listed in a stable order: preferences, then topics, alphabetically
+	out := run("k\nx\ne\nUses neovim\nd\ns\n", "profile", "review")
+	if !strings.Contains(out, "Kept 1, edited 1, dropped 1 of 4 items") {
+		t.Fatalf("review = %q, want one item each kept, edited, and dropped", out)
+	}
+
+	store, err = openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	profile, err := store.GetUserProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Prefers tea", "Uses neovim"}; strings.Join(profile.Preferences, "|") != strings.Join(want, "|") {
+		t.Errorf("Preferences = %v, want %v", profile.Preferences, want)
+	}
+	if want := []string{"sailing"}; strings.Join(profile.TopicsOfInterest, "|") != strings.Join(want, "|") {
+		t.Errorf("TopicsOfInterest = %v, want %v", profile.TopicsOfInterest, want)
+	}
+	stale, err := store.StaleProfileItems(time.Now().AddDate(0, 0, -90))
+	if err != nil || len(stale) != 1 || stale[0].Text != "sailing" {
+		t.Errorf("stale after review = %+v, %v; want only the skipped topic", stale, err)
+	}
+}
+
+func TestProfileCmd_ExportImport(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("MEMORY_PERSONA", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	file := filepath.Join(dir, "profile.yaml")
+	if _, err := run("profile", "export", file); err == nil || !strings.Contains(err.Error(), "no profile") {
+		t.Errorf("export of an empty profile = %v, want an error", err)
+	}
+
+	if _, err := run("profile", "set", "--name", "Harper", "--preference", "concise answers", "--topic", "Go", "--constraint", "Allergy:peanuts:strict"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("profile", "set", "--persona", "work", "--preference", "formal tone"); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := run("profile", "export", file); err != nil || !strings.Contains(out, "✓ Exported the profile (1 preferences, 1 topics, 1 constraints) and 1 personas") {
+		t.Fatalf("export = %q, %v", out, err)
+	}
+
+	// Merging into another machine's memory keeps what it has and adds what it lacks
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "other"))
+	if _, err := run("profile", "set", "--name", "Doc", "--preference", "long walks"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("profile", "set", "--persona", "home", "--preference", "casual tone"); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := run("profile", "import", file); err != nil || !strings.Contains(out, "2 preferences, 1 topics, and 1 constraints added; 1 personas updated") {
+		t.Fatalf("import = %q, %v", out, err)
+	}
+	out, err := run("profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Doc", "long walks", "concise answers", "Go", "peanuts"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("merged profile = %q, want it to contain %q", out, want)
+		}
+	}
+	if out, _ := run("profile", "personas"); !strings.Contains(out, "home") || !strings.Contains(out, "work") {
+		t.Errorf("personas after merge = %q, want home and work", out)
+	}
+
+	// Importing again adds nothing
+	if out, err := run("profile", "import", file); err != nil || !strings.Contains(out, "0 preferences, 0 topics, and 0 constraints added") {
+		t.Errorf("second import = %q, %v", out, err)
+	}
+
+	// Replacing makes the profile exactly the file's
+	if out, err := run("profile", "import", file, "--replace"); err != nil || !strings.Contains(out, "✓ Replaced the profile with") {
+		t.Fatalf("import --replace = %q, %v", out, err)
+	}
+	if out, _ := run("profile"); strings.Contains(out, "long walks") || !strings.Contains(out, "Harper") {
+		t.Errorf("replaced profile = %q, want only the file's", out)
+	}
+	if out, _ := run("profile", "personas"); strings.Contains(out, "home") || !strings.Contains(out, "work") {
+		t.Errorf("personas after replace = %q, want only work", out)
+	}
+
+	// A file edited after export is refused
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(strings.Replace(string(raw), "formal tone", "rude tone", 1)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("profile", "import", file); err == nil || !strings.Contains(err.Error(), "refusing") {
+		t.Errorf("import of a tampered file = %v, want it refused", err)
+	}
+}
@@ -0,0 +1,143 @@
+// ABOUTME: CLI command to bring the database under max_db_size and enforce retention policies now
+// ABOUTME: Deletes expired topics and evicts the least important archived blocks, or lists them with --dry-run
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/core"
+)
+
+var pruneDryRun bool
+
+// NewPruneCmd creates the prune command
+func NewPruneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete expired topics, compact old ones, and evict archived blocks to stay under max_db_size",
+		Long: `Delete topics whose retention policy ran out, compact old archived
+topics when compact_after is set, then evict archived blocks to bring the
+database under max_db_size.
+
+MCP servers do this on their own every 10 minutes; prune does it now.
+Topics with a delete-after policy (see memory retention) are deleted once
+it runs out, with or without max_db_size. Only ARCHIVED blocks last
+updated longer than archive_retention ago are evicted, least important
+first (fewest turns and facts), until the database is down to 90% of the
+limit; topics kept by a retention policy never are. Deleting a block
+deletes its turns, attachments, and embeddings; facts learned from it
+are kept. See 'memory compact' for what compaction does.
+
+Examples:
+  memory prune --dry-run
+  memory prune
+  memory prune --format json`,
+		Args: cobra.NoArgs,
+		RunE: runPrune,
+	}
+
+	cmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "List the blocks that would be deleted without deleting them")
+
+	return cmd
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	store, cfg, err := openStorageWithConfig()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	pruner := core.NewPruner(store, cfg.MaxDBSize, cfg.ArchiveRetention)
+	pruner.SetCompaction(cfg.CompactAfter, cfg.CompactPolicy)
+
+	var result core.PruneResult
+	if pruneDryRun {
+		if result, err = pruner.Usage(); err == nil {
+			result.Expired, err = pruner.PlanExpiry()
+		}
+		if err == nil {
+			result.Compacted, err = pruner.PlanCompaction()
+		}
+		if err == nil {
+			result.Evicted, err = pruner.Plan()
+		}
+	} else {
+		result, err = pruner.Prune()
+	}
+	if err != nil {
+		return fmt.Errorf("pruning: %w", err)
+	}
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	if len(result.Expired) > 0 && (pruneDryRun || !quiet) {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "BLOCK\tTOPIC\tRETENTION\tUPDATED\n")
+		for _, block := range result.Expired {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", block.BlockID, truncate(block.TopicLabel, 30), block.Retention, formatTime(block.UpdatedAt))
+		}
+		_ = w.Flush()
+	}
+	if !quiet {
+		switch {
+		case pruneDryRun:
+			_, _ = fmt.Fprintf(out, "Would delete %d topics past their retention policy\n", len(result.Expired))
+		case len(result.Expired) > 0:
+			_, _ = fmt.Fprintf(out, "✓ Deleted %d topics past their retention policy\n", len(result.Expired))
+		}
+	}
+	if len(result.Compacted) > 0 && (pruneDryRun || !quiet) {
+		printCompactedBlocks(out, result.Compacted)
+		if !quiet {
+			verb := "✓ Compacted"
+			if pruneDryRun {
+				verb = "Would compact"
+			}
+			_, _ = fmt.Fprintf(out, "%s %d archived topics (about %s)\n", verb, len(result.Compacted), formatBytes(result.CompactedBytes()))
+		}
+	}
+	if result.MaxBytes == 0 {
+		if !quiet && !pruneDryRun && len(result.Expired) == 0 && len(result.Compacted) == 0 {
+			_, _ = fmt.Fprintf(out, "✓ No topics past their retention policy; max_db_size is not set, so nothing is evicted\n")
+		}
+		return nil
+	}
+
+	if len(result.Evicted) > 0 && (pruneDryRun || !quiet) {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "BLOCK\tTOPIC\tIMPORTANCE\tSIZE\tUPDATED\n")
+		for _, block := range result.Evicted {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", block.BlockID, truncate(block.TopicLabel, 30),
+				block.Importance, formatBytes(block.Bytes), formatTime(block.UpdatedAt))
+		}
+		_ = w.Flush()
+	}
+	if !quiet {
+		switch {
+		case pruneDryRun:
+			_, _ = fmt.Fprintf(out, "Would evict %d archived blocks (about %s); database uses %s of %s\n",
+				len(result.Evicted), formatBytes(result.Freed()), formatBytes(result.UsedBytes), formatBytes(result.MaxBytes))
+		case len(result.Evicted) > 0:
+			_, _ = fmt.Fprintf(out, "✓ Evicted %d archived blocks (about %s)\n", len(result.Evicted), formatBytes(result.Freed()))
+		default:
+			_, _ = fmt.Fprintf(out, "✓ Database uses %s of %s; nothing evicted\n", formatBytes(result.UsedBytes), formatBytes(result.MaxBytes))
+		}
+	}
+	if result.Warning != "" {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", result.Warning)
+	}
+	return nil
+}
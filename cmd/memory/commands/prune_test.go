@@ -0,0 +1,82 @@
+// ABOUTME: Tests for the prune command
+// ABOUTME: Verifies dry runs, eviction of archived blocks, and the size lines in memory stats
+
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestPruneCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("MEMORY_MAX_DB_SIZE", "")
+	t.Setenv("MEMORY_ARCHIVE_RETENTION", "0s")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_prune", Timestamp: time.Now(), UserMessage: strings.Repeat("old ", 2000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateBridgeBlockStatus(blockID, models.StatusArchived); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	if out, err := run("prune"); err != nil || !strings.Contains(out, "max_db_size is not set, so nothing is evicted") {
+		t.Errorf("prune without a limit = %q, %v; want nothing evicted", out, err)
+	}
+	if out, err := run("stats"); err != nil || !strings.Contains(out, "(no limit)") {
+		t.Errorf("stats without a limit = %q, %v; want the used size with no limit", out, err)
+	}
+
+	// Any database is over a 1 KiB limit
+	t.Setenv("MEMORY_MAX_DB_SIZE", "1KiB")
+	out, err := run("prune", "--dry-run")
+	if err != nil || !strings.Contains(out, blockID) || !strings.Contains(out, "Would evict 1 archived blocks") {
+		t.Errorf("prune --dry-run = %q, %v; want the archived block listed", out, err)
+	}
+	out, err = run("prune")
+	if err != nil || !strings.Contains(out, "✓ Evicted 1 archived blocks") || !strings.Contains(out, "Warning: database uses") {
+		t.Errorf("prune = %q, %v; want the archived block evicted and a size warning", out, err)
+	}
+	if out, err := run("prune", "--dry-run"); err != nil || !strings.Contains(out, "Would evict 0 archived blocks") {
+		t.Errorf("prune --dry-run after pruning = %q, %v; want nothing left", out, err)
+	}
+
+	out, err = run("stats")
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	for _, want := range []string{"of 1.0 KiB limit", "Evicted", "1 archived blocks", "Warning: database uses"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("stats = %q, want it to contain %q", out, want)
+		}
+	}
+	if out, err := run("stats", "--format", "json"); err != nil || !strings.Contains(out, `"max_db_size_bytes": 1024`) || !strings.Contains(out, `"blocks": 1`) {
+		t.Errorf("stats --format json = %q, %v; want the limit and eviction count", out, err)
+	}
+}
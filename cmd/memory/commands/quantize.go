@@ -0,0 +1,111 @@
+// ABOUTME: CLI command to choose how the database stores embedding vectors
+// ABOUTME: Quantizes existing vectors to float16 or int8 (or back to float64) and reports the space saved
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// NewQuantizeCmd creates the quantize command
+func NewQuantizeCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "quantize [float64|float16|int8]",
+		Short: "Store embedding vectors quantized to save space",
+		Long: `Choose how this database stores embedding vectors, and convert the ones
+already stored.
+
+  float64  8 bytes a dimension, exact (the default)
+  float16  2 bytes a dimension, a 4x saving
+  int8     1 byte a dimension plus a scale per vector, about an 8x saving
+
+Quantized vectors are dequantized when searched; rankings barely change, but
+the precision they lose is not restored by converting back to float64. The
+format is kept in the database, so every process using it stores new vectors
+the same way. Without a format, shows the current one and what vectors take.
+
+Examples:
+  memory quantize
+  memory quantize int8 --dry-run
+  memory quantize float16`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var format storage.VectorFormat
+			if len(args) == 1 {
+				var err error
+				if format, err = storage.ParseVectorFormat(args[0]); err != nil {
+					return err
+				}
+			}
+
+			store, err := openStorage()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			// Without a format, a dry run in the current one reports usage
+			show := format == ""
+			if show {
+				if format, err = store.VectorFormat(); err != nil {
+					return fmt.Errorf("reading vector format: %w", err)
+				}
+			}
+			result, err := store.ConvertVectors(format, dryRun || show)
+			if err != nil {
+				return fmt.Errorf("converting vectors: %w", err)
+			}
+
+			if outputFormat == "json" {
+				jsonData, err := json.MarshalIndent(result, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+				return nil
+			}
+
+			out := cmd.OutOrStdout()
+			if show {
+				_, _ = fmt.Fprintf(out, "Vector format: %s\n", result.Format)
+				if len(result.Before) == 0 {
+					return nil
+				}
+				_, _ = fmt.Fprintln(out)
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				_, _ = fmt.Fprintf(w, "FORMAT\tVECTORS\tSIZE\n")
+				_, _ = fmt.Fprintf(w, "------\t-------\t----\n")
+				for _, u := range result.Before {
+					_, _ = fmt.Fprintf(w, "%s\t%d\t%s\n", u.Format, u.Vectors, formatBytes(u.Bytes))
+				}
+				return w.Flush()
+			}
+			if quiet {
+				return nil
+			}
+
+			if dryRun {
+				_, _ = fmt.Fprintf(out, "Would convert %d vectors to %s (%s → %s)\n",
+					result.Converted, result.Format, formatBytes(result.BytesBefore), formatBytes(result.BytesAfter))
+				return nil
+			}
+			_, _ = fmt.Fprintf(out, "✓ Converted %d vectors to %s (%s → %s)\n",
+				result.Converted, result.Format, formatBytes(result.BytesBefore), formatBytes(result.BytesAfter))
+			if result.Previous != result.Format {
+				_, _ = fmt.Fprintf(out, "  New vectors are stored as %s (was %s)\n", result.Format, result.Previous)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what converting would save without changing anything")
+
+	return cmd
+}
@@ -0,0 +1,80 @@
+// ABOUTME: Tests for the quantize command
+// ABOUTME: Verifies showing the vector format, dry runs, and converting stored vectors to int8
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+func TestQuantizeCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_quantize_cmd", Timestamp: time.Now(), UserMessage: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vector := make([]float64, 128)
+	for i := range vector {
+		vector[i] = float64(i%7) - 3
+	}
+	chunks := []models.Chunk{{ChunkID: "chunk_quantize_cmd", TurnID: "turn_quantize_cmd"}}
+	if err := store.ReplaceTurnEmbeddings("turn_quantize_cmd", blockID, "test-model", chunks, [][]float64{vector}); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	out, err := run("quantize")
+	if err != nil || !strings.Contains(out, "Vector format: float64") || !strings.Contains(out, "1.0 KiB") {
+		t.Errorf("quantize = %q, %v; want the float64 format and its 1 KiB of vectors", out, err)
+	}
+	if _, err := run("quantize", "int4"); err == nil {
+		t.Error("quantize int4 succeeded, want an error")
+	}
+
+	out, err = run("quantize", "int8", "--dry-run")
+	if err != nil || !strings.Contains(out, "Would convert 1 vectors to int8 (1.0 KiB → 132 B)") {
+		t.Errorf("quantize --dry-run = %q, %v; want the planned saving", out, err)
+	}
+	out, err = run("quantize", "int8")
+	if err != nil || !strings.Contains(out, "✓ Converted 1 vectors to int8") || !strings.Contains(out, "(was float64)") {
+		t.Errorf("quantize int8 = %q, %v; want the conversion", out, err)
+	}
+
+	store, err = openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	if format, err := store.VectorFormat(); err != nil || format != storage.VectorInt8 {
+		t.Errorf("VectorFormat() = %s, %v; want int8", format, err)
+	}
+	if dims, err := store.EmbeddingDimensions(); err != nil || dims[128] != 1 {
+		t.Errorf("EmbeddingDimensions() = %v, %v; want the vector still 128-dimensional", dims, err)
+	}
+}
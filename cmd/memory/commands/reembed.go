@@ -0,0 +1,174 @@
+// ABOUTME: CLI command to regenerate embeddings for stored turns
+// ABOUTME: Batches and rate limits API calls, shows progress, and resumes after interruption
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/joho/godotenv"
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/llm"
+)
+
+var (
+	reembedModel     string
+	reembedBatchSize int
+	reembedRate      int
+	reembedDryRun    bool
+)
+
+// NewReembedCmd creates reembed command
+func NewReembedCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reembed",
+		Short: "Regenerate embeddings with the current embedding model",
+		Long: `Regenerate embeddings for stored turns.
+
+Every turn without embeddings from the target model is re-chunked and
+embedded again. Chunks are sent to the API in batches and requests are
+rate limited. Each turn is saved as soon as its batch completes, so an
+interrupted run (Ctrl-C, network failure) resumes where it stopped when
+you run the command again.
+
+Requires OPENAI_API_KEY (or a key saved with 'memory auth set openai').
+
+Examples:
+  memory reembed
+  memory reembed --model text-embedding-3-large
+  memory reembed --batch-size 50 --rate 30
+  memory reembed --dry-run`,
+		RunE: runReembed,
+	}
+
+	cmd.Flags().StringVar(&reembedModel, "model", string(llm.DefaultEmbeddingModel), "Embedding model to use (overrides embedding_model from config)")
+	cmd.Flags().IntVar(&reembedBatchSize, "batch-size", 100, "Maximum chunks per embedding request")
+	cmd.Flags().IntVar(&reembedRate, "rate", 60, "Maximum requests per minute (0 = unlimited)")
+	cmd.Flags().BoolVar(&reembedDryRun, "dry-run", false, "Show how many turns need re-embedding without calling the API")
+
+	return cmd
+}
+
+func runReembed(cmd *cobra.Command, args []string) error {
+	if err := validatePositiveInt(reembedBatchSize, "batch-size"); err != nil {
+		return err
+	}
+	if reembedRate < 0 {
+		return fmt.Errorf("rate must not be negative, got %d", reembedRate)
+	}
+
+	// Load .env for API keys
+	_ = godotenv.Load()
+
+	store, cfg, err := openStorageWithConfig()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if cfg.OpenAIKey == "" && !reembedDryRun {
+		return fmt.Errorf("OPENAI_API_KEY is required to generate embeddings (or run 'memory auth set openai')")
+	}
+
+	if !cmd.Flags().Changed("model") {
+		reembedModel = cfg.EmbeddingModel
+	}
+
+	if reembedDryRun {
+		pending, err := store.TurnsNeedingEmbedding(reembedModel)
+		if err != nil {
+			return fmt.Errorf("finding turns to re-embed: %w", err)
+		}
+		return printReembedResult(cmd, core.ReembedProgress{TotalTurns: len(pending)})
+	}
+
+	clientConfig := llm.ConfigFromSettings(cfg)
+	clientConfig.EmbeddingModel = openai.EmbeddingModel(reembedModel)
+	client, err := llm.NewOpenAIClientWithConfig(clientConfig)
+	if err != nil {
+		return fmt.Errorf("initializing OpenAI client: %w", err)
+	}
+	client.SetUsageRecorder(store)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	reembedder := core.NewReembedder(store, client, reembedBatchSize, reembedRate)
+
+	var onProgress func(core.ReembedProgress)
+	if !quiet && outputFormat != "json" {
+		errOut := cmd.ErrOrStderr()
+		onProgress = func(p core.ReembedProgress) {
+			renderProgressBar(errOut, p.DoneTurns, p.TotalTurns, "turns")
+		}
+	}
+
+	progress, err := reembedder.Run(ctx, onProgress)
+	if onProgress != nil && progress.TotalTurns > 0 {
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr())
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("interrupted after %d/%d turns; run again to resume", progress.DoneTurns, progress.TotalTurns)
+		}
+		return fmt.Errorf("re-embedding stopped after %d/%d turns (run again to resume): %w", progress.DoneTurns, progress.TotalTurns, err)
+	}
+
+	return printReembedResult(cmd, progress)
+}
+
+// printReembedResult reports the outcome of a reembed run
+func printReembedResult(cmd *cobra.Command, progress core.ReembedProgress) error {
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(map[string]interface{}{
+			"model":       reembedModel,
+			"dry_run":     reembedDryRun,
+			"total_turns": progress.TotalTurns,
+			"done_turns":  progress.DoneTurns,
+			"chunks":      progress.Chunks,
+			"requests":    progress.Requests,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	if quiet {
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	switch {
+	case progress.TotalTurns == 0:
+		_, _ = fmt.Fprintf(out, "All turns already embedded with %s\n", reembedModel)
+	case reembedDryRun:
+		_, _ = fmt.Fprintf(out, "%d turns need re-embedding with %s\n", progress.TotalTurns, reembedModel)
+	default:
+		_, _ = fmt.Fprintf(out, "✓ Re-embedded %d turns (%d chunks, %d requests) with %s\n",
+			progress.DoneTurns, progress.Chunks, progress.Requests, reembedModel)
+	}
+	return nil
+}
+
+// renderProgressBar redraws a single-line progress bar in place
+func renderProgressBar(w io.Writer, done, total int, unit string) {
+	const width = 30
+	filled := width
+	percent := 100
+	if total > 0 {
+		filled = done * width / total
+		percent = done * 100 / total
+	}
+	bar := strings.Repeat("#", filled) + strings.Repeat(".", width-filled)
+	_, _ = fmt.Fprintf(w, "\r[%s] %3d%% %d/%d %s", bar, percent, done, total, unit)
+}
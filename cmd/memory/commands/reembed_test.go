@@ -0,0 +1,80 @@
+// ABOUTME: Tests for reembed command
+// ABOUTME: Verifies command structure, flags, and progress bar rendering
+
+package commands
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewReembedCmd(t *testing.T) {
+	cmd := NewReembedCmd()
+
+	if cmd.Use != "reembed" {
+		t.Errorf("Use = %q, want %q", cmd.Use, "reembed")
+	}
+
+	if cmd.Short == "" {
+		t.Error("Short description should not be empty")
+	}
+
+	if cmd.RunE == nil {
+		t.Error("RunE should be set")
+	}
+}
+
+func TestReembedCmd_Flags(t *testing.T) {
+	cmd := NewReembedCmd()
+
+	tests := []struct {
+		name   string
+		defVal string
+	}{
+		{"model", "text-embedding-3-small"},
+		{"batch-size", "100"},
+		{"rate", "60"},
+		{"dry-run", "false"},
+	}
+
+	for _, tt := range tests {
+		flag := cmd.Flags().Lookup(tt.name)
+		if flag == nil {
+			t.Errorf("--%s flag not found", tt.name)
+			continue
+		}
+		if flag.DefValue != tt.defVal {
+			t.Errorf("--%s default = %q, want %q", tt.name, flag.DefValue, tt.defVal)
+		}
+	}
+}
+
+func TestReembedCmd_Examples(t *testing.T) {
+	cmd := NewReembedCmd()
+
+	for _, part := range []string{"memory reembed", "--model", "--dry-run"} {
+		if !findSubstring(cmd.Long, part) {
+			t.Errorf("Long description should contain %q", part)
+		}
+	}
+}
+
+func TestRenderProgressBar(t *testing.T) {
+	tests := []struct {
+		done, total int
+		want        string
+	}{
+		{0, 10, "\r[..............................]   0% 0/10 turns"},
+		{5, 10, "\r[###############...............]  50% 5/10 turns"},
+		{10, 10, "\r[##############################] 100% 10/10 turns"},
+		{0, 0, "\r[##############################] 100% 0/0 turns"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		renderProgressBar(&buf, tt.done, tt.total, "turns")
+		if buf.String() != tt.want {
+			t.Errorf("renderProgressBar(%d, %d) = %q, want %q", tt.done, tt.total, buf.String(), tt.want)
+		}
+	}
+}
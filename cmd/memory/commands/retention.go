@@ -0,0 +1,171 @@
+// ABOUTME: CLI command to set retention policies on topics and tags, overriding the global pruning policy
+// ABOUTME: With no arguments lists every policy; with only a block ID shows the policy in force for it
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// NewRetentionCmd creates the retention command
+func NewRetentionCmd() *cobra.Command {
+	var tag string
+
+	cmd := &cobra.Command{
+		Use:   "retention [<block_id>] [<policy>]",
+		Short: "Keep a topic forever or delete it after a while",
+		Long: `Set a retention policy on a topic (Bridge Block) or on every topic
+carrying a tag, overriding archive_retention and max_db_size eviction.
+
+A policy is one of:
+  keep      never prune the topic, whatever the size limit
+  30d, 2w   delete the topic 30 days (2 weeks) after its last update,
+            whatever its status, with or without max_db_size
+  36h       any Go duration works too
+  default   clear the policy, so the global policy applies again
+
+A topic's own policy wins over its tags'; among its tags, the longest
+policy wins. Pruning enforces them: MCP servers every 10 minutes, or
+memory prune now. Exports leave out topics whose retention ran out.
+
+With no arguments, lists every policy. With only a block ID, shows the
+policy in force for that topic.
+
+Examples:
+  memory retention block_20260115_143022_a1b2c3d4 keep
+  memory retention --tag scratch 30d
+  memory retention --tag scratch default
+  memory retention block_20260115_143022_a1b2c3d4
+  memory retention`,
+		Args: cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if tag != "" && len(args) != 1 {
+				return fmt.Errorf("--tag needs exactly one policy, e.g. memory retention --tag scratch 30d")
+			}
+
+			store, err := openStorage()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			out := cmd.OutOrStdout()
+			writeJSON := func(v any) error {
+				jsonData, err := json.MarshalIndent(v, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(out, "%s\n", jsonData)
+				return nil
+			}
+
+			switch {
+			case tag == "" && len(args) == 0:
+				policies, err := store.ListRetention()
+				if err != nil {
+					return fmt.Errorf("listing retention policies: %w", err)
+				}
+				if outputFormat == "json" {
+					return writeJSON(policies)
+				}
+				if len(policies.Blocks) == 0 && len(policies.Tags) == 0 {
+					if !quiet {
+						_, _ = fmt.Fprintf(out, "No retention policies are set; the global pruning policy applies to every topic\n")
+					}
+					return nil
+				}
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				_, _ = fmt.Fprintf(w, "KIND\tID\tTOPIC\tRETENTION\tEXPIRES\n")
+				_, _ = fmt.Fprintf(w, "----\t--\t-----\t---------\t-------\n")
+				for _, block := range policies.Blocks {
+					expires := "never"
+					if block.ExpiresAt != nil {
+						expires = formatTime(*block.ExpiresAt)
+					}
+					_, _ = fmt.Fprintf(w, "topic\t%s\t%s\t%s\t%s\n", block.BlockID, truncate(block.TopicLabel, 40), block.Retention, expires)
+				}
+				for _, t := range policies.Tags {
+					_, _ = fmt.Fprintf(w, "tag\t%s\t%d topics\t%s\t\n", t.Tag, t.Blocks, t.Retention)
+				}
+				return w.Flush()
+
+			case tag == "" && len(args) == 1:
+				block, err := store.GetBridgeBlock(args[0])
+				if err != nil {
+					return fmt.Errorf("getting topic: %w", err)
+				}
+				if block == nil {
+					return fmt.Errorf("block %s not found", args[0])
+				}
+				policy, err := store.EffectiveRetention(block.BlockID)
+				if err != nil {
+					return fmt.Errorf("getting retention policy: %w", err)
+				}
+				source := "tag"
+				switch {
+				case block.Retention != "":
+					source = "topic"
+				case policy.IsZero():
+					source = "global"
+				}
+				result := map[string]interface{}{"block_id": block.BlockID, "retention": policy.String(), "source": source}
+				expiresAt, expires := policy.ExpiresAt(block.UpdatedAt)
+				if expires {
+					result["expires_at"] = expiresAt
+				}
+				if outputFormat == "json" {
+					return writeJSON(result)
+				}
+				if source == "global" {
+					_, _ = fmt.Fprintf(out, "%s: no policy; the global pruning policy applies\n", block.BlockID)
+				} else {
+					_, _ = fmt.Fprintf(out, "%s: %s (set on the %s)\n", block.BlockID, policy, source)
+				}
+				if expires {
+					_, _ = fmt.Fprintf(out, "Expires %s unless updated first\n", formatTime(expiresAt))
+				}
+				return nil
+			}
+
+			policy, err := models.ParseRetention(args[len(args)-1])
+			if err != nil {
+				return err
+			}
+			result := map[string]interface{}{"retention": policy.String()}
+			target := "tag " + tag
+			if tag != "" {
+				if err := store.SetTagRetention(tag, policy); err != nil {
+					return fmt.Errorf("updating tag: %w", err)
+				}
+				result["tag"] = tag
+			} else {
+				if err := store.SetBlockRetention(args[0], policy); err != nil {
+					return fmt.Errorf("updating topic: %w", err)
+				}
+				result["block_id"] = args[0]
+				target = "topic " + args[0]
+			}
+
+			if outputFormat == "json" {
+				return writeJSON(result)
+			}
+			if !quiet {
+				if policy.IsZero() {
+					_, _ = fmt.Fprintf(out, "✓ Cleared the retention policy of %s\n", target)
+				} else {
+					_, _ = fmt.Fprintf(out, "✓ Set the retention policy of %s to %s\n", target, policy)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&tag, "tag", "", "Set the policy of every topic carrying this tag instead of one topic")
+
+	return cmd
+}
@@ -0,0 +1,97 @@
+// ABOUTME: Tests for the retention command
+// ABOUTME: Verifies setting, showing, listing, and clearing policies, and prune deleting expired topics
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestRetentionCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("MEMORY_MAX_DB_SIZE", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	if out, err := run("retention"); err != nil || !strings.Contains(out, "No retention policies are set") {
+		t.Fatalf("retention with no policies = %q, %v", out, err)
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_retention", Timestamp: time.Now(), UserMessage: "scratch notes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.TagBlock(blockID, []string{"scratch"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	if out, err := run("retention", blockID, "keep"); err != nil || !strings.Contains(out, "✓ Set the retention policy of topic "+blockID+" to keep") {
+		t.Errorf("retention keep = %q, %v", out, err)
+	}
+	if out, err := run("retention", "--tag", "scratch", "1ns"); err != nil || !strings.Contains(out, "tag scratch to 1ns") {
+		t.Errorf("retention --tag = %q, %v", out, err)
+	}
+	out, err := run("retention")
+	if err != nil {
+		t.Fatalf("retention: %v", err)
+	}
+	for _, want := range []string{blockID, "keep", "never", "scratch", "1 topics", "1ns"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("retention = %q, want it to contain %q", out, want)
+		}
+	}
+
+	// The topic's own policy wins over its tag's
+	if out, err := run("retention", blockID); err != nil || !strings.Contains(out, "keep (set on the topic)") {
+		t.Errorf("retention <block> = %q, %v; want the topic's policy", out, err)
+	}
+	if out, err := run("prune", "--dry-run"); err != nil || !strings.Contains(out, "Would delete 0 topics") {
+		t.Errorf("prune --dry-run of a kept topic = %q, %v", out, err)
+	}
+
+	if out, err := run("retention", blockID, "default"); err != nil || !strings.Contains(out, "✓ Cleared the retention policy") {
+		t.Errorf("retention default = %q, %v", out, err)
+	}
+	if out, err := run("retention", blockID); err != nil || !strings.Contains(out, "1ns (set on the tag)") || !strings.Contains(out, "Expires") {
+		t.Errorf("retention <block> = %q, %v; want the tag's policy", out, err)
+	}
+	if out, err := run("prune", "--dry-run"); err != nil || !strings.Contains(out, blockID) || !strings.Contains(out, "Would delete 1 topics") {
+		t.Errorf("prune --dry-run = %q, %v; want the expired topic listed", out, err)
+	}
+	if out, err := run("prune"); err != nil || !strings.Contains(out, "✓ Deleted 1 topics past their retention policy") {
+		t.Errorf("prune = %q, %v; want the expired topic deleted", out, err)
+	}
+	if _, err := run("retention", blockID); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("retention of a deleted topic = %v, want not found", err)
+	}
+
+	if _, err := run("retention", "--tag", "scratch", "soon"); err == nil || !strings.Contains(err.Error(), "invalid retention") {
+		t.Errorf("retention with a bad policy = %v, want an error", err)
+	}
+	if _, err := run("retention", "--tag", "scratch"); err == nil {
+		t.Error("retention --tag without a policy succeeded, want an error")
+	}
+}
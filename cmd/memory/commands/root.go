@@ -12,6 +12,9 @@ var (
 	verbose      bool
 	quiet        bool
 	outputFormat string
+	configPath   string
+	contextName  string
+	dataDir      string
 )
 
 // NewRootCmd creates the root command
@@ -47,22 +50,60 @@ HMLR Architecture Components:
 			}
 			return nil
 		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			recordCommandUse(cmd)
+		},
 	}
 
 	// Global flags
 	cmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
 	cmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Quiet output")
 	cmd.PersistentFlags().StringVar(&outputFormat, "format", "auto", "Output format (auto|json|table)")
+	cmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file (default ~/.config/memory/config.yaml)")
+	cmd.PersistentFlags().StringVar(&dataDir, "data-dir", "", "Data directory holding the databases (overrides data_dir)")
+	cmd.PersistentFlags().StringVar(&contextName, "context", "", "Memory context to use for this command (default: current context)")
 
 	// Add subcommands
 	cmd.AddCommand(NewMCPCmd())
+	cmd.AddCommand(NewServeCmd())
 	cmd.AddCommand(NewAddCmd())
+	cmd.AddCommand(NewIngestCmd())
 	cmd.AddCommand(NewSearchCmd())
 	cmd.AddCommand(NewListCmd())
 	cmd.AddCommand(NewVersionCmd())
 	cmd.AddCommand(NewSyncCmd())
 	cmd.AddCommand(NewProfileCmd())
 	cmd.AddCommand(NewExportCmd())
+	cmd.AddCommand(NewImportCmd())
+	cmd.AddCommand(NewTakeoutCmd())
+	cmd.AddCommand(NewVaultCmd())
+	cmd.AddCommand(NewWipeCmd())
+	cmd.AddCommand(NewScanPIICmd())
+	cmd.AddCommand(NewStatsCmd())
+	cmd.AddCommand(NewActivityCmd())
+	cmd.AddCommand(NewPruneCmd())
+	cmd.AddCommand(NewCompactCmd())
+	cmd.AddCommand(NewUndoCmd())
+	cmd.AddCommand(NewReembedCmd())
+	cmd.AddCommand(NewBackfillEmbeddingsCmd())
+	cmd.AddCommand(NewQuantizeCmd())
+	cmd.AddCommand(NewTopicsCmd())
+	cmd.AddCommand(NewTurnsCmd())
+	cmd.AddCommand(NewFactsCmd())
+	cmd.AddCommand(NewTagCmd())
+	cmd.AddCommand(NewExcludeCmd())
+	cmd.AddCommand(NewRetentionCmd())
+	cmd.AddCommand(NewRoutingCmd())
+	cmd.AddCommand(NewDiffCmd())
+	cmd.AddCommand(NewLogCmd())
+	cmd.AddCommand(NewWatchCmd())
+	cmd.AddCommand(NewConfigCmd())
+	cmd.AddCommand(NewContextCmd())
+	cmd.AddCommand(NewAuthCmd())
+	cmd.AddCommand(NewDoctorCmd())
+	cmd.AddCommand(NewStressCmd())
+	cmd.AddCommand(NewTelemetryCmd())
+	cmd.AddCommand(NewJobsCmd())
 	cmd.AddCommand(NewInstallSkillCmd())
 
 	return cmd
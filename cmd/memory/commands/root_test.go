@@ -109,14 +109,46 @@ func TestRootCmd_Subcommands(t *testing.T) {
 
 	expectedSubcommands := []string{
 		"mcp",
+		"serve",
 		"add",
+		"ingest",
 		"search",
 		"list",
 		"version",
 		"sync",
 		"profile",
 		"export",
+		"import",
+		"takeout",
+		"vault",
+		"wipe",
+		"scan-pii",
 		"install-skill",
+		"stats",
+		"activity",
+		"prune",
+		"compact",
+		"undo",
+		"reembed",
+		"backfill-embeddings",
+		"quantize",
+		"topics",
+		"turns",
+		"facts",
+		"tag",
+		"exclude",
+		"retention",
+		"routing",
+		"diff",
+		"log",
+		"watch",
+		"config",
+		"context",
+		"auth",
+		"doctor",
+		"stress",
+		"telemetry",
+		"jobs",
 	}
 
 	for _, subCmdName := range expectedSubcommands {
@@ -0,0 +1,106 @@
+// ABOUTME: CLI commands for the log of topic routing decisions
+// ABOUTME: Summarizes continuation, shift, and resumption rates and split corrections for tuning the threshold
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// NewRoutingCmd creates the routing command group
+func NewRoutingCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "routing",
+		Short: "Inspect how turns are routed to topics",
+		Long: `Inspect the Governor's routing decisions.
+
+Every stored turn is routed to a topic: continuing the active one, resuming
+a paused one, or starting a new one. Each decision is logged with the keyword
+scores behind it, and splitting a topic marks the decision for the turn it
+was split at as corrected.`,
+	}
+
+	cmd.AddCommand(newRoutingStatsCmd())
+
+	return cmd
+}
+
+func newRoutingStatsCmd() *cobra.Command {
+	var days int
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Summarize routing decisions and corrections",
+		Long: `Summarize the routing decisions of the last --days days: how often each
+scenario fired, its average match score, and how many decisions a split later
+corrected.
+
+Near misses are topic shifts where some topic shared keywords with the turn
+but scored under topic_match_threshold. Many corrected continuations suggest
+raising the threshold; many near misses that should have continued suggest
+lowering it.
+
+Examples:
+  memory routing stats
+  memory routing stats --days 7
+  memory routing stats --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validatePositiveInt(days, "days"); err != nil {
+				return err
+			}
+
+			store, cfg, err := openStorageWithConfig()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			stats, err := store.RoutingStats(time.Now().AddDate(0, 0, -days))
+			if err != nil {
+				return fmt.Errorf("computing routing stats: %w", err)
+			}
+
+			if outputFormat == "json" {
+				jsonData, err := json.MarshalIndent(stats, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+				return nil
+			}
+
+			out := cmd.OutOrStdout()
+			if stats.Total == 0 {
+				if !quiet {
+					_, _ = fmt.Fprintf(out, "No routing decisions in the last %d days\n", days)
+				}
+				return nil
+			}
+
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintf(w, "SCENARIO\tCOUNT\tRATE\tAVG SCORE\tCORRECTED\n")
+			_, _ = fmt.Fprintf(w, "--------\t-----\t----\t---------\t---------\n")
+			for _, s := range stats.Scenarios {
+				_, _ = fmt.Fprintf(w, "%s\t%d\t%.1f%%\t%.2f\t%d\n", s.Scenario, s.Count, s.Rate*100, s.AvgScore, s.Corrected)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(out, "\nDecisions:   %d in the last %d days\n", stats.Total, days)
+			_, _ = fmt.Fprintf(out, "Corrected:   %d (%.1f%%)\n", stats.Corrected, stats.CorrectionRate*100)
+			_, _ = fmt.Fprintf(out, "Near misses: %d\n", stats.NearMisses)
+			_, _ = fmt.Fprintf(out, "Threshold:   %.2f (configured %.2f)\n", stats.Threshold, cfg.TopicMatchThreshold)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&days, "days", 30, "Summarize decisions from this many days back")
+
+	return cmd
+}
@@ -0,0 +1,75 @@
+// ABOUTME: Tests for the routing command
+// ABOUTME: Verifies routing stats reports scenario rates and corrections from the log
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+func TestRoutingStatsCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	if out, err := run("routing", "stats"); err != nil || !strings.Contains(out, "No routing decisions") {
+		t.Fatalf("routing stats with an empty log = %q, %v", out, err)
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, scenario := range []models.RoutingScenario{models.NewTopicFirst, models.TopicContinuation, models.TopicContinuation, models.TopicShift} {
+		decision := models.RoutingDecision{Scenario: scenario, Score: 0.5}
+		if err := store.LogRoutingDecision("turn_"+string(rune('a'+i)), decision, 0.3); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = store.Close()
+
+	out, err := run("routing", "stats", "--days", "7")
+	if err != nil {
+		t.Fatalf("routing stats error = %v", err)
+	}
+	for _, want := range []string{"topic_continuation", "50.0%", "Decisions:   4", "Near misses: 1", "Threshold:   0.30"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("routing stats output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, err = run("routing", "stats", "--format", "json")
+	if err != nil {
+		t.Fatalf("routing stats --format json error = %v", err)
+	}
+	var stats storage.RoutingStats
+	if err := json.Unmarshal([]byte(out), &stats); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if stats.Total != 4 || len(stats.Scenarios) != 3 || stats.Scenarios[0].Scenario != models.TopicContinuation {
+		t.Errorf("routing stats JSON = %+v, want 4 decisions led by continuations", stats)
+	}
+
+	if _, err := run("routing", "stats", "--days", "0"); err == nil {
+		t.Error("routing stats --days 0 succeeded, want an error")
+	}
+}
@@ -0,0 +1,134 @@
+// ABOUTME: CLI command to scan stored turns and facts for personal data
+// ABOUTME: Reports findings by category with block, turn, and fact references and byte offsets
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/llm"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// NewScanPIICmd creates the scan-pii command
+func NewScanPIICmd() *cobra.Command {
+	var (
+		useLLM     bool
+		tags       []string
+		reportPath string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "scan-pii",
+		Short: "Scan stored turns and facts for personal data",
+		Long: `Scan every turn and fact for personal data and report what was found,
+by category, with the block, turn, or fact and the field it is in.
+
+Pattern detectors find emails, phone numbers, US Social Security numbers,
+card numbers (Luhn-checked), IP addresses, and credentials. With --llm, the
+chat model also looks for names, addresses, dates of birth, ID numbers, and
+health details; this sends the text of every turn and fact to OpenAI.
+
+Found values are shown masked. --report writes the findings as JSON, with
+the byte offsets of each value in its field, for review or scripted
+redaction. Secret facts are skipped: their values are already encrypted.
+
+Examples:
+  memory scan-pii
+  memory scan-pii --llm --report pii-report.json
+  memory scan-pii --tag project-x --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, cfg, err := openStorageWithConfig()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			var detector core.PIIDetector
+			if useLLM {
+				if cfg.OpenAIKey == "" {
+					return fmt.Errorf("OPENAI_API_KEY is required for --llm (or run 'memory auth set openai')")
+				}
+				client, err := llm.NewOpenAIClientWithConfig(llm.ConfigFromSettings(cfg))
+				if err != nil {
+					return fmt.Errorf("initializing OpenAI client: %w", err)
+				}
+				client.SetUsageRecorder(store)
+				detector = client
+			}
+
+			data, err := store.ExportWithOptions(storage.ExportOptions{Tags: tags})
+			if err != nil {
+				return fmt.Errorf("reading memory: %w", err)
+			}
+			report := core.NewPIIScanner(detector).Scan(data)
+			if report.LLMError != "" {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: LLM detection stopped, pattern results only from then on: %s\n", report.LLMError)
+			}
+
+			if reportPath != "" {
+				jsonData, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling report: %w", err)
+				}
+				if err := os.WriteFile(reportPath, append(jsonData, '\n'), 0600); err != nil {
+					return fmt.Errorf("writing report: %w", err)
+				}
+			}
+
+			if outputFormat == "json" {
+				jsonData, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+				return nil
+			}
+			if !quiet {
+				printPIIReport(cmd, report)
+				if reportPath != "" {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Report: %s\n", reportPath)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&useLLM, "llm", false, "Also ask the chat model for names, addresses, and other personal data")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "Only scan blocks and facts with this tag (repeatable; all must match)")
+	cmd.Flags().StringVar(&reportPath, "report", "", "Write the findings as JSON to this file")
+
+	return cmd
+}
+
+// printPIIReport shows the count per category, then each finding
+func printPIIReport(cmd *cobra.Command, report *core.PIIReport) {
+	out := cmd.OutOrStdout()
+	if len(report.Findings) > 0 {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "CATEGORY\tFINDINGS\n")
+		for _, name := range report.CategoryNames() {
+			_, _ = fmt.Fprintf(w, "%s\t%d\n", name, report.Categories[name])
+		}
+		_ = w.Flush()
+
+		_, _ = fmt.Fprintln(out)
+		w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "CATEGORY\tWHERE\tFIELD\tVALUE\n")
+		for _, f := range report.Findings {
+			where := "fact " + f.FactID
+			if f.TurnID != "" {
+				where = "turn " + f.TurnID + " (" + f.BlockID + ")"
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.Category, where, f.Field, f.Preview)
+		}
+		_ = w.Flush()
+	}
+	_, _ = fmt.Fprintf(out, "✓ Scanned %d turns and %d facts: %d findings\n", report.Turns, report.Facts, len(report.Findings))
+}
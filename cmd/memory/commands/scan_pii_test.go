@@ -0,0 +1,78 @@
+// ABOUTME: Tests for the scan-pii command
+// ABOUTME: Verifies the category summary, masked values, the JSON report, and that --llm needs a key
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestScanPIICmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_pii", Timestamp: time.Now(), UserMessage: "email me at jane@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_ssn", Key: "ssn", Value: "123-45-6789", Confidence: 1, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	reportPath := filepath.Join(dir, "pii.json")
+	out, err := run("scan-pii", "--report", reportPath)
+	if err != nil {
+		t.Fatalf("scan-pii error = %v", err)
+	}
+	for _, want := range []string{"email", "ssn", "turn turn_pii", "fact fact_ssn", "ja********om", "✓ Scanned 1 turns and 1 facts: 2 findings"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("scan-pii output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "jane@example.com") || strings.Contains(out, "123-45-6789") {
+		t.Errorf("scan-pii printed an unmasked value:\n%s", out)
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report core.PIIReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("report is not JSON: %v", err)
+	}
+	if len(report.Findings) != 2 || report.Categories["email"] != 1 {
+		t.Errorf("report = %+v, want the email and the SSN", report)
+	}
+
+	if _, err := run("scan-pii", "--llm"); err == nil || !strings.Contains(err.Error(), "OPENAI_API_KEY") {
+		t.Errorf("scan-pii --llm without a key error = %v, want the key required", err)
+	}
+}
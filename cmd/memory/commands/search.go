@@ -7,14 +7,18 @@ import (
 	"fmt"
 	"text/tabwriter"
 
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
 	"github.com/spf13/cobra"
 
-	"github.com/harper/remember-standalone/internal/storage"
 	"github.com/joho/godotenv"
 )
 
 var (
-	searchLimit int
+	searchLimit  int
+	searchTags   []string
+	searchAffect string
+	searchOrigin models.Origin
 )
 
 // NewSearchCmd creates search command
@@ -30,12 +34,24 @@ with vector similarity when OpenAI is configured.
 Examples:
   memory search "python programming"
   memory search --limit 10 "machine learning"
-  memory search --format json "API keys"`,
+  memory search --format json "API keys"
+  memory search --tag project-x "deadline"
+  memory search --affect frustrated "deploys"
+  memory search --repo . "flaky tests"
+  memory search --device work-laptop "standup"
+  memory search --agent claude-code "deploy steps"`,
 		Args: cobra.ExactArgs(1),
 		RunE: runSearch,
 	}
 
-	cmd.Flags().IntVar(&searchLimit, "limit", 5, "Maximum results to return")
+	cmd.Flags().IntVar(&searchLimit, "limit", 5, "Maximum results to return (overrides retrieval_k from config)")
+	cmd.Flags().StringSliceVar(&searchTags, "tag", nil, "Only return topics with this tag (repeatable; all must match)")
+	cmd.Flags().StringVar(&searchAffect, "affect", "", "Only return topics with a turn of this tone (e.g. frustrated, or a valence like negative)")
+	cmd.Flags().StringVar(&searchOrigin.GitRepo, "repo", "", "Only return topics with a turn added in this git repo (remote URL or root path; \".\" for the current repo)")
+	cmd.Flags().StringVar(&searchOrigin.Device, "device", "", "Only return topics with a turn added from this device")
+	cmd.Flags().StringVar(&searchOrigin.Hostname, "host", "", "Only return topics with a turn added on this hostname")
+	cmd.Flags().StringVar(&searchOrigin.WorkingDir, "dir", "", "Only return topics with a turn added in this directory or below it")
+	cmd.Flags().StringVar(&searchOrigin.Agent, "agent", "", "Only return topics with a turn written by this agent (MCP client name or agent_id)")
 
 	return cmd
 }
@@ -51,15 +67,30 @@ func runSearch(cmd *cobra.Command, args []string) error {
 
 	query := args[0]
 
+	affect, err := models.ParseAffect(searchAffect)
+	if err != nil {
+		return err
+	}
+
+	origin, err := resolveSearchOrigin(searchOrigin)
+	if err != nil {
+		return err
+	}
+
 	// Initialize storage
-	store, err := storage.NewStorage()
+	store, cfg, err := openStorageWithConfig()
 	if err != nil {
 		return fmt.Errorf("initializing storage: %w", err)
 	}
 	defer func() { _ = store.Close() }()
 
+	limit := searchLimit
+	if !cmd.Flags().Changed("limit") {
+		limit = cfg.RetrievalK
+	}
+
 	// Search memories
-	results, err := store.SearchMemory(query, searchLimit)
+	results, err := store.SearchMemoryWithOptions(query, limit, storage.SearchOptions{Tags: searchTags, Affect: affect, Origin: origin})
 	if err != nil {
 		return fmt.Errorf("searching memories: %w", err)
 	}
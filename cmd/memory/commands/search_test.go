@@ -36,6 +36,17 @@ func TestSearchCmd_LimitFlag(t *testing.T) {
 	}
 }
 
+func TestSearchCmd_AffectFlag(t *testing.T) {
+	cmd := NewSearchCmd()
+
+	if cmd.Flags().Lookup("affect") == nil {
+		t.Fatal("--affect flag not found")
+	}
+	if !findSubstring(cmd.Long, "--affect") {
+		t.Error("Long description should mention --affect flag")
+	}
+}
+
 func TestSearchCmd_ArgsValidation(t *testing.T) {
 	cmd := NewSearchCmd()
 
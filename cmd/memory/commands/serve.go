@@ -0,0 +1,71 @@
+// ABOUTME: Serve command exposes memory as a JSON REST API over HTTP
+// ABOUTME: For scripts, web UIs, and shortcuts that do not speak MCP; routes call the MCP tools
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var serveHTTPAddr string
+
+// NewServeCmd creates the serve command
+func NewServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve memory as a REST API over HTTP",
+		Long: `Serve memory as a JSON REST API for clients that do not speak MCP, such as
+scripts, web UIs, and mobile shortcuts.
+
+Every route calls the MCP tool of the same purpose, so results match what
+agents see, --read-only works the same way (write routes answer 403), and
+credentials in results are masked. GET /openapi.json describes the routes:
+
+  GET    /v1/memories?query=...         search (retrieve_memory)
+  POST   /v1/memories                   store a turn (store_conversation)
+  GET    /v1/topics                     active topics
+  GET    /v1/topics/{block_id}          topic history
+  POST   /v1/topics/{block_id}/context  prompt with history, memories, and facts
+  POST   /v1/facts                      add a fact
+  GET    /v1/facts/{key}                look up a fact
+  GET    /v1/profile                    the user profile
+  GET    /v1/health                     health report
+  GET    /v1/events                     stream of changes ('memory watch')
+
+The API listens on http_addr (default 127.0.0.1:8787). When http_token (or
+MEMORY_HTTP_TOKEN) is set, requests must send "Authorization: Bearer <token>";
+without a token, only loopback addresses are allowed. Requests may instead send
+http_read_token (or MEMORY_HTTP_READ_TOKEN), which only reads: routes that
+store, change, or delete anything answer it with 403.
+
+With an OpenAI API key, the server is also an OpenAI-compatible chat endpoint:
+point any OpenAI client at http://<http_addr>/v1 and POST /v1/chat/completions
+adds related memories, facts, and the profile to each request, forwards it to
+OpenAI with your key, relays the answer (streamed or not), and stores the
+exchange. Clients send the http_token as their API key; X-Memory-Persona picks
+the profile persona. Read-only servers add memory but store nothing.
+
+--health-addr and --debug-addr work as for 'memory mcp'.`,
+		Example: `  # Serve on the default loopback address
+  memory serve
+
+  # Search from a script
+  curl -s 'http://127.0.0.1:8787/v1/memories?query=birthday'
+
+  # Chat with memory from any OpenAI client
+  OPENAI_BASE_URL=http://127.0.0.1:8787/v1 my-openai-app
+
+  # Serve on the network with a token
+  MEMORY_HTTP_TOKEN=$(openssl rand -hex 32) memory serve --http 0.0.0.0:8787`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServer(cmd, true)
+		},
+	}
+
+	cmd.Flags().StringVar(&serveHTTPAddr, "http", "", "Serve the REST API on this host:port (overrides http_addr in config)")
+	cmd.Flags().BoolVar(&mcpReadOnly, "read-only", false, "Serve only read routes and reject writes (overrides read_only in config)")
+	cmd.Flags().StringVar(&mcpHealthAddr, "health-addr", "", "Serve /healthz on this host:port (overrides health_addr in config)")
+	cmd.Flags().StringVar(&mcpDebugAddr, "debug-addr", "", "Serve pprof and expvar on this host:port (overrides debug_addr in config)")
+
+	return cmd
+}
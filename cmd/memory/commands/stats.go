@@ -0,0 +1,199 @@
+// ABOUTME: CLI command to show memory statistics
+// ABOUTME: Reports entity counts, DB size, active and hot topics, fact categories, LLM usage, and cache hits
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+var (
+	statsTopN int
+)
+
+// NewStatsCmd creates stats command
+func NewStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show memory statistics",
+		Long: `Show statistics about stored memory.
+
+Reports Bridge Block, turn, fact, and embedding counts, database
+file size and how much of max_db_size it uses, archived blocks
+evicted to stay under it, the most active topics, hot topics (the
+ones retrieved or hydrated most often), facts grouped by category, LLM token usage, block and profile cache hit rates,
+and the time of the last write. Cache hits accumulate across runs.
+
+Examples:
+  memory stats
+  memory stats --top 10
+  memory stats --format json`,
+		RunE: runStats,
+	}
+
+	cmd.Flags().IntVar(&statsTopN, "top", 5, "Number of most active and hot topics to show")
+
+	return cmd
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	if err := validatePositiveInt(statsTopN, "top"); err != nil {
+		return err
+	}
+
+	store, cfg, err := openStorageWithConfig()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	stats, err := store.Stats(statsTopN)
+	if err != nil {
+		return fmt.Errorf("computing stats: %w", err)
+	}
+	usage, err := core.NewPruner(store, cfg.MaxDBSize, cfg.ArchiveRetention).Usage()
+	if err != nil {
+		return fmt.Errorf("computing stats: %w", err)
+	}
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(statsOutput{
+			StorageStats:   stats,
+			MaxDBSizeBytes: cfg.MaxDBSize,
+			SizeWarning:    usage.Warning,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "METRIC\tVALUE\n")
+	_, _ = fmt.Fprintf(w, "------\t-----\n")
+	_, _ = fmt.Fprintf(w, "Database\t%s\n", stats.DBPath)
+	_, _ = fmt.Fprintf(w, "Size\t%s\n", formatBytes(stats.DBSizeBytes))
+	if cfg.MaxDBSize > 0 {
+		_, _ = fmt.Fprintf(w, "Used\t%s of %s limit (%.0f%%)\n", formatBytes(stats.DBUsedBytes), formatBytes(cfg.MaxDBSize),
+			float64(stats.DBUsedBytes)/float64(cfg.MaxDBSize)*100)
+	} else {
+		_, _ = fmt.Fprintf(w, "Used\t%s (no limit)\n", formatBytes(stats.DBUsedBytes))
+	}
+	if ev := stats.Evictions; ev.Blocks > 0 {
+		_, _ = fmt.Fprintf(w, "Evicted\t%d archived blocks (%s), last %s\n", ev.Blocks, formatBytes(ev.Bytes), formatTime(*ev.LastEvictedAt))
+	}
+	if c := stats.Compactions; c.Blocks > 0 {
+		_, _ = fmt.Fprintf(w, "Compacted\t%d archived blocks (%s, %d restorable), last %s\n", c.Blocks, formatBytes(c.Bytes), c.Restorable, formatTime(*c.LastCompactedAt))
+	}
+	_, _ = fmt.Fprintf(w, "Blocks\t%d\n", stats.BlockCount)
+	for _, status := range []string{"ACTIVE", "PAUSED", "CLOSED", "ARCHIVED"} {
+		if n := stats.BlocksByStatus[status]; n > 0 {
+			_, _ = fmt.Fprintf(w, "  %s\t%d\n", status, n)
+		}
+	}
+	_, _ = fmt.Fprintf(w, "Turns\t%d\n", stats.TurnCount)
+	_, _ = fmt.Fprintf(w, "Facts\t%d\n", stats.FactCount)
+	if stats.PendingFacts > 0 {
+		_, _ = fmt.Fprintf(w, "  awaiting review\t%d\n", stats.PendingFacts)
+	}
+	_, _ = fmt.Fprintf(w, "Embeddings\t%d\n", stats.EmbeddingCount)
+	lastActivity := "(never)"
+	if stats.LastActivity != nil {
+		lastActivity = formatTime(*stats.LastActivity)
+	}
+	_, _ = fmt.Fprintf(w, "Last Activity\t%s\n", lastActivity)
+	_ = w.Flush()
+
+	if usage.Warning != "" {
+		_, _ = fmt.Fprintf(out, "\nWarning: %s\n", usage.Warning)
+	}
+
+	if len(stats.TopTopics) > 0 {
+		_, _ = fmt.Fprintf(out, "\nMost Active Topics:\n")
+		w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "TOPIC\tBLOCKS\tTURNS\n")
+		for _, topic := range stats.TopTopics {
+			label := topic.Topic
+			if label == "" {
+				label = "(no topic)"
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%d\t%d\n", truncate(label, 30), topic.Blocks, topic.TurnCount)
+		}
+		_ = w.Flush()
+	}
+
+	if len(stats.HotTopics) > 0 {
+		_, _ = fmt.Fprintf(out, "\nHot Topics:\n")
+		w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "TOPIC\tBLOCK\tSTATUS\tREADS\tLAST READ\n")
+		for _, topic := range stats.HotTopics {
+			label := topic.TopicLabel
+			if label == "" {
+				label = "(no topic)"
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", truncate(label, 30), topic.BlockID, topic.Status, topic.AccessCount, formatTime(topic.LastAccessedAt))
+		}
+		_ = w.Flush()
+	}
+
+	if len(stats.FactsByCategory) > 0 {
+		_, _ = fmt.Fprintf(out, "\nFacts by Category:\n")
+		w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "CATEGORY\tFACTS\n")
+		for _, category := range storage.SortedCategories(stats.FactsByCategory) {
+			_, _ = fmt.Fprintf(w, "%s\t%d\n", category, stats.FactsByCategory[category])
+		}
+		_ = w.Flush()
+	}
+
+	if len(stats.LLMUsage) > 0 {
+		_, _ = fmt.Fprintf(out, "\nLLM Usage:\n")
+		w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "OPERATION\tMODEL\tCALLS\tPROMPT TOKENS\tCOMPLETION TOKENS\n")
+		for _, u := range stats.LLMUsage {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\n", u.Operation, u.Model, u.Calls, u.PromptTokens, u.CompletionTokens)
+		}
+		_ = w.Flush()
+	}
+
+	if len(stats.Caches) > 0 {
+		_, _ = fmt.Fprintf(out, "\nCache:\n")
+		w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "CACHE\tHITS\tMISSES\tHIT RATE\n")
+		for _, c := range stats.Caches {
+			_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%.1f%%\n", c.Name, c.Hits, c.Misses, c.HitRate()*100)
+		}
+		_ = w.Flush()
+	}
+
+	return nil
+}
+
+// statsOutput is the JSON form of memory stats: the storage stats with the size limit
+type statsOutput struct {
+	*storage.StorageStats
+	MaxDBSizeBytes int64  `json:"max_db_size_bytes"` // 0 means no limit
+	SizeWarning    string `json:"size_warning,omitempty"`
+}
+
+// formatBytes renders a byte count with a binary unit suffix
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
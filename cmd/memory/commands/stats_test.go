@@ -0,0 +1,66 @@
+// ABOUTME: Tests for stats command
+// ABOUTME: Verifies command structure, flags, and byte formatting
+
+package commands
+
+import (
+	"testing"
+)
+
+func TestNewStatsCmd(t *testing.T) {
+	cmd := NewStatsCmd()
+
+	if cmd.Use != "stats" {
+		t.Errorf("Use = %q, want %q", cmd.Use, "stats")
+	}
+
+	if cmd.Short == "" {
+		t.Error("Short description should not be empty")
+	}
+
+	if cmd.RunE == nil {
+		t.Error("RunE should be set")
+	}
+}
+
+func TestStatsCmd_TopFlag(t *testing.T) {
+	cmd := NewStatsCmd()
+
+	topFlag := cmd.Flags().Lookup("top")
+	if topFlag == nil {
+		t.Fatal("--top flag not found")
+	}
+
+	if topFlag.DefValue != "5" {
+		t.Errorf("--top default = %q, want %q", topFlag.DefValue, "5")
+	}
+}
+
+func TestStatsCmd_Examples(t *testing.T) {
+	cmd := NewStatsCmd()
+
+	for _, part := range []string{"memory stats", "--format json"} {
+		if !findSubstring(cmd.Long, part) {
+			t.Errorf("Long description should contain %q", part)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
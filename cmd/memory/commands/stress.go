@@ -0,0 +1,152 @@
+// ABOUTME: CLI command to store and search turns concurrently against a temporary database
+// ABOUTME: Reports throughput and latency percentiles, and fails if the database was left inconsistent
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// NewStressCmd creates the stress command
+func NewStressCmd() *cobra.Command {
+	var (
+		writers int
+		readers int
+		turns   int
+		stores  int
+		keep    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "stress",
+		Short: "Store and search turns concurrently to check locking holds up",
+		Long: `Store turns from many goroutines while others search, all against a fresh
+temporary database, then check the database is consistent: every stored turn
+is present in the block it was stored in, every block's turn count matches its
+turns, and at most one block is active.
+
+The writers are spread over several storage handles on the same database, each
+standing in for a separate process such as the CLI and an MCP server. They start
+topics, append to them, and append to one topic they all share, so writes
+contend both within a handle and across handles.
+
+Your own memory is never touched. The command exits non-zero if any call failed
+or any check did not hold.
+
+Examples:
+  memory stress
+  memory stress --writers 16 --turns 5000
+  memory stress --stores 4 --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validatePositiveInt(writers, "writers"); err != nil {
+				return err
+			}
+			if err := validatePositiveInt(turns, "turns"); err != nil {
+				return err
+			}
+			if err := validatePositiveInt(stores, "stores"); err != nil {
+				return err
+			}
+			if readers < 0 {
+				return fmt.Errorf("readers must not be negative, got %d", readers)
+			}
+
+			dir, err := os.MkdirTemp("", "memory-stress-")
+			if err != nil {
+				return fmt.Errorf("creating temporary database: %w", err)
+			}
+			if !keep {
+				defer func() { _ = os.RemoveAll(dir) }()
+			}
+			path := filepath.Join(dir, "memory.db")
+			open := func() (*storage.Storage, error) { return storage.NewStorageWithPath(path) }
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			report, err := core.RunStress(ctx, open, core.StressOptions{
+				Writers: writers,
+				Readers: readers,
+				Turns:   turns,
+				Stores:  stores,
+			})
+			if err != nil {
+				return err
+			}
+
+			if err := printStressReport(cmd, report); err != nil {
+				return err
+			}
+			if keep && !quiet && outputFormat != "json" {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Database kept at %s\n", path)
+			}
+			if !report.OK() {
+				return fmt.Errorf("stress run failed: %d store errors, %d search errors, %d broken invariants",
+					report.Store.Errors, report.Search.Errors, len(report.Violations))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&writers, "writers", 8, "Goroutines storing turns")
+	cmd.Flags().IntVar(&readers, "readers", 0, "Goroutines searching while the writers run (0 = half the writers)")
+	cmd.Flags().IntVar(&turns, "turns", 1000, "Turns to store across all writers")
+	cmd.Flags().IntVar(&stores, "stores", 2, "Storage handles on the database, each standing in for a process")
+	cmd.Flags().BoolVar(&keep, "keep", false, "Keep the temporary database afterwards")
+
+	return cmd
+}
+
+// printStressReport writes the outcome of a stress run
+func printStressReport(cmd *cobra.Command, report *core.StressReport) error {
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	if quiet {
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "%d turns from %d writers, with %d readers, over %d handles in %.2fs\n\n",
+		report.Store.Count, report.Writers, report.Readers, report.Stores, report.ElapsedMs/1000)
+	_, _ = fmt.Fprintf(out, "%-8s %8s %7s %9s %9s %9s %9s %7s\n", "", "calls", "per sec", "p50 ms", "p95 ms", "p99 ms", "max ms", "errors")
+	for _, row := range []struct {
+		name  string
+		stats core.LatencyStats
+	}{{"store", report.Store}, {"search", report.Search}} {
+		_, _ = fmt.Fprintf(out, "%-8s %8d %7.0f %9.2f %9.2f %9.2f %9.2f %7d\n",
+			row.name, row.stats.Count, row.stats.PerSec, row.stats.P50Ms, row.stats.P95Ms, row.stats.P99Ms, row.stats.MaxMs, row.stats.Errors)
+	}
+
+	if len(report.Errors) > 0 {
+		_, _ = fmt.Fprintln(out, "\nErrors:")
+		for _, e := range report.Errors {
+			_, _ = fmt.Fprintf(out, "  %s\n", e)
+		}
+	}
+	if len(report.Violations) > 0 {
+		_, _ = fmt.Fprintln(out, "\nBroken invariants:")
+		for _, v := range report.Violations {
+			_, _ = fmt.Fprintf(out, "  ✗ %s\n", v)
+		}
+		return nil
+	}
+	_, _ = fmt.Fprintln(out, "\n✓ Every invariant held")
+	return nil
+}
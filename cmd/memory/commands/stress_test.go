@@ -0,0 +1,71 @@
+// ABOUTME: Tests for the stress command
+// ABOUTME: Verifies a small run reports its latencies, leaves the user's memory alone, and rejects bad flags
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/core"
+)
+
+func TestStressCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		out := &bytes.Buffer{}
+		root := NewRootCmd()
+		root.SetOut(out)
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	// Enough writers over enough handles that two of them starting topics at once
+	// would leave two blocks active
+	out, err := run("stress", "--writers", "8", "--turns", "80", "--stores", "4")
+	if err != nil {
+		t.Fatalf("stress error = %v\n%s", err, out)
+	}
+	for _, want := range []string{"80 turns from 8 writers, with 4 readers, over 4 handles", "store ", "search ", "p95 ms", "✓ Every invariant held"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("stress output missing %q:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "data")); !os.IsNotExist(err) {
+		t.Errorf("stress touched the data directory: %v", err)
+	}
+
+	out, err = run("stress", "--writers", "2", "--turns", "10", "--stores", "1", "--format", "json")
+	if err != nil {
+		t.Fatalf("stress --format json error = %v", err)
+	}
+	var report core.StressReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("stress JSON did not parse: %v\n%s", err, out)
+	}
+	if report.Store.Count != 10 || report.Stores != 1 || len(report.Violations) != 0 {
+		t.Errorf("report = %+v, want 10 stores on 1 handle and no violations", report)
+	}
+
+	for _, args := range [][]string{
+		{"stress", "--writers", "0"},
+		{"stress", "--turns", "-1"},
+		{"stress", "--readers", "-2"},
+	} {
+		if _, err := run(args...); err == nil {
+			t.Errorf("%v succeeded, want an error", args)
+		}
+	}
+}
@@ -3,10 +3,16 @@
 package commands
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/harper/remember-standalone/internal/signing"
 	"github.com/harper/remember-standalone/internal/storage"
 	"github.com/spf13/cobra"
 )
@@ -27,6 +33,7 @@ Use 'memory export' for data backup and migration.`,
 
 	cmd.AddCommand(newSyncStatusCmd())
 	cmd.AddCommand(newSyncRepairBlocksCmd())
+	cmd.AddCommand(newSyncImportEmbeddingsCmd())
 
 	return cmd
 }
@@ -36,14 +43,14 @@ func newSyncStatusCmd() *cobra.Command {
 		Use:   "status",
 		Short: "Show storage status",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			store, err := storage.NewStorage()
+			store, err := openStorage()
 			if err != nil {
 				return fmt.Errorf("failed to open storage: %w", err)
 			}
 			defer func() { _ = store.Close() }()
 
 			fmt.Println("Storage: SQLite (local)")
-			fmt.Printf("Database: %s\n", storage.DefaultDBPath())
+			fmt.Printf("Database: %s\n", store.Path())
 
 			// Count blocks
 			active, err := store.GetActiveBridgeBlocks()
@@ -77,7 +84,7 @@ If multiple blocks are marked as ACTIVE (which violates the single-active-block
 invariant), this command keeps only the most recent block as ACTIVE and pauses
 the others.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			store, err := storage.NewStorage()
+			store, err := openStorage()
 			if err != nil {
 				return fmt.Errorf("failed to open storage: %w", err)
 			}
@@ -100,28 +107,111 @@ the others.`,
 	}
 }
 
+func newSyncImportEmbeddingsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import-embeddings <file>",
+		Short: "Load embeddings from a JSON embeddings export",
+		Long: `Load the embeddings.json written by 'memory takeout' back into the
+database, so restored topics are searchable without calling the embeddings API
+again.
+
+Embeddings replace any already stored for the same chunk. Embeddings of topics
+that are not in the database are skipped. Every vector must have the same
+dimension as the embeddings already stored; if not, nothing is imported.
+
+Examples:
+  memory sync import-embeddings ~/memory-backup/embeddings.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openStorage()
+			if err != nil {
+				return fmt.Errorf("failed to open storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			result, err := store.ImportEmbeddingsFromJSON(args[0])
+			if err != nil {
+				return fmt.Errorf("import failed: %w", err)
+			}
+
+			if outputFormat == "json" {
+				jsonData, err := json.MarshalIndent(result, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+				return nil
+			}
+			if !quiet {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Imported %d embeddings", result.Imported)
+				if result.Skipped > 0 {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), " (%d skipped: their topics are not in this database)", result.Skipped)
+				}
+				_, _ = fmt.Fprintln(cmd.OutOrStdout())
+			}
+			return nil
+		},
+	}
+}
+
 // NewExportCmd creates the export command
 func NewExportCmd() *cobra.Command {
 	var (
-		outputPath string
-		format     string
+		outputPath    string
+		format        string
+		tags          []string
+		revealSecrets bool
+		sign          bool
+		check         string
+		stream        bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "export",
 		Short: "Export memory data to file",
-		Long: `Export all memory data to YAML or Markdown format.
+		Long: `Export all memory data to YAML, Markdown, CSV, or JSON Lines format.
 
 Formats:
   yaml      Machine-readable YAML export (default)
   markdown  Human-readable Markdown export
+  csv       A directory of facts.csv and turns.csv, with embeddings.parquet
+            (chunk_id, block_id, vector, ...), for pandas, DuckDB, or a spreadsheet
+  jsonl     One JSON object per line for jq, Python, and other pipelines;
+            with --stream it is written to standard output as it is read
+
+JSON Lines records carry a "type": a header first, then the profile, each
+block followed by its turns (each with its block_id), then the facts.
 
 Examples:
   memory export                           # Export to memory-export-2026-01-31.yaml
   memory export -o backup.yaml            # Export to specific file
-  memory export -f markdown -o readme.md  # Export as Markdown`,
+  memory export -f markdown -o readme.md  # Export as Markdown
+  memory export -f csv -o analysis/       # Export tables for analysis
+  memory export -f jsonl --stream | jq 'select(.type == "fact")'
+  memory export --tag project-x           # Export only blocks and facts tagged project-x
+  memory export --reveal-secrets          # Include secret fact values in the clear
+  memory export --sign -o backup.yaml     # Sign the export's checksums
+  memory export --check backup.yaml       # Verify an export before trusting it
+
+Secret facts are exported as ********, unless --reveal-secrets is passed.
+
+YAML exports end with a manifest holding the SHA-256 of each section
+(header, profile, blocks, facts). With --sign, the manifest is also signed
+with the Ed25519 key in signing.key next to config.yaml. Commands that read
+exports, such as 'memory diff', refuse a file whose contents no longer match
+its manifest; --check verifies a file on its own.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			store, err := storage.NewStorage()
+			if check != "" {
+				return runCheckExport(cmd, check)
+			}
+			if stream && format != "jsonl" {
+				return fmt.Errorf("--stream applies to jsonl exports only")
+			}
+			if stream && cmd.Flags().Changed("output") {
+				return fmt.Errorf("--stream writes to standard output; leave out --output")
+			}
+
+			store, cfg, err := openStorageWithConfig()
 			if err != nil {
 				return fmt.Errorf("failed to open storage: %w", err)
 			}
@@ -129,10 +219,14 @@ Examples:
 
 			// Generate default output path if not specified
 			if outputPath == "" {
-				dateStr := time.Now().Format("2006-01-02")
+				dateStr := time.Now().In(displayLocation).Format("2006-01-02")
 				switch format {
 				case "markdown", "md":
 					outputPath = fmt.Sprintf("memory-export-%s.md", dateStr)
+				case "csv":
+					outputPath = fmt.Sprintf("memory-export-%s", dateStr)
+				case "jsonl":
+					outputPath = fmt.Sprintf("memory-export-%s.jsonl", dateStr)
 				default:
 					outputPath = fmt.Sprintf("memory-export-%s.yaml", dateStr)
 				}
@@ -143,13 +237,36 @@ Examples:
 				outputPath, _ = filepath.Abs(outputPath)
 			}
 
+			opts := storage.ExportOptions{Tags: tags, RevealSecrets: revealSecrets}
+			if sign {
+				if format == "markdown" || format == "md" || format == "csv" || format == "jsonl" {
+					return fmt.Errorf("--sign applies to YAML exports only")
+				}
+				if opts.Signer, err = signing.LoadOrCreate(signingKeyPath(cfg)); err != nil {
+					return err
+				}
+			}
 			switch format {
 			case "markdown", "md":
-				if err := store.ExportToMarkdown(outputPath); err != nil {
+				if err := store.ExportToMarkdown(outputPath, opts); err != nil {
+					return fmt.Errorf("export failed: %w", err)
+				}
+			case "csv":
+				if _, err := store.ExportToCSV(outputPath, opts); err != nil {
+					return fmt.Errorf("export failed: %w", err)
+				}
+			case "jsonl":
+				if stream {
+					if err := store.ExportToJSONL(cmd.OutOrStdout(), opts); err != nil {
+						return fmt.Errorf("export failed: %w", err)
+					}
+					return nil
+				}
+				if err := exportJSONLFile(store, outputPath, opts); err != nil {
 					return fmt.Errorf("export failed: %w", err)
 				}
 			default:
-				if err := store.ExportToYAML(outputPath); err != nil {
+				if err := store.ExportToYAML(outputPath, opts); err != nil {
 					return fmt.Errorf("export failed: %w", err)
 				}
 			}
@@ -159,12 +276,97 @@ Examples:
 		},
 	}
 
-	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "Output file path")
-	cmd.Flags().StringVarP(&format, "format", "f", "yaml", "Output format (yaml, markdown)")
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "Output file path (a directory for csv)")
+	cmd.Flags().StringVarP(&format, "format", "f", "yaml", "Output format (yaml, markdown, csv, jsonl)")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "Only export blocks and facts with this tag (repeatable; all must match)")
+	cmd.Flags().BoolVar(&revealSecrets, "reveal-secrets", false, "Export secret fact values decrypted instead of masked")
+	cmd.Flags().BoolVar(&sign, "sign", false, "Sign the export's checksum manifest with the local signing key")
+	cmd.Flags().StringVar(&check, "check", "", "Verify the checksums and signature of an export file instead of exporting")
+	cmd.Flags().BoolVar(&stream, "stream", false, "Write a jsonl export to standard output as it is read")
 
 	return cmd
 }
 
+// exportJSONLFile writes a JSON Lines export to path, replacing any file already there
+func exportJSONLFile(store *storage.Storage, path string, opts storage.ExportOptions) error {
+	file, err := os.Create(path) // #nosec G304
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := store.ExportToJSONL(file, opts); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// exportCheck is the JSON output of 'memory export --check'
+type exportCheck struct {
+	File      string   `json:"file"`
+	Sections  []string `json:"sections"`
+	Signed    bool     `json:"signed"`
+	PublicKey string   `json:"public_key,omitempty"`
+	LocalKey  bool     `json:"local_key"`
+}
+
+// runCheckExport verifies an export's manifest and reports who signed it
+func runCheckExport(cmd *cobra.Command, path string) error {
+	data, err := storage.LoadExport(path)
+	if err != nil {
+		return err
+	}
+	if data.Manifest == nil {
+		return fmt.Errorf("cannot verify %s: %w", path, storage.ErrNoManifest)
+	}
+
+	result := exportCheck{File: path, Signed: data.Manifest.Signed(), PublicKey: data.Manifest.PublicKey}
+	for name := range data.Manifest.Sections {
+		result.Sections = append(result.Sections, name)
+	}
+	sort.Strings(result.Sections)
+	if result.Signed {
+		result.LocalKey = isLocalSigningKey(result.PublicKey)
+	}
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+	if quiet {
+		return nil
+	}
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "✓ Checksums match (%s)\n", strings.Join(result.Sections, ", "))
+	switch {
+	case !result.Signed:
+		_, _ = fmt.Fprintln(out, "Not signed")
+	case result.LocalKey:
+		_, _ = fmt.Fprintf(out, "✓ Signature valid (key %s, this machine's signing key)\n", result.PublicKey)
+	default:
+		_, _ = fmt.Fprintf(out, "✓ Signature valid (key %s, not this machine's signing key)\n", result.PublicKey)
+	}
+	return nil
+}
+
+// isLocalSigningKey reports whether publicKey belongs to this machine's signing
+// key, without creating the key when there is none
+func isLocalSigningKey(publicKey string) bool {
+	cfg, err := loadConfig()
+	if err != nil {
+		return false
+	}
+	path := signingKeyPath(cfg)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return false
+	}
+	key, err := signing.LoadOrCreate(path)
+	return err == nil && key.PublicKey() == publicKey
+}
+
 // DefaultDBPath returns the default database path for display
 func DefaultDBPath() string {
 	return storage.DefaultDBPath()
@@ -4,9 +4,14 @@
 package commands
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/harper/remember-standalone/internal/models"
 	"github.com/spf13/cobra"
 )
 
@@ -37,13 +42,14 @@ func TestSyncCmd_Subcommands(t *testing.T) {
 	expectedSubcommands := []string{
 		"status",
 		"repair-blocks",
+		"import-embeddings",
 	}
 
 	for _, subCmdName := range expectedSubcommands {
 		t.Run(subCmdName, func(t *testing.T) {
 			found := false
 			for _, sub := range cmd.Commands() {
-				if sub.Use == subCmdName {
+				if sub.Name() == subCmdName {
 					found = true
 					break
 				}
@@ -134,6 +140,9 @@ func TestExportCmd_Flags(t *testing.T) {
 	}{
 		{"output", "o", ""},
 		{"format", "f", "yaml"},
+		{"reveal-secrets", "", "false"},
+		{"sign", "", "false"},
+		{"check", "", ""},
 	}
 
 	for _, tt := range tests {
@@ -161,6 +170,8 @@ func TestExportCmd_FormatOptions(t *testing.T) {
 	expectedFormats := []string{
 		"yaml",
 		"markdown",
+		"csv",
+		"jsonl",
 	}
 
 	for _, format := range expectedFormats {
@@ -193,3 +204,187 @@ func TestDefaultDBPath_NotEmpty(t *testing.T) {
 		t.Error("DefaultDBPath() returned empty string")
 	}
 }
+
+func TestExportCmd_SignAndCheck(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_editor", Key: "editor", Value: "vim", Confidence: 0.9, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	unsigned := filepath.Join(dir, "unsigned.yaml")
+	if _, err := run("export", "-o", unsigned); err != nil {
+		t.Fatalf("export error = %v", err)
+	}
+	if out, err := run("export", "--check", unsigned); err != nil || !strings.Contains(out, "✓ Checksums match") || !strings.Contains(out, "Not signed") {
+		t.Errorf("export --check unsigned = %q, %v", out, err)
+	}
+
+	signed := filepath.Join(dir, "signed.yaml")
+	if _, err := run("export", "--sign", "-o", signed); err != nil {
+		t.Fatalf("export --sign error = %v", err)
+	}
+	out, err := run("export", "--check", signed)
+	if err != nil || !strings.Contains(out, "this machine's signing key") {
+		t.Errorf("export --check signed = %q, %v; want the local key recognized", out, err)
+	}
+
+	raw, err := os.ReadFile(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(signed, []byte(strings.Replace(string(raw), "value: vim", "value: emacs", 1)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("export", "--check", signed); err == nil || !strings.Contains(err.Error(), "checksum mismatch in facts") {
+		t.Errorf("export --check tampered error = %v, want a checksum mismatch", err)
+	}
+	if _, err := run("diff", unsigned, signed); err == nil {
+		t.Error("diff accepted a tampered export")
+	}
+	if _, err := run("export", "--sign", "-f", "markdown", "-o", filepath.Join(dir, "out.md")); err == nil {
+		t.Error("export --sign accepted the markdown format")
+	}
+}
+
+func TestExportCmd_CSV(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_editor", Key: "editor", Value: "vim", Confidence: 0.9, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	out := filepath.Join(dir, "analysis")
+	root := NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetArgs([]string{"export", "-f", "csv", "-o", out})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("export -f csv error = %v", err)
+	}
+	facts, err := os.ReadFile(filepath.Join(out, "facts.csv"))
+	if err != nil || !strings.Contains(string(facts), "fact_editor,editor,vim,") {
+		t.Errorf("facts.csv = %q, %v", facts, err)
+	}
+	for _, name := range []string{"turns.csv", "embeddings.parquet"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("export -f csv did not write %s: %v", name, err)
+		}
+	}
+}
+
+func TestExportCmd_JSONLStream(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_editor", Key: "editor", Value: "vim", Confidence: 0.9, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("export", "-f", "jsonl", "--stream")
+	if err != nil {
+		t.Fatalf("export -f jsonl --stream error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 2 || !strings.HasPrefix(lines[0], `{"type":"header"`) ||
+		!strings.Contains(lines[len(lines)-1], `"type":"fact"`) || !strings.Contains(lines[len(lines)-1], `"fact_id":"fact_editor"`) {
+		t.Errorf("export --stream = %q, want a header line first and the fact last", out)
+	}
+
+	if _, err := run("export", "-f", "yaml", "--stream"); err == nil {
+		t.Error("export -f yaml --stream succeeded, want an error")
+	}
+	if _, err := run("export", "-f", "jsonl", "--stream", "-o", filepath.Join(dir, "x.jsonl")); err == nil {
+		t.Error("export --stream -o succeeded, want an error")
+	}
+
+	path := filepath.Join(dir, "memory.jsonl")
+	if _, err := run("export", "-f", "jsonl", "-o", path); err != nil {
+		t.Fatalf("export -f jsonl -o error = %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || !strings.Contains(string(data), `"key":"editor"`) {
+		t.Errorf("%s = %q, %v; want the fact", path, data, err)
+	}
+}
+
+func TestSyncCmd_ImportEmbeddings(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_emb", Timestamp: time.Now(), UserMessage: "embed me"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.GetVectorStorage().Save("chunk_emb", "turn_emb", blockID, make([]float64, 1536)); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "embeddings.json")
+	if err := store.ExportEmbeddingsToJSON(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.GetVectorStorage().Delete("chunk_emb"); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	root := NewRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"sync", "import-embeddings", path})
+	if err := root.Execute(); err != nil || !strings.Contains(out.String(), "✓ Imported 1 embeddings") {
+		t.Errorf("sync import-embeddings = %q, %v", out.String(), err)
+	}
+}
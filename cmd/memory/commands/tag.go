@@ -0,0 +1,171 @@
+// ABOUTME: CLI command to tag topics (Bridge Blocks) and facts
+// ABOUTME: Adds +tag and removes -tag; with no arguments lists every tag in use
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	tagFactKey string
+)
+
+// NewTagCmd creates the tag command
+func NewTagCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tag [<block_id>] [+tag|-tag]...",
+		Short: "Add or remove tags on a topic or fact",
+		Long: `Add or remove tags on a topic (Bridge Block) or fact.
+
+Tags are lowercase labels such as "project-x" or "work/q3". Prefix a tag
+with + (or nothing) to add it and - to remove it. With a block ID and no
+tags, shows the block's tags; with no arguments at all, lists every tag
+in use. Tags filter 'memory search --tag' and 'memory export --tag'.
+
+Examples:
+  memory tag block_20260115_143022_a1b2c3d4 +project-x +urgent
+  memory tag block_20260115_143022_a1b2c3d4 -urgent
+  memory tag --fact user_timezone +travel
+  memory tag`,
+		RunE: runTag,
+	}
+
+	// Stop flag parsing at the block ID so "-tag" removals are not read as flags
+	cmd.Flags().SetInterspersed(false)
+	cmd.Flags().StringVar(&tagFactKey, "fact", "", "Tag the most recent fact with this key instead of a block")
+
+	return cmd
+}
+
+func runTag(cmd *cobra.Command, args []string) error {
+	if tagFactKey == "" && len(args) == 0 {
+		return runTagList(cmd)
+	}
+
+	var blockID string
+	if tagFactKey == "" {
+		blockID, args = args[0], args[1:]
+	}
+	add, remove := parseTagEdits(args)
+
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	result := map[string]interface{}{}
+	var tags []string
+
+	switch {
+	case tagFactKey != "":
+		fact, err := store.GetFactByKey(tagFactKey)
+		if err != nil {
+			return fmt.Errorf("getting fact: %w", err)
+		}
+		if fact == nil {
+			return fmt.Errorf("no fact with key %q", tagFactKey)
+		}
+		tags = fact.Tags
+		if len(add) > 0 || len(remove) > 0 {
+			if tags, err = store.TagFact(fact.FactID, add, remove); err != nil {
+				return fmt.Errorf("tagging fact: %w", err)
+			}
+		}
+		result["fact_id"] = fact.FactID
+		result["key"] = fact.Key
+	case len(add) == 0 && len(remove) == 0:
+		block, err := store.GetBridgeBlock(blockID)
+		if err != nil {
+			return fmt.Errorf("getting block: %w", err)
+		}
+		if block == nil {
+			return fmt.Errorf("block %s not found", blockID)
+		}
+		tags = block.Tags
+		result["block_id"] = blockID
+	default:
+		if tags, err = store.TagBlock(blockID, add, remove); err != nil {
+			return fmt.Errorf("tagging block: %w", err)
+		}
+		result["block_id"] = blockID
+	}
+
+	if tags == nil {
+		tags = []string{}
+	}
+	result["tags"] = tags
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	if !quiet {
+		if len(tags) == 0 {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "No tags\n")
+		} else {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Tags: %s\n", strings.Join(tags, ", "))
+		}
+	}
+	return nil
+}
+
+// runTagList prints every tag in use with its block and fact counts
+func runTagList(cmd *cobra.Command) error {
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	counts, err := store.ListTags()
+	if err != nil {
+		return fmt.Errorf("listing tags: %w", err)
+	}
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(counts, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	if len(counts) == 0 {
+		if !quiet {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "No tags\n")
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "TAG\tBLOCKS\tFACTS\n")
+	_, _ = fmt.Fprintf(w, "---\t------\t-----\n")
+	for _, c := range counts {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\n", c.Tag, c.Blocks, c.Facts)
+	}
+	return w.Flush()
+}
+
+// parseTagEdits splits arguments into tags to add (+tag or tag) and remove (-tag)
+func parseTagEdits(args []string) (add, remove []string) {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			remove = append(remove, strings.TrimPrefix(arg, "-"))
+		} else {
+			add = append(add, strings.TrimPrefix(arg, "+"))
+		}
+	}
+	return add, remove
+}
@@ -0,0 +1,71 @@
+// ABOUTME: Tests for the tag command
+// ABOUTME: Verifies +tag/-tag parsing and tagging a block end to end
+
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTagEdits(t *testing.T) {
+	add, remove := parseTagEdits([]string{"+project-x", "urgent", "-old"})
+	if want := []string{"project-x", "urgent"}; !reflect.DeepEqual(add, want) {
+		t.Errorf("add = %v, want %v", add, want)
+	}
+	if want := []string{"old"}; !reflect.DeepEqual(remove, want) {
+		t.Errorf("remove = %v, want %v", remove, want)
+	}
+}
+
+func TestTagCmd_TagsBlock(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) string {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs(args)
+		if err := root.Execute(); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	run("add", "Planning the project x launch")
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := store.GetActiveBridgeBlocks()
+	_ = store.Close()
+	if err != nil || len(blocks) != 1 {
+		t.Fatalf("GetActiveBridgeBlocks() = %d blocks, %v; want 1", len(blocks), err)
+	}
+	blockID := blocks[0].BlockID
+
+	if out := run("tag", blockID, "+Project-X", "+urgent"); !strings.Contains(out, "Tags: project-x, urgent") {
+		t.Errorf("tag output = %q, want both tags", out)
+	}
+	if out := run("tag", blockID, "-urgent"); !strings.Contains(out, "Tags: project-x") || strings.Contains(out, "urgent") {
+		t.Errorf("tag removal output = %q, want only project-x", out)
+	}
+	if out := run("tag"); !strings.Contains(out, "project-x") {
+		t.Errorf("tag list output = %q, want project-x", out)
+	}
+	if out := run("search", "--tag", "project-x", "general"); !strings.Contains(out, "Found 1 result") {
+		t.Errorf("search --tag output = %q, want the tagged block", out)
+	}
+	if out := run("search", "--tag", "other", "general"); !strings.Contains(out, "No memories found") {
+		t.Errorf("search with an unused tag output = %q, want no results", out)
+	}
+}
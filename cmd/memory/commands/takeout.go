@@ -0,0 +1,70 @@
+// ABOUTME: CLI command to take out all memory data into a directory
+// ABOUTME: Writes the YAML export, embeddings, attachment contents, and a checksummed manifest
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// NewTakeoutCmd creates the takeout command
+func NewTakeoutCmd() *cobra.Command {
+	var revealSecrets bool
+
+	cmd := &cobra.Command{
+		Use:   "takeout <dir>",
+		Short: "Write a complete archive of memory data to a directory",
+		Long: `Write everything memory holds to a directory, which must be empty or
+not exist yet:
+
+  memory.yaml       Topics, turns, facts, and the profile (as 'memory export')
+  embeddings.json   Every embedding vector
+  attachments/      The stored content of each attachment, by attachment ID
+  manifest.json     Record counts and the size and SHA-256 of every file
+
+Secret facts are written as ******** unless --reveal-secrets is passed.
+
+Examples:
+  memory takeout ~/memory-takeout
+  memory takeout ./archive --reveal-secrets`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openStorage()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			dir, err := filepath.Abs(args[0])
+			if err != nil {
+				return fmt.Errorf("resolving %s: %w", args[0], err)
+			}
+			manifest, err := store.Takeout(dir, revealSecrets)
+			if err != nil {
+				return fmt.Errorf("takeout failed: %w", err)
+			}
+
+			if outputFormat == "json" {
+				jsonData, err := json.MarshalIndent(manifest, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+				return nil
+			}
+			if !quiet {
+				c := manifest.Counts
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Took out %d topics, %d turns, %d facts, %d embeddings, and %d attachments to %s\n",
+					c.Blocks, c.Turns, c.Facts, c.Embeddings, c.Attachments, dir)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&revealSecrets, "reveal-secrets", false, "Write secret fact values decrypted instead of masked")
+
+	return cmd
+}
@@ -0,0 +1,161 @@
+// ABOUTME: Telemetry command and the hook that counts command use when telemetry is enabled
+// ABOUTME: Telemetry is off by default and controlled entirely by the telemetry settings in config.yaml
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/harper/remember-standalone/internal/config"
+	"github.com/harper/remember-standalone/internal/storage"
+	"github.com/harper/remember-standalone/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+// skipTelemetry stops the current command from being counted, e.g. for read-only MCP servers
+var skipTelemetry bool
+
+// newTelemetry creates the telemetry recorder configured for store
+func newTelemetry(cfg *config.Config, store *storage.Storage) *telemetry.Telemetry {
+	return telemetry.New(cfg.Telemetry, cfg.TelemetryEndpoint, versionInfo.Version, store)
+}
+
+// featureName names a command for telemetry, e.g. "cli.context.use"; arguments are never included
+func featureName(cmd *cobra.Command) string {
+	parts := strings.Fields(cmd.CommandPath())
+	if len(parts) <= 1 {
+		return "cli"
+	}
+	return "cli." + strings.Join(parts[1:], ".")
+}
+
+// recordCommandUse counts a finished command and sends any pending report.
+// Telemetry must never break a command, so failures are only logged with --verbose.
+func recordCommandUse(cmd *cobra.Command) {
+	if skipTelemetry {
+		return
+	}
+	cfg, err := loadConfig()
+	if err != nil || cfg.Telemetry == telemetry.ModeOff || cfg.CheckContext() != nil {
+		return
+	}
+	store, err := storage.NewStorageWithPath(cfg.DBPath())
+	if err != nil {
+		return
+	}
+	defer func() { _ = store.Close() }()
+
+	tel := newTelemetry(cfg, store)
+	if err := tel.Record(featureName(cmd)); err != nil && verbose {
+		log.Printf("telemetry: %v", err)
+	}
+	if _, err := tel.Report(); err != nil && verbose {
+		log.Printf("telemetry: %v", err)
+	}
+}
+
+// NewTelemetryCmd creates the telemetry command group
+func NewTelemetryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "telemetry",
+		Short: "Show locally recorded feature usage",
+		Long: `Show and send opt-in usage telemetry.
+
+Telemetry is off by default. Set it in config.yaml:
+
+  telemetry: local     # count command and MCP tool use in the database only
+  telemetry: share     # also post completed days' counts to telemetry_endpoint
+  telemetry_endpoint: https://example.com/memory-telemetry
+
+Only feature names (like "cli.search" or "mcp.retrieve_memory"), daily counts,
+the memory version, and the OS are recorded. Messages, queries, facts, paths,
+and keys are never included.`,
+	}
+
+	cmd.AddCommand(newTelemetryShowCmd())
+	cmd.AddCommand(newTelemetryReportCmd())
+
+	return cmd
+}
+
+func newTelemetryShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Show the telemetry mode and recorded feature counts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, cfg, err := openStorageWithConfig()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			usage, err := store.GetFeatureUsage()
+			if err != nil {
+				return fmt.Errorf("reading feature usage: %w", err)
+			}
+
+			if outputFormat == "json" {
+				jsonData, err := json.MarshalIndent(map[string]interface{}{
+					"mode":     cfg.Telemetry,
+					"endpoint": cfg.TelemetryEndpoint,
+					"features": usage,
+				}, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+				return nil
+			}
+
+			out := cmd.OutOrStdout()
+			_, _ = fmt.Fprintf(out, "Telemetry: %s\n", cfg.Telemetry)
+			if cfg.Telemetry == telemetry.ModeShare {
+				_, _ = fmt.Fprintf(out, "Endpoint:  %s\n", cfg.TelemetryEndpoint)
+			}
+			if len(usage) == 0 {
+				_, _ = fmt.Fprintf(out, "No feature usage recorded\n")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintf(w, "\nFEATURE\tUSES\tDAYS\n")
+			_, _ = fmt.Fprintf(w, "-------\t----\t----\n")
+			for _, u := range usage {
+				_, _ = fmt.Fprintf(w, "%s\t%d\t%d\n", u.Feature, u.Count, u.Days)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newTelemetryReportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "report",
+		Short: "Send completed days' counts to the telemetry endpoint now",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, cfg, err := openStorageWithConfig()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			if cfg.Telemetry != telemetry.ModeShare {
+				return fmt.Errorf("telemetry is %q; set telemetry: share and telemetry_endpoint in config.yaml to send reports", cfg.Telemetry)
+			}
+
+			sent, err := newTelemetry(cfg, store).Report()
+			if err != nil {
+				return err
+			}
+			if !quiet {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Sent %d daily feature counts to %s\n", sent, cfg.TelemetryEndpoint)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,75 @@
+// ABOUTME: Tests for telemetry command and command-use recording
+// ABOUTME: Verifies nothing is recorded by default and local mode counts commands without arguments
+
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFeatureName(t *testing.T) {
+	root := NewRootCmd()
+	use, _, err := root.Find([]string{"context", "use"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := featureName(use); got != "cli.context.use" {
+		t.Errorf("featureName() = %q, want cli.context.use", got)
+	}
+	if got := featureName(root); got != "cli" {
+		t.Errorf("featureName(root) = %q, want cli", got)
+	}
+}
+
+func TestTelemetry_RecordsCommandsOnlyWhenEnabled(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("MEMORY_TELEMETRY", "")
+
+	run := func(args ...string) string {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs(args)
+		if err := root.Execute(); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	run("context", "list")
+	if out := run("telemetry", "show"); !strings.Contains(out, "Telemetry: off") || !strings.Contains(out, "No feature usage recorded") {
+		t.Errorf("telemetry is off by default and should record nothing:\n%s", out)
+	}
+
+	t.Setenv("MEMORY_TELEMETRY", "local")
+	run("search", "secret query text")
+	out := run("telemetry", "show")
+	if !strings.Contains(out, "cli.search") {
+		t.Errorf("local telemetry should count cli.search:\n%s", out)
+	}
+	if strings.Contains(out, "secret query text") {
+		t.Errorf("telemetry must not record arguments:\n%s", out)
+	}
+}
+
+func TestTelemetryReport_RequiresShare(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("MEMORY_TELEMETRY", "local")
+
+	root := NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetArgs([]string{"telemetry", "report"})
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "share") {
+		t.Errorf("report outside share mode error = %v, want a hint to enable share", err)
+	}
+}
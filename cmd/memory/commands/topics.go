@@ -0,0 +1,463 @@
+// ABOUTME: CLI commands to manage topics (Bridge Blocks)
+// ABOUTME: Provides split for fixing glued-together blocks, links for following threads, bulk archive, and taxonomy grouping
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+var (
+	topicsSplitAtTurn string
+	topicsLinkType    string
+	topicsUnlink      bool
+
+	topicsArchiveBefore string
+	topicsArchiveLabel  string
+	topicsArchiveDryRun bool
+)
+
+// NewTopicsCmd creates the topics command group
+func NewTopicsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "topics",
+		Short: "Manage topics (Bridge Blocks)",
+		Long: `Manage topics (Bridge Blocks).
+
+Each topic is a Bridge Block holding the turns of one conversation
+thread. Use these commands to correct routing mistakes and to follow
+threads across blocks.
+
+Examples:
+  memory topics split block_20260115_143022_a1b2c3d4 --at-turn turn_20260115_150011_e5f6a7b8
+  memory topics related block_20260115_143022_a1b2c3d4
+  memory topics link block_20260116_090000_b2c3d4e5 block_20260115_143022_a1b2c3d4
+  memory topics archive --before 2024-01-01 --label "scratch*"
+  memory topics taxonomy programming`,
+	}
+
+	cmd.AddCommand(newTopicsSplitCmd())
+	cmd.AddCommand(newTopicsRelatedCmd())
+	cmd.AddCommand(newTopicsLinkCmd())
+	cmd.AddCommand(newTopicsArchiveCmd())
+	cmd.AddCommand(newTopicsTaxonomyCmd())
+
+	return cmd
+}
+
+func newTopicsSplitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "split <block_id>",
+		Short: "Move a block's later turns into a new block",
+		Long: `Split a Bridge Block in two.
+
+The turn given by --at-turn and every turn after it move into a new
+block, along with their facts and embeddings. Keywords are recomputed
+for both blocks. Use this when the Governor glued two subjects together.
+
+Examples:
+  memory topics split block_20260115_143022_a1b2c3d4 --at-turn turn_20260115_150011_e5f6a7b8
+  memory topics split block_20260115_143022_a1b2c3d4 --at-turn turn_20260115_150011_e5f6a7b8 --format json`,
+		Args: cobra.ExactArgs(1),
+		RunE: runTopicsSplit,
+	}
+
+	cmd.Flags().StringVar(&topicsSplitAtTurn, "at-turn", "", "First turn to move into the new block (required)")
+	_ = cmd.MarkFlagRequired("at-turn")
+
+	return cmd
+}
+
+func runTopicsSplit(cmd *cobra.Command, args []string) error {
+	blockID := args[0]
+
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	newBlock, err := store.SplitBridgeBlock(blockID, topicsSplitAtTurn)
+	if err != nil {
+		return fmt.Errorf("splitting block: %w", err)
+	}
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(map[string]interface{}{
+			"original_block_id": blockID,
+			"new_block_id":      newBlock.BlockID,
+			"topic_label":       newBlock.TopicLabel,
+			"keywords":          newBlock.Keywords,
+			"status":            newBlock.Status,
+			"turns_moved":       newBlock.TurnCount,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	if !quiet {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Moved %d turns from %s into %s (%s)\n",
+			newBlock.TurnCount, blockID, newBlock.BlockID, newBlock.TopicLabel)
+	}
+	return nil
+}
+
+func newTopicsRelatedCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "related <block_id>",
+		Short: "List blocks linked to a block",
+		Long: `List the blocks linked to a Bridge Block in either direction.
+
+Links are recorded automatically when a topic shift spawns a new block
+(spawned-from) and when a block is split (continuation-of), and by hand
+with "memory topics link" (references). Links into the block are shown
+by their inverse name, e.g. "spawned" or "continued-by".
+
+Examples:
+  memory topics related block_20260115_143022_a1b2c3d4
+  memory topics related block_20260115_143022_a1b2c3d4 --format json`,
+		Args: cobra.ExactArgs(1),
+		RunE: runTopicsRelated,
+	}
+}
+
+func runTopicsRelated(cmd *cobra.Command, args []string) error {
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	related, err := store.GetRelatedTopics(args[0])
+	if err != nil {
+		return fmt.Errorf("getting related topics: %w", err)
+	}
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(related, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	if len(related) == 0 {
+		if !quiet {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "No related topics for %s\n", args[0])
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "RELATION\tBLOCK ID\tTOPIC\tSTATUS\n")
+	_, _ = fmt.Fprintf(w, "--------\t--------\t-----\t------\n")
+	for _, topic := range related {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", topic.Relation, topic.BlockID, truncate(topic.TopicLabel, 40), topic.Status)
+	}
+	return w.Flush()
+}
+
+func newTopicsLinkCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "link <from_block_id> <to_block_id>",
+		Short: "Link one block to another",
+		Long: `Record that one Bridge Block relates to another.
+
+The default relation is "references"; continuation-of and spawned-from
+can be set by hand to repair threads. Use --unlink to remove a link.
+
+Examples:
+  memory topics link block_20260116_090000_b2c3d4e5 block_20260115_143022_a1b2c3d4
+  memory topics link block_20260116_090000_b2c3d4e5 block_20260115_143022_a1b2c3d4 --type continuation-of
+  memory topics link block_20260116_090000_b2c3d4e5 block_20260115_143022_a1b2c3d4 --unlink`,
+		Args: cobra.ExactArgs(2),
+		RunE: runTopicsLink,
+	}
+
+	cmd.Flags().StringVar(&topicsLinkType, "type", string(models.RelationReferences), "Relation: references, continuation-of, or spawned-from")
+	cmd.Flags().BoolVar(&topicsUnlink, "unlink", false, "Remove the link instead of adding it")
+
+	return cmd
+}
+
+func runTopicsLink(cmd *cobra.Command, args []string) error {
+	relation, err := models.ParseRelationType(topicsLinkType)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	from, to := args[0], args[1]
+	if topicsUnlink {
+		removed, err := store.UnrelateBlocks(from, to, relation)
+		if err != nil {
+			return fmt.Errorf("unlinking topics: %w", err)
+		}
+		if !removed {
+			return fmt.Errorf("%s is not linked to %s as %s", from, to, relation)
+		}
+		if !quiet {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Unlinked %s -%s-> %s\n", from, relation, to)
+		}
+		return nil
+	}
+
+	if err := store.RelateBlocks(from, to, relation); err != nil {
+		return fmt.Errorf("linking topics: %w", err)
+	}
+	if !quiet {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Linked %s -%s-> %s\n", from, relation, to)
+	}
+	return nil
+}
+
+func newTopicsArchiveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Archive every block matching a filter",
+		Long: `Archive every Bridge Block matching the filters, in one transaction.
+
+--before matches blocks last updated before a time (a bare date means the
+start of that day); --label matches topic labels, ignoring case, where *
+matches any run of characters and ? one character. A block must match every
+filter given, and at least one is required. Blocks already archived are
+skipped. Archived blocks stay searchable.
+
+Examples:
+  memory topics archive --before 2024-01-01
+  memory topics archive --before 2024-01-01 --label "scratch*"
+  memory topics archive --label "scratch*" --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: runTopicsArchive,
+	}
+
+	cmd.Flags().StringVar(&topicsArchiveBefore, "before", "", "Archive blocks last updated before this time")
+	cmd.Flags().StringVar(&topicsArchiveLabel, "label", "", "Archive blocks whose topic label matches this pattern")
+	cmd.Flags().BoolVar(&topicsArchiveDryRun, "dry-run", false, "List the blocks that would be archived without archiving them")
+
+	return cmd
+}
+
+func runTopicsArchive(cmd *cobra.Command, args []string) error {
+	if topicsArchiveBefore == "" && topicsArchiveLabel == "" {
+		return fmt.Errorf("give --before, --label, or both")
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	filter := storage.ArchiveFilter{Label: topicsArchiveLabel}
+	if topicsArchiveBefore != "" {
+		if filter.Before, err = models.ParseBefore(topicsArchiveBefore, displayLocation); err != nil {
+			return err
+		}
+	}
+
+	blocks, err := store.ArchiveBlocks(filter, topicsArchiveDryRun)
+	if err != nil {
+		return fmt.Errorf("archiving topics: %w", err)
+	}
+
+	if outputFormat == "json" {
+		if blocks == nil {
+			blocks = []storage.ArchivedBlock{}
+		}
+		jsonData, err := json.MarshalIndent(map[string]interface{}{
+			"dry_run": topicsArchiveDryRun,
+			"count":   len(blocks),
+			"blocks":  blocks,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	if len(blocks) == 0 {
+		if !quiet {
+			_, _ = fmt.Fprintf(out, "No topics to archive\n")
+		}
+		return nil
+	}
+	if topicsArchiveDryRun || verbose {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "BLOCK ID\tSTATUS\tUPDATED\tTOPIC\n")
+		_, _ = fmt.Fprintf(w, "--------\t------\t-------\t-----\n")
+		for _, block := range blocks {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", block.BlockID, block.Status,
+				block.UpdatedAt.In(displayLocation).Format("2006-01-02"), truncate(block.TopicLabel, 50))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if !quiet {
+		if topicsArchiveDryRun {
+			_, _ = fmt.Fprintf(out, "%d topics would be archived\n", len(blocks))
+		} else {
+			_, _ = fmt.Fprintf(out, "✓ Archived %d topics\n", len(blocks))
+		}
+	}
+	return nil
+}
+
+func newTopicsTaxonomyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "taxonomy [node]",
+		Short: "Show topics grouped by the topic taxonomy",
+		Long: `Show how many topics fall under each node of topic_taxonomy, and the topic
+labels in use that fall under none, most used first; those are candidates to
+add to the taxonomy, as nodes or as aliases of one. With a node, list the
+topics under it and its descendants.
+
+A node is named by its path, its last segment, or an alias.
+
+Examples:
+  memory config set topic_taxonomy "programming/go=golang; programming/python; home/garden"
+  memory topics taxonomy
+  memory topics taxonomy programming
+  memory topics taxonomy golang --format json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runTopicsTaxonomy,
+	}
+}
+
+// taxonomyTopic is a topic as 'memory topics taxonomy <node>' lists it
+type taxonomyTopic struct {
+	BlockID    string    `json:"block_id"`
+	TopicLabel string    `json:"topic_label"`
+	Status     string    `json:"status"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// taxonomyNodeCount is a taxonomy node with the topics under it
+type taxonomyNodeCount struct {
+	models.TaxonomyNode
+	Blocks int `json:"blocks"`
+}
+
+func runTopicsTaxonomy(cmd *cobra.Command, args []string) error {
+	store, err := openStorage()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	taxonomy := store.TopicTaxonomy()
+	if len(args) == 1 {
+		node := taxonomy.Node(args[0])
+		if node == "" {
+			return fmt.Errorf("%q is not a node of topic_taxonomy", args[0])
+		}
+		blocks, err := store.BlocksUnderTopic(node)
+		if err != nil {
+			return fmt.Errorf("listing topics: %w", err)
+		}
+		return printTaxonomyTopics(cmd, node, blocks)
+	}
+
+	labels, err := store.TopicLabels()
+	if err != nil {
+		return fmt.Errorf("listing topic labels: %w", err)
+	}
+	nodes := make([]taxonomyNodeCount, len(taxonomy))
+	for i, n := range taxonomy {
+		nodes[i].TaxonomyNode = n
+	}
+	unmapped := []storage.TopicLabelCount{}
+	for _, label := range labels {
+		if taxonomy.Node(label.Label) == "" {
+			unmapped = append(unmapped, label)
+			continue
+		}
+		for i := range nodes {
+			if taxonomy.Under(label.Label, nodes[i].Path) {
+				nodes[i].Blocks += label.Blocks
+			}
+		}
+	}
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(map[string]interface{}{
+			"nodes":    nodes,
+			"unmapped": unmapped,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	if len(nodes) == 0 {
+		_, _ = fmt.Fprintf(out, "No topic_taxonomy configured; set one with 'memory config set topic_taxonomy'\n")
+	}
+	for _, n := range nodes {
+		_, _ = fmt.Fprintf(out, "%s%s (%d)\n", strings.Repeat("  ", n.Depth()), n.Name(), n.Blocks)
+	}
+	if len(unmapped) > 0 {
+		_, _ = fmt.Fprintf(out, "\nLabels outside the taxonomy:\n")
+		for _, label := range unmapped {
+			_, _ = fmt.Fprintf(out, "  %s (%d)\n", label.Label, label.Blocks)
+		}
+	}
+	return nil
+}
+
+// printTaxonomyTopics lists the topics under a taxonomy node
+func printTaxonomyTopics(cmd *cobra.Command, node string, blocks []models.BridgeBlock) error {
+	topics := make([]taxonomyTopic, len(blocks))
+	for i, block := range blocks {
+		topics[i] = taxonomyTopic{BlockID: block.BlockID, TopicLabel: block.TopicLabel, Status: string(block.Status), UpdatedAt: block.UpdatedAt}
+	}
+
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(map[string]interface{}{
+			"node":   node,
+			"count":  len(topics),
+			"topics": topics,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	if len(topics) == 0 {
+		_, _ = fmt.Fprintf(out, "No topics under %s\n", node)
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "BLOCK ID\tSTATUS\tUPDATED\tTOPIC\n")
+	_, _ = fmt.Fprintf(w, "--------\t------\t-------\t-----\n")
+	for _, topic := range topics {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", topic.BlockID, topic.Status,
+			topic.UpdatedAt.In(displayLocation).Format("2006-01-02"), truncate(topic.TopicLabel, 50))
+	}
+	return w.Flush()
+}
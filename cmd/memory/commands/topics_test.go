@@ -0,0 +1,174 @@
+// ABOUTME: Tests for topics commands
+// ABOUTME: Verifies topics command group, split, related, and link subcommand structure, bulk archive, and taxonomy grouping
+
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewTopicsCmd(t *testing.T) {
+	cmd := NewTopicsCmd()
+
+	if cmd.Use != "topics" {
+		t.Errorf("Use = %q, want %q", cmd.Use, "topics")
+	}
+
+	if cmd.Short == "" {
+		t.Error("Short description should not be empty")
+	}
+
+	for _, name := range []string{"split", "related", "link", "archive", "taxonomy"} {
+		found := false
+		for _, sub := range cmd.Commands() {
+			if sub.Name() == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("%s subcommand not found", name)
+		}
+	}
+}
+
+func TestTopicsLinkCmd_Args(t *testing.T) {
+	cmd := newTopicsLinkCmd()
+
+	if err := cmd.Args(cmd, []string{"block_1"}); err == nil {
+		t.Error("link should require two block IDs")
+	}
+	if err := cmd.Args(cmd, []string{"block_1", "block_2"}); err != nil {
+		t.Errorf("link with two args error = %v", err)
+	}
+	if flag := cmd.Flags().Lookup("type"); flag == nil || flag.DefValue != "references" {
+		t.Errorf("--type flag = %+v, want default references", flag)
+	}
+}
+
+func TestTopicsSplitCmd_Flags(t *testing.T) {
+	cmd := newTopicsSplitCmd()
+
+	flag := cmd.Flags().Lookup("at-turn")
+	if flag == nil {
+		t.Fatal("--at-turn flag not found")
+	}
+
+	if _, ok := flag.Annotations["cobra_annotation_bash_completion_one_required_flag"]; !ok {
+		t.Error("--at-turn should be required")
+	}
+}
+
+func TestTopicsSplitCmd_Args(t *testing.T) {
+	cmd := newTopicsSplitCmd()
+
+	if err := cmd.Args(cmd, []string{}); err == nil {
+		t.Error("split should require a block ID")
+	}
+	if err := cmd.Args(cmd, []string{"block_1"}); err != nil {
+		t.Errorf("split with one arg error = %v", err)
+	}
+}
+
+func TestTopicsSplitCmd_Examples(t *testing.T) {
+	cmd := newTopicsSplitCmd()
+
+	if !findSubstring(cmd.Long, "memory topics split") {
+		t.Error("Long description should contain usage example")
+	}
+}
+
+func TestTopicsArchiveCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	for _, tags := range []string{"scratch", "scratchpad", "travel"} {
+		if _, err := run("add", "--tags", tags, "note about "+tags); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := run("topics", "archive"); err == nil {
+		t.Error("topics archive with no filter succeeded, want an error")
+	}
+	if _, err := run("topics", "archive", "--before", "someday"); err == nil {
+		t.Error("topics archive --before someday succeeded, want an error")
+	}
+	if out, err := run("topics", "archive", "--before", "2024-01-01"); err != nil || !strings.Contains(out, "No topics to archive") {
+		t.Errorf("topics archive --before 2024-01-01 = %q, %v; want nothing archived", out, err)
+	}
+
+	out, err := run("topics", "archive", "--label", "scratch*", "--dry-run")
+	if err != nil || !strings.Contains(out, "2 topics would be archived") {
+		t.Fatalf("topics archive --dry-run = %q, %v", out, err)
+	}
+	out, err = run("topics", "archive", "--label", "scratch*")
+	if err != nil || !strings.Contains(out, "✓ Archived 2 topics") {
+		t.Fatalf("topics archive = %q, %v", out, err)
+	}
+	if out, _ := run("topics", "archive", "--label", "scratch*"); !strings.Contains(out, "No topics to archive") {
+		t.Errorf("topics archive again = %q, want the archived topics skipped", out)
+	}
+}
+
+func TestTopicsTaxonomyCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("MEMORY_TOPIC_TAXONOMY", "programming/go=golang; programming/python; home/garden")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	// Topics are mapped onto the taxonomy as they are stored
+	for _, tags := range []string{"golang", "Python", "recipes"} {
+		if _, err := run("add", "--tags", tags, "note about "+tags); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := run("topics", "taxonomy")
+	if err != nil {
+		t.Fatalf("topics taxonomy error = %v", err)
+	}
+	for _, want := range []string{"home (0)\n  garden (0)", "programming (2)\n  go (1)\n  python (1)", "Labels outside the taxonomy:\n  recipes (1)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("topics taxonomy = %q, want it to contain %q", out, want)
+		}
+	}
+
+	out, err = run("topics", "taxonomy", "golang")
+	if err != nil || !strings.Contains(out, "programming/go") || strings.Contains(out, "python") {
+		t.Errorf("topics taxonomy golang = %q, %v; want only the go topic", out, err)
+	}
+	if _, err := run("topics", "taxonomy", "cooking"); err == nil {
+		t.Error("topics taxonomy cooking succeeded, want an error for a node outside the taxonomy")
+	}
+}
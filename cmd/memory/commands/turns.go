@@ -0,0 +1,149 @@
+// ABOUTME: CLI commands for individual stored turns
+// ABOUTME: Corrects a turn's text or annotates it, then re-embeds it and re-extracts its facts
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/llm"
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// NewTurnsCmd creates the turns command group
+func NewTurnsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "turns",
+		Short: "Correct and annotate stored turns",
+	}
+
+	cmd.AddCommand(newTurnsEditCmd())
+
+	return cmd
+}
+
+func newTurnsEditCmd() *cobra.Command {
+	var (
+		userMessage string
+		aiResponse  string
+		note        string
+		noWait      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "edit <turn_id>",
+		Short: "Correct a turn's user message or AI response, or annotate it",
+		Long: `Correct what a stored turn says, or attach a note to it.
+
+Changing the user message deletes the facts extracted from it and extracts
+them again; changing either text re-embeds the turn. With an OpenAI key this
+happens before the command returns (unless --no-wait); without one it is
+queued until a process with a key runs it. Notes are kept with the turn and
+shown by get_topic_history. Turns stored as transcripts can only be annotated.
+
+Examples:
+  memory turns edit turn_20260115_143022_a1b2c3d4 --user "I moved to Lisbon"
+  memory turns edit turn_20260115_143022_a1b2c3d4 --note "this was wrong"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+			if !flags.Changed("user") && !flags.Changed("ai") && strings.TrimSpace(note) == "" {
+				return fmt.Errorf("give --user, --ai, or --note")
+			}
+
+			// Load .env for API keys
+			_ = godotenv.Load()
+
+			store, cfg, err := openStorageWithConfig()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			edit := models.TurnEdit{Annotation: note, Agent: cfg.AgentID}
+			if flags.Changed("user") {
+				edit.UserMessage = &userMessage
+			}
+			if flags.Changed("ai") {
+				edit.AIResponse = &aiResponse
+			}
+
+			var client *llm.OpenAIClient
+			if cfg.OpenAIKey != "" {
+				if client, err = llm.NewOpenAIClientWithConfig(llm.ConfigFromSettings(cfg)); err != nil {
+					if verbose {
+						fmt.Fprintf(os.Stderr, "Warning: Could not initialize OpenAI client: %v\n", err)
+					}
+					client = nil
+				} else {
+					client.SetUsageRecorder(store)
+				}
+			}
+			queue := core.NewJobQueue(store)
+			core.RegisterJobHandlers(queue, client, nil)
+
+			edited, err := core.EditTurn(store, queue, args[0], edit)
+			if err != nil {
+				return fmt.Errorf("editing turn: %w", err)
+			}
+
+			ran := 0
+			if client != nil && !noWait {
+				for _, job := range edited.Jobs {
+					if err := queue.Run(cmd.Context(), job.ID); err != nil {
+						if verbose {
+							fmt.Fprintf(os.Stderr, "Warning: %s job %d failed and will be retried: %v\n", job.Kind, job.ID, err)
+						}
+						continue
+					}
+					ran++
+				}
+			}
+
+			if outputFormat == "json" {
+				jsonData, err := json.MarshalIndent(map[string]interface{}{
+					"turn_id":       args[0],
+					"block_id":      edited.BlockID,
+					"edited_at":     edited.Turn.EditedAt,
+					"annotations":   edited.Turn.Annotations,
+					"facts_dropped": edited.FactsDropped,
+					"jobs_queued":   len(edited.Jobs) - ran,
+					"jobs_run":      ran,
+				}, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+				return nil
+			}
+			if quiet {
+				return nil
+			}
+
+			out := cmd.OutOrStdout()
+			_, _ = fmt.Fprintf(out, "✓ Updated turn %s\n", args[0])
+			if edited.FactsDropped > 0 {
+				_, _ = fmt.Fprintf(out, "  Dropped %d facts extracted from the old message\n", edited.FactsDropped)
+			}
+			if queued := len(edited.Jobs) - ran; queued > 0 {
+				_, _ = fmt.Fprintf(out, "  %d re-embedding and fact extraction jobs queued\n", queued)
+			} else if ran > 0 {
+				_, _ = fmt.Fprintf(out, "  Ran %d re-embedding and fact extraction jobs\n", ran)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&userMessage, "user", "", "Corrected user message")
+	cmd.Flags().StringVar(&aiResponse, "ai", "", "Corrected AI response")
+	cmd.Flags().StringVar(&note, "note", "", "Annotation to attach to the turn")
+	cmd.Flags().BoolVar(&noWait, "no-wait", false, "Queue re-embedding and fact extraction instead of running them now")
+
+	return cmd
+}
@@ -0,0 +1,74 @@
+// ABOUTME: Tests for the turns command
+// ABOUTME: Verifies editing and annotating a turn and queueing its re-embedding without an OpenAI key
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTurnsEditCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run("add", "I live in Porto"); err != nil {
+		t.Fatal(err)
+	}
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := store.GetActiveBridgeBlocks()
+	if err != nil || len(blocks) != 1 {
+		t.Fatalf("GetActiveBridgeBlocks() = %d blocks, %v; want 1", len(blocks), err)
+	}
+	block, err := store.GetBridgeBlock(blocks[0].BlockID)
+	if err != nil || block == nil || len(block.Turns) != 1 {
+		t.Fatalf("GetBridgeBlock() = %+v, %v; want one turn", block, err)
+	}
+	turnID := block.Turns[0].TurnID
+	_ = store.Close()
+
+	if _, err := run("turns", "edit", turnID); err == nil {
+		t.Error("turns edit with nothing to change succeeded, want an error")
+	}
+	out, err := run("turns", "edit", turnID, "--user", "I live in Lisbon", "--note", "moved in May")
+	if err != nil {
+		t.Fatalf("turns edit error = %v", err)
+	}
+	if !strings.Contains(out, "✓ Updated turn "+turnID) || !strings.Contains(out, "2 re-embedding and fact extraction jobs queued") {
+		t.Errorf("turns edit output = %q, want the update and 2 queued jobs", out)
+	}
+	if _, err := run("turns", "edit", "turn_missing", "--note", "x"); err == nil {
+		t.Error("turns edit of a missing turn succeeded, want an error")
+	}
+
+	store, err = openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	_, turn, err := store.GetTurn(turnID)
+	if err != nil || turn == nil {
+		t.Fatalf("GetTurn() = %+v, %v", turn, err)
+	}
+	if turn.UserMessage != "I live in Lisbon" || len(turn.Annotations) != 1 || turn.Annotations[0].Text != "moved in May" {
+		t.Errorf("turn = %+v, want the corrected message and the note", turn)
+	}
+}
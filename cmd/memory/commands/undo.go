@@ -0,0 +1,159 @@
+// ABOUTME: CLI command to undo recent deletes and turn edits from the undo log
+// ABOUTME: Lists the log with --list and forgets everything in it with --clear
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// NewUndoCmd creates the undo command
+func NewUndoCmd() *cobra.Command {
+	var (
+		count    int
+		list     bool
+		clearLog bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Undo the most recent deletes and turn edits",
+		Long: `Put back what the most recent destructive operations removed, newest
+first. The undo log keeps the last undo_log_size (default 20) of:
+
+  delete_topic       a topic deleted with its turns, facts, tags, and attachments
+  delete_facts       facts deleted by key or ID, or dropped when a turn was edited
+  delete_persona     a persona deleted
+  delete_attachment  an attachment deleted
+  edit_turn          a turn's text corrected or redacted
+
+Undoing a turn edit queues the turn to be embedded again; run 'memory jobs
+run' when no MCP server is running. An operation cannot be undone once
+something it refers to is gone, e.g. facts of a topic deleted since; undo
+that first.
+
+The log holds copies of what was deleted, so redacting a turn or deleting a
+topic does not remove its text until the entry ages out. --clear forgets
+the log at once; set undo_log_size to 0 to keep no log.
+
+Examples:
+  memory undo --list
+  memory undo
+  memory undo -n 3
+  memory undo --clear`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if count < 1 {
+				return fmt.Errorf("--count must be at least 1, got %d", count)
+			}
+			if list && clearLog {
+				return fmt.Errorf("--list and --clear cannot be combined")
+			}
+
+			store, err := openStorage()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			out := cmd.OutOrStdout()
+			switch {
+			case list:
+				entries, err := store.UndoLog(1000) // undo_log_size is at most 1000
+				if err != nil {
+					return err
+				}
+				if outputFormat == "json" {
+					return printUndoJSON(out, map[string]interface{}{"entries": undoEntries(entries)})
+				}
+				if len(entries) == 0 {
+					if !quiet {
+						_, _ = fmt.Fprintln(out, "Nothing to undo")
+					}
+					return nil
+				}
+				printUndoEntries(out, entries)
+				return nil
+
+			case clearLog:
+				cleared, err := store.ClearUndoLog()
+				if err != nil {
+					return err
+				}
+				if outputFormat == "json" {
+					return printUndoJSON(out, map[string]interface{}{"cleared": cleared})
+				}
+				if !quiet {
+					_, _ = fmt.Fprintf(out, "✓ Cleared %d operations from the undo log\n", cleared)
+				}
+				return nil
+			}
+
+			undone, undoErr := store.Undo(count)
+			if outputFormat == "json" {
+				if err := printUndoJSON(out, map[string]interface{}{"undone": undoEntries(undone)}); err != nil {
+					return err
+				}
+				return undoErr
+			}
+			if !quiet && len(undone) > 0 {
+				printUndoEntries(out, undone)
+				_, _ = fmt.Fprintf(out, "✓ Undid %d operations\n", len(undone))
+				for _, entry := range undone {
+					if entry.Operation == storage.UndoEditTurn {
+						_, _ = fmt.Fprintln(out, "Edited turns were queued to be embedded again; run 'memory jobs run' if no server is running")
+						break
+					}
+				}
+			}
+			if undoErr != nil {
+				return undoErr
+			}
+			if len(undone) == 0 && !quiet {
+				_, _ = fmt.Fprintln(out, "Nothing to undo")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&count, "count", "n", 1, "Number of operations to undo, newest first")
+	cmd.Flags().BoolVar(&list, "list", false, "List the operations that can be undone instead of undoing any")
+	cmd.Flags().BoolVar(&clearLog, "clear", false, "Forget every operation in the undo log, deleting the copies it keeps")
+
+	return cmd
+}
+
+// undoEntries keeps JSON output a list when there are no entries
+func undoEntries(entries []storage.UndoEntry) []storage.UndoEntry {
+	if entries == nil {
+		return []storage.UndoEntry{}
+	}
+	return entries
+}
+
+// printUndoJSON writes v as indented JSON
+func printUndoJSON(out io.Writer, v interface{}) error {
+	jsonData, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling JSON: %w", err)
+	}
+	_, _ = fmt.Fprintf(out, "%s\n", jsonData)
+	return nil
+}
+
+// printUndoEntries lists undo log entries as a table
+func printUndoEntries(out io.Writer, entries []storage.UndoEntry) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "ID\tOPERATION\tTARGET\tSUMMARY\tROWS\tWHEN\n")
+	for _, entry := range entries {
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%s\n", entry.ID, entry.Operation, truncate(entry.Target, 30),
+			truncate(entry.Summary, 40), entry.Rows, formatTime(entry.CreatedAt))
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,111 @@
+// ABOUTME: Tests for the undo command
+// ABOUTME: Verifies listing the undo log, undoing a deleted fact and topic, and clearing the log
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestUndoCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("MEMORY_UNDO_LOG_SIZE", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_undo_cmd", Timestamp: time.Now(), UserMessage: "I drink oolong"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_undo_cmd", BlockID: blockID, TurnID: "turn_undo_cmd", Key: "tea", Value: "oolong", Confidence: 0.9, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	if out, err := run("undo"); err != nil || !strings.Contains(out, "Nothing to undo") {
+		t.Errorf("undo with an empty log = %q, %v; want nothing undone", out, err)
+	}
+
+	// Deletes come from MCP clients
+	store, err = openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.DeleteFactByKey("tea"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteBridgeBlock(blockID); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	out, err := run("undo", "--list")
+	if err != nil || !strings.Contains(out, "delete_topic") || !strings.Contains(out, "delete_facts") {
+		t.Errorf("undo --list = %q, %v; want both deletes", out, err)
+	}
+	if _, err := run("undo", "--list", "--clear"); err == nil {
+		t.Error("undo --list --clear succeeded, want an error")
+	}
+	if _, err := run("undo", "-n", "0"); err == nil {
+		t.Error("undo -n 0 succeeded, want an error")
+	}
+
+	out, err = run("undo", "-n", "2", "--format", "json")
+	if err != nil {
+		t.Fatalf("undo -n 2 error = %v", err)
+	}
+	var result struct {
+		Undone []struct {
+			Operation string `json:"operation"`
+			Target    string `json:"target"`
+		} `json:"undone"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil || len(result.Undone) != 2 ||
+		result.Undone[0].Target != blockID || result.Undone[1].Target != "tea" {
+		t.Errorf("undo -n 2 = %q, %v; want the topic then the fact undone", out, err)
+	}
+
+	store, err = openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fact, err := store.GetFactByKey("tea")
+	if err != nil || fact == nil || fact.Value != "oolong" {
+		t.Errorf("fact after undo = %+v, %v; want it back", fact, err)
+	}
+	if _, err := store.DeleteFactByKey("tea"); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	if out, err := run("undo", "--clear"); err != nil || !strings.Contains(out, "✓ Cleared 1 operations") {
+		t.Errorf("undo --clear = %q, %v; want one cleared", out, err)
+	}
+	if out, err := run("undo"); err != nil || !strings.Contains(out, "Nothing to undo") {
+		t.Errorf("undo after clearing = %q, %v; want nothing undone", out, err)
+	}
+}
@@ -19,6 +19,9 @@ func truncate(s string, maxLen int) string {
 	return string(runes[:maxLen-3]) + "..."
 }
 
+// displayLocation is the configured time zone for dates shown to the user; loadConfig sets it
+var displayLocation = time.Local
+
 // formatTime formats a time for display
 func formatTime(t time.Time) string {
 	now := time.Now()
@@ -36,7 +39,7 @@ func formatTime(t time.Time) string {
 		days := int(diff.Hours() / 24)
 		return fmt.Sprintf("%dd ago", days)
 	}
-	return t.Format("2006-01-02")
+	return t.In(displayLocation).Format("2006-01-02")
 }
 
 // containsString checks if a slice contains a string
@@ -0,0 +1,146 @@
+// ABOUTME: CLI command to sync memory into a Markdown vault for Obsidian or any editor
+// ABOUTME: Writes one note per topic and a facts index, once or continuously with --watch
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// NewVaultCmd creates the vault command
+func NewVaultCmd() *cobra.Command {
+	var (
+		watch         bool
+		interval      time.Duration
+		tags          []string
+		revealSecrets bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "vault [dir]",
+		Short: "Sync memory into a Markdown vault",
+		Long: `Keep a directory of Markdown notes in step with memory, so memories are
+browsable and greppable in Obsidian or any editor:
+
+  Topics/<label>.md   One note per topic: front matter (block ID, status,
+                      tags, keywords), summary, facts, links to related
+                      topics, and the conversation
+  Facts.md            The current value of every fact, linked to its topic
+
+Each sync rewrites only notes whose content changed and removes notes of
+topics that are gone. Files it did not write are never touched, so the
+directory can live inside an existing vault. Edits to the notes themselves
+are overwritten on the next sync.
+
+The directory defaults to vault_dir (or MEMORY_VAULT_DIR). With vault_dir
+set, 'memory mcp' and 'memory serve' keep the vault in sync while they run;
+--watch does the same from here until interrupted.
+
+Secret facts are written as ******** unless --reveal-secrets is passed.
+
+Examples:
+  memory vault ~/Obsidian/Memory
+  memory vault ~/Obsidian/Memory --watch
+  memory vault ./project-x --tag project-x`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, cfg, err := openStorageWithConfig()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			dir := cfg.VaultDir
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			if dir == "" {
+				return fmt.Errorf("no vault directory: pass one or set vault_dir in config")
+			}
+			if dir, err = filepath.Abs(dir); err != nil {
+				return fmt.Errorf("resolving vault directory: %w", err)
+			}
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive, got %s", interval)
+			}
+
+			syncer := core.NewVaultSyncer(store, dir, storage.ExportOptions{Tags: tags, RevealSecrets: revealSecrets})
+			result, err := syncer.SyncIfChanged()
+			if err != nil {
+				return fmt.Errorf("vault sync failed: %w", err)
+			}
+			if err := printVaultSync(cmd, result, !watch); err != nil || !watch {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			if !quiet && outputFormat != "json" {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Watching for changes every %s (Ctrl-C to stop)\n", interval)
+			}
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+				result, err := syncer.SyncIfChanged()
+				if err != nil {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Vault sync failed: %v\n", err)
+					continue
+				}
+				if result != nil && result.Changed() {
+					if err := printVaultSync(cmd, result, false); err != nil {
+						return err
+					}
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&watch, "watch", false, "Keep syncing as memory changes until interrupted")
+	cmd.Flags().DurationVar(&interval, "interval", core.DefaultVaultInterval, "How often --watch checks memory for changes")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "Only include topics and facts with this tag (repeatable; all must match)")
+	cmd.Flags().BoolVar(&revealSecrets, "reveal-secrets", false, "Write secret fact values decrypted instead of masked")
+
+	return cmd
+}
+
+// printVaultSync reports one sync: indented JSON when it is the only output, one line
+// per sync while watching
+func printVaultSync(cmd *cobra.Command, result *storage.VaultSync, single bool) error {
+	if result == nil {
+		return nil
+	}
+	if outputFormat == "json" {
+		var jsonData []byte
+		var err error
+		if single {
+			jsonData, err = json.MarshalIndent(result, "", "  ")
+		} else {
+			jsonData, err = json.Marshal(result)
+		}
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+		return nil
+	}
+	if !quiet {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Synced %s: %d written, %d removed, %d unchanged\n",
+			result.Dir, len(result.Written), len(result.Removed), result.Unchanged)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+// ABOUTME: Tests for the vault command
+// ABOUTME: Verifies a one-off sync writes notes and that a directory is required
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+func TestVaultCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("MEMORY_VAULT_DIR", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_vault_cmd", Timestamp: time.Now(), UserMessage: "write me down",
+		Topics: []string{"Notes"}}); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	if _, err := run("vault"); err == nil || !strings.Contains(err.Error(), "no vault directory") {
+		t.Errorf("vault without a directory = %v, want an error", err)
+	}
+
+	vaultDir := filepath.Join(dir, "vault")
+	if out, err := run("vault", vaultDir); err != nil || !strings.Contains(out, "✓ Synced "+vaultDir+": 2 written") {
+		t.Fatalf("vault = %q, %v", out, err)
+	}
+	for _, name := range []string{filepath.Join(storage.VaultTopicsDir, "Notes.md"), storage.VaultFactsFile} {
+		if _, err := os.Stat(filepath.Join(vaultDir, name)); err != nil {
+			t.Errorf("vault did not write %s: %v", name, err)
+		}
+	}
+	if out, err := run("vault", vaultDir); err != nil || !strings.Contains(out, "0 written, 0 removed, 2 unchanged") {
+		t.Errorf("second vault = %q, %v; want nothing rewritten", out, err)
+	}
+}
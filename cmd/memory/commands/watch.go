@@ -0,0 +1,196 @@
+// ABOUTME: CLI command to follow changes to memory as they happen
+// ABOUTME: Reads the REST API's event stream, fed by the server's change feed, rather than polling the database
+package commands
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/rest"
+)
+
+// watchTimeLayout is how watch shows when an event happened, in the configured zone
+const watchTimeLayout = "15:04:05"
+
+// NewWatchCmd creates the watch command
+func NewWatchCmd() *cobra.Command {
+	var (
+		serverURL string
+		types     []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Follow changes to memory as they happen",
+		Long: `Print each change to memory as it happens: turns stored, facts added or
+superseded, conflicts between agents, topics paused, resumed, archived, or
+deleted, and profile updates.
+
+Changes are streamed from a running server's GET /v1/events, so start one
+with 'memory serve' (or an MCP server with http_addr set) first. watch
+connects to http_addr and sends http_token, or else http_read_token, when one
+is set; --url points it at another server. It runs until interrupted.
+
+Values of secret facts are never sent, and credentials in other values are
+masked. With --format json, each event is printed as one line of JSON.
+
+Examples:
+  memory watch
+  memory watch --type fact_superseded,conflict_detected
+  memory watch --url http://10.0.0.5:8787 --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			if _, err := rest.ParseEventTypes(strings.Join(types, ",")); err != nil {
+				return err
+			}
+			if serverURL == "" {
+				serverURL = "http://" + cfg.HTTPAddr
+			}
+			endpoint := strings.TrimSuffix(serverURL, "/") + "/v1/events"
+			if len(types) > 0 {
+				endpoint += "?types=" + url.QueryEscape(strings.Join(types, ","))
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+			if err != nil {
+				return fmt.Errorf("building request: %w", err)
+			}
+			req.Header.Set("Accept", "text/event-stream")
+			if token := cmp.Or(cfg.HTTPToken, cfg.HTTPReadToken); token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return fmt.Errorf("connecting to %s (is 'memory serve' running?): %w", serverURL, err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			if resp.StatusCode != http.StatusOK {
+				var body struct {
+					Error string `json:"error"`
+				}
+				_ = json.NewDecoder(resp.Body).Decode(&body)
+				return fmt.Errorf("%s answered %s: %s", serverURL, resp.Status, body.Error)
+			}
+			if !quiet && outputFormat != "json" {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Watching %s for changes (Ctrl-C to stop)\n", serverURL)
+			}
+
+			err = readEventStream(resp.Body, func(event models.Event) {
+				printEvent(cmd, event)
+			}, func(comment string) {
+				if strings.HasPrefix(comment, "dropped") {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s while this terminal fell behind\n", comment)
+				}
+			})
+			if ctx.Err() != nil {
+				return nil // Interrupted
+			}
+			if err != nil {
+				return fmt.Errorf("reading events: %w", err)
+			}
+			return fmt.Errorf("the server closed the event stream")
+		},
+	}
+
+	cmd.Flags().StringVar(&serverURL, "url", "", "Server to watch (default http://<http_addr>)")
+	cmd.Flags().StringSliceVar(&types, "type", nil, "Only these event types, comma-separated (default all)")
+
+	return cmd
+}
+
+// readEventStream reads server-sent events, calling onEvent with each decoded event
+// and onComment with each comment line, until the stream ends
+func readEventStream(body io.Reader, onEvent func(models.Event), onComment func(string)) error {
+	lines := bufio.NewScanner(body)
+	lines.Buffer(make([]byte, 64<<10), 1<<20)
+	var data strings.Builder
+	for lines.Scan() {
+		line := lines.Text()
+		switch {
+		case line == "":
+			if data.Len() > 0 {
+				var event models.Event
+				if err := json.Unmarshal([]byte(data.String()), &event); err != nil {
+					return fmt.Errorf("decoding event: %w", err)
+				}
+				onEvent(event)
+				data.Reset()
+			}
+		case strings.HasPrefix(line, ":"):
+			onComment(strings.TrimSpace(strings.TrimPrefix(line, ":")))
+		case strings.HasPrefix(line, "data:"):
+			data.WriteString(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		}
+	}
+	return lines.Err()
+}
+
+// printEvent writes one event as a line of text, or of JSON with --format json
+func printEvent(cmd *cobra.Command, event models.Event) {
+	out := cmd.OutOrStdout()
+	if outputFormat == "json" {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		_, _ = fmt.Fprintf(out, "%s\n", data)
+		return
+	}
+	_, _ = fmt.Fprintf(out, "%s  %-17s  %s\n", event.Time.In(displayLocation).Format(watchTimeLayout), event.Type, describeEvent(event))
+}
+
+// describeEvent says in a few words what an event changed
+func describeEvent(event models.Event) string {
+	switch event.Type {
+	case models.EventTurnStored:
+		return fmt.Sprintf("%s in %s", event.TurnID, event.BlockID)
+	case models.EventFactAdded:
+		if event.Fact != nil {
+			return fmt.Sprintf("%s = %s", event.Fact.Key, eventFactValue(event.Fact))
+		}
+	case models.EventFactSuperseded, models.EventConflictDetected:
+		if event.Fact != nil && event.Previous != nil {
+			desc := fmt.Sprintf("%s = %s (was %s)", event.Fact.Key, eventFactValue(event.Fact), eventFactValue(event.Previous))
+			if event.Type == models.EventConflictDetected {
+				desc += fmt.Sprintf(", %s overrode %s", event.Fact.Agent, event.Previous.Agent)
+			}
+			return desc
+		}
+	case models.EventTopicArchived, models.EventTopicUpdated:
+		return fmt.Sprintf("%s is %s", event.BlockID, strings.ToLower(event.Status))
+	case models.EventTopicDeleted:
+		return event.BlockID
+	case models.EventProfileUpdated:
+		if event.Persona != "" {
+			return "persona " + event.Persona
+		}
+		return "shared profile"
+	}
+	return event.BlockID
+}
+
+// eventFactValue is a fact's value as watch shows it, masking secrets
+func eventFactValue(f *models.EventFact) string {
+	if f.ValueType == models.ValueTypeSecret {
+		return "********"
+	}
+	return f.Value
+}
@@ -0,0 +1,89 @@
+// ABOUTME: Tests for the watch command
+// ABOUTME: Streams canned server-sent events and checks text and JSON output, the token, and type filters
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestWatchCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("MEMORY_TIMEZONE", "UTC")
+	t.Setenv("MEMORY_HTTP_TOKEN", "watch-token")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	at := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	stream := []models.Event{
+		{ID: "evt_1", Type: models.EventTurnStored, Time: at, BlockID: "block_1", TurnID: "turn_1"},
+		{ID: "evt_2", Type: models.EventFactSuperseded, Time: at, BlockID: "block_1",
+			Fact:     &models.EventFact{FactID: "fact_2", Key: "api_token", ValueType: models.ValueTypeSecret},
+			Previous: &models.EventFact{FactID: "fact_1", Key: "api_token", ValueType: models.ValueTypeSecret}},
+		{ID: "evt_3", Type: models.EventProfileUpdated, Time: at, Persona: "work"},
+	}
+	var gotAuth, gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotQuery = r.Header.Get("Authorization"), r.URL.RawQuery
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, ": keep-alive\n\n")
+		for _, event := range stream {
+			data, _ := json.Marshal(event)
+			_, _ = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		}
+	}))
+	defer server.Close()
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		out := &bytes.Buffer{}
+		root := NewRootCmd()
+		root.SetOut(out)
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("watch", "--url", server.URL, "--type", "turn_stored,fact_superseded,profile_updated")
+	if err == nil || !strings.Contains(err.Error(), "closed the event stream") {
+		t.Errorf("watch error = %v, want the closed stream reported", err)
+	}
+	if gotAuth != "Bearer watch-token" || gotQuery != "types=turn_stored%2Cfact_superseded%2Cprofile_updated" {
+		t.Errorf("request had Authorization %q and query %q", gotAuth, gotQuery)
+	}
+	for _, want := range []string{
+		"12:30:00  turn_stored        turn_1 in block_1",
+		"fact_superseded    api_token = ******** (was ********)",
+		"profile_updated    persona work",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("watch output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, _ = run("watch", "--url", server.URL, "--format", "json")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != len(stream) {
+		t.Fatalf("watch --format json printed %d lines, want %d:\n%s", len(lines), len(stream), out)
+	}
+	var event models.Event
+	if err := json.Unmarshal([]byte(lines[2]), &event); err != nil || event.Type != models.EventProfileUpdated || event.Persona != "work" {
+		t.Errorf("last JSON line = %s (%v), want the profile update", lines[2], err)
+	}
+
+	if _, err := run("watch", "--url", server.URL, "--type", "nonsense"); err == nil || !strings.Contains(err.Error(), "unknown event type") {
+		t.Errorf("watch --type nonsense error = %v, want it rejected", err)
+	}
+}
@@ -0,0 +1,214 @@
+// ABOUTME: CLI command to delete all memory data and prove it
+// ABOUTME: With --verify, recounts every table and writes a deletion report signed with the local signing key
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/remember-standalone/internal/signing"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// signedWipeReport is the report file: the report's JSON exactly as signed, with the key that signed it
+type signedWipeReport struct {
+	Report    json.RawMessage `json:"report"`
+	PublicKey string          `json:"public_key"`
+	Signature string          `json:"signature"`
+}
+
+// NewWipeCmd creates the wipe command
+func NewWipeCmd() *cobra.Command {
+	var (
+		verify      bool
+		skipConfirm bool
+		reportPath  string
+		checkReport string
+		publicKey   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "wipe",
+		Short: "Delete all memory data in the current context",
+		Long: `Delete every topic, turn, fact, embedding, attachment, and the profile in
+the current context's database. Memory keeps no data anywhere but this
+database, so nothing remote needs deleting.
+
+The schema is kept so the database stays usable. Deleted pages are vacuumed
+and the write-ahead log truncated, and the key that encrypts secret facts is
+removed. Take a 'memory takeout' first if you may want the data back.
+
+With --verify, wipe counts every table again afterwards and writes a
+deletion report signed with the Ed25519 key in signing.key next to
+config.yaml. The report lists each table's rows before and after; check it
+later with --check-report. A report is only accepted when it was signed by
+this machine's key, or by the key given with --public-key.
+
+Examples:
+  memory takeout ~/memory-backup && memory wipe
+  memory wipe --verify --yes
+  memory wipe --check-report memory-wipe-2026-01-31.json
+  memory wipe --check-report report.json --public-key <base64 key>`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkReport != "" {
+				return runCheckWipeReport(cmd, checkReport, publicKey)
+			}
+			if publicKey != "" {
+				return fmt.Errorf("--public-key only applies with --check-report")
+			}
+
+			store, cfg, err := openStorageWithConfig()
+			if err != nil {
+				return fmt.Errorf("initializing storage: %w", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			// Recording the command would write to the database just wiped
+			skipTelemetry = true
+
+			if !skipConfirm {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Delete all memory data in %s? This cannot be undone. [y/N] ", store.Path())
+				response, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+				if err != nil && response == "" {
+					return fmt.Errorf("failed to read response: %w", err)
+				}
+				response = strings.TrimSpace(strings.ToLower(response))
+				if response != "y" && response != "yes" {
+					_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Wipe cancelled.")
+					return nil
+				}
+			}
+
+			report, err := store.Wipe()
+			if err != nil {
+				return fmt.Errorf("wipe failed: %w", err)
+			}
+			if verify {
+				if err := store.VerifyWipe(report); err != nil {
+					return fmt.Errorf("verifying wipe: %w", err)
+				}
+				if reportPath == "" {
+					reportPath = fmt.Sprintf("memory-wipe-%s.json", time.Now().In(displayLocation).Format("2006-01-02"))
+				}
+				key, err := signing.LoadOrCreate(signingKeyPath(cfg))
+				if err != nil {
+					return err
+				}
+				if err := writeWipeReport(reportPath, report, key); err != nil {
+					return err
+				}
+			}
+
+			if outputFormat == "json" {
+				jsonData, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshaling JSON: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", jsonData)
+			} else if !quiet {
+				printWipeReport(cmd, report, verify)
+				if verify {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Signed report: %s\n", reportPath)
+				}
+			}
+
+			if verify && !report.Verified {
+				return fmt.Errorf("wipe could not be verified: %d rows remain, secret key removed: %t", report.RemainingTotal, report.SecretKeyGone)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&verify, "verify", false, "Recount every table after the wipe and write a signed deletion report")
+	cmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip confirmation prompt")
+	cmd.Flags().StringVar(&reportPath, "report", "", "Deletion report path for --verify (default memory-wipe-<date>.json)")
+	cmd.Flags().StringVar(&checkReport, "check-report", "", "Check the signature of a deletion report instead of wiping")
+	cmd.Flags().StringVar(&publicKey, "public-key", "", "Base64 public key --check-report requires the report to be signed with (default: this machine's signing key)")
+
+	return cmd
+}
+
+// printWipeReport shows each table's rows before and after
+func printWipeReport(cmd *cobra.Command, report *storage.WipeReport, verified bool) {
+	out := cmd.OutOrStdout()
+	var deleted int64
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "TABLE\tDELETED\tREMAINING\n")
+	for _, table := range report.Tables {
+		deleted += table.Deleted
+		remaining := "-"
+		if verified {
+			remaining = fmt.Sprintf("%d", table.Remaining)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%s\n", table.Name, table.Deleted, remaining)
+	}
+	_ = w.Flush()
+
+	_, _ = fmt.Fprintf(out, "✓ Wiped %d rows from %s\n", deleted, report.Database)
+	if report.Verified {
+		_, _ = fmt.Fprintln(out, "✓ Verified: every table is empty and the secret key is gone")
+	}
+}
+
+// writeWipeReport signs the report's JSON and writes it with the signature
+func writeWipeReport(path string, report *storage.WipeReport, key *signing.Key) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshaling report: %w", err)
+	}
+	signed, err := json.Marshal(signedWipeReport{Report: data, PublicKey: key.PublicKey(), Signature: key.Sign(data)})
+	if err != nil {
+		return fmt.Errorf("marshaling report: %w", err)
+	}
+	if err := os.WriteFile(path, append(signed, '\n'), 0600); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	return nil
+}
+
+// runCheckWipeReport verifies a report's signature and summarizes it. The signer
+// must be publicKey when one is given, else this machine's signing key: the key
+// in the report proves nothing alone, since anyone can re-sign an edited report.
+func runCheckWipeReport(cmd *cobra.Command, path, publicKey string) error {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is given by the user
+	if err != nil {
+		return fmt.Errorf("reading report: %w", err)
+	}
+	var signed signedWipeReport
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return fmt.Errorf("parsing report: %w", err)
+	}
+	if err := signing.Verify(signed.PublicKey, signed.Signature, signed.Report); err != nil {
+		return fmt.Errorf("report %s is not authentic: %w", path, err)
+	}
+	signer := "this machine's signing key"
+	if publicKey != "" {
+		if signed.PublicKey != publicKey {
+			return fmt.Errorf("report %s is not authentic: signed by key %s, not the expected %s", path, signed.PublicKey, publicKey)
+		}
+		signer = "the expected key"
+	} else if !isLocalSigningKey(signed.PublicKey) {
+		return fmt.Errorf("report %s is not authentic: signed by key %s, not this machine's signing key (pass --public-key to check another machine's report)", path, signed.PublicKey)
+	}
+	var report storage.WipeReport
+	if err := json.Unmarshal(signed.Report, &report); err != nil {
+		return fmt.Errorf("parsing report: %w", err)
+	}
+
+	if !quiet {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "✓ Signature valid (key %s, %s)\n", signed.PublicKey, signer)
+		printWipeReport(cmd, &report, true)
+	}
+	if !report.Verified {
+		return fmt.Errorf("report %s records a wipe that was not verified", path)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// ABOUTME: Tests for the takeout and wipe commands
+// ABOUTME: Verifies the archive, confirmation, the verified wipe, and checking the signed report
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/signing"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+func TestTakeoutAndWipeCmds(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MEMORY_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+	t.Setenv("MEMORY_CONTEXT", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	run := func(stdin string, args ...string) (string, error) {
+		t.Helper()
+		root := NewRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetIn(strings.NewReader(stdin))
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	store, err := openStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_wipe_cmd", Timestamp: time.Now(), UserMessage: "keep a copy"}); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	takeoutDir := filepath.Join(dir, "takeout")
+	if out, err := run("", "takeout", takeoutDir); err != nil || !strings.Contains(out, "✓ Took out 1 topics, 1 turns") {
+		t.Fatalf("takeout = %q, %v", out, err)
+	}
+	if _, err := os.Stat(filepath.Join(takeoutDir, "manifest.json")); err != nil {
+		t.Errorf("takeout wrote no manifest: %v", err)
+	}
+
+	if out, err := run("n\n", "wipe"); err != nil || !strings.Contains(out, "Wipe cancelled.") {
+		t.Fatalf("wipe answered no = %q, %v; want it cancelled", out, err)
+	}
+
+	reportPath := filepath.Join(dir, "wipe-report.json")
+	out, err := run("y\n", "wipe", "--verify", "--report", reportPath)
+	if err != nil {
+		t.Fatalf("wipe --verify: %v\n%s", err, out)
+	}
+	for _, want := range []string{"turns", "✓ Wiped", "✓ Verified", "Signed report: " + reportPath} {
+		if !strings.Contains(out, want) {
+			t.Errorf("wipe --verify = %q, want it to contain %q", out, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, signing.FileName)); err != nil {
+		t.Errorf("signing key not created next to config.yaml: %v", err)
+	}
+
+	if out, err := run("", "wipe", "--check-report", reportPath); err != nil || !strings.Contains(out, "✓ Signature valid") {
+		t.Errorf("wipe --check-report = %q, %v; want a valid signature", out, err)
+	}
+
+	report, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(report), `"deleted":1`, `"deleted":0`, 1)
+	if tampered == string(report) {
+		t.Fatal("report has no deleted count to tamper with")
+	}
+	if err := os.WriteFile(reportPath, []byte(tampered), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("", "wipe", "--check-report", reportPath); err == nil || !strings.Contains(err.Error(), "not authentic") {
+		t.Errorf("wipe --check-report on a tampered report = %v, want it rejected", err)
+	}
+
+	// Re-signing the edited report with another key does not make it authentic
+	var signed signedWipeReport
+	if err := json.Unmarshal([]byte(tampered), &signed); err != nil {
+		t.Fatal(err)
+	}
+	other, err := signing.LoadOrCreate(filepath.Join(dir, "other", signing.FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeWipeReport(reportPath, mustWipeReport(t, signed.Report), other); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("", "wipe", "--check-report", reportPath); err == nil || !strings.Contains(err.Error(), "not this machine's signing key") {
+		t.Errorf("wipe --check-report on a report re-signed with another key = %v, want it rejected", err)
+	}
+	if out, err := run("", "wipe", "--check-report", reportPath, "--public-key", other.PublicKey()); err != nil || !strings.Contains(out, "the expected key") {
+		t.Errorf("wipe --check-report --public-key = %q, %v; want the other key accepted", out, err)
+	}
+	if _, err := run("", "wipe", "--check-report", reportPath, "--public-key", "AAAA"); err == nil || !strings.Contains(err.Error(), "not the expected") {
+		t.Errorf("wipe --check-report with the wrong --public-key = %v, want it rejected", err)
+	}
+}
+
+// mustWipeReport decodes a report's JSON
+func mustWipeReport(t *testing.T, data []byte) *storage.WipeReport {
+	t.Helper()
+	var report storage.WipeReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	return &report
+}
@@ -4,9 +4,11 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"os"
 
 	"github.com/harper/remember-standalone/cmd/memory/commands"
+	"github.com/harper/remember-standalone/internal/redact"
 )
 
 // Version information (set by goreleaser)
@@ -20,8 +22,11 @@ func main() {
 	// Set version info for commands
 	commands.SetVersion(version, commit, date)
 
+	// Mask credentials in log lines and errors
+	log.SetOutput(redact.NewWriter(os.Stderr))
+
 	if err := commands.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %s\n", redact.String(err.Error()))
 		os.Exit(1)
 	}
 }
@@ -4,38 +4,161 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/harper/remember-standalone/internal/config"
 	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/diagnostics"
+	"github.com/harper/remember-standalone/internal/events"
+	"github.com/harper/remember-standalone/internal/health"
 	"github.com/harper/remember-standalone/internal/llm"
 	"github.com/harper/remember-standalone/internal/mcp"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/redact"
 	"github.com/harper/remember-standalone/internal/storage"
+	"github.com/harper/remember-standalone/internal/telemetry"
+	"github.com/harper/remember-standalone/internal/tracing"
 	"github.com/joho/godotenv"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 )
 
 func main() {
+	readOnly := flag.Bool("read-only", false, "Expose only retrieval tools and reject writes (overrides read_only in config)")
+	dataDir := flag.String("data-dir", "", "Data directory holding the databases (overrides data_dir in config)")
+	healthAddr := flag.String("health-addr", "", "Serve /healthz on this host:port (overrides health_addr in config)")
+	debugAddr := flag.String("debug-addr", "", "Serve pprof and expvar on this host:port (overrides debug_addr in config)")
+	flag.Parse()
+
+	// Mask credentials in every log line, including debug logging from dependencies
+	log.SetOutput(redact.NewWriter(os.Stderr))
+
 	// Load .env file if it exists (for API keys)
 	if err := godotenv.Load(); err != nil {
 		log.Printf("No .env file found (this is okay for production): %v", err)
 	}
 
-	// Verify we have required API keys
-	if os.Getenv("OPENAI_API_KEY") == "" {
+	// Load config file with environment overrides
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if *dataDir != "" {
+		if err := cfg.Override("data_dir", *dataDir); err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+	}
+	if *healthAddr != "" {
+		if err := cfg.Override("health_addr", *healthAddr); err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+	}
+	if *debugAddr != "" {
+		if err := cfg.Override("debug_addr", *debugAddr); err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+	}
+
+	// Verify we have required API keys (environment or keychain)
+	if cfg.OpenAIKey == "" {
 		log.Println("Warning: OPENAI_API_KEY not set - embeddings and LLM features will not work")
 	}
+	if err := cfg.CheckContext(); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	readOnlyFlagSet := false
+	flag.Visit(func(f *flag.Flag) { readOnlyFlagSet = readOnlyFlagSet || f.Name == "read-only" })
+	if !readOnlyFlagSet {
+		*readOnly = cfg.ReadOnly
+	}
 
-	// Initialize storage with XDG-compliant paths
-	store, err := storage.NewStorage()
+	// Refuse an unwritable data directory and warn about network filesystems
+	status := config.CheckDataDir(cfg.ContextDir(cfg.Context))
+	if warning := status.Warning(); warning != "" {
+		log.Printf("Warning: %s", warning)
+	}
+	if err := status.Problem(); err != nil && !*readOnly {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	// Initialize storage at the configured (XDG by default) path
+	var store *storage.Storage
+	if *readOnly {
+		store, err = storage.NewStorageReadOnly(cfg.DBPath())
+	} else {
+		store, err = storage.NewStorageWithPath(cfg.DBPath())
+	}
 	if err != nil {
 		log.Fatalf("Failed to initialize storage: %v", err)
 	}
+	store.SetLocation(cfg.Location)
+	store.SetSlowQueryThreshold(cfg.SlowQueryThreshold)
+	store.SetFactReview(cfg.FactReview)
+	store.SetUndoLogSize(cfg.UndoLogSize)
+	store.SetFactLimits(cfg.FactLimits())
+	store.SetTopicTaxonomy(cfg.TopicTaxonomy)
+
+	// Queue a webhook delivery for each memory event; the job queue sends them
+	var webhooks *events.Webhooks
+	if len(cfg.WebhookURLs) > 0 && !*readOnly {
+		webhooks = events.NewWebhooks(cfg.WebhookURLs, cfg.WebhookEvents, cfg.WebhookSecret)
+		webhooks.Attach(store)
+	}
+
+	// Check the database before serving it, repairing what can be repaired safely
+	selfCheck := core.RunSelfCheck(store, !*readOnly)
+	selfCheck.Log()
+
+	// Run the built-in tasks listed in schedule at their times
+	scheduler := core.NewScheduler(store, cfg.Location)
+	for _, entry := range cfg.Schedule {
+		scheduler.Schedule(entry.Task, entry.Cron)
+	}
+
+	// Keep the database under max_db_size, enforce retention policies, and compact old
+	// archived topics, unless pruning runs on a schedule
+	pruner := core.NewPruner(store, cfg.MaxDBSize, cfg.ArchiveRetention)
+	pruner.SetCompaction(cfg.CompactAfter, cfg.CompactPolicy)
+	if !*readOnly && !scheduler.Scheduled(models.TaskPrune) {
+		pruner.Start(core.DefaultPruneInterval)
+	}
+
+	// Keep the Markdown vault in vault_dir in step with memory, unless it syncs on a schedule
+	vault := core.NewVaultSyncer(store, cfg.VaultDir, storage.ExportOptions{})
+	if cfg.VaultDir != "" && (*readOnly || !scheduler.Scheduled(models.TaskSync)) {
+		vault.Start(core.DefaultVaultInterval)
+	}
+
+	// Opt-in telemetry counts tool calls; read-only servers never write, so they skip it
+	var onToolCall func(string)
+	if tel := telemetry.New(cfg.Telemetry, cfg.TelemetryEndpoint, "server", store); tel.Enabled() && !*readOnly {
+		onToolCall = func(tool string) { _ = tel.Record("mcp." + tool) }
+		go func() {
+			if _, err := tel.Report(); err != nil {
+				log.Printf("Warning: telemetry: %v", err)
+			}
+		}()
+	}
+
+	// Trace tool calls to an OpenTelemetry collector when one is configured
+	shutdownTracing := tracing.Setup(cfg.OTLPEndpoint, "memory", "server")
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Warning: flushing traces: %v", err)
+		}
+	}()
 
 	// Initialize Governor for smart routing
 	governor := core.NewGovernor(store)
+	governor.SetTopicMatchThreshold(cfg.TopicMatchThreshold)
+	governor.SetResumptionWindow(cfg.ResumptionWindow())
 
 	// Initialize ChunkEngine for hierarchical chunking
 	chunkEngine := core.NewChunkEngine()
@@ -43,32 +166,97 @@ func main() {
 	// Initialize OpenAI client and Scribe for user profile learning (optional - only if API key is set)
 	var scribe *core.Scribe
 	var openaiClient *llm.OpenAIClient
-	if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
-		client, err := llm.NewOpenAIClient(apiKey)
+	if cfg.OpenAIKey != "" {
+		client, err := llm.NewOpenAIClientWithConfig(llm.ConfigFromSettings(cfg))
 		if err != nil {
 			log.Printf("Warning: Failed to initialize OpenAI client: %v", err)
 		} else {
 			openaiClient = client
-			scribe = core.NewScribe(openaiClient)
+			// Read-only servers never write, not even usage records or learned profile updates
+			if !*readOnly {
+				openaiClient.SetUsageRecorder(store)
+				scribe = core.NewScribe(openaiClient)
+			}
 			log.Println("OpenAI client and Scribe agent initialized")
 		}
 	}
+	if cfg.RoutingMode == "embedding" {
+		if openaiClient != nil {
+			governor.SetEmbedder(openaiClient, cfg.SemanticThreshold)
+		} else {
+			log.Println("Warning: routing_mode embedding needs an OpenAI API key - routing on keywords")
+		}
+	}
+
+	// Read-only servers never write, so they run no scheduled tasks
+	if !*readOnly {
+		builtins := core.BuiltinTasks{Pruner: pruner, DigestDir: cfg.DigestDir(), ReviewAfter: cfg.ProfileReviewAge()}
+		if cfg.VaultDir != "" {
+			builtins.Vault = vault
+		}
+		if openaiClient != nil {
+			builtins.Embedder = openaiClient
+		}
+		scheduler.HandleBuiltins(builtins)
+		scheduler.Start(core.DefaultScheduleInterval)
+	}
+
+	// Serve /healthz for supervisors when an address is configured
+	stopHealth := func() {}
+	if cfg.HealthAddr != "" {
+		stop, err := health.Serve(cfg.HealthAddr, health.NewChecker(store, openaiClient))
+		if err != nil {
+			log.Fatalf("Failed to start health checks: %v", err)
+		}
+		stopHealth = stop
+		log.Printf("Health checks on http://%s/healthz", cfg.HealthAddr)
+	}
+
+	// Serve pprof and expvar for diagnosing leaks when a debug address is configured
+	stopDebug := func() {}
+	if cfg.DebugAddr != "" {
+		stop, err := diagnostics.Serve(cfg.DebugAddr, store)
+		if err != nil {
+			log.Fatalf("Failed to start debug endpoints: %v", err)
+		}
+		stopDebug = stop
+		log.Printf("Debug endpoints on http://%s/debug/pprof/", cfg.DebugAddr)
+	}
 
 	// Create MCP server
+	// Tell each client at initialize which context preset retrieve_memory applies to it
+	hooks := &mcpserver.Hooks{}
+	mcp.AdvertiseContextPresets(hooks, cfg.ContextPresets)
 	server := mcpserver.NewMCPServer(
 		"HMLR Memory System",
 		"0.1.0",
+		mcpserver.WithHooks(hooks),
 	)
 
 	// Register MCP tools and get handlers for shutdown
-	handlers := mcp.RegisterTools(server, store, governor, chunkEngine, scribe, openaiClient)
+	handlers := mcp.RegisterTools(server, store, governor, chunkEngine, scribe, openaiClient, mcp.Options{
+		DefaultMaxResults: cfg.RetrievalK,
+		DefaultPersona:    cfg.Persona,
+		JobWorkers:        cfg.JobWorkers,
+		ProfileQueueLimit: cfg.ProfileQueueLimit,
+		AgentID:           cfg.AgentID,
+		ReadOnly:          *readOnly,
+		OnToolCall:        onToolCall,
+		Webhooks:          webhooks,
+		ContextPresets:    cfg.ContextPresets,
+		DisabledTools:     cfg.DisabledTools,
+	})
 
 	// Setup graceful shutdown
 	ctx, stop := signal.NotifyContext(context.Background(),
 		os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	log.Println("HMLR MCP server starting on stdio...")
+	if *readOnly {
+		log.Println("HMLR MCP server starting on stdio (read-only)...")
+	} else {
+		log.Println("HMLR MCP server starting on stdio...")
+	}
 
 	// Start server in goroutine
 	serverErr := make(chan error, 1)
@@ -81,7 +269,12 @@ func main() {
 	case <-ctx.Done():
 		log.Println("Shutdown signal received, gracefully shutting down...")
 
-		// Wait for all async Scribe operations to complete
+		// Let running background jobs finish; queued ones wait for the next start
+		stopHealth()
+		stopDebug()
+		pruner.Stop()
+		vault.Stop()
+		scheduler.Stop()
 		handlers.Shutdown()
 
 		// Close storage (flushes pending writes, closes DB)
@@ -92,6 +285,17 @@ func main() {
 		log.Println("Shutdown complete")
 
 	case err := <-serverErr:
+		// The client hung up: still stop the job workers and close storage, which
+		// saves cache stats
+		stopHealth()
+		stopDebug()
+		pruner.Stop()
+		vault.Stop()
+		scheduler.Stop()
+		handlers.Shutdown()
+		if closeErr := store.Close(); closeErr != nil {
+			log.Printf("Warning: Error closing storage: %v", closeErr)
+		}
 		if err != nil {
 			log.Fatalf("Server error: %v", err)
 		}
@@ -0,0 +1,345 @@
+// ABOUTME: OpenAI-compatible chat completions endpoint that gives any OpenAI client persistent memory
+// ABOUTME: Adds related memories, facts, and the profile to each request, forwards it upstream, and stores the exchange
+package chatproxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+const (
+	maxBodyBytes = 16 << 20 // Chat requests carry whole conversations, sometimes with images
+
+	// DefaultBaseURL is the OpenAI API that requests are forwarded to
+	DefaultBaseURL = "https://api.openai.com/v1"
+
+	// DefaultContextTokens bounds the memory added to a request when Options gives no budget
+	DefaultContextTokens = 1500
+
+	// PersonaHeader selects the profile persona for a request, overriding the default
+	PersonaHeader = "X-Memory-Persona"
+
+	memoryPreamble = "What you remember about the user from earlier conversations follows. " +
+		"Use it where it helps; do not mention it otherwise.\n\n"
+)
+
+// Embedder generates the query embedding used to find related memories
+type Embedder interface {
+	GenerateEmbedding(text string) ([]float64, error)
+}
+
+// Options configure the proxy
+type Options struct {
+	APIKey         string              // provider key sent upstream; clients' own keys are not forwarded
+	BaseURL        string              // provider API base URL; DefaultBaseURL when empty
+	HTTPClient     *http.Client        // client for upstream requests; http.DefaultClient when nil
+	Embedder       Embedder            // enables related memories; leave nil, not a nil client, without one
+	DefaultPersona string              // profile persona used when a request sends no PersonaHeader
+	ContextTokens  int                 // memory budget per request; DefaultContextTokens when zero
+	Facts          *core.FactWeighting // how memory context chooses facts; core.DefaultFactWeighting when nil
+}
+
+// Proxy serves POST /v1/chat/completions and GET /v1/models
+type Proxy struct {
+	tools *mcpserver.MCPServer
+	store *storage.Storage
+	opts  Options
+}
+
+// New creates a proxy that reads memory from store and stores exchanges with the
+// store_conversation tool registered on tools; a read-only server stores nothing
+func New(tools *mcpserver.MCPServer, store *storage.Storage, opts Options) *Proxy {
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultBaseURL
+	}
+	opts.BaseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	if opts.ContextTokens <= 0 {
+		opts.ContextTokens = DefaultContextTokens
+	}
+	return &Proxy{tools: tools, store: store, opts: opts}
+}
+
+// ServeHTTP answers chat completions with memory, and passes the model list through
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/chat/completions":
+		p.chat(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/models":
+		resp, err := p.upstream(r.Context(), http.MethodGet, "/models", nil)
+		if err != nil {
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+		_, _ = relay(w, resp)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// message is the part of a chat message the proxy reads
+type message struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// chat adds memory to a chat completion request, forwards it, relays the answer, and
+// stores the user's last message with the reply
+func (p *Proxy) chat(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("failed to read request: %v", err))
+		return
+	}
+	var request map[string]json.RawMessage
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "request body must be a JSON object")
+		return
+	}
+	var messages []json.RawMessage
+	if err := json.Unmarshal(request["messages"], &messages); err != nil {
+		writeError(w, http.StatusBadRequest, "messages must be an array")
+		return
+	}
+
+	persona := r.Header.Get(PersonaHeader)
+	if persona == "" {
+		persona = p.opts.DefaultPersona
+	}
+	userText := lastUserText(messages)
+	if userText != "" {
+		hydrator := core.NewContextHydrator(p.store, p.opts.Embedder)
+		hydrator.SetPersona(persona)
+		if p.opts.Facts != nil {
+			hydrator.SetFactWeighting(*p.opts.Facts)
+		}
+		if memory := hydrator.HydrateMemory(userText, p.opts.ContextTokens); memory != "" {
+			request["messages"], _ = json.Marshal(withSystemMessage(messages, memoryPreamble+memory))
+			body, _ = json.Marshal(request)
+		}
+	}
+
+	resp, err := p.upstream(r.Context(), http.MethodPost, "/chat/completions", body)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	reply, err := relay(w, resp)
+	if err != nil {
+		log.Printf("[Proxy] relaying the response: %v", err)
+		return
+	}
+
+	if resp.StatusCode/100 == 2 && userText != "" && reply != "" {
+		p.storeExchange(context.WithoutCancel(r.Context()), userText, reply, persona)
+	}
+}
+
+// lastUserText returns the text of the last user message, or "" if there is none
+func lastUserText(messages []json.RawMessage) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		var m message
+		if json.Unmarshal(messages[i], &m) == nil && m.Role == "user" {
+			return contentText(m.Content)
+		}
+	}
+	return ""
+}
+
+// contentText returns a message's text: the content string, or its text parts joined
+func contentText(content json.RawMessage) string {
+	var text string
+	if json.Unmarshal(content, &text) == nil {
+		return text
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(content, &parts) != nil {
+		return ""
+	}
+	var texts []string
+	for _, part := range parts {
+		if part.Type == "text" && part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// withSystemMessage inserts a system message after the leading system and developer
+// messages, so the client's own instructions stay first
+func withSystemMessage(messages []json.RawMessage, content string) []json.RawMessage {
+	at := 0
+	for ; at < len(messages); at++ {
+		var m message
+		if json.Unmarshal(messages[at], &m) != nil || (m.Role != "system" && m.Role != "developer") {
+			break
+		}
+	}
+	inserted, _ := json.Marshal(map[string]string{"role": "system", "content": content})
+	result := make([]json.RawMessage, 0, len(messages)+1)
+	result = append(result, messages[:at]...)
+	result = append(result, inserted)
+	return append(result, messages[at:]...)
+}
+
+// upstream sends a request to the provider with the configured API key
+func (p *Proxy) upstream(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.opts.BaseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upstream request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.opts.APIKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := p.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the provider: %w", err)
+	}
+	return resp, nil
+}
+
+// relay copies an upstream response to the client and returns the assistant's reply.
+// Event streams are passed on line by line as they arrive.
+func relay(w http.ResponseWriter, resp *http.Response) (string, error) {
+	for _, name := range []string{"Content-Type", "Cache-Control", "X-Request-Id", "Openai-Processing-Ms"} {
+		if value := resp.Header.Get(name); value != "" {
+			w.Header().Set(name, value)
+		}
+	}
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			writeError(w, http.StatusBadGateway, fmt.Sprintf("failed to read the provider's response: %v", err))
+			return "", err
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.WriteHeader(resp.StatusCode)
+		if _, err := w.Write(data); err != nil {
+			return "", err
+		}
+		return completionText(data), nil
+	}
+
+	w.WriteHeader(resp.StatusCode)
+	flusher := http.NewResponseController(w)
+	var reply strings.Builder
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if _, werr := io.WriteString(w, line); werr != nil {
+				return "", werr
+			}
+			_ = flusher.Flush()
+			reply.WriteString(chunkText(line))
+		}
+		if errors.Is(err, io.EOF) {
+			return reply.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// completionText returns the first choice's message content in a chat completion
+func completionText(data []byte) string {
+	var completion struct {
+		Choices []struct {
+			Message message `json:"message"`
+		} `json:"choices"`
+	}
+	if json.Unmarshal(data, &completion) != nil || len(completion.Choices) == 0 {
+		return ""
+	}
+	return contentText(completion.Choices[0].Message.Content)
+}
+
+// chunkText returns the first choice's content delta in one "data:" line of a stream
+func chunkText(line string) string {
+	payload, ok := strings.CutPrefix(strings.TrimSpace(line), "data:")
+	if !ok {
+		return ""
+	}
+	var chunk struct {
+		Choices []struct {
+			Delta struct {
+				Content string `json:"content"`
+			} `json:"delta"`
+		} `json:"choices"`
+	}
+	if json.Unmarshal([]byte(strings.TrimSpace(payload)), &chunk) != nil || len(chunk.Choices) == 0 {
+		return ""
+	}
+	return chunk.Choices[0].Delta.Content
+}
+
+// storeExchange stores the user's message and the reply as a turn with the
+// store_conversation tool, so it is routed, enriched, and published like any other
+func (p *Proxy) storeExchange(ctx context.Context, userText, reply, persona string) {
+	tool := p.tools.GetTool("store_conversation")
+	if tool == nil {
+		return // served read-only, or the tool is disabled
+	}
+	args := map[string]any{
+		"messages": []any{
+			map[string]any{"role": "user", "content": userText},
+			map[string]any{"role": "assistant", "content": reply},
+		},
+	}
+	if persona != "" {
+		args["persona"] = persona
+	}
+	var request mcp.CallToolRequest
+	request.Params.Name = "store_conversation"
+	request.Params.Arguments = args
+	result, err := tool.Handler(ctx, request)
+	if err != nil {
+		log.Printf("[Proxy] failed to store the exchange: %v", err)
+		return
+	}
+	if result.IsError {
+		for _, content := range result.Content {
+			if text, ok := content.(mcp.TextContent); ok {
+				log.Printf("[Proxy] failed to store the exchange: %s", text.Text)
+			}
+		}
+	}
+}
+
+// writeError writes an error in the shape OpenAI clients expect
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]string{"message": message, "type": "memory_proxy_error"},
+	})
+}
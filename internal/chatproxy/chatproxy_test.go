@@ -0,0 +1,174 @@
+// ABOUTME: Tests for the OpenAI-compatible chat proxy
+// ABOUTME: Verifies memory reaches the provider, answers are relayed streamed and whole, and exchanges are stored
+
+package chatproxy
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/mcp"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// provider is a fake OpenAI API that records the requests it receives
+type provider struct {
+	mu       sync.Mutex
+	requests []map[string]any
+	auth     []string
+	stream   bool
+}
+
+func (p *provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var request map[string]any
+	_ = json.Unmarshal(body, &request)
+	p.mu.Lock()
+	p.requests = append(p.requests, request)
+	p.auth = append(p.auth, r.Header.Get("Authorization"))
+	p.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/models":
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"object":"list","data":[{"id":"gpt-test"}]}`)
+	case p.stream:
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{"You use ", "helix."} {
+			_, _ = io.WriteString(w, `data: {"choices":[{"delta":{"content":"`+chunk+`"}}]}`+"\n\n")
+		}
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id":"chatcmpl-1","choices":[{"message":{"role":"assistant","content":"You use helix."}}]}`)
+	}
+}
+
+// newTestProxy serves a proxy over an in-memory store holding one fact, forwarding to upstream
+func newTestProxy(t *testing.T, readOnly bool, upstream *httptest.Server) (*Proxy, *storage.Storage) {
+	t.Helper()
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory: %v", err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_editor", Key: "editor", Value: "helix", Confidence: 1}); err != nil {
+		t.Fatalf("SaveFact: %v", err)
+	}
+	server := mcpserver.NewMCPServer("test", "0.0.0")
+	handlers := mcp.RegisterTools(server, store, core.NewGovernor(store), core.NewChunkEngine(), nil, nil, mcp.Options{ReadOnly: readOnly})
+	t.Cleanup(func() {
+		handlers.Shutdown()
+		_ = store.Close()
+	})
+	return New(server, store, Options{APIKey: "sk-upstream", BaseURL: upstream.URL + "/", HTTPClient: upstream.Client()}), store
+}
+
+const chatRequest = `{"model":"gpt-test","messages":[
+	{"role":"system","content":"Be brief."},
+	{"role":"user","content":[{"type":"text","text":"editor"}]}]}`
+
+func chat(t *testing.T, p *Proxy, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer client-token")
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+	return rec
+}
+
+// storedTurns returns the user message and response of every stored turn
+func storedTurns(t *testing.T, store *storage.Storage) []string {
+	t.Helper()
+	blocks, err := store.GetActiveBridgeBlocks()
+	if err != nil {
+		t.Fatalf("GetActiveBridgeBlocks: %v", err)
+	}
+	var turns []string
+	for _, summary := range blocks {
+		block, err := store.GetBridgeBlock(summary.BlockID)
+		if err != nil {
+			t.Fatalf("GetBridgeBlock: %v", err)
+		}
+		for _, turn := range block.Turns {
+			turns = append(turns, turn.UserMessage+" => "+turn.AIResponse)
+		}
+	}
+	return turns
+}
+
+func TestChat_AddsMemoryAndStoresTheExchange(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		up := &provider{stream: stream}
+		upstream := httptest.NewServer(up)
+		p, store := newTestProxy(t, false, upstream)
+
+		rec := chat(t, p, chatRequest)
+		upstream.Close()
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "helix.") {
+			t.Fatalf("stream=%v: response = %d %s", stream, rec.Code, rec.Body.String())
+		}
+		if stream && !strings.HasSuffix(rec.Body.String(), "data: [DONE]\n\n") {
+			t.Errorf("stream was not relayed whole: %q", rec.Body.String())
+		}
+
+		if len(up.requests) != 1 || up.auth[0] != "Bearer sk-upstream" {
+			t.Fatalf("provider got %d requests, auth %v", len(up.requests), up.auth)
+		}
+		messages, _ := up.requests[0]["messages"].([]any)
+		if len(messages) != 3 || up.requests[0]["model"] != "gpt-test" {
+			t.Fatalf("forwarded request = %v, want the memory message added", up.requests[0])
+		}
+		memory, _ := messages[1].(map[string]any)
+		if content, _ := memory["content"].(string); memory["role"] != "system" || !strings.Contains(content, "editor: helix") {
+			t.Errorf("second message = %v, want a system message with the fact", memory)
+		}
+
+		if turns := storedTurns(t, store); len(turns) != 1 || turns[0] != "editor => You use helix." {
+			t.Errorf("stream=%v: stored turns = %q", stream, turns)
+		}
+	}
+}
+
+func TestChat_ReadOnlyStoresNothing(t *testing.T) {
+	upstream := httptest.NewServer(&provider{})
+	defer upstream.Close()
+	p, store := newTestProxy(t, true, upstream)
+
+	if rec := chat(t, p, chatRequest); rec.Code != http.StatusOK {
+		t.Fatalf("response = %d %s", rec.Code, rec.Body.String())
+	}
+	if turns := storedTurns(t, store); len(turns) != 0 {
+		t.Errorf("read-only proxy stored %q", turns)
+	}
+}
+
+func TestChat_ErrorsAndModels(t *testing.T) {
+	upstream := httptest.NewServer(&provider{})
+	p, _ := newTestProxy(t, false, upstream)
+
+	for _, body := range []string{`not json`, `{"messages": "hi"}`} {
+		if rec := chat(t, p, body); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "memory_proxy_error") {
+			t.Errorf("chat(%s) = %d %s, want an OpenAI-style 400", body, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/models", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "gpt-test") {
+		t.Errorf("GET /v1/models = %d %s", rec.Code, rec.Body.String())
+	}
+
+	upstream.Close()
+	if rec := chat(t, p, chatRequest); rec.Code != http.StatusBadGateway {
+		t.Errorf("chat with the provider down = %d %s, want 502", rec.Code, rec.Body.String())
+	}
+}
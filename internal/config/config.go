@@ -1,106 +1,875 @@
 // ABOUTME: Centralized configuration for the memory MCP server
-// ABOUTME: Loads from environment variables with validation and defaults
+// ABOUTME: Merges defaults, an XDG config file, and environment variables with validation
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/harper/remember-standalone/internal/cron"
+	"github.com/harper/remember-standalone/internal/keychain"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/redact"
 )
 
 // Config holds all configuration for the memory system
 type Config struct {
 	// Storage settings (SQLite)
-	DataDir string
+	DataDir            string
+	Context            string               // Named context whose database is used; see ContextDir
+	SlowQueryThreshold time.Duration        // Log storage queries that take at least this long; 0 disables
+	MaxDBSize          int64                // Bytes the database may use before archived blocks are evicted; 0 means no limit
+	ArchiveRetention   time.Duration        // How long archived blocks are kept before eviction may delete them
+	CompactAfter       time.Duration        // Archived blocks untouched this long have their turn bodies compacted; 0 disables
+	CompactPolicy      models.CompactPolicy // Whether compaction keeps a compressed copy of the bodies or drops them
+	UndoLogSize        int                  // Recent deletes and turn edits kept so `memory undo` can revert them; 0 disables
 
-	// OpenAI settings
+	// LLM provider settings
+	Provider       string
 	OpenAIKey      string
 	ChatModel      string
 	EmbeddingModel string
 	Timeout        time.Duration
 	MaxRetries     int
 	RetryDelay     time.Duration
+	Proxy          string // Proxy URL for LLM traffic; empty means HTTPS_PROXY/HTTP_PROXY from the environment
+	NoProxy        string // Hosts that bypass Proxy; empty means NO_PROXY from the environment
 
 	// Memory settings
 	TopicMatchThreshold float64
+	RoutingMode         string  // keywords, or embedding to also match turns against each topic's centroid embedding
+	SemanticThreshold   float64 // Centroid similarity a turn needs to match a topic in embedding routing
 	VectorDimension     int
+	RetrievalK          int                   // Default number of memories returned by search and retrieve_memory
+	Persona             string                // Default profile persona (e.g. "work"); empty means the shared profile only
+	Device              string                // Device name recorded on turns added from this machine; empty means none
+	AgentID             string                // Agent recorded on turns and facts stored by this process; empty means the MCP client's name (none for the CLI)
+	ProfileReviewDays   int                   // Days without reinforcement after which profile preferences and topics come up for review
+	ResumptionDays      int                   // Days a paused topic may sit idle and still be resumed by a matching turn; 0 means any age
+	FactMinConfidence   float64               // Facts below this confidence are left out of hydrated context
+	FactHalfLife        time.Duration         // Age at which a fact counts half as much when choosing facts for context; 0 disables decay
+	FactReview          bool                  // Facts extracted by the LLM wait for approval in the review queue before they are recalled
+	FactMaxPerTurn      int                   // Most facts saved from one turn's extraction, most confident first; 0 means no limit
+	FactExtractMinConf  float64               // Extracted facts below this confidence are discarded rather than saved
+	FactDedupe          bool                  // Extracted facts repeating their key's current value, or another fact's key in the turn, are not saved
+	ContextPresets      models.ContextPresets // Context budget and emphasis per MCP client name, applied by retrieve_memory; empty applies none
+	TopicTaxonomy       models.Taxonomy       // Hierarchical topic labels extracted topics are mapped onto; empty maps them onto labels already in use only
+
+	// Display settings
+	Location *time.Location // Zone for day IDs and displayed or exported times; timestamps are stored in UTC
+
+	// Server settings
+	ReadOnly          bool     // MCP servers expose only retrieval tools and open the database read-only
+	DisabledTools     []string // MCP tools servers leave out, e.g. delete_topic for a non-destructive deployment; empty disables none
+	JobWorkers        int      // Background workers an MCP server runs for embeddings, fact extraction, and profile learning
+	ProfileQueueLimit int      // Profile learning jobs that may wait before new ones are dropped
+	HealthAddr        string   // Address (host:port) an MCP server serves /healthz on; empty disables it
+	DebugAddr         string   // Address (host:port) an MCP server serves pprof and expvar on; empty disables it
+	HTTPAddr          string   // Address (host:port) 'memory serve' serves the REST API on
+	HTTPToken         string   // Bearer token the REST API requires; empty allows only loopback addresses
+	HTTPReadToken     string   // Bearer token the REST API also accepts, for reads only
+
+	// Webhook settings
+	WebhookURLs   []string           // URLs memory events are posted to; empty disables webhooks
+	WebhookEvents []models.EventType // Event types posted; empty means all
+	WebhookSecret string             // Key for the HMAC-SHA256 signature on each delivery; empty leaves deliveries unsigned
+
+	// Vault settings
+	VaultDir string // Markdown vault servers keep in sync with memory (one note per topic and a facts index); empty disables it
+
+	// Scheduler settings
+	Schedule []ScheduleEntry // Built-in tasks servers run on a cron schedule; empty schedules nothing
+
+	// Telemetry settings (off by default; never includes content)
+	Telemetry         string // off, local (count feature use locally), or share (also post daily aggregates)
+	TelemetryEndpoint string // URL daily aggregates are posted to when Telemetry is share
+
+	// Tracing settings
+	OTLPEndpoint string // OpenTelemetry collector base URL (OTLP/HTTP) that MCP servers send spans to; empty disables tracing
+
+	// Path is the config file that was read (it may not exist)
+	Path string
+
+	sources map[string]Source
+}
+
+// Source says where a setting's effective value came from
+type Source string
+
+const (
+	SourceDefault  Source = "default"
+	SourceFile     Source = "file"
+	SourceEnv      Source = "env"
+	SourceFlag     Source = "flag"
+	SourceKeychain Source = "keychain"
+)
+
+// ProviderKeyEnv maps each provider to the environment variable holding its API key.
+// Keys missing from the environment are looked up in the OS keychain under the provider name.
+var ProviderKeyEnv = map[string]string{
+	"openai": "OPENAI_API_KEY",
 }
 
-// DefaultDataDir returns the default data directory
+// Setting describes one configuration key: its name in config.yaml, its environment override, and its default
+type Setting struct {
+	Key     string
+	Env     string
+	Default string
+
+	set func(c *Config, value string) error
+	get func(c *Config) string
+}
+
+// settings lists every key config.yaml accepts, in display order
+var settings = []Setting{
+	{
+		Key: "data_dir", Env: "MEMORY_DATA_DIR", Default: "", // Empty means DefaultDataDir
+		set: func(c *Config, v string) error {
+			c.DataDir = expandHome(v)
+			if c.DataDir == "" {
+				c.DataDir = DefaultDataDir()
+			}
+			return nil
+		},
+		get: func(c *Config) string { return c.DataDir },
+	},
+	{
+		Key: "slow_query_threshold", Env: "MEMORY_SLOW_QUERY_THRESHOLD", Default: "0s",
+		set: func(c *Config, v string) (err error) { c.SlowQueryThreshold, err = time.ParseDuration(v); return err },
+		get: func(c *Config) string { return c.SlowQueryThreshold.String() },
+	},
+	{
+		Key: "max_db_size", Env: "MEMORY_MAX_DB_SIZE", Default: "0",
+		set: func(c *Config, v string) (err error) { c.MaxDBSize, err = parseSize(v); return err },
+		get: func(c *Config) string { return formatSize(c.MaxDBSize) },
+	},
+	{
+		Key: "archive_retention", Env: "MEMORY_ARCHIVE_RETENTION", Default: "720h",
+		set: func(c *Config, v string) (err error) { c.ArchiveRetention, err = time.ParseDuration(v); return err },
+		get: func(c *Config) string { return c.ArchiveRetention.String() },
+	},
+	{
+		Key: "compact_after", Env: "MEMORY_COMPACT_AFTER", Default: "0s",
+		set: func(c *Config, v string) (err error) { c.CompactAfter, err = time.ParseDuration(v); return err },
+		get: func(c *Config) string { return c.CompactAfter.String() },
+	},
+	{
+		Key: "compact_policy", Env: "MEMORY_COMPACT_POLICY", Default: "compress",
+		set: func(c *Config, v string) (err error) { c.CompactPolicy, err = models.ParseCompactPolicy(v); return err },
+		get: func(c *Config) string { return string(c.CompactPolicy) },
+	},
+	{
+		Key: "undo_log_size", Env: "MEMORY_UNDO_LOG_SIZE", Default: "20",
+		set: func(c *Config, v string) (err error) { c.UndoLogSize, err = strconv.Atoi(v); return err },
+		get: func(c *Config) string { return strconv.Itoa(c.UndoLogSize) },
+	},
+	{
+		Key: "provider", Env: "MEMORY_PROVIDER", Default: "openai",
+		set: func(c *Config, v string) error { c.Provider = v; return nil },
+		get: func(c *Config) string { return c.Provider },
+	},
+	{
+		Key: "chat_model", Env: "MEMORY_OPENAI_MODEL", Default: "gpt-4o-mini",
+		set: func(c *Config, v string) error { c.ChatModel = v; return nil },
+		get: func(c *Config) string { return c.ChatModel },
+	},
+	{
+		Key: "embedding_model", Env: "MEMORY_EMBEDDING_MODEL", Default: "text-embedding-3-small",
+		set: func(c *Config, v string) error { c.EmbeddingModel = v; return nil },
+		get: func(c *Config) string { return c.EmbeddingModel },
+	},
+	{
+		Key: "timeout", Env: "OPENAI_TIMEOUT", Default: "30s",
+		set: func(c *Config, v string) (err error) { c.Timeout, err = time.ParseDuration(v); return err },
+		get: func(c *Config) string { return c.Timeout.String() },
+	},
+	{
+		Key: "max_retries", Env: "OPENAI_MAX_RETRIES", Default: "3",
+		set: func(c *Config, v string) (err error) { c.MaxRetries, err = strconv.Atoi(v); return err },
+		get: func(c *Config) string { return strconv.Itoa(c.MaxRetries) },
+	},
+	{
+		Key: "retry_delay", Env: "OPENAI_RETRY_DELAY", Default: "2s",
+		set: func(c *Config, v string) (err error) { c.RetryDelay, err = time.ParseDuration(v); return err },
+		get: func(c *Config) string { return c.RetryDelay.String() },
+	},
+	{
+		Key: "proxy", Env: "MEMORY_PROXY", Default: "",
+		set: func(c *Config, v string) error { c.Proxy = v; return nil },
+		get: func(c *Config) string { return c.Proxy },
+	},
+	{
+		Key: "no_proxy", Env: "MEMORY_NO_PROXY", Default: "",
+		set: func(c *Config, v string) error { c.NoProxy = v; return nil },
+		get: func(c *Config) string { return c.NoProxy },
+	},
+	{
+		Key: "topic_match_threshold", Env: "TOPIC_MATCH_THRESHOLD", Default: "0.3",
+		set: func(c *Config, v string) (err error) {
+			c.TopicMatchThreshold, err = strconv.ParseFloat(v, 64)
+			return err
+		},
+		get: func(c *Config) string { return strconv.FormatFloat(c.TopicMatchThreshold, 'g', -1, 64) },
+	},
+	{
+		Key: "routing_mode", Env: "MEMORY_ROUTING_MODE", Default: "keywords",
+		set: func(c *Config, v string) error { c.RoutingMode = strings.TrimSpace(v); return nil },
+		get: func(c *Config) string { return c.RoutingMode },
+	},
+	{
+		Key: "semantic_match_threshold", Env: "MEMORY_SEMANTIC_MATCH_THRESHOLD", Default: "0.5",
+		set: func(c *Config, v string) (err error) {
+			c.SemanticThreshold, err = strconv.ParseFloat(v, 64)
+			return err
+		},
+		get: func(c *Config) string { return strconv.FormatFloat(c.SemanticThreshold, 'g', -1, 64) },
+	},
+	{
+		Key: "resumption_days", Env: "MEMORY_RESUMPTION_DAYS", Default: "14",
+		set: func(c *Config, v string) (err error) { c.ResumptionDays, err = strconv.Atoi(v); return err },
+		get: func(c *Config) string { return strconv.Itoa(c.ResumptionDays) },
+	},
+	{
+		Key: "vector_dimension", Env: "VECTOR_DIMENSION", Default: "1536",
+		set: func(c *Config, v string) (err error) { c.VectorDimension, err = strconv.Atoi(v); return err },
+		get: func(c *Config) string { return strconv.Itoa(c.VectorDimension) },
+	},
+	{
+		Key: "retrieval_k", Env: "MEMORY_RETRIEVAL_K", Default: "5",
+		set: func(c *Config, v string) (err error) { c.RetrievalK, err = strconv.Atoi(v); return err },
+		get: func(c *Config) string { return strconv.Itoa(c.RetrievalK) },
+	},
+	{
+		Key: "persona", Env: "MEMORY_PERSONA", Default: "",
+		set: func(c *Config, v string) (err error) {
+			if v == "" {
+				c.Persona = ""
+				return nil
+			}
+			c.Persona, err = models.NormalizePersonaName(v)
+			return err
+		},
+		get: func(c *Config) string { return c.Persona },
+	},
+	{
+		Key: "device", Env: "MEMORY_DEVICE", Default: "",
+		set: func(c *Config, v string) error { c.Device = strings.TrimSpace(v); return nil },
+		get: func(c *Config) string { return c.Device },
+	},
+	{
+		Key: "agent_id", Env: "MEMORY_AGENT_ID", Default: "",
+		set: func(c *Config, v string) error { c.AgentID = strings.TrimSpace(v); return nil },
+		get: func(c *Config) string { return c.AgentID },
+	},
+	{
+		Key: "profile_review_days", Env: "MEMORY_PROFILE_REVIEW_DAYS", Default: "90",
+		set: func(c *Config, v string) (err error) { c.ProfileReviewDays, err = strconv.Atoi(v); return err },
+		get: func(c *Config) string { return strconv.Itoa(c.ProfileReviewDays) },
+	},
+	{
+		Key: "fact_min_confidence", Env: "MEMORY_FACT_MIN_CONFIDENCE", Default: "0.3",
+		set: func(c *Config, v string) (err error) {
+			c.FactMinConfidence, err = strconv.ParseFloat(v, 64)
+			return err
+		},
+		get: func(c *Config) string { return strconv.FormatFloat(c.FactMinConfidence, 'g', -1, 64) },
+	},
+	{
+		Key: "fact_half_life", Env: "MEMORY_FACT_HALF_LIFE", Default: "2160h",
+		set: func(c *Config, v string) (err error) { c.FactHalfLife, err = time.ParseDuration(v); return err },
+		get: func(c *Config) string { return c.FactHalfLife.String() },
+	},
+	{
+		Key: "fact_review", Env: "MEMORY_FACT_REVIEW", Default: "false",
+		set: func(c *Config, v string) (err error) { c.FactReview, err = strconv.ParseBool(v); return err },
+		get: func(c *Config) string { return strconv.FormatBool(c.FactReview) },
+	},
+	{
+		Key: "fact_max_per_turn", Env: "MEMORY_FACT_MAX_PER_TURN", Default: "20",
+		set: func(c *Config, v string) (err error) { c.FactMaxPerTurn, err = strconv.Atoi(v); return err },
+		get: func(c *Config) string { return strconv.Itoa(c.FactMaxPerTurn) },
+	},
+	{
+		Key: "fact_extract_min_confidence", Env: "MEMORY_FACT_EXTRACT_MIN_CONFIDENCE", Default: "0.2",
+		set: func(c *Config, v string) (err error) {
+			c.FactExtractMinConf, err = strconv.ParseFloat(v, 64)
+			return err
+		},
+		get: func(c *Config) string { return strconv.FormatFloat(c.FactExtractMinConf, 'g', -1, 64) },
+	},
+	{
+		Key: "fact_dedupe", Env: "MEMORY_FACT_DEDUPE", Default: "true",
+		set: func(c *Config, v string) (err error) { c.FactDedupe, err = strconv.ParseBool(v); return err },
+		get: func(c *Config) string { return strconv.FormatBool(c.FactDedupe) },
+	},
+	{
+		Key: "context_presets", Env: "MEMORY_CONTEXT_PRESETS", Default: "",
+		set: func(c *Config, v string) (err error) {
+			c.ContextPresets, err = models.ParseContextPresets(v)
+			return err
+		},
+		get: func(c *Config) string { return c.ContextPresets.String() },
+	},
+	{
+		Key: "topic_taxonomy", Env: "MEMORY_TOPIC_TAXONOMY", Default: "",
+		set: func(c *Config, v string) (err error) {
+			c.TopicTaxonomy, err = models.ParseTaxonomy(v)
+			return err
+		},
+		get: func(c *Config) string { return c.TopicTaxonomy.String() },
+	},
+	{
+		Key: "timezone", Env: "MEMORY_TIMEZONE", Default: "", // Empty means the system zone ($TZ or /etc/localtime)
+		set: func(c *Config, v string) (err error) {
+			if v == "" {
+				c.Location = time.Local
+				return nil
+			}
+			c.Location, err = time.LoadLocation(v)
+			return err
+		},
+		get: func(c *Config) string { return c.Location.String() },
+	},
+	{
+		Key: "read_only", Env: "MEMORY_READ_ONLY", Default: "false",
+		set: func(c *Config, v string) (err error) { c.ReadOnly, err = strconv.ParseBool(v); return err },
+		get: func(c *Config) string { return strconv.FormatBool(c.ReadOnly) },
+	},
+	{
+		Key: "disabled_tools", Env: "MEMORY_DISABLED_TOOLS", Default: "",
+		set: func(c *Config, v string) error {
+			c.DisabledTools = nil
+			for _, item := range splitList(v) {
+				name, err := models.ParseToolName(item)
+				if err != nil {
+					return err
+				}
+				c.DisabledTools = append(c.DisabledTools, name)
+			}
+			return nil
+		},
+		get: func(c *Config) string { return strings.Join(c.DisabledTools, ",") },
+	},
+	{
+		Key: "job_workers", Env: "MEMORY_JOB_WORKERS", Default: "2",
+		set: func(c *Config, v string) (err error) { c.JobWorkers, err = strconv.Atoi(v); return err },
+		get: func(c *Config) string { return strconv.Itoa(c.JobWorkers) },
+	},
+	{
+		Key: "profile_queue_limit", Env: "MEMORY_PROFILE_QUEUE_LIMIT", Default: "100",
+		set: func(c *Config, v string) (err error) { c.ProfileQueueLimit, err = strconv.Atoi(v); return err },
+		get: func(c *Config) string { return strconv.Itoa(c.ProfileQueueLimit) },
+	},
+	{
+		Key: "health_addr", Env: "MEMORY_HEALTH_ADDR", Default: "",
+		set: func(c *Config, v string) error { c.HealthAddr = strings.TrimSpace(v); return nil },
+		get: func(c *Config) string { return c.HealthAddr },
+	},
+	{
+		Key: "debug_addr", Env: "MEMORY_DEBUG_ADDR", Default: "",
+		set: func(c *Config, v string) error { c.DebugAddr = strings.TrimSpace(v); return nil },
+		get: func(c *Config) string { return c.DebugAddr },
+	},
+	{
+		Key: "http_addr", Env: "MEMORY_HTTP_ADDR", Default: "127.0.0.1:8787",
+		set: func(c *Config, v string) error { c.HTTPAddr = strings.TrimSpace(v); return nil },
+		get: func(c *Config) string { return c.HTTPAddr },
+	},
+	{
+		Key: "http_token", Env: "MEMORY_HTTP_TOKEN", Default: "",
+		set: func(c *Config, v string) error {
+			c.HTTPToken = strings.TrimSpace(v)
+			redact.Register(c.HTTPToken)
+			return nil
+		},
+		get: func(c *Config) string {
+			if c.HTTPToken == "" {
+				return ""
+			}
+			return redact.Mask
+		},
+	},
+	{
+		Key: "http_read_token", Env: "MEMORY_HTTP_READ_TOKEN", Default: "",
+		set: func(c *Config, v string) error {
+			c.HTTPReadToken = strings.TrimSpace(v)
+			redact.Register(c.HTTPReadToken)
+			return nil
+		},
+		get: func(c *Config) string {
+			if c.HTTPReadToken == "" {
+				return ""
+			}
+			return redact.Mask
+		},
+	},
+	{
+		Key: "telemetry", Env: "MEMORY_TELEMETRY", Default: "off",
+		set: func(c *Config, v string) error { c.Telemetry = v; return nil },
+		get: func(c *Config) string { return c.Telemetry },
+	},
+	{
+		Key: "telemetry_endpoint", Env: "MEMORY_TELEMETRY_ENDPOINT", Default: "",
+		set: func(c *Config, v string) error { c.TelemetryEndpoint = v; return nil },
+		get: func(c *Config) string { return c.TelemetryEndpoint },
+	},
+	{
+		Key: "webhook_urls", Env: "MEMORY_WEBHOOK_URLS", Default: "",
+		set: func(c *Config, v string) error { c.WebhookURLs = splitList(v); return nil },
+		get: func(c *Config) string { return strings.Join(c.WebhookURLs, ",") },
+	},
+	{
+		Key: "webhook_events", Env: "MEMORY_WEBHOOK_EVENTS", Default: "",
+		set: func(c *Config, v string) error {
+			c.WebhookEvents = nil
+			for _, name := range splitList(v) {
+				t, err := models.ParseEventType(name)
+				if err != nil {
+					return err
+				}
+				c.WebhookEvents = append(c.WebhookEvents, t)
+			}
+			return nil
+		},
+		get: func(c *Config) string {
+			names := make([]string, len(c.WebhookEvents))
+			for i, t := range c.WebhookEvents {
+				names[i] = string(t)
+			}
+			return strings.Join(names, ",")
+		},
+	},
+	{
+		Key: "webhook_secret", Env: "MEMORY_WEBHOOK_SECRET", Default: "",
+		set: func(c *Config, v string) error {
+			c.WebhookSecret = strings.TrimSpace(v)
+			redact.Register(c.WebhookSecret)
+			return nil
+		},
+		get: func(c *Config) string {
+			if c.WebhookSecret == "" {
+				return ""
+			}
+			return redact.Mask
+		},
+	},
+	{
+		Key: "vault_dir", Env: "MEMORY_VAULT_DIR", Default: "",
+		set: func(c *Config, v string) error { c.VaultDir = expandHome(v); return nil },
+		get: func(c *Config) string { return c.VaultDir },
+	},
+	{
+		Key: "schedule", Env: "MEMORY_SCHEDULE", Default: "",
+		set: func(c *Config, v string) error {
+			entries, err := parseSchedule(v)
+			if err != nil {
+				return err
+			}
+			c.Schedule = entries
+			return nil
+		},
+		get: func(c *Config) string {
+			entries := make([]string, len(c.Schedule))
+			for i, e := range c.Schedule {
+				entries[i] = string(e.Task) + "=" + e.Cron.String()
+			}
+			return strings.Join(entries, "; ")
+		},
+	},
+	{
+		Key: "otlp_endpoint", Env: "OTEL_EXPORTER_OTLP_ENDPOINT", Default: "",
+		set: func(c *Config, v string) error { c.OTLPEndpoint = v; return nil },
+		get: func(c *Config) string { return c.OTLPEndpoint },
+	},
+}
+
+// Settings returns every configuration key in display order
+func Settings() []Setting {
+	return append([]Setting(nil), settings...)
+}
+
+// DefaultDataDir returns the default data directory following the XDG spec
 func DefaultDataDir() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return ".local/share/memory"
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return ".local/share/memory"
+		}
+		dataHome = filepath.Join(homeDir, ".local", "share")
+	}
+	return filepath.Join(dataHome, "memory")
+}
+
+// DefaultPath returns the config file location: $MEMORY_CONFIG, else $XDG_CONFIG_HOME/memory/config.yaml
+func DefaultPath() string {
+	if path := os.Getenv("MEMORY_CONFIG"); path != "" {
+		return path
 	}
-	return filepath.Join(homeDir, ".local", "share", "memory")
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return filepath.Join(".config", "memory", "config.yaml")
+		}
+		configHome = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configHome, "memory", "config.yaml")
 }
 
-// Load reads configuration from environment variables
+// Load reads the default config file (if present) with environment variables taking precedence
 func Load() (*Config, error) {
+	return LoadFile(DefaultPath())
+}
+
+// LoadFile reads configuration from path (a missing file is fine) with environment variables taking precedence
+func LoadFile(path string) (*Config, error) {
+	file, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
-		// Defaults
-		DataDir:             getEnv("MEMORY_DATA_DIR", DefaultDataDir()),
-		OpenAIKey:           os.Getenv("OPENAI_API_KEY"),
-		ChatModel:           getEnv("MEMORY_OPENAI_MODEL", "gpt-4o-mini"),
-		EmbeddingModel:      getEnv("MEMORY_EMBEDDING_MODEL", "text-embedding-3-small"),
-		Timeout:             getEnvDuration("OPENAI_TIMEOUT", 30*time.Second),
-		MaxRetries:          getEnvInt("OPENAI_MAX_RETRIES", 3),
-		RetryDelay:          getEnvDuration("OPENAI_RETRY_DELAY", 2*time.Second),
-		TopicMatchThreshold: getEnvFloat("TOPIC_MATCH_THRESHOLD", 0.3),
-		VectorDimension:     getEnvInt("VECTOR_DIMENSION", 1536),
+		Path:    path,
+		sources: make(map[string]Source, len(settings)),
+	}
+	cfg.OpenAIKey, cfg.sources["openai_api_key"] = ProviderKey("openai")
+	for _, s := range settings {
+		value, source := s.Default, SourceDefault
+		if v, ok := file[s.Key]; ok {
+			value, source = v, SourceFile
+		}
+		if v := os.Getenv(s.Env); v != "" {
+			value, source = v, SourceEnv
+		}
+		if err := s.set(cfg, value); err != nil {
+			return nil, fmt.Errorf("invalid %s %q (from %s): %w", s.Key, value, source, err)
+		}
+		cfg.sources[s.Key] = source
+	}
+	if err := cfg.resolveContext(); err != nil {
+		return nil, err
 	}
 
 	return cfg, cfg.Validate()
 }
 
+// Override sets a setting from a command-line flag, which takes precedence over the file and environment
+func (c *Config) Override(key, value string) error {
+	for _, s := range settings {
+		if s.Key != key {
+			continue
+		}
+		if err := s.set(c, value); err != nil {
+			return fmt.Errorf("invalid %s %q (from %s): %w", key, value, SourceFlag, err)
+		}
+		c.sources[key] = SourceFlag
+		return c.Validate()
+	}
+	return fmt.Errorf("unknown setting %q", key)
+}
+
+// ProviderKey returns a provider's API key from its environment variable, else the OS keychain.
+// An empty key comes back with SourceDefault. A key that is found is masked in logs from then on.
+func ProviderKey(provider string) (string, Source) {
+	if key := os.Getenv(ProviderKeyEnv[provider]); key != "" {
+		redact.Register(key)
+		return key, SourceEnv
+	}
+	if key, err := keychain.Get(provider); err == nil {
+		redact.Register(key)
+		return key, SourceKeychain
+	}
+	return "", SourceDefault
+}
+
+// ReadFile returns the settings in a config file; a missing file has none
+func ReadFile(path string) (map[string]string, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := map[string]string{}
+	if err := yaml.Unmarshal(raw, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	for key := range values {
+		if !isSetting(key) {
+			return nil, fmt.Errorf("unknown setting %q in %s", key, path)
+		}
+	}
+	return values, nil
+}
+
+// Validate checks that every setting is in range
 func (c *Config) Validate() error {
+	if c.Provider != "openai" {
+		return fmt.Errorf("provider must be openai, got %q", c.Provider)
+	}
+	if c.ChatModel == "" || c.EmbeddingModel == "" {
+		return fmt.Errorf("chat_model and embedding_model must not be empty")
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got %s", c.Timeout)
+	}
+	if c.RetryDelay < 0 {
+		return fmt.Errorf("retry_delay must not be negative, got %s", c.RetryDelay)
+	}
+	if c.SlowQueryThreshold < 0 {
+		return fmt.Errorf("slow_query_threshold must not be negative, got %s", c.SlowQueryThreshold)
+	}
+	if c.MaxDBSize < 0 {
+		return fmt.Errorf("max_db_size must not be negative, got %d", c.MaxDBSize)
+	}
+	if c.ArchiveRetention < 0 {
+		return fmt.Errorf("archive_retention must not be negative, got %s", c.ArchiveRetention)
+	}
+	if c.CompactAfter < 0 {
+		return fmt.Errorf("compact_after must not be negative, got %s", c.CompactAfter)
+	}
+	if c.UndoLogSize < 0 || c.UndoLogSize > 1000 {
+		return fmt.Errorf("undo_log_size must be 0-1000, got %d", c.UndoLogSize)
+	}
+	if c.Proxy != "" {
+		u, err := url.Parse(c.Proxy)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+			return fmt.Errorf("proxy must be an http, https, or socks5 URL, got %q", c.Proxy)
+		}
+	}
 	if c.TopicMatchThreshold < 0 || c.TopicMatchThreshold > 1 {
-		return fmt.Errorf("TOPIC_MATCH_THRESHOLD must be 0-1, got %f", c.TopicMatchThreshold)
+		return fmt.Errorf("topic_match_threshold must be 0-1, got %f", c.TopicMatchThreshold)
+	}
+	if c.RoutingMode != "keywords" && c.RoutingMode != "embedding" {
+		return fmt.Errorf("routing_mode must be keywords or embedding, got %q", c.RoutingMode)
+	}
+	if c.SemanticThreshold < -1 || c.SemanticThreshold > 1 {
+		return fmt.Errorf("semantic_match_threshold must be -1 to 1, got %f", c.SemanticThreshold)
+	}
+	if c.ResumptionDays < 0 || c.ResumptionDays > 3650 {
+		return fmt.Errorf("resumption_days must be 0-3650, got %d", c.ResumptionDays)
 	}
 	if c.MaxRetries < 0 || c.MaxRetries > 10 {
-		return fmt.Errorf("OPENAI_MAX_RETRIES must be 0-10, got %d", c.MaxRetries)
+		return fmt.Errorf("max_retries must be 0-10, got %d", c.MaxRetries)
+	}
+	if c.VectorDimension <= 0 {
+		return fmt.Errorf("vector_dimension must be positive, got %d", c.VectorDimension)
+	}
+	if c.RetrievalK < 1 || c.RetrievalK > 100 {
+		return fmt.Errorf("retrieval_k must be 1-100, got %d", c.RetrievalK)
+	}
+	if c.FactMinConfidence < 0 || c.FactMinConfidence > 1 {
+		return fmt.Errorf("fact_min_confidence must be 0-1, got %f", c.FactMinConfidence)
+	}
+	if c.FactHalfLife < 0 {
+		return fmt.Errorf("fact_half_life must not be negative, got %s", c.FactHalfLife)
+	}
+	if c.FactMaxPerTurn < 0 || c.FactMaxPerTurn > 1000 {
+		return fmt.Errorf("fact_max_per_turn must be 0-1000, got %d", c.FactMaxPerTurn)
+	}
+	if c.FactExtractMinConf < 0 || c.FactExtractMinConf > 1 {
+		return fmt.Errorf("fact_extract_min_confidence must be 0-1, got %f", c.FactExtractMinConf)
+	}
+	if c.ProfileReviewDays < 1 || c.ProfileReviewDays > 3650 {
+		return fmt.Errorf("profile_review_days must be 1-3650, got %d", c.ProfileReviewDays)
+	}
+	if c.JobWorkers < 1 || c.JobWorkers > 32 {
+		return fmt.Errorf("job_workers must be 1-32, got %d", c.JobWorkers)
+	}
+	if c.ProfileQueueLimit < 1 || c.ProfileQueueLimit > 10000 {
+		return fmt.Errorf("profile_queue_limit must be 1-10000, got %d", c.ProfileQueueLimit)
+	}
+	if c.HealthAddr != "" {
+		if _, _, err := net.SplitHostPort(c.HealthAddr); err != nil {
+			return fmt.Errorf("health_addr must be host:port, got %q", c.HealthAddr)
+		}
+	}
+	if c.DebugAddr != "" {
+		if _, _, err := net.SplitHostPort(c.DebugAddr); err != nil {
+			return fmt.Errorf("debug_addr must be host:port, got %q", c.DebugAddr)
+		}
+	}
+	if _, _, err := net.SplitHostPort(c.HTTPAddr); err != nil {
+		return fmt.Errorf("http_addr must be host:port, got %q", c.HTTPAddr)
+	}
+	if c.HTTPReadToken != "" && c.HTTPReadToken == c.HTTPToken {
+		return fmt.Errorf("http_read_token must differ from http_token")
+	}
+	for _, hook := range c.WebhookURLs {
+		u, err := url.Parse(hook)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("webhook_urls must be http(s) URLs, got %q", hook)
+		}
+	}
+	switch c.Telemetry {
+	case "off", "local":
+	case "share":
+		u, err := url.Parse(c.TelemetryEndpoint)
+		if c.TelemetryEndpoint == "" || err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("telemetry share needs an http(s) telemetry_endpoint, got %q", c.TelemetryEndpoint)
+		}
+	default:
+		return fmt.Errorf("telemetry must be off, local, or share, got %q", c.Telemetry)
 	}
 	return nil
 }
 
-// DBPath returns the database file path
+// Value returns a setting's effective value as it would be written in config.yaml
+func (c *Config) Value(s Setting) string {
+	return s.get(c)
+}
+
+// Source returns where a setting's effective value came from
+func (c *Config) Source(key string) Source {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// DBPath returns the database file path of the current context
 func (c *Config) DBPath() string {
-	return filepath.Join(c.DataDir, "memory.db")
+	return filepath.Join(c.ContextDir(c.Context), "memory.db")
 }
 
-// Helper functions
-func getEnv(key, defaultVal string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
+// DigestDir returns where the scheduled digest task writes the current context's digests
+func (c *Config) DigestDir() string {
+	return filepath.Join(c.ContextDir(c.Context), "digests")
+}
+
+// ProfileReviewAge returns how long a profile preference or topic may go unreinforced
+// before it comes up for review
+func (c *Config) ProfileReviewAge() time.Duration {
+	return time.Duration(c.ProfileReviewDays) * 24 * time.Hour
+}
+
+// ResumptionWindow returns how recently a paused topic must have been active for a
+// matching turn to resume it; 0 means any age
+func (c *Config) ResumptionWindow() time.Duration {
+	return time.Duration(c.ResumptionDays) * 24 * time.Hour
+}
+
+// FactLimits returns the bounds on the facts saved from one turn's extraction
+func (c *Config) FactLimits() models.FactLimits {
+	return models.FactLimits{MaxPerTurn: c.FactMaxPerTurn, MinConfidence: c.FactExtractMinConf, Dedupe: c.FactDedupe}
+}
+
+// sizeUnit is a suffix parseSize accepts
+type sizeUnit struct {
+	suffix string
+	bytes  int64
+}
+
+var (
+	binaryUnits  = []sizeUnit{{"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}}
+	decimalUnits = []sizeUnit{{"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3}, {"B", 1}}
+)
+
+// parseSize parses a byte count such as "2GiB", "500MB", or "1048576"
+func parseSize(v string) (int64, error) {
+	v = strings.TrimSpace(v)
+	for _, unit := range append(binaryUnits, decimalUnits...) {
+		if number, ok := strings.CutSuffix(v, unit.suffix); ok {
+			n, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+			if err != nil {
+				return 0, errors.New("size must be a number of bytes with an optional unit like MiB or GB")
+			}
+			return int64(n * float64(unit.bytes)), nil
+		}
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, errors.New("size must be a number of bytes with an optional unit like MiB or GB")
+	}
+	return n, nil
+}
+
+// formatSize writes a byte count in the largest binary unit that divides it exactly
+func formatSize(n int64) string {
+	for _, unit := range binaryUnits {
+		if n != 0 && n%unit.bytes == 0 {
+			return strconv.FormatInt(n/unit.bytes, 10) + unit.suffix
+		}
 	}
-	return defaultVal
+	return strconv.FormatInt(n, 10)
 }
 
-func getEnvInt(key string, defaultVal int) int {
-	if v := os.Getenv(key); v != "" {
-		if i, err := strconv.Atoi(v); err == nil {
-			return i
+// splitList splits a comma-separated setting, dropping blank items
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
 	}
-	return defaultVal
+	return items
+}
+
+// ScheduleEntry is one built-in task and the cron schedule it runs on
+type ScheduleEntry struct {
+	Task models.ScheduledTask
+	Cron *cron.Schedule
 }
 
-func getEnvFloat(key string, defaultVal float64) float64 {
-	if v := os.Getenv(key); v != "" {
-		if f, err := strconv.ParseFloat(v, 64); err == nil {
-			return f
+// parseSchedule parses "task=cron" entries separated by semicolons, such as
+// "prune=0 3 * * *; digest=@weekly"; commas are left to the cron lists
+func parseSchedule(v string) ([]ScheduleEntry, error) {
+	var entries []ScheduleEntry
+	seen := map[models.ScheduledTask]bool{}
+	for _, item := range strings.Split(v, ";") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		name, expr, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("schedule entry %q must be task=cron, e.g. prune=0 3 * * *", item)
+		}
+		task, err := models.ParseScheduledTask(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		if seen[task] {
+			return nil, fmt.Errorf("schedule lists %s twice", task)
 		}
+		seen[task] = true
+		schedule, err := cron.Parse(expr)
+		if err != nil {
+			return nil, fmt.Errorf("schedule for %s: %w", task, err)
+		}
+		entries = append(entries, ScheduleEntry{Task: task, Cron: schedule})
 	}
-	return defaultVal
+	return entries, nil
 }
 
-func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
-	if v := os.Getenv(key); v != "" {
-		if d, err := time.ParseDuration(v); err == nil {
-			return d
+// Helper functions
+func isSetting(key string) bool {
+	for _, s := range settings {
+		if s.Key == key {
+			return true
 		}
 	}
-	return defaultVal
+	return false
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
 }
@@ -4,9 +4,13 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
 )
 
 func TestLoad_Defaults(t *testing.T) {
@@ -43,6 +47,9 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.VectorDimension != 1536 {
 		t.Errorf("VectorDimension = %d, want 1536", cfg.VectorDimension)
 	}
+	if cfg.FactLimits() != models.DefaultFactLimits {
+		t.Errorf("FactLimits() = %+v, want %+v", cfg.FactLimits(), models.DefaultFactLimits)
+	}
 }
 
 func TestLoad_CustomValues(t *testing.T) {
@@ -93,11 +100,20 @@ func TestLoad_CustomValues(t *testing.T) {
 	}
 }
 
-func TestValidate_InvalidThreshold(t *testing.T) {
-	cfg := &Config{
-		TopicMatchThreshold: 1.5,
-		MaxRetries:          3,
+// validConfig returns a config that passes Validate
+func validConfig(t *testing.T) *Config {
+	t.Helper()
+	os.Clearenv()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
 	}
+	return cfg
+}
+
+func TestValidate_InvalidThreshold(t *testing.T) {
+	cfg := validConfig(t)
+	cfg.TopicMatchThreshold = 1.5
 
 	err := cfg.Validate()
 	if err == nil {
@@ -112,10 +128,8 @@ func TestValidate_InvalidThreshold(t *testing.T) {
 }
 
 func TestValidate_InvalidMaxRetries(t *testing.T) {
-	cfg := &Config{
-		TopicMatchThreshold: 0.5,
-		MaxRetries:          15,
-	}
+	cfg := validConfig(t)
+	cfg.MaxRetries = 15
 
 	err := cfg.Validate()
 	if err == nil {
@@ -149,3 +163,395 @@ func TestDefaultDataDir(t *testing.T) {
 		t.Errorf("DefaultDataDir() = %s, expected to contain 'memory'", dir)
 	}
 }
+
+func TestLoadFile_FileThenEnv(t *testing.T) {
+	os.Clearenv()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "chat_model: gpt-4o\ntopic_match_threshold: 0.4\nmax_retries: 5\nread_only: true\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OPENAI_MAX_RETRIES", "2")
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+
+	if cfg.ChatModel != "gpt-4o" {
+		t.Errorf("ChatModel = %s, want gpt-4o from file", cfg.ChatModel)
+	}
+	if cfg.TopicMatchThreshold != 0.4 {
+		t.Errorf("TopicMatchThreshold = %f, want 0.4 from file", cfg.TopicMatchThreshold)
+	}
+	if cfg.MaxRetries != 2 {
+		t.Errorf("MaxRetries = %d, want 2 from env", cfg.MaxRetries)
+	}
+	if !cfg.ReadOnly {
+		t.Error("ReadOnly = false, want true from file")
+	}
+
+	sources := map[string]Source{
+		"chat_model":      SourceFile,
+		"max_retries":     SourceEnv,
+		"embedding_model": SourceDefault,
+	}
+	for key, want := range sources {
+		if got := cfg.Source(key); got != want {
+			t.Errorf("Source(%s) = %s, want %s", key, got, want)
+		}
+	}
+}
+
+func TestLoadFile_Missing(t *testing.T) {
+	os.Clearenv()
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFile() with missing file failed: %v", err)
+	}
+	if cfg.ChatModel != "gpt-4o-mini" {
+		t.Errorf("ChatModel = %s, want default gpt-4o-mini", cfg.ChatModel)
+	}
+}
+
+func TestLoadFile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown key", "chat_modle: gpt-4o\n", "unknown setting"},
+		{"bad duration", "timeout: soon\n", "invalid timeout"},
+		{"bad provider", "provider: acme\n", "provider"},
+		{"bad persona", "persona: day job\n", "invalid persona"},
+		{"bad size", "max_db_size: big\n", "invalid max_db_size"},
+		{"bad compact policy", "compact_policy: shred\n", "invalid compact_policy"},
+		{"not a mapping", "- a\n- b\n", "failed to parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadFile(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadFile() error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		out  string
+	}{
+		{"0", 0, "0"},
+		{"1048576", 1 << 20, "1MiB"},
+		{"2GiB", 2 << 30, "2GiB"},
+		{"1.5 GiB", 3 << 29, "1536MiB"},
+		{"500MB", 500e6, "500000000"},
+		{"100KB", 100e3, "100000"},
+	}
+	for _, tt := range tests {
+		got, err := parseSize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseSize(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+		if out := formatSize(got); out != tt.out {
+			t.Errorf("formatSize(%d) = %q, want %q", got, out, tt.out)
+		}
+	}
+	for _, bad := range []string{"", "big", "GiB", "1.5"} {
+		if _, err := parseSize(bad); err == nil {
+			t.Errorf("parseSize(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("MEMORY_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	if got := DefaultPath(); got != "/tmp/xdg/memory/config.yaml" {
+		t.Errorf("DefaultPath() = %s, want /tmp/xdg/memory/config.yaml", got)
+	}
+
+	t.Setenv("MEMORY_CONFIG", "/etc/memory.yaml")
+	if got := DefaultPath(); got != "/etc/memory.yaml" {
+		t.Errorf("DefaultPath() = %s, want /etc/memory.yaml", got)
+	}
+}
+
+func TestValidate_Settings(t *testing.T) {
+	base := validConfig(t)
+	if base.RetrievalK != 5 {
+		t.Errorf("RetrievalK = %d, want 5", base.RetrievalK)
+	}
+
+	withProxy := *base
+	withProxy.Proxy = "http://proxy.corp:8080"
+	if err := withProxy.Validate(); err != nil {
+		t.Errorf("Validate() with http proxy error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   string
+	}{
+		{"empty chat model", func(c *Config) { c.ChatModel = "" }, "chat_model"},
+		{"zero timeout", func(c *Config) { c.Timeout = 0 }, "timeout"},
+		{"negative retry delay", func(c *Config) { c.RetryDelay = -time.Second }, "retry_delay"},
+		{"negative slow query threshold", func(c *Config) { c.SlowQueryThreshold = -time.Millisecond }, "slow_query_threshold"},
+		{"negative max db size", func(c *Config) { c.MaxDBSize = -1 }, "max_db_size"},
+		{"negative archive retention", func(c *Config) { c.ArchiveRetention = -time.Hour }, "archive_retention"},
+		{"negative compact after", func(c *Config) { c.CompactAfter = -time.Hour }, "compact_after"},
+		{"negative undo log size", func(c *Config) { c.UndoLogSize = -1 }, "undo_log_size"},
+		{"unknown routing mode", func(c *Config) { c.RoutingMode = "vectors" }, "routing_mode"},
+		{"semantic threshold above 1", func(c *Config) { c.SemanticThreshold = 1.5 }, "semantic_match_threshold"},
+		{"zero vector dimension", func(c *Config) { c.VectorDimension = 0 }, "vector_dimension"},
+		{"retrieval k too large", func(c *Config) { c.RetrievalK = 500 }, "retrieval_k"},
+		{"no profile review days", func(c *Config) { c.ProfileReviewDays = 0 }, "profile_review_days"},
+		{"negative resumption days", func(c *Config) { c.ResumptionDays = -1 }, "resumption_days"},
+		{"fact confidence above one", func(c *Config) { c.FactMinConfidence = 1.5 }, "fact_min_confidence"},
+		{"negative fact half life", func(c *Config) { c.FactHalfLife = -time.Hour }, "fact_half_life"},
+		{"negative facts per turn", func(c *Config) { c.FactMaxPerTurn = -1 }, "fact_max_per_turn"},
+		{"extract confidence above one", func(c *Config) { c.FactExtractMinConf = 2 }, "fact_extract_min_confidence"},
+		{"no job workers", func(c *Config) { c.JobWorkers = 0 }, "job_workers"},
+		{"no profile queue", func(c *Config) { c.ProfileQueueLimit = 0 }, "profile_queue_limit"},
+		{"health addr without port", func(c *Config) { c.HealthAddr = "localhost" }, "health_addr"},
+		{"debug addr without port", func(c *Config) { c.DebugAddr = "6060" }, "debug_addr"},
+		{"read token same as token", func(c *Config) { c.HTTPToken, c.HTTPReadToken = "s3cret", "s3cret" }, "http_read_token"},
+		{"proxy without scheme", func(c *Config) { c.Proxy = "proxy.corp:8080" }, "proxy"},
+		{"proxy with bad scheme", func(c *Config) { c.Proxy = "ftp://proxy.corp" }, "proxy"},
+		{"unknown telemetry mode", func(c *Config) { c.Telemetry = "on" }, "telemetry"},
+		{"share without endpoint", func(c *Config) { c.Telemetry = "share" }, "telemetry_endpoint"},
+		{"webhook without scheme", func(c *Config) { c.WebhookURLs = []string{"hooks.example.com/memory"} }, "webhook_urls"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := *base
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderKey_EnvThenKeychain(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fake secret-tool only runs on linux")
+	}
+	// A fake secret-tool that always returns a stored key
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho sk-from-keychain\n"
+	if err := os.WriteFile(filepath.Join(bin, "secret-tool"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	t.Setenv("OPENAI_API_KEY", "sk-from-env")
+	if key, source := ProviderKey("openai"); key != "sk-from-env" || source != SourceEnv {
+		t.Errorf("ProviderKey() = %q, %s; want sk-from-env, env", key, source)
+	}
+
+	t.Setenv("OPENAI_API_KEY", "")
+	if key, source := ProviderKey("openai"); key != "sk-from-keychain" || source != SourceKeychain {
+		t.Errorf("ProviderKey() = %q, %s; want sk-from-keychain, keychain", key, source)
+	}
+}
+
+func TestLoadFile_Persona(t *testing.T) {
+	os.Clearenv()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("persona: Work\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+	if cfg.Persona != "work" {
+		t.Errorf("Persona = %q, want normalized %q", cfg.Persona, "work")
+	}
+
+	t.Setenv("MEMORY_PERSONA", "home")
+	cfg, err = LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+	if cfg.Persona != "home" || cfg.Source("persona") != SourceEnv {
+		t.Errorf("Persona = %q from %s, want home from env", cfg.Persona, cfg.Source("persona"))
+	}
+}
+
+func TestLoadFile_Webhooks(t *testing.T) {
+	os.Clearenv()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := "webhook_urls: https://a.example.com/hook, https://b.example.com/hook\nwebhook_events: fact_superseded,conflict_detected\n"
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+	if len(cfg.WebhookURLs) != 2 || cfg.WebhookURLs[1] != "https://b.example.com/hook" {
+		t.Errorf("WebhookURLs = %v", cfg.WebhookURLs)
+	}
+	if len(cfg.WebhookEvents) != 2 || cfg.WebhookEvents[0] != models.EventFactSuperseded {
+		t.Errorf("WebhookEvents = %v", cfg.WebhookEvents)
+	}
+
+	t.Setenv("MEMORY_WEBHOOK_EVENTS", "fact_deleted")
+	if _, err := LoadFile(path); err == nil || !strings.Contains(err.Error(), "webhook_events") {
+		t.Errorf("LoadFile() with an unknown event error = %v", err)
+	}
+}
+
+func TestLoadFile_DisabledTools(t *testing.T) {
+	os.Clearenv()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("disabled_tools: delete_topic, delete_fact\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+	if len(cfg.DisabledTools) != 2 || cfg.DisabledTools[0] != "delete_topic" || cfg.DisabledTools[1] != "delete_fact" {
+		t.Errorf("DisabledTools = %v", cfg.DisabledTools)
+	}
+
+	t.Setenv("MEMORY_DISABLED_TOOLS", "forget")
+	if _, err := LoadFile(path); err == nil || !strings.Contains(err.Error(), "disabled_tools") {
+		t.Errorf("LoadFile() with an unknown tool error = %v", err)
+	}
+}
+
+func TestLoadFile_VaultDir(t *testing.T) {
+	os.Clearenv()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("vault_dir: ~/Obsidian/Memory\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+	if want := filepath.Join(home, "Obsidian", "Memory"); cfg.VaultDir != want {
+		t.Errorf("VaultDir = %q, want %q", cfg.VaultDir, want)
+	}
+}
+
+func TestLoadFile_Schedule(t *testing.T) {
+	os.Clearenv()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("schedule: \"prune=0 3 * * *; digest=@weekly; sync=0 9,17 * * 1-5\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+	if len(cfg.Schedule) != 3 {
+		t.Fatalf("Schedule = %v, want 3 entries", cfg.Schedule)
+	}
+	if cfg.Schedule[2].Task != models.TaskSync || cfg.Schedule[2].Cron.String() != "0 9,17 * * 1-5" {
+		t.Errorf("Schedule[2] = %s %q, want sync on weekdays at 9 and 17", cfg.Schedule[2].Task, cfg.Schedule[2].Cron)
+	}
+	for _, s := range Settings() {
+		if s.Key == "schedule" && cfg.Value(s) != "prune=0 3 * * *; digest=@weekly; sync=0 9,17 * * 1-5" {
+			t.Errorf("schedule value = %q, want the entries joined with semicolons", cfg.Value(s))
+		}
+	}
+
+	for value, want := range map[string]string{
+		"prune":                      "must be task=cron",
+		"backup=@daily":              "invalid scheduled task",
+		"prune=@daily; prune=@daily": "lists prune twice",
+		"prune=61 * * * *":           "minute 61 is out of range",
+	} {
+		t.Setenv("MEMORY_SCHEDULE", value)
+		if _, err := LoadFile(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadFile() with schedule %q error = %v, want it to mention %q", value, err, want)
+		}
+	}
+}
+
+func TestLoadFile_ContextPresets(t *testing.T) {
+	os.Clearenv()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("context_presets: \"Claude-Code=8000:facts; claude-ai=2000:history; *=4000\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+	if preset, ok := cfg.ContextPresets.ForClient("claude-code"); !ok || preset.MaxTokens != 8000 || preset.Emphasis != models.EmphasisFacts {
+		t.Errorf("ForClient(claude-code) = %+v, %v; want 8000 tokens heavy on facts", preset, ok)
+	}
+	if preset, ok := cfg.ContextPresets.ForClient("cursor"); !ok || preset.MaxTokens != 4000 || preset.Emphasis != models.EmphasisBalanced {
+		t.Errorf("ForClient(cursor) = %+v, %v; want the catch-all preset", preset, ok)
+	}
+	for _, s := range Settings() {
+		if s.Key == "context_presets" && cfg.Value(s) != "claude-code=8000:facts; claude-ai=2000:history; *=4000:balanced" {
+			t.Errorf("context_presets value = %q", cfg.Value(s))
+		}
+	}
+
+	for value, want := range map[string]string{
+		"claude-code":                        "must be client=tokens",
+		"claude-code=lots":                   "must be a positive number",
+		"claude-code=8000:code":              "invalid emphasis",
+		"claude-code=8000; Claude-Code=2000": "list claude-code twice",
+	} {
+		t.Setenv("MEMORY_CONTEXT_PRESETS", value)
+		if _, err := LoadFile(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadFile() with context_presets %q error = %v, want it to mention %q", value, err, want)
+		}
+	}
+}
+
+func TestLoadFile_Timezone(t *testing.T) {
+	os.Clearenv()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+	if cfg.Location != time.Local {
+		t.Errorf("Location = %v, want the system zone by default", cfg.Location)
+	}
+
+	t.Setenv("MEMORY_TIMEZONE", "Asia/Tokyo")
+	cfg, err = LoadFile(path)
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	if cfg.Location.String() != "Asia/Tokyo" {
+		t.Errorf("Location = %v, want Asia/Tokyo", cfg.Location)
+	}
+
+	t.Setenv("MEMORY_TIMEZONE", "Mars/Olympus_Mons")
+	if _, err := LoadFile(path); err == nil || !strings.Contains(err.Error(), "timezone") {
+		t.Errorf("LoadFile() with unknown zone error = %v, want timezone error", err)
+	}
+}
@@ -0,0 +1,134 @@
+// ABOUTME: Named memory contexts (workspaces), each with its own database
+// ABOUTME: The default context lives in data_dir; others live in data_dir/contexts/<name>
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultContext is the context whose database sits directly in data_dir
+const DefaultContext = "default"
+
+// contextNamePattern keeps context names safe to use as directory names
+var contextNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateContextName rejects names that are not lowercase letters, digits, '-' or '_'
+func ValidateContextName(name string) error {
+	if !contextNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid context name %q (use lowercase letters, digits, '-' and '_')", name)
+	}
+	return nil
+}
+
+// ContextDir returns the directory holding a context's database
+func (c *Config) ContextDir(name string) string {
+	if name == "" || name == DefaultContext {
+		return c.DataDir
+	}
+	return filepath.Join(c.DataDir, "contexts", name)
+}
+
+// ContextExists reports whether a context has been created
+func (c *Config) ContextExists(name string) bool {
+	if name == DefaultContext {
+		return true
+	}
+	info, err := os.Stat(c.ContextDir(name))
+	return err == nil && info.IsDir()
+}
+
+// Contexts lists the default context and every created one, sorted by name
+func (c *Config) Contexts() ([]string, error) {
+	names := []string{DefaultContext}
+	entries, err := os.ReadDir(filepath.Join(c.DataDir, "contexts"))
+	if errors.Is(err, os.ErrNotExist) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contexts: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && ValidateContextName(entry.Name()) == nil && entry.Name() != DefaultContext {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names[1:])
+	return names, nil
+}
+
+// CreateContext makes a new, empty context
+func (c *Config) CreateContext(name string) error {
+	if err := ValidateContextName(name); err != nil {
+		return err
+	}
+	if c.ContextExists(name) {
+		return fmt.Errorf("context %q already exists", name)
+	}
+	if err := os.MkdirAll(c.ContextDir(name), 0755); err != nil {
+		return fmt.Errorf("failed to create context: %w", err)
+	}
+	return nil
+}
+
+// SetContext switches this config to an existing context
+func (c *Config) SetContext(name string) error {
+	if err := ValidateContextName(name); err != nil {
+		return err
+	}
+	c.Context = name
+	return c.CheckContext()
+}
+
+// CheckContext fails if the current context has not been created, so a typo never starts a new database
+func (c *Config) CheckContext() error {
+	if !c.ContextExists(c.Context) {
+		return fmt.Errorf("context %q does not exist (create it with 'memory context create %s')", c.Context, c.Context)
+	}
+	return nil
+}
+
+// UseContext makes name the current context for future runs
+func (c *Config) UseContext(name string) error {
+	if err := c.SetContext(name); err != nil {
+		return err
+	}
+	path := c.currentContextPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(name+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to save current context: %w", err)
+	}
+	return nil
+}
+
+// currentContextPath is the file recording the context chosen by 'memory context use', next to config.yaml
+func (c *Config) currentContextPath() string {
+	return filepath.Join(filepath.Dir(c.Path), "context")
+}
+
+// resolveContext picks the context from $MEMORY_CONTEXT, else the saved current context, else the default.
+// It only checks the name; CheckContext confirms the context exists before its database is opened.
+func (c *Config) resolveContext() error {
+	name, source := DefaultContext, SourceDefault
+	if raw, err := os.ReadFile(c.currentContextPath()); err == nil {
+		if saved := strings.TrimSpace(string(raw)); saved != "" {
+			name, source = saved, SourceFile
+		}
+	}
+	if env := os.Getenv("MEMORY_CONTEXT"); env != "" {
+		name, source = env, SourceEnv
+	}
+	if err := ValidateContextName(name); err != nil {
+		return fmt.Errorf("%w (from %s)", err, source)
+	}
+	c.Context = name
+	c.sources["context"] = source
+	return nil
+}
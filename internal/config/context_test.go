@@ -0,0 +1,92 @@
+// ABOUTME: Tests for named memory contexts
+// ABOUTME: Verifies context creation, resolution order, and per-context database paths
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newContextTestConfig(t *testing.T) *Config {
+	t.Helper()
+	os.Clearenv()
+	dir := t.TempDir()
+	t.Setenv("MEMORY_DATA_DIR", filepath.Join(dir, "data"))
+
+	cfg, err := LoadFile(filepath.Join(dir, "config", "config.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+	return cfg
+}
+
+func TestContext_DefaultDBPath(t *testing.T) {
+	cfg := newContextTestConfig(t)
+
+	if cfg.Context != DefaultContext {
+		t.Errorf("Context = %s, want %s", cfg.Context, DefaultContext)
+	}
+	if want := filepath.Join(cfg.DataDir, "memory.db"); cfg.DBPath() != want {
+		t.Errorf("DBPath() = %s, want %s", cfg.DBPath(), want)
+	}
+}
+
+func TestContext_CreateAndUse(t *testing.T) {
+	cfg := newContextTestConfig(t)
+
+	if err := cfg.CreateContext("work"); err != nil {
+		t.Fatalf("CreateContext() failed: %v", err)
+	}
+	if err := cfg.CreateContext("work"); err == nil {
+		t.Error("CreateContext() should fail for an existing context")
+	}
+	if err := cfg.UseContext("work"); err != nil {
+		t.Fatalf("UseContext() failed: %v", err)
+	}
+
+	names, err := cfg.Contexts()
+	if err != nil {
+		t.Fatalf("Contexts() failed: %v", err)
+	}
+	if strings.Join(names, ",") != "default,work" {
+		t.Errorf("Contexts() = %v, want [default work]", names)
+	}
+
+	// A fresh load picks up the saved context
+	reloaded, err := LoadFile(cfg.Path)
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+	if reloaded.Context != "work" || reloaded.Source("context") != SourceFile {
+		t.Errorf("Context = %s (%s), want work (file)", reloaded.Context, reloaded.Source("context"))
+	}
+	if want := filepath.Join(cfg.DataDir, "contexts", "work", "memory.db"); reloaded.DBPath() != want {
+		t.Errorf("DBPath() = %s, want %s", reloaded.DBPath(), want)
+	}
+
+	// $MEMORY_CONTEXT overrides the saved context
+	t.Setenv("MEMORY_CONTEXT", "default")
+	reloaded, err = LoadFile(cfg.Path)
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+	if reloaded.Context != DefaultContext {
+		t.Errorf("Context = %s, want default from env", reloaded.Context)
+	}
+}
+
+func TestContext_Errors(t *testing.T) {
+	cfg := newContextTestConfig(t)
+
+	if err := cfg.SetContext("missing"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("SetContext(missing) error = %v, want 'does not exist'", err)
+	}
+	for _, name := range []string{"", "Work", "../x", "a/b"} {
+		if err := cfg.CreateContext(name); err == nil {
+			t.Errorf("CreateContext(%q) should fail", name)
+		}
+	}
+}
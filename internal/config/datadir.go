@@ -0,0 +1,76 @@
+// ABOUTME: Data directory checks run at startup and by 'memory doctor'
+// ABOUTME: Confirms the directory is writable and flags network filesystems where SQLite locking is unreliable
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DataDirStatus describes whether a directory is a safe home for a SQLite database
+type DataDirStatus struct {
+	Path       string `json:"path"`
+	Exists     bool   `json:"exists"`
+	Writable   bool   `json:"writable"`
+	Filesystem string `json:"filesystem"` // Filesystem type when known, e.g. "ext" or "nfs"
+	Network    bool   `json:"network"`    // Network filesystems risk corruption because file locks are unreliable
+	Err        error  `json:"-"`          // Why the directory is not writable
+}
+
+// CheckDataDir reports on dir without creating it. A missing directory is judged by its nearest
+// existing parent, since opening the database creates it there.
+func CheckDataDir(dir string) DataDirStatus {
+	status := DataDirStatus{Path: dir}
+
+	probe := dir
+	for {
+		info, err := os.Stat(probe)
+		if err == nil {
+			if !info.IsDir() {
+				status.Err = fmt.Errorf("%s is not a directory", probe)
+				return status
+			}
+			break
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			status.Err = err
+			return status
+		}
+		parent := filepath.Dir(probe)
+		if parent == probe {
+			status.Err = err
+			return status
+		}
+		probe = parent
+	}
+	status.Exists = probe == dir
+	status.Filesystem, status.Network = filesystemType(probe)
+
+	f, err := os.CreateTemp(probe, ".memory-write-check-*")
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	status.Writable = true
+	return status
+}
+
+// Warning returns a message when the directory is usable but risky, or "" when it is fine
+func (s DataDirStatus) Warning() string {
+	if s.Network {
+		return fmt.Sprintf("data directory %s is on a network filesystem (%s); SQLite databases can be corrupted there, so prefer a local disk", s.Path, s.Filesystem)
+	}
+	return ""
+}
+
+// Problem returns why the database cannot be written to the directory, or nil
+func (s DataDirStatus) Problem() error {
+	if s.Writable {
+		return nil
+	}
+	return fmt.Errorf("data directory %s is not writable: %w (set data_dir in config.yaml, $MEMORY_DATA_DIR, or --data-dir)", s.Path, s.Err)
+}
@@ -0,0 +1,82 @@
+// ABOUTME: Tests for data directory checks
+// ABOUTME: Verifies writability probing, missing directories, and the --data-dir style override
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckDataDir_Writable(t *testing.T) {
+	dir := t.TempDir()
+
+	status := CheckDataDir(dir)
+	if !status.Exists || !status.Writable || status.Problem() != nil {
+		t.Errorf("CheckDataDir(%s) = %+v, want existing and writable", dir, status)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("CheckDataDir left %d files behind", len(entries))
+	}
+}
+
+func TestCheckDataDir_MissingUsesParent(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "a", "b")
+
+	status := CheckDataDir(dir)
+	if status.Exists || !status.Writable {
+		t.Errorf("CheckDataDir(%s) = %+v, want missing but writable", dir, status)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("CheckDataDir should not create the directory")
+	}
+}
+
+func TestCheckDataDir_NotWritable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(dir, 0755) })
+
+	if err := CheckDataDir(dir).Problem(); err == nil {
+		t.Error("Problem() = nil for a read-only directory")
+	}
+}
+
+func TestCheckDataDir_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckDataDir(path).Problem(); err == nil {
+		t.Error("Problem() = nil for a regular file")
+	}
+}
+
+func TestOverride(t *testing.T) {
+	os.Clearenv()
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.Override("data_dir", "/tmp/memory-flag"); err != nil {
+		t.Fatalf("Override() error = %v", err)
+	}
+	if cfg.DataDir != "/tmp/memory-flag" || cfg.Source("data_dir") != SourceFlag {
+		t.Errorf("DataDir = %s (%s), want /tmp/memory-flag from flag", cfg.DataDir, cfg.Source("data_dir"))
+	}
+	if err := cfg.Override("nope", "x"); err == nil {
+		t.Error("Override() of an unknown setting should fail")
+	}
+	if err := cfg.Override("retrieval_k", "0"); err == nil {
+		t.Error("Override() should validate the new value")
+	}
+}
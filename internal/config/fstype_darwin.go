@@ -0,0 +1,30 @@
+// ABOUTME: Filesystem type detection on macOS via statfs(2)
+// ABOUTME: Used to warn when the data directory is on NFS, SMB, AFP, or WebDAV
+package config
+
+import "syscall"
+
+// networkFilesystems lists macOS filesystem type names that are remote
+var networkFilesystems = map[string]bool{
+	"nfs":    true,
+	"smbfs":  true,
+	"afpfs":  true,
+	"webdav": true,
+	"cifs":   true,
+}
+
+// filesystemType names the filesystem holding path and reports whether it is a network filesystem
+func filesystemType(path string) (string, bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return "", false
+	}
+	name := make([]byte, 0, len(st.Fstypename))
+	for _, c := range st.Fstypename {
+		if c == 0 {
+			break
+		}
+		name = append(name, byte(c))
+	}
+	return string(name), networkFilesystems[string(name)]
+}
@@ -0,0 +1,39 @@
+// ABOUTME: Filesystem type detection on Linux via statfs(2) magic numbers
+// ABOUTME: Used to warn when the data directory is on NFS, SMB, or another network filesystem
+package config
+
+import "syscall"
+
+// filesystems maps statfs f_type magic numbers to names; network marks remote filesystems
+var filesystems = map[uint32]struct {
+	name    string
+	network bool
+}{
+	0xEF53:     {"ext", false},
+	0x58465342: {"xfs", false},
+	0x9123683E: {"btrfs", false},
+	0x2FC12FC1: {"zfs", false},
+	0x01021994: {"tmpfs", false},
+	0x794C7630: {"overlayfs", false},
+	0xF2F52010: {"f2fs", false},
+	0x6969:     {"nfs", true},
+	0x517B:     {"smb", true},
+	0xFF534D42: {"cifs", true},
+	0xFE534D42: {"smb2", true},
+	0x5346414F: {"afs", true},
+	0x01021997: {"9p", true},
+	0x00C36400: {"ceph", true},
+	0x65735546: {"fuse", false},
+}
+
+// filesystemType names the filesystem holding path and reports whether it is a network filesystem
+func filesystemType(path string) (string, bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return "", false
+	}
+	if fs, ok := filesystems[uint32(st.Type)]; ok {
+		return fs.name, fs.network
+	}
+	return "unknown", false
+}
@@ -0,0 +1,11 @@
+// ABOUTME: Filesystem type fallback for platforms without statfs support
+// ABOUTME: Reports an unknown filesystem so data directory checks still run
+
+//go:build !linux && !darwin
+
+package config
+
+// filesystemType cannot tell filesystems apart on this platform
+func filesystemType(path string) (string, bool) {
+	return "unknown", false
+}
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"strings"
 
-	"github.com/google/uuid"
 	"github.com/harper/remember-standalone/internal/models"
 )
 
@@ -121,5 +120,5 @@ func splitSentences(text string) []string {
 
 // generateChunkID generates a unique chunk ID
 func generateChunkID() string {
-	return "chunk_" + uuid.New().String()
+	return models.NewID("chunk")
 }
@@ -297,9 +297,9 @@ func TestGenerateChunkID(t *testing.T) {
 		t.Errorf("generateChunkID() = %q, should start with 'chunk_'", id1)
 	}
 
-	// Should be UUID format after prefix
-	if len(id1) < 40 { // "chunk_" + UUID
-		t.Errorf("generateChunkID() = %q, too short", id1)
+	// Should be a ULID after prefix
+	if len(id1) != len("chunk_")+26 {
+		t.Errorf("generateChunkID() = %q, want a ULID after the prefix", id1)
 	}
 }
 
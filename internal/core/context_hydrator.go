@@ -1,21 +1,54 @@
 // ABOUTME: ContextHydrator assembles intelligent prompts with conversation history, memories, and facts
-// ABOUTME: Uses semantic search to retrieve relevant context and enforces token limits
+// ABOUTME: Uses semantic search to retrieve relevant context and enforces token limits, weighting facts by confidence and recency
 package core
 
 import (
 	"fmt"
+	"log"
+	"math"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/redact"
 	"github.com/harper/remember-standalone/internal/storage"
 )
 
+const (
+	factCandidates  = 20 // Facts searched for before weighting picks the ones to include
+	maxContextFacts = 5  // Facts included in hydrated context at most
+)
+
+// FactWeighting decides which facts hydrated context includes. Facts below
+// MinConfidence are left out; the rest are ranked by confidence times recency, where a
+// fact HalfLife old counts half as much as a new one (no decay when HalfLife is zero).
+type FactWeighting struct {
+	MinConfidence float64
+	HalfLife      time.Duration
+}
+
+// DefaultFactWeighting matches the fact_min_confidence and fact_half_life defaults
+var DefaultFactWeighting = FactWeighting{MinConfidence: 0.3, HalfLife: 90 * 24 * time.Hour}
+
+// Weight is what fact counts for at now: its confidence, decayed by its age
+func (w FactWeighting) Weight(fact models.Fact, now time.Time) float64 {
+	age := now.Sub(fact.CreatedAt)
+	if w.HalfLife <= 0 || age <= 0 {
+		return fact.Confidence
+	}
+	return fact.Confidence * math.Exp2(-float64(age)/float64(w.HalfLife))
+}
+
 // ContextHydrator assembles context-aware prompts for LLM interactions
 type ContextHydrator struct {
 	storage       *storage.Storage
 	vectorStorage interface {
 		GenerateEmbedding(text string) ([]float64, error)
 	}
+	persona       string        // Profile persona layered into the USER PROFILE section; empty for the shared profile
+	weighting     FactWeighting // How facts are chosen for the RELEVANT FACTS section
+	revealSecrets bool          // Whether secret facts are hydrated in the clear rather than masked
 }
 
 // NewContextHydrator creates a new ContextHydrator
@@ -25,70 +58,214 @@ func NewContextHydrator(store *storage.Storage, embeddingClient interface {
 	return &ContextHydrator{
 		storage:       store,
 		vectorStorage: embeddingClient,
+		weighting:     DefaultFactWeighting,
 	}
 }
 
+// SetPersona selects the profile persona (e.g. "work") whose preferences and
+// constraints are included in hydrated prompts; empty uses the shared profile only
+func (ch *ContextHydrator) SetPersona(persona string) {
+	ch.persona = persona
+}
+
+// SetFactWeighting changes how facts are chosen for hydrated context
+func (ch *ContextHydrator) SetFactWeighting(w FactWeighting) {
+	ch.weighting = w
+}
+
+// SetRevealSecrets includes secret fact values in hydrated context in the clear.
+// By default they are masked, since hydrated context is sent to LLM providers.
+func (ch *ContextHydrator) SetRevealSecrets(reveal bool) {
+	ch.revealSecrets = reveal
+}
+
 // HydrateBridgeBlock assembles a complete prompt for a Bridge Block conversation
 // Includes: system prompt, user profile, block history, retrieved memories, relevant facts, and current message
 func (ch *ContextHydrator) HydrateBridgeBlock(blockID string, userMessage string, maxTokens int) (string, error) {
-	var sections []string
+	pack, err := ch.PackBridgeBlock(blockID, userMessage, maxTokens)
+	if err != nil {
+		return "", err
+	}
+	ch.recordAccess(pack)
+	return pack.Prompt, nil
+}
+
+// PackBridgeBlock hydrates like HydrateBridgeBlock, returning the prompt in a ContextPack
+// that records how it was assembled
+func (ch *ContextHydrator) PackBridgeBlock(blockID string, userMessage string, maxTokens int) (*ContextPack, error) {
+	pack := ch.newPack(userMessage, maxTokens)
+	pack.BlockID = blockID
 
 	// 1. System prompt (always included)
 	systemPrompt := "You are a helpful AI assistant with access to conversation history and context."
-	sections = append(sections, "SYSTEM:\n"+systemPrompt+"\n")
+	pack.Sections = append(pack.Sections, newSection(PackSystem, "SYSTEM:\n"+systemPrompt+"\n", "", nil))
 
 	// 2. User profile (if available)
-	profile, err := ch.storage.GetUserProfile()
-	if err == nil && profile != nil {
-		profileSection := ch.formatUserProfile(profile)
-		sections = append(sections, profileSection)
-	}
+	pack.Sections = append(pack.Sections, ch.profileSection())
 
 	// 3. Bridge Block conversation history
 	block, err := ch.storage.GetBridgeBlock(blockID)
 	if err != nil {
-		return "", fmt.Errorf("failed to get bridge block: %w", err)
+		return nil, fmt.Errorf("failed to get bridge block: %w", err)
 	}
-
-	blockHistory := ch.formatBlockHistory(block)
-	sections = append(sections, blockHistory)
+	if block == nil {
+		return nil, fmt.Errorf("bridge block %s not found", blockID)
+	}
+	pack.Sections = append(pack.Sections, newSection(PackHistory, ch.formatBlockHistory(block), "", nil))
 
 	// 4. Retrieved memories from other blocks (via semantic search)
-	if ch.vectorStorage != nil {
-		memories, err := ch.storage.SearchMemory(userMessage, 3)
-		if err == nil && len(memories) > 0 {
-			// Filter out current block from memories
-			var relevantMemories []models.MemorySearchResult
-			for _, mem := range memories {
-				if mem.BlockID != blockID {
-					relevantMemories = append(relevantMemories, mem)
-				}
-			}
+	pack.Sections = append(pack.Sections, ch.memoriesSection(userMessage, blockID))
 
-			if len(relevantMemories) > 0 {
-				memoriesSection := ch.formatRetrievedMemories(relevantMemories)
-				sections = append(sections, memoriesSection)
-			}
+	// 5. Relevant facts (search by keywords from user message), as many of the
+	// weightiest as fit in what the other sections leave of the budget
+	currentMessage := "CURRENT USER MESSAGE:\n" + userMessage + "\n"
+	used := len(currentMessage)
+	for _, section := range pack.Sections {
+		if section.Included {
+			used += len(section.Text) + 1
 		}
 	}
-
-	// 5. Relevant facts (search by keywords from user message)
-	facts, err := ch.storage.SearchFacts(userMessage, 5)
-	if err == nil && len(facts) > 0 {
-		factsSection := ch.formatRelevantFacts(facts)
-		sections = append(sections, factsSection)
-	}
+	pack.Sections = append(pack.Sections, ch.factsSection(userMessage, maxTokens*4-used))
 
 	// 6. Current user message (always included at the end)
-	sections = append(sections, "CURRENT USER MESSAGE:\n"+userMessage+"\n")
+	pack.Sections = append(pack.Sections, newSection(PackMessage, currentMessage, "", nil))
 
 	// Assemble full prompt
+	var sections []string
+	for _, section := range pack.Sections {
+		if section.Included {
+			sections = append(sections, section.Text)
+		}
+	}
 	fullPrompt := strings.Join(sections, "\n")
 
 	// Token limiting (4 chars ≈ 1 token)
-	fullPrompt = ch.limitTokens(fullPrompt, userMessage, maxTokens)
+	pack.finish(ch.limitTokens(fullPrompt, userMessage, maxTokens))
+
+	return pack, nil
+}
+
+// HydrateMemory assembles what memory holds that bears on userMessage, for callers that
+// build the rest of the prompt themselves: retrieved memories, relevant facts, and the
+// user profile. Sections that do not fit in maxTokens are dropped in that order of
+// priority, and the result is empty when memory has nothing to add.
+func (ch *ContextHydrator) HydrateMemory(userMessage string, maxTokens int) string {
+	pack := ch.PackMemory(userMessage, maxTokens)
+	ch.recordAccess(pack)
+	return pack.Prompt
+}
+
+// PackMemory hydrates like HydrateMemory, returning the result in a ContextPack that
+// records how it was assembled
+func (ch *ContextHydrator) PackMemory(userMessage string, maxTokens int) *ContextPack {
+	pack := ch.newPack(userMessage, maxTokens)
+
+	// Keep sections by priority, then emit them in prompt order
+	available := maxTokens * 4 // 4 chars ≈ 1 token
+	keep := func(section *PackSection) {
+		if !section.Included {
+			return
+		}
+		if len(section.Text) > available {
+			section.drop(reasonBudget)
+			return
+		}
+		available -= len(section.Text)
+	}
+	memories := ch.memoriesSection(userMessage, "")
+	keep(&memories)
+	// Facts take the weightiest that fit rather than all or nothing
+	facts := ch.factsSection(userMessage, available)
+	keep(&facts)
+	profile := ch.profileSection()
+	keep(&profile)
+	pack.Sections = []PackSection{profile, memories, facts}
 
-	return fullPrompt, nil
+	var sb strings.Builder
+	for _, section := range pack.Sections {
+		if section.Included {
+			sb.WriteString(section.Text)
+		}
+	}
+	pack.finish(sb.String())
+	return pack
+}
+
+// recordAccess counts a read of each block whose history or memories made it into
+// pack. Packs built only to inspect hydration are not counted. Heat is a hint, so
+// failing to record it does not fail the hydration.
+func (ch *ContextHydrator) recordAccess(pack *ContextPack) {
+	var blockIDs []string
+	for _, section := range pack.Sections {
+		if !section.Included {
+			continue
+		}
+		switch section.Name {
+		case PackHistory:
+			blockIDs = append(blockIDs, pack.BlockID)
+		case PackMemories:
+			for _, item := range section.Items {
+				if item.Included {
+					blockIDs = append(blockIDs, item.ID)
+				}
+			}
+		}
+	}
+	if err := ch.storage.RecordBlockAccess(blockIDs...); err != nil {
+		log.Printf("[ContextHydrator] %v", err)
+	}
+}
+
+// profileSection is the USER PROFILE section for ch's persona
+func (ch *ContextHydrator) profileSection() PackSection {
+	profile, err := ch.storage.GetUserProfileAs(ch.persona)
+	if err != nil || profile == nil {
+		return newSection(PackProfile, "", reasonNoProfile, nil)
+	}
+	return newSection(PackProfile, ch.formatUserProfile(profile), "", nil)
+}
+
+// memoriesSection is the RETRIEVED MEMORIES section for userMessage, leaving out
+// memories from blockID (the conversation's own topic, when there is one)
+func (ch *ContextHydrator) memoriesSection(userMessage, blockID string) PackSection {
+	if ch.vectorStorage == nil {
+		return newSection(PackMemories, "", reasonNoEmbedder, nil)
+	}
+	memories, err := ch.storage.SearchMemory(userMessage, 3)
+	if err != nil || len(memories) == 0 {
+		return newSection(PackMemories, "", reasonNoMatches, nil)
+	}
+
+	var relevant []models.MemorySearchResult
+	items := make([]PackItem, 0, len(memories))
+	for _, mem := range memories {
+		item := PackItem{Kind: "memory", ID: mem.BlockID, Text: mem.TopicLabel, Score: mem.RelevanceScore}
+		if mem.BlockID == blockID {
+			item.Reason = reasonSameTopic
+		} else {
+			item.Included = true
+			relevant = append(relevant, mem)
+		}
+		items = append(items, item)
+	}
+	if len(relevant) == 0 {
+		return newSection(PackMemories, "", reasonNoneKept, items)
+	}
+	return newSection(PackMemories, ch.formatRetrievedMemories(relevant), "", items)
+}
+
+// factsSection is the RELEVANT FACTS section for userMessage, holding the weightiest
+// facts that fit in budget characters
+func (ch *ContextHydrator) factsSection(userMessage string, budget int) PackSection {
+	facts, err := ch.storage.SearchFacts(userMessage, factCandidates)
+	if err != nil || len(facts) == 0 {
+		return newSection(PackFacts, "", reasonNoMatches, nil)
+	}
+	selected, items := ch.selectFacts(facts, budget)
+	if len(selected) == 0 {
+		return newSection(PackFacts, "", reasonNoneKept, items)
+	}
+	return newSection(PackFacts, ch.formatRelevantFacts(selected), "", items)
 }
 
 // formatUserProfile formats user profile for prompt
@@ -96,6 +273,9 @@ func (ch *ContextHydrator) formatUserProfile(profile *models.UserProfile) string
 	var sb strings.Builder
 	sb.WriteString("USER PROFILE:\n")
 	sb.WriteString(fmt.Sprintf("Name: %s\n", profile.Name))
+	if profile.Persona != "" {
+		sb.WriteString(fmt.Sprintf("Persona: %s\n", profile.Persona))
+	}
 
 	if len(profile.Preferences) > 0 {
 		sb.WriteString(fmt.Sprintf("Preferences: %s\n", strings.Join(profile.Preferences, ", ")))
@@ -105,6 +285,14 @@ func (ch *ContextHydrator) formatUserProfile(profile *models.UserProfile) string
 		sb.WriteString(fmt.Sprintf("Topics of Interest: %s\n", strings.Join(profile.TopicsOfInterest, ", ")))
 	}
 
+	// Constraints are listed one per line so strict ones stand out to the model
+	if len(profile.Constraints) > 0 {
+		sb.WriteString("Constraints (always respect these):\n")
+		for _, c := range profile.Constraints {
+			sb.WriteString(fmt.Sprintf("- %s\n", c))
+		}
+	}
+
 	sb.WriteString("\n")
 	return sb.String()
 }
@@ -117,6 +305,14 @@ func (ch *ContextHydrator) formatBlockHistory(block *models.BridgeBlock) string
 
 	for i, turn := range block.Turns {
 		sb.WriteString(fmt.Sprintf("Turn %d:\n", i+1))
+		if len(turn.Messages) > 0 {
+			// Transcripts keep their system, tool-call, and tool-result lines
+			for _, m := range turn.Messages {
+				sb.WriteString(m.String() + "\n")
+			}
+			sb.WriteString("\n")
+			continue
+		}
 		sb.WriteString(fmt.Sprintf("User: %s\n", turn.UserMessage))
 		sb.WriteString(fmt.Sprintf("AI: %s\n\n", turn.AIResponse))
 	}
@@ -147,13 +343,59 @@ func (ch *ContextHydrator) formatRetrievedMemories(memories []models.MemorySearc
 	return sb.String()
 }
 
+// selectFacts picks the facts to include, weightiest first: those at or above the
+// minimum confidence, up to maxContextFacts, whose formatted section fits in budget
+// characters. It also returns every fact as a PackItem saying whether it made it and why not.
+func (ch *ContextHydrator) selectFacts(facts []models.Fact, budget int) ([]models.Fact, []PackItem) {
+	now := ch.storage.Now()
+	candidates := append([]models.Fact(nil), facts...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return ch.weighting.Weight(candidates[i], now) > ch.weighting.Weight(candidates[j], now)
+	})
+
+	budget -= len(factsHeader) + len("\n")
+	var selected []models.Fact
+	items := make([]PackItem, 0, len(candidates))
+	for _, fact := range candidates {
+		if fact.ValueType == models.ValueTypeSecret && !ch.revealSecrets {
+			fact.Value = redact.Mask
+		}
+		line := formatFact(fact)
+		item := PackItem{Kind: "fact", ID: fact.FactID, Text: fact.Key + ": " + fact.Value,
+			Score: ch.weighting.Weight(fact, now), Confidence: fact.Confidence}
+		switch {
+		case fact.Confidence < ch.weighting.MinConfidence:
+			item.Reason = "below fact_min_confidence"
+		case len(selected) == maxContextFacts:
+			item.Reason = fmt.Sprintf("past the first %d facts", maxContextFacts)
+		case len(line) > budget:
+			// A long fact that does not fit leaves room for shorter, lighter ones
+			item.Reason = reasonBudget
+		default:
+			item.Included = true
+			selected = append(selected, fact)
+			budget -= len(line)
+		}
+		items = append(items, item)
+	}
+	return selected, items
+}
+
+// factsHeader opens the RELEVANT FACTS section
+const factsHeader = "RELEVANT FACTS:\n"
+
+// formatFact formats one fact as a line of the RELEVANT FACTS section
+func formatFact(fact models.Fact) string {
+	return fmt.Sprintf("- %s: %s (confidence: %.2f)\n", fact.Key, fact.Value, fact.Confidence)
+}
+
 // formatRelevantFacts formats relevant facts
 func (ch *ContextHydrator) formatRelevantFacts(facts []models.Fact) string {
 	var sb strings.Builder
-	sb.WriteString("RELEVANT FACTS:\n")
+	sb.WriteString(factsHeader)
 
 	for _, fact := range facts {
-		sb.WriteString(fmt.Sprintf("- %s: %s (confidence: %.2f)\n", fact.Key, fact.Value, fact.Confidence))
+		sb.WriteString(formatFact(fact))
 	}
 
 	sb.WriteString("\n")
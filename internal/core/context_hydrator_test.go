@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/redact"
 	"github.com/harper/remember-standalone/internal/storage"
 )
 
@@ -101,6 +102,30 @@ func TestContextHydrator_BasicPromptAssembly(t *testing.T) {
 		}
 	})
 
+	t.Run("includes the selected persona", func(t *testing.T) {
+		if err := store.SavePersona(&models.Persona{Name: "work", Preferences: []string{"formal tone"}}); err != nil {
+			t.Fatalf("Failed to save persona: %v", err)
+		}
+
+		prompt, err := hydrator.HydrateBridgeBlock(blockID, "What should I learn?", 4000)
+		if err != nil {
+			t.Fatalf("Failed to hydrate: %v", err)
+		}
+		if strings.Contains(prompt, "formal tone") {
+			t.Error("Persona preferences should not appear without a persona selected")
+		}
+
+		hydrator.SetPersona("work")
+		defer hydrator.SetPersona("")
+		prompt, err = hydrator.HydrateBridgeBlock(blockID, "What should I learn?", 4000)
+		if err != nil {
+			t.Fatalf("Failed to hydrate: %v", err)
+		}
+		if !strings.Contains(prompt, "Persona: work") || !strings.Contains(prompt, "formal tone") {
+			t.Errorf("Expected work persona in profile, got:\n%s", prompt)
+		}
+	})
+
 	t.Run("token limiting truncates properly", func(t *testing.T) {
 		// Very tight token limit
 		userMessage := "Short question"
@@ -361,6 +386,138 @@ func TestContextHydrator_WithFacts(t *testing.T) {
 	}
 }
 
+func TestContextHydrator_HydrateMemory(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	hydrator := NewContextHydrator(store, nil)
+	if got := hydrator.HydrateMemory("what editor do I use?", 1000); got != "" {
+		t.Errorf("HydrateMemory() on empty memory = %q, want empty", got)
+	}
+
+	if err := store.SaveFact(&models.Fact{FactID: "fact_editor", Key: "editor", Value: "helix", Confidence: 1.0}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+	if err := store.SaveUserProfile(&models.UserProfile{Name: "Alice", Preferences: []string{strings.Repeat("long preference ", 40)}}); err != nil {
+		t.Fatalf("SaveUserProfile() error = %v", err)
+	}
+
+	got := hydrator.HydrateMemory("editor", 1000)
+	if !strings.Contains(got, "RELEVANT FACTS") || !strings.Contains(got, "editor: helix") || !strings.Contains(got, "USER PROFILE") {
+		t.Errorf("HydrateMemory() = %q, want the fact and the profile", got)
+	}
+	if strings.Contains(got, "CURRENT USER MESSAGE") || strings.Contains(got, "SYSTEM:") {
+		t.Errorf("HydrateMemory() should leave the rest of the prompt to the caller: %q", got)
+	}
+
+	// The profile is dropped first when the budget is tight
+	got = hydrator.HydrateMemory("editor", 40)
+	if !strings.Contains(got, "editor: helix") || strings.Contains(got, "USER PROFILE") {
+		t.Errorf("HydrateMemory() with a small budget = %q, want only the facts", got)
+	}
+}
+
+func TestFactWeighting_Weight(t *testing.T) {
+	now := time.Now()
+	w := FactWeighting{HalfLife: 30 * 24 * time.Hour}
+	tests := []struct {
+		name string
+		age  time.Duration
+		w    FactWeighting
+		want float64
+	}{
+		{"new", 0, w, 0.8},
+		{"one half life", 30 * 24 * time.Hour, w, 0.4},
+		{"two half lives", 60 * 24 * time.Hour, w, 0.2},
+		{"no decay", 60 * 24 * time.Hour, FactWeighting{}, 0.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.w.Weight(models.Fact{Confidence: 0.8, CreatedAt: now.Add(-tt.age)}, now)
+			if got < tt.want-1e-9 || got > tt.want+1e-9 {
+				t.Errorf("Weight() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextHydrator_FactWeighting(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Now()
+	for _, f := range []models.Fact{
+		{FactID: "fact_w_old", Key: "coffee_order", Value: "double espresso", Confidence: 0.95, CreatedAt: now.AddDate(-1, 0, 0)},
+		{FactID: "fact_w_new", Key: "coffee_shop", Value: "Blue Bottle", Confidence: 0.7, CreatedAt: now},
+		{FactID: "fact_w_weak", Key: "coffee_mug", Value: "green", Confidence: 0.2, CreatedAt: now},
+	} {
+		if err := store.SaveFact(&f); err != nil {
+			t.Fatalf("SaveFact() error = %v", err)
+		}
+	}
+
+	hydrator := NewContextHydrator(store, nil)
+	got := hydrator.HydrateMemory("coffee", 1000)
+	if strings.Contains(got, "coffee_mug") {
+		t.Errorf("HydrateMemory() = %q, want the fact below fact_min_confidence left out", got)
+	}
+	shop, order := strings.Index(got, "coffee_shop"), strings.Index(got, "coffee_order")
+	if shop < 0 || order < 0 || shop > order {
+		t.Errorf("HydrateMemory() = %q, want the recent fact ahead of the year-old one", got)
+	}
+
+	// Under a tight budget only the weightiest fact fits
+	budget := (len(factsHeader) + len(formatFact(models.Fact{Key: "coffee_shop", Value: "Blue Bottle", Confidence: 0.7})) + 1 + 3) / 4
+	got = hydrator.HydrateMemory("coffee", budget)
+	if !strings.Contains(got, "coffee_shop") || strings.Contains(got, "coffee_order") {
+		t.Errorf("HydrateMemory() with room for one fact = %q, want only the recent one", got)
+	}
+
+	// Without decay confidence alone decides, and a zero threshold admits everything
+	hydrator.SetFactWeighting(FactWeighting{})
+	got = hydrator.HydrateMemory("coffee", 1000)
+	shop, order = strings.Index(got, "coffee_shop"), strings.Index(got, "coffee_order")
+	if !strings.Contains(got, "coffee_mug") || order < 0 || order > shop {
+		t.Errorf("HydrateMemory() without weighting = %q, want every fact, most confident first", got)
+	}
+}
+
+func TestContextHydrator_MasksSecrets(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.SaveFact(&models.Fact{FactID: "fact_wifi", Key: "wifi_password", Value: "hunter2-wifi", ValueType: models.ValueTypeSecret, Confidence: 1}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+
+	hydrator := NewContextHydrator(store, nil)
+	got := hydrator.HydrateMemory("wifi", 1000)
+	if !strings.Contains(got, "wifi_password: "+redact.Mask) || strings.Contains(got, "hunter2-wifi") {
+		t.Errorf("HydrateMemory() = %q, want the secret fact masked", got)
+	}
+	for _, section := range hydrator.PackMemory("wifi", 1000).Sections {
+		for _, item := range section.Items {
+			if strings.Contains(item.Text, "hunter2-wifi") {
+				t.Errorf("PackMemory() item = %q, want the secret fact masked", item.Text)
+			}
+		}
+	}
+
+	hydrator.SetRevealSecrets(true)
+	if got := hydrator.HydrateMemory("wifi", 1000); !strings.Contains(got, "wifi_password: hunter2-wifi") {
+		t.Errorf("HydrateMemory() revealing secrets = %q, want the secret fact in the clear", got)
+	}
+}
+
 func TestContextHydrator_LimitTokens_WithSections(t *testing.T) {
 	store, err := storage.NewStorageInMemory()
 	if err != nil {
@@ -519,6 +676,32 @@ func TestContextHydrator_FormatUserProfile_AllFields(t *testing.T) {
 	}
 }
 
+func TestContextHydrator_FormatUserProfile_Constraints(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	hydrator := NewContextHydrator(store, nil)
+
+	profile := &models.UserProfile{
+		Name: "Doctor Biz",
+		Constraints: []models.ProfileConstraint{
+			{Type: "Dietary Restriction", Description: "vegetarian", Severity: models.SeverityStrict},
+		},
+	}
+
+	result := hydrator.formatUserProfile(profile)
+
+	if !strings.Contains(result, "Constraints (always respect these):") {
+		t.Error("Expected constraints header in profile")
+	}
+	if !strings.Contains(result, "- Dietary Restriction: vegetarian (strict)") {
+		t.Errorf("Expected constraint line in profile, got:\n%s", result)
+	}
+}
+
 func TestContextHydrator_FormatBlockHistory_EmptyTurns(t *testing.T) {
 	store, err := storage.NewStorageInMemory()
 	if err != nil {
@@ -582,6 +765,39 @@ func TestContextHydrator_FormatBlockHistory_MultipleTurns(t *testing.T) {
 	}
 }
 
+func TestContextHydrator_FormatBlockHistory_Transcript(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	hydrator := NewContextHydrator(store, nil)
+
+	block := &models.BridgeBlock{
+		BlockID:    "block_transcript",
+		TopicLabel: "Deploys",
+		Turns: []models.Turn{{
+			UserMessage: "Is prod up?",
+			AIResponse:  "Yes.",
+			Messages: []models.Message{
+				{Role: models.RoleUser, Content: "Is prod up?"},
+				{Role: models.RoleAssistant, ToolCalls: []models.ToolCall{{Name: "status"}}},
+				{Role: models.RoleTool, Name: "status", Content: "up"},
+				{Role: models.RoleAssistant, Content: "Yes."},
+			},
+		}},
+	}
+
+	result := hydrator.formatBlockHistory(block)
+
+	for _, want := range []string{"User: Is prod up?", "AI: [calls status()]", "Tool (status): up", "AI: Yes."} {
+		if !strings.Contains(result, want) {
+			t.Errorf("formatBlockHistory() = %q, want it to contain %q", result, want)
+		}
+	}
+}
+
 func TestContextHydrator_FormatRetrievedMemories_WithSummary(t *testing.T) {
 	store, err := storage.NewStorageInMemory()
 	if err != nil {
@@ -0,0 +1,113 @@
+// ABOUTME: ContextPack records how ContextHydrator assembled a prompt, for debugging it
+// ABOUTME: Lists every section with its token count and the memories and facts kept or left out, and why
+package core
+
+import (
+	"strings"
+	"time"
+)
+
+// Section names in a ContextPack, in prompt order
+const (
+	PackSystem   = "system"
+	PackProfile  = "profile"
+	PackHistory  = "history"
+	PackMemories = "memories"
+	PackFacts    = "facts"
+	PackMessage  = "message"
+)
+
+// ContextPack is what a hydration assembled and everything it weighed along the way, so
+// a prompt that went wrong can be shared and reproduced
+type ContextPack struct {
+	Query             string        `json:"query"`
+	BlockID           string        `json:"block_id,omitempty"` // Empty when only memory was hydrated (HydrateMemory)
+	Persona           string        `json:"persona,omitempty"`
+	MaxTokens         int           `json:"max_tokens"`
+	Tokens            int           `json:"tokens"` // Of the assembled prompt
+	FactMinConfidence float64       `json:"fact_min_confidence"`
+	FactHalfLife      string        `json:"fact_half_life"`
+	CreatedAt         time.Time     `json:"created_at"`
+	Sections          []PackSection `json:"sections"`
+	Prompt            string        `json:"prompt"`
+}
+
+// PackSection is one section a hydration considered
+type PackSection struct {
+	Name     string     `json:"name"`
+	Included bool       `json:"included"`
+	Reason   string     `json:"reason,omitempty"` // Why the section was left out
+	Tokens   int        `json:"tokens"`
+	Text     string     `json:"text,omitempty"`
+	Items    []PackItem `json:"items,omitempty"`
+}
+
+// PackItem is a retrieved memory or fact a section considered
+type PackItem struct {
+	Kind       string  `json:"kind"` // "memory" or "fact"
+	ID         string  `json:"id"`
+	Text       string  `json:"text"`
+	Score      float64 `json:"score"`                // Relevance for memories; confidence decayed by age for facts
+	Confidence float64 `json:"confidence,omitempty"` // Facts only
+	Included   bool    `json:"included"`
+	Reason     string  `json:"reason,omitempty"` // Why the item was left out
+}
+
+// Reasons a section or item is left out
+const (
+	reasonNoEmbedder = "no embedding client"
+	reasonNoProfile  = "no user profile"
+	reasonNoMatches  = "nothing matched the query"
+	reasonNoneKept   = "every match was left out"
+	reasonSameTopic  = "from the conversation's own topic"
+	reasonBudget     = "does not fit the token budget"
+	reasonTrimmed    = "trimmed to fit max_tokens"
+)
+
+// newPack starts a pack for hydrating query in maxTokens with ch's settings
+func (ch *ContextHydrator) newPack(query string, maxTokens int) *ContextPack {
+	return &ContextPack{
+		Query:             query,
+		Persona:           ch.persona,
+		MaxTokens:         maxTokens,
+		FactMinConfidence: ch.weighting.MinConfidence,
+		FactHalfLife:      ch.weighting.HalfLife.String(),
+		CreatedAt:         ch.storage.Now(),
+	}
+}
+
+// newSection is an included section holding text, or one left out for reason when there is no text
+func newSection(name, text, reason string, items []PackItem) PackSection {
+	if text == "" {
+		return PackSection{Name: name, Reason: reason, Items: items}
+	}
+	return PackSection{Name: name, Included: true, Text: text, Tokens: estimateTokens(text), Items: items}
+}
+
+// drop leaves the section out, and the items it had kept with it
+func (s *PackSection) drop(reason string) {
+	s.Included = false
+	s.Reason = reason
+	for i := range s.Items {
+		if s.Items[i].Included {
+			s.Items[i].Included = false
+			s.Items[i].Reason = reason
+		}
+	}
+}
+
+// finish records the assembled prompt, dropping the sections token limiting cut from it
+func (p *ContextPack) finish(prompt string) {
+	for i := range p.Sections {
+		if s := &p.Sections[i]; s.Included && !strings.Contains(prompt, s.Text) {
+			s.drop(reasonTrimmed)
+		}
+	}
+	p.Prompt = prompt
+	p.Tokens = estimateTokens(prompt)
+}
+
+// estimateTokens approximates the tokens in text (4 chars ≈ 1 token)
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
@@ -0,0 +1,164 @@
+// ABOUTME: Tests for ContextPack, the record of how a prompt was hydrated
+// ABOUTME: Verifies packs match the hydrated prompt and say why sections, memories, and facts were left out
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// fakeEmbedder lets the hydrator search memories; storage does the matching
+type fakeEmbedder struct{}
+
+func (fakeEmbedder) GenerateEmbedding(text string) ([]float64, error) { return []float64{1, 2, 3}, nil }
+
+func packSection(t *testing.T, pack *ContextPack, name string) PackSection {
+	t.Helper()
+	for _, section := range pack.Sections {
+		if section.Name == name {
+			return section
+		}
+	}
+	t.Fatalf("pack has no %s section: %+v", name, pack.Sections)
+	return PackSection{}
+}
+
+func TestContextHydrator_PackBridgeBlock(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, err := store.StoreTurn(&models.Turn{
+		TurnID: "turn_pack_1", Timestamp: time.Now(),
+		UserMessage: "Which espresso machine should I buy?", AIResponse: "A lever machine suits you.",
+		Keywords: []string{"espresso", "machine"}, Topics: []string{"coffee"},
+	})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	for _, f := range []models.Fact{
+		{FactID: "fact_pack_strong", Key: "espresso_machine", Value: "lever", Confidence: 0.9, CreatedAt: time.Now()},
+		{FactID: "fact_pack_weak", Key: "espresso_roast", Value: "dark", Confidence: 0.1, CreatedAt: time.Now()},
+	} {
+		if err := store.SaveFact(&f); err != nil {
+			t.Fatalf("SaveFact() error = %v", err)
+		}
+	}
+
+	hydrator := NewContextHydrator(store, fakeEmbedder{})
+	pack, err := hydrator.PackBridgeBlock(blockID, "espresso", 4000)
+	if err != nil {
+		t.Fatalf("PackBridgeBlock() error = %v", err)
+	}
+	prompt, err := hydrator.HydrateBridgeBlock(blockID, "espresso", 4000)
+	if err != nil {
+		t.Fatalf("HydrateBridgeBlock() error = %v", err)
+	}
+	if pack.Prompt != prompt || pack.Tokens != estimateTokens(prompt) {
+		t.Errorf("pack prompt = %q (%d tokens), want the hydrated prompt %q", pack.Prompt, pack.Tokens, prompt)
+	}
+
+	var names []string
+	for _, section := range pack.Sections {
+		names = append(names, section.Name)
+	}
+	if got := strings.Join(names, ","); got != "system,profile,history,memories,facts,message" {
+		t.Errorf("sections = %s, want every section in prompt order", got)
+	}
+	if profile := packSection(t, pack, PackProfile); profile.Included || profile.Reason != reasonNoProfile {
+		t.Errorf("profile = %+v, want it left out for want of a profile", profile)
+	}
+
+	// The only matching memory is the conversation's own topic
+	memories := packSection(t, pack, PackMemories)
+	if memories.Included || len(memories.Items) != 1 || memories.Items[0].Reason != reasonSameTopic {
+		t.Errorf("memories = %+v, want the current topic left out", memories)
+	}
+
+	facts := packSection(t, pack, PackFacts)
+	if !facts.Included || facts.Tokens != estimateTokens(facts.Text) || len(facts.Items) != 2 {
+		t.Fatalf("facts = %+v, want both facts considered", facts)
+	}
+	if item := facts.Items[0]; item.ID != "fact_pack_strong" || !item.Included || item.Score <= 0 {
+		t.Errorf("first fact = %+v, want the strong fact included with its weight", item)
+	}
+	if item := facts.Items[1]; item.ID != "fact_pack_weak" || item.Included || item.Reason != "below fact_min_confidence" {
+		t.Errorf("second fact = %+v, want the weak fact left out for its confidence", item)
+	}
+
+	// Sections token limiting cuts are marked trimmed, and so are their facts
+	if err := store.SaveUserProfile(&models.UserProfile{Name: "Alice", Preferences: []string{strings.Repeat("long preference ", 40)}}); err != nil {
+		t.Fatalf("SaveUserProfile() error = %v", err)
+	}
+	pack, err = hydrator.PackBridgeBlock(blockID, "espresso", 100)
+	if err != nil {
+		t.Fatalf("PackBridgeBlock() error = %v", err)
+	}
+	if profile := packSection(t, pack, PackProfile); profile.Included || profile.Reason != reasonTrimmed {
+		t.Errorf("profile = %+v, want it trimmed", profile)
+	}
+	if strings.Contains(pack.Prompt, "USER PROFILE") {
+		t.Errorf("prompt = %q, want no profile", pack.Prompt)
+	}
+	for _, section := range pack.Sections {
+		if section.Included && !strings.Contains(pack.Prompt, section.Text) {
+			t.Errorf("section %s is marked included but not in the prompt", section.Name)
+		}
+	}
+
+	if _, err := hydrator.PackBridgeBlock("block_missing", "espresso", 4000); err == nil {
+		t.Error("PackBridgeBlock() of a missing block succeeded, want an error")
+	}
+}
+
+func TestContextHydrator_PackMemory(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if _, err := store.StoreTurn(&models.Turn{
+		TurnID: "turn_pack_memory", Timestamp: time.Now(),
+		UserMessage: "I switched my editor to helix", AIResponse: "Noted.",
+		Keywords: []string{"editor", "helix"}, Topics: []string{"tools"},
+	}); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_pack_editor", Key: "editor", Value: "helix", Confidence: 1.0}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+	if err := store.SaveUserProfile(&models.UserProfile{Name: "Alice", Preferences: []string{strings.Repeat("long preference ", 40)}}); err != nil {
+		t.Fatalf("SaveUserProfile() error = %v", err)
+	}
+
+	withoutEmbedder := NewContextHydrator(store, nil).PackMemory("editor", 1000)
+	if memories := packSection(t, withoutEmbedder, PackMemories); memories.Included || memories.Reason != reasonNoEmbedder {
+		t.Errorf("memories without an embedder = %+v, want them left out", memories)
+	}
+
+	hydrator := NewContextHydrator(store, fakeEmbedder{})
+	pack := hydrator.PackMemory("editor", 1000)
+	if pack.Prompt != hydrator.HydrateMemory("editor", 1000) || pack.BlockID != "" {
+		t.Errorf("pack = %+v, want the HydrateMemory result and no block", pack)
+	}
+	memories := packSection(t, pack, PackMemories)
+	if !memories.Included || len(memories.Items) != 1 || !memories.Items[0].Included || memories.Items[0].Kind != "memory" {
+		t.Errorf("memories = %+v, want the helix topic included", memories)
+	}
+
+	// The profile is dropped first when the budget is tight
+	pack = hydrator.PackMemory("editor", 60)
+	if profile := packSection(t, pack, PackProfile); profile.Included || profile.Reason != reasonBudget || profile.Text == "" {
+		t.Errorf("profile = %+v, want it left out over budget with its text kept", profile)
+	}
+	if facts := packSection(t, pack, PackFacts); !facts.Included {
+		t.Errorf("facts = %+v, want them kept", facts)
+	}
+}
@@ -4,14 +4,18 @@ package core
 
 import (
 	"fmt"
-	"time"
+	"log"
+	"sort"
+	"strings"
 
-	"github.com/google/uuid"
 	"github.com/harper/remember-standalone/internal/llm"
 	"github.com/harper/remember-standalone/internal/models"
 	"github.com/harper/remember-standalone/internal/storage"
 )
 
+// FactScrubberAgent is the extracted_by value recorded on facts the FactScrubber saves
+const FactScrubberAgent = "fact_scrubber"
+
 // FactScrubber extracts and saves facts from conversation turns
 type FactScrubber struct {
 	client *llm.OpenAIClient
@@ -25,7 +29,7 @@ func NewFactScrubber(client *llm.OpenAIClient) *FactScrubber {
 }
 
 // ExtractAndSave extracts facts from a turn and saves them to storage
-// Links facts to the specified block_id and turn_id
+// Links facts to the specified block_id and turn_id; with fact review on, they are saved pending approval
 func (fs *FactScrubber) ExtractAndSave(turn *models.Turn, blockID string, store *storage.Storage) error {
 	// Extract facts from USER MESSAGE ONLY
 	// This ensures we capture information the user provides, regardless of AI response quality
@@ -38,17 +42,33 @@ func (fs *FactScrubber) ExtractAndSave(turn *models.Turn, blockID string, store
 		return fmt.Errorf("failed to extract facts: %w", err)
 	}
 
+	// Drop junk and repeats before they reach the store
+	extracted := len(facts)
+	facts, err = limitFacts(facts, store.FactLimits(), store)
+	if err != nil {
+		return err
+	}
+	if len(facts) < extracted {
+		log.Printf("[FactScrubber] kept %d of %d facts extracted from turn %s", len(facts), extracted, turn.TurnID)
+	}
+
 	// If no facts extracted, that's OK - not every conversation has extractable facts
 	if len(facts) == 0 {
 		return nil
 	}
 
-	// Enrich facts with IDs, block_id, turn_id, and timestamps
+	// Enrich facts with IDs, block_id, turn_id, timestamps, and provenance
 	for i := range facts {
-		facts[i].FactID = "fact_" + uuid.New().String()
+		facts[i].FactID = models.NewID("fact")
 		facts[i].BlockID = blockID
 		facts[i].TurnID = turn.TurnID
-		facts[i].CreatedAt = time.Now()
+		facts[i].CreatedAt = store.Now()
+		facts[i].ExtractedBy = FactScrubberAgent
+		facts[i].Agent = turn.Origin.Agent
+		facts[i].Pending = store.FactReview()
+		if facts[i].SourceQuote == "" {
+			facts[i].SourceQuote = turn.UserMessage
+		}
 	}
 
 	// Save facts to storage
@@ -58,3 +78,69 @@ func (fs *FactScrubber) ExtractAndSave(turn *models.Turn, blockID string, store
 
 	return nil
 }
+
+// limitFacts applies limits to the facts extracted from one turn: it discards those
+// below the minimum confidence, then with Dedupe keeps the most confident fact per key
+// and skips any repeating its key's current value, then keeps the MaxPerTurn most
+// confident. The facts kept stay in extraction order.
+func limitFacts(facts []models.Fact, limits models.FactLimits, store *storage.Storage) ([]models.Fact, error) {
+	kept := make([]models.Fact, 0, len(facts))
+	byKey := make(map[string]int)
+	for _, fact := range facts {
+		if fact.Confidence < limits.MinConfidence {
+			continue
+		}
+		if limits.Dedupe {
+			if fact.Key = normalizeFactKey(fact.Key); fact.Key == "" {
+				continue
+			}
+			if i, ok := byKey[fact.Key]; ok {
+				if fact.Confidence > kept[i].Confidence {
+					kept[i] = fact
+				}
+				continue
+			}
+			byKey[fact.Key] = len(kept)
+		}
+		kept = append(kept, fact)
+	}
+
+	if limits.Dedupe {
+		fresh := kept[:0]
+		for _, fact := range kept {
+			current, err := store.GetFactByKey(fact.Key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up fact %s: %w", fact.Key, err)
+			}
+			if current != nil && strings.EqualFold(strings.TrimSpace(current.Value), strings.TrimSpace(fact.Value)) {
+				continue
+			}
+			fresh = append(fresh, fact)
+		}
+		kept = fresh
+	}
+
+	if limits.MaxPerTurn > 0 && len(kept) > limits.MaxPerTurn {
+		order := make([]int, len(kept))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return kept[order[a]].Confidence > kept[order[b]].Confidence })
+		order = order[:limits.MaxPerTurn]
+		sort.Ints(order)
+		top := make([]models.Fact, len(order))
+		for i, j := range order {
+			top[i] = kept[j]
+		}
+		kept = top
+	}
+	return kept, nil
+}
+
+// normalizeFactKey writes a key the way the extraction prompt asks for, lowercase with
+// underscores, so "Favorite Language" and "favorite-language" are one key
+func normalizeFactKey(key string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(key), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_' || r == '\t'
+	}), "_")
+}
@@ -1,12 +1,16 @@
 // ABOUTME: Tests for FactScrubber fact extraction
-// ABOUTME: Verifies fact extraction and storage linking
+// ABOUTME: Verifies fact extraction, storage linking, and the limits on what one extraction saves
 
 package core
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/harper/remember-standalone/internal/llm"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
 )
 
 func TestNewFactScrubber(t *testing.T) {
@@ -33,3 +37,52 @@ func TestNewFactScrubber_WithClient(t *testing.T) {
 
 // Note: Full ExtractAndSave testing would require mocking the OpenAI client,
 // which isn't practical here. The structure is tested in integration tests.
+
+func TestLimitFacts(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	if err := store.SaveFact(&models.Fact{FactID: "fact_city", Key: "city", Value: "Chicago", Confidence: 1}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+
+	extracted := []models.Fact{
+		{Key: "name", Value: "Sam", Confidence: 0.9},
+		{Key: "Favorite Language", Value: "Go", Confidence: 0.6},
+		{Key: "favorite-language", Value: "Go", Confidence: 0.8},
+		{Key: "mood", Value: "meh", Confidence: 0.1},
+		{Key: "city", Value: " chicago", Confidence: 1},
+		{Key: "role", Value: "engineer", Confidence: 0.5},
+	}
+	keys := func(facts []models.Fact) string {
+		var out []string
+		for _, f := range facts {
+			out = append(out, fmt.Sprintf("%s=%s@%g", f.Key, f.Value, f.Confidence))
+		}
+		return strings.Join(out, " ")
+	}
+
+	tests := []struct {
+		name   string
+		limits models.FactLimits
+		want   string
+	}{
+		{"no limits", models.FactLimits{}, "name=Sam@0.9 Favorite Language=Go@0.6 favorite-language=Go@0.8 mood=meh@0.1 city= chicago@1 role=engineer@0.5"},
+		{"min confidence", models.FactLimits{MinConfidence: 0.2}, "name=Sam@0.9 Favorite Language=Go@0.6 favorite-language=Go@0.8 city= chicago@1 role=engineer@0.5"},
+		{"dedupe", models.FactLimits{Dedupe: true}, "name=Sam@0.9 favorite_language=Go@0.8 mood=meh@0.1 role=engineer@0.5"},
+		{"defaults", models.FactLimits{MaxPerTurn: 2, MinConfidence: 0.2, Dedupe: true}, "name=Sam@0.9 favorite_language=Go@0.8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := limitFacts(append([]models.Fact(nil), extracted...), tt.limits, store)
+			if err != nil {
+				t.Fatalf("limitFacts() error = %v", err)
+			}
+			if keys(got) != tt.want {
+				t.Errorf("limitFacts() = %s\nwant %s", keys(got), tt.want)
+			}
+		})
+	}
+}
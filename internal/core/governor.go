@@ -3,40 +3,176 @@
 package core
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"strings"
+	"time"
 
 	"github.com/harper/remember-standalone/internal/models"
 	"github.com/harper/remember-standalone/internal/storage"
+	"github.com/harper/remember-standalone/internal/tracing"
 )
 
+// DefaultTopicMatchThreshold is the keyword overlap a turn needs to match a block unless configured
+const DefaultTopicMatchThreshold = 0.3
+
+// DefaultSemanticMatchThreshold is the centroid similarity a turn needs to match a block
+// in embedding routing unless configured
+const DefaultSemanticMatchThreshold = 0.5
+
+// DefaultResumptionWindow is how recently a paused block must have been active for a turn to resume it unless configured
+const DefaultResumptionWindow = 14 * 24 * time.Hour
+
+// Embedder embeds a turn so embedding routing can compare it with each block's centroid
+type Embedder interface {
+	GenerateEmbedding(text string) ([]float64, error)
+	EmbeddingModel() string
+}
+
 // Governor is the smart router that decides routing scenarios
 type Governor struct {
-	storage               *storage.Storage
-	topicMatchThreshold   float64 // Threshold for keyword overlap (0.0-1.0, default 0.3 for 30%)
+	storage             *storage.Storage
+	topicMatchThreshold float64       // Threshold for keyword overlap (0.0-1.0, default 0.3 for 30%)
+	resumptionWindow    time.Duration // Paused blocks idle longer than this are not resumed; 0 resumes any
+	embedder            Embedder      // Embedding routing: compare turns with block centroids; nil routes on keywords alone
+	semanticThreshold   float64       // Centroid similarity a turn needs to match a block in embedding routing
 }
 
 // NewGovernor creates a new Governor instance
 func NewGovernor(store *storage.Storage) *Governor {
 	return &Governor{
 		storage:             store,
-		topicMatchThreshold: 0.3, // Default to 30% keyword overlap
+		topicMatchThreshold: DefaultTopicMatchThreshold,
+		resumptionWindow:    DefaultResumptionWindow,
+		semanticThreshold:   DefaultSemanticMatchThreshold,
+	}
+}
+
+// SetEmbedder turns on embedding routing: a turn is embedded and matches a block whose
+// centroid embedding is at least threshold similar (cosine, -1 to 1). Until every candidate
+// block has a centroid of the embedder's model, turns are routed on keywords. A nil
+// embedder turns it off.
+func (g *Governor) SetEmbedder(embedder Embedder, threshold float64) {
+	g.embedder = embedder
+	g.semanticThreshold = threshold
+}
+
+// SetTopicMatchThreshold sets the keyword overlap (0.0-1.0) a turn needs to match a block
+func (g *Governor) SetTopicMatchThreshold(threshold float64) {
+	g.topicMatchThreshold = threshold
+}
+
+// TopicMatchThreshold returns the keyword overlap a turn needs to match a block
+func (g *Governor) TopicMatchThreshold() float64 {
+	return g.topicMatchThreshold
+}
+
+// SetResumptionWindow sets how recently a paused block must have been active for a turn
+// to resume it; 0 lets turns resume paused blocks of any age
+func (g *Governor) SetResumptionWindow(window time.Duration) {
+	g.resumptionWindow = window
+}
+
+// ResumptionWindow returns how recently a paused block must have been active for a turn to resume it
+func (g *Governor) ResumptionWindow() time.Duration {
+	return g.resumptionWindow
+}
+
+// Resumable reports whether block, last updated when it was paused, is recent enough to
+// resume within window of now; a window of 0 allows any age
+func Resumable(block *models.BridgeBlock, window time.Duration, now time.Time) bool {
+	return window <= 0 || !block.UpdatedAt.Before(now.Add(-window))
+}
+
+// windowString renders a resumption window in days when it is a whole number of them
+func windowString(window time.Duration) string {
+	if day := 24 * time.Hour; window%day == 0 {
+		return fmt.Sprintf("%d-day", window/day)
 	}
+	return window.String()
+}
+
+// Resume makes the paused block blockID the active topic, pausing the blocks that were
+// active, and returns it with the IDs it paused. Blocks idle longer than window are
+// refused unless window is 0.
+func (g *Governor) Resume(blockID string, window time.Duration) (*models.BridgeBlock, []string, error) {
+	block, err := g.storage.GetBridgeBlock(blockID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get block: %w", err)
+	}
+	if block == nil {
+		return nil, nil, fmt.Errorf("block %s not found", blockID)
+	}
+	if block.Status != models.StatusPaused {
+		return nil, nil, fmt.Errorf("block %s is %s; only paused topics can be resumed", blockID, block.Status)
+	}
+	if !Resumable(block, window, g.storage.Now()) {
+		return nil, nil, fmt.Errorf("block %s has been idle since %s, outside the %s resumption window", blockID, block.UpdatedAt.Format(time.RFC3339), windowString(window))
+	}
+
+	activeBlocks, err := g.storage.GetActiveBridgeBlocks()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get active blocks: %w", err)
+	}
+	paused := []string{}
+	for _, active := range activeBlocks {
+		if err := g.storage.UpdateBridgeBlockStatus(active.BlockID, models.StatusPaused); err != nil {
+			return nil, nil, fmt.Errorf("failed to pause active block: %w", err)
+		}
+		paused = append(paused, active.BlockID)
+	}
+	if err := g.storage.UpdateBridgeBlockStatus(blockID, models.StatusActive); err != nil {
+		return nil, nil, fmt.Errorf("failed to reactivate block: %w", err)
+	}
+	block.Status = models.StatusActive
+	return block, paused, nil
 }
 
 // Route determines which routing scenario applies for the given turn
 // Returns a RoutingDecision indicating the scenario and relevant block IDs
 func (g *Governor) Route(turn *models.Turn) (models.RoutingDecision, error) {
+	return g.RouteContext(context.Background(), turn)
+}
+
+// RouteContext is Route, traced as a child of the span in ctx
+func (g *Governor) RouteContext(ctx context.Context, turn *models.Turn) (decision models.RoutingDecision, err error) {
+	_, span := tracing.Start(ctx, "governor.route")
+	defer func() {
+		span.SetAttributes(tracing.String("routing.scenario", string(decision.Scenario)))
+		span.RecordError(err)
+		span.End()
+	}()
+	decision, threshold, err := g.route(turn)
+	if err != nil {
+		return decision, err
+	}
+	// The log is for tuning; failing to write it should not fail the store
+	if err := g.storage.LogRoutingDecision(turn.TurnID, decision, threshold); err != nil {
+		log.Printf("[Governor] %v", err)
+	}
+	return decision, nil
+}
+
+// route picks the routing scenario for turn, with the threshold its score was measured against
+func (g *Governor) route(turn *models.Turn) (models.RoutingDecision, float64, error) {
 	// Get active blocks
 	activeBlocks, err := g.storage.GetActiveBridgeBlocks()
 	if err != nil {
-		return models.RoutingDecision{}, fmt.Errorf("failed to get active blocks: %w", err)
+		return models.RoutingDecision{}, 0, fmt.Errorf("failed to get active blocks: %w", err)
 	}
 
-	// Get paused blocks
-	pausedBlocks, err := g.storage.GetPausedBridgeBlocks()
+	// Get paused blocks recent enough to resume
+	paused, err := g.storage.GetPausedBridgeBlocks()
 	if err != nil {
-		return models.RoutingDecision{}, fmt.Errorf("failed to get paused blocks: %w", err)
+		return models.RoutingDecision{}, 0, fmt.Errorf("failed to get paused blocks: %w", err)
+	}
+	now := g.storage.Now()
+	pausedBlocks := paused[:0]
+	for _, block := range paused {
+		if Resumable(&block, g.resumptionWindow, now) {
+			pausedBlocks = append(pausedBlocks, block)
+		}
 	}
 
 	// Scenario 3: No active blocks → create new block (first topic)
@@ -45,23 +181,47 @@ func (g *Governor) Route(turn *models.Turn) (models.RoutingDecision, error) {
 			Scenario:       models.NewTopicFirst,
 			MatchedBlockID: "",
 			ActiveBlockID:  "",
-		}, nil
+		}, g.topicMatchThreshold, nil
+	}
+
+	// How many of the turn's keywords each block shares, from the keyword index
+	matches, err := g.storage.MatchBlockKeywords(turn.Keywords, false)
+	if err != nil {
+		return models.RoutingDecision{}, 0, fmt.Errorf("failed to match keywords: %w", err)
+	}
+	// In embedding routing, how similar the turn is to each block's centroid
+	similarities := g.centroidSimilarities(turn, activeBlocks, pausedBlocks)
+
+	// Score every candidate, so the log shows near misses as well as the match
+	scores := make(map[string]float64)
+	best, bestID := 0.0, ""
+	for _, blocks := range [][]models.BridgeBlock{activeBlocks, pausedBlocks} {
+		for i := range blocks {
+			if score, ok := g.topicScore(turn, &blocks[i], matches, similarities); ok && score > 0 {
+				scores[blocks[i].BlockID] = score
+				if score > best {
+					best, bestID = score, blocks[i].BlockID
+				}
+			}
+		}
 	}
 
 	// Check if turn matches any active block (Scenario 1: Continuation)
 	for _, block := range activeBlocks {
-		if g.matchesTopic(turn, &block) {
+		if g.matchesTopic(turn, &block, matches, similarities) {
 			return models.RoutingDecision{
 				Scenario:       models.TopicContinuation,
 				MatchedBlockID: block.BlockID,
 				ActiveBlockID:  block.BlockID,
-			}, nil
+				Score:          scores[block.BlockID],
+				Scores:         scores,
+			}, g.threshold(block.BlockID, similarities), nil
 		}
 	}
 
 	// Check if turn matches any paused block (Scenario 2: Resumption)
 	for _, block := range pausedBlocks {
-		if g.matchesTopic(turn, &block) {
+		if g.matchesTopic(turn, &block, matches, similarities) {
 			activeBlockID := ""
 			if len(activeBlocks) > 0 {
 				activeBlockID = activeBlocks[0].BlockID
@@ -70,7 +230,9 @@ func (g *Governor) Route(turn *models.Turn) (models.RoutingDecision, error) {
 				Scenario:       models.TopicResumption,
 				MatchedBlockID: block.BlockID,
 				ActiveBlockID:  activeBlockID,
-			}, nil
+				Score:          scores[block.BlockID],
+				Scores:         scores,
+			}, g.threshold(block.BlockID, similarities), nil
 		}
 	}
 
@@ -83,39 +245,109 @@ func (g *Governor) Route(turn *models.Turn) (models.RoutingDecision, error) {
 		Scenario:       models.TopicShift,
 		MatchedBlockID: "",
 		ActiveBlockID:  activeBlockID,
-	}, nil
+		Score:          best,
+		Scores:         scores,
+	}, g.threshold(bestID, similarities), nil
+}
+
+// RecordRelations links blockID, where a turn routed by decision was stored, to the block it
+// came from: a topic shift records the new block as spawned-from the block that was active
+func (g *Governor) RecordRelations(decision models.RoutingDecision, blockID string) error {
+	if decision.Scenario != models.TopicShift || decision.ActiveBlockID == "" || decision.ActiveBlockID == blockID {
+		return nil
+	}
+	if err := g.storage.RelateBlocks(blockID, decision.ActiveBlockID, models.RelationSpawnedFrom); err != nil {
+		return fmt.Errorf("failed to record relation: %w", err)
+	}
+	return nil
 }
 
-// matchesTopic determines if a turn matches a block's topic based on keywords and topics
-func (g *Governor) matchesTopic(turn *models.Turn, block *models.BridgeBlock) bool {
+// matchesTopic determines if a turn matches a block's topic based on topics and keywords,
+// or its centroid in embedding routing; matches holds the keyword index's count of turn
+// keywords each block shares, and similarities the turn's similarity to block centroids
+func (g *Governor) matchesTopic(turn *models.Turn, block *models.BridgeBlock, matches map[string]storage.KeywordMatch, similarities map[string]float64) bool {
+	// Match by keywords - require at least 30% keyword overlap
+	score, ok := g.topicScore(turn, block, matches, similarities)
+	return ok && score >= g.threshold(block.BlockID, similarities)
+}
+
+// threshold returns the score a turn needs to match blockID: the semantic threshold when
+// the block was scored by its centroid, else the keyword overlap threshold
+func (g *Governor) threshold(blockID string, similarities map[string]float64) float64 {
+	if _, ok := similarities[blockID]; ok {
+		return g.semanticThreshold
+	}
+	return g.topicMatchThreshold
+}
+
+// topicScore scores how well a turn fits a block: 1 when one of its topics is the block's
+// label, else the turn's similarity to the block's centroid when there is one, else the
+// share of the turn's distinct keywords the block has. ok is false when the turn has
+// nothing to compare.
+func (g *Governor) topicScore(turn *models.Turn, block *models.BridgeBlock, matches map[string]storage.KeywordMatch, similarities map[string]float64) (score float64, ok bool) {
 	// Match by topic label
 	for _, turnTopic := range turn.Topics {
 		if turnTopic == block.TopicLabel {
-			return true
+			return 1, true
 		}
 	}
-
-	// Match by keywords - require at least 30% keyword overlap
-	if len(turn.Keywords) == 0 || len(block.Keywords) == 0 {
-		return false
+	if similarity, ok := similarities[block.BlockID]; ok {
+		return similarity, true
 	}
 
-	matchCount := 0
-	for _, turnKeyword := range turn.Keywords {
-		for _, blockKeyword := range block.Keywords {
-			if g.keywordMatch(turnKeyword, blockKeyword) {
-				matchCount++
-				break // Count each turn keyword only once
-			}
-		}
+	terms := storage.KeywordTerms(turn.Keywords)
+	if len(terms) == 0 {
+		return 0, false
 	}
 
-	// Calculate overlap as a percentage of turn keywords
-	overlapRatio := float64(matchCount) / float64(len(turn.Keywords))
-	return overlapRatio >= g.topicMatchThreshold
+	// Calculate overlap as a percentage of the turn's distinct keywords
+	return float64(matches[block.BlockID].Exact) / float64(len(terms)), true
 }
 
-// keywordMatch checks if two keywords match (case-insensitive)
-func (g *Governor) keywordMatch(k1, k2 string) bool {
-	return strings.EqualFold(k1, k2)
+// centroidSimilarities embeds turn's message and returns its cosine similarity to the
+// centroid of each candidate block. Cosine and keyword scores are not comparable, so
+// unless every candidate has a centroid of the embedder's model it returns nil and the
+// whole decision is made on keywords; nothing is embedded in that case. Failures are
+// logged and leave routing to keywords rather than failing the store.
+func (g *Governor) centroidSimilarities(turn *models.Turn, candidates ...[]models.BridgeBlock) map[string]float64 {
+	if g.embedder == nil || strings.TrimSpace(turn.UserMessage) == "" {
+		return nil
+	}
+	var blockIDs []string
+	for _, blocks := range candidates {
+		for _, block := range blocks {
+			blockIDs = append(blockIDs, block.BlockID)
+		}
+	}
+	centroids, err := g.storage.BlockCentroids(blockIDs)
+	if err != nil {
+		log.Printf("[Governor] %v; routing on keywords", err)
+		return nil
+	}
+	model := g.embedder.EmbeddingModel()
+	comparable := 0
+	for _, centroid := range centroids {
+		if centroid.Model == model {
+			comparable++
+		}
+	}
+	if len(blockIDs) == 0 || comparable < len(blockIDs) {
+		return nil
+	}
+
+	vector, err := g.embedder.GenerateEmbedding(turn.UserMessage)
+	if err != nil {
+		log.Printf("[Governor] failed to embed turn for routing: %v; routing on keywords", err)
+		return nil
+	}
+	similarities := make(map[string]float64, comparable)
+	for id, centroid := range centroids {
+		if centroid.Model == model && len(centroid.Vector) == len(vector) {
+			similarities[id] = storage.CosineSimilarity(vector, centroid.Vector)
+		}
+	}
+	if len(similarities) < len(blockIDs) {
+		return nil
+	}
+	return similarities
 }
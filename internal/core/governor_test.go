@@ -4,6 +4,8 @@
 package core
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -154,6 +156,22 @@ func TestGovernor_Scenario4_TopicShift(t *testing.T) {
 	if decision.ActiveBlockID != blockID {
 		t.Errorf("ActiveBlockID = %q, want %q", decision.ActiveBlockID, blockID)
 	}
+
+	// The new block is linked back to the block it shifted away from
+	newBlockID, err := store.StoreTurn(turn)
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if err := gov.RecordRelations(decision, newBlockID); err != nil {
+		t.Fatalf("RecordRelations() error = %v", err)
+	}
+	related, err := store.GetRelatedTopics(newBlockID)
+	if err != nil {
+		t.Fatalf("GetRelatedTopics() error = %v", err)
+	}
+	if len(related) != 1 || related[0].BlockID != blockID || related[0].Relation != string(models.RelationSpawnedFrom) {
+		t.Errorf("related topics = %+v, want spawned-from %s", related, blockID)
+	}
 }
 
 func TestGovernor_Scenario2_TopicResumption(t *testing.T) {
@@ -222,6 +240,29 @@ func TestGovernor_Scenario2_TopicResumption(t *testing.T) {
 	}
 }
 
+// matchTopic runs matchesTopic with keyword counts from the keyword index, storing
+// block first (which gives it a new ID) if it is not stored yet
+func matchTopic(t *testing.T, gov *Governor, turn *models.Turn, block *models.BridgeBlock) bool {
+	t.Helper()
+	if stored, err := gov.storage.GetBridgeBlock(block.BlockID); err != nil || stored == nil {
+		id, err := gov.storage.StoreTurn(&models.Turn{
+			TurnID:    fmt.Sprintf("turn_seed_%d", time.Now().UnixNano()),
+			Timestamp: time.Now(),
+			Keywords:  block.Keywords,
+			Topics:    []string{block.TopicLabel},
+		})
+		if err != nil {
+			t.Fatalf("StoreTurn() error = %v", err)
+		}
+		block.BlockID = id
+	}
+	matches, err := gov.storage.MatchBlockKeywords(turn.Keywords, false)
+	if err != nil {
+		t.Fatalf("MatchBlockKeywords() error = %v", err)
+	}
+	return gov.matchesTopic(turn, block, matches, nil)
+}
+
 func TestGovernor_KeywordMatch(t *testing.T) {
 	store, err := storage.NewStorageInMemory()
 	if err != nil {
@@ -246,9 +287,10 @@ func TestGovernor_KeywordMatch(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := gov.keywordMatch(tt.k1, tt.k2)
-			if result != tt.match {
-				t.Errorf("keywordMatch(%q, %q) = %v, want %v", tt.k1, tt.k2, result, tt.match)
+			turn := &models.Turn{TurnID: "turn_test", Keywords: []string{tt.k1}}
+			block := &models.BridgeBlock{TopicLabel: "other-topic", Keywords: []string{tt.k2}}
+			if result := matchTopic(t, gov, turn, block); result != tt.match {
+				t.Errorf("keyword %q against block keyword %q matched = %v, want %v", tt.k1, tt.k2, result, tt.match)
 			}
 		})
 	}
@@ -275,7 +317,7 @@ func TestGovernor_MatchesTopic_ByTopicLabel(t *testing.T) {
 		Topics:   []string{"programming"}, // Matches block's TopicLabel
 	}
 
-	if !gov.matchesTopic(turn, block) {
+	if !matchTopic(t, gov, turn, block) {
 		t.Error("matchesTopic() should match by topic label")
 	}
 }
@@ -335,7 +377,7 @@ func TestGovernor_MatchesTopic_ByKeywordOverlap(t *testing.T) {
 				Topics:   []string{"different"},
 			}
 
-			result := gov.matchesTopic(turn, block)
+			result := matchTopic(t, gov, turn, block)
 			if result != tt.match {
 				t.Errorf("matchesTopic() = %v, want %v", result, tt.match)
 			}
@@ -343,6 +385,38 @@ func TestGovernor_MatchesTopic_ByKeywordOverlap(t *testing.T) {
 	}
 }
 
+func TestGovernor_SetTopicMatchThreshold(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	gov := NewGovernor(store)
+	block := &models.BridgeBlock{
+		BlockID:    "block_001",
+		TopicLabel: "other-topic",
+		Keywords:   []string{"go", "programming", "backend"},
+	}
+	// 1 of 3 keywords overlap (33%)
+	turn := &models.Turn{
+		TurnID:   "turn_test",
+		Keywords: []string{"go", "unrelated", "other"},
+	}
+
+	if !matchTopic(t, gov, turn, block) {
+		t.Fatal("matchesTopic() = false at default threshold, want true")
+	}
+
+	gov.SetTopicMatchThreshold(0.5)
+	if gov.TopicMatchThreshold() != 0.5 {
+		t.Errorf("TopicMatchThreshold() = %v, want 0.5", gov.TopicMatchThreshold())
+	}
+	if matchTopic(t, gov, turn, block) {
+		t.Error("matchesTopic() = true at 0.5 threshold, want false")
+	}
+}
+
 func TestGovernor_MatchesTopic_EmptyBlockKeywords(t *testing.T) {
 	store, err := storage.NewStorageInMemory()
 	if err != nil {
@@ -365,7 +439,240 @@ func TestGovernor_MatchesTopic_EmptyBlockKeywords(t *testing.T) {
 	}
 
 	// Should not match (no keyword overlap possible)
-	if gov.matchesTopic(turn, block) {
+	if matchTopic(t, gov, turn, block) {
 		t.Error("matchesTopic() should return false when block has no keywords")
 	}
 }
+
+func TestGovernor_LogsDecisions(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, err := store.StoreTurn(&models.Turn{
+		TurnID:    "turn_logged_seed",
+		Timestamp: time.Now(),
+		Keywords:  []string{"go", "programming", "backend"},
+		Topics:    []string{"programming"},
+	})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	gov := NewGovernor(store)
+	gov.SetTopicMatchThreshold(0.5)
+
+	// 1 of 4 keywords overlap: a near miss at 0.5
+	decision, err := gov.Route(&models.Turn{TurnID: "turn_logged", Keywords: []string{"go", "pasta", "recipe", "dinner"}})
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if decision.Scenario != models.TopicShift || decision.Score != 0.25 || decision.Scores[blockID] != 0.25 {
+		t.Errorf("decision = %+v, want a shift scoring the block 0.25", decision)
+	}
+
+	stats, err := store.RoutingStats(time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("RoutingStats() error = %v", err)
+	}
+	if stats.Total != 1 || stats.NearMisses != 1 || stats.Threshold != 0.5 {
+		t.Errorf("RoutingStats() = %+v, want the one near miss logged at 0.5", stats)
+	}
+}
+
+func TestGovernor_ResumptionWindow(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	clock := models.NewSimulatedClock(time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC))
+	store.SetClock(clock)
+
+	// Go programming is paused when cooking starts
+	goBlockID, err := store.StoreTurn(&models.Turn{
+		TurnID:    "turn_go",
+		Timestamp: clock.Now(),
+		Keywords:  []string{"go", "programming"},
+		Topics:    []string{"go-programming"},
+	})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	cookingBlockID, err := store.StoreTurn(&models.Turn{
+		TurnID:    "turn_cooking",
+		Timestamp: clock.Advance(time.Hour),
+		Keywords:  []string{"cooking", "food"},
+		Topics:    []string{"cooking"},
+	})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	gov := NewGovernor(store)
+	if gov.ResumptionWindow() != DefaultResumptionWindow {
+		t.Errorf("ResumptionWindow() = %v, want %v", gov.ResumptionWindow(), DefaultResumptionWindow)
+	}
+
+	// A paused block idle past the window is not resumed by a matching turn
+	clock.Advance(DefaultResumptionWindow + time.Hour)
+	turn := &models.Turn{
+		TurnID:   "turn_resume_go",
+		Keywords: []string{"go", "programming"},
+		Topics:   []string{"go-programming"},
+	}
+	decision, err := gov.Route(turn)
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if decision.Scenario != models.TopicShift {
+		t.Errorf("Scenario outside the window = %v, want TopicShift", decision.Scenario)
+	}
+	if _, _, err := gov.Resume(goBlockID, gov.ResumptionWindow()); err == nil || !strings.Contains(err.Error(), "resumption window") {
+		t.Errorf("Resume() outside the window error = %v, want the window named", err)
+	}
+
+	// No window resumes any age
+	gov.SetResumptionWindow(0)
+	decision, err = gov.Route(turn)
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if decision.Scenario != models.TopicResumption || decision.MatchedBlockID != goBlockID {
+		t.Errorf("Route() with no window = %v %q, want TopicResumption of %q", decision.Scenario, decision.MatchedBlockID, goBlockID)
+	}
+
+	block, paused, err := gov.Resume(goBlockID, 0)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if block.Status != models.StatusActive || len(paused) != 1 || paused[0] != cookingBlockID {
+		t.Errorf("Resume() = %v, paused %v; want active, pausing %q", block.Status, paused, cookingBlockID)
+	}
+	if cooking, err := store.GetBridgeBlock(cookingBlockID); err != nil || cooking.Status != models.StatusPaused {
+		t.Errorf("cooking block after Resume() = %+v, %v; want paused", cooking, err)
+	}
+	if _, _, err := gov.Resume(goBlockID, 0); err == nil {
+		t.Error("Resume() of an active block succeeded, want an error")
+	}
+}
+
+// routingEmbedder embeds each message as the vector given for it
+type routingEmbedder struct {
+	model   string
+	vectors map[string][]float64
+	calls   int
+}
+
+func (f *routingEmbedder) GenerateEmbedding(text string) ([]float64, error) {
+	f.calls++
+	vector, ok := f.vectors[text]
+	if !ok {
+		return nil, fmt.Errorf("no embedding for %q", text)
+	}
+	return vector, nil
+}
+
+func (f *routingEmbedder) EmbeddingModel() string { return f.model }
+
+func TestGovernor_EmbeddingRouting(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// Gardening is paused when cooking starts; each has a centroid
+	embed := func(turnID, blockID string, vector []float64) {
+		t.Helper()
+		if err := store.ReplaceTurnEmbeddings(turnID, blockID, "model-a", []models.Chunk{{ChunkID: "chunk_" + turnID, TurnID: turnID}}, [][]float64{vector}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gardenID, err := store.StoreTurn(&models.Turn{TurnID: "turn_garden", Timestamp: time.Now(), Keywords: []string{"tomatoes", "beds"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	embed("turn_garden", gardenID, []float64{0, 1})
+	cookingID, err := store.StoreTurn(&models.Turn{TurnID: "turn_cooking", Timestamp: time.Now(), Keywords: []string{"pasta", "sauce"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	embed("turn_cooking", cookingID, []float64{1, 0})
+
+	embedder := &routingEmbedder{model: "model-a", vectors: map[string][]float64{
+		"what should I plant next to the peppers": {0.1, 1},
+		"how long does the dough rest":            {1, 0.2},
+		"something else entirely":                 {-1, -1},
+	}}
+	gov := NewGovernor(store)
+	gov.SetEmbedder(embedder, 0.8)
+
+	// No keywords in common, but close to a centroid
+	route := func(message string) models.RoutingDecision {
+		t.Helper()
+		decision, err := gov.Route(&models.Turn{TurnID: "turn_" + message, UserMessage: message, Keywords: []string{"unrelated"}})
+		if err != nil {
+			t.Fatalf("Route(%q) error = %v", message, err)
+		}
+		return decision
+	}
+	if d := route("how long does the dough rest"); d.Scenario != models.TopicContinuation || d.MatchedBlockID != cookingID {
+		t.Errorf("decision near the cooking centroid = %+v, want a continuation", d)
+	}
+	if d := route("what should I plant next to the peppers"); d.Scenario != models.TopicResumption || d.MatchedBlockID != gardenID || d.Score < 0.8 {
+		t.Errorf("decision near the garden centroid = %+v, want a resumption", d)
+	}
+	if d := route("something else entirely"); d.Scenario != models.TopicShift {
+		t.Errorf("decision far from every centroid = %+v, want a shift", d)
+	}
+
+	// An embedding failure routes on keywords instead of failing
+	if d := route("not embeddable"); d.Scenario != models.TopicShift {
+		t.Errorf("decision without an embedding = %+v, want a shift", d)
+	}
+
+	// Centroids of another model are not compared, so nothing is embedded
+	embedder.model, embedder.calls = "model-b", 0
+	if d := route("how long does the dough rest"); d.Scenario != models.TopicShift || embedder.calls != 0 {
+		t.Errorf("decision with another model = %+v after %d embeddings, want a keyword shift without one", d, embedder.calls)
+	}
+}
+
+func TestGovernor_EmbeddingRoutingNeedsEveryCentroid(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// Only the cooking block has a centroid; the gardening block has none yet
+	gardenID, err := store.StoreTurn(&models.Turn{TurnID: "turn_garden", Timestamp: time.Now(), Keywords: []string{"tomatoes", "beds"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookingID, err := store.StoreTurn(&models.Turn{TurnID: "turn_cooking", Timestamp: time.Now(), Keywords: []string{"pasta", "sauce"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ReplaceTurnEmbeddings("turn_cooking", cookingID, "model-a", []models.Chunk{{ChunkID: "chunk_cooking", TurnID: "turn_cooking"}}, [][]float64{{1, 0}}); err != nil {
+		t.Fatal(err)
+	}
+
+	message := "should the tomatoes go in the raised beds"
+	embedder := &routingEmbedder{model: "model-a", vectors: map[string][]float64{message: {1, 0}}}
+	gov := NewGovernor(store)
+	gov.SetEmbedder(embedder, 0.8)
+
+	// A cosine score for cooking would beat the keyword overlap with gardening, so
+	// the whole decision is made on keywords and nothing is embedded
+	decision, err := gov.Route(&models.Turn{TurnID: "turn_mixed", UserMessage: message, Keywords: []string{"tomatoes", "beds"}})
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if decision.Scenario != models.TopicResumption || decision.MatchedBlockID != gardenID || embedder.calls != 0 {
+		t.Errorf("decision = %+v after %d embeddings, want a keyword resumption of %q without one", decision, embedder.calls, gardenID)
+	}
+}
@@ -0,0 +1,228 @@
+// ABOUTME: Ingester stores the text files in a directory (meeting notes, journals) as notes
+// ABOUTME: New and changed files are split into sections and queued for metadata, facts, and embeddings
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+const (
+	// DefaultIngestInterval is how often a watching ingest rescans its directory
+	DefaultIngestInterval = 10 * time.Second
+	// MaxIngestFileSize is the largest file ingested; bigger files are skipped
+	MaxIngestFileSize = 1 << 20
+	// maxNoteSection is the most bytes of a file stored in one turn. Sections break
+	// between paragraphs where they can, and embeddings chunk each section further.
+	maxNoteSection = 4000
+)
+
+// DefaultIngestExtensions are the file types ingested when none are given
+var DefaultIngestExtensions = []string{".md", ".markdown", ".txt"}
+
+// IngestOptions controls what an Ingester reads and how it labels the notes
+type IngestOptions struct {
+	Extensions []string      // File extensions to ingest, with the dot; DefaultIngestExtensions if empty
+	Tags       []string      // Keywords and topics added to every note
+	Origin     models.Origin // Device and workspace recorded on every note
+}
+
+// IngestedNote is a file stored as a note by one scan
+type IngestedNote struct {
+	Path     string `json:"path"`
+	BlockID  string `json:"block_id"`
+	Sections int    `json:"sections"`
+	Replaced bool   `json:"replaced"` // An earlier version of the file was a note
+}
+
+// SkippedFile is a file a scan could not ingest
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// IngestResult reports one scan of a directory
+type IngestResult struct {
+	Dir       string         `json:"dir"`
+	Ingested  []IngestedNote `json:"ingested"`
+	Unchanged int            `json:"unchanged"`
+	Skipped   []SkippedFile  `json:"skipped,omitempty"`
+	Jobs      []*models.Job  `json:"-"` // Enrichment queued for the new notes
+}
+
+// Ingester turns the files in a directory into notes: one paused topic per file, one
+// turn per section. A file is read again only when its size or modification time
+// changes, and stored again only when its content did.
+type Ingester struct {
+	store *storage.Storage
+	queue *JobQueue
+	opts  IngestOptions
+}
+
+// NewIngester creates an Ingester that queues enrichment jobs on queue
+func NewIngester(store *storage.Storage, queue *JobQueue, opts IngestOptions) *Ingester {
+	if len(opts.Extensions) == 0 {
+		opts.Extensions = DefaultIngestExtensions
+	}
+	return &Ingester{store: store, queue: queue, opts: opts}
+}
+
+// Scan ingests every new or changed file under dir. Hidden files and directories are
+// ignored. Files that are too large or not UTF-8 text are reported as skipped.
+func (in *Ingester) Scan(dir string) (*IngestResult, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	result := &IngestResult{Dir: dir}
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !in.wants(path) {
+			return nil
+		}
+		return in.ingestFile(path, result)
+	})
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// wants reports whether path has one of the ingested extensions
+func (in *Ingester) wants(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return slices.ContainsFunc(in.opts.Extensions, func(e string) bool { return strings.EqualFold(e, ext) })
+}
+
+// ingestFile stores path as a note unless it is unchanged since it was last ingested
+func (in *Ingester) ingestFile(path string, result *IngestResult) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	previous, err := in.store.GetIngestedFile(path)
+	if err != nil {
+		return err
+	}
+	if previous != nil && previous.Size == info.Size() && previous.ModTime.Equal(info.ModTime()) {
+		result.Unchanged++
+		return nil
+	}
+	if info.Size() > MaxIngestFileSize {
+		result.Skipped = append(result.Skipped, SkippedFile{Path: path, Reason: fmt.Sprintf("larger than %d bytes", MaxIngestFileSize)})
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	file := &storage.IngestedFile{Path: path, SHA256: hex.EncodeToString(sum[:]), Size: info.Size(), ModTime: info.ModTime()}
+	if previous != nil && previous.SHA256 == file.SHA256 {
+		result.Unchanged++
+		return nil
+	}
+	if !utf8.Valid(data) {
+		result.Skipped = append(result.Skipped, SkippedFile{Path: path, Reason: "not UTF-8 text"})
+		return nil
+	}
+	sections := splitNote(string(data), maxNoteSection)
+	if len(sections) == 0 {
+		result.Skipped = append(result.Skipped, SkippedFile{Path: path, Reason: "empty"})
+		return nil
+	}
+
+	turns := make([]*models.Turn, len(sections))
+	for i, section := range sections {
+		turns[i] = &models.Turn{
+			TurnID:            models.NewID("turn"),
+			Timestamp:         info.ModTime(),
+			UserMessage:       section,
+			Keywords:          slices.Clone(in.opts.Tags),
+			Topics:            slices.Clone(in.opts.Tags),
+			Origin:            in.opts.Origin,
+			PendingEnrichment: true,
+		}
+	}
+	source, err := models.NewAttachment(path, "", "")
+	if err != nil {
+		return fmt.Errorf("invalid source %s: %w", path, err)
+	}
+	label := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	blockID, err := in.store.StoreFileNote(file, label, turns, source)
+	if err != nil {
+		return fmt.Errorf("failed to store %s: %w", path, err)
+	}
+
+	for _, turn := range turns {
+		for _, kind := range []models.JobKind{models.JobExtractMetadata, models.JobExtractFacts, models.JobEmbedTurn} {
+			job, err := in.queue.Enqueue(kind, models.TurnJob{TurnID: turn.TurnID, BlockID: blockID})
+			if err != nil {
+				return fmt.Errorf("failed to queue %s: %w", kind, err)
+			}
+			result.Jobs = append(result.Jobs, job)
+		}
+	}
+	result.Ingested = append(result.Ingested, IngestedNote{Path: path, BlockID: blockID, Sections: len(sections), Replaced: previous != nil})
+	return nil
+}
+
+// splitNote splits text into sections of at most max bytes, breaking between
+// paragraphs and, for a paragraph longer than max, between words
+func splitNote(text string, max int) []string {
+	var sections []string
+	var current strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			sections = append(sections, s)
+		}
+		current.Reset()
+	}
+	for _, para := range splitParagraphs(strings.ReplaceAll(text, "\r\n", "\n")) {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		for len(para) > max {
+			flush()
+			cut := strings.LastIndexAny(para[:max], " \n\t")
+			if cut <= 0 {
+				cut = max
+				for !utf8.RuneStart(para[cut]) {
+					cut--
+				}
+			}
+			sections = append(sections, strings.TrimSpace(para[:cut]))
+			para = strings.TrimSpace(para[cut:])
+		}
+		if current.Len() > 0 && current.Len()+2+len(para) > max {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(para)
+	}
+	flush()
+	return sections
+}
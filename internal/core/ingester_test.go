@@ -0,0 +1,94 @@
+// ABOUTME: Tests for the Ingester
+// ABOUTME: Verifies which files are ingested, skipping unchanged files, replacing changed ones, and section splitting
+
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestIngester_Scan(t *testing.T) {
+	store := newJobTestStore(t)
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	standup := write("meetings/standup.md", "Ana owns the release.")
+	write("journal.txt", "Walked to the river.")
+	write("photo.png", "not a note")
+	write(".obsidian/workspace.md", "editor state")
+	write("empty.md", "  \n")
+	write("binary.md", "\xff\xfe")
+
+	ingester := NewIngester(store, NewJobQueue(store), IngestOptions{Tags: []string{"notes"}, Origin: models.Origin{Device: "laptop"}})
+	result, err := ingester.Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(result.Ingested) != 2 || len(result.Skipped) != 2 || len(result.Jobs) != 6 {
+		t.Fatalf("first Scan() = %+v, want 2 notes with 3 jobs each and 2 files skipped", result)
+	}
+	blockID := result.Ingested[1].BlockID
+	block, err := store.GetBridgeBlock(blockID)
+	if err != nil || block == nil || len(block.Turns) != 1 {
+		t.Fatalf("GetBridgeBlock() = %+v, %v; want the one-section standup note", block, err)
+	}
+	turn := block.Turns[0]
+	if result.Ingested[1].Path != standup || block.TopicLabel != "standup" || turn.UserMessage != "Ana owns the release." || turn.Origin.Device != "laptop" ||
+		!turn.PendingEnrichment || len(turn.Topics) != 1 || turn.Topics[0] != "notes" {
+		t.Errorf("standup note %s = %+v", blockID, turn)
+	}
+
+	if result, err := ingester.Scan(dir); err != nil || len(result.Ingested) != 0 || result.Unchanged != 2 {
+		t.Errorf("Scan() with no changes = %+v, %v; want everything unchanged", result, err)
+	}
+
+	// Touching a file without changing it does not store it again; editing it does
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(standup, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := ingester.Scan(dir); err != nil || len(result.Ingested) != 0 {
+		t.Errorf("Scan() after a touch = %+v, %v; want nothing stored", result, err)
+	}
+	write("meetings/standup.md", "Ana owns the release.\n\nBo owns the docs.")
+	result, err = ingester.Scan(dir)
+	if err != nil || len(result.Ingested) != 1 || !result.Ingested[0].Replaced {
+		t.Fatalf("Scan() after an edit = %+v, %v; want the note replaced", result, err)
+	}
+	if old, _ := store.GetBridgeBlock(blockID); old != nil {
+		t.Error("the edited file's old note was kept")
+	}
+}
+
+func TestSplitNote(t *testing.T) {
+	if got := splitNote("one\n\ntwo\r\n\r\nthree", 100); len(got) != 1 || got[0] != "one\n\ntwo\n\nthree" {
+		t.Errorf("splitNote() of a short note = %q, want one section", got)
+	}
+	if got := splitNote("aaaa\n\nbbbb\n\ncccc", 10); len(got) != 2 || got[0] != "aaaa\n\nbbbb" || got[1] != "cccc" {
+		t.Errorf("splitNote() = %q, want sections broken between paragraphs", got)
+	}
+	long := strings.Repeat("word ", 10)
+	for _, section := range splitNote(long, 12) {
+		if len(section) > 12 || strings.HasPrefix(section, " ") || strings.HasSuffix(section, "wor") {
+			t.Errorf("splitNote() of a long paragraph produced %q", section)
+		}
+	}
+	if got := splitNote(strings.Repeat("é", 10), 5); strings.Join(got, "") != strings.Repeat("é", 10) {
+		t.Errorf("splitNote() split a character: %q", got)
+	}
+}
@@ -0,0 +1,473 @@
+// ABOUTME: JobQueue runs embeddings, fact and metadata extraction, and Scribe updates on background workers
+// ABOUTME: Jobs live in storage, so they survive restarts; failed runs are retried with backoff and after outages
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/llm"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+	"github.com/harper/remember-standalone/internal/tracing"
+	"github.com/harper/remember-standalone/internal/util"
+)
+
+const (
+	DefaultJobWorkers = 2               // Workers a server runs when none are configured
+	jobPollInterval   = 2 * time.Second // How often idle workers look for due jobs
+	jobRetryDelay     = 5 * time.Second // Base retry delay, doubled per attempt (capped at 30s)
+	outageJobs        = 3               // Different jobs failing with no success between them that count as a provider outage
+	jobHeartbeat      = time.Minute     // How often a running job's lease is renewed; well inside storage's 10 minute lease
+
+	DefaultProfileQueueLimit = 100  // Profile jobs that may wait before new messages are dropped, when none is configured
+	maxProfileJobMessage     = 8000 // Bytes of user messages merged into one profile job
+)
+
+// JobHandler does the work of one job; an error retries the job until its attempts run out
+type JobHandler func(ctx context.Context, job *models.Job) error
+
+// JobRunResult counts the outcomes of a RunDue pass
+type JobRunResult struct {
+	Done    int `json:"done"`
+	Retried int `json:"retried"` // Failed but queued to run again
+	Failed  int `json:"failed"`  // Failed with no attempts left
+}
+
+// JobQueue claims jobs from storage and runs them with the handler for their kind.
+// Workers only claim kinds they have a handler for, so a process without an OpenAI
+// key leaves embedding jobs for one that has one.
+type JobQueue struct {
+	storage  *storage.Storage
+	handlers map[models.JobKind]JobHandler
+	profiles int // Profile jobs that may wait; see EnqueueProfile
+	wake     chan struct{}
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	once     sync.Once
+
+	outageMu    sync.Mutex
+	failed      map[int64]bool // Jobs that have failed since the last success
+	failedSince time.Time      // When the first of them failed
+}
+
+// NewJobQueue creates a queue over store's jobs table; register handlers with Handle
+func NewJobQueue(store *storage.Storage) *JobQueue {
+	return &JobQueue{
+		storage:  store,
+		handlers: make(map[models.JobKind]JobHandler),
+		profiles: DefaultProfileQueueLimit,
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		failed:   make(map[int64]bool),
+	}
+}
+
+// Handle registers the handler for kind. Register handlers before Start.
+func (q *JobQueue) Handle(kind models.JobKind, handler JobHandler) {
+	q.handlers[kind] = handler
+}
+
+// Handles reports whether the queue has a handler for kind
+func (q *JobQueue) Handles(kind models.JobKind) bool {
+	return q.handlers[kind] != nil
+}
+
+// kinds returns the kinds with handlers, sorted
+func (q *JobQueue) kinds() []models.JobKind {
+	kinds := make([]models.JobKind, 0, len(q.handlers))
+	for kind := range q.handlers {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+	return kinds
+}
+
+// SetProfileQueueLimit sets how many profile jobs may wait before EnqueueProfile drops
+// new messages; limit must be positive
+func (q *JobQueue) SetProfileQueueLimit(limit int) {
+	if limit > 0 {
+		q.profiles = limit
+	}
+}
+
+// Enqueue queues a job and wakes an idle worker
+func (q *JobQueue) Enqueue(kind models.JobKind, payload any) (*models.Job, error) {
+	job, err := q.storage.EnqueueJob(kind, payload)
+	if err != nil {
+		return nil, err
+	}
+	q.signal()
+	return job, nil
+}
+
+// EnqueueProfile queues profile learning for a message. Profile learning is best-effort
+// and a bursty agent can store turns far faster than the Scribe keeps up, so messages
+// are merged into a profile job for the same persona that has not started yet, and
+// dropped once the profile queue limit is reached. It returns the job the message went
+// to, nil if it was dropped, and whether it was merged into an existing job.
+func (q *JobQueue) EnqueueProfile(payload models.ProfileJob) (*models.Job, bool, error) {
+	job, merged, err := q.storage.EnqueueProfileJob(payload, maxProfileJobMessage, q.profiles)
+	if err != nil {
+		return nil, false, err
+	}
+	if job == nil {
+		log.Printf("[Jobs] %d profile jobs are waiting; dropped a message instead of queueing another", q.profiles)
+		return nil, false, nil
+	}
+	q.signal()
+	return job, merged, nil
+}
+
+// signal wakes an idle worker
+func (q *JobQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs workers goroutines that process due jobs until Shutdown
+func (q *JobQueue) Start(workers int) {
+	if len(q.handlers) == 0 {
+		return
+	}
+	if workers <= 0 {
+		workers = DefaultJobWorkers
+	}
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+}
+
+// Shutdown stops the workers, waiting for jobs they are running to finish.
+// Queued jobs stay in storage for the next start.
+func (q *JobQueue) Shutdown() {
+	q.once.Do(func() { close(q.stop) })
+	q.wg.Wait()
+}
+
+// work processes due jobs, sleeping until woken or the next poll when there are none
+func (q *JobQueue) work() {
+	defer q.wg.Done()
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			select {
+			case <-q.stop:
+				return
+			default:
+			}
+			job, err := q.storage.ClaimNextJob(q.kinds()...)
+			if err != nil {
+				log.Printf("[Jobs] %v", err)
+				break
+			}
+			if job == nil {
+				break
+			}
+			_ = q.process(context.Background(), job)
+		}
+
+		select {
+		case <-q.stop:
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue processes due jobs in the calling goroutine until none are left or ctx is
+// cancelled. Jobs that fail and are rescheduled are not retried within the same pass.
+func (q *JobQueue) RunDue(ctx context.Context) (JobRunResult, error) {
+	var result JobRunResult
+	if len(q.handlers) == 0 {
+		return result, nil
+	}
+	for ctx.Err() == nil {
+		job, err := q.storage.ClaimNextJob(q.kinds()...)
+		if err != nil {
+			return result, err
+		}
+		if job == nil {
+			return result, nil
+		}
+		if err := q.process(ctx, job); err == nil {
+			result.Done++
+		} else if job.Attempts < job.MaxAttempts {
+			result.Retried++
+		} else {
+			result.Failed++
+		}
+	}
+	return result, ctx.Err()
+}
+
+// Run processes job id now, whether or not it is due, and returns the handler's error.
+// A failed run is recorded like any other and retried later if attempts remain.
+func (q *JobQueue) Run(ctx context.Context, id int64) error {
+	job, err := q.storage.ClaimJob(id)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return fmt.Errorf("job %d is not pending", id)
+	}
+	return q.process(ctx, job)
+}
+
+// process runs a claimed job and records whether it succeeded, retrying failures with
+// backoff until the job runs out of attempts
+func (q *JobQueue) process(ctx context.Context, job *models.Job) (err error) {
+	ctx, span := tracing.Start(ctx, "job."+string(job.Kind), tracing.Int("job.id", int(job.ID)), tracing.Int("job.attempt", job.Attempts))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	handler := q.handlers[job.Kind]
+	if handler == nil {
+		err = fmt.Errorf("no handler for %s jobs", job.Kind)
+	} else {
+		stop := q.heartbeat(job.ID)
+		err = runHandler(ctx, handler, job)
+		stop()
+	}
+
+	if err == nil {
+		if err := q.storage.CompleteJob(job.ID); err != nil {
+			log.Printf("[Jobs] %v", err)
+		}
+		q.succeeded()
+		return nil
+	}
+
+	var retryAt time.Time
+	if handler != nil && job.Attempts < job.MaxAttempts {
+		retryAt = q.storage.Now().Add(util.CalculateBackoff(jobRetryDelay, job.Attempts))
+	}
+	if err := q.storage.FailJob(job.ID, err, retryAt); err != nil {
+		log.Printf("[Jobs] %v", err)
+	}
+	log.Printf("[Jobs] %s job %d failed (attempt %d of %d): %v", job.Kind, job.ID, job.Attempts, job.MaxAttempts, err)
+	if handler != nil {
+		q.jobFailed(job.ID)
+	}
+	return err
+}
+
+// heartbeat renews job id's lease every jobHeartbeat until the returned stop is called,
+// so a long-running job is not reclaimed by another worker while it is still running
+func (q *JobQueue) heartbeat(id int64) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(jobHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				running, err := q.storage.RenewJob(id)
+				if err != nil {
+					log.Printf("[Jobs] %v", err)
+				} else if !running {
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// jobFailed notes a failed job toward detecting a provider outage
+func (q *JobQueue) jobFailed(id int64) {
+	q.outageMu.Lock()
+	defer q.outageMu.Unlock()
+	if len(q.failed) == 0 {
+		q.failedSince = time.Now()
+	}
+	q.failed[id] = true
+}
+
+// succeeded ends any outage: once several different jobs have failed in a row, the
+// first success means the provider is back, so jobs that ran out of attempts while
+// it was down are requeued to backfill what was missed
+func (q *JobQueue) succeeded() {
+	q.outageMu.Lock()
+	failed, since := len(q.failed), q.failedSince
+	if failed > 0 {
+		q.failed = make(map[int64]bool)
+	}
+	q.outageMu.Unlock()
+
+	if failed < outageJobs {
+		return
+	}
+	n, err := q.storage.RetryJobsFailedSince(since)
+	if err != nil {
+		log.Printf("[Jobs] %v", err)
+		return
+	}
+	log.Printf("[Jobs] jobs are succeeding again after %d failed; requeued %d that ran out of attempts", failed, n)
+}
+
+// runHandler calls handler, turning a panic into an error so one bad job cannot take
+// down its worker
+func runHandler(ctx context.Context, handler JobHandler, job *models.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// RegisterJobHandlers registers the standard handlers: embeddings, fact extraction, and
+// metadata extraction when client is set, and profile learning when scribe is set
+func RegisterJobHandlers(q *JobQueue, client *llm.OpenAIClient, scribe *Scribe) {
+	if client != nil {
+		q.Handle(models.JobEmbedTurn, EmbedTurnHandler(q.storage, client, NewChunkEngine()))
+		q.Handle(models.JobExtractFacts, ExtractFactsHandler(q.storage, NewFactScrubber(client)))
+		q.Handle(models.JobExtractMetadata, ExtractMetadataHandler(q.storage, client))
+	}
+	if scribe != nil {
+		q.Handle(models.JobUpdateProfile, UpdateProfileHandler(q.storage, scribe))
+	}
+}
+
+// EmbedTurnHandler chunks and embeds a turn, replacing any embeddings it already has
+// so a retried job never duplicates them. A turn deleted since it was queued is skipped.
+func EmbedTurnHandler(store *storage.Storage, embedder BatchEmbedder, chunker *ChunkEngine) JobHandler {
+	return func(ctx context.Context, job *models.Job) error {
+		var payload models.TurnJob
+		if err := job.Decode(&payload); err != nil {
+			return err
+		}
+		blockID, turn, err := store.GetTurn(payload.TurnID)
+		if err != nil || turn == nil {
+			return err
+		}
+
+		chunks, err := chunker.ChunkTurn(turn.Text(), turn.TurnID)
+		if err != nil {
+			return fmt.Errorf("failed to chunk turn %s: %w", turn.TurnID, err)
+		}
+		if len(chunks) == 0 {
+			return nil
+		}
+		texts := make([]string, len(chunks))
+		for i, chunk := range chunks {
+			texts[i] = chunk.Content
+		}
+		vectors, err := embedder.GenerateEmbeddings(texts)
+		if err != nil {
+			return fmt.Errorf("failed to generate embeddings: %w", err)
+		}
+		if len(vectors) != len(chunks) {
+			return fmt.Errorf("expected %d embeddings, got %d", len(chunks), len(vectors))
+		}
+		return store.ReplaceTurnEmbeddings(turn.TurnID, blockID, embedder.EmbeddingModel(), chunks, vectors)
+	}
+}
+
+// ExtractFactsHandler extracts facts from a turn's user message. A turn deleted since
+// it was queued is skipped.
+func ExtractFactsHandler(store *storage.Storage, scrubber *FactScrubber) JobHandler {
+	return func(ctx context.Context, job *models.Job) error {
+		var payload models.TurnJob
+		if err := job.Decode(&payload); err != nil {
+			return err
+		}
+		blockID, turn, err := store.GetTurn(payload.TurnID)
+		if err != nil || turn == nil {
+			return err
+		}
+		return scrubber.ExtractAndSave(turn, blockID, store)
+	}
+}
+
+// MetadataExtractor extracts keywords, topics, and affect from conversation text
+type MetadataExtractor interface {
+	ExtractMetadataContext(ctx context.Context, text string) (map[string]interface{}, error)
+}
+
+// ExtractMetadataHandler fills in the keywords, topics, and affect of a turn stored while
+// the LLM was unavailable, keeping any keywords and topics it already has (such as tags
+// given to 'memory add'). A turn deleted since it was queued is skipped.
+func ExtractMetadataHandler(store *storage.Storage, extractor MetadataExtractor) JobHandler {
+	return func(ctx context.Context, job *models.Job) error {
+		var payload models.TurnJob
+		if err := job.Decode(&payload); err != nil {
+			return err
+		}
+		_, turn, err := store.GetTurn(payload.TurnID)
+		if err != nil || turn == nil {
+			return err
+		}
+
+		metadata, err := extractor.ExtractMetadataContext(ctx, turn.Text())
+		if err != nil {
+			return err
+		}
+		keywords := appendNew(turn.Keywords, metadataStrings(metadata, "keywords"))
+		topics := appendNew(turn.Topics, metadataStrings(metadata, "topics"))
+		affect := turn.Affect
+		if label, ok := metadata["affect"].(string); ok {
+			if parsed, err := models.ParseAffect(label); err == nil {
+				affect = parsed
+			}
+		}
+		return store.UpdateTurnMetadata(turn.TurnID, keywords, topics, affect)
+	}
+}
+
+// metadataStrings returns the strings in metadata's key array
+func metadataStrings(metadata map[string]interface{}, key string) []string {
+	arr, _ := metadata[key].([]interface{})
+	result := make([]string, 0, len(arr))
+	for _, item := range arr {
+		if str, ok := item.(string); ok {
+			result = append(result, str)
+		}
+	}
+	return result
+}
+
+// appendNew appends the items of more that are not already in list
+func appendNew(list, more []string) []string {
+	seen := make(map[string]bool, len(list))
+	for _, item := range list {
+		seen[item] = true
+	}
+	for _, item := range more {
+		if !seen[item] {
+			seen[item] = true
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// UpdateProfileHandler has the Scribe learn about the user from a message
+func UpdateProfileHandler(store *storage.Storage, scribe *Scribe) JobHandler {
+	return func(ctx context.Context, job *models.Job) error {
+		var payload models.ProfileJob
+		if err := job.Decode(&payload); err != nil {
+			return err
+		}
+		return scribe.UpdatePersona(payload.Message, payload.Persona, payload.TurnIDs, store)
+	}
+}
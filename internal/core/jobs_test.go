@@ -0,0 +1,343 @@
+// ABOUTME: Tests for the background JobQueue
+// ABOUTME: Verifies draining, retries, panics, workers, outage requeueing, and the embedding and metadata handlers
+
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+func newJobTestStore(t *testing.T) *storage.Storage {
+	t.Helper()
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestJobQueue_RunDue(t *testing.T) {
+	store := newJobTestStore(t)
+	queue := NewJobQueue(store)
+
+	var calls atomic.Int32
+	queue.Handle(models.JobExtractFacts, func(ctx context.Context, job *models.Job) error {
+		calls.Add(1)
+		var payload models.TurnJob
+		if err := job.Decode(&payload); err != nil {
+			return err
+		}
+		if payload.TurnID == "turn_bad" {
+			return errors.New("rate limited")
+		}
+		return nil
+	})
+	queue.Handle(models.JobUpdateProfile, func(ctx context.Context, job *models.Job) error {
+		panic("boom")
+	})
+
+	for _, id := range []string{"turn_ok", "turn_bad"} {
+		if _, err := queue.Enqueue(models.JobExtractFacts, models.TurnJob{TurnID: id}); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+	if _, err := queue.Enqueue(models.JobUpdateProfile, models.ProfileJob{Message: "hi"}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	// No handler for embeddings: the job is left for a process that has one
+	if _, err := queue.Enqueue(models.JobEmbedTurn, models.TurnJob{TurnID: "turn_ok"}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	result, err := queue.RunDue(context.Background())
+	if err != nil {
+		t.Fatalf("RunDue() error = %v", err)
+	}
+	if result.Done != 1 || result.Retried != 2 || result.Failed != 0 {
+		t.Errorf("RunDue() = %+v, want 1 done and 2 retried (error and panic)", result)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("handler calls = %d, want 2 (retries wait for their backoff)", calls.Load())
+	}
+
+	counts, err := store.JobCounts()
+	if err != nil {
+		t.Fatalf("JobCounts() error = %v", err)
+	}
+	if counts[models.JobDone] != 1 || counts[models.JobPending] != 3 {
+		t.Errorf("JobCounts() = %v, want 1 done and 3 pending", counts)
+	}
+
+	pending, err := store.ListJobs(models.JobPending, 10)
+	if err != nil {
+		t.Fatalf("ListJobs() error = %v", err)
+	}
+	for _, job := range pending {
+		if job.Kind == models.JobUpdateProfile && job.LastError != "panic: boom" {
+			t.Errorf("panicking job LastError = %q, want the recovered panic", job.LastError)
+		}
+	}
+}
+
+func TestJobQueue_RunMarksFailedAfterLastAttempt(t *testing.T) {
+	store := newJobTestStore(t)
+	queue := NewJobQueue(store)
+	queue.Handle(models.JobExtractFacts, func(ctx context.Context, job *models.Job) error {
+		return errors.New("bad key")
+	})
+
+	job, err := queue.Enqueue(models.JobExtractFacts, models.TurnJob{TurnID: "turn_1"})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	for i := 0; i < models.DefaultJobAttempts; i++ {
+		if err := queue.Run(context.Background(), job.ID); err == nil {
+			t.Fatalf("Run() attempt %d succeeded, want the handler error", i+1)
+		}
+	}
+
+	got, err := store.GetJob(job.ID)
+	if err != nil {
+		t.Fatalf("GetJob() error = %v", err)
+	}
+	if got.Status != models.JobFailed || got.Attempts != models.DefaultJobAttempts {
+		t.Errorf("job = %s after %d attempts, want failed after %d", got.Status, got.Attempts, models.DefaultJobAttempts)
+	}
+	if err := queue.Run(context.Background(), job.ID); err == nil {
+		t.Error("Run() on a failed job succeeded, want an error")
+	}
+}
+
+func TestJobQueue_Workers(t *testing.T) {
+	store := newJobTestStore(t)
+	queue := NewJobQueue(store)
+
+	done := make(chan string, 1)
+	queue.Handle(models.JobUpdateProfile, func(ctx context.Context, job *models.Job) error {
+		var payload models.ProfileJob
+		if err := job.Decode(&payload); err != nil {
+			return err
+		}
+		done <- payload.Message
+		return nil
+	})
+	queue.Start(1)
+
+	if _, err := queue.Enqueue(models.JobUpdateProfile, models.ProfileJob{Message: "I prefer Go"}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	select {
+	case msg := <-done:
+		if msg != "I prefer Go" {
+			t.Errorf("handled message = %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not run the queued job")
+	}
+	queue.Shutdown()
+
+	// Completion is recorded after the handler returns, before Shutdown does
+	counts, err := store.JobCounts()
+	if err != nil {
+		t.Fatalf("JobCounts() error = %v", err)
+	}
+	if counts[models.JobDone] != 1 {
+		t.Errorf("JobCounts() = %v, want the job done", counts)
+	}
+}
+
+func TestEmbedTurnHandler(t *testing.T) {
+	store := newReembedTestStore(t, 1)
+	pending, err := store.TurnsNeedingEmbedding("")
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("TurnsNeedingEmbedding() = %d turns, %v; want 1", len(pending), err)
+	}
+	turn := pending[0].Turn
+
+	embedder := &fakeBatchEmbedder{model: "test-model"}
+	queue := NewJobQueue(store)
+	queue.Handle(models.JobEmbedTurn, EmbedTurnHandler(store, embedder, NewChunkEngine()))
+
+	// Running the job again, as a retry after a lost completion would, is harmless
+	for i := 0; i < 2; i++ {
+		job, err := queue.Enqueue(models.JobEmbedTurn, models.TurnJob{TurnID: turn.TurnID, BlockID: pending[0].BlockID})
+		if err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+		if err := queue.Run(context.Background(), job.ID); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	}
+
+	if left, err := store.TurnsNeedingEmbedding("test-model"); err != nil || len(left) != 0 {
+		t.Errorf("TurnsNeedingEmbedding(test-model) = %d, %v; want the turn embedded", len(left), err)
+	}
+	if len(embedder.requests) != 2 || embedder.requests[0] != embedder.requests[1] {
+		t.Errorf("embedding requests = %v, want two identical requests", embedder.requests)
+	}
+
+	// A turn deleted after it was queued is skipped, not retried
+	job, err := queue.Enqueue(models.JobEmbedTurn, models.TurnJob{TurnID: "turn_gone"})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if err := queue.Run(context.Background(), job.ID); err != nil {
+		t.Errorf("Run() for a missing turn error = %v, want nil", err)
+	}
+}
+
+// fakeMetadataExtractor returns fixed metadata, or err when set
+type fakeMetadataExtractor struct {
+	err error
+}
+
+func (f *fakeMetadataExtractor) ExtractMetadataContext(ctx context.Context, text string) (map[string]interface{}, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return map[string]interface{}{
+		"keywords": []interface{}{"postgres", "migration"},
+		"topics":   []interface{}{"databases"},
+		"affect":   "curious",
+	}, nil
+}
+
+func TestExtractMetadataHandler(t *testing.T) {
+	store := newJobTestStore(t)
+	turn := &models.Turn{
+		TurnID:            "turn_meta",
+		Timestamp:         time.Now(),
+		UserMessage:       "Moving the orders service to Postgres",
+		Keywords:          []string{"postgres"},
+		Topics:            []string{"work"},
+		PendingEnrichment: true,
+	}
+	if _, err := store.StoreTurn(turn); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	extractor := &fakeMetadataExtractor{err: errors.New("connection refused")}
+	queue := NewJobQueue(store)
+	queue.Handle(models.JobExtractMetadata, ExtractMetadataHandler(store, extractor))
+	job, err := queue.Enqueue(models.JobExtractMetadata, models.TurnJob{TurnID: turn.TurnID})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if err := queue.Run(context.Background(), job.ID); err == nil {
+		t.Fatal("Run() succeeded while the LLM was down, want the extraction error")
+	}
+
+	// Once the provider recovers the retry fills in the metadata and clears the flag
+	extractor.err = nil
+	if err := queue.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	_, got, err := store.GetTurn(turn.TurnID)
+	if err != nil || got == nil {
+		t.Fatalf("GetTurn() = %v, %v", got, err)
+	}
+	if strings.Join(got.Keywords, ",") != "postgres,migration" || strings.Join(got.Topics, ",") != "work,databases" {
+		t.Errorf("keywords %v, topics %v; want the extracted ones merged into the existing ones", got.Keywords, got.Topics)
+	}
+	if got.Affect != models.AffectCurious {
+		t.Errorf("Affect = %q, want curious", got.Affect)
+	}
+	if got.PendingEnrichment {
+		t.Error("PendingEnrichment still set after the job finished")
+	}
+}
+
+func TestJobQueue_RequeuesAfterOutage(t *testing.T) {
+	store := newJobTestStore(t)
+	queue := NewJobQueue(store)
+
+	var down atomic.Bool
+	down.Store(true)
+	queue.Handle(models.JobExtractFacts, func(ctx context.Context, job *models.Job) error {
+		if down.Load() {
+			return errors.New("503 service unavailable")
+		}
+		return nil
+	})
+
+	// Several jobs run out of attempts while the provider is down
+	var ids []int64
+	for _, turnID := range []string{"turn_a", "turn_b", "turn_c"} {
+		job, err := queue.Enqueue(models.JobExtractFacts, models.TurnJob{TurnID: turnID})
+		if err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+		for i := 0; i < models.DefaultJobAttempts; i++ {
+			_ = queue.Run(context.Background(), job.ID)
+		}
+		ids = append(ids, job.ID)
+	}
+	if counts, _ := store.JobCounts(); counts[models.JobFailed] != 3 {
+		t.Fatalf("JobCounts() = %v, want 3 failed", counts)
+	}
+
+	// The first success after the outage requeues them
+	down.Store(false)
+	job, err := queue.Enqueue(models.JobExtractFacts, models.TurnJob{TurnID: "turn_d"})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if err := queue.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	for _, id := range ids {
+		got, err := store.GetJob(id)
+		if err != nil {
+			t.Fatalf("GetJob() error = %v", err)
+		}
+		if got.Status != models.JobPending || got.Attempts != 0 {
+			t.Errorf("job %d = %s with %d attempts, want requeued with fresh attempts", id, got.Status, got.Attempts)
+		}
+	}
+	result, err := queue.RunDue(context.Background())
+	if err != nil || result.Done != 3 {
+		t.Errorf("RunDue() = %+v, %v; want the 3 requeued jobs done", result, err)
+	}
+}
+
+func TestJobQueue_EnqueueProfile(t *testing.T) {
+	store := newJobTestStore(t)
+	queue := NewJobQueue(store)
+	queue.SetProfileQueueLimit(1)
+
+	var learned []models.ProfileJob
+	queue.Handle(models.JobUpdateProfile, func(ctx context.Context, job *models.Job) error {
+		var payload models.ProfileJob
+		if err := job.Decode(&payload); err != nil {
+			return err
+		}
+		learned = append(learned, payload)
+		return nil
+	})
+
+	// A burst of messages becomes one Scribe call; a second persona does not fit
+	for _, message := range []string{"I use vim", "I prefer tabs", "I write Go"} {
+		if job, _, err := queue.EnqueueProfile(models.ProfileJob{Message: message}); err != nil || job == nil {
+			t.Fatalf("EnqueueProfile(%q) = %+v, %v; want it queued", message, job, err)
+		}
+	}
+	if job, _, err := queue.EnqueueProfile(models.ProfileJob{Message: "I review on Fridays", Persona: "work"}); err != nil || job != nil {
+		t.Errorf("EnqueueProfile() over the limit = %+v, %v; want it dropped", job, err)
+	}
+
+	if result, err := queue.RunDue(context.Background()); err != nil || result.Done != 1 {
+		t.Fatalf("RunDue() = %+v, %v; want one job", result, err)
+	}
+	if len(learned) != 1 || learned[0].Merged != 2 || !strings.Contains(learned[0].Message, "I write Go") {
+		t.Errorf("learned from %+v, want the three messages in one job", learned)
+	}
+}
@@ -88,7 +88,7 @@ func (lc *LatticeCrawler) RetrieveCandidatesByVector(queryVector []float64, maxR
 	candidates := make([]CandidateMemory, 0, len(blockScores))
 	for blockID, score := range blockScores {
 		block, err := lc.storage.GetBridgeBlock(blockID)
-		if err != nil || block == nil {
+		if err != nil || block == nil || block.ExcludeFromRecall {
 			continue
 		}
 
@@ -0,0 +1,199 @@
+// ABOUTME: PIIScanner looks for personal data across stored turns and facts
+// ABOUTME: Combines the regex detectors with optional LLM detection into a report with block, turn, and fact references
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/llm"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/pii"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// Detector names recorded on findings
+const (
+	PIIDetectorRegex = "regex"
+	PIIDetectorLLM   = "llm"
+)
+
+// PIIDetector finds personal data that patterns cannot, such as names and addresses
+type PIIDetector interface {
+	DetectPII(text string) ([]llm.PIIEntity, error)
+}
+
+// PIIFinding is personal data found in one field of a turn or fact. Start and
+// End are byte offsets into that field, so the value can be located for redaction.
+type PIIFinding struct {
+	Category string `json:"category"`
+	Detector string `json:"detector"`
+	BlockID  string `json:"block_id,omitempty"`
+	TurnID   string `json:"turn_id,omitempty"`
+	FactID   string `json:"fact_id,omitempty"`
+	Field    string `json:"field"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	Preview  string `json:"preview"`
+}
+
+// PIIReport is the result of a scan
+type PIIReport struct {
+	ScannedAt  time.Time      `json:"scanned_at"`
+	Turns      int            `json:"turns_scanned"`
+	Facts      int            `json:"facts_scanned"`
+	LLM        bool           `json:"llm"`
+	LLMError   string         `json:"llm_error,omitempty"` // LLM detection stopped after this error
+	Categories map[string]int `json:"categories"`
+	Findings   []PIIFinding   `json:"findings"`
+}
+
+// CategoryNames lists the categories found, most findings first
+func (r *PIIReport) CategoryNames() []string {
+	names := make([]string, 0, len(r.Categories))
+	for name := range r.Categories {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if r.Categories[names[i]] != r.Categories[names[j]] {
+			return r.Categories[names[i]] > r.Categories[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// PIIScanner scans exported memory for personal data
+type PIIScanner struct {
+	detector PIIDetector
+}
+
+// NewPIIScanner creates a scanner; a nil detector scans with the regex detectors alone
+func NewPIIScanner(detector PIIDetector) *PIIScanner {
+	return &PIIScanner{detector: detector}
+}
+
+// piiField is one piece of stored text and where it lives
+type piiField struct {
+	blockID, turnID, factID string
+	name, text              string
+}
+
+// Scan checks every turn and fact in data. Secret facts are skipped: their
+// values are already encrypted and masked. If the LLM detector fails, the scan
+// continues with the regex detectors and records the error in the report.
+func (s *PIIScanner) Scan(data *storage.ExportData) *PIIReport {
+	report := &PIIReport{
+		ScannedAt:  time.Now().UTC(),
+		LLM:        s.detector != nil,
+		Categories: map[string]int{},
+		Findings:   []PIIFinding{},
+	}
+
+	var fields []piiField
+	for _, block := range data.Blocks {
+		for _, turn := range block.Turns {
+			report.Turns++
+			at := piiField{blockID: block.BlockID, turnID: turn.TurnID}
+			fields = append(fields, at.with("user_message", turn.UserMessage), at.with("ai_response", turn.AIResponse))
+			for i, msg := range turn.Messages {
+				fields = append(fields, at.with(fmt.Sprintf("messages[%d].content", i), msg.Content))
+				for j, call := range msg.ToolCalls {
+					fields = append(fields, at.with(fmt.Sprintf("messages[%d].tool_calls[%d].arguments", i, j), call.Arguments))
+				}
+			}
+		}
+	}
+	for _, fact := range data.Facts {
+		report.Facts++
+		at := piiField{factID: fact.FactID}
+		if fact.ValueType != string(models.ValueTypeSecret) {
+			fields = append(fields, at.with("value", fact.Value))
+		}
+		fields = append(fields, at.with("source_quote", fact.SourceQuote))
+	}
+
+	detector := s.detector
+	for _, field := range fields {
+		if strings.TrimSpace(field.text) == "" {
+			continue
+		}
+		var spans [][2]int
+		for _, m := range pii.Detect(field.text) {
+			report.add(field, m.Category, PIIDetectorRegex, m.Start, m.End)
+			spans = append(spans, [2]int{m.Start, m.End})
+		}
+		if detector == nil {
+			continue
+		}
+		entities, err := detector.DetectPII(field.text)
+		if err != nil {
+			report.LLMError = err.Error()
+			detector = nil
+			continue
+		}
+		for _, entity := range entities {
+			start, end, ok := locate(field.text, entity.Text, spans)
+			if !ok {
+				continue
+			}
+			report.add(field, strings.ToLower(strings.TrimSpace(entity.Category)), PIIDetectorLLM, start, end)
+			spans = append(spans, [2]int{start, end})
+		}
+	}
+	return report
+}
+
+// with returns a copy of f for the named field holding text
+func (f piiField) with(name, text string) piiField {
+	f.name, f.text = name, text
+	return f
+}
+
+// add records a finding at text[start:end] of field
+func (r *PIIReport) add(field piiField, category, detector string, start, end int) {
+	if category == "" {
+		category = "other"
+	}
+	r.Categories[category]++
+	r.Findings = append(r.Findings, PIIFinding{
+		Category: category,
+		Detector: detector,
+		BlockID:  field.blockID,
+		TurnID:   field.turnID,
+		FactID:   field.factID,
+		Field:    field.name,
+		Start:    start,
+		End:      end,
+		Preview:  pii.Preview(field.text[start:end]),
+	})
+}
+
+// locate finds the first occurrence of quote in text that no earlier finding
+// covers. Quotes the model did not copy verbatim are not found and are dropped.
+func locate(text, quote string, spans [][2]int) (int, int, bool) {
+	if strings.TrimSpace(quote) == "" {
+		return 0, 0, false
+	}
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], quote)
+		if i < 0 {
+			return 0, 0, false
+		}
+		start, end := offset+i, offset+i+len(quote)
+		covered := false
+		for _, span := range spans {
+			if start < span[1] && span[0] < end {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return start, end, true
+		}
+		offset = end
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,109 @@
+// ABOUTME: Tests for PIIScanner
+// ABOUTME: Verifies findings reference their block, turn, or fact, LLM quotes are located, and LLM failures degrade to regex
+
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/llm"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// fakePIIDetector returns fixed entities, or fails every call when err is set
+type fakePIIDetector struct {
+	entities []llm.PIIEntity
+	err      error
+	calls    int
+}
+
+func (f *fakePIIDetector) DetectPII(text string) ([]llm.PIIEntity, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.entities, nil
+}
+
+func piiTestExport() *storage.ExportData {
+	return &storage.ExportData{
+		Blocks: []storage.ExportBlock{{
+			BlockID: "block_1",
+			Turns: []storage.ExportTurn{{
+				TurnID:      "turn_1",
+				UserMessage: "I'm Jane Doe, reach me at jane@example.com",
+				AIResponse:  "Noted.",
+				Messages: []models.Message{
+					{Role: models.RoleUser, Content: "I'm Jane Doe, reach me at jane@example.com"},
+				},
+			}},
+		}},
+		Facts: []storage.ExportFact{
+			{FactID: "fact_phone", Key: "phone", Value: "(312) 555-0142"},
+			{FactID: "fact_token", Key: "token", Value: "********", ValueType: "secret"},
+		},
+	}
+}
+
+func TestPIIScanner_Regex(t *testing.T) {
+	report := NewPIIScanner(nil).Scan(piiTestExport())
+
+	if report.Turns != 1 || report.Facts != 2 || report.LLM {
+		t.Errorf("report = %d turns, %d facts, llm %t; want 1, 2, false", report.Turns, report.Facts, report.LLM)
+	}
+	if report.Categories["email"] != 2 || report.Categories["phone"] != 1 || len(report.Findings) != 3 {
+		t.Fatalf("Categories = %v, findings %d; want the email in two fields and the phone fact", report.Categories, len(report.Findings))
+	}
+
+	first := report.Findings[0]
+	if first.BlockID != "block_1" || first.TurnID != "turn_1" || first.Field != "user_message" || first.Detector != PIIDetectorRegex {
+		t.Errorf("first finding = %+v, want the user message of turn_1 in block_1", first)
+	}
+	if text := "I'm Jane Doe, reach me at jane@example.com"; text[first.Start:first.End] != "jane@example.com" {
+		t.Errorf("offsets %d:%d do not cover the email", first.Start, first.End)
+	}
+	if first.Preview != "ja********om" {
+		t.Errorf("Preview = %q, want the email masked", first.Preview)
+	}
+	if last := report.Findings[2]; last.FactID != "fact_phone" || last.Field != "value" {
+		t.Errorf("last finding = %+v, want the phone fact's value", last)
+	}
+	if names := report.CategoryNames(); len(names) != 2 || names[0] != "email" {
+		t.Errorf("CategoryNames() = %v, want email first", names)
+	}
+}
+
+func TestPIIScanner_LLM(t *testing.T) {
+	detector := &fakePIIDetector{entities: []llm.PIIEntity{
+		{Category: "Name", Text: "Jane Doe"},
+		{Category: "email", Text: "jane@example.com"}, // already found by regex
+		{Category: "address", Text: "not in the text"},
+	}}
+	report := NewPIIScanner(detector).Scan(piiTestExport())
+
+	if report.Categories["name"] != 2 {
+		t.Errorf("name findings = %d, want one per field holding the name", report.Categories["name"])
+	}
+	if report.Categories["email"] != 2 || report.Categories["address"] != 0 {
+		t.Errorf("Categories = %v, want regex matches not repeated and unquoted entities dropped", report.Categories)
+	}
+	for _, f := range report.Findings {
+		if f.Category == "name" && f.Detector != PIIDetectorLLM {
+			t.Errorf("name finding detector = %s, want llm", f.Detector)
+		}
+	}
+}
+
+func TestPIIScanner_LLMFailure(t *testing.T) {
+	detector := &fakePIIDetector{err: errors.New("no API key")}
+	report := NewPIIScanner(detector).Scan(piiTestExport())
+
+	if detector.calls != 1 {
+		t.Errorf("detector called %d times, want it dropped after the first failure", detector.calls)
+	}
+	if report.LLMError == "" || report.Categories["email"] != 2 {
+		t.Errorf("report = %+v, want the error recorded and regex findings kept", report)
+	}
+}
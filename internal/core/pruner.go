@@ -0,0 +1,229 @@
+// ABOUTME: Pruner keeps the database under max_db_size by evicting archived blocks past their retention
+// ABOUTME: Also deletes topics whose own retention policy ran out, compacts old archived ones, and warns near the limit
+package core
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+const (
+	DefaultPruneInterval = 10 * time.Minute // How often a server checks the database size
+	sizeWarnFraction     = 0.8              // Share of the limit at which each check warns
+	pruneTargetFraction  = 0.9              // Share of the limit eviction brings the database down to, so it does not evict again on the next write
+)
+
+// PruneResult is what one size check found and evicted
+type PruneResult struct {
+	UsedBytes int64                    `json:"used_bytes"` // Bytes in use before eviction
+	MaxBytes  int64                    `json:"max_bytes"`
+	Expired   []storage.ExpiredBlock   `json:"expired,omitempty"`   // Blocks whose retention policy ran out
+	Compacted []storage.CompactedBlock `json:"compacted,omitempty"` // Archived blocks whose turn bodies were compacted
+	Evicted   []storage.EvictedBlock   `json:"evicted,omitempty"`
+	Warning   string                   `json:"warning,omitempty"` // Set when the database is near or over the limit
+}
+
+// Pruner enforces a database size limit. Only archived blocks last updated longer ago
+// than the retention period are evicted, least important first; active, paused, and
+// closed blocks and blocks whose retention policy keeps them are never evicted, so a
+// database can stay over the limit. Blocks whose retention policy ran out are deleted
+// with or without a limit, and with compaction set, old archived blocks are compacted
+// before anything is evicted.
+type Pruner struct {
+	store         *storage.Storage
+	maxBytes      int64
+	retention     time.Duration
+	compactAfter  time.Duration
+	compactPolicy models.CompactPolicy
+	logf          func(format string, args ...any)
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// NewPruner creates a Pruner for a limit of maxBytes; with a limit of 0 it only deletes
+// blocks whose retention policy ran out
+func NewPruner(store *storage.Storage, maxBytes int64, retention time.Duration) *Pruner {
+	return &Pruner{
+		store:     store,
+		maxBytes:  maxBytes,
+		retention: retention,
+		logf:      log.Printf,
+		stop:      make(chan struct{}),
+	}
+}
+
+// SetCompaction makes Prune compact archived blocks last updated more than after ago,
+// keeping or dropping their turn bodies per policy. An after of 0 turns compaction off.
+func (p *Pruner) SetCompaction(after time.Duration, policy models.CompactPolicy) {
+	p.compactAfter = after
+	p.compactPolicy = policy
+}
+
+// Usage reports the bytes in use with a warning once they pass 80% of the limit
+func (p *Pruner) Usage() (PruneResult, error) {
+	used, err := p.store.UsedBytes()
+	if err != nil {
+		return PruneResult{}, err
+	}
+	result := PruneResult{UsedBytes: used, MaxBytes: p.maxBytes}
+	result.Warning = p.warning(used)
+	return result, nil
+}
+
+// Prune deletes blocks whose retention policy ran out and compacts old archived blocks,
+// then evicts archived blocks when the database is over the limit, bringing it down to
+// 90% of the limit. The result carries a warning once the database passes 80%.
+func (p *Pruner) Prune() (PruneResult, error) {
+	expired, err := p.store.ExpireBlocks()
+	if err != nil {
+		return PruneResult{}, err
+	}
+	var compacted []storage.CompactedBlock
+	if p.compactAfter > 0 {
+		if compacted, err = p.store.CompactArchived(p.compactAfter, p.compactPolicy); err != nil {
+			return PruneResult{Expired: expired}, err
+		}
+	}
+	result, err := p.Usage()
+	result.Expired = expired
+	result.Compacted = compacted
+	if err != nil || p.maxBytes <= 0 {
+		return result, err
+	}
+	if result.UsedBytes > p.maxBytes {
+		evicted, err := p.store.EvictArchived(p.target(), p.retention)
+		if err != nil {
+			return result, err
+		}
+		result.Evicted = evicted
+		if used, err := p.store.UsedBytes(); err == nil {
+			result.Warning = p.warning(used)
+		}
+		if len(evicted) == 0 {
+			result.Warning += fmt.Sprintf(", and no archived blocks older than %s are left to evict; archive or delete topics, or raise the limit", p.retention)
+		}
+	}
+	return result, nil
+}
+
+// Plan returns the archived blocks Prune would evict now, without evicting them
+func (p *Pruner) Plan() ([]storage.EvictedBlock, error) {
+	if p.maxBytes <= 0 {
+		return nil, nil
+	}
+	used, err := p.store.UsedBytes()
+	if err != nil || used <= p.maxBytes {
+		return nil, err
+	}
+	return p.store.EvictionPlan(p.target(), p.retention)
+}
+
+// PlanExpiry returns the blocks whose retention policy ran out, which Prune would delete
+// now, without deleting them
+func (p *Pruner) PlanExpiry() ([]storage.ExpiredBlock, error) {
+	return p.store.ExpiredBlocks()
+}
+
+// PlanCompaction returns the archived blocks Prune would compact now, without compacting
+// them; none when compaction is off
+func (p *Pruner) PlanCompaction() ([]storage.CompactedBlock, error) {
+	if p.compactAfter <= 0 {
+		return nil, nil
+	}
+	return p.store.CompactionPlan(p.compactAfter)
+}
+
+// target is the size eviction brings the database down to
+func (p *Pruner) target() int64 {
+	return int64(float64(p.maxBytes) * pruneTargetFraction)
+}
+
+// Freed returns the estimated bytes the evicted blocks took up
+func (r PruneResult) Freed() int64 {
+	var freed int64
+	for _, block := range r.Evicted {
+		freed += block.Bytes
+	}
+	return freed
+}
+
+// CompactedBytes returns the estimated bytes compaction freed
+func (r PruneResult) CompactedBytes() int64 {
+	var freed int64
+	for _, block := range r.Compacted {
+		freed += block.Bytes
+	}
+	return freed
+}
+
+// warning describes how close used is to the limit, or returns "" under 80%
+func (p *Pruner) warning(used int64) string {
+	if p.maxBytes <= 0 {
+		return ""
+	}
+	percent := float64(used) / float64(p.maxBytes) * 100
+	switch {
+	case used > p.maxBytes:
+		return fmt.Sprintf("database uses %s, over max_db_size %s", formatMiB(used), formatMiB(p.maxBytes))
+	case float64(used) >= float64(p.maxBytes)*sizeWarnFraction:
+		return fmt.Sprintf("database uses %s, %.0f%% of max_db_size %s; archived blocks will be evicted past the limit",
+			formatMiB(used), percent, formatMiB(p.maxBytes))
+	}
+	return ""
+}
+
+// Start prunes now and then every interval until Stop, logging deletions and warnings
+func (p *Pruner) Start(interval time.Duration) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			p.log(p.Prune())
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// log reports a background prune: what it expired and evicted and any size warning
+func (p *Pruner) log(result PruneResult, err error) {
+	if err != nil {
+		p.logf("[Prune] %v", err)
+		return
+	}
+	if len(result.Expired) > 0 {
+		p.logf("[Prune] deleted %d topics whose retention policy ran out", len(result.Expired))
+	}
+	if len(result.Compacted) > 0 {
+		p.logf("[Prune] compacted %d archived topics (about %s)", len(result.Compacted), formatMiB(result.CompactedBytes()))
+	}
+	if len(result.Evicted) > 0 {
+		p.logf("[Prune] evicted %d archived blocks (about %s) to stay under max_db_size %s", len(result.Evicted), formatMiB(result.Freed()), formatMiB(p.maxBytes))
+	}
+	if result.Warning != "" {
+		p.logf("Warning: %s", result.Warning)
+	}
+}
+
+// Stop ends the pruning loop, waiting for a running check to finish
+func (p *Pruner) Stop() {
+	p.once.Do(func() { close(p.stop) })
+	p.wg.Wait()
+}
+
+// formatMiB renders a byte count in mebibytes
+func formatMiB(n int64) string {
+	return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+}
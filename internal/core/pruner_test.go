@@ -0,0 +1,204 @@
+// ABOUTME: Tests for the Pruner
+// ABOUTME: Verifies size warnings, compaction and eviction of archived blocks, retention policies, and the background loop's log lines
+
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// newPrunerTestStore returns a store with one archived and one active block
+func newPrunerTestStore(t *testing.T) *storage.Storage {
+	t.Helper()
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	archived, err := store.StoreTurn(&models.Turn{TurnID: "turn_prune_a", Timestamp: time.Now(), UserMessage: strings.Repeat("old ", 2000)})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if err := store.UpdateBridgeBlockStatus(archived, models.StatusArchived); err != nil {
+		t.Fatalf("UpdateBridgeBlockStatus() error = %v", err)
+	}
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_prune_b", Timestamp: time.Now(), UserMessage: "current"}); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	return store
+}
+
+func TestPruner_Usage(t *testing.T) {
+	store := newPrunerTestStore(t)
+	used, err := store.UsedBytes()
+	if err != nil {
+		t.Fatalf("UsedBytes() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		maxBytes int64
+		want     string
+	}{
+		{"no limit", 0, ""},
+		{"well under", used * 2, ""},
+		{"near the limit", used * 10 / 9, "% of max_db_size"},
+		{"over the limit", used / 2, "over max_db_size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewPruner(store, tt.maxBytes, 0).Usage()
+			if err != nil {
+				t.Fatalf("Usage() error = %v", err)
+			}
+			if result.UsedBytes != used {
+				t.Errorf("UsedBytes = %d, want %d", result.UsedBytes, used)
+			}
+			if (tt.want == "") != (result.Warning == "") || !strings.Contains(result.Warning, tt.want) {
+				t.Errorf("Warning = %q, want %q", result.Warning, tt.want)
+			}
+		})
+	}
+}
+
+func TestPruner_Prune(t *testing.T) {
+	store := newPrunerTestStore(t)
+
+	// The archived block was updated moments ago, so a long retention protects it
+	if result, err := NewPruner(store, 1, time.Hour).Prune(); err != nil || len(result.Evicted) != 0 {
+		t.Fatalf("Prune() within retention = %+v, %v, want nothing evicted", result, err)
+	} else if !strings.Contains(result.Warning, "no archived blocks older than 1h0m0s are left") {
+		t.Errorf("Warning = %q, want it to say nothing can be evicted", result.Warning)
+	}
+
+	pruner := NewPruner(store, 1, 0)
+	plan, err := pruner.Plan()
+	if err != nil || len(plan) != 1 {
+		t.Fatalf("Plan() = %v, %v, want the archived block", plan, err)
+	}
+	result, err := pruner.Prune()
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if len(result.Evicted) != 1 || result.Evicted[0].BlockID != plan[0].BlockID || result.Freed() < 8000 {
+		t.Errorf("Evicted = %+v, want the planned block", result.Evicted)
+	}
+	if active, _ := store.GetActiveBridgeBlocks(); len(active) != 1 {
+		t.Errorf("active blocks = %d, want the active block kept", len(active))
+	}
+	if !strings.Contains(result.Warning, "over max_db_size") || strings.Contains(result.Warning, "left to evict") {
+		t.Errorf("Warning = %q, want over the limit after evicting", result.Warning)
+	}
+}
+
+func TestPruner_Start(t *testing.T) {
+	store := newPrunerTestStore(t)
+
+	var (
+		mu     sync.Mutex
+		logged []string
+	)
+	pruner := NewPruner(store, 1, 0)
+	pruner.logf = func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}
+	pruner.Start(time.Hour)
+	pruner.Stop()
+	pruner.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(logged) != 2 || !strings.HasPrefix(logged[0], "[Prune] evicted 1 archived blocks") || !strings.HasPrefix(logged[1], "Warning: ") {
+		t.Errorf("logged %q, want the eviction and a warning", logged)
+	}
+
+	// Without a limit only retention policies are enforced, and nothing is due
+	var unlimitedLogged []string
+	unlimited := NewPruner(store, 0, 0)
+	unlimited.logf = func(format string, args ...any) {
+		unlimitedLogged = append(unlimitedLogged, fmt.Sprintf(format, args...))
+	}
+	unlimited.Start(time.Hour)
+	unlimited.Stop()
+	if len(unlimitedLogged) != 0 {
+		t.Errorf("logged %q without a limit, want nothing", unlimitedLogged)
+	}
+}
+
+func TestPruner_Retention(t *testing.T) {
+	store := newPrunerTestStore(t)
+	plan, err := NewPruner(store, 1, 0).Plan()
+	if err != nil || len(plan) != 1 {
+		t.Fatalf("Plan() = %v, %v, want the archived block", plan, err)
+	}
+	active, err := store.GetActiveBridgeBlocks()
+	if err != nil || len(active) != 1 {
+		t.Fatalf("GetActiveBridgeBlocks() = %d blocks, %v", len(active), err)
+	}
+	archived, current := plan[0].BlockID, active[0].BlockID
+
+	// A kept archived block is never evicted, even over the limit
+	if err := store.SetBlockRetention(archived, models.KeepForever); err != nil {
+		t.Fatalf("SetBlockRetention() error = %v", err)
+	}
+	if result, err := NewPruner(store, 1, 0).Prune(); err != nil || len(result.Evicted) != 0 {
+		t.Errorf("Prune() = %+v, %v; want the kept block left alone", result, err)
+	}
+
+	// A block past its own retention is deleted without a limit, whatever its status
+	if err := store.SetBlockRetention(current, models.Retention{After: time.Nanosecond}); err != nil {
+		t.Fatalf("SetBlockRetention() error = %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	pruner := NewPruner(store, 0, 0)
+	if expiring, err := pruner.PlanExpiry(); err != nil || len(expiring) != 1 || expiring[0].BlockID != current {
+		t.Fatalf("PlanExpiry() = %+v, %v; want the active block", expiring, err)
+	}
+	result, err := pruner.Prune()
+	if err != nil || len(result.Expired) != 1 || len(result.Evicted) != 0 {
+		t.Fatalf("Prune() = %+v, %v; want the active block expired", result, err)
+	}
+	if block, _ := store.GetBridgeBlock(current); block != nil {
+		t.Errorf("expired block still stored: %+v", block)
+	}
+	if block, _ := store.GetBridgeBlock(archived); block == nil {
+		t.Error("kept block deleted")
+	}
+}
+
+func TestPruner_Compaction(t *testing.T) {
+	store := newPrunerTestStore(t)
+
+	pruner := NewPruner(store, 0, 0)
+	if plan, err := pruner.PlanCompaction(); err != nil || plan != nil {
+		t.Fatalf("PlanCompaction() without compaction = %v, %v; want nothing", plan, err)
+	}
+
+	pruner.SetCompaction(time.Nanosecond, models.CompactCompress)
+	time.Sleep(time.Millisecond)
+	plan, err := pruner.PlanCompaction()
+	if err != nil || len(plan) != 1 {
+		t.Fatalf("PlanCompaction() = %v, %v; want the archived block", plan, err)
+	}
+	result, err := pruner.Prune()
+	if err != nil || len(result.Compacted) != 1 || result.Compacted[0].BlockID != plan[0].BlockID || result.CompactedBytes() < 8000 {
+		t.Fatalf("Prune() = %+v, %v; want the archived block compacted", result, err)
+	}
+	block, err := store.GetBridgeBlock(plan[0].BlockID)
+	if err != nil || len(block.Turns) != 1 || !strings.HasPrefix(block.Turns[0].UserMessage, "[Compacted 1 turns]") {
+		t.Errorf("compacted block = %+v, %v; want its turn replaced by the digest", block, err)
+	}
+	if result, err := pruner.Prune(); err != nil || len(result.Compacted) != 0 {
+		t.Errorf("Prune() again = %+v, %v; want nothing left to compact", result, err)
+	}
+}
@@ -0,0 +1,197 @@
+// ABOUTME: Reembedder regenerates turn embeddings, e.g. after switching embedding models
+// ABOUTME: Batches API calls, rate limits requests, and resumes from where it stopped
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// BatchEmbedder generates embeddings for several texts in one request
+type BatchEmbedder interface {
+	GenerateEmbeddings(texts []string) ([][]float64, error)
+	EmbeddingModel() string
+}
+
+// ReembedProgress reports how far a re-embedding run has got
+type ReembedProgress struct {
+	TotalTurns int `json:"total_turns"`
+	DoneTurns  int `json:"done_turns"`
+	Chunks     int `json:"chunks"`
+	Requests   int `json:"requests"`
+}
+
+// Reembedder regenerates embeddings for every turn not yet embedded with the target model.
+// Progress is committed per turn, so an interrupted run picks up where it left off.
+type Reembedder struct {
+	storage      *storage.Storage
+	embedder     BatchEmbedder
+	chunkEngine  *ChunkEngine
+	batchSize    int           // Maximum texts per embedding request
+	minInterval  time.Duration // Minimum delay between requests (rate limit)
+	lastRequest  time.Time
+	missingOnly  bool // Only turns with no embeddings at all, from any model
+	sleepContext func(ctx context.Context, d time.Duration) error
+}
+
+// NewReembedder creates a Reembedder that sends up to batchSize texts per request
+// and at most requestsPerMinute requests (0 disables rate limiting)
+func NewReembedder(store *storage.Storage, embedder BatchEmbedder, batchSize, requestsPerMinute int) *Reembedder {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	var interval time.Duration
+	if requestsPerMinute > 0 {
+		interval = time.Minute / time.Duration(requestsPerMinute)
+	}
+	return &Reembedder{
+		storage:      store,
+		embedder:     embedder,
+		chunkEngine:  NewChunkEngine(),
+		batchSize:    batchSize,
+		minInterval:  interval,
+		sleepContext: sleepContext,
+	}
+}
+
+// MissingOnly limits r to turns with no embeddings from any model, such as turns
+// stored before an API key was configured, and leaves turns embedded with another
+// model alone. It returns r.
+func (r *Reembedder) MissingOnly() *Reembedder {
+	r.missingOnly = true
+	return r
+}
+
+// Pending returns the turns still awaiting embeddings from the target model, or
+// from any model when r is MissingOnly
+func (r *Reembedder) Pending() ([]storage.PendingTurn, error) {
+	if r.missingOnly {
+		return r.storage.TurnsNeedingEmbedding("")
+	}
+	return r.storage.TurnsNeedingEmbedding(r.embedder.EmbeddingModel())
+}
+
+// Run re-embeds all pending turns, calling onProgress after each batch is saved.
+// Cancelling ctx stops the run after the current batch; completed turns stay done.
+func (r *Reembedder) Run(ctx context.Context, onProgress func(ReembedProgress)) (ReembedProgress, error) {
+	pending, err := r.Pending()
+	if err != nil {
+		return ReembedProgress{}, err
+	}
+
+	progress := ReembedProgress{TotalTurns: len(pending)}
+	if onProgress != nil {
+		onProgress(progress)
+	}
+
+	for start := 0; start < len(pending); {
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+
+		// Group whole turns until the batch is full (a large turn may span requests)
+		var (
+			batch  []storage.PendingTurn
+			chunks [][]models.Chunk
+			texts  int
+		)
+		end := start
+		for end < len(pending) && (len(batch) == 0 || texts < r.batchSize) {
+			turn := pending[end].Turn
+			turnChunks, err := r.chunkEngine.ChunkTurn(turn.Text(), turn.TurnID)
+			if err != nil {
+				return progress, fmt.Errorf("failed to chunk turn %s: %w", turn.TurnID, err)
+			}
+			batch = append(batch, pending[end])
+			chunks = append(chunks, turnChunks)
+			texts += len(turnChunks)
+			end++
+		}
+
+		vectors, err := r.embedChunks(ctx, chunks, &progress)
+		if err != nil {
+			return progress, err
+		}
+
+		offset := 0
+		for i, p := range batch {
+			n := len(chunks[i])
+			if err := r.storage.ReplaceTurnEmbeddings(p.Turn.TurnID, p.BlockID, r.embedder.EmbeddingModel(), chunks[i], vectors[offset:offset+n]); err != nil {
+				return progress, fmt.Errorf("failed to save embeddings for turn %s: %w", p.Turn.TurnID, err)
+			}
+			offset += n
+			progress.DoneTurns++
+			progress.Chunks += n
+		}
+
+		if onProgress != nil {
+			onProgress(progress)
+		}
+		start = end
+	}
+
+	return progress, nil
+}
+
+// embedChunks embeds every chunk's content in batchSize requests, preserving order
+func (r *Reembedder) embedChunks(ctx context.Context, chunks [][]models.Chunk, progress *ReembedProgress) ([][]float64, error) {
+	var texts []string
+	for _, turnChunks := range chunks {
+		for _, chunk := range turnChunks {
+			texts = append(texts, chunk.Content)
+		}
+	}
+
+	vectors := make([][]float64, 0, len(texts))
+	for i := 0; i < len(texts); i += r.batchSize {
+		end := i + r.batchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+
+		if err := r.throttle(ctx); err != nil {
+			return nil, err
+		}
+
+		batchVectors, err := r.embedder.GenerateEmbeddings(texts[i:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate embeddings: %w", err)
+		}
+		if len(batchVectors) != end-i {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", end-i, len(batchVectors))
+		}
+		progress.Requests++
+		vectors = append(vectors, batchVectors...)
+	}
+
+	return vectors, nil
+}
+
+// throttle waits until minInterval has passed since the previous request
+func (r *Reembedder) throttle(ctx context.Context) error {
+	if r.minInterval > 0 && !r.lastRequest.IsZero() {
+		if wait := r.minInterval - time.Since(r.lastRequest); wait > 0 {
+			if err := r.sleepContext(ctx, wait); err != nil {
+				return err
+			}
+		}
+	}
+	r.lastRequest = time.Now()
+	return nil
+}
+
+// sleepContext sleeps for d or until ctx is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
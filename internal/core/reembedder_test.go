@@ -0,0 +1,190 @@
+// ABOUTME: Tests for Reembedder batch re-embedding pipeline
+// ABOUTME: Verifies batching, model tracking, resumption, and cancellation
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// fakeBatchEmbedder returns fixed vectors and records request sizes
+type fakeBatchEmbedder struct {
+	model    string
+	requests []int
+	failAt   int // 1-based request number that fails (0 = never)
+}
+
+func (f *fakeBatchEmbedder) EmbeddingModel() string { return f.model }
+
+func (f *fakeBatchEmbedder) GenerateEmbeddings(texts []string) ([][]float64, error) {
+	f.requests = append(f.requests, len(texts))
+	if f.failAt > 0 && len(f.requests) == f.failAt {
+		return nil, errors.New("rate limited")
+	}
+	vectors := make([][]float64, len(texts))
+	for i := range texts {
+		vectors[i] = []float64{1, 2, 3}
+	}
+	return vectors, nil
+}
+
+func newReembedTestStore(t *testing.T, turns int) *storage.Storage {
+	t.Helper()
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	base := time.Now()
+	for i := 0; i < turns; i++ {
+		turn := &models.Turn{
+			TurnID:      "turn_reembed_" + string(rune('a'+i)),
+			Timestamp:   base.Add(time.Duration(i) * time.Second),
+			UserMessage: "One sentence. Two sentences.",
+		}
+		if _, err := store.StoreTurn(turn); err != nil {
+			t.Fatalf("StoreTurn() error = %v", err)
+		}
+	}
+	return store
+}
+
+func TestReembedder_Run(t *testing.T) {
+	store := newReembedTestStore(t, 3)
+	embedder := &fakeBatchEmbedder{model: "test-model"}
+
+	var updates []ReembedProgress
+	r := NewReembedder(store, embedder, 8, 0)
+	progress, err := r.Run(context.Background(), func(p ReembedProgress) {
+		updates = append(updates, p)
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if progress.TotalTurns != 3 || progress.DoneTurns != 3 {
+		t.Errorf("progress = %+v, want 3/3 turns", progress)
+	}
+	// Each turn yields 4 chunks (turn, paragraph, 2 sentences)
+	if progress.Chunks != 12 {
+		t.Errorf("Chunks = %d, want 12", progress.Chunks)
+	}
+	for _, n := range embedder.requests {
+		if n > 8 {
+			t.Errorf("request had %d texts, exceeds batch size 8", n)
+		}
+	}
+	if progress.Requests != len(embedder.requests) {
+		t.Errorf("Requests = %d, embedder saw %d", progress.Requests, len(embedder.requests))
+	}
+	if len(updates) < 2 || updates[0].DoneTurns != 0 {
+		t.Errorf("expected initial and incremental progress updates, got %+v", updates)
+	}
+
+	pending, _ := r.Pending()
+	if len(pending) != 0 {
+		t.Errorf("len(Pending()) = %d after run, want 0", len(pending))
+	}
+}
+
+func TestReembedder_ResumesAfterFailure(t *testing.T) {
+	store := newReembedTestStore(t, 3)
+
+	// Batch size 4 = one turn per request; the second request fails
+	failing := &fakeBatchEmbedder{model: "test-model", failAt: 2}
+	progress, err := NewReembedder(store, failing, 4, 0).Run(context.Background(), nil)
+	if err == nil {
+		t.Fatal("Run() expected error")
+	}
+	if progress.DoneTurns != 1 {
+		t.Errorf("DoneTurns = %d, want 1", progress.DoneTurns)
+	}
+
+	// Second run only processes what is left
+	embedder := &fakeBatchEmbedder{model: "test-model"}
+	progress, err = NewReembedder(store, embedder, 4, 0).Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if progress.TotalTurns != 2 || progress.DoneTurns != 2 {
+		t.Errorf("resumed progress = %+v, want 2/2 turns", progress)
+	}
+}
+
+func TestReembedder_RateLimit(t *testing.T) {
+	store := newReembedTestStore(t, 3)
+	embedder := &fakeBatchEmbedder{model: "test-model"}
+
+	r := NewReembedder(store, embedder, 4, 60)
+	if r.minInterval != time.Second {
+		t.Fatalf("minInterval = %v, want 1s", r.minInterval)
+	}
+
+	var waits []time.Duration
+	r.sleepContext = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	if _, err := r.Run(context.Background(), nil); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	// The first request goes out immediately; the other two wait
+	if len(waits) != 2 {
+		t.Errorf("throttled %d times, want 2", len(waits))
+	}
+}
+
+func TestReembedder_Cancelled(t *testing.T) {
+	store := newReembedTestStore(t, 2)
+	embedder := &fakeBatchEmbedder{model: "test-model"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	progress, err := NewReembedder(store, embedder, 4, 0).Run(ctx, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+	if progress.DoneTurns != 0 || len(embedder.requests) != 0 {
+		t.Errorf("expected no work after cancellation, got %+v", progress)
+	}
+}
+
+func TestReembedder_MissingOnly(t *testing.T) {
+	store := newReembedTestStore(t, 2)
+
+	// Two turns have embeddings from another model; a later one was stored without any
+	old := &fakeBatchEmbedder{model: "old-model"}
+	if _, err := NewReembedder(store, old, 4, 0).Run(context.Background(), nil); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_reembed_late", UserMessage: "Stored without a key."}); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	embedder := &fakeBatchEmbedder{model: "test-model"}
+	r := NewReembedder(store, embedder, 4, 0).MissingOnly()
+	progress, err := r.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if progress.TotalTurns != 1 || progress.DoneTurns != 1 {
+		t.Errorf("progress = %+v, want only the turn with no embeddings", progress)
+	}
+	if pending, _ := r.Pending(); len(pending) != 0 {
+		t.Errorf("len(Pending()) = %d after backfill, want 0", len(pending))
+	}
+
+	// Turns embedded with another model still need re-embedding with this one
+	if pending, _ := NewReembedder(store, embedder, 4, 0).Pending(); len(pending) != 2 {
+		t.Errorf("len(Pending()) without MissingOnly = %d, want 2", len(pending))
+	}
+}
@@ -0,0 +1,286 @@
+// ABOUTME: Scheduler runs built-in maintenance (consolidation, pruning, digests, re-embedding, vault sync, profile review) on cron schedules
+// ABOUTME: Records every run in the database, so each slot runs once across servers and 'memory jobs history' can show it
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/cron"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+const (
+	DefaultScheduleInterval = 30 * time.Second    // How often a server checks whether a scheduled task is due
+	ConsolidateAge          = 30 * 24 * time.Hour // Idle time after which consolidation archives paused and closed topics
+	firstDigestPeriod       = 24 * time.Hour      // Period the first digest covers; later ones cover the time since the last
+	defaultReviewAge        = 90 * 24 * time.Hour // Unreinforced time after which the review task lists a profile item
+	reembedBatchSize        = 100                 // Chunks per embedding request in a scheduled backfill
+	reembedRate             = 60                  // Embedding requests per minute in a scheduled backfill
+)
+
+// ScheduledFunc runs one slot of a scheduled task, returning a one-line summary of what it did
+type ScheduledFunc func(ctx context.Context, run *models.ScheduledRun) (string, error)
+
+// BuiltinTasks is what the built-in tasks work with; a task whose dependency is missing
+// fails each run with the reason, so the history shows why it did nothing
+type BuiltinTasks struct {
+	Pruner      *Pruner
+	Vault       *VaultSyncer  // nil without vault_dir
+	Embedder    BatchEmbedder // nil without an API key
+	DigestDir   string        // Where digests are written
+	ReviewAfter time.Duration // How long a profile item may go unreinforced; defaultReviewAge when zero
+}
+
+// scheduleEntry is a task's schedule and the next slot it runs for
+type scheduleEntry struct {
+	task     models.ScheduledTask
+	schedule *cron.Schedule
+	next     time.Time
+}
+
+// Scheduler runs tasks when their cron schedules fire. A slot is claimed in the
+// database before it runs, so servers sharing a database run it once between them. A
+// slot missed while no server was running runs once when the next server starts;
+// earlier missed slots are skipped.
+type Scheduler struct {
+	store   *storage.Storage
+	loc     *time.Location
+	entries []*scheduleEntry
+	tasks   map[models.ScheduledTask]ScheduledFunc
+	logf    func(format string, args ...any)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// NewScheduler creates a Scheduler that reads cron schedules in loc
+func NewScheduler(store *storage.Storage, loc *time.Location) *Scheduler {
+	if loc == nil {
+		loc = time.Local
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		store:  store,
+		loc:    loc,
+		tasks:  map[models.ScheduledTask]ScheduledFunc{},
+		logf:   log.Printf,
+		ctx:    ctx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+	}
+}
+
+// Schedule runs task whenever schedule fires
+func (s *Scheduler) Schedule(task models.ScheduledTask, schedule *cron.Schedule) {
+	s.entries = append(s.entries, &scheduleEntry{task: task, schedule: schedule})
+}
+
+// Scheduled reports whether task has a schedule
+func (s *Scheduler) Scheduled(task models.ScheduledTask) bool {
+	for _, entry := range s.entries {
+		if entry.task == task {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle registers fn to run task
+func (s *Scheduler) Handle(task models.ScheduledTask, fn ScheduledFunc) {
+	s.tasks[task] = fn
+}
+
+// HandleBuiltins registers the built-in tasks
+func (s *Scheduler) HandleBuiltins(b BuiltinTasks) {
+	s.Handle(models.TaskConsolidate, func(ctx context.Context, run *models.ScheduledRun) (string, error) {
+		cutoff := run.ScheduledFor.Add(-ConsolidateAge)
+		ids, err := s.store.ArchiveIdleBlocks(cutoff)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("archived %d topics idle since %s", len(ids), cutoff.In(s.loc).Format("2006-01-02")), nil
+	})
+	s.Handle(models.TaskPrune, func(ctx context.Context, run *models.ScheduledRun) (string, error) {
+		if b.Pruner == nil {
+			return "", errors.New("pruning is not available")
+		}
+		result, err := b.Pruner.Prune()
+		if err != nil {
+			return "", err
+		}
+		summary := fmt.Sprintf("deleted %d topics past their retention policy", len(result.Expired))
+		if b.Pruner.compactAfter > 0 {
+			summary += fmt.Sprintf(", compacted %d archived topics (about %s)", len(result.Compacted), formatMiB(result.CompactedBytes()))
+		}
+		if b.Pruner.maxBytes > 0 {
+			summary += fmt.Sprintf(", evicted %d archived blocks (about %s)", len(result.Evicted), formatMiB(result.Freed()))
+		}
+		if result.Warning != "" {
+			summary += "; " + result.Warning
+		}
+		return summary, nil
+	})
+	s.Handle(models.TaskDigest, func(ctx context.Context, run *models.ScheduledRun) (string, error) {
+		since := run.ScheduledFor.Add(-firstDigestPeriod)
+		last, err := s.store.LastScheduledRun(models.TaskDigest)
+		if err != nil {
+			return "", err
+		}
+		if last != nil && last.ScheduledFor.Before(run.ScheduledFor) {
+			since = last.ScheduledFor
+		}
+		digest, err := s.store.WriteDigest(b.DigestDir, since, run.ScheduledFor)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("wrote %s: %d topics, %d facts", digest.Path, digest.Topics, digest.Facts), nil
+	})
+	s.Handle(models.TaskReembed, func(ctx context.Context, run *models.ScheduledRun) (string, error) {
+		if b.Embedder == nil {
+			return "", errors.New("no OpenAI API key")
+		}
+		progress, err := NewReembedder(s.store, b.Embedder, reembedBatchSize, reembedRate).Run(ctx, nil)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("embedded %d turns (%d chunks) with %s", progress.DoneTurns, progress.Chunks, b.Embedder.EmbeddingModel()), nil
+	})
+	s.Handle(models.TaskSync, func(ctx context.Context, run *models.ScheduledRun) (string, error) {
+		if b.Vault == nil {
+			return "", errors.New("vault_dir is not set")
+		}
+		result, err := b.Vault.SyncIfChanged()
+		if err != nil {
+			return "", err
+		}
+		if result == nil {
+			return "vault unchanged", nil
+		}
+		return fmt.Sprintf("wrote %d notes, removed %d, %d unchanged", len(result.Written), len(result.Removed), result.Unchanged), nil
+	})
+	s.Handle(models.TaskReview, func(ctx context.Context, run *models.ScheduledRun) (string, error) {
+		age := b.ReviewAfter
+		if age <= 0 {
+			age = defaultReviewAge
+		}
+		cutoff := run.ScheduledFor.Add(-age)
+		items, err := s.store.StaleProfileItems(cutoff)
+		if err != nil {
+			return "", err
+		}
+		since := cutoff.In(s.loc).Format("2006-01-02")
+		if len(items) == 0 {
+			return fmt.Sprintf("no profile items unreinforced since %s", since), nil
+		}
+		return fmt.Sprintf("%d profile items unreinforced since %s; review them with 'memory profile review'", len(items), since), nil
+	})
+}
+
+// Start checks for due tasks now and then every interval until Stop; it does nothing
+// without a schedule
+func (s *Scheduler) Start(interval time.Duration) {
+	if len(s.entries) == 0 {
+		return
+	}
+	s.plan(time.Now())
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.runDue(time.Now())
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// plan sets each task's next slot: the first after its last recorded run, or after now
+// for a task that never ran
+func (s *Scheduler) plan(now time.Time) {
+	for _, entry := range s.entries {
+		from := now
+		if runs, err := s.store.ListScheduledRuns(entry.task, 1); err != nil {
+			s.logf("[Schedule] %v", err)
+		} else if len(runs) > 0 && runs[0].ScheduledFor.Before(now) {
+			from = runs[0].ScheduledFor
+		}
+		entry.next = entry.schedule.Next(from.In(s.loc))
+		if entry.next.IsZero() {
+			s.logf("[Schedule] %s: %q never fires", entry.task, entry.schedule)
+		}
+	}
+}
+
+// runDue runs every task whose next slot has come, once for the latest slot at or
+// before now, and returns the runs it recorded. Slots another server already claimed
+// are skipped.
+func (s *Scheduler) runDue(now time.Time) []models.ScheduledRun {
+	var runs []models.ScheduledRun
+	for _, entry := range s.entries {
+		if entry.next.IsZero() || now.Before(entry.next) {
+			continue
+		}
+		slot := entry.next
+		for next := entry.schedule.Next(slot); !next.IsZero() && !next.After(now); next = entry.schedule.Next(next) {
+			slot = next
+		}
+		entry.next = entry.schedule.Next(slot)
+
+		if run := s.run(entry.task, slot); run != nil {
+			runs = append(runs, *run)
+		}
+	}
+	return runs
+}
+
+// run claims the slot and runs the task, recording and logging how it went
+func (s *Scheduler) run(task models.ScheduledTask, slot time.Time) *models.ScheduledRun {
+	run, err := s.store.StartScheduledRun(task, slot)
+	if err != nil {
+		s.logf("[Schedule] %s: %v", task, err)
+		return nil
+	}
+	if run == nil {
+		return nil
+	}
+
+	var summary string
+	fn, ok := s.tasks[task]
+	if ok {
+		summary, err = fn(s.ctx, run)
+	} else {
+		err = fmt.Errorf("%s cannot run in this process", task)
+	}
+	if finishErr := s.store.FinishScheduledRun(run, summary, err); finishErr != nil {
+		s.logf("[Schedule] %s: %v", task, finishErr)
+	}
+	if err != nil {
+		s.logf("[Schedule] %s failed: %v", task, err)
+	} else {
+		s.logf("[Schedule] %s: %s", task, summary)
+	}
+	return run
+}
+
+// Stop ends the scheduling loop, cancelling a running task and waiting for it to return
+func (s *Scheduler) Stop() {
+	s.once.Do(func() {
+		close(s.stop)
+		s.cancel()
+	})
+	s.wg.Wait()
+}
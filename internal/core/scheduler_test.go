@@ -0,0 +1,217 @@
+// ABOUTME: Tests for the Scheduler
+// ABOUTME: Verifies tasks run once per slot, missed slots catch up once, and failures are recorded
+
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/cron"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+func newTestScheduler(t *testing.T, store *storage.Storage, entries map[models.ScheduledTask]string) *Scheduler {
+	t.Helper()
+	s := NewScheduler(store, time.UTC)
+	s.logf = func(string, ...any) {}
+	for task, expr := range entries {
+		schedule, err := cron.Parse(expr)
+		if err != nil {
+			t.Fatalf("cron.Parse(%q) error = %v", expr, err)
+		}
+		s.Schedule(task, schedule)
+	}
+	return s
+}
+
+func TestScheduler_RunsEachSlotOnce(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	s := newTestScheduler(t, store, map[models.ScheduledTask]string{models.TaskPrune: "0 3 * * *"})
+	var slots []time.Time
+	s.Handle(models.TaskPrune, func(ctx context.Context, run *models.ScheduledRun) (string, error) {
+		slots = append(slots, run.ScheduledFor)
+		return "pruned", nil
+	})
+
+	start := time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC)
+	s.plan(start)
+	if runs := s.runDue(start.Add(30 * time.Minute)); len(runs) != 0 {
+		t.Fatalf("runDue() before the slot = %v, want nothing", runs)
+	}
+	runs := s.runDue(start.Add(61 * time.Minute))
+	if len(runs) != 1 || runs[0].Status != models.JobDone || runs[0].Summary != "pruned" {
+		t.Fatalf("runDue() at the slot = %+v, want one done run", runs)
+	}
+	if runs := s.runDue(start.Add(62 * time.Minute)); len(runs) != 0 {
+		t.Errorf("runDue() again = %v, want the slot to run once", runs)
+	}
+
+	// A second server sharing the database skips the slot the first one claimed
+	other := newTestScheduler(t, store, map[models.ScheduledTask]string{models.TaskPrune: "0 3 * * *"})
+	other.Handle(models.TaskPrune, func(ctx context.Context, run *models.ScheduledRun) (string, error) {
+		t.Error("second server ran a claimed slot")
+		return "", nil
+	})
+	other.plan(start)
+	if runs := other.runDue(start.Add(61 * time.Minute)); len(runs) != 0 {
+		t.Errorf("second server runDue() = %v, want nothing", runs)
+	}
+	if want := time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC); len(slots) != 1 || !slots[0].Equal(want) {
+		t.Errorf("slots = %v, want one run for %v", slots, want)
+	}
+}
+
+func TestScheduler_CatchesUpOnce(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	entries := map[models.ScheduledTask]string{models.TaskDigest: "@hourly"}
+	count := 0
+	handler := func(ctx context.Context, run *models.ScheduledRun) (string, error) {
+		count++
+		return "", nil
+	}
+	first := newTestScheduler(t, store, entries)
+	first.Handle(models.TaskDigest, handler)
+	start := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	first.plan(start)
+	first.runDue(start.Add(45 * time.Minute))
+
+	// The next server starts five hours later: only the latest missed slot runs
+	later := start.Add(5 * time.Hour)
+	second := newTestScheduler(t, store, entries)
+	second.Handle(models.TaskDigest, handler)
+	second.plan(later)
+	runs := second.runDue(later)
+	if want := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC); len(runs) != 1 || !runs[0].ScheduledFor.Equal(want) {
+		t.Fatalf("runDue() after downtime = %+v, want one run for %v", runs, want)
+	}
+	if count != 2 {
+		t.Errorf("handler ran %d times, want 2", count)
+	}
+}
+
+func TestScheduler_RecordsFailures(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	s := newTestScheduler(t, store, map[models.ScheduledTask]string{
+		models.TaskReembed: "* * * * *",
+		models.TaskSync:    "* * * * *",
+		models.TaskDigest:  "* * * * *",
+	})
+	s.HandleBuiltins(BuiltinTasks{DigestDir: t.TempDir()})
+	s.Handle(models.TaskDigest, func(ctx context.Context, run *models.ScheduledRun) (string, error) {
+		return "", errors.New("disk full")
+	})
+
+	now := time.Date(2026, 10, 16, 12, 0, 30, 0, time.UTC)
+	s.plan(now.Add(-time.Minute))
+	runs := s.runDue(now)
+	if len(runs) != 3 {
+		t.Fatalf("runDue() = %+v, want 3 runs", runs)
+	}
+	errs := map[models.ScheduledTask]string{}
+	for _, run := range runs {
+		if run.Status != models.JobFailed {
+			t.Errorf("%s status = %s, want failed", run.Task, run.Status)
+		}
+		errs[run.Task] = run.Error
+	}
+	for task, want := range map[models.ScheduledTask]string{
+		models.TaskReembed: "no OpenAI API key",
+		models.TaskSync:    "vault_dir is not set",
+		models.TaskDigest:  "disk full",
+	} {
+		if !strings.Contains(errs[task], want) {
+			t.Errorf("%s error = %q, want %q", task, errs[task], want)
+		}
+	}
+
+	history, err := store.ListScheduledRuns("", 10)
+	if err != nil || len(history) != 3 {
+		t.Errorf("ListScheduledRuns() = %v, %v, want the 3 runs recorded", history, err)
+	}
+}
+
+func TestScheduler_BuiltinConsolidateAndDigest(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Now().UTC()
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_sched_a", Timestamp: now.Add(-time.Hour), UserMessage: "Plan the offsite", Topics: []string{"Offsite"}}); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	s := newTestScheduler(t, store, map[models.ScheduledTask]string{
+		models.TaskConsolidate: "* * * * *",
+		models.TaskDigest:      "* * * * *",
+	})
+	dir := t.TempDir()
+	s.HandleBuiltins(BuiltinTasks{DigestDir: dir})
+	s.plan(now.Add(-time.Minute))
+	runs := s.runDue(now)
+	if len(runs) != 2 {
+		t.Fatalf("runDue() = %+v, want 2 runs", runs)
+	}
+	for _, run := range runs {
+		if run.Status != models.JobDone {
+			t.Errorf("%s = %+v, want done", run.Task, run)
+		}
+	}
+	if !strings.HasPrefix(runs[0].Summary, "archived 0 topics") && !strings.HasPrefix(runs[1].Summary, "archived 0 topics") {
+		t.Errorf("summaries = %q, %q; want consolidation to archive nothing recent", runs[0].Summary, runs[1].Summary)
+	}
+	if !strings.Contains(runs[0].Summary+runs[1].Summary, dir) {
+		t.Errorf("summaries = %q, %q; want the digest written to %s", runs[0].Summary, runs[1].Summary, dir)
+	}
+}
+
+func TestScheduler_BuiltinReview(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.SaveUserProfile(&models.UserProfile{Preferences: []string{"Prefers tea"}, TopicsOfInterest: []string{"sailing"}}); err != nil {
+		t.Fatalf("SaveUserProfile() error = %v", err)
+	}
+
+	s := newTestScheduler(t, store, map[models.ScheduledTask]string{models.TaskReview: "* * * * *"})
+	s.HandleBuiltins(BuiltinTasks{ReviewAfter: 30 * 24 * time.Hour})
+
+	// Nothing has gone unreinforced for 30 days yet
+	now := time.Now().UTC()
+	s.plan(now.Add(-time.Minute))
+	runs := s.runDue(now)
+	if len(runs) != 1 || runs[0].Status != models.JobDone || !strings.HasPrefix(runs[0].Summary, "no profile items") {
+		t.Fatalf("runDue() = %+v, want one run finding nothing to review", runs)
+	}
+
+	later := now.Add(31 * 24 * time.Hour)
+	s.plan(later.Add(-time.Minute))
+	runs = s.runDue(later)
+	if len(runs) != 1 || !strings.HasPrefix(runs[0].Summary, "2 profile items") {
+		t.Fatalf("runDue() a month later = %+v, want both items up for review", runs)
+	}
+}
@@ -51,8 +51,11 @@ func NewScribe(client interface{}) *Scribe {
 		}
 	}
 
-	// Get chat model from env or use default
+	// Use the wrapper's configured chat model, else env or default
 	chatModel := os.Getenv("MEMORY_OPENAI_MODEL")
+	if configured, ok := client.(interface{ ChatModel() string }); ok {
+		chatModel = configured.ChatModel()
+	}
 	if chatModel == "" {
 		chatModel = "gpt-4o-mini"
 	}
@@ -65,19 +68,21 @@ func NewScribe(client interface{}) *Scribe {
 	}
 }
 
-// UpdateProfileAsync runs Scribe in a goroutine (fire-and-forget)
-// Analyzes user message and updates profile asynchronously
-func (s *Scribe) UpdateProfileAsync(userMessage string, profile *models.UserProfile, store *storage.Storage) {
-	// This method is typically called with `go scribe.UpdateProfileAsync(...)`
-	// Run the actual update logic
-	if err := s.updateProfile(userMessage, profile, store); err != nil {
-		// Log error but don't crash - this is async background work
-		log.Printf("[Scribe] Error updating profile: %v", err)
-	}
+// UpdatePersona learns from userMessage and returns any error; the background job queue
+// calls it with bounded concurrency and retries failures. Learned preferences and
+// constraints go to persona, while name and topics go to the shared profile. An empty
+// persona updates the shared profile only. turnIDs name the turns userMessage came from;
+// the profile history records them with what was learned.
+func (s *Scribe) UpdatePersona(userMessage, persona string, turnIDs []string, store *storage.Storage) error {
+	return s.updateProfile(userMessage, persona, turnIDs, &models.UserProfile{
+		Preferences:      []string{},
+		TopicsOfInterest: []string{},
+		LastUpdated:      time.Now(),
+	}, store)
 }
 
 // updateProfile is the internal sync implementation
-func (s *Scribe) updateProfile(userMessage string, profile *models.UserProfile, store *storage.Storage) error {
+func (s *Scribe) updateProfile(userMessage, persona string, turnIDs []string, profile *models.UserProfile, store *storage.Storage) error {
 	// Skip empty messages
 	if strings.TrimSpace(userMessage) == "" {
 		return nil
@@ -107,13 +112,35 @@ func (s *Scribe) updateProfile(userMessage string, profile *models.UserProfile,
 		currentProfile = profile
 	}
 
-	// Merge new info into profile
-	currentProfile.Merge(userInfo)
+	// Load the persona the conversation belongs to, if any
+	var currentPersona *models.Persona
+	if persona != "" {
+		currentPersona, err = store.GetPersona(persona)
+		if err != nil {
+			return fmt.Errorf("failed to load persona: %w", err)
+		}
+		if currentPersona == nil {
+			currentPersona = &models.Persona{Name: persona}
+		}
+	}
+
+	// Merge new info into profile (and persona)
+	currentProfile.MergeForPersona(currentPersona, userInfo)
 
 	// Save updated profile
-	if err := store.SaveUserProfile(currentProfile); err != nil {
+	if err := store.SaveLearnedProfile(currentProfile, turnIDs); err != nil {
 		return fmt.Errorf("failed to save profile: %w", err)
 	}
+	if currentPersona != nil {
+		if err := store.SaveLearnedPersona(currentPersona, turnIDs); err != nil {
+			return fmt.Errorf("failed to save persona: %w", err)
+		}
+	}
+
+	// Preferences and topics learned again stay fresh for profile review
+	if err := store.ReinforceProfileItems(persona, models.InfoStrings(userInfo, "preferences"), models.InfoStrings(userInfo, "topics_of_interest")); err != nil {
+		return fmt.Errorf("failed to reinforce profile: %w", err)
+	}
 
 	log.Printf("[Scribe] Profile updated successfully")
 	return nil
@@ -127,9 +154,10 @@ Extract the following if present:
 - name: user's name (string)
 - preferences: list of preferences, habits, or ways they like to work (array of strings)
 - topics_of_interest: subjects, technologies, or areas they're interested in (array of strings)
+- constraints: rules that answers must respect, such as allergies, dietary restrictions, or accessibility needs (array of objects with "type", "description", and "severity" of "strict", "moderate", or "mild")
 
 Return ONLY a JSON object with these fields. Only include fields that are actually mentioned.
-Example: {"name": "Alice", "preferences": ["TDD", "simple solutions"], "topics_of_interest": ["AI", "distributed systems"]}
+Example: {"name": "Alice", "preferences": ["TDD", "simple solutions"], "topics_of_interest": ["AI", "distributed systems"], "constraints": [{"type": "Dietary Restriction", "description": "vegetarian", "severity": "strict"}]}
 
 If nothing is found, return an empty object: {}`
 
@@ -0,0 +1,121 @@
+// ABOUTME: Startup self-check for MCP servers: schema version, the one-active-block invariant, and embeddings
+// ABOUTME: Repairs what it safely can and logs a one-line summary instead of waiting for a user to run repair commands
+package core
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// SelfCheckReport is what a startup self-check found and repaired
+type SelfCheckReport struct {
+	SchemaVersion       int           // Version recorded in the database
+	ActiveBlocks        int           // ACTIVE blocks found before any repair
+	RepairedBlocks      bool          // Whether extra ACTIVE blocks were paused
+	EmbeddingDimensions map[int]int   // Stored vectors by dimension; 0 counts malformed vectors
+	Problems            []string      // What needs attention and could not be repaired here
+	Duration            time.Duration // How long the check took
+}
+
+// RunSelfCheck checks the database a server is about to serve. Open has already
+// migrated the schema, so the check confirms the recorded version and flags a database
+// written by a newer build. With repair set, more than one ACTIVE block is fixed by
+// pausing all but the newest; read-only servers only report it. Embeddings are scanned
+// by size, which never reads the vectors themselves, for mixed dimensions (left by a
+// model change, and meaningless to compare) and malformed vectors.
+func RunSelfCheck(store *storage.Storage, repair bool) SelfCheckReport {
+	start := time.Now()
+	var report SelfCheckReport
+
+	if version, err := store.SchemaVersion(); err != nil {
+		report.problem("%v", err)
+	} else {
+		report.SchemaVersion = version
+		if version > storage.SchemaVersion {
+			report.problem("database schema version %d is newer than this build's %d; upgrade memory before writing to it", version, storage.SchemaVersion)
+		}
+	}
+
+	if blocks, err := store.GetActiveBridgeBlocks(); err != nil {
+		report.problem("%v", err)
+	} else {
+		report.ActiveBlocks = len(blocks)
+	}
+	if report.ActiveBlocks > 1 {
+		if !repair {
+			report.problem("%d blocks are active, want at most 1; run 'memory sync repair-blocks'", report.ActiveBlocks)
+		} else if repaired, err := store.RepairActiveBlockInvariant(); err != nil {
+			report.problem("%v", err)
+		} else {
+			report.RepairedBlocks = repaired
+		}
+	}
+
+	if dims, err := store.EmbeddingDimensions(); err != nil {
+		report.problem("%v", err)
+	} else {
+		report.EmbeddingDimensions = dims
+		if n := dims[0]; n > 0 {
+			report.problem("%d embeddings are malformed; run 'memory reembed' to regenerate them", n)
+		}
+		if sizes := report.dimensions(); len(sizes) > 1 {
+			report.problem("embeddings have mixed dimensions (%s), and searches never match vectors of another dimension; run 'memory reembed' to regenerate them with one model", report.formatDimensions())
+		}
+	}
+
+	report.Duration = time.Since(start)
+	return report
+}
+
+// problem records something that needs attention
+func (r *SelfCheckReport) problem(format string, args ...any) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// dimensions returns the well-formed vector dimensions found, smallest first
+func (r *SelfCheckReport) dimensions() []int {
+	sizes := make([]int, 0, len(r.EmbeddingDimensions))
+	for dim := range r.EmbeddingDimensions {
+		if dim > 0 {
+			sizes = append(sizes, dim)
+		}
+	}
+	sort.Ints(sizes)
+	return sizes
+}
+
+// formatDimensions lists vector counts by dimension, e.g. "1536:1200,3072:4"
+func (r *SelfCheckReport) formatDimensions() string {
+	sizes := r.dimensions()
+	if len(sizes) == 0 {
+		return "none"
+	}
+	parts := make([]string, len(sizes))
+	for i, dim := range sizes {
+		parts[i] = fmt.Sprintf("%d:%d", dim, r.EmbeddingDimensions[dim])
+	}
+	return strings.Join(parts, ",")
+}
+
+// Summary is the report as one line of key=value pairs, easy to grep and parse
+func (r *SelfCheckReport) Summary() string {
+	return fmt.Sprintf("schema_version=%d active_blocks=%d repaired_blocks=%t embedding_dimensions=%s problems=%d duration=%s",
+		r.SchemaVersion, r.ActiveBlocks, r.RepairedBlocks, r.formatDimensions(), len(r.Problems), r.Duration.Round(time.Millisecond))
+}
+
+// Log writes the summary and then each problem as a warning
+func (r *SelfCheckReport) Log() {
+	log.Printf("[SelfCheck] %s", r.Summary())
+	if r.RepairedBlocks {
+		log.Printf("[SelfCheck] paused all but the newest of %d %s blocks", r.ActiveBlocks, models.StatusActive)
+	}
+	for _, problem := range r.Problems {
+		log.Printf("Warning: %s", problem)
+	}
+}
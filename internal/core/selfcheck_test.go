@@ -0,0 +1,107 @@
+// ABOUTME: Tests for the startup self-check
+// ABOUTME: Verifies active-block repair, read-only reporting, and mixed embedding dimensions
+
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// newSelfCheckTestStore returns a store with two ACTIVE blocks and vectors of two dimensions
+func newSelfCheckTestStore(t *testing.T) *storage.Storage {
+	t.Helper()
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	// Each stored turn opens a new block and pauses the last one
+	var blockIDs []string
+	base := time.Now()
+	for i, id := range []string{"turn_check_a", "turn_check_b"} {
+		blockID, err := store.StoreTurn(&models.Turn{TurnID: id, Timestamp: base.Add(time.Duration(i) * time.Second), UserMessage: "hello"})
+		if err != nil {
+			t.Fatalf("StoreTurn() error = %v", err)
+		}
+		blockIDs = append(blockIDs, blockID)
+	}
+	if err := store.UpdateBridgeBlockStatus(blockIDs[0], models.StatusActive); err != nil {
+		t.Fatalf("UpdateBridgeBlockStatus() error = %v", err)
+	}
+
+	vectors := store.GetVectorStorage()
+	if err := vectors.SaveWithDimension("chunk_a", "turn_check_a", blockIDs[0], []float64{1, 0, 0}, 3); err != nil {
+		t.Fatalf("SaveWithDimension() error = %v", err)
+	}
+	if err := vectors.SaveWithDimension("chunk_b", "turn_check_b", blockIDs[1], []float64{1, 0, 0, 0}, 4); err != nil {
+		t.Fatalf("SaveWithDimension() error = %v", err)
+	}
+	return store
+}
+
+func TestRunSelfCheck(t *testing.T) {
+	store := newSelfCheckTestStore(t)
+
+	report := RunSelfCheck(store, true)
+	if report.SchemaVersion != storage.SchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", report.SchemaVersion, storage.SchemaVersion)
+	}
+	if report.ActiveBlocks != 2 || !report.RepairedBlocks {
+		t.Errorf("ActiveBlocks = %d, RepairedBlocks = %v, want 2 repaired", report.ActiveBlocks, report.RepairedBlocks)
+	}
+	if active, _ := store.GetActiveBridgeBlocks(); len(active) != 1 {
+		t.Errorf("active blocks after repair = %d, want 1", len(active))
+	}
+	if len(report.Problems) != 1 || !strings.Contains(report.Problems[0], "mixed dimensions (3:1,4:1)") {
+		t.Errorf("Problems = %v, want only the mixed dimensions", report.Problems)
+	}
+
+	summary := report.Summary()
+	for _, want := range []string{"schema_version=", "active_blocks=2", "repaired_blocks=true", "embedding_dimensions=3:1,4:1", "problems=1"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary() = %q, want it to contain %q", summary, want)
+		}
+	}
+
+	// Once repaired, a second check finds nothing new to fix
+	if again := RunSelfCheck(store, true); again.ActiveBlocks != 1 || again.RepairedBlocks {
+		t.Errorf("second check = %+v, want one active block and no repair", again)
+	}
+}
+
+func TestRunSelfCheck_ReadOnly(t *testing.T) {
+	store := newSelfCheckTestStore(t)
+
+	report := RunSelfCheck(store, false)
+	if report.RepairedBlocks {
+		t.Error("RepairedBlocks = true without repair")
+	}
+	if active, _ := store.GetActiveBridgeBlocks(); len(active) != 2 {
+		t.Errorf("active blocks = %d, want both left alone", len(active))
+	}
+	if !strings.Contains(strings.Join(report.Problems, "\n"), "repair-blocks") {
+		t.Errorf("Problems = %v, want one pointing at repair-blocks", report.Problems)
+	}
+}
+
+func TestRunSelfCheck_Empty(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	report := RunSelfCheck(store, true)
+	if len(report.Problems) != 0 || report.RepairedBlocks {
+		t.Errorf("report = %+v, want a clean check", report)
+	}
+	if !strings.Contains(report.Summary(), "embedding_dimensions=none") {
+		t.Errorf("Summary() = %q, want no embeddings", report.Summary())
+	}
+}
@@ -0,0 +1,342 @@
+// ABOUTME: Stress harness storing and searching turns from many goroutines and storage handles at once
+// ABOUTME: Checks the database is consistent afterwards and reports throughput and latency percentiles
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// stressMaxErrors is how many operation errors a stress report quotes
+const stressMaxErrors = 10
+
+// stressVocabulary is what stress turns talk about and readers search for
+var stressVocabulary = []string{
+	"kubernetes", "garden", "recipe", "budget", "marathon", "guitar", "sourdough", "invoice",
+	"python", "vacation", "mortgage", "telescope", "podcast", "chess", "espresso", "backup",
+}
+
+// StressOptions configure a stress run; zero values use the defaults
+type StressOptions struct {
+	Writers int // Goroutines storing turns (default 8)
+	Readers int // Goroutines searching while the writers run (default half the writers)
+	Turns   int // Turns stored across all writers (default 1000)
+	Stores  int // Storage handles on the database, each standing in for a process (default 2)
+}
+
+// withDefaults fills in the zero values of opts
+func (opts StressOptions) withDefaults() StressOptions {
+	if opts.Writers <= 0 {
+		opts.Writers = 8
+	}
+	if opts.Readers <= 0 {
+		opts.Readers = max(1, opts.Writers/2)
+	}
+	if opts.Turns <= 0 {
+		opts.Turns = 1000
+	}
+	if opts.Stores <= 0 {
+		opts.Stores = 2
+	}
+	return opts
+}
+
+// LatencyStats summarizes the calls made to one operation during a stress run
+type LatencyStats struct {
+	Count  int     `json:"count"`
+	Errors int     `json:"errors"`
+	PerSec float64 `json:"per_sec"`
+	P50Ms  float64 `json:"p50_ms"`
+	P95Ms  float64 `json:"p95_ms"`
+	P99Ms  float64 `json:"p99_ms"`
+	MaxMs  float64 `json:"max_ms"`
+}
+
+// StressReport is the outcome of a stress run
+type StressReport struct {
+	Writers    int          `json:"writers"`
+	Readers    int          `json:"readers"`
+	Turns      int          `json:"turns"`
+	Stores     int          `json:"stores"`
+	ElapsedMs  float64      `json:"elapsed_ms"`
+	Store      LatencyStats `json:"store"`  // StoreTurn and AppendTurnToBlock
+	Search     LatencyStats `json:"search"` // SearchMemory
+	Errors     []string     `json:"errors,omitempty"`
+	Violations []string     `json:"violations"` // Invariants found broken afterwards
+}
+
+// OK reports whether every call succeeded and every invariant held
+func (r *StressReport) OK() bool {
+	return r.Store.Errors == 0 && r.Search.Errors == 0 && len(r.Violations) == 0
+}
+
+// stressRecorder collects latencies and errors from concurrent callers
+type stressRecorder struct {
+	mu       sync.Mutex
+	store    []time.Duration
+	search   []time.Duration
+	storeErr int
+	readErr  int
+	errors   []string
+	placed   map[string]string // turn ID -> block ID it was stored in
+	active   int               // Most blocks readers saw active at once
+}
+
+func (rec *stressRecorder) stored(turnID, blockID string, took time.Duration, err error) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.store = append(rec.store, took)
+	if err != nil {
+		rec.storeErr++
+		rec.noteError("store", err)
+		return
+	}
+	rec.placed[turnID] = blockID
+}
+
+func (rec *stressRecorder) searched(took time.Duration, err error) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.search = append(rec.search, took)
+	if err != nil {
+		rec.readErr++
+		rec.noteError("search", err)
+	}
+}
+
+// sawActive notes how many blocks a reader saw active
+func (rec *stressRecorder) sawActive(n int) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.active = max(rec.active, n)
+}
+
+func (rec *stressRecorder) noteError(op string, err error) {
+	if len(rec.errors) < stressMaxErrors {
+		rec.errors = append(rec.errors, fmt.Sprintf("%s: %v", op, err))
+	}
+}
+
+// RunStress stores opts.Turns turns from opts.Writers goroutines while opts.Readers
+// goroutines search, spread over opts.Stores storage handles from open, all on the
+// same database. Writers start new topics, append to their own, and every fifth turn
+// append to one topic they all share, so writes contend within and across handles.
+// Readers also watch that at most one block is active at any moment, since a later
+// new topic pauses every active block and hides a race that left two. Afterwards a
+// fresh handle checks that every stored turn is there, in the block it was stored in,
+// that each block's turn count matches its turns, and that at most one block is
+// active. Cancelling ctx stops the writers early; what was stored is still checked.
+func RunStress(ctx context.Context, open func() (*storage.Storage, error), opts StressOptions) (*StressReport, error) {
+	opts = opts.withDefaults()
+	stores := make([]*storage.Storage, 0, opts.Stores)
+	defer func() {
+		for _, store := range stores {
+			_ = store.Close()
+		}
+	}()
+	for i := 0; i < opts.Stores; i++ {
+		store, err := open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open storage: %w", err)
+		}
+		stores = append(stores, store)
+	}
+
+	rec := &stressRecorder{placed: make(map[string]string, opts.Turns+1)}
+
+	// The shared topic every writer appends to
+	shared := stressTurn(-1, 0, rand.New(rand.NewSource(0)))
+	sharedID, err := stores[0].StoreTurn(shared)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store the shared topic: %w", err)
+	}
+	rec.placed[shared.TurnID] = sharedID
+
+	start := time.Now()
+	var writers, readers sync.WaitGroup
+	writing := make(chan struct{})
+	for w := 0; w < opts.Writers; w++ {
+		writers.Add(1)
+		go func(w int) {
+			defer writers.Done()
+			store := stores[w%len(stores)]
+			rng := rand.New(rand.NewSource(int64(w) + 1))
+			current := ""
+			for j, i := 0, w; i < opts.Turns && ctx.Err() == nil; j, i = j+1, i+opts.Writers {
+				turn := stressTurn(w, j, rng)
+				began := time.Now()
+				var blockID string
+				var err error
+				switch {
+				case j%5 == 4:
+					blockID = sharedID
+					err = store.AppendTurnToBlock(blockID, turn)
+				case j%10 == 0 || current == "":
+					blockID, err = store.StoreTurn(turn)
+					if err == nil {
+						current = blockID
+					}
+				default:
+					blockID = current
+					err = store.AppendTurnToBlock(blockID, turn)
+				}
+				rec.stored(turn.TurnID, blockID, time.Since(began), err)
+			}
+		}(w)
+	}
+	for r := 0; r < opts.Readers; r++ {
+		readers.Add(1)
+		go func(r int) {
+			defer readers.Done()
+			store := stores[r%len(stores)]
+			rng := rand.New(rand.NewSource(int64(-r) - 1))
+			for {
+				select {
+				case <-writing:
+					return
+				default:
+				}
+				query := stressVocabulary[rng.Intn(len(stressVocabulary))]
+				began := time.Now()
+				_, err := store.SearchMemory(query, 5)
+				rec.searched(time.Since(began), err)
+				if active, err := store.GetActiveBridgeBlocks(); err == nil {
+					rec.sawActive(len(active))
+				}
+			}
+		}(r)
+	}
+	writers.Wait()
+	close(writing)
+	readers.Wait()
+	elapsed := time.Since(start)
+
+	report := &StressReport{
+		Writers:    opts.Writers,
+		Readers:    opts.Readers,
+		Turns:      opts.Turns,
+		Stores:     opts.Stores,
+		ElapsedMs:  float64(elapsed.Microseconds()) / 1000,
+		Store:      stressLatency(rec.store, rec.storeErr, elapsed),
+		Search:     stressLatency(rec.search, rec.readErr, elapsed),
+		Errors:     rec.errors,
+		Violations: []string{},
+	}
+	if rec.active > 1 {
+		report.Violations = append(report.Violations, fmt.Sprintf("%d blocks were active at once while writing; at most one may be", rec.active))
+	}
+
+	check, err := open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer func() { _ = check.Close() }()
+	violations, err := stressInvariants(check, rec.placed)
+	if err != nil {
+		return nil, err
+	}
+	report.Violations = append(report.Violations, violations...)
+	return report, nil
+}
+
+// stressTurn makes writer w's jth turn: a token unique to it plus a few vocabulary words
+func stressTurn(w, j int, rng *rand.Rand) *models.Turn {
+	token := fmt.Sprintf("stress%dx%d", w+1, j)
+	words := []string{token}
+	for k := 0; k < 3; k++ {
+		words = append(words, stressVocabulary[rng.Intn(len(stressVocabulary))])
+	}
+	return &models.Turn{
+		TurnID:      models.NewID("turn"),
+		UserMessage: "Notes on " + strings.Join(words, " "),
+		AIResponse:  "Noted.",
+		Keywords:    words[:2],
+		Topics:      []string{words[1]},
+	}
+}
+
+// stressInvariants checks what the stress writers left behind: every turn stored is
+// present in the block it was stored in, no other turns exist, at most one block is
+// active, and every block's turn count matches its turns
+func stressInvariants(store *storage.Storage, placed map[string]string) ([]string, error) {
+	var violations []string
+	stats, err := store.Stats(0)
+	if err != nil {
+		return nil, err
+	}
+	if stats.TurnCount != len(placed) {
+		violations = append(violations, fmt.Sprintf("%d turns stored but %d in the database", len(placed), stats.TurnCount))
+	}
+
+	missing, moved := 0, 0
+	for turnID, blockID := range placed {
+		gotBlock, turn, err := store.GetTurn(turnID)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case turn == nil:
+			missing++
+		case gotBlock != blockID:
+			moved++
+		}
+	}
+	if missing > 0 {
+		violations = append(violations, fmt.Sprintf("%d stored turns are missing", missing))
+	}
+	if moved > 0 {
+		violations = append(violations, fmt.Sprintf("%d turns are not in the block they were stored in", moved))
+	}
+
+	active, err := store.GetActiveBridgeBlocks()
+	if err != nil {
+		return nil, err
+	}
+	if len(active) > 1 {
+		violations = append(violations, fmt.Sprintf("%d blocks are active; at most one may be", len(active)))
+	}
+
+	mismatches, err := store.TurnCountMismatches()
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range mismatches {
+		if i == stressMaxErrors {
+			violations = append(violations, fmt.Sprintf("... and %d more blocks with wrong turn counts", len(mismatches)-i))
+			break
+		}
+		violations = append(violations, fmt.Sprintf("block %s records %d turns but holds %d", m.BlockID, m.Recorded, m.Actual))
+	}
+	return violations, nil
+}
+
+// stressLatency summarizes the durations of calls made over elapsed
+func stressLatency(durations []time.Duration, errors int, elapsed time.Duration) LatencyStats {
+	stats := LatencyStats{Count: len(durations), Errors: errors}
+	if len(durations) == 0 {
+		return stats
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	percentile := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(sorted)))) - 1
+		return float64(sorted[max(i, 0)].Microseconds()) / 1000
+	}
+	if elapsed > 0 {
+		stats.PerSec = float64(len(durations)) / elapsed.Seconds()
+	}
+	stats.P50Ms = percentile(0.50)
+	stats.P95Ms = percentile(0.95)
+	stats.P99Ms = percentile(0.99)
+	stats.MaxMs = percentile(1)
+	return stats
+}
@@ -0,0 +1,84 @@
+// ABOUTME: Tests for the concurrent stress harness
+// ABOUTME: Verifies a run over several storage handles keeps every invariant, and latency percentiles
+
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+func TestRunStress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.db")
+	open := func() (*storage.Storage, error) { return storage.NewStorageWithPath(path) }
+
+	report, err := RunStress(context.Background(), open, StressOptions{Writers: 4, Turns: 60, Stores: 2})
+	if err != nil {
+		t.Fatalf("RunStress() error = %v", err)
+	}
+	if !report.OK() {
+		t.Fatalf("RunStress() errors = %v, violations = %v", report.Errors, report.Violations)
+	}
+	if report.Store.Count != 60 || report.Readers != 2 || report.Search.Count == 0 {
+		t.Errorf("report = %d stores and %d searches from %d readers, want 60 stores and some searches from 2", report.Store.Count, report.Search.Count, report.Readers)
+	}
+	if report.Store.P95Ms < report.Store.P50Ms || report.Store.MaxMs < report.Store.P99Ms || report.Store.PerSec <= 0 {
+		t.Errorf("store latency = %+v, want ordered percentiles and a throughput", report.Store)
+	}
+
+	// Turns from the run plus the shared topic's first turn
+	store, err := open()
+	if err != nil {
+		t.Fatalf("open() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	stats, err := store.Stats(0)
+	if err != nil || stats.TurnCount != 61 {
+		t.Errorf("Stats() = %d turns, %v, want 61", stats.TurnCount, err)
+	}
+}
+
+func TestStressInvariants_TurnCount(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	turn := &models.Turn{TurnID: "turn_1", UserMessage: "hello"}
+	blockID, err := store.StoreTurn(turn)
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if violations, err := stressInvariants(store, map[string]string{"turn_1": blockID}); err != nil || len(violations) != 0 {
+		t.Fatalf("stressInvariants() = %v, %v, want none", violations, err)
+	}
+
+	// A turn reported stored that never reached the database
+	violations, err := stressInvariants(store, map[string]string{"turn_1": blockID, "turn_lost": blockID})
+	if err != nil || len(violations) != 2 {
+		t.Errorf("stressInvariants() with a lost turn = %v, %v, want a count and a missing-turn violation", violations, err)
+	}
+}
+
+func TestStressLatency(t *testing.T) {
+	var durations []time.Duration
+	for i := 100; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	stats := stressLatency(durations, 3, 2*time.Second)
+	if stats.Count != 100 || stats.Errors != 3 || stats.PerSec != 50 {
+		t.Errorf("stressLatency() = %+v, want 100 calls, 3 errors, 50/s", stats)
+	}
+	if stats.P50Ms != 50 || stats.P95Ms != 95 || stats.P99Ms != 99 || stats.MaxMs != 100 {
+		t.Errorf("stressLatency() percentiles = %v/%v/%v/%v, want 50/95/99/100", stats.P50Ms, stats.P95Ms, stats.P99Ms, stats.MaxMs)
+	}
+	if empty := stressLatency(nil, 0, time.Second); empty.Count != 0 || empty.P95Ms != 0 {
+		t.Errorf("stressLatency(nil) = %+v, want zeros", empty)
+	}
+}
@@ -0,0 +1,61 @@
+// ABOUTME: Corrects stored turns and annotates them
+// ABOUTME: Edited text drops the facts extracted from the old text and queues re-extraction and re-embedding
+package core
+
+import (
+	"fmt"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// EditedTurn is a turn as saved by EditTurn and the follow-up work it caused
+type EditedTurn struct {
+	BlockID      string
+	Turn         *models.Turn
+	FactsDropped int64         // Facts the FactScrubber extracted from the old user message
+	Jobs         []*models.Job // Re-embedding and fact re-extraction, when the text changed
+}
+
+// EditTurn applies edit to a stored turn. When the user message changes, the facts the
+// FactScrubber extracted from it are deleted and extraction is queued again; when
+// either text changes, the turn is queued to be re-embedded. Annotations alone queue
+// nothing, and neither does a nil queue; jobs the queue has no handler for wait for a
+// process that has one.
+func EditTurn(store *storage.Storage, queue *JobQueue, turnID string, edit models.TurnEdit) (*EditedTurn, error) {
+	_, before, err := store.GetTurn(turnID)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil {
+		return nil, fmt.Errorf("turn %s not found", turnID)
+	}
+
+	blockID, turn, err := store.UpdateTurn(turnID, edit)
+	if err != nil {
+		return nil, err
+	}
+	result := &EditedTurn{BlockID: blockID, Turn: turn}
+
+	var kinds []models.JobKind
+	if turn.UserMessage != before.UserMessage {
+		if result.FactsDropped, err = store.DeleteTurnFacts(turnID, FactScrubberAgent); err != nil {
+			return nil, err
+		}
+		kinds = append(kinds, models.JobExtractFacts)
+	}
+	if turn.UserMessage != before.UserMessage || turn.AIResponse != before.AIResponse {
+		kinds = append(kinds, models.JobEmbedTurn)
+	}
+	if queue == nil {
+		return result, nil
+	}
+	for _, kind := range kinds {
+		job, err := queue.Enqueue(kind, models.TurnJob{TurnID: turnID, BlockID: blockID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to queue %s: %w", kind, err)
+		}
+		result.Jobs = append(result.Jobs, job)
+	}
+	return result, nil
+}
@@ -0,0 +1,70 @@
+// ABOUTME: Tests for editing stored turns
+// ABOUTME: Verifies stale extracted facts are dropped and re-embedding and extraction are queued
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+func TestEditTurn(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_edit", Timestamp: time.Now(), UserMessage: "I live in Porto", AIResponse: "Nice"})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_edit", BlockID: blockID, TurnID: "turn_edit", Key: "city", Value: "Porto", Confidence: 1, ExtractedBy: FactScrubberAgent}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+	queue := NewJobQueue(store)
+
+	kinds := func(jobs []*models.Job) []models.JobKind {
+		result := []models.JobKind{}
+		for _, job := range jobs {
+			result = append(result, job.Kind)
+		}
+		return result
+	}
+
+	// A note queues nothing
+	edited, err := EditTurn(store, queue, "turn_edit", models.TurnEdit{Annotation: "this was wrong"})
+	if err != nil || len(edited.Jobs) != 0 || edited.FactsDropped != 0 {
+		t.Fatalf("EditTurn(annotation) = %+v, %v; want no jobs", edited, err)
+	}
+
+	response := "Lovely"
+	edited, err = EditTurn(store, queue, "turn_edit", models.TurnEdit{AIResponse: &response})
+	if err != nil {
+		t.Fatalf("EditTurn(ai_response) error = %v", err)
+	}
+	if got := kinds(edited.Jobs); len(got) != 1 || got[0] != models.JobEmbedTurn || edited.FactsDropped != 0 {
+		t.Errorf("EditTurn(ai_response) queued %v, dropped %d; want only re-embedding", got, edited.FactsDropped)
+	}
+
+	message := "I live in Lisbon"
+	edited, err = EditTurn(store, queue, "turn_edit", models.TurnEdit{UserMessage: &message})
+	if err != nil {
+		t.Fatalf("EditTurn(user_message) error = %v", err)
+	}
+	if got := kinds(edited.Jobs); len(got) != 2 || got[0] != models.JobExtractFacts || got[1] != models.JobEmbedTurn {
+		t.Errorf("EditTurn(user_message) queued %v, want fact extraction and re-embedding", got)
+	}
+	if edited.FactsDropped != 1 {
+		t.Errorf("FactsDropped = %d, want 1", edited.FactsDropped)
+	}
+	if fact, err := store.GetFactByKey("city"); err != nil || fact != nil {
+		t.Errorf("GetFactByKey(city) = %+v, %v; want the stale fact gone", fact, err)
+	}
+
+	if _, err := EditTurn(store, nil, "turn_missing", models.TurnEdit{Annotation: "x"}); err == nil {
+		t.Error("EditTurn() of a missing turn succeeded, want an error")
+	}
+}
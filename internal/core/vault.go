@@ -0,0 +1,94 @@
+// ABOUTME: VaultSyncer keeps a Markdown vault in step with memory while a server or 'memory vault --watch' runs
+// ABOUTME: Checks the database's change version each interval and syncs only after something was written
+package core
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// DefaultVaultInterval is how often a running vault sync checks the database for changes
+const DefaultVaultInterval = 10 * time.Second
+
+// VaultSyncer syncs a Markdown vault whenever the database has changed. Checking is
+// cheap, so intervals can be short; unchanged notes are never rewritten.
+type VaultSyncer struct {
+	store *storage.Storage
+	dir   string
+	opts  storage.ExportOptions
+	logf  func(format string, args ...any)
+
+	mu      sync.Mutex
+	version uint64
+	synced  bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// NewVaultSyncer creates a syncer for the vault in dir with the data opts select
+func NewVaultSyncer(store *storage.Storage, dir string, opts storage.ExportOptions) *VaultSyncer {
+	return &VaultSyncer{
+		store: store,
+		dir:   dir,
+		opts:  opts,
+		logf:  log.Printf,
+		stop:  make(chan struct{}),
+	}
+}
+
+// SyncIfChanged syncs the vault unless the database is unchanged since the last sync,
+// returning nil when it skipped. A write in flight postpones the sync to the next call.
+func (v *VaultSyncer) SyncIfChanged() (*storage.VaultSync, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	version, ok := v.store.ChangeVersion()
+	if !ok || (v.synced && version == v.version) {
+		return nil, nil
+	}
+	result, err := v.store.SyncVault(v.dir, v.opts)
+	if err != nil {
+		return nil, err
+	}
+	v.version, v.synced = version, true
+	return result, nil
+}
+
+// Start syncs now and then every interval until Stop, logging what changed
+func (v *VaultSyncer) Start(interval time.Duration) {
+	v.wg.Add(1)
+	go func() {
+		defer v.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			v.log(v.SyncIfChanged())
+			select {
+			case <-v.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// log reports a background sync that changed the vault
+func (v *VaultSyncer) log(result *storage.VaultSync, err error) {
+	if err != nil {
+		v.logf("[Vault] %v", err)
+		return
+	}
+	if result != nil && result.Changed() {
+		v.logf("[Vault] wrote %d and removed %d notes in %s", len(result.Written), len(result.Removed), v.dir)
+	}
+}
+
+// Stop ends the sync loop, waiting for a running sync to finish
+func (v *VaultSyncer) Stop() {
+	v.once.Do(func() { close(v.stop) })
+	v.wg.Wait()
+}
@@ -0,0 +1,39 @@
+// ABOUTME: Tests for the VaultSyncer
+// ABOUTME: Verifies it syncs once per change and skips while the database is unchanged
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+func TestVaultSyncer_SyncIfChanged(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_vault_a", Timestamp: time.Now(), UserMessage: "first"}); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	syncer := NewVaultSyncer(store, t.TempDir(), storage.ExportOptions{})
+	result, err := syncer.SyncIfChanged()
+	if err != nil || result == nil || len(result.Written) != 2 {
+		t.Fatalf("first SyncIfChanged() = %+v, %v; want the topic note and facts index written", result, err)
+	}
+	if result, err := syncer.SyncIfChanged(); err != nil || result != nil {
+		t.Errorf("SyncIfChanged() with no writes = %+v, %v; want it skipped", result, err)
+	}
+
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_vault_b", Timestamp: time.Now(), UserMessage: "second"}); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if result, err := syncer.SyncIfChanged(); err != nil || result == nil || !result.Changed() {
+		t.Errorf("SyncIfChanged() after a write = %+v, %v; want a sync", result, err)
+	}
+}
@@ -0,0 +1,100 @@
+// ABOUTME: Tests for the cron expression parser
+// ABOUTME: Verifies fields, ranges, lists, steps, shortcuts, and the next firing time
+
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	start := time.Date(2026, 10, 16, 14, 7, 30, 0, time.UTC) // a Friday
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 16, 14, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 16, 14, 15, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)},
+		{"30 9,17 * * *", time.Date(2026, 10, 16, 17, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1 * 6", time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)}, // 1st of the month or a Saturday
+		{"@hourly", time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+		}
+		if got := s.Next(start); !got.Equal(tt.want) {
+			t.Errorf("Parse(%q).Next() = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestNextNever(t *testing.T) {
+	s, err := Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next() = %v, want the zero time for a date that never exists", got)
+	}
+}
+
+func TestNextUsesZone(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	s, err := Parse("0 3 * * *")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	got := s.Next(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC).In(chicago))
+	if want := time.Date(2026, 10, 17, 3, 0, 0, 0, chicago); !got.Equal(want) {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"* * * *", "must have 5 fields"},
+		{"60 * * * *", "minute 60 is out of range 0-59"},
+		{"* 24 * * *", "hour 24 is out of range"},
+		{"* * 0 * *", "day of month 0 is out of range"},
+		{"* * * 13 *", "month 13 is out of range"},
+		{"* * * * 8", "day of week 8 is out of range"},
+		{"*/0 * * * *", "step \"0\" must be a positive number"},
+		{"5-1 * * * *", "runs backwards"},
+		{"a * * * *", "is not a number"},
+		{"@sometimes", "unknown cron shortcut"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want it to mention %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	s, err := Parse(" @daily ")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if s.String() != "@daily" {
+		t.Errorf("String() = %q, want %q", s.String(), "@daily")
+	}
+}
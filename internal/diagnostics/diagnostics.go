@@ -0,0 +1,125 @@
+// ABOUTME: Debug HTTP listener exposing pprof profiles and expvar runtime variables
+// ABOUTME: Off unless debug_addr is set, for diagnosing leaks and goroutine growth in long-running servers
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/redact"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+var (
+	publishOnce sync.Once
+	published   atomic.Pointer[storage.Storage] // Storage reported under "memory" in /debug/vars
+)
+
+// publish registers the expvar variables once per process; later servers only swap
+// the storage they report on, since expvar panics on duplicate names
+func publish(store *storage.Storage) {
+	published.Store(store)
+	publishOnce.Do(func() {
+		expvar.Publish("goroutines", expvar.Func(func() any { return runtime.NumGoroutine() }))
+		expvar.Publish("memory", expvar.Func(func() any {
+			store := published.Load()
+			if store == nil {
+				return nil
+			}
+			return storageVars(store)
+		}))
+	})
+}
+
+// storageVars reports the job queue depth and cache counters; errors are reported in place
+// of the value so one failing query does not hide the rest
+func storageVars(store *storage.Storage) map[string]any {
+	vars := make(map[string]any)
+	if counts, err := store.JobCounts(); err != nil {
+		vars["jobs"] = err.Error()
+	} else {
+		vars["jobs"] = counts
+	}
+	if queue, err := store.JobQueueStats(); err != nil {
+		vars["queue"] = err.Error()
+	} else {
+		vars["queue"] = queue
+	}
+	if caches, err := store.CacheStats(); err != nil {
+		vars["caches"] = err.Error()
+	} else {
+		vars["caches"] = caches
+	}
+	return vars
+}
+
+// Handler serves pprof under /debug/pprof/ and expvar under /debug/vars
+func Handler(store *storage.Storage) http.Handler {
+	publish(store)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.Handle("/debug/pprof/cmdline", redacted(http.HandlerFunc(pprof.Cmdline)))
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", redacted(expvar.Handler()))
+	return mux
+}
+
+// redactingWriter holds a response body until the handler is done
+type redactingWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	return w.body.Write(p)
+}
+
+// redacted masks credentials in a text handler's response, such as a key passed
+// on the command line. Binary profiles cannot be masked and are served as they are.
+func redacted(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &redactingWriter{ResponseWriter: w}
+		h.ServeHTTP(rw, r)
+		_, _ = io.WriteString(w, redact.String(rw.body.String()))
+	})
+}
+
+// Serve listens on addr and serves Handler in the background. Profiles reveal memory
+// contents, so it warns when addr is not a loopback address. The returned function
+// stops the listener.
+func Serve(addr string, store *storage.Storage) (func(), error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for debugging: %w", err)
+	}
+	if ip, ok := ln.Addr().(*net.TCPAddr); ok && !ip.IP.IsLoopback() {
+		log.Printf("Warning: debug listener on %s is reachable from other machines; profiles can expose memory contents", ln.Addr())
+	}
+
+	// No write timeout: CPU profiles and traces stream for as long as requested
+	server := &http.Server{Handler: Handler(store), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[Debug] %v", err)
+		}
+	}()
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}, nil
+}
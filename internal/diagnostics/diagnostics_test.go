@@ -0,0 +1,103 @@
+// ABOUTME: Tests for the debug listener
+// ABOUTME: Verifies pprof and expvar are served and that starting twice does not panic
+
+package diagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+func TestHandler(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	if _, err := store.EnqueueJob(models.JobEmbedTurn, models.TurnJob{TurnID: "turn_1"}); err != nil {
+		t.Fatalf("EnqueueJob() error = %v", err)
+	}
+
+	// A second handler, as a restarted server would create, reuses the published vars
+	_ = Handler(store)
+	handler := Handler(store)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d, want 200", path, rec.Code)
+		}
+		return rec
+	}
+
+	var vars struct {
+		Goroutines int `json:"goroutines"`
+		Memory     struct {
+			Jobs  map[string]int `json:"jobs"`
+			Queue struct {
+				Pending map[string]int `json:"pending"`
+			} `json:"queue"`
+		} `json:"memory"`
+		MemStats map[string]any `json:"memstats"`
+	}
+	if err := json.Unmarshal(get("/debug/vars").Body.Bytes(), &vars); err != nil {
+		t.Fatalf("/debug/vars is not JSON: %v", err)
+	}
+	if vars.Goroutines == 0 || vars.MemStats == nil {
+		t.Errorf("/debug/vars = %+v, want goroutines and memstats", vars)
+	}
+	if vars.Memory.Jobs[string(models.JobPending)] != 1 {
+		t.Errorf("memory.jobs = %v, want the pending job", vars.Memory.Jobs)
+	}
+	if vars.Memory.Queue.Pending[string(models.JobEmbedTurn)] != 1 {
+		t.Errorf("memory.queue = %+v, want the pending embedding job", vars.Memory.Queue)
+	}
+
+	if body := get("/debug/pprof/").Body.String(); !strings.Contains(body, "goroutine") {
+		t.Errorf("/debug/pprof/ does not list the goroutine profile")
+	}
+	get("/debug/pprof/goroutine?debug=1")
+}
+
+func TestRedacted(t *testing.T) {
+	handler := redacted(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("memory mcp --openai-api-key="))
+		_, _ = w.Write([]byte("sk-" + strings.Repeat("k", 40) + "\n"))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/cmdline", nil))
+	if got := rec.Body.String(); got != "memory mcp --openai-api-key=********\n" {
+		t.Errorf("body = %q, want the key masked across writes", got)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/plain" {
+		t.Errorf("Content-Type = %q, want the handler's header kept", got)
+	}
+}
+
+func TestServe(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	stop, err := Serve("127.0.0.1:0", store)
+	if err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	stop()
+
+	if _, err := Serve("127.0.0.1", store); err == nil {
+		t.Error("Serve() without a port succeeded, want an error")
+	}
+}
@@ -0,0 +1,159 @@
+// ABOUTME: Webhook delivery of memory events from storage's change feed, queued as jobs so failures are retried with backoff
+// ABOUTME: Each POST carries the event as JSON, signed with HMAC-SHA256 when a secret is configured
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/redact"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+const (
+	webhookTimeout = 10 * time.Second // How long one delivery attempt may take
+
+	EventHeader     = "X-Memory-Event"     // The event type
+	DeliveryHeader  = "X-Memory-Delivery"  // The event ID, the same on every retry
+	SignatureHeader = "X-Memory-Signature" // "sha256=" and the hex HMAC of the body, when a secret is set
+)
+
+// JobEnqueuer queues background jobs; *storage.Storage implements it
+type JobEnqueuer interface {
+	EnqueueJob(kind models.JobKind, payload any) (*models.Job, error)
+}
+
+// Webhooks posts memory events to configured URLs
+type Webhooks struct {
+	urls   []string
+	types  []models.EventType // empty means every type
+	secret string
+	client *http.Client
+}
+
+// NewWebhooks creates webhooks for urls that receive events of types (all types when
+// empty), signed with secret when it is set
+func NewWebhooks(urls []string, types []models.EventType, secret string) *Webhooks {
+	return &Webhooks{
+		urls:   urls,
+		types:  types,
+		secret: secret,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Wants reports whether events of type t are delivered
+func (w *Webhooks) Wants(t models.EventType) bool {
+	return len(w.types) == 0 || slices.Contains(w.types, t)
+}
+
+// Attach subscribes the webhooks to store's change feed, queueing a delivery job in
+// store's job queue for each wanted event. The subscription is lossless, and closing
+// store waits for the last events to be queued.
+func (w *Webhooks) Attach(store *storage.Storage) {
+	sub := store.Subscribe(storage.SubscribeOptions{Types: w.types, Lossless: true})
+	enqueue := w.Enqueue(store)
+	go func() {
+		defer sub.Close()
+		for event := range sub.C {
+			enqueue(event)
+		}
+	}()
+}
+
+// Enqueue returns a function that queues a delivery job per URL for a wanted event.
+// Fact values are masked first, so credentials that slipped into a plain fact are not
+// sent; secret facts never carry a value.
+func (w *Webhooks) Enqueue(jobs JobEnqueuer) func(models.Event) {
+	return func(event models.Event) {
+		if !w.Wants(event.Type) {
+			return
+		}
+		event = Mask(event)
+		for _, url := range w.urls {
+			if _, err := jobs.EnqueueJob(models.JobDeliverWebhook, models.WebhookJob{URL: url, Event: event}); err != nil {
+				log.Printf("[Webhooks] failed to queue %s for %s: %v", event.Type, url, err)
+			}
+		}
+	}
+}
+
+// Mask returns event with credentials in its fact values masked, for sending it
+// anywhere outside memory; secret facts never carry a value
+func Mask(event models.Event) models.Event {
+	event.Fact, event.Previous = maskFact(event.Fact), maskFact(event.Previous)
+	return event
+}
+
+// maskFact returns a copy of f with credentials in its value masked
+func maskFact(f *models.EventFact) *models.EventFact {
+	if f == nil {
+		return nil
+	}
+	masked := *f
+	masked.Value = redact.String(f.Value)
+	return &masked
+}
+
+// Deliver is the job handler for deliver_webhook jobs. A URL removed from the
+// configuration since the job was queued is skipped.
+func (w *Webhooks) Deliver(ctx context.Context, job *models.Job) error {
+	var payload models.WebhookJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	if !slices.Contains(w.urls, payload.URL) {
+		log.Printf("[Webhooks] skipping %s %s: %s is no longer configured", payload.Event.Type, payload.Event.ID, payload.URL)
+		return nil
+	}
+	return w.Send(ctx, payload.URL, payload.Event)
+}
+
+// Send posts event to url; any response other than 2xx is an error
+func (w *Webhooks) Send(ctx context.Context, url string, event models.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "memory-webhooks/1")
+	req.Header.Set(EventHeader, string(event.Type))
+	req.Header.Set(DeliveryHeader, event.ID)
+	if w.secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post %s to %s: %w", event.Type, url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s answered %s", url, resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature header value for body: "sha256=" and the hex HMAC-SHA256
+// of body keyed with secret. Receivers recompute it to check a delivery came from here.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
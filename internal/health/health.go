@@ -0,0 +1,242 @@
+// ABOUTME: Health checks for MCP servers: storage reachability, LLM calls, the job queue, and pending enrichment
+// ABOUTME: Backs the health MCP tool and the /healthz endpoint supervisors poll
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/llm"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// Overall health, worst first
+const (
+	StatusDown     = "down"     // Storage is unreachable; the server cannot do its job
+	StatusDegraded = "degraded" // The server works, but LLM calls are failing or jobs are backing up
+	StatusOK       = "ok"
+)
+
+const (
+	pingTimeout   = 5 * time.Second  // How long the storage check may take
+	jobBacklogAge = 15 * time.Minute // How long a due job may wait before the queue counts as backed up
+)
+
+// Store is the storage a Checker probes; *storage.Storage implements it
+type Store interface {
+	Ping(ctx context.Context) error
+	ReadOnly() bool
+	JobCounts() (map[models.JobStatus]int, error)
+	JobQueueStats() (storage.JobQueueStats, error)
+	OldestDueJob() (time.Time, error)
+	PendingEnrichmentCount() (int, error)
+}
+
+// LLM reports how recent API calls went; *llm.OpenAIClient implements it
+type LLM interface {
+	Status() llm.CallStatus
+}
+
+// Report is the result of a health check
+type Report struct {
+	Status    string        `json:"status"`
+	Problems  []string      `json:"problems,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Storage   StorageHealth `json:"storage"`
+	LLM       LLMHealth     `json:"llm"`
+	Jobs      JobsHealth    `json:"jobs"`
+	// Turns stored while the LLM was missing or failing, still waiting on metadata,
+	// embeddings, or facts
+	PendingEnrichment int `json:"pending_enrichment"`
+}
+
+// StorageHealth says whether the database answered
+type StorageHealth struct {
+	Reachable bool    `json:"reachable"`
+	ReadOnly  bool    `json:"read_only"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// LLMHealth says when this process's LLM calls last succeeded and failed
+type LLMHealth struct {
+	Configured  bool       `json:"configured"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastFailure *time.Time `json:"last_failure,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// JobsHealth is the depth of the background job queue
+type JobsHealth struct {
+	Pending         int                    `json:"pending"`
+	Running         int                    `json:"running"`
+	Failed          int                    `json:"failed"`
+	OldestDue       *time.Time             `json:"oldest_due,omitempty"`      // When the longest-waiting due job became due
+	PendingByKind   map[models.JobKind]int `json:"pending_by_kind,omitempty"` // Pending jobs of each kind
+	ProfilesDropped int64                  `json:"profile_messages_dropped"`  // Messages this server dropped because the profile queue was full
+}
+
+// Checker builds health reports
+type Checker struct {
+	store Store
+	llm   LLM // nil when no OpenAI key is configured
+	now   func() time.Time
+}
+
+// NewChecker creates a Checker over store and client; client may be nil
+func NewChecker(store Store, client *llm.OpenAIClient) *Checker {
+	c := &Checker{store: store, now: time.Now}
+	if client != nil {
+		c.llm = client
+	}
+	return c
+}
+
+// Check probes storage, the LLM, and the job queue. The report is down when storage
+// cannot be reached and degraded when the last LLM call failed, due jobs have waited
+// longer than 15 minutes, or turns wait on enrichment with no LLM configured to do it.
+func (c *Checker) Check(ctx context.Context) Report {
+	now := c.now()
+	report := Report{Status: StatusOK, CheckedAt: now.UTC()}
+
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	start := time.Now()
+	err := c.store.Ping(pingCtx)
+	report.Storage.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	report.Storage.ReadOnly = c.store.ReadOnly()
+	if err != nil {
+		report.Storage.Error = err.Error()
+		report.problem(StatusDown, "storage is unreachable: %v", err)
+	} else {
+		report.Storage.Reachable = true
+	}
+
+	if c.llm != nil {
+		status := c.llm.Status()
+		report.LLM = LLMHealth{
+			Configured:  true,
+			LastSuccess: utcOrNil(status.LastSuccess),
+			LastFailure: utcOrNil(status.LastFailure),
+			LastError:   status.LastError,
+		}
+		if status.LastFailure.After(status.LastSuccess) {
+			report.problem(StatusDegraded, "last LLM call failed: %s", status.LastError)
+		}
+	}
+
+	if report.Storage.Reachable {
+		c.checkJobs(&report, now)
+		c.checkEnrichment(&report)
+	}
+	return report
+}
+
+// checkEnrichment counts turns waiting on enrichment. With an LLM configured they are
+// backfilled as their jobs run; without one they wait until a key is set.
+func (c *Checker) checkEnrichment(report *Report) {
+	pending, err := c.store.PendingEnrichmentCount()
+	if err != nil {
+		report.problem(StatusDegraded, "cannot count turns pending enrichment: %v", err)
+		return
+	}
+	report.PendingEnrichment = pending
+	if pending > 0 && c.llm == nil {
+		report.problem(StatusDegraded, "%d turns are waiting on enrichment and no OpenAI key is configured", pending)
+	}
+}
+
+// checkJobs fills in the queue depth, marking the report degraded when jobs back up
+func (c *Checker) checkJobs(report *Report, now time.Time) {
+	counts, err := c.store.JobCounts()
+	if err != nil {
+		report.problem(StatusDegraded, "cannot read the job queue: %v", err)
+		return
+	}
+	report.Jobs.Pending = counts[models.JobPending]
+	report.Jobs.Running = counts[models.JobRunning]
+	report.Jobs.Failed = counts[models.JobFailed]
+
+	queue, err := c.store.JobQueueStats()
+	if err != nil {
+		report.problem(StatusDegraded, "cannot read the job queue: %v", err)
+		return
+	}
+	report.Jobs.PendingByKind = queue.Pending
+	report.Jobs.ProfilesDropped = queue.ProfilesDropped
+
+	oldest, err := c.store.OldestDueJob()
+	if err != nil {
+		report.problem(StatusDegraded, "cannot read the job queue: %v", err)
+		return
+	}
+	report.Jobs.OldestDue = utcOrNil(oldest)
+	if wait := now.Sub(oldest); !oldest.IsZero() && wait > jobBacklogAge {
+		report.problem(StatusDegraded, "job queue is backed up: oldest due job has waited %s", wait.Round(time.Second))
+	}
+}
+
+// problem records a problem and lowers the report's status to status if it is worse
+func (r *Report) problem(status, format string, args ...any) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+	if status == StatusDown || r.Status == StatusOK {
+		r.Status = status
+	}
+}
+
+// utcOrNil returns t in UTC, or nil for the zero time
+func utcOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// ServeHTTP writes a health report as JSON. Supervisors can key off the status code
+// alone: 503 when the server is down, 200 otherwise (including degraded).
+func (c *Checker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	report := c.Check(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == StatusDown {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if r.Method == http.MethodGet {
+		_ = json.NewEncoder(w).Encode(report)
+	}
+}
+
+// Serve listens on addr and serves /healthz from checker in the background. The
+// returned function stops the listener.
+func Serve(addr string, checker *Checker) (func(), error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for health checks: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", checker)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[Health] %v", err)
+		}
+	}()
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}, nil
+}
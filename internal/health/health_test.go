@@ -0,0 +1,189 @@
+// ABOUTME: Tests for health checks
+// ABOUTME: Verifies ok, degraded, and down reports and the /healthz status codes
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/llm"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+type fakeStore struct {
+	pingErr   error
+	counts    map[models.JobStatus]int
+	oldestDue time.Time
+	pending   int
+}
+
+func (s *fakeStore) Ping(ctx context.Context) error { return s.pingErr }
+func (s *fakeStore) ReadOnly() bool                 { return false }
+func (s *fakeStore) JobCounts() (map[models.JobStatus]int, error) {
+	return s.counts, nil
+}
+func (s *fakeStore) JobQueueStats() (storage.JobQueueStats, error) {
+	return storage.JobQueueStats{}, nil
+}
+func (s *fakeStore) OldestDueJob() (time.Time, error)     { return s.oldestDue, nil }
+func (s *fakeStore) PendingEnrichmentCount() (int, error) { return s.pending, nil }
+
+type fakeLLM struct{ status llm.CallStatus }
+
+func (l fakeLLM) Status() llm.CallStatus { return l.status }
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2025, 12, 6, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		store   *fakeStore
+		llm     LLM
+		want    string
+		problem string
+	}{
+		{
+			name:  "healthy",
+			store: &fakeStore{counts: map[models.JobStatus]int{models.JobPending: 2}, oldestDue: now.Add(-time.Minute)},
+			llm:   fakeLLM{llm.CallStatus{LastSuccess: now.Add(-time.Minute), LastFailure: now.Add(-time.Hour), LastError: "timeout"}},
+			want:  StatusOK,
+		},
+		{
+			name:  "no LLM configured",
+			store: &fakeStore{},
+			want:  StatusOK,
+		},
+		{
+			name:    "last LLM call failed",
+			store:   &fakeStore{},
+			llm:     fakeLLM{llm.CallStatus{LastSuccess: now.Add(-time.Hour), LastFailure: now.Add(-time.Minute), LastError: "401 invalid api key"}},
+			want:    StatusDegraded,
+			problem: "invalid api key",
+		},
+		{
+			name:    "jobs backed up",
+			store:   &fakeStore{counts: map[models.JobStatus]int{models.JobPending: 40}, oldestDue: now.Add(-time.Hour)},
+			want:    StatusDegraded,
+			problem: "backed up",
+		},
+		{
+			name:  "enrichment waiting on jobs",
+			store: &fakeStore{pending: 3},
+			llm:   fakeLLM{llm.CallStatus{LastSuccess: now}},
+			want:  StatusOK,
+		},
+		{
+			name:    "enrichment with no LLM configured",
+			store:   &fakeStore{pending: 3},
+			want:    StatusDegraded,
+			problem: "3 turns are waiting on enrichment",
+		},
+		{
+			name:    "storage unreachable",
+			store:   &fakeStore{pingErr: errors.New("disk I/O error")},
+			llm:     fakeLLM{llm.CallStatus{LastFailure: now}},
+			want:    StatusDown,
+			problem: "disk I/O error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &Checker{store: tt.store, llm: tt.llm, now: func() time.Time { return now }}
+			report := checker.Check(context.Background())
+			if report.Status != tt.want {
+				t.Errorf("Status = %q, want %q (problems: %v)", report.Status, tt.want, report.Problems)
+			}
+			if tt.problem != "" && !strings.Contains(strings.Join(report.Problems, "\n"), tt.problem) {
+				t.Errorf("Problems = %v, want one mentioning %q", report.Problems, tt.problem)
+			}
+			if tt.problem == "" && len(report.Problems) != 0 {
+				t.Errorf("Problems = %v, want none", report.Problems)
+			}
+			if report.LLM.Configured != (tt.llm != nil) {
+				t.Errorf("LLM.Configured = %v, want %v", report.LLM.Configured, tt.llm != nil)
+			}
+		})
+	}
+}
+
+func TestCheck_Storage(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if _, err := store.EnqueueJob(models.JobEmbedTurn, models.TurnJob{TurnID: "turn_1"}); err != nil {
+		t.Fatalf("EnqueueJob() error = %v", err)
+	}
+
+	report := NewChecker(store, nil).Check(context.Background())
+	if report.Status != StatusOK || !report.Storage.Reachable {
+		t.Errorf("report = %+v, want ok with storage reachable", report)
+	}
+	if report.Jobs.Pending != 1 || report.Jobs.OldestDue == nil || report.Jobs.PendingByKind[models.JobEmbedTurn] != 1 {
+		t.Errorf("Jobs = %+v, want the pending job", report.Jobs)
+	}
+	if report.LLM.Configured {
+		t.Error("LLM.Configured = true without a client")
+	}
+
+	_ = store.Close()
+	if report := NewChecker(store, nil).Check(context.Background()); report.Status != StatusDown {
+		t.Errorf("Status after Close = %q, want down", report.Status)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		store *fakeStore
+		code  int
+	}{
+		{"ok", &fakeStore{}, http.StatusOK},
+		{"down", &fakeStore{pingErr: errors.New("database is locked")}, http.StatusServiceUnavailable},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &Checker{store: tt.store, now: time.Now}
+			rec := httptest.NewRecorder()
+			checker.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			if rec.Code != tt.code {
+				t.Errorf("status code = %d, want %d", rec.Code, tt.code)
+			}
+			var report Report
+			if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+				t.Fatalf("body %q is not a report: %v", rec.Body.String(), err)
+			}
+			if report.Status != tt.name {
+				t.Errorf("report status = %q, want %q", report.Status, tt.name)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	(&Checker{store: &fakeStore{}, now: time.Now}).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/healthz", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status code = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestServe(t *testing.T) {
+	stop, err := Serve("127.0.0.1:0", &Checker{store: &fakeStore{}, now: time.Now})
+	if err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	stop()
+
+	if _, err := Serve("not an address", &Checker{store: &fakeStore{}, now: time.Now}); err == nil {
+		t.Error("Serve() with a bad address succeeded, want an error")
+	}
+}
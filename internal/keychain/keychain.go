@@ -0,0 +1,129 @@
+// ABOUTME: API key storage in the OS keychain (macOS Keychain, Linux Secret Service)
+// ABOUTME: Shells out to security(1) or secret-tool(1) so no cgo or extra dependencies are needed
+package keychain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// service is the keychain service name every memory secret is stored under
+const service = "memory"
+
+var (
+	// ErrNotFound means the keychain has no secret for the account
+	ErrNotFound = errors.New("no key stored in keychain")
+	// ErrUnsupported means neither security(1) nor secret-tool(1) is available
+	ErrUnsupported = errors.New("no supported keychain (needs macOS security or Linux secret-tool)")
+)
+
+// runner executes a command with stdin and returns its stdout and exit code; tests replace it
+var runner = func(stdin, name string, args ...string) (string, int, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return stdout.String(), exitErr.ExitCode(), fmt.Errorf("%s: %s", name, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), 0, err
+}
+
+// lookPath finds a keychain tool; tests replace it
+var lookPath = exec.LookPath
+
+// backend returns the keychain tool for this OS
+func backend() (string, error) {
+	var tool string
+	switch runtime.GOOS {
+	case "darwin":
+		tool = "security"
+	case "linux", "freebsd", "openbsd":
+		tool = "secret-tool"
+	default:
+		return "", ErrUnsupported
+	}
+	if _, err := lookPath(tool); err != nil {
+		return "", ErrUnsupported
+	}
+	return tool, nil
+}
+
+// Get returns the secret stored for account
+func Get(account string) (string, error) {
+	tool, err := backend()
+	if err != nil {
+		return "", err
+	}
+
+	var out string
+	var code int
+	if tool == "security" {
+		out, code, err = runner("", tool, "find-generic-password", "-s", service, "-a", account, "-w")
+		if code == 44 { // errSecItemNotFound
+			return "", ErrNotFound
+		}
+	} else {
+		out, code, err = runner("", tool, "lookup", "service", service, "account", account)
+		if code == 1 && strings.TrimSpace(out) == "" {
+			return "", ErrNotFound
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read keychain: %w", err)
+	}
+
+	secret := strings.TrimRight(out, "\r\n")
+	if secret == "" {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+// Set stores secret for account, replacing any existing one
+func Set(account, secret string) error {
+	tool, err := backend()
+	if err != nil {
+		return err
+	}
+
+	if tool == "security" {
+		_, _, err = runner("", tool, "add-generic-password", "-U", "-s", service, "-a", account, "-l", service+": "+account, "-w", secret)
+	} else {
+		_, _, err = runner(secret, tool, "store", "--label", service+": "+account, "service", service, "account", account)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write keychain: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the secret for account; deleting a missing secret is not an error
+func Delete(account string) error {
+	tool, err := backend()
+	if err != nil {
+		return err
+	}
+
+	var code int
+	if tool == "security" {
+		_, code, err = runner("", tool, "delete-generic-password", "-s", service, "-a", account)
+		if code == 44 {
+			return nil
+		}
+	} else {
+		_, _, err = runner("", tool, "clear", "service", service, "account", account)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete keychain entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// ABOUTME: Tests for OS keychain access
+// ABOUTME: Fakes security(1) and secret-tool(1) to verify get, set, and delete round trips
+
+package keychain
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+)
+
+// fakeKeychain stands in for security(1) and secret-tool(1)
+func fakeKeychain(t *testing.T) {
+	t.Helper()
+	store := map[string]string{}
+
+	origRunner, origLookPath := runner, lookPath
+	t.Cleanup(func() { runner, lookPath = origRunner, origLookPath })
+
+	lookPath = func(string) (string, error) { return "/usr/bin/fake", nil }
+	runner = func(stdin, name string, args ...string) (string, int, error) {
+		// The account always follows "-a" (security) or "account" (secret-tool)
+		var account, secret string
+		for i := 0; i < len(args)-1; i++ {
+			switch args[i] {
+			case "-a", "account":
+				account = args[i+1]
+			case "-w":
+				secret = args[i+1]
+			}
+		}
+
+		switch args[0] {
+		case "find-generic-password", "lookup":
+			if v, ok := store[account]; ok {
+				return v + "\n", 0, nil
+			}
+			if name == "security" {
+				return "", 44, errors.New("not found")
+			}
+			return "", 1, errors.New("")
+		case "add-generic-password":
+			store[account] = secret
+		case "store":
+			store[account] = stdin
+		case "delete-generic-password", "clear":
+			if _, ok := store[account]; !ok && name == "security" {
+				return "", 44, errors.New("not found")
+			}
+			delete(store, account)
+		}
+		return "", 0, nil
+	}
+}
+
+func TestKeychain_RoundTrip(t *testing.T) {
+	switch runtime.GOOS {
+	case "darwin", "linux", "freebsd", "openbsd":
+	default:
+		t.Skip("no keychain backend on " + runtime.GOOS)
+	}
+	fakeKeychain(t)
+
+	if _, err := Get("openai"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() before Set error = %v, want ErrNotFound", err)
+	}
+	if err := Set("openai", "sk-test"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	got, err := Get("openai")
+	if err != nil || got != "sk-test" {
+		t.Fatalf("Get() = %q, %v; want sk-test", got, err)
+	}
+	if err := Delete("openai"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := Delete("openai"); err != nil {
+		t.Errorf("Delete() of missing key error = %v, want nil", err)
+	}
+	if _, err := Get("openai"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestKeychain_Unsupported(t *testing.T) {
+	origLookPath := lookPath
+	t.Cleanup(func() { lookPath = origLookPath })
+	lookPath = func(string) (string, error) { return "", errors.New("not found") }
+
+	if _, err := Get("openai"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Get() error = %v, want ErrUnsupported", err)
+	}
+	if err := Set("openai", "sk-test"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Set() error = %v, want ErrUnsupported", err)
+	}
+}
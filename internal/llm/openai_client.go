@@ -6,10 +6,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/harper/remember-standalone/internal/config"
 	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/tracing"
 	"github.com/harper/remember-standalone/internal/util"
 	openai "github.com/sashabaranov/go-openai"
 )
@@ -19,6 +24,8 @@ const (
 	DefaultChatModel = "gpt-4o-mini"
 	// DefaultEmbeddingModel is the default model for embeddings
 	DefaultEmbeddingModel = openai.SmallEmbedding3
+	// FactPromptVersion identifies the ExtractFacts prompt; bump it when the prompt changes
+	FactPromptVersion = "facts-2"
 )
 
 // ClientConfig holds configuration for the OpenAI client
@@ -28,6 +35,9 @@ type ClientConfig struct {
 	EmbeddingModel openai.EmbeddingModel
 	MaxRetries     int
 	RetryDelay     time.Duration
+	Proxy          string          // Explicit proxy URL; empty uses HTTPS_PROXY/NO_PROXY from the environment
+	NoProxy        string          // Hosts that bypass Proxy; empty uses NO_PROXY
+	TopicTaxonomy  models.Taxonomy // Labels ExtractMetadata maps topics onto; empty leaves topics as the model names them
 }
 
 // DefaultConfig returns the default client configuration
@@ -46,6 +56,30 @@ func DefaultConfig(apiKey string) *ClientConfig {
 	}
 }
 
+// ConfigFromSettings builds a client configuration from the loaded memory config
+func ConfigFromSettings(cfg *config.Config) *ClientConfig {
+	return &ClientConfig{
+		APIKey:         cfg.OpenAIKey,
+		ChatModel:      cfg.ChatModel,
+		EmbeddingModel: openai.EmbeddingModel(cfg.EmbeddingModel),
+		MaxRetries:     cfg.MaxRetries,
+		RetryDelay:     cfg.RetryDelay,
+		Proxy:          cfg.Proxy,
+		NoProxy:        cfg.NoProxy,
+		TopicTaxonomy:  cfg.TopicTaxonomy,
+	}
+}
+
+// UsageRecorder receives token counts for each successful API call
+type UsageRecorder interface {
+	RecordLLMUsage(operation, model string, promptTokens, completionTokens int) error
+}
+
+// RateLimiter blocks until another API request may be sent
+type RateLimiter interface {
+	Wait()
+}
+
 // OpenAIClient wraps the OpenAI API client with retry logic
 type OpenAIClient struct {
 	client         *openai.Client
@@ -53,6 +87,42 @@ type OpenAIClient struct {
 	embeddingModel openai.EmbeddingModel
 	maxRetries     int
 	retryDelay     time.Duration
+	usageRecorder  UsageRecorder
+	rateLimiter    RateLimiter
+	taxonomy       models.Taxonomy
+
+	statusMu sync.Mutex
+	status   CallStatus
+}
+
+// CallStatus records when the client's API calls last succeeded and failed
+type CallStatus struct {
+	LastSuccess time.Time // Zero until a call succeeds
+	LastFailure time.Time // Zero until a call fails after all its retries
+	LastError   string    // Error of the last failed call
+}
+
+// Status returns when API calls last succeeded and failed
+func (c *OpenAIClient) Status() CallStatus {
+	c.statusMu.Lock()
+	defer c.statusMu.Unlock()
+	return c.status
+}
+
+// succeeded records that the API answered a call
+func (c *OpenAIClient) succeeded() {
+	c.statusMu.Lock()
+	c.status.LastSuccess = time.Now()
+	c.statusMu.Unlock()
+}
+
+// failed records a call that failed after all its retries and returns err
+func (c *OpenAIClient) failed(err error) error {
+	c.statusMu.Lock()
+	c.status.LastFailure = time.Now()
+	c.status.LastError = err.Error()
+	c.statusMu.Unlock()
+	return err
 }
 
 // NewOpenAIClient creates a new OpenAI client with the given API key using default configuration
@@ -66,7 +136,15 @@ func NewOpenAIClientWithConfig(config *ClientConfig) (*OpenAIClient, error) {
 		return nil, fmt.Errorf("OpenAI API key is required")
 	}
 
-	client := openai.NewClient(config.APIKey)
+	clientConfig := openai.DefaultConfig(config.APIKey)
+	httpClient, err := newHTTPClient(config.Proxy, config.NoProxy)
+	if err != nil {
+		return nil, err
+	}
+	if httpClient != nil {
+		clientConfig.HTTPClient = httpClient
+	}
+	client := openai.NewClientWithConfig(clientConfig)
 
 	return &OpenAIClient{
 		client:         client,
@@ -74,6 +152,7 @@ func NewOpenAIClientWithConfig(config *ClientConfig) (*OpenAIClient, error) {
 		embeddingModel: config.EmbeddingModel,
 		maxRetries:     config.MaxRetries,
 		retryDelay:     config.RetryDelay,
+		taxonomy:       config.TopicTaxonomy,
 	}, nil
 }
 
@@ -82,6 +161,35 @@ func (c *OpenAIClient) GetClient() *openai.Client {
 	return c.client
 }
 
+// SetUsageRecorder sets the recorder that receives token usage for each call
+func (c *OpenAIClient) SetUsageRecorder(recorder UsageRecorder) {
+	c.usageRecorder = recorder
+}
+
+// SetRateLimiter sets a limiter consulted before every API request, including retries
+func (c *OpenAIClient) SetRateLimiter(limiter RateLimiter) {
+	c.rateLimiter = limiter
+}
+
+// waitForRate blocks on the configured rate limiter, if any
+func (c *OpenAIClient) waitForRate() {
+	if c.rateLimiter != nil {
+		c.rateLimiter.Wait()
+	}
+}
+
+// recordUsage marks the API as answering and forwards token usage to the configured
+// recorder, if any
+func (c *OpenAIClient) recordUsage(operation, model string, usage openai.Usage) {
+	c.succeeded()
+	if c.usageRecorder == nil {
+		return
+	}
+	if err := c.usageRecorder.RecordLLMUsage(operation, model, usage.PromptTokens, usage.CompletionTokens); err != nil {
+		log.Printf("[LLM] failed to record usage: %v", err)
+	}
+}
+
 // GenerateEmbedding generates a 1536-dimensional embedding vector using text-embedding-3-small
 func (c *OpenAIClient) GenerateEmbedding(text string) ([]float64, error) {
 	var lastErr error
@@ -90,6 +198,7 @@ func (c *OpenAIClient) GenerateEmbedding(text string) ([]float64, error) {
 		if attempt > 0 {
 			time.Sleep(util.CalculateBackoff(c.retryDelay, attempt))
 		}
+		c.waitForRate()
 
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 
@@ -110,6 +219,8 @@ func (c *OpenAIClient) GenerateEmbedding(text string) ([]float64, error) {
 			continue
 		}
 
+		c.recordUsage("embedding", string(c.embeddingModel), resp.Usage)
+
 		// Convert []float32 to []float64
 		embedding32 := resp.Data[0].Embedding
 		embedding64 := make([]float64, len(embedding32))
@@ -121,17 +232,137 @@ func (c *OpenAIClient) GenerateEmbedding(text string) ([]float64, error) {
 		return embedding64, nil
 	}
 
-	return nil, fmt.Errorf("failed to generate embedding after %d attempts: %w", c.maxRetries+1, lastErr)
+	return nil, c.failed(fmt.Errorf("failed to generate embedding after %d attempts: %w", c.maxRetries+1, lastErr))
+}
+
+// ChatModel returns the model used for chat completions
+func (c *OpenAIClient) ChatModel() string {
+	return c.chatModel
+}
+
+// EmbeddingModel returns the name of the model used for embeddings
+func (c *OpenAIClient) EmbeddingModel() string {
+	return string(c.embeddingModel)
+}
+
+// GenerateEmbeddings embeds several texts in a single API call, returning vectors in input order
+func (c *OpenAIClient) GenerateEmbeddings(texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	var lastErr error
+
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(util.CalculateBackoff(c.retryDelay, attempt))
+		}
+		c.waitForRate()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+
+		resp, err := c.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+			Input: texts,
+			Model: c.embeddingModel,
+		})
+		cancel()
+
+		if err != nil {
+			lastErr = fmt.Errorf("attempt %d: %w", attempt+1, err)
+			continue
+		}
+
+		if len(resp.Data) != len(texts) {
+			lastErr = fmt.Errorf("attempt %d: expected %d embeddings, got %d", attempt+1, len(texts), len(resp.Data))
+			continue
+		}
+
+		c.recordUsage("embedding", string(c.embeddingModel), resp.Usage)
+
+		// Results carry their input index; place them back in request order
+		embeddings := make([][]float64, len(texts))
+		for _, data := range resp.Data {
+			if data.Index < 0 || data.Index >= len(texts) {
+				return nil, fmt.Errorf("embedding index %d out of range", data.Index)
+			}
+			vector := make([]float64, len(data.Embedding))
+			for i, v := range data.Embedding {
+				vector[i] = float64(v)
+			}
+			embeddings[data.Index] = vector
+		}
+
+		return embeddings, nil
+	}
+
+	return nil, c.failed(fmt.Errorf("failed to generate embeddings after %d attempts: %w", c.maxRetries+1, lastErr))
+}
+
+// GenerateResponse sends a fully assembled prompt to the chat model and returns its reply
+func (c *OpenAIClient) GenerateResponse(prompt string) (string, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(util.CalculateBackoff(c.retryDelay, attempt))
+		}
+		c.waitForRate()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+
+		resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+			Model: c.chatModel,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: prompt,
+				},
+			},
+			Temperature: 0.3,
+		})
+		cancel()
+
+		if err != nil {
+			lastErr = fmt.Errorf("attempt %d: %w", attempt+1, err)
+			continue
+		}
+
+		if len(resp.Choices) == 0 {
+			lastErr = fmt.Errorf("attempt %d: no completion choices returned", attempt+1)
+			continue
+		}
+
+		c.recordUsage("generate_response", c.chatModel, resp.Usage)
+		return resp.Choices[0].Message.Content, nil
+	}
+
+	return "", c.failed(fmt.Errorf("failed to generate response after %d attempts: %w", c.maxRetries+1, lastErr))
 }
 
 // ExtractMetadata uses gpt-4o-mini to extract keywords, topics, and affect from conversation text
 func (c *OpenAIClient) ExtractMetadata(text string) (map[string]interface{}, error) {
+	return c.ExtractMetadataContext(context.Background(), text)
+}
+
+// ExtractMetadataContext is ExtractMetadata, traced as a child of the span in ctx.
+// Canceling ctx abandons the request and any remaining retries.
+func (c *OpenAIClient) ExtractMetadataContext(ctx context.Context, text string) (metadata map[string]interface{}, err error) {
+	ctx, span := tracing.Start(ctx, "llm.extract_metadata", tracing.String("llm.model", c.chatModel), tracing.Int("llm.input_chars", len(text)))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	systemPrompt := `You are a metadata extraction assistant. Given a conversation, extract:
 1. keywords: Important terms and concepts (array of strings)
 2. topics: High-level subjects discussed (array of strings)
-3. affect: Overall emotional tone/sentiment (string: positive, negative, neutral, mixed)
+3. affect: Overall emotional tone of the user (one string: excited, satisfied, frustrated, anxious, confused, curious; or positive, negative, neutral, mixed when no specific label fits)
 
 Return ONLY a JSON object with these three fields. No additional text.`
+	if len(c.taxonomy) > 0 {
+		systemPrompt += "\n\nName topics with these labels wherever one fits, written exactly as shown, choosing the most specific; use another label only for a subject none of them covers:\n" +
+			strings.Join(c.taxonomy.Labels(), "\n")
+	}
 
 	userPrompt := fmt.Sprintf("Extract metadata from this conversation:\n\n%s", text)
 
@@ -141,10 +372,15 @@ Return ONLY a JSON object with these three fields. No additional text.`
 		if attempt > 0 {
 			time.Sleep(util.CalculateBackoff(c.retryDelay, attempt))
 		}
+		if ctx.Err() != nil {
+			break
+		}
+		c.waitForRate()
+		span.SetAttributes(tracing.Int("llm.attempts", attempt+1))
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 
-		resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		resp, err := c.client.CreateChatCompletion(reqCtx, openai.ChatCompletionRequest{
 			Model: c.chatModel,
 			Messages: []openai.ChatCompletionMessage{
 				{
@@ -171,21 +407,46 @@ Return ONLY a JSON object with these three fields. No additional text.`
 			continue
 		}
 
+		c.recordUsage("extract_metadata", c.chatModel, resp.Usage)
 		content := resp.Choices[0].Message.Content
 
 		// Parse JSON response
-		var metadata map[string]interface{}
 		if err := json.Unmarshal([]byte(content), &metadata); err != nil {
 			cancel()
 			lastErr = fmt.Errorf("attempt %d: failed to parse JSON: %w", attempt+1, err)
 			continue
 		}
+		c.mapTopics(metadata)
 
 		cancel()
 		return metadata, nil
 	}
 
-	return nil, fmt.Errorf("failed to extract metadata after %d attempts: %w", c.maxRetries+1, lastErr)
+	if lastErr == nil {
+		lastErr = ctx.Err()
+	}
+	return nil, c.failed(fmt.Errorf("failed to extract metadata after %d attempts: %w", c.maxRetries+1, lastErr))
+}
+
+// mapTopics maps the topics in extracted metadata onto the taxonomy, in case the model
+// named one of its nodes by an alias or in other words
+func (c *OpenAIClient) mapTopics(metadata map[string]interface{}) {
+	raw, ok := metadata["topics"].([]interface{})
+	if !ok || len(c.taxonomy) == 0 {
+		return
+	}
+	var topics []string
+	for _, item := range raw {
+		if topic, ok := item.(string); ok {
+			topics = append(topics, topic)
+		}
+	}
+	mapped := c.taxonomy.MapTopics(topics, nil)
+	result := make([]interface{}, len(mapped))
+	for i, topic := range mapped {
+		result[i] = topic
+	}
+	metadata["topics"] = result
 }
 
 // ExtractFacts uses gpt-4o-mini to extract key-value facts from conversation text
@@ -208,9 +469,10 @@ For each fact, provide:
 - key: descriptive fact name (lowercase, underscores). For API keys, include service name (e.g., "weather_api_key")
 - value: the actual value
 - confidence: 0.0 to 1.0 (how certain you are)
+- quote: the exact words from the conversation that state the fact
 
-Return ONLY a JSON array of fact objects. Each object must have: key, value, confidence.
-Example: [{"key": "weather_api_key", "value": "ABC123XYZ", "confidence": 1.0}]
+Return ONLY a JSON array of fact objects. Each object must have: key, value, confidence, quote.
+Example: [{"key": "weather_api_key", "value": "ABC123XYZ", "confidence": 1.0, "quote": "my weather API key is ABC123XYZ"}]
 
 Extract EVERY fact explicitly stated. Do not infer or assume.`
 
@@ -222,6 +484,7 @@ Extract EVERY fact explicitly stated. Do not infer or assume.`
 		if attempt > 0 {
 			time.Sleep(util.CalculateBackoff(c.retryDelay, attempt))
 		}
+		c.waitForRate()
 
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 
@@ -252,6 +515,7 @@ Extract EVERY fact explicitly stated. Do not infer or assume.`
 			continue
 		}
 
+		c.recordUsage("extract_facts", c.chatModel, resp.Usage)
 		content := resp.Choices[0].Message.Content
 
 		// Parse JSON response into temporary struct
@@ -259,6 +523,7 @@ Extract EVERY fact explicitly stated. Do not infer or assume.`
 			Key        string  `json:"key"`
 			Value      string  `json:"value"`
 			Confidence float64 `json:"confidence"`
+			Quote      string  `json:"quote"`
 		}
 
 		var factResponses []FactResponse
@@ -272,9 +537,12 @@ Extract EVERY fact explicitly stated. Do not infer or assume.`
 		facts := make([]models.Fact, len(factResponses))
 		for i, fr := range factResponses {
 			facts[i] = models.Fact{
-				Key:        fr.Key,
-				Value:      fr.Value,
-				Confidence: fr.Confidence,
+				Key:           fr.Key,
+				Value:         fr.Value,
+				Confidence:    fr.Confidence,
+				SourceModel:   c.chatModel,
+				PromptVersion: FactPromptVersion,
+				SourceQuote:   fr.Quote,
 			}
 		}
 
@@ -282,5 +550,86 @@ Extract EVERY fact explicitly stated. Do not infer or assume.`
 		return facts, nil
 	}
 
-	return nil, fmt.Errorf("failed to extract facts after %d attempts: %w", c.maxRetries+1, lastErr)
+	return nil, c.failed(fmt.Errorf("failed to extract facts after %d attempts: %w", c.maxRetries+1, lastErr))
+}
+
+// PIIEntity is personal data the model found, quoted exactly as it appears in the text
+type PIIEntity struct {
+	Category string `json:"category"`
+	Text     string `json:"text"`
+}
+
+// DetectPII asks the chat model for personal data that patterns miss, such as
+// names, street addresses, dates of birth, and health details
+func (c *OpenAIClient) DetectPII(text string) ([]PIIEntity, error) {
+	systemPrompt := `You are a privacy reviewer. Given text from a user's stored conversations, find personal data about any real person.
+
+Categories:
+- name: a person's full or partial name
+- address: a street or postal address
+- date_of_birth: a birth date
+- government_id: passport, driver's license, national ID, or tax numbers
+- financial: bank account, IBAN, or card numbers
+- health: medical conditions, medications, or diagnoses tied to a person
+- email, phone: contact details
+- credential: passwords, API keys, or tokens
+
+For each item, provide:
+- category: one of the categories above
+- text: the exact characters from the input, copied verbatim
+
+Return ONLY a JSON array. Return [] when there is none.
+Example: [{"category": "address", "text": "221B Baker Street"}]
+
+Do not report public figures, company names, or generic places.`
+
+	userPrompt := fmt.Sprintf("Find personal data in this text:\n\n%s", text)
+
+	var lastErr error
+
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(util.CalculateBackoff(c.retryDelay, attempt))
+		}
+		c.waitForRate()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+
+		resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+			Model: c.chatModel,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleSystem,
+					Content: systemPrompt,
+				},
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: userPrompt,
+				},
+			},
+			Temperature: 0,
+		})
+		cancel()
+
+		if err != nil {
+			lastErr = fmt.Errorf("attempt %d: %w", attempt+1, err)
+			continue
+		}
+
+		if len(resp.Choices) == 0 {
+			lastErr = fmt.Errorf("attempt %d: no completion choices returned", attempt+1)
+			continue
+		}
+
+		c.recordUsage("detect_pii", c.chatModel, resp.Usage)
+
+		var entities []PIIEntity
+		if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &entities); err != nil {
+			lastErr = fmt.Errorf("attempt %d: failed to parse JSON: %w", attempt+1, err)
+			continue
+		}
+		return entities, nil
+	}
+
+	return nil, c.failed(fmt.Errorf("failed to detect PII after %d attempts: %w", c.maxRetries+1, lastErr))
 }
@@ -0,0 +1,72 @@
+// ABOUTME: HTTP proxy selection for LLM API traffic
+// ABOUTME: Uses an explicit proxy URL with a NO_PROXY-style bypass list, or falls back to the environment
+package llm
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// NewHTTPClient returns the HTTP client LLM API requests go through: one using
+// config's proxy, or the default client, which honors HTTPS_PROXY and NO_PROXY
+func NewHTTPClient(config *ClientConfig) (*http.Client, error) {
+	client, err := newHTTPClient(config.Proxy, config.NoProxy)
+	if err != nil || client != nil {
+		return client, err
+	}
+	return http.DefaultClient, nil
+}
+
+// newHTTPClient returns an HTTP client that sends requests through proxyURL, except for hosts in noProxy.
+// With no proxyURL it returns nil so callers keep the default client, which honors HTTPS_PROXY and NO_PROXY.
+func newHTTPClient(proxyURL, noProxy string) (*http.Client, error) {
+	if proxyURL == "" {
+		return nil, nil
+	}
+	proxy, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	if noProxy == "" {
+		noProxy = os.Getenv("NO_PROXY")
+		if noProxy == "" {
+			noProxy = os.Getenv("no_proxy")
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+		return proxy, nil
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// bypassProxy reports whether host matches a comma-separated NO_PROXY list.
+// Entries match the host itself or any subdomain; "*" matches everything.
+func bypassProxy(host, noProxy string) bool {
+	host = strings.ToLower(host)
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		entry = strings.TrimPrefix(entry, ".")
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
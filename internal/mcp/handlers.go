@@ -1,5 +1,5 @@
 // ABOUTME: MCP tool handler implementations for HMLR server
-// ABOUTME: Contains handler implementations with proper error handling for all 11 tools
+// ABOUTME: Contains handler implementations with proper error handling for all 14 tools
 package mcp
 
 import (
@@ -7,18 +7,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"sync"
-	"sync/atomic"
+	"slices"
+	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/health"
 	"github.com/harper/remember-standalone/internal/llm"
 	"github.com/harper/remember-standalone/internal/models"
 	"github.com/harper/remember-standalone/internal/storage"
 	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
 )
 
+// resumeCandidates is how many search results resume_topic checks for a paused topic
+const resumeCandidates = 20
+
 // Handlers contains the handler functions for all MCP tools
 type Handlers struct {
 	storage      *storage.Storage
@@ -26,51 +30,92 @@ type Handlers struct {
 	chunkEngine  *core.ChunkEngine
 	scribe       *core.Scribe
 	openaiClient *llm.OpenAIClient // For metadata extraction
-	shutdownWg   *sync.WaitGroup   // Track pending async operations
-	shuttingDown atomic.Bool       // Prevents new goroutines during shutdown
+	jobs         *core.JobQueue    // Background embeddings, fact extraction, and Scribe updates; nil when read-only
+	health       *health.Checker
+	opts         Options
 }
 
 // StoreConversation handles the store_conversation tool
 func (h *Handlers) StoreConversation(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Extract arguments
-	message, err := request.RequireString("message")
+	// Extract arguments; a messages transcript takes the place of message and context
+	message := request.GetString("message", "")
+	contextStr := request.GetString("context", "")
+
+	transcript, err := parseMessages(request)
 	if err != nil {
-		return mcp.NewToolResultError("message argument is required and must be a string"), nil
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var fromTranscript *models.Turn
+	if len(transcript) > 0 {
+		fromTranscript, err = models.NewTurnFromMessages(transcript)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid messages: %v", err)), nil
+		}
+		message = fromTranscript.UserMessage
+	}
+	if message == "" {
+		return mcp.NewToolResultError("message argument is required and must be a string (or pass messages)"), nil
 	}
 
-	contextStr := request.GetString("context", "")
+	persona, err := h.persona(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	attachments, err := parseAttachments(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	// Extract keywords and topics using LLM
-	var keywords, topics []string
+	origin, err := parseOrigin(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if origin.Agent == "" {
+		origin.Agent = h.agent(ctx)
+	}
+
+	// Extract keywords, topics, and affect using LLM. Without one, or when the call
+	// fails, the turn is stored anyway and the extraction is queued for later.
+	keywords, topics := []string{}, []string{}
+	var affect models.Affect
+	metadataPending := true
 	if h.openaiClient != nil {
-		metadata, err := h.openaiClient.ExtractMetadata(message)
+		metadataText := message
+		if fromTranscript != nil {
+			metadataText = fromTranscript.Text()
+		}
+		metadata, err := h.openaiClient.ExtractMetadataContext(ctx, metadataText)
 		if err != nil {
-			log.Printf("Warning: metadata extraction failed: %v", err)
-			// Continue with empty arrays rather than failing the entire request
-			keywords = []string{}
-			topics = []string{}
+			log.Printf("Warning: metadata extraction failed, queued for later: %v", err)
 		} else {
 			keywords = extractStringArray(metadata, "keywords")
 			topics = extractStringArray(metadata, "topics")
+			affect = extractAffect(metadata)
+			metadataPending = false
 		}
-	} else {
-		// No LLM client available, use empty arrays
-		keywords = []string{}
-		topics = []string{}
 	}
 
 	// Create a turn
 	turn := &models.Turn{
-		TurnID:      fmt.Sprintf("turn_%s_%s", time.Now().Format("20060102_150405"), uuid.New().String()[:8]),
-		Timestamp:   time.Now(),
+		TurnID:      models.NewID("turn"),
+		Timestamp:   h.storage.Now(),
 		UserMessage: message,
 		AIResponse:  contextStr, // Using context as AI response for now
 		Keywords:    keywords,
 		Topics:      topics,
+		Affect:      affect,
+		Origin:      origin,
+		// Cleared once its queued jobs finish
+		PendingEnrichment: h.jobs != nil && (metadataPending || !h.jobs.Handles(models.JobEmbedTurn)),
+	}
+	if fromTranscript != nil {
+		turn.AIResponse = fromTranscript.AIResponse
+		turn.Messages = fromTranscript.Messages
 	}
 
 	// Get routing decision from Governor
-	decision, err := h.governor.Route(turn)
+	decision, err := h.governor.RouteContext(ctx, turn)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("routing failed: %v", err)), nil
 	}
@@ -82,7 +127,7 @@ func (h *Handlers) StoreConversation(ctx context.Context, request mcp.CallToolRe
 	switch decision.Scenario {
 	case models.TopicContinuation:
 		// Append to existing active block
-		if err := h.storage.AppendTurnToBlock(decision.MatchedBlockID, turn); err != nil {
+		if err := h.storage.AppendTurnToBlockContext(ctx, decision.MatchedBlockID, turn); err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to append turn: %v", err)), nil
 		}
 		blockID = decision.MatchedBlockID
@@ -97,14 +142,14 @@ func (h *Handlers) StoreConversation(ctx context.Context, request mcp.CallToolRe
 		if err := h.storage.UpdateBridgeBlockStatus(decision.MatchedBlockID, models.StatusActive); err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to reactivate block: %v", err)), nil
 		}
-		if err := h.storage.AppendTurnToBlock(decision.MatchedBlockID, turn); err != nil {
+		if err := h.storage.AppendTurnToBlockContext(ctx, decision.MatchedBlockID, turn); err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to append turn: %v", err)), nil
 		}
 		blockID = decision.MatchedBlockID
 
 	case models.NewTopicFirst:
 		// Create new block (first topic)
-		blockID, err = h.storage.StoreTurn(turn)
+		blockID, err = h.storage.StoreTurnContext(ctx, turn)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to create new block: %v", err)), nil
 		}
@@ -116,46 +161,35 @@ func (h *Handlers) StoreConversation(ctx context.Context, request mcp.CallToolRe
 				return mcp.NewToolResultError(fmt.Sprintf("failed to pause active block: %v", err)), nil
 			}
 		}
-		blockID, err = h.storage.StoreTurn(turn)
+		blockID, err = h.storage.StoreTurnContext(ctx, turn)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to create new block: %v", err)), nil
 		}
 	}
 
+	// Link the block to the thread it came from; a missing link is not worth failing the store
+	if err := h.governor.RecordRelations(decision, blockID); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	// Attach files, URLs, and blobs to the stored turn
+	attachmentIDs := make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		if err := h.storage.AttachToTurn(turn.TurnID, a); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to attach %s: %v", a.Name, err)), nil
+		}
+		attachmentIDs = append(attachmentIDs, a.AttachmentID)
+	}
+
 	// Get facts count for the block
 	facts, err := h.storage.GetFactsForBlock(blockID)
 	if err == nil {
 		factsExtracted = len(facts)
 	}
 
-	// Trigger Scribe async to update user profile (with goroutine tracking)
-	// Note: We check shuttingDown before Add(1) to prevent launching goroutines
-	// after Shutdown() has started waiting. The goroutine also checks the flag
-	// to bail out early if shutdown began after we passed the outer check.
-	if h.scribe != nil && !h.shuttingDown.Load() {
-		profile, profileErr := h.storage.GetUserProfile()
-		if profileErr == nil {
-			// If no profile exists, create empty one
-			if profile == nil {
-				profile = &models.UserProfile{
-					Name:             "",
-					Preferences:      []string{},
-					TopicsOfInterest: []string{},
-					LastUpdated:      time.Now(),
-				}
-			}
-			// Run Scribe async - track goroutine for clean shutdown
-			h.shutdownWg.Add(1)
-			go func() {
-				defer h.shutdownWg.Done()
-				// Early exit if shutdown started between Add(1) and here
-				if h.shuttingDown.Load() {
-					return
-				}
-				h.scribe.UpdateProfileAsync(message, profile, h.storage)
-			}()
-		}
-	}
+	// Embeddings, facts, and profile learning wait on background workers, so a slow
+	// LLM never holds up the call
+	jobsQueued := h.queueTurnJobs(turn, blockID, message, persona, metadataPending)
 
 	// Build response
 	response := map[string]interface{}{
@@ -163,6 +197,11 @@ func (h *Handlers) StoreConversation(ctx context.Context, request mcp.CallToolRe
 		"turn_id":          turn.TurnID,
 		"routing_scenario": string(decision.Scenario),
 		"facts_extracted":  factsExtracted,
+		"attachment_ids":   attachmentIDs,
+		"jobs_queued":      jobsQueued,
+	}
+	if turn.PendingEnrichment {
+		response["pending_enrichment"] = true
 	}
 
 	responseJSON, err := json.Marshal(response)
@@ -181,28 +220,61 @@ func (h *Handlers) RetrieveMemory(ctx context.Context, request mcp.CallToolReque
 		return mcp.NewToolResultError("query argument is required and must be a string"), nil
 	}
 
-	maxResults := request.GetInt("max_results", 5)
+	maxResults := request.GetInt("max_results", h.opts.DefaultMaxResults)
+	tags := request.GetStringSlice("tags", nil)
+	affect, err := models.ParseAffect(request.GetString("affect", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	origin, err := parseOrigin(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	// Search for relevant memories
-	memories, err := h.storage.SearchMemory(query, maxResults)
+	// Search for relevant memories, keeping only blocks with every requested tag, the affect, and the origin
+	memories, err := h.storage.SearchMemoryWithOptions(query, maxResults, storage.SearchOptions{Tags: tags, Affect: affect, Origin: origin})
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("memory search failed: %v", err)), nil
 	}
 
-	// Get facts from matched blocks
+	// Get facts from matched blocks, only the agent's when filtering by one
 	factsList := []models.Fact{}
 	for _, memory := range memories {
 		facts, err := h.storage.GetFactsForBlock(memory.BlockID)
-		if err == nil {
-			factsList = append(factsList, facts...)
+		if err != nil {
+			continue
+		}
+		for _, fact := range facts {
+			if fact.ExcludeFromRecall {
+				continue
+			}
+			if origin.Agent == "" || fact.Agent == origin.Agent {
+				factsList = append(factsList, fact)
+			}
 		}
 	}
 
+	// Fit the response to the client's context budget when it has one
+	preset, fit := h.contextPreset(ctx, request)
+	trimmed := false
+	if fit {
+		memories, factsList, trimmed = fitContext(preset, memories, factsList)
+	}
+	blockIDs := make([]string, len(memories))
+	for i, memory := range memories {
+		blockIDs[i] = memory.BlockID
+	}
+	h.recordAccess(blockIDs...)
+
 	// Build response
 	response := map[string]interface{}{
 		"memories": memories,
 		"facts":    factsList,
 	}
+	if fit {
+		response["preset"] = preset
+		response["trimmed"] = trimmed
+	}
 
 	responseJSON, err := json.Marshal(response)
 	if err != nil {
@@ -212,6 +284,14 @@ func (h *Handlers) RetrieveMemory(ctx context.Context, request mcp.CallToolReque
 	return mcp.NewToolResultText(string(responseJSON)), nil
 }
 
+// recordAccess counts a read of each block. Heat is a hint, so failing to record it
+// does not fail the call.
+func (h *Handlers) recordAccess(blockIDs ...string) {
+	if err := h.storage.RecordBlockAccess(blockIDs...); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
 // ListActiveTopics handles the list_active_topics tool
 func (h *Handlers) ListActiveTopics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Get all active blocks
@@ -258,24 +338,64 @@ func (h *Handlers) GetTopicHistory(ctx context.Context, request mcp.CallToolRequ
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get block: %v", err)), nil
 	}
+	if block == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("block %s not found", blockID)), nil
+	}
+	h.recordAccess(blockID)
+
+	attachments, err := h.storage.GetBlockAttachments(blockID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get attachments: %v", err)), nil
+	}
+	attachmentsByTurn := make(map[string][]models.Attachment)
+	for _, a := range attachments {
+		attachmentsByTurn[a.TurnID] = append(attachmentsByTurn[a.TurnID], a)
+	}
 
 	// Format turns for response
 	turns := make([]map[string]interface{}, 0, len(block.Turns))
 	for _, turn := range block.Turns {
-		turns = append(turns, map[string]interface{}{
+		turnAttachments := attachmentsByTurn[turn.TurnID]
+		if turnAttachments == nil {
+			turnAttachments = []models.Attachment{}
+		}
+		entry := map[string]interface{}{
 			"turn_id":      turn.TurnID,
 			"timestamp":    turn.Timestamp.Format(time.RFC3339),
 			"user_message": turn.UserMessage,
 			"ai_response":  turn.AIResponse,
-		})
+			"affect":       turn.Affect,
+			"attachments":  turnAttachments,
+		}
+		if len(turn.Messages) > 0 {
+			entry["messages"] = turn.Messages
+		}
+		if !turn.Origin.IsZero() {
+			entry["origin"] = turn.Origin
+		}
+		if len(turn.Annotations) > 0 {
+			entry["annotations"] = turn.Annotations
+		}
+		if turn.EditedAt != nil {
+			entry["edited_at"] = turn.EditedAt.Format(time.RFC3339)
+		}
+		turns = append(turns, entry)
+	}
+
+	affectCounts := block.AffectCounts
+	if affectCounts == nil {
+		affectCounts = map[models.Affect]int{}
 	}
 
 	// Build response
 	response := map[string]interface{}{
-		"block_id":    block.BlockID,
-		"topic_label": block.TopicLabel,
-		"turns":       turns,
-		"summary":     block.Summary,
+		"block_id":      block.BlockID,
+		"topic_label":   block.TopicLabel,
+		"turns":         turns,
+		"summary":       block.Summary,
+		"tags":          block.Tags,
+		"affect":        block.Affect,
+		"affect_counts": affectCounts,
 	}
 
 	responseJSON, err := json.Marshal(response)
@@ -288,8 +408,13 @@ func (h *Handlers) GetTopicHistory(ctx context.Context, request mcp.CallToolRequ
 
 // GetUserProfile handles the get_user_profile tool
 func (h *Handlers) GetUserProfile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Load user profile from storage
-	profile, err := h.storage.GetUserProfile()
+	persona, err := h.persona(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Load user profile from storage, with the persona layered on top
+	profile, err := h.storage.GetUserProfileAs(persona)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to load profile: %v", err)), nil
 	}
@@ -300,18 +425,25 @@ func (h *Handlers) GetUserProfile(ctx context.Context, request mcp.CallToolReque
 			Name:             "",
 			Preferences:      []string{},
 			TopicsOfInterest: []string{},
+			Constraints:      []models.ProfileConstraint{},
 			LastUpdated:      time.Now(),
 		}
 	}
 
+	// List the personas so agents know which they can select
+	personas, err := h.storage.ListPersonas()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list personas: %v", err)), nil
+	}
+	personaNames := make([]string, 0, len(personas))
+	for _, p := range personas {
+		personaNames = append(personaNames, p.Name)
+	}
+
 	// Build response
 	response := map[string]interface{}{
-		"profile": map[string]interface{}{
-			"name":               profile.Name,
-			"preferences":        profile.Preferences,
-			"topics_of_interest": profile.TopicsOfInterest,
-			"last_updated":       profile.LastUpdated.Format(time.RFC3339),
-		},
+		"profile":  h.profileResponse(profile),
+		"personas": personaNames,
 	}
 
 	responseJSON, err := json.Marshal(response)
@@ -324,6 +456,30 @@ func (h *Handlers) GetUserProfile(ctx context.Context, request mcp.CallToolReque
 
 // UpdateUserProfile handles the update_user_profile tool
 func (h *Handlers) UpdateUserProfile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	persona, err := h.persona(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Remove entries first, e.g. to revert what get_profile_diff shows was learned; a
+	// preference not in the persona is removed from the shared profile
+	for _, text := range request.GetStringSlice("remove_preferences", nil) {
+		item := models.ProfileItem{Persona: persona, Kind: models.ProfileItemPreference, Text: text}
+		err := h.storage.DropProfileItem(item)
+		if err != nil && persona != "" {
+			item.Persona = ""
+			err = h.storage.DropProfileItem(item)
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to remove preference: %v", err)), nil
+		}
+	}
+	for _, text := range request.GetStringSlice("remove_topics_of_interest", nil) {
+		if err := h.storage.DropProfileItem(models.ProfileItem{Kind: models.ProfileItemTopic, Text: text}); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to remove topic: %v", err)), nil
+		}
+	}
+
 	// Load existing profile or create new one
 	profile, err := h.storage.GetUserProfile()
 	if err != nil {
@@ -335,6 +491,7 @@ func (h *Handlers) UpdateUserProfile(ctx context.Context, request mcp.CallToolRe
 			Name:             "",
 			Preferences:      []string{},
 			TopicsOfInterest: []string{},
+			Constraints:      []models.ProfileConstraint{},
 			LastUpdated:      time.Now(),
 		}
 	}
@@ -363,25 +520,118 @@ func (h *Handlers) UpdateUserProfile(ctx context.Context, request mcp.CallToolRe
 				updateInfo["topics_of_interest"] = topicsArray
 			}
 		}
+
+		// Get constraints if provided; reject malformed ones rather than dropping them
+		if constraintsRaw, exists := args["constraints"]; exists {
+			constraintsArray, ok := constraintsRaw.([]interface{})
+			if !ok {
+				return mcp.NewToolResultError("constraints must be an array of objects"), nil
+			}
+			constraints := make([]models.ProfileConstraint, 0, len(constraintsArray))
+			for i, item := range constraintsArray {
+				obj, ok := item.(map[string]interface{})
+				if !ok {
+					return mcp.NewToolResultError(fmt.Sprintf("constraints[%d] must be an object", i)), nil
+				}
+				c := models.ProfileConstraint{}
+				c.Type, _ = obj["type"].(string)
+				c.Description, _ = obj["description"].(string)
+				c.Severity, _ = obj["severity"].(string)
+				if err := c.Validate(); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("constraints[%d]: %v", i, err)), nil
+				}
+				constraints = append(constraints, c)
+			}
+			updateInfo["constraints"] = constraints
+		}
+	}
+
+	// Preferences and constraints go to the persona when one is selected
+	var p *models.Persona
+	if persona != "" {
+		p, err = h.storage.GetPersona(persona)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to load persona: %v", err)), nil
+		}
+		if p == nil {
+			p = &models.Persona{Name: persona}
+		}
 	}
 
 	// Merge updates into profile
-	profile.Merge(updateInfo)
+	profile.MergeForPersona(p, updateInfo)
 
 	// Save updated profile
 	if err := h.storage.SaveUserProfile(profile); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to save profile: %v", err)), nil
 	}
+	if p != nil {
+		if err := h.storage.SavePersona(p); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to save persona: %v", err)), nil
+		}
+	}
+	if err := h.storage.ReinforceProfileItems(persona, models.InfoStrings(updateInfo, "preferences"), models.InfoStrings(updateInfo, "topics_of_interest")); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to reinforce profile: %v", err)), nil
+	}
 
 	// Build response
 	response := map[string]interface{}{
 		"success": true,
-		"profile": map[string]interface{}{
-			"name":               profile.Name,
-			"preferences":        profile.Preferences,
-			"topics_of_interest": profile.TopicsOfInterest,
-			"last_updated":       profile.LastUpdated.Format(time.RFC3339),
-		},
+		"profile": h.profileResponse(profile.WithPersona(p)),
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(responseJSON)), nil
+}
+
+// GetProfileDiff handles the get_profile_diff tool
+func (h *Handlers) GetProfileDiff(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	persona, err := h.persona(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// A bare since date starts at the beginning of that day, a bare until date ends with it
+	var since time.Time
+	if raw := request.GetString("since", ""); raw != "" {
+		if since, err = models.ParseBefore(raw, h.storage.Location()); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	until := time.Now()
+	if raw := request.GetString("until", ""); raw != "" {
+		if until, err = models.ParseAsOf(raw, h.storage.Location()); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	diff, err := h.storage.GetProfileDiff(persona, since, until)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to diff profile: %v", err)), nil
+	}
+
+	// Build response
+	learned := 0
+	for _, change := range diff.Changes {
+		if len(change.TurnIDs) > 0 {
+			learned++
+		}
+	}
+	response := map[string]interface{}{
+		"count":   len(diff.Changes),
+		"learned": learned,
+		"changes": diff.Changes,
+		"until":   diff.Until.Format(time.RFC3339),
+	}
+	if !since.IsZero() {
+		response["since"] = diff.Since.Format(time.RFC3339)
+	}
+	if diff.Persona != "" {
+		response["persona"] = diff.Persona
 	}
 
 	responseJSON, err := json.Marshal(response)
@@ -407,11 +657,25 @@ func (h *Handlers) AddFact(ctx context.Context, request mcp.CallToolRequest) (*m
 
 	confidence := request.GetFloat("confidence", 1.0)
 
+	valueType, err := models.ParseFactValueType(request.GetString("value_type", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid fact: %v", err)), nil
+	}
+	if value, err = models.NormalizeFactValue(valueType, value); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid fact: %v", err)), nil
+	}
+
 	// Create fact using constructor with validation
 	fact, err := models.NewFact("direct_input", "direct_input", key, value, confidence)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("invalid fact: %v", err)), nil
 	}
+	fact.ValueType = valueType
+
+	// Record who added the fact and why
+	fact.ExtractedBy = request.GetString("agent", h.agent(ctx))
+	fact.Agent = fact.ExtractedBy
+	fact.SourceQuote = request.GetString("quote", "")
 
 	// Save fact
 	if err := h.storage.SaveFact(fact); err != nil {
@@ -420,10 +684,12 @@ func (h *Handlers) AddFact(ctx context.Context, request mcp.CallToolRequest) (*m
 
 	// Build response
 	response := map[string]interface{}{
-		"success": true,
-		"fact_id": fact.FactID,
-		"key":     fact.Key,
-		"value":   fact.Value,
+		"success":      true,
+		"fact_id":      fact.FactID,
+		"key":          fact.Key,
+		"value":        fact.Value,
+		"value_type":   fact.ValueType,
+		"extracted_by": fact.ExtractedBy,
 	}
 
 	responseJSON, err := json.Marshal(response)
@@ -442,8 +708,17 @@ func (h *Handlers) GetFact(ctx context.Context, request mcp.CallToolRequest) (*m
 		return mcp.NewToolResultError("key argument is required and must be a string"), nil
 	}
 
-	// Look up fact
-	fact, err := h.storage.GetFactByKey(key)
+	// Look up fact, as it was at as_of when given
+	var fact *models.Fact
+	var asOf time.Time
+	if raw := request.GetString("as_of", ""); raw != "" {
+		if asOf, err = models.ParseAsOf(raw, h.storage.Location()); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		fact, err = h.storage.GetFactAsOf(key, asOf)
+	} else {
+		fact, err = h.storage.GetFactByKey(key)
+	}
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get fact: %v", err)), nil
 	}
@@ -461,10 +736,22 @@ func (h *Handlers) GetFact(ctx context.Context, request mcp.CallToolRequest) (*m
 			"fact_id":    fact.FactID,
 			"key":        fact.Key,
 			"value":      fact.Value,
+			"value_type": fact.ValueType,
 			"confidence": fact.Confidence,
 			"created_at": fact.CreatedAt.Format(time.RFC3339),
+			"tags":       fact.Tags,
+			"source": map[string]interface{}{
+				"model":          fact.SourceModel,
+				"prompt_version": fact.PromptVersion,
+				"quote":          fact.SourceQuote,
+				"extracted_by":   fact.ExtractedBy,
+				"agent":          fact.Agent,
+			},
 		}
 	}
+	if !asOf.IsZero() {
+		response["as_of"] = asOf.Format(time.RFC3339)
+	}
 
 	responseJSON, err := json.Marshal(response)
 	if err != nil {
@@ -531,6 +818,46 @@ func (h *Handlers) ArchiveTopic(ctx context.Context, request mcp.CallToolRequest
 	return mcp.NewToolResultText(string(responseJSON)), nil
 }
 
+// ArchiveTopics handles the archive_topics tool
+func (h *Handlers) ArchiveTopics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract arguments
+	filter := storage.ArchiveFilter{Label: request.GetString("label", "")}
+	if raw := request.GetString("before", ""); raw != "" {
+		before, err := models.ParseBefore(raw, h.storage.Location())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		filter.Before = before
+	}
+	if filter.Before.IsZero() && filter.Label == "" {
+		return mcp.NewToolResultError("before, label, or both are required"), nil
+	}
+	dryRun := request.GetBool("dry_run", false)
+
+	blocks, err := h.storage.ArchiveBlocks(filter, dryRun)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to archive topics: %v", err)), nil
+	}
+	if blocks == nil {
+		blocks = []storage.ArchivedBlock{}
+	}
+
+	// Build response
+	response := map[string]interface{}{
+		"success": true,
+		"dry_run": dryRun,
+		"count":   len(blocks),
+		"topics":  blocks,
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(responseJSON)), nil
+}
+
 // DeleteTopic handles the delete_topic tool
 func (h *Handlers) DeleteTopic(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract arguments
@@ -559,12 +886,481 @@ func (h *Handlers) DeleteTopic(ctx context.Context, request mcp.CallToolRequest)
 	return mcp.NewToolResultText(string(responseJSON)), nil
 }
 
-// Shutdown waits for all pending async Scribe operations to complete
+// TagMemory handles the tag_memory tool
+func (h *Handlers) TagMemory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract arguments
+	blockID := request.GetString("block_id", "")
+	factKey := request.GetString("fact_key", "")
+	if (blockID == "") == (factKey == "") {
+		return mcp.NewToolResultError("exactly one of block_id or fact_key is required"), nil
+	}
+	add := request.GetStringSlice("add", nil)
+	remove := request.GetStringSlice("remove", nil)
+
+	response := map[string]interface{}{
+		"success": true,
+	}
+
+	if blockID != "" {
+		tags, err := h.storage.TagBlock(blockID, add, remove)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to tag block: %v", err)), nil
+		}
+		response["block_id"] = blockID
+		response["tags"] = tags
+	} else {
+		fact, err := h.storage.GetFactByKey(factKey)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get fact: %v", err)), nil
+		}
+		if fact == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("no fact with key %q", factKey)), nil
+		}
+		tags, err := h.storage.TagFact(fact.FactID, add, remove)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to tag fact: %v", err)), nil
+		}
+		response["fact_id"] = fact.FactID
+		response["key"] = fact.Key
+		response["tags"] = tags
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(responseJSON)), nil
+}
+
+// ExcludeFromRecall handles the exclude_from_recall tool
+func (h *Handlers) ExcludeFromRecall(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract arguments
+	blockID := request.GetString("block_id", "")
+	factKey := request.GetString("fact_key", "")
+	if (blockID == "") == (factKey == "") {
+		return mcp.NewToolResultError("exactly one of block_id or fact_key is required"), nil
+	}
+	excluded := request.GetBool("excluded", true)
+
+	response := map[string]interface{}{
+		"success":             true,
+		"exclude_from_recall": excluded,
+	}
+
+	if blockID != "" {
+		if err := h.storage.ExcludeBlockFromRecall(blockID, excluded); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to update topic: %v", err)), nil
+		}
+		response["block_id"] = blockID
+	} else {
+		if err := h.storage.ExcludeFactFromRecall(factKey, excluded); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to update fact: %v", err)), nil
+		}
+		response["key"] = factKey
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(responseJSON)), nil
+}
+
+// RateMemory handles the rate_memory tool
+func (h *Handlers) RateMemory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract arguments
+	ratingArg, err := request.RequireString("rating")
+	if err != nil {
+		return mcp.NewToolResultError("rating argument is required and must be a string"), nil
+	}
+	rating, err := models.ParseRating(ratingArg)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	feedback := &models.MemoryFeedback{
+		Query:   request.GetString("query", ""),
+		BlockID: request.GetString("block_id", ""),
+		FactID:  request.GetString("fact_id", ""),
+		Rating:  rating,
+		Note:    request.GetString("note", ""),
+		Agent:   h.agent(ctx),
+	}
+	if (feedback.BlockID == "") == (feedback.FactID == "") {
+		return mcp.NewToolResultError("exactly one of block_id or fact_id is required"), nil
+	}
+
+	if err := h.storage.RateMemory(feedback); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to rate memory: %v", err)), nil
+	}
+
+	response := map[string]interface{}{
+		"success":     true,
+		"feedback_id": feedback.FeedbackID,
+		"rating":      feedback.Rating,
+	}
+	if feedback.BlockID != "" {
+		response["block_id"] = feedback.BlockID
+	} else {
+		response["fact_id"] = feedback.FactID
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(responseJSON)), nil
+}
+
+// ResumeTopic handles the resume_topic tool
+func (h *Handlers) ResumeTopic(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract arguments
+	blockID := request.GetString("block_id", "")
+	query := request.GetString("query", "")
+	if (blockID == "") == (query == "") {
+		return mcp.NewToolResultError("exactly one of block_id or query is required"), nil
+	}
+	window := h.governor.ResumptionWindow()
+	if _, ok := request.GetArguments()["within_days"]; ok {
+		days := request.GetFloat("within_days", 0)
+		if days < 0 {
+			return mcp.NewToolResultError("within_days must be 0 or more"), nil
+		}
+		window = time.Duration(days * float64(24*time.Hour))
+	}
+
+	// Find the most relevant paused topic recent enough to resume
+	if query != "" {
+		memories, err := h.storage.SearchMemoryWithOptions(query, resumeCandidates, storage.SearchOptions{})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("memory search failed: %v", err)), nil
+		}
+		now := h.storage.Now()
+		for _, memory := range memories {
+			block, err := h.storage.GetBridgeBlock(memory.BlockID)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to get block: %v", err)), nil
+			}
+			if block != nil && block.Status == models.StatusPaused && core.Resumable(block, window, now) {
+				blockID = block.BlockID
+				break
+			}
+		}
+		if blockID == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("no paused topic idle within the resumption window matches %q; pass within_days to look further back", query)), nil
+		}
+	}
+
+	block, paused, err := h.governor.Resume(blockID, window)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to resume topic: %v", err)), nil
+	}
+
+	response := map[string]interface{}{
+		"success":     true,
+		"block_id":    block.BlockID,
+		"topic_label": block.TopicLabel,
+		"paused":      paused,
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(responseJSON)), nil
+}
+
+// ReviewFacts handles the review_facts tool
+func (h *Handlers) ReviewFacts(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract arguments
+	approve := request.GetStringSlice("approve", nil)
+	reject := request.GetStringSlice("reject", nil)
+	values := map[string]string{}
+	if raw, ok := request.GetArguments()["values"].(map[string]interface{}); ok {
+		for id, v := range raw {
+			value, ok := v.(string)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("value for %s must be a string", id)), nil
+			}
+			values[id] = value
+		}
+	}
+	for id := range values {
+		if !slices.Contains(approve, id) {
+			return mcp.NewToolResultError(fmt.Sprintf("a value was given for %s, which is not being approved", id)), nil
+		}
+	}
+
+	approved := []models.Fact{}
+	for _, id := range approve {
+		fact, err := h.storage.ApproveFact(id, values[id])
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to approve fact: %v", err)), nil
+		}
+		approved = append(approved, *fact)
+	}
+	for _, id := range reject {
+		if err := h.storage.RejectFact(id); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to reject fact: %v", err)), nil
+		}
+	}
+
+	pending, err := h.storage.PendingFacts()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list pending facts: %v", err)), nil
+	}
+	if pending == nil {
+		pending = []models.Fact{}
+	}
+
+	// Build response
+	response := map[string]interface{}{
+		"success":  true,
+		"approved": approved,
+		"rejected": len(reject),
+		"pending":  pending,
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(responseJSON)), nil
+}
+
+// UpdateTurn handles the update_turn tool
+func (h *Handlers) UpdateTurn(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Extract arguments
+	turnID, err := request.RequireString("turn_id")
+	if err != nil {
+		return mcp.NewToolResultError("turn_id argument is required and must be a string"), nil
+	}
+	edit := models.TurnEdit{Annotation: request.GetString("annotation", ""), Agent: h.agent(ctx)}
+	args := request.GetArguments()
+	if text, ok := args["user_message"].(string); ok {
+		edit.UserMessage = &text
+	}
+	if text, ok := args["ai_response"].(string); ok {
+		edit.AIResponse = &text
+	}
+	if edit.UserMessage == nil && edit.AIResponse == nil && strings.TrimSpace(edit.Annotation) == "" {
+		return mcp.NewToolResultError("give user_message, ai_response, or annotation"), nil
+	}
+
+	edited, err := core.EditTurn(h.storage, h.jobs, turnID, edit)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to update turn: %v", err)), nil
+	}
+
+	response := map[string]interface{}{
+		"success":       true,
+		"turn_id":       turnID,
+		"block_id":      edited.BlockID,
+		"annotations":   edited.Turn.Annotations,
+		"facts_dropped": edited.FactsDropped,
+		"jobs_queued":   len(edited.Jobs),
+	}
+	if edited.Turn.EditedAt != nil {
+		response["edited_at"] = edited.Turn.EditedAt.Format(time.RFC3339)
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(responseJSON)), nil
+}
+
+// GetRelatedTopics handles the get_related_topics tool
+func (h *Handlers) GetRelatedTopics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	blockID, err := request.RequireString("block_id")
+	if err != nil {
+		return mcp.NewToolResultError("block_id argument is required and must be a string"), nil
+	}
+
+	related, err := h.storage.GetRelatedTopics(blockID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get related topics: %v", err)), nil
+	}
+
+	topics := make([]map[string]interface{}, 0, len(related))
+	for _, topic := range related {
+		topics = append(topics, map[string]interface{}{
+			"block_id":    topic.BlockID,
+			"topic_label": topic.TopicLabel,
+			"status":      topic.Status,
+			"relation":    topic.Relation,
+			"created_at":  topic.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	response := map[string]interface{}{
+		"block_id": blockID,
+		"related":  topics,
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(responseJSON)), nil
+}
+
+// Health handles the health tool
+func (h *Handlers) Health(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	responseJSON, err := json.Marshal(h.health.Check(ctx))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(responseJSON)), nil
+}
+
+// GetMemoryHealth handles the get_memory_health tool
+func (h *Handlers) GetMemoryHealth(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	idleCutoff := h.storage.Now().Add(-core.ConsolidateAge)
+	memory, err := h.storage.MemoryHealth(idleCutoff)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to check memory health: %v", err)), nil
+	}
+	report := h.health.Check(ctx)
+
+	// Each signal worth acting on comes with the action that clears it
+	suggestions := []map[string]interface{}{}
+	suggest := func(action, reason string) {
+		suggestions = append(suggestions, map[string]interface{}{"action": action, "reason": reason})
+	}
+	status, problems := report.Status, report.Problems
+	if !memory.InvariantOK {
+		problems = append(problems, fmt.Sprintf("%d topics are active, want at most 1", memory.ActiveBlocks))
+		if status == health.StatusOK {
+			status = health.StatusDegraded
+		}
+		suggest("repair", "run 'memory sync repair-blocks' (or restart the server) to pause every active topic but the newest")
+	}
+	if memory.IdleBlocks > 0 {
+		suggest("consolidate", fmt.Sprintf("%d paused or closed topics have been idle since %s; archive them with archive_topics (before=%s)",
+			memory.IdleBlocks, idleCutoff.Format("2006-01-02"), idleCutoff.Format("2006-01-02")))
+	}
+	if memory.PendingFacts > 0 {
+		suggest("review_facts", fmt.Sprintf("%d extracted facts wait for approval and are not recalled until reviewed", memory.PendingFacts))
+	}
+	if memory.Conflicts > 0 {
+		suggest("resolve_conflicts", fmt.Sprintf("%d facts replaced a different value recorded by another agent; check them with get_fact and correct them with add_fact", memory.Conflicts))
+	}
+	if memory.PendingEnrichment > 0 && h.openaiClient == nil {
+		suggest("configure_llm", fmt.Sprintf("%d turns wait on enrichment, which needs an OpenAI API key", memory.PendingEnrichment))
+	}
+
+	// Build response
+	response := map[string]interface{}{
+		"status":      status,
+		"memory":      memory,
+		"jobs":        report.Jobs,
+		"suggestions": suggestions,
+		"checked_at":  report.CheckedAt.Format(time.RFC3339),
+	}
+	if len(problems) > 0 {
+		response["problems"] = problems
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal response: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(responseJSON)), nil
+}
+
+// persona returns the normalized persona argument, else the server's default persona
+func (h *Handlers) persona(request mcp.CallToolRequest) (string, error) {
+	name := request.GetString("persona", "")
+	if name == "" {
+		return h.opts.DefaultPersona, nil
+	}
+	return models.NormalizePersonaName(name)
+}
+
+// profileResponse renders a profile (possibly a persona view) for tool responses
+func (h *Handlers) profileResponse(profile *models.UserProfile) map[string]interface{} {
+	response := map[string]interface{}{
+		"name":               profile.Name,
+		"preferences":        profile.Preferences,
+		"topics_of_interest": profile.TopicsOfInterest,
+		"constraints":        profile.Constraints,
+		"last_updated":       profile.LastUpdated.Format(time.RFC3339),
+	}
+	if profile.Persona != "" {
+		response["persona"] = profile.Persona
+	}
+	return response
+}
+
+// agent returns who stored turns and facts are attributed to: the configured agent_id,
+// or else the MCP client's name
+func (h *Handlers) agent(ctx context.Context) string {
+	if h.opts.AgentID != "" {
+		return h.opts.AgentID
+	}
+	return clientName(ctx)
+}
+
+// clientName returns the name the MCP client gave at initialization, or "mcp" if unknown
+func clientName(ctx context.Context) string {
+	if session, ok := mcpserver.ClientSessionFromContext(ctx).(mcpserver.SessionWithClientInfo); ok {
+		if name := session.GetClientInfo().Name; name != "" {
+			return name
+		}
+	}
+	return "mcp"
+}
+
+// queueTurnJobs queues the background work for a stored turn and returns how many jobs
+// were queued. Jobs this server has no handler for (it has no OpenAI key) are queued
+// all the same and wait for a process that has one. The message is merged into a
+// waiting profile job when there is one, which does not count as a new job. A job that
+// cannot be queued is logged and skipped; the turn is already saved.
+func (h *Handlers) queueTurnJobs(turn *models.Turn, blockID, message, persona string, metadata bool) int {
+	if h.jobs == nil {
+		return 0
+	}
+	kinds := []models.JobKind{models.JobEmbedTurn, models.JobExtractFacts}
+	if metadata {
+		kinds = append(kinds, models.JobExtractMetadata)
+	}
+	queued := 0
+	for _, kind := range kinds {
+		if _, err := h.jobs.Enqueue(kind, models.TurnJob{TurnID: turn.TurnID, BlockID: blockID}); err != nil {
+			log.Printf("Warning: %v", err)
+			continue
+		}
+		queued++
+	}
+
+	job, merged, err := h.jobs.EnqueueProfile(models.ProfileJob{Message: message, Persona: persona, TurnIDs: []string{turn.TurnID}})
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	} else if job != nil && !merged {
+		queued++
+	}
+	return queued
+}
+
+// Shutdown stops the background job workers once their current jobs finish; queued
+// jobs stay in the database for the next start
 func (h *Handlers) Shutdown() {
-	h.shuttingDown.Store(true)
-	log.Println("Waiting for pending Scribe operations to complete...")
-	h.shutdownWg.Wait()
-	log.Println("All Scribe operations completed")
+	if h.jobs == nil {
+		return
+	}
+	log.Println("Waiting for running background jobs to finish...")
+	h.jobs.Shutdown()
+	log.Println("Background jobs stopped")
 }
 
 // extractStringArray extracts a string array from metadata map
@@ -582,3 +1378,90 @@ func extractStringArray(metadata map[string]interface{}, key string) []string {
 	}
 	return []string{}
 }
+
+// parseMessages reads the optional messages argument: a transcript of role-tagged
+// messages, with tool calls in either flat or OpenAI form
+func parseMessages(request mcp.CallToolRequest) ([]models.Message, error) {
+	args, _ := request.Params.Arguments.(map[string]any)
+	raw, exists := args["messages"]
+	if !exists {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("messages must be an array of objects")
+	}
+	var messages []models.Message
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("messages must be an array of objects: %v", err)
+	}
+	return messages, nil
+}
+
+// parseOrigin reads the optional origin argument: an object with device, hostname,
+// working_dir, git_repo, location, and agent strings
+func parseOrigin(request mcp.CallToolRequest) (models.Origin, error) {
+	args, _ := request.Params.Arguments.(map[string]any)
+	raw, exists := args["origin"]
+	if !exists {
+		return models.Origin{}, nil
+	}
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return models.Origin{}, fmt.Errorf("origin must be an object")
+	}
+	var o models.Origin
+	o.Device, _ = obj["device"].(string)
+	o.Hostname, _ = obj["hostname"].(string)
+	o.WorkingDir, _ = obj["working_dir"].(string)
+	o.GitRepo, _ = obj["git_repo"].(string)
+	o.Location, _ = obj["location"].(string)
+	o.Agent, _ = obj["agent"].(string)
+	return o.Normalize(), nil
+}
+
+// parseAttachments reads the optional attachments argument: objects with a ref (file
+// path or URL) and/or inline content, plus an optional name and mime_type
+func parseAttachments(request mcp.CallToolRequest) ([]*models.Attachment, error) {
+	args, _ := request.Params.Arguments.(map[string]any)
+	raw, exists := args["attachments"]
+	if !exists {
+		return nil, nil
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("attachments must be an array of objects")
+	}
+
+	attachments := make([]*models.Attachment, 0, len(items))
+	for i, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("attachments[%d] must be an object", i)
+		}
+		ref, _ := obj["ref"].(string)
+		name, _ := obj["name"].(string)
+		content, _ := obj["content"].(string)
+		a, err := models.NewAttachment(ref, name, content)
+		if err != nil {
+			return nil, fmt.Errorf("attachments[%d]: %v", i, err)
+		}
+		a.MimeType, _ = obj["mime_type"].(string)
+		attachments = append(attachments, a)
+	}
+	return attachments, nil
+}
+
+// extractAffect reads the affect label from metadata, dropping labels outside the known vocabulary
+func extractAffect(metadata map[string]interface{}) models.Affect {
+	label, ok := metadata["affect"].(string)
+	if !ok {
+		return ""
+	}
+	affect, err := models.ParseAffect(label)
+	if err != nil {
+		log.Printf("Warning: ignoring metadata affect: %v", err)
+		return ""
+	}
+	return affect
+}
@@ -0,0 +1,123 @@
+// ABOUTME: Per-client context presets: the budget and emphasis retrieve_memory applies, chosen by the client's name
+// ABOUTME: Servers advertise each client's preset in the instructions returned from initialize
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// AdvertiseContextPresets adds a hook that tells each client at initialize which preset
+// retrieve_memory applies to it. Pass hooks to the server with mcpserver.WithHooks.
+func AdvertiseContextPresets(hooks *mcpserver.Hooks, presets models.ContextPresets) {
+	if len(presets) == 0 {
+		return
+	}
+	hooks.AddAfterInitialize(func(ctx context.Context, id any, request *mcp.InitializeRequest, result *mcp.InitializeResult) {
+		preset, ok := presets.ForClient(request.Params.ClientInfo.Name)
+		if !ok {
+			return
+		}
+		if result.Instructions != "" {
+			result.Instructions += "\n\n"
+		}
+		result.Instructions += fmt.Sprintf("Memory context preset for this client: retrieve_memory returns about %d tokens of memories and facts, weighted %s. Pass max_tokens to change the budget for one call.",
+			preset.MaxTokens, emphasisDescription(preset.Emphasis))
+	})
+}
+
+// emphasisDescription says in words how a preset splits its budget
+func emphasisDescription(emphasis models.ContextEmphasis) string {
+	switch emphasis {
+	case models.EmphasisFacts:
+		return "toward facts"
+	case models.EmphasisHistory:
+		return "toward conversation history"
+	}
+	return "evenly between facts and conversation history"
+}
+
+// contextPreset returns the preset retrieve_memory applies to the calling client: the
+// configured one, with its budget replaced by a max_tokens argument. A max_tokens
+// argument alone gives a balanced preset; with neither, nothing is trimmed.
+func (h *Handlers) contextPreset(ctx context.Context, request mcp.CallToolRequest) (models.ContextPreset, bool) {
+	client := clientName(ctx)
+	preset, ok := h.opts.ContextPresets.ForClient(client)
+	if maxTokens := request.GetInt("max_tokens", 0); maxTokens > 0 {
+		if !ok {
+			preset = models.ContextPreset{Client: client, Emphasis: models.EmphasisBalanced}
+		}
+		preset.MaxTokens, ok = maxTokens, true
+	}
+	return preset, ok
+}
+
+// fitContext keeps as many memories and facts as fit the preset's budget (4 characters
+// to a token, measured as JSON), in the order given. Facts get the preset's share and
+// memories the rest, each taking what the other leaves unused. A memory too large to
+// fit keeps its most recent turns that do. It reports whether anything was left out.
+func fitContext(preset models.ContextPreset, memories []models.MemorySearchResult, facts []models.Fact) ([]models.MemorySearchResult, []models.Fact, bool) {
+	budget := preset.MaxTokens * 4
+	factBudget := int(float64(budget) * preset.FactShare())
+
+	keptFacts, factsUsed := fitFacts(facts, factBudget)
+	keptMemories, memoriesUsed := fitMemories(memories, budget-factsUsed)
+	if left := budget - memoriesUsed; left > factBudget {
+		keptFacts, _ = fitFacts(facts, left)
+	}
+
+	trimmed := len(keptFacts) < len(facts) || len(keptMemories) < len(memories)
+	for i := range keptMemories {
+		trimmed = trimmed || len(keptMemories[i].Turns) < len(memories[i].Turns)
+	}
+	return keptMemories, keptFacts, trimmed
+}
+
+// fitFacts keeps the facts that fit in budget characters, returning the characters used
+func fitFacts(facts []models.Fact, budget int) ([]models.Fact, int) {
+	kept := []models.Fact{}
+	used := 0
+	for _, fact := range facts {
+		if size := jsonSize(fact); used+size <= budget {
+			kept = append(kept, fact)
+			used += size
+		}
+	}
+	return kept, used
+}
+
+// fitMemories keeps the memories that fit in budget characters, dropping the oldest
+// turns of one that does not fit whole, and returns the characters used
+func fitMemories(memories []models.MemorySearchResult, budget int) ([]models.MemorySearchResult, int) {
+	kept := []models.MemorySearchResult{}
+	used := 0
+	for _, memory := range memories {
+		for {
+			if size := jsonSize(memory); used+size <= budget {
+				kept = append(kept, memory)
+				used += size
+				break
+			}
+			if len(memory.Turns) == 0 {
+				break
+			}
+			memory.Turns = memory.Turns[1:]
+		}
+	}
+	return kept, used
+}
+
+// jsonSize is the length of v encoded as JSON
+func jsonSize(v any) int {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
@@ -1,19 +1,47 @@
 // ABOUTME: MCP tool definitions and registration for HMLR server
-// ABOUTME: Defines JSON schemas for all 11 MCP tools following DESIGN.md spec
+// ABOUTME: Defines JSON schemas for all 17 MCP tools following DESIGN.md spec
 package mcp
 
 import (
-	"sync"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/events"
+	"github.com/harper/remember-standalone/internal/health"
 	"github.com/harper/remember-standalone/internal/llm"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/redact"
 	"github.com/harper/remember-standalone/internal/storage"
+	"github.com/harper/remember-standalone/internal/tracing"
 	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 )
 
-// RegisterTools registers all MCP tools with the server
-func RegisterTools(server *mcpserver.MCPServer, store *storage.Storage, governor *core.Governor, chunkEngine *core.ChunkEngine, scribe *core.Scribe, openaiClient *llm.OpenAIClient) *Handlers {
+// Options tune tool behavior; zero values use the defaults
+type Options struct {
+	DefaultMaxResults int                   // retrieve_memory results when max_results is omitted (default 5)
+	ReadOnly          bool                  // register only the retrieval and listing tools
+	OnToolCall        func(tool string)     // called with the tool name before each call, e.g. for telemetry
+	DefaultPersona    string                // profile persona used when a tool call gives none (empty: shared profile)
+	JobWorkers        int                   // background job workers (default core.DefaultJobWorkers); read-only servers run none
+	ProfileQueueLimit int                   // profile learning jobs that may wait (default core.DefaultProfileQueueLimit)
+	Webhooks          *events.Webhooks      // delivers queued webhook events; nil leaves them for another process
+	AgentID           string                // agent recorded on stored turns and facts (default: the MCP client's name)
+	ContextPresets    models.ContextPresets // retrieve_memory budget and emphasis per MCP client name
+	DisabledTools     []string              // tools left unregistered, so clients are never offered them
+}
+
+// RegisterTools registers all MCP tools with the server, or only the read tools when opts.ReadOnly is set.
+// Tools named in opts.DisabledTools are left out either way.
+func RegisterTools(server *mcpserver.MCPServer, store *storage.Storage, governor *core.Governor, chunkEngine *core.ChunkEngine, scribe *core.Scribe, openaiClient *llm.OpenAIClient, opts Options) *Handlers {
+	if opts.DefaultMaxResults <= 0 {
+		opts.DefaultMaxResults = 5
+	}
+
 	// Initialize handlers
 	handlers := &Handlers{
 		storage:      store,
@@ -21,11 +49,58 @@ func RegisterTools(server *mcpserver.MCPServer, store *storage.Storage, governor
 		chunkEngine:  chunkEngine,
 		scribe:       scribe,
 		openaiClient: openaiClient,
-		shutdownWg:   &sync.WaitGroup{},
+		health:       health.NewChecker(store, openaiClient),
+		opts:         opts,
+	}
+
+	// Writable servers work through queued embeddings, fact extraction, and profile
+	// learning in the background, including jobs left over from earlier runs
+	if !opts.ReadOnly {
+		handlers.jobs = core.NewJobQueue(store)
+		core.RegisterJobHandlers(handlers.jobs, openaiClient, scribe)
+		if opts.Webhooks != nil {
+			handlers.jobs.Handle(models.JobDeliverWebhook, opts.Webhooks.Deliver)
+		}
+		handlers.jobs.SetProfileQueueLimit(opts.ProfileQueueLimit)
+		handlers.jobs.Start(opts.JobWorkers)
+	}
+
+	// addTool registers a tool, reporting each call to opts.OnToolCall and tracing it
+	// as the root span of the storage and LLM work it does. Credentials in results are
+	// masked, except from get_fact, which returns the value asked for by key.
+	addTool := func(tool mcp.Tool, handler mcpserver.ToolHandlerFunc) {
+		if slices.Contains(opts.DisabledTools, tool.Name) {
+			return
+		}
+		inner, name := handler, tool.Name
+		handler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if opts.OnToolCall != nil {
+				opts.OnToolCall(name)
+			}
+			ctx, span := tracing.Start(ctx, "mcp."+name, tracing.String("mcp.tool", name))
+			defer span.End()
+			result, err := inner(ctx, request)
+			if name != "get_fact" {
+				redactResult(result)
+			}
+			span.RecordError(err)
+			if result != nil && result.IsError {
+				span.RecordError(toolError(result))
+			}
+			return result, err
+		}
+		server.AddTool(tool, handler)
+	}
+
+	// addWriteTool registers a tool that changes memory; read-only servers skip it
+	addWriteTool := func(tool mcp.Tool, handler mcpserver.ToolHandlerFunc) {
+		if !opts.ReadOnly {
+			addTool(tool, handler)
+		}
 	}
 
 	// 1. store_conversation - Store a conversation turn in HMLR memory system
-	server.AddTool(mcp.Tool{
+	addWriteTool(mcp.Tool{
 		Name:        "store_conversation",
 		Description: "Store a conversation turn in HMLR memory system. Automatically routes to the correct Bridge Block based on topic matching.",
 		InputSchema: mcp.ToolInputSchema{
@@ -33,21 +108,53 @@ func RegisterTools(server *mcpserver.MCPServer, store *storage.Storage, governor
 			Properties: map[string]interface{}{
 				"message": map[string]interface{}{
 					"type":        "string",
-					"description": "User message to store",
+					"description": "User message to store (required unless messages is given)",
+				},
+				"messages": map[string]interface{}{
+					"type":        "array",
+					"description": "Full transcript for this turn, in order, replacing message and context. Keeps system prompts, assistant tool calls, and tool results; needs at least one user message.",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"role":         map[string]interface{}{"type": "string", "enum": []string{"user", "assistant", "system", "tool"}},
+							"content":      map[string]interface{}{"type": "string"},
+							"name":         map[string]interface{}{"type": "string", "description": "Speaker or tool name"},
+							"tool_calls":   map[string]interface{}{"type": "array", "description": "Tool calls made by an assistant message: {id, name, arguments}", "items": map[string]interface{}{"type": "object"}},
+							"tool_call_id": map[string]interface{}{"type": "string", "description": "ID of the call a tool message answers"},
+						},
+						"required": []string{"role"},
+					},
 				},
 				"context": map[string]interface{}{
 					"type":        "string",
 					"description": "Optional additional context",
 				},
+				"persona": map[string]interface{}{
+					"type":        "string",
+					"description": "Persona (e.g. 'work', 'home') whose preferences and constraints the Scribe should learn; defaults to the server's persona",
+				},
+				"origin": originSchema("Where this turn was said (device, repository, ...), so memories can later be scoped to it. All fields optional."),
+				"attachments": map[string]interface{}{
+					"type":        "array",
+					"description": "Files, URLs, or small text blobs discussed in this turn. Inline content (up to 64KB) is embedded so the topic can be found through it.",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"ref":       map[string]interface{}{"type": "string", "description": "File path or URL; omit for a blob"},
+							"name":      map[string]interface{}{"type": "string", "description": "Display name (defaults to the file name or URL)"},
+							"content":   map[string]interface{}{"type": "string", "description": "Inline text: the blob itself, or the file or page text"},
+							"mime_type": map[string]interface{}{"type": "string", "description": "Optional MIME type, e.g. 'text/markdown'"},
+						},
+					},
+				},
 			},
-			Required: []string{"message"},
 		},
 	}, handlers.StoreConversation)
 
 	// 2. retrieve_memory - Retrieve relevant memories from HMLR system
-	server.AddTool(mcp.Tool{
+	addTool(mcp.Tool{
 		Name:        "retrieve_memory",
-		Description: "Retrieve relevant memories from HMLR system based on semantic search and fact lookup.",
+		Description: "Retrieve relevant memories from HMLR system based on semantic search and fact lookup. Memories found semantically include snippets: the sentences that matched the query.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
@@ -57,8 +164,22 @@ func RegisterTools(server *mcpserver.MCPServer, store *storage.Storage, governor
 				},
 				"max_results": map[string]interface{}{
 					"type":        "number",
-					"description": "Maximum number of results to return (default: 5)",
-					"default":     5,
+					"description": fmt.Sprintf("Maximum number of results to return (default: %d)", opts.DefaultMaxResults),
+					"default":     opts.DefaultMaxResults,
+				},
+				"tags": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Only return topics carrying every one of these tags (e.g., ['project-x'])",
+				},
+				"origin": originSchema("Only return topics with a turn from this origin; every field given must match, and working_dir also matches subdirectories (e.g. {\"git_repo\": \"github.com/acme/api\"}). With an agent, only that agent's facts are returned too"),
+				"affect": map[string]interface{}{
+					"type":        "string",
+					"description": "Only return topics with a turn of this emotional tone: excited, satisfied, frustrated, anxious, confused, curious, or a valence (positive, negative, neutral, mixed) matching every tone under it",
+				},
+				"max_tokens": map[string]interface{}{
+					"type":        "number",
+					"description": "Approximate token budget for the memories and facts returned (default: this client's context preset, if the server has one; otherwise no limit)",
 				},
 			},
 			Required: []string{"query"},
@@ -66,7 +187,7 @@ func RegisterTools(server *mcpserver.MCPServer, store *storage.Storage, governor
 	}, handlers.RetrieveMemory)
 
 	// 3. list_active_topics - List all active Bridge Block topics
-	server.AddTool(mcp.Tool{
+	addTool(mcp.Tool{
 		Name:        "list_active_topics",
 		Description: "List all active Bridge Block topics with their metadata.",
 		InputSchema: mcp.ToolInputSchema{
@@ -76,7 +197,7 @@ func RegisterTools(server *mcpserver.MCPServer, store *storage.Storage, governor
 	}, handlers.ListActiveTopics)
 
 	// 4. get_topic_history - Get conversation history for a specific topic
-	server.AddTool(mcp.Tool{
+	addTool(mcp.Tool{
 		Name:        "get_topic_history",
 		Description: "Get the complete conversation history for a specific Bridge Block topic.",
 		InputSchema: mcp.ToolInputSchema{
@@ -92,19 +213,24 @@ func RegisterTools(server *mcpserver.MCPServer, store *storage.Storage, governor
 	}, handlers.GetTopicHistory)
 
 	// 5. get_user_profile - Get the user profile summary
-	server.AddTool(mcp.Tool{
+	addTool(mcp.Tool{
 		Name:        "get_user_profile",
-		Description: "Get the user profile summary with preferences and topics of interest.",
+		Description: "Get the user profile summary with preferences, topics of interest, and constraints, optionally for a persona such as 'work' or 'home'.",
 		InputSchema: mcp.ToolInputSchema{
-			Type:       "object",
-			Properties: map[string]interface{}{},
+			Type: "object",
+			Properties: map[string]interface{}{
+				"persona": map[string]interface{}{
+					"type":        "string",
+					"description": "Persona whose preferences and constraints are layered over the shared profile; defaults to the server's persona",
+				},
+			},
 		},
 	}, handlers.GetUserProfile)
 
 	// 6. update_user_profile - Update user profile preferences directly
-	server.AddTool(mcp.Tool{
+	addWriteTool(mcp.Tool{
 		Name:        "update_user_profile",
-		Description: "Update user profile with name, preferences, or topics of interest. All fields are optional - only provided fields will be updated.",
+		Description: "Update user profile with name, preferences, topics of interest, or constraints, or remove preferences and topics (for example ones get_profile_diff shows were learned by mistake). All fields are optional - only provided fields will be updated.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
@@ -112,6 +238,10 @@ func RegisterTools(server *mcpserver.MCPServer, store *storage.Storage, governor
 					"type":        "string",
 					"description": "User's name",
 				},
+				"persona": map[string]interface{}{
+					"type":        "string",
+					"description": "Persona to update; preferences and constraints go to it while name and topics stay shared. Defaults to the server's persona",
+				},
 				"preferences": map[string]interface{}{
 					"type":        "array",
 					"items":       map[string]interface{}{"type": "string"},
@@ -122,12 +252,35 @@ func RegisterTools(server *mcpserver.MCPServer, store *storage.Storage, governor
 					"items":       map[string]interface{}{"type": "string"},
 					"description": "Topics the user is interested in (e.g., 'Go programming', 'distributed systems')",
 				},
+				"remove_preferences": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Preferences to remove, exactly as the profile holds them; the persona's are tried before the shared profile's",
+				},
+				"remove_topics_of_interest": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Topics of interest to remove, exactly as the profile holds them",
+				},
+				"constraints": map[string]interface{}{
+					"type": "array",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"type":        map[string]interface{}{"type": "string", "description": "Kind of constraint (e.g., 'Dietary Restriction', 'Allergy')"},
+							"description": map[string]interface{}{"type": "string", "description": "What must be respected (e.g., 'vegetarian, no meat or fish')"},
+							"severity":    map[string]interface{}{"type": "string", "enum": []string{"strict", "moderate", "mild"}},
+						},
+						"required": []string{"type", "description"},
+					},
+					"description": "Constraints answers must respect. A constraint with an existing type and description updates its severity.",
+				},
 			},
 		},
 	}, handlers.UpdateUserProfile)
 
 	// 7. add_fact - Directly add a key-value fact
-	server.AddTool(mcp.Tool{
+	addWriteTool(mcp.Tool{
 		Name:        "add_fact",
 		Description: "Directly add a key-value fact to memory without storing a conversation. Useful for storing API keys, settings, or explicit user data.",
 		InputSchema: mcp.ToolInputSchema{
@@ -141,20 +294,33 @@ func RegisterTools(server *mcpserver.MCPServer, store *storage.Storage, governor
 					"type":        "string",
 					"description": "Fact value",
 				},
+				"value_type": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"string", "number", "date", "boolean", "secret", "json"},
+					"description": "How to interpret the value (default: string). Dates are YYYY-MM-DD or RFC 3339; the value is validated and stored in canonical form.",
+				},
 				"confidence": map[string]interface{}{
 					"type":        "number",
 					"description": "Confidence score 0.0-1.0 (default: 1.0)",
 					"default":     1.0,
 				},
+				"agent": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the agent recording the fact (default: this server's agent_id, or the MCP client's name)",
+				},
+				"quote": map[string]interface{}{
+					"type":        "string",
+					"description": "The words the fact comes from, e.g. what the user said",
+				},
 			},
 			Required: []string{"key", "value"},
 		},
 	}, handlers.AddFact)
 
 	// 8. get_fact - Look up a specific fact by key
-	server.AddTool(mcp.Tool{
+	addTool(mcp.Tool{
 		Name:        "get_fact",
-		Description: "Look up a specific fact by its key. Returns the most recent value if multiple exist.",
+		Description: "Look up a specific fact by its key. Returns the most recent value if multiple exist, or with as_of the value it had at that time.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
@@ -162,13 +328,17 @@ func RegisterTools(server *mcpserver.MCPServer, store *storage.Storage, governor
 					"type":        "string",
 					"description": "Fact key to look up",
 				},
+				"as_of": map[string]interface{}{
+					"type":        "string",
+					"description": "Return the value the fact had at this time instead of now: RFC 3339, 'YYYY-MM-DD HH:MM', or a date for the end of that day (e.g. '2026-03-01'). Deleted facts are not remembered.",
+				},
 			},
 			Required: []string{"key"},
 		},
 	}, handlers.GetFact)
 
 	// 9. delete_fact - Remove a fact by key
-	server.AddTool(mcp.Tool{
+	addWriteTool(mcp.Tool{
 		Name:        "delete_fact",
 		Description: "Delete a fact by its key. Removes all facts with the given key.",
 		InputSchema: mcp.ToolInputSchema{
@@ -184,7 +354,7 @@ func RegisterTools(server *mcpserver.MCPServer, store *storage.Storage, governor
 	}, handlers.DeleteFact)
 
 	// 10. archive_topic - Mark a topic as archived/completed
-	server.AddTool(mcp.Tool{
+	addWriteTool(mcp.Tool{
 		Name:        "archive_topic",
 		Description: "Mark a topic (Bridge Block) as archived/completed. The topic will no longer appear in active topics but its data is preserved.",
 		InputSchema: mcp.ToolInputSchema{
@@ -200,7 +370,7 @@ func RegisterTools(server *mcpserver.MCPServer, store *storage.Storage, governor
 	}, handlers.ArchiveTopic)
 
 	// 11. delete_topic - Permanently delete a topic
-	server.AddTool(mcp.Tool{
+	addWriteTool(mcp.Tool{
 		Name:        "delete_topic",
 		Description: "Permanently delete a topic (Bridge Block) and all its associated data including facts and embeddings. This action cannot be undone.",
 		InputSchema: mcp.ToolInputSchema{
@@ -215,5 +385,296 @@ func RegisterTools(server *mcpserver.MCPServer, store *storage.Storage, governor
 		},
 	}, handlers.DeleteTopic)
 
+	// 12. tag_memory - Add or remove tags on a topic or fact
+	addWriteTool(mcp.Tool{
+		Name:        "tag_memory",
+		Description: "Add or remove tags on a topic (Bridge Block) or a fact. Tags are lowercase labels like 'project-x' that retrieve_memory and exports can filter on.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"block_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Bridge Block ID to tag (give this or fact_key)",
+				},
+				"fact_key": map[string]interface{}{
+					"type":        "string",
+					"description": "Key of the fact to tag; the most recent fact with this key is used (give this or block_id)",
+				},
+				"add": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Tags to add",
+				},
+				"remove": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Tags to remove",
+				},
+			},
+		},
+	}, handlers.TagMemory)
+
+	// 13. get_related_topics - Follow links between topics
+	addTool(mcp.Tool{
+		Name:        "get_related_topics",
+		Description: "List topics (Bridge Blocks) linked to a topic: blocks it continues or was spawned from, blocks it references, and the inverse links. Use it to follow a conversational thread across blocks.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"block_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Bridge Block ID to find related topics for",
+				},
+			},
+			Required: []string{"block_id"},
+		},
+	}, handlers.GetRelatedTopics)
+
+	// 14. health - Report whether the server can do its job
+	addTool(mcp.Tool{
+		Name:        "health",
+		Description: "Check the memory server's health: whether storage is reachable, when LLM calls last succeeded or failed, and how many background jobs are queued, and how many turns still wait on enrichment. Status is ok, degraded, or down, with the problems found.",
+		InputSchema: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: map[string]interface{}{},
+		},
+	}, handlers.Health)
+
+	// 15. exclude_from_recall - Keep a topic or fact without surfacing it
+	addWriteTool(mcp.Tool{
+		Name:        "exclude_from_recall",
+		Description: "Keep a topic (Bridge Block) or fact stored but stop it from being surfaced: retrieve_memory, searches, and hydrated context skip it. Looking it up by block_id or key still works. Pass excluded=false to let it back in.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"block_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Bridge Block ID to exclude (give this or fact_key)",
+				},
+				"fact_key": map[string]interface{}{
+					"type":        "string",
+					"description": "Key of the facts to exclude; every fact saved so far with this key is excluded (give this or block_id)",
+				},
+				"excluded": map[string]interface{}{
+					"type":        "boolean",
+					"description": "false to recall it again (default: true)",
+				},
+			},
+		},
+	}, handlers.ExcludeFromRecall)
+
+	// 16. update_turn - Correct or annotate a stored turn
+	addWriteTool(mcp.Tool{
+		Name:        "update_turn",
+		Description: "Correct a stored turn's user_message or ai_response, or attach an annotation such as \"this was wrong\". Editing the user message replaces the facts extracted from it; edited turns are re-embedded in the background.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"turn_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Turn ID, as returned by store_conversation or get_topic_history",
+				},
+				"user_message": map[string]interface{}{
+					"type":        "string",
+					"description": "Corrected user message",
+				},
+				"ai_response": map[string]interface{}{
+					"type":        "string",
+					"description": "Corrected AI response",
+				},
+				"annotation": map[string]interface{}{
+					"type":        "string",
+					"description": "Note to attach to the turn",
+				},
+			},
+			Required: []string{"turn_id"},
+		},
+	}, handlers.UpdateTurn)
+
+	// 17. archive_topics - Archive every topic matching a filter
+	addWriteTool(mcp.Tool{
+		Name:        "archive_topics",
+		Description: "Archive every topic (Bridge Block) last updated before a time and/or whose label matches a pattern, in one transaction. Topics already archived are skipped. Use dry_run to see what would be archived.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"before": map[string]interface{}{
+					"type":        "string",
+					"description": "Archive topics last updated before this time: YYYY-MM-DD (start of that day), YYYY-MM-DD HH:MM, or RFC 3339",
+				},
+				"label": map[string]interface{}{
+					"type":        "string",
+					"description": "Archive topics whose label matches this pattern, ignoring case; * matches any run of characters, ? one (e.g. 'scratch*')",
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "List the topics that would be archived without archiving them (default: false)",
+				},
+			},
+		},
+	}, handlers.ArchiveTopics)
+
+	// 18. review_facts - Approve or reject facts waiting in the review queue
+	addWriteTool(mcp.Tool{
+		Name:        "review_facts",
+		Description: "With fact_review on, facts extracted from conversations wait in a review queue and are not recalled until approved. Approve or reject pending facts by fact_id; the response lists the facts still pending. Call with no arguments to list them.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"approve": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "IDs of pending facts to approve, so they are recalled from now on",
+				},
+				"reject": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "IDs of pending facts to reject; rejected facts are deleted",
+				},
+				"values": map[string]interface{}{
+					"type":                 "object",
+					"additionalProperties": map[string]interface{}{"type": "string"},
+					"description":          "Corrected values for facts being approved, by fact_id (e.g. {\"fact_01J...\": \"Lisbon\"})",
+				},
+			},
+		},
+	}, handlers.ReviewFacts)
+
+	// 19. get_memory_health - Report what is stored and what needs attention
+	addTool(mcp.Tool{
+		Name:        "get_memory_health",
+		Description: "Check the health of the stored memory: counts of topics, turns, facts, and embeddings; the enrichment backlog (turns and queued jobs); facts waiting for review; whether the one-active-topic invariant holds; fact conflicts between agents; and topics idle long enough to consolidate. Suggestions name the action for each signal, so a supervising agent can decide to repair or consolidate.",
+		InputSchema: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: map[string]interface{}{},
+		},
+	}, handlers.GetMemoryHealth)
+
+	// 20. resume_topic - Make a paused topic the active one
+	addWriteTool(mcp.Tool{
+		Name:        "resume_topic",
+		Description: fmt.Sprintf("Resume a paused topic (Bridge Block), pausing the active one, so the next turns continue it. Give its block_id, or a query to resume the best-matching paused topic. Only topics idle within the resumption window (%s) are resumed, as when store_conversation routes a turn; within_days overrides it.", describeWindow(governor.ResumptionWindow())),
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"block_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Bridge Block ID of the paused topic (give this or query)",
+				},
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "Search query; resumes the most relevant paused topic within the window (give this or block_id)",
+				},
+				"within_days": map[string]interface{}{
+					"type":        "number",
+					"description": "Resume only topics idle at most this many days; 0 allows any age (default: the server's resumption window)",
+				},
+			},
+		},
+	}, handlers.ResumeTopic)
+
+	// 21. rate_memory - Thumbs up or down on a retrieved memory
+	addWriteTool(mcp.Tool{
+		Name:        "rate_memory",
+		Description: "Rate a memory that retrieve_memory returned: \"up\" if it helped answer the query, \"down\" if it was irrelevant or wrong. Topics rated up are kept longer when the database is over its size limit, and rated queries become labeled data for the retrieval benchmark, so pass the query the memory was retrieved for.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"rating": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"up", "down"},
+					"description": "up for a useful memory, down for an unhelpful one",
+				},
+				"block_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Bridge Block ID of the rated memory (give this or fact_id)",
+				},
+				"fact_id": map[string]interface{}{
+					"type":        "string",
+					"description": "ID of the rated fact (give this or block_id)",
+				},
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "The retrieve_memory query that returned the memory",
+				},
+				"note": map[string]interface{}{
+					"type":        "string",
+					"description": "Why the memory helped or did not (optional)",
+				},
+			},
+			Required: []string{"rating"},
+		},
+	}, handlers.RateMemory)
+
+	// 22. get_profile_diff - What was learned about the user over a time range
+	addTool(mcp.Tool{
+		Name:        "get_profile_diff",
+		Description: "Audit how the user profile evolved: the preferences, topics of interest, constraints, and name added to or removed from the shared profile (and the persona) over a time range, oldest first. Changes the Scribe learned from conversation carry the turn_ids that triggered them; direct edits carry none. Revert an unwanted learning with update_user_profile's remove_preferences or remove_topics_of_interest.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"since": map[string]interface{}{
+					"type":        "string",
+					"description": "Only changes after this time: RFC 3339, 'YYYY-MM-DD HH:MM', or a date for the start of that day (default: all history)",
+				},
+				"until": map[string]interface{}{
+					"type":        "string",
+					"description": "Only changes up to this time, in the same formats; a date means the end of that day (default: now)",
+				},
+				"persona": map[string]interface{}{
+					"type":        "string",
+					"description": "Persona whose changes are listed with the shared profile's; defaults to the server's persona",
+				},
+			},
+		},
+	}, handlers.GetProfileDiff)
+
 	return handlers
 }
+
+// describeWindow renders a resumption window for tool descriptions
+func describeWindow(window time.Duration) string {
+	if window <= 0 {
+		return "any age"
+	}
+	return fmt.Sprintf("%g days", window.Hours()/24)
+}
+
+// originSchema describes the origin object taken by store_conversation and retrieve_memory
+func originSchema(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "object",
+		"description": description,
+		"properties": map[string]interface{}{
+			"device":      map[string]interface{}{"type": "string", "description": "Device name, e.g. 'work-laptop'"},
+			"hostname":    map[string]interface{}{"type": "string", "description": "Machine hostname"},
+			"working_dir": map[string]interface{}{"type": "string", "description": "Absolute working directory"},
+			"git_repo":    map[string]interface{}{"type": "string", "description": "Git remote URL, or the repository root when it has no remote"},
+			"location":    map[string]interface{}{"type": "string", "description": "Free-form place, e.g. 'Chicago'"},
+			"agent":       map[string]interface{}{"type": "string", "description": "MCP client or agent that wrote the turn, e.g. 'claude-code' (stored turns default to this server's agent_id or the client's name)"},
+		},
+	}
+}
+
+// redactResult masks credentials in the text of a tool result
+func redactResult(result *mcp.CallToolResult) {
+	if result == nil {
+		return
+	}
+	for i, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			text.Text = redact.String(text.Text)
+			result.Content[i] = text
+		}
+	}
+}
+
+// toolError returns the text of a tool error result as an error
+func toolError(result *mcp.CallToolResult) error {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return errors.New(text.Text)
+		}
+	}
+	return errors.New("tool call failed")
+}
@@ -0,0 +1,135 @@
+// ABOUTME: Affect labels for the emotional tone of conversation turns
+// ABOUTME: Specific labels like "frustrated" roll up to a valence so filters can be broad or narrow
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Affect is the emotional tone of a turn
+type Affect string
+
+// The four valences, which every affect rolls up to
+const (
+	AffectPositive Affect = "positive"
+	AffectNegative Affect = "negative"
+	AffectNeutral  Affect = "neutral"
+	AffectMixed    Affect = "mixed"
+)
+
+// Specific affects the metadata extractor may return
+const (
+	AffectExcited    Affect = "excited"
+	AffectSatisfied  Affect = "satisfied"
+	AffectFrustrated Affect = "frustrated"
+	AffectAnxious    Affect = "anxious"
+	AffectConfused   Affect = "confused"
+	AffectCurious    Affect = "curious"
+)
+
+// affectValence maps every known affect to its valence
+var affectValence = map[Affect]Affect{
+	AffectPositive:   AffectPositive,
+	AffectNegative:   AffectNegative,
+	AffectNeutral:    AffectNeutral,
+	AffectMixed:      AffectMixed,
+	AffectExcited:    AffectPositive,
+	AffectSatisfied:  AffectPositive,
+	AffectFrustrated: AffectNegative,
+	AffectAnxious:    AffectNegative,
+	AffectConfused:   AffectNegative,
+	AffectCurious:    AffectNeutral,
+}
+
+// Affects lists every known affect, valences first
+var Affects = []Affect{
+	AffectPositive, AffectNegative, AffectNeutral, AffectMixed,
+	AffectExcited, AffectSatisfied, AffectFrustrated, AffectAnxious, AffectConfused, AffectCurious,
+}
+
+// ParseAffect lowercases and validates an affect label; empty means unknown and is allowed
+func ParseAffect(s string) (Affect, error) {
+	a := Affect(strings.ToLower(strings.TrimSpace(s)))
+	if a == "" {
+		return "", nil
+	}
+	if _, ok := affectValence[a]; !ok {
+		return "", fmt.Errorf("unknown affect %q", s)
+	}
+	return a, nil
+}
+
+// Valence returns the positive, negative, neutral, or mixed tone the affect belongs to
+func (a Affect) Valence() Affect {
+	return affectValence[a]
+}
+
+// IsValence reports whether a is one of the four valences rather than a specific affect
+func (a Affect) IsValence() bool {
+	return a != "" && affectValence[a] == a
+}
+
+// Matches reports whether a satisfies filter: a valence filter matches every affect with
+// that valence, a specific filter only itself
+func (a Affect) Matches(filter Affect) bool {
+	if filter.IsValence() {
+		return a.Valence() == filter
+	}
+	return a == filter
+}
+
+// AffectsMatching returns every affect that satisfies filter
+func AffectsMatching(filter Affect) []Affect {
+	var matching []Affect
+	for _, a := range Affects {
+		if a.Matches(filter) {
+			matching = append(matching, a)
+		}
+	}
+	return matching
+}
+
+// AggregateAffect counts the affects of turns, ignoring turns without one, and picks
+// the dominant affect with DominantAffect. No labelled turns gives "" and nil.
+func AggregateAffect(turns []Turn) (Affect, map[Affect]int) {
+	var counts map[Affect]int
+	for _, turn := range turns {
+		if turn.Affect == "" {
+			continue
+		}
+		if counts == nil {
+			counts = make(map[Affect]int)
+		}
+		counts[turn.Affect]++
+	}
+	return DominantAffect(counts), counts
+}
+
+// DominantAffect picks the most common affect, or when several tie, their shared
+// valence (mixed if they disagree). Empty counts give "".
+func DominantAffect(counts map[Affect]int) Affect {
+	best := 0
+	var leaders []Affect
+	for _, a := range Affects {
+		switch n := counts[a]; {
+		case n > best:
+			best, leaders = n, []Affect{a}
+		case n == best && n > 0:
+			leaders = append(leaders, a)
+		}
+	}
+	if len(leaders) == 0 {
+		return ""
+	}
+	if len(leaders) == 1 {
+		return leaders[0]
+	}
+	valence := leaders[0].Valence()
+	for _, a := range leaders[1:] {
+		if a.Valence() != valence {
+			return AffectMixed
+		}
+	}
+	return valence
+}
@@ -0,0 +1,84 @@
+// ABOUTME: Tests for turn affect labels
+// ABOUTME: Verifies parsing, valence matching, and per-block aggregation
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAffect(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Affect
+		wantErr bool
+	}{
+		{"frustrated", AffectFrustrated, false},
+		{" Negative ", AffectNegative, false},
+		{"", "", false},
+		{"grumpy", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAffect(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAffect(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAffect(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAffect_Matches(t *testing.T) {
+	if !AffectFrustrated.Matches(AffectNegative) {
+		t.Error("frustrated should match the negative valence")
+	}
+	if AffectFrustrated.Matches(AffectAnxious) {
+		t.Error("frustrated should not match anxious")
+	}
+	if AffectNegative.Matches(AffectFrustrated) {
+		t.Error("a plain negative turn should not match the specific frustrated filter")
+	}
+
+	want := []Affect{AffectPositive, AffectExcited, AffectSatisfied}
+	if got := AffectsMatching(AffectPositive); !reflect.DeepEqual(got, want) {
+		t.Errorf("AffectsMatching(positive) = %v, want %v", got, want)
+	}
+}
+
+func TestAggregateAffect(t *testing.T) {
+	turns := []Turn{
+		{Affect: AffectFrustrated},
+		{Affect: AffectFrustrated},
+		{Affect: AffectCurious},
+		{},
+	}
+	dominant, counts := AggregateAffect(turns)
+	if dominant != AffectFrustrated {
+		t.Errorf("dominant = %q, want frustrated", dominant)
+	}
+	if want := map[Affect]int{AffectFrustrated: 2, AffectCurious: 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+
+	if dominant, counts := AggregateAffect([]Turn{{}}); dominant != "" || counts != nil {
+		t.Errorf("AggregateAffect(unlabelled) = %q, %v; want empty", dominant, counts)
+	}
+}
+
+func TestDominantAffect_Ties(t *testing.T) {
+	tests := []struct {
+		counts map[Affect]int
+		want   Affect
+	}{
+		{map[Affect]int{AffectFrustrated: 1, AffectAnxious: 1}, AffectNegative},
+		{map[Affect]int{AffectFrustrated: 1, AffectExcited: 1}, AffectMixed},
+		{map[Affect]int{}, ""},
+	}
+	for _, tt := range tests {
+		if got := DominantAffect(tt.counts); got != tt.want {
+			t.Errorf("DominantAffect(%v) = %q, want %q", tt.counts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// ABOUTME: Parses the timestamps time-travel queries and time filters take ('memory log --as-of', get_fact as_of, --before)
+// ABOUTME: Accepts RFC 3339, a date and time in the configured zone, or a date meaning the end (or, for before, the start) of that day
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// asOfLayouts are the accepted as-of formats without a zone, read in the configured zone
+var asOfLayouts = []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02 15:04"}
+
+// ParseAsOf parses an as-of timestamp: RFC 3339, a date and time without a zone read in
+// loc, or a bare date, which means the end of that day in loc so that everything
+// recorded that day counts
+func ParseAsOf(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if loc == nil {
+		loc = time.Local
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range asOfLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	if day, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid as-of time %q (use YYYY-MM-DD, YYYY-MM-DD HH:MM, or RFC 3339)", value)
+}
+
+// ParseBefore parses the cutoff of a "before" filter in the formats ParseAsOf takes,
+// except that a bare date means the start of that day, so nothing from that day matches
+func ParseBefore(value string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	if day, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(value), loc); err == nil {
+		return day, nil
+	}
+	t, err := ParseAsOf(value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q (use YYYY-MM-DD, YYYY-MM-DD HH:MM, or RFC 3339)", value)
+	}
+	return t, nil
+}
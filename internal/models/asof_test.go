@@ -0,0 +1,57 @@
+// ABOUTME: Tests for parsing as-of timestamps and before cutoffs
+// ABOUTME: Covers RFC 3339, zone-less times in the configured zone, bare dates, and bad input
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAsOf(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("no zone data: %v", err)
+	}
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2026-03-01T14:30:00Z", time.Date(2026, 3, 1, 14, 30, 0, 0, time.UTC)},
+		{"2026-03-01T14:30:00-08:00", time.Date(2026, 3, 1, 22, 30, 0, 0, time.UTC)},
+		{"2026-03-01 14:30", time.Date(2026, 3, 1, 14, 30, 0, 0, tokyo)},
+		{"2026-03-01T14:30:15", time.Date(2026, 3, 1, 14, 30, 15, 0, tokyo)},
+		{" 2026-03-01 ", time.Date(2026, 3, 2, 0, 0, 0, 0, tokyo).Add(-time.Nanosecond)},
+	}
+	for _, tt := range tests {
+		got, err := ParseAsOf(tt.value, tokyo)
+		if err != nil {
+			t.Errorf("ParseAsOf(%q) error = %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseAsOf(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "yesterday", "03/01/2026", "2026-13-01"} {
+		if _, err := ParseAsOf(value, tokyo); err == nil {
+			t.Errorf("ParseAsOf(%q) should fail", value)
+		}
+	}
+}
+
+func TestParseBefore(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("no zone data: %v", err)
+	}
+	if got, err := ParseBefore(" 2024-01-01 ", tokyo); err != nil || !got.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, tokyo)) {
+		t.Errorf("ParseBefore(date) = %v, %v; want the start of the day", got, err)
+	}
+	if got, err := ParseBefore("2024-01-01 09:30", tokyo); err != nil || !got.Equal(time.Date(2024, 1, 1, 9, 30, 0, 0, tokyo)) {
+		t.Errorf("ParseBefore(date and time) = %v, %v", got, err)
+	}
+	if _, err := ParseBefore("last year", tokyo); err == nil {
+		t.Error("ParseBefore(\"last year\") should fail")
+	}
+}
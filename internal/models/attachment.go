@@ -0,0 +1,118 @@
+// ABOUTME: Attachment model for files, URLs, and small text blobs tied to a turn
+// ABOUTME: Attachment text is embedded with the turn's block so it is found by search
+package models
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// AttachmentKind says what an attachment points at
+type AttachmentKind string
+
+const (
+	AttachmentFile AttachmentKind = "file" // Ref is a local file path
+	AttachmentURL  AttachmentKind = "url"  // Ref is a URL
+	AttachmentBlob AttachmentKind = "blob" // No Ref; Content is the attachment
+)
+
+// MaxAttachmentContent caps the inline text stored (and embedded) per attachment
+const MaxAttachmentContent = 64 * 1024
+
+// IsValid checks if the AttachmentKind is a valid value
+func (k AttachmentKind) IsValid() bool {
+	return k == AttachmentFile || k == AttachmentURL || k == AttachmentBlob
+}
+
+// Attachment is a file, URL, or small blob associated with a turn
+type Attachment struct {
+	AttachmentID string         `json:"attachment_id"`
+	TurnID       string         `json:"turn_id"`
+	BlockID      string         `json:"block_id,omitempty"` // Block holding the turn; filled in on read
+	Kind         AttachmentKind `json:"kind"`
+	Name         string         `json:"name"`                // Display name, e.g. "design-doc.md"
+	Ref          string         `json:"ref,omitempty"`       // File path or URL; empty for blobs
+	MimeType     string         `json:"mime_type,omitempty"` // e.g. "text/markdown"; optional
+	Content      string         `json:"content,omitempty"`   // Inline text, chunked and embedded when set
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// NewAttachment builds an attachment for ref (a path or URL) or, with an empty ref, a blob of content.
+// The kind is inferred from ref and the name defaults to its last path element.
+func NewAttachment(ref, name, content string) (*Attachment, error) {
+	a := &Attachment{
+		AttachmentID: generateAttachmentID(),
+		Kind:         InferAttachmentKind(ref),
+		Name:         strings.TrimSpace(name),
+		Ref:          strings.TrimSpace(ref),
+		Content:      content,
+		CreatedAt:    Now(),
+	}
+	if a.Name == "" {
+		a.Name = defaultAttachmentName(a.Kind, a.Ref)
+	}
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// InferAttachmentKind returns url for http(s) references, blob for an empty one, and file otherwise
+func InferAttachmentKind(ref string) AttachmentKind {
+	ref = strings.TrimSpace(ref)
+	lower := strings.ToLower(ref)
+	switch {
+	case ref == "":
+		return AttachmentBlob
+	case strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://"):
+		return AttachmentURL
+	default:
+		return AttachmentFile
+	}
+}
+
+// defaultAttachmentName picks a display name from the reference
+func defaultAttachmentName(kind AttachmentKind, ref string) string {
+	switch kind {
+	case AttachmentFile:
+		return filepath.Base(ref)
+	case AttachmentURL:
+		return ref
+	default:
+		return "snippet"
+	}
+}
+
+// Validate checks that the attachment is well formed
+func (a *Attachment) Validate() error {
+	if !a.Kind.IsValid() {
+		return fmt.Errorf("invalid attachment kind %q (want file, url, or blob)", a.Kind)
+	}
+	if a.Kind == AttachmentBlob {
+		if a.Content == "" {
+			return errors.New("blob attachment needs content")
+		}
+	} else if a.Ref == "" {
+		return fmt.Errorf("%s attachment needs a reference", a.Kind)
+	}
+	if len(a.Content) > MaxAttachmentContent {
+		return fmt.Errorf("attachment content is %d bytes, limit is %d", len(a.Content), MaxAttachmentContent)
+	}
+	return nil
+}
+
+// String renders the attachment as "name (ref)" or just the name for blobs
+func (a Attachment) String() string {
+	if a.Ref == "" || a.Ref == a.Name {
+		return a.Name
+	}
+	return fmt.Sprintf("%s (%s)", a.Name, a.Ref)
+}
+
+// generateAttachmentID creates a unique attachment ID
+func generateAttachmentID() string {
+	return NewID("att")
+}
@@ -0,0 +1,51 @@
+// ABOUTME: Tests for turn attachments
+// ABOUTME: Verifies kind inference, default names, and validation limits
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewAttachment(t *testing.T) {
+	tests := []struct {
+		ref, name, content string
+		wantKind           AttachmentKind
+		wantName           string
+		wantErr            bool
+	}{
+		{"docs/design.md", "", "", AttachmentFile, "design.md", false},
+		{"https://example.com/spec", "", "", AttachmentURL, "https://example.com/spec", false},
+		{"HTTP://example.com", "Spec", "", AttachmentURL, "Spec", false},
+		{"", "", "some pasted text", AttachmentBlob, "snippet", false},
+		{"", "", "", "", "", true},
+		{"notes.txt", "", strings.Repeat("x", MaxAttachmentContent+1), "", "", true},
+	}
+	for _, tt := range tests {
+		a, err := NewAttachment(tt.ref, tt.name, tt.content)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewAttachment(%q, %q) error = %v, wantErr %v", tt.ref, tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if a.Kind != tt.wantKind || a.Name != tt.wantName {
+			t.Errorf("NewAttachment(%q, %q) = %s %q, want %s %q", tt.ref, tt.name, a.Kind, a.Name, tt.wantKind, tt.wantName)
+		}
+		if !strings.HasPrefix(a.AttachmentID, "att_") {
+			t.Errorf("AttachmentID = %q, want att_ prefix", a.AttachmentID)
+		}
+	}
+}
+
+func TestAttachment_String(t *testing.T) {
+	a, _ := NewAttachment("docs/design.md", "", "")
+	if got := a.String(); got != "design.md (docs/design.md)" {
+		t.Errorf("String() = %q", got)
+	}
+	u, _ := NewAttachment("https://example.com", "", "")
+	if got := u.String(); got != "https://example.com" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -19,16 +19,21 @@ const (
 
 // BridgeBlock represents a topic-based conversation thread
 type BridgeBlock struct {
-	BlockID     string            `json:"block_id"`
-	DayID       string            `json:"day_id"`
-	TopicLabel  string            `json:"topic_label"`
-	Keywords    []string          `json:"keywords"`
-	Status      BridgeBlockStatus `json:"status"`
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
-	Turns       []Turn            `json:"turns"`
-	Summary     string            `json:"summary,omitempty"`
-	TurnCount   int               `json:"turn_count"`
+	BlockID           string            `json:"block_id"`
+	DayID             string            `json:"day_id"`
+	TopicLabel        string            `json:"topic_label"`
+	Keywords          []string          `json:"keywords"`
+	Status            BridgeBlockStatus `json:"status"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+	Turns             []Turn            `json:"turns"`
+	Summary           string            `json:"summary,omitempty"`
+	TurnCount         int               `json:"turn_count"`
+	Tags              []string          `json:"tags,omitempty"`
+	Affect            Affect            `json:"affect,omitempty"`              // Dominant affect of the turns; see AggregateAffect
+	AffectCounts      map[Affect]int    `json:"affect_counts,omitempty"`       // Turns per affect
+	ExcludeFromRecall bool              `json:"exclude_from_recall,omitempty"` // Kept, but never returned by searches or hydrated into context
+	Retention         string            `json:"retention,omitempty"`           // The block's own retention policy, e.g. "keep" or "30d"; see ParseRetention
 }
 
 // Validate checks if the BridgeBlock has valid data
@@ -50,5 +55,5 @@ func (b *BridgeBlock) Validate() error {
 func (b *BridgeBlock) AddTurn(turn Turn) {
 	b.Turns = append(b.Turns, turn)
 	b.TurnCount = len(b.Turns)
-	b.UpdatedAt = time.Now().UTC()
+	b.UpdatedAt = Now().UTC()
 }
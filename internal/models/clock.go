@@ -0,0 +1,78 @@
+// ABOUTME: Clock is the source of "now" for timestamps, decay, retention, and routing windows
+// ABOUTME: A SimulatedClock lets tests and benchmarks step through days of conversation instantly
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the wall clock
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock is the wall clock, the default everywhere
+var SystemClock Clock = systemClock{}
+
+// SimulatedClock is a clock that only moves when told to. It is safe for concurrent use.
+type SimulatedClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewSimulatedClock returns a clock stopped at start
+func NewSimulatedClock(start time.Time) *SimulatedClock {
+	return &SimulatedClock{now: start}
+}
+
+// Now returns the simulated time
+func (c *SimulatedClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d and returns the new time
+func (c *SimulatedClock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
+
+// Set moves the clock to t, which may be earlier than its current time
+func (c *SimulatedClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// clock is what Now reads; constructors such as NewTurn and NewFact stamp with it
+var clock struct {
+	sync.RWMutex
+	c Clock
+}
+
+// SetClock makes c the clock models stamp new entities with, and storage and routing
+// fall back to; nil restores the wall clock. Tests that set it should restore it.
+func SetClock(c Clock) {
+	clock.Lock()
+	defer clock.Unlock()
+	clock.c = c
+}
+
+// Now returns the time on the clock set with SetClock, else the wall clock
+func Now() time.Time {
+	clock.RLock()
+	defer clock.RUnlock()
+	if clock.c == nil {
+		return time.Now()
+	}
+	return clock.c.Now()
+}
@@ -0,0 +1,45 @@
+// ABOUTME: Tests for the clock models stamp new entities with
+// ABOUTME: Verifies simulated clocks move only when told to and that SetClock reaches constructors
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSimulatedClock(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	clock := NewSimulatedClock(start)
+	if !clock.Now().Equal(start) {
+		t.Errorf("Now() = %v, want %v", clock.Now(), start)
+	}
+	if got := clock.Advance(48 * time.Hour); !got.Equal(start.Add(48 * time.Hour)) {
+		t.Errorf("Advance(48h) = %v, want %v", got, start.Add(48*time.Hour))
+	}
+	clock.Set(start)
+	if !clock.Now().Equal(start) {
+		t.Errorf("Now() after Set() = %v, want %v", clock.Now(), start)
+	}
+}
+
+func TestSetClock(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	SetClock(NewSimulatedClock(start))
+	defer SetClock(nil)
+
+	turn, err := NewTurn("hello", "hi", nil, nil)
+	if err != nil {
+		t.Fatalf("NewTurn() error = %v", err)
+	}
+	if !turn.Timestamp.Equal(start) {
+		t.Errorf("NewTurn() timestamp = %v, want the simulated %v", turn.Timestamp, start)
+	}
+	if at, ok := IDTime(NewID("turn")); !ok || !at.Equal(start) {
+		t.Errorf("IDTime(NewID()) = %v, %v; want %v", at, ok, start)
+	}
+
+	SetClock(nil)
+	if since := time.Since(Now()); since < 0 || since > time.Minute {
+		t.Errorf("Now() after SetClock(nil) is %v from the wall clock", since)
+	}
+}
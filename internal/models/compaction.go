@@ -0,0 +1,52 @@
+// ABOUTME: Compaction of old archived topics: turn bodies replaced by the topic's summary and facts
+// ABOUTME: A policy decides whether the original bodies are kept compressed or dropped
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CompactPolicy is what compaction does with the turn bodies it replaces
+type CompactPolicy string
+
+const (
+	CompactCompress CompactPolicy = "compress" // Keep a compressed copy the turns can be restored from
+	CompactDrop     CompactPolicy = "drop"     // Discard the bodies for good
+)
+
+// ParseCompactPolicy parses "compress" or "drop"; "" parses to compress
+func ParseCompactPolicy(v string) (CompactPolicy, error) {
+	switch CompactPolicy(strings.ToLower(strings.TrimSpace(v))) {
+	case "", CompactCompress:
+		return CompactCompress, nil
+	case CompactDrop:
+		return CompactDrop, nil
+	}
+	return "", fmt.Errorf("invalid compact policy %q: must be compress or drop", v)
+}
+
+// CompactionDigest is the text a compacted topic's first turn holds in place of the
+// conversation: the summary (or the label, without one) and the topic's facts. Secret
+// facts are left out, since turn text is exported and sent to the LLM.
+func CompactionDigest(label, summary string, turns int, facts []Fact) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Compacted %d turns] ", turns)
+	if summary = strings.TrimSpace(summary); summary != "" {
+		b.WriteString(summary)
+	} else {
+		b.WriteString(label)
+	}
+	var listed bool
+	for _, fact := range facts {
+		if fact.ValueType == ValueTypeSecret {
+			continue
+		}
+		if !listed {
+			b.WriteString("\n\nFacts:")
+			listed = true
+		}
+		fmt.Fprintf(&b, "\n- %s: %s", fact.Key, fact.Value)
+	}
+	return b.String()
+}
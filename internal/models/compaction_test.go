@@ -0,0 +1,48 @@
+// ABOUTME: Tests for archive compaction policies and digests
+// ABOUTME: Verifies policy parsing and that digests carry the summary and non-secret facts
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCompactPolicy(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    CompactPolicy
+		wantErr bool
+	}{
+		{"", CompactCompress, false},
+		{"compress", CompactCompress, false},
+		{" DROP ", CompactDrop, false},
+		{"delete", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCompactPolicy(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCompactPolicy(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCompactPolicy(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompactionDigest(t *testing.T) {
+	facts := []Fact{
+		{Key: "compost_ratio", Value: "3:1"},
+		{Key: "shed_code", Value: "4821", ValueType: ValueTypeSecret},
+	}
+	got := CompactionDigest("Garden", "Planned the spring beds.", 4, facts)
+	want := "[Compacted 4 turns] Planned the spring beds.\n\nFacts:\n- compost_ratio: 3:1"
+	if got != want {
+		t.Errorf("CompactionDigest() = %q, want %q", got, want)
+	}
+
+	got = CompactionDigest("Garden", " ", 1, facts[1:])
+	if got != "[Compacted 1 turns] Garden" || strings.Contains(got, "4821") {
+		t.Errorf("CompactionDigest() without summary = %q, want the label and no secret", got)
+	}
+}
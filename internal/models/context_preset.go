@@ -0,0 +1,115 @@
+// ABOUTME: Context presets size and weight the memory an MCP client gets, chosen by the client's name
+// ABOUTME: E.g. an 8k-token budget heavy on facts for a coding agent, 2k heavy on history for a chat client
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ContextEmphasis says which part of recalled memory a preset favors when the budget is tight
+type ContextEmphasis string
+
+const (
+	EmphasisBalanced ContextEmphasis = "balanced" // Facts and conversation history share the budget evenly
+	EmphasisFacts    ContextEmphasis = "facts"    // Most of the budget goes to facts
+	EmphasisHistory  ContextEmphasis = "history"  // Most of the budget goes to conversation history
+)
+
+// AnyClient is the preset name that matches clients no other preset names
+const AnyClient = "*"
+
+// ContextPreset is the context budget for MCP clients with one name
+type ContextPreset struct {
+	Client    string          `json:"client"`     // MCP client name from initialize, e.g. "claude-code", or AnyClient
+	MaxTokens int             `json:"max_tokens"` // Budget for what retrieve_memory returns
+	Emphasis  ContextEmphasis `json:"emphasis"`
+}
+
+// FactShare is the fraction of the budget set aside for facts; history gets the rest,
+// and either side's unused budget goes to the other
+func (p ContextPreset) FactShare() float64 {
+	switch p.Emphasis {
+	case EmphasisFacts:
+		return 0.7
+	case EmphasisHistory:
+		return 0.3
+	}
+	return 0.5
+}
+
+// String renders the preset the way ParseContextPresets reads it
+func (p ContextPreset) String() string {
+	return fmt.Sprintf("%s=%d:%s", p.Client, p.MaxTokens, p.Emphasis)
+}
+
+// ContextPresets are the presets a server applies, at most one per client name
+type ContextPresets []ContextPreset
+
+// ForClient returns the preset for the client named name, ignoring case, else the
+// AnyClient preset, else false
+func (ps ContextPresets) ForClient(name string) (ContextPreset, bool) {
+	var fallback *ContextPreset
+	for i := range ps {
+		switch {
+		case strings.EqualFold(ps[i].Client, name):
+			return ps[i], true
+		case ps[i].Client == AnyClient:
+			fallback = &ps[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return ContextPreset{}, false
+}
+
+// String renders the presets the way ParseContextPresets reads them
+func (ps ContextPresets) String() string {
+	parts := make([]string, len(ps))
+	for i, p := range ps {
+		parts[i] = p.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ParseContextPresets parses "client=tokens[:emphasis]" entries separated by semicolons,
+// such as "claude-code=8000:facts; claude-ai=2000:history; *=4000". The emphasis is
+// facts, history, or balanced (the default).
+func ParseContextPresets(v string) (ContextPresets, error) {
+	var presets ContextPresets
+	seen := map[string]bool{}
+	for _, item := range strings.Split(v, ";") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		client, spec, ok := strings.Cut(item, "=")
+		client = strings.ToLower(strings.TrimSpace(client))
+		if !ok || client == "" {
+			return nil, fmt.Errorf("context preset %q must be client=tokens[:emphasis], e.g. claude-code=8000:facts", item)
+		}
+		if seen[client] {
+			return nil, fmt.Errorf("context presets list %s twice", client)
+		}
+		seen[client] = true
+
+		tokens, emphasis, _ := strings.Cut(spec, ":")
+		preset := ContextPreset{Client: client, Emphasis: EmphasisBalanced}
+		n, err := strconv.Atoi(strings.TrimSpace(tokens))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("context preset for %s: token budget %q must be a positive number", client, strings.TrimSpace(tokens))
+		}
+		preset.MaxTokens = n
+		if e := ContextEmphasis(strings.ToLower(strings.TrimSpace(emphasis))); e != "" {
+			switch e {
+			case EmphasisBalanced, EmphasisFacts, EmphasisHistory:
+				preset.Emphasis = e
+			default:
+				return nil, fmt.Errorf("context preset for %s: invalid emphasis %q (must be facts, history, or balanced)", client, e)
+			}
+		}
+		presets = append(presets, preset)
+	}
+	return presets, nil
+}
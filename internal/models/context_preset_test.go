@@ -0,0 +1,49 @@
+// ABOUTME: Tests for per-client context presets
+// ABOUTME: Verifies parsing, rendering, matching a client name, and the fact share of each emphasis
+package models
+
+import "testing"
+
+func TestParseContextPresets(t *testing.T) {
+	presets, err := ParseContextPresets(" Claude-Code=8000:facts; claude-ai=2000:History ;*=4000; ")
+	if err != nil {
+		t.Fatalf("ParseContextPresets: %v", err)
+	}
+	want := "claude-code=8000:facts; claude-ai=2000:history; *=4000:balanced"
+	if presets.String() != want {
+		t.Errorf("ParseContextPresets = %q, want %q", presets.String(), want)
+	}
+	if empty, err := ParseContextPresets(""); err != nil || len(empty) != 0 {
+		t.Errorf("ParseContextPresets(\"\") = %v, %v; want none", empty, err)
+	}
+
+	for _, bad := range []string{"claude-code", "=8000", "claude-code=lots", "claude-code=0", "claude-code=8000:everything", "a=1; A=2"} {
+		if _, err := ParseContextPresets(bad); err == nil {
+			t.Errorf("ParseContextPresets(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestContextPresets_ForClient(t *testing.T) {
+	presets, err := ParseContextPresets("*=4000; claude-code=8000:facts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := presets.ForClient("Claude-Code"); !ok || p.MaxTokens != 8000 || p.Emphasis != EmphasisFacts {
+		t.Errorf("ForClient(Claude-Code) = %+v, %v; want its own preset", p, ok)
+	}
+	if p, ok := presets.ForClient("cursor"); !ok || p.Client != AnyClient || p.MaxTokens != 4000 {
+		t.Errorf("ForClient(cursor) = %+v, %v; want the * preset", p, ok)
+	}
+	if _, ok := presets[1:].ForClient("cursor"); ok {
+		t.Error("ForClient without a * preset matched an unnamed client")
+	}
+}
+
+func TestContextPreset_FactShare(t *testing.T) {
+	for emphasis, want := range map[ContextEmphasis]float64{EmphasisFacts: 0.7, EmphasisHistory: 0.3, EmphasisBalanced: 0.5} {
+		if got := (ContextPreset{Emphasis: emphasis}).FactShare(); got != want {
+			t.Errorf("FactShare(%s) = %v, want %v", emphasis, got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// ABOUTME: Event model for memory changes that webhooks and other subscribers react to
+// ABOUTME: Events carry IDs, keys, and fact values, but never the value of a secret fact
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EventType names a kind of memory change
+type EventType string
+
+const (
+	EventTurnStored       EventType = "turn_stored"       // A turn was stored in a topic
+	EventFactAdded        EventType = "fact_added"        // A fact was saved
+	EventFactSuperseded   EventType = "fact_superseded"   // A fact replaced an earlier value for the same key
+	EventTopicArchived    EventType = "topic_archived"    // A topic was archived
+	EventConflictDetected EventType = "conflict_detected" // A fact replaced a value for its key recorded by a different agent
+	EventTopicUpdated     EventType = "topic_updated"     // A topic was paused, resumed, or closed, or split in two
+	EventTopicDeleted     EventType = "topic_deleted"     // A topic was deleted
+	EventProfileUpdated   EventType = "profile_updated"   // The shared profile or a persona was saved or deleted
+)
+
+// eventTypes lists every event type in documentation order
+var eventTypes = []EventType{EventTurnStored, EventFactAdded, EventFactSuperseded, EventTopicArchived, EventConflictDetected,
+	EventTopicUpdated, EventTopicDeleted, EventProfileUpdated}
+
+// EventTypes returns every event type
+func EventTypes() []EventType {
+	return append([]EventType(nil), eventTypes...)
+}
+
+// ParseEventType validates an event type name
+func ParseEventType(s string) (EventType, error) {
+	for _, t := range eventTypes {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	names := make([]string, len(eventTypes))
+	for i, t := range eventTypes {
+		names[i] = string(t)
+	}
+	return "", fmt.Errorf("unknown event type %q (want %s)", s, strings.Join(names, ", "))
+}
+
+// Event describes one memory change
+type Event struct {
+	ID       string     `json:"id"`
+	Type     EventType  `json:"type"`
+	Time     time.Time  `json:"time"`
+	BlockID  string     `json:"block_id,omitempty"`
+	TurnID   string     `json:"turn_id,omitempty"`
+	Status   string     `json:"status,omitempty"`   // The topic's new status, for topic events
+	Persona  string     `json:"persona,omitempty"`  // The persona updated; empty for the shared profile
+	Fact     *EventFact `json:"fact,omitempty"`     // The fact added, or the one replacing Previous
+	Previous *EventFact `json:"previous,omitempty"` // The fact superseded or contradicted
+}
+
+// EventFact is the part of a fact an event carries
+type EventFact struct {
+	FactID    string        `json:"fact_id"`
+	Key       string        `json:"key"`
+	Value     string        `json:"value,omitempty"` // Empty for secrets
+	ValueType FactValueType `json:"value_type,omitempty"`
+	Agent     string        `json:"agent,omitempty"` // Who recorded the fact (Fact.ExtractedBy)
+}
+
+// NewEvent creates an event of type t happening now
+func NewEvent(t EventType) Event {
+	return Event{
+		ID:   NewID("evt"),
+		Type: t,
+		Time: Now().UTC(),
+	}
+}
+
+// NewEventFact describes f for an event, leaving out the value of a secret
+func NewEventFact(f *Fact) *EventFact {
+	ef := &EventFact{FactID: f.FactID, Key: f.Key, Value: f.Value, ValueType: f.ValueType, Agent: f.ExtractedBy}
+	if f.ValueType == ValueTypeSecret {
+		ef.Value = ""
+	}
+	return ef
+}
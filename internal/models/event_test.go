@@ -0,0 +1,36 @@
+// ABOUTME: Tests for memory events
+// ABOUTME: Verifies event type parsing and that secret fact values stay out of events
+package models
+
+import "testing"
+
+func TestParseEventType(t *testing.T) {
+	for _, want := range EventTypes() {
+		got, err := ParseEventType(string(want))
+		if err != nil || got != want {
+			t.Errorf("ParseEventType(%q) = %q, %v", want, got, err)
+		}
+	}
+	if _, err := ParseEventType("fact_deleted"); err == nil {
+		t.Error("ParseEventType(fact_deleted) should fail")
+	}
+}
+
+func TestNewEventFact(t *testing.T) {
+	plain := &Fact{FactID: "fact_1", Key: "editor", Value: "vim", ExtractedBy: "claude-code"}
+	if ef := NewEventFact(plain); ef.Value != "vim" || ef.Agent != "claude-code" {
+		t.Errorf("NewEventFact(plain) = %+v", ef)
+	}
+
+	secret := &Fact{FactID: "fact_2", Key: "api_key", Value: "sk-live-123", ValueType: ValueTypeSecret}
+	if ef := NewEventFact(secret); ef.Value != "" || ef.ValueType != ValueTypeSecret {
+		t.Errorf("NewEventFact(secret) = %+v, want no value", ef)
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	a, b := NewEvent(EventTurnStored), NewEvent(EventTurnStored)
+	if a.ID == b.ID || a.Type != EventTurnStored || a.Time.IsZero() {
+		t.Errorf("NewEvent = %+v, %+v", a, b)
+	}
+}
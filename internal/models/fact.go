@@ -6,21 +6,41 @@ import (
 	"errors"
 	"fmt"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 // Fact represents an extracted key-value fact
 type Fact struct {
-	FactID     string    `json:"fact_id"`
-	BlockID    string    `json:"block_id"`
-	TurnID     string    `json:"turn_id"`
-	Key        string    `json:"key"`
-	Value      string    `json:"value"`
-	Confidence float64   `json:"confidence"`
-	CreatedAt  time.Time `json:"created_at"`
+	FactID     string        `json:"fact_id"`
+	BlockID    string        `json:"block_id"`
+	TurnID     string        `json:"turn_id"`
+	Key        string        `json:"key"`
+	Value      string        `json:"value"`
+	ValueType  FactValueType `json:"value_type,omitempty"` // empty means string
+	Confidence float64       `json:"confidence"`
+	CreatedAt  time.Time     `json:"created_at"`
+	Tags       []string      `json:"tags,omitempty"`
+
+	// Provenance: where the fact came from (empty when unknown)
+	SourceModel   string `json:"source_model,omitempty"`   // LLM that extracted it
+	PromptVersion string `json:"prompt_version,omitempty"` // version of the extraction prompt
+	SourceQuote   string `json:"source_quote,omitempty"`   // text of the originating turn the fact rests on
+	ExtractedBy   string `json:"extracted_by,omitempty"`   // agent or component that recorded it
+	Agent         string `json:"agent,omitempty"`          // MCP client or configured agent whose memory it is
+
+	ExcludeFromRecall bool `json:"exclude_from_recall,omitempty"` // Kept, but never returned by fact searches or hydrated into context
+	Pending           bool `json:"pending,omitempty"`             // Awaiting approval in the review queue; recalled only once approved
 }
 
+// FactLimits bound what one LLM extraction may save from a turn; the zero value imposes none
+type FactLimits struct {
+	MaxPerTurn    int     // Facts saved from one turn, most confident first; 0 means no limit
+	MinConfidence float64 // Extracted facts below this confidence are discarded
+	Dedupe        bool    // Keep one fact per key from a turn, and skip facts repeating their key's current value
+}
+
+// DefaultFactLimits matches the fact_max_per_turn, fact_extract_min_confidence, and fact_dedupe defaults
+var DefaultFactLimits = FactLimits{MaxPerTurn: 20, MinConfidence: 0.2, Dedupe: true}
+
 // NewFact creates a new Fact with validation
 func NewFact(blockID, turnID, key, value string, confidence float64) (*Fact, error) {
 	if blockID == "" {
@@ -38,12 +58,13 @@ func NewFact(blockID, turnID, key, value string, confidence float64) (*Fact, err
 		TurnID:     turnID,
 		Key:        key,
 		Value:      value,
+		ValueType:  ValueTypeString,
 		Confidence: confidence,
-		CreatedAt:  time.Now().UTC(),
+		CreatedAt:  Now().UTC(),
 	}, nil
 }
 
 // generateFactID generates a unique fact identifier
 func generateFactID() string {
-	return fmt.Sprintf("fact_%s", uuid.New().String()[:8])
+	return NewID("fact")
 }
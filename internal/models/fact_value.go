@@ -0,0 +1,147 @@
+// ABOUTME: Typed fact values: the value_type of a fact and parsing of its value
+// ABOUTME: Values are stored as text; the type says how to validate and read them
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FactValueType says how a fact's value should be interpreted
+type FactValueType string
+
+const (
+	ValueTypeString  FactValueType = "string"
+	ValueTypeNumber  FactValueType = "number"
+	ValueTypeDate    FactValueType = "date"
+	ValueTypeBoolean FactValueType = "boolean"
+	ValueTypeSecret  FactValueType = "secret"
+	ValueTypeJSON    FactValueType = "json"
+)
+
+// FactValueTypes lists every value type, in documentation order
+var FactValueTypes = []FactValueType{
+	ValueTypeString, ValueTypeNumber, ValueTypeDate, ValueTypeBoolean, ValueTypeSecret, ValueTypeJSON,
+}
+
+// dateLayouts are the accepted date formats; date-only values are stored as 2006-01-02
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"}
+
+// ParseFactValueType validates a value type name; empty means string
+func ParseFactValueType(s string) (FactValueType, error) {
+	if s == "" {
+		return ValueTypeString, nil
+	}
+	t := FactValueType(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range FactValueTypes {
+		if t == known {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("invalid value type %q (want one of %s)", s, joinValueTypes())
+}
+
+// NormalizeFactValue checks that value parses as valueType and returns its canonical text:
+// numbers and booleans as Go formats them, dates as 2006-01-02 or RFC3339 in UTC, JSON compacted.
+func NormalizeFactValue(valueType FactValueType, value string) (string, error) {
+	switch valueType {
+	case ValueTypeString, ValueTypeSecret, "":
+		return value, nil
+	case ValueTypeNumber:
+		n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return "", fmt.Errorf("value %q is not a number", value)
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case ValueTypeBoolean:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("value %q is not a boolean", value)
+		}
+		return strconv.FormatBool(b), nil
+	case ValueTypeDate:
+		t, dateOnly, err := parseFactDate(value)
+		if err != nil {
+			return "", err
+		}
+		if dateOnly {
+			return t.Format("2006-01-02"), nil
+		}
+		return t.UTC().Format(time.RFC3339), nil
+	case ValueTypeJSON:
+		var v interface{}
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return "", fmt.Errorf("value is not valid JSON: %w", err)
+		}
+		compact, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(compact), nil
+	default:
+		return "", fmt.Errorf("invalid value type %q", valueType)
+	}
+}
+
+// Number returns the value of a number fact
+func (f *Fact) Number() (float64, error) {
+	if f.ValueType != ValueTypeNumber {
+		return 0, fmt.Errorf("fact %s is %s, not number", f.Key, f.typeName())
+	}
+	return strconv.ParseFloat(f.Value, 64)
+}
+
+// Date returns the value of a date fact; date-only values are midnight UTC
+func (f *Fact) Date() (time.Time, error) {
+	if f.ValueType != ValueTypeDate {
+		return time.Time{}, fmt.Errorf("fact %s is %s, not date", f.Key, f.typeName())
+	}
+	t, _, err := parseFactDate(f.Value)
+	return t, err
+}
+
+// Bool returns the value of a boolean fact
+func (f *Fact) Bool() (bool, error) {
+	if f.ValueType != ValueTypeBoolean {
+		return false, fmt.Errorf("fact %s is %s, not boolean", f.Key, f.typeName())
+	}
+	return strconv.ParseBool(f.Value)
+}
+
+// JSON decodes the value of a json fact into v
+func (f *Fact) JSON(v interface{}) error {
+	if f.ValueType != ValueTypeJSON {
+		return fmt.Errorf("fact %s is %s, not json", f.Key, f.typeName())
+	}
+	return json.Unmarshal([]byte(f.Value), v)
+}
+
+// typeName returns the fact's value type, treating empty as string
+func (f *Fact) typeName() FactValueType {
+	if f.ValueType == "" {
+		return ValueTypeString
+	}
+	return f.ValueType
+}
+
+// parseFactDate parses a date value, reporting whether it had no time of day
+func parseFactDate(value string) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, layout == "2006-01-02", nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("value %q is not a date (use YYYY-MM-DD or RFC 3339)", value)
+}
+
+func joinValueTypes() string {
+	names := make([]string, len(FactValueTypes))
+	for i, t := range FactValueTypes {
+		names[i] = string(t)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,77 @@
+// ABOUTME: Tests for typed fact values
+// ABOUTME: Verifies value type parsing, canonical values, and typed accessors
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFactValueType(t *testing.T) {
+	if got, err := ParseFactValueType(""); err != nil || got != ValueTypeString {
+		t.Errorf("ParseFactValueType(\"\") = %q, %v; want string", got, err)
+	}
+	if got, err := ParseFactValueType("Number"); err != nil || got != ValueTypeNumber {
+		t.Errorf("ParseFactValueType(Number) = %q, %v; want number", got, err)
+	}
+	if _, err := ParseFactValueType("integer"); err == nil {
+		t.Error("ParseFactValueType(integer) should fail")
+	}
+}
+
+func TestNormalizeFactValue(t *testing.T) {
+	tests := []struct {
+		valueType FactValueType
+		in        string
+		want      string
+		wantErr   bool
+	}{
+		{ValueTypeString, " anything ", " anything ", false},
+		{ValueTypeSecret, "sk-123", "sk-123", false},
+		{ValueTypeNumber, " 42.50 ", "42.5", false},
+		{ValueTypeNumber, "forty", "", true},
+		{ValueTypeBoolean, "TRUE", "true", false},
+		{ValueTypeBoolean, "yes", "", true},
+		{ValueTypeDate, "2026-03-01", "2026-03-01", false},
+		{ValueTypeDate, "2026-03-01T10:00:00-08:00", "2026-03-01T18:00:00Z", false},
+		{ValueTypeDate, "March 1st", "", true},
+		{ValueTypeJSON, `{ "a": [1, 2] }`, `{"a":[1,2]}`, false},
+		{ValueTypeJSON, `{"a":`, "", true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeFactValue(tt.valueType, tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeFactValue(%s, %q) error = %v, wantErr %v", tt.valueType, tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeFactValue(%s, %q) = %q, want %q", tt.valueType, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFact_TypedAccessors(t *testing.T) {
+	n := Fact{Key: "age", Value: "41", ValueType: ValueTypeNumber}
+	if v, err := n.Number(); err != nil || v != 41 {
+		t.Errorf("Number() = %v, %v; want 41", v, err)
+	}
+	if _, err := n.Bool(); err == nil {
+		t.Error("Bool() on a number fact should fail")
+	}
+
+	d := Fact{Key: "birthday", Value: "2026-03-01", ValueType: ValueTypeDate}
+	if v, err := d.Date(); err != nil || !v.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Date() = %v, %v; want 2026-03-01", v, err)
+	}
+
+	j := Fact{Key: "prefs", Value: `{"theme":"dark"}`, ValueType: ValueTypeJSON}
+	var prefs map[string]string
+	if err := j.JSON(&prefs); err != nil || prefs["theme"] != "dark" {
+		t.Errorf("JSON() = %v, %v; want theme dark", prefs, err)
+	}
+
+	untyped := Fact{Key: "name", Value: "Harper"}
+	if _, err := untyped.Number(); err == nil {
+		t.Error("Number() on an untyped fact should fail")
+	}
+}
@@ -0,0 +1,49 @@
+// ABOUTME: Feedback on retrieved memories: a thumbs up or down on a block or fact returned for a query
+// ABOUTME: Ratings raise or lower a block's importance and label retrieval benchmark queries
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Rating is a thumbs up or thumbs down on a retrieved memory
+type Rating string
+
+const (
+	RatingUp   Rating = "up"   // The memory helped answer the query
+	RatingDown Rating = "down" // The memory was irrelevant or wrong for the query
+)
+
+// Value is the rating as a score: +1 up, -1 down
+func (r Rating) Value() int {
+	if r == RatingDown {
+		return -1
+	}
+	return 1
+}
+
+// ParseRating parses "up" or "down", also accepting "+1"/"-1" and "helpful"/"unhelpful"
+func ParseRating(v string) (Rating, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "up", "+1", "1", "helpful":
+		return RatingUp, nil
+	case "down", "-1", "unhelpful":
+		return RatingDown, nil
+	}
+	return "", fmt.Errorf("invalid rating %q: must be up or down", v)
+}
+
+// MemoryFeedback is one rating of a block or fact retrieved for a query. Exactly one of
+// BlockID and FactID is set.
+type MemoryFeedback struct {
+	FeedbackID string    `json:"feedback_id"`
+	Query      string    `json:"query,omitempty"` // The query the memory was retrieved for
+	BlockID    string    `json:"block_id,omitempty"`
+	FactID     string    `json:"fact_id,omitempty"`
+	Rating     Rating    `json:"rating"`
+	Note       string    `json:"note,omitempty"`
+	Agent      string    `json:"agent,omitempty"` // Who gave the rating
+	CreatedAt  time.Time `json:"created_at"`
+}
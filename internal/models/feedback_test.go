@@ -0,0 +1,35 @@
+// ABOUTME: Tests for feedback ratings on retrieved memories
+// ABOUTME: Verifies rating parsing and their score values
+package models
+
+import "testing"
+
+func TestParseRating(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rating
+		wantErr bool
+	}{
+		{"up", RatingUp, false},
+		{" Down ", RatingDown, false},
+		{"+1", RatingUp, false},
+		{"-1", RatingDown, false},
+		{"unhelpful", RatingDown, false},
+		{"", "", true},
+		{"meh", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRating(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRating(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRating(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if RatingUp.Value() != 1 || RatingDown.Value() != -1 {
+		t.Errorf("Value() = %d, %d, want 1, -1", RatingUp.Value(), RatingDown.Value())
+	}
+}
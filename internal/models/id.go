@@ -0,0 +1,127 @@
+// ABOUTME: Generates the IDs of blocks, turns, facts, and other stored entities
+// ABOUTME: IDs are a kind prefix and a ULID, so they sort by creation time; older timestamped IDs still parse
+package models
+
+import (
+	"crypto/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// crockford is the Crockford base32 alphabet ULIDs are written in
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidLen is the length of a ULID: 48 bits of milliseconds and 80 random bits, 5 bits a character
+const ulidLen = 26
+
+// legacyIDTime is the timestamp IDs embedded before ULIDs (block_20060102_150405_1a2b3c4d)
+const legacyIDTime = "20060102_150405"
+
+// idGen makes IDs in the same millisecond increase by bumping the previous
+// randomness instead of drawing new, so they still sort in the order they were made
+var idGen struct {
+	sync.Mutex
+	ms      uint64
+	entropy [10]byte
+}
+
+// NewID returns a new ID for an entity of kind prefix (e.g. "turn"): the prefix, an
+// underscore, and a ULID. IDs sort by when they were made, and the 80 random bits
+// keep IDs made on different devices from colliding.
+func NewID(prefix string) string {
+	return NewIDAt(prefix, Now())
+}
+
+// NewIDAt returns a new ID as NewID would have made it at t
+func NewIDAt(prefix string, t time.Time) string {
+	ms := uint64(t.UnixMilli())
+
+	idGen.Lock()
+	if ms != idGen.ms || !increment(idGen.entropy[:]) {
+		if _, err := rand.Read(idGen.entropy[:]); err != nil {
+			panic("models: reading random bytes for an ID: " + err.Error())
+		}
+		idGen.ms = ms
+	}
+	var b [16]byte
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> (40 - 8*i))
+	}
+	copy(b[6:], idGen.entropy[:])
+	idGen.Unlock()
+
+	return prefix + "_" + encodeULID(b)
+}
+
+// increment adds one to the big-endian number in b, reporting false when it overflows
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID writes the 128 bits of b as 26 Crockford base32 characters
+func encodeULID(b [16]byte) string {
+	var out [ulidLen]byte
+	for i := range out {
+		var v byte
+		for j := 0; j < 5; j++ {
+			v <<= 1
+			// 26 characters hold 130 bits; the first two are always zero
+			if bit := i*5 + j - 2; bit >= 0 && b[bit/8]&(0x80>>(bit%8)) != 0 {
+				v |= 1
+			}
+		}
+		out[i] = crockford[v]
+	}
+	return string(out[:])
+}
+
+// IDTime returns when the entity with id was created, as its ID records it: the time in
+// its ULID, or the local time embedded in IDs made before ULIDs. ok is false for IDs
+// that record no time, such as older fact and attachment IDs.
+func IDTime(id string) (t time.Time, ok bool) {
+	if i := strings.LastIndexByte(id, '_'); i >= 0 && len(id)-i-1 == ulidLen {
+		if ms, ok := decodeULIDTime(id[i+1:]); ok {
+			return time.UnixMilli(int64(ms)).UTC(), true
+		}
+	}
+	// prefix_20060102_150405_suffix
+	parts := strings.Split(id, "_")
+	for i := 0; i+1 < len(parts); i++ {
+		if len(parts[i]) == 8 && len(parts[i+1]) == 6 {
+			if t, err := time.ParseInLocation(legacyIDTime, parts[i]+"_"+parts[i+1], time.Local); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// decodeULIDTime reads the milliseconds in a ULID's first 10 characters, ignoring case
+func decodeULIDTime(ulid string) (uint64, bool) {
+	var ms uint64
+	for i := 0; i < ulidLen; i++ {
+		v := strings.IndexByte(crockford, upper(ulid[i]))
+		if v < 0 || (i == 0 && v > 7) { // more than 128 bits
+			return 0, false
+		}
+		if i < 10 {
+			ms = ms<<5 | uint64(v)
+		}
+	}
+	return ms, true
+}
+
+// upper returns the upper case of an ASCII letter
+func upper(c byte) byte {
+	if 'a' <= c && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
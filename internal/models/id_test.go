@@ -0,0 +1,76 @@
+// ABOUTME: Tests for generated entity IDs
+// ABOUTME: Verifies ULID IDs sort by creation time, stay unique, and that older IDs still yield their time
+package models
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewID(t *testing.T) {
+	id := NewID("block")
+	if !strings.HasPrefix(id, "block_") || len(id) != len("block_")+ulidLen {
+		t.Fatalf("NewID() = %q, want block_ and a ULID", id)
+	}
+	for _, c := range strings.TrimPrefix(id, "block_") {
+		if !strings.ContainsRune(crockford, c) {
+			t.Fatalf("NewID() = %q has %q outside the Crockford alphabet", id, c)
+		}
+	}
+
+	// IDs made in the same millisecond still sort in the order they were made
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ids := make([]string, 1000)
+	seen := make(map[string]bool)
+	for i := range ids {
+		ids[i] = NewIDAt("turn", at)
+		if seen[ids[i]] {
+			t.Fatalf("NewIDAt() repeated %s", ids[i])
+		}
+		seen[ids[i]] = true
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Error("IDs made in one millisecond do not sort in order")
+	}
+
+	// Later IDs sort after earlier ones, whatever their randomness
+	if earlier, later := NewIDAt("fact", at.Add(time.Millisecond)), NewIDAt("fact", at); earlier < later {
+		t.Errorf("NewIDAt(%v) = %s sorts before NewIDAt(%v) = %s", at.Add(time.Millisecond), earlier, at, later)
+	}
+	if first, second := NewIDAt("fact", at.AddDate(-30, 0, 0)), NewIDAt("fact", at.AddDate(30, 0, 0)); first > second {
+		t.Errorf("%s sorts after %s", first, second)
+	}
+}
+
+func TestIDTime(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 30, 45, 123e6, time.UTC)
+	id := NewIDAt("block", at)
+	if got, ok := IDTime(id); !ok || !got.Equal(at) {
+		t.Errorf("IDTime(%s) = %v, %v; want %v", id, got, ok, at)
+	}
+	if got, ok := IDTime(strings.ToLower(id)); !ok || !got.Equal(at) {
+		t.Errorf("IDTime(lower case) = %v, %v; want %v", got, ok, at)
+	}
+
+	legacy := time.Date(2025, 12, 6, 14, 30, 22, 0, time.Local)
+	tests := []struct {
+		id     string
+		want   time.Time
+		wantOK bool
+	}{
+		{"block_20251206_143022_1a2b3c4d", legacy, true},
+		{"turn_20251206_143022_1a2b3c4d", legacy, true},
+		{"fact_1a2b3c4d", time.Time{}, false},
+		{"fact_8d2c5e1a-4f3b-4c2d-9e8f-7a6b5c4d3e2f", time.Time{}, false},
+		{"block_ZZZZZZZZZZZZZZZZZZZZZZZZZZ", time.Time{}, false}, // over 128 bits
+		{"", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := IDTime(tt.id)
+		if ok != tt.wantOK || !got.Equal(tt.want) {
+			t.Errorf("IDTime(%q) = %v, %v; want %v, %v", tt.id, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// ABOUTME: Job model for background work queued in storage
+// ABOUTME: Embeddings, fact extraction, and profile learning run as jobs off the request path
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// JobKind names the work a job does
+type JobKind string
+
+const (
+	JobEmbedTurn       JobKind = "embed_turn"       // Chunk and embed a stored turn; payload TurnJob
+	JobExtractFacts    JobKind = "extract_facts"    // Extract facts from a stored turn; payload TurnJob
+	JobExtractMetadata JobKind = "extract_metadata" // Fill in a stored turn's keywords, topics, and affect; payload TurnJob
+	JobUpdateProfile   JobKind = "update_profile"   // Learn about the user from a message; payload ProfileJob
+	JobDeliverWebhook  JobKind = "deliver_webhook"  // POST a memory event to a webhook; payload WebhookJob
+)
+
+// JobStatus is where a job is in its life
+type JobStatus string
+
+const (
+	JobPending JobStatus = "pending" // Waiting for a worker, possibly until RunAfter
+	JobRunning JobStatus = "running" // Claimed by a worker
+	JobDone    JobStatus = "done"    // Finished successfully
+	JobFailed  JobStatus = "failed"  // Out of attempts; see LastError
+)
+
+// DefaultJobAttempts is how many times a job runs before it is marked failed
+const DefaultJobAttempts = 5
+
+// IsValid checks if the JobStatus is a valid value
+func (s JobStatus) IsValid() bool {
+	return s == JobPending || s == JobRunning || s == JobDone || s == JobFailed
+}
+
+// ParseJobStatus validates a job status name
+func ParseJobStatus(s string) (JobStatus, error) {
+	status := JobStatus(s)
+	if !status.IsValid() {
+		return "", fmt.Errorf("invalid job status %q (must be pending, running, done, or failed)", s)
+	}
+	return status, nil
+}
+
+// Job is one unit of background work with its retry state
+type Job struct {
+	ID          int64           `json:"id"`
+	Kind        JobKind         `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      JobStatus       `json:"status"`
+	Attempts    int             `json:"attempts"`             // Runs started so far
+	MaxAttempts int             `json:"max_attempts"`         // Runs allowed before the job fails
+	LastError   string          `json:"last_error,omitempty"` // Error from the latest failed run
+	RunAfter    time.Time       `json:"run_after"`            // Earliest time a worker may pick the job up
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// Decode unmarshals the job's payload into v
+func (j *Job) Decode(v any) error {
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return fmt.Errorf("invalid %s payload: %w", j.Kind, err)
+	}
+	return nil
+}
+
+// TurnJob is the payload of jobs about one stored turn
+type TurnJob struct {
+	TurnID  string `json:"turn_id"`
+	BlockID string `json:"block_id"`
+}
+
+// ProfileJob is the payload of a profile learning job
+type ProfileJob struct {
+	Message string   `json:"message"`
+	Persona string   `json:"persona,omitempty"`  // Persona the conversation belongs to; empty for the shared profile
+	TurnIDs []string `json:"turn_ids,omitempty"` // Turns the messages came from, recorded with what is learned
+	Merged  int      `json:"merged,omitempty"`   // Later messages folded into Message while the job waited
+}
+
+// WebhookJob is the payload of a webhook delivery job
+type WebhookJob struct {
+	URL   string `json:"url"`
+	Event Event  `json:"event"`
+}
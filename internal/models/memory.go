@@ -4,9 +4,20 @@ package models
 
 // MemorySearchResult represents a memory retrieval result
 type MemorySearchResult struct {
-	BlockID        string  `json:"block_id"`
-	TopicLabel     string  `json:"topic_label"`
-	RelevanceScore float64 `json:"relevance_score"`
-	Summary        string  `json:"summary"`
-	Turns          []Turn  `json:"turns,omitempty"`
+	BlockID        string       `json:"block_id"`
+	TopicLabel     string       `json:"topic_label"`
+	RelevanceScore float64      `json:"relevance_score"`
+	Summary        string       `json:"summary"`
+	Turns          []Turn       `json:"turns,omitempty"`
+	Tags           []string     `json:"tags,omitempty"`
+	Affect         Affect       `json:"affect,omitempty"`      // Dominant affect of the block's turns
+	Attachments    []Attachment `json:"attachments,omitempty"` // Attachments on the block's turns, content trimmed to a preview
+	Snippets       []Snippet    `json:"snippets,omitempty"`    // Chunks semantic search matched, best first
+}
+
+// Snippet is the text of a chunk that semantically matched a search query
+type Snippet struct {
+	Text   string  `json:"text"`
+	TurnID string  `json:"turn_id"` // The turn, or attachment, the text comes from
+	Score  float64 `json:"score"`
 }
@@ -0,0 +1,188 @@
+// ABOUTME: Role-tagged messages that make up a turn's transcript
+// ABOUTME: Keeps system prompts, tool calls, and tool results from imported agent transcripts
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Role identifies who produced a message
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+	RoleSystem    Role = "system"
+	RoleTool      Role = "tool"
+)
+
+// ParseRole lowercases and validates a role
+func ParseRole(s string) (Role, error) {
+	r := Role(strings.ToLower(strings.TrimSpace(s)))
+	switch r {
+	case RoleUser, RoleAssistant, RoleSystem, RoleTool:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown role %q (want user, assistant, system, or tool)", s)
+}
+
+// Label returns the speaker label used when rendering transcripts
+func (r Role) Label() string {
+	switch r {
+	case RoleUser:
+		return "User"
+	case RoleAssistant:
+		return "AI"
+	case RoleSystem:
+		return "System"
+	case RoleTool:
+		return "Tool"
+	}
+	return string(r)
+}
+
+// ToolCall is a tool invocation requested by an assistant message
+type ToolCall struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments,omitempty"` // Usually a JSON object, kept verbatim
+}
+
+// UnmarshalJSON accepts the flat {"id","name","arguments"} form as well as the
+// OpenAI {"id","type":"function","function":{"name","arguments"}} form, with
+// arguments given either as a string or as a JSON object
+func (tc *ToolCall) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ID        string          `json:"id"`
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+		Function  *struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	tc.ID, tc.Name = raw.ID, raw.Name
+	args := raw.Arguments
+	if raw.Function != nil {
+		tc.Name, args = raw.Function.Name, raw.Function.Arguments
+	}
+
+	tc.Arguments = ""
+	if len(args) > 0 && string(args) != "null" {
+		var s string
+		if err := json.Unmarshal(args, &s); err == nil {
+			tc.Arguments = s
+		} else {
+			tc.Arguments = string(args)
+		}
+	}
+	return nil
+}
+
+// Message is one entry in a turn's transcript
+type Message struct {
+	Role       Role       `json:"role"`
+	Content    string     `json:"content,omitempty"`
+	Name       string     `json:"name,omitempty"`         // Speaker or tool name
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // Calls requested by an assistant message
+	ToolCallID string     `json:"tool_call_id,omitempty"` // Call a tool message answers
+}
+
+// Validate checks that the message has a known role and something to say
+func (m Message) Validate() error {
+	if _, err := ParseRole(string(m.Role)); err != nil {
+		return err
+	}
+	if strings.TrimSpace(m.Content) == "" && len(m.ToolCalls) == 0 {
+		return fmt.Errorf("%s message has no content", m.Role)
+	}
+	for i, call := range m.ToolCalls {
+		if call.Name == "" {
+			return fmt.Errorf("tool call %d has no name", i)
+		}
+	}
+	return nil
+}
+
+// Speaker returns the message's label, with the speaker or tool name when set, e.g. "Tool (search)"
+func (m Message) Speaker() string {
+	if m.Name != "" {
+		return fmt.Sprintf("%s (%s)", m.Role.Label(), m.Name)
+	}
+	return m.Role.Label()
+}
+
+// Body returns the message content followed by any tool calls, e.g. "[calls search({"q":"x"})]"
+func (m Message) Body() string {
+	parts := []string{}
+	if m.Content != "" {
+		parts = append(parts, m.Content)
+	}
+	for _, call := range m.ToolCalls {
+		parts = append(parts, fmt.Sprintf("[calls %s(%s)]", call.Name, call.Arguments))
+	}
+	return strings.Join(parts, " ")
+}
+
+// String renders the message as one transcript line, e.g. "Tool (search): 3 results"
+func (m Message) String() string {
+	return m.Speaker() + ": " + m.Body()
+}
+
+// NewTurnFromMessages builds a turn from a transcript. UserMessage and AIResponse
+// hold the joined user and assistant text so search and fact extraction keep working.
+func NewTurnFromMessages(messages []Message) (*Turn, error) {
+	if len(messages) == 0 {
+		return nil, errors.New("turn needs at least one message")
+	}
+
+	normalized := make([]Message, len(messages))
+	var user, assistant []string
+	for i, m := range messages {
+		role, err := ParseRole(string(m.Role))
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		m.Role = role
+		if err := m.Validate(); err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		switch {
+		case m.Role == RoleUser && m.Content != "":
+			user = append(user, m.Content)
+		case m.Role == RoleAssistant && m.Content != "":
+			assistant = append(assistant, m.Content)
+		}
+		normalized[i] = m
+	}
+	if len(user) == 0 {
+		return nil, errors.New("turn needs at least one user message")
+	}
+
+	turn, err := NewTurn(strings.Join(user, "\n\n"), strings.Join(assistant, "\n\n"), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	turn.Messages = normalized
+	return turn, nil
+}
+
+// Text returns the turn's text for embedding: the user message and AI response,
+// or every message (tool calls included) when the turn has a transcript
+func (t *Turn) Text() string {
+	if len(t.Messages) == 0 {
+		return t.UserMessage + " " + t.AIResponse
+	}
+	lines := make([]string, len(t.Messages))
+	for i, m := range t.Messages {
+		lines[i] = m.String()
+	}
+	return strings.Join(lines, "\n")
+}
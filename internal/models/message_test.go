@@ -0,0 +1,84 @@
+// ABOUTME: Tests for role-tagged turn messages
+// ABOUTME: Verifies role parsing, tool-call decoding, and building turns from transcripts
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseRole(t *testing.T) {
+	if r, err := ParseRole(" Assistant "); err != nil || r != RoleAssistant {
+		t.Errorf("ParseRole(Assistant) = %q, %v", r, err)
+	}
+	if _, err := ParseRole("ai"); err == nil {
+		t.Error("ParseRole(ai) should fail")
+	}
+}
+
+func TestToolCall_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name, in string
+		want     ToolCall
+	}{
+		{"flat with string arguments", `{"id":"c1","name":"search","arguments":"{\"q\":\"go\"}"}`, ToolCall{ID: "c1", Name: "search", Arguments: `{"q":"go"}`}},
+		{"flat with object arguments", `{"id":"c1","name":"search","arguments":{"q":"go"}}`, ToolCall{ID: "c1", Name: "search", Arguments: `{"q":"go"}`}},
+		{"openai form", `{"id":"c2","type":"function","function":{"name":"lookup","arguments":"{}"}}`, ToolCall{ID: "c2", Name: "lookup", Arguments: "{}"}},
+		{"no arguments", `{"name":"now"}`, ToolCall{Name: "now"}},
+	}
+	for _, tt := range tests {
+		var got ToolCall
+		if err := json.Unmarshal([]byte(tt.in), &got); err != nil {
+			t.Errorf("%s: error = %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewTurnFromMessages(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "You are a deploy bot"},
+		{Role: "User", Content: "Is prod healthy?"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "c1", Name: "check_health", Arguments: `{"env":"prod"}`}}},
+		{Role: "tool", Name: "check_health", ToolCallID: "c1", Content: "all green"},
+		{Role: "assistant", Content: "Prod is healthy."},
+	}
+	turn, err := NewTurnFromMessages(messages)
+	if err != nil {
+		t.Fatalf("NewTurnFromMessages() error = %v", err)
+	}
+	if turn.UserMessage != "Is prod healthy?" || turn.AIResponse != "Prod is healthy." {
+		t.Errorf("turn = %q / %q, want the user and assistant text", turn.UserMessage, turn.AIResponse)
+	}
+	if len(turn.Messages) != 5 || turn.Messages[1].Role != RoleUser {
+		t.Errorf("Messages = %+v, want 5 with normalized roles", turn.Messages)
+	}
+
+	text := turn.Text()
+	for _, want := range []string{"System: You are a deploy bot", `[calls check_health({"env":"prod"})]`, "Tool (check_health): all green"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text() = %q, want it to contain %q", text, want)
+		}
+	}
+
+	if _, err := NewTurnFromMessages([]Message{{Role: RoleSystem, Content: "hi"}}); err == nil {
+		t.Error("a transcript without a user message should fail")
+	}
+	if _, err := NewTurnFromMessages([]Message{{Role: RoleUser}}); err == nil {
+		t.Error("an empty user message should fail")
+	}
+	if _, err := NewTurnFromMessages([]Message{{Role: "robot", Content: "beep"}}); err == nil {
+		t.Error("an unknown role should fail")
+	}
+}
+
+func TestTurnText_PlainTurn(t *testing.T) {
+	turn := Turn{UserMessage: "hello", AIResponse: "hi"}
+	if got := turn.Text(); got != "hello hi" {
+		t.Errorf("Text() = %q, want %q", got, "hello hi")
+	}
+}
@@ -0,0 +1,57 @@
+// ABOUTME: Origin records where a turn was said: device, host, directory, git repo, location, agent
+// ABOUTME: Supplied by the MCP client or gathered by the CLI, and usable as a search filter
+package models
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Origin describes the device and workspace a turn came from. Every field is optional.
+type Origin struct {
+	Device     string `yaml:"device,omitempty" json:"device,omitempty"`           // Device name, e.g. "work-laptop"
+	Hostname   string `yaml:"hostname,omitempty" json:"hostname,omitempty"`       // Machine hostname
+	WorkingDir string `yaml:"working_dir,omitempty" json:"working_dir,omitempty"` // Absolute working directory
+	GitRepo    string `yaml:"git_repo,omitempty" json:"git_repo,omitempty"`       // Repository remote URL, or its root path when it has no remote
+	Location   string `yaml:"location,omitempty" json:"location,omitempty"`       // Free-form place, e.g. "Chicago" or "41.88,-87.63"
+	Agent      string `yaml:"agent,omitempty" json:"agent,omitempty"`             // MCP client or configured agent_id that wrote the turn, e.g. "claude-code"
+}
+
+// Normalize trims every field and cleans the working directory path
+func (o Origin) Normalize() Origin {
+	o.Device = strings.TrimSpace(o.Device)
+	o.Hostname = strings.TrimSpace(o.Hostname)
+	o.WorkingDir = strings.TrimSpace(o.WorkingDir)
+	if o.WorkingDir != "" {
+		o.WorkingDir = filepath.Clean(o.WorkingDir)
+	}
+	o.GitRepo = strings.TrimSpace(o.GitRepo)
+	o.Location = strings.TrimSpace(o.Location)
+	o.Agent = strings.TrimSpace(o.Agent)
+	return o
+}
+
+// IsZero reports whether no field is set
+func (o Origin) IsZero() bool {
+	return o == Origin{}
+}
+
+// Matches reports whether o satisfies filter: every set filter field must be equal,
+// except WorkingDir, which also matches subdirectories
+func (o Origin) Matches(filter Origin) bool {
+	switch {
+	case filter.Device != "" && o.Device != filter.Device,
+		filter.Hostname != "" && o.Hostname != filter.Hostname,
+		filter.GitRepo != "" && o.GitRepo != filter.GitRepo,
+		filter.Location != "" && o.Location != filter.Location,
+		filter.Agent != "" && o.Agent != filter.Agent:
+		return false
+	}
+	if filter.WorkingDir != "" {
+		dir := filepath.Clean(filter.WorkingDir)
+		if o.WorkingDir != dir && !strings.HasPrefix(o.WorkingDir, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator)) {
+			return false
+		}
+	}
+	return true
+}
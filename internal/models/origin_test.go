@@ -0,0 +1,46 @@
+// ABOUTME: Tests for turn origins
+// ABOUTME: Verifies normalization and the filter matching used by search
+package models
+
+import "testing"
+
+func TestOrigin_Normalize(t *testing.T) {
+	o := Origin{Device: " laptop ", WorkingDir: "/src/api/../api/", GitRepo: "\tgithub.com/acme/api\n", Agent: " claude-code "}.Normalize()
+	want := Origin{Device: "laptop", WorkingDir: "/src/api", GitRepo: "github.com/acme/api", Agent: "claude-code"}
+	if o != want {
+		t.Errorf("Normalize() = %+v, want %+v", o, want)
+	}
+	if !(Origin{}).IsZero() || o.IsZero() {
+		t.Error("IsZero() should be true only for the empty origin")
+	}
+}
+
+func TestOrigin_Matches(t *testing.T) {
+	o := Origin{Device: "laptop", Hostname: "box", WorkingDir: "/src/api/cmd", GitRepo: "github.com/acme/api", Agent: "claude-code"}
+	tests := []struct {
+		name   string
+		filter Origin
+		want   bool
+	}{
+		{"empty filter", Origin{}, true},
+		{"same repo", Origin{GitRepo: "github.com/acme/api"}, true},
+		{"other repo", Origin{GitRepo: "github.com/acme/web"}, false},
+		{"repo and device", Origin{GitRepo: "github.com/acme/api", Device: "laptop"}, true},
+		{"repo and other device", Origin{GitRepo: "github.com/acme/api", Device: "desktop"}, false},
+		{"same dir", Origin{WorkingDir: "/src/api/cmd"}, true},
+		{"parent dir", Origin{WorkingDir: "/src/api/"}, true},
+		{"root dir", Origin{WorkingDir: "/"}, true},
+		{"sibling prefix", Origin{WorkingDir: "/src/ap"}, false},
+		{"child dir", Origin{WorkingDir: "/src/api/cmd/memory"}, false},
+		{"location unset on turn", Origin{Location: "Chicago"}, false},
+		{"same agent", Origin{Agent: "claude-code"}, true},
+		{"other agent", Origin{Agent: "research-agent"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := o.Matches(tt.filter); got != tt.want {
+				t.Errorf("Matches(%+v) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// ABOUTME: Personas are named facets of the user profile such as "work" or "home"
+// ABOUTME: Each keeps its own preferences and constraints, layered over the shared profile
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaxPersonaNameLength is the longest persona name accepted
+const MaxPersonaNameLength = 32
+
+var personaNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Persona holds the preferences and constraints that apply only in one context,
+// so professional and personal traits are not blended into one profile.
+// Name and topics of interest stay on the shared UserProfile.
+type Persona struct {
+	Name        string              `json:"name"`
+	Preferences []string            `json:"preferences,omitempty"`
+	Constraints []ProfileConstraint `json:"constraints,omitempty"`
+	LastUpdated time.Time           `json:"last_updated"`
+}
+
+// NormalizePersonaName lowercases and trims a persona name and validates it
+func NormalizePersonaName(name string) (string, error) {
+	n := strings.ToLower(strings.TrimSpace(name))
+	if n == "" {
+		return "", fmt.Errorf("persona name cannot be empty")
+	}
+	if len(n) > MaxPersonaNameLength {
+		return "", fmt.Errorf("persona name %q is longer than %d characters", name, MaxPersonaNameLength)
+	}
+	if !personaNamePattern.MatchString(n) {
+		return "", fmt.Errorf("invalid persona name %q: use letters, digits, - and _", name)
+	}
+	return n, nil
+}
+
+// AddConstraint adds c to the persona, or updates the severity of an existing match
+func (p *Persona) AddConstraint(c ProfileConstraint) error {
+	constraints, err := addConstraint(p.Constraints, c)
+	if err != nil {
+		return err
+	}
+	p.Constraints = constraints
+	return nil
+}
+
+// Merge merges the preferences and constraints in newInfo into the persona,
+// with the same rules as UserProfile.Merge; other keys are ignored
+func (p *Persona) Merge(newInfo map[string]interface{}) {
+	scratch := &UserProfile{Preferences: p.Preferences, Constraints: p.Constraints}
+	scratch.Merge(map[string]interface{}{
+		"preferences": newInfo["preferences"],
+		"constraints": newInfo["constraints"],
+	})
+	p.Preferences, p.Constraints, p.LastUpdated = scratch.Preferences, scratch.Constraints, scratch.LastUpdated
+}
+
+// MergeForPersona merges newInfo with the persona-specific fields (preferences and
+// constraints) going to p and the rest to the shared profile. A nil p merges everything into up.
+func (up *UserProfile) MergeForPersona(p *Persona, newInfo map[string]interface{}) {
+	if p == nil {
+		up.Merge(newInfo)
+		return
+	}
+	p.Merge(newInfo)
+
+	shared := make(map[string]interface{}, len(newInfo))
+	for key, value := range newInfo {
+		if key != "preferences" && key != "constraints" {
+			shared[key] = value
+		}
+	}
+	up.Merge(shared)
+}
+
+// WithPersona returns a copy of the profile with p's preferences and constraints
+// added after the shared ones. A nil p returns an unchanged copy.
+func (up *UserProfile) WithPersona(p *Persona) *UserProfile {
+	view := *up
+	view.Preferences = append([]string(nil), up.Preferences...)
+	view.Constraints = append([]ProfileConstraint(nil), up.Constraints...)
+	if p == nil {
+		return &view
+	}
+
+	view.Persona = p.Name
+	for _, pref := range p.Preferences {
+		if !contains(view.Preferences, pref) {
+			view.Preferences = append(view.Preferences, pref)
+		}
+	}
+	for _, c := range p.Constraints {
+		view.Constraints, _ = addConstraint(view.Constraints, c)
+	}
+	if p.LastUpdated.After(view.LastUpdated) {
+		view.LastUpdated = p.LastUpdated
+	}
+	return &view
+}
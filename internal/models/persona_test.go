@@ -0,0 +1,96 @@
+// ABOUTME: Tests for personas layered over the user profile
+// ABOUTME: Verifies name normalization, persona-aware merging, and the layered view
+package models
+
+import "testing"
+
+func TestNormalizePersonaName(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "work", want: "work"},
+		{in: "  Home ", want: "home"},
+		{in: "side_project-2", want: "side_project-2"},
+		{in: "", wantErr: true},
+		{in: "two words", wantErr: true},
+		{in: "-work", wantErr: true},
+		{in: "abcdefghijklmnopqrstuvwxyz0123456", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := NormalizePersonaName(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizePersonaName(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizePersonaName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserProfile_MergeForPersona(t *testing.T) {
+	up := &UserProfile{Name: "Alice"}
+	work := &Persona{Name: "work"}
+
+	up.MergeForPersona(work, map[string]interface{}{
+		"name":               "Alice Smith",
+		"preferences":        []interface{}{"formal tone"},
+		"topics_of_interest": []interface{}{"Go"},
+		"constraints": []interface{}{
+			map[string]interface{}{"type": "Schedule", "description": "no meetings on Fridays"},
+		},
+	})
+
+	if up.Name != "Alice Smith" || len(up.TopicsOfInterest) != 1 {
+		t.Errorf("shared profile = %+v, want name and topics merged", up)
+	}
+	if len(up.Preferences) != 0 || len(up.Constraints) != 0 {
+		t.Errorf("shared profile got persona fields: %+v", up)
+	}
+	if len(work.Preferences) != 1 || len(work.Constraints) != 1 {
+		t.Errorf("persona = %+v, want preferences and constraints merged", work)
+	}
+
+	// Without a persona everything goes to the shared profile
+	up.MergeForPersona(nil, map[string]interface{}{"preferences": []interface{}{"dark mode"}})
+	if len(up.Preferences) != 1 {
+		t.Errorf("Preferences = %v, want [dark mode]", up.Preferences)
+	}
+}
+
+func TestUserProfile_WithPersona(t *testing.T) {
+	up := &UserProfile{
+		Name:        "Alice",
+		Preferences: []string{"concise", "formal tone"},
+		Constraints: []ProfileConstraint{{Type: "Allergy", Description: "peanuts", Severity: SeverityStrict}},
+	}
+	work := &Persona{
+		Name:        "work",
+		Preferences: []string{"formal tone", "bullet points"},
+		Constraints: []ProfileConstraint{{Type: "Schedule", Description: "no meetings on Fridays"}},
+	}
+
+	view := up.WithPersona(work)
+	if view.Persona != "work" {
+		t.Errorf("Persona = %q, want work", view.Persona)
+	}
+	if want := []string{"concise", "formal tone", "bullet points"}; len(view.Preferences) != len(want) || view.Preferences[2] != want[2] {
+		t.Errorf("Preferences = %v, want %v", view.Preferences, want)
+	}
+	if len(view.Constraints) != 2 {
+		t.Errorf("Constraints = %v, want 2", view.Constraints)
+	}
+
+	// The shared profile is not modified
+	if len(up.Preferences) != 2 || len(up.Constraints) != 1 || up.Persona != "" {
+		t.Errorf("WithPersona modified the shared profile: %+v", up)
+	}
+
+	if plain := up.WithPersona(nil); plain.Persona != "" || len(plain.Preferences) != 2 {
+		t.Errorf("WithPersona(nil) = %+v, want an unchanged copy", plain)
+	}
+}
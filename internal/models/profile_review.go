@@ -0,0 +1,39 @@
+// ABOUTME: Profile items (preferences and topics) tracked for staleness review
+// ABOUTME: Each item remembers when it was last reinforced by learning, an update, or the user confirming it
+package models
+
+import "time"
+
+// ProfileItemKind is the kind of profile entry a review covers
+type ProfileItemKind string
+
+const (
+	ProfileItemPreference ProfileItemKind = "preference" // An entry in preferences, shared or a persona's
+	ProfileItemTopic      ProfileItemKind = "topic"      // An entry in the shared topics of interest
+)
+
+// ProfileItem is one preference or topic with when it was last reinforced
+type ProfileItem struct {
+	Persona      string          `json:"persona,omitempty"` // Empty for the shared profile
+	Kind         ProfileItemKind `json:"kind"`
+	Text         string          `json:"text"`
+	ReinforcedAt time.Time       `json:"reinforced_at"`
+}
+
+// InfoStrings returns the strings listed under key in profile update info, as
+// decoded from JSON ([]interface{}) or built in Go ([]string)
+func InfoStrings(info map[string]interface{}, key string) []string {
+	switch values := info[key].(type) {
+	case []string:
+		return values
+	case []interface{}:
+		var out []string
+		for _, v := range values {
+			if s, ok := v.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
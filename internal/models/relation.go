@@ -0,0 +1,66 @@
+// ABOUTME: Relations between Bridge Blocks for following conversation threads
+// ABOUTME: Defines continuation-of, spawned-from, and references links and their inverses
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// RelationType says how one block relates to another
+type RelationType string
+
+const (
+	// RelationContinuationOf - the block carries on another's thread (e.g. the tail of a split)
+	RelationContinuationOf RelationType = "continuation-of"
+
+	// RelationSpawnedFrom - the block was started by a topic shift away from another
+	RelationSpawnedFrom RelationType = "spawned-from"
+
+	// RelationReferences - the block refers to another (linked by hand or by an agent)
+	RelationReferences RelationType = "references"
+)
+
+// RelationTypes lists every relation type
+var RelationTypes = []RelationType{RelationContinuationOf, RelationSpawnedFrom, RelationReferences}
+
+// ParseRelationType validates a relation type name
+func ParseRelationType(s string) (RelationType, error) {
+	for _, t := range RelationTypes {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("invalid relation %q (want %s, %s, or %s)", s, RelationContinuationOf, RelationSpawnedFrom, RelationReferences)
+}
+
+// Inverse names the relation as seen from the other block, e.g. "continued-by" for continuation-of
+func (r RelationType) Inverse() string {
+	switch r {
+	case RelationContinuationOf:
+		return "continued-by"
+	case RelationSpawnedFrom:
+		return "spawned"
+	case RelationReferences:
+		return "referenced-by"
+	default:
+		return string(r)
+	}
+}
+
+// BlockRelation is a directed link: FromBlockID <Type> ToBlockID
+type BlockRelation struct {
+	FromBlockID string       `json:"from_block_id"`
+	ToBlockID   string       `json:"to_block_id"`
+	Type        RelationType `json:"relation"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// RelatedTopic is a block related to another, with the relation read from the first block's side
+type RelatedTopic struct {
+	BlockID    string            `json:"block_id"`
+	TopicLabel string            `json:"topic_label"`
+	Status     BridgeBlockStatus `json:"status"`
+	Relation   string            `json:"relation"` // e.g. "spawned-from", or "spawned" for the inverse direction
+	CreatedAt  time.Time         `json:"created_at"`
+}
@@ -0,0 +1,96 @@
+// ABOUTME: Retention policies set on individual topics or tags, overriding the global pruning policy
+// ABOUTME: A policy either keeps a topic forever or deletes it a fixed time after its last update
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retention is how long a topic is kept, overriding archive_retention and max_db_size
+// eviction. The zero value means no override: the global policy applies.
+type Retention struct {
+	Keep  bool          // Never pruned, whatever the size limit
+	After time.Duration // Deleted once this long past its last update, whatever its status
+}
+
+// KeepForever is the policy that exempts a topic from pruning
+var KeepForever = Retention{Keep: true}
+
+// ParseRetention parses "keep" (or "forever"), a number of days or weeks such as "30d"
+// or "2w", or a Go duration such as "36h". "" and "default" parse to the zero policy.
+func ParseRetention(s string) (Retention, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	switch v {
+	case "", "default":
+		return Retention{}, nil
+	case "keep", "forever":
+		return KeepForever, nil
+	}
+
+	var after time.Duration
+	if n, unit := strings.TrimRight(v, "dw"), strings.TrimLeft(v, "0123456789"); unit == "d" || unit == "w" {
+		count, err := strconv.Atoi(n)
+		if err != nil {
+			return Retention{}, fmt.Errorf("invalid retention %q: use keep, a number of days like 30d, or a duration like 36h", s)
+		}
+		after = time.Duration(count) * 24 * time.Hour
+		if unit == "w" {
+			after *= 7
+		}
+	} else {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return Retention{}, fmt.Errorf("invalid retention %q: use keep, a number of days like 30d, or a duration like 36h", s)
+		}
+		after = d
+	}
+	if after <= 0 {
+		return Retention{}, fmt.Errorf("invalid retention %q: must be longer than zero", s)
+	}
+	return Retention{After: after}, nil
+}
+
+// String renders the policy the way ParseRetention reads it: "keep", whole days as "30d",
+// other durations as Go durations, and "" for the zero policy
+func (r Retention) String() string {
+	switch {
+	case r.Keep:
+		return "keep"
+	case r.After <= 0:
+		return ""
+	case r.After%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", r.After/(24*time.Hour))
+	}
+	return r.After.String()
+}
+
+// IsZero reports whether the policy leaves the global policy in place
+func (r Retention) IsZero() bool {
+	return !r.Keep && r.After <= 0
+}
+
+// Longer returns whichever of r and other keeps a topic longer. A set policy is
+// longer than the zero policy, since it was chosen on purpose.
+func (r Retention) Longer(other Retention) Retention {
+	switch {
+	case r.Keep || other.IsZero():
+		return r
+	case other.Keep || r.IsZero():
+		return other
+	case other.After > r.After:
+		return other
+	}
+	return r
+}
+
+// ExpiresAt returns when a topic last updated at updated is deleted, or false when the
+// policy never deletes it
+func (r Retention) ExpiresAt(updated time.Time) (time.Time, bool) {
+	if r.Keep || r.After <= 0 {
+		return time.Time{}, false
+	}
+	return updated.Add(r.After), true
+}
@@ -0,0 +1,72 @@
+// ABOUTME: Tests for retention policies
+// ABOUTME: Verifies parsing, rendering, choosing the longer of two policies, and expiry times
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRetention(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		in      string
+		want    Retention
+		str     string
+		wantErr bool
+	}{
+		{"", Retention{}, "", false},
+		{"default", Retention{}, "", false},
+		{"keep", KeepForever, "keep", false},
+		{" Forever ", KeepForever, "keep", false},
+		{"30d", Retention{After: 30 * day}, "30d", false},
+		{"2w", Retention{After: 14 * day}, "14d", false},
+		{"48h", Retention{After: 2 * day}, "2d", false},
+		{"36h", Retention{After: 36 * time.Hour}, "36h0m0s", false},
+		{"0d", Retention{}, "", true},
+		{"-5h", Retention{}, "", true},
+		{"1.5d", Retention{}, "", true},
+		{"soon", Retention{}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRetention(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRetention(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want || got.String() != tt.str {
+			t.Errorf("ParseRetention(%q) = %+v (%q), want %+v (%q)", tt.in, got, got.String(), tt.want, tt.str)
+		}
+	}
+}
+
+func TestRetention_Longer(t *testing.T) {
+	week, month := Retention{After: 7 * 24 * time.Hour}, Retention{After: 30 * 24 * time.Hour}
+	tests := []struct {
+		a, b, want Retention
+	}{
+		{week, month, month},
+		{month, week, month},
+		{week, KeepForever, KeepForever},
+		{KeepForever, week, KeepForever},
+		{Retention{}, week, week},
+		{week, Retention{}, week},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Longer(tt.b); got != tt.want {
+			t.Errorf("%v.Longer(%v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRetention_ExpiresAt(t *testing.T) {
+	updated := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if at, ok := (Retention{After: 24 * time.Hour}).ExpiresAt(updated); !ok || !at.Equal(updated.Add(24*time.Hour)) {
+		t.Errorf("ExpiresAt() = %v, %v; want a day after the update", at, ok)
+	}
+	for _, r := range []Retention{KeepForever, {}} {
+		if _, ok := r.ExpiresAt(updated); ok {
+			t.Errorf("%+v.ExpiresAt() expires, want never", r)
+		}
+	}
+}
@@ -27,7 +27,9 @@ func (r RoutingScenario) IsValid() bool {
 
 // RoutingDecision contains the routing decision and relevant metadata
 type RoutingDecision struct {
-	Scenario       RoutingScenario `json:"scenario"`
-	MatchedBlockID string          `json:"matched_block_id,omitempty"`
-	ActiveBlockID  string          `json:"active_block_id,omitempty"`
+	Scenario       RoutingScenario    `json:"scenario"`
+	MatchedBlockID string             `json:"matched_block_id,omitempty"`
+	ActiveBlockID  string             `json:"active_block_id,omitempty"`
+	Score          float64            `json:"score"`            // Matched block's score, or the best near miss
+	Scores         map[string]float64 `json:"scores,omitempty"` // Score of each active or paused block sharing keywords or topic
 }
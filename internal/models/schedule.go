@@ -0,0 +1,50 @@
+// ABOUTME: Scheduled task model for built-in maintenance a server runs on a cron schedule
+// ABOUTME: Each run of a task is recorded so 'memory jobs history' can show what ran and how it went
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ScheduledTask names a built-in task a server can run on a schedule
+type ScheduledTask string
+
+const (
+	TaskConsolidate ScheduledTask = "consolidate" // Archive paused and closed topics idle for a month
+	TaskPrune       ScheduledTask = "prune"       // Evict archived topics to stay under max_db_size
+	TaskDigest      ScheduledTask = "digest"      // Write a Markdown digest of new topics and facts
+	TaskReembed     ScheduledTask = "reembed"     // Embed turns still missing embeddings from the configured model
+	TaskSync        ScheduledTask = "sync"        // Sync the Markdown vault in vault_dir
+	TaskReview      ScheduledTask = "review"      // List profile preferences and topics not reinforced in profile_review_days
+)
+
+// ScheduledTasks lists every task, in the order they are documented
+var ScheduledTasks = []ScheduledTask{TaskConsolidate, TaskPrune, TaskDigest, TaskReembed, TaskSync, TaskReview}
+
+// ParseScheduledTask validates a scheduled task name
+func ParseScheduledTask(s string) (ScheduledTask, error) {
+	for _, task := range ScheduledTasks {
+		if string(task) == s {
+			return task, nil
+		}
+	}
+	names := make([]string, len(ScheduledTasks))
+	for i, task := range ScheduledTasks {
+		names[i] = string(task)
+	}
+	return "", fmt.Errorf("invalid scheduled task %q (must be one of %s)", s, strings.Join(names, ", "))
+}
+
+// ScheduledRun is one run of a scheduled task
+type ScheduledRun struct {
+	ID           int64         `json:"id"`
+	Task         ScheduledTask `json:"task"`
+	ScheduledFor time.Time     `json:"scheduled_for"` // The slot the schedule fired for; each slot runs once
+	Status       JobStatus     `json:"status"`        // running, done, or failed
+	Summary      string        `json:"summary,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	StartedAt    time.Time     `json:"started_at"`
+	FinishedAt   *time.Time    `json:"finished_at,omitempty"`
+}
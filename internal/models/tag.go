@@ -0,0 +1,49 @@
+// ABOUTME: Tag normalization for labels on blocks and facts
+// ABOUTME: Tags are lowercase slugs like "project-x" or "work/q3"; a leading + or # is dropped
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MaxTagLength is the longest tag accepted
+const MaxTagLength = 64
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_:/.-]*$`)
+
+// NormalizeTag lowercases and trims a tag, dropping a leading + or #, and validates it
+func NormalizeTag(tag string) (string, error) {
+	t := strings.ToLower(strings.TrimSpace(tag))
+	t = strings.TrimLeft(t, "+#")
+	if t == "" {
+		return "", fmt.Errorf("tag cannot be empty")
+	}
+	if len(t) > MaxTagLength {
+		return "", fmt.Errorf("tag %q is longer than %d characters", tag, MaxTagLength)
+	}
+	if !tagPattern.MatchString(t) {
+		return "", fmt.Errorf("invalid tag %q: use letters, digits, and - _ : / .", tag)
+	}
+	return t, nil
+}
+
+// NormalizeTags normalizes every tag, returning them sorted with duplicates removed
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	var out []string
+	for _, tag := range tags {
+		t, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
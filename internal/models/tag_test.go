@@ -0,0 +1,45 @@
+// ABOUTME: Tests for tag normalization
+// ABOUTME: Verifies casing, prefixes, deduplication, and rejection of invalid tags
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"project-x", "project-x", false},
+		{"  Project-X ", "project-x", false},
+		{"+work/q3", "work/q3", false},
+		{"#Health", "health", false},
+		{"", "", true},
+		{"+", "", true},
+		{"has space", "", true},
+		{"-leading", "", true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeTag(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeTag(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeTag(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeTags_SortsAndDeduplicates(t *testing.T) {
+	got, err := NormalizeTags([]string{"zeta", "+Alpha", "alpha"})
+	if err != nil {
+		t.Fatalf("NormalizeTags() error = %v", err)
+	}
+	if want := []string{"alpha", "zeta"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeTags() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,202 @@
+// ABOUTME: Topic taxonomy: hierarchical topic labels, with aliases, that extracted topics are mapped onto
+// ABOUTME: Keeps labels consistent ("golang" and "Go programming" both become programming/go) so topics group by node
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TaxonomyNode is one label in a topic taxonomy
+type TaxonomyNode struct {
+	Path    string   `json:"path"`              // Lowercase segments separated by "/", e.g. "programming/go"
+	Aliases []string `json:"aliases,omitempty"` // Other labels that mean this node, e.g. "golang"
+}
+
+// Depth is how many ancestors the node has; top-level nodes have none
+func (n TaxonomyNode) Depth() int {
+	return strings.Count(n.Path, "/")
+}
+
+// Name is the node's last path segment
+func (n TaxonomyNode) Name() string {
+	return n.Path[strings.LastIndex(n.Path, "/")+1:]
+}
+
+// String renders the node the way ParseTaxonomy reads it
+func (n TaxonomyNode) String() string {
+	if len(n.Aliases) == 0 {
+		return n.Path
+	}
+	return n.Path + "=" + strings.Join(n.Aliases, ",")
+}
+
+// Taxonomy is a user's topic labels, sorted by path, with every ancestor of a node
+// present as a node of its own
+type Taxonomy []TaxonomyNode
+
+// TopicKey is how topic labels are compared: lowercase, with runs of spaces, hyphens,
+// and underscores as single spaces, so "Go-Programming" and "go programming" match
+func TopicKey(label string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(label), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_' || r == '\t'
+	}), " ")
+}
+
+// taxonomyPathKey is TopicKey applied to each segment of a path, so "Programming / Go"
+// names programming/go
+func taxonomyPathKey(label string) string {
+	segments := strings.Split(label, "/")
+	for i, segment := range segments {
+		segments[i] = TopicKey(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// Labels returns every node's path, for prompting a model with the taxonomy
+func (t Taxonomy) Labels() []string {
+	labels := make([]string, len(t))
+	for i, n := range t {
+		labels[i] = n.Path
+	}
+	return labels
+}
+
+// Node returns the path of the node label names: its full path, its last segment when
+// no other node shares that name, or one of its aliases. It returns "" for a label
+// outside the taxonomy.
+func (t Taxonomy) Node(label string) string {
+	key, path := TopicKey(label), taxonomyPathKey(label)
+	if key == "" {
+		return ""
+	}
+	var byName []string
+	for _, n := range t {
+		if taxonomyPathKey(n.Path) == path {
+			return n.Path
+		}
+		for _, alias := range n.Aliases {
+			if TopicKey(alias) == key {
+				return n.Path
+			}
+		}
+		if TopicKey(n.Name()) == key {
+			byName = append(byName, n.Path)
+		}
+	}
+	if len(byName) == 1 {
+		return byName[0]
+	}
+	return ""
+}
+
+// Under reports whether label names node or one of its descendants
+func (t Taxonomy) Under(label, node string) bool {
+	path := t.Node(label)
+	return path != "" && (path == node || strings.HasPrefix(path, node+"/"))
+}
+
+// MapTopics maps each topic onto the node it names. A topic outside the taxonomy that
+// matches one of known, the labels already in use, by TopicKey takes that label, so
+// labels stay consistent even without a taxonomy; any other topic is kept as it is.
+// Duplicates after mapping are dropped.
+func (t Taxonomy) MapTopics(topics, known []string) []string {
+	inUse := make(map[string]string, len(known))
+	for _, label := range known {
+		if key := TopicKey(label); key != "" {
+			if _, ok := inUse[key]; !ok {
+				inUse[key] = label
+			}
+		}
+	}
+	mapped := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		topic = strings.TrimSpace(topic)
+		if path := t.Node(topic); path != "" {
+			topic = path
+		} else if label, ok := inUse[TopicKey(topic)]; ok {
+			topic = label
+		}
+		if topic != "" && !slices.Contains(mapped, topic) {
+			mapped = append(mapped, topic)
+		}
+	}
+	return mapped
+}
+
+// String renders the taxonomy the way ParseTaxonomy reads it, leaving out ancestors
+// that are only implied by their descendants
+func (t Taxonomy) String() string {
+	var parts []string
+	for i, n := range t {
+		implied := len(n.Aliases) == 0 && i+1 < len(t) && strings.HasPrefix(t[i+1].Path, n.Path+"/")
+		if !implied {
+			parts = append(parts, n.String())
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ParseTaxonomy parses "path[=alias,alias]" entries separated by semicolons, such as
+// "programming/go=golang,go programming; programming/python; home/garden=gardening".
+// Paths are lowercased, and each ancestor of a path ("programming") becomes a node too.
+func ParseTaxonomy(v string) (Taxonomy, error) {
+	nodes := map[string]*TaxonomyNode{}
+	aliasOf := map[string]string{}
+	for _, item := range strings.Split(v, ";") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		spec, aliases, _ := strings.Cut(item, "=")
+		path, err := parseTaxonomyPath(spec)
+		if err != nil {
+			return nil, err
+		}
+		for i := range strings.Split(path, "/") {
+			ancestor := strings.Join(strings.Split(path, "/")[:i+1], "/")
+			if nodes[ancestor] == nil {
+				nodes[ancestor] = &TaxonomyNode{Path: ancestor}
+			}
+		}
+		node := nodes[path]
+		for _, alias := range strings.Split(aliases, ",") {
+			if alias = strings.TrimSpace(alias); alias == "" {
+				continue
+			}
+			key := TopicKey(alias)
+			if other, ok := aliasOf[key]; ok && other != path {
+				return nil, fmt.Errorf("topic taxonomy alias %q names both %s and %s", alias, other, path)
+			}
+			if _, ok := aliasOf[key]; !ok {
+				aliasOf[key] = path
+				node.Aliases = append(node.Aliases, alias)
+			}
+		}
+	}
+
+	taxonomy := make(Taxonomy, 0, len(nodes))
+	for _, n := range nodes {
+		taxonomy = append(taxonomy, *n)
+	}
+	slices.SortFunc(taxonomy, func(a, b TaxonomyNode) int { return strings.Compare(a.Path, b.Path) })
+	for _, n := range taxonomy {
+		if other, ok := aliasOf[TopicKey(n.Path)]; ok && other != n.Path {
+			return nil, fmt.Errorf("topic taxonomy alias %q of %s is itself a node", n.Path, other)
+		}
+	}
+	return taxonomy, nil
+}
+
+// parseTaxonomyPath normalizes a node path: lowercase segments, each trimmed and with
+// inner runs of whitespace collapsed
+func parseTaxonomyPath(spec string) (string, error) {
+	segments := strings.Split(strings.ToLower(spec), "/")
+	for i, segment := range segments {
+		segments[i] = strings.Join(strings.Fields(segment), " ")
+		if segments[i] == "" {
+			return "", fmt.Errorf("topic taxonomy node %q must be segments separated by /, e.g. programming/go", strings.TrimSpace(spec))
+		}
+	}
+	return strings.Join(segments, "/"), nil
+}
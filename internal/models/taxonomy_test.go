@@ -0,0 +1,79 @@
+// ABOUTME: Tests for the topic taxonomy
+// ABOUTME: Verifies parsing, rendering, mapping labels and aliases onto nodes, and grouping under a node
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseTaxonomy(t *testing.T) {
+	taxonomy, err := ParseTaxonomy(" Programming/Go=golang, Go programming; programming / python ;home/garden=gardening; ")
+	if err != nil {
+		t.Fatalf("ParseTaxonomy: %v", err)
+	}
+	want := []string{"home", "home/garden", "programming", "programming/go", "programming/python"}
+	if !slices.Equal(taxonomy.Labels(), want) {
+		t.Errorf("Labels() = %v, want %v", taxonomy.Labels(), want)
+	}
+	if got, want := taxonomy.String(), "home/garden=gardening; programming/go=golang,Go programming; programming/python"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if round, err := ParseTaxonomy(taxonomy.String()); err != nil || round.String() != taxonomy.String() {
+		t.Errorf("ParseTaxonomy(String()) = %q, %v; want the same taxonomy", round.String(), err)
+	}
+	if empty, err := ParseTaxonomy(""); err != nil || len(empty) != 0 {
+		t.Errorf("ParseTaxonomy(\"\") = %v, %v; want none", empty, err)
+	}
+
+	for _, bad := range []string{"programming//go", "/go", "a=x; b=x", "a=b; b"} {
+		if _, err := ParseTaxonomy(bad); err == nil {
+			t.Errorf("ParseTaxonomy(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestTaxonomy_Node(t *testing.T) {
+	taxonomy, err := ParseTaxonomy("programming/go=golang,go programming; programming/python; work/notes; home/notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"Programming / Go": "programming/go",
+		"golang":           "programming/go",
+		"Go-Programming":   "programming/go",
+		"Python":           "programming/python",
+		"programming":      "programming",
+		"notes":            "", // Two nodes are named notes
+		"work/notes":       "work/notes",
+		"gardening":        "",
+	}
+	for label, want := range tests {
+		if got := taxonomy.Node(label); got != want {
+			t.Errorf("Node(%q) = %q, want %q", label, got, want)
+		}
+	}
+
+	if !taxonomy.Under("golang", "programming") || !taxonomy.Under("python", "programming/python") {
+		t.Error("Under() = false for labels in the node")
+	}
+	if taxonomy.Under("golang", "program") || taxonomy.Under("gardening", "programming") {
+		t.Error("Under() = true for a label outside the node")
+	}
+}
+
+func TestTaxonomy_MapTopics(t *testing.T) {
+	taxonomy, err := ParseTaxonomy("programming/go=golang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := taxonomy.MapTopics([]string{"Golang", "go", "Home Garden", "recipes"}, []string{"home-garden"})
+	want := []string{"programming/go", "home-garden", "recipes"}
+	if !slices.Equal(got, want) {
+		t.Errorf("MapTopics() = %v, want %v", got, want)
+	}
+	// Without a taxonomy, labels in use still keep topics consistent
+	if got := Taxonomy(nil).MapTopics([]string{"Go Programming"}, []string{"go programming"}); !slices.Equal(got, []string{"go programming"}) {
+		t.Errorf("MapTopics() without a taxonomy = %v", got)
+	}
+}
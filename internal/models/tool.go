@@ -0,0 +1,44 @@
+// ABOUTME: Names of the MCP tools a server can offer
+// ABOUTME: Lets configuration name tools to disable without depending on the server package
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToolNames lists every MCP tool, in the order servers register them
+var ToolNames = []string{
+	"store_conversation",
+	"retrieve_memory",
+	"list_active_topics",
+	"get_topic_history",
+	"get_user_profile",
+	"update_user_profile",
+	"add_fact",
+	"get_fact",
+	"delete_fact",
+	"archive_topic",
+	"delete_topic",
+	"tag_memory",
+	"get_related_topics",
+	"health",
+	"exclude_from_recall",
+	"update_turn",
+	"archive_topics",
+	"review_facts",
+	"get_memory_health",
+	"resume_topic",
+	"rate_memory",
+	"get_profile_diff",
+}
+
+// ParseToolName validates an MCP tool name
+func ParseToolName(s string) (string, error) {
+	for _, name := range ToolNames {
+		if name == s {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("invalid tool %q (must be one of %s)", s, strings.Join(ToolNames, ", "))
+}
@@ -4,11 +4,8 @@ package models
 
 import (
 	"errors"
-	"fmt"
 	"strings"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 // Turn represents a single conversation turn
@@ -19,6 +16,31 @@ type Turn struct {
 	AIResponse  string    `json:"ai_response"`
 	Keywords    []string  `json:"keywords,omitempty"`
 	Topics      []string  `json:"topics,omitempty"`
+	Affect      Affect    `json:"affect,omitempty"`   // Emotional tone from metadata extraction; empty if unknown
+	Messages    []Message `json:"messages,omitempty"` // Full transcript with roles; empty for a plain user/AI pair
+	Origin      Origin    `json:"origin,omitzero"`    // Device and workspace the turn came from
+
+	Annotations []TurnAnnotation `json:"annotations,omitempty"` // Notes about the turn, oldest first
+	EditedAt    *time.Time       `json:"edited_at,omitempty"`   // When its text was last corrected
+
+	// PendingEnrichment is set while metadata, embeddings, or facts for the turn wait on
+	// an LLM that was missing or failing; queued jobs fill them in and clear it
+	PendingEnrichment bool `json:"pending_enrichment,omitempty"`
+}
+
+// TurnAnnotation is a note attached to a stored turn, such as "this was wrong"
+type TurnAnnotation struct {
+	Text      string    `json:"text"`
+	Agent     string    `json:"agent,omitempty"` // Who added it
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TurnEdit corrects a stored turn's text and/or annotates it; nil texts are left as they are
+type TurnEdit struct {
+	UserMessage *string
+	AIResponse  *string
+	Annotation  string
+	Agent       string // Recorded on the annotation
 }
 
 // NewTurn creates a new Turn with validation
@@ -28,7 +50,7 @@ func NewTurn(userMessage, aiResponse string, keywords, topics []string) (*Turn,
 	}
 	return &Turn{
 		TurnID:      generateTurnID(),
-		Timestamp:   time.Now().UTC(),
+		Timestamp:   Now().UTC(),
 		UserMessage: userMessage,
 		AIResponse:  aiResponse,
 		Keywords:    keywords,
@@ -38,5 +60,5 @@ func NewTurn(userMessage, aiResponse string, keywords, topics []string) (*Turn,
 
 // generateTurnID generates a unique turn identifier
 func generateTurnID() string {
-	return fmt.Sprintf("turn_%s_%s", time.Now().Format("20060102_150405"), uuid.New().String()[:8])
+	return NewID("turn")
 }
@@ -5,6 +5,7 @@ package models
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewTurn(t *testing.T) {
@@ -204,22 +205,12 @@ func TestTurn_TurnIDFormat(t *testing.T) {
 		t.Fatalf("NewTurn() error = %v", err)
 	}
 
-	// TurnID format should be: turn_YYYYMMDD_HHMMSS_<uuid>
+	// TurnID format should be: turn_<ULID>
 	parts := strings.Split(turn.TurnID, "_")
-	if len(parts) < 3 {
+	if len(parts) != 2 || parts[0] != "turn" || len(parts[1]) != 26 {
 		t.Errorf("TurnID format unexpected: %s", turn.TurnID)
 	}
-	if parts[0] != "turn" {
-		t.Errorf("TurnID should start with 'turn', got: %s", parts[0])
-	}
-
-	// Date part should be 8 digits
-	if len(parts) >= 2 && len(parts[1]) != 8 {
-		t.Errorf("TurnID date part should be 8 digits, got: %s", parts[1])
-	}
-
-	// Time part should be 6 digits
-	if len(parts) >= 3 && len(parts[2]) != 6 {
-		t.Errorf("TurnID time part should be 6 digits, got: %s", parts[2])
+	if created, ok := IDTime(turn.TurnID); !ok || created.Sub(turn.Timestamp).Abs() > time.Second {
+		t.Errorf("IDTime(%s) = %v, %v; want about %v", turn.TurnID, created, ok, turn.Timestamp)
 	}
 }
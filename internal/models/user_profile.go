@@ -3,15 +3,85 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
+// Constraint severities, from hard limits to soft ones
+const (
+	SeverityStrict   = "strict"   // Must never be violated (allergies, dietary rules)
+	SeverityModerate = "moderate" // Should be honored unless the user overrides it
+	SeverityMild     = "mild"     // A leaning rather than a rule
+)
+
+// ProfileConstraint is a rule about the user that answers must respect,
+// such as a dietary restriction or an accessibility need
+type ProfileConstraint struct {
+	Type        string `json:"type"`               // e.g. "Dietary Restriction"
+	Description string `json:"description"`        // e.g. "Strictly vegetarian, no meat or fish"
+	Severity    string `json:"severity,omitempty"` // strict, moderate, or mild; empty means unspecified
+}
+
 // UserProfile represents user context and preferences
 type UserProfile struct {
-	Name             string    `json:"name"`
-	Preferences      []string  `json:"preferences,omitempty"`
-	TopicsOfInterest []string  `json:"topics_of_interest,omitempty"`
-	LastUpdated      time.Time `json:"last_updated"`
+	Name             string              `json:"name"`
+	Preferences      []string            `json:"preferences,omitempty"`
+	TopicsOfInterest []string            `json:"topics_of_interest,omitempty"`
+	Constraints      []ProfileConstraint `json:"constraints,omitempty"`
+	Persona          string              `json:"persona,omitempty"` // Persona layered into this view, if any; see WithPersona
+	LastUpdated      time.Time           `json:"last_updated"`
+}
+
+// Validate checks that a constraint has a type and description and a known severity
+func (c ProfileConstraint) Validate() error {
+	if strings.TrimSpace(c.Type) == "" || strings.TrimSpace(c.Description) == "" {
+		return fmt.Errorf("constraint type and description cannot be empty")
+	}
+	switch c.Severity {
+	case "", SeverityStrict, SeverityModerate, SeverityMild:
+		return nil
+	default:
+		return fmt.Errorf("invalid constraint severity %q (want %s, %s, or %s)", c.Severity, SeverityStrict, SeverityModerate, SeverityMild)
+	}
+}
+
+// String renders a constraint for prompts, e.g. "Dietary Restriction: vegetarian (strict)"
+func (c ProfileConstraint) String() string {
+	if c.Severity == "" {
+		return fmt.Sprintf("%s: %s", c.Type, c.Description)
+	}
+	return fmt.Sprintf("%s: %s (%s)", c.Type, c.Description, c.Severity)
+}
+
+// AddConstraint adds c to the profile, or updates the severity of an existing constraint
+// with the same type and description (compared case-insensitively)
+func (up *UserProfile) AddConstraint(c ProfileConstraint) error {
+	constraints, err := addConstraint(up.Constraints, c)
+	if err != nil {
+		return err
+	}
+	up.Constraints = constraints
+	return nil
+}
+
+// addConstraint validates c and adds it to constraints, updating the severity of a match in place
+func addConstraint(constraints []ProfileConstraint, c ProfileConstraint) ([]ProfileConstraint, error) {
+	c.Type = strings.TrimSpace(c.Type)
+	c.Description = strings.TrimSpace(c.Description)
+	c.Severity = strings.ToLower(strings.TrimSpace(c.Severity))
+	if err := c.Validate(); err != nil {
+		return constraints, err
+	}
+	for i, existing := range constraints {
+		if strings.EqualFold(existing.Type, c.Type) && strings.EqualFold(existing.Description, c.Description) {
+			if c.Severity != "" {
+				constraints[i].Severity = c.Severity
+			}
+			return constraints, nil
+		}
+	}
+	return append(constraints, c), nil
 }
 
 // Merge intelligently merges new user info into the profile
@@ -44,8 +114,32 @@ func (up *UserProfile) Merge(newInfo map[string]interface{}) {
 		}
 	}
 
+	// Merge constraints, given as objects with type, description, and severity.
+	// Invalid entries are skipped so one bad item does not lose the rest.
+	switch constraints := newInfo["constraints"].(type) {
+	case []ProfileConstraint:
+		for _, c := range constraints {
+			_ = up.AddConstraint(c)
+		}
+	case []interface{}:
+		for _, item := range constraints {
+			if m, ok := item.(map[string]interface{}); ok {
+				_ = up.AddConstraint(constraintFromMap(m))
+			}
+		}
+	}
+
 	// Update last_updated timestamp
-	up.LastUpdated = time.Now()
+	up.LastUpdated = Now()
+}
+
+// constraintFromMap reads a constraint from decoded JSON
+func constraintFromMap(m map[string]interface{}) ProfileConstraint {
+	str := func(key string) string {
+		s, _ := m[key].(string)
+		return s
+	}
+	return ProfileConstraint{Type: str("type"), Description: str("description"), Severity: str("severity")}
 }
 
 // contains checks if a string slice contains a specific string
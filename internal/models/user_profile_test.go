@@ -173,6 +173,52 @@ func TestUserProfile_Merge_TopicsOfInterest(t *testing.T) {
 	}
 }
 
+func TestUserProfile_Merge_Constraints(t *testing.T) {
+	up := &UserProfile{
+		Constraints: []ProfileConstraint{{Type: "Dietary Restriction", Description: "vegetarian", Severity: SeverityModerate}},
+	}
+
+	up.Merge(map[string]interface{}{
+		"constraints": []interface{}{
+			// Same constraint, different case: severity is updated in place
+			map[string]interface{}{"type": "dietary restriction", "description": "Vegetarian", "severity": "strict"},
+			map[string]interface{}{"type": "Accessibility", "description": "prefers large text"},
+			// Invalid entries are skipped
+			map[string]interface{}{"type": "Allergy"},
+			map[string]interface{}{"type": "Allergy", "description": "peanuts", "severity": "fatal"},
+			"not an object",
+		},
+	})
+
+	if len(up.Constraints) != 2 {
+		t.Fatalf("Constraints = %+v, want 2 entries", up.Constraints)
+	}
+	if up.Constraints[0].Severity != SeverityStrict {
+		t.Errorf("Constraints[0].Severity = %q, want %q", up.Constraints[0].Severity, SeverityStrict)
+	}
+	if up.Constraints[1].Type != "Accessibility" || up.Constraints[1].Severity != "" {
+		t.Errorf("Constraints[1] = %+v, want unspecified-severity Accessibility", up.Constraints[1])
+	}
+
+	up.Merge(map[string]interface{}{
+		"constraints": []ProfileConstraint{{Type: "Allergy", Description: "peanuts", Severity: SeverityStrict}},
+	})
+	if len(up.Constraints) != 3 {
+		t.Errorf("Constraints length = %d, want 3", len(up.Constraints))
+	}
+}
+
+func TestProfileConstraint_String(t *testing.T) {
+	c := ProfileConstraint{Type: "Dietary Restriction", Description: "vegetarian", Severity: SeverityStrict}
+	if got, want := c.String(), "Dietary Restriction: vegetarian (strict)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	c.Severity = ""
+	if got, want := c.String(), "Dietary Restriction: vegetarian"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
 func TestUserProfile_Merge_UpdatesTimestamp(t *testing.T) {
 	up := &UserProfile{
 		LastUpdated: time.Now().Add(-1 * time.Hour),
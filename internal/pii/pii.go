@@ -0,0 +1,117 @@
+// ABOUTME: Regex detectors for personal data in stored text
+// ABOUTME: Finds emails, phone numbers, SSNs, card numbers, IP addresses, and credentials by offset
+package pii
+
+import (
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/harper/remember-standalone/internal/redact"
+)
+
+// Categories the regex detectors report
+const (
+	CategoryCredential = "credential"
+	CategoryEmail      = "email"
+	CategorySSN        = "ssn"
+	CategoryCreditCard = "credit_card"
+	CategoryPhone      = "phone"
+	CategoryIPAddress  = "ip_address"
+)
+
+// Match is personal data found at text[Start:End]
+type Match struct {
+	Category string
+	Start    int
+	End      int
+}
+
+// detector finds one category; valid, when set, rejects matches of the right
+// shape that are not real, such as card numbers failing the Luhn check
+type detector struct {
+	category string
+	pattern  *regexp.Regexp
+	valid    func(string) bool
+}
+
+// detectors run in priority order: text claimed by one is not reported by a later one
+var detectors = []detector{
+	{CategoryEmail, regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`), nil},
+	{CategorySSN, regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), validSSN},
+	{CategoryCreditCard, regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), luhn},
+	{CategoryPhone, regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{3}\)\s?|\b\d{3}[\s.-])\d{3}[\s.-]\d{4}\b`), nil},
+	{CategoryIPAddress, regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), validIP},
+}
+
+// Detect finds personal data in text, ordered by offset. Credentials are found
+// with the same patterns the log redactor uses.
+func Detect(text string) []Match {
+	var matches []Match
+	claimed := func(start, end int) bool {
+		for _, m := range matches {
+			if start < m.End && m.Start < end {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, span := range redact.Locate(text) {
+		if !claimed(span[0], span[1]) {
+			matches = append(matches, Match{Category: CategoryCredential, Start: span[0], End: span[1]})
+		}
+	}
+	for _, d := range detectors {
+		for _, loc := range d.pattern.FindAllStringIndex(text, -1) {
+			if claimed(loc[0], loc[1]) || (d.valid != nil && !d.valid(text[loc[0]:loc[1]])) {
+				continue
+			}
+			matches = append(matches, Match{Category: d.category, Start: loc[0], End: loc[1]})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+	return matches
+}
+
+// Preview masks a found value for display, keeping the first and last two
+// characters of values long enough that this does not give them away
+func Preview(value string) string {
+	if utf8.RuneCountInString(value) < 8 {
+		return redact.Mask
+	}
+	runes := []rune(value)
+	return string(runes[:2]) + redact.Mask + string(runes[len(runes)-2:])
+}
+
+// validSSN rejects numbers the SSA never issues
+func validSSN(s string) bool {
+	area, group, serial := s[0:3], s[4:6], s[7:11]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum used by card numbers
+func luhn(s string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(s)
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// validIP accepts addresses that identify a host, rejecting loopback and 0.0.0.0
+func validIP(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && !ip.IsLoopback() && !ip.IsUnspecified()
+}
@@ -0,0 +1,71 @@
+// ABOUTME: Tests for the personal data detectors
+// ABOUTME: Verifies each category, the validators that reject look-alikes, and masked previews
+
+package pii
+
+import (
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		category string // empty when nothing should be found
+		found    string
+	}{
+		{"email", "write to jane.doe@example.co.uk today", CategoryEmail, "jane.doe@example.co.uk"},
+		{"ssn", "my SSN is 123-45-6789", CategorySSN, "123-45-6789"},
+		{"unissued ssn", "ticket 666-12-3456", "", ""},
+		{"card", "card 4111 1111 1111 1111 exp 12/29", CategoryCreditCard, "4111 1111 1111 1111"},
+		{"card failing luhn", "order 4111 1111 1111 1112", "", ""},
+		{"phone", "call (312) 555-0142 after six", CategoryPhone, "(312) 555-0142"},
+		{"international phone", "cell +1 312-555-0142", CategoryPhone, "+1 312-555-0142"},
+		{"ip", "ssh into 203.0.113.7 first", CategoryIPAddress, "203.0.113.7"},
+		{"loopback", "listening on 127.0.0.1", "", ""},
+		{"invalid ip", "version 999.1.2.3", "", ""},
+		{"credential", "password=hunter2hunter2", CategoryCredential, "hunter2hunter2"},
+		{"plain text", "we talked about Go generics", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := Detect(tt.in)
+			if tt.category == "" {
+				if len(matches) != 0 {
+					t.Errorf("Detect(%q) = %+v, want nothing", tt.in, matches)
+				}
+				return
+			}
+			if len(matches) != 1 {
+				t.Fatalf("Detect(%q) = %+v, want one match", tt.in, matches)
+			}
+			m := matches[0]
+			if m.Category != tt.category || tt.in[m.Start:m.End] != tt.found {
+				t.Errorf("Detect(%q) = %s %q, want %s %q", tt.in, m.Category, tt.in[m.Start:m.End], tt.category, tt.found)
+			}
+		})
+	}
+}
+
+func TestDetect_Ordered(t *testing.T) {
+	in := "ip 203.0.113.7, mail a.b@example.com, key sk-proj-abcdefghijklmnopqrstuvwx"
+	matches := Detect(in)
+	want := []string{CategoryIPAddress, CategoryEmail, CategoryCredential}
+	if len(matches) != len(want) {
+		t.Fatalf("Detect() = %+v, want %v", matches, want)
+	}
+	for i, m := range matches {
+		if m.Category != want[i] {
+			t.Errorf("match %d = %s, want %s (ordered by offset)", i, m.Category, want[i])
+		}
+	}
+}
+
+func TestPreview(t *testing.T) {
+	if got := Preview("jane.doe@example.com"); got != "ja********om" {
+		t.Errorf("Preview() = %q, want the ends kept", got)
+	}
+	if got := Preview("1234567"); got != "********" {
+		t.Errorf("Preview() = %q, want short values fully masked", got)
+	}
+}
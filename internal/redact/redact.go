@@ -0,0 +1,127 @@
+// ABOUTME: Masks credentials in text bound for logs, debug output, and tool responses
+// ABOUTME: Matches well-known token formats, key=value credentials, and registered secret fact values
+package redact
+
+import (
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask replaces every redacted value
+const Mask = "********"
+
+// minRegistered is the shortest registered value that is masked; shorter ones
+// would mask ordinary words and numbers wherever they appear
+const minRegistered = 6
+
+// tokenPatterns match credentials by their shape alone
+var tokenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`),                                        // OpenAI and Anthropic API keys
+	regexp.MustCompile(`\b[rs]k_(?:live|test)_[A-Za-z0-9]{10,}`),                         // Stripe keys
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),                                  // AWS access key IDs
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{30,}`),                                   // GitHub tokens
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{20,}`),                                 // GitHub fine-grained tokens
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),                                 // Slack tokens
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`),                                        // Google API keys
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]+`), // JWTs
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+}
+
+// prefixedPatterns keep their first group (the label) and mask the rest
+var prefixedPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9._~+/-]{8,}=*`),
+	regexp.MustCompile(`(?i)(\b(?:api[_-]?key|access[_-]?token|auth[_-]?token|refresh[_-]?token|client[_-]?secret|secret[_-]?key|password|passwd|token)"?\s*[:=]\s*"?)[^\s"',;&]{6,}`),
+}
+
+var (
+	mu         sync.RWMutex
+	registered = map[string]bool{}
+	replacer   *strings.Replacer // masks registered values; nil when there are none
+)
+
+// Register adds values that must always be masked, such as the values of secret facts.
+// Values shorter than six characters are ignored.
+func Register(values ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	changed := false
+	for _, value := range values {
+		if len(value) >= minRegistered && !registered[value] {
+			registered[value] = true
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	// Longest first, so a value containing another is masked whole
+	sorted := make([]string, 0, len(registered))
+	for value := range registered {
+		sorted = append(sorted, value)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	pairs := make([]string, 0, 2*len(sorted))
+	for _, value := range sorted {
+		pairs = append(pairs, value, Mask)
+	}
+	replacer = strings.NewReplacer(pairs...)
+}
+
+// String masks every credential in s
+func String(s string) string {
+	mu.RLock()
+	r := replacer
+	mu.RUnlock()
+	if r != nil {
+		s = r.Replace(s)
+	}
+	for _, pattern := range tokenPatterns {
+		s = pattern.ReplaceAllString(s, Mask)
+	}
+	for _, pattern := range prefixedPatterns {
+		s = pattern.ReplaceAllString(s, "${1}"+Mask)
+	}
+	return s
+}
+
+// Locate returns the start and end offsets of each credential String would mask
+// by its shape, ignoring registered values. Offsets of a labelled credential
+// cover the value only. Spans may overlap.
+func Locate(s string) [][2]int {
+	var spans [][2]int
+	for _, pattern := range tokenPatterns {
+		for _, loc := range pattern.FindAllStringIndex(s, -1) {
+			spans = append(spans, [2]int{loc[0], loc[1]})
+		}
+	}
+	for _, pattern := range prefixedPatterns {
+		for _, loc := range pattern.FindAllStringSubmatchIndex(s, -1) {
+			spans = append(spans, [2]int{loc[3], loc[1]})
+		}
+	}
+	return spans
+}
+
+// writer masks each write before passing it on
+type writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a writer that masks credentials in each write. Loggers write
+// one entry at a time, so wrap a logger's output with it; a credential split
+// across two writes is not caught.
+func NewWriter(w io.Writer) io.Writer {
+	return writer{w: w}
+}
+
+// Write reports len(p) on success, even when masking changed the length
+func (w writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, String(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,103 @@
+// ABOUTME: GET /v1/events streams memory events from storage's change feed as server-sent events
+// ABOUTME: 'memory watch' reads it; fact values are masked as they are for webhooks
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/events"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// keepAliveInterval is how often an idle event stream sends a comment, so proxies and
+// clients can tell a quiet stream from a dead one
+const keepAliveInterval = 30 * time.Second
+
+// shutdownKey holds, in a request's context, a channel closed when the server shuts down
+type shutdownKey struct{}
+
+// shuttingDown returns the channel closed when the server serving r shuts down; it is
+// nil, so never ready, for handlers served some other way
+func shuttingDown(r *http.Request) <-chan struct{} {
+	done, _ := r.Context().Value(shutdownKey{}).(<-chan struct{})
+	return done
+}
+
+// ParseEventTypes parses comma-separated event type names, as the types parameter of
+// GET /v1/events takes them
+func ParseEventTypes(list string) ([]models.EventType, error) {
+	var types []models.EventType
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		t, err := models.ParseEventType(name)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// events streams the events published from now on, limited to the types query
+// parameter when given, until the client disconnects or the server shuts down.
+// Each is an SSE message named for its type whose data is the event as JSON.
+func (a *api) events(w http.ResponseWriter, r *http.Request) {
+	types, err := ParseEventTypes(r.URL.Query().Get("types"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	sub := a.store.Subscribe(storage.SubscribeOptions{Types: types})
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	var dropped int64
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-shuttingDown(r):
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			// A client too slow to keep up is told how much it missed
+			if n := sub.Dropped(); n > dropped {
+				_, _ = fmt.Fprintf(w, ": dropped %d events\n\n", n-dropped)
+				dropped = n
+			}
+			data, err := json.Marshal(events.Mask(event))
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
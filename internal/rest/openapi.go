@@ -0,0 +1,188 @@
+// ABOUTME: OpenAPI 3 document for the REST API, served at /openapi.json
+// ABOUTME: Parameters and request bodies come from the MCP tool schemas, so the document tracks the tools
+package rest
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// openAPI serves the document for the routes this server offers; write routes
+// are left out when memory is served read-only, as are routes of disabled tools
+func (a *api) openAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.document())
+}
+
+// document builds the OpenAPI document
+func (a *api) document() map[string]any {
+	paths := map[string]map[string]any{}
+	for _, rt := range routes {
+		var operation map[string]any
+		if rt.tool == "" {
+			operation = hydrateOperation(rt)
+		} else {
+			tool := a.tools.GetTool(rt.tool)
+			if tool == nil {
+				continue
+			}
+			operation = toolOperation(rt, tool.Tool.Description, tool.Tool.InputSchema.Properties, tool.Tool.InputSchema.Required)
+		}
+		if paths[rt.path] == nil {
+			paths[rt.path] = map[string]any{}
+		}
+		paths[rt.path][strings.ToLower(rt.method)] = operation
+	}
+
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "Memory REST API",
+			"version":     "1",
+			"description": "Read and write HMLR memory over HTTP. Routes other than hydrate_context call the MCP tool named in their description. Errors are {\"error\": message}.",
+		},
+		"paths": paths,
+	}
+	if a.opts.Token != "" || a.opts.ReadToken != "" {
+		doc["components"] = map[string]any{
+			"securitySchemes": map[string]any{"bearer": map[string]any{"type": "http", "scheme": "bearer"}},
+		}
+		doc["security"] = []any{map[string]any{"bearer": []string{}}}
+	}
+	return doc
+}
+
+// toolOperation describes a route backed by a tool: path wildcards become path
+// parameters, and the tool's other arguments query parameters or the JSON body
+func toolOperation(rt route, description string, properties map[string]any, required []string) map[string]any {
+	inPath := pathParams(rt.path)
+	var parameters []any
+	for _, name := range inPath {
+		parameters = append(parameters, map[string]any{
+			"name": name, "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+		})
+	}
+
+	rest := map[string]any{}
+	var restRequired []string
+	for name, schema := range properties {
+		if !slices.Contains(inPath, name) {
+			rest[name] = schema
+		}
+	}
+	for _, name := range required {
+		if !slices.Contains(inPath, name) {
+			restRequired = append(restRequired, name)
+		}
+	}
+
+	operation := map[string]any{
+		"operationId": operationID(rt),
+		"summary":     rt.summary,
+		"description": description + " (MCP tool " + rt.tool + ")",
+		"responses":   toolResponses(),
+	}
+	if rt.method == http.MethodGet || rt.method == http.MethodDelete {
+		names := make([]string, 0, len(rest))
+		for name := range rest {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			schema := rest[name]
+			parameter := map[string]any{"name": name, "in": "query", "required": slices.Contains(restRequired, name), "schema": schema}
+			if kind, _ := schemaType(schema); kind == "object" {
+				parameter["description"] = "JSON-encoded object"
+			} else if kind == "array" {
+				parameter["description"] = "Repeat the parameter or separate values with commas"
+			}
+			parameters = append(parameters, parameter)
+		}
+	} else if len(rest) > 0 {
+		body := map[string]any{"type": "object", "properties": rest}
+		if len(restRequired) > 0 {
+			body["required"] = restRequired
+		}
+		operation["requestBody"] = map[string]any{
+			"required": len(restRequired) > 0,
+			"content":  map[string]any{"application/json": map[string]any{"schema": body}},
+		}
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+	return operation
+}
+
+// hydrateOperation describes the context route
+func hydrateOperation(rt route) map[string]any {
+	return map[string]any{
+		"operationId": operationID(rt),
+		"summary":     rt.summary,
+		"parameters": []any{map[string]any{
+			"name": "block_id", "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+		}},
+		"requestBody": map[string]any{
+			"required": true,
+			"content": map[string]any{"application/json": map[string]any{"schema": map[string]any{
+				"type":     "object",
+				"required": []string{"message"},
+				"properties": map[string]any{
+					"message":    map[string]any{"type": "string", "description": "The new user message"},
+					"max_tokens": map[string]any{"type": "integer", "description": "Prompt budget in tokens", "default": DefaultContextTokens},
+					"persona":    map[string]any{"type": "string", "description": "Profile persona to include; defaults to the server's persona"},
+				},
+			}}},
+		},
+		"responses": map[string]any{
+			"200": jsonResponse("The assembled prompt", map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"block_id": map[string]any{"type": "string"},
+					"prompt":   map[string]any{"type": "string"},
+				},
+			}),
+			"404": jsonResponse("Topic not found", errorSchema()),
+		},
+	}
+}
+
+// toolResponses are the responses every tool route can give
+func toolResponses() map[string]any {
+	return map[string]any{
+		"200": jsonResponse("The tool's result", map[string]any{"type": "object"}),
+		"400": jsonResponse("Invalid arguments, or the tool failed", errorSchema()),
+		"403": jsonResponse("Memory is served read-only, or the tool is disabled", errorSchema()),
+		"404": jsonResponse("Not found", map[string]any{"type": "object"}),
+	}
+}
+
+// jsonResponse describes a JSON response
+func jsonResponse(description string, schema map[string]any) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content":     map[string]any{"application/json": map[string]any{"schema": schema}},
+	}
+}
+
+// errorSchema is the schema of {"error": message}
+func errorSchema() map[string]any {
+	return map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"error": map[string]any{"type": "string"}},
+	}
+}
+
+// operationID names a route's operation after its tool; the two tag routes are
+// told apart by what they tag
+func operationID(rt route) string {
+	switch {
+	case rt.tool == "":
+		return "hydrate_context"
+	case rt.tool == "tag_memory" && strings.Contains(rt.path, "{fact_key}"):
+		return "tag_fact"
+	case rt.tool == "tag_memory":
+		return "tag_topic"
+	}
+	return rt.tool
+}
@@ -0,0 +1,394 @@
+// ABOUTME: REST API over the MCP tools for clients that do not speak MCP
+// ABOUTME: Each route calls a registered tool, so read-only mode, telemetry, tracing, and masking apply unchanged
+package rest
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// maxBodyBytes bounds request bodies; attachments are limited to 64KB each
+const maxBodyBytes = 4 << 20
+
+// DefaultContextTokens is the prompt budget for context hydration when a request gives none
+const DefaultContextTokens = 4000
+
+// Agent is recorded as the author of facts added through the API without an agent
+const Agent = "rest"
+
+// route maps an HTTP method and path to an MCP tool. Path wildcards are named
+// after the tool argument they fill; GET and DELETE routes take the remaining
+// arguments from the query string, others from a JSON object body.
+type route struct {
+	method  string
+	path    string
+	tool    string // empty for routes the API answers itself
+	summary string
+}
+
+// routes lists every endpoint in the order the OpenAPI document shows them
+var routes = []route{
+	{"GET", "/v1/memories", "retrieve_memory", "Search memories by meaning, tags, origin, and affect"},
+	{"POST", "/v1/memories", "store_conversation", "Store a conversation turn, routed to a topic"},
+	{"GET", "/v1/topics", "list_active_topics", "List active topics"},
+	{"GET", "/v1/topics/{block_id}", "get_topic_history", "Get a topic with its conversation history"},
+	{"DELETE", "/v1/topics/{block_id}", "delete_topic", "Delete a topic and everything in it"},
+	{"GET", "/v1/topics/{block_id}/related", "get_related_topics", "List topics linked to a topic"},
+	{"POST", "/v1/topics/{block_id}/archive", "archive_topic", "Archive a topic"},
+	{"POST", "/v1/topics/{block_id}/tags", "tag_memory", "Add or remove a topic's tags"},
+	{"POST", "/v1/topics/{block_id}/context", "", "Build a prompt for a new message in a topic from its history, related memories, facts, and the profile"},
+	{"POST", "/v1/facts", "add_fact", "Add a fact"},
+	{"GET", "/v1/facts/{key}", "get_fact", "Get the most recent fact with a key"},
+	{"DELETE", "/v1/facts/{key}", "delete_fact", "Delete every fact with a key"},
+	{"POST", "/v1/facts/{fact_key}/tags", "tag_memory", "Add or remove a fact's tags"},
+	{"GET", "/v1/profile", "get_user_profile", "Get the user profile"},
+	{"PATCH", "/v1/profile", "update_user_profile", "Update the user profile"},
+	{"GET", "/v1/health", "health", "Check storage, LLM, and job queue health"},
+}
+
+// Embedder generates the query embedding used to find related memories during context hydration
+type Embedder interface {
+	GenerateEmbedding(text string) ([]float64, error)
+}
+
+// Options configure the API; zero values disable what they control
+type Options struct {
+	Token          string              // bearer token every request must carry
+	ReadToken      string              // bearer token also accepted, but refused with 403 on routes that write
+	DefaultPersona string              // profile persona for context hydration when a request gives none
+	Embedder       Embedder            // enables related memories in hydrated context; leave nil, not a nil client, without one
+	Chat           http.Handler        // serves POST /v1/chat/completions and GET /v1/models when set
+	Facts          *core.FactWeighting // how hydrated context chooses facts; core.DefaultFactWeighting when nil
+}
+
+// api serves the routes
+type api struct {
+	tools *mcpserver.MCPServer
+	store *storage.Storage
+	opts  Options
+}
+
+// NewHandler returns the API's handler. Routes call the tools registered on
+// tools, so a read-only server answers write routes with 403, as does any
+// server for the routes of tools it disables.
+func NewHandler(tools *mcpserver.MCPServer, store *storage.Storage, opts Options) http.Handler {
+	a := &api{tools: tools, store: store, opts: opts}
+	mux := http.NewServeMux()
+	writes := map[string]bool{}
+	for _, rt := range routes {
+		pattern := rt.method + " " + rt.path
+		if rt.tool == "" {
+			mux.HandleFunc(pattern, a.hydrate)
+			continue
+		}
+		mux.HandleFunc(pattern, a.callTool(rt))
+		writes[pattern] = rt.method != http.MethodGet
+	}
+	mux.HandleFunc("GET /openapi.json", a.openAPI)
+	mux.HandleFunc("GET /v1/events", a.events)
+	if opts.Chat != nil {
+		mux.Handle("POST /v1/chat/completions", opts.Chat)
+		mux.Handle("GET /v1/models", opts.Chat)
+		writes["POST /v1/chat/completions"] = true // Stores the exchange as a turn
+	}
+	return a.authorize(mux, writes)
+}
+
+// Serve answers API requests on addr until the returned stop function is called
+func Serve(addr string, handler http.Handler) (func(), error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the REST API: %w", err)
+	}
+	// Shutdown waits for requests to finish, so event streams are told to end first
+	streams, endStreams := context.WithCancel(context.Background())
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return context.WithValue(context.Background(), shutdownKey{}, streams.Done())
+		},
+	}
+	server.RegisterOnShutdown(endStreams)
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[REST] %v", err)
+		}
+	}()
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}, nil
+}
+
+// IsLoopback reports whether addr (host:port) only accepts connections from this machine
+func IsLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// authorize rejects requests without a bearer token when one is configured, and
+// requests carrying the read token on the mux patterns writes marks as writing
+func (a *api) authorize(mux *http.ServeMux, writes map[string]bool) http.Handler {
+	if a.opts.Token == "" && a.opts.ReadToken == "" {
+		return mux
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		switch {
+		case a.opts.Token != "" && subtle.ConstantTimeCompare(got, []byte("Bearer "+a.opts.Token)) == 1:
+		case a.opts.ReadToken != "" && subtle.ConstantTimeCompare(got, []byte("Bearer "+a.opts.ReadToken)) == 1:
+			if _, pattern := mux.Handler(r); writes[pattern] {
+				writeError(w, http.StatusForbidden, "the read-only token cannot be used to write")
+				return
+			}
+		default:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="memory"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// callTool returns a handler that calls rt's tool with the request's arguments
+func (a *api) callTool(rt route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tool := a.tools.GetTool(rt.tool)
+		if tool == nil {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("%s is not enabled on this server", rt.tool))
+			return
+		}
+
+		args, err := requestArguments(r, rt, tool.Tool)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if _, ok := tool.Tool.InputSchema.Properties["agent"]; ok && args["agent"] == nil {
+			args["agent"] = Agent
+		}
+
+		var request mcp.CallToolRequest
+		request.Params.Name = rt.tool
+		request.Params.Arguments = args
+		request.Header = r.Header
+		result, err := tool.Handler(r.Context(), request)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeResult(w, result)
+	}
+}
+
+// requestArguments collects a tool call's arguments from the path, then the
+// query string or JSON body. Query values are converted to the types the tool's
+// schema declares; objects are given as JSON.
+func requestArguments(r *http.Request, rt route, tool mcp.Tool) (map[string]any, error) {
+	args := map[string]any{}
+	if r.Method == http.MethodGet || r.Method == http.MethodDelete {
+		for name, values := range r.URL.Query() {
+			value, err := queryValue(name, values, tool.InputSchema.Properties[name])
+			if err != nil {
+				return nil, err
+			}
+			args[name] = value
+		}
+	} else if r.ContentLength != 0 {
+		decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodyBytes))
+		if err := decoder.Decode(&args); err != nil {
+			return nil, fmt.Errorf("request body must be a JSON object: %v", err)
+		}
+		if args == nil {
+			args = map[string]any{}
+		}
+	}
+
+	for _, name := range pathParams(rt.path) {
+		args[name] = r.PathValue(name)
+	}
+	return args, nil
+}
+
+// queryValue converts a query parameter to the type its schema declares
+func queryValue(name string, values []string, schema any) (any, error) {
+	kind, _ := schemaType(schema)
+	switch kind {
+	case "number":
+		n, err := strconv.ParseFloat(values[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", name)
+		}
+		return n, nil
+	case "boolean":
+		b, err := strconv.ParseBool(values[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", name)
+		}
+		return b, nil
+	case "array":
+		var items []any
+		for _, value := range values {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		return items, nil
+	case "object":
+		var object map[string]any
+		if err := json.Unmarshal([]byte(values[0]), &object); err != nil {
+			return nil, fmt.Errorf("%s must be a JSON object", name)
+		}
+		return object, nil
+	default:
+		return values[0], nil
+	}
+}
+
+// schemaType returns the type declared by a property schema
+func schemaType(schema any) (string, bool) {
+	properties, ok := schema.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	kind, ok := properties["type"].(string)
+	return kind, ok
+}
+
+// pathParams returns the wildcard names in a route path
+func pathParams(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			names = append(names, strings.Trim(segment, "{}"))
+		}
+	}
+	return names
+}
+
+// hydrateRequest is the body of the context route
+type hydrateRequest struct {
+	Message   string `json:"message"`
+	MaxTokens int    `json:"max_tokens"`
+	Persona   string `json:"persona"`
+}
+
+// hydrate builds a prompt for a new message in a topic
+func (a *api) hydrate(w http.ResponseWriter, r *http.Request) {
+	var req hydrateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodyBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("request body must be a JSON object: %v", err))
+		return
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		writeError(w, http.StatusBadRequest, "message is required")
+		return
+	}
+	if req.MaxTokens <= 0 {
+		req.MaxTokens = DefaultContextTokens
+	}
+	if req.Persona == "" {
+		req.Persona = a.opts.DefaultPersona
+	}
+
+	blockID := r.PathValue("block_id")
+	block, err := a.store.GetBridgeBlock(blockID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if block == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("block %s not found", blockID))
+		return
+	}
+
+	hydrator := core.NewContextHydrator(a.store, a.opts.Embedder)
+	hydrator.SetPersona(req.Persona)
+	if a.opts.Facts != nil {
+		hydrator.SetFactWeighting(*a.opts.Facts)
+	}
+
+	prompt, err := hydrator.HydrateBridgeBlock(blockID, req.Message, req.MaxTokens)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"block_id": blockID, "prompt": prompt})
+}
+
+// writeResult writes a tool result: its JSON text on success; errors as 404
+// when something was not found and 400 otherwise. A lookup answering
+// {"found": false} is sent with 404.
+func writeResult(w http.ResponseWriter, result *mcp.CallToolResult) {
+	text := resultText(result)
+	if result.IsError {
+		status := http.StatusBadRequest
+		if strings.Contains(text, "not found") {
+			status = http.StatusNotFound
+		}
+		writeError(w, status, text)
+		return
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal([]byte(text), &body); err != nil {
+		writeJSON(w, http.StatusOK, map[string]any{"result": text})
+		return
+	}
+	status := http.StatusOK
+	if found, ok := body["found"].(bool); ok && !found {
+		status = http.StatusNotFound
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(text))
+}
+
+// resultText joins the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// writeError writes {"error": message} with status
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// writeJSON writes v as JSON with status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,330 @@
+// ABOUTME: Tests for the REST API
+// ABOUTME: Verifies routes call the MCP tools, status codes, read-only mode, the bearer and read tokens, events, and the OpenAPI document
+
+package rest
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/mcp"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// newTestAPI serves the tools registered over an in-memory store
+func newTestAPI(t *testing.T, readOnly bool, opts Options) http.Handler {
+	t.Helper()
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory: %v", err)
+	}
+	server := mcpserver.NewMCPServer("test", "0.0.0")
+	handlers := mcp.RegisterTools(server, store, core.NewGovernor(store), core.NewChunkEngine(), nil, nil, mcp.Options{ReadOnly: readOnly})
+	t.Cleanup(func() {
+		handlers.Shutdown()
+		_ = store.Close()
+	})
+	return NewHandler(server, store, opts)
+}
+
+// do sends a request and decodes the JSON response body
+func do(t *testing.T, h http.Handler, method, target, body string, header ...string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var decoded map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+		t.Fatalf("%s %s: response is not a JSON object: %v\n%s", method, target, err, rec.Body.String())
+	}
+	return rec.Code, decoded
+}
+
+func TestStoreThenListAndHydrate(t *testing.T) {
+	h := newTestAPI(t, false, Options{})
+
+	status, body := do(t, h, "POST", "/v1/memories", `{"message": "Planning a trip to Lisbon in May"}`)
+	if status != http.StatusOK {
+		t.Fatalf("POST /v1/memories = %d %v", status, body)
+	}
+	blockID, _ := body["block_id"].(string)
+	if blockID == "" {
+		t.Fatalf("store_conversation result has no block_id: %v", body)
+	}
+
+	status, body = do(t, h, "GET", "/v1/topics", "")
+	if status != http.StatusOK || !strings.Contains(mustJSON(t, body), blockID) {
+		t.Errorf("GET /v1/topics = %d %v, want the stored block %s", status, body, blockID)
+	}
+
+	status, body = do(t, h, "GET", "/v1/topics/"+blockID, "")
+	if status != http.StatusOK || !strings.Contains(mustJSON(t, body), "Lisbon") {
+		t.Errorf("GET /v1/topics/%s = %d %v, want the stored turn", blockID, status, body)
+	}
+
+	status, body = do(t, h, "POST", "/v1/topics/"+blockID+"/context", `{"message": "What month is the trip?"}`)
+	if status != http.StatusOK {
+		t.Fatalf("POST context = %d %v", status, body)
+	}
+	if prompt, _ := body["prompt"].(string); !strings.Contains(prompt, "Lisbon") || !strings.Contains(prompt, "What month is the trip?") {
+		t.Errorf("prompt does not include the history and the new message:\n%s", prompt)
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	h := newTestAPI(t, false, Options{})
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	if status, body := do(t, h, "GET", "/v1/events?types=turn_stored,nonsense", ""); status != http.StatusBadRequest {
+		t.Errorf("GET /v1/events with an unknown type = %d %v, want 400", status, body)
+	}
+
+	resp, err := http.Get(server.URL + "/v1/events?types=turn_stored")
+	if err != nil {
+		t.Fatalf("GET /v1/events error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("GET /v1/events = %d %s, want an event stream", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	_, body := do(t, h, "POST", "/v1/memories", `{"message": "Planning a trip to Lisbon in May"}`)
+	blockID, _ := body["block_id"].(string)
+
+	lines := bufio.NewScanner(resp.Body)
+	var message []string
+	for lines.Scan() && lines.Text() != "" {
+		message = append(message, lines.Text())
+	}
+	if len(message) != 3 || message[1] != "event: turn_stored" || !strings.Contains(message[2], `"block_id":"`+blockID+`"`) {
+		t.Errorf("event stream sent %q, want turn_stored in %s", message, blockID)
+	}
+}
+
+func TestNotFoundAndBadRequests(t *testing.T) {
+	h := newTestAPI(t, false, Options{})
+
+	tests := []struct {
+		method, target, body string
+		want                 int
+	}{
+		{"GET", "/v1/facts/missing", "", http.StatusNotFound},
+		{"GET", "/v1/topics/block_missing", "", http.StatusNotFound},
+		{"POST", "/v1/topics/block_missing/context", `{"message": "hi"}`, http.StatusNotFound},
+		{"POST", "/v1/topics/block_missing/context", `{}`, http.StatusBadRequest},
+		{"POST", "/v1/memories", `not json`, http.StatusBadRequest},
+		{"GET", "/v1/memories?query=x&max_results=many", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		status, body := do(t, h, tt.method, tt.target, tt.body)
+		if status != tt.want {
+			t.Errorf("%s %s = %d %v, want %d", tt.method, tt.target, status, body, tt.want)
+		}
+	}
+}
+
+func TestReadOnlyRejectsWrites(t *testing.T) {
+	h := newTestAPI(t, true, Options{})
+
+	status, body := do(t, h, "POST", "/v1/memories", `{"message": "hello"}`)
+	if status != http.StatusForbidden {
+		t.Errorf("POST /v1/memories on a read-only server = %d %v, want 403", status, body)
+	}
+	if status, body := do(t, h, "GET", "/v1/topics", ""); status != http.StatusOK {
+		t.Errorf("GET /v1/topics on a read-only server = %d %v, want 200", status, body)
+	}
+
+	_, doc := do(t, h, "GET", "/openapi.json", "")
+	paths, _ := doc["paths"].(map[string]any)
+	if memories, _ := paths["/v1/memories"].(map[string]any); memories["post"] != nil || memories["get"] == nil {
+		t.Errorf("read-only document should list only the read operation for /v1/memories: %v", memories)
+	}
+}
+
+func TestDisabledTools(t *testing.T) {
+	store, err := storage.NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory: %v", err)
+	}
+	server := mcpserver.NewMCPServer("test", "0.0.0")
+	handlers := mcp.RegisterTools(server, store, core.NewGovernor(store), core.NewChunkEngine(), nil, nil, mcp.Options{DisabledTools: []string{"delete_topic", "add_fact"}})
+	t.Cleanup(func() {
+		handlers.Shutdown()
+		_ = store.Close()
+	})
+	h := NewHandler(server, store, Options{})
+
+	// Every tool a server registers can be named in disabled_tools
+	tools := server.ListTools()
+	for name := range tools {
+		if _, err := models.ParseToolName(name); err != nil {
+			t.Errorf("registered tool missing from models.ToolNames: %v", err)
+		}
+	}
+	if len(tools) != len(models.ToolNames)-2 || tools["delete_topic"] != nil || tools["add_fact"] != nil {
+		t.Errorf("registered %d tools, want all %d but delete_topic and add_fact", len(tools), len(models.ToolNames))
+	}
+
+	if status, body := do(t, h, "POST", "/v1/facts", `{"key": "tea", "value": "oolong"}`); status != http.StatusForbidden {
+		t.Errorf("POST /v1/facts with add_fact disabled = %d %v, want 403", status, body)
+	}
+	if status, body := do(t, h, "POST", "/v1/memories", `{"message": "hello"}`); status != http.StatusOK {
+		t.Errorf("POST /v1/memories = %d %v, want 200", status, body)
+	}
+	_, doc := do(t, h, "GET", "/openapi.json", "")
+	paths, _ := doc["paths"].(map[string]any)
+	if facts, _ := paths["/v1/facts"].(map[string]any); facts["post"] != nil {
+		t.Errorf("document lists a disabled tool's route: %v", facts)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	h := newTestAPI(t, false, Options{Token: "s3cret"})
+
+	if status, _ := do(t, h, "GET", "/v1/topics", ""); status != http.StatusUnauthorized {
+		t.Errorf("request without a token = %d, want 401", status)
+	}
+	if status, _ := do(t, h, "GET", "/v1/topics", "", "Authorization", "Bearer wrong"); status != http.StatusUnauthorized {
+		t.Errorf("request with the wrong token = %d, want 401", status)
+	}
+	if status, body := do(t, h, "GET", "/v1/topics", "", "Authorization", "Bearer s3cret"); status != http.StatusOK {
+		t.Errorf("request with the token = %d %v, want 200", status, body)
+	}
+}
+
+func TestReadToken(t *testing.T) {
+	chat := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	h := newTestAPI(t, false, Options{Token: "s3cret", ReadToken: "r3ad", Chat: chat})
+	read := []string{"Authorization", "Bearer r3ad"}
+
+	status, body := do(t, h, "POST", "/v1/memories", `{"message": "hello"}`, "Authorization", "Bearer s3cret")
+	blockID, _ := body["block_id"].(string)
+	if status != http.StatusOK || blockID == "" {
+		t.Fatalf("POST /v1/memories with the token = %d %v, want 200 with a block_id", status, body)
+	}
+
+	for _, rt := range []struct{ method, target, body string }{
+		{"GET", "/v1/memories?query=hello", ""},
+		{"GET", "/v1/topics", ""},
+		{"GET", "/v1/topics/" + blockID, ""},
+		{"POST", "/v1/topics/" + blockID + "/context", `{"message": "and then?"}`},
+		{"GET", "/v1/models", ""},
+	} {
+		if status, body := do(t, h, rt.method, rt.target, rt.body, read...); status != http.StatusOK {
+			t.Errorf("%s %s with the read token = %d %v, want 200", rt.method, rt.target, status, body)
+		}
+	}
+
+	for _, rt := range []struct{ method, target, body string }{
+		{"POST", "/v1/memories", `{"message": "hello"}`},
+		{"DELETE", "/v1/topics/" + blockID, ""},
+		{"POST", "/v1/topics/" + blockID + "/archive", "{}"},
+		{"POST", "/v1/topics/" + blockID + "/tags", `{"add": ["x"]}`},
+		{"POST", "/v1/facts", `{"key": "tea", "value": "oolong"}`},
+		{"DELETE", "/v1/facts/tea", ""},
+		{"PATCH", "/v1/profile", `{"name": "Ada"}`},
+		{"POST", "/v1/chat/completions", "{}"},
+	} {
+		if status, body := do(t, h, rt.method, rt.target, rt.body, read...); status != http.StatusForbidden {
+			t.Errorf("%s %s with the read token = %d %v, want 403", rt.method, rt.target, status, body)
+		}
+	}
+
+	if status, _ := do(t, h, "GET", "/v1/topics", "", "Authorization", "Bearer wrong"); status != http.StatusUnauthorized {
+		t.Errorf("request with the wrong token = %d, want 401", status)
+	}
+	if status, body := do(t, h, "DELETE", "/v1/topics/"+blockID, "", "Authorization", "Bearer s3cret"); status != http.StatusOK {
+		t.Errorf("DELETE with the token = %d %v, want 200", status, body)
+	}
+}
+
+func TestChatHandlerBehindToken(t *testing.T) {
+	chat := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"path": "` + r.URL.Path + `"}`))
+	})
+	h := newTestAPI(t, false, Options{Token: "s3cret", Chat: chat})
+
+	if status, _ := do(t, h, "POST", "/v1/chat/completions", "{}"); status != http.StatusUnauthorized {
+		t.Errorf("chat without a token = %d, want 401", status)
+	}
+	for _, rt := range []struct{ method, path string }{{"POST", "/v1/chat/completions"}, {"GET", "/v1/models"}} {
+		if status, body := do(t, h, rt.method, rt.path, "{}", "Authorization", "Bearer s3cret"); status != http.StatusOK || body["path"] != rt.path {
+			t.Errorf("%s %s = %d %v, want the chat handler", rt.method, rt.path, status, body)
+		}
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	h := newTestAPI(t, false, Options{Token: "s3cret"})
+
+	status, doc := do(t, h, "GET", "/openapi.json", "", "Authorization", "Bearer s3cret")
+	if status != http.StatusOK {
+		t.Fatalf("GET /openapi.json = %d", status)
+	}
+	if doc["openapi"] != "3.0.3" || doc["security"] == nil {
+		t.Errorf("document header = %v, %v", doc["openapi"], doc["security"])
+	}
+
+	paths, _ := doc["paths"].(map[string]any)
+	ids := map[string]bool{}
+	for _, rt := range routes {
+		operations, _ := paths[rt.path].(map[string]any)
+		operation, _ := operations[strings.ToLower(rt.method)].(map[string]any)
+		if operation == nil {
+			t.Errorf("document is missing %s %s", rt.method, rt.path)
+			continue
+		}
+		id, _ := operation["operationId"].(string)
+		if ids[id] {
+			t.Errorf("operationId %q is used twice", id)
+		}
+		ids[id] = true
+	}
+
+	// The fact key is a path parameter, not part of the body
+	get, _ := paths["/v1/facts/{key}"].(map[string]any)["get"].(map[string]any)
+	if s := mustJSON(t, get["parameters"]); !strings.Contains(s, `"in":"path"`) {
+		t.Errorf("GET /v1/facts/{key} parameters = %s", s)
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	tests := map[string]bool{
+		"127.0.0.1:8787": true,
+		"localhost:8787": true,
+		"[::1]:8787":     true,
+		"0.0.0.0:8787":   false,
+		":8787":          false,
+		"10.0.0.5:8787":  false,
+		"not-an-address": false,
+	}
+	for addr, want := range tests {
+		if got := IsLoopback(addr); got != want {
+			t.Errorf("IsLoopback(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return string(data)
+}
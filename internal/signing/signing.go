@@ -0,0 +1,106 @@
+// ABOUTME: Ed25519 signing key for reports that must be verifiable later
+// ABOUTME: The key lives beside the config file, outside the data directory, so it outlasts a wipe
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileName is the key file created next to config.yaml
+const FileName = "signing.key"
+
+// Key signs data with a private key held on this machine
+type Key struct {
+	private ed25519.PrivateKey
+}
+
+// LoadOrCreate reads the PEM-encoded key at path, creating one readable only by
+// the owner when it does not exist
+func LoadOrCreate(path string) (*Key, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is derived from the config path
+	if errors.Is(err, os.ErrNotExist) {
+		return create(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM-encoded", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+	}
+	private, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not an Ed25519 key", path)
+	}
+	return &Key{private: private}, nil
+}
+
+// create generates a key and writes it to path
+func create(path string) (*Key, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signing key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create signing key directory: %w", err)
+	}
+	// O_EXCL: if another process created the key first, use its key
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600) // #nosec G304
+	if errors.Is(err, os.ErrExist) {
+		return LoadOrCreate(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signing key: %w", err)
+	}
+	if err := pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to write signing key: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write signing key: %w", err)
+	}
+	return &Key{private: private}, nil
+}
+
+// PublicKey returns the base64-encoded public key that verifies this key's signatures
+func (k *Key) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(k.private.Public().(ed25519.PublicKey))
+}
+
+// Sign returns a base64-encoded signature of data
+func (k *Key) Sign(data []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(k.private, data))
+}
+
+// Verify checks a base64-encoded signature of data against a base64-encoded public key
+func Verify(publicKey, signature string, data []byte) error {
+	pub, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return errors.New("invalid public key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return errors.New("invalid signature encoding")
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pub), data, sig) {
+		return errors.New("signature does not match")
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// ABOUTME: Tests for the signing key
+// ABOUTME: Verifies the key is created once with owner-only permissions and that signatures verify
+
+package signing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadOrCreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", FileName)
+
+	key, err := LoadOrCreate(path)
+	if err != nil {
+		t.Fatalf("LoadOrCreate() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("key file not written: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("key file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	again, err := LoadOrCreate(path)
+	if err != nil {
+		t.Fatalf("second LoadOrCreate() error = %v", err)
+	}
+	if again.PublicKey() != key.PublicKey() {
+		t.Error("second LoadOrCreate() returned a different key")
+	}
+
+	data := []byte("deleted 42 rows")
+	sig := key.Sign(data)
+	if err := Verify(key.PublicKey(), sig, data); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := Verify(key.PublicKey(), sig, []byte("deleted 41 rows")); err == nil {
+		t.Error("Verify() accepted a signature over different data")
+	}
+	if err := Verify("not a key", sig, data); err == nil {
+		t.Error("Verify() accepted an invalid public key")
+	}
+}
+
+func TestLoadOrCreate_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOrCreate(path); err == nil {
+		t.Error("LoadOrCreate() accepted a corrupt key file")
+	}
+}
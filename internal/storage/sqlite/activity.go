@@ -0,0 +1,146 @@
+// ABOUTME: Interaction history by day: turns per day and the busiest topics of each week
+// ABOUTME: Days are bucketed in the storage time zone, as day IDs are, for the activity heatmap
+package sqlite
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// dayLayout is how days are keyed, the same as day IDs
+const dayLayout = "2006-01-02"
+
+// activityTopTopics is how many topics each week of activity lists
+const activityTopTopics = 3
+
+// DayActivity is how many turns were stored on one day
+type DayActivity struct {
+	Day   string `json:"day"` // YYYY-MM-DD in the storage time zone
+	Turns int    `json:"turns"`
+}
+
+// TopicTurns is how many turns a topic got within some period
+type TopicTurns struct {
+	Topic string `json:"topic"`
+	Turns int    `json:"turns"`
+}
+
+// WeekActivity is one week of activity, Sunday to Saturday, with its busiest topics
+type WeekActivity struct {
+	Week      string       `json:"week"` // The week's Sunday, YYYY-MM-DD
+	Turns     int          `json:"turns"`
+	TopTopics []TopicTurns `json:"top_topics"`
+}
+
+// Activity is the interaction history over a run of whole weeks
+type Activity struct {
+	From          string         `json:"from"` // First day, a Sunday
+	To            string         `json:"to"`   // Last day
+	TotalTurns    int            `json:"total_turns"`
+	ActiveDays    int            `json:"active_days"`
+	LongestStreak int            `json:"longest_streak"` // Most consecutive days with a turn
+	MaxDayTurns   int            `json:"max_day_turns"`
+	Days          []DayActivity  `json:"days"`  // Every day from From to To, oldest first
+	Weeks         []WeekActivity `json:"weeks"` // Every week from From, oldest first
+}
+
+// Activity counts the turns stored on each day of the given number of weeks up to and
+// including the day of to, starting on a Sunday so the days form whole weeks, and
+// ranks each week's topics by their turns in it
+func (s *Storage) Activity(to time.Time, weeks int) (*Activity, error) {
+	if weeks <= 0 {
+		return nil, fmt.Errorf("weeks must be positive, got %d", weeks)
+	}
+	loc := s.location()
+	to = to.In(loc)
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, loc)
+	first := last.AddDate(0, 0, -int(last.Weekday())-7*(weeks-1))
+	end := last.AddDate(0, 0, 1)
+
+	activity := &Activity{From: first.Format(dayLayout), To: last.Format(dayLayout)}
+	dayIndex := make(map[string]int)
+	for day := first; day.Before(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format(dayLayout)
+		dayIndex[key] = len(activity.Days)
+		activity.Days = append(activity.Days, DayActivity{Day: key})
+		if day.Weekday() == time.Sunday {
+			activity.Weeks = append(activity.Weeks, WeekActivity{Week: key, TopTopics: []TopicTurns{}})
+		}
+	}
+
+	// Timestamps are compared as stored, in whatever zone they were saved in, so the
+	// query reaches a day past each end and days outside the range are skipped below
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rows, err := s.db.Query(`
+		SELECT t.created_at, COALESCE(b.topic_label, '')
+		FROM turns t
+		JOIN bridge_blocks b ON b.id = t.block_id
+		WHERE t.created_at >= ? AND t.created_at < ?
+	`, first.AddDate(0, 0, -1).UTC(), end.AddDate(0, 0, 1).UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read activity: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	weekTopics := make([]map[string]int, len(activity.Weeks))
+	for rows.Next() {
+		var at time.Time
+		var topic string
+		if err := rows.Scan(&at, &topic); err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", err)
+		}
+		i, ok := dayIndex[at.In(loc).Format(dayLayout)]
+		if !ok {
+			continue
+		}
+		activity.Days[i].Turns++
+		week := i / 7
+		activity.Weeks[week].Turns++
+		if topic != "" {
+			if weekTopics[week] == nil {
+				weekTopics[week] = make(map[string]int)
+			}
+			weekTopics[week][topic]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read activity: %w", err)
+	}
+
+	streak := 0
+	for _, day := range activity.Days {
+		activity.TotalTurns += day.Turns
+		activity.MaxDayTurns = max(activity.MaxDayTurns, day.Turns)
+		if day.Turns == 0 {
+			streak = 0
+			continue
+		}
+		activity.ActiveDays++
+		streak++
+		activity.LongestStreak = max(activity.LongestStreak, streak)
+	}
+	for week, topics := range weekTopics {
+		activity.Weeks[week].TopTopics = topTopicTurns(topics, activityTopTopics)
+	}
+	return activity, nil
+}
+
+// topTopicTurns ranks topics by turns, most first and by name among equals, keeping n
+func topTopicTurns(counts map[string]int, n int) []TopicTurns {
+	ranked := make([]TopicTurns, 0, len(counts))
+	for topic, turns := range counts {
+		ranked = append(ranked, TopicTurns{Topic: topic, Turns: turns})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Turns != ranked[j].Turns {
+			return ranked[i].Turns > ranked[j].Turns
+		}
+		return ranked[i].Topic < ranked[j].Topic
+	})
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
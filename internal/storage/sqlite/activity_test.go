@@ -0,0 +1,81 @@
+// ABOUTME: Tests for the activity history behind the heatmap
+// ABOUTME: Verifies turns are bucketed by day in the storage zone, weeks start on Sunday, and topics are ranked per week
+
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestActivity(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// UTC-5: 03:00 UTC on the 4th is still the 3rd here
+	loc := time.FixedZone("EST", -5*60*60)
+	store.SetLocation(loc)
+
+	turns := []struct {
+		block, topic string
+		at           time.Time
+	}{
+		{"block_go", "go", time.Date(2026, 3, 2, 9, 0, 0, 0, loc)},      // Monday of week 1
+		{"block_go", "go", time.Date(2026, 3, 4, 3, 0, 0, 0, time.UTC)}, // Tuesday the 3rd here
+		{"block_rust", "rust", time.Date(2026, 3, 3, 12, 0, 0, 0, loc)},
+		{"block_go", "go", time.Date(2026, 3, 9, 9, 0, 0, 0, loc)}, // Monday of week 2
+		{"block_garden", "garden", time.Date(2026, 3, 10, 9, 0, 0, 0, loc)},
+		{"block_garden", "garden", time.Date(2026, 3, 10, 10, 0, 0, 0, loc)},
+		{"block_go", "go", time.Date(2026, 2, 20, 9, 0, 0, 0, loc)}, // Before the range
+		{"block_go", "go", time.Date(2026, 3, 12, 9, 0, 0, 0, loc)}, // After the last day
+	}
+	for i, turn := range turns {
+		if err := store.blocks.Save(&models.BridgeBlock{BlockID: turn.block, DayID: "2026-03-02", TopicLabel: turn.topic, Status: models.StatusPaused, CreatedAt: turn.at, UpdatedAt: turn.at}); err != nil {
+			t.Fatalf("Save block error = %v", err)
+		}
+		if err := store.turns.Save(turn.block, &models.Turn{TurnID: "turn_" + string(rune('a'+i)), Timestamp: turn.at, UserMessage: "hello"}); err != nil {
+			t.Fatalf("Save turn error = %v", err)
+		}
+	}
+
+	if _, err := store.Activity(time.Now(), 0); err == nil {
+		t.Error("Activity() with no weeks succeeded, want an error")
+	}
+
+	// Two weeks ending Wednesday the 11th start on Sunday the 1st
+	activity, err := store.Activity(time.Date(2026, 3, 11, 15, 0, 0, 0, loc), 2)
+	if err != nil {
+		t.Fatalf("Activity() error = %v", err)
+	}
+	if activity.From != "2026-03-01" || activity.To != "2026-03-11" || len(activity.Days) != 11 || len(activity.Weeks) != 2 {
+		t.Fatalf("Activity() covers %s to %s in %d days and %d weeks, want 2026-03-01 to 2026-03-11 in 11 days and 2 weeks",
+			activity.From, activity.To, len(activity.Days), len(activity.Weeks))
+	}
+
+	byDay := map[string]int{}
+	for _, day := range activity.Days {
+		byDay[day.Day] = day.Turns
+	}
+	for day, want := range map[string]int{"2026-03-01": 0, "2026-03-02": 1, "2026-03-03": 2, "2026-03-04": 0, "2026-03-09": 1, "2026-03-10": 2} {
+		if byDay[day] != want {
+			t.Errorf("turns on %s = %d, want %d", day, byDay[day], want)
+		}
+	}
+	if activity.TotalTurns != 6 || activity.ActiveDays != 4 || activity.LongestStreak != 2 || activity.MaxDayTurns != 2 {
+		t.Errorf("totals = %d turns, %d days, streak %d, max %d; want 6, 4, 2, 2",
+			activity.TotalTurns, activity.ActiveDays, activity.LongestStreak, activity.MaxDayTurns)
+	}
+
+	first, second := activity.Weeks[0], activity.Weeks[1]
+	if first.Week != "2026-03-01" || first.Turns != 3 || len(first.TopTopics) != 2 || first.TopTopics[0] != (TopicTurns{Topic: "go", Turns: 2}) {
+		t.Errorf("first week = %+v, want 3 turns led by go", first)
+	}
+	if second.Week != "2026-03-08" || second.Turns != 3 || len(second.TopTopics) != 2 || second.TopTopics[0] != (TopicTurns{Topic: "garden", Turns: 2}) {
+		t.Errorf("second week = %+v, want 3 turns led by garden", second)
+	}
+}
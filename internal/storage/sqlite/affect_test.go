@@ -0,0 +1,108 @@
+// ABOUTME: Tests for turn affect in storage
+// ABOUTME: Verifies affect round-trips, per-block aggregation, and the search filter
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// newAffectStorage returns storage with a frustrated deploy block and a curious garden block
+func newAffectStorage(t *testing.T) (*Storage, string, string) {
+	t.Helper()
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	deploy, err := store.StoreTurn(&models.Turn{
+		TurnID:      "turn_deploy_1",
+		UserMessage: "the deploy failed again",
+		Keywords:    []string{"deploy"},
+		Affect:      models.AffectFrustrated,
+	})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if err := store.AppendTurnToBlock(deploy, &models.Turn{
+		TurnID:      "turn_deploy_2",
+		UserMessage: "the deploy is still failing",
+		Keywords:    []string{"deploy"},
+		Affect:      models.AffectFrustrated,
+	}); err != nil {
+		t.Fatalf("AppendTurnToBlock() error = %v", err)
+	}
+	if err := store.AppendTurnToBlock(deploy, &models.Turn{
+		TurnID:      "turn_deploy_3",
+		UserMessage: "what does the deploy log say",
+		Keywords:    []string{"deploy"},
+	}); err != nil {
+		t.Fatalf("AppendTurnToBlock() error = %v", err)
+	}
+
+	garden, err := store.StoreTurn(&models.Turn{
+		TurnID:      "turn_garden",
+		UserMessage: "how deep should the deploy of garden beds be",
+		Keywords:    []string{"garden"},
+		Affect:      models.AffectCurious,
+	})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	return store, deploy, garden
+}
+
+func TestTurnAffect_RoundTripAndAggregate(t *testing.T) {
+	store, deploy, _ := newAffectStorage(t)
+
+	block, err := store.GetBridgeBlock(deploy)
+	if err != nil {
+		t.Fatalf("GetBridgeBlock() error = %v", err)
+	}
+	if len(block.Turns) != 3 {
+		t.Fatalf("len(Turns) = %d, want 3", len(block.Turns))
+	}
+	if block.Turns[0].Affect != models.AffectFrustrated || block.Turns[2].Affect != "" {
+		t.Errorf("turn affects = %q, %q; want frustrated and empty", block.Turns[0].Affect, block.Turns[2].Affect)
+	}
+	if block.Affect != models.AffectFrustrated {
+		t.Errorf("block.Affect = %q, want frustrated", block.Affect)
+	}
+	if block.AffectCounts[models.AffectFrustrated] != 2 || len(block.AffectCounts) != 1 {
+		t.Errorf("block.AffectCounts = %v, want frustrated: 2", block.AffectCounts)
+	}
+}
+
+func TestSearchMemoryWithOptions_Affect(t *testing.T) {
+	store, deploy, garden := newAffectStorage(t)
+	query := "deploy failing garden"
+
+	for _, filter := range []models.Affect{models.AffectFrustrated, models.AffectNegative} {
+		results, err := store.SearchMemoryWithOptions(query, 10, SearchOptions{Affect: filter})
+		if err != nil {
+			t.Fatalf("SearchMemoryWithOptions(%s) error = %v", filter, err)
+		}
+		if len(results) != 1 || results[0].BlockID != deploy {
+			t.Fatalf("SearchMemoryWithOptions(%s) = %+v, want only the deploy block", filter, results)
+		}
+		if results[0].Affect != models.AffectFrustrated {
+			t.Errorf("result affect = %q, want frustrated", results[0].Affect)
+		}
+	}
+
+	results, err := store.SearchMemoryWithOptions(query, 10, SearchOptions{Affect: models.AffectExcited})
+	if err != nil || len(results) != 0 {
+		t.Errorf("SearchMemoryWithOptions(excited) = %+v, %v; want no results", results, err)
+	}
+
+	all, _ := store.SearchMemory(query, 10)
+	if len(all) != 2 {
+		t.Errorf("SearchMemory() without affect = %d results, want 2 (deploy and %s)", len(all), garden)
+	}
+
+	if _, err := store.SearchMemoryWithOptions(query, 10, SearchOptions{Affect: "grumpy"}); err == nil {
+		t.Error("SearchMemoryWithOptions() with an unknown affect should fail")
+	}
+}
@@ -0,0 +1,119 @@
+// ABOUTME: Bulk archival of Bridge Blocks selected by last update and topic label
+// ABOUTME: Archives every matching block in one transaction so a filter never half-applies
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// ArchiveFilter selects the blocks ArchiveBlocks archives; a block must match every field
+// set, and at least one must be
+type ArchiveFilter struct {
+	Before time.Time // Last updated before this time
+	Label  string    // Topic label pattern, ignoring case: * matches any run of characters, ? one
+}
+
+// ArchivedBlock is a block ArchiveBlocks archived, or would archive
+type ArchivedBlock struct {
+	BlockID    string                   `json:"block_id"`
+	TopicLabel string                   `json:"topic_label"`
+	Status     models.BridgeBlockStatus `json:"status"` // Status before archiving
+	UpdatedAt  time.Time                `json:"updated_at"`
+}
+
+// ArchiveBlocks archives the blocks matching filter that are not archived already, in one
+// transaction, and returns them oldest first. With dryRun it only returns them.
+func (s *Storage) ArchiveBlocks(filter ArchiveFilter, dryRun bool) (blocks []ArchivedBlock, err error) {
+	if filter.Before.IsZero() && filter.Label == "" {
+		return nil, fmt.Errorf("an archive filter needs a before time or a label")
+	}
+	defer func() { // runs after the unlock below, so subscribers may use storage
+		if err == nil && !dryRun {
+			for _, block := range blocks {
+				event := models.NewEvent(models.EventTopicArchived)
+				event.BlockID = block.BlockID
+				s.publish(event)
+			}
+		}
+	}()
+
+	where := []string{"status != ?"}
+	args := []interface{}{string(models.StatusArchived)}
+	if !filter.Before.IsZero() {
+		where = append(where, "updated_at < ?")
+		args = append(args, filter.Before.UTC())
+	}
+	if filter.Label != "" {
+		where = append(where, `COALESCE(topic_label, '') LIKE ? ESCAPE '\'`)
+		args = append(args, labelPattern(filter.Label))
+	}
+	clause := strings.Join(where, " AND ")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.Query(`
+		SELECT id, COALESCE(topic_label, ''), status, updated_at FROM bridge_blocks
+		WHERE `+clause+`
+		ORDER BY updated_at, id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blocks to archive: %w", err)
+	}
+	for rows.Next() {
+		var block ArchivedBlock
+		if err := rows.Scan(&block.BlockID, &block.TopicLabel, &block.Status, &block.UpdatedAt); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to scan block to archive: %w", err)
+		}
+		blocks = append(blocks, block)
+	}
+	err = rows.Err()
+	_ = rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blocks to archive: %w", err)
+	}
+	if dryRun || len(blocks) == 0 {
+		return blocks, nil
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE bridge_blocks SET status = ?, updated_at = ?
+		WHERE `+clause,
+		append([]interface{}{string(models.StatusArchived), s.db.now().UTC()}, args...)...); err != nil {
+		return nil, fmt.Errorf("failed to archive blocks: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit archive: %w", err)
+	}
+	return blocks, nil
+}
+
+// labelPattern turns a topic label pattern using * and ? into a LIKE pattern
+func labelPattern(pattern string) string {
+	var b strings.Builder
+	for _, r := range pattern {
+		switch r {
+		case '\\', '%', '_':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '*':
+			b.WriteRune('%')
+		case '?':
+			b.WriteRune('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
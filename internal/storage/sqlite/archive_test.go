@@ -0,0 +1,84 @@
+// ABOUTME: Tests for bulk archival of blocks by filter
+// ABOUTME: Verifies label patterns, the before cutoff, dry runs, and that a filter is required
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestArchiveBlocks(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	old := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	ids := map[string]string{}
+	for _, label := range []string{"scratch notes", "Scratchpad", "work_plan", "workXplan", "trip"} {
+		id, err := store.StoreTurn(&models.Turn{TurnID: "turn_archive_" + label, Timestamp: time.Now(), UserMessage: label, Topics: []string{label}})
+		if err != nil {
+			t.Fatalf("StoreTurn() error = %v", err)
+		}
+		ids[label] = id
+	}
+	for _, label := range []string{"scratch notes", "Scratchpad", "work_plan", "workXplan"} {
+		if _, err := store.db.Exec(`UPDATE bridge_blocks SET updated_at = ? WHERE id = ?`, old, ids[label]); err != nil {
+			t.Fatalf("backdating %s: %v", label, err)
+		}
+	}
+
+	if _, err := store.ArchiveBlocks(ArchiveFilter{}, false); err == nil {
+		t.Error("ArchiveBlocks() with no filter succeeded, want an error")
+	}
+
+	labels := func(blocks []ArchivedBlock) map[string]bool {
+		got := map[string]bool{}
+		for _, block := range blocks {
+			got[block.TopicLabel] = true
+		}
+		return got
+	}
+
+	// A dry run lists without archiving
+	blocks, err := store.ArchiveBlocks(ArchiveFilter{Label: "SCRATCH*"}, true)
+	if err != nil {
+		t.Fatalf("ArchiveBlocks(dry run) error = %v", err)
+	}
+	if got := labels(blocks); len(got) != 2 || !got["scratch notes"] || !got["Scratchpad"] {
+		t.Errorf("ArchiveBlocks(dry run) = %+v, want both scratch topics", blocks)
+	}
+	if block, _ := store.GetBridgeBlock(ids["Scratchpad"]); block == nil || block.Status == models.StatusArchived {
+		t.Errorf("block after a dry run = %+v, want it left alone", block)
+	}
+
+	// _ in a label is literal, not a wildcard
+	blocks, err = store.ArchiveBlocks(ArchiveFilter{Label: "work_plan", Before: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, false)
+	if err != nil {
+		t.Fatalf("ArchiveBlocks() error = %v", err)
+	}
+	if len(blocks) != 1 || blocks[0].BlockID != ids["work_plan"] || blocks[0].Status != models.StatusPaused {
+		t.Errorf("ArchiveBlocks(work_plan) = %+v, want only the paused work_plan block", blocks)
+	}
+
+	// Only blocks last updated before the cutoff, and not the ones already archived
+	blocks, err = store.ArchiveBlocks(ArchiveFilter{Before: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, false)
+	if err != nil {
+		t.Fatalf("ArchiveBlocks(before) error = %v", err)
+	}
+	if got := labels(blocks); len(got) != 3 || got["work_plan"] || got["trip"] {
+		t.Errorf("ArchiveBlocks(before) = %+v, want the three other old blocks", blocks)
+	}
+	for label, id := range ids {
+		block, err := store.GetBridgeBlock(id)
+		if err != nil || block == nil {
+			t.Fatalf("GetBridgeBlock(%s) = %+v, %v", label, block, err)
+		}
+		if archived := block.Status == models.StatusArchived; archived != (label != "trip") {
+			t.Errorf("%s status = %s", label, block.Status)
+		}
+	}
+}
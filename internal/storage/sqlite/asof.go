@@ -0,0 +1,352 @@
+// ABOUTME: Time-travel reads: facts and the profile as they stood at a past time, and their history
+// ABOUTME: Built from fact versions (every value saved for a key) and the profile_history table
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// ProfileVersion is the shared profile or a persona as it was saved at one time
+type ProfileVersion struct {
+	Persona          string                     `json:"persona,omitempty"` // Empty for the shared profile
+	Name             string                     `json:"name,omitempty"`
+	Preferences      []string                   `json:"preferences,omitempty"`
+	TopicsOfInterest []string                   `json:"topics_of_interest,omitempty"`
+	Constraints      []models.ProfileConstraint `json:"constraints,omitempty"`
+	Deleted          bool                       `json:"deleted,omitempty"`  // The persona was deleted
+	TurnIDs          []string                   `json:"turn_ids,omitempty"` // Turns the Scribe learned this version from; empty for direct edits
+	SavedAt          time.Time                  `json:"saved_at"`
+}
+
+// profileVersionData is the part of a ProfileVersion kept in profile_history.data
+type profileVersionData struct {
+	Name             string                     `json:"name,omitempty"`
+	Preferences      []string                   `json:"preferences,omitempty"`
+	TopicsOfInterest []string                   `json:"topics_of_interest,omitempty"`
+	Constraints      []models.ProfileConstraint `json:"constraints,omitempty"`
+}
+
+// HistoryEntry is one change in memory's history: a value recorded for a fact, or a
+// version of the profile or a persona
+type HistoryEntry struct {
+	At         time.Time       `json:"at"`
+	Fact       *models.Fact    `json:"fact,omitempty"`
+	Superseded bool            `json:"superseded,omitempty"` // A later value for the fact's key replaced it
+	Profile    *ProfileVersion `json:"profile,omitempty"`
+}
+
+// MemorySnapshot is what memory held at a past time
+type MemorySnapshot struct {
+	AsOf    time.Time           `json:"as_of"`
+	Facts   []models.Fact       `json:"facts"`             // The value each key had, by key
+	Profile *models.UserProfile `json:"profile,omitempty"` // Nil when no profile had been saved yet
+}
+
+// recordProfileVersion appends v to the profile history, unless it is the same as the
+// latest version of its persona
+func recordProfileVersion(tx *Tx, v *ProfileVersion) error {
+	var data sql.NullString
+	if !v.Deleted {
+		encoded, err := json.Marshal(profileVersionData{
+			Name:             v.Name,
+			Preferences:      v.Preferences,
+			TopicsOfInterest: v.TopicsOfInterest,
+			Constraints:      v.Constraints,
+		})
+		if err != nil {
+			return err
+		}
+		data = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	var latest sql.NullString
+	err := tx.QueryRow(`
+		SELECT data FROM profile_history
+		WHERE persona = ?
+		ORDER BY saved_at DESC, id DESC
+		LIMIT 1
+	`, v.Persona).Scan(&latest)
+	switch {
+	case err == nil && canonicalProfileData(latest) == data:
+		return nil
+	case err != nil && err != sql.ErrNoRows:
+		return fmt.Errorf("failed to read profile history: %w", err)
+	}
+
+	var turnIDs sql.NullString
+	if len(v.TurnIDs) > 0 {
+		encoded, err := json.Marshal(v.TurnIDs)
+		if err != nil {
+			return err
+		}
+		turnIDs = sql.NullString{String: string(encoded), Valid: true}
+	}
+	if _, err := tx.Exec(`INSERT INTO profile_history (persona, data, turn_ids, saved_at) VALUES (?, ?, ?, ?)`,
+		v.Persona, data, turnIDs, v.SavedAt.UTC()); err != nil {
+		return fmt.Errorf("failed to record profile history: %w", err)
+	}
+	return nil
+}
+
+// canonicalProfileData re-encodes stored version data the way recordProfileVersion
+// does, so versions seeded by the migration (which spells out empty lists) compare equal
+func canonicalProfileData(data sql.NullString) sql.NullString {
+	var d profileVersionData
+	if !data.Valid || json.Unmarshal([]byte(data.String), &d) != nil {
+		return data
+	}
+	encoded, err := json.Marshal(d)
+	if err != nil {
+		return data
+	}
+	return sql.NullString{String: string(encoded), Valid: true}
+}
+
+// VersionAsOf returns the version of persona ("" for the shared profile) saved most
+// recently at or before at, or nil if there was none
+func (s *ProfileStore) VersionAsOf(persona string, at time.Time) (*ProfileVersion, error) {
+	rows, err := s.db.Query(`
+		SELECT persona, data, turn_ids, saved_at FROM profile_history
+		WHERE persona = ? AND saved_at <= ?
+		ORDER BY saved_at DESC, id DESC
+		LIMIT 1
+	`, persona, at.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile history: %w", err)
+	}
+	versions, err := scanProfileVersions(rows)
+	if err != nil || len(versions) == 0 {
+		return nil, err
+	}
+	return versions[0], nil
+}
+
+// Versions returns up to limit profile and persona versions, newest first
+func (s *ProfileStore) Versions(limit int) ([]*ProfileVersion, error) {
+	rows, err := s.db.Query(`
+		SELECT persona, data, turn_ids, saved_at FROM profile_history
+		ORDER BY saved_at DESC, id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile history: %w", err)
+	}
+	return scanProfileVersions(rows)
+}
+
+// scanProfileVersions reads profile_history rows, closing them when done
+func scanProfileVersions(rows *Rows) ([]*ProfileVersion, error) {
+	defer func() { _ = rows.Close() }()
+
+	var versions []*ProfileVersion
+	for rows.Next() {
+		var (
+			v       ProfileVersion
+			data    sql.NullString
+			turnIDs sql.NullString
+		)
+		if err := rows.Scan(&v.Persona, &data, &turnIDs, &v.SavedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan profile history: %w", err)
+		}
+		if !data.Valid {
+			v.Deleted = true
+		} else {
+			var d profileVersionData
+			if err := json.Unmarshal([]byte(data.String), &d); err != nil {
+				return nil, fmt.Errorf("failed to decode profile history: %w", err)
+			}
+			v.Name, v.Preferences, v.TopicsOfInterest, v.Constraints = d.Name, d.Preferences, d.TopicsOfInterest, d.Constraints
+		}
+		if turnIDs.Valid {
+			_ = json.Unmarshal([]byte(turnIDs.String), &v.TurnIDs)
+		}
+		versions = append(versions, &v)
+	}
+	return versions, rows.Err()
+}
+
+// ListAsOf returns the value each fact key had at at, by key: the most recent fact saved
+// at or before then. An empty key means every key. Deleted facts are gone from history too,
+// and facts awaiting review are not part of it yet.
+func (s *FactStore) ListAsOf(at time.Time, key string) ([]models.Fact, error) {
+	where, args := "created_at <= ? AND NOT pending", []interface{}{at.UTC()}
+	if key != "" {
+		where, args = where+" AND key = ?", append(args, key)
+	}
+	rows, err := s.db.Query(`
+		SELECT `+factColumns+` FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY key ORDER BY created_at DESC, rowid DESC) AS version
+			FROM facts
+			WHERE `+where+`
+		)
+		WHERE version = 1
+		ORDER BY key
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read facts as of %s: %w", at.Format(time.RFC3339), err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var facts []models.Fact
+	for rows.Next() {
+		fact, err := s.scanFact(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan fact: %w", err)
+		}
+		facts = append(facts, *fact)
+	}
+	return facts, rows.Err()
+}
+
+// Versions returns up to limit saved fact values, newest first, each marked superseded
+// when a later value for its key exists. An empty key means every key.
+func (s *FactStore) Versions(key string, limit int) ([]HistoryEntry, error) {
+	where, args := "WHERE NOT pending", []interface{}{}
+	if key != "" {
+		where, args = where+" AND key = ?", append(args, key)
+	}
+	rows, err := s.db.Query(`
+		SELECT `+factColumns+`,
+			ROW_NUMBER() OVER (PARTITION BY key ORDER BY created_at DESC, rowid DESC) > 1
+		FROM facts
+		`+where+`
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT ?
+	`, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fact history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []HistoryEntry
+	for rows.Next() {
+		var superseded bool
+		fact, err := s.scanFact(scanFunc(func(dest ...any) error {
+			return rows.Scan(append(dest, &superseded)...)
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan fact: %w", err)
+		}
+		entries = append(entries, HistoryEntry{At: fact.CreatedAt, Fact: fact, Superseded: superseded})
+	}
+	return entries, rows.Err()
+}
+
+// scanFunc adapts a function to the row scanner scanFact reads from
+type scanFunc func(dest ...any) error
+
+func (f scanFunc) Scan(dest ...any) error { return f(dest...) }
+
+// GetFactAsOf returns the value key had at at, or nil if it had none
+func (s *Storage) GetFactAsOf(key string, at time.Time) (*models.Fact, error) {
+	facts, err := s.facts.ListAsOf(at, key)
+	if err != nil || len(facts) == 0 {
+		return nil, err
+	}
+	if err := s.attachFactTags(facts); err != nil {
+		return nil, err
+	}
+	return &facts[0], nil
+}
+
+// GetUserProfileAsOf returns the user profile as it was at at, with the named persona
+// layered on as it was then, like GetUserProfileAs. It is nil when no profile had been
+// saved by then and no persona is asked for.
+func (s *Storage) GetUserProfileAsOf(persona string, at time.Time) (*models.UserProfile, error) {
+	var profile *models.UserProfile
+	base, err := s.profile.VersionAsOf("", at)
+	if err != nil {
+		return nil, err
+	}
+	if base != nil {
+		profile = &models.UserProfile{
+			Name:             base.Name,
+			Preferences:      nonNil(base.Preferences),
+			TopicsOfInterest: nonNil(base.TopicsOfInterest),
+			Constraints:      base.Constraints,
+			LastUpdated:      base.SavedAt,
+		}
+		if profile.Constraints == nil {
+			profile.Constraints = []models.ProfileConstraint{}
+		}
+	}
+	if persona == "" {
+		return profile, nil
+	}
+
+	name, err := models.NormalizePersonaName(persona)
+	if err != nil {
+		return nil, err
+	}
+	layer, err := s.profile.VersionAsOf(name, at)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		profile = &models.UserProfile{Preferences: []string{}, TopicsOfInterest: []string{}, Constraints: []models.ProfileConstraint{}}
+	}
+	var p *models.Persona
+	if layer != nil && !layer.Deleted {
+		p = &models.Persona{Name: name, Preferences: layer.Preferences, Constraints: layer.Constraints, LastUpdated: layer.SavedAt}
+	}
+	view := profile.WithPersona(p)
+	if view.Persona == "" {
+		view.Persona = name
+	}
+	return view, nil
+}
+
+// GetMemoryAsOf returns the facts and profile (with persona layered on, if given) as they
+// were at at, for auditing what an agent knew when it acted
+func (s *Storage) GetMemoryAsOf(at time.Time, persona string) (*MemorySnapshot, error) {
+	facts, err := s.facts.ListAsOf(at, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachFactTags(facts); err != nil {
+		return nil, err
+	}
+	profile, err := s.GetUserProfileAsOf(persona, at)
+	if err != nil {
+		return nil, err
+	}
+	if facts == nil {
+		facts = []models.Fact{}
+	}
+	return &MemorySnapshot{AsOf: at, Facts: facts, Profile: profile}, nil
+}
+
+// History returns up to limit changes to memory, newest first: fact values recorded and
+// profile and persona versions saved. With a key, only that fact's values are returned.
+func (s *Storage) History(key string, limit int) ([]HistoryEntry, error) {
+	entries, err := s.facts.Versions(key, limit)
+	if err != nil || key != "" {
+		return entries, err
+	}
+	versions, err := s.profile.Versions(limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		entries = append(entries, HistoryEntry{At: v.SavedAt, Profile: v})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].At.After(entries[j].At) })
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// nonNil returns s, or an empty slice in place of nil
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
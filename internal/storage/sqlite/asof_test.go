@@ -0,0 +1,181 @@
+// ABOUTME: Tests for time-travel reads of facts and the profile
+// ABOUTME: Verifies values resolve as of a past time, personas layer as they were, and history lists every change
+
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestGetMemoryAsOf(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	march := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	april := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	may := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, f := range []*models.Fact{
+		{FactID: "fact_city_1", Key: "home_city", Value: "Chicago", Confidence: 1, CreatedAt: march, Agent: "claude-code"},
+		{FactID: "fact_city_2", Key: "home_city", Value: "Oakland", Confidence: 1, CreatedAt: may, Agent: "research-agent"},
+		{FactID: "fact_editor", Key: "editor", Value: "helix", Confidence: 1, CreatedAt: april},
+	} {
+		if err := store.SaveFact(f); err != nil {
+			t.Fatalf("SaveFact() error = %v", err)
+		}
+	}
+	for _, p := range []*models.UserProfile{
+		{Name: "Harper", Preferences: []string{"tea"}, LastUpdated: march},
+		{Name: "Harper", Preferences: []string{"coffee"}, LastUpdated: may},
+	} {
+		if err := store.profile.Save(p); err != nil {
+			t.Fatalf("Save profile error = %v", err)
+		}
+	}
+	if err := store.profile.SavePersona(&models.Persona{Name: "work", Preferences: []string{"formal tone"}, LastUpdated: april}); err != nil {
+		t.Fatalf("SavePersona() error = %v", err)
+	}
+
+	snapshot, err := store.GetMemoryAsOf(april.Add(time.Hour), "work")
+	if err != nil {
+		t.Fatalf("GetMemoryAsOf() error = %v", err)
+	}
+	if len(snapshot.Facts) != 2 || snapshot.Facts[0].Key != "editor" || snapshot.Facts[1].Value != "Chicago" || snapshot.Facts[1].Agent != "claude-code" {
+		t.Errorf("Facts = %+v, want editor and the March home_city", snapshot.Facts)
+	}
+	p := snapshot.Profile
+	if p == nil || p.Persona != "work" || len(p.Preferences) != 2 || p.Preferences[0] != "tea" || p.Preferences[1] != "formal tone" {
+		t.Errorf("Profile = %+v, want the March profile with the work persona layered on", p)
+	}
+
+	if before, err := store.GetMemoryAsOf(march.Add(-time.Hour), ""); err != nil || len(before.Facts) != 0 || before.Profile != nil {
+		t.Errorf("GetMemoryAsOf(before anything) = %+v, %v; want nothing", before, err)
+	}
+
+	if fact, err := store.GetFactAsOf("home_city", may); err != nil || fact == nil || fact.Value != "Oakland" {
+		t.Errorf("GetFactAsOf(may) = %+v, %v; want Oakland", fact, err)
+	}
+	if fact, err := store.GetFactAsOf("editor", march); err != nil || fact != nil {
+		t.Errorf("GetFactAsOf(editor, march) = %+v, %v; want nil before it was recorded", fact, err)
+	}
+
+	// A deleted persona no longer layers on from then on
+	if ok, err := store.profile.DeletePersona("work"); err != nil || !ok {
+		t.Fatalf("DeletePersona() = %v, %v", ok, err)
+	}
+	now, err := store.GetUserProfileAsOf("work", time.Now().Add(time.Minute))
+	if err != nil || len(now.Preferences) != 1 || now.Preferences[0] != "coffee" {
+		t.Errorf("GetUserProfileAsOf(now) = %+v, %v; want the May profile alone", now, err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, value := range []string{"Chicago", "Oakland"} {
+		if err := store.SaveFact(&models.Fact{FactID: "fact_city_" + value, Key: "home_city", Value: value, Confidence: 1, CreatedAt: start.Add(time.Duration(i) * 2 * time.Hour)}); err != nil {
+			t.Fatalf("SaveFact() error = %v", err)
+		}
+	}
+	profile := &models.UserProfile{Name: "Harper", LastUpdated: start.Add(time.Hour)}
+	if err := store.profile.Save(profile); err != nil {
+		t.Fatalf("Save profile error = %v", err)
+	}
+	// Saving the same profile again records no new version
+	profile.LastUpdated = start.Add(90 * time.Minute)
+	if err := store.profile.Save(profile); err != nil {
+		t.Fatalf("Save profile error = %v", err)
+	}
+
+	entries, err := store.History("", 10)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("History() = %d entries, want 3", len(entries))
+	}
+	if e := entries[0]; e.Fact == nil || e.Fact.Value != "Oakland" || e.Superseded {
+		t.Errorf("entries[0] = %+v, want the current home_city", e)
+	}
+	if e := entries[1]; e.Profile == nil || e.Profile.Name != "Harper" || e.Profile.Persona != "" {
+		t.Errorf("entries[1] = %+v, want the shared profile version", e)
+	}
+	if e := entries[2]; e.Fact == nil || e.Fact.Value != "Chicago" || !e.Superseded {
+		t.Errorf("entries[2] = %+v, want the superseded home_city", e)
+	}
+
+	if keyed, err := store.History("home_city", 1); err != nil || len(keyed) != 1 || keyed[0].Fact.Value != "Oakland" {
+		t.Errorf("History(home_city, 1) = %+v, %v; want the latest value only", keyed, err)
+	}
+}
+
+func TestProfileHistorySeededOnUpgrade(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.db")
+	store, err := NewStorageWithPath(path)
+	if err != nil {
+		t.Fatalf("NewStorageWithPath() error = %v", err)
+	}
+	profile := &models.UserProfile{Name: "Harper", Preferences: []string{"tea"}, LastUpdated: time.Now()}
+	if err := store.profile.Save(profile); err != nil {
+		t.Fatalf("Save profile error = %v", err)
+	}
+	// Roll back to before profile history existed (and the keyword index after it)
+	for _, stmt := range []string{
+		`DROP TABLE profile_history`,
+		`DROP TABLE block_keywords`,
+		`ALTER TABLE bridge_blocks ADD COLUMN keywords TEXT`,
+		`ALTER TABLE bridge_blocks DROP COLUMN exclude_from_recall`,
+		`ALTER TABLE facts DROP COLUMN exclude_from_recall`,
+		`ALTER TABLE turns DROP COLUMN annotations`,
+		`ALTER TABLE turns DROP COLUMN edited_at`,
+		`ALTER TABLE embeddings DROP COLUMN format`,
+		`DROP TABLE settings`,
+		`DROP INDEX idx_facts_pending`,
+		`ALTER TABLE facts DROP COLUMN pending`,
+		`ALTER TABLE bridge_blocks DROP COLUMN retention`,
+		`DROP TABLE tag_retention`,
+		`DROP TABLE block_access`,
+		`DROP TABLE memory_feedback`,
+		`DROP TABLE chunks`,
+		`DROP TABLE compactions`,
+		`DROP TABLE undo_log`,
+		`ALTER TABLE bridge_blocks DROP COLUMN centroid`,
+		`ALTER TABLE bridge_blocks DROP COLUMN centroid_count`,
+		`ALTER TABLE bridge_blocks DROP COLUMN centroid_model`,
+		`PRAGMA user_version = 18`,
+	} {
+		if _, err := store.db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	_ = store.Close()
+
+	store, err = NewStorageWithPath(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	versions, err := store.profile.Versions(10)
+	if err != nil || len(versions) != 1 || versions[0].Name != "Harper" || len(versions[0].Preferences) != 1 {
+		t.Fatalf("Versions() = %+v, %v; want the existing profile seeded", versions, err)
+	}
+
+	// Saving the unchanged profile does not duplicate the seeded version
+	if err := store.profile.Save(profile); err != nil {
+		t.Fatalf("Save profile error = %v", err)
+	}
+	if versions, _ := store.profile.Versions(10); len(versions) != 1 {
+		t.Errorf("Versions() after an unchanged save = %d, want 1", len(versions))
+	}
+}
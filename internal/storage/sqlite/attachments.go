@@ -0,0 +1,141 @@
+// ABOUTME: Attachment storage for SQLite
+// ABOUTME: Persists files, URLs, and blobs on turns and looks them up by ID or block
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// AttachmentStore handles attachment persistence
+type AttachmentStore struct {
+	db *DB
+}
+
+// NewAttachmentStore creates a new AttachmentStore
+func NewAttachmentStore(db *DB) *AttachmentStore {
+	return &AttachmentStore{db: db}
+}
+
+// attachmentColumns selects an attachment joined with its turn's block
+const attachmentColumns = `a.id, a.turn_id, t.block_id, a.kind, a.name, a.ref, a.mime_type, a.content, a.created_at`
+
+// Save inserts or replaces an attachment on a.TurnID
+func (s *AttachmentStore) Save(a *models.Attachment) error {
+	_, err := s.db.Exec(`
+		INSERT INTO attachments (id, turn_id, kind, name, ref, mime_type, content, created_at)
+		VALUES (?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), ?)
+		ON CONFLICT(id) DO UPDATE SET
+			kind = excluded.kind,
+			name = excluded.name,
+			ref = excluded.ref,
+			mime_type = excluded.mime_type,
+			content = excluded.content
+	`, a.AttachmentID, a.TurnID, string(a.Kind), a.Name, a.Ref, a.MimeType, a.Content, a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save attachment: %w", err)
+	}
+	return nil
+}
+
+// Get returns an attachment by ID, or nil if it does not exist
+func (s *AttachmentStore) Get(attachmentID string) (*models.Attachment, error) {
+	rows, err := s.db.Query(`
+		SELECT `+attachmentColumns+`
+		FROM attachments a JOIN turns t ON t.id = a.turn_id
+		WHERE a.id = ?
+	`, attachmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	attachments, err := scanAttachments(rows)
+	if err != nil || len(attachments) == 0 {
+		return nil, err
+	}
+	return &attachments[0], nil
+}
+
+// ForBlocks returns the attachments on each block's turns, oldest first
+func (s *AttachmentStore) ForBlocks(blockIDs []string) (map[string][]models.Attachment, error) {
+	byBlock := make(map[string][]models.Attachment)
+	if len(blockIDs) == 0 {
+		return byBlock, nil
+	}
+
+	args := make([]interface{}, len(blockIDs))
+	for i, id := range blockIDs {
+		args[i] = id
+	}
+	rows, err := s.db.Query(`
+		SELECT `+attachmentColumns+`
+		FROM attachments a JOIN turns t ON t.id = a.turn_id
+		WHERE t.block_id IN (`+placeholders(len(blockIDs))+`)
+		ORDER BY a.created_at ASC, a.id ASC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	attachments, err := scanAttachments(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range attachments {
+		byBlock[a.BlockID] = append(byBlock[a.BlockID], a)
+	}
+	return byBlock, nil
+}
+
+// BlocksMatching returns blocks with an attachment whose name appears in query or contains it
+func (s *AttachmentStore) BlocksMatching(query string) (map[string]bool, error) {
+	rows, err := s.db.Query(`
+		SELECT t.block_id, a.name
+		FROM attachments a JOIN turns t ON t.id = a.turn_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match attachments: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	queryLower := strings.ToLower(strings.TrimSpace(query))
+	blocks := make(map[string]bool)
+	for rows.Next() {
+		var blockID, name string
+		if err := rows.Scan(&blockID, &name); err != nil {
+			return nil, err
+		}
+		nameLower := strings.ToLower(name)
+		if queryLower != "" && (strings.Contains(queryLower, nameLower) || strings.Contains(nameLower, queryLower)) {
+			blocks[blockID] = true
+		}
+	}
+	return blocks, rows.Err()
+}
+
+// scanAttachments scans rows selected with attachmentColumns
+func scanAttachments(rows *Rows) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	for rows.Next() {
+		var (
+			a                      models.Attachment
+			kind                   string
+			ref, mimeType, content sql.NullString
+		)
+		if err := rows.Scan(&a.AttachmentID, &a.TurnID, &a.BlockID, &kind, &a.Name,
+			&ref, &mimeType, &content, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.Kind = models.AttachmentKind(kind)
+		a.Ref = ref.String
+		a.MimeType = mimeType.String
+		a.Content = content.String
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
@@ -0,0 +1,148 @@
+// ABOUTME: Tests for turn attachments in storage
+// ABOUTME: Verifies attaching, lookup by block, search, embedding, and deletion
+package sqlite
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// fakeEmbedder returns a fixed vector for any text
+type fakeEmbedder struct{}
+
+func (fakeEmbedder) GenerateEmbedding(text string) ([]float64, error) {
+	vector := make([]float64, ExpectedDimension)
+	vector[0] = 1
+	return vector, nil
+}
+
+// singleChunker returns the whole text as one chunk
+type singleChunker struct{}
+
+func (singleChunker) ChunkTurn(text string, turnID string) ([]models.Chunk, error) {
+	return []models.Chunk{{ChunkID: "chunk_" + turnID, ChunkType: models.ChunkTypeTurn, Content: text, TurnID: turnID}}, nil
+}
+
+// countEmbeddings returns how many embeddings are stored under ownerID
+func countEmbeddings(t *testing.T, store *Storage, ownerID string) int {
+	t.Helper()
+	var n int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM embeddings WHERE turn_id = ?", ownerID).Scan(&n); err != nil {
+		t.Fatalf("count embeddings: %v", err)
+	}
+	return n
+}
+
+func TestAttachToTurn_AndBlockAttachments(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	turn := &models.Turn{TurnID: "turn_1", UserMessage: "let's review the design", Keywords: []string{"design"}}
+	blockID, err := store.StoreTurn(turn)
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	doc, _ := models.NewAttachment("docs/design-doc.md", "", "# Design\nCache layer in front of the API")
+	link, _ := models.NewAttachment("https://example.com/rfc", "RFC", "")
+	for _, a := range []*models.Attachment{doc, link} {
+		if err := store.AttachToTurn(turn.TurnID, a); err != nil {
+			t.Fatalf("AttachToTurn() error = %v", err)
+		}
+	}
+	if err := store.AttachToTurn("turn_missing", link); err == nil {
+		t.Error("AttachToTurn() on a missing turn should fail")
+	}
+
+	got, err := store.GetBlockAttachments(blockID)
+	if err != nil {
+		t.Fatalf("GetBlockAttachments() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("GetBlockAttachments() = %d attachments, want 2", len(got))
+	}
+	if got[0].Name != "design-doc.md" || got[0].Kind != models.AttachmentFile || got[0].BlockID != blockID || got[0].TurnID != "turn_1" {
+		t.Errorf("first attachment = %+v", got[0])
+	}
+	if got[1].Kind != models.AttachmentURL || got[1].Content != "" {
+		t.Errorf("second attachment = %+v", got[1])
+	}
+
+	a, err := store.GetAttachment(doc.AttachmentID)
+	if err != nil || a == nil || !strings.Contains(a.Content, "Cache layer") {
+		t.Errorf("GetAttachment() = %+v, %v", a, err)
+	}
+	if a, _ := store.GetAttachment("att_missing"); a != nil {
+		t.Errorf("GetAttachment(missing) = %+v, want nil", a)
+	}
+}
+
+func TestSearchMemory_FindsBlocksByAttachmentName(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	turn := &models.Turn{TurnID: "turn_1", UserMessage: "we went over it", Keywords: []string{"review"}}
+	blockID, _ := store.StoreTurn(turn)
+	doc, _ := models.NewAttachment("", "design doc", strings.Repeat("long design text ", 40))
+	if err := store.AttachToTurn(turn.TurnID, doc); err != nil {
+		t.Fatalf("AttachToTurn() error = %v", err)
+	}
+
+	results, err := store.SearchMemory("the design doc we discussed", 5)
+	if err != nil {
+		t.Fatalf("SearchMemory() error = %v", err)
+	}
+	if len(results) != 1 || results[0].BlockID != blockID {
+		t.Fatalf("SearchMemory() = %+v, want the block with the design doc", results)
+	}
+	if len(results[0].Attachments) != 1 {
+		t.Fatalf("result attachments = %+v, want 1", results[0].Attachments)
+	}
+	if preview := results[0].Attachments[0].Content; len([]rune(preview)) != attachmentPreviewLen+3 {
+		t.Errorf("attachment preview is %d runes, want %d", len([]rune(preview)), attachmentPreviewLen+3)
+	}
+}
+
+func TestAttachToTurn_EmbedsContentAndDeleteRemovesIt(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	store.SetOpenAIClient(fakeEmbedder{})
+	store.SetChunkEngine(singleChunker{})
+
+	turn := &models.Turn{TurnID: "turn_1", UserMessage: "see attached"}
+	if _, err := store.StoreTurn(turn); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	blob, _ := models.NewAttachment("", "notes", "deploy checklist")
+	if err := store.AttachToTurn(turn.TurnID, blob); err != nil {
+		t.Fatalf("AttachToTurn() error = %v", err)
+	}
+	if n := countEmbeddings(t, store, blob.AttachmentID); n != 1 {
+		t.Errorf("attachment embeddings = %d, want 1", n)
+	}
+
+	deleted, err := store.DeleteAttachment(blob.AttachmentID)
+	if err != nil || !deleted {
+		t.Fatalf("DeleteAttachment() = %v, %v; want true", deleted, err)
+	}
+	if n := countEmbeddings(t, store, blob.AttachmentID); n != 0 {
+		t.Errorf("attachment embeddings after delete = %d, want 0", n)
+	}
+	if n := countEmbeddings(t, store, turn.TurnID); n != 1 {
+		t.Errorf("turn embeddings after delete = %d, want 1 (untouched)", n)
+	}
+	if deleted, _ := store.DeleteAttachment(blob.AttachmentID); deleted {
+		t.Error("DeleteAttachment() twice should report not found")
+	}
+}
@@ -0,0 +1,91 @@
+// ABOUTME: Access tracking for bridge blocks: how often and how recently each was retrieved or hydrated
+// ABOUTME: Feeds eviction importance and the hot topics in memory stats
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BlockHeat is how often and how recently a block's memory was read back
+type BlockHeat struct {
+	BlockID        string    `json:"block_id"`
+	TopicLabel     string    `json:"topic_label"`
+	Status         string    `json:"status"`
+	AccessCount    int       `json:"access_count"`
+	LastAccessedAt time.Time `json:"last_accessed_at"`
+}
+
+// RecordBlockAccess counts one read of each block, e.g. one retrieve_memory result or
+// one hydrated prompt, and stamps when it happened. Unknown blocks are ignored, and a
+// read-only database records nothing.
+func (s *Storage) RecordBlockAccess(blockIDs ...string) error {
+	if len(blockIDs) == 0 || s.db.ReadOnly() {
+		return nil
+	}
+	seen := make(map[string]bool, len(blockIDs))
+	unique := make([]any, 0, len(blockIDs)+1)
+	unique = append(unique, s.db.now())
+	for _, id := range blockIDs {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 1 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.db.Exec(`
+		INSERT INTO block_access (block_id, access_count, last_accessed_at)
+		SELECT id, 1, ? FROM bridge_blocks WHERE id IN (?`+strings.Repeat(", ?", len(unique)-2)+`)
+		ON CONFLICT(block_id) DO UPDATE SET
+			access_count = access_count + 1,
+			last_accessed_at = excluded.last_accessed_at
+	`, unique...); err != nil {
+		return fmt.Errorf("failed to record block access: %w", err)
+	}
+	return nil
+}
+
+// BlockAccess returns how often and how recently a block was read, or nil if it never was
+func (s *Storage) BlockAccess(blockID string) (*BlockHeat, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	heat, err := s.blockHeat(`WHERE a.block_id = ?`, blockID)
+	if err != nil || len(heat) == 0 {
+		return nil, err
+	}
+	return &heat[0], nil
+}
+
+// hotTopics ranks the blocks read most often, most recently read first among equals
+func (s *Storage) hotTopics(limit int) ([]BlockHeat, error) {
+	return s.blockHeat(`ORDER BY a.access_count DESC, a.last_accessed_at DESC LIMIT ?`, limit)
+}
+
+// blockHeat reads access records joined to their blocks, filtered and ordered by clause
+func (s *Storage) blockHeat(clause string, args ...any) ([]BlockHeat, error) {
+	rows, err := s.db.Query(`
+		SELECT a.block_id, COALESCE(b.topic_label, ''), b.status, a.access_count, a.last_accessed_at
+		FROM block_access a
+		JOIN bridge_blocks b ON b.id = a.block_id
+		`+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read block access: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	heat := []BlockHeat{}
+	for rows.Next() {
+		var h BlockHeat
+		if err := rows.Scan(&h.BlockID, &h.TopicLabel, &h.Status, &h.AccessCount, &h.LastAccessedAt); err != nil {
+			return nil, err
+		}
+		heat = append(heat, h)
+	}
+	return heat, rows.Err()
+}
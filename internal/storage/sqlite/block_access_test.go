@@ -0,0 +1,107 @@
+// ABOUTME: Tests for block access tracking
+// ABOUTME: Verifies reads are counted and stamped, hot topics are ranked, and reads weigh against eviction
+
+package sqlite
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestRecordBlockAccess(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	old := time.Now().Add(-90 * 24 * time.Hour)
+	for _, id := range []string{"block_a", "block_b", "block_c"} {
+		if err := store.blocks.Save(&models.BridgeBlock{BlockID: id, DayID: "2025-01-01", TopicLabel: id, Status: models.StatusArchived, CreatedAt: old, UpdatedAt: old}); err != nil {
+			t.Fatalf("Save block error = %v", err)
+		}
+		if err := store.turns.Save(id, &models.Turn{TurnID: id + "_turn", Timestamp: old, UserMessage: strings.Repeat("x", 4000)}); err != nil {
+			t.Fatalf("Save turn error = %v", err)
+		}
+	}
+
+	if heat, err := store.BlockAccess("block_a"); err != nil || heat != nil {
+		t.Fatalf("BlockAccess() before any read = %+v, %v, want nil", heat, err)
+	}
+
+	// One call counts each block once; unknown and empty IDs are ignored
+	before := time.Now().Add(-time.Second)
+	if err := store.RecordBlockAccess("block_a", "block_a", "block_missing", ""); err != nil {
+		t.Fatalf("RecordBlockAccess() error = %v", err)
+	}
+	if err := store.RecordBlockAccess("block_a", "block_b"); err != nil {
+		t.Fatalf("RecordBlockAccess() error = %v", err)
+	}
+	heat, err := store.BlockAccess("block_a")
+	if err != nil || heat == nil {
+		t.Fatalf("BlockAccess() = %+v, %v", heat, err)
+	}
+	if heat.AccessCount != 2 || heat.LastAccessedAt.Before(before) || heat.TopicLabel != "block_a" {
+		t.Errorf("BlockAccess() = %+v, want 2 reads just now", heat)
+	}
+	if heat, err := store.BlockAccess("block_missing"); err != nil || heat != nil {
+		t.Errorf("BlockAccess() of an unknown block = %+v, %v, want nil", heat, err)
+	}
+
+	// Recording reads leaves the block's own updated_at alone
+	block, err := store.GetBridgeBlock("block_a")
+	if err != nil || block == nil || block.UpdatedAt.After(before) {
+		t.Errorf("block after reads = %+v, %v, want updated_at untouched", block, err)
+	}
+
+	stats, err := store.Stats(5)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	var hot []string
+	for _, h := range stats.HotTopics {
+		hot = append(hot, h.BlockID)
+	}
+	if got := strings.Join(hot, ","); got != "block_a,block_b" {
+		t.Errorf("HotTopics = %s, want block_a,block_b", got)
+	}
+
+	// Blocks read within the retention are not evicted, however old their last update
+	plan, err := store.EvictionPlan(0, 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("EvictionPlan() error = %v", err)
+	}
+	if len(plan) != 1 || plan[0].BlockID != "block_c" {
+		t.Errorf("EvictionPlan() = %+v, want only the unread block_c", plan)
+	}
+
+	// Once the reads are old too, each one adds to a block's importance
+	if _, err := store.db.Exec(`UPDATE block_access SET last_accessed_at = ?`, old); err != nil {
+		t.Fatal(err)
+	}
+	plan, err = store.EvictionPlan(0, 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("EvictionPlan() error = %v", err)
+	}
+	var ids []string
+	for _, block := range plan {
+		ids = append(ids, block.BlockID)
+	}
+	if got := strings.Join(ids, ","); got != "block_c,block_b,block_a" {
+		t.Errorf("EvictionPlan() = %s, want block_c,block_b,block_a", got)
+	}
+	if plan[2].Importance != 3 {
+		t.Errorf("importance of block_a = %d, want 1 turn + 2 reads", plan[2].Importance)
+	}
+
+	// Deleting a block deletes its access record
+	if err := store.DeleteBridgeBlock("block_a"); err != nil {
+		t.Fatalf("DeleteBridgeBlock() error = %v", err)
+	}
+	if heat, err := store.BlockAccess("block_a"); err != nil || heat != nil {
+		t.Errorf("BlockAccess() of a deleted block = %+v, %v, want nil", heat, err)
+	}
+}
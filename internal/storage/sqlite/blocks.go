@@ -5,47 +5,122 @@ package sqlite
 import (
 	"database/sql"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/harper/remember-standalone/internal/models"
 )
 
+// blockColumns are the columns block reads select, with the keywords gathered from
+// block_keywords into a JSON array in the order they were first seen
+const blockColumns = `id, day_id, topic_label,
+	(SELECT json_group_array(keyword) FROM (
+		SELECT keyword FROM block_keywords WHERE block_id = bridge_blocks.id ORDER BY position)),
+	status, summary, turn_count, created_at, updated_at, exclude_from_recall, COALESCE(retention, '')`
+
 // BlockStore handles bridge block persistence
 type BlockStore struct {
-	db *DB
+	db    *DB
+	cache *lruCache[[]models.BridgeBlock] // Reads by ID, with turns, by status, and of all blocks
 }
 
 // NewBlockStore creates a new BlockStore
 func NewBlockStore(db *DB) *BlockStore {
-	return &BlockStore{db: db}
+	return &BlockStore{
+		db:    db,
+		cache: newLRUCache("blocks", db, blockCacheSize, cloneBlocks, "bridge_blocks", "block_keywords", "turns"),
+	}
 }
 
 // Save saves or updates a bridge block (upsert)
 func (s *BlockStore) Save(block *models.BridgeBlock) error {
-	keywordsJSON, err := json.Marshal(block.Keywords)
+	tx, err := s.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := saveBlock(tx, block); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
-	_, err = s.db.Exec(`
-		INSERT INTO bridge_blocks (id, day_id, topic_label, keywords, status, summary, turn_count, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+// saveBlock upserts a bridge block and replaces its keywords through ex, which should
+// be a transaction so the two are written together
+func saveBlock(ex execer, block *models.BridgeBlock) error {
+	if _, err := ex.Exec(`
+		INSERT INTO bridge_blocks (id, day_id, topic_label, status, summary, turn_count, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			day_id = excluded.day_id,
 			topic_label = excluded.topic_label,
-			keywords = excluded.keywords,
 			status = excluded.status,
 			summary = excluded.summary,
 			turn_count = excluded.turn_count,
 			updated_at = excluded.updated_at
-	`, block.BlockID, block.DayID, block.TopicLabel, string(keywordsJSON), string(block.Status),
-		block.Summary, block.TurnCount, block.CreatedAt, block.UpdatedAt)
+	`, utcArgs([]interface{}{block.BlockID, block.DayID, block.TopicLabel, string(block.Status),
+		block.Summary, block.TurnCount, block.CreatedAt, block.UpdatedAt})...); err != nil {
+		return err
+	}
+	return setBlockKeywords(ex, block.BlockID, block.Keywords)
+}
 
-	return err
+// AddTurn counts one more turn in a block, stamps it updated at, and merges in the
+// turn's keywords. It increments the stored count rather than saving a count read
+// earlier, so appends from other processes at the same time are not lost.
+func (s *BlockStore) AddTurn(blockID string, keywords []string, at time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`UPDATE bridge_blocks SET turn_count = turn_count + 1, updated_at = ? WHERE id = ?`,
+		utcArgs([]interface{}{at, blockID})...); err != nil {
+		return err
+	}
+	if err := addBlockKeywords(tx, blockID, keywords); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // Get retrieves a bridge block by ID (without turns)
 func (s *BlockStore) Get(blockID string) (*models.BridgeBlock, error) {
+	return s.loadOne("block:"+blockID, func() (*models.BridgeBlock, error) { return s.get(blockID) })
+}
+
+// loadOne reads a single block through the cache; missing blocks are not cached
+func (s *BlockStore) loadOne(key string, fetch func() (*models.BridgeBlock, error)) (*models.BridgeBlock, error) {
+	blocks, err := s.cache.load(key, func() ([]models.BridgeBlock, bool, error) {
+		block, err := fetch()
+		if err != nil || block == nil {
+			return nil, false, err
+		}
+		return []models.BridgeBlock{*block}, true, nil
+	})
+	if err != nil || len(blocks) == 0 {
+		return nil, err
+	}
+	return &blocks[0], nil
+}
+
+// loadList reads a list of blocks through the cache
+func (s *BlockStore) loadList(key string, fetch func() ([]models.BridgeBlock, error)) ([]models.BridgeBlock, error) {
+	return s.cache.load(key, func() ([]models.BridgeBlock, bool, error) {
+		blocks, err := fetch()
+		return blocks, err == nil, err
+	})
+}
+
+// CacheStats reports the block cache's hits and misses
+func (s *BlockStore) CacheStats() CacheStats {
+	return s.cache.stats()
+}
+
+// get reads a bridge block by ID from the database
+func (s *BlockStore) get(blockID string) (*models.BridgeBlock, error) {
 	var (
 		block        models.BridgeBlock
 		keywordsJSON sql.NullString
@@ -54,11 +129,11 @@ func (s *BlockStore) Get(blockID string) (*models.BridgeBlock, error) {
 	)
 
 	err := s.db.QueryRow(`
-		SELECT id, day_id, topic_label, keywords, status, summary, turn_count, created_at, updated_at
+		SELECT `+blockColumns+`
 		FROM bridge_blocks
 		WHERE id = ?
 	`, blockID).Scan(&block.BlockID, &block.DayID, &block.TopicLabel, &keywordsJSON,
-		&status, &summary, &block.TurnCount, &block.CreatedAt, &block.UpdatedAt)
+		&status, &summary, &block.TurnCount, &block.CreatedAt, &block.UpdatedAt, &block.ExcludeFromRecall, &block.Retention)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -86,7 +161,12 @@ func (s *BlockStore) Get(blockID string) (*models.BridgeBlock, error) {
 
 // GetWithTurns retrieves a bridge block with all its turns
 func (s *BlockStore) GetWithTurns(blockID string) (*models.BridgeBlock, error) {
-	block, err := s.Get(blockID)
+	return s.loadOne("turns:"+blockID, func() (*models.BridgeBlock, error) { return s.getWithTurns(blockID) })
+}
+
+// getWithTurns reads a bridge block and its turns from the database
+func (s *BlockStore) getWithTurns(blockID string) (*models.BridgeBlock, error) {
+	block, err := s.get(blockID)
 	if err != nil || block == nil {
 		return block, err
 	}
@@ -99,14 +179,20 @@ func (s *BlockStore) GetWithTurns(blockID string) (*models.BridgeBlock, error) {
 
 	block.Turns = turns
 	block.TurnCount = len(turns)
+	block.Affect, block.AffectCounts = models.AggregateAffect(turns)
 
 	return block, nil
 }
 
 // GetByStatus retrieves all blocks with a specific status
 func (s *BlockStore) GetByStatus(status models.BridgeBlockStatus) ([]models.BridgeBlock, error) {
+	return s.loadList("status:"+string(status), func() ([]models.BridgeBlock, error) { return s.getByStatus(status) })
+}
+
+// getByStatus reads the blocks with status from the database
+func (s *BlockStore) getByStatus(status models.BridgeBlockStatus) ([]models.BridgeBlock, error) {
 	rows, err := s.db.Query(`
-		SELECT id, day_id, topic_label, keywords, status, summary, turn_count, created_at, updated_at
+		SELECT `+blockColumns+`
 		FROM bridge_blocks
 		WHERE status = ?
 		ORDER BY updated_at DESC
@@ -121,8 +207,13 @@ func (s *BlockStore) GetByStatus(status models.BridgeBlockStatus) ([]models.Brid
 
 // ListAll retrieves all bridge blocks
 func (s *BlockStore) ListAll() ([]models.BridgeBlock, error) {
+	return s.loadList("all", s.listAll)
+}
+
+// listAll reads every bridge block from the database
+func (s *BlockStore) listAll() ([]models.BridgeBlock, error) {
 	rows, err := s.db.Query(`
-		SELECT id, day_id, topic_label, keywords, status, summary, turn_count, created_at, updated_at
+		SELECT ` + blockColumns + `
 		FROM bridge_blocks
 		ORDER BY updated_at DESC
 	`)
@@ -134,6 +225,50 @@ func (s *BlockStore) ListAll() ([]models.BridgeBlock, error) {
 	return s.scanBlocks(rows)
 }
 
+// ListByIDs reads the blocks among ids that recall may return (not excluded from it),
+// most recently updated first
+func (s *BlockStore) ListByIDs(ids []string) ([]models.BridgeBlock, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := s.db.Query(`
+		SELECT `+blockColumns+`
+		FROM bridge_blocks
+		WHERE id IN (?`+strings.Repeat(", ?", len(ids)-1)+`) AND NOT exclude_from_recall
+		ORDER BY updated_at DESC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	return s.scanBlocks(rows)
+}
+
+// MatchTopicLabels returns the IDs of blocks whose topic label contains query,
+// ignoring case the way keywords do
+func (s *BlockStore) MatchTopicLabels(query string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT id FROM bridge_blocks WHERE instr(fold_case(topic_label), ?) > 0`, foldCase(query))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 // Delete removes a bridge block (turns will cascade delete)
 func (s *BlockStore) Delete(blockID string) error {
 	_, err := s.db.Exec("DELETE FROM bridge_blocks WHERE id = ?", blockID)
@@ -146,12 +281,12 @@ func (s *BlockStore) UpdateStatus(blockID string, status models.BridgeBlockStatu
 		UPDATE bridge_blocks
 		SET status = ?, updated_at = ?
 		WHERE id = ?
-	`, string(status), time.Now(), blockID)
+	`, string(status), s.db.now(), blockID)
 	return err
 }
 
 // scanBlocks scans rows into a slice of BridgeBlock
-func (s *BlockStore) scanBlocks(rows *sql.Rows) ([]models.BridgeBlock, error) {
+func (s *BlockStore) scanBlocks(rows *Rows) ([]models.BridgeBlock, error) {
 	var blocks []models.BridgeBlock
 
 	for rows.Next() {
@@ -163,7 +298,7 @@ func (s *BlockStore) scanBlocks(rows *sql.Rows) ([]models.BridgeBlock, error) {
 		)
 
 		err := rows.Scan(&block.BlockID, &block.DayID, &block.TopicLabel, &keywordsJSON,
-			&status, &summary, &block.TurnCount, &block.CreatedAt, &block.UpdatedAt)
+			&status, &summary, &block.TurnCount, &block.CreatedAt, &block.UpdatedAt, &block.ExcludeFromRecall, &block.Retention)
 		if err != nil {
 			return nil, err
 		}
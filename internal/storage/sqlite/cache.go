@@ -0,0 +1,259 @@
+// ABOUTME: Small in-process LRU caches for blocks, the user profile, and memory searches
+// ABOUTME: Entries are keyed to table write versions, so any write to their tables invalidates them
+package sqlite
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// Cache sizes: block entries cover single blocks, blocks with turns, and block lists
+const (
+	blockCacheSize       = 256
+	profileCacheSize     = 1
+	searchCacheSize      = 128
+	queryVectorCacheSize = 256
+)
+
+// CacheStats reports how well a cache is working
+type CacheStats struct {
+	Name     string `json:"name"`
+	Hits     int64  `json:"hits"`
+	Misses   int64  `json:"misses"`
+	Size     int    `json:"size"`
+	Capacity int    `json:"capacity"`
+}
+
+// HitRate returns hits as a fraction of lookups, or 0 before any lookup
+func (c CacheStats) HitRate() float64 {
+	if c.Hits+c.Misses == 0 {
+		return 0
+	}
+	return float64(c.Hits) / float64(c.Hits+c.Misses)
+}
+
+// statsCache is a cache whose counters Storage reports and saves
+type statsCache interface {
+	stats() CacheStats
+	takeCounts() (hits, misses int64)
+}
+
+// lruCache is a fixed-size least-recently-used cache. Each entry remembers the table
+// version it was read at and is only served while that version is current. Values
+// are cloned going in and coming out, so callers may modify what they get. A cache
+// with no db holds values no write can make stale.
+type lruCache[V any] struct {
+	name     string
+	db       *DB      // nil = entries never go stale
+	tables   []string // Tables the cached reads depend on
+	capacity int
+	clone    func(V) V
+
+	mu      sync.Mutex
+	order   *list.List // Most recently used at the front
+	entries map[string]*list.Element
+	hits    atomic.Int64
+	misses  atomic.Int64
+}
+
+type lruEntry[V any] struct {
+	key     string
+	version uint64
+	value   V
+}
+
+// newLRUCache creates a cache of reads from tables
+func newLRUCache[V any](name string, db *DB, capacity int, clone func(V) V, tables ...string) *lruCache[V] {
+	return &lruCache[V]{
+		name:     name,
+		db:       db,
+		tables:   tables,
+		capacity: capacity,
+		clone:    clone,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// load returns the cached value for key, or calls fetch and caches its result when
+// ok reports it is worth keeping (e.g. not a missing row)
+func (c *lruCache[V]) load(key string, fetch func() (value V, ok bool, err error)) (V, error) {
+	version, versionOK := uint64(0), true
+	if c.db != nil {
+		version, versionOK = c.db.tableVersion(c.tables...)
+	}
+	if versionOK {
+		if value, hit := c.get(key, version); hit {
+			c.hits.Add(1)
+			return value, nil
+		}
+	}
+	c.misses.Add(1)
+
+	value, ok, err := fetch()
+	if err == nil && ok && versionOK {
+		c.put(key, version, value)
+	}
+	return value, err
+}
+
+// get returns a copy of the entry for key if it was read at version
+func (c *lruCache[V]) get(key string, version uint64) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var zero V
+	el, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+	entry := el.Value.(*lruEntry[V])
+	if entry.version != version {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return c.clone(entry.value), true
+}
+
+// put stores a copy of value for key, evicting the least recently used entry when full
+func (c *lruCache[V]) put(key string, version uint64, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value = &lruEntry[V]{key: key, version: version, value: c.clone(value)}
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry[V]{key: key, version: version, value: c.clone(value)})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[V]).key)
+	}
+}
+
+// stats returns the cache's counters and size
+func (c *lruCache[V]) stats() CacheStats {
+	c.mu.Lock()
+	size := c.order.Len()
+	c.mu.Unlock()
+	return CacheStats{
+		Name:     c.name,
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+		Size:     size,
+		Capacity: c.capacity,
+	}
+}
+
+// takeCounts returns and zeroes the hit and miss counters
+func (c *lruCache[V]) takeCounts() (hits, misses int64) {
+	return c.hits.Swap(0), c.misses.Swap(0)
+}
+
+// cloneBlocks deep-copies blocks and their turns
+func cloneBlocks(blocks []models.BridgeBlock) []models.BridgeBlock {
+	if blocks == nil {
+		return nil
+	}
+	out := make([]models.BridgeBlock, len(blocks))
+	for i, b := range blocks {
+		b.Keywords = cloneStrings(b.Keywords)
+		b.Tags = cloneStrings(b.Tags)
+		b.Turns = cloneTurns(b.Turns)
+		if b.AffectCounts != nil {
+			counts := make(map[models.Affect]int, len(b.AffectCounts))
+			for affect, n := range b.AffectCounts {
+				counts[affect] = n
+			}
+			b.AffectCounts = counts
+		}
+		out[i] = b
+	}
+	return out
+}
+
+// cloneTurns deep-copies turns and their messages
+func cloneTurns(turns []models.Turn) []models.Turn {
+	if turns == nil {
+		return nil
+	}
+	out := make([]models.Turn, len(turns))
+	for i, t := range turns {
+		t.Keywords = cloneStrings(t.Keywords)
+		t.Topics = cloneStrings(t.Topics)
+		if t.Messages != nil {
+			messages := make([]models.Message, len(t.Messages))
+			for j, m := range t.Messages {
+				if m.ToolCalls != nil {
+					m.ToolCalls = append([]models.ToolCall(nil), m.ToolCalls...)
+				}
+				messages[j] = m
+			}
+			t.Messages = messages
+		}
+		if t.Annotations != nil {
+			t.Annotations = append([]models.TurnAnnotation(nil), t.Annotations...)
+		}
+		if t.EditedAt != nil {
+			edited := *t.EditedAt
+			t.EditedAt = &edited
+		}
+		out[i] = t
+	}
+	return out
+}
+
+// cloneSearchResults deep-copies search results and their turns
+func cloneSearchResults(results []models.MemorySearchResult) []models.MemorySearchResult {
+	if results == nil {
+		return nil
+	}
+	out := make([]models.MemorySearchResult, len(results))
+	for i, r := range results {
+		r.Turns = cloneTurns(r.Turns)
+		r.Tags = cloneStrings(r.Tags)
+		if r.Attachments != nil {
+			r.Attachments = append([]models.Attachment(nil), r.Attachments...)
+		}
+		if r.Snippets != nil {
+			r.Snippets = append([]models.Snippet(nil), r.Snippets...)
+		}
+		out[i] = r
+	}
+	return out
+}
+
+// cloneVector copies an embedding vector
+func cloneVector(v []float64) []float64 {
+	if v == nil {
+		return nil
+	}
+	return append([]float64{}, v...)
+}
+
+// cloneProfile deep-copies a user profile
+func cloneProfile(p *models.UserProfile) *models.UserProfile {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	c.Preferences = cloneStrings(p.Preferences)
+	c.TopicsOfInterest = cloneStrings(p.TopicsOfInterest)
+	if p.Constraints != nil {
+		c.Constraints = append([]models.ProfileConstraint(nil), p.Constraints...)
+	}
+	return &c
+}
+
+// cloneStrings copies a string slice, keeping nil and empty distinct
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string{}, s...)
+}
@@ -0,0 +1,250 @@
+// ABOUTME: Tests for the block, profile, and search caches
+// ABOUTME: Verifies hits, invalidation by local and cross-process writes, copy isolation, and saved stats
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// cacheStat returns the named cache's stats from store
+func cacheStat(t *testing.T, store *Storage, name string) CacheStats {
+	t.Helper()
+	stats, err := store.CacheStats()
+	if err != nil {
+		t.Fatalf("CacheStats() error = %v", err)
+	}
+	for _, c := range stats {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("no %q cache in %+v", name, stats)
+	return CacheStats{}
+}
+
+func TestBlockCache_HitsAndInvalidation(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_1", UserMessage: "first", Topics: []string{"cache"}})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	before := cacheStat(t, store, "blocks")
+	for i := 0; i < 3; i++ {
+		if _, err := store.GetBridgeBlock(blockID); err != nil {
+			t.Fatalf("GetBridgeBlock() error = %v", err)
+		}
+	}
+	after := cacheStat(t, store, "blocks")
+	if hits := after.Hits - before.Hits; hits != 2 {
+		t.Errorf("hits = %d, want 2 (first read misses, the rest hit)", hits)
+	}
+
+	if err := store.AppendTurnToBlock(blockID, &models.Turn{TurnID: "turn_2", UserMessage: "second"}); err != nil {
+		t.Fatalf("AppendTurnToBlock() error = %v", err)
+	}
+	block, err := store.GetBridgeBlock(blockID)
+	if err != nil {
+		t.Fatalf("GetBridgeBlock() error = %v", err)
+	}
+	if len(block.Turns) != 2 {
+		t.Errorf("turns after append = %d, want 2", len(block.Turns))
+	}
+
+	if err := store.UpdateBridgeBlockStatus(blockID, models.StatusPaused); err != nil {
+		t.Fatalf("UpdateBridgeBlockStatus() error = %v", err)
+	}
+	block, err = store.GetBridgeBlock(blockID)
+	if err != nil {
+		t.Fatalf("GetBridgeBlock() error = %v", err)
+	}
+	if block.Status != models.StatusPaused {
+		t.Errorf("Status = %s, want %s", block.Status, models.StatusPaused)
+	}
+	paused, err := store.GetPausedBridgeBlocks()
+	if err != nil {
+		t.Fatalf("GetPausedBridgeBlocks() error = %v", err)
+	}
+	if len(paused) != 1 {
+		t.Errorf("paused blocks = %d, want 1", len(paused))
+	}
+}
+
+func TestBlockCache_ReturnsCopies(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_1", UserMessage: "original", Keywords: []string{"kept"}})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	block, err := store.GetBridgeBlock(blockID)
+	if err != nil {
+		t.Fatalf("GetBridgeBlock() error = %v", err)
+	}
+	block.Turns[0].UserMessage = "changed"
+	block.Turns[0].Keywords[0] = "changed"
+	block.Keywords = append(block.Keywords, "changed")
+
+	again, err := store.GetBridgeBlock(blockID)
+	if err != nil {
+		t.Fatalf("GetBridgeBlock() error = %v", err)
+	}
+	if again.Turns[0].UserMessage != "original" || again.Turns[0].Keywords[0] != "kept" {
+		t.Errorf("cached turn changed by caller: %+v", again.Turns[0])
+	}
+	for _, k := range again.Keywords {
+		if k == "changed" {
+			t.Errorf("cached keywords changed by caller: %v", again.Keywords)
+		}
+	}
+}
+
+func TestProfileCache(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.SaveUserProfile(&models.UserProfile{Name: "Ada", Preferences: []string{"tea"}}); err != nil {
+		t.Fatalf("SaveUserProfile() error = %v", err)
+	}
+	profile, err := store.GetUserProfile()
+	if err != nil {
+		t.Fatalf("GetUserProfile() error = %v", err)
+	}
+	profile.Preferences[0] = "coffee"
+
+	profile, err = store.GetUserProfile()
+	if err != nil {
+		t.Fatalf("GetUserProfile() error = %v", err)
+	}
+	if profile.Preferences[0] != "tea" {
+		t.Errorf("Preferences = %v, want the stored [tea]", profile.Preferences)
+	}
+	if c := cacheStat(t, store, "profile"); c.Hits < 1 {
+		t.Errorf("profile hits = %d, want at least 1", c.Hits)
+	}
+
+	if err := store.SaveUserProfile(&models.UserProfile{Name: "Grace"}); err != nil {
+		t.Fatalf("SaveUserProfile() error = %v", err)
+	}
+	profile, err = store.GetUserProfile()
+	if err != nil {
+		t.Fatalf("GetUserProfile() error = %v", err)
+	}
+	if profile.Name != "Grace" {
+		t.Errorf("Name = %q after save, want Grace", profile.Name)
+	}
+}
+
+func TestBlockCache_SeesOtherProcessWrites(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+	reader, err := NewStorageWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewStorageWithPath() error = %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	blockID, err := reader.StoreTurn(&models.Turn{TurnID: "turn_1", UserMessage: "first"})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if _, err := reader.GetBridgeBlock(blockID); err != nil {
+		t.Fatalf("GetBridgeBlock() error = %v", err)
+	}
+
+	// A second Storage on the same file stands in for another process
+	writer, err := NewStorageWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewStorageWithPath() error = %v", err)
+	}
+	if err := writer.UpdateBridgeBlockStatus(blockID, models.StatusClosed); err != nil {
+		t.Fatalf("UpdateBridgeBlockStatus() error = %v", err)
+	}
+	_ = writer.Close()
+
+	block, err := reader.GetBridgeBlock(blockID)
+	if err != nil {
+		t.Fatalf("GetBridgeBlock() error = %v", err)
+	}
+	if block.Status != models.StatusClosed {
+		t.Errorf("Status = %s, want %s written by the other process", block.Status, models.StatusClosed)
+	}
+}
+
+func TestCacheStats_SavedOnClose(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+	store, err := NewStorageWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewStorageWithPath() error = %v", err)
+	}
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_1", UserMessage: "first"})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := store.GetBridgeBlock(blockID); err != nil {
+			t.Fatalf("GetBridgeBlock() error = %v", err)
+		}
+	}
+	first := cacheStat(t, store, "blocks")
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	store, err = NewStorageWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewStorageWithPath() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	reopened := cacheStat(t, store, "blocks")
+	if reopened.Hits != first.Hits || reopened.Misses != first.Misses {
+		t.Errorf("after reopen hits/misses = %d/%d, want the saved %d/%d", reopened.Hits, reopened.Misses, first.Hits, first.Misses)
+	}
+	if reopened.Size != 0 {
+		t.Errorf("Size = %d after reopen, want an empty cache", reopened.Size)
+	}
+
+	stats, err := store.Stats(5)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if len(stats.Caches) != 4 {
+		t.Errorf("Stats().Caches = %+v, want blocks, profile, searches, and query_vectors", stats.Caches)
+	}
+}
+
+func TestWrittenTable(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"INSERT INTO turns (id) VALUES (?)", "turns"},
+		{"\n\t\tINSERT OR REPLACE INTO user_profile (id) VALUES (1)", "user_profile"},
+		{"REPLACE INTO embeddings VALUES (?)", "embeddings"},
+		{"UPDATE bridge_blocks SET status = ?", "bridge_blocks"},
+		{"update OR IGNORE Facts SET value = ?", "facts"},
+		{"DELETE FROM \"turns\" WHERE id = ?", "turns"},
+		{"CREATE TABLE x (id INTEGER)", anyTable},
+		{"PRAGMA optimize", anyTable},
+	}
+	for _, tt := range tests {
+		if got := writtenTable(tt.query); got != tt.want {
+			t.Errorf("writtenTable(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// ABOUTME: Per-block centroid embeddings: the mean of a block's embeddings, kept on its row
+// ABOUTME: Folded in as each embedding is saved, and recomputed when embeddings are replaced, moved, or removed
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// BlockCentroid is the mean of a block's embeddings of one model, the block's position
+// for comparing a turn's embedding against whole topics
+type BlockCentroid struct {
+	BlockID string    `json:"block_id"`
+	Model   string    `json:"model,omitempty"`
+	Count   int       `json:"count"` // Embeddings averaged
+	Vector  []float64 `json:"vector"`
+}
+
+// centroidQuerier is a database or transaction that can read and update a block's centroid
+type centroidQuerier interface {
+	execer
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// BlockCentroids returns the centroids of the given blocks that have one, by block ID.
+// Blocks without embeddings have none; nor do blocks embedded before centroids were
+// kept until another of their turns is embedded or they are re-embedded.
+func (s *Storage) BlockCentroids(blockIDs []string) (map[string]BlockCentroid, error) {
+	centroids := make(map[string]BlockCentroid, len(blockIDs))
+	if len(blockIDs) == 0 {
+		return centroids, nil
+	}
+	args := make([]interface{}, len(blockIDs))
+	for i, id := range blockIDs {
+		args[i] = id
+	}
+	rows, err := s.db.Query(`
+		SELECT id, COALESCE(centroid_model, ''), centroid_count, centroid FROM bridge_blocks
+		WHERE centroid_count > 0 AND id IN (`+placeholders(len(blockIDs))+`)
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read block centroids: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var c BlockCentroid
+		var blob []byte
+		if err := rows.Scan(&c.BlockID, &c.Model, &c.Count, &blob); err != nil {
+			return nil, fmt.Errorf("failed to scan block centroid: %w", err)
+		}
+		c.Vector = blobToVector(blob)
+		centroids[c.BlockID] = c
+	}
+	return centroids, rows.Err()
+}
+
+// addToCentroid folds vector, an embedding of model just saved in blockID, into the
+// block's centroid. It reports stale when the centroid cannot be updated in place: it
+// averages another model or dimension, or none while the block already held other
+// embeddings of model (saved before centroids were kept); refreshCentroid rebuilds it.
+func addToCentroid(q centroidQuerier, blockID, model string, vector []float64) (stale bool, err error) {
+	var blob []byte
+	var count int
+	var centroidModel string
+	err = q.QueryRow(`
+		SELECT centroid, centroid_count, COALESCE(centroid_model, '') FROM bridge_blocks WHERE id = ?
+	`, blockID).Scan(&blob, &count, &centroidModel)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read block centroid: %w", err)
+	}
+
+	centroid := blobToVector(blob)
+	if count == 0 || centroidModel != model || len(centroid) != len(vector) {
+		var saved int
+		if err := q.QueryRow(`
+			SELECT COUNT(*) FROM embeddings WHERE block_id = ? AND COALESCE(model, '') = ?
+		`, blockID, model).Scan(&saved); err != nil {
+			return false, fmt.Errorf("failed to count block embeddings: %w", err)
+		}
+		if saved > 1 {
+			return true, nil
+		}
+		count, centroid = 0, make([]float64, len(vector))
+	}
+
+	// A running mean, so the block's other embeddings need not be read
+	for i, v := range vector {
+		centroid[i] += (v - centroid[i]) / float64(count+1)
+	}
+	return false, saveCentroid(q, blockID, model, count+1, centroid)
+}
+
+// refreshCentroid recomputes a block's centroid from its embeddings of the model saved
+// most recently, clearing it when the block has none
+func refreshCentroid(db *DB, blockID string) error {
+	rows, err := db.Query(`
+		SELECT COALESCE(model, ''), vector, format FROM embeddings
+		WHERE block_id = ?
+		ORDER BY created_at DESC, id
+	`, blockID)
+	if err != nil {
+		return fmt.Errorf("failed to read block embeddings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var model string
+	var sum []float64
+	count := 0
+	for rows.Next() {
+		var m string
+		var blob []byte
+		var format sql.NullString
+		if err := rows.Scan(&m, &blob, &format); err != nil {
+			return fmt.Errorf("failed to scan block embedding: %w", err)
+		}
+		vector := decodeVector(vectorFormatOf(format), blob)
+		if count == 0 {
+			model, sum = m, make([]float64, len(vector))
+		}
+		if m != model || len(vector) != len(sum) || len(vector) == 0 {
+			continue
+		}
+		for i, v := range vector {
+			sum[i] += v
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read block embeddings: %w", err)
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	if count == 0 {
+		if _, err := db.Exec(`
+			UPDATE bridge_blocks SET centroid = NULL, centroid_count = 0, centroid_model = NULL WHERE id = ?
+		`, blockID); err != nil {
+			return fmt.Errorf("failed to clear block centroid: %w", err)
+		}
+		return nil
+	}
+	for i := range sum {
+		sum[i] /= float64(count)
+	}
+	return saveCentroid(db, blockID, model, count, sum)
+}
+
+// refreshCentroids recomputes the centroids of blockIDs, skipping empty IDs and repeats
+func refreshCentroids(db *DB, blockIDs ...string) error {
+	seen := make(map[string]bool, len(blockIDs))
+	for _, id := range blockIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if err := refreshCentroid(db, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveCentroid writes a block's centroid
+func saveCentroid(ex execer, blockID, model string, count int, centroid []float64) error {
+	if _, err := ex.Exec(`
+		UPDATE bridge_blocks SET centroid = ?, centroid_count = ?, centroid_model = ? WHERE id = ?
+	`, vectorToBlob(centroid), count, nullString(model), blockID); err != nil {
+		return fmt.Errorf("failed to save block centroid: %w", err)
+	}
+	return nil
+}
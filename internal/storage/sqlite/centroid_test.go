@@ -0,0 +1,128 @@
+// ABOUTME: Tests for per-block centroid embeddings
+// ABOUTME: Verifies the running mean, rebuilds on re-embedding, model changes, splits, and deletes
+
+package sqlite
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestBlockCentroids(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Now()
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_c1", Timestamp: now, UserMessage: "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, id := range []string{"turn_c2", "turn_c3"} {
+		if err := store.AppendTurnToBlock(blockID, &models.Turn{TurnID: id, Timestamp: now.Add(time.Duration(i+1) * time.Second), UserMessage: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	empty, err := store.StoreTurn(&models.Turn{TurnID: "turn_c_empty", Timestamp: now, UserMessage: "never embedded"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	centroid := func(blockID string) BlockCentroid {
+		t.Helper()
+		centroids, err := store.BlockCentroids([]string{blockID})
+		if err != nil {
+			t.Fatalf("BlockCentroids() error = %v", err)
+		}
+		return centroids[blockID]
+	}
+	near := func(got, want []float64) bool {
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if math.Abs(got[i]-want[i]) > 1e-9 {
+				return false
+			}
+		}
+		return true
+	}
+	embed := func(turnID, model string, vectors ...[]float64) {
+		t.Helper()
+		chunks := make([]models.Chunk, len(vectors))
+		for i := range vectors {
+			chunks[i] = models.Chunk{ChunkID: turnID + "_" + string(rune('a'+i)), TurnID: turnID}
+		}
+		if err := store.ReplaceTurnEmbeddings(turnID, blockID, model, chunks, vectors); err != nil {
+			t.Fatalf("ReplaceTurnEmbeddings(%s) error = %v", turnID, err)
+		}
+	}
+
+	// Each embedding is folded into the running mean
+	embed("turn_c1", "model-a", []float64{1, 0}, []float64{0, 1})
+	embed("turn_c2", "model-a", []float64{4, 4})
+	if c := centroid(blockID); c.Count != 3 || c.Model != "model-a" || !near(c.Vector, []float64{5.0 / 3, 5.0 / 3}) {
+		t.Errorf("centroid = %+v, want the mean of three vectors", c)
+	}
+	if c := centroid(empty); c.Count != 0 || c.Vector != nil {
+		t.Errorf("centroid of a block without embeddings = %+v, want none", c)
+	}
+
+	// Re-embedding a turn replaces its vectors in the mean
+	embed("turn_c1", "model-a", []float64{2, 2})
+	if c := centroid(blockID); c.Count != 2 || !near(c.Vector, []float64{3, 3}) {
+		t.Errorf("centroid after re-embedding = %+v, want [3 3] over 2", c)
+	}
+
+	// A new model starts over with that model's vectors alone
+	embed("turn_c3", "model-b", []float64{1, 1, 1})
+	if c := centroid(blockID); c.Count != 1 || c.Model != "model-b" || !near(c.Vector, []float64{1, 1, 1}) {
+		t.Errorf("centroid after a model change = %+v, want model-b's vector", c)
+	}
+	embed("turn_c1", "model-b", []float64{3, 3, 3})
+	embed("turn_c2", "model-b", []float64{5, 5, 5})
+	if c := centroid(blockID); c.Count != 3 || !near(c.Vector, []float64{3, 3, 3}) {
+		t.Errorf("centroid after re-embedding with model-b = %+v, want [3 3 3] over 3", c)
+	}
+
+	// Blocks embedded before centroids were kept are rebuilt on their next embedding
+	if _, err := store.db.Exec("UPDATE bridge_blocks SET centroid = NULL, centroid_count = 0 WHERE id = ?", blockID); err != nil {
+		t.Fatal(err)
+	}
+	embed("turn_c3", "model-b", []float64{7, 7, 7})
+	if c := centroid(blockID); c.Count != 3 || !near(c.Vector, []float64{5, 5, 5}) {
+		t.Errorf("centroid after a rebuild = %+v, want [5 5 5] over 3", c)
+	}
+
+	// Splitting moves the tail's vectors to the new block's centroid
+	newBlock, err := store.SplitBridgeBlock(blockID, "turn_c2")
+	if err != nil {
+		t.Fatalf("SplitBridgeBlock() error = %v", err)
+	}
+	if c := centroid(blockID); c.Count != 1 || !near(c.Vector, []float64{3, 3, 3}) {
+		t.Errorf("original centroid after split = %+v, want turn_c1's vector", c)
+	}
+	if c := centroid(newBlock.BlockID); c.Count != 2 || !near(c.Vector, []float64{6, 6, 6}) {
+		t.Errorf("new block centroid after split = %+v, want the mean of the tail", c)
+	}
+
+	// Deleting an attachment takes its vectors out
+	if err := store.AttachToTurn("turn_c1", &models.Attachment{AttachmentID: "att_c1", Kind: models.AttachmentBlob, Name: "notes.txt", Content: "notes"}); err != nil {
+		t.Fatal(err)
+	}
+	embed("att_c1", "model-b", []float64{1, 1, 1})
+	if c := centroid(blockID); c.Count != 2 || !near(c.Vector, []float64{2, 2, 2}) {
+		t.Errorf("centroid with the attachment = %+v, want [2 2 2] over 2", c)
+	}
+	if ok, err := store.DeleteAttachment("att_c1"); err != nil || !ok {
+		t.Fatalf("DeleteAttachment() = %v, %v", ok, err)
+	}
+	if c := centroid(blockID); c.Count != 1 || c.Model != "model-b" || !near(c.Vector, []float64{3, 3, 3}) {
+		t.Errorf("centroid after deleting the attachment = %+v, want turn_c1's vector", c)
+	}
+}
@@ -0,0 +1,158 @@
+// ABOUTME: In-process change feed: channel subscriptions to the memory events storage publishes
+// ABOUTME: Webhooks and event streams subscribe here instead of polling the database for changes
+package sqlite
+
+import (
+	"log"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// subscriptionBuffer is how many events a subscription holds for a slow reader by default
+const subscriptionBuffer = 256
+
+// drainTimeout bounds how long Close waits for lossless subscribers to take their last events
+const drainTimeout = 5 * time.Second
+
+// SubscribeOptions choose the events a subscription receives and what happens when its
+// reader falls behind; the zero value receives every event and drops what overflows
+type SubscribeOptions struct {
+	Types    []models.EventType // Only these types; empty means every type
+	Buffer   int                // Events held for a slow reader; 0 uses the default of 256
+	Lossless bool               // Writers wait for room instead of dropping events, and Close waits for the reader to finish
+}
+
+// Subscription is a channel of memory events published after the writes they describe.
+// Read C until it is closed, which happens when the subscription or the storage is
+// closed, and call Close when done.
+type Subscription struct {
+	C <-chan models.Event
+
+	ch       chan models.Event
+	opts     SubscribeOptions
+	feed     *changeFeed
+	dropped  atomic.Int64
+	done     chan struct{} // Closed by Close, once the reader is finished
+	doneOnce sync.Once
+	chOnce   sync.Once
+}
+
+// Dropped returns how many events the subscription lost because its reader fell behind
+func (sub *Subscription) Dropped() int64 {
+	return sub.dropped.Load()
+}
+
+// Close stops the subscription and closes C; events already buffered are discarded.
+// The reader of a lossless subscription calls it once it has handled its last event,
+// so storage knows it may close.
+func (sub *Subscription) Close() {
+	sub.doneOnce.Do(func() { close(sub.done) })
+	sub.feed.remove(sub)
+	sub.closeChannel()
+}
+
+// wants reports whether the subscription receives events of type t
+func (sub *Subscription) wants(t models.EventType) bool {
+	return len(sub.opts.Types) == 0 || slices.Contains(sub.opts.Types, t)
+}
+
+// closeChannel closes C once; the caller holds the feed's lock or has removed sub from it
+func (sub *Subscription) closeChannel() {
+	sub.chOnce.Do(func() { close(sub.ch) })
+}
+
+// changeFeed fans published events out to subscriptions
+type changeFeed struct {
+	mu     sync.RWMutex // Read-held while sending, so channels are not closed mid-send
+	subs   []*Subscription
+	closed bool
+}
+
+// Subscribe returns a subscription to the events published from now on. A subscription
+// that falls more than its buffer behind loses events (see Dropped) unless it is
+// lossless, in which case writers wait for it; lossless readers should only do quick
+// work, such as queueing a job.
+func (s *Storage) Subscribe(opts SubscribeOptions) *Subscription {
+	if opts.Buffer <= 0 {
+		opts.Buffer = subscriptionBuffer
+	}
+	ch := make(chan models.Event, opts.Buffer)
+	sub := &Subscription{C: ch, ch: ch, opts: opts, feed: &s.changes, done: make(chan struct{})}
+
+	s.changes.mu.Lock()
+	defer s.changes.mu.Unlock()
+	if s.changes.closed {
+		sub.closeChannel()
+		sub.doneOnce.Do(func() { close(sub.done) })
+		return sub
+	}
+	s.changes.subs = append(s.changes.subs, sub)
+	return sub
+}
+
+// subscribed reports whether any subscription is open
+func (f *changeFeed) subscribed() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.subs) > 0
+}
+
+// send delivers event to every subscription that wants it
+func (f *changeFeed) send(event models.Event) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for _, sub := range f.subs {
+		if !sub.wants(event.Type) {
+			continue
+		}
+		if sub.opts.Lossless {
+			select {
+			case sub.ch <- event:
+			case <-sub.done: // The reader gave up; Close is waiting for the lock
+			}
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// remove takes sub out of the feed
+func (f *changeFeed) remove(sub *Subscription) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subs = slices.DeleteFunc(f.subs, func(s *Subscription) bool { return s == sub })
+}
+
+// close ends every subscription, then gives lossless readers until drainTimeout to
+// handle the events they still hold and call Close
+func (f *changeFeed) close() {
+	f.mu.Lock()
+	f.closed = true
+	subs := f.subs
+	f.subs = nil
+	for _, sub := range subs {
+		sub.closeChannel()
+	}
+	f.mu.Unlock()
+
+	deadline := time.After(drainTimeout)
+	for _, sub := range subs {
+		if !sub.opts.Lossless {
+			continue
+		}
+		select {
+		case <-sub.done:
+		case <-deadline:
+			log.Printf("[Storage] closing before a subscriber took %d remaining events", len(sub.ch))
+			return
+		}
+	}
+}
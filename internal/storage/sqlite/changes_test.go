@@ -0,0 +1,128 @@
+// ABOUTME: Tests for the change feed's channel subscriptions
+// ABOUTME: Verifies filtering, topic and profile events, dropping for slow readers, and closing
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// receive returns the next event on sub, failing the test if none arrives
+func receive(t *testing.T, sub *Subscription) models.Event {
+	t.Helper()
+	select {
+	case event, ok := <-sub.C:
+		if !ok {
+			t.Fatal("subscription closed, want an event")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event arrived")
+	}
+	return models.Event{}
+}
+
+func TestSubscribe_ReceivesChanges(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	all := store.Subscribe(SubscribeOptions{})
+	defer all.Close()
+	topics := store.Subscribe(SubscribeOptions{Types: []models.EventType{models.EventTopicUpdated, models.EventTopicDeleted}})
+	defer topics.Close()
+
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_1", UserMessage: "planning the garden"})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if event := receive(t, all); event.Type != models.EventTurnStored || event.BlockID != blockID {
+		t.Errorf("first event = %+v, want turn_stored in %s", event, blockID)
+	}
+
+	if err := store.UpdateBridgeBlockStatus(blockID, models.StatusPaused); err != nil {
+		t.Fatalf("UpdateBridgeBlockStatus() error = %v", err)
+	}
+	if err := store.DeleteBridgeBlock(blockID); err != nil {
+		t.Fatalf("DeleteBridgeBlock() error = %v", err)
+	}
+	for _, want := range []models.Event{
+		{Type: models.EventTopicUpdated, BlockID: blockID, Status: string(models.StatusPaused)},
+		{Type: models.EventTopicDeleted, BlockID: blockID},
+	} {
+		for _, sub := range []*Subscription{all, topics} {
+			if event := receive(t, sub); event.Type != want.Type || event.BlockID != want.BlockID || event.Status != want.Status {
+				t.Errorf("event = %+v, want %s of %s %q", event, want.Type, want.BlockID, want.Status)
+			}
+		}
+	}
+
+	// Profile changes say which persona changed
+	if err := store.SaveUserProfile(&models.UserProfile{Name: "Sam"}); err != nil {
+		t.Fatalf("SaveUserProfile() error = %v", err)
+	}
+	if err := store.SavePersona(&models.Persona{Name: "Work"}); err != nil {
+		t.Fatalf("SavePersona() error = %v", err)
+	}
+	for _, persona := range []string{"", "work"} {
+		if event := receive(t, all); event.Type != models.EventProfileUpdated || event.Persona != persona {
+			t.Errorf("event = %+v, want profile_updated for persona %q", event, persona)
+		}
+	}
+	select {
+	case event := <-topics.C:
+		t.Errorf("topic subscription received %s", event.Type)
+	default:
+	}
+}
+
+func TestSubscribe_DropsForSlowReaders(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	sub := store.Subscribe(SubscribeOptions{Buffer: 2})
+	for i := 0; i < 5; i++ {
+		if err := store.SaveUserProfile(&models.UserProfile{Name: "Sam"}); err != nil {
+			t.Fatalf("SaveUserProfile() error = %v", err)
+		}
+	}
+	if sub.Dropped() != 3 {
+		t.Errorf("Dropped() = %d, want 3 past the buffer of 2", sub.Dropped())
+	}
+
+	// Closing the subscription closes its channel and stops delivery
+	sub.Close()
+	sub.Close()
+	if err := store.SaveUserProfile(&models.UserProfile{Name: "Sam"}); err != nil {
+		t.Fatalf("SaveUserProfile() after Close() error = %v", err)
+	}
+	if store.publishing() {
+		t.Error("publishing() = true with no subscribers left")
+	}
+}
+
+func TestSubscribe_ClosedWithStorage(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	sub := store.Subscribe(SubscribeOptions{})
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, ok := <-sub.C; ok {
+		t.Error("subscription still open after the storage closed")
+	}
+	if late := store.Subscribe(SubscribeOptions{}); late != nil {
+		if _, ok := <-late.C; ok {
+			t.Error("subscription made after the storage closed is open")
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// ABOUTME: Storage of the text of embedded chunks, keyed by chunk ID like their embeddings
+// ABOUTME: Lets semantic search show the sentences that matched a query as snippets
+package sqlite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// saveChunks stores the text of chunks under blockID, each under the turn (or
+// attachment) its TurnID names, replacing any already stored with the same ID
+func saveChunks(ex execer, blockID string, chunks []models.Chunk, now time.Time) error {
+	for _, chunk := range chunks {
+		if _, err := ex.Exec(`
+			INSERT INTO chunks (id, turn_id, block_id, chunk_type, parent_id, content, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET
+				turn_id = excluded.turn_id,
+				block_id = excluded.block_id,
+				chunk_type = excluded.chunk_type,
+				parent_id = excluded.parent_id,
+				content = excluded.content
+		`, chunk.ChunkID, chunk.TurnID, nullString(blockID), string(chunk.ChunkType),
+			nullString(chunk.ParentChunkID), chunk.Content, now.UTC()); err != nil {
+			return fmt.Errorf("failed to save chunk %s: %w", chunk.ChunkID, err)
+		}
+	}
+	return nil
+}
+
+// deleteChunks removes the chunks of a turn or attachment
+func deleteChunks(ex execer, ownerID string) error {
+	if _, err := ex.Exec("DELETE FROM chunks WHERE turn_id = ?", ownerID); err != nil {
+		return fmt.Errorf("failed to delete chunks of %s: %w", ownerID, err)
+	}
+	return nil
+}
+
+// chunkContents returns the stored text of each chunk ID that has any. Chunks embedded
+// before their text was kept, or imported with bare embeddings, are missing.
+func (s *Storage) chunkContents(chunkIDs []string) (map[string]string, error) {
+	contents := make(map[string]string, len(chunkIDs))
+	if len(chunkIDs) == 0 {
+		return contents, nil
+	}
+	args := make([]interface{}, len(chunkIDs))
+	for i, id := range chunkIDs {
+		args[i] = id
+	}
+	rows, err := s.db.Query("SELECT id, content FROM chunks WHERE id IN ("+placeholders(len(chunkIDs))+")", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunk contents: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var id, content string
+		if err := rows.Scan(&id, &content); err != nil {
+			return nil, err
+		}
+		contents[id] = content
+	}
+	return contents, rows.Err()
+}
@@ -0,0 +1,154 @@
+// ABOUTME: Tests for stored chunk text and the snippets semantic search returns
+// ABOUTME: Verifies matched sentences are quoted, and that re-embedding and edits replace them
+package sqlite
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// gardenEmbedder points text mentioning the garden one way and everything else another
+type gardenEmbedder struct{}
+
+func (gardenEmbedder) GenerateEmbedding(text string) ([]float64, error) {
+	vector := make([]float64, ExpectedDimension)
+	if strings.Contains(strings.ToLower(text), "garden") {
+		vector[0] = 1
+	} else {
+		vector[1] = 1
+	}
+	return vector, nil
+}
+
+// sentenceChunker returns a turn chunk and one chunk per sentence
+type sentenceChunker struct{}
+
+func (sentenceChunker) ChunkTurn(text string, turnID string) ([]models.Chunk, error) {
+	turnChunk := models.Chunk{ChunkID: "chunk_" + turnID, ChunkType: models.ChunkTypeTurn, Content: text, TurnID: turnID}
+	chunks := []models.Chunk{turnChunk}
+	for i, sentence := range strings.SplitAfter(text, ". ") {
+		if strings.TrimSpace(sentence) == "" {
+			continue
+		}
+		chunks = append(chunks, models.Chunk{
+			ChunkID:       turnChunk.ChunkID + "_" + string(rune('a'+i)),
+			ChunkType:     models.ChunkTypeSentence,
+			Content:       sentence,
+			ParentChunkID: turnChunk.ChunkID,
+			TurnID:        turnID,
+		})
+	}
+	return chunks, nil
+}
+
+// countChunks returns how many chunks are stored for ownerID
+func countChunks(t *testing.T, store *Storage, ownerID string) int {
+	t.Helper()
+	var n int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM chunks WHERE turn_id = ?", ownerID).Scan(&n); err != nil {
+		t.Fatalf("count chunks: %v", err)
+	}
+	return n
+}
+
+func TestSearchMemory_Snippets(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	store.SetOpenAIClient(gardenEmbedder{})
+	store.SetChunkEngine(sentenceChunker{})
+
+	turn := &models.Turn{TurnID: "turn_1", UserMessage: "It rained all week. The garden needs compost before spring."}
+	if _, err := store.StoreTurn(turn); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if n := countChunks(t, store, turn.TurnID); n != 3 {
+		t.Fatalf("chunks = %d, want 3 (the turn and its two sentences)", n)
+	}
+
+	results, err := store.SearchMemory("garden", 5)
+	if err != nil {
+		t.Fatalf("SearchMemory() error = %v", err)
+	}
+	if len(results) != 1 || len(results[0].Snippets) == 0 {
+		t.Fatalf("SearchMemory() = %+v, want one result with snippets", results)
+	}
+	best := results[0].Snippets[0]
+	if best.Text != "The garden needs compost before spring." || best.TurnID != turn.TurnID || best.Score < 0.99 {
+		t.Errorf("best snippet = %+v, want the garden sentence rather than the whole turn", best)
+	}
+	for _, snippet := range results[0].Snippets {
+		if snippet.Text == turn.UserMessage {
+			t.Errorf("snippets = %+v, want the turn chunk replaced by its sentences", results[0].Snippets)
+		}
+	}
+
+	// Re-embedding swaps the chunks with the vectors
+	chunks := []models.Chunk{{ChunkID: "rechunk_1", ChunkType: models.ChunkTypeTurn, Content: "Garden notes, re-embedded.", TurnID: turn.TurnID}}
+	vector, _ := gardenEmbedder{}.GenerateEmbedding(chunks[0].Content)
+	if err := store.ReplaceTurnEmbeddings(turn.TurnID, results[0].BlockID, "test-model", chunks, [][]float64{vector}); err != nil {
+		t.Fatalf("ReplaceTurnEmbeddings() error = %v", err)
+	}
+	results, err = store.SearchMemory("garden", 5)
+	if err != nil {
+		t.Fatalf("SearchMemory() error = %v", err)
+	}
+	if len(results) != 1 || len(results[0].Snippets) != 1 || results[0].Snippets[0].Text != "Garden notes, re-embedded." {
+		t.Errorf("snippets after re-embedding = %+v, want the new chunk only", results)
+	}
+
+	// An edit drops the old text, which no longer says what the turn does
+	edited := "The garden is done for the year."
+	if _, _, err := store.UpdateTurn(turn.TurnID, models.TurnEdit{UserMessage: &edited}); err != nil {
+		t.Fatalf("UpdateTurn() error = %v", err)
+	}
+	if n := countChunks(t, store, turn.TurnID); n != 0 {
+		t.Errorf("chunks after edit = %d, want 0", n)
+	}
+	results, err = store.SearchMemory("garden", 5)
+	if err != nil {
+		t.Fatalf("SearchMemory() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Snippets != nil {
+		t.Errorf("SearchMemory() after edit = %+v, want the block without snippets", results)
+	}
+}
+
+func TestMatchSnippets_CapsAndTrims(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	var chunks []models.Chunk
+	var hits []models.VectorSearchResult
+	for i, text := range []string{strings.Repeat("long ", 100), "second", "third", "fourth"} {
+		id := "chunk_" + string(rune('a'+i))
+		chunks = append(chunks, models.Chunk{ChunkID: id, ChunkType: models.ChunkTypeSentence, Content: text, TurnID: "turn_1"})
+		hits = append(hits, models.VectorSearchResult{ChunkID: id, TurnID: "turn_1", SimilarityScore: 1 - float64(i)/10})
+	}
+	hits = append(hits, models.VectorSearchResult{ChunkID: "chunk_unsaved", TurnID: "turn_1", SimilarityScore: 0.1})
+	if err := saveChunks(store.db, "", chunks, store.db.now()); err != nil {
+		t.Fatalf("saveChunks() error = %v", err)
+	}
+
+	snippets, err := store.matchSnippets(map[string][]models.VectorSearchResult{"block_1": hits})
+	if err != nil {
+		t.Fatalf("matchSnippets() error = %v", err)
+	}
+	got := snippets["block_1"]
+	if len(got) != maxSnippets {
+		t.Fatalf("snippets = %+v, want %d", got, maxSnippets)
+	}
+	if text := []rune(got[0].Text); len(text) != snippetLen+3 || !strings.HasSuffix(got[0].Text, "...") {
+		t.Errorf("first snippet is %d runes, want it trimmed to %d", len(text), snippetLen+3)
+	}
+	if got[1].Text != "second" || got[2].Text != "third" {
+		t.Errorf("snippets = %+v, want the best matches in order", got)
+	}
+}
@@ -0,0 +1,303 @@
+// ABOUTME: Compaction of old archived blocks: turn bodies replaced by the block's summary and facts
+// ABOUTME: Keeps a gzipped copy of the bodies to restore from, or drops them, per policy
+package sqlite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// CompactedBlock is an archived block whose turn bodies were, or would be, compacted
+type CompactedBlock struct {
+	BlockID    string    `json:"block_id"`
+	TopicLabel string    `json:"topic_label"`
+	Turns      int       `json:"turns"`
+	Bytes      int64     `json:"bytes"` // Estimated bytes of turn bodies and their embeddings freed
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CompactionStats summarizes every compaction recorded in the database
+type CompactionStats struct {
+	Blocks          int        `json:"blocks"`
+	Bytes           int64      `json:"bytes"`
+	Restorable      int        `json:"restorable"` // Blocks compacted with a compressed copy of their turns
+	LastCompactedAt *time.Time `json:"last_compacted_at,omitempty"`
+}
+
+// compactionCandidates selects archived blocks with turns, last updated before the
+// cutoff, not compacted yet and not kept forever by a retention policy, oldest first
+const compactionCandidates = `
+	SELECT b.id, COALESCE(b.topic_label, ''), b.updated_at,
+		(SELECT COUNT(*) FROM turns t WHERE t.block_id = b.id),
+		COALESCE((SELECT SUM(length(COALESCE(t.user_message, '')) + length(COALESCE(t.ai_response, '')) + length(COALESCE(t.messages, '')))
+			FROM turns t WHERE t.block_id = b.id), 0)
+			+ COALESCE((SELECT SUM(length(e.vector)) FROM embeddings e
+				WHERE e.turn_id IN (SELECT t.id FROM turns t WHERE t.block_id = b.id)), 0)
+	FROM bridge_blocks b
+	WHERE b.status = ? AND b.updated_at < ?
+		AND EXISTS (SELECT 1 FROM turns t WHERE t.block_id = b.id)
+		AND NOT EXISTS (SELECT 1 FROM compactions c WHERE c.block_id = b.id)
+		AND NOT ` + blockKeptExpr + `
+	ORDER BY b.updated_at, b.id`
+
+// compactedTurn is the body of a turn as compaction found it; messages is the raw column
+type compactedTurn struct {
+	ID          string  `json:"id"`
+	UserMessage string  `json:"user_message"`
+	AIResponse  string  `json:"ai_response"`
+	Messages    *string `json:"messages,omitempty"`
+}
+
+// CompactionPlan returns the archived blocks CompactArchived would compact now, without
+// compacting them
+func (s *Storage) CompactionPlan(olderThan time.Duration) ([]CompactedBlock, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.compactionPlan(olderThan)
+}
+
+// compactionPlan lists the blocks archived and last updated more than olderThan ago
+// that are due for compaction
+func (s *Storage) compactionPlan(olderThan time.Duration) ([]CompactedBlock, error) {
+	cutoff := s.db.now().UTC().Add(-olderThan)
+	rows, err := s.db.Query(compactionCandidates, string(models.StatusArchived), cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blocks to compact: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var plan []CompactedBlock
+	for rows.Next() {
+		var block CompactedBlock
+		if err := rows.Scan(&block.BlockID, &block.TopicLabel, &block.UpdatedAt, &block.Turns, &block.Bytes); err != nil {
+			return nil, fmt.Errorf("failed to scan block to compact: %w", err)
+		}
+		plan = append(plan, block)
+	}
+	return plan, rows.Err()
+}
+
+// CompactArchived replaces the turn bodies of archived blocks last updated more than
+// olderThan ago with a digest of each block's summary and facts, held by its first turn;
+// its other turns are left empty. Turns keep their IDs, times, origins, annotations, and
+// attachments, and blocks keep their keywords and tags, so keyword search and hydration
+// still find them. The turns' embeddings and chunks are deleted. With CompactCompress the
+// original bodies are kept gzipped for RestoreCompacted; with CompactDrop they are gone.
+// Blocks kept forever by a retention policy are never compacted.
+func (s *Storage) CompactArchived(olderThan time.Duration, policy models.CompactPolicy) ([]CompactedBlock, error) {
+	if policy != models.CompactCompress && policy != models.CompactDrop {
+		return nil, fmt.Errorf("invalid compact policy %q: must be compress or drop", policy)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plan, err := s.compactionPlan(olderThan)
+	if err != nil || len(plan) == 0 {
+		return nil, err
+	}
+
+	// Read everything first: the transaction holds the only connection to write with
+	type compaction struct {
+		turns    []compactedTurn
+		digest   string
+		original []byte
+	}
+	work := make([]compaction, len(plan))
+	for i, block := range plan {
+		turns, err := s.turnBodies(block.BlockID)
+		if err != nil {
+			return nil, err
+		}
+		var summary string
+		if err := s.db.QueryRow("SELECT COALESCE(summary, '') FROM bridge_blocks WHERE id = ?", block.BlockID).Scan(&summary); err != nil {
+			return nil, fmt.Errorf("failed to get summary of block %s: %w", block.BlockID, err)
+		}
+		facts, err := s.facts.GetByBlock(block.BlockID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get facts of block %s: %w", block.BlockID, err)
+		}
+		work[i] = compaction{turns: turns, digest: models.CompactionDigest(block.TopicLabel, summary, len(turns), facts)}
+		if policy == models.CompactCompress {
+			if work[i].original, err = encodeCompacted(turns); err != nil {
+				return nil, fmt.Errorf("failed to compress turns of block %s: %w", block.BlockID, err)
+			}
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin compaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := s.db.now().UTC()
+	for i, block := range plan {
+		for j, turn := range work[i].turns {
+			body := ""
+			if j == 0 {
+				body = work[i].digest
+			}
+			if _, err := tx.Exec("UPDATE turns SET user_message = ?, ai_response = '', messages = NULL WHERE id = ?", body, turn.ID); err != nil {
+				return nil, fmt.Errorf("failed to compact turn %s: %w", turn.ID, err)
+			}
+			if _, err := tx.Exec("DELETE FROM embeddings WHERE turn_id = ?", turn.ID); err != nil {
+				return nil, fmt.Errorf("failed to delete embeddings of turn %s: %w", turn.ID, err)
+			}
+			if err := deleteChunks(tx, turn.ID); err != nil {
+				return nil, err
+			}
+		}
+		var original interface{}
+		if work[i].original != nil {
+			original = work[i].original
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO compactions (block_id, turns, bytes, policy, original, compacted_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, block.BlockID, block.Turns, block.Bytes, string(policy), original, now); err != nil {
+			return nil, fmt.Errorf("failed to record compaction of block %s: %w", block.BlockID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit compaction: %w", err)
+	}
+	for _, block := range plan {
+		if err := refreshCentroid(s.db, block.BlockID); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// RestoreCompacted puts back the turn bodies of a block compacted with a compressed
+// copy, returning how many turns were restored. Their embeddings are not restored;
+// 'memory reembed' regenerates them.
+func (s *Storage) RestoreCompacted(blockID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var original []byte
+	err := s.db.QueryRow("SELECT original FROM compactions WHERE block_id = ?", blockID).Scan(&original)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("block %s is not compacted", blockID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get compaction of block %s: %w", blockID, err)
+	}
+	if original == nil {
+		return 0, fmt.Errorf("block %s was compacted with the drop policy; its turns cannot be restored", blockID)
+	}
+	turns, err := decodeCompacted(original)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decompress turns of block %s: %w", blockID, err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	restored := 0
+	for _, turn := range turns {
+		result, err := tx.Exec("UPDATE turns SET user_message = ?, ai_response = ?, messages = ? WHERE id = ? AND block_id = ?",
+			turn.UserMessage, turn.AIResponse, turn.Messages, turn.ID, blockID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to restore turn %s: %w", turn.ID, err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			restored++
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM compactions WHERE block_id = ?", blockID); err != nil {
+		return 0, fmt.Errorf("failed to delete compaction of block %s: %w", blockID, err)
+	}
+	return restored, tx.Commit()
+}
+
+// CompactionStats counts the blocks compacted so far and the bytes they were estimated to free
+func (s *Storage) CompactionStats() (CompactionStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var stats CompactionStats
+	if err := s.db.QueryRow("SELECT COUNT(*), COALESCE(SUM(bytes), 0), COUNT(original) FROM compactions").Scan(&stats.Blocks, &stats.Bytes, &stats.Restorable); err != nil {
+		return CompactionStats{}, fmt.Errorf("failed to read compaction stats: %w", err)
+	}
+	var last time.Time
+	err := s.db.QueryRow("SELECT compacted_at FROM compactions ORDER BY compacted_at DESC LIMIT 1").Scan(&last)
+	if err != nil && err != sql.ErrNoRows {
+		return CompactionStats{}, fmt.Errorf("failed to read compaction stats: %w", err)
+	}
+	if err == nil {
+		stats.LastCompactedAt = &last
+	}
+	return stats, nil
+}
+
+// turnBodies returns the bodies of a block's turns, oldest first
+func (s *Storage) turnBodies(blockID string) ([]compactedTurn, error) {
+	rows, err := s.db.Query(`
+		SELECT id, COALESCE(user_message, ''), COALESCE(ai_response, ''), messages
+		FROM turns WHERE block_id = ?
+		ORDER BY created_at, id
+	`, blockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get turns of block %s: %w", blockID, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var turns []compactedTurn
+	for rows.Next() {
+		var (
+			turn     compactedTurn
+			messages sql.NullString
+		)
+		if err := rows.Scan(&turn.ID, &turn.UserMessage, &turn.AIResponse, &messages); err != nil {
+			return nil, err
+		}
+		if messages.Valid {
+			turn.Messages = &messages.String
+		}
+		turns = append(turns, turn)
+	}
+	return turns, rows.Err()
+}
+
+// encodeCompacted gzips turn bodies as JSON
+func encodeCompacted(turns []compactedTurn) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(turns); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeCompacted reads turn bodies written by encodeCompacted
+func decodeCompacted(data []byte) ([]compactedTurn, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	var turns []compactedTurn
+	if err := json.Unmarshal(raw, &turns); err != nil {
+		return nil, err
+	}
+	return turns, nil
+}
@@ -0,0 +1,131 @@
+// ABOUTME: Tests for compaction of old archived blocks
+// ABOUTME: Verifies digests replace turn bodies, embeddings go, and compressed copies restore
+
+package sqlite
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestCompactArchived(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	old := time.Now().Add(-90 * 24 * time.Hour)
+	for _, b := range []struct {
+		id      string
+		status  models.BridgeBlockStatus
+		updated time.Time
+	}{
+		{"block_old", models.StatusArchived, old},
+		{"block_kept", models.StatusArchived, old},
+		{"block_recent", models.StatusArchived, time.Now()},
+		{"block_paused", models.StatusPaused, old},
+	} {
+		if err := store.blocks.Save(&models.BridgeBlock{BlockID: b.id, DayID: "2025-01-01", TopicLabel: b.id, Summary: "Summary of " + b.id,
+			Status: b.status, CreatedAt: b.updated, UpdatedAt: b.updated}); err != nil {
+			t.Fatalf("Save block error = %v", err)
+		}
+		for i, text := range []string{"first " + strings.Repeat("x", 2000), "second " + strings.Repeat("y", 2000)} {
+			turn := &models.Turn{TurnID: b.id + "_turn_" + string(rune('a'+i)), Timestamp: b.updated.Add(time.Duration(i) * time.Minute),
+				UserMessage: text, AIResponse: "reply " + text}
+			if err := store.turns.Save(b.id, turn); err != nil {
+				t.Fatalf("Save turn error = %v", err)
+			}
+		}
+	}
+	if err := store.facts.Save(&models.Fact{FactID: "fact_ratio", BlockID: "block_old", Key: "compost_ratio", Value: "3:1", Confidence: 1}); err != nil {
+		t.Fatalf("Save fact error = %v", err)
+	}
+	if err := store.embeddings.Save("chunk_old", "block_old_turn_a", "block_old", make([]float64, ExpectedDimension)); err != nil {
+		t.Fatalf("Save embedding error = %v", err)
+	}
+	if err := store.SetBlockRetention("block_kept", models.KeepForever); err != nil {
+		t.Fatalf("SetBlockRetention() error = %v", err)
+	}
+
+	plan, err := store.CompactionPlan(30 * 24 * time.Hour)
+	if err != nil || len(plan) != 1 || plan[0].BlockID != "block_old" || plan[0].Turns != 2 || plan[0].Bytes < 8000 {
+		t.Fatalf("CompactionPlan() = %+v, %v; want only the old unkept archived block", plan, err)
+	}
+	if _, err := store.CompactArchived(30*24*time.Hour, "shred"); err == nil {
+		t.Error("CompactArchived() with an unknown policy succeeded, want an error")
+	}
+
+	compacted, err := store.CompactArchived(30*24*time.Hour, models.CompactCompress)
+	if err != nil || len(compacted) != 1 || compacted[0].BlockID != "block_old" {
+		t.Fatalf("CompactArchived() = %+v, %v; want the planned block", compacted, err)
+	}
+	block, err := store.GetBridgeBlock("block_old")
+	if err != nil || len(block.Turns) != 2 {
+		t.Fatalf("GetBridgeBlock() = %+v, %v; want both turns kept", block, err)
+	}
+	if want := "[Compacted 2 turns] Summary of block_old\n\nFacts:\n- compost_ratio: 3:1"; block.Turns[0].UserMessage != want || block.Turns[0].AIResponse != "" {
+		t.Errorf("first turn = %q / %q, want the digest", block.Turns[0].UserMessage, block.Turns[0].AIResponse)
+	}
+	if block.Turns[1].UserMessage != "" || block.Turns[1].AIResponse != "" {
+		t.Errorf("second turn = %+v, want it emptied", block.Turns[1])
+	}
+	if n := countEmbeddings(t, store, "block_old_turn_a"); n != 0 {
+		t.Errorf("embeddings after compaction = %d, want 0", n)
+	}
+	if plan, err := store.CompactionPlan(30 * 24 * time.Hour); err != nil || len(plan) != 0 {
+		t.Errorf("CompactionPlan() after compacting = %+v, %v; want nothing", plan, err)
+	}
+	stats, err := store.CompactionStats()
+	if err != nil || stats.Blocks != 1 || stats.Restorable != 1 || stats.Bytes != compacted[0].Bytes || stats.LastCompactedAt == nil {
+		t.Errorf("CompactionStats() = %+v, %v; want the compaction recorded", stats, err)
+	}
+
+	restored, err := store.RestoreCompacted("block_old")
+	if err != nil || restored != 2 {
+		t.Fatalf("RestoreCompacted() = %d, %v; want 2 turns", restored, err)
+	}
+	block, _ = store.GetBridgeBlock("block_old")
+	if !strings.HasPrefix(block.Turns[0].UserMessage, "first ") || !strings.HasPrefix(block.Turns[1].AIResponse, "reply second ") {
+		t.Errorf("restored turns = %+v, want the original bodies", block.Turns)
+	}
+	if _, err := store.RestoreCompacted("block_old"); err == nil || !strings.Contains(err.Error(), "not compacted") {
+		t.Errorf("RestoreCompacted() twice error = %v, want not compacted", err)
+	}
+
+	// Dropped bodies are gone for good
+	if compacted, err := store.CompactArchived(30*24*time.Hour, models.CompactDrop); err != nil || len(compacted) != 1 {
+		t.Fatalf("CompactArchived(drop) = %+v, %v; want the restored block compacted again", compacted, err)
+	}
+	if _, err := store.RestoreCompacted("block_old"); err == nil || !strings.Contains(err.Error(), "drop policy") {
+		t.Errorf("RestoreCompacted() after drop error = %v, want it refused", err)
+	}
+	if stats, _ := store.CompactionStats(); stats.Blocks != 1 || stats.Restorable != 0 {
+		t.Errorf("CompactionStats() after drop = %+v, want one block, not restorable", stats)
+	}
+}
+
+func TestCompactedTurns_RoundTrip(t *testing.T) {
+	messages := `[{"role":"user","content":"hi"}]`
+	turns := []compactedTurn{
+		{ID: "turn_a", UserMessage: "hello", AIResponse: "hi"},
+		{ID: "turn_b", Messages: &messages},
+	}
+	data, err := encodeCompacted(turns)
+	if err != nil {
+		t.Fatalf("encodeCompacted() error = %v", err)
+	}
+	got, err := decodeCompacted(data)
+	if err != nil {
+		t.Fatalf("decodeCompacted() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != turns[0] || got[1].Messages == nil || *got[1].Messages != messages {
+		t.Errorf("decodeCompacted() = %+v, want %+v", got, turns)
+	}
+	if _, err := decodeCompacted([]byte("not gzip")); err == nil {
+		t.Error("decodeCompacted() of garbage succeeded, want an error")
+	}
+}
@@ -0,0 +1,40 @@
+// ABOUTME: Consistency checks between blocks and the turns they hold
+// ABOUTME: Used by memory stress to confirm concurrent writers left the database consistent
+package sqlite
+
+import "fmt"
+
+// TurnCountMismatch is a block whose recorded turn count differs from the turns it holds
+type TurnCountMismatch struct {
+	BlockID  string `json:"block_id"`
+	Recorded int    `json:"recorded"`
+	Actual   int    `json:"actual"`
+}
+
+// TurnCountMismatches returns every block whose turn_count is not the number of its
+// turns, as a write lost to a concurrent writer would leave it
+func (s *Storage) TurnCountMismatches() ([]TurnCountMismatch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rows, err := s.db.Query(`
+		SELECT b.id, COALESCE(b.turn_count, 0), COUNT(t.id)
+		FROM bridge_blocks b
+		LEFT JOIN turns t ON t.block_id = b.id
+		GROUP BY b.id
+		HAVING COALESCE(b.turn_count, 0) != COUNT(t.id)
+		ORDER BY b.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check turn counts: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	mismatches := []TurnCountMismatch{}
+	for rows.Next() {
+		var m TurnCountMismatch
+		if err := rows.Scan(&m.BlockID, &m.Recorded, &m.Actual); err != nil {
+			return nil, fmt.Errorf("failed to scan turn counts: %w", err)
+		}
+		mismatches = append(mismatches, m)
+	}
+	return mismatches, rows.Err()
+}
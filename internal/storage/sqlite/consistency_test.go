@@ -0,0 +1,133 @@
+// ABOUTME: Tests for block and turn consistency checks
+// ABOUTME: Verifies mismatched turn counts are found, appends from two handles are not lost, and new topics leave one active
+
+package sqlite
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestTurnCountMismatches(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_1", UserMessage: "hello"})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if mismatches, err := store.TurnCountMismatches(); err != nil || len(mismatches) != 0 {
+		t.Fatalf("TurnCountMismatches() = %v, %v, want none", mismatches, err)
+	}
+
+	if _, err := store.db.Exec(`UPDATE bridge_blocks SET turn_count = 3 WHERE id = ?`, blockID); err != nil {
+		t.Fatalf("UPDATE error = %v", err)
+	}
+	mismatches, err := store.TurnCountMismatches()
+	if err != nil || len(mismatches) != 1 || mismatches[0] != (TurnCountMismatch{BlockID: blockID, Recorded: 3, Actual: 1}) {
+		t.Errorf("TurnCountMismatches() = %+v, %v, want %s recording 3 of 1", mismatches, err, blockID)
+	}
+}
+
+func TestAppendTurnToBlock_TwoHandles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.db")
+	first, err := NewStorageWithPath(path)
+	if err != nil {
+		t.Fatalf("NewStorageWithPath() error = %v", err)
+	}
+	defer func() { _ = first.Close() }()
+	second, err := NewStorageWithPath(path)
+	if err != nil {
+		t.Fatalf("NewStorageWithPath() error = %v", err)
+	}
+	defer func() { _ = second.Close() }()
+
+	blockID, err := first.StoreTurn(&models.Turn{TurnID: "turn_0", UserMessage: "shared topic", Keywords: []string{"shared"}})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	// Each handle stands in for a process appending to the same block
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for h, store := range []*Storage{first, second} {
+		wg.Add(1)
+		go func(h int, store *Storage) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				turn := &models.Turn{TurnID: fmt.Sprintf("turn_%d_%d", h, i), UserMessage: "more", Keywords: []string{fmt.Sprintf("kw%d_%d", h, i)}}
+				if err := store.AppendTurnToBlock(blockID, turn); err != nil {
+					errs <- err
+				}
+			}
+		}(h, store)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("AppendTurnToBlock() error = %v", err)
+	}
+
+	if mismatches, err := first.TurnCountMismatches(); err != nil || len(mismatches) != 0 {
+		t.Errorf("TurnCountMismatches() = %+v, %v, want none after concurrent appends", mismatches, err)
+	}
+	block, err := second.blocks.Get(blockID)
+	if err != nil || block == nil {
+		t.Fatalf("Get() = %v, %v", block, err)
+	}
+	if block.TurnCount != 41 || len(block.Keywords) != 41 || block.Keywords[0] != "shared" {
+		t.Errorf("block has %d turns and %d keywords starting %v, want 41 of each starting with shared", block.TurnCount, len(block.Keywords), block.Keywords[:1])
+	}
+}
+
+func TestStoreTurn_HandlesLeaveOneActiveBlock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.db")
+	var stores []*Storage
+	for i := 0; i < 8; i++ {
+		store, err := NewStorageWithPath(path)
+		if err != nil {
+			t.Fatalf("NewStorageWithPath() error = %v", err)
+		}
+		defer func() { _ = store.Close() }()
+		stores = append(stores, store)
+	}
+
+	// Each round every handle, standing in for a process, starts a new topic at the
+	// same moment; one of them must end up the only active block
+	for round := 0; round < 10; round++ {
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		errs := make(chan error, len(stores))
+		for h, store := range stores {
+			wg.Add(1)
+			go func(h int, store *Storage) {
+				defer wg.Done()
+				<-start
+				if _, err := store.StoreTurn(&models.Turn{TurnID: fmt.Sprintf("turn_%d_%d", round, h), UserMessage: "new topic"}); err != nil {
+					errs <- err
+				}
+			}(h, store)
+		}
+		close(start)
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Fatalf("StoreTurn() error = %v", err)
+		}
+
+		var active int
+		if err := stores[0].db.QueryRow("SELECT COUNT(*) FROM bridge_blocks WHERE status = ?", string(models.StatusActive)).Scan(&active); err != nil {
+			t.Fatalf("count active blocks error = %v", err)
+		}
+		if active != 1 {
+			t.Fatalf("round %d: %d blocks are active after concurrent new topics, want 1", round, active)
+		}
+	}
+}
@@ -3,18 +3,29 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
+	sqldriver "database/sql/driver"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
-	_ "modernc.org/sqlite"
+	driver "modernc.org/sqlite"
+
+	"github.com/harper/remember-standalone/internal/models"
 )
 
 // DB wraps a SQLite database connection
 type DB struct {
-	conn *sql.DB
-	path string
+	conn     *sql.DB
+	path     string
+	readOnly bool
+	writes   writeTracker
+	slow     slowLog
+	secrets  secretKey
+	clock    models.Clock // Stamps stored rows; nil = models.Now
 }
 
 // DefaultDataDir returns the default data directory for memory storage following XDG spec.
@@ -44,7 +55,7 @@ func Open(path string) (*DB, error) {
 	}
 
 	// Open database with WAL mode for better concurrency
-	conn, err := sql.Open("sqlite", path+"?_pragma=journal_mode(WAL)&_pragma=foreign_keys(ON)")
+	conn, err := sql.Open("sqlite", path+"?_pragma=journal_mode(WAL)&_pragma=foreign_keys(ON)&"+dsnParams)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -60,11 +71,62 @@ func Open(path string) (*DB, error) {
 		path: path,
 	}
 
-	// Initialize schema
-	if err := db.initSchema(); err != nil {
+	// Initialize schema, one process at a time
+	unlock, err := lockSchema(path)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	err = db.initSchema()
+	unlock()
+	if err != nil {
 		_ = conn.Close()
 		return nil, fmt.Errorf("failed to initialize schema: %w", err)
 	}
+	if err := db.openWriter(); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to open write connection: %w", err)
+	}
+
+	return db, nil
+}
+
+// OpenReadOnly opens an existing database with query_only set, so every write fails.
+// It neither creates the file nor migrates it; the schema must already be current.
+func OpenReadOnly(path string) (*DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open database read-only: %w", err)
+	}
+
+	conn, err := sql.Open("sqlite", path+"?_pragma=query_only(ON)&_pragma=foreign_keys(ON)&"+dsnParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := conn.Ping(); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	db := &DB{
+		conn:     conn,
+		path:     path,
+		readOnly: true,
+	}
+
+	var applied int
+	if err := conn.QueryRow("PRAGMA user_version").Scan(&applied); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if applied < len(migrations) {
+		_ = conn.Close()
+		return nil, fmt.Errorf("database schema is out of date; open it once without read-only mode to migrate")
+	}
+	// Nothing is written here, but the connection's data_version shows other processes' writes
+	if err := db.openWriter(); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to open write connection: %w", err)
+	}
 
 	return db, nil
 }
@@ -76,6 +138,9 @@ func OpenInMemory() (*DB, error) {
 		return nil, fmt.Errorf("failed to open in-memory database: %w", err)
 	}
 
+	// Every new connection to :memory: is a separate, empty database
+	conn.SetMaxOpenConns(1)
+
 	db := &DB{
 		conn: conn,
 		path: ":memory:",
@@ -89,20 +154,98 @@ func OpenInMemory() (*DB, error) {
 	return db, nil
 }
 
-// initSchema creates all database tables and indexes
+// initSchema creates all database tables and indexes, then applies migrations
 func (db *DB) initSchema() error {
-	_, err := db.conn.Exec(Schema)
-	return err
+	if _, err := db.conn.Exec(Schema); err != nil {
+		return err
+	}
+	return db.migrate()
+}
+
+// migrate applies any migrations newer than the database's user_version. Each
+// migration re-reads the version inside its transaction, so one another process
+// applied in the meantime is skipped rather than applied twice.
+func (db *DB) migrate() error {
+	var applied int
+	if err := db.conn.QueryRow("PRAGMA user_version").Scan(&applied); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := applied; i < len(migrations); i++ {
+		tx, err := db.conn.Begin()
+		if err != nil {
+			return busy(err)
+		}
+		if err := tx.QueryRow("PRAGMA user_version").Scan(&applied); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to read schema version: %w", err)
+		}
+		if applied > i {
+			_ = tx.Rollback()
+			continue
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("migration %d failed: %w", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SchemaVersion returns the schema version recorded in the database
+func (db *DB) SchemaVersion() (int, error) {
+	var applied int
+	if err := db.conn.QueryRow("PRAGMA user_version").Scan(&applied); err != nil {
+		return 0, err
+	}
+	return applied + 1, nil
+}
+
+// Begin starts a transaction. It holds the write lock until Commit or Rollback.
+func (db *DB) Begin() (*Tx, error) {
+	db.writes.mu.Lock()
+	var (
+		tx  *sql.Tx
+		err error
+	)
+	if db.writes.writer != nil {
+		tx, err = db.writes.writer.BeginTx(context.Background(), nil)
+	} else {
+		tx, err = db.conn.Begin()
+	}
+	if err != nil {
+		db.writes.mu.Unlock()
+		return nil, busy(err)
+	}
+	return &Tx{Tx: tx, db: db}, nil
 }
 
 // Close closes the database connection
 func (db *DB) Close() error {
+	if db.writes.writer != nil {
+		_ = db.writes.writer.Close()
+		db.writes.writer = nil
+	}
 	if db.conn != nil {
 		return db.conn.Close()
 	}
 	return nil
 }
 
+// Ping checks that the database file can still be read
+func (db *DB) Ping(ctx context.Context) error {
+	var tables int
+	return db.conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master").Scan(&tables)
+}
+
 // Conn returns the underlying sql.DB connection for advanced usage
 func (db *DB) Conn() *sql.DB {
 	return db.conn
@@ -113,17 +256,113 @@ func (db *DB) Path() string {
 	return db.path
 }
 
-// Exec executes a query without returning rows
+// now returns the time on the database's clock, for timestamps written to it
+func (db *DB) now() time.Time {
+	if db.clock == nil {
+		return models.Now()
+	}
+	return db.clock.Now()
+}
+
+// ReadOnly reports whether the database was opened with OpenReadOnly
+func (db *DB) ReadOnly() bool {
+	return db.readOnly
+}
+
+// Size returns the on-disk size of the database in bytes, including the WAL file
+func (db *DB) Size() int64 {
+	if db.path == ":memory:" {
+		return 0
+	}
+	var total int64
+	for _, p := range []string{db.path, db.path + "-wal"} {
+		if info, err := os.Stat(p); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+// Exec executes a query without returning rows, recording the table it writes
 func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
-	return db.conn.Exec(query, args...)
+	db.writes.mu.Lock()
+	defer db.writes.mu.Unlock()
+	defer db.observe(query, time.Now())
+	var (
+		result sql.Result
+		err    error
+	)
+	if db.writes.writer != nil {
+		result, err = db.writes.writer.ExecContext(context.Background(), query, utcArgs(args)...)
+	} else {
+		result, err = db.conn.Exec(query, utcArgs(args)...)
+	}
+	db.bump(writtenTable(query))
+	return result, busy(err)
 }
 
-// Query executes a query that returns rows
-func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	return db.conn.Query(query, args...)
+// utcArgs converts time arguments to UTC so stored timestamps share one offset and sort correctly
+func utcArgs(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		if t, ok := arg.(time.Time); ok {
+			arg = t.UTC()
+		}
+		converted[i] = arg
+	}
+	return converted
+}
+
+// offsetTimeLayouts are the driver's time formats that carry a UTC offset
+var offsetTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999 -0700 MST", // time.Time.String, the driver's default
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+}
+
+// utcTimestamp rewrites a stored timestamp with a UTC offset as the driver writes
+// the same instant in UTC; anything else is returned unchanged
+func utcTimestamp(s string) string {
+	value := s
+	if i := strings.Index(value, " m="); i >= 0 {
+		value = value[:i] // Monotonic clock reading from time.Time.String
+	}
+	for _, layout := range offsetTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().String()
+		}
+	}
+	return s
+}
+
+func init() {
+	if err := driver.RegisterDeterministicScalarFunction("utc_time", 1, func(_ *driver.FunctionContext, args []sqldriver.Value) (sqldriver.Value, error) {
+		switch v := args[0].(type) {
+		case string:
+			return utcTimestamp(v), nil
+		case []byte:
+			return utcTimestamp(string(v)), nil
+		default:
+			return v, nil
+		}
+	}); err != nil {
+		panic(fmt.Sprintf("failed to register utc_time: %v", err))
+	}
+}
+
+// Query executes a query that returns rows; it is timed until the rows are closed
+func (db *DB) Query(query string, args ...interface{}) (*Rows, error) {
+	start := time.Now()
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		db.observe(query, start)
+		return nil, err
+	}
+	return &Rows{Rows: rows, db: db, query: query, start: start}, nil
 }
 
 // QueryRow executes a query that returns at most one row
 func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	defer db.observe(query, time.Now())
 	return db.conn.QueryRow(query, args...)
 }
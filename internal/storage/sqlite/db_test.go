@@ -141,3 +141,161 @@ func TestIndexesExist(t *testing.T) {
 		}
 	}
 }
+
+func TestMigrationsApplied(t *testing.T) {
+	if SchemaVersion != len(migrations)+1 {
+		t.Fatalf("SchemaVersion = %d, want %d (base schema + migrations)", SchemaVersion, len(migrations)+1)
+	}
+
+	db, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("OpenInMemory() error = %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion() error = %v", err)
+	}
+	if version != SchemaVersion {
+		t.Errorf("SchemaVersion() = %d, want %d", version, SchemaVersion)
+	}
+
+	// Migration 1 adds embeddings.model
+	if _, err := db.Exec("SELECT model FROM embeddings LIMIT 1"); err != nil {
+		t.Errorf("embeddings.model column missing: %v", err)
+	}
+}
+
+func TestMigrationsUpgradeExistingDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+
+	// Simulate a database created before any migrations existed
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, err := db.Exec(`DROP TABLE embeddings; DROP TABLE block_tags; DROP TABLE fact_tags;
+		DROP TABLE facts; DROP TABLE user_profile; DROP TABLE turns; PRAGMA user_version = 0;`); err != nil {
+		t.Fatalf("reset error = %v", err)
+	}
+	// Blocks kept their keywords in a JSON column before the keyword index
+	if _, err := db.Exec(`DROP TABLE block_keywords; ALTER TABLE bridge_blocks ADD COLUMN keywords TEXT;`); err != nil {
+		t.Fatalf("restore legacy keywords column error = %v", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE bridge_blocks DROP COLUMN exclude_from_recall`); err != nil {
+		t.Fatalf("drop exclude_from_recall error = %v", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE bridge_blocks DROP COLUMN retention`); err != nil {
+		t.Fatalf("drop retention error = %v", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profile_history DROP COLUMN turn_ids`); err != nil {
+		t.Fatalf("drop turn_ids error = %v", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE bridge_blocks DROP COLUMN centroid; ALTER TABLE bridge_blocks DROP COLUMN centroid_count;
+		ALTER TABLE bridge_blocks DROP COLUMN centroid_model;`); err != nil {
+		t.Fatalf("drop centroid columns error = %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE embeddings (
+		id TEXT PRIMARY KEY, chunk_id TEXT NOT NULL, turn_id TEXT, block_id TEXT,
+		vector BLOB NOT NULL, created_at DATETIME NOT NULL)`); err != nil {
+		t.Fatalf("create legacy table error = %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE facts (
+		id TEXT PRIMARY KEY, block_id TEXT REFERENCES bridge_blocks(id) ON DELETE SET NULL,
+		turn_id TEXT, key TEXT NOT NULL, value TEXT NOT NULL, confidence REAL DEFAULT 1.0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatalf("create legacy facts table error = %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE user_profile (
+		id INTEGER PRIMARY KEY CHECK (id = 1), name TEXT, preferences TEXT,
+		topics_of_interest TEXT, updated_at DATETIME DEFAULT CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatalf("create legacy user_profile table error = %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE turns (
+		id TEXT PRIMARY KEY, block_id TEXT NOT NULL REFERENCES bridge_blocks(id) ON DELETE CASCADE,
+		user_message TEXT, ai_response TEXT, keywords TEXT, topics TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatalf("create legacy turns table error = %v", err)
+	}
+	_ = db.Close()
+
+	// Reopening applies the pending migrations exactly once
+	for i := 0; i < 2; i++ {
+		db, err = Open(dbPath)
+		if err != nil {
+			t.Fatalf("Open() #%d error = %v", i+1, err)
+		}
+		version, _ := db.SchemaVersion()
+		if version != SchemaVersion {
+			t.Errorf("SchemaVersion() = %d, want %d", version, SchemaVersion)
+		}
+		_ = db.Close()
+	}
+}
+
+func TestTimestampsMigratedToUTC(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	// Timestamps as written in local time before they were stored in UTC.
+	// As text, block_a sorts after block_b although it was updated half an hour earlier.
+	if _, err := db.Exec(`INSERT INTO bridge_blocks (id, day_id, topic_label, created_at, updated_at) VALUES
+			('block_a', '2026-03-01', 'a', '2026-03-01 09:00:00 +0200 EET', '2026-03-01 09:30:00.25 +0200 EET m=+12.500000001'),
+			('block_b', '2026-03-01', 'b', '2026-03-01 08:00:00', '2026-03-01 08:00:00 +0000 UTC');
+		INSERT INTO turns (id, block_id, user_message, created_at, edited_at) VALUES
+			('turn_a', 'block_a', 'hello', '2026-03-01 02:15:00 -0500 EST', NULL);
+		PRAGMA user_version = 35;`); err != nil {
+		t.Fatalf("seeding offset timestamps error = %v", err)
+	}
+	_ = db.Close()
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open() after upgrade error = %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`SELECT created_at || '' FROM bridge_blocks WHERE id = 'block_a'`, "2026-03-01 07:00:00 +0000 UTC"},
+		{`SELECT updated_at || '' FROM bridge_blocks WHERE id = 'block_a'`, "2026-03-01 07:30:00.25 +0000 UTC"},
+		{`SELECT created_at || '' FROM bridge_blocks WHERE id = 'block_b'`, "2026-03-01 08:00:00"}, // CURRENT_TIMESTAMP is already UTC
+		{`SELECT created_at || '' FROM turns WHERE id = 'turn_a'`, "2026-03-01 07:15:00 +0000 UTC"},
+		{`SELECT COALESCE(edited_at, 'null') FROM turns WHERE id = 'turn_a'`, "null"},
+		{`SELECT id FROM bridge_blocks ORDER BY updated_at DESC LIMIT 1`, "block_b"},
+	}
+	for _, tt := range tests {
+		var got string
+		if err := db.QueryRow(tt.query).Scan(&got); err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestUTCTimestamp(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"2026-03-01 09:00:00.5 +0200 EET", "2026-03-01 07:00:00.5 +0000 UTC"},
+		{"2026-03-01 09:00:00 +0200 EET m=+1.000000001", "2026-03-01 07:00:00 +0000 UTC"},
+		{"2026-03-01T09:00:00-05:00", "2026-03-01 14:00:00 +0000 UTC"},
+		{"2026-03-01 07:00:00 +0000 UTC", "2026-03-01 07:00:00 +0000 UTC"},
+		{"2026-03-01 07:00:00", "2026-03-01 07:00:00"},
+		{"2026-03-01T07:00:00Z", "2026-03-01T07:00:00Z"},
+		{"not a time", "not a time"},
+	}
+	for _, tt := range tests {
+		if got := utcTimestamp(tt.in); got != tt.want {
+			t.Errorf("utcTimestamp(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// ABOUTME: Comparison of two memory exports
+// ABOUTME: Reports added, removed, and changed blocks, turns, facts, and profile fields
+package sqlite
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DiffChange describes an item present in both exports whose fields differ
+type DiffChange struct {
+	ID     string   `json:"id"`
+	Fields []string `json:"fields"`
+}
+
+// DiffSet lists the IDs added, removed, and changed for one kind of item
+type DiffSet struct {
+	Added   []string     `json:"added"`
+	Removed []string     `json:"removed"`
+	Changed []DiffChange `json:"changed"`
+}
+
+// Empty reports whether the set contains no differences
+func (d DiffSet) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// ExportDiff is the difference between two exports
+type ExportDiff struct {
+	Blocks        DiffSet  `json:"blocks"`
+	Turns         DiffSet  `json:"turns"`
+	Facts         DiffSet  `json:"facts"`
+	ProfileFields []string `json:"profile_fields"`
+}
+
+// Empty reports whether the exports hold the same data
+func (d *ExportDiff) Empty() bool {
+	return d.Blocks.Empty() && d.Turns.Empty() && d.Facts.Empty() && len(d.ProfileFields) == 0
+}
+
+// LoadExport reads an export written by ExportToYAML (JSON exports also parse),
+// refusing one whose manifest no longer matches its contents. Exports written
+// before manifests existed load unchecked.
+func LoadExport(path string) (*ExportData, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+
+	var data ExportData
+	if err := yaml.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse export %s: %w", path, err)
+	}
+	if data.Manifest != nil {
+		if err := VerifyExport(&data); err != nil {
+			return nil, fmt.Errorf("refusing export %s: %w", path, err)
+		}
+	}
+	return &data, nil
+}
+
+// DiffExports compares export a (before) with export b (after)
+func DiffExports(a, b *ExportData) *ExportDiff {
+	diff := &ExportDiff{
+		ProfileFields: diffProfiles(a.Profile, b.Profile),
+	}
+
+	blocksA, turnsA := indexBlocks(a)
+	blocksB, turnsB := indexBlocks(b)
+
+	diff.Blocks = diffMaps(blocksA, blocksB, blockChanges)
+	diff.Turns = diffMaps(turnsA, turnsB, func(x, y placedTurn) []string {
+		var fields []string
+		if x.BlockID != y.BlockID {
+			fields = append(fields, "block_id")
+		}
+		return append(fields, turnChanges(x.ExportTurn, y.ExportTurn)...)
+	})
+
+	factsA := make(map[string]ExportFact, len(a.Facts))
+	for _, f := range a.Facts {
+		factsA[f.FactID] = f
+	}
+	factsB := make(map[string]ExportFact, len(b.Facts))
+	for _, f := range b.Facts {
+		factsB[f.FactID] = f
+	}
+	diff.Facts = diffMaps(factsA, factsB, factChanges)
+
+	return diff
+}
+
+// blockChanges lists the fields of a block that differ between x and y, other than its turns
+func blockChanges(x, y ExportBlock) []string {
+	var fields []string
+	if x.TopicLabel != y.TopicLabel {
+		fields = append(fields, "topic_label")
+	}
+	if x.Status != y.Status {
+		fields = append(fields, "status")
+	}
+	if !slices.Equal(x.Keywords, y.Keywords) {
+		fields = append(fields, "keywords")
+	}
+	if x.Summary != y.Summary {
+		fields = append(fields, "summary")
+	}
+	if !slices.Equal(x.Tags, y.Tags) {
+		fields = append(fields, "tags")
+	}
+	if len(x.Turns) != len(y.Turns) {
+		fields = append(fields, "turn_count")
+	}
+	return fields
+}
+
+// turnChanges lists the fields of a turn that differ between x and y
+func turnChanges(x, y ExportTurn) []string {
+	var fields []string
+	if x.UserMessage != y.UserMessage {
+		fields = append(fields, "user_message")
+	}
+	if x.AIResponse != y.AIResponse {
+		fields = append(fields, "ai_response")
+	}
+	if x.Timestamp != y.Timestamp {
+		fields = append(fields, "timestamp")
+	}
+	if x.Affect != y.Affect {
+		fields = append(fields, "affect")
+	}
+	if !reflect.DeepEqual(x.Messages, y.Messages) {
+		fields = append(fields, "messages")
+	}
+	if !reflect.DeepEqual(x.Origin, y.Origin) {
+		fields = append(fields, "origin")
+	}
+	return fields
+}
+
+// factChanges lists the fields of a fact that differ between x and y
+func factChanges(x, y ExportFact) []string {
+	var fields []string
+	if x.Key != y.Key {
+		fields = append(fields, "key")
+	}
+	if x.Value != y.Value {
+		fields = append(fields, "value")
+	}
+	if x.ValueType != y.ValueType {
+		fields = append(fields, "value_type")
+	}
+	if x.Confidence != y.Confidence {
+		fields = append(fields, "confidence")
+	}
+	if !slices.Equal(x.Tags, y.Tags) {
+		fields = append(fields, "tags")
+	}
+	return fields
+}
+
+// placedTurn is an exported turn together with the block that holds it
+type placedTurn struct {
+	ExportTurn
+	BlockID string
+}
+
+// indexBlocks maps blocks and turns in an export by ID
+func indexBlocks(data *ExportData) (map[string]ExportBlock, map[string]placedTurn) {
+	blocks := make(map[string]ExportBlock, len(data.Blocks))
+	turns := make(map[string]placedTurn)
+	for _, block := range data.Blocks {
+		blocks[block.BlockID] = block
+		for _, turn := range block.Turns {
+			turns[turn.TurnID] = placedTurn{ExportTurn: turn, BlockID: block.BlockID}
+		}
+	}
+	return blocks, turns
+}
+
+// diffMaps compares two ID-keyed maps, using changed to list differing fields
+func diffMaps[T any](a, b map[string]T, changed func(x, y T) []string) DiffSet {
+	set := DiffSet{Added: []string{}, Removed: []string{}, Changed: []DiffChange{}}
+
+	for id, x := range a {
+		y, ok := b[id]
+		if !ok {
+			set.Removed = append(set.Removed, id)
+			continue
+		}
+		if fields := changed(x, y); len(fields) > 0 {
+			set.Changed = append(set.Changed, DiffChange{ID: id, Fields: fields})
+		}
+	}
+	for id := range b {
+		if _, ok := a[id]; !ok {
+			set.Added = append(set.Added, id)
+		}
+	}
+
+	sort.Strings(set.Added)
+	sort.Strings(set.Removed)
+	sort.Slice(set.Changed, func(i, j int) bool { return set.Changed[i].ID < set.Changed[j].ID })
+	return set
+}
+
+// diffProfiles lists the profile fields that differ
+func diffProfiles(a, b *ExportProfile) []string {
+	if a == nil {
+		a = &ExportProfile{}
+	}
+	if b == nil {
+		b = &ExportProfile{}
+	}
+
+	fields := []string{}
+	if a.Name != b.Name {
+		fields = append(fields, "name")
+	}
+	if !slices.Equal(a.Preferences, b.Preferences) {
+		fields = append(fields, "preferences")
+	}
+	if !slices.Equal(a.TopicsOfInterest, b.TopicsOfInterest) {
+		fields = append(fields, "topics_of_interest")
+	}
+	if !slices.Equal(a.Constraints, b.Constraints) {
+		fields = append(fields, "constraints")
+	}
+	if !slices.EqualFunc(a.Personas, b.Personas, func(x, y ExportPersona) bool {
+		return x.Name == y.Name && slices.Equal(x.Preferences, y.Preferences) && slices.Equal(x.Constraints, y.Constraints)
+	}) {
+		fields = append(fields, "personas")
+	}
+	return fields
+}
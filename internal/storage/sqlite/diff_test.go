@@ -0,0 +1,123 @@
+// ABOUTME: Tests for export comparison
+// ABOUTME: Verifies added, removed, and changed detection and export loading
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestDiffExports(t *testing.T) {
+	before := &ExportData{
+		Profile: &ExportProfile{Name: "Alice", Preferences: []string{"tea"}},
+		Blocks: []ExportBlock{
+			{BlockID: "block_a", TopicLabel: "Go", Status: "ACTIVE", Turns: []ExportTurn{
+				{TurnID: "turn_1", UserMessage: "hello"},
+				{TurnID: "turn_2", UserMessage: "generics"},
+			}},
+			{BlockID: "block_gone", TopicLabel: "Old", Status: "PAUSED"},
+		},
+		Facts: []ExportFact{
+			{FactID: "fact_1", Key: "lang", Value: "go", Confidence: 1},
+			{FactID: "fact_2", Key: "pet", Value: "cat", Confidence: 1},
+		},
+	}
+	after := &ExportData{
+		Profile: &ExportProfile{Name: "Alice", Preferences: []string{"tea", "coffee"}},
+		Blocks: []ExportBlock{
+			{BlockID: "block_a", TopicLabel: "Go", Status: "PAUSED", Turns: []ExportTurn{
+				{TurnID: "turn_1", UserMessage: "hello"},
+			}},
+			{BlockID: "block_new", TopicLabel: "Generics", Status: "ACTIVE", Turns: []ExportTurn{
+				{TurnID: "turn_2", UserMessage: "generics"},
+			}},
+		},
+		Facts: []ExportFact{
+			{FactID: "fact_1", Key: "lang", Value: "rust", Confidence: 1},
+			{FactID: "fact_3", Key: "city", Value: "Paris", Confidence: 0.8},
+		},
+	}
+
+	diff := DiffExports(before, after)
+
+	if len(diff.Blocks.Added) != 1 || diff.Blocks.Added[0] != "block_new" {
+		t.Errorf("Blocks.Added = %v, want [block_new]", diff.Blocks.Added)
+	}
+	if len(diff.Blocks.Removed) != 1 || diff.Blocks.Removed[0] != "block_gone" {
+		t.Errorf("Blocks.Removed = %v, want [block_gone]", diff.Blocks.Removed)
+	}
+	if len(diff.Blocks.Changed) != 1 || diff.Blocks.Changed[0].ID != "block_a" {
+		t.Fatalf("Blocks.Changed = %+v, want block_a", diff.Blocks.Changed)
+	}
+	if fields := diff.Blocks.Changed[0].Fields; len(fields) != 2 || fields[0] != "status" || fields[1] != "turn_count" {
+		t.Errorf("block_a changed fields = %v, want [status turn_count]", fields)
+	}
+
+	if len(diff.Turns.Changed) != 1 || diff.Turns.Changed[0].Fields[0] != "block_id" {
+		t.Errorf("Turns.Changed = %+v, want turn_2 moved", diff.Turns.Changed)
+	}
+	if len(diff.Turns.Added) != 0 || len(diff.Turns.Removed) != 0 {
+		t.Errorf("Turns added/removed = %v/%v, want none", diff.Turns.Added, diff.Turns.Removed)
+	}
+
+	if len(diff.Facts.Added) != 1 || len(diff.Facts.Removed) != 1 || len(diff.Facts.Changed) != 1 {
+		t.Errorf("Facts diff = %+v, want one of each", diff.Facts)
+	}
+
+	if len(diff.ProfileFields) != 1 || diff.ProfileFields[0] != "preferences" {
+		t.Errorf("ProfileFields = %v, want [preferences]", diff.ProfileFields)
+	}
+
+	if diff.Empty() {
+		t.Error("Empty() = true, want false")
+	}
+}
+
+func TestDiffExports_Identical(t *testing.T) {
+	data := &ExportData{
+		Blocks: []ExportBlock{{BlockID: "block_a", Keywords: []string{}, Turns: []ExportTurn{{TurnID: "turn_1"}}}},
+		Facts:  []ExportFact{{FactID: "fact_1", Key: "k", Value: "v"}},
+	}
+	same := &ExportData{
+		Profile: &ExportProfile{},
+		Blocks:  []ExportBlock{{BlockID: "block_a", Turns: []ExportTurn{{TurnID: "turn_1"}}}},
+		Facts:   []ExportFact{{FactID: "fact_1", Key: "k", Value: "v"}},
+	}
+
+	if diff := DiffExports(data, same); !diff.Empty() {
+		t.Errorf("DiffExports() = %+v, want no differences", diff)
+	}
+}
+
+func TestLoadExport_RoundTrip(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	_, _ = store.StoreTurn(&models.Turn{TurnID: "turn_rt", Timestamp: time.Now(), UserMessage: "round trip"})
+	_ = store.SaveFact(&models.Fact{FactID: "fact_rt", Key: "k", Value: "v", Confidence: 0.9, CreatedAt: time.Now()})
+
+	path := filepath.Join(t.TempDir(), "export.yaml")
+	if err := store.ExportToYAML(path, ExportOptions{}); err != nil {
+		t.Fatalf("ExportToYAML() error = %v", err)
+	}
+
+	loaded, err := LoadExport(path)
+	if err != nil {
+		t.Fatalf("LoadExport() error = %v", err)
+	}
+
+	exported, _ := store.Export()
+	if diff := DiffExports(exported, loaded); !diff.Empty() {
+		t.Errorf("round-tripped export differs: %+v", diff)
+	}
+
+	if _, err := LoadExport(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadExport() expected error for missing file")
+	}
+}
@@ -0,0 +1,108 @@
+// ABOUTME: Markdown digest of what memory learned in a period: active topics and new facts
+// ABOUTME: Written by the scheduled digest task, one file per run, for reading or mailing on
+package sqlite
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// digestSnippetLen is the most characters of a topic's first new message a digest quotes
+const digestSnippetLen = 160
+
+// Digest reports a digest written by WriteDigest
+type Digest struct {
+	Path   string    `json:"path"`
+	Since  time.Time `json:"since"`
+	Until  time.Time `json:"until"`
+	Topics int       `json:"topics"` // Topics with turns in the period
+	Facts  int       `json:"facts"`  // Facts recorded in the period
+}
+
+// WriteDigest writes a Markdown digest of the topics that gained turns and the facts
+// recorded between since and until to a new file in dir, named for until. Secret facts
+// are masked.
+func (s *Storage) WriteDigest(dir string, since, until time.Time) (*Digest, error) {
+	data, err := s.ExportWithOptions(ExportOptions{})
+	if err != nil {
+		return nil, err
+	}
+	within := func(timestamp string) bool {
+		t, err := time.Parse(time.RFC3339, timestamp)
+		return err == nil && !t.Before(since) && t.Before(until)
+	}
+
+	loc := s.location()
+	digest := &Digest{Since: since, Until: until}
+	var b bytes.Buffer
+	_, _ = fmt.Fprintf(&b, "# Memory digest\n\n%s to %s\n\n", since.In(loc).Format("2006-01-02 15:04"), until.In(loc).Format("2006-01-02 15:04 MST"))
+
+	var topics bytes.Buffer
+	for _, block := range data.Blocks {
+		var turns []ExportTurn
+		for _, turn := range block.Turns {
+			if within(turn.Timestamp) {
+				turns = append(turns, turn)
+			}
+		}
+		if len(turns) == 0 {
+			continue
+		}
+		digest.Topics++
+		label := block.TopicLabel
+		if label == "" {
+			label = block.BlockID
+		}
+		_, _ = fmt.Fprintf(&topics, "- **%s** (%s, %d new turns)", label, strings.ToLower(block.Status), len(turns))
+		if snippet := digestSnippet(block.Summary, turns[0].UserMessage); snippet != "" {
+			_, _ = fmt.Fprintf(&topics, ": %s", snippet)
+		}
+		_, _ = fmt.Fprintln(&topics)
+	}
+	_, _ = fmt.Fprintf(&b, "## Topics (%d)\n\n", digest.Topics)
+	if digest.Topics == 0 {
+		_, _ = fmt.Fprint(&b, "No conversations.\n\n")
+	} else {
+		_, _ = fmt.Fprintf(&b, "%s\n", topics.Bytes())
+	}
+
+	var facts bytes.Buffer
+	for _, fact := range data.Facts {
+		if within(fact.CreatedAt) {
+			digest.Facts++
+			_, _ = fmt.Fprintf(&facts, "- **%s:** %s\n", fact.Key, strings.Join(strings.Fields(fact.Value), " "))
+		}
+	}
+	_, _ = fmt.Fprintf(&b, "## Facts (%d)\n\n", digest.Facts)
+	if digest.Facts == 0 {
+		_, _ = fmt.Fprint(&b, "No new facts.\n")
+	} else {
+		_, _ = b.Write(facts.Bytes())
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create digest directory: %w", err)
+	}
+	digest.Path = filepath.Join(dir, "digest-"+until.In(loc).Format("20060102-1504")+".md")
+	if err := os.WriteFile(digest.Path, b.Bytes(), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write digest: %w", err)
+	}
+	return digest, nil
+}
+
+// digestSnippet is a topic's summary, or else the start of its first new message, on one line
+func digestSnippet(summary, message string) string {
+	text := summary
+	if text == "" {
+		text = message
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > digestSnippetLen {
+		text = strings.TrimSpace(string(runes[:digestSnippetLen])) + "…"
+	}
+	return text
+}
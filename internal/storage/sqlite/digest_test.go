@@ -0,0 +1,66 @@
+// ABOUTME: Tests for the Markdown digest of a period
+// ABOUTME: Verifies only the topics and facts from the period are listed
+
+package sqlite
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestWriteDigest(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	store.SetLocation(time.UTC)
+
+	now := time.Now().UTC()
+	for _, turn := range []*models.Turn{
+		{TurnID: "turn_old", Timestamp: now.Add(-10 * 24 * time.Hour), UserMessage: "Planning the garden beds", Topics: []string{"Garden"}},
+		{TurnID: "turn_new", Timestamp: now.Add(-time.Hour), UserMessage: "Booked flights   to Lisbon\nfor May", Topics: []string{"Lisbon Trip"}},
+	} {
+		if _, err := store.StoreTurn(turn); err != nil {
+			t.Fatalf("StoreTurn() error = %v", err)
+		}
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_1", Key: "home_airport", Value: "SFO", Confidence: 0.9, CreatedAt: now.Add(-time.Hour)}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_2", Key: "seat", Value: "aisle", Confidence: 0.9, CreatedAt: now.Add(-48 * time.Hour)}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	digest, err := store.WriteDigest(dir, now.Add(-24*time.Hour), now)
+	if err != nil {
+		t.Fatalf("WriteDigest() error = %v", err)
+	}
+	if digest.Topics != 1 || digest.Facts != 1 {
+		t.Errorf("WriteDigest() = %d topics, %d facts, want 1 and 1", digest.Topics, digest.Facts)
+	}
+	if !strings.HasPrefix(digest.Path, dir) || !strings.HasSuffix(digest.Path, now.Format("20060102-1504")+".md") {
+		t.Errorf("Path = %q, want a file in %s named for the end of the period", digest.Path, dir)
+	}
+
+	data, err := os.ReadFile(digest.Path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	text := string(data)
+	for _, want := range []string{"## Topics (1)", "**Lisbon Trip** (active, 1 new turns): Booked flights to Lisbon for May", "## Facts (1)", "**home_airport:** SFO"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("digest missing %q:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"Garden", "aisle"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("digest contains %q:\n%s", unwanted, text)
+		}
+	}
+}
@@ -31,7 +31,15 @@ func (s *EmbeddingStore) Save(chunkID, turnID, blockID string, vector []float64)
 	if len(vector) != ExpectedDimension {
 		return fmt.Errorf("invalid embedding dimension: expected %d, got %d", ExpectedDimension, len(vector))
 	}
-	return s.saveVector(chunkID, turnID, blockID, vector)
+	return s.saveVector(chunkID, turnID, blockID, "", vector)
+}
+
+// SaveWithModel saves an embedding vector and records the model that produced it
+func (s *EmbeddingStore) SaveWithModel(chunkID, turnID, blockID, model string, vector []float64) error {
+	if len(vector) != ExpectedDimension {
+		return fmt.Errorf("invalid embedding dimension: expected %d, got %d", ExpectedDimension, len(vector))
+	}
+	return s.saveVector(chunkID, turnID, blockID, model, vector)
 }
 
 // SaveWithDimension saves an embedding vector with custom dimension (for testing)
@@ -39,24 +47,68 @@ func (s *EmbeddingStore) SaveWithDimension(chunkID, turnID, blockID string, vect
 	if len(vector) != expectedDim {
 		return fmt.Errorf("invalid embedding dimension: expected %d, got %d", expectedDim, len(vector))
 	}
-	return s.saveVector(chunkID, turnID, blockID, vector)
+	return s.saveVector(chunkID, turnID, blockID, "", vector)
 }
 
-// saveVector saves a vector to the database
-func (s *EmbeddingStore) saveVector(chunkID, turnID, blockID string, vector []float64) error {
-	blob := vectorToBlob(vector)
-	embID := fmt.Sprintf("emb_%s", chunkID)
+// saveVector saves a vector to the database in the database's vector format, folding
+// it into its block's centroid
+func (s *EmbeddingStore) saveVector(chunkID, turnID, blockID, model string, vector []float64) error {
+	format, err := s.Format()
+	if err != nil {
+		return err
+	}
 
-	_, err := s.db.Exec(`
-		INSERT INTO embeddings (id, chunk_id, turn_id, block_id, vector, created_at)
-		VALUES (?, ?, ?, ?, ?, ?)
-		ON CONFLICT(id) DO UPDATE SET
-			vector = excluded.vector,
-			turn_id = excluded.turn_id,
-			block_id = excluded.block_id
-	`, embID, chunkID, nullString(turnID), nullString(blockID), blob, time.Now())
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// A chunk saved again replaces its vector, perhaps in another block, so the
+	// centroids it counted in are rebuilt rather than added to
+	var previousBlock sql.NullString
+	err = tx.QueryRow("SELECT block_id FROM embeddings WHERE id = ?", fmt.Sprintf("emb_%s", chunkID)).Scan(&previousBlock)
+	resaved := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if _, err := tx.Exec(upsertEmbeddingSQL, embeddingArgs(format, chunkID, turnID, blockID, model, vector, s.db.now())...); err != nil {
+		return err
+	}
+	stale := resaved
+	if !resaved && blockID != "" {
+		if stale, err = addToCentroid(tx, blockID, model, vector); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if stale {
+		return refreshCentroids(s.db, blockID, previousBlock.String)
+	}
+	return nil
+}
 
-	return err
+// upsertEmbeddingSQL inserts or replaces a single embedding row
+const upsertEmbeddingSQL = `
+	INSERT INTO embeddings (id, chunk_id, turn_id, block_id, vector, format, model, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(id) DO UPDATE SET
+		vector = excluded.vector,
+		format = excluded.format,
+		turn_id = excluded.turn_id,
+		block_id = excluded.block_id,
+		model = excluded.model
+`
+
+// embeddingArgs builds the arguments for upsertEmbeddingSQL, encoding vector in format
+// and stamping it created at now
+func embeddingArgs(format VectorFormat, chunkID, turnID, blockID, model string, vector []float64, now time.Time) []interface{} {
+	return []interface{}{
+		fmt.Sprintf("emb_%s", chunkID), chunkID, nullString(turnID), nullString(blockID),
+		encodeVector(format, vector), format.column(), nullString(model), now.UTC(),
+	}
 }
 
 // GetByChunkID retrieves an embedding by chunk ID
@@ -66,13 +118,14 @@ func (s *EmbeddingStore) GetByChunkID(chunkID string) (*models.Embedding, error)
 		turnID  sql.NullString
 		blockID sql.NullString
 		blob    []byte
+		format  sql.NullString
 	)
 
 	err := s.db.QueryRow(`
-		SELECT chunk_id, turn_id, block_id, vector, created_at
+		SELECT chunk_id, turn_id, block_id, vector, format, created_at
 		FROM embeddings
 		WHERE chunk_id = ?
-	`, chunkID).Scan(&emb.ChunkID, &turnID, &blockID, &blob, &emb.CreatedAt)
+	`, chunkID).Scan(&emb.ChunkID, &turnID, &blockID, &blob, &format, &emb.CreatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -87,7 +140,7 @@ func (s *EmbeddingStore) GetByChunkID(chunkID string) (*models.Embedding, error)
 	if blockID.Valid {
 		emb.BlockID = blockID.String
 	}
-	emb.Vector = blobToVector(blob)
+	emb.Vector = decodeVector(vectorFormatOf(format), blob)
 
 	return &emb, nil
 }
@@ -95,7 +148,7 @@ func (s *EmbeddingStore) GetByChunkID(chunkID string) (*models.Embedding, error)
 // GetByBlock retrieves all embeddings for a block
 func (s *EmbeddingStore) GetByBlock(blockID string) ([]models.Embedding, error) {
 	rows, err := s.db.Query(`
-		SELECT chunk_id, turn_id, block_id, vector, created_at
+		SELECT chunk_id, turn_id, block_id, vector, format, created_at
 		FROM embeddings
 		WHERE block_id = ?
 		ORDER BY created_at ASC
@@ -111,7 +164,7 @@ func (s *EmbeddingStore) GetByBlock(blockID string) ([]models.Embedding, error)
 // SearchSimilar performs cosine similarity search
 func (s *EmbeddingStore) SearchSimilar(queryVector []float64, maxResults int) ([]models.VectorSearchResult, error) {
 	rows, err := s.db.Query(`
-		SELECT chunk_id, turn_id, block_id, vector
+		SELECT chunk_id, turn_id, block_id, vector, format
 		FROM embeddings
 	`)
 	if err != nil {
@@ -127,13 +180,15 @@ func (s *EmbeddingStore) SearchSimilar(queryVector []float64, maxResults int) ([
 			turnID  sql.NullString
 			blockID sql.NullString
 			blob    []byte
+			format  sql.NullString
 		)
 
-		if err := rows.Scan(&chunkID, &turnID, &blockID, &blob); err != nil {
+		if err := rows.Scan(&chunkID, &turnID, &blockID, &blob, &format); err != nil {
 			return nil, err
 		}
 
-		vector := blobToVector(blob)
+		// Quantized vectors are compared after dequantizing
+		vector := decodeVector(vectorFormatOf(format), blob)
 		similarity := CosineSimilarity(queryVector, vector)
 
 		result := models.VectorSearchResult{
@@ -167,14 +222,51 @@ func (s *EmbeddingStore) SearchSimilar(queryVector []float64, maxResults int) ([
 	return results, nil
 }
 
-// Delete removes an embedding by chunk ID
+// Delete removes an embedding, and the text of its chunk, by chunk ID
 func (s *EmbeddingStore) Delete(chunkID string) error {
-	_, err := s.db.Exec("DELETE FROM embeddings WHERE chunk_id = ?", chunkID)
-	return err
+	var blockID sql.NullString
+	if err := s.db.QueryRow("SELECT block_id FROM embeddings WHERE chunk_id = ?", chunkID).Scan(&blockID); err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if _, err := s.db.Exec("DELETE FROM embeddings WHERE chunk_id = ?", chunkID); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec("DELETE FROM chunks WHERE id = ?", chunkID); err != nil {
+		return err
+	}
+	return refreshCentroids(s.db, blockID.String)
+}
+
+// Dimensions counts stored vectors by dimension. A blob whose length fits no vector of
+// its format (a float64 blob not a multiple of 8 bytes long, say) is malformed; those
+// are counted under dimension 0.
+func (s *EmbeddingStore) Dimensions() (map[int]int, error) {
+	rows, err := s.db.Query("SELECT format, length(vector), COUNT(*) FROM embeddings GROUP BY format, length(vector)")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	dims := make(map[int]int)
+	for rows.Next() {
+		var (
+			format  sql.NullString
+			size, n int
+		)
+		if err := rows.Scan(&format, &size, &n); err != nil {
+			return nil, err
+		}
+		dim, ok := vectorDimension(vectorFormatOf(format), size)
+		if !ok {
+			dim = 0
+		}
+		dims[dim] += n
+	}
+	return dims, rows.Err()
 }
 
 // scanEmbeddings scans rows into embeddings
-func (s *EmbeddingStore) scanEmbeddings(rows *sql.Rows) ([]models.Embedding, error) {
+func (s *EmbeddingStore) scanEmbeddings(rows *Rows) ([]models.Embedding, error) {
 	var embeddings []models.Embedding
 
 	for rows.Next() {
@@ -183,9 +275,10 @@ func (s *EmbeddingStore) scanEmbeddings(rows *sql.Rows) ([]models.Embedding, err
 			turnID  sql.NullString
 			blockID sql.NullString
 			blob    []byte
+			format  sql.NullString
 		)
 
-		if err := rows.Scan(&emb.ChunkID, &turnID, &blockID, &blob, &emb.CreatedAt); err != nil {
+		if err := rows.Scan(&emb.ChunkID, &turnID, &blockID, &blob, &format, &emb.CreatedAt); err != nil {
 			return nil, err
 		}
 
@@ -195,7 +288,7 @@ func (s *EmbeddingStore) scanEmbeddings(rows *sql.Rows) ([]models.Embedding, err
 		if blockID.Valid {
 			emb.BlockID = blockID.String
 		}
-		emb.Vector = blobToVector(blob)
+		emb.Vector = decodeVector(vectorFormatOf(format), blob)
 
 		embeddings = append(embeddings, emb)
 	}
@@ -203,7 +296,7 @@ func (s *EmbeddingStore) scanEmbeddings(rows *sql.Rows) ([]models.Embedding, err
 	return embeddings, rows.Err()
 }
 
-// vectorToBlob converts a float64 slice to binary blob
+// vectorToBlob converts a float64 slice to binary blob (the float64 vector format)
 func vectorToBlob(vector []float64) []byte {
 	blob := make([]byte, len(vector)*8)
 	for i, v := range vector {
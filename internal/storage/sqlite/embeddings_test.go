@@ -244,3 +244,40 @@ func TestEmbeddingCascadeOnBlockDelete(t *testing.T) {
 		t.Error("Embedding should be deleted after block deletion (CASCADE)")
 	}
 }
+
+func TestEmbeddingDimensions(t *testing.T) {
+	db, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("OpenInMemory() error = %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	blockStore := NewBlockStore(db)
+	block := &models.BridgeBlock{BlockID: "block_dims", DayID: "2026-01-31", Status: models.StatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := blockStore.Save(block); err != nil {
+		t.Fatalf("Save block error = %v", err)
+	}
+
+	store := NewEmbeddingStore(db)
+	if err := store.SaveWithDimension("chunk_a", "turn_a", "block_dims", []float64{1, 0, 0, 0}, 4); err != nil {
+		t.Fatalf("Save error = %v", err)
+	}
+	if err := store.SaveWithDimension("chunk_b", "turn_b", "block_dims", []float64{0, 1, 0, 0}, 4); err != nil {
+		t.Fatalf("Save error = %v", err)
+	}
+	if err := store.SaveWithDimension("chunk_c", "turn_c", "block_dims", []float64{1, 0}, 2); err != nil {
+		t.Fatalf("Save error = %v", err)
+	}
+	// A truncated blob is counted as malformed
+	if _, err := db.Exec("UPDATE embeddings SET vector = substr(vector, 1, 5) WHERE chunk_id = ?", "chunk_c"); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+
+	dims, err := store.Dimensions()
+	if err != nil {
+		t.Fatalf("Dimensions() error = %v", err)
+	}
+	if len(dims) != 2 || dims[4] != 2 || dims[0] != 1 {
+		t.Errorf("Dimensions() = %v, want 2 of dimension 4 and 1 malformed", dims)
+	}
+}
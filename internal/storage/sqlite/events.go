@@ -0,0 +1,115 @@
+// ABOUTME: Publishes memory events once the write they describe has succeeded
+// ABOUTME: Turns stored, facts added, superseded, or contradicted, topics changed, and profile updates
+package sqlite
+
+import (
+	"log"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// EventPublisher receives memory events synchronously. Publish is called after storage
+// releases its locks, so it may read and write storage, but it runs on the writer's
+// goroutine and should return quickly. Most consumers should Subscribe instead.
+type EventPublisher interface {
+	Publish(event models.Event)
+}
+
+// SetEventPublisher publishes memory events to p from now on; nil stops publishing
+func (s *Storage) SetEventPublisher(p EventPublisher) {
+	s.events = p
+}
+
+// publishing reports whether anyone receives events, so work only events need can be skipped
+func (s *Storage) publishing() bool {
+	return s.events != nil || s.changes.subscribed()
+}
+
+// publish sends event to the publisher, if there is one, and to the change feed
+func (s *Storage) publish(event models.Event) {
+	if s.events != nil {
+		s.events.Publish(event)
+	}
+	s.changes.send(event)
+}
+
+// publishTopic publishes topic_archived, or topic_updated for any other status, for a
+// block whose status changed
+func (s *Storage) publishTopic(blockID string, status models.BridgeBlockStatus) {
+	s.publish(topicEvent(blockID, status))
+}
+
+// topicEvent is topic_archived, or topic_updated for any other status, for blockID
+func topicEvent(blockID string, status models.BridgeBlockStatus) models.Event {
+	event := models.NewEvent(models.EventTopicUpdated)
+	if status == models.StatusArchived {
+		event.Type = models.EventTopicArchived
+	}
+	event.BlockID, event.Status = blockID, string(status)
+	return event
+}
+
+// publishProfile publishes profile_updated for the shared profile, or persona when set
+func (s *Storage) publishProfile(persona string) {
+	event := models.NewEvent(models.EventProfileUpdated)
+	event.Persona = persona
+	s.publish(event)
+}
+
+// publishTurn publishes turn_stored for a turn saved in blockID
+func (s *Storage) publishTurn(blockID string, turn *models.Turn) {
+	if !s.publishing() {
+		return
+	}
+	event := models.NewEvent(models.EventTurnStored)
+	event.BlockID, event.TurnID = blockID, turn.TurnID
+	s.publish(event)
+}
+
+// latestFact returns the fact that fact will supersede: the most recent one with its key.
+// It is only looked up when events are published.
+func (s *Storage) latestFact(fact *models.Fact) *models.Fact {
+	if !s.publishing() {
+		return nil
+	}
+	previous, err := s.facts.GetByKey(fact.Key)
+	if err != nil {
+		log.Printf("[Storage] failed to look up fact %s for events: %v", fact.Key, err)
+		return nil
+	}
+	if previous == nil || previous.FactID == fact.FactID {
+		return nil
+	}
+	if !fact.CreatedAt.IsZero() && fact.CreatedAt.Before(previous.CreatedAt) {
+		return nil // an older fact does not replace a newer one
+	}
+	return previous
+}
+
+// publishFact publishes fact_added for a saved fact. When it replaces a different value
+// for its key, fact_superseded follows, and conflict_detected too if the two values were
+// recorded by different agents.
+func (s *Storage) publishFact(fact, previous *models.Fact) {
+	if !s.publishing() {
+		return
+	}
+	added := models.NewEvent(models.EventFactAdded)
+	added.BlockID, added.TurnID = fact.BlockID, fact.TurnID
+	added.Fact = models.NewEventFact(fact)
+	s.publish(added)
+
+	if previous == nil || previous.Value == fact.Value {
+		return
+	}
+	superseded := models.NewEvent(models.EventFactSuperseded)
+	superseded.BlockID, superseded.TurnID = fact.BlockID, fact.TurnID
+	superseded.Fact, superseded.Previous = models.NewEventFact(fact), models.NewEventFact(previous)
+	s.publish(superseded)
+
+	if previous.ExtractedBy != "" && fact.ExtractedBy != "" && previous.ExtractedBy != fact.ExtractedBy {
+		conflict := models.NewEvent(models.EventConflictDetected)
+		conflict.BlockID, conflict.TurnID = fact.BlockID, fact.TurnID
+		conflict.Fact, conflict.Previous = superseded.Fact, superseded.Previous
+		s.publish(conflict)
+	}
+}
@@ -0,0 +1,106 @@
+// ABOUTME: Tests for memory events published by storage
+// ABOUTME: Verifies turn, fact, supersede, conflict, and archive events and that secrets stay out of them
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// recorder collects published events; it reads storage from Publish to prove locks are released
+type recorder struct {
+	store  *Storage
+	events []models.Event
+}
+
+func (r *recorder) Publish(event models.Event) {
+	if event.BlockID != "" {
+		_, _ = r.store.GetBridgeBlock(event.BlockID)
+	}
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) types() []models.EventType {
+	var types []models.EventType
+	for _, e := range r.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestStorageEvents(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	rec := &recorder{store: store}
+	store.SetEventPublisher(rec)
+
+	turn := &models.Turn{TurnID: "turn_1", UserMessage: "I use vim"}
+	blockID, err := store.StoreTurn(turn)
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if err := store.AppendTurnToBlock(blockID, &models.Turn{TurnID: "turn_2", UserMessage: "and tmux"}); err != nil {
+		t.Fatalf("AppendTurnToBlock() error = %v", err)
+	}
+
+	now := time.Now()
+	save := func(id, value, agent string, valueType models.FactValueType, at time.Time) {
+		t.Helper()
+		fact := &models.Fact{FactID: id, BlockID: blockID, TurnID: "turn_1", Key: "editor", Value: value,
+			ValueType: valueType, Confidence: 1, CreatedAt: at, ExtractedBy: agent}
+		if err := store.SaveFact(fact); err != nil {
+			t.Fatalf("SaveFact(%s) error = %v", id, err)
+		}
+	}
+	save("fact_1", "vim", "fact_scrubber", "", now)
+	save("fact_2", "vim", "fact_scrubber", "", now.Add(time.Second))                        // same value: added only
+	save("fact_3", "emacs", "fact_scrubber", "", now.Add(2*time.Second))                    // superseded
+	save("fact_4", "helix", "claude-code", "", now.Add(3*time.Second))                      // superseded by another agent
+	save("fact_5", "nano", "claude-code", "", now.Add(-time.Hour))                          // older: added only
+	save("fact_6", "s3cret", "claude-code", models.ValueTypeSecret, now.Add(4*time.Second)) // secret supersedes
+
+	if err := store.UpdateBridgeBlockStatus(blockID, models.StatusPaused); err != nil {
+		t.Fatalf("UpdateBridgeBlockStatus(paused) error = %v", err)
+	}
+	if err := store.UpdateBridgeBlockStatus(blockID, models.StatusArchived); err != nil {
+		t.Fatalf("UpdateBridgeBlockStatus(archived) error = %v", err)
+	}
+
+	want := []models.EventType{
+		models.EventTurnStored, models.EventTurnStored,
+		models.EventFactAdded,
+		models.EventFactAdded,
+		models.EventFactAdded, models.EventFactSuperseded,
+		models.EventFactAdded, models.EventFactSuperseded, models.EventConflictDetected,
+		models.EventFactAdded,
+		models.EventFactAdded, models.EventFactSuperseded,
+		models.EventTopicUpdated, models.EventTopicArchived,
+	}
+	got := rec.types()
+	if len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events = %v, want %v", got, want)
+		}
+	}
+
+	if e := rec.events[0]; e.BlockID != blockID || e.TurnID != "turn_1" {
+		t.Errorf("turn_stored = %+v", e)
+	}
+	if e := rec.events[8]; e.Fact.Value != "helix" || e.Previous.Value != "emacs" || e.Previous.Agent != "fact_scrubber" {
+		t.Errorf("conflict_detected = %+v, %+v", e.Fact, e.Previous)
+	}
+	if e := rec.events[11]; e.Fact.Value != "" || e.Fact.ValueType != models.ValueTypeSecret || e.Previous.Value != "helix" {
+		t.Errorf("secret fact_superseded = %+v, %+v", e.Fact, e.Previous)
+	}
+	if e := rec.events[12]; e.BlockID != blockID {
+		t.Errorf("topic_archived = %+v", e)
+	}
+}
@@ -0,0 +1,178 @@
+// ABOUTME: Database size accounting and eviction of archived blocks
+// ABOUTME: Deletes the least important archived blocks past their retention to bring the database under a size limit
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// EvictedBlock is an archived block chosen for eviction
+type EvictedBlock struct {
+	BlockID    string    `json:"block_id"`
+	TopicLabel string    `json:"topic_label"`
+	Importance int       `json:"importance"` // Turns plus two per fact learned from the block, plus one per read, plus two per net thumbs up
+	Bytes      int64     `json:"bytes"`      // Estimated bytes of turns, attachments, and embeddings freed
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// EvictionStats summarizes every eviction recorded in the database
+type EvictionStats struct {
+	Blocks        int        `json:"blocks"`
+	Bytes         int64      `json:"bytes"`
+	LastEvictedAt *time.Time `json:"last_evicted_at,omitempty"`
+}
+
+// evictionCandidates selects archived blocks neither updated nor read since the cutoff,
+// least important first and least recently used first among equals. Facts count double
+// because hydration keeps using them; they are kept when their block is evicted. Each
+// time the block was retrieved or hydrated counts once, and each net thumbs up from
+// rate_memory, on the block or its facts, counts double (a net thumbs down lowers the
+// importance the same way). Blocks kept forever by their own retention policy, or by
+// a tag's when they have none, are never candidates.
+const evictionCandidates = `
+	SELECT b.id, COALESCE(b.topic_label, ''), b.updated_at,
+		(SELECT COUNT(*) FROM turns t WHERE t.block_id = b.id)
+			+ 2 * (SELECT COUNT(*) FROM facts f WHERE f.block_id = b.id)
+			+ COALESCE(a.access_count, 0)
+			+ 2 * ` + blockRatingExpr + ` AS importance,
+		COALESCE((SELECT SUM(length(COALESCE(t.user_message, '')) + length(COALESCE(t.ai_response, '')) + length(COALESCE(t.messages, '')))
+			FROM turns t WHERE t.block_id = b.id), 0)
+			+ COALESCE((SELECT SUM(length(COALESCE(a.content, ''))) FROM attachments a JOIN turns t ON t.id = a.turn_id WHERE t.block_id = b.id), 0)
+			+ COALESCE((SELECT SUM(length(e.vector)) FROM embeddings e WHERE e.block_id = b.id), 0)
+	FROM bridge_blocks b
+	LEFT JOIN block_access a ON a.block_id = b.id
+	WHERE b.status = ? AND b.updated_at < ? AND COALESCE(a.last_accessed_at, b.updated_at) < ?
+		AND NOT ` + blockKeptExpr + `
+	ORDER BY importance ASC, MAX(b.updated_at, COALESCE(a.last_accessed_at, b.updated_at)) ASC`
+
+// blockKeptExpr is true for a block b kept forever by its own retention policy, or by a
+// tag's when it has none
+const blockKeptExpr = `COALESCE(b.retention, CASE WHEN EXISTS (
+			SELECT 1 FROM block_tags bt JOIN tag_retention tr ON tr.tag = bt.tag
+			WHERE bt.block_id = b.id AND tr.retention = 'keep') THEN 'keep' END, '') = 'keep'`
+
+// UsedBytes returns the bytes the database's live pages take up. Unlike the file size
+// it drops as soon as rows are deleted, since freed pages are reused before the file
+// grows again.
+func (s *Storage) UsedBytes() (int64, error) {
+	var pages, free, pageSize int64
+	for _, p := range []struct {
+		pragma string
+		dest   *int64
+	}{
+		{"page_count", &pages},
+		{"freelist_count", &free},
+		{"page_size", &pageSize},
+	} {
+		if err := s.db.QueryRow("PRAGMA " + p.pragma).Scan(p.dest); err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", p.pragma, err)
+		}
+	}
+	return (pages - free) * pageSize, nil
+}
+
+// EvictionPlan returns the archived blocks EvictArchived would delete to bring the
+// database down to target bytes, without deleting them
+func (s *Storage) EvictionPlan(target int64, retention time.Duration) ([]EvictedBlock, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.evictionPlan(target, retention)
+}
+
+// EvictArchived deletes archived blocks, least important first, until the estimated
+// bytes freed bring the database down to target. Blocks updated or read within
+// retention are never evicted. Deleting a block deletes its turns, attachments, and
+// embeddings; facts learned from it are kept. Each eviction is recorded for EvictionStats.
+func (s *Storage) EvictArchived(target int64, retention time.Duration) ([]EvictedBlock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plan, err := s.evictionPlan(target, retention)
+	if err != nil || len(plan) == 0 {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin eviction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := s.db.now().UTC()
+	evicted := plan[:0]
+	for _, block := range plan {
+		// A block unarchived since it was planned is no longer a candidate
+		result, err := tx.Exec("DELETE FROM bridge_blocks WHERE id = ? AND status = ?", block.BlockID, string(models.StatusArchived))
+		if err != nil {
+			return nil, fmt.Errorf("failed to evict block %s: %w", block.BlockID, err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			continue
+		}
+		if _, err := tx.Exec(`
+			INSERT OR REPLACE INTO evictions (block_id, topic_label, importance, bytes, evicted_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, block.BlockID, block.TopicLabel, block.Importance, block.Bytes, now); err != nil {
+			return nil, fmt.Errorf("failed to record eviction of block %s: %w", block.BlockID, err)
+		}
+		evicted = append(evicted, block)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit eviction: %w", err)
+	}
+	return evicted, nil
+}
+
+// evictionPlan picks candidates until their estimated size covers the bytes over target
+func (s *Storage) evictionPlan(target int64, retention time.Duration) ([]EvictedBlock, error) {
+	used, err := s.UsedBytes()
+	if err != nil {
+		return nil, err
+	}
+	excess := used - target
+	if excess <= 0 {
+		return nil, nil
+	}
+
+	cutoff := s.db.now().UTC().Add(-retention)
+	rows, err := s.db.Query(evictionCandidates, string(models.StatusArchived), cutoff, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find eviction candidates: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var plan []EvictedBlock
+	for rows.Next() && excess > 0 {
+		var block EvictedBlock
+		if err := rows.Scan(&block.BlockID, &block.TopicLabel, &block.UpdatedAt, &block.Importance, &block.Bytes); err != nil {
+			return nil, err
+		}
+		plan = append(plan, block)
+		excess -= block.Bytes
+	}
+	return plan, rows.Err()
+}
+
+// EvictionStats counts the blocks evicted so far and the bytes they were estimated to free
+func (s *Storage) EvictionStats() (EvictionStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var stats EvictionStats
+	if err := s.db.QueryRow("SELECT COUNT(*), COALESCE(SUM(bytes), 0) FROM evictions").Scan(&stats.Blocks, &stats.Bytes); err != nil {
+		return EvictionStats{}, fmt.Errorf("failed to read eviction stats: %w", err)
+	}
+	var last time.Time
+	err := s.db.QueryRow("SELECT evicted_at FROM evictions ORDER BY evicted_at DESC LIMIT 1").Scan(&last)
+	if err != nil && err != sql.ErrNoRows {
+		return EvictionStats{}, fmt.Errorf("failed to read eviction stats: %w", err)
+	}
+	if err == nil {
+		stats.LastEvictedAt = &last
+	}
+	return stats, nil
+}
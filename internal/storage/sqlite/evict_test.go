@@ -0,0 +1,108 @@
+// ABOUTME: Tests for database size accounting and eviction
+// ABOUTME: Verifies only old archived blocks are evicted, least important first, and that evictions are recorded
+
+package sqlite
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestEvictArchived(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	old := time.Now().Add(-90 * 24 * time.Hour)
+	for _, b := range []struct {
+		id      string
+		status  models.BridgeBlockStatus
+		updated time.Time
+		turns   int
+		facts   int
+	}{
+		{"block_old_small", models.StatusArchived, old, 1, 0},
+		{"block_old_facts", models.StatusArchived, old.Add(-time.Hour), 1, 2},
+		{"block_old_big", models.StatusArchived, old, 3, 0},
+		{"block_recent", models.StatusArchived, time.Now(), 1, 0},
+		{"block_paused", models.StatusPaused, old, 1, 0},
+	} {
+		if err := store.blocks.Save(&models.BridgeBlock{BlockID: b.id, DayID: "2025-01-01", TopicLabel: b.id, Status: b.status, CreatedAt: b.updated, UpdatedAt: b.updated}); err != nil {
+			t.Fatalf("Save block error = %v", err)
+		}
+		for i := 0; i < b.turns; i++ {
+			turn := &models.Turn{TurnID: b.id + "_turn_" + string(rune('a'+i)), Timestamp: b.updated, UserMessage: strings.Repeat("x", 4000)}
+			if err := store.turns.Save(b.id, turn); err != nil {
+				t.Fatalf("Save turn error = %v", err)
+			}
+		}
+		for i := 0; i < b.facts; i++ {
+			if err := store.facts.Save(&models.Fact{FactID: b.id + "_fact_" + string(rune('a'+i)), BlockID: b.id, Key: "k", Value: "v", Confidence: 1}); err != nil {
+				t.Fatalf("Save fact error = %v", err)
+			}
+		}
+	}
+
+	used, err := store.UsedBytes()
+	if err != nil || used <= 0 {
+		t.Fatalf("UsedBytes() = %d, %v, want a positive size", used, err)
+	}
+
+	// Under the limit there is nothing to evict
+	if plan, err := store.EvictionPlan(used, 30*24*time.Hour); err != nil || len(plan) != 0 {
+		t.Fatalf("EvictionPlan(used) = %v, %v, want nothing", plan, err)
+	}
+
+	// A little over the limit evicts only the least important block
+	plan, err := store.EvictionPlan(used-100, 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("EvictionPlan() error = %v", err)
+	}
+	if len(plan) != 1 || plan[0].BlockID != "block_old_small" || plan[0].Bytes < 4000 {
+		t.Fatalf("EvictionPlan() = %+v, want block_old_small with its turn's bytes", plan)
+	}
+
+	// Far over the limit evicts every old archived block, fewest turns and facts first
+	evicted, err := store.EvictArchived(0, 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("EvictArchived() error = %v", err)
+	}
+	var ids []string
+	for _, block := range evicted {
+		ids = append(ids, block.BlockID)
+	}
+	if got, want := strings.Join(ids, ","), "block_old_small,block_old_big,block_old_facts"; got != want {
+		t.Errorf("evicted %s, want %s", got, want)
+	}
+	for _, id := range []string{"block_recent", "block_paused"} {
+		if block, err := store.GetBridgeBlock(id); err != nil || block == nil {
+			t.Errorf("GetBridgeBlock(%s) = %v, %v, want it kept", id, block, err)
+		}
+	}
+	if block, err := store.GetBridgeBlock("block_old_big"); err == nil && block != nil {
+		t.Error("block_old_big still exists after eviction")
+	}
+
+	// Facts outlive their block
+	var orphaned int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM facts WHERE block_id IS NULL").Scan(&orphaned); err != nil || orphaned != 2 {
+		t.Errorf("facts without a block = %d, %v, want both kept", orphaned, err)
+	}
+
+	stats, err := store.EvictionStats()
+	if err != nil {
+		t.Fatalf("EvictionStats() error = %v", err)
+	}
+	if stats.Blocks != 3 || stats.Bytes < 5*4000 || stats.LastEvictedAt == nil {
+		t.Errorf("EvictionStats() = %+v, want 3 blocks recorded", stats)
+	}
+
+	if evicted, err := store.EvictArchived(0, 30*24*time.Hour); err != nil || len(evicted) != 0 {
+		t.Errorf("second EvictArchived() = %v, %v, want nothing left to evict", evicted, err)
+	}
+}
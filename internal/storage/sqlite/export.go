@@ -3,12 +3,17 @@
 package sqlite
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/signing"
 	"gopkg.in/yaml.v3"
 )
 
@@ -17,17 +22,29 @@ type ExportData struct {
 	Version    string              `yaml:"version" json:"version"`
 	ExportedAt string              `yaml:"exported_at" json:"exported_at"`
 	Tool       string              `yaml:"tool" json:"tool"`
+	Tags       []string            `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Retention  map[string]string   `yaml:"tag_retention,omitempty" json:"tag_retention,omitempty"` // Retention policy by tag
 	Profile    *ExportProfile      `yaml:"profile,omitempty" json:"profile,omitempty"`
 	Blocks     []ExportBlock       `yaml:"blocks,omitempty" json:"blocks,omitempty"`
 	Facts      []ExportFact        `yaml:"facts,omitempty" json:"facts,omitempty"`
 	Embeddings string              `yaml:"embeddings_file,omitempty" json:"embeddings_file,omitempty"`
+	Manifest   *ExportManifest     `yaml:"manifest,omitempty" json:"manifest,omitempty"`
 }
 
 // ExportProfile represents user profile for export
 type ExportProfile struct {
-	Name             string   `yaml:"name" json:"name"`
-	Preferences      []string `yaml:"preferences" json:"preferences"`
-	TopicsOfInterest []string `yaml:"topics_of_interest" json:"topics_of_interest"`
+	Name             string                     `yaml:"name" json:"name"`
+	Preferences      []string                   `yaml:"preferences" json:"preferences"`
+	TopicsOfInterest []string                   `yaml:"topics_of_interest" json:"topics_of_interest"`
+	Constraints      []models.ProfileConstraint `yaml:"constraints,omitempty" json:"constraints,omitempty"`
+	Personas         []ExportPersona            `yaml:"personas,omitempty" json:"personas,omitempty"`
+}
+
+// ExportPersona represents a profile persona for export
+type ExportPersona struct {
+	Name        string                     `yaml:"name" json:"name"`
+	Preferences []string                   `yaml:"preferences,omitempty" json:"preferences,omitempty"`
+	Constraints []models.ProfileConstraint `yaml:"constraints,omitempty" json:"constraints,omitempty"`
 }
 
 // ExportBlock represents a bridge block for export
@@ -37,16 +54,32 @@ type ExportBlock struct {
 	Status     string       `yaml:"status" json:"status"`
 	Keywords   []string     `yaml:"keywords,omitempty" json:"keywords,omitempty"`
 	Summary    string       `yaml:"summary,omitempty" json:"summary,omitempty"`
+	Tags       []string     `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Retention  string       `yaml:"retention,omitempty" json:"retention,omitempty"` // The block's own retention policy
 	CreatedAt  string       `yaml:"created_at" json:"created_at"`
 	Turns      []ExportTurn `yaml:"turns" json:"turns"`
 }
 
 // ExportTurn represents a turn for export
 type ExportTurn struct {
-	TurnID      string `yaml:"turn_id" json:"turn_id"`
-	UserMessage string `yaml:"user_message" json:"user_message"`
-	AIResponse  string `yaml:"ai_response" json:"ai_response"`
-	Timestamp   string `yaml:"timestamp" json:"timestamp"`
+	TurnID      string             `yaml:"turn_id" json:"turn_id"`
+	UserMessage string             `yaml:"user_message" json:"user_message"`
+	AIResponse  string             `yaml:"ai_response" json:"ai_response"`
+	Timestamp   string             `yaml:"timestamp" json:"timestamp"`
+	Affect      string             `yaml:"affect,omitempty" json:"affect,omitempty"`
+	Messages    []models.Message   `yaml:"messages,omitempty" json:"messages,omitempty"`
+	Origin      *models.Origin     `yaml:"origin,omitempty" json:"origin,omitempty"`
+	Attachments []ExportAttachment `yaml:"attachments,omitempty" json:"attachments,omitempty"`
+}
+
+// ExportAttachment represents a turn attachment for export
+type ExportAttachment struct {
+	AttachmentID string `yaml:"attachment_id" json:"attachment_id"`
+	Kind         string `yaml:"kind" json:"kind"`
+	Name         string `yaml:"name" json:"name"`
+	Ref          string `yaml:"ref,omitempty" json:"ref,omitempty"`
+	MimeType     string `yaml:"mime_type,omitempty" json:"mime_type,omitempty"`
+	Content      string `yaml:"content,omitempty" json:"content,omitempty"`
 }
 
 // ExportFact represents a fact for export
@@ -54,98 +87,282 @@ type ExportFact struct {
 	FactID     string  `yaml:"fact_id" json:"fact_id"`
 	Key        string  `yaml:"key" json:"key"`
 	Value      string  `yaml:"value" json:"value"`
-	Confidence float64 `yaml:"confidence" json:"confidence"`
-	CreatedAt  string  `yaml:"created_at" json:"created_at"`
+	ValueType  string   `yaml:"value_type,omitempty" json:"value_type,omitempty"`
+	Confidence float64  `yaml:"confidence" json:"confidence"`
+	Tags       []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	CreatedAt  string   `yaml:"created_at" json:"created_at"`
+
+	SourceModel   string `yaml:"source_model,omitempty" json:"source_model,omitempty"`
+	PromptVersion string `yaml:"prompt_version,omitempty" json:"prompt_version,omitempty"`
+	SourceQuote   string `yaml:"source_quote,omitempty" json:"source_quote,omitempty"`
+	ExtractedBy   string `yaml:"extracted_by,omitempty" json:"extracted_by,omitempty"`
+	Agent         string `yaml:"agent,omitempty" json:"agent,omitempty"`
+}
+
+// ExportOptions selects what an export includes
+type ExportOptions struct {
+	// Tags limits the export to blocks carrying every tag, plus facts that carry
+	// every tag or belong to a selected block. Empty exports everything.
+	Tags []string
+	// RevealSecrets exports secret fact values decrypted instead of masked
+	RevealSecrets bool
+	// Signer signs the checksum manifest of YAML exports; nil leaves it unsigned
+	Signer *signing.Key
 }
 
 // Export exports all data from storage
 func (s *Storage) Export() (*ExportData, error) {
+	return s.ExportWithOptions(ExportOptions{})
+}
+
+// ExportWithOptions exports the data selected by opts
+func (s *Storage) ExportWithOptions(opts ExportOptions) (*ExportData, error) {
+	var data *ExportData
+	err := s.walkExport(opts, exportVisitor{
+		header: func(header *ExportData) error {
+			data = header
+			data.Facts = []ExportFact{}
+			return nil
+		},
+		block: func(block ExportBlock) error {
+			data.Blocks = append(data.Blocks, block)
+			return nil
+		},
+		fact: func(fact ExportFact, blockID string) error {
+			data.Facts = append(data.Facts, fact)
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// exportVisitor receives an export piece by piece: the header with the profile, then
+// each block with its turns, then each fact with the block it came from
+type exportVisitor struct {
+	header func(*ExportData) error
+	block  func(ExportBlock) error
+	fact   func(fact ExportFact, blockID string) error
+}
+
+// walkExport reads the data selected by opts and hands it to visit, reading one block's
+// turns at a time so large memories need not be held whole
+func (s *Storage) walkExport(opts ExportOptions, visit exportVisitor) error {
+	tags, err := models.NormalizeTags(opts.Tags)
+	if err != nil {
+		return err
+	}
+
 	data := &ExportData{
 		Version:    "1.0",
-		ExportedAt: time.Now().Format(time.RFC3339),
+		ExportedAt: s.db.now().In(s.location()).Format(time.RFC3339),
 		Tool:       "memory",
+		Tags:       tags,
+	}
+
+	// A nil selection means everything is exported
+	var selectedBlocks, selectedFacts map[string]bool
+	if len(tags) > 0 {
+		if selectedBlocks, err = s.tags.BlocksWithAll(tags); err != nil {
+			return fmt.Errorf("failed to select blocks by tag: %w", err)
+		}
+		if selectedFacts, err = s.tags.FactsWithAll(tags); err != nil {
+			return fmt.Errorf("failed to select facts by tag: %w", err)
+		}
 	}
 
 	// Export profile
-	profile, err := s.GetUserProfile()
+	if data.Profile, err = s.exportProfile(); err != nil {
+		return err
+	}
+
+	// Export blocks with turns, leaving out blocks whose retention ran out: the next
+	// prune deletes them
+	if data.Retention, err = s.TagRetentionPolicies(); err != nil {
+		return err
+	}
+	if len(data.Retention) == 0 {
+		data.Retention = nil
+	}
+	if err := visit.header(data); err != nil {
+		return err
+	}
+	expired, err := s.expiredBlocks(s.db.now())
 	if err != nil {
-		return nil, fmt.Errorf("failed to get profile: %w", err)
+		return err
 	}
-	if profile != nil {
-		data.Profile = &ExportProfile{
-			Name:             profile.Name,
-			Preferences:      profile.Preferences,
-			TopicsOfInterest: profile.TopicsOfInterest,
-		}
+	expiredIDs := make(map[string]bool, len(expired))
+	for _, block := range expired {
+		expiredIDs[block.BlockID] = true
 	}
 
-	// Export blocks with turns
 	blocks, err := s.blocks.ListAll()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list blocks: %w", err)
+		return fmt.Errorf("failed to list blocks: %w", err)
 	}
 
+	blockIDs := make([]string, 0, len(blocks))
 	for _, block := range blocks {
+		blockIDs = append(blockIDs, block.BlockID)
+	}
+	blockTags, err := s.tags.ForBlocks(blockIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get block tags: %w", err)
+	}
+	blockAttachments, err := s.attachments.ForBlocks(blockIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get attachments: %w", err)
+	}
+
+	for _, block := range blocks {
+		if selectedBlocks != nil && !selectedBlocks[block.BlockID] || expiredIDs[block.BlockID] {
+			continue
+		}
 		fullBlock, err := s.blocks.GetWithTurns(block.BlockID)
 		if err != nil {
 			continue
 		}
 
-		exportBlock := ExportBlock{
-			BlockID:    fullBlock.BlockID,
-			TopicLabel: fullBlock.TopicLabel,
-			Status:     string(fullBlock.Status),
-			Keywords:   fullBlock.Keywords,
-			Summary:    fullBlock.Summary,
-			CreatedAt:  fullBlock.CreatedAt.Format(time.RFC3339),
-			Turns:      make([]ExportTurn, 0, len(fullBlock.Turns)),
-		}
-
-		for _, turn := range fullBlock.Turns {
-			exportBlock.Turns = append(exportBlock.Turns, ExportTurn{
-				TurnID:      turn.TurnID,
-				UserMessage: turn.UserMessage,
-				AIResponse:  turn.AIResponse,
-				Timestamp:   turn.Timestamp.Format(time.RFC3339),
-			})
+		if err := visit.block(s.exportBlock(fullBlock, blockTags[fullBlock.BlockID], blockAttachments[fullBlock.BlockID])); err != nil {
+			return err
 		}
-
-		data.Blocks = append(data.Blocks, exportBlock)
 	}
 
-	// Export facts (without block reference for orphaned facts)
+	// Export facts (without block reference for orphaned facts); facts awaiting review are left out
 	allFacts := []ExportFact{}
+	var factBlockIDs []string
 	rows, err := s.db.Query(`
-		SELECT id, key, value, confidence, created_at
+		SELECT id, block_id, key, value, value_type, confidence, created_at,
+			source_model, prompt_version, source_quote, extracted_by, agent
 		FROM facts
+		WHERE NOT pending
 		ORDER BY created_at DESC
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query facts: %w", err)
+		return fmt.Errorf("failed to query facts: %w", err)
 	}
 	defer func() { _ = rows.Close() }()
 
 	for rows.Next() {
 		var fact ExportFact
+		var blockID, sourceModel, promptVersion, sourceQuote, extractedBy, agent sql.NullString
 		var createdAt time.Time
-		if err := rows.Scan(&fact.FactID, &fact.Key, &fact.Value, &fact.Confidence, &createdAt); err != nil {
+		if err := rows.Scan(&fact.FactID, &blockID, &fact.Key, &fact.Value, &fact.ValueType, &fact.Confidence, &createdAt,
+			&sourceModel, &promptVersion, &sourceQuote, &extractedBy, &agent); err != nil {
+			continue
+		}
+		fact.SourceModel = sourceModel.String
+		fact.PromptVersion = promptVersion.String
+		fact.SourceQuote = sourceQuote.String
+		fact.ExtractedBy = extractedBy.String
+		fact.Agent = agent.String
+		if fact.ValueType == string(models.ValueTypeSecret) {
+			fact.Value = s.exportSecret(fact.FactID, fact.Value, opts.RevealSecrets)
+		}
+		if selectedFacts != nil && !selectedFacts[fact.FactID] && !selectedBlocks[blockID.String] {
 			continue
 		}
-		fact.CreatedAt = createdAt.Format(time.RFC3339)
+		fact.CreatedAt = createdAt.In(s.location()).Format(time.RFC3339)
 		allFacts = append(allFacts, fact)
+		factBlockIDs = append(factBlockIDs, blockID.String)
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("failed to read facts: %w", err)
 	}
-	data.Facts = allFacts
 
-	return data, nil
+	factIDs := make([]string, len(allFacts))
+	for i, fact := range allFacts {
+		factIDs[i] = fact.FactID
+	}
+	factTags, err := s.tags.ForFacts(factIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get fact tags: %w", err)
+	}
+	for i, fact := range allFacts {
+		fact.Tags = factTags[fact.FactID]
+		if err := visit.fact(fact, factBlockIDs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportBlock converts a block read with its turns, and its tags and attachments, to
+// the form exports write
+func (s *Storage) exportBlock(block *models.BridgeBlock, tags []string, attachments []models.Attachment) ExportBlock {
+	exported := ExportBlock{
+		BlockID:    block.BlockID,
+		TopicLabel: block.TopicLabel,
+		Status:     string(block.Status),
+		Keywords:   block.Keywords,
+		Summary:    block.Summary,
+		Tags:       tags,
+		Retention:  block.Retention,
+		CreatedAt:  block.CreatedAt.In(s.location()).Format(time.RFC3339),
+		Turns:      make([]ExportTurn, 0, len(block.Turns)),
+	}
+
+	turnAttachments := make(map[string][]ExportAttachment)
+	for _, a := range attachments {
+		turnAttachments[a.TurnID] = append(turnAttachments[a.TurnID], ExportAttachment{
+			AttachmentID: a.AttachmentID,
+			Kind:         string(a.Kind),
+			Name:         a.Name,
+			Ref:          a.Ref,
+			MimeType:     a.MimeType,
+			Content:      a.Content,
+		})
+	}
+
+	for _, turn := range block.Turns {
+		var origin *models.Origin
+		if !turn.Origin.IsZero() {
+			origin = &turn.Origin
+		}
+		exported.Turns = append(exported.Turns, ExportTurn{
+			TurnID:      turn.TurnID,
+			UserMessage: turn.UserMessage,
+			AIResponse:  turn.AIResponse,
+			Timestamp:   turn.Timestamp.In(s.location()).Format(time.RFC3339),
+			Affect:      string(turn.Affect),
+			Messages:    turn.Messages,
+			Origin:      origin,
+			Attachments: turnAttachments[turn.TurnID],
+		})
+	}
+	return exported
+}
+
+// exportSecret masks a stored secret value, or decrypts it when reveal is set
+func (s *Storage) exportSecret(factID, stored string, reveal bool) string {
+	if !reveal {
+		return SecretMask
+	}
+	value, err := s.db.openSecret(factID, stored)
+	if err != nil {
+		log.Printf("[Export] %v", err)
+		return unreadableSecret
+	}
+	return value
 }
 
-// ExportToYAML exports data to a YAML file
-func (s *Storage) ExportToYAML(outputPath string) error {
-	data, err := s.Export()
+// ExportToYAML exports the data selected by opts to a YAML file, with a
+// manifest of section checksums that LoadExport verifies
+func (s *Storage) ExportToYAML(outputPath string, opts ExportOptions) error {
+	data, err := s.ExportWithOptions(opts)
 	if err != nil {
 		return err
 	}
+	if data.Manifest, err = newExportManifest(data, opts.Signer); err != nil {
+		return err
+	}
+	return writeExportYAML(outputPath, data)
+}
 
+// writeExportYAML writes data to a YAML file at outputPath
+func writeExportYAML(outputPath string, data *ExportData) error {
 	// Ensure directory exists
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
@@ -166,9 +383,9 @@ func (s *Storage) ExportToYAML(outputPath string) error {
 	return nil
 }
 
-// ExportToMarkdown exports data to a Markdown file
-func (s *Storage) ExportToMarkdown(outputPath string) error {
-	data, err := s.Export()
+// ExportToMarkdown exports the data selected by opts to a Markdown file
+func (s *Storage) ExportToMarkdown(outputPath string, opts ExportOptions) error {
+	data, err := s.ExportWithOptions(opts)
 	if err != nil {
 		return err
 	}
@@ -185,8 +402,11 @@ func (s *Storage) ExportToMarkdown(outputPath string) error {
 	defer func() { _ = file.Close() }()
 
 	// Write header
-	_, _ = fmt.Fprintf(file, "# Memory Export - %s\n\n", time.Now().Format("2006-01-02"))
+	_, _ = fmt.Fprintf(file, "# Memory Export - %s\n\n", s.db.now().In(s.location()).Format("2006-01-02"))
 	_, _ = fmt.Fprintf(file, "Generated: %s\n\n", data.ExportedAt)
+	if len(data.Tags) > 0 {
+		_, _ = fmt.Fprintf(file, "Tags: %s\n\n", strings.Join(data.Tags, ", "))
+	}
 
 	// Write profile
 	if data.Profile != nil {
@@ -205,6 +425,21 @@ func (s *Storage) ExportToMarkdown(outputPath string) error {
 				_, _ = fmt.Fprintf(file, "  - %s\n", topic)
 			}
 		}
+		if len(data.Profile.Constraints) > 0 {
+			_, _ = fmt.Fprintln(file, "- **Constraints:**")
+			for _, c := range data.Profile.Constraints {
+				_, _ = fmt.Fprintf(file, "  - %s\n", c)
+			}
+		}
+		for _, p := range data.Profile.Personas {
+			_, _ = fmt.Fprintf(file, "- **Persona %s:**\n", p.Name)
+			for _, pref := range p.Preferences {
+				_, _ = fmt.Fprintf(file, "  - %s\n", pref)
+			}
+			for _, c := range p.Constraints {
+				_, _ = fmt.Fprintf(file, "  - %s\n", c)
+			}
+		}
 		_, _ = fmt.Fprintln(file)
 	}
 
@@ -212,10 +447,10 @@ func (s *Storage) ExportToMarkdown(outputPath string) error {
 	if len(data.Facts) > 0 {
 		_, _ = fmt.Fprintln(file, "## Facts")
 		_, _ = fmt.Fprintln(file)
-		_, _ = fmt.Fprintln(file, "| Key | Value | Confidence |")
-		_, _ = fmt.Fprintln(file, "|-----|-------|------------|")
+		_, _ = fmt.Fprintln(file, "| Key | Value | Confidence | Source |")
+		_, _ = fmt.Fprintln(file, "|-----|-------|------------|--------|")
 		for _, fact := range data.Facts {
-			_, _ = fmt.Fprintf(file, "| %s | %s | %.2f |\n", fact.Key, fact.Value, fact.Confidence)
+			_, _ = fmt.Fprintf(file, "| %s | %s | %.2f | %s |\n", fact.Key, fact.Value, fact.Confidence, factSource(fact))
 		}
 		_, _ = fmt.Fprintln(file)
 	}
@@ -229,10 +464,32 @@ func (s *Storage) ExportToMarkdown(outputPath string) error {
 			if len(block.Keywords) > 0 {
 				_, _ = fmt.Fprintf(file, "*Keywords: %s*\n\n", formatKeywords(block.Keywords))
 			}
+			if len(block.Tags) > 0 {
+				_, _ = fmt.Fprintf(file, "*Tags: %s*\n\n", formatKeywords(block.Tags))
+			}
+			if block.Retention != "" {
+				_, _ = fmt.Fprintf(file, "*Retention: %s*\n\n", block.Retention)
+			}
 			for _, turn := range block.Turns {
-				_, _ = fmt.Fprintf(file, "**User:** %s\n\n", turn.UserMessage)
-				if turn.AIResponse != "" {
-					_, _ = fmt.Fprintf(file, "**AI:** %s\n\n", turn.AIResponse)
+				if len(turn.Messages) > 0 {
+					for _, m := range turn.Messages {
+						_, _ = fmt.Fprintf(file, "**%s:** %s\n\n", m.Speaker(), m.Body())
+					}
+				} else {
+					_, _ = fmt.Fprintf(file, "**User:** %s\n\n", turn.UserMessage)
+					if turn.AIResponse != "" {
+						_, _ = fmt.Fprintf(file, "**AI:** %s\n\n", turn.AIResponse)
+					}
+				}
+				for _, a := range turn.Attachments {
+					if a.Ref != "" && a.Ref != a.Name {
+						_, _ = fmt.Fprintf(file, "*Attachment: %s (%s)*\n\n", a.Name, a.Ref)
+					} else {
+						_, _ = fmt.Fprintf(file, "*Attachment: %s*\n\n", a.Name)
+					}
+				}
+				if turn.Origin != nil && turn.Origin.Agent != "" {
+					_, _ = fmt.Fprintf(file, "*Agent: %s*\n\n", turn.Origin.Agent)
 				}
 			}
 			_, _ = fmt.Fprintln(file, "---")
@@ -243,10 +500,20 @@ func (s *Storage) ExportToMarkdown(outputPath string) error {
 	return nil
 }
 
+// ExportEmbedding is one embedding in the file ExportEmbeddingsToJSON writes
+type ExportEmbedding struct {
+	ChunkID   string    `json:"chunk_id"`
+	TurnID    string    `json:"turn_id"`
+	BlockID   string    `json:"block_id"`
+	Model     string    `json:"model,omitempty"`
+	Vector    []float64 `json:"vector"`
+	CreatedAt string    `json:"created_at"`
+}
+
 // ExportEmbeddingsToJSON exports embeddings to a separate JSON file
 func (s *Storage) ExportEmbeddingsToJSON(outputPath string) error {
 	rows, err := s.db.Query(`
-		SELECT chunk_id, turn_id, block_id, vector, created_at
+		SELECT chunk_id, turn_id, block_id, model, vector, format, created_at
 		FROM embeddings
 	`)
 	if err != nil {
@@ -254,26 +521,21 @@ func (s *Storage) ExportEmbeddingsToJSON(outputPath string) error {
 	}
 	defer func() { _ = rows.Close() }()
 
-	type EmbeddingExport struct {
-		ChunkID   string    `json:"chunk_id"`
-		TurnID    string    `json:"turn_id"`
-		BlockID   string    `json:"block_id"`
-		Vector    []float64 `json:"vector"`
-		CreatedAt string    `json:"created_at"`
-	}
-
-	var embeddings []EmbeddingExport
+	var embeddings []ExportEmbedding
 	for rows.Next() {
 		var (
-			emb       EmbeddingExport
+			emb       ExportEmbedding
+			model     sql.NullString
 			blob      []byte
+			format    sql.NullString
 			createdAt time.Time
 		)
-		if err := rows.Scan(&emb.ChunkID, &emb.TurnID, &emb.BlockID, &blob, &createdAt); err != nil {
+		if err := rows.Scan(&emb.ChunkID, &emb.TurnID, &emb.BlockID, &model, &blob, &format, &createdAt); err != nil {
 			continue
 		}
-		emb.Vector = blobToVector(blob)
-		emb.CreatedAt = createdAt.Format(time.RFC3339)
+		emb.Model = model.String
+		emb.Vector = decodeVector(vectorFormatOf(format), blob)
+		emb.CreatedAt = createdAt.In(s.location()).Format(time.RFC3339)
 		embeddings = append(embeddings, emb)
 	}
 
@@ -297,6 +559,25 @@ func (s *Storage) ExportEmbeddingsToJSON(outputPath string) error {
 	return nil
 }
 
+// factSource describes where a fact came from, e.g. "fact_scrubber (gpt-4o-mini)", or
+// "claude-code via fact_scrubber (gpt-4o-mini)" when the agent it belongs to is known
+func factSource(fact ExportFact) string {
+	if fact.Agent != "" && fact.Agent != fact.ExtractedBy {
+		if source := factSource(ExportFact{ExtractedBy: fact.ExtractedBy, SourceModel: fact.SourceModel}); source != "" {
+			return fact.Agent + " via " + source
+		}
+		return fact.Agent
+	}
+	switch {
+	case fact.ExtractedBy != "" && fact.SourceModel != "":
+		return fmt.Sprintf("%s (%s)", fact.ExtractedBy, fact.SourceModel)
+	case fact.ExtractedBy != "":
+		return fact.ExtractedBy
+	default:
+		return fact.SourceModel
+	}
+}
+
 func formatKeywords(keywords []string) string {
 	result := ""
 	for i, kw := range keywords {
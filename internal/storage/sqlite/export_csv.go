@@ -0,0 +1,168 @@
+// ABOUTME: Analysis export: facts and turns as CSV, embeddings as Parquet
+// ABOUTME: Writes one flat table per file, readable by pandas, DuckDB, or a spreadsheet
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Files written by ExportToCSV
+const (
+	CSVFactsFile          = "facts.csv"
+	CSVTurnsFile          = "turns.csv"
+	ParquetEmbeddingsFile = "embeddings.parquet"
+)
+
+// parquetEmbedding is a row of embeddings.parquet
+type parquetEmbedding struct {
+	ChunkID    string    `parquet:"chunk_id"`
+	TurnID     string    `parquet:"turn_id"`
+	BlockID    string    `parquet:"block_id"`
+	Model      string    `parquet:"model"`
+	Dimensions int32     `parquet:"dimensions"`
+	Vector     []float64 `parquet:"vector,list"`
+	CreatedAt  time.Time `parquet:"created_at,timestamp(millisecond)"`
+}
+
+// parquetBatch is how many embeddings are buffered before each Parquet write
+const parquetBatch = 256
+
+// ExportToCSV writes the data opts selects to dir as facts.csv, turns.csv, and
+// embeddings.parquet, returning the paths written. Lists such as tags are joined
+// with "; ". Embeddings go to Parquet, whose vector column is a list of doubles
+// that DuckDB reads as DOUBLE[] and pandas as arrays, with no text to parse.
+func (s *Storage) ExportToCSV(dir string, opts ExportOptions) ([]string, error) {
+	data, err := s.ExportWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	factBlocks, err := s.factBlocks()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	facts := [][]string{{"fact_id", "key", "value", "value_type", "confidence", "block_id", "tags", "created_at",
+		"agent", "extracted_by", "source_model", "prompt_version", "source_quote"}}
+	for _, f := range data.Facts {
+		facts = append(facts, []string{f.FactID, f.Key, f.Value, f.ValueType, strconv.FormatFloat(f.Confidence, 'g', -1, 64),
+			factBlocks[f.FactID], strings.Join(f.Tags, "; "), f.CreatedAt, f.Agent, f.ExtractedBy, f.SourceModel, f.PromptVersion, f.SourceQuote})
+	}
+
+	turns := [][]string{{"turn_id", "block_id", "topic_label", "timestamp", "user_message", "ai_response", "affect",
+		"device", "hostname", "working_dir", "git_repo", "location", "agent"}}
+	selected := make(map[string]bool, len(data.Blocks))
+	for _, block := range data.Blocks {
+		selected[block.BlockID] = true
+		for _, t := range block.Turns {
+			row := []string{t.TurnID, block.BlockID, block.TopicLabel, t.Timestamp, t.UserMessage, t.AIResponse, t.Affect}
+			if t.Origin != nil {
+				row = append(row, t.Origin.Device, t.Origin.Hostname, t.Origin.WorkingDir, t.Origin.GitRepo, t.Origin.Location, t.Origin.Agent)
+			} else {
+				row = append(row, "", "", "", "", "", "")
+			}
+			turns = append(turns, row)
+		}
+	}
+
+	paths := []string{filepath.Join(dir, CSVFactsFile), filepath.Join(dir, CSVTurnsFile), filepath.Join(dir, ParquetEmbeddingsFile)}
+	if err := writeCSV(paths[0], facts); err != nil {
+		return nil, err
+	}
+	if err := writeCSV(paths[1], turns); err != nil {
+		return nil, err
+	}
+	if err := s.writeEmbeddingsParquet(paths[2], selected, len(opts.Tags) > 0); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// writeEmbeddingsParquet writes every embedding, or only those of the selected blocks
+// when filtered, one row per chunk, to path as zstd-compressed Parquet
+func (s *Storage) writeEmbeddingsParquet(path string, selected map[string]bool, filtered bool) error {
+	rows, err := s.db.Query(`
+		SELECT chunk_id, turn_id, block_id, model, vector, format, created_at
+		FROM embeddings
+		ORDER BY block_id, created_at, chunk_id
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query embeddings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	file, err := os.Create(path) // #nosec G304
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	w := parquet.NewGenericWriter[parquetEmbedding](file, parquet.Compression(&parquet.Zstd))
+	fail := func(err error) error {
+		_ = file.Close()
+		return err
+	}
+	batch := make([]parquetEmbedding, 0, parquetBatch)
+	flush := func() error {
+		if _, err := w.Write(batch); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for rows.Next() {
+		var (
+			chunkID, turnID, blockID, model, format sql.NullString
+			blob                                    []byte
+			createdAt                               time.Time
+		)
+		if err := rows.Scan(&chunkID, &turnID, &blockID, &model, &blob, &format, &createdAt); err != nil {
+			return fail(fmt.Errorf("failed to read embedding: %w", err))
+		}
+		if filtered && !selected[blockID.String] {
+			continue
+		}
+		vector := decodeVector(vectorFormatOf(format), blob)
+		batch = append(batch, parquetEmbedding{ChunkID: chunkID.String, TurnID: turnID.String, BlockID: blockID.String,
+			Model: model.String, Dimensions: int32(len(vector)), Vector: vector, CreatedAt: createdAt.UTC()}) // #nosec G115 -- dimensions are far below 2^31
+		if len(batch) == parquetBatch {
+			if err := flush(); err != nil {
+				return fail(err)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fail(fmt.Errorf("failed to read embeddings: %w", err))
+	}
+	if err := flush(); err != nil {
+		return fail(err)
+	}
+	if err := w.Close(); err != nil {
+		return fail(fmt.Errorf("failed to write %s: %w", filepath.Base(path), err))
+	}
+	return file.Close()
+}
+
+// writeCSV writes records to path, replacing any file already there
+func writeCSV(path string, records [][]string) error {
+	file, err := os.Create(path) // #nosec G304
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	w := csv.NewWriter(file)
+	if err := w.WriteAll(records); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,134 @@
+// ABOUTME: Tests for the analysis export
+// ABOUTME: Verifies the facts and turns CSV tables, the embeddings Parquet file, secret masking, and tag filtering
+
+package sqlite
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func readCSV(t *testing.T, path string) []map[string]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening %s: %v", path, err)
+	}
+	defer func() { _ = f.Close() }()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	var rows []map[string]string
+	for _, record := range records[1:] {
+		row := map[string]string{}
+		for i, column := range records[0] {
+			row[column] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestExportToCSV(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	work, err := store.StoreTurn(&models.Turn{TurnID: "turn_work", Timestamp: time.Now(), UserMessage: "Ship it, \"carefully\"\nplease",
+		AIResponse: "Will do.", Topics: []string{"Release"}, Origin: models.Origin{Device: "laptop"}})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	home, err := store.StoreTurn(&models.Turn{TurnID: "turn_home", Timestamp: time.Now(), UserMessage: "Buy milk"})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if _, err := store.TagBlock(work, []string{"work"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_owner", BlockID: work, Key: "owner", Value: "Ana", Confidence: 0.75,
+		CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_pin", Key: "pin", Value: "1234", ValueType: models.ValueTypeSecret,
+		Confidence: 1, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	vector := make([]float64, ExpectedDimension)
+	vector[0], vector[1] = 0.1, -2.5e-7
+	vectors := store.GetVectorStorage()
+	if err := vectors.SaveWithModel("chunk_work", "turn_work", work, "text-embedding-3-small", vector); err != nil {
+		t.Fatal(err)
+	}
+	if err := vectors.SaveWithModel("chunk_home", "turn_home", home, "text-embedding-3-small", vector); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "csv")
+	paths, err := store.ExportToCSV(dir, ExportOptions{})
+	if err != nil {
+		t.Fatalf("ExportToCSV() error = %v", err)
+	}
+	if len(paths) != 3 {
+		t.Fatalf("ExportToCSV() wrote %v, want three files", paths)
+	}
+
+	facts := readCSV(t, filepath.Join(dir, CSVFactsFile))
+	byKey := map[string]map[string]string{}
+	for _, f := range facts {
+		byKey[f["key"]] = f
+	}
+	if owner := byKey["owner"]; owner["value"] != "Ana" || owner["confidence"] != "0.75" || owner["block_id"] != work {
+		t.Errorf("owner fact row = %v", owner)
+	}
+	if byKey["pin"]["value"] != SecretMask {
+		t.Errorf("secret fact exported as %q, want it masked", byKey["pin"]["value"])
+	}
+
+	turns := readCSV(t, filepath.Join(dir, CSVTurnsFile))
+	if len(turns) != 2 {
+		t.Fatalf("turns.csv has %d rows, want 2", len(turns))
+	}
+	for _, turn := range turns {
+		if turn["turn_id"] == "turn_work" && (turn["user_message"] != "Ship it, \"carefully\"\nplease" ||
+			turn["topic_label"] != "Release" || turn["device"] != "laptop" || turn["block_id"] != work) {
+			t.Errorf("work turn row = %v", turn)
+		}
+	}
+
+	embeddings, err := parquet.ReadFile[parquetEmbedding](filepath.Join(dir, ParquetEmbeddingsFile))
+	if err != nil {
+		t.Fatalf("reading embeddings.parquet: %v", err)
+	}
+	if len(embeddings) != 2 || embeddings[0].Dimensions != ExpectedDimension || embeddings[0].Model != "text-embedding-3-small" || embeddings[0].CreatedAt.IsZero() {
+		t.Fatalf("embeddings.parquet = %d rows, first %+v", len(embeddings), embeddings[0].ChunkID)
+	}
+	if decoded := embeddings[0].Vector; len(decoded) != ExpectedDimension || decoded[0] != 0.1 || decoded[1] != -2.5e-7 {
+		t.Errorf("vector did not round-trip: %v", decoded[:2])
+	}
+
+	// A tag limits every table to the selected topics
+	tagged := filepath.Join(t.TempDir(), "tagged")
+	if _, err := store.ExportToCSV(tagged, ExportOptions{Tags: []string{"work"}}); err != nil {
+		t.Fatalf("ExportToCSV() with a tag error = %v", err)
+	}
+	if turns := readCSV(t, filepath.Join(tagged, CSVTurnsFile)); len(turns) != 1 || turns[0]["turn_id"] != "turn_work" {
+		t.Errorf("tagged turns.csv = %v", turns)
+	}
+	if facts := readCSV(t, filepath.Join(tagged, CSVFactsFile)); len(facts) != 1 || facts[0]["key"] != "owner" {
+		t.Errorf("tagged facts.csv = %v", facts)
+	}
+	if embeddings, err := parquet.ReadFile[parquetEmbedding](filepath.Join(tagged, ParquetEmbeddingsFile)); err != nil || len(embeddings) != 1 || embeddings[0].ChunkID != "chunk_work" {
+		t.Errorf("tagged embeddings.parquet has %d rows, %v; want the work chunk only", len(embeddings), err)
+	}
+}
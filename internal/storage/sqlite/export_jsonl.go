@@ -0,0 +1,103 @@
+// ABOUTME: JSON Lines export: one typed JSON object per line for jq, Python, and other pipelines
+// ABOUTME: Writes the header, profile, each block followed by its turns, then facts, without building one document
+package sqlite
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Record types of a JSON Lines export, the "type" field of each line
+const (
+	JSONLHeader  = "header"  // First line: version, export time, tool, and the tags selected
+	JSONLProfile = "profile" // The shared profile with its personas
+	JSONLBlock   = "block"   // A block without its turns; "turns" counts the turn lines after it
+	JSONLTurn    = "turn"    // A turn, with the block_id of the block it belongs to
+	JSONLFact    = "fact"    // A fact, with the block_id it was learned in when there is one
+)
+
+// jsonlHeader is the first line of a JSON Lines export; the profile, blocks, and facts
+// follow on their own lines
+type jsonlHeader struct {
+	Type string `json:"type"`
+	*ExportData
+}
+
+// jsonlProfile is the profile line of a JSON Lines export
+type jsonlProfile struct {
+	Type string `json:"type"`
+	*ExportProfile
+}
+
+// jsonlBlock is a block line of a JSON Lines export, its turns replaced by their count
+type jsonlBlock struct {
+	Type string `json:"type"`
+	ExportBlock
+	Turns int `json:"turns"`
+}
+
+// jsonlTurn is a turn line of a JSON Lines export
+type jsonlTurn struct {
+	Type    string `json:"type"`
+	BlockID string `json:"block_id"`
+	ExportTurn
+}
+
+// jsonlFact is a fact line of a JSON Lines export
+type jsonlFact struct {
+	Type string `json:"type"`
+	ExportFact
+	BlockID string `json:"block_id,omitempty"`
+}
+
+// ExportToJSONL writes the data opts selects to w as JSON Lines: a header, the profile,
+// each block followed by its turns, then the facts. Blocks are read one at a time, so
+// the export starts at once and memory use stays flat however many turns there are.
+func (s *Storage) ExportToJSONL(w io.Writer, opts ExportOptions) error {
+	out := bufio.NewWriter(w)
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	write := func(record any) error {
+		if err := enc.Encode(record); err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+		return nil
+	}
+
+	err := s.walkExport(opts, exportVisitor{
+		header: func(header *ExportData) error {
+			profile := header.Profile
+			header.Profile = nil
+			if err := write(jsonlHeader{Type: JSONLHeader, ExportData: header}); err != nil {
+				return err
+			}
+			if profile == nil {
+				return nil
+			}
+			return write(jsonlProfile{Type: JSONLProfile, ExportProfile: profile})
+		},
+		block: func(block ExportBlock) error {
+			if err := write(jsonlBlock{Type: JSONLBlock, ExportBlock: block, Turns: len(block.Turns)}); err != nil {
+				return err
+			}
+			for _, turn := range block.Turns {
+				if err := write(jsonlTurn{Type: JSONLTurn, BlockID: block.BlockID, ExportTurn: turn}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		fact: func(fact ExportFact, blockID string) error {
+			return write(jsonlFact{Type: JSONLFact, ExportFact: fact, BlockID: blockID})
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
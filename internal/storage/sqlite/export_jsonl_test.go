@@ -0,0 +1,124 @@
+// ABOUTME: Tests for the JSON Lines export
+// ABOUTME: Verifies record order and types, turns tied to their blocks, secret masking, and tag filtering
+
+package sqlite
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// readJSONL decodes each line of a JSON Lines export
+func readJSONL(t *testing.T, data []byte) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var record map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestExportToJSONL(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	work, err := store.StoreTurn(&models.Turn{TurnID: "turn_work", Timestamp: time.Now(), UserMessage: "Ship it\nplease <now>",
+		AIResponse: "Will do.", Topics: []string{"Release"}})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if err := store.AppendTurnToBlock(work, &models.Turn{TurnID: "turn_work_2", Timestamp: time.Now().Add(time.Second), UserMessage: "Shipped"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_home", Timestamp: time.Now(), UserMessage: "Buy milk"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.TagBlock(work, []string{"work"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_owner", BlockID: work, Key: "owner", Value: "Ana", Confidence: 0.75,
+		CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_pin", Key: "pin", Value: "1234", ValueType: models.ValueTypeSecret,
+		Confidence: 1, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveUserProfile(&models.UserProfile{Name: "Harper", Preferences: []string{"tea"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := store.ExportToJSONL(&buf, ExportOptions{}); err != nil {
+		t.Fatalf("ExportToJSONL() error = %v", err)
+	}
+	records := readJSONL(t, buf.Bytes())
+
+	var types []string
+	counts := map[string]int{}
+	for _, r := range records {
+		types = append(types, r["type"].(string))
+		counts[r["type"].(string)]++
+	}
+	if len(types) < 2 || types[0] != JSONLHeader || types[1] != JSONLProfile || types[len(types)-1] != JSONLFact {
+		t.Fatalf("record types = %v, want the header, the profile, blocks and turns, then facts", types)
+	}
+	if counts[JSONLBlock] != 2 || counts[JSONLTurn] != 3 || counts[JSONLFact] != 2 {
+		t.Errorf("record counts = %v, want 2 blocks, 3 turns, and 2 facts", counts)
+	}
+	if records[0]["version"] != "1.0" || records[0]["profile"] != nil || records[1]["name"] != "Harper" {
+		t.Errorf("header and profile = %v, %v", records[0], records[1])
+	}
+
+	// Each block's turns follow it and name it
+	for i, r := range records {
+		if r["type"] != JSONLBlock || r["block_id"] != work {
+			continue
+		}
+		if r["turns"] != float64(2) || r["topic_label"] != "Release" {
+			t.Errorf("work block record = %v", r)
+		}
+		for _, turn := range records[i+1 : i+3] {
+			if turn["type"] != JSONLTurn || turn["block_id"] != work {
+				t.Errorf("record after the work block = %v, want its turn", turn)
+			}
+		}
+		if records[i+1]["user_message"] != "Ship it\nplease <now>" {
+			t.Errorf("first work turn = %v", records[i+1])
+		}
+	}
+	for _, r := range records {
+		if r["type"] == JSONLFact && r["key"] == "owner" && r["block_id"] != work {
+			t.Errorf("owner fact = %v, want its block", r)
+		}
+		if r["type"] == JSONLFact && r["key"] == "pin" && (r["value"] != SecretMask || r["block_id"] != nil) {
+			t.Errorf("pin fact = %v, want it masked without a block", r)
+		}
+	}
+
+	// A tag limits the export to the selected topics and facts
+	buf.Reset()
+	if err := store.ExportToJSONL(&buf, ExportOptions{Tags: []string{"work"}}); err != nil {
+		t.Fatalf("ExportToJSONL() with a tag error = %v", err)
+	}
+	counts = map[string]int{}
+	for _, r := range readJSONL(t, buf.Bytes()) {
+		counts[r["type"].(string)]++
+	}
+	if counts[JSONLBlock] != 1 || counts[JSONLTurn] != 2 || counts[JSONLFact] != 1 {
+		t.Errorf("tagged record counts = %v, want the work block, its turns, and its fact", counts)
+	}
+}
@@ -0,0 +1,139 @@
+// ABOUTME: Checksum manifest for YAML exports, optionally signed with the local signing key
+// ABOUTME: Verifying an export rehashes each section so edits to the file are caught on load
+package sqlite
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/harper/remember-standalone/internal/signing"
+)
+
+// manifestAlgorithm is the hash recorded in export manifests
+const manifestAlgorithm = "sha256"
+
+// ErrNoManifest is returned when verifying an export that carries no manifest
+var ErrNoManifest = errors.New("export has no manifest")
+
+// ExportManifest holds the SHA-256 of each export section and, for signed
+// exports, an Ed25519 signature over those checksums
+type ExportManifest struct {
+	Algorithm string            `yaml:"algorithm" json:"algorithm"`
+	Sections  map[string]string `yaml:"sections" json:"sections"`
+	PublicKey string            `yaml:"public_key,omitempty" json:"public_key,omitempty"`
+	Signature string            `yaml:"signature,omitempty" json:"signature,omitempty"`
+}
+
+// exportHeader is the part of an export outside its profile, blocks, and facts
+type exportHeader struct {
+	Version    string   `yaml:"version"`
+	ExportedAt string   `yaml:"exported_at"`
+	Tool       string   `yaml:"tool"`
+	Tags       []string `yaml:"tags,omitempty"`
+	Embeddings string   `yaml:"embeddings_file,omitempty"`
+}
+
+// exportSections hashes each section of data. A section is hashed as its YAML
+// encoding, which survives a round trip through the export file unchanged.
+func exportSections(data *ExportData) (map[string]string, error) {
+	sections := map[string]any{
+		"header": exportHeader{
+			Version:    data.Version,
+			ExportedAt: data.ExportedAt,
+			Tool:       data.Tool,
+			Tags:       data.Tags,
+			Embeddings: data.Embeddings,
+		},
+		"profile": data.Profile,
+		"blocks":  data.Blocks,
+		"facts":   data.Facts,
+	}
+
+	sums := make(map[string]string, len(sections))
+	for name, section := range sections {
+		encoded, err := yaml.Marshal(section)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode export %s: %w", name, err)
+		}
+		sum := sha256.Sum256(encoded)
+		sums[name] = hex.EncodeToString(sum[:])
+	}
+	return sums, nil
+}
+
+// newExportManifest checksums data, signing the checksums when key is set
+func newExportManifest(data *ExportData, key *signing.Key) (*ExportManifest, error) {
+	sums, err := exportSections(data)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &ExportManifest{Algorithm: manifestAlgorithm, Sections: sums}
+	if key != nil {
+		manifest.PublicKey = key.PublicKey()
+		manifest.Signature = key.Sign(manifest.signedBytes())
+	}
+	return manifest, nil
+}
+
+// signedBytes is what a manifest's signature covers: the algorithm and each
+// section's checksum, one per line in name order
+func (m *ExportManifest) signedBytes() []byte {
+	names := make([]string, 0, len(m.Sections))
+	for name := range m.Sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(m.Algorithm + "\n")
+	for _, name := range names {
+		b.WriteString(name + "=" + m.Sections[name] + "\n")
+	}
+	return []byte(b.String())
+}
+
+// Signed reports whether the manifest carries a signature
+func (m *ExportManifest) Signed() bool {
+	return m.Signature != ""
+}
+
+// VerifyExport checks every section of data against its manifest checksum and
+// the manifest's signature, if it has one. Exports without a manifest return ErrNoManifest.
+func VerifyExport(data *ExportData) error {
+	manifest := data.Manifest
+	if manifest == nil {
+		return ErrNoManifest
+	}
+	if manifest.Algorithm != manifestAlgorithm {
+		return fmt.Errorf("unsupported manifest algorithm %q", manifest.Algorithm)
+	}
+
+	sums, err := exportSections(data)
+	if err != nil {
+		return err
+	}
+	var mismatched []string
+	for name, sum := range sums {
+		if manifest.Sections[name] != sum {
+			mismatched = append(mismatched, name)
+		}
+	}
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+		return fmt.Errorf("export has been modified: checksum mismatch in %s", strings.Join(mismatched, ", "))
+	}
+
+	if manifest.Signature == "" && manifest.PublicKey == "" {
+		return nil
+	}
+	if err := signing.Verify(manifest.PublicKey, manifest.Signature, manifest.signedBytes()); err != nil {
+		return fmt.Errorf("export signature is not valid: %w", err)
+	}
+	return nil
+}
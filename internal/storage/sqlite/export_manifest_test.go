@@ -0,0 +1,141 @@
+// ABOUTME: Tests for export manifests
+// ABOUTME: Verifies checksums survive the YAML round trip and that edited or re-signed exports are refused
+
+package sqlite
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/signing"
+)
+
+// manifestTestStore holds a turn with a transcript, origin, and attachment, a
+// tagged fact, a secret fact, and a profile, so every export section has content
+func manifestTestStore(t *testing.T) *Storage {
+	t.Helper()
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	turn := &models.Turn{
+		TurnID:      "turn_manifest",
+		Timestamp:   time.Now(),
+		UserMessage: "ship it: yes",
+		AIResponse:  "Shipping.\nDone.",
+		Messages: []models.Message{
+			{Role: models.RoleUser, Content: "ship it: yes"},
+			{Role: models.RoleAssistant, ToolCalls: []models.ToolCall{{ID: "call_1", Name: "deploy", Arguments: `{"env":"prod"}`}}},
+		},
+		Origin: models.Origin{Device: "laptop", WorkingDir: "/src/app"},
+	}
+	if _, err := store.StoreTurn(turn); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	attachment, err := models.NewAttachment("", "notes.md", "# Notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AttachToTurn("turn_manifest", attachment); err != nil {
+		t.Fatalf("AttachToTurn() error = %v", err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_lang", Key: "language", Value: "Go", Confidence: 0.85, Tags: []string{"work"}}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_token", Key: "token", Value: "tok-123456", ValueType: models.ValueTypeSecret, Confidence: 1}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+	if err := store.SaveUserProfile(&models.UserProfile{Name: "Harper", Preferences: []string{"terse answers"}}); err != nil {
+		t.Fatalf("SaveUserProfile() error = %v", err)
+	}
+	return store
+}
+
+func TestExportManifest(t *testing.T) {
+	store := manifestTestStore(t)
+	path := filepath.Join(t.TempDir(), "export.yaml")
+	if err := store.ExportToYAML(path, ExportOptions{}); err != nil {
+		t.Fatalf("ExportToYAML() error = %v", err)
+	}
+
+	loaded, err := LoadExport(path)
+	if err != nil {
+		t.Fatalf("LoadExport() error = %v", err)
+	}
+	if loaded.Manifest == nil || len(loaded.Manifest.Sections) != 4 {
+		t.Fatalf("Manifest = %+v, want checksums for header, profile, blocks, and facts", loaded.Manifest)
+	}
+	if loaded.Manifest.Signed() {
+		t.Error("unsigned export has a signature")
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := filepath.Join(t.TempDir(), "tampered.yaml")
+	if err := os.WriteFile(tampered, []byte(strings.Replace(string(raw), "value: Go", "value: Rust", 1)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadExport(tampered)
+	if err == nil || !strings.Contains(err.Error(), "facts") {
+		t.Errorf("LoadExport(tampered) error = %v, want a checksum mismatch in facts", err)
+	}
+
+	// Exports written before manifests existed still load
+	data, err := store.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyExport(data); !errors.Is(err, ErrNoManifest) {
+		t.Errorf("VerifyExport(no manifest) error = %v, want ErrNoManifest", err)
+	}
+}
+
+func TestExportManifest_Signed(t *testing.T) {
+	store := manifestTestStore(t)
+	key, err := signing.LoadOrCreate(filepath.Join(t.TempDir(), signing.FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "export.yaml")
+	if err := store.ExportToYAML(path, ExportOptions{Signer: key}); err != nil {
+		t.Fatalf("ExportToYAML() error = %v", err)
+	}
+
+	loaded, err := LoadExport(path)
+	if err != nil {
+		t.Fatalf("LoadExport() error = %v", err)
+	}
+	if !loaded.Manifest.Signed() || loaded.Manifest.PublicKey != key.PublicKey() {
+		t.Errorf("Manifest = %+v, want it signed by the export key", loaded.Manifest)
+	}
+
+	// Editing a section and its checksum together still breaks the signature
+	loaded.Facts[0].Value = "edited"
+	sums, err := exportSections(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.Manifest.Sections = sums
+	if err := VerifyExport(loaded); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("VerifyExport(re-checksummed) error = %v, want an invalid signature", err)
+	}
+
+	// So does signing the edit with a different key but keeping the original public key
+	other, err := signing.LoadOrCreate(filepath.Join(t.TempDir(), signing.FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.Manifest.Signature = other.Sign(loaded.Manifest.signedBytes())
+	if err := VerifyExport(loaded); err == nil {
+		t.Error("VerifyExport() accepted a signature from a different key")
+	}
+}
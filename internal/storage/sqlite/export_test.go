@@ -27,6 +27,7 @@ func TestExport(t *testing.T) {
 		TopicsOfInterest: []string{"Go", "SQLite"},
 	}
 	_ = store.SaveUserProfile(profile)
+	_ = store.SavePersona(&models.Persona{Name: "work", Preferences: []string{"formal tone"}})
 
 	turn := &models.Turn{
 		TurnID:      "turn_export_1",
@@ -64,6 +65,9 @@ func TestExport(t *testing.T) {
 	if data.Profile.Name != "Doctor Biz" {
 		t.Errorf("Profile.Name = %v, want Doctor Biz", data.Profile.Name)
 	}
+	if len(data.Profile.Personas) != 1 || data.Profile.Personas[0].Name != "work" {
+		t.Errorf("Profile.Personas = %+v, want the work persona", data.Profile.Personas)
+	}
 	if len(data.Blocks) != 1 {
 		t.Errorf("Blocks count = %v, want 1", len(data.Blocks))
 	}
@@ -87,7 +91,7 @@ func TestExportToYAML(t *testing.T) {
 	tempDir := t.TempDir()
 	outputPath := filepath.Join(tempDir, "export.yaml")
 
-	err = store.ExportToYAML(outputPath)
+	err = store.ExportToYAML(outputPath, ExportOptions{})
 	if err != nil {
 		t.Fatalf("ExportToYAML() error = %v", err)
 	}
@@ -144,7 +148,7 @@ func TestExportToMarkdown(t *testing.T) {
 	tempDir := t.TempDir()
 	outputPath := filepath.Join(tempDir, "export.md")
 
-	err = store.ExportToMarkdown(outputPath)
+	err = store.ExportToMarkdown(outputPath, ExportOptions{})
 	if err != nil {
 		t.Fatalf("ExportToMarkdown() error = %v", err)
 	}
@@ -307,7 +311,7 @@ func TestExportToMarkdown_WithKeywords(t *testing.T) {
 	tempDir := t.TempDir()
 	outputPath := filepath.Join(tempDir, "export_kw.md")
 
-	err = store.ExportToMarkdown(outputPath)
+	err = store.ExportToMarkdown(outputPath, ExportOptions{})
 	if err != nil {
 		t.Fatalf("ExportToMarkdown() error = %v", err)
 	}
@@ -341,7 +345,7 @@ func TestExportToMarkdown_WithTopicsOfInterest(t *testing.T) {
 	tempDir := t.TempDir()
 	outputPath := filepath.Join(tempDir, "export_topics.md")
 
-	err = store.ExportToMarkdown(outputPath)
+	err = store.ExportToMarkdown(outputPath, ExportOptions{})
 	if err != nil {
 		t.Fatalf("ExportToMarkdown() error = %v", err)
 	}
@@ -367,7 +371,7 @@ func TestExportToYAML_NestedDir(t *testing.T) {
 	tempDir := t.TempDir()
 	outputPath := filepath.Join(tempDir, "nested", "deep", "export.yaml")
 
-	err = store.ExportToYAML(outputPath)
+	err = store.ExportToYAML(outputPath, ExportOptions{})
 	if err != nil {
 		t.Fatalf("ExportToYAML() to nested dir error = %v", err)
 	}
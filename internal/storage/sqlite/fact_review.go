@@ -0,0 +1,101 @@
+// ABOUTME: Review queue for LLM-extracted facts: with fact review on they are saved pending
+// ABOUTME: Pending facts stay out of lookups, searches, and context until approved; rejecting deletes them
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// ListPending returns the facts awaiting review, oldest first
+func (s *FactStore) ListPending() ([]models.Fact, error) {
+	rows, err := s.db.Query(`
+		SELECT ` + factColumns + `
+		FROM facts
+		WHERE pending
+		ORDER BY created_at, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending facts: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return s.scanFacts(rows)
+}
+
+// CountPending returns how many facts await review
+func (s *FactStore) CountPending() (int, error) {
+	var n int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM facts WHERE pending").Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count pending facts: %w", err)
+	}
+	return n, nil
+}
+
+// SetFactReview makes facts extracted by an LLM from now on wait for approval in the
+// review queue instead of being recalled straight away
+func (s *Storage) SetFactReview(on bool) {
+	s.reviewFacts = on
+}
+
+// FactReview reports whether extracted facts wait for approval
+func (s *Storage) FactReview() bool {
+	return s.reviewFacts
+}
+
+// PendingFacts returns the facts awaiting review, oldest first
+func (s *Storage) PendingFacts() ([]models.Fact, error) {
+	facts, err := s.facts.ListPending()
+	if err != nil {
+		return nil, err
+	}
+	return facts, s.attachFactTags(facts)
+}
+
+// pendingFact returns the pending fact with factID, or an error if there is none
+func (s *Storage) pendingFact(factID string) (*models.Fact, error) {
+	fact, err := s.facts.GetByID(factID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fact: %w", err)
+	}
+	if fact == nil {
+		return nil, fmt.Errorf("fact %s not found", factID)
+	}
+	if !fact.Pending {
+		return nil, fmt.Errorf("fact %s is not awaiting review", factID)
+	}
+	return fact, nil
+}
+
+// ApproveFact lets a pending fact be recalled, first correcting its value when value is
+// not empty. It counts as saved now for events: approving a new value for a key
+// supersedes the value recalled until then.
+func (s *Storage) ApproveFact(factID, value string) (*models.Fact, error) {
+	fact, err := s.pendingFact(factID)
+	if err != nil {
+		return nil, err
+	}
+	if value != "" && value != fact.Value {
+		fact.Value = value
+		if err := s.facts.Save(fact); err != nil {
+			return nil, err
+		}
+	}
+
+	previous := s.latestFact(fact)
+	if _, err := s.db.Exec("UPDATE facts SET pending = 0 WHERE id = ?", factID); err != nil {
+		return nil, fmt.Errorf("failed to approve fact: %w", err)
+	}
+	fact.Pending = false
+	s.publishFact(fact, previous)
+	return fact, nil
+}
+
+// RejectFact deletes a pending fact
+func (s *Storage) RejectFact(factID string) error {
+	if _, err := s.pendingFact(factID); err != nil {
+		return err
+	}
+	return s.facts.DeleteByID(factID)
+}
@@ -0,0 +1,103 @@
+// ABOUTME: Tests for the fact review queue
+// ABOUTME: Verifies pending facts stay out of recall until approved, and approving, correcting, and rejecting them
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestFactReview(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	rec := &recorder{store: store}
+	store.SetEventPublisher(rec)
+
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_review", UserMessage: "I live in Porto and drive a Saab"})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	now := time.Now()
+	save := func(id, key, value string, pending bool, at time.Time) {
+		t.Helper()
+		fact := &models.Fact{FactID: id, BlockID: blockID, TurnID: "turn_review", Key: key, Value: value,
+			Confidence: 0.9, CreatedAt: at, ExtractedBy: "fact_scrubber", Pending: pending}
+		if err := store.SaveFact(fact); err != nil {
+			t.Fatalf("SaveFact(%s) error = %v", id, err)
+		}
+	}
+	save("fact_city_old", "home_city", "Lisbon", false, now.Add(-time.Hour))
+	rec.events = nil
+	save("fact_city", "home_city", "Porto", true, now)
+	save("fact_car", "car", "Saab", true, now.Add(time.Second))
+	save("fact_pet", "pet", "cat", true, now.Add(2*time.Second))
+
+	if len(rec.events) != 0 {
+		t.Errorf("saving pending facts published %v, want nothing until approved", rec.types())
+	}
+	if fact, err := store.GetFactByKey("home_city"); err != nil || fact == nil || fact.Value != "Lisbon" {
+		t.Errorf("GetFactByKey(home_city) = %+v, %v; want the approved Lisbon", fact, err)
+	}
+	if facts, err := store.SearchFacts("Saab", 5); err != nil || len(facts) != 0 {
+		t.Errorf("SearchFacts(Saab) = %+v, %v; want pending facts left out", facts, err)
+	}
+	if facts, err := store.GetFactsForBlock(blockID); err != nil || len(facts) != 1 {
+		t.Errorf("GetFactsForBlock() = %d facts, %v; want only the approved one", len(facts), err)
+	}
+	if stats, err := store.Stats(5); err != nil || stats.PendingFacts != 3 {
+		t.Errorf("Stats().PendingFacts = %v, %v; want 3", stats, err)
+	}
+
+	pending, err := store.PendingFacts()
+	if err != nil {
+		t.Fatalf("PendingFacts() error = %v", err)
+	}
+	if len(pending) != 3 || pending[0].FactID != "fact_city" || !pending[0].Pending {
+		t.Fatalf("PendingFacts() = %+v, want the three pending facts, oldest first", pending)
+	}
+
+	// Approving a new value for a key supersedes the one recalled until then
+	approved, err := store.ApproveFact("fact_city", "")
+	if err != nil || approved.Pending {
+		t.Fatalf("ApproveFact() = %+v, %v", approved, err)
+	}
+	if fact, _ := store.GetFactByKey("home_city"); fact == nil || fact.Value != "Porto" {
+		t.Errorf("GetFactByKey(home_city) after approval = %+v, want Porto", fact)
+	}
+	if types := rec.types(); len(types) != 2 || types[0] != models.EventFactAdded || types[1] != models.EventFactSuperseded {
+		t.Errorf("approval published %v, want fact_added and fact_superseded", types)
+	}
+
+	// A corrected value is saved before approval
+	if _, err := store.ApproveFact("fact_car", "Volvo"); err != nil {
+		t.Fatalf("ApproveFact(corrected) error = %v", err)
+	}
+	if facts, err := store.SearchFacts("Volvo", 5); err != nil || len(facts) != 1 {
+		t.Errorf("SearchFacts(Volvo) = %+v, %v; want the corrected fact", facts, err)
+	}
+
+	if err := store.RejectFact("fact_pet"); err != nil {
+		t.Fatalf("RejectFact() error = %v", err)
+	}
+	if fact, err := store.facts.GetByID("fact_pet"); err != nil || fact != nil {
+		t.Errorf("rejected fact = %+v, %v; want it deleted", fact, err)
+	}
+
+	if _, err := store.ApproveFact("fact_city", ""); err == nil {
+		t.Error("approving an approved fact succeeded, want an error")
+	}
+	if err := store.RejectFact("fact_city_old"); err == nil {
+		t.Error("rejecting an approved fact succeeded, want an error")
+	}
+	if err := store.RejectFact("fact_missing"); err == nil {
+		t.Error("rejecting a missing fact succeeded, want an error")
+	}
+	if pending, err := store.PendingFacts(); err != nil || len(pending) != 0 {
+		t.Errorf("PendingFacts() = %+v, %v; want the queue empty", pending, err)
+	}
+}
@@ -4,11 +4,18 @@ package sqlite
 
 import (
 	"database/sql"
+	"fmt"
+	"log"
 	"time"
 
 	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/redact"
 )
 
+// factColumns lists the facts columns in the order scanFact reads them
+const factColumns = `id, block_id, turn_id, key, value, value_type, confidence, created_at,
+	source_model, prompt_version, source_quote, extracted_by, agent, exclude_from_recall, pending`
+
 // FactStore handles fact persistence
 type FactStore struct {
 	db *DB
@@ -19,100 +26,97 @@ func NewFactStore(db *DB) *FactStore {
 	return &FactStore{db: db}
 }
 
-// Save saves a fact
+// Save saves a fact, rewriting a typed value into its canonical form.
+// A value that does not parse as its value_type is rejected.
 func (s *FactStore) Save(fact *models.Fact) error {
 	createdAt := fact.CreatedAt
 	if createdAt.IsZero() {
-		createdAt = time.Now()
+		createdAt = s.db.now()
+	}
+
+	valueType, err := models.ParseFactValueType(string(fact.ValueType))
+	if err != nil {
+		return err
+	}
+	value, err := models.NormalizeFactValue(valueType, fact.Value)
+	if err != nil {
+		return fmt.Errorf("fact %s: %w", fact.Key, err)
 	}
+	fact.ValueType, fact.Value = valueType, value
 
-	_, err := s.db.Exec(`
-		INSERT INTO facts (id, block_id, turn_id, key, value, confidence, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+	// Secrets never reach the database in the clear
+	stored := value
+	if valueType == models.ValueTypeSecret {
+		if stored, err = s.db.sealSecret(fact.FactID, value); err != nil {
+			return fmt.Errorf("fact %s: failed to encrypt secret: %w", fact.Key, err)
+		}
+		redact.Register(value)
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO facts (`+factColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			block_id = excluded.block_id,
 			turn_id = excluded.turn_id,
 			key = excluded.key,
 			value = excluded.value,
-			confidence = excluded.confidence
+			value_type = excluded.value_type,
+			confidence = excluded.confidence,
+			source_model = excluded.source_model,
+			prompt_version = excluded.prompt_version,
+			source_quote = excluded.source_quote,
+			extracted_by = excluded.extracted_by,
+			agent = excluded.agent
 	`, fact.FactID, nullString(fact.BlockID), nullString(fact.TurnID),
-		fact.Key, fact.Value, fact.Confidence, createdAt)
+		fact.Key, stored, string(fact.ValueType), fact.Confidence, createdAt,
+		nullString(fact.SourceModel), nullString(fact.PromptVersion),
+		nullString(fact.SourceQuote), nullString(fact.ExtractedBy), nullString(fact.Agent),
+		fact.ExcludeFromRecall, fact.Pending)
 
 	return err
 }
 
 // GetByID retrieves a fact by its ID
 func (s *FactStore) GetByID(factID string) (*models.Fact, error) {
-	var (
-		fact    models.Fact
-		blockID sql.NullString
-		turnID  sql.NullString
-	)
-
-	err := s.db.QueryRow(`
-		SELECT id, block_id, turn_id, key, value, confidence, created_at
+	fact, err := s.scanFact(s.db.QueryRow(`
+		SELECT `+factColumns+`
 		FROM facts
 		WHERE id = ?
-	`, factID).Scan(&fact.FactID, &blockID, &turnID, &fact.Key, &fact.Value,
-		&fact.Confidence, &fact.CreatedAt)
-
+	`, factID))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-
-	if blockID.Valid {
-		fact.BlockID = blockID.String
-	}
-	if turnID.Valid {
-		fact.TurnID = turnID.String
-	}
-
-	return &fact, nil
+	return fact, nil
 }
 
-// GetByKey retrieves the most recent fact with the given key
+// GetByKey retrieves the most recent approved fact with the given key
 func (s *FactStore) GetByKey(key string) (*models.Fact, error) {
-	var (
-		fact    models.Fact
-		blockID sql.NullString
-		turnID  sql.NullString
-	)
-
-	err := s.db.QueryRow(`
-		SELECT id, block_id, turn_id, key, value, confidence, created_at
+	fact, err := s.scanFact(s.db.QueryRow(`
+		SELECT `+factColumns+`
 		FROM facts
-		WHERE key = ?
+		WHERE key = ? AND NOT pending
 		ORDER BY created_at DESC
 		LIMIT 1
-	`, key).Scan(&fact.FactID, &blockID, &turnID, &fact.Key, &fact.Value,
-		&fact.Confidence, &fact.CreatedAt)
-
+	`, key))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-
-	if blockID.Valid {
-		fact.BlockID = blockID.String
-	}
-	if turnID.Valid {
-		fact.TurnID = turnID.String
-	}
-
-	return &fact, nil
+	return fact, nil
 }
 
-// GetByBlock retrieves all facts for a block
+// GetByBlock retrieves all approved facts for a block
 func (s *FactStore) GetByBlock(blockID string) ([]models.Fact, error) {
 	rows, err := s.db.Query(`
-		SELECT id, block_id, turn_id, key, value, confidence, created_at
+		SELECT `+factColumns+`
 		FROM facts
-		WHERE block_id = ?
+		WHERE block_id = ? AND NOT pending
 		ORDER BY created_at DESC
 	`, blockID)
 	if err != nil {
@@ -127,9 +131,9 @@ func (s *FactStore) GetByBlock(blockID string) ([]models.Fact, error) {
 func (s *FactStore) Search(query string, maxResults int) ([]models.Fact, error) {
 	likePattern := "%" + query + "%"
 	rows, err := s.db.Query(`
-		SELECT id, block_id, turn_id, key, value, confidence, created_at
+		SELECT `+factColumns+`
 		FROM facts
-		WHERE key LIKE ? OR value LIKE ?
+		WHERE (key LIKE ? OR value LIKE ?) AND NOT exclude_from_recall AND NOT pending
 		ORDER BY confidence DESC, created_at DESC
 		LIMIT ?
 	`, likePattern, likePattern, maxResults)
@@ -141,6 +145,58 @@ func (s *FactStore) Search(query string, maxResults int) ([]models.Fact, error)
 	return s.scanFacts(rows)
 }
 
+// NumberRange returns number facts whose value is between min and max inclusive,
+// newest first. An empty key matches every key.
+func (s *FactStore) NumberRange(key string, min, max float64) ([]models.Fact, error) {
+	rows, err := s.db.Query(`
+		SELECT `+factColumns+`
+		FROM facts
+		WHERE value_type = 'number' AND (? = '' OR key = ?) AND NOT pending
+			AND CAST(value AS REAL) BETWEEN ? AND ?
+		ORDER BY created_at DESC
+	`, key, key, min, max)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	return s.scanFacts(rows)
+}
+
+// DateRange returns date facts whose value falls in [from, to), newest first.
+// A zero from or to leaves that end open; an empty key matches every key.
+func (s *FactStore) DateRange(key string, from, to time.Time) ([]models.Fact, error) {
+	rows, err := s.db.Query(`
+		SELECT `+factColumns+`
+		FROM facts
+		WHERE value_type = 'date' AND (? = '' OR key = ?) AND NOT pending
+		ORDER BY created_at DESC
+	`, key, key)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	facts, err := s.scanFacts(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// Date values mix date-only and RFC 3339 text, so compare parsed times
+	var matched []models.Fact
+	for _, fact := range facts {
+		t, err := fact.Date()
+		if err != nil {
+			continue
+		}
+		if (!from.IsZero() && t.Before(from)) || (!to.IsZero() && !t.Before(to)) {
+			continue
+		}
+		matched = append(matched, fact)
+	}
+	return matched, nil
+}
+
 // DeleteByID deletes a fact by its ID
 func (s *FactStore) DeleteByID(factID string) error {
 	_, err := s.db.Exec("DELETE FROM facts WHERE id = ?", factID)
@@ -157,33 +213,59 @@ func (s *FactStore) DeleteByKey(key string) (int64, error) {
 }
 
 // scanFacts scans rows into a slice of Fact
-func (s *FactStore) scanFacts(rows *sql.Rows) ([]models.Fact, error) {
+func (s *FactStore) scanFacts(rows *Rows) ([]models.Fact, error) {
 	var facts []models.Fact
 
 	for rows.Next() {
-		var (
-			fact    models.Fact
-			blockID sql.NullString
-			turnID  sql.NullString
-		)
-
-		err := rows.Scan(&fact.FactID, &blockID, &turnID, &fact.Key, &fact.Value,
-			&fact.Confidence, &fact.CreatedAt)
+		fact, err := s.scanFact(rows)
 		if err != nil {
 			return nil, err
 		}
+		facts = append(facts, *fact)
+	}
 
-		if blockID.Valid {
-			fact.BlockID = blockID.String
-		}
-		if turnID.Valid {
-			fact.TurnID = turnID.String
-		}
+	return facts, rows.Err()
+}
+
+// scanFact scans one row selected with factColumns, decrypting a secret value.
+// A secret the key cannot open reads as a placeholder rather than failing the query.
+func (s *FactStore) scanFact(row interface{ Scan(dest ...any) error }) (*models.Fact, error) {
+	var (
+		fact                                    models.Fact
+		valueType                               string
+		blockID, turnID                         sql.NullString
+		sourceModel, promptVersion, sourceQuote sql.NullString
+		extractedBy, agent                      sql.NullString
+	)
 
-		facts = append(facts, fact)
+	err := row.Scan(&fact.FactID, &blockID, &turnID, &fact.Key, &fact.Value, &valueType,
+		&fact.Confidence, &fact.CreatedAt,
+		&sourceModel, &promptVersion, &sourceQuote, &extractedBy, &agent, &fact.ExcludeFromRecall, &fact.Pending)
+	if err != nil {
+		return nil, err
 	}
 
-	return facts, rows.Err()
+	fact.ValueType = models.FactValueType(valueType)
+	fact.BlockID = blockID.String
+	fact.TurnID = turnID.String
+	fact.SourceModel = sourceModel.String
+	fact.PromptVersion = promptVersion.String
+	fact.SourceQuote = sourceQuote.String
+	fact.ExtractedBy = extractedBy.String
+	fact.Agent = agent.String
+
+	if fact.ValueType == models.ValueTypeSecret {
+		value, err := s.db.openSecret(fact.FactID, fact.Value)
+		if err != nil {
+			log.Printf("[Facts] %v", err)
+			value = unreadableSecret
+		} else {
+			redact.Register(value)
+		}
+		fact.Value = value
+	}
+
+	return &fact, nil
 }
 
 // nullString converts an empty string to sql.NullString
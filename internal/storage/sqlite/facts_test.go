@@ -280,3 +280,118 @@ func TestFactCascadeOnBlockDelete(t *testing.T) {
 		t.Errorf("BlockID should be empty after block deletion, got %v", retrieved.BlockID)
 	}
 }
+
+func TestFactProvenanceRoundTrip(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_1", UserMessage: "I live in Oakland"})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	fact := &models.Fact{
+		FactID:        "fact_home",
+		BlockID:       blockID,
+		TurnID:        "turn_1",
+		Key:           "home_city",
+		Value:         "Oakland",
+		Confidence:    0.9,
+		SourceModel:   "gpt-4o-mini",
+		PromptVersion: "facts-2",
+		SourceQuote:   "I live in Oakland",
+		ExtractedBy:   "fact_scrubber",
+		Agent:         "claude-code",
+	}
+	if err := store.SaveFact(fact); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+
+	got, err := store.GetFactByKey("home_city")
+	if err != nil || got == nil {
+		t.Fatalf("GetFactByKey() = %v, %v", got, err)
+	}
+	if got.SourceModel != fact.SourceModel || got.PromptVersion != fact.PromptVersion ||
+		got.SourceQuote != fact.SourceQuote || got.ExtractedBy != fact.ExtractedBy || got.Agent != fact.Agent {
+		t.Errorf("provenance = %+v, want it to match the saved fact", got)
+	}
+
+	data, err := store.Export()
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(data.Facts) != 1 || data.Facts[0].SourceQuote != "I live in Oakland" || data.Facts[0].ExtractedBy != "fact_scrubber" || data.Facts[0].Agent != "claude-code" {
+		t.Errorf("exported facts = %+v, want provenance included", data.Facts)
+	}
+	if source := factSource(data.Facts[0]); source != "claude-code via fact_scrubber (gpt-4o-mini)" {
+		t.Errorf("factSource() = %q, want the agent and the extractor", source)
+	}
+
+	// Facts saved without provenance read back with empty fields
+	if err := store.SaveFact(&models.Fact{FactID: "fact_bare", BlockID: blockID, Key: "bare", Value: "v", Confidence: 1}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+	bare, _ := store.GetFactByKey("bare")
+	if bare.SourceModel != "" || bare.ExtractedBy != "" || bare.Agent != "" {
+		t.Errorf("bare fact provenance = %+v, want empty", bare)
+	}
+}
+
+func TestTypedFacts_SaveAndRanges(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_1", UserMessage: "numbers and dates"})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	save := func(id, key, value string, valueType models.FactValueType) error {
+		return store.SaveFact(&models.Fact{FactID: id, BlockID: blockID, Key: key, Value: value, ValueType: valueType, Confidence: 1})
+	}
+	for _, f := range []struct {
+		id, key, value string
+		valueType      models.FactValueType
+	}{
+		{"f1", "budget", "1500", models.ValueTypeNumber},
+		{"f2", "budget", "90.5", models.ValueTypeNumber},
+		{"f3", "deadline", "2026-03-01", models.ValueTypeDate},
+		{"f4", "deadline", "2026-05-10T09:00:00Z", models.ValueTypeDate},
+		{"f5", "budget_note", "1200", models.ValueTypeString},
+	} {
+		if err := save(f.id, f.key, f.value, f.valueType); err != nil {
+			t.Fatalf("SaveFact(%s) error = %v", f.id, err)
+		}
+	}
+
+	if err := save("bad", "budget", "lots", models.ValueTypeNumber); err == nil {
+		t.Error("SaveFact() with a non-numeric number value should fail")
+	}
+
+	numbers, err := store.GetNumberFacts("budget", 100, 2000)
+	if err != nil {
+		t.Fatalf("GetNumberFacts() error = %v", err)
+	}
+	if len(numbers) != 1 || numbers[0].FactID != "f1" {
+		t.Errorf("GetNumberFacts(100..2000) = %+v, want only f1", numbers)
+	}
+
+	dates, err := store.GetDateFacts("", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), time.Time{})
+	if err != nil {
+		t.Fatalf("GetDateFacts() error = %v", err)
+	}
+	if len(dates) != 1 || dates[0].FactID != "f4" {
+		t.Errorf("GetDateFacts(after April) = %+v, want only f4", dates)
+	}
+
+	got, _ := store.GetFactByKey("budget_note")
+	if got.ValueType != models.ValueTypeString {
+		t.Errorf("ValueType = %q, want string", got.ValueType)
+	}
+}
@@ -0,0 +1,90 @@
+// ABOUTME: Feature usage counts for opt-in telemetry
+// ABOUTME: Keeps one counter per feature per day and tracks which days have been reported
+package sqlite
+
+// FeatureUsage is the all-time use count of one command or tool
+type FeatureUsage struct {
+	Feature string `json:"feature"`
+	Count   int    `json:"count"`
+	Days    int    `json:"days"`
+}
+
+// FeatureDay is one feature's use count on one day (YYYY-MM-DD)
+type FeatureDay struct {
+	Day     string `json:"day"`
+	Feature string `json:"feature"`
+	Count   int    `json:"count"`
+}
+
+// FeatureStore handles feature usage persistence
+type FeatureStore struct {
+	db *DB
+}
+
+// NewFeatureStore creates a new FeatureStore
+func NewFeatureStore(db *DB) *FeatureStore {
+	return &FeatureStore{db: db}
+}
+
+// Record adds one use of feature on day
+func (s *FeatureStore) Record(feature, day string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO feature_usage (feature, day, count) VALUES (?, ?, 1)
+		ON CONFLICT(feature, day) DO UPDATE SET count = count + 1
+	`, feature, day)
+	return err
+}
+
+// Summary totals use counts per feature, most used first
+func (s *FeatureStore) Summary() ([]FeatureUsage, error) {
+	rows, err := s.db.Query(`
+		SELECT feature, SUM(count), COUNT(*)
+		FROM feature_usage
+		GROUP BY feature
+		ORDER BY SUM(count) DESC, feature ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var usage []FeatureUsage
+	for rows.Next() {
+		var u FeatureUsage
+		if err := rows.Scan(&u.Feature, &u.Count, &u.Days); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+// Unreported returns daily counts for days before beforeDay that have not been reported
+func (s *FeatureStore) Unreported(beforeDay string) ([]FeatureDay, error) {
+	rows, err := s.db.Query(`
+		SELECT day, feature, count
+		FROM feature_usage
+		WHERE reported = 0 AND day < ?
+		ORDER BY day ASC, feature ASC
+	`, beforeDay)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var days []FeatureDay
+	for rows.Next() {
+		var d FeatureDay
+		if err := rows.Scan(&d.Day, &d.Feature, &d.Count); err != nil {
+			return nil, err
+		}
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
+
+// MarkReported flags every count for days before beforeDay as reported
+func (s *FeatureStore) MarkReported(beforeDay string) error {
+	_, err := s.db.Exec(`UPDATE feature_usage SET reported = 1 WHERE day < ?`, beforeDay)
+	return err
+}
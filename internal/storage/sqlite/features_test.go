@@ -0,0 +1,44 @@
+// ABOUTME: Tests for feature usage counts
+// ABOUTME: Verifies daily counters, totals, and reported-day tracking
+package sqlite
+
+import "testing"
+
+func TestFeatureStore_RecordAndReport(t *testing.T) {
+	db, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("OpenInMemory() error = %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	store := NewFeatureStore(db)
+	_ = store.Record("cli.search", "2026-01-01")
+	_ = store.Record("cli.search", "2026-01-01")
+	_ = store.Record("cli.search", "2026-01-02")
+	_ = store.Record("mcp.retrieve_memory", "2026-01-02")
+
+	usage, err := store.Summary()
+	if err != nil {
+		t.Fatalf("Summary() error = %v", err)
+	}
+	if len(usage) != 2 || usage[0].Feature != "cli.search" || usage[0].Count != 3 || usage[0].Days != 2 {
+		t.Fatalf("Summary() = %+v, want cli.search first with 3 uses over 2 days", usage)
+	}
+
+	// Only days before the cutoff are reportable
+	days, err := store.Unreported("2026-01-02")
+	if err != nil {
+		t.Fatalf("Unreported() error = %v", err)
+	}
+	if len(days) != 1 || days[0].Count != 2 {
+		t.Fatalf("Unreported() = %+v, want one day with 2 uses", days)
+	}
+
+	if err := store.MarkReported("2026-01-02"); err != nil {
+		t.Fatalf("MarkReported() error = %v", err)
+	}
+	days, _ = store.Unreported("2026-01-03")
+	if len(days) != 2 {
+		t.Errorf("Unreported() after MarkReported = %+v, want the two 2026-01-02 counts", days)
+	}
+}
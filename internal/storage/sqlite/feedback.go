@@ -0,0 +1,107 @@
+// ABOUTME: Feedback on retrieved memories, recorded by the rate_memory tool
+// ABOUTME: Net ratings feed eviction importance; rated queries become labeled retrieval benchmark data
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// RateMemory records a rating of the block or fact in fb, which must name exactly one
+// of them, and fills in its ID and time
+func (s *Storage) RateMemory(fb *models.MemoryFeedback) error {
+	if (fb.BlockID == "") == (fb.FactID == "") {
+		return fmt.Errorf("feedback must name exactly one of a block or a fact")
+	}
+	if fb.Rating != models.RatingUp && fb.Rating != models.RatingDown {
+		return fmt.Errorf("invalid rating %q: must be up or down", fb.Rating)
+	}
+	if fb.BlockID != "" {
+		block, err := s.blocks.Get(fb.BlockID)
+		if err != nil {
+			return fmt.Errorf("failed to get block: %w", err)
+		}
+		if block == nil {
+			return fmt.Errorf("block %s not found", fb.BlockID)
+		}
+	} else {
+		fact, err := s.facts.GetByID(fb.FactID)
+		if err != nil {
+			return fmt.Errorf("failed to get fact: %w", err)
+		}
+		if fact == nil {
+			return fmt.Errorf("fact %s not found", fb.FactID)
+		}
+	}
+
+	fb.Query = strings.TrimSpace(fb.Query)
+	fb.CreatedAt = s.db.now()
+	if fb.FeedbackID == "" {
+		fb.FeedbackID = models.NewIDAt("fb", fb.CreatedAt)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.db.Exec(`
+		INSERT INTO memory_feedback (id, query, block_id, fact_id, rating, note, agent, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, fb.FeedbackID, fb.Query, nullString(fb.BlockID), nullString(fb.FactID), fb.Rating.Value(),
+		nullString(fb.Note), nullString(fb.Agent), fb.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save feedback: %w", err)
+	}
+	return nil
+}
+
+// ListFeedback returns every rating, oldest first
+func (s *Storage) ListFeedback() ([]models.MemoryFeedback, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rows, err := s.db.Query(`
+		SELECT id, query, COALESCE(block_id, ''), COALESCE(fact_id, ''), rating,
+			COALESCE(note, ''), COALESCE(agent, ''), created_at
+		FROM memory_feedback
+		ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedback: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	feedback := []models.MemoryFeedback{}
+	for rows.Next() {
+		var fb models.MemoryFeedback
+		var rating int
+		if err := rows.Scan(&fb.FeedbackID, &fb.Query, &fb.BlockID, &fb.FactID, &rating, &fb.Note, &fb.Agent, &fb.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		fb.Rating = models.RatingUp
+		if rating < 0 {
+			fb.Rating = models.RatingDown
+		}
+		feedback = append(feedback, fb)
+	}
+	return feedback, rows.Err()
+}
+
+// BlockRating returns a block's net rating: thumbs up minus thumbs down, counting
+// ratings of the block itself and of facts learned from it
+func (s *Storage) BlockRating(blockID string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var net sql.NullInt64
+	if err := s.db.QueryRow(`SELECT `+blockRatingExpr+` FROM bridge_blocks b WHERE b.id = ?`, blockID).Scan(&net); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get block rating: %w", err)
+	}
+	return int(net.Int64), nil
+}
+
+// blockRatingExpr is the net rating of the block aliased b, over ratings of the block
+// and of its facts
+const blockRatingExpr = `COALESCE((SELECT SUM(r.rating) FROM memory_feedback r
+		LEFT JOIN facts f ON f.id = r.fact_id
+		WHERE r.block_id = b.id OR f.block_id = b.id), 0)`
@@ -0,0 +1,102 @@
+// ABOUTME: Tests for feedback on retrieved memories
+// ABOUTME: Verifies ratings are validated and listed, and that they raise or lower eviction importance
+
+package sqlite
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestRateMemory(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	old := time.Now().Add(-90 * 24 * time.Hour)
+	for _, id := range []string{"block_a", "block_b", "block_c"} {
+		if err := store.blocks.Save(&models.BridgeBlock{BlockID: id, DayID: "2025-01-01", TopicLabel: id, Status: models.StatusArchived, CreatedAt: old, UpdatedAt: old}); err != nil {
+			t.Fatalf("Save block error = %v", err)
+		}
+		if err := store.turns.Save(id, &models.Turn{TurnID: id + "_turn", Timestamp: old, UserMessage: strings.Repeat("x", 4000)}); err != nil {
+			t.Fatalf("Save turn error = %v", err)
+		}
+	}
+	if err := store.facts.Save(&models.Fact{FactID: "fact_c", BlockID: "block_c", Key: "k", Value: "v", Confidence: 1}); err != nil {
+		t.Fatalf("Save fact error = %v", err)
+	}
+
+	for _, fb := range []models.MemoryFeedback{
+		{Rating: models.RatingUp},
+		{BlockID: "block_a", FactID: "fact_c", Rating: models.RatingUp},
+		{BlockID: "block_a", Rating: "sideways"},
+		{BlockID: "block_missing", Rating: models.RatingUp},
+		{FactID: "fact_missing", Rating: models.RatingDown},
+	} {
+		if err := store.RateMemory(&fb); err == nil {
+			t.Errorf("RateMemory(%+v) succeeded, want an error", fb)
+		}
+	}
+
+	for _, fb := range []models.MemoryFeedback{
+		{Query: "  rust lifetimes ", BlockID: "block_a", Rating: models.RatingUp, Agent: "claude"},
+		{Query: "rust lifetimes", BlockID: "block_a", Rating: models.RatingUp},
+		{Query: "rust lifetimes", BlockID: "block_b", Rating: models.RatingDown, Note: "about C++"},
+		{Query: "what is k", FactID: "fact_c", Rating: models.RatingDown},
+	} {
+		if err := store.RateMemory(&fb); err != nil {
+			t.Fatalf("RateMemory(%+v) error = %v", fb, err)
+		}
+		if fb.FeedbackID == "" || fb.CreatedAt.IsZero() {
+			t.Errorf("RateMemory() left ID %q and time %v unset", fb.FeedbackID, fb.CreatedAt)
+		}
+	}
+
+	feedback, err := store.ListFeedback()
+	if err != nil {
+		t.Fatalf("ListFeedback() error = %v", err)
+	}
+	if len(feedback) != 4 {
+		t.Fatalf("ListFeedback() = %d ratings, want 4", len(feedback))
+	}
+	if fb := feedback[0]; fb.Query != "rust lifetimes" || fb.BlockID != "block_a" || fb.Rating != models.RatingUp || fb.Agent != "claude" {
+		t.Errorf("first rating = %+v, want claude's thumbs up on block_a with a trimmed query", fb)
+	}
+	if fb := feedback[2]; fb.Rating != models.RatingDown || fb.Note != "about C++" {
+		t.Errorf("third rating = %+v, want a thumbs down with its note", fb)
+	}
+	if fb := feedback[3]; fb.FactID != "fact_c" || fb.BlockID != "" {
+		t.Errorf("fourth rating = %+v, want it on fact_c alone", fb)
+	}
+
+	// Ratings of a block's facts count toward the block
+	for id, want := range map[string]int{"block_a": 2, "block_b": -1, "block_c": -1, "block_missing": 0} {
+		if got, err := store.BlockRating(id); err != nil || got != want {
+			t.Errorf("BlockRating(%s) = %d, %v, want %d", id, got, err, want)
+		}
+	}
+
+	// Unrated, block_a (1 turn) would go before block_c (1 turn, 1 fact); two thumbs up
+	// keep it longest, and a thumbs down sends block_b first
+	evicted, err := store.EvictArchived(0, 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("EvictArchived() error = %v", err)
+	}
+	var ids []string
+	for _, block := range evicted {
+		ids = append(ids, block.BlockID)
+	}
+	if got, want := strings.Join(ids, ","), "block_b,block_c,block_a"; got != want {
+		t.Errorf("evicted %s, want %s", got, want)
+	}
+
+	// Ratings of an evicted block go with it; its facts' ratings stay with the facts
+	if feedback, err := store.ListFeedback(); err != nil || len(feedback) != 1 || feedback[0].FactID != "fact_c" {
+		t.Errorf("ListFeedback() after eviction = %+v, %v, want only the fact's rating", feedback, err)
+	}
+}
@@ -0,0 +1,104 @@
+// ABOUTME: Import of embeddings from the JSON file ExportEmbeddingsToJSON writes
+// ABOUTME: Restores vectors from a backup without calling the embeddings API again
+package sqlite
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// EmbeddingImport reports what ImportEmbeddingsFromJSON restored
+type EmbeddingImport struct {
+	Imported   int `json:"imported"`
+	Skipped    int `json:"skipped"` // Embeddings of topics not in this database
+	Dimensions int `json:"dimensions"`
+}
+
+// ImportEmbeddingsFromJSON loads embeddings written by ExportEmbeddingsToJSON. An
+// embedding whose chunk is already stored replaces it; one whose topic is not in the
+// database is skipped. Every vector must have the same dimension as the others and as
+// the embeddings already stored, since searches never match vectors of another
+// dimension; otherwise nothing is imported.
+func (s *Storage) ImportEmbeddingsFromJSON(path string) (*EmbeddingImport, error) {
+	data, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embeddings: %w", err)
+	}
+	var embeddings []ExportEmbedding
+	if err := json.Unmarshal(data, &embeddings); err != nil {
+		return nil, fmt.Errorf("failed to parse embeddings: %w", err)
+	}
+
+	result := &EmbeddingImport{}
+	for _, emb := range embeddings {
+		if emb.ChunkID == "" {
+			return nil, fmt.Errorf("embedding without a chunk_id in %s", path)
+		}
+		if len(emb.Vector) == 0 {
+			return nil, fmt.Errorf("empty embedding for chunk %s", emb.ChunkID)
+		}
+		if result.Dimensions != 0 && len(emb.Vector) != result.Dimensions {
+			return nil, fmt.Errorf("invalid embedding dimension for chunk %s: expected %d, got %d", emb.ChunkID, result.Dimensions, len(emb.Vector))
+		}
+		result.Dimensions = len(emb.Vector)
+	}
+	if len(embeddings) == 0 {
+		return result, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dims, err := s.embeddings.Dimensions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check stored embeddings: %w", err)
+	}
+	var stored []int
+	for dim, count := range dims {
+		if dim != 0 && count > 0 && dim != result.Dimensions {
+			stored = append(stored, dim)
+		}
+	}
+	if len(stored) > 0 {
+		sort.Ints(stored)
+		return nil, fmt.Errorf("invalid embedding dimension: the file holds %d-dimensional vectors but the database holds %d-dimensional ones; run 'memory reembed' instead",
+			result.Dimensions, stored[0])
+	}
+
+	format, err := s.embeddings.Format()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, emb := range embeddings {
+		var exists int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM bridge_blocks WHERE id = ?", emb.BlockID).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to check block %s: %w", emb.BlockID, err)
+		}
+		if exists == 0 {
+			result.Skipped++
+			continue
+		}
+		args := embeddingArgs(format, emb.ChunkID, emb.TurnID, emb.BlockID, emb.Model, emb.Vector, s.db.now())
+		if createdAt, err := time.Parse(time.RFC3339, emb.CreatedAt); err == nil {
+			args[len(args)-1] = createdAt.UTC()
+		}
+		if _, err := tx.Exec(upsertEmbeddingSQL, args...); err != nil {
+			return nil, fmt.Errorf("failed to save embedding for chunk %s: %w", emb.ChunkID, err)
+		}
+		result.Imported++
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit embeddings: %w", err)
+	}
+	return result, nil
+}
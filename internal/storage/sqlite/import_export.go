@@ -0,0 +1,687 @@
+// ABOUTME: Import of the blocks and facts in a memory export into the database
+// ABOUTME: Resolves IDs already stored with different contents by skipping, overwriting, merging, or renaming
+package sqlite
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// ConflictStrategy is what ImportExport does with an incoming block or fact whose ID is
+// already stored with different contents
+type ConflictStrategy string
+
+const (
+	ConflictSkip      ConflictStrategy = "skip"      // Keep the stored item and drop the incoming one
+	ConflictOverwrite ConflictStrategy = "overwrite" // Replace the stored item with the incoming one
+	ConflictMerge     ConflictStrategy = "merge"     // Keep the stored item and add what only the incoming one has
+	ConflictRename    ConflictStrategy = "rename"    // Import the incoming item under a new ID, keeping both
+)
+
+// ConflictStrategies lists every strategy in the order prompts offer them
+var ConflictStrategies = []ConflictStrategy{ConflictSkip, ConflictOverwrite, ConflictMerge, ConflictRename}
+
+// ParseConflictStrategy parses a strategy name
+func ParseConflictStrategy(name string) (ConflictStrategy, error) {
+	for _, strategy := range ConflictStrategies {
+		if strings.EqualFold(strings.TrimSpace(name), string(strategy)) {
+			return strategy, nil
+		}
+	}
+	return "", fmt.Errorf("invalid conflict strategy %q: must be skip, overwrite, merge, or rename", name)
+}
+
+// ImportConflict is an incoming block or fact whose ID is already stored with different contents
+type ImportConflict struct {
+	Kind     string   `json:"kind"` // "block" or "fact"
+	ID       string   `json:"id"`
+	Fields   []string `json:"fields"`   // The fields that differ
+	Stored   string   `json:"stored"`   // The stored item in a few words
+	Incoming string   `json:"incoming"` // The incoming item in a few words
+}
+
+// ImportDecision is how one conflict was resolved
+type ImportDecision struct {
+	ImportConflict
+	Strategy ConflictStrategy `json:"strategy"`
+	NewID    string           `json:"new_id,omitempty"` // The ID a renamed item was imported under
+}
+
+// ExportImport reports what ImportExport did
+type ExportImport struct {
+	Blocks        int              `json:"blocks"`         // Blocks added, renamed ones included
+	Turns         int              `json:"turns"`          // Turns added, or replaced by overwrites
+	Facts         int              `json:"facts"`          // Facts added, renamed ones included
+	Unchanged     int              `json:"unchanged"`      // Blocks and facts already stored as exported
+	MaskedSecrets int              `json:"masked_secrets"` // Secret facts left out because the export masks their values
+	Decisions     []ImportDecision `json:"decisions"`
+}
+
+// ImportOptions say how ImportExport resolves conflicts
+type ImportOptions struct {
+	// OnConflict resolves every conflict when Resolve is not set; the default is ConflictSkip
+	OnConflict ConflictStrategy
+	// Resolve, when set, is asked about each conflict in turn, before anything is saved
+	Resolve func(ImportConflict) (ConflictStrategy, error)
+}
+
+// blockImport is an exported block converted back to what storage saves, and how it
+// is to be imported
+type blockImport struct {
+	block       models.BridgeBlock // Without turns
+	turns       []*models.Turn
+	attachments []*models.Attachment
+	tags        []string
+	stored      *models.BridgeBlock // The block stored under the same ID, with its turns; nil if none
+	storedTags  []string
+	strategy    ConflictStrategy // Empty for a block not stored yet
+	decision    int              // Index of the block's decision, or -1
+}
+
+// factImport is an exported fact converted back to what storage saves, and how it is
+// to be imported
+type factImport struct {
+	fact     models.Fact
+	stored   *models.Fact // The fact stored under the same ID; nil if none
+	strategy ConflictStrategy
+	decision int
+}
+
+// ImportExport adds the blocks and facts of an export to the database, such as one
+// written by another context or machine. Blocks and facts not stored yet are added;
+// ones stored exactly as exported are left alone. Each one stored with different
+// contents is a conflict, resolved by opts: skip keeps the stored item, overwrite
+// replaces it, merge keeps it and adds the incoming turns, keywords, and tags it lacks
+// (for a fact, the newer value wins), and rename adds the incoming item under a new ID.
+//
+// Every conflict is resolved, and the whole export validated, before anything is
+// saved. Imported blocks marked active are paused, unless they are the stored active
+// block, so the conversation in progress stays active. Secret facts the export masks are left out. The profile and
+// embeddings are not imported; see ImportProfile and ImportEmbeddingsFromJSON.
+func (s *Storage) ImportExport(data *ExportData, opts ImportOptions) (*ExportImport, error) {
+	if opts.OnConflict == "" {
+		opts.OnConflict = ConflictSkip
+	}
+	if _, err := ParseConflictStrategy(string(opts.OnConflict)); err != nil {
+		return nil, err
+	}
+	resolve := opts.Resolve
+	if resolve == nil {
+		resolve = func(ImportConflict) (ConflictStrategy, error) { return opts.OnConflict, nil }
+	}
+
+	result := &ExportImport{Decisions: []ImportDecision{}}
+	decide := func(conflict ImportConflict) (ConflictStrategy, int, error) {
+		strategy, err := resolve(conflict)
+		if err != nil {
+			return "", -1, err
+		}
+		if strategy, err = ParseConflictStrategy(string(strategy)); err != nil {
+			return "", -1, err
+		}
+		result.Decisions = append(result.Decisions, ImportDecision{ImportConflict: conflict, Strategy: strategy})
+		return strategy, len(result.Decisions) - 1, nil
+	}
+
+	blocks, err := s.planBlockImports(data.Blocks, result, decide)
+	if err != nil {
+		return nil, err
+	}
+	facts, err := s.planFactImports(data.Facts, result, decide)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.importBlocks(blocks, result); err != nil {
+		return result, err
+	}
+	for _, p := range facts {
+		if err := s.importFact(p, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// planBlockImports validates and converts the exported blocks, comparing each with
+// the block stored under its ID and deciding its conflict if it differs
+func (s *Storage) planBlockImports(exported []ExportBlock, result *ExportImport,
+	decide func(ImportConflict) (ConflictStrategy, int, error)) ([]*blockImport, error) {
+	plans := make([]*blockImport, 0, len(exported))
+	seenBlocks, seenTurns := map[string]bool{}, map[string]bool{}
+	for _, in := range exported {
+		if in.BlockID == "" {
+			return nil, fmt.Errorf("block %q has no block_id", in.TopicLabel)
+		}
+		if seenBlocks[in.BlockID] {
+			return nil, fmt.Errorf("block %s is listed twice", in.BlockID)
+		}
+		seenBlocks[in.BlockID] = true
+		for _, turn := range in.Turns {
+			if turn.TurnID == "" {
+				return nil, fmt.Errorf("block %s has a turn without a turn_id", in.BlockID)
+			}
+			if seenTurns[turn.TurnID] {
+				return nil, fmt.Errorf("turn %s is listed twice", turn.TurnID)
+			}
+			seenTurns[turn.TurnID] = true
+		}
+
+		p, err := s.convertBlock(in)
+		if err != nil {
+			return nil, err
+		}
+		p.decision = -1
+		plans = append(plans, p)
+
+		if p.stored, err = s.blocks.GetWithTurns(in.BlockID); err != nil {
+			return nil, fmt.Errorf("failed to get block %s: %w", in.BlockID, err)
+		}
+		// Only the stored active block may stay active
+		if p.block.Status == models.StatusActive && (p.stored == nil || p.stored.Status != models.StatusActive) {
+			p.block.Status = models.StatusPaused
+		}
+		if p.stored == nil {
+			continue
+		}
+		tags, err := s.tags.ForBlocks([]string{in.BlockID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block tags: %w", err)
+		}
+		p.storedTags = tags[in.BlockID]
+		attachments, err := s.attachments.ForBlocks([]string{in.BlockID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get attachments: %w", err)
+		}
+
+		// Compare the block as it would be exported from here with the incoming one,
+		// its times shown in this database's timezone
+		stored := s.exportBlock(p.stored, p.storedTags, attachments[in.BlockID])
+		incoming := s.exportBlock(&models.BridgeBlock{
+			BlockID: p.block.BlockID, TopicLabel: p.block.TopicLabel, Status: p.block.Status,
+			Keywords: p.block.Keywords, Summary: p.block.Summary, Retention: p.block.Retention,
+			CreatedAt: p.block.CreatedAt, Turns: derefTurns(p.turns),
+		}, p.tags, nil)
+		fields := importBlockChanges(stored, incoming)
+		if len(fields) == 0 {
+			result.Unchanged++
+			continue
+		}
+		if p.strategy, p.decision, err = decide(ImportConflict{
+			Kind:     "block",
+			ID:       in.BlockID,
+			Fields:   fields,
+			Stored:   describeImportBlock(stored),
+			Incoming: describeImportBlock(incoming),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return plans, nil
+}
+
+// convertBlock converts an exported block back to the block, turns, attachments, and
+// tags storage saves
+func (s *Storage) convertBlock(in ExportBlock) (*blockImport, error) {
+	createdAt, err := parseExportTime(in.CreatedAt, s.db.now())
+	if err != nil {
+		return nil, fmt.Errorf("block %s: invalid created_at: %w", in.BlockID, err)
+	}
+	status := models.BridgeBlockStatus(strings.ToUpper(in.Status))
+	if status == "" {
+		status = models.StatusPaused
+	}
+	tags, err := models.NormalizeTags(in.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("block %s: %w", in.BlockID, err)
+	}
+	if in.Retention != "" {
+		if _, err := models.ParseRetention(in.Retention); err != nil {
+			return nil, fmt.Errorf("block %s: %w", in.BlockID, err)
+		}
+	}
+
+	p := &blockImport{
+		block: models.BridgeBlock{
+			BlockID:    in.BlockID,
+			DayID:      createdAt.In(s.location()).Format("2006-01-02"),
+			TopicLabel: in.TopicLabel,
+			Status:     status,
+			Keywords:   in.Keywords,
+			Summary:    in.Summary,
+			CreatedAt:  createdAt,
+			Retention:  in.Retention,
+		},
+		tags: tags,
+	}
+	if err := p.block.Validate(); err != nil {
+		return nil, fmt.Errorf("block %s: %w", in.BlockID, err)
+	}
+
+	for _, t := range in.Turns {
+		timestamp, err := parseExportTime(t.Timestamp, createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("turn %s: invalid timestamp: %w", t.TurnID, err)
+		}
+		turn := &models.Turn{
+			TurnID:      t.TurnID,
+			Timestamp:   timestamp,
+			UserMessage: t.UserMessage,
+			AIResponse:  t.AIResponse,
+			Affect:      models.Affect(t.Affect),
+			Messages:    t.Messages,
+		}
+		if t.Origin != nil {
+			turn.Origin = *t.Origin
+		}
+		p.turns = append(p.turns, turn)
+
+		for _, a := range t.Attachments {
+			attachment := &models.Attachment{
+				AttachmentID: a.AttachmentID,
+				TurnID:       t.TurnID,
+				BlockID:      in.BlockID,
+				Kind:         models.AttachmentKind(a.Kind),
+				Name:         a.Name,
+				Ref:          a.Ref,
+				MimeType:     a.MimeType,
+				Content:      a.Content,
+				CreatedAt:    timestamp,
+			}
+			if attachment.AttachmentID == "" {
+				return nil, fmt.Errorf("turn %s has an attachment without an attachment_id", t.TurnID)
+			}
+			if err := attachment.Validate(); err != nil {
+				return nil, fmt.Errorf("attachment %s: %w", a.AttachmentID, err)
+			}
+			p.attachments = append(p.attachments, attachment)
+		}
+	}
+	return p, nil
+}
+
+// importBlockChanges lists the fields of a block that differ between the stored and
+// incoming exports of it, including its turns and retention, which diffs leave to
+// the turn comparison or ignore
+func importBlockChanges(stored, incoming ExportBlock) []string {
+	fields := blockChanges(stored, incoming)
+	if stored.Retention != incoming.Retention {
+		fields = append(fields, "retention")
+	}
+	if slices.Contains(fields, "turn_count") {
+		return fields
+	}
+	storedTurns := make(map[string]ExportTurn, len(stored.Turns))
+	for _, turn := range stored.Turns {
+		storedTurns[turn.TurnID] = turn
+	}
+	for _, turn := range incoming.Turns {
+		if prev, ok := storedTurns[turn.TurnID]; !ok || len(turnChanges(prev, turn)) > 0 {
+			return append(fields, "turns")
+		}
+	}
+	return fields
+}
+
+// describeImportBlock describes a block in a conflict
+func describeImportBlock(block ExportBlock) string {
+	return fmt.Sprintf("%q, %s, %d turns", block.TopicLabel, block.Status, len(block.Turns))
+}
+
+// planFactImports validates and converts the exported facts, comparing each with the
+// fact stored under its ID and deciding its conflict if it differs
+func (s *Storage) planFactImports(exported []ExportFact, result *ExportImport,
+	decide func(ImportConflict) (ConflictStrategy, int, error)) ([]*factImport, error) {
+	plans := make([]*factImport, 0, len(exported))
+	seen := map[string]bool{}
+	for _, in := range exported {
+		if in.FactID == "" {
+			return nil, fmt.Errorf("fact %q has no fact_id", in.Key)
+		}
+		if seen[in.FactID] {
+			return nil, fmt.Errorf("fact %s is listed twice", in.FactID)
+		}
+		seen[in.FactID] = true
+
+		valueType, err := models.ParseFactValueType(in.ValueType)
+		if err != nil {
+			return nil, fmt.Errorf("fact %s: %w", in.FactID, err)
+		}
+		if valueType == models.ValueTypeSecret && (in.Value == SecretMask || in.Value == unreadableSecret) {
+			result.MaskedSecrets++
+			continue
+		}
+
+		p := &factImport{decision: -1}
+		if p.fact, err = convertFact(in, valueType, s.db.now()); err != nil {
+			return nil, err
+		}
+		plans = append(plans, p)
+
+		if p.stored, err = s.GetFactByID(in.FactID); err != nil {
+			return nil, fmt.Errorf("failed to get fact %s: %w", in.FactID, err)
+		}
+		if p.stored == nil {
+			continue
+		}
+		stored, incoming := exportedFact(p.stored), exportedFact(&p.fact)
+		fields := factChanges(stored, incoming)
+		if len(fields) == 0 {
+			result.Unchanged++
+			continue
+		}
+		if p.strategy, p.decision, err = decide(ImportConflict{
+			Kind:     "fact",
+			ID:       in.FactID,
+			Fields:   fields,
+			Stored:   describeImportFact(stored),
+			Incoming: describeImportFact(incoming),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return plans, nil
+}
+
+// convertFact converts an exported fact back to the fact storage saves, its value in
+// canonical form
+func convertFact(in ExportFact, valueType models.FactValueType, now time.Time) (models.Fact, error) {
+	if strings.TrimSpace(in.Key) == "" {
+		return models.Fact{}, fmt.Errorf("fact %s has no key", in.FactID)
+	}
+	value, err := models.NormalizeFactValue(valueType, in.Value)
+	if err != nil {
+		return models.Fact{}, fmt.Errorf("fact %s: %w", in.FactID, err)
+	}
+	tags, err := models.NormalizeTags(in.Tags)
+	if err != nil {
+		return models.Fact{}, fmt.Errorf("fact %s: %w", in.FactID, err)
+	}
+	createdAt, err := parseExportTime(in.CreatedAt, now)
+	if err != nil {
+		return models.Fact{}, fmt.Errorf("fact %s: invalid created_at: %w", in.FactID, err)
+	}
+	return models.Fact{
+		FactID:        in.FactID,
+		Key:           in.Key,
+		Value:         value,
+		ValueType:     valueType,
+		Confidence:    in.Confidence,
+		CreatedAt:     createdAt,
+		Tags:          tags,
+		SourceModel:   in.SourceModel,
+		PromptVersion: in.PromptVersion,
+		SourceQuote:   in.SourceQuote,
+		ExtractedBy:   in.ExtractedBy,
+		Agent:         in.Agent,
+	}, nil
+}
+
+// exportedFact is the part of a fact conflicts compare
+func exportedFact(fact *models.Fact) ExportFact {
+	valueType := fact.ValueType
+	if valueType == "" {
+		valueType = models.ValueTypeString
+	}
+	return ExportFact{
+		FactID:     fact.FactID,
+		Key:        fact.Key,
+		Value:      fact.Value,
+		ValueType:  string(valueType),
+		Confidence: fact.Confidence,
+		Tags:       fact.Tags,
+	}
+}
+
+// describeImportFact describes a fact in a conflict, never showing a secret's value
+func describeImportFact(fact ExportFact) string {
+	value := fact.Value
+	if fact.ValueType == string(models.ValueTypeSecret) {
+		value = SecretMask
+	}
+	return fmt.Sprintf("%s = %s", fact.Key, value)
+}
+
+// parseExportTime parses a time written by an export, or returns fallback for an empty one
+func parseExportTime(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// derefTurns copies turns into a slice of values
+func derefTurns(turns []*models.Turn) []models.Turn {
+	values := make([]models.Turn, len(turns))
+	for i, turn := range turns {
+		values[i] = *turn
+	}
+	return values
+}
+
+// importBlocks saves the planned blocks and their turns, then publishes the turns added
+func (s *Storage) importBlocks(plans []*blockImport, result *ExportImport) (err error) {
+	type savedTurn struct {
+		blockID string
+		turn    *models.Turn
+	}
+	var saved []savedTurn
+	defer func() { // runs after the unlock below, so subscribers may use storage
+		for _, st := range saved {
+			s.publishTurn(st.blockID, st.turn)
+		}
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range plans {
+		// Blocks stored as exported are left alone
+		if p.strategy == ConflictSkip || p.stored != nil && p.strategy == "" {
+			continue
+		}
+		if p.strategy == ConflictRename {
+			newID := s.renameBlockImport(p)
+			result.Decisions[p.decision].NewID = newID
+		}
+		turns, err := s.importBlock(p)
+		if err != nil {
+			return fmt.Errorf("failed to import block %s: %w", p.block.BlockID, err)
+		}
+		if p.stored == nil || p.strategy == ConflictRename {
+			result.Blocks++
+		}
+		result.Turns += len(turns)
+		for _, turn := range turns {
+			saved = append(saved, savedTurn{p.block.BlockID, turn})
+		}
+	}
+	return nil
+}
+
+// renameBlockImport gives a block, its turns, and their attachments new IDs, so it is
+// imported beside the stored block rather than over it, and returns the block's new ID
+func (s *Storage) renameBlockImport(p *blockImport) string {
+	p.block.BlockID = s.newBlockID(s.db.now())
+	if p.block.Status == models.StatusActive {
+		p.block.Status = models.StatusPaused
+	}
+	p.stored, p.storedTags = nil, nil
+	turnIDs := make(map[string]string, len(p.turns))
+	for _, turn := range p.turns {
+		turnIDs[turn.TurnID] = models.NewID("turn")
+		turn.TurnID = turnIDs[turn.TurnID]
+	}
+	for _, a := range p.attachments {
+		a.AttachmentID = models.NewID("att")
+		a.TurnID, a.BlockID = turnIDs[a.TurnID], p.block.BlockID
+	}
+	return p.block.BlockID
+}
+
+// importBlock saves one planned block as its strategy says, returning the turns saved
+func (s *Storage) importBlock(p *blockImport) ([]*models.Turn, error) {
+	block := p.block
+	block.UpdatedAt = s.db.now()
+
+	storedTurns := map[string]bool{}
+	if p.stored != nil {
+		for _, turn := range p.stored.Turns {
+			storedTurns[turn.TurnID] = true
+		}
+	}
+	var removeTags []string
+	if p.strategy == ConflictMerge {
+		merged := *p.stored
+		merged.Keywords = appendMissing(slices.Clone(merged.Keywords), block.Keywords)
+		if merged.Summary == "" {
+			merged.Summary = block.Summary
+		}
+		if merged.Retention == "" {
+			merged.Retention = block.Retention
+		}
+		merged.UpdatedAt = block.UpdatedAt
+		block = merged
+	} else {
+		for _, tag := range p.storedTags {
+			if !slices.Contains(p.tags, tag) {
+				removeTags = append(removeTags, tag)
+			}
+		}
+	}
+
+	if err := s.blocks.Save(&block); err != nil {
+		return nil, err
+	}
+	if err := s.tags.AddToBlock(block.BlockID, p.tags); err != nil {
+		return nil, err
+	}
+	if err := s.tags.RemoveFromBlock(block.BlockID, removeTags); err != nil {
+		return nil, err
+	}
+	if _, err := s.blocks.SetRetention(block.BlockID, block.Retention); err != nil {
+		return nil, err
+	}
+
+	// An overwrite drops the stored turns the export does not have
+	if p.strategy == ConflictOverwrite {
+		incoming := make(map[string]bool, len(p.turns))
+		for _, turn := range p.turns {
+			incoming[turn.TurnID] = true
+		}
+		for id := range storedTurns {
+			if incoming[id] {
+				continue
+			}
+			if _, err := s.db.Exec("DELETE FROM embeddings WHERE turn_id = ?", id); err != nil {
+				return nil, fmt.Errorf("failed to delete embeddings of turn %s: %w", id, err)
+			}
+			if err := deleteChunks(s.db, id); err != nil {
+				return nil, err
+			}
+			if err := s.turns.Delete(id); err != nil {
+				return nil, fmt.Errorf("failed to delete turn %s: %w", id, err)
+			}
+		}
+		if err := refreshCentroid(s.db, block.BlockID); err != nil {
+			return nil, err
+		}
+	}
+
+	var saved []*models.Turn
+	savedIDs := map[string]bool{}
+	for _, turn := range p.turns {
+		if storedTurns[turn.TurnID] && p.strategy != ConflictOverwrite {
+			continue
+		}
+		// A turn stored in another block stays there
+		owner, err := s.turns.BlockOf(turn.TurnID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get turn: %w", err)
+		}
+		if owner != "" && owner != block.BlockID {
+			continue
+		}
+		if storedTurns[turn.TurnID] && !p.turnChanged(turn) {
+			continue
+		}
+		if err := s.turns.Save(block.BlockID, turn); err != nil {
+			return nil, fmt.Errorf("failed to save turn: %w", err)
+		}
+		saved = append(saved, turn)
+		savedIDs[turn.TurnID] = true
+	}
+	for _, a := range p.attachments {
+		if !savedIDs[a.TurnID] {
+			continue
+		}
+		if err := s.attachments.Save(a); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.db.Exec(`UPDATE bridge_blocks SET turn_count = (SELECT COUNT(*) FROM turns WHERE block_id = ?) WHERE id = ?`,
+		block.BlockID, block.BlockID); err != nil {
+		return nil, fmt.Errorf("failed to count turns: %w", err)
+	}
+	return saved, nil
+}
+
+// turnChanged reports whether an incoming turn differs from the stored turn with its ID
+func (p *blockImport) turnChanged(turn *models.Turn) bool {
+	for _, stored := range p.stored.Turns {
+		if stored.TurnID == turn.TurnID {
+			return stored.UserMessage != turn.UserMessage || stored.AIResponse != turn.AIResponse ||
+				!stored.Timestamp.Equal(turn.Timestamp) || stored.Affect != turn.Affect ||
+				!reflect.DeepEqual(stored.Messages, turn.Messages) || stored.Origin != turn.Origin
+		}
+	}
+	return true
+}
+
+// importFact saves one planned fact as its strategy says
+func (s *Storage) importFact(p *factImport, result *ExportImport) error {
+	fact := p.fact
+	var removeTags []string
+	switch {
+	case p.stored == nil:
+		result.Facts++
+	case p.strategy == ConflictSkip || p.strategy == "":
+		return nil
+	case p.strategy == ConflictRename:
+		fact.FactID = models.NewID("fact")
+		result.Decisions[p.decision].NewID = fact.FactID
+		result.Facts++
+	case p.strategy == ConflictOverwrite:
+		fact.BlockID, fact.TurnID = p.stored.BlockID, p.stored.TurnID
+		for _, tag := range p.stored.Tags {
+			if !slices.Contains(fact.Tags, tag) {
+				removeTags = append(removeTags, tag)
+			}
+		}
+	case p.strategy == ConflictMerge:
+		// The newer value wins; tags from both are kept
+		if !fact.CreatedAt.After(p.stored.CreatedAt) {
+			tags := fact.Tags
+			fact = *p.stored
+			fact.Tags = tags
+		}
+		fact.BlockID, fact.TurnID = p.stored.BlockID, p.stored.TurnID
+	}
+
+	if err := s.SaveFact(&fact); err != nil {
+		return fmt.Errorf("failed to import fact %s: %w", p.fact.FactID, err)
+	}
+	if err := s.tags.AddToFact(fact.FactID, fact.Tags); err != nil {
+		return fmt.Errorf("failed to add fact tags: %w", err)
+	}
+	if err := s.tags.RemoveFromFact(fact.FactID, removeTags); err != nil {
+		return fmt.Errorf("failed to remove fact tags: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+// ABOUTME: Tests for importing the blocks and facts of an export
+// ABOUTME: Verifies new items are added, identical ones left alone, and each conflict strategy
+
+package sqlite
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// importSource stores a topic of two turns and a tagged fact, and returns their export
+func importSource(t *testing.T) *ExportData {
+	t.Helper()
+	source, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = source.Close() }()
+
+	blockID, err := source.StoreTurn(&models.Turn{TurnID: "turn_1", UserMessage: "learning go", Keywords: []string{"go"}})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if err := source.AppendTurnToBlock(blockID, &models.Turn{TurnID: "turn_2", UserMessage: "go generics"}); err != nil {
+		t.Fatalf("AppendTurnToBlock() error = %v", err)
+	}
+	if _, err := source.TagBlock(blockID, []string{"golang"}, nil); err != nil {
+		t.Fatalf("TagBlock() error = %v", err)
+	}
+	if err := source.SaveFact(&models.Fact{FactID: "fact_1", Key: "language", Value: "go", Confidence: 1}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+	if _, err := source.TagFact("fact_1", []string{"work"}, nil); err != nil {
+		t.Fatalf("TagFact() error = %v", err)
+	}
+
+	data, err := source.Export()
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(data.Blocks) != 1 || len(data.Facts) != 1 {
+		t.Fatalf("Export() = %d blocks and %d facts, want 1 of each", len(data.Blocks), len(data.Facts))
+	}
+	return data
+}
+
+// changedExport is data with the topic relabeled, a third turn, and the fact given a
+// newer value
+func changedExport(data *ExportData) *ExportData {
+	changed := *data
+	block := data.Blocks[0]
+	block.TopicLabel = "Go generics"
+	block.Turns = append(slices.Clone(block.Turns), ExportTurn{
+		TurnID:      "turn_3",
+		UserMessage: "type parameters",
+		Timestamp:   block.Turns[1].Timestamp,
+	})
+	block.Tags = []string{"generics"}
+	changed.Blocks = []ExportBlock{block}
+
+	fact := data.Facts[0]
+	fact.Value = "rust"
+	fact.Tags = []string{"hobby"}
+	fact.CreatedAt = time.Now().Add(time.Hour).Format(time.RFC3339)
+	changed.Facts = []ExportFact{fact}
+	return &changed
+}
+
+func TestImportExport_AddsAndLeavesIdenticalAlone(t *testing.T) {
+	data := importSource(t)
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	result, err := store.ImportExport(data, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportExport() error = %v", err)
+	}
+	if result.Blocks != 1 || result.Turns != 2 || result.Facts != 1 || len(result.Decisions) != 0 {
+		t.Errorf("ImportExport() = %+v, want 1 block, 2 turns, 1 fact, no conflicts", result)
+	}
+
+	blockID := data.Blocks[0].BlockID
+	block, err := store.GetBridgeBlock(blockID)
+	if err != nil || block == nil {
+		t.Fatalf("GetBridgeBlock() = %v, %v", block, err)
+	}
+	if block.Status != models.StatusPaused || block.TurnCount != 2 || !slices.Equal(block.Tags, []string{"golang"}) {
+		t.Errorf("imported block = %s with %d turns tagged %v, want PAUSED with 2 tagged golang", block.Status, block.TurnCount, block.Tags)
+	}
+	fact, err := store.GetFactByID("fact_1")
+	if err != nil || fact == nil || fact.Value != "go" || !slices.Equal(fact.Tags, []string{"work"}) {
+		t.Errorf("GetFactByID() = %+v, %v, want go tagged work", fact, err)
+	}
+
+	// Importing the same export again finds everything already there
+	again, err := store.ImportExport(data, ImportOptions{OnConflict: ConflictOverwrite})
+	if err != nil {
+		t.Fatalf("ImportExport() again error = %v", err)
+	}
+	if again.Unchanged != 2 || again.Blocks+again.Turns+again.Facts != 0 || len(again.Decisions) != 0 {
+		t.Errorf("ImportExport() again = %+v, want 2 unchanged and nothing else", again)
+	}
+}
+
+func TestImportExport_Strategies(t *testing.T) {
+	data := importSource(t)
+	changed := changedExport(data)
+	blockID := data.Blocks[0].BlockID
+
+	importInto := func(t *testing.T, strategy ConflictStrategy) (*Storage, *ExportImport) {
+		t.Helper()
+		store, err := NewStorageInMemory()
+		if err != nil {
+			t.Fatalf("NewStorageInMemory() error = %v", err)
+		}
+		t.Cleanup(func() { _ = store.Close() })
+		if _, err := store.ImportExport(data, ImportOptions{}); err != nil {
+			t.Fatalf("ImportExport() error = %v", err)
+		}
+		result, err := store.ImportExport(changed, ImportOptions{OnConflict: strategy})
+		if err != nil {
+			t.Fatalf("ImportExport(%s) error = %v", strategy, err)
+		}
+		if len(result.Decisions) != 2 || result.Decisions[0].Kind != "block" || result.Decisions[1].Kind != "fact" {
+			t.Fatalf("decisions = %+v, want a block and a fact", result.Decisions)
+		}
+		for _, d := range result.Decisions {
+			if d.Strategy != strategy {
+				t.Errorf("decision %s = %s, want %s", d.ID, d.Strategy, strategy)
+			}
+		}
+		return store, result
+	}
+	check := func(t *testing.T, store *Storage, id, label string, turns int, tags []string) {
+		t.Helper()
+		block, err := store.GetBridgeBlock(id)
+		if err != nil || block == nil {
+			t.Fatalf("GetBridgeBlock(%s) = %v, %v", id, block, err)
+		}
+		if block.TopicLabel != label || block.TurnCount != turns || !slices.Equal(block.Tags, tags) {
+			t.Errorf("block %s = %q with %d turns tagged %v, want %q with %d tagged %v",
+				id, block.TopicLabel, block.TurnCount, block.Tags, label, turns, tags)
+		}
+	}
+	factIs := func(t *testing.T, store *Storage, id, value string, tags []string) {
+		t.Helper()
+		fact, err := store.GetFactByID(id)
+		if err != nil || fact == nil || fact.Value != value || !slices.Equal(fact.Tags, tags) {
+			t.Errorf("fact %s = %+v, %v, want %s tagged %v", id, fact, err, value, tags)
+		}
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		store, result := importInto(t, ConflictSkip)
+		if result.Turns != 0 || result.Facts != 0 {
+			t.Errorf("result = %+v, want nothing imported", result)
+		}
+		check(t, store, blockID, data.Blocks[0].TopicLabel, 2, []string{"golang"})
+		factIs(t, store, "fact_1", "go", []string{"work"})
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		store, result := importInto(t, ConflictOverwrite)
+		if result.Turns != 1 || result.Blocks != 0 {
+			t.Errorf("result = %+v, want 1 turn and no new blocks", result)
+		}
+		if fields := result.Decisions[0].Fields; !slices.Equal(fields, []string{"topic_label", "tags", "turn_count"}) {
+			t.Errorf("block conflict fields = %v, want topic_label, tags, turn_count", fields)
+		}
+		check(t, store, blockID, "Go generics", 3, []string{"generics"})
+		factIs(t, store, "fact_1", "rust", []string{"hobby"})
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		store, _ := importInto(t, ConflictMerge)
+		check(t, store, blockID, data.Blocks[0].TopicLabel, 3, []string{"generics", "golang"})
+		factIs(t, store, "fact_1", "rust", []string{"hobby", "work"})
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		store, result := importInto(t, ConflictRename)
+		if result.Blocks != 1 || result.Turns != 3 || result.Facts != 1 {
+			t.Errorf("result = %+v, want 1 block of 3 turns and 1 fact", result)
+		}
+		newBlock, newFact := result.Decisions[0].NewID, result.Decisions[1].NewID
+		if newBlock == "" || newBlock == blockID || newFact == "" || newFact == "fact_1" {
+			t.Fatalf("new IDs = %q and %q, want fresh ones", newBlock, newFact)
+		}
+		check(t, store, blockID, data.Blocks[0].TopicLabel, 2, []string{"golang"})
+		check(t, store, newBlock, "Go generics", 3, []string{"generics"})
+		factIs(t, store, "fact_1", "go", []string{"work"})
+		factIs(t, store, newFact, "rust", []string{"hobby"})
+	})
+}
+
+func TestImportExport_Resolve(t *testing.T) {
+	data := importSource(t)
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	if _, err := store.ImportExport(data, ImportOptions{}); err != nil {
+		t.Fatalf("ImportExport() error = %v", err)
+	}
+
+	var asked []ImportConflict
+	result, err := store.ImportExport(changedExport(data), ImportOptions{
+		OnConflict: ConflictOverwrite,
+		Resolve: func(c ImportConflict) (ConflictStrategy, error) {
+			asked = append(asked, c)
+			if c.Kind == "fact" {
+				return ConflictSkip, nil
+			}
+			return ConflictMerge, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("ImportExport() error = %v", err)
+	}
+	if len(asked) != 2 || asked[1].Stored != "language = go" || asked[1].Incoming != "language = rust" {
+		t.Errorf("asked about %+v, want the block then language = go against rust", asked)
+	}
+	if result.Decisions[0].Strategy != ConflictMerge || result.Decisions[1].Strategy != ConflictSkip {
+		t.Errorf("decisions = %+v, want merge then skip", result.Decisions)
+	}
+	if fact, _ := store.GetFactByID("fact_1"); fact == nil || fact.Value != "go" {
+		t.Errorf("fact_1 = %+v, want the skipped import to leave go", fact)
+	}
+
+	_, err = store.ImportExport(changedExport(data), ImportOptions{
+		Resolve: func(ImportConflict) (ConflictStrategy, error) { return "shred", nil },
+	})
+	if err == nil {
+		t.Error("ImportExport() with an invalid strategy succeeded, want an error")
+	}
+}
+
+func TestImportExport_PausesActiveBlocks(t *testing.T) {
+	data := importSource(t)
+	data.Blocks[0].Status = string(models.StatusActive)
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	activeID, err := store.StoreTurn(&models.Turn{TurnID: "turn_here", UserMessage: "current conversation"})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if _, err := store.ImportExport(data, ImportOptions{}); err != nil {
+		t.Fatalf("ImportExport() error = %v", err)
+	}
+	active, err := store.GetActiveBridgeBlocks()
+	if err != nil || len(active) != 1 || active[0].BlockID != activeID {
+		t.Errorf("active blocks = %v, %v, want only %s", active, err, activeID)
+	}
+}
+
+func TestImportExport_Validation(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	masked, err := store.ImportExport(&ExportData{Facts: []ExportFact{
+		{FactID: "fact_pin", Key: "pin", Value: SecretMask, ValueType: string(models.ValueTypeSecret)},
+	}}, ImportOptions{})
+	if err != nil || masked.MaskedSecrets != 1 || masked.Facts != 0 {
+		t.Errorf("ImportExport() of a masked secret = %+v, %v, want it left out", masked, err)
+	}
+
+	for name, data := range map[string]*ExportData{
+		"duplicate block": {Blocks: []ExportBlock{
+			{BlockID: "block_a", TopicLabel: "A", Status: "PAUSED"},
+			{BlockID: "block_a", TopicLabel: "A again", Status: "PAUSED"},
+		}},
+		"bad timestamp": {Blocks: []ExportBlock{
+			{BlockID: "block_a", TopicLabel: "A", Status: "PAUSED", Turns: []ExportTurn{{TurnID: "turn_1", Timestamp: "yesterday"}}},
+		}},
+		"bad number": {
+			Blocks: []ExportBlock{{BlockID: "block_ok", TopicLabel: "Fine", Status: "PAUSED"}},
+			Facts:  []ExportFact{{FactID: "fact_n", Key: "age", Value: "old", ValueType: "number"}},
+		},
+	} {
+		if _, err := store.ImportExport(data, ImportOptions{}); err == nil {
+			t.Errorf("%s: ImportExport() succeeded, want an error", name)
+		}
+	}
+	// Nothing is saved from an export that fails validation
+	if block, err := store.GetBridgeBlock("block_ok"); err != nil || block != nil {
+		t.Errorf("GetBridgeBlock(block_ok) = %v, %v, want nothing saved", block, err)
+	}
+}
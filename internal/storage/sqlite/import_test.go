@@ -0,0 +1,100 @@
+// ABOUTME: Tests for importing the JSON embeddings export
+// ABOUTME: Verifies round-trips, upserts, skipped topics, and dimension validation
+
+package sqlite
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestImportEmbeddingsFromJSON(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	kept, err := store.StoreTurn(&models.Turn{TurnID: "turn_kept", Timestamp: time.Now(), UserMessage: "kept"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gone, err := store.StoreTurn(&models.Turn{TurnID: "turn_gone", Timestamp: time.Now(), UserMessage: "gone"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vector := make([]float64, ExpectedDimension)
+	vector[0] = 0.25
+	vectors := store.GetVectorStorage()
+	if err := vectors.SaveWithModel("chunk_kept", "turn_kept", kept, "text-embedding-3-small", vector); err != nil {
+		t.Fatal(err)
+	}
+	if err := vectors.Save("chunk_gone", "turn_gone", gone, vector); err != nil {
+		t.Fatal(err)
+	}
+	original, err := vectors.GetByChunkID("chunk_kept")
+	if err != nil || original == nil {
+		t.Fatalf("GetByChunkID() = %v, %v", original, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "embeddings.json")
+	if err := store.ExportEmbeddingsToJSON(path); err != nil {
+		t.Fatalf("ExportEmbeddingsToJSON() error = %v", err)
+	}
+	if err := vectors.Delete("chunk_kept"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteBridgeBlock(gone); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ { // importing twice replaces rather than duplicates
+		result, err := store.ImportEmbeddingsFromJSON(path)
+		if err != nil {
+			t.Fatalf("ImportEmbeddingsFromJSON() error = %v", err)
+		}
+		if result.Imported != 1 || result.Skipped != 1 || result.Dimensions != ExpectedDimension {
+			t.Errorf("import %d = %+v, want 1 imported and the deleted topic's embedding skipped", i+1, result)
+		}
+	}
+	restored, err := vectors.GetByChunkID("chunk_kept")
+	if err != nil || restored == nil {
+		t.Fatalf("GetByChunkID() after import = %v, %v", restored, err)
+	}
+	if restored.BlockID != kept || restored.Vector[0] != 0.25 || !restored.CreatedAt.Equal(original.CreatedAt.Truncate(time.Second)) {
+		t.Errorf("restored embedding = %s %v %v, want it as exported", restored.BlockID, restored.Vector[0], restored.CreatedAt)
+	}
+	if pending, err := store.TurnsNeedingEmbedding("text-embedding-3-small"); err != nil || len(pending) != 0 {
+		t.Errorf("turns still needing embeddings after import = %v, %v; want the model restored", pending, err)
+	}
+	if dims, _ := store.EmbeddingDimensions(); dims[ExpectedDimension] != 1 {
+		t.Errorf("EmbeddingDimensions() = %v, want one vector", dims)
+	}
+
+	write := func(content string) string {
+		t.Helper()
+		p := filepath.Join(t.TempDir(), "bad.json")
+		if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	for name, tc := range map[string]struct{ file, want string }{
+		"mixed dimensions": {`[{"chunk_id":"a","block_id":"` + kept + `","vector":[1,2]},{"chunk_id":"b","block_id":"` + kept + `","vector":[1]}]`, "expected 2, got 1"},
+		"stored dimension": {`[{"chunk_id":"a","block_id":"` + kept + `","vector":[1,2]}]`, "database holds 1536-dimensional"},
+		"empty vector":     {`[{"chunk_id":"a","block_id":"` + kept + `","vector":[]}]`, "empty embedding"},
+		"not JSON":         {`{`, "failed to parse"},
+	} {
+		if _, err := store.ImportEmbeddingsFromJSON(write(tc.file)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: error = %v, want %q", name, err, tc.want)
+		}
+	}
+	if dims, _ := store.EmbeddingDimensions(); len(dims) != 1 {
+		t.Errorf("a rejected import stored vectors: %v", dims)
+	}
+}
@@ -0,0 +1,166 @@
+// ABOUTME: Storage for files ingested as notes (meeting notes, journals, ...)
+// ABOUTME: Each file becomes a paused topic; a changed file replaces the topic its last version became
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// IngestedFile records a file stored as a note: what it held when it was read and the
+// topic it became
+type IngestedFile struct {
+	Path       string    `json:"path"`
+	SHA256     string    `json:"sha256"`
+	Size       int64     `json:"size"`
+	ModTime    time.Time `json:"mod_time"`
+	BlockID    string    `json:"block_id,omitempty"` // Empty once the topic is deleted
+	IngestedAt time.Time `json:"ingested_at"`
+}
+
+// GetIngestedFile returns the record of the file at path, or nil if it was never ingested
+func (s *Storage) GetIngestedFile(path string) (*IngestedFile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f := IngestedFile{Path: path}
+	var blockID sql.NullString
+	err := s.db.QueryRow(`
+		SELECT sha256, size, mod_time, block_id, ingested_at FROM ingested_files WHERE path = ?
+	`, path).Scan(&f.SHA256, &f.Size, &f.ModTime, &blockID, &f.IngestedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingested file: %w", err)
+	}
+	f.BlockID = blockID.String
+	return &f, nil
+}
+
+// ListIngestedFiles returns every ingested file, ordered by path
+func (s *Storage) ListIngestedFiles() ([]IngestedFile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`
+		SELECT path, sha256, size, mod_time, block_id, ingested_at FROM ingested_files ORDER BY path
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingested files: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var files []IngestedFile
+	for rows.Next() {
+		var f IngestedFile
+		var blockID sql.NullString
+		if err := rows.Scan(&f.Path, &f.SHA256, &f.Size, &f.ModTime, &blockID, &f.IngestedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ingested file: %w", err)
+		}
+		f.BlockID = blockID.String
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
+
+// StoreFileNote stores turns, the sections of file, as a new topic labeled label and
+// records file as ingested. The topic is PAUSED, so the conversation in progress stays
+// active, and dated by the file's modification time. A topic made from an earlier version
+// of the file is deleted first; facts learned from it are kept. source is attached to
+// the first turn to record where the note came from.
+func (s *Storage) StoreFileNote(file *IngestedFile, label string, turns []*models.Turn, source *models.Attachment) (blockID string, err error) {
+	if len(turns) == 0 {
+		return "", fmt.Errorf("no turns to store for %s", file.Path)
+	}
+	if source != nil {
+		if err := source.Validate(); err != nil {
+			return "", err
+		}
+	}
+	defer func() { // runs after the unlock below, so subscribers may use storage
+		if err == nil {
+			for _, turn := range turns {
+				s.publishTurn(blockID, turn)
+			}
+		}
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var previous sql.NullString
+	err = s.db.QueryRow("SELECT block_id FROM ingested_files WHERE path = ?", file.Path).Scan(&previous)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to get ingested file: %w", err)
+	}
+	if previous.Valid {
+		if err := s.blocks.Delete(previous.String); err != nil {
+			return "", fmt.Errorf("failed to delete previous note: %w", err)
+		}
+	}
+
+	now := s.db.now()
+	blockID = s.newBlockID(now)
+	block := &models.BridgeBlock{
+		BlockID:    blockID,
+		DayID:      file.ModTime.In(s.location()).Format("2006-01-02"),
+		TopicLabel: label,
+		Status:     models.StatusPaused,
+		CreatedAt:  file.ModTime,
+		UpdatedAt:  now,
+		TurnCount:  len(turns),
+	}
+	for _, turn := range turns {
+		block.Keywords = appendMissing(block.Keywords, turn.Keywords)
+	}
+	if err := s.blocks.Save(block); err != nil {
+		return "", fmt.Errorf("failed to save bridge block: %w", err)
+	}
+	for _, turn := range turns {
+		if err := s.turns.Save(blockID, turn); err != nil {
+			return "", fmt.Errorf("failed to save turn: %w", err)
+		}
+	}
+	if source != nil {
+		source.TurnID, source.BlockID = turns[0].TurnID, blockID
+		if err := s.attachments.Save(source); err != nil {
+			return "", err
+		}
+	}
+
+	file.BlockID, file.IngestedAt = blockID, now
+	if _, err := s.db.Exec(`
+		INSERT INTO ingested_files (path, sha256, size, mod_time, block_id, ingested_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET
+			sha256 = excluded.sha256,
+			size = excluded.size,
+			mod_time = excluded.mod_time,
+			block_id = excluded.block_id,
+			ingested_at = excluded.ingested_at
+	`, utcArgs([]interface{}{file.Path, file.SHA256, file.Size, file.ModTime, blockID, now})...); err != nil {
+		return "", fmt.Errorf("failed to record ingested file: %w", err)
+	}
+	return blockID, nil
+}
+
+// appendMissing appends the items of more that list does not already hold
+func appendMissing(list, more []string) []string {
+	for _, item := range more {
+		found := false
+		for _, existing := range list {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
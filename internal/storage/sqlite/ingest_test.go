@@ -0,0 +1,88 @@
+// ABOUTME: Tests for storing ingested files as notes
+// ABOUTME: Verifies notes are paused topics with their source attached and that a new version replaces the old
+
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestStoreFileNote(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	active, err := store.StoreTurn(&models.Turn{TurnID: "turn_chat", Timestamp: time.Now(), UserMessage: "chatting"})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	modTime := time.Date(2025, 3, 4, 9, 30, 0, 0, time.UTC)
+	store.SetLocation(time.UTC)
+	file := &IngestedFile{Path: "/notes/standup.md", SHA256: "aaa", Size: 10, ModTime: modTime}
+	source, err := models.NewAttachment(file.Path, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	turns := []*models.Turn{
+		{TurnID: "turn_note_1", Timestamp: modTime, UserMessage: "Standup notes", Keywords: []string{"standup"}},
+		{TurnID: "turn_note_2", Timestamp: modTime, UserMessage: "Action items", Keywords: []string{"standup", "todo"}},
+	}
+	first, err := store.StoreFileNote(file, "standup", turns, source)
+	if err != nil {
+		t.Fatalf("StoreFileNote() error = %v", err)
+	}
+
+	block, err := store.GetBridgeBlock(first)
+	if err != nil || block == nil {
+		t.Fatalf("GetBridgeBlock() = %v, %v", block, err)
+	}
+	if block.Status != models.StatusPaused || block.TopicLabel != "standup" || block.DayID != "2025-03-04" || len(block.Turns) != 2 {
+		t.Errorf("note block = %+v, want a paused two-turn topic dated by the file", block)
+	}
+	if len(block.Keywords) != 2 {
+		t.Errorf("note keywords = %v, want them merged from every section", block.Keywords)
+	}
+	if still, _ := store.GetBridgeBlock(active); still == nil || still.Status != models.StatusActive {
+		t.Error("storing a note paused the conversation in progress")
+	}
+	attachments, err := store.GetBlockAttachments(first)
+	if err != nil || len(attachments) != 1 || attachments[0].Ref != file.Path {
+		t.Errorf("note attachments = %+v, %v; want the source file", attachments, err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_note", BlockID: first, TurnID: "turn_note_2", Key: "owner",
+		Value: "Ana", Confidence: 1, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	recorded, err := store.GetIngestedFile(file.Path)
+	if err != nil || recorded == nil || recorded.BlockID != first || recorded.SHA256 != "aaa" || !recorded.ModTime.Equal(modTime) {
+		t.Fatalf("GetIngestedFile() = %+v, %v", recorded, err)
+	}
+	if missing, err := store.GetIngestedFile("/notes/other.md"); err != nil || missing != nil {
+		t.Errorf("GetIngestedFile() for a new file = %+v, %v; want nil", missing, err)
+	}
+
+	// A new version replaces the note the old one became, keeping its facts
+	file = &IngestedFile{Path: file.Path, SHA256: "bbb", Size: 12, ModTime: modTime.Add(time.Hour)}
+	second, err := store.StoreFileNote(file, "standup",
+		[]*models.Turn{{TurnID: "turn_note_3", Timestamp: file.ModTime, UserMessage: "Revised notes"}}, nil)
+	if err != nil {
+		t.Fatalf("StoreFileNote() again error = %v", err)
+	}
+	if old, _ := store.GetBridgeBlock(first); old != nil {
+		t.Error("the previous version's note was not deleted")
+	}
+	if fact, _ := store.GetFactByKey("owner"); fact == nil {
+		t.Error("replacing a note deleted the facts learned from it")
+	}
+	files, err := store.ListIngestedFiles()
+	if err != nil || len(files) != 1 || files[0].BlockID != second || files[0].SHA256 != "bbb" {
+		t.Errorf("ListIngestedFiles() = %+v, %v", files, err)
+	}
+}
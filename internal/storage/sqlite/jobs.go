@@ -0,0 +1,605 @@
+// ABOUTME: Background job queue persistence for SQLite
+// ABOUTME: Jobs are claimed atomically, retried with a delay, and reclaimed when a worker dies mid-job
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// jobLease is how long a job may stay running without its lease being renewed before
+// it is assumed abandoned (its process exited mid-job) and another worker may claim it
+const jobLease = 10 * time.Minute
+
+// jobColumns selects a job for scanJob
+const jobColumns = `id, kind, payload, status, attempts, max_attempts, last_error, run_after, created_at, updated_at`
+
+// JobStore handles background job persistence
+type JobStore struct {
+	db *DB
+
+	// Profile messages merged into a waiting job or dropped by this process
+	profilesMerged  atomic.Int64
+	profilesDropped atomic.Int64
+}
+
+// JobQueueStats is the depth of the job queue by kind, with what this process did to
+// keep profile learning bounded
+type JobQueueStats struct {
+	Pending         map[models.JobKind]int `json:"pending"`                  // Pending jobs of each kind
+	ProfilesMerged  int64                  `json:"profile_messages_merged"`  // Messages folded into a waiting profile job
+	ProfilesDropped int64                  `json:"profile_messages_dropped"` // Messages dropped because the profile queue was full
+}
+
+// profileMessageSeparator joins messages merged into one profile job
+const profileMessageSeparator = "\n\n"
+
+// NewJobStore creates a new JobStore
+func NewJobStore(db *DB) *JobStore {
+	return &JobStore{db: db}
+}
+
+// execer runs a write on the database or inside a transaction
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// Enqueue adds a pending job that may run right away
+func (s *JobStore) Enqueue(kind models.JobKind, payload []byte, maxAttempts int) (*models.Job, error) {
+	return insertJob(s.db, kind, payload, maxAttempts, s.db.now())
+}
+
+// EnqueueProfile queues a profile learning job, keeping the queue bounded when an agent
+// stores turns faster than the Scribe learns from them. The message is merged into the
+// newest profile job for the same persona that has not started yet, as long as the
+// merged message stays within maxMessage bytes. Otherwise a new job is queued, unless
+// limit profile jobs are already pending, in which case the message is dropped and nil
+// is returned. merged reports whether the message joined an existing job.
+func (s *JobStore) EnqueueProfile(payload models.ProfileJob, maxAttempts, maxMessage, limit int) (job *models.Job, merged bool, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var (
+		id       int64
+		existing string
+	)
+	err = tx.QueryRow(`
+		SELECT id, payload FROM jobs
+		WHERE kind = ? AND status = ? AND attempts = 0 AND COALESCE(json_extract(payload, '$.persona'), '') = ?
+		ORDER BY id DESC LIMIT 1
+	`, string(models.JobUpdateProfile), string(models.JobPending), payload.Persona).Scan(&id, &existing)
+	switch {
+	case err == nil:
+		var waiting models.ProfileJob
+		if err := json.Unmarshal([]byte(existing), &waiting); err != nil {
+			return nil, false, fmt.Errorf("invalid %s payload: %w", models.JobUpdateProfile, err)
+		}
+		if len(waiting.Message)+len(profileMessageSeparator)+len(payload.Message) <= maxMessage {
+			waiting.Message += profileMessageSeparator + payload.Message
+			waiting.TurnIDs = append(waiting.TurnIDs, payload.TurnIDs...)
+			waiting.Merged++
+			data, err := json.Marshal(waiting)
+			if err != nil {
+				return nil, false, err
+			}
+			if _, err := tx.Exec("UPDATE jobs SET payload = ?, updated_at = ? WHERE id = ?", string(data), s.db.now().UTC(), id); err != nil {
+				return nil, false, err
+			}
+			job, err := scanJob(tx.QueryRow("SELECT "+jobColumns+" FROM jobs WHERE id = ?", id))
+			if err != nil {
+				return nil, false, err
+			}
+			if err := tx.Commit(); err != nil {
+				return nil, false, err
+			}
+			s.profilesMerged.Add(1)
+			return job, true, nil
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, false, err
+	}
+
+	var pending int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM jobs WHERE kind = ? AND status = ?",
+		string(models.JobUpdateProfile), string(models.JobPending)).Scan(&pending); err != nil {
+		return nil, false, err
+	}
+	if pending >= limit {
+		s.profilesDropped.Add(1)
+		return nil, false, nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, false, err
+	}
+	job, err = insertJob(tx, models.JobUpdateProfile, data, maxAttempts, s.db.now())
+	if err != nil {
+		return nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return job, false, nil
+}
+
+// insertJob adds a pending job through ex, created at now
+func insertJob(ex execer, kind models.JobKind, payload []byte, maxAttempts int, now time.Time) (*models.Job, error) {
+	now = now.UTC()
+	result, err := ex.Exec(`
+		INSERT INTO jobs (kind, payload, status, attempts, max_attempts, run_after, created_at, updated_at)
+		VALUES (?, ?, ?, 0, ?, ?, ?, ?)
+	`, string(kind), string(payload), string(models.JobPending), maxAttempts, now, now, now)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return &models.Job{
+		ID:          id,
+		Kind:        kind,
+		Payload:     payload,
+		Status:      models.JobPending,
+		MaxAttempts: maxAttempts,
+		RunAfter:    now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// Get returns a job by ID, or nil if it does not exist
+func (s *JobStore) Get(id int64) (*models.Job, error) {
+	job, err := scanJob(s.db.QueryRow("SELECT "+jobColumns+" FROM jobs WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return job, err
+}
+
+// ClaimNext marks the next due job of one of kinds (any kind when empty) as running
+// and returns it, or nil when there is nothing to do
+func (s *JobStore) ClaimNext(kinds []models.JobKind, now time.Time) (*models.Job, error) {
+	query := `SELECT id FROM jobs
+		WHERE ((status = ? AND run_after <= ?) OR (status = ? AND updated_at < ?))`
+	args := []interface{}{string(models.JobPending), now.UTC(), string(models.JobRunning), now.Add(-jobLease).UTC()}
+	if len(kinds) > 0 {
+		query += " AND kind IN (?" + strings.Repeat(", ?", len(kinds)-1) + ")"
+		for _, kind := range kinds {
+			args = append(args, string(kind))
+		}
+	}
+	query += " ORDER BY run_after ASC, id ASC LIMIT 1"
+	return s.claim(query, args, now)
+}
+
+// Claim marks job id as running and returns it, ignoring when it is due. It returns
+// nil if the job does not exist, has finished, or is running in another worker.
+func (s *JobStore) Claim(id int64, now time.Time) (*models.Job, error) {
+	return s.claim(`SELECT id FROM jobs WHERE id = ? AND (status = ? OR (status = ? AND updated_at < ?))`,
+		[]interface{}{id, string(models.JobPending), string(models.JobRunning), now.Add(-jobLease).UTC()}, now)
+}
+
+// claim runs selectQuery to pick a job and marks it running in the same transaction,
+// so two workers never claim one job
+func (s *JobStore) claim(selectQuery string, args []interface{}, now time.Time) (*models.Job, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Abandoned jobs with no attempts left fail instead of being reclaimed, so a job
+	// that kills its worker every time cannot run forever
+	expired := now.Add(-jobLease).UTC()
+	if _, err := tx.Exec(`
+		UPDATE turns SET pending_enrichment = 1 WHERE id IN (
+			SELECT `+jobTurn+` FROM jobs WHERE status = ? AND updated_at < ? AND attempts >= max_attempts)
+	`, string(models.JobRunning), expired); err != nil {
+		return nil, fmt.Errorf("failed to update turn enrichment: %w", err)
+	}
+	if _, err := tx.Exec(`
+		UPDATE jobs SET status = ?, last_error = 'abandoned with no attempts left', updated_at = ?
+		WHERE status = ? AND updated_at < ? AND attempts >= max_attempts
+	`, string(models.JobFailed), now.UTC(), string(models.JobRunning), expired); err != nil {
+		return nil, fmt.Errorf("failed to fail abandoned jobs: %w", err)
+	}
+
+	var id int64
+	if err := tx.QueryRow(selectQuery, args...).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if err := tx.Commit(); err != nil {
+				return nil, fmt.Errorf("failed to fail abandoned jobs: %w", err)
+			}
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find job: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE jobs SET status = ?, attempts = attempts + 1, updated_at = ? WHERE id = ?
+	`, string(models.JobRunning), now.UTC(), id); err != nil {
+		return nil, fmt.Errorf("failed to claim job %d: %w", id, err)
+	}
+
+	job, err := scanJob(tx.QueryRow("SELECT "+jobColumns+" FROM jobs WHERE id = ?", id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job %d: %w", id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to claim job %d: %w", id, err)
+	}
+	return job, nil
+}
+
+// Renew extends the lease on running job id, so a job that takes longer than jobLease
+// is not reclaimed while its worker is still running it. It reports whether the job
+// was still running.
+func (s *JobStore) Renew(id int64, now time.Time) (bool, error) {
+	result, err := s.db.Exec("UPDATE jobs SET updated_at = ? WHERE id = ? AND status = ?",
+		now.UTC(), id, string(models.JobRunning))
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// jobTurn selects the turn a job enriches (NULL for jobs not about a turn); it matches
+// the idx_jobs_turn index
+const jobTurn = `json_extract(payload, '$.turn_id')`
+
+// Complete marks a job done. When it was the turn's last unfinished job, the turn no
+// longer waits on enrichment.
+func (s *JobStore) Complete(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`
+		UPDATE jobs SET status = ?, last_error = NULL, updated_at = ? WHERE id = ?
+	`, string(models.JobDone), s.db.now().UTC(), id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE turns SET pending_enrichment = 0
+		WHERE pending_enrichment = 1
+			AND id = (SELECT `+jobTurn+` FROM jobs WHERE id = ?)
+			AND NOT EXISTS (SELECT 1 FROM jobs WHERE `+jobTurn+` = turns.id AND status != ?)
+	`, id, string(models.JobDone)); err != nil {
+		return fmt.Errorf("failed to update turn enrichment: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Fail records a failed run. A non-zero retryAt puts the job back in the queue until
+// then; a zero retryAt marks it failed for good. Either way the job's turn, if it
+// has one, is marked as waiting on enrichment.
+func (s *JobStore) Fail(id int64, message string, retryAt time.Time) error {
+	status := models.JobPending
+	if retryAt.IsZero() {
+		status, retryAt = models.JobFailed, s.db.now()
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`
+		UPDATE jobs SET status = ?, last_error = ?, run_after = ?, updated_at = ? WHERE id = ?
+	`, string(status), message, retryAt.UTC(), s.db.now().UTC(), id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE turns SET pending_enrichment = 1 WHERE id = (SELECT `+jobTurn+` FROM jobs WHERE id = ?)
+	`, id); err != nil {
+		return fmt.Errorf("failed to update turn enrichment: %w", err)
+	}
+	return tx.Commit()
+}
+
+// List returns up to limit jobs with status (every status when empty), newest first
+func (s *JobStore) List(status models.JobStatus, limit int) ([]models.Job, error) {
+	query := "SELECT " + jobColumns + " FROM jobs"
+	var args []interface{}
+	if status != "" {
+		query += " WHERE status = ?"
+		args = append(args, string(status))
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var jobs []models.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+// Counts returns the number of jobs in each status
+func (s *JobStore) Counts() (map[models.JobStatus]int, error) {
+	rows, err := s.db.Query("SELECT status, COUNT(*) FROM jobs GROUP BY status")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := make(map[models.JobStatus]int)
+	for rows.Next() {
+		var (
+			status string
+			n      int
+		)
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[models.JobStatus(status)] = n
+	}
+	return counts, rows.Err()
+}
+
+// PendingByKind returns the number of pending jobs of each kind
+func (s *JobStore) PendingByKind() (map[models.JobKind]int, error) {
+	rows, err := s.db.Query("SELECT kind, COUNT(*) FROM jobs WHERE status = ? GROUP BY kind", string(models.JobPending))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := make(map[models.JobKind]int)
+	for rows.Next() {
+		var (
+			kind string
+			n    int
+		)
+		if err := rows.Scan(&kind, &n); err != nil {
+			return nil, err
+		}
+		counts[models.JobKind(kind)] = n
+	}
+	return counts, rows.Err()
+}
+
+// OldestDue returns when the longest-waiting due job became due, or the zero time
+// when no pending job is due
+func (s *JobStore) OldestDue(now time.Time) (time.Time, error) {
+	var runAfter time.Time
+	err := s.db.QueryRow(`
+		SELECT run_after FROM jobs WHERE status = ? AND run_after <= ? ORDER BY run_after ASC LIMIT 1
+	`, string(models.JobPending), now.UTC()).Scan(&runAfter)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return runAfter, err
+}
+
+// Retry puts failed jobs back in the queue with fresh attempts: those in ids, or every
+// failed job when ids is empty. It returns how many were requeued.
+func (s *JobStore) Retry(ids []int64) (int, error) {
+	now := s.db.now()
+	query := `UPDATE jobs SET status = ?, attempts = 0, run_after = ?, updated_at = ? WHERE status = ?`
+	args := []interface{}{string(models.JobPending), now, now, string(models.JobFailed)}
+	if len(ids) > 0 {
+		query += " AND id IN (?" + strings.Repeat(", ?", len(ids)-1) + ")"
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// RetrySince puts jobs that failed at or after since back in the queue with fresh
+// attempts and returns how many were requeued
+func (s *JobStore) RetrySince(since time.Time) (int, error) {
+	now := s.db.now()
+	result, err := s.db.Exec(`
+		UPDATE jobs SET status = ?, attempts = 0, run_after = ?, updated_at = ? WHERE status = ? AND updated_at >= ?
+	`, string(models.JobPending), now, now, string(models.JobFailed), since)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// Purge deletes jobs that finished before cutoff and returns how many were removed
+func (s *JobStore) Purge(cutoff time.Time) (int, error) {
+	result, err := s.db.Exec("DELETE FROM jobs WHERE status = ? AND updated_at < ?", string(models.JobDone), cutoff)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// scanJob scans a row selected with jobColumns
+func scanJob(row interface{ Scan(...interface{}) error }) (*models.Job, error) {
+	var (
+		job       models.Job
+		kind      string
+		payload   string
+		status    string
+		lastError sql.NullString
+	)
+	if err := row.Scan(&job.ID, &kind, &payload, &status, &job.Attempts, &job.MaxAttempts,
+		&lastError, &job.RunAfter, &job.CreatedAt, &job.UpdatedAt); err != nil {
+		return nil, err
+	}
+	job.Kind = models.JobKind(kind)
+	job.Payload = json.RawMessage(payload)
+	job.Status = models.JobStatus(status)
+	job.LastError = lastError.String
+	return &job, nil
+}
+
+// EnqueueJob queues a job of kind with payload marshaled as JSON
+func (s *Storage) EnqueueJob(kind models.JobKind, payload any) (*models.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", kind, err)
+	}
+	job, err := s.jobs.Enqueue(kind, data, models.DefaultJobAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enqueue %s job: %w", kind, err)
+	}
+	return job, nil
+}
+
+// EnqueueProfileJob queues profile learning for payload, merging it into a waiting job
+// for the same persona while the merged message stays within maxMessage bytes. When
+// limit profile jobs are already pending the message is dropped and the job is nil;
+// merged reports whether it joined an existing job.
+func (s *Storage) EnqueueProfileJob(payload models.ProfileJob, maxMessage, limit int) (job *models.Job, merged bool, err error) {
+	job, merged, err = s.jobs.EnqueueProfile(payload, models.DefaultJobAttempts, maxMessage, limit)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to enqueue %s job: %w", models.JobUpdateProfile, err)
+	}
+	return job, merged, nil
+}
+
+// GetJob returns a job by ID, or nil if it does not exist
+func (s *Storage) GetJob(id int64) (*models.Job, error) {
+	job, err := s.jobs.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// ClaimNextJob claims the next due job of one of kinds (any kind when none are
+// given), or returns nil when none is due
+func (s *Storage) ClaimNextJob(kinds ...models.JobKind) (*models.Job, error) {
+	return s.jobs.ClaimNext(kinds, s.db.now())
+}
+
+// ClaimJob claims job id whether or not it is due, or returns nil if it is finished
+// or being run elsewhere
+func (s *Storage) ClaimJob(id int64) (*models.Job, error) {
+	return s.jobs.Claim(id, s.db.now())
+}
+
+// RenewJob extends the lease on a claimed job that is still running, reporting
+// whether it was still running
+func (s *Storage) RenewJob(id int64) (bool, error) {
+	running, err := s.jobs.Renew(id, s.db.now())
+	if err != nil {
+		return false, fmt.Errorf("failed to renew job %d: %w", id, err)
+	}
+	return running, nil
+}
+
+// CompleteJob marks a claimed job done
+func (s *Storage) CompleteJob(id int64) error {
+	if err := s.jobs.Complete(id); err != nil {
+		return fmt.Errorf("failed to complete job %d: %w", id, err)
+	}
+	return nil
+}
+
+// FailJob records that a claimed job's run failed with runErr, retrying it at retryAt
+// or, when retryAt is zero, marking it failed
+func (s *Storage) FailJob(id int64, runErr error, retryAt time.Time) error {
+	if err := s.jobs.Fail(id, runErr.Error(), retryAt); err != nil {
+		return fmt.Errorf("failed to record job %d failure: %w", id, err)
+	}
+	return nil
+}
+
+// ListJobs returns up to limit jobs with status (every status when empty), newest first
+func (s *Storage) ListJobs(status models.JobStatus, limit int) ([]models.Job, error) {
+	jobs, err := s.jobs.List(status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// JobCounts returns the number of jobs in each status
+func (s *Storage) JobCounts() (map[models.JobStatus]int, error) {
+	counts, err := s.jobs.Counts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	return counts, nil
+}
+
+// JobQueueStats returns the pending jobs of each kind and how many profile messages
+// this process merged or dropped
+func (s *Storage) JobQueueStats() (JobQueueStats, error) {
+	pending, err := s.jobs.PendingByKind()
+	if err != nil {
+		return JobQueueStats{}, fmt.Errorf("failed to count pending jobs: %w", err)
+	}
+	return JobQueueStats{
+		Pending:         pending,
+		ProfilesMerged:  s.jobs.profilesMerged.Load(),
+		ProfilesDropped: s.jobs.profilesDropped.Load(),
+	}, nil
+}
+
+// OldestDueJob returns when the longest-waiting due job became due, or the zero time
+// when the queue has caught up
+func (s *Storage) OldestDueJob() (time.Time, error) {
+	runAfter, err := s.jobs.OldestDue(s.db.now())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to find the oldest due job: %w", err)
+	}
+	return runAfter, nil
+}
+
+// RetryJobs requeues failed jobs (all of them when ids is empty) and returns how many
+func (s *Storage) RetryJobs(ids ...int64) (int, error) {
+	n, err := s.jobs.Retry(ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retry jobs: %w", err)
+	}
+	return n, nil
+}
+
+// RetryJobsFailedSince requeues jobs that failed at or after since and returns how many
+func (s *Storage) RetryJobsFailedSince(since time.Time) (int, error) {
+	n, err := s.jobs.RetrySince(since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retry jobs: %w", err)
+	}
+	return n, nil
+}
+
+// PurgeJobs deletes jobs that finished before cutoff and returns how many
+func (s *Storage) PurgeJobs(cutoff time.Time) (int, error) {
+	n, err := s.jobs.Purge(cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge jobs: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,360 @@
+// ABOUTME: Tests for the background job queue storage
+// ABOUTME: Verifies claiming, retry scheduling, stale job recovery, retry, purge, pending enrichment, and profile merging
+
+package sqlite
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func newJobStorage(t *testing.T) *Storage {
+	t.Helper()
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestJobs_EnqueueClaimComplete(t *testing.T) {
+	store := newJobStorage(t)
+
+	embed, err := store.EnqueueJob(models.JobEmbedTurn, models.TurnJob{TurnID: "turn_1", BlockID: "block_1"})
+	if err != nil {
+		t.Fatalf("EnqueueJob() error = %v", err)
+	}
+	if _, err := store.EnqueueJob(models.JobUpdateProfile, models.ProfileJob{Message: "I like tea"}); err != nil {
+		t.Fatalf("EnqueueJob() error = %v", err)
+	}
+
+	if oldest, err := store.OldestDueJob(); err != nil || !oldest.Equal(embed.RunAfter) {
+		t.Errorf("OldestDueJob() = %v, %v; want the first job's run time %v", oldest, err, embed.RunAfter)
+	}
+
+	// Only kinds the caller can handle are claimed
+	job, err := store.ClaimNextJob(models.JobUpdateProfile)
+	if err != nil {
+		t.Fatalf("ClaimNextJob() error = %v", err)
+	}
+	if job == nil || job.Kind != models.JobUpdateProfile {
+		t.Fatalf("ClaimNextJob(update_profile) = %+v, want the profile job", job)
+	}
+	if job.Status != models.JobRunning || job.Attempts != 1 {
+		t.Errorf("claimed job status/attempts = %s/%d, want running/1", job.Status, job.Attempts)
+	}
+	var payload models.ProfileJob
+	if err := job.Decode(&payload); err != nil || payload.Message != "I like tea" {
+		t.Errorf("Decode() = %+v, %v; want the enqueued message", payload, err)
+	}
+
+	// A running job is not claimed again
+	if again, err := store.ClaimNextJob(models.JobUpdateProfile); err != nil || again != nil {
+		t.Errorf("ClaimNextJob() while running = %+v, %v; want nil", again, err)
+	}
+	if err := store.CompleteJob(job.ID); err != nil {
+		t.Fatalf("CompleteJob() error = %v", err)
+	}
+
+	job, err = store.ClaimNextJob()
+	if err != nil {
+		t.Fatalf("ClaimNextJob() error = %v", err)
+	}
+	if job == nil || job.ID != embed.ID {
+		t.Fatalf("ClaimNextJob() = %+v, want the embed job", job)
+	}
+
+	counts, err := store.JobCounts()
+	if err != nil {
+		t.Fatalf("JobCounts() error = %v", err)
+	}
+	if counts[models.JobDone] != 1 || counts[models.JobRunning] != 1 {
+		t.Errorf("JobCounts() = %v, want 1 done and 1 running", counts)
+	}
+}
+
+func TestJobs_FailRetryAndPurge(t *testing.T) {
+	store := newJobStorage(t)
+
+	queued, err := store.EnqueueJob(models.JobExtractFacts, models.TurnJob{TurnID: "turn_1"})
+	if err != nil {
+		t.Fatalf("EnqueueJob() error = %v", err)
+	}
+	job, err := store.ClaimNextJob()
+	if err != nil || job == nil {
+		t.Fatalf("ClaimNextJob() = %+v, %v", job, err)
+	}
+
+	// A retry scheduled in the future is not due yet
+	if err := store.FailJob(job.ID, errors.New("rate limited"), time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("FailJob() error = %v", err)
+	}
+	if due, err := store.ClaimNextJob(); err != nil || due != nil {
+		t.Errorf("ClaimNextJob() before retry time = %+v, %v; want nil", due, err)
+	}
+	if oldest, err := store.OldestDueJob(); err != nil || !oldest.IsZero() {
+		t.Errorf("OldestDueJob() before retry time = %v, %v; want the zero time", oldest, err)
+	}
+	got, err := store.GetJob(queued.ID)
+	if err != nil {
+		t.Fatalf("GetJob() error = %v", err)
+	}
+	if got.Status != models.JobPending || got.LastError != "rate limited" {
+		t.Errorf("after retryable failure = %s %q, want pending with the error", got.Status, got.LastError)
+	}
+
+	// Claiming by ID ignores the schedule; a zero retry time fails the job for good
+	job, err = store.ClaimJob(queued.ID)
+	if err != nil || job == nil {
+		t.Fatalf("ClaimJob() = %+v, %v", job, err)
+	}
+	if job.Attempts != 2 {
+		t.Errorf("Attempts = %d, want 2", job.Attempts)
+	}
+	if err := store.FailJob(job.ID, errors.New("bad key"), time.Time{}); err != nil {
+		t.Fatalf("FailJob() error = %v", err)
+	}
+	failed, err := store.ListJobs(models.JobFailed, 10)
+	if err != nil {
+		t.Fatalf("ListJobs() error = %v", err)
+	}
+	if len(failed) != 1 || failed[0].LastError != "bad key" {
+		t.Fatalf("failed jobs = %+v, want the job with its last error", failed)
+	}
+
+	n, err := store.RetryJobs()
+	if err != nil || n != 1 {
+		t.Fatalf("RetryJobs() = %d, %v; want 1", n, err)
+	}
+	job, err = store.ClaimNextJob()
+	if err != nil || job == nil {
+		t.Fatalf("ClaimNextJob() after retry = %+v, %v", job, err)
+	}
+	if job.Attempts != 1 {
+		t.Errorf("Attempts after retry = %d, want a fresh count of 1", job.Attempts)
+	}
+	if err := store.CompleteJob(job.ID); err != nil {
+		t.Fatalf("CompleteJob() error = %v", err)
+	}
+
+	if n, err := store.PurgeJobs(time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Errorf("PurgeJobs(an hour ago) = %d, %v; want nothing that recent", n, err)
+	}
+	if n, err := store.PurgeJobs(time.Now().Add(time.Second)); err != nil || n != 1 {
+		t.Errorf("PurgeJobs(now) = %d, %v; want the finished job", n, err)
+	}
+}
+
+func TestJobs_AbandonedJobIsReclaimed(t *testing.T) {
+	store := newJobStorage(t)
+
+	if _, err := store.EnqueueJob(models.JobEmbedTurn, models.TurnJob{TurnID: "turn_1"}); err != nil {
+		t.Fatalf("EnqueueJob() error = %v", err)
+	}
+	start := time.Now()
+	job, err := store.jobs.ClaimNext(nil, start)
+	if err != nil || job == nil {
+		t.Fatalf("ClaimNext() = %+v, %v", job, err)
+	}
+
+	if again, err := store.jobs.ClaimNext(nil, start.Add(jobLease/2)); err != nil || again != nil {
+		t.Errorf("ClaimNext() within the lease = %+v, %v; want nil", again, err)
+	}
+	again, err := store.jobs.ClaimNext(nil, start.Add(jobLease+time.Minute))
+	if err != nil || again == nil {
+		t.Fatalf("ClaimNext() after the lease = %+v, %v; want the abandoned job", again, err)
+	}
+	if again.ID != job.ID || again.Attempts != 2 {
+		t.Errorf("reclaimed job = %d attempt %d, want %d attempt 2", again.ID, again.Attempts, job.ID)
+	}
+}
+
+func TestJobs_RenewedLeaseIsNotReclaimed(t *testing.T) {
+	store := newJobStorage(t)
+
+	if _, err := store.EnqueueJob(models.JobEmbedTurn, models.TurnJob{TurnID: "turn_1"}); err != nil {
+		t.Fatalf("EnqueueJob() error = %v", err)
+	}
+	start := time.Now()
+	job, err := store.jobs.ClaimNext(nil, start)
+	if err != nil || job == nil {
+		t.Fatalf("ClaimNext() = %+v, %v", job, err)
+	}
+
+	if running, err := store.jobs.Renew(job.ID, start.Add(jobLease-time.Minute)); err != nil || !running {
+		t.Fatalf("Renew() = %v, %v; want the job still running", running, err)
+	}
+	if again, err := store.jobs.ClaimNext(nil, start.Add(jobLease+time.Minute)); err != nil || again != nil {
+		t.Errorf("ClaimNext() within the renewed lease = %+v, %v; want nil", again, err)
+	}
+
+	if err := store.CompleteJob(job.ID); err != nil {
+		t.Fatalf("CompleteJob() error = %v", err)
+	}
+	if running, err := store.RenewJob(job.ID); err != nil || running {
+		t.Errorf("RenewJob() after completion = %v, %v; want false", running, err)
+	}
+}
+
+func TestJobs_AbandonedJobFailsWithoutAttemptsLeft(t *testing.T) {
+	store := newJobStorage(t)
+
+	turn := &models.Turn{TurnID: "turn_1", Timestamp: time.Now(), UserMessage: "Moved to Postgres"}
+	if _, err := store.StoreTurn(turn); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if _, err := store.jobs.Enqueue(models.JobEmbedTurn, []byte(`{"turn_id":"turn_1"}`), 1); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	start := time.Now()
+	job, err := store.jobs.ClaimNext(nil, start)
+	if err != nil || job == nil {
+		t.Fatalf("ClaimNext() = %+v, %v", job, err)
+	}
+
+	if again, err := store.jobs.ClaimNext(nil, start.Add(jobLease+time.Minute)); err != nil || again != nil {
+		t.Fatalf("ClaimNext() after the lease with no attempts left = %+v, %v; want nil", again, err)
+	}
+	failed, err := store.GetJob(job.ID)
+	if err != nil || failed == nil {
+		t.Fatalf("GetJob() = %+v, %v", failed, err)
+	}
+	if failed.Status != models.JobFailed || failed.Attempts != 1 || failed.LastError == "" {
+		t.Errorf("abandoned job = %s after %d attempts (%q), want failed after 1 with an error", failed.Status, failed.Attempts, failed.LastError)
+	}
+	_, stored, err := store.GetTurn("turn_1")
+	if err != nil || stored == nil || !stored.PendingEnrichment {
+		t.Errorf("turn after its job failed = %+v, %v; want it waiting on enrichment", stored, err)
+	}
+}
+
+func TestJobs_PendingEnrichment(t *testing.T) {
+	store := newJobStorage(t)
+
+	turn := &models.Turn{TurnID: "turn_1", Timestamp: time.Now(), UserMessage: "Moved to Postgres", PendingEnrichment: true}
+	if _, err := store.StoreTurn(turn); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	pending := func() int {
+		t.Helper()
+		n, err := store.PendingEnrichmentCount()
+		if err != nil {
+			t.Fatalf("PendingEnrichmentCount() error = %v", err)
+		}
+		return n
+	}
+	if pending() != 1 {
+		t.Fatalf("PendingEnrichmentCount() = %d after storing a pending turn, want 1", pending())
+	}
+
+	for _, kind := range []models.JobKind{models.JobEmbedTurn, models.JobExtractMetadata} {
+		if _, err := store.EnqueueJob(kind, models.TurnJob{TurnID: turn.TurnID}); err != nil {
+			t.Fatalf("EnqueueJob() error = %v", err)
+		}
+	}
+
+	// The turn waits until every one of its jobs is done
+	first, _ := store.ClaimNextJob()
+	if err := store.CompleteJob(first.ID); err != nil {
+		t.Fatalf("CompleteJob() error = %v", err)
+	}
+	if pending() != 1 {
+		t.Errorf("PendingEnrichmentCount() with a job left = %d, want 1", pending())
+	}
+	second, _ := store.ClaimNextJob()
+	if err := store.CompleteJob(second.ID); err != nil {
+		t.Fatalf("CompleteJob() error = %v", err)
+	}
+	if pending() != 0 {
+		t.Errorf("PendingEnrichmentCount() after its jobs finished = %d, want 0", pending())
+	}
+
+	// A failure marks the turn pending again, and requeueing the failure backfills it
+	before := time.Now().Add(-time.Second)
+	if _, err := store.EnqueueJob(models.JobExtractFacts, models.TurnJob{TurnID: turn.TurnID}); err != nil {
+		t.Fatalf("EnqueueJob() error = %v", err)
+	}
+	job, _ := store.ClaimNextJob()
+	if err := store.FailJob(job.ID, errors.New("connection refused"), time.Time{}); err != nil {
+		t.Fatalf("FailJob() error = %v", err)
+	}
+	if pending() != 1 {
+		t.Errorf("PendingEnrichmentCount() after a failure = %d, want 1", pending())
+	}
+	if n, err := store.RetryJobsFailedSince(time.Now().Add(time.Hour)); err != nil || n != 0 {
+		t.Errorf("RetryJobsFailedSince(later) = %d, %v; want 0", n, err)
+	}
+	if n, err := store.RetryJobsFailedSince(before); err != nil || n != 1 {
+		t.Fatalf("RetryJobsFailedSince(before) = %d, %v; want 1", n, err)
+	}
+	job, _ = store.ClaimNextJob()
+	if job == nil || job.Attempts != 1 {
+		t.Fatalf("ClaimNextJob() after requeue = %+v, want a fresh attempt", job)
+	}
+	if err := store.CompleteJob(job.ID); err != nil {
+		t.Fatalf("CompleteJob() error = %v", err)
+	}
+	if pending() != 0 {
+		t.Errorf("PendingEnrichmentCount() after the backfill = %d, want 0", pending())
+	}
+}
+
+func TestJobs_EnqueueProfileMergesAndDrops(t *testing.T) {
+	store := newJobStorage(t)
+
+	turns := 0
+	enqueue := func(message, persona string, maxMessage, limit int) (*models.Job, bool) {
+		t.Helper()
+		turns++
+		turnID := "turn_" + string(rune('a'+turns-1))
+		job, merged, err := store.EnqueueProfileJob(models.ProfileJob{Message: message, Persona: persona, TurnIDs: []string{turnID}}, maxMessage, limit)
+		if err != nil {
+			t.Fatalf("EnqueueProfileJob(%q) error = %v", message, err)
+		}
+		return job, merged
+	}
+
+	first, merged := enqueue("I use vim", "", 100, 10)
+	if first == nil || merged {
+		t.Fatalf("first EnqueueProfileJob() = %+v, merged %v; want a new job", first, merged)
+	}
+
+	// A waiting job for the same persona collects later messages
+	job, merged := enqueue("I prefer tabs", "", 100, 10)
+	if job == nil || !merged || job.ID != first.ID {
+		t.Fatalf("second EnqueueProfileJob() = %+v, merged %v; want it merged into job %d", job, merged, first.ID)
+	}
+	var payload models.ProfileJob
+	if err := job.Decode(&payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Message != "I use vim\n\nI prefer tabs" || payload.Merged != 1 || strings.Join(payload.TurnIDs, ",") != "turn_a,turn_b" {
+		t.Errorf("merged payload = %+v, want both messages and their turns", payload)
+	}
+
+	// Other personas and messages that would make the merge too long get their own job
+	if job, merged := enqueue("I review PRs on Fridays", "work", 100, 10); job == nil || merged {
+		t.Errorf("EnqueueProfileJob(work) = %+v, merged %v; want a new job", job, merged)
+	}
+	if job, merged := enqueue(strings.Repeat("x", 90), "", 100, 10); job == nil || merged || job.ID == first.ID {
+		t.Errorf("EnqueueProfileJob(long) = %+v, merged %v; want a new job", job, merged)
+	}
+
+	// A full queue drops the message
+	if job, _ := enqueue("I like Go", "home", 100, 3); job != nil {
+		t.Errorf("EnqueueProfileJob() with 3 waiting and a limit of 3 = %+v, want it dropped", job)
+	}
+
+	stats, err := store.JobQueueStats()
+	if err != nil {
+		t.Fatalf("JobQueueStats() error = %v", err)
+	}
+	if stats.Pending[models.JobUpdateProfile] != 3 || stats.ProfilesMerged != 1 || stats.ProfilesDropped != 1 {
+		t.Errorf("JobQueueStats() = %+v, want 3 pending, 1 merged, 1 dropped", stats)
+	}
+}
@@ -0,0 +1,162 @@
+// ABOUTME: Keyword index for blocks, kept in the normalized block_keywords table
+// ABOUTME: Answers exact and prefix keyword matches with per-block match counts for routing and search
+package sqlite
+
+import (
+	sqldriver "database/sql/driver"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	driver "modernc.org/sqlite"
+)
+
+// minPrefixLength is the shortest term that prefix-matches keywords; shorter terms
+// only match exactly, so "go" does not match every keyword starting with it
+const minPrefixLength = 3
+
+// foldCase is how keywords and search terms are compared: lowercased, letter by letter.
+// It is also the fold_case SQL function, so migrations fold keywords the same way.
+func foldCase(s string) string {
+	return strings.ToLower(s)
+}
+
+func init() {
+	if err := driver.RegisterDeterministicScalarFunction("fold_case", 1, func(_ *driver.FunctionContext, args []sqldriver.Value) (sqldriver.Value, error) {
+		switch v := args[0].(type) {
+		case string:
+			return foldCase(v), nil
+		case []byte:
+			return foldCase(string(v)), nil
+		default:
+			return v, nil
+		}
+	}); err != nil {
+		panic(fmt.Sprintf("failed to register fold_case: %v", err))
+	}
+}
+
+// KeywordMatch counts how many of a set of terms matched one block's keywords
+type KeywordMatch struct {
+	Exact  int `json:"exact"`  // Terms equal to one of the block's keywords
+	Prefix int `json:"prefix"` // Other terms only starting one of them
+}
+
+// Total is the number of terms that matched either way
+func (m KeywordMatch) Total() int {
+	return m.Exact + m.Prefix
+}
+
+// setBlockKeywords replaces a block's keywords, keeping their order; keywords are
+// trimmed, and repeats differing only in case are dropped
+func setBlockKeywords(ex execer, blockID string, keywords []string) error {
+	if _, err := ex.Exec(`DELETE FROM block_keywords WHERE block_id = ?`, blockID); err != nil {
+		return fmt.Errorf("failed to clear block keywords: %w", err)
+	}
+	for i, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			continue
+		}
+		if _, err := ex.Exec(`INSERT OR IGNORE INTO block_keywords (block_id, keyword, folded, position) VALUES (?, ?, ?, ?)`,
+			blockID, keyword, foldCase(keyword), i); err != nil {
+			return fmt.Errorf("failed to save block keywords: %w", err)
+		}
+	}
+	return nil
+}
+
+// addBlockKeywords appends keywords a block does not have yet after the ones it has,
+// leaving the rest in place, so concurrent appends each add theirs
+func addBlockKeywords(ex execer, blockID string, keywords []string) error {
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			continue
+		}
+		if _, err := ex.Exec(`
+			INSERT OR IGNORE INTO block_keywords (block_id, keyword, folded, position)
+			SELECT ?, ?, ?, COALESCE(MAX(position), -1) + 1 FROM block_keywords WHERE block_id = ?
+		`, blockID, keyword, foldCase(keyword), blockID); err != nil {
+			return fmt.Errorf("failed to save block keywords: %w", err)
+		}
+	}
+	return nil
+}
+
+// MatchKeywords returns, for each block with a keyword matching any of terms, how
+// many terms matched. Matching ignores case; with prefix, terms of at least
+// minPrefixLength characters also match keywords they start. Repeated terms count once.
+func (s *BlockStore) MatchKeywords(terms []string, prefix bool) (map[string]KeywordMatch, error) {
+	terms = KeywordTerms(terms)
+	matches := make(map[string]KeywordMatch)
+	if len(terms) == 0 {
+		return matches, nil
+	}
+
+	// Each term is a range of keywords: just itself, or everything it starts
+	rows := make([]string, len(terms))
+	args := make([]interface{}, 0, 3*len(terms))
+	for i, term := range terms {
+		upper := term
+		if prefix && utf8.RuneCountInString(term) >= minPrefixLength {
+			upper = term + string(utf8.MaxRune)
+		}
+		rows[i] = "(?, ?, ?)"
+		args = append(args, term, term, upper)
+	}
+	result, err := s.db.Query(`
+		WITH terms(term, lower, upper) AS (VALUES `+strings.Join(rows, ", ")+`)
+		SELECT block_id, SUM(exact), COUNT(*) - SUM(exact) FROM (
+			SELECT k.block_id, t.term, MAX(k.folded = t.term) AS exact
+			FROM terms t
+			JOIN block_keywords k ON k.folded >= t.lower AND k.folded <= t.upper
+			GROUP BY k.block_id, t.term
+		)
+		GROUP BY block_id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match keywords: %w", err)
+	}
+	defer func() { _ = result.Close() }()
+
+	for result.Next() {
+		var (
+			blockID string
+			m       KeywordMatch
+		)
+		if err := result.Scan(&blockID, &m.Exact, &m.Prefix); err != nil {
+			return nil, fmt.Errorf("failed to scan keyword match: %w", err)
+		}
+		matches[blockID] = m
+	}
+	return matches, result.Err()
+}
+
+// KeywordTerms returns the distinct terms among keywords, trimmed and case-folded
+func KeywordTerms(keywords []string) []string {
+	seen := make(map[string]bool, len(keywords))
+	var terms []string
+	for _, keyword := range keywords {
+		term := foldCase(strings.TrimSpace(keyword))
+		if term == "" || seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// queryTerms splits a search query into keyword terms at anything that is not a
+// letter, digit, or one of the joiners keywords commonly contain (as in "c++" or "node.js")
+func queryTerms(query string) []string {
+	fields := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("+#.-_", r)
+	})
+	for i, field := range fields {
+		fields[i] = strings.Trim(field, ".-_")
+	}
+	return KeywordTerms(fields)
+}
@@ -0,0 +1,183 @@
+// ABOUTME: Tests for the block keyword index
+// ABOUTME: Verifies keyword order and case folding, exact and prefix matches, search relevance, and the upgrade migration
+package sqlite
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// storeKeywordBlock stores a turn with keywords as a new block and returns its ID
+func storeKeywordBlock(t *testing.T, store *Storage, turnID string, keywords ...string) string {
+	t.Helper()
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: turnID, Timestamp: time.Now(), UserMessage: turnID, Keywords: keywords})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	return blockID
+}
+
+func TestBlockKeywords_OrderAndCase(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID := storeKeywordBlock(t, store, "turn_kw_order", "Kubernetes", " helm ", "kubernetes", "")
+	if err := store.AppendTurnToBlock(blockID, &models.Turn{TurnID: "turn_kw_more", Timestamp: time.Now(), Keywords: []string{"HELM", "argo"}}); err != nil {
+		t.Fatalf("AppendTurnToBlock() error = %v", err)
+	}
+
+	block, err := store.GetBridgeBlock(blockID)
+	if err != nil {
+		t.Fatalf("GetBridgeBlock() error = %v", err)
+	}
+	if want := []string{"Kubernetes", "helm", "argo"}; !reflect.DeepEqual(block.Keywords, want) {
+		t.Errorf("Keywords = %v, want %v", block.Keywords, want)
+	}
+}
+
+func TestMatchBlockKeywords(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	k8s := storeKeywordBlock(t, store, "turn_kw_k8s", "kubernetes", "deployment", "go")
+	garden := storeKeywordBlock(t, store, "turn_kw_garden", "tomatoes", "golang")
+
+	exact, err := store.MatchBlockKeywords([]string{"Kubernetes", "deployment", "kubernetes", "kube"}, false)
+	if err != nil {
+		t.Fatalf("MatchBlockKeywords() error = %v", err)
+	}
+	if want := map[string]KeywordMatch{k8s: {Exact: 2}}; !reflect.DeepEqual(exact, want) {
+		t.Errorf("exact matches = %+v, want %+v", exact, want)
+	}
+
+	prefix, err := store.MatchBlockKeywords([]string{"kube", "deploy", "go", "tom"}, true)
+	if err != nil {
+		t.Fatalf("MatchBlockKeywords() error = %v", err)
+	}
+	// "go" is too short to prefix-match "golang"
+	want := map[string]KeywordMatch{k8s: {Exact: 1, Prefix: 2}, garden: {Prefix: 1}}
+	if !reflect.DeepEqual(prefix, want) {
+		t.Errorf("prefix matches = %+v, want %+v", prefix, want)
+	}
+
+	if none, err := store.MatchBlockKeywords(nil, true); err != nil || len(none) != 0 {
+		t.Errorf("MatchBlockKeywords(nil) = %v, %v; want no matches", none, err)
+	}
+}
+
+func TestMatchBlockKeywords_NonASCIICase(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID := storeKeywordBlock(t, store, "turn_kw_zurich", "Zürich", "ZÜRICH", "Ölkännchen")
+	block, err := store.GetBridgeBlock(blockID)
+	if err != nil {
+		t.Fatalf("GetBridgeBlock() error = %v", err)
+	}
+	if want := []string{"Zürich", "Ölkännchen"}; !reflect.DeepEqual(block.Keywords, want) {
+		t.Errorf("Keywords = %v, want %v", block.Keywords, want)
+	}
+
+	for _, query := range []string{"zürich", "ZÜRICH", "ölkänn"} {
+		if results := store.keywordSearch(query, 10, nil); len(results) != 1 || results[0].BlockID != blockID {
+			t.Errorf("keywordSearch(%q) = %+v, want the block whatever the case", query, results)
+		}
+	}
+}
+
+func TestKeywordSearch_Relevance(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	both := storeKeywordBlock(t, store, "turn_kw_both", "kubernetes", "helm")
+	one := storeKeywordBlock(t, store, "turn_kw_one", "kubernetes", "terraform")
+	storeKeywordBlock(t, store, "turn_kw_none", "gardening")
+
+	results := store.keywordSearch("how do I upgrade helm charts on kubernetes?", 10, nil)
+	if len(results) != 2 {
+		t.Fatalf("keywordSearch() = %d results, want 2", len(results))
+	}
+	if results[0].BlockID != both || results[1].BlockID != one {
+		t.Errorf("keywordSearch() order = %s, %s; want the block matching more words first", results[0].BlockID, results[1].BlockID)
+	}
+	if !(results[0].RelevanceScore > results[1].RelevanceScore && results[1].RelevanceScore > 0.5) {
+		t.Errorf("scores = %v, %v; want both above 0.5, the fuller match higher", results[0].RelevanceScore, results[1].RelevanceScore)
+	}
+
+	if results := store.keywordSearch("terra", 10, nil); len(results) != 1 || results[0].BlockID != one {
+		t.Errorf("keywordSearch(terra) = %+v, want a prefix match on terraform", results)
+	}
+}
+
+func TestBlockKeywordsMigratedOnUpgrade(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	// Roll back to before the keyword index: keywords lived in a JSON column
+	for _, stmt := range []string{
+		`DROP TABLE block_keywords`,
+		`ALTER TABLE bridge_blocks ADD COLUMN keywords TEXT`,
+		`INSERT INTO bridge_blocks (id, day_id, topic_label, keywords) VALUES
+			('block_a', '2026-01-01', 'a', '["Go", "sqlite", "go", " ", "Zürich", "ZÜRICH"]'),
+			('block_b', '2026-01-01', 'b', 'not json'),
+			('block_c', '2026-01-01', 'c', NULL)`,
+		`ALTER TABLE bridge_blocks DROP COLUMN exclude_from_recall`,
+		`ALTER TABLE facts DROP COLUMN exclude_from_recall`,
+		`ALTER TABLE turns DROP COLUMN annotations`,
+		`ALTER TABLE turns DROP COLUMN edited_at`,
+		`ALTER TABLE embeddings DROP COLUMN format`,
+		`DROP TABLE settings`,
+		`DROP INDEX idx_facts_pending`,
+		`ALTER TABLE facts DROP COLUMN pending`,
+		`ALTER TABLE bridge_blocks DROP COLUMN retention`,
+		`DROP TABLE tag_retention`,
+		`DROP TABLE block_access`,
+		`DROP TABLE memory_feedback`,
+		`DROP TABLE chunks`,
+		`DROP TABLE compactions`,
+		`DROP TABLE undo_log`,
+		`ALTER TABLE bridge_blocks DROP COLUMN centroid`,
+		`ALTER TABLE bridge_blocks DROP COLUMN centroid_count`,
+		`ALTER TABLE bridge_blocks DROP COLUMN centroid_model`,
+		`ALTER TABLE profile_history DROP COLUMN turn_ids`,
+		`PRAGMA user_version = 19`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	_ = db.Close()
+
+	store, err := NewStorageWithPath(path)
+	if err != nil {
+		t.Fatalf("NewStorageWithPath() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	for id, want := range map[string][]string{"block_a": {"Go", "sqlite", "Zürich"}, "block_b": {}, "block_c": {}} {
+		block, err := store.GetBridgeBlock(id)
+		if err != nil || block == nil {
+			t.Fatalf("GetBridgeBlock(%s) = %v, %v", id, block, err)
+		}
+		if !reflect.DeepEqual(block.Keywords, want) {
+			t.Errorf("%s keywords = %v, want %v", id, block.Keywords, want)
+		}
+	}
+}
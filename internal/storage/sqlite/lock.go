@@ -0,0 +1,75 @@
+// ABOUTME: Cross-process coordination for a database file shared by the CLI and MCP servers
+// ABOUTME: A lock file serializes schema setup; busy_timeout makes writers wait on each other
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	driver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// busyTimeout is how long a statement waits for another connection or process to
+// release the write lock before failing with SQLITE_BUSY. Writes are short, so a
+// writer waiting this long means another process is stuck or running a long
+// maintenance command.
+const busyTimeout = 5 * time.Second
+
+// openLockTimeout is how long Open waits for another process to finish creating or
+// migrating the schema
+const openLockTimeout = 30 * time.Second
+
+// lockPollInterval is how often a waiting Open retries the lock file
+const lockPollInterval = 50 * time.Millisecond
+
+// dsnParams are the connection parameters shared by every file database: writers
+// wait busyTimeout for the lock, and write transactions take it when they begin
+// (BEGIN IMMEDIATE), since a transaction that reads first and then tries to write
+// can fail at once with SQLITE_BUSY rather than wait
+var dsnParams = fmt.Sprintf("_pragma=busy_timeout(%d)&_txlock=immediate", busyTimeout.Milliseconds())
+
+// lockSchema takes the lock file next to the database at path, waiting up to
+// openLockTimeout for another process to release it, and returns the function that
+// releases it. The lock is advisory and held only while the schema is set up, so two
+// processes opening a new or outdated database do not both run the same migration.
+// The operating system drops the lock when its process exits, so a crash leaves no
+// stale lock behind.
+func lockSchema(path string) (func(), error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(openLockTimeout)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", f.Name(), err)
+		}
+		if locked {
+			return func() {
+				_ = unlock(f)
+				_ = f.Close()
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			_ = f.Close()
+			return nil, fmt.Errorf("another memory process has been setting up %s for over %s", path, openLockTimeout)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// busy explains SQLITE_BUSY errors, which mean another connection kept the write lock
+// for longer than busyTimeout; other errors are returned as they are
+func busy(err error) error {
+	var sqliteErr *driver.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_BUSY {
+		return fmt.Errorf("database is busy: another process held its write lock for over %s: %w", busyTimeout, err)
+	}
+	return err
+}
@@ -0,0 +1,18 @@
+// ABOUTME: Lock file fallback for platforms without flock(2)
+// ABOUTME: Always succeeds; migrations then rely on SQLite's own locking and busy_timeout
+
+//go:build !unix
+
+package sqlite
+
+import "os"
+
+// tryLock cannot lock files on this platform
+func tryLock(f *os.File) (bool, error) {
+	return true, nil
+}
+
+// unlock does nothing on this platform
+func unlock(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,100 @@
+// ABOUTME: Tests for cross-process coordination on a shared database file
+// ABOUTME: Verifies the schema lock serializes opens and busy writers wait or fail with a clear error
+
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenWaitsForSchemaLock(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+
+	// Another process setting up the schema holds the lock file
+	release, err := lockSchema(dbPath)
+	if err != nil {
+		t.Fatalf("lockSchema() error = %v", err)
+	}
+
+	opened := make(chan error, 1)
+	go func() {
+		db, err := Open(dbPath)
+		if err == nil {
+			_ = db.Close()
+		}
+		opened <- err
+	}()
+
+	select {
+	case err := <-opened:
+		t.Fatalf("Open() returned %v while the schema lock was held, want it to wait", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	release()
+	select {
+	case err := <-opened:
+		if err != nil {
+			t.Fatalf("Open() after the lock was released error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Open() did not finish after the lock was released")
+	}
+}
+
+func TestConcurrentWritersSerialize(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+	first, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer func() { _ = first.Close() }()
+	// A second handle on the file stands in for another process
+	second, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("second Open() error = %v", err)
+	}
+	defer func() { _ = second.Close() }()
+
+	tx, err := first.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if _, err := tx.Exec("INSERT INTO facts (id, key, value) VALUES (?, ?, ?)", "fact_1", "editor", "vim"); err != nil {
+		t.Fatalf("tx.Exec() error = %v", err)
+	}
+
+	// The other writer waits for the transaction instead of failing
+	wrote := make(chan error, 1)
+	go func() {
+		_, err := second.Exec("INSERT INTO facts (id, key, value) VALUES (?, ?, ?)", "fact_2", "shell", "zsh")
+		wrote <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if err := <-wrote; err != nil {
+		t.Fatalf("waiting Exec() error = %v", err)
+	}
+
+	// A writer that runs out of patience gets an error saying why
+	if _, err := second.writes.writer.ExecContext(context.Background(), "PRAGMA busy_timeout = 10"); err != nil {
+		t.Fatalf("setting busy_timeout: %v", err)
+	}
+	tx, err = first.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.Exec("DELETE FROM facts"); err != nil {
+		t.Fatalf("tx.Exec() error = %v", err)
+	}
+	_, err = second.Exec("INSERT INTO facts (id, key, value) VALUES (?, ?, ?)", "fact_3", "os", "linux")
+	if err == nil || !strings.Contains(err.Error(), "database is busy") {
+		t.Errorf("Exec() while locked error = %v, want a busy error", err)
+	}
+}
@@ -0,0 +1,83 @@
+// ABOUTME: Health signals about the stored memory itself: counts, backlogs, the active block invariant, and conflicts
+// ABOUTME: Backs the get_memory_health MCP tool, so supervising agents can decide when to repair or consolidate
+package sqlite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// MemoryHealth summarizes what is stored and what needs attention
+type MemoryHealth struct {
+	Blocks            int            `json:"blocks"`
+	BlocksByStatus    map[string]int `json:"blocks_by_status"`
+	Turns             int            `json:"turns"`
+	Facts             int            `json:"facts"`
+	Embeddings        int            `json:"embeddings"`
+	PendingEnrichment int            `json:"pending_enrichment"` // Turns still waiting on metadata, embeddings, or facts
+	PendingFacts      int            `json:"pending_facts"`      // Facts waiting in the review queue
+	ActiveBlocks      int            `json:"active_blocks"`
+	InvariantOK       bool           `json:"invariant_ok"` // At most one block is active
+	IdleBlocks        int            `json:"idle_blocks"`  // Paused and closed blocks idle past the cutoff, which consolidation archives
+	Conflicts         int            `json:"conflicts"`    // Keys whose current value replaced a different value recorded by another agent
+}
+
+// factConflicts counts keys whose latest approved value replaced a different value
+// recorded by a different agent: the same rule that publishes conflict_detected.
+// Secret values are encrypted, so they are never compared.
+const factConflicts = `
+	SELECT COUNT(*) FROM (
+		SELECT value, COALESCE(extracted_by, '') AS extracted_by, COALESCE(value_type, '') AS value_type,
+			LAG(value) OVER w AS previous_value,
+			COALESCE(LAG(extracted_by) OVER w, '') AS previous_extracted_by,
+			ROW_NUMBER() OVER (PARTITION BY key ORDER BY created_at DESC, id DESC) AS newest
+		FROM facts
+		WHERE NOT pending
+		WINDOW w AS (PARTITION BY key ORDER BY created_at, id)
+	)
+	WHERE newest = 1 AND previous_value IS NOT NULL AND previous_value != value AND value_type != ?
+		AND extracted_by != '' AND previous_extracted_by != '' AND extracted_by != previous_extracted_by`
+
+// MemoryHealth counts what is stored and the signals a supervising agent acts on. Blocks
+// paused or closed and last updated before idleCutoff count as idle.
+func (s *Storage) MemoryHealth(idleCutoff time.Time) (*MemoryHealth, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	health := &MemoryHealth{BlocksByStatus: make(map[string]int)}
+	for _, c := range []struct {
+		name  string
+		query string
+		dest  *int
+		args  []any
+	}{
+		{"blocks", "SELECT COUNT(*) FROM bridge_blocks", &health.Blocks, nil},
+		{"turns", "SELECT COUNT(*) FROM turns", &health.Turns, nil},
+		{"facts", "SELECT COUNT(*) FROM facts", &health.Facts, nil},
+		{"embeddings", "SELECT COUNT(*) FROM embeddings", &health.Embeddings, nil},
+		{"idle blocks", "SELECT COUNT(*) FROM bridge_blocks WHERE status IN (?, ?) AND updated_at < ?", &health.IdleBlocks,
+			[]any{string(models.StatusPaused), string(models.StatusClosed), idleCutoff.UTC()}},
+		{"fact conflicts", factConflicts, &health.Conflicts, []any{string(models.ValueTypeSecret)}},
+	} {
+		if err := s.db.QueryRow(c.query, c.args...).Scan(c.dest); err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", c.name, err)
+		}
+	}
+
+	if err := s.countBlocksByStatus(health.BlocksByStatus); err != nil {
+		return nil, err
+	}
+	health.ActiveBlocks = health.BlocksByStatus[string(models.StatusActive)]
+	health.InvariantOK = health.ActiveBlocks <= 1
+
+	var err error
+	if health.PendingEnrichment, err = s.turns.PendingEnrichmentCount(); err != nil {
+		return nil, fmt.Errorf("failed to count turns pending enrichment: %w", err)
+	}
+	if health.PendingFacts, err = s.facts.CountPending(); err != nil {
+		return nil, err
+	}
+	return health, nil
+}
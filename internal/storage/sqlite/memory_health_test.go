@@ -0,0 +1,82 @@
+// ABOUTME: Tests for memory health signals
+// ABOUTME: Verifies counts, the active block invariant, idle blocks, pending facts, and fact conflicts between agents
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestMemoryHealth(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Now()
+	empty, err := store.MemoryHealth(now)
+	if err != nil {
+		t.Fatalf("MemoryHealth() error = %v", err)
+	}
+	if !empty.InvariantOK || empty.Blocks != 0 || empty.Conflicts != 0 {
+		t.Errorf("MemoryHealth() of an empty store = %+v", empty)
+	}
+
+	old := now.Add(-60 * 24 * time.Hour)
+	for _, b := range []struct {
+		id      string
+		status  models.BridgeBlockStatus
+		updated time.Time
+	}{
+		{"block_active_a", models.StatusActive, now},
+		{"block_active_b", models.StatusActive, now},
+		{"block_idle", models.StatusPaused, old},
+		{"block_recent", models.StatusClosed, now},
+	} {
+		if err := store.blocks.Save(&models.BridgeBlock{BlockID: b.id, DayID: "2026-01-01", TopicLabel: b.id, Status: b.status, CreatedAt: b.updated, UpdatedAt: b.updated}); err != nil {
+			t.Fatalf("Save block error = %v", err)
+		}
+	}
+	for i, f := range []struct {
+		key, value, by string
+		pending        bool
+	}{
+		{"home_city", "Lisbon", "fact_scrubber", false},
+		{"home_city", "Porto", "claude-code", false}, // another agent's value: a conflict
+		{"car", "Saab", "fact_scrubber", false},
+		{"car", "Volvo", "fact_scrubber", false}, // the same agent changing its mind
+		{"pet", "cat", "fact_scrubber", false},
+		{"pet", "dog", "claude-code", true}, // pending facts are not current
+		{"drink", "tea", "fact_scrubber", false},
+		{"drink", "tea", "claude-code", false}, // the same value
+	} {
+		fact := &models.Fact{FactID: "fact_" + string(rune('a'+i)), Key: f.key, Value: f.value, Confidence: 1,
+			CreatedAt: old.Add(time.Duration(i) * time.Minute), ExtractedBy: f.by, Pending: f.pending}
+		if err := store.facts.Save(fact); err != nil {
+			t.Fatalf("Save fact error = %v", err)
+		}
+	}
+
+	health, err := store.MemoryHealth(now.Add(-30 * 24 * time.Hour))
+	if err != nil {
+		t.Fatalf("MemoryHealth() error = %v", err)
+	}
+	if health.Blocks != 4 || health.BlocksByStatus[string(models.StatusActive)] != 2 || health.Facts != 8 {
+		t.Errorf("counts = %+v, want 4 blocks, 2 active, and 8 facts", health)
+	}
+	if health.InvariantOK || health.ActiveBlocks != 2 {
+		t.Errorf("InvariantOK = %v with %d active blocks, want it broken", health.InvariantOK, health.ActiveBlocks)
+	}
+	if health.IdleBlocks != 1 {
+		t.Errorf("IdleBlocks = %d, want 1", health.IdleBlocks)
+	}
+	if health.PendingFacts != 1 {
+		t.Errorf("PendingFacts = %d, want 1", health.PendingFacts)
+	}
+	if health.Conflicts != 1 {
+		t.Errorf("Conflicts = %d, want 1", health.Conflicts)
+	}
+}
@@ -0,0 +1,91 @@
+// ABOUTME: Tests for turn origins in storage
+// ABOUTME: Verifies origins round-trip and scope search to a repo, device, or directory
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestTurnOrigin_RoundTripAndSearch(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	api, err := store.StoreTurn(&models.Turn{
+		TurnID:      "turn_api",
+		UserMessage: "the tests are flaky",
+		Keywords:    []string{"tests"},
+		Origin: models.Origin{
+			Device:     " laptop ",
+			Hostname:   "box",
+			WorkingDir: "/src/api/internal/",
+			GitRepo:    "git@github.com:acme/api.git",
+			Agent:      "claude-code",
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	web, err := store.StoreTurn(&models.Turn{
+		TurnID:      "turn_web",
+		UserMessage: "the tests are slow",
+		Keywords:    []string{"tests"},
+		Origin:      models.Origin{Device: "desktop", WorkingDir: "/src/web", GitRepo: "git@github.com:acme/web.git", Agent: "research-agent"},
+	})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if _, err := store.StoreTurn(&models.Turn{
+		TurnID:      "turn_plain",
+		UserMessage: "tests everywhere",
+		Keywords:    []string{"tests"},
+	}); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	block, err := store.GetBridgeBlock(api)
+	if err != nil {
+		t.Fatalf("GetBridgeBlock() error = %v", err)
+	}
+	want := models.Origin{Device: "laptop", Hostname: "box", WorkingDir: "/src/api/internal", GitRepo: "git@github.com:acme/api.git", Agent: "claude-code"}
+	if got := block.Turns[0].Origin; got != want {
+		t.Errorf("Origin = %+v, want normalized %+v", got, want)
+	}
+
+	tests := []struct {
+		name   string
+		filter models.Origin
+		want   []string
+	}{
+		{"repo", models.Origin{GitRepo: "git@github.com:acme/web.git"}, []string{web}},
+		{"device", models.Origin{Device: "laptop"}, []string{api}},
+		{"parent dir", models.Origin{WorkingDir: "/src"}, []string{api, web}},
+		{"subdir", models.Origin{WorkingDir: "/src/api"}, []string{api}},
+		{"agent", models.Origin{Agent: "research-agent"}, []string{web}},
+		{"no match", models.Origin{GitRepo: "github.com/acme/docs"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := store.SearchMemoryWithOptions("tests", 10, SearchOptions{Origin: tt.filter})
+			if err != nil {
+				t.Fatalf("SearchMemoryWithOptions() error = %v", err)
+			}
+			got := map[string]bool{}
+			for _, r := range results {
+				got[r.BlockID] = true
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("results = %v, want blocks %v", got, tt.want)
+			}
+			for _, id := range tt.want {
+				if !got[id] {
+					t.Errorf("block %s missing from %v", id, got)
+				}
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ package sqlite
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/harper/remember-standalone/internal/models"
@@ -12,28 +13,46 @@ import (
 
 // ProfileStore handles user profile persistence
 type ProfileStore struct {
-	db *DB
+	db    *DB
+	cache *lruCache[*models.UserProfile]
 }
 
 // NewProfileStore creates a new ProfileStore
 func NewProfileStore(db *DB) *ProfileStore {
-	return &ProfileStore{db: db}
+	return &ProfileStore{
+		db:    db,
+		cache: newLRUCache("profile", db, profileCacheSize, cloneProfile, "user_profile"),
+	}
 }
 
 // Get retrieves the user profile, returning nil if not found
 func (s *ProfileStore) Get() (*models.UserProfile, error) {
+	return s.cache.load("profile", func() (*models.UserProfile, bool, error) {
+		profile, err := s.get()
+		return profile, err == nil && profile != nil, err
+	})
+}
+
+// CacheStats reports the profile cache's hits and misses
+func (s *ProfileStore) CacheStats() CacheStats {
+	return s.cache.stats()
+}
+
+// get reads the user profile from the database
+func (s *ProfileStore) get() (*models.UserProfile, error) {
 	var (
 		name           sql.NullString
 		prefsJSON      sql.NullString
 		topicsJSON     sql.NullString
+		constraintsJSON sql.NullString
 		updatedAt      time.Time
 	)
 
 	err := s.db.QueryRow(`
-		SELECT name, preferences, topics_of_interest, updated_at
+		SELECT name, preferences, topics_of_interest, constraints, updated_at
 		FROM user_profile
 		WHERE id = 1
-	`).Scan(&name, &prefsJSON, &topicsJSON, &updatedAt)
+	`).Scan(&name, &prefsJSON, &topicsJSON, &constraintsJSON, &updatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -66,12 +85,26 @@ func (s *ProfileStore) Get() (*models.UserProfile, error) {
 		profile.TopicsOfInterest = []string{}
 	}
 
+	if constraintsJSON.Valid && constraintsJSON.String != "" {
+		if err := json.Unmarshal([]byte(constraintsJSON.String), &profile.Constraints); err != nil {
+			profile.Constraints = []models.ProfileConstraint{}
+		}
+	} else {
+		profile.Constraints = []models.ProfileConstraint{}
+	}
+
 	return profile, nil
 }
 
 // Save saves or updates the user profile (upsert)
 func (s *ProfileStore) Save(profile *models.UserProfile) error {
-	var prefsJSON, topicsJSON []byte
+	return s.SaveLearned(profile, nil)
+}
+
+// SaveLearned saves the user profile like Save, recording in its history the turns
+// the Scribe learned it from
+func (s *ProfileStore) SaveLearned(profile *models.UserProfile, turnIDs []string) error {
+	var prefsJSON, topicsJSON, constraintsJSON []byte
 	var err error
 
 	if profile.Preferences != nil {
@@ -92,20 +125,204 @@ func (s *ProfileStore) Save(profile *models.UserProfile) error {
 		topicsJSON = []byte("[]")
 	}
 
-	updatedAt := time.Now()
+	if profile.Constraints != nil {
+		constraintsJSON, err = json.Marshal(profile.Constraints)
+		if err != nil {
+			return err
+		}
+	} else {
+		constraintsJSON = []byte("[]")
+	}
+
+	updatedAt := s.db.now()
 	if !profile.LastUpdated.IsZero() {
 		updatedAt = profile.LastUpdated
 	}
 
-	_, err = s.db.Exec(`
-		INSERT INTO user_profile (id, name, preferences, topics_of_interest, updated_at)
-		VALUES (1, ?, ?, ?, ?)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.Exec(`
+		INSERT INTO user_profile (id, name, preferences, topics_of_interest, constraints, updated_at)
+		VALUES (1, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			name = excluded.name,
 			preferences = excluded.preferences,
 			topics_of_interest = excluded.topics_of_interest,
+			constraints = excluded.constraints,
 			updated_at = excluded.updated_at
-	`, profile.Name, string(prefsJSON), string(topicsJSON), updatedAt)
+	`, profile.Name, string(prefsJSON), string(topicsJSON), string(constraintsJSON), updatedAt.UTC())
+	if err != nil {
+		return err
+	}
+	if err := recordProfileVersion(tx, &ProfileVersion{
+		Name:             profile.Name,
+		Preferences:      profile.Preferences,
+		TopicsOfInterest: profile.TopicsOfInterest,
+		Constraints:      profile.Constraints,
+		TurnIDs:          turnIDs,
+		SavedAt:          updatedAt,
+	}); err != nil {
+		return err
+	}
+	if err := syncProfileItems(tx, "", models.ProfileItemPreference, profile.Preferences, updatedAt); err != nil {
+		return err
+	}
+	if err := syncProfileItems(tx, "", models.ProfileItemTopic, profile.TopicsOfInterest, updatedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
-	return err
+// GetPersona retrieves a persona by name, returning nil if not found
+func (s *ProfileStore) GetPersona(name string) (*models.Persona, error) {
+	rows, err := s.db.Query(`
+		SELECT name, preferences, constraints, updated_at
+		FROM profile_personas
+		WHERE name = ?
+	`, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get persona: %w", err)
+	}
+	personas, err := scanPersonas(rows)
+	if err != nil || len(personas) == 0 {
+		return nil, err
+	}
+	return personas[0], nil
+}
+
+// ListPersonas returns every persona ordered by name
+func (s *ProfileStore) ListPersonas() ([]*models.Persona, error) {
+	rows, err := s.db.Query(`
+		SELECT name, preferences, constraints, updated_at
+		FROM profile_personas
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list personas: %w", err)
+	}
+	return scanPersonas(rows)
+}
+
+// SavePersona saves or updates a persona (upsert by name)
+func (s *ProfileStore) SavePersona(persona *models.Persona) error {
+	return s.SaveLearnedPersona(persona, nil)
+}
+
+// SaveLearnedPersona saves a persona like SavePersona, recording in its history the
+// turns the Scribe learned it from
+func (s *ProfileStore) SaveLearnedPersona(persona *models.Persona, turnIDs []string) error {
+	prefsJSON, err := json.Marshal(persona.Preferences)
+	if err != nil {
+		return err
+	}
+	constraintsJSON, err := json.Marshal(persona.Constraints)
+	if err != nil {
+		return err
+	}
+
+	updatedAt := s.db.now()
+	if !persona.LastUpdated.IsZero() {
+		updatedAt = persona.LastUpdated
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save persona: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.Exec(`
+		INSERT INTO profile_personas (name, preferences, constraints, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			preferences = excluded.preferences,
+			constraints = excluded.constraints,
+			updated_at = excluded.updated_at
+	`, persona.Name, string(prefsJSON), string(constraintsJSON), updatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save persona: %w", err)
+	}
+	if err := recordProfileVersion(tx, &ProfileVersion{
+		Persona:     persona.Name,
+		Preferences: persona.Preferences,
+		Constraints: persona.Constraints,
+		TurnIDs:     turnIDs,
+		SavedAt:     updatedAt,
+	}); err != nil {
+		return err
+	}
+	if err := syncProfileItems(tx, persona.Name, models.ProfileItemPreference, persona.Preferences, updatedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeletePersona removes a persona, reporting whether it existed
+func (s *ProfileStore) DeletePersona(name string) (bool, error) {
+	return s.deletePersona(name, nil)
+}
+
+// deletePersona removes a persona like DeletePersona; before, when set, runs first in
+// the same transaction
+func (s *ProfileStore) deletePersona(name string, before func(tx *Tx) error) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete persona: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if before != nil {
+		if err := before(tx); err != nil {
+			return false, err
+		}
+	}
+	result, err := tx.Exec(`DELETE FROM profile_personas WHERE name = ?`, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete persona: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err := recordProfileVersion(tx, &ProfileVersion{Persona: name, Deleted: true, SavedAt: s.db.now()}); err != nil {
+		return false, err
+	}
+	if err := syncProfileItems(tx, name, models.ProfileItemPreference, nil, s.db.now()); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// scanPersonas reads persona rows, closing them when done
+func scanPersonas(rows *Rows) ([]*models.Persona, error) {
+	defer func() { _ = rows.Close() }()
+
+	var personas []*models.Persona
+	for rows.Next() {
+		var (
+			persona         models.Persona
+			prefsJSON       sql.NullString
+			constraintsJSON sql.NullString
+		)
+		if err := rows.Scan(&persona.Name, &prefsJSON, &constraintsJSON, &persona.LastUpdated); err != nil {
+			return nil, fmt.Errorf("failed to scan persona: %w", err)
+		}
+		if prefsJSON.Valid && prefsJSON.String != "" {
+			_ = json.Unmarshal([]byte(prefsJSON.String), &persona.Preferences)
+		}
+		if constraintsJSON.Valid && constraintsJSON.String != "" {
+			_ = json.Unmarshal([]byte(constraintsJSON.String), &persona.Constraints)
+		}
+		if persona.Preferences == nil {
+			persona.Preferences = []string{}
+		}
+		if persona.Constraints == nil {
+			persona.Constraints = []models.ProfileConstraint{}
+		}
+		personas = append(personas, &persona)
+	}
+	return personas, rows.Err()
 }
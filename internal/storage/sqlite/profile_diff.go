@@ -0,0 +1,138 @@
+// ABOUTME: Profile diffs: what was added to and removed from the profile over a time range
+// ABOUTME: Compares consecutive profile_history versions, naming the turns the Scribe learned each from
+package sqlite
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// ProfileChange is one entry added to or removed from the shared profile or a persona
+// when a version of it was saved
+type ProfileChange struct {
+	At      time.Time `json:"at"`
+	Persona string    `json:"persona,omitempty"`  // Empty for the shared profile
+	Field   string    `json:"field"`              // name, preferences, topics_of_interest, or constraints
+	Change  string    `json:"change"`             // added or removed
+	Value   string    `json:"value"`              // Constraints read "Type: description (severity)"
+	TurnIDs []string  `json:"turn_ids,omitempty"` // Turns the Scribe learned the change from; empty for direct edits
+}
+
+// ProfileDiff is every change to the profile, and to a persona if one was asked for,
+// saved after Since and up to Until, oldest first
+type ProfileDiff struct {
+	Persona string          `json:"persona,omitempty"`
+	Since   time.Time       `json:"since"`
+	Until   time.Time       `json:"until"`
+	Changes []ProfileChange `json:"changes"`
+}
+
+// VersionsBetween returns the versions of persona ("" for the shared profile) saved after
+// since and at or before until, oldest first
+func (s *ProfileStore) VersionsBetween(persona string, since, until time.Time) ([]*ProfileVersion, error) {
+	rows, err := s.db.Query(`
+		SELECT persona, data, turn_ids, saved_at FROM profile_history
+		WHERE persona = ? AND saved_at > ? AND saved_at <= ?
+		ORDER BY saved_at, id
+	`, persona, since.UTC(), until.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile history: %w", err)
+	}
+	return scanProfileVersions(rows)
+}
+
+// GetProfileDiff returns what was added to and removed from the shared profile, and the
+// named persona when one is given, between since and until. Each change names the turns
+// the Scribe learned it from; changes made through update_user_profile, the CLI, profile
+// review, or import have none, as do versions saved before turns were recorded.
+func (s *Storage) GetProfileDiff(persona string, since, until time.Time) (*ProfileDiff, error) {
+	if until.Before(since) {
+		return nil, fmt.Errorf("until (%s) is before since (%s)", until.Format(time.RFC3339), since.Format(time.RFC3339))
+	}
+	personas := []string{""}
+	if persona != "" {
+		name, err := models.NormalizePersonaName(persona)
+		if err != nil {
+			return nil, err
+		}
+		persona = name
+		personas = append(personas, name)
+	}
+
+	diff := &ProfileDiff{Persona: persona, Since: since, Until: until, Changes: []ProfileChange{}}
+	for _, p := range personas {
+		prev, err := s.profile.VersionAsOf(p, since)
+		if err != nil {
+			return nil, err
+		}
+		versions, err := s.profile.VersionsBetween(p, since, until)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range versions {
+			diff.Changes = append(diff.Changes, profileChanges(prev, v)...)
+			prev = v
+		}
+	}
+	sort.SliceStable(diff.Changes, func(i, j int) bool { return diff.Changes[i].At.Before(diff.Changes[j].At) })
+	return diff, nil
+}
+
+// profileChanges lists what next added to and removed from prev, the version before it
+// (nil when there was none)
+func profileChanges(prev, next *ProfileVersion) []ProfileChange {
+	var before ProfileVersion
+	if prev != nil && !prev.Deleted {
+		before = *prev
+	}
+	var changes []ProfileChange
+	record := func(field, change, value string) {
+		changes = append(changes, ProfileChange{At: next.SavedAt, Persona: next.Persona, Field: field, Change: change, Value: value, TurnIDs: next.TurnIDs})
+	}
+	compare := func(field string, old, new []string) {
+		for _, value := range old {
+			if !slices.Contains(new, value) {
+				record(field, "removed", value)
+			}
+		}
+		for _, value := range new {
+			if !slices.Contains(old, value) {
+				record(field, "added", value)
+			}
+		}
+	}
+
+	after := *next
+	if after.Deleted {
+		after = ProfileVersion{}
+	}
+	var oldName, newName []string
+	if before.Name != "" {
+		oldName = []string{before.Name}
+	}
+	if after.Name != "" {
+		newName = []string{after.Name}
+	}
+	compare("name", oldName, newName)
+	compare("preferences", before.Preferences, after.Preferences)
+	compare("topics_of_interest", before.TopicsOfInterest, after.TopicsOfInterest)
+	compare("constraints", constraintTexts(before.Constraints), constraintTexts(after.Constraints))
+	return changes
+}
+
+// constraintTexts renders constraints as "Type: description (severity)" for diffing
+func constraintTexts(constraints []models.ProfileConstraint) []string {
+	texts := make([]string, 0, len(constraints))
+	for _, c := range constraints {
+		text := c.Type + ": " + c.Description
+		if c.Severity != "" {
+			text += " (" + c.Severity + ")"
+		}
+		texts = append(texts, text)
+	}
+	return texts
+}
@@ -0,0 +1,93 @@
+// ABOUTME: Tests for profile diffs over a time range
+// ABOUTME: Verifies added and removed entries, the turns they were learned from, and persona changes
+
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestGetProfileDiff(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	march := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	april := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	may := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.profile.Save(&models.UserProfile{Name: "Harper", Preferences: []string{"tea"}, LastUpdated: march}); err != nil {
+		t.Fatalf("Save profile error = %v", err)
+	}
+	if err := store.profile.SaveLearned(&models.UserProfile{Name: "Harper", Preferences: []string{"coffee"}, TopicsOfInterest: []string{"Go"},
+		Constraints: []models.ProfileConstraint{{Type: "Allergy", Description: "peanuts", Severity: "strict"}}, LastUpdated: april}, []string{"turn_a", "turn_b"}); err != nil {
+		t.Fatalf("SaveLearned() error = %v", err)
+	}
+	if err := store.profile.SaveLearnedPersona(&models.Persona{Name: "work", Preferences: []string{"formal tone"}, LastUpdated: april.Add(time.Hour)}, []string{"turn_c"}); err != nil {
+		t.Fatalf("SaveLearnedPersona() error = %v", err)
+	}
+	if err := store.profile.Save(&models.UserProfile{Name: "Harper", Preferences: []string{"coffee"}, LastUpdated: may}); err != nil {
+		t.Fatalf("Save profile error = %v", err)
+	}
+
+	describe := func(changes []ProfileChange) string {
+		var lines []string
+		for _, c := range changes {
+			lines = append(lines, fmt.Sprintf("%s %s %s %s %v", c.Persona, c.Change, c.Field, c.Value, c.TurnIDs))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	// From the March version on: what April learned, then what May removed by hand
+	diff, err := store.GetProfileDiff("work", march, may)
+	if err != nil {
+		t.Fatalf("GetProfileDiff() error = %v", err)
+	}
+	want := strings.Join([]string{
+		" removed preferences tea [turn_a turn_b]",
+		" added preferences coffee [turn_a turn_b]",
+		" added topics_of_interest Go [turn_a turn_b]",
+		" added constraints Allergy: peanuts (strict) [turn_a turn_b]",
+		"work added preferences formal tone [turn_c]",
+		" removed topics_of_interest Go []",
+		" removed constraints Allergy: peanuts (strict) []",
+	}, "\n")
+	if got := describe(diff.Changes); got != want {
+		t.Errorf("GetProfileDiff(work, march, may) =\n%s\nwant\n%s", got, want)
+	}
+	if diff.Persona != "work" || !diff.Changes[0].At.Equal(april) {
+		t.Errorf("diff = %+v, want the work persona and April's time", diff)
+	}
+
+	// The shared profile alone, over a narrower range
+	diff, err = store.GetProfileDiff("", march.Add(time.Hour), april.Add(time.Hour))
+	if err != nil || len(diff.Changes) != 4 || diff.Changes[0].Persona != "" {
+		t.Errorf("GetProfileDiff(\"\", march, april) = %+v, %v; want April's four shared changes", diff, err)
+	}
+
+	// Everything from the start includes the first version
+	if diff, err := store.GetProfileDiff("", time.Time{}, march); err != nil || describe(diff.Changes) != " added name Harper []\n added preferences tea []" {
+		t.Errorf("GetProfileDiff(start, march) = %+v, %v; want the first version added", diff, err)
+	}
+	if diff, err := store.GetProfileDiff("", may.Add(time.Hour), may.Add(2*time.Hour)); err != nil || len(diff.Changes) != 0 {
+		t.Errorf("GetProfileDiff(after may) = %+v, %v; want no changes", diff, err)
+	}
+	if _, err := store.GetProfileDiff("", may, march); err == nil {
+		t.Error("GetProfileDiff() with until before since succeeded, want an error")
+	}
+
+	// A deleted persona loses everything it held
+	if ok, err := store.profile.DeletePersona("work"); err != nil || !ok {
+		t.Fatalf("DeletePersona() = %v, %v", ok, err)
+	}
+	diff, err = store.GetProfileDiff("work", may, time.Now().Add(time.Minute))
+	if err != nil || describe(diff.Changes) != "work removed preferences formal tone []" {
+		t.Errorf("GetProfileDiff() after deleting work = %+v, %v; want its preference removed", diff, err)
+	}
+}
@@ -0,0 +1,184 @@
+// ABOUTME: Tracks when each profile preference and topic was last reinforced
+// ABOUTME: Lists items gone stale and lets a review confirm, edit, or drop them
+package sqlite
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// syncProfileItems makes the tracked items of one persona and kind match texts: new
+// texts start out reinforced at at, and texts no longer in the profile are forgotten
+func syncProfileItems(tx *Tx, persona string, kind models.ProfileItemKind, texts []string, at time.Time) error {
+	args := []interface{}{persona, string(kind)}
+	query := `DELETE FROM profile_items WHERE persona = ? AND kind = ?`
+	if len(texts) > 0 {
+		query += ` AND text NOT IN (` + placeholders(len(texts)) + `)`
+		for _, text := range texts {
+			args = append(args, text)
+		}
+	}
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to update profile items: %w", err)
+	}
+	for _, text := range texts {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO profile_items (persona, kind, text, reinforced_at) VALUES (?, ?, ?, ?)`,
+			persona, string(kind), text, at.UTC()); err != nil {
+			return fmt.Errorf("failed to update profile items: %w", err)
+		}
+	}
+	return nil
+}
+
+// Reinforce marks texts of one persona and kind as reinforced at at; texts the profile
+// does not hold are ignored
+func (s *ProfileStore) Reinforce(persona string, kind models.ProfileItemKind, texts []string, at time.Time) error {
+	for _, text := range texts {
+		if _, err := s.db.Exec(`
+			UPDATE profile_items SET reinforced_at = ?
+			WHERE persona = ? AND kind = ? AND text = ? AND reinforced_at < ?
+		`, at, persona, string(kind), text, at); err != nil {
+			return fmt.Errorf("failed to reinforce profile item: %w", err)
+		}
+	}
+	return nil
+}
+
+// StaleItems returns the items last reinforced before before, least recently reinforced first
+func (s *ProfileStore) StaleItems(before time.Time) ([]models.ProfileItem, error) {
+	rows, err := s.db.Query(`
+		SELECT persona, kind, text, reinforced_at FROM profile_items
+		WHERE reinforced_at < ?
+		ORDER BY reinforced_at, persona, kind, text
+	`, before.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale profile items: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []models.ProfileItem
+	for rows.Next() {
+		var (
+			item models.ProfileItem
+			kind string
+		)
+		if err := rows.Scan(&item.Persona, &kind, &item.Text, &item.ReinforcedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan profile item: %w", err)
+		}
+		item.Kind = models.ProfileItemKind(kind)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// ReinforceProfileItems records that preferences and topics were just learned or
+// restated. Preferences belong to persona (the shared profile when empty); topics are
+// always shared.
+func (s *Storage) ReinforceProfileItems(persona string, preferences, topics []string) error {
+	if persona != "" {
+		name, err := models.NormalizePersonaName(persona)
+		if err != nil {
+			return err
+		}
+		persona = name
+	}
+	now := s.db.now()
+	if err := s.profile.Reinforce(persona, models.ProfileItemPreference, preferences, now); err != nil {
+		return err
+	}
+	return s.profile.Reinforce("", models.ProfileItemTopic, topics, now)
+}
+
+// StaleProfileItems returns the preferences and topics not reinforced since before,
+// least recently reinforced first
+func (s *Storage) StaleProfileItems(before time.Time) ([]models.ProfileItem, error) {
+	return s.profile.StaleItems(before)
+}
+
+// ConfirmProfileItem keeps a profile item, counting it as reinforced now
+func (s *Storage) ConfirmProfileItem(item models.ProfileItem) error {
+	return s.profile.Reinforce(item.Persona, item.Kind, []string{item.Text}, s.db.now())
+}
+
+// EditProfileItem replaces a profile item's text in place; the new text counts as
+// reinforced now. If the profile already holds the new text, the old item is dropped.
+func (s *Storage) EditProfileItem(item models.ProfileItem, text string) error {
+	if text == "" {
+		return fmt.Errorf("profile item text cannot be empty")
+	}
+	if err := s.reviseProfileItem(item, text); err != nil {
+		return err
+	}
+	return s.ConfirmProfileItem(models.ProfileItem{Persona: item.Persona, Kind: item.Kind, Text: text})
+}
+
+// DropProfileItem removes a preference or topic from the profile
+func (s *Storage) DropProfileItem(item models.ProfileItem) error {
+	return s.reviseProfileItem(item, "")
+}
+
+// reviseProfileItem replaces item's text with replacement, or removes it when replacement
+// is empty or already present, and saves the profile or persona holding it
+func (s *Storage) reviseProfileItem(item models.ProfileItem, replacement string) error {
+	revise := func(texts []string) ([]string, error) {
+		out := make([]string, 0, len(texts))
+		found := false
+		for _, text := range texts {
+			switch {
+			case text != item.Text:
+				out = append(out, text)
+			case found:
+				// a repeat of the item goes too
+			default:
+				found = true
+				if replacement != "" && !slices.Contains(texts, replacement) {
+					out = append(out, replacement)
+				}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("profile has no %s %q", item.Kind, item.Text)
+		}
+		return out, nil
+	}
+
+	if item.Persona != "" {
+		if item.Kind != models.ProfileItemPreference {
+			return fmt.Errorf("personas have no %ss", item.Kind)
+		}
+		persona, err := s.GetPersona(item.Persona)
+		if err != nil {
+			return err
+		}
+		if persona == nil {
+			return fmt.Errorf("persona %q not found", item.Persona)
+		}
+		if persona.Preferences, err = revise(persona.Preferences); err != nil {
+			return err
+		}
+		return s.SavePersona(persona)
+	}
+
+	profile, err := s.GetUserProfile()
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		return fmt.Errorf("profile has no %s %q", item.Kind, item.Text)
+	}
+	switch item.Kind {
+	case models.ProfileItemPreference:
+		profile.Preferences, err = revise(profile.Preferences)
+	case models.ProfileItemTopic:
+		profile.TopicsOfInterest, err = revise(profile.TopicsOfInterest)
+	default:
+		err = fmt.Errorf("invalid profile item kind %q", item.Kind)
+	}
+	if err != nil {
+		return err
+	}
+	return s.SaveUserProfile(profile)
+}
@@ -0,0 +1,204 @@
+// ABOUTME: Tests for profile item staleness tracking and review
+// ABOUTME: Verifies items follow profile saves, reinforcement, confirm/edit/drop, and the upgrade migration
+package sqlite
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// staleTexts returns the texts of the items stale as of before, in order
+func staleTexts(t *testing.T, store *Storage, before time.Time) []string {
+	t.Helper()
+	items, err := store.StaleProfileItems(before)
+	if err != nil {
+		t.Fatalf("StaleProfileItems() error = %v", err)
+	}
+	texts := []string{}
+	for _, item := range items {
+		texts = append(texts, string(item.Kind)+":"+item.Persona+":"+item.Text)
+	}
+	return texts
+}
+
+// ageProfileItem backdates when an item was last reinforced
+func ageProfileItem(t *testing.T, store *Storage, text string, at time.Time) {
+	t.Helper()
+	if _, err := store.db.Exec(`UPDATE profile_items SET reinforced_at = ? WHERE text = ?`, at, text); err != nil {
+		t.Fatalf("backdating %q: %v", text, err)
+	}
+}
+
+func TestProfileItems_FollowSavesAndReinforcement(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.SaveUserProfile(&models.UserProfile{Preferences: []string{"Prefers tea", "Uses vim"}, TopicsOfInterest: []string{"sailing"}}); err != nil {
+		t.Fatalf("SaveUserProfile() error = %v", err)
+	}
+	if err := store.SavePersona(&models.Persona{Name: "work", Preferences: []string{"Formal tone"}}); err != nil {
+		t.Fatalf("SavePersona() error = %v", err)
+	}
+
+	future := time.Now().Add(time.Hour)
+	if got, want := staleTexts(t, store, future), 4; len(got) != want {
+		t.Fatalf("stale items = %v, want all %d", got, want)
+	}
+	if got := staleTexts(t, store, time.Now().Add(-time.Hour)); len(got) != 0 {
+		t.Errorf("items stale an hour ago = %v, want none", got)
+	}
+
+	old := time.Now().AddDate(0, -6, 0)
+	for _, text := range []string{"Prefers tea", "Uses vim", "sailing", "Formal tone"} {
+		ageProfileItem(t, store, text, old)
+	}
+	// Restating an item reinforces it; texts the profile does not hold are ignored
+	if err := store.ReinforceProfileItems("Work", []string{"Formal tone", "Unknown"}, []string{"sailing"}); err != nil {
+		t.Fatalf("ReinforceProfileItems() error = %v", err)
+	}
+	cutoff := time.Now().AddDate(0, 0, -90)
+	if got, want := staleTexts(t, store, cutoff), []string{"preference::Prefers tea", "preference::Uses vim"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("stale items = %v, want %v", got, want)
+	}
+
+	// Removing an item from the profile forgets it; re-adding it starts it fresh
+	if err := store.SaveUserProfile(&models.UserProfile{Preferences: []string{"Prefers tea"}, TopicsOfInterest: []string{"sailing"}}); err != nil {
+		t.Fatalf("SaveUserProfile() error = %v", err)
+	}
+	if got, want := staleTexts(t, store, cutoff), []string{"preference::Prefers tea"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("stale items after removing one = %v, want %v", got, want)
+	}
+	if _, err := store.DeletePersona("work"); err != nil {
+		t.Fatalf("DeletePersona() error = %v", err)
+	}
+	if got := staleTexts(t, store, future); len(got) != 2 {
+		t.Errorf("items after deleting the persona = %v, want the 2 shared ones", got)
+	}
+}
+
+func TestProfileItems_Review(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.SaveUserProfile(&models.UserProfile{Preferences: []string{"Prefers tea", "Uses vim", "Likes jazz"}, TopicsOfInterest: []string{"sailing"}}); err != nil {
+		t.Fatalf("SaveUserProfile() error = %v", err)
+	}
+	if err := store.SavePersona(&models.Persona{Name: "work", Preferences: []string{"Formal tone"}}); err != nil {
+		t.Fatalf("SavePersona() error = %v", err)
+	}
+	old := time.Now().AddDate(-1, 0, 0)
+	for _, text := range []string{"Prefers tea", "Uses vim", "Likes jazz", "sailing", "Formal tone"} {
+		ageProfileItem(t, store, text, old)
+	}
+
+	if err := store.ConfirmProfileItem(models.ProfileItem{Kind: models.ProfileItemPreference, Text: "Prefers tea"}); err != nil {
+		t.Fatalf("ConfirmProfileItem() error = %v", err)
+	}
+	if err := store.EditProfileItem(models.ProfileItem{Kind: models.ProfileItemPreference, Text: "Uses vim"}, "Uses neovim"); err != nil {
+		t.Fatalf("EditProfileItem() error = %v", err)
+	}
+	// Editing to text the profile already holds merges the two
+	if err := store.EditProfileItem(models.ProfileItem{Kind: models.ProfileItemPreference, Text: "Likes jazz"}, "Prefers tea"); err != nil {
+		t.Fatalf("EditProfileItem() merge error = %v", err)
+	}
+	if err := store.DropProfileItem(models.ProfileItem{Kind: models.ProfileItemTopic, Text: "sailing"}); err != nil {
+		t.Fatalf("DropProfileItem() error = %v", err)
+	}
+	if err := store.DropProfileItem(models.ProfileItem{Persona: "work", Kind: models.ProfileItemPreference, Text: "Formal tone"}); err != nil {
+		t.Fatalf("DropProfileItem(persona) error = %v", err)
+	}
+	if err := store.DropProfileItem(models.ProfileItem{Kind: models.ProfileItemTopic, Text: "sailing"}); err == nil {
+		t.Error("DropProfileItem() of a missing item succeeded, want an error")
+	}
+
+	profile, err := store.GetUserProfile()
+	if err != nil {
+		t.Fatalf("GetUserProfile() error = %v", err)
+	}
+	if want := []string{"Prefers tea", "Uses neovim"}; !reflect.DeepEqual(profile.Preferences, want) {
+		t.Errorf("Preferences = %v, want %v", profile.Preferences, want)
+	}
+	if len(profile.TopicsOfInterest) != 0 {
+		t.Errorf("TopicsOfInterest = %v, want none", profile.TopicsOfInterest)
+	}
+	persona, err := store.GetPersona("work")
+	if err != nil || persona == nil || len(persona.Preferences) != 0 {
+		t.Errorf("GetPersona(work) = %+v, %v; want no preferences left", persona, err)
+	}
+	if got := staleTexts(t, store, time.Now().AddDate(0, 0, -90)); len(got) != 0 {
+		t.Errorf("stale items after review = %v, want none", got)
+	}
+}
+
+func TestProfileItemsSeededOnUpgrade(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	// Roll back to before item tracking, with a profile saved long ago
+	for _, stmt := range []string{
+		`DROP TABLE profile_items`,
+		`INSERT INTO user_profile (id, preferences, topics_of_interest, updated_at)
+			VALUES (1, '["Prefers tea", 3]', 'not json', '2025-01-02 03:04:05')`,
+		`INSERT INTO profile_personas (name, preferences, updated_at) VALUES ('work', '["Formal tone"]', '2025-06-01 00:00:00')`,
+		`ALTER TABLE bridge_blocks DROP COLUMN exclude_from_recall`,
+		`ALTER TABLE facts DROP COLUMN exclude_from_recall`,
+		`ALTER TABLE turns DROP COLUMN annotations`,
+		`ALTER TABLE turns DROP COLUMN edited_at`,
+		`ALTER TABLE embeddings DROP COLUMN format`,
+		`DROP TABLE settings`,
+		`DROP INDEX idx_facts_pending`,
+		`ALTER TABLE facts DROP COLUMN pending`,
+		`ALTER TABLE bridge_blocks DROP COLUMN retention`,
+		`DROP TABLE tag_retention`,
+		`DROP TABLE block_access`,
+		`DROP TABLE memory_feedback`,
+		`DROP TABLE chunks`,
+		`DROP TABLE compactions`,
+		`DROP TABLE undo_log`,
+		`ALTER TABLE bridge_blocks DROP COLUMN centroid`,
+		`ALTER TABLE bridge_blocks DROP COLUMN centroid_count`,
+		`ALTER TABLE bridge_blocks DROP COLUMN centroid_model`,
+		`ALTER TABLE profile_history DROP COLUMN turn_ids`,
+		`PRAGMA user_version = 20`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	_ = db.Close()
+
+	store, err := NewStorageWithPath(path)
+	if err != nil {
+		t.Fatalf("NewStorageWithPath() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	items, err := store.StaleProfileItems(time.Now())
+	if err != nil {
+		t.Fatalf("StaleProfileItems() error = %v", err)
+	}
+	want := []models.ProfileItem{
+		{Kind: models.ProfileItemPreference, Text: "Prefers tea", ReinforcedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Persona: "work", Kind: models.ProfileItemPreference, Text: "Formal tone", ReinforcedAt: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("items = %+v, want %+v", items, want)
+	}
+	for i := range want {
+		if items[i].Persona != want[i].Persona || items[i].Kind != want[i].Kind || items[i].Text != want[i].Text || !items[i].ReinforcedAt.Equal(want[i].ReinforcedAt) {
+			t.Errorf("items[%d] = %+v, want %+v", i, items[i], want[i])
+		}
+	}
+}
@@ -146,3 +146,112 @@ func TestProfileNilArrays(t *testing.T) {
 		t.Errorf("Name = %v, want NilTest", retrieved.Name)
 	}
 }
+
+func TestProfileConstraintsRoundTrip(t *testing.T) {
+	db, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("OpenInMemory() error = %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	store := NewProfileStore(db)
+	profile := &models.UserProfile{
+		Name: "Harper",
+		Constraints: []models.ProfileConstraint{
+			{Type: "Dietary Restriction", Description: "vegetarian", Severity: models.SeverityStrict},
+		},
+	}
+	if err := store.Save(profile); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	retrieved, err := store.Get()
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(retrieved.Constraints) != 1 {
+		t.Fatalf("Constraints length = %d, want 1", len(retrieved.Constraints))
+	}
+	if got := retrieved.Constraints[0]; got != profile.Constraints[0] {
+		t.Errorf("Constraints[0] = %+v, want %+v", got, profile.Constraints[0])
+	}
+}
+
+func TestPersonas(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.SaveUserProfile(&models.UserProfile{Name: "Harper", Preferences: []string{"concise answers"}}); err != nil {
+		t.Fatalf("SaveUserProfile() error = %v", err)
+	}
+
+	work := &models.Persona{
+		Name:        "Work",
+		Preferences: []string{"formal tone"},
+		Constraints: []models.ProfileConstraint{{Type: "Schedule", Description: "no meetings before 10am"}},
+	}
+	if err := store.SavePersona(work); err != nil {
+		t.Fatalf("SavePersona() error = %v", err)
+	}
+	if work.Name != "work" {
+		t.Errorf("SavePersona() name = %q, want normalized %q", work.Name, "work")
+	}
+	if err := store.SavePersona(&models.Persona{Name: "home", Preferences: []string{"casual tone"}}); err != nil {
+		t.Fatalf("SavePersona() error = %v", err)
+	}
+
+	personas, err := store.ListPersonas()
+	if err != nil {
+		t.Fatalf("ListPersonas() error = %v", err)
+	}
+	if len(personas) != 2 || personas[0].Name != "home" || personas[1].Name != "work" {
+		t.Fatalf("ListPersonas() = %+v, want home and work", personas)
+	}
+
+	view, err := store.GetUserProfileAs("WORK")
+	if err != nil {
+		t.Fatalf("GetUserProfileAs() error = %v", err)
+	}
+	if view.Persona != "work" || view.Name != "Harper" {
+		t.Errorf("GetUserProfileAs() persona = %q name = %q", view.Persona, view.Name)
+	}
+	if len(view.Preferences) != 2 || view.Preferences[1] != "formal tone" {
+		t.Errorf("GetUserProfileAs() preferences = %v, want shared then persona", view.Preferences)
+	}
+	if len(view.Constraints) != 1 {
+		t.Errorf("GetUserProfileAs() constraints = %v, want 1", view.Constraints)
+	}
+
+	// The shared profile is untouched by the persona view
+	base, err := store.GetUserProfile()
+	if err != nil {
+		t.Fatalf("GetUserProfile() error = %v", err)
+	}
+	if len(base.Preferences) != 1 || base.Persona != "" {
+		t.Errorf("GetUserProfile() = %+v, want only shared preferences", base)
+	}
+
+	// A persona that does not exist yet adds nothing
+	view, err = store.GetUserProfileAs("travel")
+	if err != nil {
+		t.Fatalf("GetUserProfileAs(travel) error = %v", err)
+	}
+	if view.Persona != "travel" || len(view.Preferences) != 1 {
+		t.Errorf("GetUserProfileAs(travel) = %+v", view)
+	}
+
+	if _, err := store.GetUserProfileAs("not a name"); err == nil {
+		t.Error("GetUserProfileAs() with invalid name should fail")
+	}
+
+	deleted, err := store.DeletePersona("home")
+	if err != nil || !deleted {
+		t.Fatalf("DeletePersona() = %v, %v", deleted, err)
+	}
+	if p, _ := store.GetPersona("home"); p != nil {
+		t.Error("GetPersona() after delete should return nil")
+	}
+}
@@ -0,0 +1,183 @@
+// ABOUTME: Export and import of the user profile on its own, apart from conversation history
+// ABOUTME: Moves the learned profile and personas between contexts or machines as a YAML export file
+package sqlite
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// ProfileImport counts what ImportProfile added to the stored profile
+type ProfileImport struct {
+	Preferences int  `json:"preferences"` // Across the shared profile and personas
+	Topics      int  `json:"topics"`
+	Constraints int  `json:"constraints"` // Across the shared profile and personas
+	Personas    int  `json:"personas"`    // Personas created or updated
+	Replaced    bool `json:"replaced"`    // The stored profile was replaced rather than merged into
+}
+
+// exportProfile returns the shared profile and every persona as exports write them, or
+// nil when there is neither
+func (s *Storage) exportProfile() (*ExportProfile, error) {
+	var exported *ExportProfile
+	profile, err := s.GetUserProfile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+	if profile != nil {
+		exported = &ExportProfile{
+			Name:             profile.Name,
+			Preferences:      profile.Preferences,
+			TopicsOfInterest: profile.TopicsOfInterest,
+			Constraints:      profile.Constraints,
+		}
+	}
+	personas, err := s.profile.ListPersonas()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get personas: %w", err)
+	}
+	if len(personas) > 0 && exported == nil {
+		exported = &ExportProfile{}
+	}
+	for _, p := range personas {
+		exported.Personas = append(exported.Personas, ExportPersona{
+			Name:        p.Name,
+			Preferences: p.Preferences,
+			Constraints: p.Constraints,
+		})
+	}
+	return exported, nil
+}
+
+// ExportProfileToYAML writes the shared profile and every persona, and nothing else, to
+// a YAML export file that LoadExport reads and ImportProfile applies
+func (s *Storage) ExportProfileToYAML(outputPath string) (*ExportProfile, error) {
+	profile, err := s.exportProfile()
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("there is no profile to export")
+	}
+	data := &ExportData{
+		Version:    "1.0",
+		ExportedAt: s.db.now().In(s.location()).Format(time.RFC3339),
+		Tool:       "memory",
+		Profile:    profile,
+	}
+	if data.Manifest, err = newExportManifest(data, nil); err != nil {
+		return nil, err
+	}
+	return profile, writeExportYAML(outputPath, data)
+}
+
+// ImportProfile merges an exported profile into the stored one: preferences, topics,
+// constraints, and personas missing here are added, and the name is taken only when
+// none is set. With replace, the shared profile and personas become exactly the
+// export's, deleting personas it does not list. Everything is validated before
+// anything is saved.
+func (s *Storage) ImportProfile(imported *ExportProfile, replace bool) (*ProfileImport, error) {
+	if imported == nil {
+		return nil, fmt.Errorf("the file holds no profile")
+	}
+	result := &ProfileImport{Replaced: replace}
+
+	profile, err := s.GetUserProfile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+	if profile == nil || replace {
+		profile = &models.UserProfile{Preferences: []string{}, TopicsOfInterest: []string{}, Constraints: []models.ProfileConstraint{}}
+	}
+	if profile.Name == "" || replace {
+		profile.Name = imported.Name
+	}
+	profile.Preferences, result.Preferences = mergeStrings(profile.Preferences, imported.Preferences)
+	profile.TopicsOfInterest, result.Topics = mergeStrings(profile.TopicsOfInterest, imported.TopicsOfInterest)
+	for _, c := range imported.Constraints {
+		before := len(profile.Constraints)
+		if err := profile.AddConstraint(c); err != nil {
+			return nil, fmt.Errorf("invalid constraint %q: %w", c, err)
+		}
+		result.Constraints += len(profile.Constraints) - before
+	}
+
+	personas := make([]*models.Persona, 0, len(imported.Personas))
+	listed := map[string]bool{}
+	for _, p := range imported.Personas {
+		name, err := models.NormalizePersonaName(p.Name)
+		if err != nil {
+			return nil, err
+		}
+		if listed[name] {
+			return nil, fmt.Errorf("persona %s is listed twice", name)
+		}
+		listed[name] = true
+
+		persona := &models.Persona{Name: name}
+		if !replace {
+			existing, err := s.GetPersona(name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get persona %s: %w", name, err)
+			}
+			if existing != nil {
+				persona = existing
+			}
+		}
+		var added int
+		persona.Preferences, added = mergeStrings(persona.Preferences, p.Preferences)
+		result.Preferences += added
+		for _, c := range p.Constraints {
+			before := len(persona.Constraints)
+			if err := persona.AddConstraint(c); err != nil {
+				return nil, fmt.Errorf("invalid constraint %q in persona %s: %w", c, name, err)
+			}
+			result.Constraints += len(persona.Constraints) - before
+		}
+		personas = append(personas, persona)
+	}
+
+	var stale []string
+	if replace {
+		current, err := s.ListPersonas()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get personas: %w", err)
+		}
+		for _, p := range current {
+			if !listed[p.Name] {
+				stale = append(stale, p.Name)
+			}
+		}
+	}
+
+	if err := s.SaveUserProfile(profile); err != nil {
+		return nil, fmt.Errorf("failed to save profile: %w", err)
+	}
+	for _, persona := range personas {
+		if err := s.SavePersona(persona); err != nil {
+			return nil, fmt.Errorf("failed to save persona %s: %w", persona.Name, err)
+		}
+		result.Personas++
+	}
+	for _, name := range stale {
+		if _, err := s.DeletePersona(name); err != nil {
+			return nil, fmt.Errorf("failed to delete persona %s: %w", name, err)
+		}
+	}
+	return result, nil
+}
+
+// mergeStrings appends the items of add missing from list, returning how many it added
+func mergeStrings(list, add []string) ([]string, int) {
+	added := 0
+	for _, item := range add {
+		if item != "" && !slices.Contains(list, item) {
+			list = append(list, item)
+			added++
+		}
+	}
+	return list, added
+}
@@ -0,0 +1,79 @@
+// ABOUTME: Tests for exporting and importing the user profile on its own
+// ABOUTME: Verifies the round trip through a YAML file, merging, and that invalid imports save nothing
+
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestProfileExportImport(t *testing.T) {
+	source, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = source.Close() }()
+
+	if err := source.SaveUserProfile(&models.UserProfile{Name: "Harper", Preferences: []string{"tea"}, TopicsOfInterest: []string{"Go"},
+		Constraints: []models.ProfileConstraint{{Type: "Allergy", Description: "peanuts", Severity: models.SeverityStrict}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.SavePersona(&models.Persona{Name: "work", Preferences: []string{"formal tone"}}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "profile.yaml")
+	if _, err := source.ExportProfileToYAML(path); err != nil {
+		t.Fatalf("ExportProfileToYAML() error = %v", err)
+	}
+	data, err := LoadExport(path)
+	if err != nil {
+		t.Fatalf("LoadExport() error = %v", err)
+	}
+	if data.Profile == nil || len(data.Blocks) != 0 || len(data.Facts) != 0 {
+		t.Fatalf("profile export = %+v, want only the profile", data)
+	}
+
+	target, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = target.Close() }()
+	if err := target.SaveUserProfile(&models.UserProfile{Name: "Doc", Preferences: []string{"coffee", "tea"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// An invalid constraint anywhere in the file saves nothing
+	bad := *data.Profile
+	bad.Personas = []ExportPersona{{Name: "home", Constraints: []models.ProfileConstraint{{Type: "Diet", Description: "vegan", Severity: "extreme"}}}}
+	if _, err := target.ImportProfile(&bad, false); err == nil {
+		t.Fatal("ImportProfile() with an invalid constraint succeeded")
+	}
+	if profile, _ := target.GetUserProfile(); len(profile.Preferences) != 2 || len(profile.Constraints) != 0 {
+		t.Errorf("profile after a failed import = %+v, want it unchanged", profile)
+	}
+
+	result, err := target.ImportProfile(data.Profile, false)
+	if err != nil {
+		t.Fatalf("ImportProfile() error = %v", err)
+	}
+	if *result != (ProfileImport{Preferences: 1, Topics: 1, Constraints: 1, Personas: 1}) {
+		t.Errorf("ImportProfile() = %+v", result)
+	}
+	profile, err := target.GetUserProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Name != "Doc" || len(profile.Preferences) != 2 || len(profile.TopicsOfInterest) != 1 || len(profile.Constraints) != 1 {
+		t.Errorf("merged profile = %+v, want Doc's name and preferences plus the topic and constraint", profile)
+	}
+	if work, err := target.GetPersona("work"); err != nil || work == nil || work.Preferences[0] != "formal tone" {
+		t.Errorf("work persona = %+v, %v", work, err)
+	}
+
+	if _, err := target.ImportProfile(nil, false); err == nil {
+		t.Error("ImportProfile(nil) succeeded, want an error for a file without a profile")
+	}
+}
@@ -0,0 +1,103 @@
+// ABOUTME: Do-not-recall list: blocks and facts kept in the database but never surfaced automatically
+// ABOUTME: Searches, retrieve_memory, and hydrated context skip them; explicit lookups by ID or key still work
+package sqlite
+
+import "fmt"
+
+// RecallExclusions lists what is excluded from recall
+type RecallExclusions struct {
+	Blocks   []ExcludedBlock `json:"blocks"`
+	FactKeys []string        `json:"fact_keys"` // Keys with at least one excluded fact
+}
+
+// ExcludedBlock is a block excluded from recall
+type ExcludedBlock struct {
+	BlockID    string `json:"block_id"`
+	TopicLabel string `json:"topic_label"`
+}
+
+// SetExcludeFromRecall flags or unflags a block, reporting whether it exists
+func (s *BlockStore) SetExcludeFromRecall(blockID string, excluded bool) (bool, error) {
+	result, err := s.db.Exec(`UPDATE bridge_blocks SET exclude_from_recall = ? WHERE id = ?`, excluded, blockID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update block: %w", err)
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// SetExcludeFromRecall flags or unflags every fact with key, current and superseded,
+// returning how many there are
+func (s *FactStore) SetExcludeFromRecall(key string, excluded bool) (int64, error) {
+	result, err := s.db.Exec(`UPDATE facts SET exclude_from_recall = ? WHERE key = ?`, excluded, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update facts: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ExcludeBlockFromRecall keeps a block out of searches and hydrated context, or lets
+// it back in when excluded is false
+func (s *Storage) ExcludeBlockFromRecall(blockID string, excluded bool) error {
+	found, err := s.blocks.SetExcludeFromRecall(blockID, excluded)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("block %s not found", blockID)
+	}
+	return nil
+}
+
+// ExcludeFactFromRecall keeps every fact with key out of fact searches, retrieve_memory,
+// and hydrated context, or lets them back in when excluded is false. Facts saved under
+// the key later are recalled as usual.
+func (s *Storage) ExcludeFactFromRecall(key string, excluded bool) error {
+	n, err := s.facts.SetExcludeFromRecall(key, excluded)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no fact with key %q", key)
+	}
+	return nil
+}
+
+// ListRecallExclusions returns the blocks and fact keys excluded from recall
+func (s *Storage) ListRecallExclusions() (*RecallExclusions, error) {
+	exclusions := &RecallExclusions{Blocks: []ExcludedBlock{}, FactKeys: []string{}}
+
+	rows, err := s.db.Query(`
+		SELECT id, COALESCE(topic_label, '') FROM bridge_blocks
+		WHERE exclude_from_recall
+		ORDER BY updated_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list excluded blocks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var block ExcludedBlock
+		if err := rows.Scan(&block.BlockID, &block.TopicLabel); err != nil {
+			return nil, fmt.Errorf("failed to scan excluded block: %w", err)
+		}
+		exclusions.Blocks = append(exclusions.Blocks, block)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.db.Query(`SELECT DISTINCT key FROM facts WHERE exclude_from_recall ORDER BY key`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list excluded facts: %w", err)
+	}
+	defer func() { _ = keys.Close() }()
+	for keys.Next() {
+		var key string
+		if err := keys.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan excluded fact: %w", err)
+		}
+		exclusions.FactKeys = append(exclusions.FactKeys, key)
+	}
+	return exclusions, keys.Err()
+}
@@ -0,0 +1,92 @@
+// ABOUTME: Tests for the do-not-recall list
+// ABOUTME: Verifies excluded blocks and facts stay stored but out of searches until let back in
+package sqlite
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestExcludeFromRecall(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	private := storeKeywordBlock(t, store, "turn_recall_private", "therapy", "anxiety")
+	public := storeKeywordBlock(t, store, "turn_recall_public", "therapy", "insurance")
+	for _, fact := range []models.Fact{
+		{FactID: "fact_recall_old", BlockID: private, Key: "therapist_name", Value: "Dr. Old", Confidence: 0.9, CreatedAt: time.Now().Add(-time.Hour)},
+		{FactID: "fact_recall_new", BlockID: private, Key: "therapist_name", Value: "Dr. New", Confidence: 0.9},
+		{FactID: "fact_recall_plan", BlockID: public, Key: "therapy_plan", Value: "PPO", Confidence: 0.9},
+	} {
+		if err := store.SaveFact(&fact); err != nil {
+			t.Fatalf("SaveFact() error = %v", err)
+		}
+	}
+
+	if err := store.ExcludeBlockFromRecall(private, true); err != nil {
+		t.Fatalf("ExcludeBlockFromRecall() error = %v", err)
+	}
+	if err := store.ExcludeFactFromRecall("therapist_name", true); err != nil {
+		t.Fatalf("ExcludeFactFromRecall() error = %v", err)
+	}
+	if err := store.ExcludeBlockFromRecall("block_missing", true); err == nil {
+		t.Error("ExcludeBlockFromRecall() of a missing block succeeded, want an error")
+	}
+	if err := store.ExcludeFactFromRecall("missing_key", true); err == nil {
+		t.Error("ExcludeFactFromRecall() of a missing key succeeded, want an error")
+	}
+
+	results, err := store.SearchMemory("therapy", 10)
+	if err != nil {
+		t.Fatalf("SearchMemory() error = %v", err)
+	}
+	if len(results) != 1 || results[0].BlockID != public {
+		t.Errorf("SearchMemory() = %+v, want only the block still recalled", results)
+	}
+	facts, err := store.SearchFacts("therap", 10)
+	if err != nil {
+		t.Fatalf("SearchFacts() error = %v", err)
+	}
+	if len(facts) != 1 || facts[0].Key != "therapy_plan" {
+		t.Errorf("SearchFacts() = %+v, want neither therapist_name value", facts)
+	}
+
+	// Explicit lookups still work, and show the flag
+	block, err := store.GetBridgeBlock(private)
+	if err != nil || block == nil || !block.ExcludeFromRecall {
+		t.Errorf("GetBridgeBlock() = %+v, %v; want the block, marked excluded", block, err)
+	}
+	fact, err := store.GetFactByKey("therapist_name")
+	if err != nil || fact == nil || fact.Value != "Dr. New" || !fact.ExcludeFromRecall {
+		t.Errorf("GetFactByKey() = %+v, %v; want the latest value, marked excluded", fact, err)
+	}
+
+	exclusions, err := store.ListRecallExclusions()
+	if err != nil {
+		t.Fatalf("ListRecallExclusions() error = %v", err)
+	}
+	want := &RecallExclusions{Blocks: []ExcludedBlock{{BlockID: private, TopicLabel: block.TopicLabel}}, FactKeys: []string{"therapist_name"}}
+	if !reflect.DeepEqual(exclusions, want) {
+		t.Errorf("ListRecallExclusions() = %+v, want %+v", exclusions, want)
+	}
+
+	// Saving the block again keeps it excluded; letting it back in recalls it
+	if err := store.AppendTurnToBlock(private, &models.Turn{TurnID: "turn_recall_more", Timestamp: time.Now(), Keywords: []string{"sleep"}}); err != nil {
+		t.Fatalf("AppendTurnToBlock() error = %v", err)
+	}
+	if results, _ := store.SearchMemory("sleep", 10); len(results) != 0 {
+		t.Errorf("SearchMemory(sleep) = %+v, want the block still excluded after a new turn", results)
+	}
+	if err := store.ExcludeBlockFromRecall(private, false); err != nil {
+		t.Fatalf("ExcludeBlockFromRecall(false) error = %v", err)
+	}
+	if results, _ := store.SearchMemory("sleep", 10); len(results) != 1 {
+		t.Errorf("SearchMemory(sleep) = %+v, want the block recalled again", results)
+	}
+}
@@ -0,0 +1,139 @@
+// ABOUTME: Storage support for re-embedding turns with a different model
+// ABOUTME: Finds turns lacking embeddings for a model and swaps their vectors atomically
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// PendingTurn is a turn that still needs embeddings, with the block it belongs to
+type PendingTurn struct {
+	BlockID string
+	Turn    models.Turn
+}
+
+// TurnsNeedingEmbedding returns turns with no embeddings produced by model,
+// oldest first. An empty model matches turns with no embeddings at all.
+func (s *Storage) TurnsNeedingEmbedding(model string) ([]PendingTurn, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	query := `
+		SELECT t.id, t.block_id, t.user_message, t.ai_response, t.keywords, t.topics, t.messages, t.created_at
+		FROM turns t
+		WHERE NOT EXISTS (
+			SELECT 1 FROM embeddings e WHERE e.turn_id = t.id AND e.model = ?
+		)
+		ORDER BY t.created_at ASC, t.id ASC
+	`
+	args := []interface{}{model}
+	if model == "" {
+		query = `
+			SELECT t.id, t.block_id, t.user_message, t.ai_response, t.keywords, t.topics, t.messages, t.created_at
+			FROM turns t
+			WHERE NOT EXISTS (SELECT 1 FROM embeddings e WHERE e.turn_id = t.id)
+			ORDER BY t.created_at ASC, t.id ASC
+		`
+		args = nil
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query turns needing embedding: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var pending []PendingTurn
+	for rows.Next() {
+		var (
+			p            PendingTurn
+			keywordsJSON sql.NullString
+			topicsJSON   sql.NullString
+			messagesJSON sql.NullString
+		)
+		if err := rows.Scan(&p.Turn.TurnID, &p.BlockID, &p.Turn.UserMessage, &p.Turn.AIResponse,
+			&keywordsJSON, &topicsJSON, &messagesJSON, &p.Turn.Timestamp); err != nil {
+			return nil, err
+		}
+		if keywordsJSON.Valid && keywordsJSON.String != "" {
+			_ = json.Unmarshal([]byte(keywordsJSON.String), &p.Turn.Keywords)
+		}
+		if topicsJSON.Valid && topicsJSON.String != "" {
+			_ = json.Unmarshal([]byte(topicsJSON.String), &p.Turn.Topics)
+		}
+		if messagesJSON.Valid && messagesJSON.String != "" {
+			_ = json.Unmarshal([]byte(messagesJSON.String), &p.Turn.Messages)
+		}
+		pending = append(pending, p)
+	}
+
+	return pending, rows.Err()
+}
+
+// ReplaceTurnEmbeddings deletes a turn's existing embeddings and chunks and stores new
+// ones in a single transaction, so an interrupted re-embed never leaves a turn half done.
+// Vectors may use any dimension, but all vectors for the turn must match.
+func (s *Storage) ReplaceTurnEmbeddings(turnID, blockID, model string, chunks []models.Chunk, vectors [][]float64) error {
+	if len(chunks) != len(vectors) {
+		return fmt.Errorf("chunk/vector count mismatch: %d chunks, %d vectors", len(chunks), len(vectors))
+	}
+	for i := range vectors {
+		if len(vectors[i]) == 0 || len(vectors[i]) != len(vectors[0]) {
+			return fmt.Errorf("invalid embedding dimension for chunk %s: got %d", chunks[i].ChunkID, len(vectors[i]))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	format, err := s.embeddings.Format()
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Old vectors of the turn leave the block's centroid, so it is rebuilt; a turn
+	// embedded for the first time is folded in
+	var previousBlock sql.NullString
+	if err := tx.QueryRow("SELECT block_id FROM embeddings WHERE turn_id = ? LIMIT 1", turnID).Scan(&previousBlock); err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to read old embeddings: %w", err)
+	}
+	stale := previousBlock.Valid
+	if _, err := tx.Exec("DELETE FROM embeddings WHERE turn_id = ?", turnID); err != nil {
+		return fmt.Errorf("failed to delete old embeddings: %w", err)
+	}
+	if err := deleteChunks(tx, turnID); err != nil {
+		return err
+	}
+	if err := saveChunks(tx, blockID, chunks, s.db.now()); err != nil {
+		return err
+	}
+
+	for i, chunk := range chunks {
+		if _, err := tx.Exec(upsertEmbeddingSQL, embeddingArgs(format, chunk.ChunkID, turnID, blockID, model, vectors[i], s.db.now())...); err != nil {
+			return fmt.Errorf("failed to save embedding for chunk %s: %w", chunk.ChunkID, err)
+		}
+		if !stale {
+			if stale, err = addToCentroid(tx, blockID, model, vectors[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if stale {
+		return refreshCentroids(s.db, blockID, previousBlock.String)
+	}
+	return nil
+}
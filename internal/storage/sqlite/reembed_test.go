@@ -0,0 +1,102 @@
+// ABOUTME: Tests for re-embedding storage support
+// ABOUTME: Verifies pending-turn detection by model and atomic embedding replacement
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestTurnsNeedingEmbedding(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, _ := store.StoreTurn(&models.Turn{TurnID: "turn_re_1", Timestamp: time.Now(), UserMessage: "first"})
+	_ = store.AppendTurnToBlock(blockID, &models.Turn{TurnID: "turn_re_2", Timestamp: time.Now().Add(time.Second), UserMessage: "second"})
+
+	pending, err := store.TurnsNeedingEmbedding("model-a")
+	if err != nil {
+		t.Fatalf("TurnsNeedingEmbedding() error = %v", err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("len(pending) = %d, want 2", len(pending))
+	}
+	if pending[0].Turn.TurnID != "turn_re_1" || pending[0].BlockID != blockID {
+		t.Errorf("pending[0] = %+v, want turn_re_1 in %s", pending[0], blockID)
+	}
+
+	chunks := []models.Chunk{{ChunkID: "chunk_re_1", TurnID: "turn_re_1", Content: "first"}}
+	if err := store.ReplaceTurnEmbeddings("turn_re_1", blockID, "model-a", chunks, [][]float64{{1, 0, 0}}); err != nil {
+		t.Fatalf("ReplaceTurnEmbeddings() error = %v", err)
+	}
+
+	pending, _ = store.TurnsNeedingEmbedding("model-a")
+	if len(pending) != 1 || pending[0].Turn.TurnID != "turn_re_2" {
+		t.Errorf("pending for model-a = %+v, want only turn_re_2", pending)
+	}
+
+	// A different model still needs both turns
+	pending, _ = store.TurnsNeedingEmbedding("model-b")
+	if len(pending) != 2 {
+		t.Errorf("len(pending) for model-b = %d, want 2", len(pending))
+	}
+
+	// Empty model means "no embeddings at all"
+	pending, _ = store.TurnsNeedingEmbedding("")
+	if len(pending) != 1 || pending[0].Turn.TurnID != "turn_re_2" {
+		t.Errorf("pending without embeddings = %+v, want only turn_re_2", pending)
+	}
+}
+
+func TestReplaceTurnEmbeddings_ReplacesOldVectors(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, _ := store.StoreTurn(&models.Turn{TurnID: "turn_swap", Timestamp: time.Now(), UserMessage: "hello"})
+
+	old := []models.Chunk{{ChunkID: "chunk_old_1"}, {ChunkID: "chunk_old_2"}}
+	if err := store.ReplaceTurnEmbeddings("turn_swap", blockID, "old-model", old, [][]float64{{1, 0}, {0, 1}}); err != nil {
+		t.Fatalf("ReplaceTurnEmbeddings(old) error = %v", err)
+	}
+
+	fresh := []models.Chunk{{ChunkID: "chunk_new_1"}}
+	if err := store.ReplaceTurnEmbeddings("turn_swap", blockID, "new-model", fresh, [][]float64{{0.5, 0.5, 0.5}}); err != nil {
+		t.Fatalf("ReplaceTurnEmbeddings(new) error = %v", err)
+	}
+
+	embeddings, err := store.GetVectorStorage().GetByBlock(blockID)
+	if err != nil {
+		t.Fatalf("GetByBlock() error = %v", err)
+	}
+	if len(embeddings) != 1 || embeddings[0].ChunkID != "chunk_new_1" {
+		t.Fatalf("embeddings = %+v, want only chunk_new_1", embeddings)
+	}
+	if len(embeddings[0].Vector) != 3 {
+		t.Errorf("vector dimension = %d, want 3", len(embeddings[0].Vector))
+	}
+}
+
+func TestReplaceTurnEmbeddings_Validation(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	chunks := []models.Chunk{{ChunkID: "a"}, {ChunkID: "b"}}
+
+	if err := store.ReplaceTurnEmbeddings("t", "b", "m", chunks, [][]float64{{1}}); err == nil {
+		t.Error("expected error for chunk/vector count mismatch")
+	}
+	if err := store.ReplaceTurnEmbeddings("t", "b", "m", chunks, [][]float64{{1, 2}, {1}}); err == nil {
+		t.Error("expected error for mixed vector dimensions")
+	}
+}
@@ -0,0 +1,82 @@
+// ABOUTME: Block relation storage for SQLite
+// ABOUTME: Keeps directed links between Bridge Blocks and lists a block's related topics
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// RelationStore handles block relation persistence
+type RelationStore struct {
+	db *DB
+}
+
+// NewRelationStore creates a new RelationStore
+func NewRelationStore(db *DB) *RelationStore {
+	return &RelationStore{db: db}
+}
+
+// Add records from <relation> to; an existing identical relation is left alone
+func (s *RelationStore) Add(from, to string, relation models.RelationType) error {
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO block_relations (from_block_id, to_block_id, relation, created_at)
+		VALUES (?, ?, ?, ?)
+	`, from, to, string(relation), s.db.now())
+	if err != nil {
+		return fmt.Errorf("failed to add relation: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes from <relation> to, reporting whether it existed
+func (s *RelationStore) Remove(from, to string, relation models.RelationType) (bool, error) {
+	result, err := s.db.Exec(`
+		DELETE FROM block_relations WHERE from_block_id = ? AND to_block_id = ? AND relation = ?
+	`, from, to, string(relation))
+	if err != nil {
+		return false, fmt.Errorf("failed to remove relation: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// Related returns the blocks linked to blockID in either direction, newest link first.
+// Incoming links are named by their inverse (e.g. "spawned" for spawned-from).
+func (s *RelationStore) Related(blockID string) ([]models.RelatedTopic, error) {
+	rows, err := s.db.Query(`
+		SELECT r.to_block_id, b.topic_label, b.status, r.relation, 0, r.created_at
+		FROM block_relations r JOIN bridge_blocks b ON b.id = r.to_block_id
+		WHERE r.from_block_id = ?
+		UNION ALL
+		SELECT r.from_block_id, b.topic_label, b.status, r.relation, 1, r.created_at
+		FROM block_relations r JOIN bridge_blocks b ON b.id = r.from_block_id
+		WHERE r.to_block_id = ?
+		ORDER BY 6 DESC, 1
+	`, blockID, blockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get related blocks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	related := []models.RelatedTopic{}
+	for rows.Next() {
+		var (
+			topic    models.RelatedTopic
+			status   string
+			relation string
+			incoming bool
+		)
+		if err := rows.Scan(&topic.BlockID, &topic.TopicLabel, &status, &relation, &incoming, &topic.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan relation: %w", err)
+		}
+		topic.Status = models.BridgeBlockStatus(status)
+		topic.Relation = relation
+		if incoming {
+			topic.Relation = models.RelationType(relation).Inverse()
+		}
+		related = append(related, topic)
+	}
+	return related, rows.Err()
+}
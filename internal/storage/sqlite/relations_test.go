@@ -0,0 +1,78 @@
+// ABOUTME: Tests for block relation storage
+// ABOUTME: Verifies linking, inverse names, validation, and cleanup when a block is deleted
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestBlockRelations(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	first, _ := store.StoreTurn(&models.Turn{TurnID: "turn_rel_1", Timestamp: time.Now(), UserMessage: "Deploy pipeline", Topics: []string{"deploys"}})
+	second, _ := store.StoreTurn(&models.Turn{TurnID: "turn_rel_2", Timestamp: time.Now(), UserMessage: "Rollback plan", Topics: []string{"rollbacks"}})
+
+	if err := store.RelateBlocks(second, first, models.RelationSpawnedFrom); err != nil {
+		t.Fatalf("RelateBlocks() error = %v", err)
+	}
+	if err := store.RelateBlocks(first, second, models.RelationReferences); err != nil {
+		t.Fatalf("RelateBlocks() error = %v", err)
+	}
+	// Recording the same link twice is harmless
+	if err := store.RelateBlocks(second, first, models.RelationSpawnedFrom); err != nil {
+		t.Fatalf("RelateBlocks() repeat error = %v", err)
+	}
+
+	related, err := store.GetRelatedTopics(first)
+	if err != nil {
+		t.Fatalf("GetRelatedTopics() error = %v", err)
+	}
+	relations := map[string]string{}
+	for _, r := range related {
+		relations[r.Relation] = r.BlockID
+	}
+	if len(related) != 2 || relations["references"] != second || relations["spawned"] != second {
+		t.Errorf("GetRelatedTopics(first) = %+v, want references and spawned", related)
+	}
+	if related[0].TopicLabel == "" || related[0].Status == "" {
+		t.Errorf("GetRelatedTopics() = %+v, want topic label and status filled in", related[0])
+	}
+
+	removed, err := store.UnrelateBlocks(first, second, models.RelationReferences)
+	if err != nil || !removed {
+		t.Fatalf("UnrelateBlocks() = %v, %v", removed, err)
+	}
+	related, _ = store.GetRelatedTopics(second)
+	if len(related) != 1 || related[0].Relation != "spawned-from" {
+		t.Errorf("GetRelatedTopics(second) = %+v, want only spawned-from", related)
+	}
+
+	if err := store.RelateBlocks(first, first, models.RelationReferences); err == nil {
+		t.Error("RelateBlocks() to itself should fail")
+	}
+	if err := store.RelateBlocks(first, "block_missing", models.RelationReferences); err == nil {
+		t.Error("RelateBlocks() to a missing block should fail")
+	}
+	if err := store.RelateBlocks(first, second, "duplicates"); err == nil {
+		t.Error("RelateBlocks() with an unknown relation should fail")
+	}
+	if _, err := store.GetRelatedTopics("block_missing"); err == nil {
+		t.Error("GetRelatedTopics() for a missing block should fail")
+	}
+
+	// Deleting a block drops its links
+	if err := store.DeleteBridgeBlock(second); err != nil {
+		t.Fatalf("DeleteBridgeBlock() error = %v", err)
+	}
+	related, _ = store.GetRelatedTopics(first)
+	if len(related) != 0 {
+		t.Errorf("GetRelatedTopics() after delete = %+v, want none", related)
+	}
+}
@@ -0,0 +1,295 @@
+// ABOUTME: Retention policies on blocks and tags that override the global pruning policy
+// ABOUTME: Keeps topics out of eviction, or deletes them a fixed time after their last update
+package sqlite
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// RetentionPolicies lists the retention policies set on blocks and tags
+type RetentionPolicies struct {
+	Blocks []BlockRetention `json:"blocks"`
+	Tags   []TagRetention   `json:"tags"`
+}
+
+// BlockRetention is a block's own retention policy
+type BlockRetention struct {
+	BlockID    string     `json:"block_id"`
+	TopicLabel string     `json:"topic_label"`
+	Retention  string     `json:"retention"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // When the block is deleted, unless it is updated first
+}
+
+// TagRetention is the retention policy of the blocks carrying a tag
+type TagRetention struct {
+	Tag       string `json:"tag"`
+	Retention string `json:"retention"`
+	Blocks    int    `json:"blocks"` // Blocks carrying the tag
+}
+
+// ExpiredBlock is a block deleted, or due for deletion, because its retention ran out
+type ExpiredBlock struct {
+	BlockID    string    `json:"block_id"`
+	TopicLabel string    `json:"topic_label"`
+	Retention  string    `json:"retention"` // The policy in effect, from the block or its tags
+	UpdatedAt  time.Time `json:"updated_at"`
+	ExpiredAt  time.Time `json:"expired_at"`
+}
+
+// retainedBlock is a block with a retention policy of its own or from its tags
+type retainedBlock struct {
+	topicLabel string
+	updatedAt  time.Time
+	own        models.Retention
+	tags       models.Retention // The longest policy among the block's tags
+}
+
+// effective returns the policy in force: the block's own, else its tags'
+func (b *retainedBlock) effective() models.Retention {
+	if !b.own.IsZero() {
+		return b.own
+	}
+	return b.tags
+}
+
+// SetRetention sets or clears a block's own policy, reporting whether the block exists
+func (s *BlockStore) SetRetention(blockID, policy string) (bool, error) {
+	var value any
+	if policy != "" {
+		value = policy
+	}
+	result, err := s.db.Exec(`UPDATE bridge_blocks SET retention = ? WHERE id = ?`, value, blockID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update block retention: %w", err)
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// SetBlockRetention sets the retention policy of a block, overriding its tags' policies
+// and the global pruning policy; the zero policy clears it
+func (s *Storage) SetBlockRetention(blockID string, policy models.Retention) error {
+	found, err := s.blocks.SetRetention(blockID, policy.String())
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("block %s not found", blockID)
+	}
+	return nil
+}
+
+// SetTagRetention sets the retention policy of every block carrying tag, now or later,
+// unless the block has a policy of its own; the zero policy clears it. A block with
+// several such tags keeps the longest policy.
+func (s *Storage) SetTagRetention(tag string, policy models.Retention) error {
+	tag, err := models.NormalizeTag(tag)
+	if err != nil {
+		return err
+	}
+	if policy.IsZero() {
+		_, err = s.db.Exec(`DELETE FROM tag_retention WHERE tag = ?`, tag)
+	} else {
+		_, err = s.db.Exec(`
+			INSERT INTO tag_retention (tag, retention) VALUES (?, ?)
+			ON CONFLICT(tag) DO UPDATE SET retention = excluded.retention
+		`, tag, policy.String())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update tag retention: %w", err)
+	}
+	return nil
+}
+
+// TagRetentionPolicies returns the policy set on each tag
+func (s *Storage) TagRetentionPolicies() (map[string]string, error) {
+	rows, err := s.db.Query(`SELECT tag, retention FROM tag_retention`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tag retention: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	policies := make(map[string]string)
+	for rows.Next() {
+		var tag, retention string
+		if err := rows.Scan(&tag, &retention); err != nil {
+			return nil, fmt.Errorf("failed to scan tag retention: %w", err)
+		}
+		policies[tag] = retention
+	}
+	return policies, rows.Err()
+}
+
+// ListRetention returns the policies set on blocks, soonest to expire first, and on tags
+func (s *Storage) ListRetention() (*RetentionPolicies, error) {
+	policies := &RetentionPolicies{Blocks: []BlockRetention{}, Tags: []TagRetention{}}
+
+	retained, err := s.retainedBlocks()
+	if err != nil {
+		return nil, err
+	}
+	for blockID, block := range retained {
+		if block.own.IsZero() {
+			continue
+		}
+		entry := BlockRetention{BlockID: blockID, TopicLabel: block.topicLabel, Retention: block.own.String()}
+		if at, ok := block.own.ExpiresAt(block.updatedAt); ok {
+			entry.ExpiresAt = &at
+		}
+		policies.Blocks = append(policies.Blocks, entry)
+	}
+	sort.Slice(policies.Blocks, func(i, j int) bool {
+		a, b := policies.Blocks[i], policies.Blocks[j]
+		switch {
+		case a.ExpiresAt == nil || b.ExpiresAt == nil:
+			if (a.ExpiresAt == nil) != (b.ExpiresAt == nil) {
+				return b.ExpiresAt == nil
+			}
+		case !a.ExpiresAt.Equal(*b.ExpiresAt):
+			return a.ExpiresAt.Before(*b.ExpiresAt)
+		}
+		return a.BlockID < b.BlockID
+	})
+
+	rows, err := s.db.Query(`
+		SELECT tr.tag, tr.retention, (SELECT COUNT(*) FROM block_tags bt WHERE bt.tag = tr.tag)
+		FROM tag_retention tr
+		ORDER BY tr.tag
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tag retention: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var tag TagRetention
+		if err := rows.Scan(&tag.Tag, &tag.Retention, &tag.Blocks); err != nil {
+			return nil, fmt.Errorf("failed to scan tag retention: %w", err)
+		}
+		policies.Tags = append(policies.Tags, tag)
+	}
+	return policies, rows.Err()
+}
+
+// EffectiveRetention returns the policy in force for a block: its own, else the longest
+// of its tags' policies, else the zero policy, meaning the global policy applies
+func (s *Storage) EffectiveRetention(blockID string) (models.Retention, error) {
+	retained, err := s.retainedBlocks()
+	if err != nil {
+		return models.Retention{}, err
+	}
+	if block, ok := retained[blockID]; ok {
+		return block.effective(), nil
+	}
+	return models.Retention{}, nil
+}
+
+// ExpiredBlocks returns the blocks whose retention has run out, without deleting them
+func (s *Storage) ExpiredBlocks() ([]ExpiredBlock, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.expiredBlocks(s.db.now())
+}
+
+// ExpireBlocks deletes every block whose retention has run out, whatever its status and
+// the database size. Like eviction, deleting a block deletes its turns, attachments,
+// and embeddings; facts learned from it are kept.
+func (s *Storage) ExpireBlocks() ([]ExpiredBlock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plan, err := s.expiredBlocks(s.db.now())
+	if err != nil || len(plan) == 0 {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin expiry: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	expired := plan[:0]
+	for _, block := range plan {
+		// A block updated since it was planned has more time left
+		result, err := tx.Exec("DELETE FROM bridge_blocks WHERE id = ? AND updated_at <= ?", block.BlockID, block.UpdatedAt.UTC())
+		if err != nil {
+			return nil, fmt.Errorf("failed to expire block %s: %w", block.BlockID, err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			expired = append(expired, block)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit expiry: %w", err)
+	}
+	return expired, nil
+}
+
+// expiredBlocks lists the blocks whose retention ran out before now, oldest first
+func (s *Storage) expiredBlocks(now time.Time) ([]ExpiredBlock, error) {
+	retained, err := s.retainedBlocks()
+	if err != nil {
+		return nil, err
+	}
+	var expired []ExpiredBlock
+	for blockID, block := range retained {
+		policy := block.effective()
+		if at, ok := policy.ExpiresAt(block.updatedAt); ok && at.Before(now) {
+			expired = append(expired, ExpiredBlock{
+				BlockID:    blockID,
+				TopicLabel: block.topicLabel,
+				Retention:  policy.String(),
+				UpdatedAt:  block.updatedAt,
+				ExpiredAt:  at,
+			})
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		if !expired[i].ExpiredAt.Equal(expired[j].ExpiredAt) {
+			return expired[i].ExpiredAt.Before(expired[j].ExpiredAt)
+		}
+		return expired[i].BlockID < expired[j].BlockID
+	})
+	return expired, nil
+}
+
+// retainedBlocks returns every block with a policy of its own or from one of its tags.
+// Policies that no longer parse are ignored.
+func (s *Storage) retainedBlocks() (map[string]*retainedBlock, error) {
+	rows, err := s.db.Query(`
+		SELECT b.id, COALESCE(b.topic_label, ''), b.updated_at, COALESCE(b.retention, ''), COALESCE(tr.retention, '')
+		FROM bridge_blocks b
+		LEFT JOIN block_tags bt ON bt.block_id = b.id
+		LEFT JOIN tag_retention tr ON tr.tag = bt.tag
+		WHERE b.retention IS NOT NULL OR tr.retention IS NOT NULL
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention policies: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	retained := make(map[string]*retainedBlock)
+	for rows.Next() {
+		var (
+			blockID, label, own, tag string
+			updated                  time.Time
+		)
+		if err := rows.Scan(&blockID, &label, &updated, &own, &tag); err != nil {
+			return nil, fmt.Errorf("failed to scan retention policy: %w", err)
+		}
+		block, ok := retained[blockID]
+		if !ok {
+			block = &retainedBlock{topicLabel: label, updatedAt: updated}
+			block.own, _ = models.ParseRetention(own)
+			retained[blockID] = block
+		}
+		if policy, err := models.ParseRetention(tag); err == nil {
+			block.tags = block.tags.Longer(policy)
+		}
+	}
+	return retained, rows.Err()
+}
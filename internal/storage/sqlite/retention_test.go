@@ -0,0 +1,177 @@
+// ABOUTME: Tests for retention policies on blocks and tags
+// ABOUTME: Verifies kept blocks escape eviction, expired blocks are deleted and left out of exports
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestRetentionPolicies(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	day := 24 * time.Hour
+	old := time.Now().Add(-90 * day)
+	for _, b := range []struct {
+		id     string
+		status models.BridgeBlockStatus
+		tags   []string
+		own    models.Retention
+	}{
+		{"block_plain", models.StatusArchived, nil, models.Retention{}},
+		{"block_keep", models.StatusArchived, nil, models.KeepForever},
+		{"block_family", models.StatusArchived, []string{"family"}, models.Retention{}},
+		{"block_scratch", models.StatusPaused, []string{"scratch"}, models.Retention{}},
+		{"block_scratch_work", models.StatusArchived, []string{"scratch", "work"}, models.Retention{}},
+		{"block_family_own", models.StatusClosed, []string{"family"}, models.Retention{After: 30 * day}},
+	} {
+		if err := store.blocks.Save(&models.BridgeBlock{BlockID: b.id, DayID: "2025-01-01", TopicLabel: b.id, Status: b.status, CreatedAt: old, UpdatedAt: old}); err != nil {
+			t.Fatalf("Save block error = %v", err)
+		}
+		if err := store.turns.Save(b.id, &models.Turn{TurnID: b.id + "_turn", Timestamp: old, UserMessage: "notes"}); err != nil {
+			t.Fatalf("Save turn error = %v", err)
+		}
+		if len(b.tags) > 0 {
+			if _, err := store.TagBlock(b.id, b.tags, nil); err != nil {
+				t.Fatalf("TagBlock() error = %v", err)
+			}
+		}
+		if !b.own.IsZero() {
+			if err := store.SetBlockRetention(b.id, b.own); err != nil {
+				t.Fatalf("SetBlockRetention() error = %v", err)
+			}
+		}
+	}
+	for tag, policy := range map[string]models.Retention{"family": models.KeepForever, "scratch": {After: 30 * day}, "work": {After: 365 * day}} {
+		if err := store.SetTagRetention(tag, policy); err != nil {
+			t.Fatalf("SetTagRetention(%s) error = %v", tag, err)
+		}
+	}
+	if err := store.SetBlockRetention("block_missing", models.KeepForever); err == nil {
+		t.Error("SetBlockRetention() of a missing block succeeded, want an error")
+	}
+
+	// A block's own policy wins over its tags'; among tags the longest wins
+	for blockID, want := range map[string]string{
+		"block_plain":        "",
+		"block_family":       "keep",
+		"block_scratch_work": "365d",
+		"block_family_own":   "30d",
+	} {
+		if policy, err := store.EffectiveRetention(blockID); err != nil || policy.String() != want {
+			t.Errorf("EffectiveRetention(%s) = %q, %v; want %q", blockID, policy, err, want)
+		}
+	}
+
+	// Blocks kept by their own policy or a tag's are never evicted
+	plan, err := store.EvictionPlan(0, 30*day)
+	if err != nil {
+		t.Fatalf("EvictionPlan() error = %v", err)
+	}
+	evictable := make(map[string]bool)
+	for _, block := range plan {
+		evictable[block.BlockID] = true
+	}
+	if !evictable["block_plain"] || !evictable["block_scratch_work"] || evictable["block_keep"] || evictable["block_family"] {
+		t.Errorf("EvictionPlan() = %v, want kept blocks left out", evictable)
+	}
+
+	policies, err := store.ListRetention()
+	if err != nil {
+		t.Fatalf("ListRetention() error = %v", err)
+	}
+	if len(policies.Blocks) != 2 || policies.Blocks[0].BlockID != "block_family_own" || policies.Blocks[1].ExpiresAt != nil {
+		t.Errorf("ListRetention().Blocks = %+v, want the expiring block before the kept one", policies.Blocks)
+	}
+	if len(policies.Tags) != 3 || policies.Tags[0].Tag != "family" || policies.Tags[0].Blocks != 2 {
+		t.Errorf("ListRetention().Tags = %+v, want three tags", policies.Tags)
+	}
+
+	// Exports carry the policies and leave out expired blocks
+	data, err := store.Export()
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	exported := make(map[string]ExportBlock)
+	for _, block := range data.Blocks {
+		exported[block.BlockID] = block
+	}
+	if _, ok := exported["block_scratch"]; ok || len(exported) != 4 {
+		t.Errorf("Export() blocks = %d, want the 2 expired blocks left out", len(exported))
+	}
+	if exported["block_keep"].Retention != "keep" || data.Retention["scratch"] != "30d" {
+		t.Errorf("Export() retention = %q, tags %v; want the policies", exported["block_keep"].Retention, data.Retention)
+	}
+
+	expired, err := store.ExpireBlocks()
+	if err != nil {
+		t.Fatalf("ExpireBlocks() error = %v", err)
+	}
+	if len(expired) != 2 || expired[0].BlockID != "block_family_own" || expired[1].BlockID != "block_scratch" || expired[1].Retention != "30d" {
+		t.Errorf("ExpireBlocks() = %+v, want the two expired blocks", expired)
+	}
+	if block, _ := store.GetBridgeBlock("block_scratch"); block != nil {
+		t.Error("expired block still stored")
+	}
+	if expired, err := store.ExpiredBlocks(); err != nil || len(expired) != 0 {
+		t.Errorf("ExpiredBlocks() after expiring = %+v, %v; want none", expired, err)
+	}
+
+	// Clearing a policy lets the global policy apply again
+	if err := store.SetBlockRetention("block_keep", models.Retention{}); err != nil {
+		t.Fatalf("SetBlockRetention(zero) error = %v", err)
+	}
+	if err := store.SetTagRetention("family", models.Retention{}); err != nil {
+		t.Fatalf("SetTagRetention(zero) error = %v", err)
+	}
+	if plan, err := store.EvictionPlan(0, 30*day); err != nil || len(plan) != 4 {
+		t.Errorf("EvictionPlan() after clearing = %d blocks, %v; want 4", len(plan), err)
+	}
+}
+
+func TestRetention_SimulatedClock(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	clock := models.NewSimulatedClock(start)
+	store.SetClock(clock)
+
+	day := 24 * time.Hour
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_scratch", Timestamp: start, UserMessage: "scratch notes"})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	block, err := store.GetBridgeBlock(blockID)
+	if err != nil {
+		t.Fatalf("GetBridgeBlock() error = %v", err)
+	}
+	if !block.CreatedAt.Equal(start) || block.DayID != start.In(store.Location()).Format("2006-01-02") {
+		t.Errorf("block created %v on %s, want the simulated %v", block.CreatedAt, block.DayID, start)
+	}
+	if err := store.SetBlockRetention(blockID, models.Retention{After: 30 * day}); err != nil {
+		t.Fatalf("SetBlockRetention() error = %v", err)
+	}
+
+	// Retention is measured against the storage's clock, not the wall clock
+	clock.Advance(29 * day)
+	if expired, err := store.ExpiredBlocks(); err != nil || len(expired) != 0 {
+		t.Fatalf("ExpiredBlocks() after 29 days = %v, %v; want none", expired, err)
+	}
+	clock.Advance(2 * day)
+	expired, err := store.ExpireBlocks()
+	if err != nil {
+		t.Fatalf("ExpireBlocks() error = %v", err)
+	}
+	if len(expired) != 1 || expired[0].BlockID != blockID || !expired[0].ExpiredAt.Equal(start.Add(30*day)) {
+		t.Errorf("ExpireBlocks() after 31 days = %+v, want %s expired at %v", expired, blockID, start.Add(30*day))
+	}
+}
@@ -0,0 +1,100 @@
+// ABOUTME: Log of Governor routing decisions and the statistics drawn from it
+// ABOUTME: Shows how often each scenario fires and how often splits correct it, for tuning the match threshold
+package sqlite
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// RoutingStats summarizes the routing decisions logged since a time
+type RoutingStats struct {
+	Since          time.Time              `json:"since"`
+	Total          int                    `json:"total"`
+	Scenarios      []RoutingScenarioStats `json:"scenarios"`
+	Corrected      int                    `json:"corrected"` // Decisions a later split showed were wrong
+	CorrectionRate float64                `json:"correction_rate"`
+	NearMisses     int                    `json:"near_misses"` // Topic shifts where a block scored above zero but under the threshold
+	Threshold      float64                `json:"threshold"`   // Threshold of the latest decision
+}
+
+// RoutingScenarioStats summarizes the logged decisions of one scenario
+type RoutingScenarioStats struct {
+	Scenario  models.RoutingScenario `json:"scenario"`
+	Count     int                    `json:"count"`
+	Rate      float64                `json:"rate"`
+	Corrected int                    `json:"corrected"`
+	AvgScore  float64                `json:"avg_score"`
+}
+
+// LogRoutingDecision records how turnID was routed and the match threshold in force
+func (s *Storage) LogRoutingDecision(turnID string, decision models.RoutingDecision, threshold float64) error {
+	var scores interface{}
+	if len(decision.Scores) > 0 {
+		data, err := json.Marshal(decision.Scores)
+		if err != nil {
+			return fmt.Errorf("failed to marshal routing scores: %w", err)
+		}
+		scores = string(data)
+	}
+	if _, err := s.db.Exec(`
+		INSERT INTO routing_log (turn_id, scenario, matched_block_id, active_block_id, score, scores, threshold, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, utcArgs([]interface{}{turnID, string(decision.Scenario), nullString(decision.MatchedBlockID),
+		nullString(decision.ActiveBlockID), decision.Score, scores, threshold, s.db.now()})...); err != nil {
+		return fmt.Errorf("failed to log routing decision: %w", err)
+	}
+	return nil
+}
+
+// RoutingStats summarizes the routing decisions logged since since
+func (s *Storage) RoutingStats(since time.Time) (*RoutingStats, error) {
+	stats := &RoutingStats{Since: since, Scenarios: []RoutingScenarioStats{}}
+
+	rows, err := s.db.Query(`
+		SELECT scenario, COUNT(*), COUNT(corrected_at), AVG(score) FROM routing_log
+		WHERE created_at >= ?
+		GROUP BY scenario
+		ORDER BY COUNT(*) DESC, scenario
+	`, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize routing log: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var scenario RoutingScenarioStats
+		if err := rows.Scan(&scenario.Scenario, &scenario.Count, &scenario.Corrected, &scenario.AvgScore); err != nil {
+			return nil, fmt.Errorf("failed to scan routing stats: %w", err)
+		}
+		stats.Total += scenario.Count
+		stats.Corrected += scenario.Corrected
+		stats.Scenarios = append(stats.Scenarios, scenario)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	_ = rows.Close()
+	if stats.Total == 0 {
+		return stats, nil
+	}
+	for i := range stats.Scenarios {
+		stats.Scenarios[i].Rate = float64(stats.Scenarios[i].Count) / float64(stats.Total)
+	}
+	stats.CorrectionRate = float64(stats.Corrected) / float64(stats.Total)
+
+	if err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM routing_log
+		WHERE created_at >= ? AND scenario = ? AND score > 0
+	`, since.UTC(), string(models.TopicShift)).Scan(&stats.NearMisses); err != nil {
+		return nil, fmt.Errorf("failed to count near misses: %w", err)
+	}
+	if err := s.db.QueryRow(`
+		SELECT threshold FROM routing_log WHERE created_at >= ? ORDER BY created_at DESC, id DESC LIMIT 1
+	`, since.UTC()).Scan(&stats.Threshold); err != nil {
+		return nil, fmt.Errorf("failed to read routing threshold: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,78 @@
+// ABOUTME: Tests for the routing decision log
+// ABOUTME: Verifies logged decisions, split corrections, and the rates RoutingStats reports
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestRoutingStats(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	since := time.Now().Add(-time.Minute)
+	empty, err := store.RoutingStats(since)
+	if err != nil {
+		t.Fatalf("RoutingStats() error = %v", err)
+	}
+	if empty.Total != 0 || len(empty.Scenarios) != 0 {
+		t.Errorf("RoutingStats() with nothing logged = %+v, want zero", empty)
+	}
+
+	blockID := storeKeywordBlock(t, store, "turn_routing_1", "go")
+	if err := store.AppendTurnToBlock(blockID, &models.Turn{TurnID: "turn_routing_2", Timestamp: time.Now(), Keywords: []string{"soup"}}); err != nil {
+		t.Fatalf("AppendTurnToBlock() error = %v", err)
+	}
+	decisions := []struct {
+		turnID   string
+		decision models.RoutingDecision
+	}{
+		{"turn_routing_1", models.RoutingDecision{Scenario: models.NewTopicFirst}},
+		{"turn_routing_2", models.RoutingDecision{Scenario: models.TopicContinuation, MatchedBlockID: blockID, ActiveBlockID: blockID,
+			Score: 0.5, Scores: map[string]float64{blockID: 0.5}}},
+		{"turn_routing_3", models.RoutingDecision{Scenario: models.TopicShift, ActiveBlockID: blockID, Score: 0.2, Scores: map[string]float64{blockID: 0.2}}},
+		{"turn_routing_4", models.RoutingDecision{Scenario: models.TopicShift, ActiveBlockID: blockID}},
+	}
+	for _, d := range decisions {
+		if err := store.LogRoutingDecision(d.turnID, d.decision, 0.3); err != nil {
+			t.Fatalf("LogRoutingDecision() error = %v", err)
+		}
+	}
+
+	// Splitting at the continued turn corrects its routing
+	if _, err := store.SplitBridgeBlock(blockID, "turn_routing_2"); err != nil {
+		t.Fatalf("SplitBridgeBlock() error = %v", err)
+	}
+
+	stats, err := store.RoutingStats(since)
+	if err != nil {
+		t.Fatalf("RoutingStats() error = %v", err)
+	}
+	if stats.Total != 4 || stats.Corrected != 1 || stats.CorrectionRate != 0.25 || stats.NearMisses != 1 || stats.Threshold != 0.3 {
+		t.Errorf("RoutingStats() = %+v, want 4 decisions, 1 corrected, 1 near miss at 0.3", stats)
+	}
+	want := []RoutingScenarioStats{
+		{Scenario: models.TopicShift, Count: 2, Rate: 0.5, AvgScore: 0.1},
+		{Scenario: models.NewTopicFirst, Count: 1, Rate: 0.25},
+		{Scenario: models.TopicContinuation, Count: 1, Rate: 0.25, Corrected: 1, AvgScore: 0.5},
+	}
+	if len(stats.Scenarios) != len(want) {
+		t.Fatalf("Scenarios = %+v, want %+v", stats.Scenarios, want)
+	}
+	for i := range want {
+		if stats.Scenarios[i] != want[i] {
+			t.Errorf("Scenarios[%d] = %+v, want %+v", i, stats.Scenarios[i], want[i])
+		}
+	}
+
+	later, err := store.RoutingStats(time.Now().Add(time.Minute))
+	if err != nil || later.Total != 0 {
+		t.Errorf("RoutingStats(future) = %+v, %v; want nothing", later, err)
+	}
+}
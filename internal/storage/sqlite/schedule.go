@@ -0,0 +1,165 @@
+// ABOUTME: Storage for runs of scheduled tasks and the topic consolidation they perform
+// ABOUTME: Each task runs once per slot however many servers share the database
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// scheduledRunColumns selects a run for scanScheduledRun
+const scheduledRunColumns = `id, task, scheduled_for, status, summary, error, started_at, finished_at`
+
+// StartScheduledRun records that task is starting for the slot scheduledFor and returns
+// the run, or nil when another process already started that slot
+func (s *Storage) StartScheduledRun(task models.ScheduledTask, scheduledFor time.Time) (*models.ScheduledRun, error) {
+	now := s.db.now()
+	result, err := s.db.Exec(`
+		INSERT OR IGNORE INTO scheduled_runs (task, scheduled_for, status, started_at)
+		VALUES (?, ?, ?, ?)
+	`, utcArgs([]interface{}{string(task), scheduledFor, string(models.JobRunning), now})...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start scheduled run: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start scheduled run: %w", err)
+	}
+	return &models.ScheduledRun{
+		ID:           id,
+		Task:         task,
+		ScheduledFor: scheduledFor,
+		Status:       models.JobRunning,
+		StartedAt:    now,
+	}, nil
+}
+
+// FinishScheduledRun records how a run ended: done with summary, or failed with runErr
+func (s *Storage) FinishScheduledRun(run *models.ScheduledRun, summary string, runErr error) error {
+	now := s.db.now()
+	run.Status, run.Summary, run.FinishedAt = models.JobDone, summary, &now
+	if runErr != nil {
+		run.Status, run.Error = models.JobFailed, runErr.Error()
+	}
+	if _, err := s.db.Exec(`
+		UPDATE scheduled_runs SET status = ?, summary = ?, error = ?, finished_at = ? WHERE id = ?
+	`, utcArgs([]interface{}{string(run.Status), nullString(run.Summary), nullString(run.Error), now, run.ID})...); err != nil {
+		return fmt.Errorf("failed to finish scheduled run %d: %w", run.ID, err)
+	}
+	return nil
+}
+
+// ListScheduledRuns returns up to limit runs of task (every task when empty), newest first
+func (s *Storage) ListScheduledRuns(task models.ScheduledTask, limit int) ([]models.ScheduledRun, error) {
+	rows, err := s.db.Query(`
+		SELECT `+scheduledRunColumns+` FROM scheduled_runs
+		WHERE ? = '' OR task = ?
+		ORDER BY started_at DESC, id DESC
+		LIMIT ?
+	`, string(task), string(task), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled runs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var runs []models.ScheduledRun
+	for rows.Next() {
+		run, err := scanScheduledRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list scheduled runs: %w", err)
+	}
+	return runs, nil
+}
+
+// LastScheduledRun returns the most recent run of task that finished successfully, or
+// nil when it never has
+func (s *Storage) LastScheduledRun(task models.ScheduledTask) (*models.ScheduledRun, error) {
+	rows, err := s.db.Query(`
+		SELECT `+scheduledRunColumns+` FROM scheduled_runs
+		WHERE task = ? AND status = ?
+		ORDER BY started_at DESC, id DESC
+		LIMIT 1
+	`, string(task), string(models.JobDone))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the last scheduled run: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	return scanScheduledRun(rows)
+}
+
+// scanScheduledRun reads one row selected with scheduledRunColumns
+func scanScheduledRun(rows *Rows) (*models.ScheduledRun, error) {
+	var (
+		run             models.ScheduledRun
+		task, status    string
+		summary, errMsg sql.NullString
+		finishedAt      sql.NullTime
+	)
+	if err := rows.Scan(&run.ID, &task, &run.ScheduledFor, &status, &summary, &errMsg, &run.StartedAt, &finishedAt); err != nil {
+		return nil, fmt.Errorf("failed to scan scheduled run: %w", err)
+	}
+	run.Task, run.Status = models.ScheduledTask(task), models.JobStatus(status)
+	run.Summary, run.Error = summary.String, errMsg.String
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	return &run, nil
+}
+
+// ArchiveIdleBlocks archives the paused and closed blocks last updated before cutoff,
+// returning their IDs. Archived blocks stay searchable and become eligible for eviction
+// once the database passes max_db_size.
+func (s *Storage) ArchiveIdleBlocks(cutoff time.Time) (ids []string, err error) {
+	defer func() { // runs after the unlock below, so subscribers may use storage
+		if err == nil {
+			for _, id := range ids {
+				event := models.NewEvent(models.EventTopicArchived)
+				event.BlockID = id
+				s.publish(event)
+			}
+		}
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows, err := s.db.Query(`
+		SELECT id FROM bridge_blocks WHERE status IN (?, ?) AND updated_at < ? ORDER BY updated_at
+	`, string(models.StatusPaused), string(models.StatusClosed), cutoff.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find idle blocks: %w", err)
+	}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to scan idle block: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	err = rows.Err()
+	_ = rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find idle blocks: %w", err)
+	}
+	for _, id := range ids {
+		if err := s.blocks.UpdateStatus(id, models.StatusArchived); err != nil {
+			return nil, fmt.Errorf("failed to archive block %s: %w", id, err)
+		}
+	}
+	return ids, nil
+}
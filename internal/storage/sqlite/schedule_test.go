@@ -0,0 +1,114 @@
+// ABOUTME: Tests for scheduled run history and topic consolidation
+// ABOUTME: Verifies each slot runs once, runs are listed newest first, and only idle topics are archived
+
+package sqlite
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestScheduledRuns(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	slot := time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC)
+	run, err := store.StartScheduledRun(models.TaskPrune, slot)
+	if err != nil || run == nil {
+		t.Fatalf("StartScheduledRun() = %v, %v, want a run", run, err)
+	}
+	if again, err := store.StartScheduledRun(models.TaskPrune, slot); err != nil || again != nil {
+		t.Fatalf("StartScheduledRun() for a started slot = %v, %v, want nil", again, err)
+	}
+	if err := store.FinishScheduledRun(run, "evicted 2 archived blocks", nil); err != nil {
+		t.Fatalf("FinishScheduledRun() error = %v", err)
+	}
+
+	digest, err := store.StartScheduledRun(models.TaskDigest, slot)
+	if err != nil || digest == nil {
+		t.Fatalf("StartScheduledRun(digest) = %v, %v, want a run", digest, err)
+	}
+	if err := store.FinishScheduledRun(digest, "", errors.New("disk full")); err != nil {
+		t.Fatalf("FinishScheduledRun() error = %v", err)
+	}
+
+	runs, err := store.ListScheduledRuns("", 10)
+	if err != nil {
+		t.Fatalf("ListScheduledRuns() error = %v", err)
+	}
+	if len(runs) != 2 || runs[0].Task != models.TaskDigest || runs[1].Task != models.TaskPrune {
+		t.Fatalf("ListScheduledRuns() = %+v, want digest then prune", runs)
+	}
+	if runs[0].Status != models.JobFailed || runs[0].Error != "disk full" || runs[0].FinishedAt == nil {
+		t.Errorf("digest run = %+v, want failed with its error", runs[0])
+	}
+	if runs[1].Status != models.JobDone || runs[1].Summary != "evicted 2 archived blocks" || !runs[1].ScheduledFor.Equal(slot) {
+		t.Errorf("prune run = %+v, want done with its summary and slot", runs[1])
+	}
+
+	if runs, err := store.ListScheduledRuns(models.TaskPrune, 10); err != nil || len(runs) != 1 {
+		t.Errorf("ListScheduledRuns(prune) = %v, %v, want 1 run", runs, err)
+	}
+	if last, err := store.LastScheduledRun(models.TaskPrune); err != nil || last == nil || last.ID != run.ID {
+		t.Errorf("LastScheduledRun(prune) = %v, %v, want run %d", last, err, run.ID)
+	}
+	if last, err := store.LastScheduledRun(models.TaskDigest); err != nil || last != nil {
+		t.Errorf("LastScheduledRun(digest) = %v, %v, want nil for a task that only failed", last, err)
+	}
+}
+
+func TestArchiveIdleBlocks(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	for _, b := range []struct {
+		id      string
+		status  models.BridgeBlockStatus
+		updated time.Time
+	}{
+		{"block_paused_old", models.StatusPaused, old},
+		{"block_closed_old", models.StatusClosed, old},
+		{"block_active_old", models.StatusActive, old},
+		{"block_paused_recent", models.StatusPaused, time.Now()},
+	} {
+		if err := store.blocks.Save(&models.BridgeBlock{BlockID: b.id, DayID: "2026-08-01", TopicLabel: b.id, Status: b.status, CreatedAt: b.updated, UpdatedAt: b.updated}); err != nil {
+			t.Fatalf("Save block error = %v", err)
+		}
+	}
+
+	rec := &recorder{store: store}
+	store.SetEventPublisher(rec)
+
+	ids, err := store.ArchiveIdleBlocks(time.Now().Add(-30 * 24 * time.Hour))
+	if err != nil {
+		t.Fatalf("ArchiveIdleBlocks() error = %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("ArchiveIdleBlocks() = %v, want the old paused and closed blocks", ids)
+	}
+	for _, id := range []string{"block_paused_old", "block_closed_old"} {
+		block, err := store.GetBridgeBlock(id)
+		if err != nil || block.Status != models.StatusArchived {
+			t.Errorf("%s = %v, %v, want archived", id, block, err)
+		}
+	}
+	for _, id := range []string{"block_active_old", "block_paused_recent"} {
+		block, err := store.GetBridgeBlock(id)
+		if err != nil || block.Status == models.StatusArchived {
+			t.Errorf("%s = %v, %v, want it left alone", id, block, err)
+		}
+	}
+	if types := rec.types(); len(types) != 2 || types[0] != models.EventTopicArchived || types[1] != models.EventTopicArchived {
+		t.Errorf("events = %v, want a topic_archived event per block", types)
+	}
+}
@@ -58,6 +58,25 @@ CREATE TABLE IF NOT EXISTS embeddings (
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 );
 
+-- LLM usage table (token accounting per API call)
+CREATE TABLE IF NOT EXISTS llm_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    operation TEXT NOT NULL,
+    model TEXT,
+    prompt_tokens INTEGER DEFAULT 0,
+    completion_tokens INTEGER DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Feature usage table (opt-in telemetry: daily counts per command or tool, never content)
+CREATE TABLE IF NOT EXISTS feature_usage (
+    feature TEXT NOT NULL,
+    day TEXT NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    reported INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (feature, day)
+);
+
 -- Indexes for efficient querying
 CREATE INDEX IF NOT EXISTS idx_blocks_day ON bridge_blocks(day_id);
 CREATE INDEX IF NOT EXISTS idx_blocks_status ON bridge_blocks(status);
@@ -68,5 +87,350 @@ CREATE INDEX IF NOT EXISTS idx_embeddings_block ON embeddings(block_id);
 CREATE INDEX IF NOT EXISTS idx_embeddings_chunk ON embeddings(chunk_id);
 `
 
+// migrations upgrade databases created by older versions of Schema.
+// Each entry runs exactly once, in order; PRAGMA user_version records how many
+// have been applied. Append only - never edit or reorder existing entries.
+var migrations = []string{
+	// 1: track which model produced each embedding (for re-embedding)
+	`ALTER TABLE embeddings ADD COLUMN model TEXT;
+	CREATE INDEX IF NOT EXISTS idx_embeddings_turn ON embeddings(turn_id);`,
+	// 2: normalized tags on blocks and facts
+	`CREATE TABLE IF NOT EXISTS block_tags (
+		block_id TEXT NOT NULL REFERENCES bridge_blocks(id) ON DELETE CASCADE,
+		tag TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (block_id, tag)
+	);
+	CREATE TABLE IF NOT EXISTS fact_tags (
+		fact_id TEXT NOT NULL REFERENCES facts(id) ON DELETE CASCADE,
+		tag TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (fact_id, tag)
+	);
+	CREATE INDEX IF NOT EXISTS idx_block_tags_tag ON block_tags(tag);
+	CREATE INDEX IF NOT EXISTS idx_fact_tags_tag ON fact_tags(tag);`,
+	// 3: where each fact came from
+	`ALTER TABLE facts ADD COLUMN source_model TEXT;
+	ALTER TABLE facts ADD COLUMN prompt_version TEXT;
+	ALTER TABLE facts ADD COLUMN source_quote TEXT;
+	ALTER TABLE facts ADD COLUMN extracted_by TEXT;`,
+	// 4: how each fact's value should be interpreted
+	`ALTER TABLE facts ADD COLUMN value_type TEXT NOT NULL DEFAULT 'string';
+	CREATE INDEX IF NOT EXISTS idx_facts_type ON facts(value_type, key);`,
+	// 5: structured constraints (dietary, accessibility, ...) on the user profile
+	`ALTER TABLE user_profile ADD COLUMN constraints TEXT;`,
+	// 6: named personas layered over the shared profile
+	`CREATE TABLE IF NOT EXISTS profile_personas (
+		name TEXT PRIMARY KEY,
+		preferences TEXT,
+		constraints TEXT,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`,
+	// 7: links between blocks (continuation-of, spawned-from, references)
+	`CREATE TABLE IF NOT EXISTS block_relations (
+		from_block_id TEXT NOT NULL REFERENCES bridge_blocks(id) ON DELETE CASCADE,
+		to_block_id TEXT NOT NULL REFERENCES bridge_blocks(id) ON DELETE CASCADE,
+		relation TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (from_block_id, to_block_id, relation)
+	);
+	CREATE INDEX IF NOT EXISTS idx_block_relations_to ON block_relations(to_block_id);`,
+	// 8: emotional tone of each turn
+	`ALTER TABLE turns ADD COLUMN affect TEXT;
+	CREATE INDEX IF NOT EXISTS idx_turns_affect ON turns(affect);`,
+	// 9: files, URLs, and blobs attached to turns
+	`CREATE TABLE IF NOT EXISTS attachments (
+		id TEXT PRIMARY KEY,
+		turn_id TEXT NOT NULL REFERENCES turns(id) ON DELETE CASCADE,
+		kind TEXT NOT NULL,
+		name TEXT NOT NULL,
+		ref TEXT,
+		mime_type TEXT,
+		content TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_attachments_turn ON attachments(turn_id);`,
+	// 10: role-tagged transcripts (system, tool calls, ...) on turns
+	`ALTER TABLE turns ADD COLUMN messages TEXT;`,
+	// 11: device and workspace each turn came from
+	`ALTER TABLE turns ADD COLUMN device TEXT;
+	ALTER TABLE turns ADD COLUMN hostname TEXT;
+	ALTER TABLE turns ADD COLUMN working_dir TEXT;
+	ALTER TABLE turns ADD COLUMN git_repo TEXT;
+	ALTER TABLE turns ADD COLUMN location TEXT;
+	CREATE INDEX IF NOT EXISTS idx_turns_git_repo ON turns(git_repo);`,
+	// 12: block and profile cache hits, accumulated across processes
+	`CREATE TABLE IF NOT EXISTS cache_stats (
+		name TEXT PRIMARY KEY,
+		hits INTEGER NOT NULL DEFAULT 0,
+		misses INTEGER NOT NULL DEFAULT 0,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`,
+	// 13: background job queue for embeddings, fact extraction, and profile learning
+	`CREATE TABLE IF NOT EXISTS jobs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		max_attempts INTEGER NOT NULL DEFAULT 5,
+		last_error TEXT,
+		run_after DATETIME NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_jobs_status_run_after ON jobs(status, run_after);`,
+	// 14: turns whose LLM enrichment (metadata, embeddings, facts) waits on the provider
+	`ALTER TABLE turns ADD COLUMN pending_enrichment INTEGER NOT NULL DEFAULT 0;
+	CREATE INDEX IF NOT EXISTS idx_turns_pending_enrichment ON turns(pending_enrichment) WHERE pending_enrichment = 1;
+	CREATE INDEX IF NOT EXISTS idx_jobs_turn ON jobs(json_extract(payload, '$.turn_id'));`,
+	// 15: archived blocks deleted to keep the database under max_db_size
+	`CREATE TABLE IF NOT EXISTS evictions (
+		block_id TEXT PRIMARY KEY,
+		topic_label TEXT,
+		importance INTEGER NOT NULL,
+		bytes INTEGER NOT NULL,
+		evicted_at DATETIME NOT NULL
+	);`,
+	// 16: files ingested as notes, so unchanged files are skipped and changed ones replaced
+	`CREATE TABLE IF NOT EXISTS ingested_files (
+		path TEXT PRIMARY KEY,
+		sha256 TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		mod_time DATETIME NOT NULL,
+		block_id TEXT REFERENCES bridge_blocks(id) ON DELETE SET NULL,
+		ingested_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_ingested_files_block ON ingested_files(block_id);`,
+	// 17: runs of scheduled tasks; one row per task and slot, so only one server runs each
+	`CREATE TABLE IF NOT EXISTS scheduled_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task TEXT NOT NULL,
+		scheduled_for DATETIME NOT NULL,
+		status TEXT NOT NULL,
+		summary TEXT,
+		error TEXT,
+		started_at DATETIME NOT NULL,
+		finished_at DATETIME,
+		UNIQUE(task, scheduled_for)
+	);`,
+	// 18: MCP client or configured agent that wrote each turn and fact
+	`ALTER TABLE turns ADD COLUMN agent TEXT;
+	ALTER TABLE facts ADD COLUMN agent TEXT;
+	CREATE INDEX IF NOT EXISTS idx_turns_agent ON turns(agent);
+	CREATE INDEX IF NOT EXISTS idx_facts_agent ON facts(agent);`,
+	// 19: every saved version of the shared profile (persona '') and of each persona, so
+	// the profile can be read as it was at a past time; data is NULL for a deleted persona.
+	// History starts with the versions held at upgrade.
+	`CREATE TABLE IF NOT EXISTS profile_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		persona TEXT NOT NULL DEFAULT '',
+		data TEXT,
+		saved_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_profile_history_persona ON profile_history(persona, saved_at);
+	INSERT INTO profile_history (persona, data, saved_at)
+		SELECT '', json_object(
+			'name', name,
+			'preferences', CASE WHEN json_valid(preferences) THEN json(preferences) END,
+			'topics_of_interest', CASE WHEN json_valid(topics_of_interest) THEN json(topics_of_interest) END,
+			'constraints', CASE WHEN json_valid(constraints) THEN json(constraints) END), COALESCE(updated_at, CURRENT_TIMESTAMP)
+		FROM user_profile;
+	INSERT INTO profile_history (persona, data, saved_at)
+		SELECT name, json_object(
+			'preferences', CASE WHEN json_valid(preferences) THEN json(preferences) END,
+			'constraints', CASE WHEN json_valid(constraints) THEN json(constraints) END), COALESCE(updated_at, CURRENT_TIMESTAMP)
+		FROM profile_personas;`,
+	// 20: block keywords move from a JSON column into an indexed table, matched
+	// case-insensitively; position keeps the order they were first seen in
+	`CREATE TABLE IF NOT EXISTS block_keywords (
+		block_id TEXT NOT NULL REFERENCES bridge_blocks(id) ON DELETE CASCADE,
+		keyword TEXT NOT NULL COLLATE NOCASE,
+		position INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (block_id, keyword)
+	);
+	CREATE INDEX IF NOT EXISTS idx_block_keywords_keyword ON block_keywords(keyword);
+	INSERT OR IGNORE INTO block_keywords (block_id, keyword, position)
+		SELECT b.id, trim(k.value), k.key
+		FROM bridge_blocks b, json_each(CASE WHEN NOT json_valid(b.keywords) THEN '[]'
+			WHEN json_type(b.keywords) = 'array' THEN b.keywords ELSE '[]' END) k
+		WHERE k.type = 'text' AND trim(k.value) != '';
+	ALTER TABLE bridge_blocks DROP COLUMN keywords;`,
+	// 21: when each profile preference and topic (persona '' for the shared profile) was
+	// last reinforced, for reviewing stale ones; items held at upgrade date from their
+	// profile's last save
+	`CREATE TABLE IF NOT EXISTS profile_items (
+		persona TEXT NOT NULL DEFAULT '',
+		kind TEXT NOT NULL,
+		text TEXT NOT NULL,
+		reinforced_at DATETIME NOT NULL,
+		PRIMARY KEY (persona, kind, text)
+	);
+	CREATE INDEX IF NOT EXISTS idx_profile_items_reinforced ON profile_items(reinforced_at);
+	INSERT OR IGNORE INTO profile_items (persona, kind, text, reinforced_at)
+		SELECT '', 'preference', i.value, COALESCE(u.updated_at, CURRENT_TIMESTAMP)
+		FROM user_profile u, json_each(CASE WHEN NOT json_valid(u.preferences) THEN '[]'
+			WHEN json_type(u.preferences) = 'array' THEN u.preferences ELSE '[]' END) i
+		WHERE i.type = 'text';
+	INSERT OR IGNORE INTO profile_items (persona, kind, text, reinforced_at)
+		SELECT '', 'topic', i.value, COALESCE(u.updated_at, CURRENT_TIMESTAMP)
+		FROM user_profile u, json_each(CASE WHEN NOT json_valid(u.topics_of_interest) THEN '[]'
+			WHEN json_type(u.topics_of_interest) = 'array' THEN u.topics_of_interest ELSE '[]' END) i
+		WHERE i.type = 'text';
+	INSERT OR IGNORE INTO profile_items (persona, kind, text, reinforced_at)
+		SELECT p.name, 'preference', i.value, COALESCE(p.updated_at, CURRENT_TIMESTAMP)
+		FROM profile_personas p, json_each(CASE WHEN NOT json_valid(p.preferences) THEN '[]'
+			WHEN json_type(p.preferences) = 'array' THEN p.preferences ELSE '[]' END) i
+		WHERE i.type = 'text';`,
+	// 22: blocks and facts kept but never surfaced by searches or hydrated context
+	`ALTER TABLE bridge_blocks ADD COLUMN exclude_from_recall INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE facts ADD COLUMN exclude_from_recall INTEGER NOT NULL DEFAULT 0;`,
+	// 23: every Governor routing decision, with the keyword scores behind it, for tuning
+	// the match threshold; corrected_at is set when a split shows the turn was misrouted
+	`CREATE TABLE IF NOT EXISTS routing_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		turn_id TEXT NOT NULL,
+		scenario TEXT NOT NULL,
+		matched_block_id TEXT,
+		active_block_id TEXT,
+		score REAL NOT NULL DEFAULT 0,
+		scores TEXT,
+		threshold REAL NOT NULL,
+		created_at DATETIME NOT NULL,
+		corrected_at DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_routing_log_created ON routing_log(created_at);
+	CREATE INDEX IF NOT EXISTS idx_routing_log_turn ON routing_log(turn_id);`,
+	// 24: notes attached to a turn (a JSON array), and when its text was last corrected
+	`ALTER TABLE turns ADD COLUMN annotations TEXT;
+	ALTER TABLE turns ADD COLUMN edited_at DATETIME;`,
+	// 25: how each embedding's vector is encoded (NULL for float64), and database-wide
+	// settings such as the encoding new vectors get
+	`ALTER TABLE embeddings ADD COLUMN format TEXT;
+	CREATE TABLE IF NOT EXISTS settings (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);`,
+	// 26: facts extracted while fact_review is on wait in a review queue until approved
+	`ALTER TABLE facts ADD COLUMN pending INTEGER NOT NULL DEFAULT 0;
+	CREATE INDEX IF NOT EXISTS idx_facts_pending ON facts(created_at) WHERE pending;`,
+	// 27: retention policies overriding the global pruning policy, on a block (NULL
+	// for none) or on every block carrying a tag
+	`ALTER TABLE bridge_blocks ADD COLUMN retention TEXT;
+	CREATE TABLE IF NOT EXISTS tag_retention (
+		tag TEXT PRIMARY KEY,
+		retention TEXT NOT NULL
+	);`,
+	// 28: how often and how recently each block was retrieved or hydrated; kept apart
+	// from bridge_blocks so recording a read does not invalidate cached block reads
+	`CREATE TABLE IF NOT EXISTS block_access (
+		block_id TEXT PRIMARY KEY REFERENCES bridge_blocks(id) ON DELETE CASCADE,
+		access_count INTEGER NOT NULL DEFAULT 0,
+		last_accessed_at DATETIME NOT NULL
+	);`,
+	// 29: thumbs up or down on a block or fact retrieved for a query, from rate_memory
+	`CREATE TABLE IF NOT EXISTS memory_feedback (
+		id TEXT PRIMARY KEY,
+		query TEXT NOT NULL DEFAULT '',
+		block_id TEXT REFERENCES bridge_blocks(id) ON DELETE CASCADE,
+		fact_id TEXT REFERENCES facts(id) ON DELETE CASCADE,
+		rating INTEGER NOT NULL,
+		note TEXT,
+		agent TEXT,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_memory_feedback_block ON memory_feedback(block_id);
+	CREATE INDEX IF NOT EXISTS idx_memory_feedback_fact ON memory_feedback(fact_id);`,
+	// 30: the text of each embedded chunk, so semantic hits can show the sentence that matched
+	`CREATE TABLE IF NOT EXISTS chunks (
+		id TEXT PRIMARY KEY,
+		turn_id TEXT NOT NULL,
+		block_id TEXT REFERENCES bridge_blocks(id) ON DELETE CASCADE,
+		chunk_type TEXT NOT NULL,
+		parent_id TEXT,
+		content TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_chunks_turn ON chunks(turn_id);
+	CREATE INDEX IF NOT EXISTS idx_chunks_block ON chunks(block_id);`,
+	// 31: archived blocks whose turn bodies were replaced by their summary and facts
+	`CREATE TABLE IF NOT EXISTS compactions (
+		block_id TEXT PRIMARY KEY REFERENCES bridge_blocks(id) ON DELETE CASCADE,
+		turns INTEGER NOT NULL,
+		bytes INTEGER NOT NULL,
+		policy TEXT NOT NULL,
+		original BLOB,
+		compacted_at DATETIME NOT NULL
+	);`,
+	// 32: the turns the Scribe learned each profile version from (a JSON array), so
+	// profile diffs can show what triggered a change; NULL for direct edits
+	`ALTER TABLE profile_history ADD COLUMN turn_ids TEXT;`,
+	// 33: rows removed or rewritten by destructive operations (a gzipped JSON copy), so
+	// the most recent ones can be undone
+	`CREATE TABLE IF NOT EXISTS undo_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		operation TEXT NOT NULL,
+		target TEXT NOT NULL,
+		summary TEXT NOT NULL,
+		rows INTEGER NOT NULL,
+		data BLOB NOT NULL,
+		created_at DATETIME NOT NULL
+	);`,
+	// 34: each block's centroid, the running mean of its embeddings (float64 blob), so
+	// turns can be compared with whole topics without averaging their chunks on each read
+	`ALTER TABLE bridge_blocks ADD COLUMN centroid BLOB;
+	ALTER TABLE bridge_blocks ADD COLUMN centroid_count INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE bridge_blocks ADD COLUMN centroid_model TEXT;`,
+	// 35: keywords are matched by a copy folded the way search terms are (every
+	// letter lowercased, where NOCASE only folds ASCII), which also keys out repeats
+	`CREATE TABLE block_keywords_folded (
+		block_id TEXT NOT NULL REFERENCES bridge_blocks(id) ON DELETE CASCADE,
+		keyword TEXT NOT NULL,
+		folded TEXT NOT NULL,
+		position INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (block_id, folded)
+	);
+	INSERT OR IGNORE INTO block_keywords_folded (block_id, keyword, folded, position)
+		SELECT block_id, keyword, fold_case(keyword), position FROM block_keywords ORDER BY block_id, position;
+	DROP TABLE block_keywords;
+	ALTER TABLE block_keywords_folded RENAME TO block_keywords;
+	CREATE INDEX IF NOT EXISTS idx_block_keywords_folded ON block_keywords(folded);`,
+	// 36: timestamps written before they were stored in UTC keep the offset they were
+	// written with, which breaks sorting and range comparisons as text; rewrite them in UTC
+	`UPDATE attachments SET created_at = utc_time(created_at) WHERE created_at IS NOT utc_time(created_at);
+	UPDATE block_access SET last_accessed_at = utc_time(last_accessed_at) WHERE last_accessed_at IS NOT utc_time(last_accessed_at);
+	UPDATE block_relations SET created_at = utc_time(created_at) WHERE created_at IS NOT utc_time(created_at);
+	UPDATE block_tags SET created_at = utc_time(created_at) WHERE created_at IS NOT utc_time(created_at);
+	UPDATE bridge_blocks SET created_at = utc_time(created_at) WHERE created_at IS NOT utc_time(created_at);
+	UPDATE bridge_blocks SET updated_at = utc_time(updated_at) WHERE updated_at IS NOT utc_time(updated_at);
+	UPDATE cache_stats SET updated_at = utc_time(updated_at) WHERE updated_at IS NOT utc_time(updated_at);
+	UPDATE chunks SET created_at = utc_time(created_at) WHERE created_at IS NOT utc_time(created_at);
+	UPDATE compactions SET compacted_at = utc_time(compacted_at) WHERE compacted_at IS NOT utc_time(compacted_at);
+	UPDATE embeddings SET created_at = utc_time(created_at) WHERE created_at IS NOT utc_time(created_at);
+	UPDATE evictions SET evicted_at = utc_time(evicted_at) WHERE evicted_at IS NOT utc_time(evicted_at);
+	UPDATE fact_tags SET created_at = utc_time(created_at) WHERE created_at IS NOT utc_time(created_at);
+	UPDATE facts SET created_at = utc_time(created_at) WHERE created_at IS NOT utc_time(created_at);
+	UPDATE ingested_files SET mod_time = utc_time(mod_time) WHERE mod_time IS NOT utc_time(mod_time);
+	UPDATE ingested_files SET ingested_at = utc_time(ingested_at) WHERE ingested_at IS NOT utc_time(ingested_at);
+	UPDATE jobs SET run_after = utc_time(run_after) WHERE run_after IS NOT utc_time(run_after);
+	UPDATE jobs SET created_at = utc_time(created_at) WHERE created_at IS NOT utc_time(created_at);
+	UPDATE jobs SET updated_at = utc_time(updated_at) WHERE updated_at IS NOT utc_time(updated_at);
+	UPDATE llm_usage SET created_at = utc_time(created_at) WHERE created_at IS NOT utc_time(created_at);
+	UPDATE memory_feedback SET created_at = utc_time(created_at) WHERE created_at IS NOT utc_time(created_at);
+	UPDATE profile_history SET saved_at = utc_time(saved_at) WHERE saved_at IS NOT utc_time(saved_at);
+	UPDATE profile_items SET reinforced_at = utc_time(reinforced_at) WHERE reinforced_at IS NOT utc_time(reinforced_at);
+	UPDATE profile_personas SET updated_at = utc_time(updated_at) WHERE updated_at IS NOT utc_time(updated_at);
+	UPDATE routing_log SET created_at = utc_time(created_at) WHERE created_at IS NOT utc_time(created_at);
+	UPDATE routing_log SET corrected_at = utc_time(corrected_at) WHERE corrected_at IS NOT utc_time(corrected_at);
+	UPDATE scheduled_runs SET scheduled_for = utc_time(scheduled_for) WHERE scheduled_for IS NOT utc_time(scheduled_for);
+	UPDATE scheduled_runs SET started_at = utc_time(started_at) WHERE started_at IS NOT utc_time(started_at);
+	UPDATE scheduled_runs SET finished_at = utc_time(finished_at) WHERE finished_at IS NOT utc_time(finished_at);
+	UPDATE turns SET created_at = utc_time(created_at) WHERE created_at IS NOT utc_time(created_at);
+	UPDATE turns SET edited_at = utc_time(edited_at) WHERE edited_at IS NOT utc_time(edited_at);
+	UPDATE undo_log SET created_at = utc_time(created_at) WHERE created_at IS NOT utc_time(created_at);
+	UPDATE user_profile SET updated_at = utc_time(updated_at) WHERE updated_at IS NOT utc_time(updated_at);`,
+}
+
 // SchemaVersion is the current schema version for migrations
-const SchemaVersion = 1
+// (the base Schema plus every entry in migrations)
+const SchemaVersion = 37
@@ -0,0 +1,66 @@
+// ABOUTME: Caches memory search results by a hash of the query and its options
+// ABOUTME: Also keeps query embeddings, so a repeated query skips the embedding call even after writes
+package sqlite
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// searchTables are the tables a memory search reads. A write to any of them can change
+// which blocks match, not only the blocks a cached search returned (a new turn can give
+// an unrelated block the query's keywords), so each invalidates every cached search.
+var searchTables = []string{"bridge_blocks", "block_keywords", "turns", "embeddings", "chunks", "block_tags", "attachments"}
+
+// searchCache holds search results and the query embeddings behind them
+type searchCache struct {
+	results *lruCache[[]models.MemorySearchResult] // Ranked results by searchKey
+	vectors *lruCache[[]float64]                   // Query embeddings by model and query text
+}
+
+// newSearchCache creates the search caches for db
+func newSearchCache(db *DB) *searchCache {
+	return &searchCache{
+		results: newLRUCache("searches", db, searchCacheSize, cloneSearchResults, searchTables...),
+		// Embeddings depend only on the model and the text, so no write invalidates them
+		vectors: newLRUCache[[]float64]("query_vectors", nil, queryVectorCacheSize, cloneVector),
+	}
+}
+
+// embeddingModel returns the model the embedding client reports, or "" without one
+func (s *Storage) embeddingModel() string {
+	if m, ok := s.openaiClient.(interface{ EmbeddingModel() string }); ok {
+		return m.EmbeddingModel()
+	}
+	return ""
+}
+
+// searchKey hashes everything that decides a search's results: the query, how many
+// results, the filters, and whether (and with which model) it searches semantically
+func (s *Storage) searchKey(query string, maxResults int, opts SearchOptions) string {
+	tags := cloneStrings(opts.Tags)
+	sort.Strings(tags)
+	key, _ := json.Marshal(struct {
+		Query    string
+		Max      int
+		Tags     []string
+		Affect   models.Affect
+		Origin   models.Origin
+		Semantic bool
+		Model    string
+	}{query, maxResults, tags, opts.Affect, opts.Origin, s.openaiClient != nil, s.embeddingModel()})
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:])
+}
+
+// queryEmbedding embeds a search query, reusing the embedding of an identical earlier query
+func (s *Storage) queryEmbedding(query string) ([]float64, error) {
+	return s.search.vectors.load(s.embeddingModel()+"\x00"+query, func() ([]float64, bool, error) {
+		vector, err := s.openaiClient.GenerateEmbedding(query)
+		return vector, err == nil && len(vector) > 0, err
+	})
+}
@@ -0,0 +1,112 @@
+// ABOUTME: Tests for the search result and query embedding caches
+// ABOUTME: Verifies repeated searches skip the embedder, writes invalidate results, and failures are not cached
+package sqlite
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// countingEmbedder counts embedding calls, failing them while fail is set
+type countingEmbedder struct {
+	calls int
+	fail  bool
+}
+
+func (e *countingEmbedder) GenerateEmbedding(text string) ([]float64, error) {
+	e.calls++
+	if e.fail {
+		return nil, errors.New("embedding service unavailable")
+	}
+	vector := make([]float64, ExpectedDimension)
+	vector[0] = 1
+	return vector, nil
+}
+
+func TestSearchCache_RepeatedSearchSkipsEmbedding(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	embedder := &countingEmbedder{}
+	store.SetOpenAIClient(embedder)
+
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_garden", UserMessage: "tomatoes", Keywords: []string{"gardening"}}); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	first, err := store.SearchMemory("gardening", 5)
+	if err != nil || len(first) != 1 {
+		t.Fatalf("SearchMemory() = %+v, %v; want one block", first, err)
+	}
+	label := first[0].TopicLabel
+	first[0].TopicLabel = "changed by caller"
+
+	before := cacheStat(t, store, "searches")
+	again, err := store.SearchMemory("gardening", 5)
+	if err != nil || len(again) != 1 {
+		t.Fatalf("SearchMemory() again = %+v, %v; want one block", again, err)
+	}
+	if hits := cacheStat(t, store, "searches").Hits - before.Hits; hits != 1 {
+		t.Errorf("search hits = %d, want 1", hits)
+	}
+	if embedder.calls != 1 {
+		t.Errorf("embedder called %d times, want once", embedder.calls)
+	}
+	if again[0].TopicLabel != label {
+		t.Errorf("cached result changed by caller: %q", again[0].TopicLabel)
+	}
+
+	// Other options are searched separately
+	if _, err := store.SearchMemoryWithTags("gardening", 5, []string{"plants"}); err != nil {
+		t.Fatalf("SearchMemoryWithTags() error = %v", err)
+	}
+	if hits := cacheStat(t, store, "searches").Hits - before.Hits; hits != 1 {
+		t.Errorf("search hits = %d after a tag-filtered search, want still 1", hits)
+	}
+
+	// A write can make a new block match: results are searched again, reusing the query's embedding
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_garden_2", UserMessage: "basil", Keywords: []string{"gardening"}, Topics: []string{"herbs"}}); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	after, err := store.SearchMemory("gardening", 5)
+	if err != nil {
+		t.Fatalf("SearchMemory() after write error = %v", err)
+	}
+	if len(after) != 2 {
+		t.Errorf("SearchMemory() after write found %d blocks, want 2", len(after))
+	}
+	if embedder.calls != 1 {
+		t.Errorf("embedder called %d times, want the cached query embedding reused", embedder.calls)
+	}
+}
+
+func TestSearchCache_SkipsFailedSemanticSearch(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	embedder := &countingEmbedder{fail: true}
+	store.SetOpenAIClient(embedder)
+
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_garden", UserMessage: "tomatoes", Keywords: []string{"gardening"}}); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		results, err := store.SearchMemory("gardening", 5)
+		if err != nil || len(results) != 1 {
+			t.Fatalf("SearchMemory() = %+v, %v; want the keyword match", results, err)
+		}
+	}
+	if embedder.calls != 2 {
+		t.Errorf("embedder called %d times, want each search to retry it", embedder.calls)
+	}
+	if c := cacheStat(t, store, "searches"); c.Hits != 0 || c.Size != 0 {
+		t.Errorf("searches cache = %+v, want keyword-only results left uncached", c)
+	}
+}
@@ -0,0 +1,203 @@
+// ABOUTME: Encryption at rest for facts with value_type secret
+// ABOUTME: Values are sealed with AES-256-GCM under a key file kept next to the database
+package sqlite
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/harper/remember-standalone/internal/redact"
+)
+
+const (
+	// secretPrefix marks a stored value as sealed; the rest is base64(nonce || ciphertext)
+	secretPrefix = "enc:v1:"
+	// SecretMask replaces secret values in exports
+	SecretMask = redact.Mask
+	// unreadableSecret is returned for a sealed value the key cannot open
+	unreadableSecret = "[encrypted: key unavailable]"
+)
+
+// errNoSecretKey means the key file does not exist and may not be created
+var errNoSecretKey = errors.New("no secret key")
+
+// secretKey loads the database's secret key on first use
+type secretKey struct {
+	mu   sync.Mutex
+	aead cipher.AEAD
+}
+
+// SecretKeyPath returns the key file for a database path; in-memory databases have none
+func SecretKeyPath(dbPath string) string {
+	if dbPath == ":memory:" || dbPath == "" {
+		return ""
+	}
+	return dbPath + ".key"
+}
+
+// cipher returns the AEAD for db, creating the key file when create is set.
+// An in-memory database gets a random key that lives as long as it does.
+func (k *secretKey) cipher(db *DB, create bool) (cipher.AEAD, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.aead != nil {
+		return k.aead, nil
+	}
+
+	var key []byte
+	path := SecretKeyPath(db.path)
+	if path == "" {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate secret key: %w", err)
+		}
+	} else {
+		var err error
+		if key, err = readSecretKey(path); errors.Is(err, os.ErrNotExist) {
+			if !create || db.readOnly {
+				return nil, errNoSecretKey
+			}
+			key, err = createSecretKey(path)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secret key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secret key: %w", err)
+	}
+	k.aead = aead
+	return aead, nil
+}
+
+// readSecretKey reads a base64-encoded 32-byte key
+func readSecretKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is derived from the database path
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("secret key %s is not a base64-encoded 32-byte key", path)
+	}
+	return key, nil
+}
+
+// createSecretKey writes a new random key readable only by the owner.
+// O_EXCL makes a concurrent creator lose the race and read the winner's key instead.
+func createSecretKey(path string) ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate secret key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create secret key directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600) // #nosec G304
+	if errors.Is(err, os.ErrExist) {
+		return readSecretKey(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secret key: %w", err)
+	}
+	if _, err := file.WriteString(base64.StdEncoding.EncodeToString(key) + "\n"); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to write secret key: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write secret key: %w", err)
+	}
+	return key, nil
+}
+
+// sealSecret encrypts a secret value, binding it to the fact ID so a sealed
+// value copied onto another fact will not open
+func (db *DB) sealSecret(factID, value string) (string, error) {
+	aead, err := db.secrets.cipher(db, true)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(factID))
+	return secretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openSecret decrypts a stored secret value. Values stored before encryption
+// existed are returned as they are.
+func (db *DB) openSecret(factID, stored string) (string, error) {
+	encoded, ok := strings.CutPrefix(stored, secretPrefix)
+	if !ok {
+		return stored, nil
+	}
+	aead, err := db.secrets.cipher(db, false)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("secret value of fact %s is corrupt", factID)
+	}
+	value, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(factID))
+	if err != nil {
+		return "", fmt.Errorf("secret value of fact %s does not open with %s", factID, SecretKeyPath(db.path))
+	}
+	return string(value), nil
+}
+
+// sealPlaintextSecrets encrypts secret values stored before encryption existed
+func (s *FactStore) sealPlaintextSecrets() (int, error) {
+	rows, err := s.db.Query(`
+		SELECT id, value FROM facts
+		WHERE value_type = 'secret' AND substr(value, 1, ?) != ?
+	`, len(secretPrefix), secretPrefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find plaintext secrets: %w", err)
+	}
+	plain := map[string]string{}
+	for rows.Next() {
+		var id, value string
+		if err := rows.Scan(&id, &value); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("failed to read plaintext secret: %w", err)
+		}
+		plain[id] = value
+	}
+	if err := rows.Close(); err != nil {
+		return 0, fmt.Errorf("failed to read plaintext secrets: %w", err)
+	}
+
+	for id, value := range plain {
+		sealed, err := s.db.sealSecret(id, value)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := s.db.Exec("UPDATE facts SET value = ? WHERE id = ?", sealed, id); err != nil {
+			return 0, fmt.Errorf("failed to encrypt secret: %w", err)
+		}
+	}
+	return len(plain), nil
+}
+
+// forget drops the loaded key, so the next secret saved creates a new one
+func (k *secretKey) forget() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.aead = nil
+}
@@ -0,0 +1,132 @@
+// ABOUTME: Tests for encryption of secret fact values at rest
+// ABOUTME: Verifies sealed rows, transparent reads, the key file, legacy plaintext, and export masking
+
+package sqlite
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/redact"
+)
+
+func TestSecretFacts(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+	store, err := NewStorageWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewStorageWithPath() error = %v", err)
+	}
+
+	if _, err := os.Stat(SecretKeyPath(dbPath)); !os.IsNotExist(err) {
+		t.Fatalf("key file exists before any secret was saved: %v", err)
+	}
+
+	if err := store.SaveFact(&models.Fact{FactID: "fact_secret", Key: "stripe_api_key", Value: "sk_live_123", ValueType: models.ValueTypeSecret, Confidence: 1}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_plain", Key: "user_name", Value: "Harper", Confidence: 1}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+
+	info, err := os.Stat(SecretKeyPath(dbPath))
+	if err != nil {
+		t.Fatalf("key file missing after saving a secret: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("key file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	var stored string
+	if err := store.db.QueryRow("SELECT value FROM facts WHERE id = 'fact_secret'").Scan(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stored, secretPrefix) || strings.Contains(stored, "sk_live_123") {
+		t.Errorf("stored value = %q, want it sealed", stored)
+	}
+
+	if fact, err := store.GetFactByKey("stripe_api_key"); err != nil || fact == nil || fact.Value != "sk_live_123" {
+		t.Errorf("GetFactByKey() = %+v, %v, want the decrypted value", fact, err)
+	}
+	if got := redact.String("key is sk_live_123"); got != "key is "+redact.Mask {
+		t.Errorf("redact.String() = %q, want the secret value masked in logs", got)
+	}
+
+	data, err := store.Export()
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	values := map[string]string{}
+	for _, fact := range data.Facts {
+		values[fact.Key] = fact.Value
+	}
+	if values["stripe_api_key"] != SecretMask || values["user_name"] != "Harper" {
+		t.Errorf("exported values = %v, want only the secret masked", values)
+	}
+	if data, err := store.ExportWithOptions(ExportOptions{RevealSecrets: true}); err != nil || !strings.Contains(factValues(data), "sk_live_123") {
+		t.Errorf("ExportWithOptions(RevealSecrets) = %v, want the decrypted secret", err)
+	}
+
+	// A secret stored in the clear before encryption existed is sealed on the next open
+	if _, err := store.db.Exec("UPDATE facts SET value = 'legacy' WHERE id = 'fact_secret'"); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+	if store, err = NewStorageWithPath(dbPath); err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	if err := store.db.QueryRow("SELECT value FROM facts WHERE id = 'fact_secret'").Scan(&stored); err != nil || !strings.HasPrefix(stored, secretPrefix) {
+		t.Errorf("legacy secret stored as %q, %v; want it sealed", stored, err)
+	}
+	if fact, err := store.GetFactByKey("stripe_api_key"); err != nil || fact == nil || fact.Value != "legacy" {
+		t.Errorf("GetFactByKey() after reopen = %+v, %v, want the legacy value", fact, err)
+	}
+	_ = store.Close()
+
+	// Without the right key the secret reads as a placeholder, and other facts still load
+	if err := os.Remove(SecretKeyPath(dbPath)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := createSecretKey(SecretKeyPath(dbPath)); err != nil {
+		t.Fatal(err)
+	}
+	if store, err = NewStorageWithPath(dbPath); err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	facts, err := store.SearchFacts("", 10)
+	if err != nil || len(facts) != 2 {
+		t.Fatalf("SearchFacts() = %d facts, %v, want both", len(facts), err)
+	}
+	if fact, _ := store.GetFactByKey("stripe_api_key"); fact == nil || fact.Value != unreadableSecret {
+		t.Errorf("GetFactByKey() with the wrong key = %+v, want the placeholder", fact)
+	}
+}
+
+func TestSecretKey_Corrupt(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+	if err := os.WriteFile(SecretKeyPath(dbPath), []byte("not a key\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewStorageWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewStorageWithPath() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	err = store.SaveFact(&models.Fact{FactID: "fact_secret", Key: "token", Value: "abc", ValueType: models.ValueTypeSecret, Confidence: 1})
+	if err == nil || !strings.Contains(err.Error(), "not a base64-encoded 32-byte key") {
+		t.Errorf("SaveFact() with a corrupt key = %v, want an error", err)
+	}
+}
+
+// factValues joins the exported fact values for matching
+func factValues(data *ExportData) string {
+	var values []string
+	for _, fact := range data.Facts {
+		values = append(values, fact.Value)
+	}
+	return strings.Join(values, ",")
+}
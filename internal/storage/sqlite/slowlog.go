@@ -0,0 +1,76 @@
+// ABOUTME: Logs storage queries that take longer than a configurable threshold
+// ABOUTME: Times each statement through its last row and logs its SQL, never its arguments
+package sqlite
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// maxLoggedSQL caps how much of a slow statement is logged
+const maxLoggedSQL = 300
+
+// slowLog reports statements that run for at least its threshold. Arguments are left
+// out because they hold memory content; the SQL alone shows which query is slow.
+type slowLog struct {
+	threshold atomic.Int64                     // Nanoseconds; 0 disables logging
+	logf      func(format string, args ...any) // log.Printf unless replaced in tests
+}
+
+// SetSlowQueryThreshold logs every statement that takes at least d, including the
+// time spent reading its rows. Zero turns logging off.
+func (db *DB) SetSlowQueryThreshold(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	db.slow.threshold.Store(int64(d))
+}
+
+// observe logs query if it has been running since start for at least the threshold
+func (db *DB) observe(query string, start time.Time) {
+	threshold := time.Duration(db.slow.threshold.Load())
+	if threshold <= 0 {
+		return
+	}
+	elapsed := time.Since(start)
+	if elapsed < threshold {
+		return
+	}
+	logf := db.slow.logf
+	if logf == nil {
+		logf = log.Printf
+	}
+	logf("[Storage] slow query (%s): %s", elapsed.Round(time.Microsecond), compactSQL(query))
+}
+
+// compactSQL folds a statement onto one line and truncates it for logging
+func compactSQL(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > maxLoggedSQL {
+		query = query[:maxLoggedSQL] + "..."
+	}
+	return query
+}
+
+// Rows is a query's result set. Closing it logs the query if it was slow, so the
+// time includes stepping through every row, where full table scans spend it.
+type Rows struct {
+	*sql.Rows
+	db     *DB
+	query  string
+	start  time.Time
+	closed bool
+}
+
+// Close closes the rows and logs the query if it took at least the slow query threshold
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	if !r.closed {
+		r.closed = true
+		r.db.observe(r.query, r.start)
+	}
+	return err
+}
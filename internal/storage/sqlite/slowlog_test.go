@@ -0,0 +1,105 @@
+// ABOUTME: Tests for slow query logging
+// ABOUTME: Verifies statements over the threshold are logged with their SQL and without arguments
+
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlowQueryLog(t *testing.T) {
+	db, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("OpenInMemory() error = %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	var logged []string
+	db.slow.logf = func(format string, args ...any) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}
+
+	// Off by default
+	if _, err := db.Exec("INSERT INTO facts (id, key, value) VALUES (?, ?, ?)", "fact_1", "secret_key", "secret value"); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if len(logged) != 0 {
+		t.Fatalf("logged %q with no threshold, want nothing", logged)
+	}
+
+	// Every statement takes at least a nanosecond
+	db.SetSlowQueryThreshold(time.Nanosecond)
+	if _, err := db.Exec("INSERT INTO facts (id, key, value) VALUES (?, ?, ?)", "fact_2", "secret_key", "secret value"); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM facts").Scan(&n); err != nil {
+		t.Fatalf("QueryRow() error = %v", err)
+	}
+	rows, err := db.Query(`
+		SELECT key, value
+		FROM facts
+		WHERE value LIKE ?`, "%secret%")
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	for rows.Next() {
+	}
+	if len(logged) != 2 {
+		t.Errorf("logged %d queries before the rows were closed, want 2", len(logged))
+	}
+	_ = rows.Close()
+	_ = rows.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if _, err := tx.Exec("DELETE FROM facts"); err != nil {
+		t.Fatalf("tx.Exec() error = %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	want := []string{
+		"INSERT INTO facts (id, key, value) VALUES (?, ?, ?)",
+		"SELECT COUNT(*) FROM facts",
+		"SELECT key, value FROM facts WHERE value LIKE ?",
+		"DELETE FROM facts",
+	}
+	if len(logged) != len(want) {
+		t.Fatalf("logged %q, want %d slow queries", logged, len(want))
+	}
+	for i, line := range logged {
+		if !strings.HasPrefix(line, "[Storage] slow query (") || !strings.HasSuffix(line, "): "+want[i]) {
+			t.Errorf("log line %d = %q, want the compacted SQL %q", i, line, want[i])
+		}
+		if strings.Contains(line, "secret") {
+			t.Errorf("log line %d = %q includes query arguments", i, line)
+		}
+	}
+
+	// A threshold no query reaches logs nothing
+	logged = nil
+	db.SetSlowQueryThreshold(time.Hour)
+	if err := db.QueryRow("SELECT COUNT(*) FROM facts").Scan(&n); err != nil {
+		t.Fatalf("QueryRow() error = %v", err)
+	}
+	if len(logged) != 0 {
+		t.Errorf("logged %q under the threshold, want nothing", logged)
+	}
+}
+
+func TestCompactSQL(t *testing.T) {
+	long := "SELECT " + strings.Repeat("a, ", 200) + "b FROM t"
+	if got := compactSQL(long); len(got) != maxLoggedSQL+3 || !strings.HasSuffix(got, "...") {
+		t.Errorf("compactSQL(long) = %d bytes, want it truncated to %d", len(got), maxLoggedSQL)
+	}
+	if got := compactSQL("\n\tSELECT 1\n\t  FROM t\n"); got != "SELECT 1 FROM t" {
+		t.Errorf("compactSQL() = %q", got)
+	}
+}
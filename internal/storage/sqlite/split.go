@@ -0,0 +1,171 @@
+// ABOUTME: Bridge Block split operation for SQLite
+// ABOUTME: Moves the tail of a block's turns, facts, and embeddings into a new block
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// SplitBridgeBlock moves atTurnID and every later turn of blockID into a new block.
+// Both blocks get keywords recomputed from their remaining turns, and facts and
+// embeddings follow their turns. The new block takes over the original's status
+// (an ACTIVE original is paused) so the single-active-block invariant holds.
+// The new block is recorded as a continuation-of the original, and the routing of
+// atTurnID is logged as corrected.
+func (s *Storage) SplitBridgeBlock(blockID, atTurnID string) (*models.BridgeBlock, error) {
+	s.mu.Lock()
+	newBlock, originalStatus, err := s.splitBridgeBlock(blockID, atTurnID)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	s.publishTopic(newBlock.BlockID, newBlock.Status)
+	s.publishTopic(blockID, originalStatus)
+	return newBlock, nil
+}
+
+// splitBridgeBlock splits the block, returning the new block and the original's status;
+// the caller holds s.mu
+func (s *Storage) splitBridgeBlock(blockID, atTurnID string) (*models.BridgeBlock, models.BridgeBlockStatus, error) {
+
+	block, err := s.blocks.Get(blockID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get block: %w", err)
+	}
+	if block == nil {
+		return nil, "", fmt.Errorf("block not found: %s", blockID)
+	}
+
+	turns, err := s.turns.GetByBlock(blockID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get turns: %w", err)
+	}
+
+	at := -1
+	for i := range turns {
+		if turns[i].TurnID == atTurnID {
+			at = i
+			break
+		}
+	}
+	if at < 0 {
+		return nil, "", fmt.Errorf("turn %s not found in block %s", atTurnID, blockID)
+	}
+	if at == 0 {
+		return nil, "", fmt.Errorf("cannot split at the first turn of a block")
+	}
+
+	head, tail := turns[:at], turns[at:]
+	now := s.db.now()
+
+	newBlock := &models.BridgeBlock{
+		BlockID:    s.newBlockID(now),
+		DayID:      tail[0].Timestamp.In(s.location()).Format("2006-01-02"),
+		TopicLabel: inferTopicLabel(&tail[0]),
+		Keywords:   turnKeywords(tail),
+		Status:     block.Status,
+		TurnCount:  len(tail),
+		CreatedAt:  tail[0].Timestamp,
+		UpdatedAt:  now,
+	}
+
+	originalStatus := block.Status
+	if originalStatus == models.StatusActive {
+		originalStatus = models.StatusPaused
+	}
+
+	tailIDs := make([]interface{}, len(tail))
+	for i := range tail {
+		tailIDs[i] = tail[i].TurnID
+	}
+	inClause := "(" + strings.TrimSuffix(strings.Repeat("?,", len(tailIDs)), ",") + ")"
+	moveArgs := append([]interface{}{newBlock.BlockID}, tailIDs...)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`
+		INSERT INTO bridge_blocks (id, day_id, topic_label, status, summary, turn_count, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, utcArgs([]interface{}{newBlock.BlockID, newBlock.DayID, newBlock.TopicLabel, string(newBlock.Status),
+		"", newBlock.TurnCount, newBlock.CreatedAt, newBlock.UpdatedAt})...); err != nil {
+		return nil, "", fmt.Errorf("failed to create new block: %w", err)
+	}
+	if err := setBlockKeywords(tx, newBlock.BlockID, newBlock.Keywords); err != nil {
+		return nil, "", err
+	}
+
+	if _, err := tx.Exec("UPDATE turns SET block_id = ? WHERE id IN "+inClause, moveArgs...); err != nil {
+		return nil, "", fmt.Errorf("failed to move turns: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE facts SET block_id = ? WHERE turn_id IN "+inClause, moveArgs...); err != nil {
+		return nil, "", fmt.Errorf("failed to move facts: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE embeddings SET block_id = ? WHERE turn_id IN "+inClause, moveArgs...); err != nil {
+		return nil, "", fmt.Errorf("failed to move embeddings: %w", err)
+	}
+	// Attachment embeddings are keyed by attachment ID rather than turn ID
+	if _, err := tx.Exec("UPDATE embeddings SET block_id = ? WHERE turn_id IN (SELECT id FROM attachments WHERE turn_id IN "+inClause+")", moveArgs...); err != nil {
+		return nil, "", fmt.Errorf("failed to move attachment embeddings: %w", err)
+	}
+	chunkArgs := append(append([]interface{}{}, moveArgs...), tailIDs...)
+	if _, err := tx.Exec("UPDATE chunks SET block_id = ? WHERE turn_id IN "+inClause+" OR turn_id IN (SELECT id FROM attachments WHERE turn_id IN "+inClause+")", chunkArgs...); err != nil {
+		return nil, "", fmt.Errorf("failed to move chunks: %w", err)
+	}
+
+	// The new block carries on the original's thread
+	if _, err := tx.Exec(`
+		INSERT INTO block_relations (from_block_id, to_block_id, relation, created_at)
+		VALUES (?, ?, ?, ?)
+	`, newBlock.BlockID, blockID, string(models.RelationContinuationOf), now.UTC()); err != nil {
+		return nil, "", fmt.Errorf("failed to relate blocks: %w", err)
+	}
+
+	// Routing the split turn into the original block was a mistake
+	if _, err := tx.Exec(`
+		UPDATE routing_log SET corrected_at = ? WHERE turn_id = ? AND corrected_at IS NULL
+	`, now.UTC(), atTurnID); err != nil {
+		return nil, "", fmt.Errorf("failed to record routing correction: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE bridge_blocks SET status = ?, turn_count = ?, updated_at = ?
+		WHERE id = ?
+	`, string(originalStatus), len(head), now.UTC(), blockID); err != nil {
+		return nil, "", fmt.Errorf("failed to update original block: %w", err)
+	}
+	if err := setBlockKeywords(tx, blockID, turnKeywords(head)); err != nil {
+		return nil, "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, "", fmt.Errorf("failed to commit split: %w", err)
+	}
+	if err := refreshCentroids(s.db, blockID, newBlock.BlockID); err != nil {
+		return nil, "", err
+	}
+
+	newBlock.Turns = tail
+	return newBlock, originalStatus, nil
+}
+
+// turnKeywords returns the de-duplicated keywords of turns in first-seen order
+func turnKeywords(turns []models.Turn) []string {
+	seen := make(map[string]bool)
+	keywords := []string{}
+	for _, turn := range turns {
+		for _, keyword := range turn.Keywords {
+			if !seen[keyword] {
+				seen[keyword] = true
+				keywords = append(keywords, keyword)
+			}
+		}
+	}
+	return keywords
+}
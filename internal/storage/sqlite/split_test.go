@@ -0,0 +1,110 @@
+// ABOUTME: Tests for Bridge Block split operation
+// ABOUTME: Verifies turns, facts, embeddings, keywords, and status move correctly
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestSplitBridgeBlock(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	base := time.Now().Add(-time.Hour)
+	blockID, _ := store.StoreTurn(&models.Turn{
+		TurnID: "turn_split_1", Timestamp: base, UserMessage: "Go generics",
+		Keywords: []string{"go", "generics"}, Topics: []string{"golang"},
+	})
+	_ = store.AppendTurnToBlock(blockID, &models.Turn{
+		TurnID: "turn_split_2", Timestamp: base.Add(time.Minute), UserMessage: "Type params",
+		Keywords: []string{"go"},
+	})
+	_ = store.AppendTurnToBlock(blockID, &models.Turn{
+		TurnID: "turn_split_3", Timestamp: base.Add(2 * time.Minute), UserMessage: "Sourdough starter",
+		Keywords: []string{"baking", "bread"}, Topics: []string{"baking"},
+	})
+	_ = store.AppendTurnToBlock(blockID, &models.Turn{
+		TurnID: "turn_split_4", Timestamp: base.Add(3 * time.Minute), UserMessage: "Oven temp",
+		Keywords: []string{"oven"},
+	})
+
+	_ = store.SaveFact(&models.Fact{FactID: "fact_split", Key: "bread_type", Value: "sourdough", BlockID: blockID, TurnID: "turn_split_3", CreatedAt: time.Now()})
+	_ = store.ReplaceTurnEmbeddings("turn_split_4", blockID, "m", []models.Chunk{{ChunkID: "chunk_split"}}, [][]float64{{1, 0}})
+
+	newBlock, err := store.SplitBridgeBlock(blockID, "turn_split_3")
+	if err != nil {
+		t.Fatalf("SplitBridgeBlock() error = %v", err)
+	}
+
+	if newBlock.TurnCount != 2 || newBlock.TopicLabel != "baking" {
+		t.Errorf("new block = %+v, want 2 turns labelled baking", newBlock)
+	}
+	if newBlock.Status != models.StatusActive {
+		t.Errorf("new block status = %s, want ACTIVE", newBlock.Status)
+	}
+
+	original, _ := store.GetBridgeBlock(blockID)
+	if len(original.Turns) != 2 || original.TurnCount != 2 {
+		t.Errorf("original has %d turns (count %d), want 2", len(original.Turns), original.TurnCount)
+	}
+	if original.Status != models.StatusPaused {
+		t.Errorf("original status = %s, want PAUSED", original.Status)
+	}
+	if len(original.Keywords) != 2 || original.Keywords[0] != "go" || original.Keywords[1] != "generics" {
+		t.Errorf("original keywords = %v, want [go generics]", original.Keywords)
+	}
+
+	moved, _ := store.GetBridgeBlock(newBlock.BlockID)
+	if len(moved.Turns) != 2 || moved.Turns[0].TurnID != "turn_split_3" {
+		t.Errorf("moved turns = %+v, want turn_split_3 first", moved.Turns)
+	}
+	if len(moved.Keywords) != 3 {
+		t.Errorf("moved keywords = %v, want [baking bread oven]", moved.Keywords)
+	}
+
+	facts, _ := store.GetFactsForBlock(newBlock.BlockID)
+	if len(facts) != 1 || facts[0].FactID != "fact_split" {
+		t.Errorf("facts for new block = %+v, want fact_split", facts)
+	}
+
+	embeddings, _ := store.GetVectorStorage().GetByBlock(newBlock.BlockID)
+	if len(embeddings) != 1 || embeddings[0].ChunkID != "chunk_split" {
+		t.Errorf("embeddings for new block = %+v, want chunk_split", embeddings)
+	}
+
+	active, _ := store.GetActiveBridgeBlocks()
+	if len(active) != 1 {
+		t.Errorf("active blocks = %d, want 1", len(active))
+	}
+
+	related, _ := store.GetRelatedTopics(newBlock.BlockID)
+	if len(related) != 1 || related[0].BlockID != blockID || related[0].Relation != string(models.RelationContinuationOf) {
+		t.Errorf("related topics of new block = %+v, want continuation-of original", related)
+	}
+}
+
+func TestSplitBridgeBlock_Errors(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, _ := store.StoreTurn(&models.Turn{TurnID: "turn_only", Timestamp: time.Now(), UserMessage: "hi"})
+
+	if _, err := store.SplitBridgeBlock("block_missing", "turn_only"); err == nil {
+		t.Error("expected error for missing block")
+	}
+	if _, err := store.SplitBridgeBlock(blockID, "turn_missing"); err == nil {
+		t.Error("expected error for turn not in block")
+	}
+	if _, err := store.SplitBridgeBlock(blockID, "turn_only"); err == nil {
+		t.Error("expected error when splitting at the first turn")
+	}
+}
@@ -0,0 +1,288 @@
+// ABOUTME: Aggregate statistics about the memory database
+// ABOUTME: Counts entities, sizes the DB file, and ranks active and most-read topics
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TopicActivity summarizes how much conversation a topic has accumulated
+type TopicActivity struct {
+	Topic     string `json:"topic"`
+	Blocks    int    `json:"blocks"`
+	TurnCount int    `json:"turn_count"`
+}
+
+// StorageStats contains aggregate statistics about stored memory
+type StorageStats struct {
+	DBPath          string          `json:"db_path"`
+	DBSizeBytes     int64           `json:"db_size_bytes"`
+	DBUsedBytes     int64           `json:"db_used_bytes"` // Bytes in live pages; what max_db_size limits
+	BlockCount      int             `json:"block_count"`
+	BlocksByStatus  map[string]int  `json:"blocks_by_status"`
+	TurnCount       int             `json:"turn_count"`
+	FactCount       int             `json:"fact_count"`
+	PendingFacts    int             `json:"pending_facts"` // Facts awaiting review, counted in FactCount
+	FactsByCategory map[string]int  `json:"facts_by_category"`
+	EmbeddingCount  int             `json:"embedding_count"`
+	TopTopics       []TopicActivity `json:"top_topics"`
+	HotTopics       []BlockHeat     `json:"hot_topics"` // Blocks retrieved or hydrated most often
+	LLMUsage        []LLMUsage      `json:"llm_usage"`
+	Caches          []CacheStats    `json:"caches"`
+	LastActivity    *time.Time      `json:"last_activity,omitempty"`
+	Evictions       EvictionStats   `json:"evictions"`
+	Compactions     CompactionStats `json:"compactions"`
+}
+
+// Stats computes aggregate statistics, ranking up to topN topics by turn count and
+// up to topN blocks by how often they were read
+func (s *Storage) Stats(topN int) (*StorageStats, error) {
+	stats := &StorageStats{
+		DBPath:          s.db.Path(),
+		DBSizeBytes:     s.db.Size(),
+		BlocksByStatus:  make(map[string]int),
+		FactsByCategory: make(map[string]int),
+	}
+
+	counts := []struct {
+		table string
+		dest  *int
+	}{
+		{"bridge_blocks", &stats.BlockCount},
+		{"turns", &stats.TurnCount},
+		{"facts", &stats.FactCount},
+		{"embeddings", &stats.EmbeddingCount},
+	}
+	for _, c := range counts {
+		if err := s.db.QueryRow("SELECT COUNT(*) FROM " + c.table).Scan(c.dest); err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", c.table, err)
+		}
+	}
+
+	pending, err := s.facts.CountPending()
+	if err != nil {
+		return nil, err
+	}
+	stats.PendingFacts = pending
+
+	used, err := s.UsedBytes()
+	if err != nil {
+		return nil, err
+	}
+	stats.DBUsedBytes = used
+
+	if err := s.countBlocksByStatus(stats.BlocksByStatus); err != nil {
+		return nil, err
+	}
+	if err := s.countFactsByCategory(stats.FactsByCategory); err != nil {
+		return nil, err
+	}
+
+	topTopics, err := s.topTopics(topN)
+	if err != nil {
+		return nil, err
+	}
+	stats.TopTopics = topTopics
+
+	hotTopics, err := s.hotTopics(topN)
+	if err != nil {
+		return nil, err
+	}
+	stats.HotTopics = hotTopics
+
+	usage, err := s.usage.Summary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize LLM usage: %w", err)
+	}
+	stats.LLMUsage = usage
+
+	caches, err := s.CacheStats()
+	if err != nil {
+		return nil, err
+	}
+	stats.Caches = caches
+
+	var lastActivity time.Time
+	err = s.db.QueryRow("SELECT updated_at FROM bridge_blocks ORDER BY updated_at DESC LIMIT 1").Scan(&lastActivity)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get last activity: %w", err)
+	}
+	if err == nil {
+		stats.LastActivity = &lastActivity
+	}
+
+	evictions, err := s.EvictionStats()
+	if err != nil {
+		return nil, err
+	}
+	stats.Evictions = evictions
+
+	compactions, err := s.CompactionStats()
+	if err != nil {
+		return nil, err
+	}
+	stats.Compactions = compactions
+
+	return stats, nil
+}
+
+// countBlocksByStatus fills counts with the number of blocks per status
+func (s *Storage) countBlocksByStatus(counts map[string]int) error {
+	rows, err := s.db.Query("SELECT status, COUNT(*) FROM bridge_blocks GROUP BY status")
+	if err != nil {
+		return fmt.Errorf("failed to count blocks by status: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var (
+			status string
+			count  int
+		)
+		if err := rows.Scan(&status, &count); err != nil {
+			return err
+		}
+		counts[status] = count
+	}
+	return rows.Err()
+}
+
+// countFactsByCategory fills counts with the number of facts per key category
+func (s *Storage) countFactsByCategory(counts map[string]int) error {
+	rows, err := s.db.Query("SELECT key, COUNT(*) FROM facts GROUP BY key")
+	if err != nil {
+		return fmt.Errorf("failed to count facts by key: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var (
+			key   string
+			count int
+		)
+		if err := rows.Scan(&key, &count); err != nil {
+			return err
+		}
+		counts[FactCategory(key)] += count
+	}
+	return rows.Err()
+}
+
+// topTopics ranks topic labels by the number of turns stored under them
+func (s *Storage) topTopics(limit int) ([]TopicActivity, error) {
+	rows, err := s.db.Query(`
+		SELECT b.topic_label, COUNT(DISTINCT b.id), COUNT(t.id)
+		FROM bridge_blocks b
+		LEFT JOIN turns t ON t.block_id = b.id
+		GROUP BY b.topic_label
+		ORDER BY COUNT(t.id) DESC, b.topic_label ASC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank topics: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	topics := []TopicActivity{}
+	for rows.Next() {
+		var (
+			topic sql.NullString
+			ta    TopicActivity
+		)
+		if err := rows.Scan(&topic, &ta.Blocks, &ta.TurnCount); err != nil {
+			return nil, err
+		}
+		ta.Topic = topic.String
+		topics = append(topics, ta)
+	}
+	return topics, rows.Err()
+}
+
+// FactCategory derives a coarse category from a fact key.
+// Keys are snake_case with the most general noun last ("weather_api_key",
+// "dietary_preference"), so the final segment groups related facts together.
+func FactCategory(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if i := strings.LastIndex(key, "_"); i >= 0 && i < len(key)-1 {
+		return key[i+1:]
+	}
+	return key
+}
+
+// SortedCategories returns category names ordered by count (descending)
+func SortedCategories(counts map[string]int) []string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// caches returns the block, profile, and search caches
+func (s *Storage) caches() []statsCache {
+	return []statsCache{s.blocks.cache, s.profile.cache, s.search.results, s.search.vectors}
+}
+
+// CacheStats reports each cache's hits and misses: those saved by earlier processes
+// plus this one's, with this process's current size
+func (s *Storage) CacheStats() ([]CacheStats, error) {
+	saved := make(map[string]CacheStats)
+	rows, err := s.db.Query("SELECT name, hits, misses FROM cache_stats")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache stats: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var c CacheStats
+		if err := rows.Scan(&c.Name, &c.Hits, &c.Misses); err != nil {
+			return nil, err
+		}
+		saved[c.Name] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var stats []CacheStats
+	for _, cache := range s.caches() {
+		c := cache.stats()
+		c.Hits += saved[c.Name].Hits
+		c.Misses += saved[c.Name].Misses
+		stats = append(stats, c)
+	}
+	return stats, nil
+}
+
+// saveCacheStats adds this process's unsaved cache hits and misses to cache_stats
+func (s *Storage) saveCacheStats() error {
+	if s.db.ReadOnly() {
+		return nil
+	}
+	for _, cache := range s.caches() {
+		hits, misses := cache.takeCounts()
+		if hits == 0 && misses == 0 {
+			continue
+		}
+		if _, err := s.db.Exec(`
+			INSERT INTO cache_stats (name, hits, misses, updated_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT(name) DO UPDATE SET
+				hits = hits + excluded.hits,
+				misses = misses + excluded.misses,
+				updated_at = excluded.updated_at
+		`, cache.stats().Name, hits, misses, s.db.now()); err != nil {
+			return fmt.Errorf("failed to save cache stats: %w", err)
+		}
+	}
+	return nil
+}
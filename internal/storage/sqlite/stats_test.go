@@ -0,0 +1,173 @@
+// ABOUTME: Tests for aggregate storage statistics
+// ABOUTME: Verifies counts, topic ranking, fact categories, and LLM usage totals
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestStats_Empty(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	stats, err := store.Stats(5)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+
+	if stats.BlockCount != 0 || stats.TurnCount != 0 || stats.FactCount != 0 || stats.EmbeddingCount != 0 {
+		t.Errorf("Expected zero counts, got %+v", stats)
+	}
+	if stats.LastActivity != nil {
+		t.Error("LastActivity should be nil for empty database")
+	}
+	if len(stats.TopTopics) != 0 {
+		t.Errorf("TopTopics = %v, want empty", stats.TopTopics)
+	}
+}
+
+func TestStats_Counts(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	goBlock, _ := store.StoreTurn(&models.Turn{
+		TurnID:      "turn_stats_1",
+		Timestamp:   time.Now(),
+		UserMessage: "Let's talk about Go",
+		Topics:      []string{"golang"},
+	})
+	_ = store.AppendTurnToBlock(goBlock, &models.Turn{
+		TurnID:      "turn_stats_2",
+		Timestamp:   time.Now(),
+		UserMessage: "Channels please",
+	})
+	_, _ = store.StoreTurn(&models.Turn{
+		TurnID:      "turn_stats_3",
+		Timestamp:   time.Now(),
+		UserMessage: "Dinner ideas",
+		Topics:      []string{"cooking"},
+	})
+
+	_ = store.SaveFacts([]models.Fact{
+		{FactID: "f1", Key: "weather_api_key", Value: "abc", Confidence: 1.0},
+		{FactID: "f2", Key: "stripe_api_key", Value: "def", Confidence: 1.0},
+		{FactID: "f3", Key: "name", Value: "Harper", Confidence: 1.0},
+	})
+
+	_ = store.RecordLLMUsage("embedding", "text-embedding-3-small", 10, 0)
+	_ = store.RecordLLMUsage("embedding", "text-embedding-3-small", 5, 0)
+
+	stats, err := store.Stats(5)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+
+	if stats.BlockCount != 2 {
+		t.Errorf("BlockCount = %d, want 2", stats.BlockCount)
+	}
+	if stats.TurnCount != 3 {
+		t.Errorf("TurnCount = %d, want 3", stats.TurnCount)
+	}
+	if stats.FactCount != 3 {
+		t.Errorf("FactCount = %d, want 3", stats.FactCount)
+	}
+	if stats.BlocksByStatus["ACTIVE"] != 1 || stats.BlocksByStatus["PAUSED"] != 1 {
+		t.Errorf("BlocksByStatus = %v, want 1 ACTIVE and 1 PAUSED", stats.BlocksByStatus)
+	}
+	if stats.FactsByCategory["key"] != 2 || stats.FactsByCategory["name"] != 1 {
+		t.Errorf("FactsByCategory = %v", stats.FactsByCategory)
+	}
+	if len(stats.TopTopics) != 2 || stats.TopTopics[0].Topic != "golang" || stats.TopTopics[0].TurnCount != 2 {
+		t.Errorf("TopTopics = %+v, want golang first with 2 turns", stats.TopTopics)
+	}
+	if len(stats.LLMUsage) != 1 || stats.LLMUsage[0].Calls != 2 || stats.LLMUsage[0].PromptTokens != 15 {
+		t.Errorf("LLMUsage = %+v, want 2 calls with 15 prompt tokens", stats.LLMUsage)
+	}
+	if stats.LastActivity == nil {
+		t.Error("LastActivity should be set")
+	}
+}
+
+func TestStats_TopNLimit(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	for _, topic := range []string{"a", "b", "c"} {
+		_, _ = store.StoreTurn(&models.Turn{
+			TurnID:      "turn_" + topic,
+			Timestamp:   time.Now(),
+			UserMessage: "message",
+			Topics:      []string{topic},
+		})
+	}
+
+	stats, err := store.Stats(2)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if len(stats.TopTopics) != 2 {
+		t.Errorf("TopTopics length = %d, want 2", len(stats.TopTopics))
+	}
+}
+
+func TestStats_DBSize(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+	store, err := NewStorageWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewStorageWithPath() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	stats, err := store.Stats(5)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if stats.DBPath != dbPath {
+		t.Errorf("DBPath = %q, want %q", stats.DBPath, dbPath)
+	}
+	if stats.DBSizeBytes <= 0 {
+		t.Errorf("DBSizeBytes = %d, want > 0", stats.DBSizeBytes)
+	}
+}
+
+func TestFactCategory(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"weather_api_key", "key"},
+		{"dietary_preference", "preference"},
+		{"name", "name"},
+		{"Favorite_Language", "language"},
+		{"trailing_", "trailing_"},
+	}
+
+	for _, tt := range tests {
+		if got := FactCategory(tt.key); got != tt.want {
+			t.Errorf("FactCategory(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestSortedCategories(t *testing.T) {
+	got := SortedCategories(map[string]int{"name": 1, "key": 3, "email": 1})
+	want := []string{"key", "email", "name"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("SortedCategories() = %v, want %v", got, want)
+		}
+	}
+}
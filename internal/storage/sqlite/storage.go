@@ -3,6 +3,7 @@
 package sqlite
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"sort"
@@ -10,25 +11,40 @@ import (
 	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/tracing"
 )
 
 // Storage manages all persistent data for HMLR using SQLite
 type Storage struct {
-	db            *DB
-	blocks        *BlockStore
-	turns         *TurnStore
-	facts         *FactStore
-	embeddings    *EmbeddingStore
-	profile       *ProfileStore
-	openaiClient  interface {
+	db           *DB
+	blocks       *BlockStore
+	turns        *TurnStore
+	facts        *FactStore
+	embeddings   *EmbeddingStore
+	profile      *ProfileStore
+	usage        *UsageStore
+	features     *FeatureStore
+	tags         *TagStore
+	relations    *RelationStore
+	attachments  *AttachmentStore
+	jobs         *JobStore
+	search       *searchCache
+	openaiClient interface {
 		GenerateEmbedding(text string) ([]float64, error)
 	}
 	chunkEngine interface {
 		ChunkTurn(text string, turnID string) ([]models.Chunk, error)
 	}
-	mu sync.RWMutex
+	blockIDGen  func() string     // nil = timestamp plus random suffix
+	loc         *time.Location    // zone for day IDs and exports; nil = time.Local
+	events      EventPublisher    // nil = events are not published
+	changes     changeFeed        // Channel subscriptions to published events
+	reviewFacts bool              // Extracted facts wait for approval in the review queue
+	factLimits  models.FactLimits // Bounds on the facts saved from one extraction
+	taxonomy    models.Taxonomy   // Topic labels stored turns' topics are mapped onto
+	undoLogSize int               // Destructive operations kept in the undo log; 0 = none
+	mu          sync.RWMutex
 }
 
 // BridgeBlockInfo contains summary information about a Bridge Block
@@ -53,13 +69,55 @@ func NewStorageWithPath(dbPath string) (*Storage, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	store := &Storage{
+		db:          db,
+		blocks:      NewBlockStore(db),
+		turns:       NewTurnStore(db),
+		facts:       NewFactStore(db),
+		embeddings:  NewEmbeddingStore(db),
+		profile:     NewProfileStore(db),
+		usage:       NewUsageStore(db),
+		features:    NewFeatureStore(db),
+		tags:        NewTagStore(db),
+		relations:   NewRelationStore(db),
+		attachments: NewAttachmentStore(db),
+		jobs:        NewJobStore(db),
+		search:      newSearchCache(db),
+		undoLogSize: DefaultUndoLogSize,
+	}
+
+	// Secrets saved before encryption at rest are sealed on the first writable open
+	if sealed, err := store.facts.sealPlaintextSecrets(); err != nil {
+		_ = db.Close()
+		return nil, err
+	} else if sealed > 0 {
+		log.Printf("[Storage] encrypted %d secret facts stored in the clear", sealed)
+	}
+	return store, nil
+}
+
+// NewStorageReadOnly opens an existing database for reading; every write method returns an error
+func NewStorageReadOnly(dbPath string) (*Storage, error) {
+	db, err := OpenReadOnly(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Storage{
-		db:         db,
-		blocks:     NewBlockStore(db),
-		turns:      NewTurnStore(db),
-		facts:      NewFactStore(db),
-		embeddings: NewEmbeddingStore(db),
-		profile:    NewProfileStore(db),
+		db:          db,
+		blocks:      NewBlockStore(db),
+		turns:       NewTurnStore(db),
+		facts:       NewFactStore(db),
+		embeddings:  NewEmbeddingStore(db),
+		profile:     NewProfileStore(db),
+		usage:       NewUsageStore(db),
+		features:    NewFeatureStore(db),
+		tags:        NewTagStore(db),
+		relations:   NewRelationStore(db),
+		attachments: NewAttachmentStore(db),
+		jobs:        NewJobStore(db),
+		search:      newSearchCache(db),
+		undoLogSize: DefaultUndoLogSize,
 	}, nil
 }
 
@@ -71,23 +129,84 @@ func NewStorageInMemory() (*Storage, error) {
 	}
 
 	return &Storage{
-		db:         db,
-		blocks:     NewBlockStore(db),
-		turns:      NewTurnStore(db),
-		facts:      NewFactStore(db),
-		embeddings: NewEmbeddingStore(db),
-		profile:    NewProfileStore(db),
+		db:          db,
+		blocks:      NewBlockStore(db),
+		turns:       NewTurnStore(db),
+		facts:       NewFactStore(db),
+		embeddings:  NewEmbeddingStore(db),
+		profile:     NewProfileStore(db),
+		usage:       NewUsageStore(db),
+		features:    NewFeatureStore(db),
+		tags:        NewTagStore(db),
+		relations:   NewRelationStore(db),
+		attachments: NewAttachmentStore(db),
+		jobs:        NewJobStore(db),
+		search:      newSearchCache(db),
+		undoLogSize: DefaultUndoLogSize,
 	}, nil
 }
 
-// Close closes the database connection
+// Close ends change feed subscriptions, giving lossless subscribers a moment to finish,
+// and closes the database connection
 func (s *Storage) Close() error {
+	s.changes.close()
 	if s.db != nil {
+		// Cache stats are a nicety; losing them should not fail the close
+		if err := s.saveCacheStats(); err != nil {
+			log.Printf("[Storage] %v", err)
+		}
 		return s.db.Close()
 	}
 	return nil
 }
 
+// Path returns the database file path
+func (s *Storage) Path() string {
+	return s.db.Path()
+}
+
+// ReadOnly reports whether this storage rejects writes
+func (s *Storage) ReadOnly() bool {
+	return s.db.ReadOnly()
+}
+
+// Ping checks that the database can still be read
+func (s *Storage) Ping(ctx context.Context) error {
+	if err := s.db.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to reach database: %w", err)
+	}
+	return nil
+}
+
+// ChangeVersion returns a number that changes whenever this process or another writes
+// the database, so exporters can skip work when nothing changed. It reports false
+// while a write is in flight; ask again later.
+func (s *Storage) ChangeVersion() (uint64, bool) {
+	return s.db.version()
+}
+
+// SchemaVersion returns the schema version recorded in the database; compare it with
+// the package's SchemaVersion to tell whether a newer build wrote the database
+func (s *Storage) SchemaVersion() (int, error) {
+	version, err := s.db.SchemaVersion()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// EmbeddingDimensions counts stored vectors by dimension, with malformed vectors
+// counted under dimension 0
+func (s *Storage) EmbeddingDimensions() (map[int]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dims, err := s.embeddings.Dimensions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count embedding dimensions: %w", err)
+	}
+	return dims, nil
+}
+
 // SetOpenAIClient sets the OpenAI client for embeddings
 func (s *Storage) SetOpenAIClient(client interface {
 	GenerateEmbedding(text string) ([]float64, error)
@@ -105,43 +224,51 @@ func (s *Storage) SetChunkEngine(engine interface {
 // StoreTurn stores a conversation turn and creates/updates a Bridge Block
 // INVARIANT: Only ONE block can be ACTIVE at a time.
 func (s *Storage) StoreTurn(turn *models.Turn) (string, error) {
+	return s.StoreTurnContext(context.Background(), turn)
+}
+
+// StoreTurnContext is StoreTurn, traced as a child of the span in ctx with the
+// database writes and embedding calls as separate spans
+func (s *Storage) StoreTurnContext(ctx context.Context, turn *models.Turn) (blockID string, err error) {
+	ctx, span := tracing.Start(ctx, "storage.store_turn", tracing.String("turn.id", turn.TurnID))
+	defer func() {
+		span.SetAttributes(tracing.String("block.id", blockID))
+		span.RecordError(err)
+		span.End()
+	}()
+	defer func() { // runs after the unlock below, so subscribers may use storage
+		if err == nil {
+			s.publishTurn(blockID, turn)
+		}
+	}()
+
+	waitStart := time.Now()
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	span.SetAttributes(tracing.Int("storage.lock_wait_us", int(time.Since(waitStart).Microseconds())))
+	turn.Topics = s.mapTopics(turn.Topics)
 
-	// Get active blocks and auto-repair if invariant violated
-	activeBlocks, err := s.blocks.GetByStatus(models.StatusActive)
-	if err != nil {
-		return "", fmt.Errorf("failed to check active blocks: %w", err)
-	}
+	_, dbSpan := tracing.Start(ctx, "sqlite.create_block")
+	defer dbSpan.End()
 
-	// Auto-repair: if multiple active blocks exist, pause all but newest
-	if len(activeBlocks) > 1 {
-		var newestBlock *models.BridgeBlock
-		for i := range activeBlocks {
-			block := &activeBlocks[i]
-			if newestBlock == nil || block.UpdatedAt.After(newestBlock.UpdatedAt) {
-				newestBlock = block
-			}
-		}
-		for i := range activeBlocks {
-			if activeBlocks[i].BlockID != newestBlock.BlockID {
-				if err := s.blocks.UpdateStatus(activeBlocks[i].BlockID, models.StatusPaused); err != nil {
-					return "", fmt.Errorf("failed to auto-repair block %s: %w", activeBlocks[i].BlockID, err)
-				}
-			}
-		}
-		activeBlocks, _ = s.blocks.GetByStatus(models.StatusActive)
+	// Pausing the active block and creating its successor happen in one write
+	// transaction, so concurrent writers (other handles or processes) cannot each
+	// pause the same block and leave two active. Pausing every active block also
+	// repairs a database that already has more than one.
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer func() { _ = tx.Rollback() }()
 
-	// Pause any existing active block
-	if len(activeBlocks) == 1 {
-		if err := s.blocks.UpdateStatus(activeBlocks[0].BlockID, models.StatusPaused); err != nil {
-			return "", fmt.Errorf("failed to pause existing active block: %w", err)
-		}
+	now := s.db.now()
+	if _, err := tx.Exec(`UPDATE bridge_blocks SET status = ?, updated_at = ? WHERE status = ?`,
+		string(models.StatusPaused), now.UTC(), string(models.StatusActive)); err != nil {
+		return "", fmt.Errorf("failed to pause existing active block: %w", err)
 	}
 
-	today := time.Now().Format("2006-01-02")
-	blockID := fmt.Sprintf("block_%s_%s", time.Now().Format("20060102_150405"), uuid.New().String()[:8])
+	today := now.In(s.location()).Format("2006-01-02")
+	blockID = s.newBlockID(now)
 
 	block := &models.BridgeBlock{
 		BlockID:    blockID,
@@ -149,24 +276,28 @@ func (s *Storage) StoreTurn(turn *models.Turn) (string, error) {
 		TopicLabel: inferTopicLabel(turn),
 		Keywords:   turn.Keywords,
 		Status:     models.StatusActive,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
 		TurnCount:  1,
 	}
 
 	// Save Bridge Block
-	if err := s.blocks.Save(block); err != nil {
+	if err := saveBlock(tx, block); err != nil {
 		return "", fmt.Errorf("failed to save bridge block: %w", err)
 	}
 
 	// Save the turn
-	if err := s.turns.Save(blockID, turn); err != nil {
+	if err := saveTurn(tx, blockID, turn); err != nil {
 		return "", fmt.Errorf("failed to save turn: %w", err)
 	}
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to store turn: %w", err)
+	}
+	dbSpan.End()
 
 	// Generate and save embeddings if clients are configured
 	if s.openaiClient != nil && s.chunkEngine != nil {
-		if err := s.generateAndSaveEmbeddings(turn, blockID); err != nil {
+		if err := s.generateAndSaveEmbeddings(ctx, turn, blockID); err != nil {
 			log.Printf("[Storage] failed to generate embeddings: %v", err)
 		}
 	}
@@ -174,22 +305,99 @@ func (s *Storage) StoreTurn(turn *models.Turn) (string, error) {
 	return blockID, nil
 }
 
+// SetLocation sets the time zone used for day IDs and exported timestamps.
+// Timestamps are always stored in UTC.
+func (s *Storage) SetLocation(loc *time.Location) {
+	s.loc = loc
+}
+
+// SetClock sets the clock that stamps what is stored and that retention, eviction, and
+// scheduling measure against, e.g. a models.SimulatedClock in tests; nil falls back to models.Now
+func (s *Storage) SetClock(c models.Clock) {
+	s.db.clock = c
+}
+
+// Now returns the time on the storage's clock
+func (s *Storage) Now() time.Time {
+	return s.db.now()
+}
+
+// SetSlowQueryThreshold logs storage queries that take at least d; zero turns it off
+func (s *Storage) SetSlowQueryThreshold(d time.Duration) {
+	s.db.SetSlowQueryThreshold(d)
+}
+
+// Location returns the configured time zone, defaulting to the system zone
+func (s *Storage) Location() *time.Location {
+	return s.location()
+}
+
+// location returns the configured time zone, defaulting to the system zone
+func (s *Storage) location() *time.Location {
+	if s.loc == nil {
+		return time.Local
+	}
+	return s.loc
+}
+
+// SetBlockIDGenerator overrides how new block IDs are generated (e.g. for reproducible benchmarks)
+func (s *Storage) SetBlockIDGenerator(gen func() string) {
+	s.blockIDGen = gen
+}
+
+// newBlockID returns the ID for a block created at now
+func (s *Storage) newBlockID(now time.Time) string {
+	if s.blockIDGen != nil {
+		return s.blockIDGen()
+	}
+	return models.NewIDAt("block", now)
+}
+
 // generateAndSaveEmbeddings generates and saves embeddings for a turn
-func (s *Storage) generateAndSaveEmbeddings(turn *models.Turn, blockID string) error {
-	fullText := turn.UserMessage + " " + turn.AIResponse
+func (s *Storage) generateAndSaveEmbeddings(ctx context.Context, turn *models.Turn, blockID string) error {
+	return s.embedText(ctx, turn.Text(), turn.TurnID, blockID)
+}
+
+// embedText chunks text and saves an embedding per chunk under ownerID (the
+// embeddings turn_id column) and blockID
+func (s *Storage) embedText(ctx context.Context, text, ownerID, blockID string) (err error) {
+	ctx, span := tracing.Start(ctx, "storage.embed", tracing.String("embedding.owner_id", ownerID))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 
-	chunks, err := s.chunkEngine.ChunkTurn(fullText, turn.TurnID)
+	chunks, err := s.chunkEngine.ChunkTurn(text, ownerID)
 	if err != nil {
-		return fmt.Errorf("failed to chunk turn: %w", err)
+		return fmt.Errorf("failed to chunk text: %w", err)
+	}
+	span.SetAttributes(tracing.Int("embedding.chunks", len(chunks)))
+
+	// Record the model when the client reports it, so re-embedding can skip these
+	var model string
+	if m, ok := s.openaiClient.(interface{ EmbeddingModel() string }); ok {
+		model = m.EmbeddingModel()
+	}
+
+	// The text is kept so search results can quote the chunk that matched
+	if err := saveChunks(s.db, blockID, chunks, s.db.now()); err != nil {
+		return err
 	}
 
 	for _, chunk := range chunks {
+		_, llmSpan := tracing.Start(ctx, "llm.generate_embedding", tracing.String("llm.model", model), tracing.Int("llm.input_chars", len(chunk.Content)))
 		embedding, err := s.openaiClient.GenerateEmbedding(chunk.Content)
+		llmSpan.RecordError(err)
+		llmSpan.End()
 		if err != nil {
 			return fmt.Errorf("failed to generate embedding for chunk %s: %w", chunk.ChunkID, err)
 		}
 
-		if err := s.embeddings.Save(chunk.ChunkID, turn.TurnID, blockID, embedding); err != nil {
+		_, dbSpan := tracing.Start(ctx, "sqlite.save_embedding")
+		err = s.embeddings.SaveWithModel(chunk.ChunkID, ownerID, blockID, model, embedding)
+		dbSpan.RecordError(err)
+		dbSpan.End()
+		if err != nil {
 			return fmt.Errorf("failed to save embedding for chunk %s: %w", chunk.ChunkID, err)
 		}
 	}
@@ -201,7 +409,16 @@ func (s *Storage) generateAndSaveEmbeddings(turn *models.Turn, blockID string) e
 func (s *Storage) GetBridgeBlock(blockID string) (*models.BridgeBlock, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.blocks.GetWithTurns(blockID)
+	block, err := s.blocks.GetWithTurns(blockID)
+	if err != nil || block == nil {
+		return block, err
+	}
+	tags, err := s.tags.ForBlocks([]string{blockID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block tags: %w", err)
+	}
+	block.Tags = tags[blockID]
+	return block, nil
 }
 
 // GetActiveBridgeBlocks retrieves all Bridge Blocks with ACTIVE status
@@ -217,60 +434,302 @@ func (s *Storage) GetPausedBridgeBlocks() ([]models.BridgeBlock, error) {
 // UpdateBridgeBlockStatus updates the status of a Bridge Block
 func (s *Storage) UpdateBridgeBlockStatus(blockID string, status models.BridgeBlockStatus) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	return s.blocks.UpdateStatus(blockID, status)
+	err := s.blocks.UpdateStatus(blockID, status)
+	s.mu.Unlock()
+	if err == nil {
+		s.publishTopic(blockID, status)
+	}
+	return err
 }
 
 // AppendTurnToBlock appends a turn to an existing Bridge Block
 func (s *Storage) AppendTurnToBlock(blockID string, turn *models.Turn) error {
+	return s.AppendTurnToBlockContext(context.Background(), blockID, turn)
+}
+
+// AppendTurnToBlockContext is AppendTurnToBlock, traced as a child of the span in ctx
+func (s *Storage) AppendTurnToBlockContext(ctx context.Context, blockID string, turn *models.Turn) (err error) {
+	_, span := tracing.Start(ctx, "storage.append_turn", tracing.String("turn.id", turn.TurnID), tracing.String("block.id", blockID))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	defer func() { // runs after the unlock below, so subscribers may use storage
+		if err == nil {
+			s.publishTurn(blockID, turn)
+		}
+	}()
+
+	waitStart := time.Now()
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	span.SetAttributes(tracing.Int("storage.lock_wait_us", int(time.Since(waitStart).Microseconds())))
 
 	block, err := s.blocks.Get(blockID)
 	if err != nil || block == nil {
 		return fmt.Errorf("failed to get block: %w", err)
 	}
+	turn.Topics = s.mapTopics(turn.Topics)
 
 	// Save the turn
 	if err := s.turns.Save(blockID, turn); err != nil {
 		return fmt.Errorf("failed to save turn: %w", err)
 	}
 
-	// Update block metadata
-	block.TurnCount++
-	block.UpdatedAt = time.Now()
+	// Update block metadata and merge keywords
+	if err := s.blocks.AddTurn(blockID, turn.Keywords, s.db.now()); err != nil {
+		return fmt.Errorf("failed to update block: %w", err)
+	}
+	return nil
+}
 
-	// Merge keywords
-	for _, keyword := range turn.Keywords {
-		found := false
-		for _, existing := range block.Keywords {
-			if existing == keyword {
-				found = true
-				break
-			}
+// GetTurn returns a turn and the block holding it, or a nil turn if it does not exist
+func (s *Storage) GetTurn(turnID string) (string, *models.Turn, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.getTurn(turnID)
+}
+
+// getTurn is GetTurn for callers holding the lock
+func (s *Storage) getTurn(turnID string) (string, *models.Turn, error) {
+	blockID, err := s.turns.BlockOf(turnID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get turn: %w", err)
+	}
+	if blockID == "" {
+		return "", nil, nil
+	}
+	turns, err := s.turns.GetByBlock(blockID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get turn: %w", err)
+	}
+	for i := range turns {
+		if turns[i].TurnID == turnID {
+			return blockID, &turns[i], nil
+		}
+	}
+	return "", nil, nil
+}
+
+// UpdateTurn corrects a stored turn's user message or AI response and appends an
+// annotation, returning the turn as saved and its block. A turn that holds a
+// transcript can only be annotated.
+func (s *Storage) UpdateTurn(turnID string, edit models.TurnEdit) (string, *models.Turn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blockID, turn, err := s.getTurn(turnID)
+	if err != nil {
+		return "", nil, err
+	}
+	if turn == nil {
+		return "", nil, fmt.Errorf("turn %s not found", turnID)
+	}
+
+	now := s.db.now().UTC()
+	edited := false
+	if edit.UserMessage != nil && *edit.UserMessage != turn.UserMessage {
+		if strings.TrimSpace(*edit.UserMessage) == "" {
+			return "", nil, fmt.Errorf("user message cannot be empty")
 		}
-		if !found {
-			block.Keywords = append(block.Keywords, keyword)
+		turn.UserMessage, edited = *edit.UserMessage, true
+	}
+	if edit.AIResponse != nil && *edit.AIResponse != turn.AIResponse {
+		turn.AIResponse, edited = *edit.AIResponse, true
+	}
+	if edited && len(turn.Messages) > 0 {
+		return "", nil, fmt.Errorf("turn %s holds a transcript; it can be annotated but not edited", turnID)
+	}
+	if edited {
+		turn.EditedAt = &now
+	}
+	if note := strings.TrimSpace(edit.Annotation); note != "" {
+		turn.Annotations = append(turn.Annotations, models.TurnAnnotation{Text: note, Agent: edit.Agent, CreatedAt: now})
+	}
+
+	if !edited {
+		if err := s.turns.Revise(turn); err != nil {
+			return "", nil, fmt.Errorf("failed to update turn: %w", err)
 		}
+		return blockID, turn, nil
+	}
+
+	// Edited text may redact something; the undo log keeps the old text
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := s.recordUndo(tx, UndoEditTurn, turnID, "edit of turn "+turnID,
+		undoCapture{table: "turns", where: "id = ?", args: []interface{}{turnID}},
+	); err != nil {
+		return "", nil, err
 	}
+	if err := reviseTurn(tx, turn); err != nil {
+		return "", nil, fmt.Errorf("failed to update turn: %w", err)
+	}
+	// Snippets of the old text should not be shown for the edited turn
+	if err := deleteChunks(tx, turnID); err != nil {
+		return "", nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", nil, fmt.Errorf("failed to update turn: %w", err)
+	}
+	return blockID, turn, nil
+}
 
-	return s.blocks.Save(block)
+// UpdateTurnMetadata sets the keywords, topics, and affect of a stored turn
+func (s *Storage) UpdateTurnMetadata(turnID string, keywords, topics []string, affect models.Affect) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.turns.UpdateMetadata(turnID, keywords, s.mapTopics(topics), affect); err != nil {
+		return fmt.Errorf("failed to update turn metadata: %w", err)
+	}
+	return nil
 }
 
-// DeleteBridgeBlock deletes a bridge block (cascade deletes turns and embeddings)
+// PendingEnrichmentCount returns how many turns still wait on metadata, embeddings, or
+// facts from an LLM that was missing or failing when they were stored
+func (s *Storage) PendingEnrichmentCount() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n, err := s.turns.PendingEnrichmentCount()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count turns pending enrichment: %w", err)
+	}
+	return n, nil
+}
+
+// DeleteBridgeBlock deletes a bridge block (cascade deletes turns and embeddings),
+// keeping a copy in the undo log
 func (s *Storage) DeleteBridgeBlock(blockID string) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	return s.blocks.Delete(blockID)
+	err := s.deleteBlock(blockID)
+	s.mu.Unlock()
+	if err == nil {
+		event := models.NewEvent(models.EventTopicDeleted)
+		event.BlockID = blockID
+		s.publish(event)
+	}
+	return err
+}
+
+// deleteBlock deletes a block and what cascades with it, recording every row it
+// removes, and the facts it unlinks, in the undo log
+func (s *Storage) deleteBlock(blockID string) error {
+	var label string
+	if err := s.db.QueryRow("SELECT COALESCE((SELECT topic_label FROM bridge_blocks WHERE id = ?), '')", blockID).Scan(&label); err != nil {
+		return fmt.Errorf("failed to get block %s: %w", blockID, err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Parents come first, so restoring them in order satisfies foreign keys
+	byBlock := func(table string) undoCapture {
+		return undoCapture{table: table, where: "block_id = ?", args: []interface{}{blockID}}
+	}
+	turnIDs := "SELECT id FROM turns WHERE block_id = ?"
+	if err := s.recordUndo(tx, UndoDeleteTopic, blockID, fmt.Sprintf("topic %q", label),
+		undoCapture{table: "bridge_blocks", where: "id = ?", args: []interface{}{blockID}},
+		byBlock("turns"),
+		undoCapture{table: "attachments", where: "turn_id IN (" + turnIDs + ")", args: []interface{}{blockID}},
+		byBlock("facts"),
+		byBlock("embeddings"),
+		byBlock("chunks"),
+		byBlock("block_tags"),
+		byBlock("block_keywords"),
+		undoCapture{table: "block_relations", where: "from_block_id = ? OR to_block_id = ?", args: []interface{}{blockID, blockID}},
+		byBlock("block_access"),
+		byBlock("memory_feedback"),
+		byBlock("compactions"),
+		byBlock("ingested_files"),
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM bridge_blocks WHERE id = ?", blockID); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // SearchMemory searches for relevant blocks based on query
 func (s *Storage) SearchMemory(query string, maxResults int) ([]models.MemorySearchResult, error) {
+	return s.SearchMemoryWithTags(query, maxResults, nil)
+}
+
+// SearchOptions narrows a memory search; the zero value searches everything
+type SearchOptions struct {
+	Tags   []string      // Keep only blocks carrying every tag
+	Affect models.Affect // Keep only blocks with a turn of this affect (a valence like "negative" matches all its affects)
+	Origin models.Origin // Keep only blocks with a turn from this origin (set fields must match)
+}
+
+// SearchMemoryWithTags searches like SearchMemory, keeping only blocks that carry every tag
+func (s *Storage) SearchMemoryWithTags(query string, maxResults int, tags []string) ([]models.MemorySearchResult, error) {
+	return s.SearchMemoryWithOptions(query, maxResults, SearchOptions{Tags: tags})
+}
+
+// SearchMemoryWithOptions searches like SearchMemory, keeping only blocks that pass opts.
+// Results are cached by query and options until a write to the tables searched.
+func (s *Storage) SearchMemoryWithOptions(query string, maxResults int, opts SearchOptions) ([]models.MemorySearchResult, error) {
+	return s.search.results.load(s.searchKey(query, maxResults, opts), func() ([]models.MemorySearchResult, bool, error) {
+		return s.searchMemory(query, maxResults, opts)
+	})
+}
+
+// searchMemory runs a memory search; complete is false when semantic search failed and
+// the results are keyword matches only, which are not worth caching
+func (s *Storage) searchMemory(query string, maxResults int, opts SearchOptions) (results []models.MemorySearchResult, complete bool, err error) {
+	var allowed map[string]bool
+	if len(opts.Tags) > 0 {
+		normalized, err := models.NormalizeTags(opts.Tags)
+		if err != nil {
+			return nil, false, err
+		}
+		allowed, err = s.tags.BlocksWithAll(normalized)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to filter by tags: %w", err)
+		}
+		if len(allowed) == 0 {
+			return nil, true, nil
+		}
+	}
+	if opts.Affect != "" {
+		affect, err := models.ParseAffect(string(opts.Affect))
+		if err != nil {
+			return nil, false, err
+		}
+		withAffect, err := s.turns.BlocksWithAffect(models.AffectsMatching(affect))
+		if err != nil {
+			return nil, false, err
+		}
+		allowed = intersectAllowed(allowed, withAffect)
+		if len(allowed) == 0 {
+			return nil, true, nil
+		}
+	}
+	if !opts.Origin.IsZero() {
+		withOrigin, err := s.turns.BlocksWithOrigin(opts.Origin.Normalize())
+		if err != nil {
+			return nil, false, err
+		}
+		allowed = intersectAllowed(allowed, withOrigin)
+		if len(allowed) == 0 {
+			return nil, true, nil
+		}
+	}
+
 	var allResults []models.MemorySearchResult
+	complete = true
 	blockScores := make(map[string]float64)
+	blockSnippets := make(map[string][]models.Snippet)
 
 	// 1. Keyword-based search
-	keywordResults := s.keywordSearch(query, maxResults)
+	keywordResults := s.keywordSearch(query, maxResults, allowed)
 	for _, result := range keywordResults {
 		blockScores[result.BlockID] = result.RelevanceScore
 		allResults = append(allResults, result)
@@ -278,11 +737,13 @@ func (s *Storage) SearchMemory(query string, maxResults int) ([]models.MemorySea
 
 	// 2. Semantic search (if OpenAI client is available)
 	if s.openaiClient != nil {
-		semanticResults, err := s.semanticSearch(query, maxResults)
+		semanticResults, err := s.semanticSearch(query, maxResults, allowed)
 		if err != nil {
 			log.Printf("[Storage] semantic search failed: %v", err)
+			complete = false
 		} else {
 			for _, result := range semanticResults {
+				blockSnippets[result.BlockID] = result.Snippets
 				if existingScore, exists := blockScores[result.BlockID]; exists {
 					blockScores[result.BlockID] = (existingScore + result.RelevanceScore) / 2
 				} else {
@@ -298,6 +759,8 @@ func (s *Storage) SearchMemory(query string, maxResults int) ([]models.MemorySea
 		if score, exists := blockScores[allResults[i].BlockID]; exists {
 			allResults[i].RelevanceScore = score
 		}
+		// A block keyword search found keeps the snippets semantic search matched
+		allResults[i].Snippets = blockSnippets[allResults[i].BlockID]
 	}
 
 	sort.Slice(allResults, func(i, j int) bool {
@@ -318,40 +781,115 @@ func (s *Storage) SearchMemory(query string, maxResults int) ([]models.MemorySea
 		uniqueResults = uniqueResults[:maxResults]
 	}
 
-	return uniqueResults, nil
+	if err := s.attachResultTags(uniqueResults); err != nil {
+		return nil, false, err
+	}
+	if err := s.attachResultAffect(uniqueResults); err != nil {
+		return nil, false, err
+	}
+	if err := s.attachResultAttachments(uniqueResults); err != nil {
+		return nil, false, err
+	}
+
+	return uniqueResults, complete, nil
+}
+
+// intersectAllowed combines two block filters; a nil filter allows everything
+func intersectAllowed(a, b map[string]bool) map[string]bool {
+	if a == nil {
+		return b
+	}
+	both := make(map[string]bool)
+	for id := range a {
+		if b[id] {
+			both[id] = true
+		}
+	}
+	return both
 }
 
-// keywordSearch performs keyword-based search across all blocks
-func (s *Storage) keywordSearch(query string, maxResults int) []models.MemorySearchResult {
+// keywordSearch performs keyword-based search across all blocks: blocks whose keywords
+// the query's words match (exactly, or as prefixes), whose topic label contains the
+// query, or whose attachments the query names. Blocks matching more of the query's
+// words score higher. A non-nil allowed set restricts results to those block IDs.
+func (s *Storage) keywordSearch(query string, maxResults int, allowed map[string]bool) []models.MemorySearchResult {
 	var results []models.MemorySearchResult
 
-	blocks, err := s.blocks.ListAll()
+	terms := queryTerms(query)
+	matched, err := s.blocks.MatchKeywords(terms, true)
 	if err != nil {
-		return results
+		log.Printf("[Storage] failed to match keywords: %v", err)
+	}
+	labelled, err := s.blocks.MatchTopicLabels(query)
+	if err != nil {
+		log.Printf("[Storage] failed to match topic labels: %v", err)
+	}
+	// Blocks whose attachments are named in the query match too
+	attached, err := s.attachments.BlocksMatching(query)
+	if err != nil {
+		log.Printf("[Storage] failed to match attachments: %v", err)
 	}
 
-	for _, block := range blocks {
-		if matchesQuery(&block, query) {
-			results = append(results, models.MemorySearchResult{
-				BlockID:        block.BlockID,
-				TopicLabel:     block.TopicLabel,
-				RelevanceScore: 0.5,
-				Summary:        block.Summary,
-				Turns:          block.Turns,
-			})
+	candidates := make(map[string]bool, len(matched)+len(labelled)+len(attached))
+	for id := range matched {
+		candidates[id] = true
+	}
+	for _, id := range labelled {
+		candidates[id] = true
+	}
+	for id := range attached {
+		candidates[id] = true
+	}
+	ids := make([]string, 0, len(candidates))
+	for id := range candidates {
+		if allowed == nil || allowed[id] {
+			ids = append(ids, id)
 		}
+	}
+	blocks, err := s.blocks.ListByIDs(ids)
+	if err != nil {
+		log.Printf("[Storage] failed to load matching blocks: %v", err)
+		return results
+	}
 
-		if len(results) >= maxResults*2 {
-			break
-		}
+	for _, block := range blocks {
+		results = append(results, models.MemorySearchResult{
+			BlockID:        block.BlockID,
+			TopicLabel:     block.TopicLabel,
+			RelevanceScore: keywordRelevance(matched[block.BlockID], len(terms)),
+			Summary:        block.Summary,
+			Turns:          block.Turns,
+		})
 	}
 
+	// Most relevant first, most recently updated first among equals
+	sort.SliceStable(results, func(i, j int) bool { return results[i].RelevanceScore > results[j].RelevanceScore })
+	if len(results) > maxResults*2 {
+		results = results[:maxResults*2]
+	}
 	return results
 }
 
-// semanticSearch performs vector-based semantic search
-func (s *Storage) semanticSearch(query string, maxResults int) ([]models.MemorySearchResult, error) {
-	queryEmbedding, err := s.openaiClient.GenerateEmbedding(query)
+// keywordRelevance scores a keyword search match: 0.5 for any match, rising to 1 as
+// more of the query's terms match the block's keywords, prefix matches counting half
+func keywordRelevance(m KeywordMatch, terms int) float64 {
+	if terms == 0 {
+		return 0.5
+	}
+	covered := (float64(m.Exact) + float64(m.Prefix)/2) / float64(terms)
+	return 0.5 + 0.5*min(covered, 1)
+}
+
+// MatchBlockKeywords returns, for each block whose keywords match any of terms, how
+// many terms matched, ignoring case; with prefix, terms also match keywords they start
+func (s *Storage) MatchBlockKeywords(terms []string, prefix bool) (map[string]KeywordMatch, error) {
+	return s.blocks.MatchKeywords(terms, prefix)
+}
+
+// semanticSearch performs vector-based semantic search.
+// A non-nil allowed set restricts results to those block IDs.
+func (s *Storage) semanticSearch(query string, maxResults int, allowed map[string]bool) ([]models.MemorySearchResult, error) {
+	queryEmbedding, err := s.queryEmbedding(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
@@ -362,16 +900,25 @@ func (s *Storage) semanticSearch(query string, maxResults int) ([]models.MemoryS
 	}
 
 	blockScores := make(map[string]float64)
+	blockHits := make(map[string][]models.VectorSearchResult)
 	for _, vr := range vectorResults {
+		if allowed != nil && !allowed[vr.BlockID] {
+			continue
+		}
 		if existingScore, exists := blockScores[vr.BlockID]; !exists || vr.SimilarityScore > existingScore {
 			blockScores[vr.BlockID] = vr.SimilarityScore
 		}
+		blockHits[vr.BlockID] = append(blockHits[vr.BlockID], vr)
+	}
+	snippets, err := s.matchSnippets(blockHits)
+	if err != nil {
+		return nil, err
 	}
 
 	var results []models.MemorySearchResult
 	for blockID, score := range blockScores {
 		block, err := s.GetBridgeBlock(blockID)
-		if err != nil || block == nil {
+		if err != nil || block == nil || block.ExcludeFromRecall {
 			continue
 		}
 
@@ -381,23 +928,103 @@ func (s *Storage) semanticSearch(query string, maxResults int) ([]models.MemoryS
 			RelevanceScore: score,
 			Summary:        block.Summary,
 			Turns:          block.Turns,
+			Snippets:       snippets[blockID],
 		})
 	}
 
 	return results, nil
 }
 
+// maxSnippets caps the matched chunks returned with each semantic search result
+const maxSnippets = 3
+
+// snippetLen caps the text of each snippet
+const snippetLen = 280
+
+// matchSnippets turns each block's vector hits, best first, into snippets of the chunk
+// text. A chunk inside another that matched (a sentence of a matching paragraph, say)
+// stands in for it, so each snippet is the most precise match for its part of the turn.
+func (s *Storage) matchSnippets(hits map[string][]models.VectorSearchResult) (map[string][]models.Snippet, error) {
+	var ids []string
+	for _, blockHits := range hits {
+		for _, hit := range blockHits {
+			ids = append(ids, hit.ChunkID)
+		}
+	}
+	contents, err := s.chunkContents(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	snippets := make(map[string][]models.Snippet)
+	for blockID, blockHits := range hits {
+		var matched []models.Snippet
+	next:
+		for _, hit := range blockHits {
+			text := strings.TrimSpace(contents[hit.ChunkID])
+			if text == "" {
+				continue
+			}
+			for i, kept := range matched {
+				if kept.TurnID != hit.TurnID {
+					continue
+				}
+				if strings.Contains(text, kept.Text) {
+					continue next
+				}
+				if strings.Contains(kept.Text, text) {
+					matched[i] = models.Snippet{Text: text, TurnID: hit.TurnID, Score: hit.SimilarityScore}
+					continue next
+				}
+			}
+			matched = append(matched, models.Snippet{Text: text, TurnID: hit.TurnID, Score: hit.SimilarityScore})
+		}
+		if len(matched) > maxSnippets {
+			matched = matched[:maxSnippets]
+		}
+		for i := range matched {
+			if text := []rune(matched[i].Text); len(text) > snippetLen {
+				matched[i].Text = string(text[:snippetLen]) + "..."
+			}
+		}
+		if len(matched) > 0 {
+			snippets[blockID] = matched
+		}
+	}
+	return snippets, nil
+}
+
 // --- Fact operations ---
 
-// SaveFact saves a single fact
+// SetFactLimits bounds the facts an LLM extraction saves from one turn from now on
+func (s *Storage) SetFactLimits(limits models.FactLimits) {
+	s.factLimits = limits
+}
+
+// FactLimits returns the bounds on the facts saved from one extraction
+func (s *Storage) FactLimits() models.FactLimits {
+	return s.factLimits
+}
+
+// SaveFact saves a single fact. Events for a pending fact wait until it is approved.
 func (s *Storage) SaveFact(fact *models.Fact) error {
-	return s.facts.Save(fact)
+	var previous *models.Fact
+	if !fact.Pending {
+		previous = s.latestFact(fact)
+	}
+	if err := s.facts.Save(fact); err != nil {
+		return err
+	}
+	if !fact.Pending {
+		s.publishFact(fact, previous)
+	}
+	return nil
 }
 
 // SaveFacts saves a slice of facts
 func (s *Storage) SaveFacts(facts []models.Fact) error {
 	for _, fact := range facts {
-		if err := s.facts.Save(&fact); err != nil {
+		if err := s.SaveFact(&fact); err != nil {
 			return err
 		}
 	}
@@ -406,27 +1033,413 @@ func (s *Storage) SaveFacts(facts []models.Fact) error {
 
 // GetFactByKey retrieves a fact by its key (returns most recent if multiple exist)
 func (s *Storage) GetFactByKey(key string) (*models.Fact, error) {
-	return s.facts.GetByKey(key)
+	fact, err := s.facts.GetByKey(key)
+	if err != nil || fact == nil {
+		return fact, err
+	}
+	tags, err := s.tags.ForFacts([]string{fact.FactID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fact tags: %w", err)
+	}
+	fact.Tags = tags[fact.FactID]
+	return fact, nil
+}
+
+// GetFactByID retrieves one fact, superseded or not, or nil if there is none
+func (s *Storage) GetFactByID(factID string) (*models.Fact, error) {
+	fact, err := s.facts.GetByID(factID)
+	if err != nil || fact == nil {
+		return fact, err
+	}
+	tags, err := s.tags.ForFacts([]string{fact.FactID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fact tags: %w", err)
+	}
+	fact.Tags = tags[fact.FactID]
+	return fact, nil
 }
 
 // GetFactsForBlock retrieves all facts for a specific block
 func (s *Storage) GetFactsForBlock(blockID string) ([]models.Fact, error) {
-	return s.facts.GetByBlock(blockID)
+	facts, err := s.facts.GetByBlock(blockID)
+	if err != nil {
+		return nil, err
+	}
+	return facts, s.attachFactTags(facts)
 }
 
 // SearchFacts searches for facts relevant to a query string
 func (s *Storage) SearchFacts(query string, maxResults int) ([]models.Fact, error) {
-	return s.facts.Search(query, maxResults)
+	facts, err := s.facts.Search(query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+	return facts, s.attachFactTags(facts)
+}
+
+// GetNumberFacts returns number facts with values between min and max inclusive (empty key = any key)
+func (s *Storage) GetNumberFacts(key string, min, max float64) ([]models.Fact, error) {
+	facts, err := s.facts.NumberRange(key, min, max)
+	if err != nil {
+		return nil, err
+	}
+	return facts, s.attachFactTags(facts)
 }
 
-// DeleteFactByKey deletes all facts with the given key
+// GetDateFacts returns date facts with values in [from, to); zero times leave that end open
+func (s *Storage) GetDateFacts(key string, from, to time.Time) ([]models.Fact, error) {
+	facts, err := s.facts.DateRange(key, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return facts, s.attachFactTags(facts)
+}
+
+// DeleteFactByKey deletes all facts with the given key, keeping a copy in the undo log
 func (s *Storage) DeleteFactByKey(key string) (int64, error) {
-	return s.facts.DeleteByKey(key)
+	return s.deleteFacts(key, fmt.Sprintf("facts with key %q", key), "key = ?", key)
 }
 
-// DeleteFactByID deletes a specific fact by its ID
+// DeleteFactByID deletes a specific fact by its ID, keeping a copy in the undo log
 func (s *Storage) DeleteFactByID(factID string) error {
-	return s.facts.DeleteByID(factID)
+	_, err := s.deleteFacts(factID, "fact "+factID, "id = ?", factID)
+	return err
+}
+
+// DeleteTurnFacts deletes the facts extractedBy recorded from a turn, returning how
+// many; a copy is kept in the undo log
+func (s *Storage) DeleteTurnFacts(turnID, extractedBy string) (int64, error) {
+	n, err := s.deleteFacts(turnID, fmt.Sprintf("facts %s extracted from turn %s", extractedBy, turnID),
+		"turn_id = ? AND extracted_by = ?", turnID, extractedBy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete facts of turn %s: %w", turnID, err)
+	}
+	return n, nil
+}
+
+// deleteFacts deletes the facts matching where, recording them with their tags and
+// ratings in the undo log, and returns how many it deleted
+func (s *Storage) deleteFacts(target, summary, where string, args ...interface{}) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	factIDs := "fact_id IN (SELECT id FROM facts WHERE " + where + ")"
+	if err := s.recordUndo(tx, UndoDeleteFacts, target, summary,
+		undoCapture{table: "facts", where: where, args: args},
+		undoCapture{table: "fact_tags", where: factIDs, args: args},
+		undoCapture{table: "memory_feedback", where: factIDs, args: args},
+	); err != nil {
+		return 0, err
+	}
+	result, err := tx.Exec("DELETE FROM facts WHERE "+where, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := result.RowsAffected()
+	return n, tx.Commit()
+}
+
+// --- Tag operations ---
+
+// TagBlock adds and removes tags on a block and returns its resulting tags
+func (s *Storage) TagBlock(blockID string, add, remove []string) ([]string, error) {
+	add, remove, err := normalizeTagEdits(add, remove)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	block, err := s.blocks.Get(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block: %w", err)
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", blockID)
+	}
+	if err := s.tags.AddToBlock(blockID, add); err != nil {
+		return nil, fmt.Errorf("failed to add block tags: %w", err)
+	}
+	if err := s.tags.RemoveFromBlock(blockID, remove); err != nil {
+		return nil, fmt.Errorf("failed to remove block tags: %w", err)
+	}
+
+	tags, err := s.tags.ForBlocks([]string{blockID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block tags: %w", err)
+	}
+	return tags[blockID], nil
+}
+
+// TagFact adds and removes tags on a fact and returns its resulting tags
+func (s *Storage) TagFact(factID string, add, remove []string) ([]string, error) {
+	add, remove, err := normalizeTagEdits(add, remove)
+	if err != nil {
+		return nil, err
+	}
+
+	fact, err := s.facts.GetByID(factID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fact: %w", err)
+	}
+	if fact == nil {
+		return nil, fmt.Errorf("fact %s not found", factID)
+	}
+	if err := s.tags.AddToFact(factID, add); err != nil {
+		return nil, fmt.Errorf("failed to add fact tags: %w", err)
+	}
+	if err := s.tags.RemoveFromFact(factID, remove); err != nil {
+		return nil, fmt.Errorf("failed to remove fact tags: %w", err)
+	}
+
+	tags, err := s.tags.ForFacts([]string{factID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fact tags: %w", err)
+	}
+	return tags[factID], nil
+}
+
+// ListTags returns every tag in use with its block and fact counts
+func (s *Storage) ListTags() ([]TagCount, error) {
+	return s.tags.List()
+}
+
+// attachResultTags fills in the tags of each search result's block
+func (s *Storage) attachResultTags(results []models.MemorySearchResult) error {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.BlockID
+	}
+	tags, err := s.tags.ForBlocks(ids)
+	if err != nil {
+		return fmt.Errorf("failed to get block tags: %w", err)
+	}
+	for i := range results {
+		results[i].Tags = tags[results[i].BlockID]
+	}
+	return nil
+}
+
+// attachResultAffect fills in the dominant affect of each result's block
+func (s *Storage) attachResultAffect(results []models.MemorySearchResult) error {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.BlockID
+	}
+	counts, err := s.turns.AffectCounts(ids)
+	if err != nil {
+		return err
+	}
+	for i := range results {
+		results[i].Affect = models.DominantAffect(counts[results[i].BlockID])
+	}
+	return nil
+}
+
+// attachmentPreviewLen caps the attachment content returned with search results
+const attachmentPreviewLen = 280
+
+// attachResultAttachments fills in each result's attachments, trimming content to a preview
+func (s *Storage) attachResultAttachments(results []models.MemorySearchResult) error {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.BlockID
+	}
+	byBlock, err := s.attachments.ForBlocks(ids)
+	if err != nil {
+		return err
+	}
+	for i := range results {
+		attachments := byBlock[results[i].BlockID]
+		for j := range attachments {
+			if content := []rune(attachments[j].Content); len(content) > attachmentPreviewLen {
+				attachments[j].Content = string(content[:attachmentPreviewLen]) + "..."
+			}
+		}
+		results[i].Attachments = attachments
+	}
+	return nil
+}
+
+// attachFactTags fills in the tags of each fact
+func (s *Storage) attachFactTags(facts []models.Fact) error {
+	ids := make([]string, len(facts))
+	for i, f := range facts {
+		ids[i] = f.FactID
+	}
+	tags, err := s.tags.ForFacts(ids)
+	if err != nil {
+		return fmt.Errorf("failed to get fact tags: %w", err)
+	}
+	for i := range facts {
+		facts[i].Tags = tags[facts[i].FactID]
+	}
+	return nil
+}
+
+// normalizeTagEdits validates tags to add and remove, requiring at least one
+func normalizeTagEdits(add, remove []string) ([]string, []string, error) {
+	if len(add) == 0 && len(remove) == 0 {
+		return nil, nil, fmt.Errorf("no tags to add or remove")
+	}
+	add, err := models.NormalizeTags(add)
+	if err != nil {
+		return nil, nil, err
+	}
+	remove, err = models.NormalizeTags(remove)
+	if err != nil {
+		return nil, nil, err
+	}
+	return add, remove, nil
+}
+
+// --- Relation operations ---
+
+// RelateBlocks records that from <relation> to, e.g. a block references another
+func (s *Storage) RelateBlocks(from, to string, relation models.RelationType) error {
+	if _, err := models.ParseRelationType(string(relation)); err != nil {
+		return err
+	}
+	if from == to {
+		return fmt.Errorf("cannot relate block %s to itself", from)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range []string{from, to} {
+		block, err := s.blocks.Get(id)
+		if err != nil {
+			return fmt.Errorf("failed to get block: %w", err)
+		}
+		if block == nil {
+			return fmt.Errorf("block %s not found", id)
+		}
+	}
+	return s.relations.Add(from, to, relation)
+}
+
+// UnrelateBlocks removes a relation, reporting whether it existed
+func (s *Storage) UnrelateBlocks(from, to string, relation models.RelationType) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.relations.Remove(from, to, relation)
+}
+
+// GetRelatedTopics returns the blocks linked to blockID in either direction
+func (s *Storage) GetRelatedTopics(blockID string) ([]models.RelatedTopic, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	block, err := s.blocks.Get(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block: %w", err)
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", blockID)
+	}
+	return s.relations.Related(blockID)
+}
+
+// --- Attachment operations ---
+
+// AttachToTurn stores a on an existing turn. When embeddings are configured the
+// attachment's content is chunked and embedded into the turn's block (keyed by
+// attachment ID), so semantic search finds the block through it.
+func (s *Storage) AttachToTurn(turnID string, a *models.Attachment) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blockID, err := s.turns.BlockOf(turnID)
+	if err != nil {
+		return fmt.Errorf("failed to get turn: %w", err)
+	}
+	if blockID == "" {
+		return fmt.Errorf("turn %s not found", turnID)
+	}
+
+	a.TurnID = turnID
+	a.BlockID = blockID
+	if err := s.attachments.Save(a); err != nil {
+		return err
+	}
+
+	if a.Content != "" && s.openaiClient != nil && s.chunkEngine != nil {
+		if err := s.embedText(context.Background(), a.Content, a.AttachmentID, blockID); err != nil {
+			log.Printf("[Storage] failed to embed attachment %s: %v", a.AttachmentID, err)
+		}
+	}
+	return nil
+}
+
+// GetAttachment returns an attachment by ID, or nil if it does not exist
+func (s *Storage) GetAttachment(attachmentID string) (*models.Attachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.attachments.Get(attachmentID)
+}
+
+// GetBlockAttachments returns the attachments on a block's turns, oldest first
+func (s *Storage) GetBlockAttachments(blockID string) ([]models.Attachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	byBlock, err := s.attachments.ForBlocks([]string{blockID})
+	if err != nil {
+		return nil, err
+	}
+	return byBlock[blockID], nil
+}
+
+// DeleteAttachment removes an attachment, its embeddings, and their chunks, reporting
+// whether it existed; a copy is kept in the undo log
+func (s *Storage) DeleteAttachment(attachmentID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := s.recordUndo(tx, UndoDeleteAttachment, attachmentID, "attachment "+attachmentID,
+		undoCapture{table: "attachments", where: "id = ?", args: []interface{}{attachmentID}},
+		undoCapture{table: "embeddings", where: "turn_id = ?", args: []interface{}{attachmentID}},
+		undoCapture{table: "chunks", where: "turn_id = ?", args: []interface{}{attachmentID}},
+	); err != nil {
+		return false, err
+	}
+
+	var blockID string
+	if err := tx.QueryRow("SELECT COALESCE((SELECT block_id FROM embeddings WHERE turn_id = ? LIMIT 1), '')", attachmentID).Scan(&blockID); err != nil {
+		return false, fmt.Errorf("failed to read attachment embeddings: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM embeddings WHERE turn_id = ?", attachmentID); err != nil {
+		return false, fmt.Errorf("failed to delete attachment embeddings: %w", err)
+	}
+	if err := deleteChunks(tx, attachmentID); err != nil {
+		return false, err
+	}
+	result, err := tx.Exec("DELETE FROM attachments WHERE id = ?", attachmentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return n > 0, refreshCentroids(s.db, blockID)
 }
 
 // --- Profile operations ---
@@ -438,8 +1451,128 @@ func (s *Storage) GetUserProfile() (*models.UserProfile, error) {
 
 // SaveUserProfile saves the user profile
 func (s *Storage) SaveUserProfile(profile *models.UserProfile) error {
-	profile.LastUpdated = time.Now()
-	return s.profile.Save(profile)
+	return s.SaveLearnedProfile(profile, nil)
+}
+
+// SaveLearnedProfile saves the user profile, recording the turns the Scribe learned
+// it from so GetProfileDiff can show what triggered each change
+func (s *Storage) SaveLearnedProfile(profile *models.UserProfile, turnIDs []string) error {
+	profile.LastUpdated = s.db.now()
+	if err := s.profile.SaveLearned(profile, turnIDs); err != nil {
+		return err
+	}
+	s.publishProfile("")
+	return nil
+}
+
+// GetUserProfileAs loads the user profile with the named persona's preferences and
+// constraints layered on top. An empty persona is the same as GetUserProfile; a persona
+// that does not exist yet adds nothing.
+func (s *Storage) GetUserProfileAs(persona string) (*models.UserProfile, error) {
+	profile, err := s.profile.Get()
+	if err != nil || persona == "" {
+		return profile, err
+	}
+	p, err := s.GetPersona(persona)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		profile = &models.UserProfile{Preferences: []string{}, TopicsOfInterest: []string{}, Constraints: []models.ProfileConstraint{}}
+	}
+	view := profile.WithPersona(p)
+	if view.Persona == "" {
+		view.Persona, _ = models.NormalizePersonaName(persona)
+	}
+	return view, nil
+}
+
+// GetPersona loads a persona by name, returning nil if it does not exist
+func (s *Storage) GetPersona(name string) (*models.Persona, error) {
+	name, err := models.NormalizePersonaName(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.profile.GetPersona(name)
+}
+
+// SavePersona saves a persona, normalizing its name
+func (s *Storage) SavePersona(persona *models.Persona) error {
+	return s.SaveLearnedPersona(persona, nil)
+}
+
+// SaveLearnedPersona saves a persona like SavePersona, recording the turns the Scribe
+// learned it from
+func (s *Storage) SaveLearnedPersona(persona *models.Persona, turnIDs []string) error {
+	name, err := models.NormalizePersonaName(persona.Name)
+	if err != nil {
+		return err
+	}
+	persona.Name = name
+	persona.LastUpdated = s.db.now()
+	if err := s.profile.SaveLearnedPersona(persona, turnIDs); err != nil {
+		return err
+	}
+	s.publishProfile(name)
+	return nil
+}
+
+// ListPersonas returns every persona ordered by name
+func (s *Storage) ListPersonas() ([]*models.Persona, error) {
+	return s.profile.ListPersonas()
+}
+
+// DeletePersona removes a persona, reporting whether it existed; a copy is kept in the
+// undo log
+func (s *Storage) DeletePersona(name string) (bool, error) {
+	name, err := models.NormalizePersonaName(name)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := s.profile.deletePersona(name, func(tx *Tx) error {
+		return s.recordUndo(tx, UndoDeletePersona, name, "persona "+name,
+			undoCapture{table: "profile_personas", where: "name = ?", args: []interface{}{name}},
+			undoCapture{table: "profile_items", where: "persona = ?", args: []interface{}{name}},
+		)
+	})
+	if deleted && err == nil {
+		s.publishProfile(name)
+	}
+	return deleted, err
+}
+
+// --- LLM usage operations ---
+
+// RecordLLMUsage records token usage for a single LLM call
+func (s *Storage) RecordLLMUsage(operation, model string, promptTokens, completionTokens int) error {
+	return s.usage.Record(operation, model, promptTokens, completionTokens)
+}
+
+// GetLLMUsage returns LLM usage aggregated by operation and model
+func (s *Storage) GetLLMUsage() ([]LLMUsage, error) {
+	return s.usage.Summary()
+}
+
+// --- Feature usage operations ---
+
+// RecordFeatureUse counts one use of a command or tool today (UTC)
+func (s *Storage) RecordFeatureUse(feature string) error {
+	return s.features.Record(feature, s.db.now().UTC().Format("2006-01-02"))
+}
+
+// GetFeatureUsage returns all-time use counts per feature
+func (s *Storage) GetFeatureUsage() ([]FeatureUsage, error) {
+	return s.features.Summary()
+}
+
+// UnreportedFeatureUsage returns daily counts not yet reported for days before the given day
+func (s *Storage) UnreportedFeatureUsage(beforeDay string) ([]FeatureDay, error) {
+	return s.features.Unreported(beforeDay)
+}
+
+// MarkFeatureUsageReported flags daily counts for days before the given day as reported
+func (s *Storage) MarkFeatureUsageReported(beforeDay string) error {
+	return s.features.MarkReported(beforeDay)
 }
 
 // --- Embedding operations ---
@@ -490,13 +1623,3 @@ func inferTopicLabel(turn *models.Turn) string {
 	}
 	return "General Discussion"
 }
-
-func matchesQuery(block *models.BridgeBlock, query string) bool {
-	queryLower := strings.ToLower(query)
-	for _, keyword := range block.Keywords {
-		if strings.Contains(queryLower, strings.ToLower(keyword)) {
-			return true
-		}
-	}
-	return strings.Contains(strings.ToLower(block.TopicLabel), queryLower)
-}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -739,56 +741,37 @@ func TestInferTopicLabel(t *testing.T) {
 	}
 }
 
-func TestMatchesQuery(t *testing.T) {
+func TestMatchTopicLabels(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
 	tests := []struct {
 		name   string
-		block  *models.BridgeBlock
+		label  string
 		query  string
 		expect bool
 	}{
-		{
-			name: "matches keyword",
-			block: &models.BridgeBlock{
-				Keywords:   []string{"go", "programming"},
-				TopicLabel: "development",
-			},
-			query:  "go",
-			expect: true,
-		},
-		{
-			name: "matches topic label",
-			block: &models.BridgeBlock{
-				Keywords:   []string{"test"},
-				TopicLabel: "programming",
-			},
-			query:  "programming",
-			expect: true,
-		},
-		{
-			name: "case insensitive",
-			block: &models.BridgeBlock{
-				Keywords:   []string{"GO"},
-				TopicLabel: "test",
-			},
-			query:  "go",
-			expect: true,
-		},
-		{
-			name: "no match",
-			block: &models.BridgeBlock{
-				Keywords:   []string{"python"},
-				TopicLabel: "scripting",
-			},
-			query:  "java",
-			expect: false,
-		},
+		{"matches topic label", "programming", "programming", true},
+		{"case insensitive", "Go Development", "go dev", true},
+		{"case insensitive beyond ASCII", "Über Café", "über café", true},
+		{"no match", "scripting", "java", false},
 	}
 
-	for _, tt := range tests {
+	for i, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := matchesQuery(tt.block, tt.query)
-			if result != tt.expect {
-				t.Errorf("matchesQuery() = %v, want %v", result, tt.expect)
+			blockID := fmt.Sprintf("block_label_%d", i)
+			if err := store.blocks.Save(&models.BridgeBlock{BlockID: blockID, DayID: "2026-01-01", TopicLabel: tt.label, Status: models.StatusPaused}); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			ids, err := store.blocks.MatchTopicLabels(tt.query)
+			if err != nil {
+				t.Fatalf("MatchTopicLabels() error = %v", err)
+			}
+			if result := slices.Contains(ids, blockID); result != tt.expect {
+				t.Errorf("MatchTopicLabels(%q) includes %q = %v, want %v", tt.query, tt.label, result, tt.expect)
 			}
 		})
 	}
@@ -938,7 +921,7 @@ func TestKeywordSearch(t *testing.T) {
 	}
 
 	// Test keyword search (internal function)
-	results := store.keywordSearch("testing", 10)
+	results := store.keywordSearch("testing", 10, nil)
 	if len(results) == 0 {
 		t.Error("keywordSearch() should find block with matching keyword")
 	}
@@ -1027,3 +1010,108 @@ func TestNewStorageWithPath_NestedDir(t *testing.T) {
 		t.Error("Database file in nested dir was not created")
 	}
 }
+
+func TestSetBlockIDGenerator(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	n := 0
+	store.SetBlockIDGenerator(func() string {
+		n++
+		return fmt.Sprintf("block_fixed_%d", n)
+	})
+
+	blockID, err := store.StoreTurn(&models.Turn{
+		TurnID:      "turn_fixed",
+		Timestamp:   time.Now(),
+		UserMessage: "Deterministic IDs",
+		Topics:      []string{"test"},
+	})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if blockID != "block_fixed_1" {
+		t.Errorf("blockID = %q, want block_fixed_1", blockID)
+	}
+}
+
+func TestNewStorageReadOnly(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+
+	if _, err := NewStorageReadOnly(dbPath); err == nil {
+		t.Fatal("NewStorageReadOnly() on a missing database should fail")
+	}
+
+	store, err := NewStorageWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewStorageWithPath() error = %v", err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_ro", Key: "city", Value: "Chicago", Confidence: 1.0, CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+	_ = store.Close()
+
+	ro, err := NewStorageReadOnly(dbPath)
+	if err != nil {
+		t.Fatalf("NewStorageReadOnly() error = %v", err)
+	}
+	defer func() { _ = ro.Close() }()
+
+	if !ro.ReadOnly() {
+		t.Error("ReadOnly() = false, want true")
+	}
+	fact, err := ro.GetFactByKey("city")
+	if err != nil || fact == nil || fact.Value != "Chicago" {
+		t.Fatalf("GetFactByKey() = %v, %v; want Chicago", fact, err)
+	}
+
+	if err := ro.SaveFact(&models.Fact{FactID: "fact_ro2", Key: "pet", Value: "cat", Confidence: 1.0, CreatedAt: time.Now()}); err == nil {
+		t.Error("SaveFact() on read-only storage should fail")
+	}
+	if _, err := ro.DeleteFactByKey("city"); err == nil {
+		t.Error("DeleteFactByKey() on read-only storage should fail")
+	}
+	if _, err := ro.StoreTurn(&models.Turn{TurnID: "turn_ro", UserMessage: "hi", Timestamp: time.Now()}); err == nil {
+		t.Error("StoreTurn() on read-only storage should fail")
+	}
+}
+
+func TestStoreTurn_DayIDUsesLocationAndStoresUTC(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// UTC+14: the local date is usually ahead of UTC's
+	loc, err := time.LoadLocation("Pacific/Kiritimati")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	store.SetLocation(loc)
+
+	now := time.Now().In(loc)
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_tz", UserMessage: "hello", Timestamp: now})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	block, err := store.GetBridgeBlock(blockID)
+	if err != nil {
+		t.Fatalf("GetBridgeBlock() error = %v", err)
+	}
+	if want := time.Now().In(loc).Format("2006-01-02"); block.DayID != want {
+		t.Errorf("DayID = %s, want %s (date in the configured zone)", block.DayID, want)
+	}
+
+	var raw string
+	if err := store.db.QueryRow("SELECT created_at FROM turns WHERE id = ?", "turn_tz").Scan(&raw); err != nil {
+		t.Fatalf("reading raw timestamp: %v", err)
+	}
+	if !strings.HasSuffix(raw, "Z") {
+		t.Errorf("stored timestamp %q should be UTC", raw)
+	}
+}
@@ -0,0 +1,187 @@
+// ABOUTME: Tag storage for blocks and facts
+// ABOUTME: Keeps tags in normalized block_tags and fact_tags tables and answers tag filters
+package sqlite
+
+import (
+	"strings"
+)
+
+// TagCount is one tag with the number of blocks and facts carrying it
+type TagCount struct {
+	Tag    string `json:"tag"`
+	Blocks int    `json:"blocks"`
+	Facts  int    `json:"facts"`
+}
+
+// tagTable names a tag table and the column holding the tagged item's ID
+type tagTable struct {
+	name   string
+	column string
+}
+
+var (
+	blockTagTable = tagTable{name: "block_tags", column: "block_id"}
+	factTagTable  = tagTable{name: "fact_tags", column: "fact_id"}
+)
+
+// TagStore handles tag persistence
+type TagStore struct {
+	db *DB
+}
+
+// NewTagStore creates a new TagStore
+func NewTagStore(db *DB) *TagStore {
+	return &TagStore{db: db}
+}
+
+// AddToBlock tags a block; tags it already has are ignored
+func (s *TagStore) AddToBlock(blockID string, tags []string) error {
+	return s.add(blockTagTable, blockID, tags)
+}
+
+// RemoveFromBlock removes tags from a block
+func (s *TagStore) RemoveFromBlock(blockID string, tags []string) error {
+	return s.remove(blockTagTable, blockID, tags)
+}
+
+// AddToFact tags a fact; tags it already has are ignored
+func (s *TagStore) AddToFact(factID string, tags []string) error {
+	return s.add(factTagTable, factID, tags)
+}
+
+// RemoveFromFact removes tags from a fact
+func (s *TagStore) RemoveFromFact(factID string, tags []string) error {
+	return s.remove(factTagTable, factID, tags)
+}
+
+// ForBlocks returns the sorted tags of each block that has any
+func (s *TagStore) ForBlocks(blockIDs []string) (map[string][]string, error) {
+	return s.forItems(blockTagTable, blockIDs)
+}
+
+// ForFacts returns the sorted tags of each fact that has any
+func (s *TagStore) ForFacts(factIDs []string) (map[string][]string, error) {
+	return s.forItems(factTagTable, factIDs)
+}
+
+// BlocksWithAll returns the IDs of blocks carrying every one of tags
+func (s *TagStore) BlocksWithAll(tags []string) (map[string]bool, error) {
+	return s.withAll(blockTagTable, tags)
+}
+
+// FactsWithAll returns the IDs of facts carrying every one of tags
+func (s *TagStore) FactsWithAll(tags []string) (map[string]bool, error) {
+	return s.withAll(factTagTable, tags)
+}
+
+// List returns every tag in use with its block and fact counts, alphabetically
+func (s *TagStore) List() ([]TagCount, error) {
+	rows, err := s.db.Query(`
+		SELECT tag, SUM(is_block), SUM(1 - is_block)
+		FROM (
+			SELECT tag, 1 AS is_block FROM block_tags
+			UNION ALL
+			SELECT tag, 0 AS is_block FROM fact_tags
+		)
+		GROUP BY tag
+		ORDER BY tag ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var counts []TagCount
+	for rows.Next() {
+		var c TagCount
+		if err := rows.Scan(&c.Tag, &c.Blocks, &c.Facts); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+func (s *TagStore) add(table tagTable, id string, tags []string) error {
+	for _, tag := range tags {
+		_, err := s.db.Exec(`INSERT OR IGNORE INTO `+table.name+` (`+table.column+`, tag) VALUES (?, ?)`, id, tag)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TagStore) remove(table tagTable, id string, tags []string) error {
+	for _, tag := range tags {
+		_, err := s.db.Exec(`DELETE FROM `+table.name+` WHERE `+table.column+` = ? AND tag = ?`, id, tag)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TagStore) forItems(table tagTable, ids []string) (map[string][]string, error) {
+	tags := make(map[string][]string)
+	if len(ids) == 0 {
+		return tags, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := s.db.Query(`
+		SELECT `+table.column+`, tag FROM `+table.name+`
+		WHERE `+table.column+` IN (`+placeholders(len(ids))+`)
+		ORDER BY tag ASC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var id, tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, err
+		}
+		tags[id] = append(tags[id], tag)
+	}
+	return tags, rows.Err()
+}
+
+func (s *TagStore) withAll(table tagTable, tags []string) (map[string]bool, error) {
+	args := make([]interface{}, 0, len(tags)+1)
+	for _, tag := range tags {
+		args = append(args, tag)
+	}
+	args = append(args, len(tags))
+
+	rows, err := s.db.Query(`
+		SELECT `+table.column+` FROM `+table.name+`
+		WHERE tag IN (`+placeholders(len(tags))+`)
+		GROUP BY `+table.column+`
+		HAVING COUNT(DISTINCT tag) = ?
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// placeholders returns n comma-separated SQL parameter markers
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
@@ -0,0 +1,155 @@
+// ABOUTME: Tests for tags on blocks and facts
+// ABOUTME: Verifies tagging, tag filters in search and export, and cascade on delete
+package sqlite
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// newTaggedStorage returns storage with two blocks, each holding one fact
+func newTaggedStorage(t *testing.T) (*Storage, string, string) {
+	t.Helper()
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	var ids []string
+	for _, topic := range []string{"launch", "garden"} {
+		turn := &models.Turn{
+			TurnID:      "turn_" + topic,
+			UserMessage: "talking about the " + topic,
+			Keywords:    []string{topic},
+			Topics:      []string{topic},
+		}
+		blockID, err := store.StoreTurn(turn)
+		if err != nil {
+			t.Fatalf("StoreTurn() error = %v", err)
+		}
+		fact, _ := models.NewFact(blockID, turn.TurnID, topic+"_note", "about "+topic, 1.0)
+		if err := store.SaveFact(fact); err != nil {
+			t.Fatalf("SaveFact() error = %v", err)
+		}
+		ids = append(ids, blockID)
+	}
+	return store, ids[0], ids[1]
+}
+
+func TestTagBlock_AddRemoveAndRead(t *testing.T) {
+	store, launch, _ := newTaggedStorage(t)
+
+	tags, err := store.TagBlock(launch, []string{"+Project-X", "urgent", "urgent"}, nil)
+	if err != nil {
+		t.Fatalf("TagBlock() error = %v", err)
+	}
+	if want := []string{"project-x", "urgent"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("TagBlock() = %v, want %v", tags, want)
+	}
+
+	tags, err = store.TagBlock(launch, nil, []string{"urgent"})
+	if err != nil {
+		t.Fatalf("TagBlock() remove error = %v", err)
+	}
+	if want := []string{"project-x"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("TagBlock() after remove = %v, want %v", tags, want)
+	}
+
+	block, _ := store.GetBridgeBlock(launch)
+	if !reflect.DeepEqual(block.Tags, []string{"project-x"}) {
+		t.Errorf("GetBridgeBlock().Tags = %v, want [project-x]", block.Tags)
+	}
+
+	if _, err := store.TagBlock("block_missing", []string{"x"}, nil); err == nil {
+		t.Error("TagBlock() on a missing block should fail")
+	}
+	if _, err := store.TagBlock(launch, []string{"not valid"}, nil); err == nil {
+		t.Error("TagBlock() with an invalid tag should fail")
+	}
+}
+
+func TestTagFact(t *testing.T) {
+	store, _, _ := newTaggedStorage(t)
+
+	fact, _ := store.GetFactByKey("garden_note")
+	if _, err := store.TagFact(fact.FactID, []string{"home"}, nil); err != nil {
+		t.Fatalf("TagFact() error = %v", err)
+	}
+
+	fact, _ = store.GetFactByKey("garden_note")
+	if !reflect.DeepEqual(fact.Tags, []string{"home"}) {
+		t.Errorf("GetFactByKey().Tags = %v, want [home]", fact.Tags)
+	}
+
+	counts, err := store.ListTags()
+	if err != nil {
+		t.Fatalf("ListTags() error = %v", err)
+	}
+	if len(counts) != 1 || counts[0] != (TagCount{Tag: "home", Blocks: 0, Facts: 1}) {
+		t.Errorf("ListTags() = %+v, want home on one fact", counts)
+	}
+}
+
+func TestSearchMemoryWithTags(t *testing.T) {
+	store, launch, garden := newTaggedStorage(t)
+	_, _ = store.TagBlock(garden, []string{"home"}, nil)
+
+	results, err := store.SearchMemoryWithTags("talking about the launch and the garden", 10, []string{"home"})
+	if err != nil {
+		t.Fatalf("SearchMemoryWithTags() error = %v", err)
+	}
+	if len(results) != 1 || results[0].BlockID != garden {
+		t.Fatalf("SearchMemoryWithTags() = %+v, want only the garden block", results)
+	}
+	if !reflect.DeepEqual(results[0].Tags, []string{"home"}) {
+		t.Errorf("result tags = %v, want [home]", results[0].Tags)
+	}
+
+	all, _ := store.SearchMemory("talking about the launch and the garden", 10)
+	if len(all) != 2 {
+		t.Errorf("SearchMemory() without tags = %d results, want 2 (launch %s and garden)", len(all), launch)
+	}
+}
+
+func TestExportWithOptions_Tags(t *testing.T) {
+	store, launch, garden := newTaggedStorage(t)
+	_, _ = store.TagBlock(launch, []string{"project-x"}, nil)
+	gardenFact, _ := store.GetFactByKey("garden_note")
+	_, _ = store.TagFact(gardenFact.FactID, []string{"project-x"}, nil)
+
+	data, err := store.ExportWithOptions(ExportOptions{Tags: []string{"project-x"}})
+	if err != nil {
+		t.Fatalf("ExportWithOptions() error = %v", err)
+	}
+	if len(data.Blocks) != 1 || data.Blocks[0].BlockID != launch {
+		t.Errorf("exported blocks = %+v, want only %s", data.Blocks, launch)
+	}
+	// The launch block's fact comes with its block; the garden fact is tagged itself
+	if len(data.Facts) != 2 {
+		t.Errorf("exported %d facts, want 2", len(data.Facts))
+	}
+	if !reflect.DeepEqual(data.Tags, []string{"project-x"}) {
+		t.Errorf("export tags = %v, want [project-x]", data.Tags)
+	}
+
+	full, _ := store.Export()
+	if len(full.Blocks) != 2 {
+		t.Errorf("Export() without tags = %d blocks, want 2 (including %s)", len(full.Blocks), garden)
+	}
+}
+
+func TestTags_CascadeOnDelete(t *testing.T) {
+	store, launch, _ := newTaggedStorage(t)
+	_, _ = store.TagBlock(launch, []string{"project-x"}, nil)
+
+	if err := store.DeleteBridgeBlock(launch); err != nil {
+		t.Fatalf("DeleteBridgeBlock() error = %v", err)
+	}
+	counts, _ := store.ListTags()
+	if len(counts) != 0 {
+		t.Errorf("ListTags() after delete = %+v, want none", counts)
+	}
+}
@@ -0,0 +1,163 @@
+// ABOUTME: Full data takeout into a directory
+// ABOUTME: Writes the YAML export, embeddings JSON, and attachment contents, with a checksummed manifest
+package sqlite
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Takeout file names inside the output directory
+const (
+	TakeoutManifestFile   = "manifest.json"
+	TakeoutExportFile     = "memory.yaml"
+	TakeoutEmbeddingsFile = "embeddings.json"
+	TakeoutAttachmentsDir = "attachments"
+)
+
+// TakeoutCounts is how many of each kind of record the takeout holds
+type TakeoutCounts struct {
+	Blocks      int `json:"blocks"`
+	Turns       int `json:"turns"`
+	Facts       int `json:"facts"`
+	Embeddings  int `json:"embeddings"`
+	Attachments int `json:"attachments"`
+}
+
+// TakeoutFile is one file in a takeout, relative to its directory
+type TakeoutFile struct {
+	Path   string `json:"path"`
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// TakeoutManifest describes a takeout; it is written to manifest.json
+type TakeoutManifest struct {
+	Tool            string        `json:"tool"`
+	CreatedAt       string        `json:"created_at"`
+	SchemaVersion   int           `json:"schema_version"`
+	SecretsRevealed bool          `json:"secrets_revealed"`
+	Counts          TakeoutCounts `json:"counts"`
+	Files           []TakeoutFile `json:"files"`
+}
+
+// Takeout writes everything in the database to dir, which must be empty or not
+// exist: the YAML export, embeddings JSON, and each attachment's stored content
+// under attachments/<attachment_id>/. Secret facts are masked unless revealSecrets is set.
+func (s *Storage) Takeout(dir string, revealSecrets bool) (*TakeoutManifest, error) {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("takeout directory %s is not empty", dir)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create takeout directory: %w", err)
+	}
+
+	version, err := s.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	manifest := &TakeoutManifest{
+		Tool:            "memory",
+		CreatedAt:       s.db.now().In(s.location()).Format(time.RFC3339),
+		SchemaVersion:   version,
+		SecretsRevealed: revealSecrets,
+	}
+
+	if err := s.ExportToYAML(filepath.Join(dir, TakeoutExportFile), ExportOptions{RevealSecrets: revealSecrets}); err != nil {
+		return nil, err
+	}
+	if err := s.ExportEmbeddingsToJSON(filepath.Join(dir, TakeoutEmbeddingsFile)); err != nil {
+		return nil, err
+	}
+	if err := s.takeoutAttachments(dir); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM bridge_blocks), (SELECT COUNT(*) FROM turns),
+			(SELECT COUNT(*) FROM facts), (SELECT COUNT(*) FROM embeddings),
+			(SELECT COUNT(*) FROM attachments)
+	`).Scan(&manifest.Counts.Blocks, &manifest.Counts.Turns, &manifest.Counts.Facts,
+		&manifest.Counts.Embeddings, &manifest.Counts.Attachments); err != nil {
+		return nil, fmt.Errorf("failed to count records: %w", err)
+	}
+
+	if manifest.Files, err = checksumFiles(dir); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, TakeoutManifestFile), append(data, '\n'), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// takeoutAttachments writes the stored content of each attachment; attachments
+// that only reference a file or URL are listed in the YAML export alone
+func (s *Storage) takeoutAttachments(dir string) error {
+	rows, err := s.db.Query("SELECT id, name, content FROM attachments WHERE content IS NOT NULL ORDER BY id")
+	if err != nil {
+		return fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var id, name, content string
+		if err := rows.Scan(&id, &name, &content); err != nil {
+			return fmt.Errorf("failed to read attachment: %w", err)
+		}
+		// Names come from clients, so keep only the last element
+		name = filepath.Base(filepath.Clean("/" + name))
+		if name == "/" || name == "." {
+			name = "content"
+		}
+		attachmentDir := filepath.Join(dir, TakeoutAttachmentsDir, filepath.Base(id))
+		if err := os.MkdirAll(attachmentDir, 0700); err != nil {
+			return fmt.Errorf("failed to create attachment directory: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(attachmentDir, name), []byte(content), 0600); err != nil {
+			return fmt.Errorf("failed to write attachment %s: %w", id, err)
+		}
+	}
+	return rows.Err()
+}
+
+// checksumFiles lists every file under dir with its size and SHA-256, in lexical order
+func checksumFiles(dir string) ([]TakeoutFile, error) {
+	var files []TakeoutFile
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(path) // #nosec G304 -- path is inside the takeout directory
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+
+		hash := sha256.New()
+		n, err := io.Copy(hash, file)
+		if err != nil {
+			return err
+		}
+		files = append(files, TakeoutFile{Path: filepath.ToSlash(rel), Bytes: n, SHA256: hex.EncodeToString(hash.Sum(nil))})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum takeout: %w", err)
+	}
+	return files, nil
+}
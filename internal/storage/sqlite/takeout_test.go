@@ -0,0 +1,93 @@
+// ABOUTME: Tests for data takeout
+// ABOUTME: Verifies the files written, attachment contents, manifest checksums, and secret masking
+
+package sqlite
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestTakeout(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_takeout", Timestamp: time.Now(), UserMessage: "notes attached"}); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	attachment, err := models.NewAttachment("", "../../notes.md", "# Notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AttachToTurn("turn_takeout", attachment); err != nil {
+		t.Fatalf("AttachToTurn() error = %v", err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_token", Key: "github_token", Value: "tok-123456", ValueType: models.ValueTypeSecret, Confidence: 1}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "takeout")
+	manifest, err := store.Takeout(dir, false)
+	if err != nil {
+		t.Fatalf("Takeout() error = %v", err)
+	}
+	if manifest.Counts.Blocks != 1 || manifest.Counts.Turns != 1 || manifest.Counts.Facts != 1 || manifest.Counts.Attachments != 1 {
+		t.Errorf("Counts = %+v, want one block, turn, fact, and attachment", manifest.Counts)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, TakeoutAttachmentsDir, attachment.AttachmentID, "notes.md"))
+	if err != nil || string(content) != "# Notes" {
+		t.Errorf("attachment file = %q, %v; want its content under its own directory", content, err)
+	}
+	export, err := os.ReadFile(filepath.Join(dir, TakeoutExportFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(export), "tok-123456") || !strings.Contains(string(export), SecretMask) {
+		t.Error("memory.yaml holds the secret value, want it masked")
+	}
+
+	var written TakeoutManifest
+	data, err := os.ReadFile(filepath.Join(dir, TakeoutManifestFile))
+	if err != nil || json.Unmarshal(data, &written) != nil {
+		t.Fatalf("manifest.json unreadable: %v", err)
+	}
+	var paths []string
+	for _, file := range written.Files {
+		paths = append(paths, file.Path)
+		body, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file.Path)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sum := sha256.Sum256(body); hex.EncodeToString(sum[:]) != file.SHA256 || int64(len(body)) != file.Bytes {
+			t.Errorf("%s: manifest checksum or size does not match the file", file.Path)
+		}
+	}
+	want := "attachments/" + attachment.AttachmentID + "/notes.md,embeddings.json,memory.yaml"
+	if got := strings.Join(paths, ","); got != want {
+		t.Errorf("manifest files = %s, want %s", got, want)
+	}
+
+	// Secrets are written when asked, and a used directory is refused
+	if _, err := store.Takeout(dir, true); err == nil || !strings.Contains(err.Error(), "not empty") {
+		t.Errorf("Takeout() into a used directory = %v, want an error", err)
+	}
+	revealed := filepath.Join(t.TempDir(), "revealed")
+	if manifest, err := store.Takeout(revealed, true); err != nil || !manifest.SecretsRevealed {
+		t.Fatalf("Takeout(reveal) = %+v, %v", manifest, err)
+	}
+	if export, _ := os.ReadFile(filepath.Join(revealed, TakeoutExportFile)); !strings.Contains(string(export), "tok-123456") {
+		t.Error("memory.yaml lacks the secret value with revealSecrets set")
+	}
+}
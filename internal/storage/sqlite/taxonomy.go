@@ -0,0 +1,88 @@
+// ABOUTME: Maps stored turns' topics onto the topic taxonomy, or onto labels already in use
+// ABOUTME: Lists topic labels in use and the blocks grouped under a taxonomy node
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// TopicLabelCount is a topic label in use and how many blocks carry it
+type TopicLabelCount struct {
+	Label  string `json:"label"`
+	Blocks int    `json:"blocks"`
+}
+
+// SetTopicTaxonomy makes turns stored from now on have their topics mapped onto taxonomy
+func (s *Storage) SetTopicTaxonomy(taxonomy models.Taxonomy) {
+	s.taxonomy = taxonomy
+}
+
+// TopicTaxonomy returns the taxonomy stored topics are mapped onto
+func (s *Storage) TopicTaxonomy() models.Taxonomy {
+	return s.taxonomy
+}
+
+// mapTopics maps topics onto the taxonomy, falling back to the topic labels of existing
+// blocks, so a new block is labeled like the blocks before it; the caller holds s.mu
+func (s *Storage) mapTopics(topics []string) []string {
+	if len(topics) == 0 {
+		return topics
+	}
+	blocks, err := s.blocks.ListAll()
+	if err != nil {
+		blocks = nil // Mapping onto the taxonomy alone still helps
+	}
+	known := make([]string, len(blocks))
+	for i, block := range blocks {
+		known[i] = block.TopicLabel
+	}
+	return s.taxonomy.MapTopics(topics, known)
+}
+
+// TopicLabels returns the topic labels in use, carried by the most blocks first
+func (s *Storage) TopicLabels() ([]TopicLabelCount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`
+		SELECT topic_label, COUNT(*)
+		FROM bridge_blocks
+		GROUP BY topic_label
+		ORDER BY COUNT(*) DESC, topic_label
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topic labels: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var labels []TopicLabelCount
+	for rows.Next() {
+		var label TopicLabelCount
+		if err := rows.Scan(&label.Label, &label.Blocks); err != nil {
+			return nil, fmt.Errorf("failed to scan topic label: %w", err)
+		}
+		labels = append(labels, label)
+	}
+	return labels, rows.Err()
+}
+
+// BlocksUnderTopic returns the blocks whose topic label falls under the taxonomy node,
+// or one of its descendants, most recently updated first
+func (s *Storage) BlocksUnderTopic(node string) ([]models.BridgeBlock, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	blocks, err := s.blocks.ListAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blocks: %w", err)
+	}
+	var under []models.BridgeBlock
+	for _, block := range blocks {
+		if s.taxonomy.Under(block.TopicLabel, node) {
+			under = append(under, block)
+		}
+	}
+	return under, nil
+}
@@ -0,0 +1,66 @@
+// ABOUTME: Tests for mapping stored topics onto the topic taxonomy
+// ABOUTME: Verifies taxonomy and learned-label mapping on store and metadata updates, label counts, and grouping by node
+package sqlite
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestTopicTaxonomy(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	taxonomy, err := models.ParseTaxonomy("programming/go=golang,go programming; programming/python")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.SetTopicTaxonomy(taxonomy)
+
+	goBlock, err := store.StoreTurn(&models.Turn{TurnID: "turn_go", UserMessage: "channels", Topics: []string{"Go Programming", "golang"}})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_garden", UserMessage: "tomatoes", Topics: []string{"Home Garden"}}); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	// A label outside the taxonomy is learned from the blocks already using it
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_garden_2", UserMessage: "peppers", Topics: []string{"home-garden"}}); err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	_, turn, err := store.GetTurn("turn_go")
+	if err != nil || !slices.Equal(turn.Topics, []string{"programming/go"}) {
+		t.Fatalf("stored topics = %v, %v; want [programming/go]", turn.Topics, err)
+	}
+	_, turn, err = store.GetTurn("turn_garden_2")
+	if err != nil || !slices.Equal(turn.Topics, []string{"Home Garden"}) {
+		t.Errorf("stored topics = %v, %v; want the label in use, [Home Garden]", turn.Topics, err)
+	}
+
+	if err := store.UpdateTurnMetadata("turn_garden", nil, []string{"python"}, ""); err != nil {
+		t.Fatalf("UpdateTurnMetadata() error = %v", err)
+	}
+	if _, turn, _ = store.GetTurn("turn_garden"); !slices.Equal(turn.Topics, []string{"programming/python"}) {
+		t.Errorf("updated topics = %v, want [programming/python]", turn.Topics)
+	}
+
+	labels, err := store.TopicLabels()
+	if err != nil {
+		t.Fatalf("TopicLabels() error = %v", err)
+	}
+	if len(labels) != 2 || labels[0] != (TopicLabelCount{Label: "Home Garden", Blocks: 2}) || labels[1] != (TopicLabelCount{Label: "programming/go", Blocks: 1}) {
+		t.Errorf("TopicLabels() = %+v", labels)
+	}
+
+	under, err := store.BlocksUnderTopic("programming")
+	if err != nil {
+		t.Fatalf("BlocksUnderTopic() error = %v", err)
+	}
+	if len(under) != 1 || under[0].BlockID != goBlock {
+		t.Errorf("BlocksUnderTopic(programming) = %+v, want only %s", under, goBlock)
+	}
+}
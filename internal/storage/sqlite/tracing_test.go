@@ -0,0 +1,85 @@
+// ABOUTME: Tests for tracing spans emitted by storage
+// ABOUTME: Verifies a traced StoreTurn splits into database and embedding spans under the caller's span
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/tracing"
+)
+
+func TestStoreTurnContext_Spans(t *testing.T) {
+	type span struct {
+		Name         string `json:"name"`
+		SpanID       string `json:"spanId"`
+		ParentSpanID string `json:"parentSpanId"`
+	}
+	var (
+		mu    sync.Mutex
+		spans []span
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []span `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		defer mu.Unlock()
+		for _, rs := range body.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+	}))
+	defer collector.Close()
+
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	store.SetOpenAIClient(fakeEmbedder{})
+	store.SetChunkEngine(singleChunker{})
+
+	shutdown := tracing.Setup(collector.URL, "memory", "test")
+	ctx, root := tracing.Start(context.Background(), "mcp.store_conversation")
+	if _, err := store.StoreTurnContext(ctx, &models.Turn{TurnID: "turn_traced", UserMessage: "trace me"}); err != nil {
+		t.Fatalf("StoreTurnContext() error = %v", err)
+	}
+	root.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+
+	byName := map[string]span{}
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+	parents := map[string]string{
+		"storage.store_turn":     "mcp.store_conversation",
+		"sqlite.create_block":    "storage.store_turn",
+		"storage.embed":          "storage.store_turn",
+		"llm.generate_embedding": "storage.embed",
+		"sqlite.save_embedding":  "storage.embed",
+	}
+	for name, parent := range parents {
+		s, ok := byName[name]
+		if !ok {
+			t.Errorf("span %s not exported (got %v)", name, spans)
+			continue
+		}
+		if s.ParentSpanID != byName[parent].SpanID {
+			t.Errorf("span %s should be a child of %s", name, parent)
+		}
+	}
+}
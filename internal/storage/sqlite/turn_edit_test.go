@@ -0,0 +1,101 @@
+// ABOUTME: Tests for correcting and annotating stored turns
+// ABOUTME: Verifies edited text, annotations, and edited_at persist and transcripts cannot be edited
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestUpdateTurn(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_edit", Timestamp: time.Now(), UserMessage: "I live in Porto", AIResponse: "Nice"})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	// An annotation alone leaves the text and edited_at alone
+	if _, turn, err := store.UpdateTurn("turn_edit", models.TurnEdit{Annotation: "check this", Agent: "cli"}); err != nil || turn.EditedAt != nil {
+		t.Fatalf("UpdateTurn(annotation) = %+v, %v; want no edited_at", turn, err)
+	}
+	message := "I live in Lisbon"
+	gotBlock, turn, err := store.UpdateTurn("turn_edit", models.TurnEdit{UserMessage: &message, Annotation: "this was wrong"})
+	if err != nil {
+		t.Fatalf("UpdateTurn() error = %v", err)
+	}
+	if gotBlock != blockID || turn.EditedAt == nil {
+		t.Errorf("UpdateTurn() = %s, %+v; want block %s with edited_at set", gotBlock, turn, blockID)
+	}
+
+	_, stored, err := store.GetTurn("turn_edit")
+	if err != nil || stored == nil {
+		t.Fatalf("GetTurn() = %+v, %v", stored, err)
+	}
+	if stored.UserMessage != message || stored.AIResponse != "Nice" || stored.EditedAt == nil {
+		t.Errorf("stored turn = %+v, want the corrected message and edited_at", stored)
+	}
+	if len(stored.Annotations) != 2 || stored.Annotations[0].Text != "check this" || stored.Annotations[0].Agent != "cli" || stored.Annotations[1].Text != "this was wrong" {
+		t.Errorf("Annotations = %+v, want both notes in order", stored.Annotations)
+	}
+
+	// Saving the block again keeps the annotations
+	if err := store.AppendTurnToBlock(blockID, &models.Turn{TurnID: "turn_edit_more", Timestamp: time.Now(), UserMessage: "more"}); err != nil {
+		t.Fatalf("AppendTurnToBlock() error = %v", err)
+	}
+	if _, stored, _ := store.GetTurn("turn_edit"); stored == nil || len(stored.Annotations) != 2 {
+		t.Errorf("turn after another save = %+v, want its annotations kept", stored)
+	}
+
+	empty := " "
+	if _, _, err := store.UpdateTurn("turn_edit", models.TurnEdit{UserMessage: &empty}); err == nil {
+		t.Error("UpdateTurn() to an empty message succeeded, want an error")
+	}
+	if _, _, err := store.UpdateTurn("turn_missing", models.TurnEdit{Annotation: "x"}); err == nil {
+		t.Error("UpdateTurn() of a missing turn succeeded, want an error")
+	}
+
+	transcript := &models.Turn{TurnID: "turn_edit_transcript", Timestamp: time.Now(), UserMessage: "hi", Messages: []models.Message{{Role: "user", Content: "hi"}}}
+	if err := store.AppendTurnToBlock(blockID, transcript); err != nil {
+		t.Fatalf("AppendTurnToBlock() error = %v", err)
+	}
+	if _, _, err := store.UpdateTurn("turn_edit_transcript", models.TurnEdit{UserMessage: &message}); err == nil {
+		t.Error("UpdateTurn() of a transcript's text succeeded, want an error")
+	}
+	if _, _, err := store.UpdateTurn("turn_edit_transcript", models.TurnEdit{Annotation: "fine"}); err != nil {
+		t.Errorf("UpdateTurn() annotating a transcript error = %v", err)
+	}
+}
+
+func TestDeleteTurnFacts(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID := storeKeywordBlock(t, store, "turn_facts")
+	for _, fact := range []models.Fact{
+		{FactID: "fact_turn_extracted", BlockID: blockID, TurnID: "turn_facts", Key: "city", Value: "Porto", Confidence: 1, ExtractedBy: "fact_scrubber"},
+		{FactID: "fact_turn_added", BlockID: blockID, TurnID: "turn_facts", Key: "pet", Value: "cat", Confidence: 1, ExtractedBy: "claude-code"},
+	} {
+		if err := store.SaveFact(&fact); err != nil {
+			t.Fatalf("SaveFact() error = %v", err)
+		}
+	}
+
+	n, err := store.DeleteTurnFacts("turn_facts", "fact_scrubber")
+	if err != nil || n != 1 {
+		t.Fatalf("DeleteTurnFacts() = %d, %v; want 1", n, err)
+	}
+	facts, err := store.GetFactsForBlock(blockID)
+	if err != nil || len(facts) != 1 || facts[0].FactID != "fact_turn_added" {
+		t.Errorf("facts left = %+v, %v; want only the one added by hand", facts, err)
+	}
+}
@@ -5,6 +5,8 @@ package sqlite
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/harper/remember-standalone/internal/models"
 )
@@ -21,6 +23,11 @@ func NewTurnStore(db *DB) *TurnStore {
 
 // Save saves a turn for a block
 func (s *TurnStore) Save(blockID string, turn *models.Turn) error {
+	return saveTurn(s.db, blockID, turn)
+}
+
+// saveTurn upserts a turn for a block through ex
+func saveTurn(ex execer, blockID string, turn *models.Turn) error {
 	keywordsJSON, err := json.Marshal(turn.Keywords)
 	if err != nil {
 		return err
@@ -31,16 +38,39 @@ func (s *TurnStore) Save(blockID string, turn *models.Turn) error {
 		return err
 	}
 
-	_, err = s.db.Exec(`
-		INSERT INTO turns (id, block_id, user_message, ai_response, keywords, topics, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+	origin := turn.Origin.Normalize()
+
+	// Plain user/AI turns store no transcript
+	var messagesJSON []byte
+	if len(turn.Messages) > 0 {
+		if messagesJSON, err = json.Marshal(turn.Messages); err != nil {
+			return err
+		}
+	}
+
+	_, err = ex.Exec(`
+		INSERT INTO turns (id, block_id, user_message, ai_response, keywords, topics, affect, messages,
+			device, hostname, working_dir, git_repo, location, agent, pending_enrichment, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''),
+			NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			user_message = excluded.user_message,
 			ai_response = excluded.ai_response,
 			keywords = excluded.keywords,
-			topics = excluded.topics
+			topics = excluded.topics,
+			affect = excluded.affect,
+			messages = excluded.messages,
+			device = excluded.device,
+			hostname = excluded.hostname,
+			working_dir = excluded.working_dir,
+			git_repo = excluded.git_repo,
+			location = excluded.location,
+			agent = excluded.agent,
+			pending_enrichment = excluded.pending_enrichment
 	`, turn.TurnID, blockID, turn.UserMessage, turn.AIResponse,
-		string(keywordsJSON), string(topicsJSON), turn.Timestamp)
+		string(keywordsJSON), string(topicsJSON), string(turn.Affect), string(messagesJSON),
+		origin.Device, origin.Hostname, origin.WorkingDir, origin.GitRepo, origin.Location, origin.Agent,
+		turn.PendingEnrichment, turn.Timestamp.UTC())
 
 	return err
 }
@@ -48,7 +78,9 @@ func (s *TurnStore) Save(blockID string, turn *models.Turn) error {
 // GetByBlock retrieves all turns for a block
 func (s *TurnStore) GetByBlock(blockID string) ([]models.Turn, error) {
 	rows, err := s.db.Query(`
-		SELECT id, user_message, ai_response, keywords, topics, created_at
+		SELECT id, user_message, ai_response, keywords, topics, affect, messages,
+			device, hostname, working_dir, git_repo, location, agent, pending_enrichment, created_at,
+			annotations, edited_at
 		FROM turns
 		WHERE block_id = ?
 		ORDER BY created_at ASC
@@ -64,10 +96,17 @@ func (s *TurnStore) GetByBlock(blockID string) ([]models.Turn, error) {
 			turn         models.Turn
 			keywordsJSON sql.NullString
 			topicsJSON   sql.NullString
+			affect       sql.NullString
+			messagesJSON sql.NullString
+			origin       [6]sql.NullString
+			annotations  sql.NullString
+			editedAt     sql.NullTime
 		)
 
 		err := rows.Scan(&turn.TurnID, &turn.UserMessage, &turn.AIResponse,
-			&keywordsJSON, &topicsJSON, &turn.Timestamp)
+			&keywordsJSON, &topicsJSON, &affect, &messagesJSON,
+			&origin[0], &origin[1], &origin[2], &origin[3], &origin[4], &origin[5], &turn.PendingEnrichment, &turn.Timestamp,
+			&annotations, &editedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -88,14 +127,216 @@ func (s *TurnStore) GetByBlock(blockID string) ([]models.Turn, error) {
 			turn.Topics = []string{}
 		}
 
+		turn.Affect = models.Affect(affect.String)
+
+		if messagesJSON.Valid && messagesJSON.String != "" {
+			if err := json.Unmarshal([]byte(messagesJSON.String), &turn.Messages); err != nil {
+				turn.Messages = nil
+			}
+		}
+
+		turn.Origin = scanOrigin(origin)
+
+		if annotations.Valid && annotations.String != "" {
+			if err := json.Unmarshal([]byte(annotations.String), &turn.Annotations); err != nil {
+				turn.Annotations = nil
+			}
+		}
+		if editedAt.Valid {
+			turn.EditedAt = &editedAt.Time
+		}
+
 		turns = append(turns, turn)
 	}
 
 	return turns, rows.Err()
 }
 
+// BlocksWithOrigin returns the blocks holding at least one turn whose origin matches filter
+func (s *TurnStore) BlocksWithOrigin(filter models.Origin) (map[string]bool, error) {
+	// Exact fields narrow the scan in SQL; Matches applies the working directory prefix rule
+	var clauses []string
+	var args []interface{}
+	for _, f := range []struct{ column, value string }{
+		{"device", filter.Device},
+		{"hostname", filter.Hostname},
+		{"git_repo", filter.GitRepo},
+		{"location", filter.Location},
+		{"agent", filter.Agent},
+	} {
+		if f.value != "" {
+			clauses = append(clauses, f.column+" = ?")
+			args = append(args, f.value)
+		}
+	}
+	if filter.WorkingDir != "" {
+		clauses = append(clauses, "working_dir IS NOT NULL")
+	}
+	if len(clauses) == 0 {
+		return nil, nil
+	}
+
+	rows, err := s.db.Query(`
+		SELECT block_id, device, hostname, working_dir, git_repo, location, agent
+		FROM turns
+		WHERE `+strings.Join(clauses, " AND "), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter turns by origin: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	blocks := make(map[string]bool)
+	for rows.Next() {
+		var (
+			blockID string
+			origin  [6]sql.NullString
+		)
+		if err := rows.Scan(&blockID, &origin[0], &origin[1], &origin[2], &origin[3], &origin[4], &origin[5]); err != nil {
+			return nil, err
+		}
+		o := scanOrigin(origin)
+		if o.Matches(filter) {
+			blocks[blockID] = true
+		}
+	}
+	return blocks, rows.Err()
+}
+
+// BlockOf returns the ID of the block holding turnID, or "" if the turn does not exist
+func (s *TurnStore) BlockOf(turnID string) (string, error) {
+	var blockID string
+	err := s.db.QueryRow("SELECT block_id FROM turns WHERE id = ?", turnID).Scan(&blockID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return blockID, err
+}
+
+// UpdateMetadata sets a turn's keywords, topics, and affect
+func (s *TurnStore) UpdateMetadata(turnID string, keywords, topics []string, affect models.Affect) error {
+	keywordsJSON, err := json.Marshal(keywords)
+	if err != nil {
+		return err
+	}
+	topicsJSON, err := json.Marshal(topics)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`
+		UPDATE turns SET keywords = ?, topics = ?, affect = NULLIF(?, '') WHERE id = ?
+	`, string(keywordsJSON), string(topicsJSON), string(affect), turnID)
+	return err
+}
+
+// Revise saves a turn's corrected texts, annotations, and when it was edited
+func (s *TurnStore) Revise(turn *models.Turn) error {
+	return reviseTurn(s.db, turn)
+}
+
+// reviseTurn writes a turn's text, annotations, and edit time with ex
+func reviseTurn(ex execer, turn *models.Turn) error {
+	var annotationsJSON []byte
+	if len(turn.Annotations) > 0 {
+		var err error
+		if annotationsJSON, err = json.Marshal(turn.Annotations); err != nil {
+			return err
+		}
+	}
+	_, err := ex.Exec(`
+		UPDATE turns SET user_message = ?, ai_response = ?, annotations = NULLIF(?, ''), edited_at = ?
+		WHERE id = ?
+	`, turn.UserMessage, turn.AIResponse, string(annotationsJSON), turn.EditedAt, turn.TurnID)
+	return err
+}
+
+// PendingEnrichmentCount returns how many turns wait on LLM enrichment
+func (s *TurnStore) PendingEnrichmentCount() (int, error) {
+	var n int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM turns WHERE pending_enrichment = 1").Scan(&n)
+	return n, err
+}
+
 // Delete removes a specific turn
 func (s *TurnStore) Delete(turnID string) error {
 	_, err := s.db.Exec("DELETE FROM turns WHERE id = ?", turnID)
 	return err
 }
+
+// AffectCounts returns, for each block that has any, how many of its turns carry each affect
+func (s *TurnStore) AffectCounts(blockIDs []string) (map[string]map[models.Affect]int, error) {
+	counts := make(map[string]map[models.Affect]int)
+	if len(blockIDs) == 0 {
+		return counts, nil
+	}
+
+	args := make([]interface{}, len(blockIDs))
+	for i, id := range blockIDs {
+		args[i] = id
+	}
+	rows, err := s.db.Query(`
+		SELECT block_id, affect, COUNT(*)
+		FROM turns
+		WHERE affect IS NOT NULL AND block_id IN (`+placeholders(len(blockIDs))+`)
+		GROUP BY block_id, affect
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count turn affects: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var (
+			blockID, affect string
+			n               int
+		)
+		if err := rows.Scan(&blockID, &affect, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan affect count: %w", err)
+		}
+		if counts[blockID] == nil {
+			counts[blockID] = make(map[models.Affect]int)
+		}
+		counts[blockID][models.Affect(affect)] = n
+	}
+	return counts, rows.Err()
+}
+
+// BlocksWithAffect returns the IDs of blocks with at least one turn carrying any of affects
+func (s *TurnStore) BlocksWithAffect(affects []models.Affect) (map[string]bool, error) {
+	blocks := make(map[string]bool)
+	if len(affects) == 0 {
+		return blocks, nil
+	}
+
+	args := make([]interface{}, len(affects))
+	for i, a := range affects {
+		args[i] = string(a)
+	}
+	rows, err := s.db.Query(`
+		SELECT DISTINCT block_id FROM turns WHERE affect IN (`+placeholders(len(affects))+`)
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter by affect: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var blockID string
+		if err := rows.Scan(&blockID); err != nil {
+			return nil, fmt.Errorf("failed to scan block: %w", err)
+		}
+		blocks[blockID] = true
+	}
+	return blocks, rows.Err()
+}
+
+// scanOrigin builds an Origin from the device, hostname, working_dir, git_repo, location, and agent columns
+func scanOrigin(cols [6]sql.NullString) models.Origin {
+	return models.Origin{
+		Device:     cols[0].String,
+		Hostname:   cols[1].String,
+		WorkingDir: cols[2].String,
+		GitRepo:    cols[3].String,
+		Location:   cols[4].String,
+		Agent:      cols[5].String,
+	}
+}
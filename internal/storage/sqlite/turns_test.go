@@ -85,6 +85,52 @@ func TestTurnStore_SaveAndGetByBlock(t *testing.T) {
 	}
 }
 
+func TestTurnStore_SaveAndGetMessages(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	turn, err := models.NewTurnFromMessages([]models.Message{
+		{Role: models.RoleUser, Content: "What time is it?"},
+		{Role: models.RoleAssistant, ToolCalls: []models.ToolCall{{ID: "c1", Name: "clock"}}},
+		{Role: models.RoleTool, Name: "clock", ToolCallID: "c1", Content: "09:00"},
+		{Role: models.RoleAssistant, Content: "It is nine."},
+	})
+	if err != nil {
+		t.Fatalf("NewTurnFromMessages() error = %v", err)
+	}
+	blockID, err := store.StoreTurn(turn)
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+
+	block, err := store.GetBridgeBlock(blockID)
+	if err != nil {
+		t.Fatalf("GetBridgeBlock() error = %v", err)
+	}
+	got := block.Turns[0]
+	if len(got.Messages) != 4 {
+		t.Fatalf("Messages = %+v, want 4", got.Messages)
+	}
+	if got.Messages[1].ToolCalls[0].Name != "clock" || got.Messages[2].ToolCallID != "c1" {
+		t.Errorf("tool-call structure lost: %+v", got.Messages)
+	}
+	if got.UserMessage != "What time is it?" || got.AIResponse != "It is nine." {
+		t.Errorf("turn = %q / %q", got.UserMessage, got.AIResponse)
+	}
+
+	plain := &models.Turn{TurnID: "turn_plain", UserMessage: "hi", Timestamp: time.Now().Add(time.Minute)}
+	if err := store.AppendTurnToBlock(blockID, plain); err != nil {
+		t.Fatalf("AppendTurnToBlock() error = %v", err)
+	}
+	block, _ = store.GetBridgeBlock(blockID)
+	if block.Turns[1].Messages != nil {
+		t.Errorf("plain turn Messages = %+v, want nil", block.Turns[1].Messages)
+	}
+}
+
 func TestTurnStore_Delete(t *testing.T) {
 	db, err := OpenInMemory()
 	if err != nil {
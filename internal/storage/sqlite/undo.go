@@ -0,0 +1,447 @@
+// ABOUTME: Undo log of destructive operations: deleted topics, facts, personas, attachments, and edited turns
+// ABOUTME: Copies the rows an operation removes or rewrites so 'memory undo' can put them back, newest first
+package sqlite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+// DefaultUndoLogSize is how many destructive operations the undo log keeps by default
+const DefaultUndoLogSize = 20
+
+// Operations the undo log records
+const (
+	UndoDeleteTopic      = "delete_topic"      // A topic with its turns, embeddings, tags, and attachments was deleted
+	UndoDeleteFacts      = "delete_facts"      // Facts were deleted by key, by ID, or from an edited turn
+	UndoDeletePersona    = "delete_persona"    // A persona was deleted
+	UndoDeleteAttachment = "delete_attachment" // An attachment and its embeddings were deleted
+	UndoEditTurn         = "edit_turn"         // A turn's text was corrected or redacted
+)
+
+// UndoEntry is one destructive operation kept in the undo log
+type UndoEntry struct {
+	ID        int64     `json:"id"`
+	Operation string    `json:"operation"`
+	Target    string    `json:"target"` // Block ID, fact key or ID, persona, attachment ID, or turn ID
+	Summary   string    `json:"summary"`
+	Rows      int       `json:"rows"` // Rows kept to restore
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// undoCapture selects the rows of one table an operation is about to remove or rewrite
+type undoCapture struct {
+	table string
+	where string
+	args  []interface{}
+}
+
+// undoRows are the rows of one table copied before an operation
+type undoRows struct {
+	Table   string        `json:"table"`
+	Columns []string      `json:"columns"`
+	Rows    [][]undoValue `json:"rows"`
+}
+
+// undoValue is one column value with its SQLite type, so it is restored exactly; all
+// fields empty is NULL
+type undoValue struct {
+	Int   *int64     `json:"i,omitempty"`
+	Float *float64   `json:"f,omitempty"`
+	Text  *string    `json:"s,omitempty"`
+	Blob  *[]byte    `json:"b,omitempty"`
+	Time  *time.Time `json:"t,omitempty"`
+}
+
+// SetUndoLogSize keeps the last n destructive operations in the undo log from now on;
+// 0 turns the log off. Older entries are dropped at the next operation.
+func (s *Storage) SetUndoLogSize(n int) {
+	s.undoLogSize = n
+}
+
+// recordUndo copies the rows captures select into the undo log as one operation, and
+// trims the log to its size. Call it in the operation's transaction, before changing
+// anything. Nothing is recorded when the log is off or no rows match.
+func (s *Storage) recordUndo(tx *Tx, operation, target, summary string, captures ...undoCapture) error {
+	if s.undoLogSize <= 0 {
+		return nil
+	}
+	var (
+		snapshot []undoRows
+		rows     int
+	)
+	for _, c := range captures {
+		copied, err := captureRows(tx, c)
+		if err != nil {
+			return fmt.Errorf("failed to copy %s for undo: %w", c.table, err)
+		}
+		if len(copied.Rows) > 0 {
+			snapshot = append(snapshot, copied)
+			rows += len(copied.Rows)
+		}
+	}
+	if rows == 0 {
+		return nil
+	}
+	data, err := encodeUndo(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode undo log entry: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO undo_log (operation, target, summary, rows, data, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, operation, target, summary, rows, data, s.db.now().UTC()); err != nil {
+		return fmt.Errorf("failed to record undo log entry: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM undo_log WHERE id NOT IN (SELECT id FROM undo_log ORDER BY id DESC LIMIT ?)`, s.undoLogSize); err != nil {
+		return fmt.Errorf("failed to trim undo log: %w", err)
+	}
+	return nil
+}
+
+// UndoLog returns up to limit operations that can be undone, newest first
+func (s *Storage) UndoLog(limit int) ([]UndoEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.undoLog(limit)
+}
+
+// undoLog reads the newest limit entries of the undo log
+func (s *Storage) undoLog(limit int) ([]UndoEntry, error) {
+	rows, err := s.db.Query(`
+		SELECT id, operation, target, summary, rows, created_at FROM undo_log
+		ORDER BY id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read undo log: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []UndoEntry
+	for rows.Next() {
+		var e UndoEntry
+		if err := rows.Scan(&e.ID, &e.Operation, &e.Target, &e.Summary, &e.Rows, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan undo log entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Undo reverses the n most recent operations in the undo log, newest first, putting
+// back the rows each removed or rewrote, and returns the operations undone. It stops at
+// the first operation that cannot be undone, e.g. because a row it restores refers to
+// one deleted since; the operations undone before it stay undone. Turns whose edit is
+// undone are queued to be embedded again. Restored topics, their turns, facts, and
+// personas are published as the writes that first stored them were.
+func (s *Storage) Undo(n int) ([]UndoEntry, error) {
+	var events []models.Event
+	defer func() { // runs after the unlock below, so subscribers may use storage
+		for _, event := range events {
+			s.publish(event)
+		}
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.undoLog(n)
+	if err != nil {
+		return nil, err
+	}
+	var undone []UndoEntry
+	for _, entry := range entries {
+		snapshot, err := s.undo(entry)
+		if err != nil {
+			return undone, fmt.Errorf("failed to undo %s of %s: %w", entry.Operation, entry.Target, err)
+		}
+		undone = append(undone, entry)
+		if s.publishing() {
+			events = append(events, s.undoEvents(entry, snapshot)...)
+		}
+	}
+	return undone, nil
+}
+
+// undoEvents are the events of the rows an undone entry restored: topic_updated (or
+// topic_archived) and turn_stored for a topic brought back, fact_added for each fact,
+// and profile_updated for a persona
+func (s *Storage) undoEvents(entry UndoEntry, snapshot []undoRows) []models.Event {
+	var events []models.Event
+	for _, copied := range snapshot {
+		for _, row := range copied.Rows {
+			text := func(column string) string {
+				if i := slices.Index(copied.Columns, column); i >= 0 && row[i].Text != nil {
+					return *row[i].Text
+				}
+				return ""
+			}
+			switch {
+			case copied.Table == "bridge_blocks":
+				events = append(events, topicEvent(text("id"), models.BridgeBlockStatus(text("status"))))
+			case copied.Table == "turns" && entry.Operation == UndoDeleteTopic:
+				event := models.NewEvent(models.EventTurnStored)
+				event.BlockID, event.TurnID = text("block_id"), text("id")
+				events = append(events, event)
+			case copied.Table == "facts":
+				fact, err := s.facts.GetByID(text("id"))
+				if err != nil || fact == nil {
+					continue
+				}
+				event := models.NewEvent(models.EventFactAdded)
+				event.BlockID, event.TurnID = fact.BlockID, fact.TurnID
+				event.Fact = models.NewEventFact(fact)
+				events = append(events, event)
+			}
+		}
+	}
+	if entry.Operation == UndoDeletePersona {
+		event := models.NewEvent(models.EventProfileUpdated)
+		event.Persona = entry.Target
+		events = append(events, event)
+	}
+	return events
+}
+
+// undo restores the rows of one undo log entry, removes the entry, and returns the
+// rows restored
+func (s *Storage) undo(entry UndoEntry) ([]undoRows, error) {
+	var data []byte
+	if err := s.db.QueryRow("SELECT data FROM undo_log WHERE id = ?", entry.ID).Scan(&data); err != nil {
+		return nil, err
+	}
+	snapshot, err := decodeUndo(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode undo log entry: %w", err)
+	}
+	if err := s.restore(entry, snapshot); err != nil {
+		return nil, err
+	}
+	// Restored embeddings count in their blocks' centroids again
+	return snapshot, refreshCentroids(s.db, restoredBlocks(snapshot)...)
+}
+
+// restore puts back the rows of snapshot in one transaction
+func (s *Storage) restore(entry UndoEntry, snapshot []undoRows) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, rows := range snapshot {
+		if err := restoreRows(tx, rows); err != nil {
+			return err
+		}
+	}
+
+	now := s.db.now()
+	switch entry.Operation {
+	case UndoDeletePersona:
+		// History shows the persona back from now on
+		var prefsJSON, constraintsJSON sql.NullString
+		if err := tx.QueryRow("SELECT preferences, constraints FROM profile_personas WHERE name = ?", entry.Target).Scan(&prefsJSON, &constraintsJSON); err != nil {
+			return fmt.Errorf("failed to read restored persona: %w", err)
+		}
+		v := &ProfileVersion{Persona: entry.Target, SavedAt: now}
+		if prefsJSON.Valid {
+			_ = json.Unmarshal([]byte(prefsJSON.String), &v.Preferences)
+		}
+		if constraintsJSON.Valid {
+			_ = json.Unmarshal([]byte(constraintsJSON.String), &v.Constraints)
+		}
+		if err := recordProfileVersion(tx, v); err != nil {
+			return err
+		}
+	case UndoEditTurn:
+		// The embeddings are of the edited text
+		var blockID string
+		if err := tx.QueryRow("SELECT block_id FROM turns WHERE id = ?", entry.Target).Scan(&blockID); err != nil {
+			return fmt.Errorf("failed to read restored turn: %w", err)
+		}
+		payload, err := json.Marshal(models.TurnJob{TurnID: entry.Target, BlockID: blockID})
+		if err != nil {
+			return err
+		}
+		if _, err := insertJob(tx, models.JobEmbedTurn, payload, models.DefaultJobAttempts, now); err != nil {
+			return fmt.Errorf("failed to queue %s: %w", models.JobEmbedTurn, err)
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM undo_log WHERE id = ?", entry.ID); err != nil {
+		return fmt.Errorf("failed to remove undo log entry: %w", err)
+	}
+	return tx.Commit()
+}
+
+// restoredBlocks lists the blocks of the embeddings in snapshot
+func restoredBlocks(snapshot []undoRows) []string {
+	var blockIDs []string
+	for _, copied := range snapshot {
+		if copied.Table != "embeddings" {
+			continue
+		}
+		column := slices.Index(copied.Columns, "block_id")
+		if column < 0 {
+			continue
+		}
+		for _, row := range copied.Rows {
+			if text := row[column].Text; text != nil {
+				blockIDs = append(blockIDs, *text)
+			}
+		}
+	}
+	return blockIDs
+}
+
+// ClearUndoLog deletes every entry in the undo log, so nothing deleted can be brought
+// back, and returns how many there were
+func (s *Storage) ClearUndoLog() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, err := s.db.Exec("DELETE FROM undo_log")
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear undo log: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// captureRows copies the rows c selects
+func captureRows(tx *Tx, c undoCapture) (undoRows, error) {
+	copied := undoRows{Table: c.table}
+	rows, err := tx.Query("SELECT * FROM "+c.table+" WHERE "+c.where, c.args...)
+	if err != nil {
+		return copied, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if copied.Columns, err = rows.Columns(); err != nil {
+		return copied, err
+	}
+	for rows.Next() {
+		values := make([]interface{}, len(copied.Columns))
+		dest := make([]interface{}, len(values))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return copied, err
+		}
+		row := make([]undoValue, len(values))
+		for i, v := range values {
+			if row[i], err = newUndoValue(v); err != nil {
+				return copied, fmt.Errorf("column %s: %w", copied.Columns[i], err)
+			}
+		}
+		copied.Rows = append(copied.Rows, row)
+	}
+	return copied, rows.Err()
+}
+
+// restoreRows writes copied rows back, replacing the columns of rows still present
+// (such as facts whose block was set to NULL when it was deleted)
+func restoreRows(tx *Tx, copied undoRows) error {
+	columns := make([]string, len(copied.Columns))
+	updates := make([]string, len(copied.Columns))
+	for i, column := range copied.Columns {
+		columns[i] = `"` + column + `"`
+		updates[i] = columns[i] + " = excluded." + columns[i]
+	}
+	query := "INSERT INTO " + copied.Table + " (" + strings.Join(columns, ", ") + ") VALUES (" + placeholders(len(columns)) +
+		") ON CONFLICT DO UPDATE SET " + strings.Join(updates, ", ")
+	for _, row := range copied.Rows {
+		args := make([]interface{}, len(row))
+		for i, v := range row {
+			args[i] = v.value()
+		}
+		if _, err := tx.Exec(query, args...); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", copied.Table, err)
+		}
+	}
+	return nil
+}
+
+// newUndoValue records a value scanned from SQLite
+func newUndoValue(v interface{}) (undoValue, error) {
+	switch v := v.(type) {
+	case nil:
+		return undoValue{}, nil
+	case int64:
+		return undoValue{Int: &v}, nil
+	case bool:
+		var i int64
+		if v {
+			i = 1
+		}
+		return undoValue{Int: &i}, nil
+	case float64:
+		return undoValue{Float: &v}, nil
+	case string:
+		return undoValue{Text: &v}, nil
+	case []byte:
+		blob := append([]byte{}, v...)
+		return undoValue{Blob: &blob}, nil
+	case time.Time:
+		return undoValue{Time: &v}, nil
+	default:
+		return undoValue{}, fmt.Errorf("unexpected value of type %T", v)
+	}
+}
+
+// value returns the value to write back
+func (v undoValue) value() interface{} {
+	switch {
+	case v.Int != nil:
+		return *v.Int
+	case v.Float != nil:
+		return *v.Float
+	case v.Text != nil:
+		return *v.Text
+	case v.Blob != nil:
+		return *v.Blob
+	case v.Time != nil:
+		return v.Time.UTC()
+	default:
+		return nil
+	}
+}
+
+// encodeUndo gzips copied rows as JSON
+func encodeUndo(snapshot []undoRows) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(snapshot); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeUndo reads rows written by encodeUndo
+func decodeUndo(data []byte) ([]undoRows, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot []undoRows
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
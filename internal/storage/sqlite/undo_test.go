@@ -0,0 +1,268 @@
+// ABOUTME: Tests for the undo log of destructive operations
+// ABOUTME: Verifies deleted topics, facts, personas, and edited turns come back with their events, and the log's size limit
+
+package sqlite
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestUndoDeleteTopic(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_undo_1", Timestamp: time.Now(), UserMessage: "plan the garden", AIResponse: "tomatoes first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ReplaceTurnEmbeddings("turn_undo_1", blockID, "model-a", []models.Chunk{{ChunkID: "chunk_undo_1"}}, [][]float64{{0.25, -1, 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_undo_1", BlockID: blockID, TurnID: "turn_undo_1", Key: "crop", Value: "tomatoes", Confidence: 0.9, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.TagBlock(blockID, []string{"garden"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AttachToTurn("turn_undo_1", &models.Attachment{AttachmentID: "att_undo_1", Kind: models.AttachmentBlob, Name: "plan.txt", Content: "rows of tomatoes"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.DeleteBridgeBlock(blockID); err != nil {
+		t.Fatalf("DeleteBridgeBlock() error = %v", err)
+	}
+	if block, _ := store.GetBridgeBlock(blockID); block != nil {
+		t.Fatal("block still there after deleting it")
+	}
+	log, err := store.UndoLog(10)
+	if err != nil || len(log) != 1 || log[0].Operation != UndoDeleteTopic || log[0].Target != blockID || log[0].Rows == 0 {
+		t.Fatalf("UndoLog() = %+v, %v; want the deleted topic", log, err)
+	}
+
+	undone, err := store.Undo(1)
+	if err != nil || len(undone) != 1 || undone[0].ID != log[0].ID {
+		t.Fatalf("Undo(1) = %+v, %v; want the topic deletion undone", undone, err)
+	}
+	block, err := store.GetBridgeBlock(blockID)
+	if err != nil || block == nil || len(block.Turns) != 1 || block.Turns[0].AIResponse != "tomatoes first" {
+		t.Fatalf("GetBridgeBlock() after undo = %+v, %v; want the turn back", block, err)
+	}
+	if !slices.Equal(block.Tags, []string{"garden"}) {
+		t.Errorf("tags after undo = %v, want [garden]", block.Tags)
+	}
+	if fact, err := store.GetFactByKey("crop"); err != nil || fact == nil || fact.Value != "tomatoes" {
+		t.Errorf("GetFactByKey(crop) after undo = %+v, %v; want the fact back", fact, err)
+	}
+	if a, err := store.GetAttachment("att_undo_1"); err != nil || a == nil || a.Content != "rows of tomatoes" {
+		t.Errorf("GetAttachment() after undo = %+v, %v; want the attachment back", a, err)
+	}
+	embeddings, err := store.GetVectorStorage().GetByBlock(blockID)
+	if err != nil || len(embeddings) != 1 || !slices.Equal(embeddings[0].Vector, []float64{0.25, -1, 3}) {
+		t.Errorf("embeddings after undo = %+v, %v; want the vector unchanged", embeddings, err)
+	}
+
+	if log, _ := store.UndoLog(10); len(log) != 0 {
+		t.Errorf("UndoLog() after undo = %+v, want it empty", log)
+	}
+	if undone, err := store.Undo(1); err != nil || len(undone) != 0 {
+		t.Errorf("Undo(1) with an empty log = %+v, %v; want nothing", undone, err)
+	}
+}
+
+func TestUndoFactsAndPersona(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_undo_2", Timestamp: time.Now(), UserMessage: "I live in Chicago"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_undo_2", BlockID: blockID, TurnID: "turn_undo_2", Key: "city", Value: "Chicago", Confidence: 0.8, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SavePersona(&models.Persona{Name: "work", Preferences: []string{"formal tone"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := store.DeleteFactByKey("city"); err != nil || n != 1 {
+		t.Fatalf("DeleteFactByKey() = %d, %v", n, err)
+	}
+	if ok, err := store.DeletePersona("work"); err != nil || !ok {
+		t.Fatalf("DeletePersona() = %v, %v", ok, err)
+	}
+	// Nothing deleted, nothing logged
+	if n, err := store.DeleteFactByKey("missing"); err != nil || n != 0 {
+		t.Fatalf("DeleteFactByKey(missing) = %d, %v", n, err)
+	}
+
+	undone, err := store.Undo(5)
+	if err != nil || len(undone) != 2 || undone[0].Operation != UndoDeletePersona || undone[1].Operation != UndoDeleteFacts {
+		t.Fatalf("Undo(5) = %+v, %v; want the persona then the facts", undone, err)
+	}
+	if fact, err := store.GetFactByKey("city"); err != nil || fact == nil || fact.FactID != "fact_undo_2" {
+		t.Errorf("GetFactByKey(city) after undo = %+v, %v; want the fact back", fact, err)
+	}
+	persona, err := store.GetPersona("work")
+	if err != nil || persona == nil || !slices.Equal(persona.Preferences, []string{"formal tone"}) {
+		t.Errorf("GetPersona(work) after undo = %+v, %v; want the persona back", persona, err)
+	}
+	// History shows the persona back, not still deleted
+	if v, err := store.profile.VersionAsOf("work", time.Now().Add(time.Minute)); err != nil || v == nil || v.Deleted {
+		t.Errorf("latest work version after undo = %+v, %v; want it restored", v, err)
+	}
+}
+
+func TestUndo_PublishesRestoredChanges(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_undo_4", Timestamp: time.Now(), UserMessage: "plan the garden"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fact := range []*models.Fact{
+		{FactID: "fact_undo_4", BlockID: blockID, TurnID: "turn_undo_4", Key: "crop", Value: "tomatoes", Confidence: 0.9, CreatedAt: time.Now()},
+		{FactID: "fact_undo_5", BlockID: blockID, TurnID: "turn_undo_4", Key: "gate_code", Value: "4321", ValueType: models.ValueTypeSecret, Confidence: 0.9, CreatedAt: time.Now()},
+	} {
+		if err := store.SaveFact(fact); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.DeleteFactByKey("gate_code"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteBridgeBlock(blockID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The recorder reads storage as events arrive, so they must come after the unlock
+	rec := &recorder{store: store}
+	store.SetEventPublisher(rec)
+	sub := store.Subscribe(SubscribeOptions{})
+	defer sub.Close()
+
+	if undone, err := store.Undo(2); err != nil || len(undone) != 2 {
+		t.Fatalf("Undo(2) = %+v, %v; want the topic and the fact back", undone, err)
+	}
+	for _, want := range []models.Event{
+		{Type: models.EventTopicUpdated, BlockID: blockID, Status: string(models.StatusActive)},
+		{Type: models.EventTurnStored, BlockID: blockID, TurnID: "turn_undo_4"},
+		{Type: models.EventFactAdded, BlockID: blockID, TurnID: "turn_undo_4", Fact: &models.EventFact{FactID: "fact_undo_4", Value: "tomatoes"}},
+		{Type: models.EventFactAdded, BlockID: blockID, TurnID: "turn_undo_4", Fact: &models.EventFact{FactID: "fact_undo_5"}},
+	} {
+		event := receive(t, sub)
+		if event.Type != want.Type || event.BlockID != want.BlockID || event.TurnID != want.TurnID || event.Status != want.Status {
+			t.Errorf("event = %+v, want %+v", event, want)
+			continue
+		}
+		if want.Fact != nil && (event.Fact == nil || event.Fact.FactID != want.Fact.FactID || event.Fact.Value != want.Fact.Value) {
+			t.Errorf("%s fact = %+v, want %+v (secret values left out)", event.Type, event.Fact, want.Fact)
+		}
+	}
+	if len(rec.events) != 4 {
+		t.Errorf("publisher received %v, want the same 4 events", rec.types())
+	}
+}
+
+func TestUndoEditTurn(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_undo_3", Timestamp: time.Now(), UserMessage: "my password is hunter2"}); err != nil {
+		t.Fatal(err)
+	}
+	redacted := "my password is [redacted]"
+	if _, _, err := store.UpdateTurn("turn_undo_3", models.TurnEdit{UserMessage: &redacted}); err != nil {
+		t.Fatalf("UpdateTurn() error = %v", err)
+	}
+	// Annotating alone changes no text and is not logged
+	if _, _, err := store.UpdateTurn("turn_undo_3", models.TurnEdit{Annotation: "checked"}); err != nil {
+		t.Fatalf("UpdateTurn(annotation) error = %v", err)
+	}
+	log, err := store.UndoLog(10)
+	if err != nil || len(log) != 1 || log[0].Operation != UndoEditTurn || log[0].Target != "turn_undo_3" {
+		t.Fatalf("UndoLog() = %+v, %v; want the edit alone", log, err)
+	}
+
+	if _, err := store.Undo(1); err != nil {
+		t.Fatalf("Undo(1) error = %v", err)
+	}
+	_, turn, err := store.GetTurn("turn_undo_3")
+	if err != nil || turn.UserMessage != "my password is hunter2" || turn.EditedAt != nil {
+		t.Errorf("GetTurn() after undo = %+v, %v; want the original text", turn, err)
+	}
+	jobs, err := store.ListJobs(models.JobPending, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queued := false
+	for _, job := range jobs {
+		var payload models.TurnJob
+		if job.Kind == models.JobEmbedTurn && job.Decode(&payload) == nil && payload.TurnID == "turn_undo_3" {
+			queued = true
+		}
+	}
+	if !queued {
+		t.Errorf("pending jobs after undo = %+v, want the turn queued for embedding", jobs)
+	}
+}
+
+func TestUndoLogSize(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_undo_4", Timestamp: time.Now(), UserMessage: "facts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleteFact := func(key string) {
+		t.Helper()
+		if err := store.SaveFact(&models.Fact{FactID: "fact_" + key, BlockID: blockID, TurnID: "turn_undo_4", Key: key, Value: "v", Confidence: 0.9, CreatedAt: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.DeleteFactByKey(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store.SetUndoLogSize(2)
+	deleteFact("a")
+	deleteFact("b")
+	deleteFact("c")
+	log, err := store.UndoLog(10)
+	if err != nil || len(log) != 2 || log[0].Target != "c" || log[1].Target != "b" {
+		t.Fatalf("UndoLog() = %+v, %v; want only the newest two", log, err)
+	}
+
+	store.SetUndoLogSize(0)
+	deleteFact("d")
+	if log, _ := store.UndoLog(10); len(log) != 2 {
+		t.Errorf("UndoLog() with the log off = %+v, want the delete of d unrecorded", log)
+	}
+
+	if n, err := store.ClearUndoLog(); err != nil || n != 2 {
+		t.Errorf("ClearUndoLog() = %d, %v; want 2", n, err)
+	}
+	if undone, err := store.Undo(1); err != nil || len(undone) != 0 {
+		t.Errorf("Undo(1) after clearing = %+v, %v; want nothing", undone, err)
+	}
+}
@@ -0,0 +1,66 @@
+// ABOUTME: LLM usage accounting for SQLite
+// ABOUTME: Records token counts per API call and aggregates them by operation
+package sqlite
+
+import (
+	"database/sql"
+)
+
+// LLMUsage summarizes LLM calls for one operation and model
+type LLMUsage struct {
+	Operation        string `json:"operation"`
+	Model            string `json:"model"`
+	Calls            int    `json:"calls"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+}
+
+// UsageStore handles LLM usage persistence
+type UsageStore struct {
+	db *DB
+}
+
+// NewUsageStore creates a new UsageStore
+func NewUsageStore(db *DB) *UsageStore {
+	return &UsageStore{db: db}
+}
+
+// Record stores token usage for a single LLM call
+func (s *UsageStore) Record(operation, model string, promptTokens, completionTokens int) error {
+	_, err := s.db.Exec(`
+		INSERT INTO llm_usage (operation, model, prompt_tokens, completion_tokens, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, operation, nullString(model), promptTokens, completionTokens, s.db.now())
+	return err
+}
+
+// Summary aggregates usage by operation and model
+func (s *UsageStore) Summary() ([]LLMUsage, error) {
+	rows, err := s.db.Query(`
+		SELECT operation, model, COUNT(*), SUM(prompt_tokens), SUM(completion_tokens)
+		FROM llm_usage
+		GROUP BY operation, model
+		ORDER BY operation ASC, model ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var usage []LLMUsage
+	for rows.Next() {
+		var (
+			u     LLMUsage
+			model sql.NullString
+		)
+		if err := rows.Scan(&u.Operation, &model, &u.Calls, &u.PromptTokens, &u.CompletionTokens); err != nil {
+			return nil, err
+		}
+		if model.Valid {
+			u.Model = model.String
+		}
+		usage = append(usage, u)
+	}
+
+	return usage, rows.Err()
+}
@@ -0,0 +1,52 @@
+// ABOUTME: Tests for LLM usage accounting
+// ABOUTME: Verifies usage records aggregate by operation and model
+package sqlite
+
+import "testing"
+
+func TestUsageStore_RecordAndSummary(t *testing.T) {
+	db, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("OpenInMemory() error = %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	store := NewUsageStore(db)
+
+	_ = store.Record("extract_facts", "gpt-4o-mini", 100, 20)
+	_ = store.Record("extract_facts", "gpt-4o-mini", 50, 10)
+	_ = store.Record("embedding", "text-embedding-3-small", 8, 0)
+
+	usage, err := store.Summary()
+	if err != nil {
+		t.Fatalf("Summary() error = %v", err)
+	}
+	if len(usage) != 2 {
+		t.Fatalf("Summary() returned %d rows, want 2", len(usage))
+	}
+
+	// Ordered by operation name
+	if usage[0].Operation != "embedding" || usage[0].Calls != 1 {
+		t.Errorf("usage[0] = %+v, want 1 embedding call", usage[0])
+	}
+	facts := usage[1]
+	if facts.Calls != 2 || facts.PromptTokens != 150 || facts.CompletionTokens != 30 {
+		t.Errorf("extract_facts usage = %+v, want 2 calls, 150/30 tokens", facts)
+	}
+}
+
+func TestUsageStore_EmptySummary(t *testing.T) {
+	db, err := OpenInMemory()
+	if err != nil {
+		t.Fatalf("OpenInMemory() error = %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	usage, err := NewUsageStore(db).Summary()
+	if err != nil {
+		t.Fatalf("Summary() error = %v", err)
+	}
+	if len(usage) != 0 {
+		t.Errorf("Summary() = %v, want empty", usage)
+	}
+}
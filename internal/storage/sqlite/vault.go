@@ -0,0 +1,329 @@
+// ABOUTME: Markdown vault export: one note per topic plus a facts index, for Obsidian or any editor
+// ABOUTME: Syncs incrementally, rewriting only notes whose content changed and removing notes of deleted topics
+package sqlite
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// VaultStateFile lists the notes a sync wrote, so the next one removes only its own stale notes
+	VaultStateFile = ".memory-vault.json"
+	// VaultFactsFile is the facts index at the root of the vault
+	VaultFactsFile = "Facts.md"
+	// VaultTopicsDir holds one note per topic
+	VaultTopicsDir = "Topics"
+
+	maxNoteNameLen = 80 // Characters of a topic label kept in its note's file name
+)
+
+// VaultSync reports what one vault sync changed; paths are relative to the vault
+type VaultSync struct {
+	Dir       string   `json:"dir"`
+	Written   []string `json:"written,omitempty"`
+	Removed   []string `json:"removed,omitempty"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// Changed reports whether the sync wrote or removed any note
+func (v *VaultSync) Changed() bool {
+	return len(v.Written) > 0 || len(v.Removed) > 0
+}
+
+// vaultState is the contents of VaultStateFile
+type vaultState struct {
+	Files []string `json:"files"`
+}
+
+// SyncVault brings the Markdown vault in dir up to date with the data selected by
+// opts: a note per topic under Topics/ and a Facts.md index. Notes whose content has
+// not changed are left alone, so editors and file watchers see only real changes;
+// notes this function wrote for topics that are gone are removed. Other files in dir
+// are never touched. Secret facts are masked unless opts.RevealSecrets is set.
+func (s *Storage) SyncVault(dir string, opts ExportOptions) (*VaultSync, error) {
+	data, err := s.ExportWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	factBlocks, err := s.factBlocks()
+	if err != nil {
+		return nil, err
+	}
+
+	// Name every topic's note first so notes can link to each other
+	names := vaultNoteNames(data.Blocks)
+	notes := map[string][]byte{}
+	blockFacts := map[string][]ExportFact{}
+	current := map[string]bool{}
+	var facts []ExportFact // The newest fact per key, as data.Facts is newest first
+	for _, fact := range data.Facts {
+		if current[fact.Key] {
+			continue
+		}
+		current[fact.Key] = true
+		facts = append(facts, fact)
+		if blockID := factBlocks[fact.FactID]; blockID != "" {
+			blockFacts[blockID] = append(blockFacts[blockID], fact)
+		}
+	}
+	for _, block := range data.Blocks {
+		related, err := s.GetRelatedTopics(block.BlockID)
+		if err != nil {
+			return nil, err
+		}
+		var links []string
+		for _, r := range related {
+			if name, ok := names[r.BlockID]; ok {
+				links = append(links, fmt.Sprintf("[[%s]] (%s)", name, r.Relation))
+			}
+		}
+		note, err := topicNote(block, blockFacts[block.BlockID], links)
+		if err != nil {
+			return nil, err
+		}
+		notes[filepath.ToSlash(filepath.Join(VaultTopicsDir, names[block.BlockID]+".md"))] = note
+	}
+	notes[VaultFactsFile] = factsNote(facts, factBlocks, names)
+
+	return writeVault(dir, notes)
+}
+
+// factBlocks maps each fact that came from a topic to the topic's block ID
+func (s *Storage) factBlocks() (map[string]string, error) {
+	rows, err := s.db.Query("SELECT id, block_id FROM facts WHERE block_id IS NOT NULL AND block_id != ''")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fact topics: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	blocks := map[string]string{}
+	for rows.Next() {
+		var factID, blockID string
+		if err := rows.Scan(&factID, &blockID); err != nil {
+			return nil, fmt.Errorf("failed to read fact topics: %w", err)
+		}
+		blocks[factID] = blockID
+	}
+	return blocks, rows.Err()
+}
+
+// vaultNoteNames gives each block a note name from its topic label. The oldest topic
+// with a label gets the plain name; later ones with the same name get their block ID added.
+func vaultNoteNames(blocks []ExportBlock) map[string]string {
+	ordered := slices.Clone(blocks)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].CreatedAt != ordered[j].CreatedAt {
+			return ordered[i].CreatedAt < ordered[j].CreatedAt
+		}
+		return ordered[i].BlockID < ordered[j].BlockID
+	})
+	names := map[string]string{}
+	taken := map[string]bool{}
+	for _, block := range ordered {
+		name := noteName(block.TopicLabel)
+		if name == "" {
+			name = block.BlockID
+		}
+		if taken[strings.ToLower(name)] {
+			name = fmt.Sprintf("%s (%s)", name, block.BlockID)
+		}
+		taken[strings.ToLower(name)] = true
+		names[block.BlockID] = name
+	}
+	return names
+}
+
+// noteName turns a topic label into a file name Obsidian can link to, dropping the
+// characters file systems and wiki links reserve
+func noteName(label string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(`\/:*?"<>|#^[]`, r):
+			return -1
+		case r < ' ':
+			return ' '
+		}
+		return r
+	}, label)
+	name = strings.Join(strings.Fields(name), " ")
+	name = strings.TrimLeft(name, ".")
+	if runes := []rune(name); len(runes) > maxNoteNameLen {
+		name = strings.TrimSpace(string(runes[:maxNoteNameLen]))
+	}
+	return name
+}
+
+// topicFrontMatter is the YAML front matter of a topic note; Obsidian reads tags and aliases
+type topicFrontMatter struct {
+	BlockID  string   `yaml:"block_id"`
+	Status   string   `yaml:"status"`
+	Created  string   `yaml:"created"`
+	Tags     []string `yaml:"tags,omitempty"`
+	Keywords []string `yaml:"keywords,omitempty"`
+	Aliases  []string `yaml:"aliases,omitempty"`
+}
+
+// topicNote renders one topic: front matter, summary, facts, related topics, and the conversation
+func topicNote(block ExportBlock, facts []ExportFact, related []string) ([]byte, error) {
+	front := topicFrontMatter{
+		BlockID:  block.BlockID,
+		Status:   block.Status,
+		Created:  block.CreatedAt,
+		Tags:     block.Tags,
+		Keywords: block.Keywords,
+	}
+	title := block.TopicLabel
+	if title == "" {
+		title = block.BlockID
+	} else {
+		front.Aliases = []string{block.TopicLabel}
+	}
+	header, err := yaml.Marshal(front)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode front matter for %s: %w", block.BlockID, err)
+	}
+
+	var b bytes.Buffer
+	_, _ = fmt.Fprintf(&b, "---\n%s---\n\n# %s\n\n", header, title)
+	if block.Summary != "" {
+		_, _ = fmt.Fprintf(&b, "> %s\n\n", strings.ReplaceAll(strings.TrimSpace(block.Summary), "\n", "\n> "))
+	}
+	if len(facts) > 0 {
+		_, _ = fmt.Fprint(&b, "## Facts\n\n")
+		for _, fact := range facts {
+			_, _ = fmt.Fprintf(&b, "- **%s:** %s\n", fact.Key, fact.Value)
+		}
+		_, _ = fmt.Fprintln(&b)
+	}
+	if len(related) > 0 {
+		_, _ = fmt.Fprint(&b, "## Related\n\n")
+		for _, link := range related {
+			_, _ = fmt.Fprintf(&b, "- %s\n", link)
+		}
+		_, _ = fmt.Fprintln(&b)
+	}
+	_, _ = fmt.Fprint(&b, "## Conversation\n")
+	for _, turn := range block.Turns {
+		_, _ = fmt.Fprintf(&b, "\n### %s\n\n", turn.Timestamp)
+		if len(turn.Messages) > 0 {
+			for _, m := range turn.Messages {
+				_, _ = fmt.Fprintf(&b, "**%s:** %s\n\n", m.Speaker(), m.Body())
+			}
+		} else {
+			_, _ = fmt.Fprintf(&b, "**User:** %s\n\n", turn.UserMessage)
+			if turn.AIResponse != "" {
+				_, _ = fmt.Fprintf(&b, "**AI:** %s\n\n", turn.AIResponse)
+			}
+		}
+		for _, a := range turn.Attachments {
+			if a.Ref != "" && a.Ref != a.Name {
+				_, _ = fmt.Fprintf(&b, "*Attachment: %s (%s)*\n\n", a.Name, a.Ref)
+			} else {
+				_, _ = fmt.Fprintf(&b, "*Attachment: %s*\n\n", a.Name)
+			}
+		}
+	}
+	return append(bytes.TrimRight(b.Bytes(), "\n"), '\n'), nil
+}
+
+// factsNote renders the index of current facts, linking each to the topic it came from
+func factsNote(facts []ExportFact, factBlocks, names map[string]string) []byte {
+	sorted := slices.Clone(facts)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+
+	var b bytes.Buffer
+	_, _ = fmt.Fprint(&b, "# Facts\n\n")
+	if len(sorted) == 0 {
+		_, _ = fmt.Fprint(&b, "No facts yet.\n")
+		return b.Bytes()
+	}
+	_, _ = fmt.Fprintln(&b, "| Key | Value | Confidence | Source | Topic | Recorded |")
+	_, _ = fmt.Fprintln(&b, "|-----|-------|------------|--------|-------|----------|")
+	for _, fact := range sorted {
+		topic := ""
+		if name, ok := names[factBlocks[fact.FactID]]; ok {
+			topic = "[[" + name + "]]"
+		}
+		_, _ = fmt.Fprintf(&b, "| %s | %s | %.2f | %s | %s | %s |\n",
+			tableCell(fact.Key), tableCell(fact.Value), fact.Confidence, tableCell(factSource(fact)), topic, fact.CreatedAt)
+	}
+	return b.Bytes()
+}
+
+// tableCell keeps a value on one Markdown table row
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// writeVault writes notes that changed, removes notes from the last sync that are no
+// longer produced, and records the notes written
+func writeVault(dir string, notes map[string][]byte) (*VaultSync, error) {
+	if err := os.MkdirAll(filepath.Join(dir, VaultTopicsDir), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create vault: %w", err)
+	}
+	var previous vaultState
+	if data, err := os.ReadFile(filepath.Join(dir, VaultStateFile)); err == nil { // #nosec G304
+		if err := json.Unmarshal(data, &previous); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", VaultStateFile, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", VaultStateFile, err)
+	}
+
+	result := &VaultSync{Dir: dir}
+	state := vaultState{Files: make([]string, 0, len(notes))}
+	for path := range notes {
+		state.Files = append(state.Files, path)
+	}
+	sort.Strings(state.Files)
+
+	for _, path := range state.Files {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		if existing, err := os.ReadFile(full); err == nil && bytes.Equal(existing, notes[path]) { // #nosec G304
+			result.Unchanged++
+			continue
+		}
+		if err := os.WriteFile(full, notes[path], 0600); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		result.Written = append(result.Written, path)
+	}
+	for _, path := range previous.Files {
+		if _, ok := notes[path]; ok || !isVaultNote(path) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, filepath.FromSlash(path))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		result.Removed = append(result.Removed, path)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", VaultStateFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, VaultStateFile), append(data, '\n'), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", VaultStateFile, err)
+	}
+	return result, nil
+}
+
+// isVaultNote reports whether a path from the state file is one SyncVault writes,
+// so an edited state file cannot make a sync delete anything else
+func isVaultNote(path string) bool {
+	if path == VaultFactsFile {
+		return true
+	}
+	dir, file := filepath.Split(filepath.FromSlash(path))
+	return filepath.Clean(dir) == VaultTopicsDir && strings.HasSuffix(file, ".md") && file == filepath.Base(file)
+}
@@ -0,0 +1,123 @@
+// ABOUTME: Tests for the Markdown vault export
+// ABOUTME: Verifies topic notes, the facts index, links, incremental rewrites, removals, and untouched user files
+package sqlite
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestSyncVault(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	dir := t.TempDir()
+	read := func(path string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			t.Fatalf("reading %s: %v", path, err)
+		}
+		return string(data)
+	}
+
+	trip, err := store.StoreTurn(&models.Turn{TurnID: "turn_1", Timestamp: time.Now(), UserMessage: "Planning a trip to Lisbon",
+		AIResponse: "May is a good month.", Keywords: []string{"lisbon", "travel"}, Topics: []string{"Lisbon Trip"}})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	flights, err := store.StoreTurn(&models.Turn{TurnID: "turn_2", Timestamp: time.Now(), UserMessage: "Booking flights",
+		Keywords: []string{"flights"}, Topics: []string{"Flights"}})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if err := store.RelateBlocks(flights, trip, models.RelationSpawnedFrom); err != nil {
+		t.Fatalf("RelateBlocks() error = %v", err)
+	}
+	now := time.Now()
+	for i, value := range []string{"Porto", "Lisbon"} {
+		if err := store.SaveFact(&models.Fact{FactID: "fact_city_" + value, BlockID: trip, Key: "destination", Value: value,
+			Confidence: 0.9, CreatedAt: now.Add(time.Duration(i) * time.Second), ExtractedBy: "fact_scrubber"}); err != nil {
+			t.Fatalf("SaveFact() error = %v", err)
+		}
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_token", Key: "api_token", Value: "sk-secret",
+		ValueType: models.ValueTypeSecret, Confidence: 1, CreatedAt: now}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "My notes.md"), []byte("mine"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := store.SyncVault(dir, ExportOptions{})
+	if err != nil {
+		t.Fatalf("SyncVault() error = %v", err)
+	}
+	if len(result.Written) != 3 || result.Unchanged != 0 {
+		t.Fatalf("first sync = %+v, want 3 notes written", result)
+	}
+
+	tripNote := read(filepath.Join(VaultTopicsDir, "Lisbon Trip.md"))
+	for _, want := range []string{"block_id: " + trip, "- lisbon", "**User:** Planning a trip to Lisbon",
+		"**AI:** May is a good month.", "- **destination:** Lisbon"} {
+		if !strings.Contains(tripNote, want) {
+			t.Errorf("topic note is missing %q:\n%s", want, tripNote)
+		}
+	}
+	if strings.Contains(tripNote, "Porto") {
+		t.Errorf("topic note lists a superseded fact:\n%s", tripNote)
+	}
+	if flightsNote := read(filepath.Join(VaultTopicsDir, "Flights.md")); !strings.Contains(flightsNote, "[[Lisbon Trip]] (spawned-from)") {
+		t.Errorf("flights note does not link to the trip:\n%s", flightsNote)
+	}
+	facts := read(VaultFactsFile)
+	if !strings.Contains(facts, "| destination | Lisbon | 0.90 | fact_scrubber | [[Lisbon Trip]] |") {
+		t.Errorf("facts index is missing the linked destination:\n%s", facts)
+	}
+	if strings.Contains(facts, "sk-secret") || !strings.Contains(facts, SecretMask) {
+		t.Errorf("facts index does not mask secrets:\n%s", facts)
+	}
+
+	// Nothing changed: nothing is rewritten
+	result, err = store.SyncVault(dir, ExportOptions{})
+	if err != nil || result.Changed() || result.Unchanged != 3 {
+		t.Fatalf("unchanged sync = %+v, %v", result, err)
+	}
+
+	// Deleting a topic removes its note and rewrites only what linked to it
+	if err := store.DeleteBridgeBlock(flights); err != nil {
+		t.Fatalf("DeleteBridgeBlock() error = %v", err)
+	}
+	result, err = store.SyncVault(dir, ExportOptions{})
+	if err != nil {
+		t.Fatalf("SyncVault() error = %v", err)
+	}
+	if !slices.Equal(result.Removed, []string{"Topics/Flights.md"}) || !slices.Equal(result.Written, []string{"Topics/Lisbon Trip.md"}) {
+		t.Errorf("sync after a delete = %+v, want the flights note removed and the trip note rewritten", result)
+	}
+	if read("My notes.md") != "mine" {
+		t.Error("sync touched a file it did not write")
+	}
+}
+
+func TestVaultNoteNames(t *testing.T) {
+	names := vaultNoteNames([]ExportBlock{
+		{BlockID: "block_b", TopicLabel: "Plans: Q3/Q4?", CreatedAt: "2025-01-02T00:00:00Z"},
+		{BlockID: "block_a", TopicLabel: "plans q3q4", CreatedAt: "2025-01-01T00:00:00Z"},
+		{BlockID: "block_c", TopicLabel: "[[]]", CreatedAt: "2025-01-03T00:00:00Z"},
+	})
+	if names["block_a"] != "plans q3q4" || names["block_b"] != "Plans Q3Q4 (block_b)" || names["block_c"] != "block_c" {
+		t.Errorf("names = %v", names)
+	}
+	if !isVaultNote("Topics/x.md") || isVaultNote("../x.md") || isVaultNote("Topics/../../x.md") || isVaultNote("notes.md") {
+		t.Error("isVaultNote accepts paths SyncVault never writes")
+	}
+}
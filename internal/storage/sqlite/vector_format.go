@@ -0,0 +1,358 @@
+// ABOUTME: Encodings for stored embedding vectors: exact float64, or float16 and int8 quantized
+// ABOUTME: Sets the database's encoding for new vectors and converts the ones already stored
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// VectorFormat is how an embedding vector is encoded in the embeddings table
+type VectorFormat string
+
+const (
+	VectorFloat64 VectorFormat = "float64" // 8 bytes a dimension, exact; the default
+	VectorFloat16 VectorFormat = "float16" // 2 bytes a dimension, about 3 significant digits
+	VectorInt8    VectorFormat = "int8"    // 1 byte a dimension plus a 4-byte scale per vector
+)
+
+// vectorFormatKey is the settings key holding the format new vectors are stored in
+const vectorFormatKey = "vector_format"
+
+// convertBatch is how many vectors ConvertVectors re-encodes per query
+const convertBatch = 500
+
+// ParseVectorFormat validates a vector format name
+func ParseVectorFormat(name string) (VectorFormat, error) {
+	switch format := VectorFormat(name); format {
+	case VectorFloat64, VectorFloat16, VectorInt8:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown vector format %q (want float64, float16, or int8)", name)
+}
+
+// vectorFormatOf reads an embeddings.format column, which is NULL for float64
+func vectorFormatOf(column sql.NullString) VectorFormat {
+	if !column.Valid || column.String == "" {
+		return VectorFloat64
+	}
+	return VectorFormat(column.String)
+}
+
+// column is the embeddings.format value for the format
+func (f VectorFormat) column() sql.NullString {
+	if f == VectorFloat64 {
+		return sql.NullString{}
+	}
+	return nullString(string(f))
+}
+
+// encodeVector encodes vector in format
+func encodeVector(format VectorFormat, vector []float64) []byte {
+	switch format {
+	case VectorFloat16:
+		blob := make([]byte, len(vector)*2)
+		for i, v := range vector {
+			binary.LittleEndian.PutUint16(blob[i*2:], float32ToHalf(float32(v)))
+		}
+		return blob
+	case VectorInt8:
+		// Values are stored as multiples of scale, the largest magnitude over 127
+		var maxAbs float64
+		for _, v := range vector {
+			maxAbs = math.Max(maxAbs, math.Abs(v))
+		}
+		scale := float32(maxAbs / 127)
+		blob := make([]byte, 4+len(vector))
+		binary.LittleEndian.PutUint32(blob, math.Float32bits(scale))
+		for i, v := range vector {
+			if scale != 0 {
+				blob[4+i] = byte(int8(math.Max(-127, math.Min(127, math.Round(v/float64(scale))))))
+			}
+		}
+		return blob
+	}
+	return vectorToBlob(vector)
+}
+
+// decodeVector decodes a vector stored in format
+func decodeVector(format VectorFormat, blob []byte) []float64 {
+	switch format {
+	case VectorFloat16:
+		vector := make([]float64, len(blob)/2)
+		for i := range vector {
+			vector[i] = float64(halfToFloat32(binary.LittleEndian.Uint16(blob[i*2:])))
+		}
+		return vector
+	case VectorInt8:
+		if len(blob) < 4 {
+			return []float64{}
+		}
+		scale := float64(math.Float32frombits(binary.LittleEndian.Uint32(blob)))
+		vector := make([]float64, len(blob)-4)
+		for i := range vector {
+			vector[i] = float64(int8(blob[4+i])) * scale
+		}
+		return vector
+	}
+	return blobToVector(blob)
+}
+
+// vectorDimension is the dimension of a vector stored in size bytes of format; ok is
+// false when no vector of that format takes that many bytes
+func vectorDimension(format VectorFormat, size int) (dim int, ok bool) {
+	switch format {
+	case VectorFloat16:
+		return size / 2, size%2 == 0
+	case VectorInt8:
+		return size - 4, size >= 4
+	case VectorFloat64:
+		return size / 8, size%8 == 0
+	}
+	return 0, false
+}
+
+// vectorSize is how many bytes a vector of dim dimensions takes in format
+func vectorSize(format VectorFormat, dim int) int {
+	switch format {
+	case VectorFloat16:
+		return dim * 2
+	case VectorInt8:
+		return 4 + dim
+	}
+	return dim * 8
+}
+
+// float32ToHalf converts f to IEEE 754 half precision, rounding to nearest even
+func float32ToHalf(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int((bits>>23)&0xff) - 127 + 15
+	mant := bits & 0x7fffff
+
+	switch {
+	case (bits>>23)&0xff == 0xff: // Inf and NaN
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	case exp >= 0x1f: // too large: infinity
+		return sign | 0x7c00
+	case exp <= 0: // subnormal, or too small: zero
+		if exp < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		shift := uint(14 - exp)
+		half := uint16(mant >> shift)
+		if rem, halfway := mant&(1<<shift-1), uint32(1)<<(shift-1); rem > halfway || (rem == halfway && half&1 == 1) {
+			half++
+		}
+		return sign | half
+	}
+	half := sign | uint16(exp)<<10 | uint16(mant>>13)
+	// A carry out of the mantissa correctly rounds up into the exponent
+	if rem := mant & 0x1fff; rem > 0x1000 || (rem == 0x1000 && half&1 == 1) {
+		half++
+	}
+	return half
+}
+
+// halfToFloat32 converts IEEE 754 half precision to a float32
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+	switch exp {
+	case 0: // zero and subnormals: mant × 2^-24
+		f := float32(mant) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	case 0x1f: // Inf and NaN
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	}
+	return math.Float32frombits(sign | (exp-15+127)<<23 | mant<<13)
+}
+
+// Format returns the format new vectors are stored in
+func (s *EmbeddingStore) Format() (VectorFormat, error) {
+	var value string
+	err := s.db.QueryRow("SELECT value FROM settings WHERE key = ?", vectorFormatKey).Scan(&value)
+	if err == sql.ErrNoRows {
+		return VectorFloat64, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read vector format: %w", err)
+	}
+	return VectorFormat(value), nil
+}
+
+// VectorFormatUsage is how many stored vectors use a format and the bytes they take
+type VectorFormatUsage struct {
+	Format  VectorFormat `json:"format"`
+	Vectors int          `json:"vectors"`
+	Bytes   int64        `json:"bytes"`
+}
+
+// VectorConversion reports a ConvertVectors run
+type VectorConversion struct {
+	Format      VectorFormat        `json:"format"`    // Format new and converted vectors are stored in
+	Previous    VectorFormat        `json:"previous"`  // Format new vectors were stored in before
+	Before      []VectorFormatUsage `json:"before"`    // Stored vectors by format before converting
+	Converted   int                 `json:"converted"` // Vectors re-encoded (or that would be, for a dry run)
+	BytesBefore int64               `json:"bytes_before"`
+	BytesAfter  int64               `json:"bytes_after"`
+	DryRun      bool                `json:"dry_run,omitempty"`
+}
+
+// VectorFormat returns the format new vectors are stored in
+func (s *Storage) VectorFormat() (VectorFormat, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.embeddings.Format()
+}
+
+// ConvertVectors makes format the database's vector format and re-encodes every stored
+// vector in another format into it, in one transaction, then reclaims the space freed.
+// Quantizing loses precision that converting back does not restore. With dryRun it only
+// reports what converting would do.
+func (s *Storage) ConvertVectors(format VectorFormat, dryRun bool) (*VectorConversion, error) {
+	if _, err := ParseVectorFormat(string(format)); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, err := s.embeddings.Format()
+	if err != nil {
+		return nil, err
+	}
+	result := &VectorConversion{Format: format, Previous: previous, DryRun: dryRun}
+
+	rows, err := s.db.Query(`
+		SELECT format, length(vector), COUNT(*) FROM embeddings
+		GROUP BY format, length(vector)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure stored vectors: %w", err)
+	}
+	usage := make(map[VectorFormat]*VectorFormatUsage)
+	for rows.Next() {
+		var (
+			column   sql.NullString
+			size, n  int
+			oldBytes int64
+		)
+		if err := rows.Scan(&column, &size, &n); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to measure stored vectors: %w", err)
+		}
+		from := vectorFormatOf(column)
+		if usage[from] == nil {
+			usage[from] = &VectorFormatUsage{Format: from}
+		}
+		oldBytes = int64(size) * int64(n)
+		usage[from].Vectors += n
+		usage[from].Bytes += oldBytes
+		result.BytesBefore += oldBytes
+
+		dim, ok := vectorDimension(from, size)
+		if from == format || !ok {
+			result.BytesAfter += oldBytes
+			continue
+		}
+		result.Converted += n
+		result.BytesAfter += int64(vectorSize(format, dim)) * int64(n)
+	}
+	err = rows.Err()
+	_ = rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure stored vectors: %w", err)
+	}
+	for _, u := range usage {
+		result.Before = append(result.Before, *u)
+	}
+	sort.Slice(result.Before, func(i, j int) bool { return result.Before[i].Format < result.Before[j].Format })
+	if dryRun {
+		return result, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`
+		INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
+	`, vectorFormatKey, string(format)); err != nil {
+		return nil, fmt.Errorf("failed to save vector format: %w", err)
+	}
+
+	// Batches walk the table in id order; malformed vectors are left as they are
+	type stored struct {
+		id     string
+		format VectorFormat
+		blob   []byte
+	}
+	after := ""
+	for {
+		rows, err := tx.Query(`
+			SELECT id, format, vector FROM embeddings
+			WHERE COALESCE(format, ?) != ? AND id > ?
+			ORDER BY id LIMIT ?
+		`, string(VectorFloat64), string(format), after, convertBatch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vectors to convert: %w", err)
+		}
+		var batch []stored
+		for rows.Next() {
+			var (
+				v      stored
+				column sql.NullString
+			)
+			if err := rows.Scan(&v.id, &column, &v.blob); err != nil {
+				_ = rows.Close()
+				return nil, fmt.Errorf("failed to read vector to convert: %w", err)
+			}
+			v.format = vectorFormatOf(column)
+			batch = append(batch, v)
+		}
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vectors to convert: %w", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, v := range batch {
+			if _, ok := vectorDimension(v.format, len(v.blob)); !ok {
+				continue
+			}
+			blob := encodeVector(format, decodeVector(v.format, v.blob))
+			if _, err := tx.Exec("UPDATE embeddings SET vector = ?, format = ? WHERE id = ?", blob, format.column(), v.id); err != nil {
+				return nil, fmt.Errorf("failed to convert vector %s: %w", v.id, err)
+			}
+		}
+		after = batch[len(batch)-1].id
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit vector conversion: %w", err)
+	}
+
+	if result.Converted > 0 {
+		if _, err := s.db.Exec("VACUUM"); err != nil {
+			return nil, fmt.Errorf("failed to reclaim space: %w", err)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,175 @@
+// ABOUTME: Tests for the float64, float16, and int8 vector formats
+// ABOUTME: Verifies half-precision rounding, quantization error, and converting stored vectors
+package sqlite
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestFloat16(t *testing.T) {
+	tests := []struct {
+		in   float32
+		want uint16
+	}{
+		{0, 0x0000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.5, 0x3800},
+		{65504, 0x7bff},                     // largest half
+		{65536, 0x7c00},                     // overflows to infinity
+		{float32(math.Pow(2, -24)), 0x0001}, // smallest subnormal
+		{float32(math.Pow(2, -26)), 0x0000}, // underflows to zero
+		{1 + 1.0/2048, 0x3c00},              // halfway rounds to even
+		{1 + 3.0/2048, 0x3c02},              // halfway rounds to even
+		{float32(math.Inf(-1)), 0xfc00},
+	}
+	for _, tt := range tests {
+		if got := float32ToHalf(tt.in); got != tt.want {
+			t.Errorf("float32ToHalf(%v) = %#04x, want %#04x", tt.in, got, tt.want)
+		}
+	}
+	for _, h := range []uint16{0x0000, 0x0001, 0x03ff, 0x3c00, 0xc000, 0x7bff, 0x7c00, 0x8001} {
+		if got := float32ToHalf(halfToFloat32(h)); got != h {
+			t.Errorf("float32ToHalf(halfToFloat32(%#04x)) = %#04x", h, got)
+		}
+	}
+	if nan := halfToFloat32(float32ToHalf(float32(math.NaN()))); !math.IsNaN(float64(nan)) {
+		t.Errorf("NaN round trip = %v, want NaN", nan)
+	}
+}
+
+func TestVectorFormats(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	vector := make([]float64, 256)
+	for i := range vector {
+		vector[i] = rng.NormFloat64() * 0.05
+	}
+
+	tests := []struct {
+		format  VectorFormat
+		size    int
+		maxDiff float64
+	}{
+		{VectorFloat64, 256 * 8, 0},
+		{VectorFloat16, 256 * 2, 1e-4},
+		{VectorInt8, 4 + 256, 0.002},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			blob := encodeVector(tt.format, vector)
+			if len(blob) != tt.size || vectorSize(tt.format, len(vector)) != tt.size {
+				t.Fatalf("encoded size = %d, want %d", len(blob), tt.size)
+			}
+			if dim, ok := vectorDimension(tt.format, len(blob)); !ok || dim != len(vector) {
+				t.Errorf("vectorDimension() = %d, %v; want %d", dim, ok, len(vector))
+			}
+			decoded := decodeVector(tt.format, blob)
+			if len(decoded) != len(vector) {
+				t.Fatalf("decoded %d dimensions, want %d", len(decoded), len(vector))
+			}
+			for i := range vector {
+				if diff := math.Abs(decoded[i] - vector[i]); diff > tt.maxDiff {
+					t.Fatalf("dimension %d decoded as %v, want within %v of %v", i, decoded[i], tt.maxDiff, vector[i])
+				}
+			}
+			if sim := CosineSimilarity(vector, decoded); sim < 0.999 {
+				t.Errorf("similarity to the original = %v, want at least 0.999", sim)
+			}
+		})
+	}
+
+	if zero := decodeVector(VectorInt8, encodeVector(VectorInt8, make([]float64, 4))); len(zero) != 4 || zero[0] != 0 {
+		t.Errorf("int8 zero vector decoded as %v", zero)
+	}
+	if _, err := ParseVectorFormat("int4"); err == nil {
+		t.Error("ParseVectorFormat(int4) succeeded, want an error")
+	}
+}
+
+func TestConvertVectors(t *testing.T) {
+	store, err := NewStorageInMemory()
+	if err != nil {
+		t.Fatalf("NewStorageInMemory() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_quantize", Timestamp: time.Now(), UserMessage: "hello"})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	rng := rand.New(rand.NewSource(2))
+	vectors := make([][]float64, 3)
+	for i := range vectors {
+		vectors[i] = make([]float64, 64)
+		for j := range vectors[i] {
+			vectors[i][j] = rng.NormFloat64()
+		}
+		if err := store.embeddings.SaveWithDimension(chunkName(i), "turn_quantize", blockID, vectors[i], 64); err != nil {
+			t.Fatalf("SaveWithDimension() error = %v", err)
+		}
+	}
+
+	if format, err := store.VectorFormat(); err != nil || format != VectorFloat64 {
+		t.Errorf("VectorFormat() = %q, %v; want float64 by default", format, err)
+	}
+
+	plan, err := store.ConvertVectors(VectorInt8, true)
+	if err != nil {
+		t.Fatalf("ConvertVectors(dry run) error = %v", err)
+	}
+	if plan.Converted != 3 || plan.BytesBefore != 3*64*8 || plan.BytesAfter != 3*(4+64) || len(plan.Before) != 1 {
+		t.Errorf("ConvertVectors(dry run) = %+v, want 3 vectors shrinking from %d to %d bytes", plan, 3*64*8, 3*(4+64))
+	}
+	if format, _ := store.VectorFormat(); format != VectorFloat64 {
+		t.Errorf("a dry run changed the format to %s", format)
+	}
+
+	done, err := store.ConvertVectors(VectorInt8, false)
+	if err != nil {
+		t.Fatalf("ConvertVectors() error = %v", err)
+	}
+	if done.Converted != 3 || done.Previous != VectorFloat64 || done.BytesAfter != plan.BytesAfter {
+		t.Errorf("ConvertVectors() = %+v, want the dry run's plan carried out", done)
+	}
+	if format, _ := store.VectorFormat(); format != VectorInt8 {
+		t.Errorf("VectorFormat() = %s after converting, want int8", format)
+	}
+
+	// New vectors are quantized too, and searches still rank the nearest first
+	if err := store.embeddings.SaveWithDimension(chunkName(3), "turn_quantize", blockID, vectors[0], 64); err != nil {
+		t.Fatalf("SaveWithDimension() error = %v", err)
+	}
+	var size int
+	if err := store.db.QueryRow("SELECT length(vector) FROM embeddings WHERE chunk_id = ?", chunkName(3)).Scan(&size); err != nil || size != 4+64 {
+		t.Errorf("new vector takes %d bytes, %v; want %d", size, err, 4+64)
+	}
+	results, err := store.embeddings.SearchSimilar(vectors[1], 1)
+	if err != nil || len(results) != 1 || results[0].ChunkID != chunkName(1) || results[0].SimilarityScore < 0.99 {
+		t.Errorf("SearchSimilar() = %+v, %v; want %s", results, err, chunkName(1))
+	}
+	if dims, err := store.EmbeddingDimensions(); err != nil || len(dims) != 1 || dims[64] != 4 {
+		t.Errorf("EmbeddingDimensions() = %v, %v; want 4 of dimension 64", dims, err)
+	}
+
+	// Converting back stores float64s again (without the precision quantizing lost)
+	back, err := store.ConvertVectors(VectorFloat64, false)
+	if err != nil || back.Converted != 4 || back.BytesAfter != 4*64*8 {
+		t.Errorf("ConvertVectors(float64) = %+v, %v; want 4 vectors back at 8 bytes a dimension", back, err)
+	}
+	emb, err := store.embeddings.GetByChunkID(chunkName(1))
+	if err != nil || emb == nil || CosineSimilarity(emb.Vector, vectors[1]) < 0.99 {
+		t.Errorf("GetByChunkID() = %+v, %v; want the vector back", emb, err)
+	}
+	if _, err := store.ConvertVectors("int4", false); err == nil {
+		t.Error("ConvertVectors(int4) succeeded, want an error")
+	}
+}
+
+func chunkName(i int) string {
+	return "chunk_quantize_" + string(rune('a'+i))
+}
@@ -0,0 +1,159 @@
+// ABOUTME: Deletes every row in the database and proves it by counting again
+// ABOUTME: Vacuums and checkpoints so deleted pages do not linger in the file or WAL, and removes the secret key
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// WipedTable is one table's row count before a wipe and after it
+type WipedTable struct {
+	Name      string `json:"name"`
+	Deleted   int64  `json:"deleted"`
+	Remaining int64  `json:"remaining"`
+}
+
+// WipeReport records what a wipe deleted; Verified is set once every table
+// has been counted again and found empty and the secret key is gone
+type WipeReport struct {
+	Database       string       `json:"database"`
+	WipedAt        time.Time    `json:"wiped_at"`
+	SchemaVersion  int          `json:"schema_version"`
+	Tables         []WipedTable `json:"tables"`
+	SecretKey      string       `json:"secret_key,omitempty"` // key file removed; empty for in-memory databases
+	SecretKeyGone  bool         `json:"secret_key_removed"`
+	Verified       bool         `json:"verified"`
+	VerifiedAt     *time.Time   `json:"verified_at,omitempty"`
+	RemainingTotal int64        `json:"remaining_rows"`
+}
+
+// Wipe deletes every row from every table, keeping the schema so the database
+// stays usable, then vacuums and truncates the WAL so deleted data is not left
+// in free pages. The secret key is deleted with it; any copy of a secret that
+// survived elsewhere can no longer be decrypted.
+func (s *Storage) Wipe() (*WipeReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	version, err := s.db.SchemaVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	tables, err := s.countRows()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin wipe: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Tables reference each other; check foreign keys once everything is gone
+	if _, err := tx.Exec("PRAGMA defer_foreign_keys = ON"); err != nil {
+		return nil, fmt.Errorf("failed to defer foreign keys: %w", err)
+	}
+	for i, table := range tables {
+		if _, err := tx.Exec(`DELETE FROM "` + table.Name + `"`); err != nil {
+			return nil, fmt.Errorf("failed to wipe %s: %w", table.Name, err)
+		}
+		tables[i].Deleted, tables[i].Remaining = table.Remaining, 0
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit wipe: %w", err)
+	}
+
+	// Counters this process gathered would be saved again on Close
+	for _, cache := range s.caches() {
+		cache.takeCounts()
+	}
+
+	if _, err := s.db.Exec("VACUUM"); err != nil {
+		return nil, fmt.Errorf("failed to vacuum: %w", err)
+	}
+	if s.db.path != ":memory:" {
+		if _, err := s.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+			return nil, fmt.Errorf("failed to checkpoint: %w", err)
+		}
+	}
+
+	report := &WipeReport{
+		Database:      s.db.path,
+		WipedAt:       s.db.now().UTC(),
+		SchemaVersion: version,
+		Tables:        tables,
+		SecretKey:     SecretKeyPath(s.db.path),
+	}
+	if report.SecretKey != "" {
+		if err := os.Remove(report.SecretKey); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove secret key: %w", err)
+		}
+	}
+	s.db.secrets.forget()
+	return report, nil
+}
+
+// VerifyWipe counts every table again and checks the secret key file is gone,
+// filling in the report's remaining rows and setting Verified when nothing is left
+func (s *Storage) VerifyWipe(report *WipeReport) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tables, err := s.countRows()
+	if err != nil {
+		return err
+	}
+	deleted := make(map[string]int64, len(report.Tables))
+	for _, table := range report.Tables {
+		deleted[table.Name] = table.Deleted
+	}
+	report.RemainingTotal = 0
+	for i := range tables {
+		tables[i].Deleted = deleted[tables[i].Name]
+		report.RemainingTotal += tables[i].Remaining
+	}
+	report.Tables = tables
+
+	report.SecretKeyGone = true
+	if report.SecretKey != "" {
+		if _, err := os.Stat(report.SecretKey); !errors.Is(err, os.ErrNotExist) {
+			report.SecretKeyGone = false
+		}
+	}
+
+	now := s.db.now().UTC()
+	report.VerifiedAt = &now
+	report.Verified = report.RemainingTotal == 0 && report.SecretKeyGone
+	return nil
+}
+
+// countRows lists every table with its row count in Remaining
+func (s *Storage) countRows() ([]WipedTable, error) {
+	rows, err := s.db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	var tables []WipedTable
+	for rows.Next() {
+		var table WipedTable
+		if err := rows.Scan(&table.Name); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	for i := range tables {
+		if err := s.db.QueryRow(`SELECT COUNT(*) FROM "` + tables[i].Name + `"`).Scan(&tables[i].Remaining); err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", tables[i].Name, err)
+		}
+	}
+	return tables, nil
+}
@@ -0,0 +1,74 @@
+// ABOUTME: Tests for wiping the database
+// ABOUTME: Verifies every table is emptied, the recount, removal of the secret key, and that the database stays usable
+
+package sqlite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/models"
+)
+
+func TestWipe(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+	store, err := NewStorageWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewStorageWithPath() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blockID, err := store.StoreTurn(&models.Turn{TurnID: "turn_wipe", Timestamp: time.Now(), UserMessage: "remember this"})
+	if err != nil {
+		t.Fatalf("StoreTurn() error = %v", err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_wipe", BlockID: blockID, Key: "api_token", Value: "abcdef", ValueType: models.ValueTypeSecret, Confidence: 1}); err != nil {
+		t.Fatalf("SaveFact() error = %v", err)
+	}
+	if err := store.SaveUserProfile(&models.UserProfile{Name: "Harper"}); err != nil {
+		t.Fatalf("SaveUserProfile() error = %v", err)
+	}
+
+	report, err := store.Wipe()
+	if err != nil {
+		t.Fatalf("Wipe() error = %v", err)
+	}
+	deleted := map[string]int64{}
+	for _, table := range report.Tables {
+		deleted[table.Name] = table.Deleted
+	}
+	if deleted["bridge_blocks"] != 1 || deleted["turns"] != 1 || deleted["facts"] != 1 || deleted["user_profile"] != 1 {
+		t.Errorf("deleted = %v, want the block, turn, fact, and profile", deleted)
+	}
+	if report.Verified {
+		t.Error("Verified set before VerifyWipe")
+	}
+
+	if err := store.VerifyWipe(report); err != nil {
+		t.Fatalf("VerifyWipe() error = %v", err)
+	}
+	if !report.Verified || report.RemainingTotal != 0 || !report.SecretKeyGone || report.VerifiedAt == nil {
+		t.Errorf("report = %+v, want a verified wipe", report)
+	}
+	if _, err := os.Stat(SecretKeyPath(dbPath)); !os.IsNotExist(err) {
+		t.Errorf("secret key still exists: %v", err)
+	}
+
+	// The schema survives, so memory keeps working, with a new secret key
+	if _, err := store.StoreTurn(&models.Turn{TurnID: "turn_after", Timestamp: time.Now(), UserMessage: "fresh start"}); err != nil {
+		t.Fatalf("StoreTurn() after wipe error = %v", err)
+	}
+	if err := store.SaveFact(&models.Fact{FactID: "fact_after", Key: "api_token", Value: "ghijkl", ValueType: models.ValueTypeSecret, Confidence: 1}); err != nil {
+		t.Fatalf("SaveFact() after wipe error = %v", err)
+	}
+	if fact, err := store.GetFactByKey("api_token"); err != nil || fact == nil || fact.Value != "ghijkl" {
+		t.Errorf("GetFactByKey() after wipe = %+v, %v", fact, err)
+	}
+
+	// A recount that finds rows does not verify
+	if err := store.VerifyWipe(report); err != nil || report.Verified || report.RemainingTotal == 0 {
+		t.Errorf("VerifyWipe() with rows left = %+v, %v; want it unverified", report, err)
+	}
+}
@@ -0,0 +1,168 @@
+// ABOUTME: Tracks writes per table so cached reads know when they are stale
+// ABOUTME: Funnels this process's writes through one connection whose data_version reveals other processes' commits
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// anyTable is the generation bumped by writes whose table is unknown, including
+// commits from other processes; every table version includes it
+const anyTable = "*"
+
+// writeTracker serializes this process's writes and counts them per table.
+// File databases write through a single dedicated connection: SQLite leaves
+// "PRAGMA data_version" unchanged on a connection for its own commits, so a change
+// seen there means another process wrote to the database.
+type writeTracker struct {
+	mu          sync.Mutex // Held for each Exec and for a transaction's whole life
+	writer      *sql.Conn  // nil for in-memory databases, which no other process can see
+	gens        map[string]uint64
+	dataVersion int64
+}
+
+// writeTargetPattern finds the table an INSERT, REPLACE, UPDATE, or DELETE writes
+var writeTargetPattern = regexp.MustCompile(`(?is)^\s*(?:INSERT(?:\s+OR\s+\w+)?\s+INTO|REPLACE\s+INTO|UPDATE(?:\s+OR\s+\w+)?|DELETE\s+FROM)\s+["\x60\[]?(\w+)`)
+
+// writtenTable returns the table query writes, or anyTable when it cannot tell
+func writtenTable(query string) string {
+	if m := writeTargetPattern.FindStringSubmatch(query); m != nil {
+		return strings.ToLower(m[1])
+	}
+	return anyTable
+}
+
+// openWriter dedicates a connection to writes and records its data_version
+func (db *DB) openWriter() error {
+	writer, err := db.conn.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	if err := writer.QueryRowContext(context.Background(), "PRAGMA data_version").Scan(&db.writes.dataVersion); err != nil {
+		_ = writer.Close()
+		return err
+	}
+	db.writes.writer = writer
+	return nil
+}
+
+// bump records writes to tables; the caller holds writes.mu
+func (db *DB) bump(tables ...string) {
+	if db.writes.gens == nil {
+		db.writes.gens = make(map[string]uint64)
+	}
+	for _, table := range tables {
+		db.writes.gens[table]++
+	}
+}
+
+// tableVersion returns a number that changes whenever one of tables is written by this
+// process or the database is written by another. It reports false while a write is in
+// flight, when a read may be about to go stale, so callers should skip caching.
+func (db *DB) tableVersion(tables ...string) (uint64, bool) {
+	if !db.writes.mu.TryLock() {
+		return 0, false
+	}
+	defer db.writes.mu.Unlock()
+	if !db.noteOtherWrites() {
+		return 0, false
+	}
+
+	sum := db.writes.gens[anyTable]
+	for _, table := range tables {
+		sum += db.writes.gens[table]
+	}
+	return sum, true
+}
+
+// version returns a number that changes whenever any table is written by this process
+// or the database is written by another; like tableVersion, it reports false while a
+// write is in flight
+func (db *DB) version() (uint64, bool) {
+	if !db.writes.mu.TryLock() {
+		return 0, false
+	}
+	defer db.writes.mu.Unlock()
+	if !db.noteOtherWrites() {
+		return 0, false
+	}
+
+	var sum uint64
+	for _, gen := range db.writes.gens {
+		sum += gen
+	}
+	return sum, true
+}
+
+// noteOtherWrites bumps anyTable when another process has committed since the last
+// check; the caller holds writes.mu. It reports false when the check fails.
+func (db *DB) noteOtherWrites() bool {
+	if db.writes.writer == nil {
+		return true
+	}
+	var version int64
+	if err := db.writes.writer.QueryRowContext(context.Background(), "PRAGMA data_version").Scan(&version); err != nil {
+		return false
+	}
+	if version != db.writes.dataVersion {
+		db.writes.dataVersion = version
+		db.bump(anyTable)
+	}
+	return true
+}
+
+// Tx is a transaction that records the tables it writes when it commits. It holds
+// the database's write lock until Commit or Rollback, so use only tx inside it.
+type Tx struct {
+	*sql.Tx
+	db     *DB
+	tables []string
+	done   bool
+}
+
+// Exec executes a write inside the transaction
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	tx.tables = append(tx.tables, writtenTable(query))
+	defer tx.db.observe(query, time.Now())
+	return tx.Tx.Exec(query, utcArgs(args)...)
+}
+
+// QueryRow executes a query inside the transaction that returns at most one row
+func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	defer tx.db.observe(query, time.Now())
+	return tx.Tx.QueryRow(query, args...)
+}
+
+// Commit commits the transaction and marks the tables it wrote as changed
+func (tx *Tx) Commit() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	err := tx.Tx.Commit()
+	if err == nil {
+		tx.db.bump(tx.tables...)
+	}
+	tx.release()
+	return err
+}
+
+// Rollback aborts the transaction; after Commit it does nothing and returns sql.ErrTxDone
+func (tx *Tx) Rollback() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	err := tx.Tx.Rollback()
+	tx.release()
+	return err
+}
+
+// release gives up the write lock once
+func (tx *Tx) release() {
+	tx.done = true
+	tx.db.writes.mu.Unlock()
+}
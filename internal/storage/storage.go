@@ -21,6 +21,9 @@ type VectorStorage = sqlite.EmbeddingStore
 // ExpectedEmbeddingDimension is the expected dimension for OpenAI embeddings
 const ExpectedEmbeddingDimension = sqlite.ExpectedDimension
 
+// SchemaVersion is the schema version this build migrates databases to
+const SchemaVersion = sqlite.SchemaVersion
+
 // NewStorage initializes storage with SQLite backend
 func NewStorage() (*Storage, error) {
 	return sqlite.NewStorage()
@@ -31,6 +34,11 @@ func NewStorageWithPath(dbPath string) (*Storage, error) {
 	return sqlite.NewStorageWithPath(dbPath)
 }
 
+// NewStorageReadOnly opens an existing database for reading; writes return an error
+func NewStorageReadOnly(dbPath string) (*Storage, error) {
+	return sqlite.NewStorageReadOnly(dbPath)
+}
+
 // NewStorageInMemory creates an in-memory storage (for testing)
 func NewStorageInMemory() (*Storage, error) {
 	return sqlite.NewStorageInMemory()
@@ -45,12 +53,18 @@ func NewVectorStorage(db *sqlite.DB) (*VectorStorage, error) {
 // SkipDimensionValidation can be set to true in tests to allow non-1536D vectors
 var SkipDimensionValidation = false
 
+// SearchOptions narrows a memory search by tags, affect, and origin
+type SearchOptions = sqlite.SearchOptions
+
 // ExportData represents the complete exportable data structure
 type ExportData = sqlite.ExportData
 
 // ExportProfile represents user profile for export
 type ExportProfile = sqlite.ExportProfile
 
+// ProfileImport counts what importing a profile added
+type ProfileImport = sqlite.ProfileImport
+
 // ExportBlock represents a bridge block for export
 type ExportBlock = sqlite.ExportBlock
 
@@ -60,6 +74,151 @@ type ExportTurn = sqlite.ExportTurn
 // ExportFact represents a fact for export
 type ExportFact = sqlite.ExportFact
 
+// ExportOptions selects what an export includes
+type ExportOptions = sqlite.ExportOptions
+
+// ExportManifest holds the checksum of each export section and an optional signature
+type ExportManifest = sqlite.ExportManifest
+
+// ErrNoManifest is returned when verifying an export that carries no manifest
+var ErrNoManifest = sqlite.ErrNoManifest
+
+// ExportDiff is the difference between two exports
+type ExportDiff = sqlite.ExportDiff
+
+// DiffSet lists the IDs added, removed, and changed for one kind of item
+type DiffSet = sqlite.DiffSet
+
+// DiffChange describes an item present in both exports whose fields differ
+type DiffChange = sqlite.DiffChange
+
+// LoadExport reads an export file written by 'memory export'
+func LoadExport(path string) (*ExportData, error) {
+	return sqlite.LoadExport(path)
+}
+
+// VerifyExport checks an export against its manifest checksums and signature
+func VerifyExport(data *ExportData) error {
+	return sqlite.VerifyExport(data)
+}
+
+// DiffExports compares export a (before) with export b (after)
+func DiffExports(a, b *ExportData) *ExportDiff {
+	return sqlite.DiffExports(a, b)
+}
+
+// ConflictStrategy is what importing an export does with a block or fact already
+// stored with different contents
+type ConflictStrategy = sqlite.ConflictStrategy
+
+// Conflict strategies: keep the stored item, replace it, combine them, or keep both
+const (
+	ConflictSkip      = sqlite.ConflictSkip
+	ConflictOverwrite = sqlite.ConflictOverwrite
+	ConflictMerge     = sqlite.ConflictMerge
+	ConflictRename    = sqlite.ConflictRename
+)
+
+// ConflictStrategies lists every strategy in the order prompts offer them
+var ConflictStrategies = sqlite.ConflictStrategies
+
+// ParseConflictStrategy parses a strategy name
+func ParseConflictStrategy(name string) (ConflictStrategy, error) {
+	return sqlite.ParseConflictStrategy(name)
+}
+
+// ImportConflict is an incoming block or fact whose ID is already stored with different contents
+type ImportConflict = sqlite.ImportConflict
+
+// ImportDecision is how one import conflict was resolved
+type ImportDecision = sqlite.ImportDecision
+
+// ImportOptions say how importing an export resolves conflicts
+type ImportOptions = sqlite.ImportOptions
+
+// ExportImport reports what importing an export did
+type ExportImport = sqlite.ExportImport
+
+// StorageStats contains aggregate statistics about stored memory
+type StorageStats = sqlite.StorageStats
+
+// TopicActivity summarizes how much conversation a topic has accumulated
+type TopicActivity = sqlite.TopicActivity
+
+// LLMUsage summarizes LLM calls for one operation and model
+type LLMUsage = sqlite.LLMUsage
+
+// CacheStats reports hits and misses for the block or profile cache
+type CacheStats = sqlite.CacheStats
+
+// JobQueueStats is the depth of the job queue by kind, with merged and dropped profile messages
+type JobQueueStats = sqlite.JobQueueStats
+
+// EvictedBlock is an archived block deleted, or planned for deletion, to keep the database under its size limit
+type EvictedBlock = sqlite.EvictedBlock
+
+// EvictionStats summarizes the archived blocks evicted so far
+type EvictionStats = sqlite.EvictionStats
+
+// CompactedBlock is an archived block whose turn bodies were, or would be, replaced by its summary and facts
+type CompactedBlock = sqlite.CompactedBlock
+
+// CompactionStats summarizes the archived blocks compacted so far
+type CompactionStats = sqlite.CompactionStats
+
+// BlockCentroid is the mean of a block's embeddings of one model
+type BlockCentroid = sqlite.BlockCentroid
+
+// UndoEntry is a deleted or edited piece of memory that 'memory undo' can put back
+type UndoEntry = sqlite.UndoEntry
+
+// Operations recorded in the undo log
+const (
+	UndoDeleteTopic      = sqlite.UndoDeleteTopic
+	UndoDeleteFacts      = sqlite.UndoDeleteFacts
+	UndoDeletePersona    = sqlite.UndoDeletePersona
+	UndoDeleteAttachment = sqlite.UndoDeleteAttachment
+	UndoEditTurn         = sqlite.UndoEditTurn
+)
+
+// ExpiredBlock is a block deleted, or due for deletion, because its retention policy ran out
+type ExpiredBlock = sqlite.ExpiredBlock
+
+// RetentionPolicies lists the retention policies set on blocks and tags
+type RetentionPolicies = sqlite.RetentionPolicies
+
+// BlockHeat is how often and how recently a block was retrieved or hydrated
+type BlockHeat = sqlite.BlockHeat
+
+// MemoryHealth summarizes what is stored and the signals that call for repair or consolidation
+type MemoryHealth = sqlite.MemoryHealth
+
+// TakeoutManifest describes a takeout directory: what it holds and each file's checksum
+type TakeoutManifest = sqlite.TakeoutManifest
+
+// TakeoutFile is one file in a takeout with its size and SHA-256
+type TakeoutFile = sqlite.TakeoutFile
+
+// WipeReport records what a wipe deleted and whether a recount found anything left
+type WipeReport = sqlite.WipeReport
+
+// WipedTable is one table's row count before a wipe and after it
+type WipedTable = sqlite.WipedTable
+
+// TopicLabelCount is a topic label in use with the number of blocks carrying it
+type TopicLabelCount = sqlite.TopicLabelCount
+
+// TagCount is one tag with the number of blocks and facts carrying it
+type TagCount = sqlite.TagCount
+
+// PendingTurn is a turn that still needs embeddings, with the block it belongs to
+type PendingTurn = sqlite.PendingTurn
+
+// SortedCategories returns fact category names ordered by count (descending)
+func SortedCategories(counts map[string]int) []string {
+	return sqlite.SortedCategories(counts)
+}
+
 // Helper function for tests that need to work with turns from blocks
 func GetTurnsFromBlock(block *models.BridgeBlock) []models.Turn {
 	return block.Turns
@@ -69,3 +228,107 @@ func GetTurnsFromBlock(block *models.BridgeBlock) []models.Turn {
 func DefaultDBPath() string {
 	return sqlite.DefaultDBPath()
 }
+
+// EventPublisher receives memory events after the writes they describe
+type EventPublisher = sqlite.EventPublisher
+
+// Subscription is a channel of memory events from the change feed
+type Subscription = sqlite.Subscription
+
+// SubscribeOptions choose which events a subscription receives and whether it may drop them
+type SubscribeOptions = sqlite.SubscribeOptions
+
+// VaultSync reports what one Markdown vault sync wrote, removed, and left unchanged
+type VaultSync = sqlite.VaultSync
+
+// VaultTopicsDir and VaultFactsFile are where a vault sync writes topic notes and the facts index
+const (
+	VaultTopicsDir = sqlite.VaultTopicsDir
+	VaultFactsFile = sqlite.VaultFactsFile
+)
+
+// IngestedFile records a file stored as a note by 'memory ingest'
+type IngestedFile = sqlite.IngestedFile
+
+// EmbeddingImport reports what an import of the JSON embeddings export restored
+type EmbeddingImport = sqlite.EmbeddingImport
+
+// Digest reports a Markdown digest of the topics and facts of a period
+type Digest = sqlite.Digest
+
+// ProfileVersion is the shared profile or a persona as it was saved at one time
+type ProfileVersion = sqlite.ProfileVersion
+
+// HistoryEntry is one change in memory's history: a fact value or a profile version
+type HistoryEntry = sqlite.HistoryEntry
+
+// ProfileChange is one entry added to or removed from the profile or a persona
+type ProfileChange = sqlite.ProfileChange
+
+// ProfileDiff is every profile change saved over a time range
+type ProfileDiff = sqlite.ProfileDiff
+
+// MemorySnapshot is the facts and profile as they were at a past time
+type MemorySnapshot = sqlite.MemorySnapshot
+
+// RecallExclusions lists the blocks and fact keys excluded from recall
+type RecallExclusions = sqlite.RecallExclusions
+
+// ExcludedBlock is a block excluded from recall
+type ExcludedBlock = sqlite.ExcludedBlock
+
+// RoutingStats summarizes logged routing decisions
+type RoutingStats = sqlite.RoutingStats
+
+// RoutingScenarioStats summarizes the logged decisions of one routing scenario
+type RoutingScenarioStats = sqlite.RoutingScenarioStats
+
+// ArchiveFilter selects the blocks Storage.ArchiveBlocks archives
+type ArchiveFilter = sqlite.ArchiveFilter
+
+// ArchivedBlock is a block archived by Storage.ArchiveBlocks
+type ArchivedBlock = sqlite.ArchivedBlock
+
+// VectorFormat is how stored embedding vectors are encoded
+type VectorFormat = sqlite.VectorFormat
+
+// Vector formats: exact float64, and the float16 and int8 quantizations
+const (
+	VectorFloat64 = sqlite.VectorFloat64
+	VectorFloat16 = sqlite.VectorFloat16
+	VectorInt8    = sqlite.VectorInt8
+)
+
+// VectorConversion reports a Storage.ConvertVectors run
+type VectorConversion = sqlite.VectorConversion
+
+// VectorFormatUsage is how many stored vectors use a format and the bytes they take
+type VectorFormatUsage = sqlite.VectorFormatUsage
+
+// ParseVectorFormat validates a vector format name
+func ParseVectorFormat(name string) (VectorFormat, error) {
+	return sqlite.ParseVectorFormat(name)
+}
+
+// KeywordMatch counts how many of a set of terms matched one block's keywords
+type KeywordMatch = sqlite.KeywordMatch
+
+// KeywordTerms returns the distinct terms among keywords, trimmed and lowercased
+func KeywordTerms(keywords []string) []string {
+	return sqlite.KeywordTerms(keywords)
+}
+
+// Activity is turns per day and each week's busiest topics, for the activity heatmap
+type Activity = sqlite.Activity
+
+// DayActivity is how many turns were stored on one day
+type DayActivity = sqlite.DayActivity
+
+// WeekActivity is one week of activity with its busiest topics
+type WeekActivity = sqlite.WeekActivity
+
+// TopicTurns is how many turns a topic got within some period
+type TopicTurns = sqlite.TopicTurns
+
+// TurnCountMismatch is a block whose recorded turn count differs from the turns it holds
+type TurnCountMismatch = sqlite.TurnCountMismatch
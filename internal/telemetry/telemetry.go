@@ -0,0 +1,112 @@
+// ABOUTME: Opt-in, local-first usage telemetry for commands and MCP tools
+// ABOUTME: Counts feature use per day in the database and, when shared, posts only daily aggregates
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/storage/sqlite"
+)
+
+// Telemetry modes, set by the telemetry config key
+const (
+	ModeOff   = "off"   // Record nothing
+	ModeLocal = "local" // Count feature use in the database only
+	ModeShare = "share" // Also post completed days' counts to the telemetry endpoint
+)
+
+// Store persists daily feature counts; *storage.Storage implements it
+type Store interface {
+	RecordFeatureUse(feature string) error
+	UnreportedFeatureUsage(beforeDay string) ([]sqlite.FeatureDay, error)
+	MarkFeatureUsageReported(beforeDay string) error
+}
+
+// Report is the body posted to the telemetry endpoint. It holds feature names and counts only:
+// no messages, queries, keys, paths, or identifiers.
+type Report struct {
+	Version string              `json:"version"`
+	OS      string              `json:"os"`
+	Arch    string              `json:"arch"`
+	Days    []sqlite.FeatureDay `json:"days"`
+}
+
+// Telemetry records feature use according to its mode
+type Telemetry struct {
+	mode     string
+	endpoint string
+	version  string
+	store    Store
+	client   *http.Client
+	now      func() time.Time
+}
+
+// New creates a Telemetry; a nil store behaves like ModeOff
+func New(mode, endpoint, version string, store Store) *Telemetry {
+	return &Telemetry{
+		mode:     mode,
+		endpoint: endpoint,
+		version:  version,
+		store:    store,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		now:      time.Now,
+	}
+}
+
+// Enabled reports whether feature use is being recorded
+func (t *Telemetry) Enabled() bool {
+	return t != nil && t.store != nil && (t.mode == ModeLocal || t.mode == ModeShare)
+}
+
+// Mode returns the configured telemetry mode
+func (t *Telemetry) Mode() string {
+	return t.mode
+}
+
+// Record counts one use of feature, such as "cli.search" or "mcp.retrieve_memory"
+func (t *Telemetry) Record(feature string) error {
+	if !t.Enabled() {
+		return nil
+	}
+	return t.store.RecordFeatureUse(feature)
+}
+
+// Report posts the counts for every completed, unreported day and marks them reported.
+// It returns the number of daily counts sent; outside ModeShare it sends nothing.
+func (t *Telemetry) Report() (int, error) {
+	if !t.Enabled() || t.mode != ModeShare {
+		return 0, nil
+	}
+
+	today := t.now().UTC().Format("2006-01-02")
+	days, err := t.store.UnreportedFeatureUsage(today)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read feature usage: %w", err)
+	}
+	if len(days) == 0 {
+		return 0, nil
+	}
+
+	body, err := json.Marshal(Report{Version: t.version, OS: runtime.GOOS, Arch: runtime.GOARCH, Days: days})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode telemetry report: %w", err)
+	}
+	resp, err := t.client.Post(t.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to send telemetry report: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("telemetry endpoint returned %s", resp.Status)
+	}
+
+	if err := t.store.MarkFeatureUsageReported(today); err != nil {
+		return 0, fmt.Errorf("failed to mark feature usage reported: %w", err)
+	}
+	return len(days), nil
+}
@@ -0,0 +1,96 @@
+// ABOUTME: Tests for opt-in telemetry
+// ABOUTME: Verifies modes gate recording and that reports carry only completed days' counts
+
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/storage/sqlite"
+)
+
+func newStore(t *testing.T) *sqlite.Storage {
+	t.Helper()
+	store, err := sqlite.NewStorageInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestRecord_Modes(t *testing.T) {
+	for _, tt := range []struct {
+		mode string
+		want int
+	}{
+		{ModeOff, 0},
+		{ModeLocal, 1},
+		{ModeShare, 1},
+	} {
+		store := newStore(t)
+		tel := New(tt.mode, "", "test", store)
+		if err := tel.Record("cli.search"); err != nil {
+			t.Fatalf("%s: Record() error = %v", tt.mode, err)
+		}
+		usage, _ := store.GetFeatureUsage()
+		if len(usage) != tt.want {
+			t.Errorf("%s: recorded %d features, want %d", tt.mode, len(usage), tt.want)
+		}
+	}
+
+	var nilTel *Telemetry
+	if nilTel.Enabled() {
+		t.Error("nil Telemetry should be disabled")
+	}
+}
+
+func TestReport_SendsCompletedDays(t *testing.T) {
+	var got Report
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	store := newStore(t)
+	tel := New(ModeShare, server.URL, "1.2.3", store)
+	_ = tel.Record("mcp.retrieve_memory")
+
+	// Today's counts are still growing, so they wait
+	if n, err := tel.Report(); err != nil || n != 0 {
+		t.Fatalf("Report() = %d, %v; want nothing sent for today", n, err)
+	}
+
+	tel.now = func() time.Time { return time.Now().Add(48 * time.Hour) }
+	n, err := tel.Report()
+	if err != nil || n != 1 {
+		t.Fatalf("Report() = %d, %v; want 1 daily count", n, err)
+	}
+	if got.Version != "1.2.3" || len(got.Days) != 1 || got.Days[0].Feature != "mcp.retrieve_memory" {
+		t.Errorf("report = %+v, want one mcp.retrieve_memory count", got)
+	}
+
+	// Reported days are not sent again
+	if n, _ := tel.Report(); n != 0 {
+		t.Errorf("second Report() sent %d counts, want 0", n)
+	}
+}
+
+func TestReport_LocalNeverSends(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("local mode must not contact the endpoint")
+	}))
+	defer server.Close()
+
+	store := newStore(t)
+	tel := New(ModeLocal, server.URL, "test", store)
+	_ = tel.Record("cli.add")
+	tel.now = func() time.Time { return time.Now().Add(48 * time.Hour) }
+	if n, err := tel.Report(); err != nil || n != 0 {
+		t.Errorf("Report() = %d, %v; want nothing in local mode", n, err)
+	}
+}
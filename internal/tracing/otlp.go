@@ -0,0 +1,288 @@
+// ABOUTME: Exports ended spans to an OpenTelemetry collector over OTLP/HTTP with JSON encoding
+// ABOUTME: Batches spans in memory and posts them periodically, on a full batch, and at shutdown
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBatchSize     = 256             // Spans per request; a full batch is sent right away
+	defaultFlushInterval = 5 * time.Second // Longest a span waits before it is sent
+	maxPendingSpans      = 4096            // Spans kept while the collector is unreachable; older ones are dropped
+)
+
+// Exporter sends spans to an OTLP/HTTP endpoint
+type Exporter struct {
+	url     string
+	service string
+	version string
+	client  *http.Client
+
+	mu      sync.Mutex
+	pending []*Span
+	kick    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewExporter creates an exporter posting to endpoint, the collector's base URL
+// (e.g. http://localhost:4318, as in OTEL_EXPORTER_OTLP_ENDPOINT), and starts its
+// background flusher. Spans are reported under service and version.
+func NewExporter(endpoint, service, version string) *Exporter {
+	url := strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url += "/v1/traces"
+	}
+	e := &Exporter{
+		url:     url,
+		service: service,
+		version: version,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		kick:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go e.run(defaultFlushInterval)
+	return e
+}
+
+// add queues an ended span
+func (e *Exporter) add(s *Span) {
+	e.mu.Lock()
+	if len(e.pending) >= maxPendingSpans {
+		e.pending = e.pending[1:]
+	}
+	e.pending = append(e.pending, s)
+	full := len(e.pending) >= defaultBatchSize
+	e.mu.Unlock()
+
+	if full {
+		select {
+		case e.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run flushes pending spans on every tick or full batch until Shutdown
+func (e *Exporter) run(interval time.Duration) {
+	defer close(e.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-e.kick:
+		case <-e.stop:
+			return
+		}
+		if err := e.Flush(context.Background()); err != nil {
+			log.Printf("Warning: exporting traces: %v", err)
+		}
+	}
+}
+
+// Flush sends every pending span. Spans that fail to send are kept for the next flush.
+func (e *Exporter) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	batch := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+
+	for len(batch) > 0 {
+		n := min(len(batch), defaultBatchSize)
+		if err := e.send(ctx, batch[:n]); err != nil {
+			e.mu.Lock()
+			e.pending = append(batch, e.pending...)
+			if over := len(e.pending) - maxPendingSpans; over > 0 {
+				e.pending = e.pending[over:]
+			}
+			e.mu.Unlock()
+			return err
+		}
+		batch = batch[n:]
+	}
+	return nil
+}
+
+// Shutdown stops the background flusher and sends what is left
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	e.once.Do(func() { close(e.stop) })
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return e.Flush(ctx)
+}
+
+// send posts one batch of spans
+func (e *Exporter) send(ctx context.Context, spans []*Span) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return fmt.Errorf("encoding spans: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting spans: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned %s", resp.Status)
+	}
+	return nil
+}
+
+// OTLP JSON types: the protobuf ExportTraceServiceRequest in its JSON mapping, where
+// IDs are hex strings and 64-bit integers are decimal strings
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+const (
+	spanKindInternal = 1
+	spanKindServer   = 2
+	statusError      = 2
+)
+
+// request builds the OTLP request body for spans
+func (e *Exporter) request(spans []*Span) otlpRequest {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		span := otlpSpan{
+			TraceID:           s.TraceID,
+			SpanID:            s.SpanID,
+			ParentSpanID:      s.ParentID,
+			Name:              s.Name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.EndTime.UnixNano(), 10),
+			Attributes:        keyValues(s.Attrs),
+		}
+		if s.ParentID == "" {
+			span.Kind = spanKindServer
+		}
+		if s.Err != "" {
+			span.Status = otlpStatus{Code: statusError, Message: s.Err}
+		}
+		s.mu.Unlock()
+		out = append(out, span)
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: keyValues([]Attr{
+			String("service.name", e.service),
+			String("service.version", e.version),
+		})},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: "github.com/harper/remember-standalone/internal/tracing", Version: e.version},
+			Spans: out,
+		}},
+	}}}
+}
+
+// keyValues converts attributes to their OTLP form
+func keyValues(attrs []Attr) []otlpKeyValue {
+	kvs := make([]otlpKeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		var v otlpValue
+		switch x := a.Value.(type) {
+		case string:
+			v.StringValue = &x
+		case int64:
+			s := strconv.FormatInt(x, 10)
+			v.IntValue = &s
+		case float64:
+			v.DoubleValue = &x
+		case bool:
+			v.BoolValue = &x
+		default:
+			s := fmt.Sprint(x)
+			v.StringValue = &s
+		}
+		kvs = append(kvs, otlpKeyValue{Key: a.Key, Value: v})
+	}
+	return kvs
+}
+
+// Setup installs an exporter for endpoint and returns a function that flushes and
+// removes it. An empty endpoint leaves tracing off and returns a no-op.
+func Setup(endpoint, service, version string) (shutdown func(context.Context) error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }
+	}
+	e := NewExporter(endpoint, service, version)
+	SetExporter(e)
+	return func(ctx context.Context) error {
+		SetExporter(nil)
+		return e.Shutdown(ctx)
+	}
+}
@@ -0,0 +1,148 @@
+// ABOUTME: Lightweight spans carried on context.Context for timing the MCP → Governor → Storage → LLM chain
+// ABOUTME: Spans are dropped unless an exporter is installed, so untraced runs pay almost nothing
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Attr is a span attribute; values are strings, ints, floats, or bools
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// String returns a string attribute
+func String(key, value string) Attr { return Attr{Key: key, Value: value} }
+
+// Int returns an integer attribute
+func Int(key string, value int) Attr { return Attr{Key: key, Value: int64(value)} }
+
+// Float returns a floating point attribute
+func Float(key string, value float64) Attr { return Attr{Key: key, Value: value} }
+
+// Bool returns a boolean attribute
+func Bool(key string, value bool) Attr { return Attr{Key: key, Value: value} }
+
+// Span times one operation. A nil *Span is valid and ignores every call, which is what
+// Start returns when tracing is off.
+type Span struct {
+	Name      string
+	TraceID   string // 32 hex characters, shared by every span in the trace
+	SpanID    string // 16 hex characters
+	ParentID  string // Empty for a trace's root span
+	StartTime time.Time
+	EndTime   time.Time
+	Attrs     []Attr
+	Err       string // Set by RecordError; marks the span as failed
+
+	mu       sync.Mutex
+	ended    bool
+	exporter *Exporter
+}
+
+// exporter is the installed exporter, or nil when tracing is off
+var exporter atomic.Pointer[Exporter]
+
+// SetExporter installs e as the destination for ended spans; nil turns tracing off
+func SetExporter(e *Exporter) {
+	exporter.Store(e)
+}
+
+// Enabled reports whether spans are being recorded
+func Enabled() bool {
+	return exporter.Load() != nil
+}
+
+type spanKey struct{}
+
+// Start begins a span named name, a child of the span in ctx if there is one, and
+// returns a context carrying it. Call End on the span when the operation finishes.
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	e := exporter.Load()
+	if e == nil {
+		return ctx, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	span := &Span{
+		Name:      name,
+		SpanID:    randomHex(8),
+		StartTime: time.Now(),
+		Attrs:     attrs,
+		exporter:  e,
+	}
+	if parent := FromContext(ctx); parent != nil {
+		span.TraceID, span.ParentID = parent.TraceID, parent.SpanID
+	} else {
+		span.TraceID = randomHex(16)
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// FromContext returns the span carried by ctx, or nil
+func FromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Attrs = append(s.Attrs, attrs...)
+}
+
+// RecordError marks the span as failed with err; a nil err is ignored
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Err = err.Error()
+}
+
+// End finishes the span and hands it to the exporter. Later calls do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+	s.exporter.add(s)
+}
+
+// Duration returns how long the span ran, or zero before End
+func (s *Span) Duration() time.Duration {
+	if s == nil || s.EndTime.IsZero() {
+		return 0
+	}
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// randomHex returns n random bytes as hex
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,147 @@
+// ABOUTME: Tests for spans and the OTLP exporter
+// ABOUTME: Verifies parent/child linkage through context and the OTLP/HTTP JSON request body
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// collector is a fake OTLP/HTTP endpoint that keeps every request body
+type collector struct {
+	mu       sync.Mutex
+	paths    []string
+	requests []otlpRequest
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req otlpRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paths = append(c.paths, r.URL.Path)
+	c.requests = append(c.requests, req)
+}
+
+func (c *collector) spans() []otlpSpan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var spans []otlpSpan
+	for _, req := range c.requests {
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+	}
+	return spans
+}
+
+func TestStart_OffReturnsNilSpan(t *testing.T) {
+	SetExporter(nil)
+	ctx, span := Start(context.Background(), "noop")
+	if span != nil || FromContext(ctx) != nil || Enabled() {
+		t.Fatal("Start with tracing off should return no span")
+	}
+	// Every method is safe on the nil span
+	span.SetAttributes(String("k", "v"))
+	span.RecordError(errors.New("boom"))
+	span.End()
+}
+
+func TestExporter_ExportsNestedSpans(t *testing.T) {
+	c := &collector{}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	shutdown := Setup(server.URL, "memory", "1.2.3")
+	ctx, root := Start(context.Background(), "mcp.store_conversation")
+	childCtx, child := Start(ctx, "governor.route", String("routing.scenario", "topic_shift"))
+	_, grandchild := Start(childCtx, "storage.embed", Int("embedding.chunks", 2), Bool("cached", false), Float("score", 0.5))
+	grandchild.RecordError(errors.New("rate limited"))
+	grandchild.End()
+	child.End()
+	root.End()
+	root.End() // a second End is ignored
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+	if Enabled() {
+		t.Error("shutdown should turn tracing off")
+	}
+
+	if len(c.paths) != 1 || c.paths[0] != "/v1/traces" {
+		t.Fatalf("requests to %v, want one to /v1/traces", c.paths)
+	}
+	res := c.requests[0].ResourceSpans[0].Resource.Attributes
+	if len(res) == 0 || res[0].Key != "service.name" || *res[0].Value.StringValue != "memory" {
+		t.Errorf("resource attributes = %+v, want service.name memory", res)
+	}
+
+	spans := c.spans()
+	if len(spans) != 3 {
+		t.Fatalf("exported %d spans, want 3", len(spans))
+	}
+	byName := map[string]otlpSpan{}
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+	r, ch, g := byName["mcp.store_conversation"], byName["governor.route"], byName["storage.embed"]
+	if r.ParentSpanID != "" || r.Kind != spanKindServer {
+		t.Errorf("root = %+v, want a server span without parent", r)
+	}
+	if ch.ParentSpanID != r.SpanID || g.ParentSpanID != ch.SpanID {
+		t.Error("children should point at their parents' span IDs")
+	}
+	if ch.TraceID != r.TraceID || g.TraceID != r.TraceID || len(r.TraceID) != 32 || len(r.SpanID) != 16 {
+		t.Errorf("trace IDs = %s, %s, %s; want one shared 32-character ID", r.TraceID, ch.TraceID, g.TraceID)
+	}
+	if g.Status.Code != statusError || g.Status.Message != "rate limited" {
+		t.Errorf("status = %+v, want error with message", g.Status)
+	}
+	if len(g.Attributes) != 3 || *g.Attributes[0].Value.IntValue != "2" || *g.Attributes[1].Value.BoolValue || *g.Attributes[2].Value.DoubleValue != 0.5 {
+		t.Errorf("attributes = %+v, want int, bool, and double values", g.Attributes)
+	}
+	if r.StartTimeUnixNano == "" || r.EndTimeUnixNano < r.StartTimeUnixNano {
+		t.Errorf("times = %s..%s, want a valid range", r.StartTimeUnixNano, r.EndTimeUnixNano)
+	}
+}
+
+func TestExporter_KeepsSpansWhenCollectorFails(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	c := &collector{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		c.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	e := NewExporter(server.URL+"/v1/traces", "memory", "test")
+	SetExporter(e)
+	t.Cleanup(func() { SetExporter(nil) })
+
+	_, span := Start(context.Background(), "retrieve_memory")
+	span.End()
+	if err := e.Flush(context.Background()); err == nil {
+		t.Fatal("Flush() should report the collector error")
+	}
+
+	fail.Store(false)
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if spans := c.spans(); len(spans) != 1 || spans[0].Name != "retrieve_memory" {
+		t.Errorf("exported %+v, want the span kept from the failed flush", spans)
+	}
+}
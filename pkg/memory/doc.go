@@ -0,0 +1,31 @@
+// ABOUTME: Package documentation for the public Go SDK and its compatibility promise
+// ABOUTME: Explains what pkg/memory covers and how it is versioned relative to internal packages
+
+// Package memory embeds HMLR memory in a Go program: store conversation turns,
+// routed into topics the same way the MCP server routes them, search them by
+// meaning, keep facts and a user profile, and build the memory section of a
+// prompt.
+//
+//	mem, err := memory.Open(memory.Options{Path: "memory.db", Provider: provider})
+//	if err != nil {
+//		return err
+//	}
+//	defer mem.Close()
+//
+//	stored, err := mem.Remember(ctx, memory.Turn{UserMessage: "I moved to Lisbon"})
+//	memories, err := mem.Search(ctx, "where do I live?", memory.SearchOptions{})
+//	prompt, err := mem.Hydrate(ctx, "where do I live?", 1500)
+//
+// A database written through this package is the same database the memory
+// CLI and MCP server use, so the three can share one file.
+//
+// # Compatibility
+//
+// Everything exported from this package follows semantic versioning with the
+// module's release tags: within a major version, exported identifiers are not
+// removed or renamed, function signatures do not change, and interfaces gain
+// no methods. New types, functions, and struct fields may be added in minor
+// releases, so construct option and input structs with field names. Packages
+// under internal/ carry no such promise and cannot be imported from outside
+// this module.
+package memory
@@ -0,0 +1,320 @@
+// ABOUTME: Public Go SDK over HMLR storage, routing, search, facts, profile, and context hydration
+// ABOUTME: Curated types and interfaces with a semver promise; internal packages stay free to change
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/harper/remember-standalone/internal/core"
+	"github.com/harper/remember-standalone/internal/models"
+	"github.com/harper/remember-standalone/internal/storage"
+)
+
+// DefaultMaxResults is how many memories Search returns when SearchOptions gives no limit
+const DefaultMaxResults = 5
+
+// ErrReadOnly is returned by writes to memory opened with Options.ReadOnly
+var ErrReadOnly = errors.New("memory is opened read-only")
+
+// Store keeps conversation turns, facts, and the user profile
+type Store interface {
+	Remember(ctx context.Context, turn Turn) (Stored, error)
+	AddFact(ctx context.Context, fact Fact) (Fact, error)
+	Fact(ctx context.Context, key string) (*Fact, error)
+	DeleteFact(ctx context.Context, key string) (int, error)
+	Profile(ctx context.Context, persona string) (*Profile, error)
+	Close() error
+}
+
+// Retriever finds stored memories related to a query
+type Retriever interface {
+	Search(ctx context.Context, query string, opts SearchOptions) ([]Memory, error)
+}
+
+// Hydrator builds the memory section of a prompt for a new message
+type Hydrator interface {
+	Hydrate(ctx context.Context, message string, maxTokens int) (string, error)
+}
+
+// Turn is one exchange to remember. Keywords and Topics steer routing into topics;
+// when both are empty and the Provider can extract metadata, it fills them in.
+type Turn struct {
+	UserMessage string
+	AIResponse  string
+	Keywords    []string
+	Topics      []string
+}
+
+// Stored reports where Remember put a turn
+type Stored struct {
+	TurnID   string
+	TopicID  string
+	Scenario string // topic_continuation, topic_resumption, topic_shift, or new_topic_first
+}
+
+// Memory is a topic found by Search
+type Memory struct {
+	TopicID string
+	Label   string
+	Summary string
+	Score   float64
+	Tags    []string
+}
+
+// SearchOptions narrow a search; the zero value returns DefaultMaxResults of anything
+type SearchOptions struct {
+	MaxResults int
+	Tags       []string // keep only topics carrying every tag
+}
+
+// Fact is a key-value fact about the user. The most recent fact with a key is the current one.
+type Fact struct {
+	ID         string
+	Key        string
+	Value      string
+	Confidence float64 // 0 to 1; AddFact uses 1 when zero
+	Agent      string  // who recorded it
+	CreatedAt  time.Time
+}
+
+// Profile is what memory has learned about the user, with a persona's layer applied
+type Profile struct {
+	Name             string
+	Preferences      []string
+	TopicsOfInterest []string
+	Constraints      []Constraint
+	Persona          string
+}
+
+// Constraint is a rule the user lives by, such as a dietary restriction
+type Constraint struct {
+	Type        string
+	Description string
+	Severity    string // strict, moderate, mild, or empty
+}
+
+// Options configure Open
+type Options struct {
+	Path     string   // SQLite database file; empty opens a private in-memory database
+	ReadOnly bool     // open an existing database without writing to it
+	Provider Provider // enables semantic search and metadata extraction; nil searches by keyword only
+	Persona  string   // profile persona Hydrate applies
+	Agent    string   // recorded on every turn and fact this client writes, unless a Fact names its own
+}
+
+// Client implements every interface
+var (
+	_ Store     = (*Client)(nil)
+	_ Retriever = (*Client)(nil)
+	_ Hydrator  = (*Client)(nil)
+)
+
+// Client is memory opened from a database; it is safe for concurrent use
+type Client struct {
+	store    *storage.Storage
+	governor *core.Governor
+	provider Provider
+	opts     Options
+}
+
+// Open opens the memory database described by opts, creating and migrating it as needed
+func Open(opts Options) (*Client, error) {
+	var store *storage.Storage
+	var err error
+	switch {
+	case opts.ReadOnly && opts.Path == "":
+		return nil, errors.New("a read-only memory needs a database path")
+	case opts.ReadOnly:
+		store, err = storage.NewStorageReadOnly(opts.Path)
+	case opts.Path == "":
+		store, err = storage.NewStorageInMemory()
+	default:
+		store, err = storage.NewStorageWithPath(opts.Path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory: %w", err)
+	}
+	if opts.Provider != nil {
+		store.SetOpenAIClient(opts.Provider)
+		store.SetChunkEngine(core.NewChunkEngine())
+	}
+	return &Client{store: store, governor: core.NewGovernor(store), provider: opts.Provider, opts: opts}, nil
+}
+
+// Close closes the database
+func (c *Client) Close() error {
+	return c.store.Close()
+}
+
+// Remember stores turn, continuing, resuming, or starting a topic as the MCP server's
+// store_conversation does. With a Provider, the turn is embedded for semantic search.
+func (c *Client) Remember(ctx context.Context, turn Turn) (Stored, error) {
+	if c.opts.ReadOnly {
+		return Stored{}, ErrReadOnly
+	}
+	if turn.UserMessage == "" {
+		return Stored{}, errors.New("turn has no user message")
+	}
+	keywords, topics := turn.Keywords, turn.Topics
+	if len(keywords) == 0 && len(topics) == 0 {
+		keywords, topics = c.extractMetadata(ctx, turn)
+	}
+	t := &models.Turn{
+		TurnID:      models.NewID("turn"),
+		Timestamp:   time.Now(),
+		UserMessage: turn.UserMessage,
+		AIResponse:  turn.AIResponse,
+		Keywords:    keywords,
+		Topics:      topics,
+		Origin:      models.Origin{Agent: c.opts.Agent},
+	}
+
+	decision, err := c.governor.RouteContext(ctx, t)
+	if err != nil {
+		return Stored{}, fmt.Errorf("failed to route turn: %w", err)
+	}
+	blockID := decision.MatchedBlockID
+	switch decision.Scenario {
+	case models.TopicContinuation:
+		err = c.store.AppendTurnToBlockContext(ctx, blockID, t)
+	case models.TopicResumption:
+		if decision.ActiveBlockID != "" {
+			if err := c.store.UpdateBridgeBlockStatus(decision.ActiveBlockID, models.StatusPaused); err != nil {
+				return Stored{}, fmt.Errorf("failed to pause active topic: %w", err)
+			}
+		}
+		if err := c.store.UpdateBridgeBlockStatus(blockID, models.StatusActive); err != nil {
+			return Stored{}, fmt.Errorf("failed to resume topic: %w", err)
+		}
+		err = c.store.AppendTurnToBlockContext(ctx, blockID, t)
+	default: // a new topic; StoreTurn pauses the active one
+		blockID, err = c.store.StoreTurnContext(ctx, t)
+	}
+	if err != nil {
+		return Stored{}, fmt.Errorf("failed to store turn: %w", err)
+	}
+	// A missing link between topics is not worth failing the store
+	if err := c.governor.RecordRelations(decision, blockID); err != nil {
+		log.Printf("[Memory] %v", err)
+	}
+	return Stored{TurnID: t.TurnID, TopicID: blockID, Scenario: string(decision.Scenario)}, nil
+}
+
+// extractMetadata asks the provider for a turn's keywords and topics, when it can;
+// a failed extraction leaves them empty rather than failing the store
+func (c *Client) extractMetadata(ctx context.Context, turn Turn) (keywords, topics []string) {
+	extractor, ok := c.provider.(metadataExtractor)
+	if !ok {
+		return nil, nil
+	}
+	metadata, err := extractor.ExtractMetadataContext(ctx, turn.UserMessage+"\n"+turn.AIResponse)
+	if err != nil {
+		return nil, nil
+	}
+	return stringList(metadata["keywords"]), stringList(metadata["topics"])
+}
+
+// stringList returns the strings in a decoded JSON array
+func stringList(value any) []string {
+	items, _ := value.([]any)
+	var result []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// Search returns the topics most related to query, best first
+func (c *Client) Search(ctx context.Context, query string, opts SearchOptions) ([]Memory, error) {
+	if opts.MaxResults <= 0 {
+		opts.MaxResults = DefaultMaxResults
+	}
+	results, err := c.store.SearchMemoryWithOptions(query, opts.MaxResults, storage.SearchOptions{Tags: opts.Tags})
+	if err != nil {
+		return nil, err
+	}
+	memories := make([]Memory, 0, len(results))
+	for _, r := range results {
+		memories = append(memories, Memory{TopicID: r.BlockID, Label: r.TopicLabel, Summary: r.Summary, Score: r.RelevanceScore, Tags: r.Tags})
+	}
+	return memories, nil
+}
+
+// Hydrate returns related memories, facts, and the profile for message, within about
+// maxTokens tokens, or "" when memory has nothing to add
+func (c *Client) Hydrate(ctx context.Context, message string, maxTokens int) (string, error) {
+	hydrator := core.NewContextHydrator(c.store, c.provider)
+	hydrator.SetPersona(c.opts.Persona)
+	return hydrator.HydrateMemory(message, maxTokens), nil
+}
+
+// AddFact records fact and returns it with its ID and time filled in
+func (c *Client) AddFact(ctx context.Context, fact Fact) (Fact, error) {
+	if c.opts.ReadOnly {
+		return Fact{}, ErrReadOnly
+	}
+	if fact.Key == "" || fact.Value == "" {
+		return Fact{}, errors.New("fact key and value cannot be empty")
+	}
+	if fact.Confidence == 0 {
+		fact.Confidence = 1
+	}
+	if fact.Confidence < 0 || fact.Confidence > 1 {
+		return Fact{}, fmt.Errorf("fact confidence %v is not between 0 and 1", fact.Confidence)
+	}
+	if fact.ID == "" {
+		fact.ID = models.NewID("fact")
+	}
+	if fact.CreatedAt.IsZero() {
+		fact.CreatedAt = time.Now()
+	}
+	if fact.Agent == "" {
+		fact.Agent = c.opts.Agent
+	}
+	if err := c.store.SaveFact(&models.Fact{
+		FactID: fact.ID, Key: fact.Key, Value: fact.Value, Confidence: fact.Confidence,
+		ExtractedBy: fact.Agent, Agent: fact.Agent, CreatedAt: fact.CreatedAt,
+	}); err != nil {
+		return Fact{}, err
+	}
+	return fact, nil
+}
+
+// Fact returns the current fact with key, or nil when there is none
+func (c *Client) Fact(ctx context.Context, key string) (*Fact, error) {
+	f, err := c.store.GetFactByKey(key)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	return &Fact{ID: f.FactID, Key: f.Key, Value: f.Value, Confidence: f.Confidence, Agent: f.ExtractedBy, CreatedAt: f.CreatedAt}, nil
+}
+
+// DeleteFact deletes every fact with key and returns how many there were
+func (c *Client) DeleteFact(ctx context.Context, key string) (int, error) {
+	if c.opts.ReadOnly {
+		return 0, ErrReadOnly
+	}
+	n, err := c.store.DeleteFactByKey(key)
+	return int(n), err
+}
+
+// Profile returns the user profile with persona's layer applied (none when empty),
+// or nil when nothing has been learned yet
+func (c *Client) Profile(ctx context.Context, persona string) (*Profile, error) {
+	p, err := c.store.GetUserProfileAs(persona)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	profile := &Profile{Name: p.Name, Preferences: p.Preferences, TopicsOfInterest: p.TopicsOfInterest, Persona: p.Persona}
+	for _, constraint := range p.Constraints {
+		profile.Constraints = append(profile.Constraints, Constraint(constraint))
+	}
+	return profile, nil
+}
@@ -0,0 +1,141 @@
+// ABOUTME: Tests for the public Go SDK
+// ABOUTME: Verifies routing, keyword and semantic search, facts, hydration, profiles, and read-only databases
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// wordProvider embeds text as a bag of hashed words and extracts every long word as a keyword
+type wordProvider struct{}
+
+func (wordProvider) GenerateEmbedding(text string) ([]float64, error) {
+	vector := make([]float64, EmbeddingDimensions)
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(strings.Trim(word, ".,?!")))
+		vector[h.Sum32()%EmbeddingDimensions]++
+	}
+	return vector, nil
+}
+
+func (wordProvider) ExtractMetadataContext(ctx context.Context, text string) (map[string]any, error) {
+	var keywords []any
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		if word = strings.Trim(word, ".,?!"); len(word) > 4 {
+			keywords = append(keywords, word)
+		}
+	}
+	return map[string]any{"keywords": keywords, "topics": []any{}}, nil
+}
+
+func open(t *testing.T, opts Options) *Client {
+	t.Helper()
+	mem, err := Open(opts)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = mem.Close() })
+	return mem
+}
+
+func TestRememberRoutesAndSearches(t *testing.T) {
+	ctx := context.Background()
+	mem := open(t, Options{Provider: wordProvider{}})
+
+	first, err := mem.Remember(ctx, Turn{UserMessage: "Planning a Lisbon vacation in springtime", AIResponse: "Lisbon is lovely in May."})
+	if err != nil {
+		t.Fatalf("Remember() error = %v", err)
+	}
+	if first.Scenario != "new_topic_first" || first.TopicID == "" || first.TurnID == "" {
+		t.Errorf("first turn = %+v", first)
+	}
+	second, err := mem.Remember(ctx, Turn{UserMessage: "Which Lisbon neighborhoods for the vacation?"})
+	if err != nil {
+		t.Fatalf("Remember() error = %v", err)
+	}
+	if second.TopicID != first.TopicID {
+		t.Errorf("second turn = %+v, want it to continue topic %s", second, first.TopicID)
+	}
+
+	memories, err := mem.Search(ctx, "lisbon", SearchOptions{})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(memories) != 1 || memories[0].TopicID != first.TopicID {
+		t.Errorf("Search() = %+v, want the Lisbon topic", memories)
+	}
+
+	if _, err := mem.Remember(ctx, Turn{}); err == nil {
+		t.Error("Remember() without a user message should fail")
+	}
+}
+
+func TestFactsHydrateAndProfile(t *testing.T) {
+	ctx := context.Background()
+	mem := open(t, Options{})
+
+	if f, err := mem.Fact(ctx, "editor"); err != nil || f != nil {
+		t.Errorf("Fact() before any = %v, %v; want nil, nil", f, err)
+	}
+	added, err := mem.AddFact(ctx, Fact{Key: "editor", Value: "helix", Agent: "sdk-test"})
+	if err != nil {
+		t.Fatalf("AddFact() error = %v", err)
+	}
+	if added.ID == "" || added.Confidence != 1 || added.CreatedAt.IsZero() {
+		t.Errorf("AddFact() = %+v, want ID, confidence, and time filled in", added)
+	}
+	if _, err := mem.AddFact(ctx, Fact{Key: "editor", Value: "vim", Confidence: 2}); err == nil {
+		t.Error("AddFact() with confidence 2 should fail")
+	}
+
+	got, err := mem.Fact(ctx, "editor")
+	if err != nil || got == nil || got.Value != "helix" || got.Agent != "sdk-test" {
+		t.Errorf("Fact() = %+v, %v", got, err)
+	}
+
+	prompt, err := mem.Hydrate(ctx, "editor", 1000)
+	if err != nil || !strings.Contains(prompt, "editor: helix") {
+		t.Errorf("Hydrate() = %q, %v; want the fact", prompt, err)
+	}
+
+	if n, err := mem.DeleteFact(ctx, "editor"); err != nil || n != 1 {
+		t.Errorf("DeleteFact() = %d, %v; want 1", n, err)
+	}
+
+	if profile, err := mem.Profile(ctx, ""); err != nil || profile != nil {
+		t.Errorf("Profile() before learning = %+v, %v; want nil", profile, err)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "memory.db")
+	writer := open(t, Options{Path: path})
+	if _, err := writer.AddFact(ctx, Fact{Key: "city", Value: "Lisbon"}); err != nil {
+		t.Fatalf("AddFact() error = %v", err)
+	}
+
+	reader := open(t, Options{Path: path, ReadOnly: true})
+	if f, err := reader.Fact(ctx, "city"); err != nil || f == nil || f.Value != "Lisbon" {
+		t.Errorf("read-only Fact() = %+v, %v", f, err)
+	}
+	if _, err := reader.Remember(ctx, Turn{UserMessage: "hello"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("read-only Remember() error = %v, want ErrReadOnly", err)
+	}
+	if _, err := Open(Options{ReadOnly: true}); err == nil {
+		t.Error("Open() read-only without a path should fail")
+	}
+}
+
+func TestNewOpenAIProviderNeedsAKey(t *testing.T) {
+	if provider, err := NewOpenAIProvider(""); err == nil || provider != nil {
+		t.Errorf("NewOpenAIProvider(\"\") = %v, %v; want an error and no provider", provider, err)
+	}
+}
@@ -0,0 +1,35 @@
+// ABOUTME: Provider interface for the embeddings memory is searched by, and an OpenAI-backed provider
+// ABOUTME: A provider that can also extract metadata lets Remember route turns that carry no keywords
+
+package memory
+
+import (
+	"context"
+
+	"github.com/harper/remember-standalone/internal/llm"
+)
+
+// EmbeddingDimensions is the vector length a Provider must return, that of OpenAI's text-embedding-3-small
+const EmbeddingDimensions = 1536
+
+// Provider generates the embeddings memory is searched by. GenerateEmbedding must
+// return EmbeddingDimensions values; a database is searched with one provider's vectors.
+type Provider interface {
+	GenerateEmbedding(text string) ([]float64, error)
+}
+
+// metadataExtractor is implemented by providers that can extract a turn's keywords
+// and topics; NewOpenAIProvider's provider does
+type metadataExtractor interface {
+	ExtractMetadataContext(ctx context.Context, text string) (map[string]any, error)
+}
+
+// NewOpenAIProvider returns a provider that embeds with OpenAI's text-embedding-3-small
+// and extracts turn metadata with its chat model, using apiKey
+func NewOpenAIProvider(apiKey string) (Provider, error) {
+	client, err := llm.NewOpenAIClient(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}